@@ -3,28 +3,50 @@ package main
 import (
 	"context"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/getkin/kin-openapi/routers"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/smartwaste/backend/docs"
+	"github.com/smartwaste/backend/internal/auth"
+	"github.com/smartwaste/backend/internal/cache"
 	"github.com/smartwaste/backend/internal/config"
 	"github.com/smartwaste/backend/internal/database"
+	"github.com/smartwaste/backend/internal/graphql"
+	"github.com/smartwaste/backend/internal/grpcapi"
 	"github.com/smartwaste/backend/internal/handlers"
+	"github.com/smartwaste/backend/internal/jobs"
+	"github.com/smartwaste/backend/internal/metrics"
+	"github.com/smartwaste/backend/internal/models"
 	"github.com/smartwaste/backend/internal/mqtt"
 	"github.com/smartwaste/backend/internal/nats"
+	"github.com/smartwaste/backend/internal/payments"
+	"github.com/smartwaste/backend/internal/realtime"
 	"github.com/smartwaste/backend/internal/repository"
 	"github.com/smartwaste/backend/internal/services"
+	"github.com/smartwaste/backend/pkg/security"
+	"github.com/smartwaste/pkg/health"
+	"google.golang.org/grpc"
 )
 
+// healthCheckTimeout bounds each dependency check of the readiness probe
+const healthCheckTimeout = 2 * time.Second
+
 func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
 
 	// Set Gin mode
 	gin.SetMode(cfg.Server.Mode)
+	if err := handlers.RegisterValidators(); err != nil {
+		log.Fatalf("Failed to register request validators: %v", err)
+	}
 
 	// Initialize database connection
 	db, err := database.InitDB(&cfg.Database)
@@ -32,61 +54,355 @@ func main() {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 	defer database.CloseDB()
+	metrics.RegisterDBStats(db.DB)
+
+	// Initialize the read cache; the API keeps working against Postgres alone if Redis is down
+	readCache, err := cache.New(&cfg.Cache)
+	if err != nil {
+		log.Printf("Warning: caching disabled: %v", err)
+		readCache = cache.Noop{}
+	} else if cfg.Cache.Enabled {
+		log.Printf("Caching statistics in Redis at %s for %s", cfg.Cache.RedisAddr, cfg.Cache.TTL)
+	}
 
 	// Initialize repositories
-	userRepo := repository.NewUserRepository(db)
-	driverRepo := repository.NewDriverRepository(db)
-	binRepo := repository.NewBinRepository(db)
-	collectionRepo := repository.NewCollectionRepository(db)
-	companyRepo := repository.NewCompanyRepository(db)
-	pricingRepo := repository.NewPricingRepository(db)
+	repoDB := repository.NewDB(db, cfg.Database.QueryTimeout)
+	userRepo := repository.NewUserRepository(repoDB)
+	driverRepo := repository.NewDriverRepository(repoDB)
+	binRepo := repository.NewBinRepository(repoDB, readCache)
+	collectionRepo := repository.NewCollectionRepository(repoDB)
+	companyRepo := repository.NewCompanyRepository(repoDB)
+	memberRepo := repository.NewCompanyMemberRepository(repoDB)
+	organizationRepo := repository.NewOrganizationRepository(repoDB)
+	contractRepo := repository.NewContractRepository(repoDB)
+	pricingRepo := repository.NewPricingRepository(repoDB)
+	notificationRepo := repository.NewNotificationRepository(repoDB)
+	readingRepo := repository.NewBinReadingRepository(repoDB)
+	deadLetterRepo := repository.NewDeadLetterRepository(repoDB)
+	processedEventRepo := repository.NewProcessedEventRepository(repoDB)
+	collectionSagaRepo := repository.NewCollectionSagaRepository(repoDB)
+	shiftRepo := repository.NewDriverShiftRepository(repoDB)
+	vehicleRepo := repository.NewVehicleRepository(repoDB)
+	rewardRepo := repository.NewRewardRepository(repoDB)
+	searchRepo := repository.NewSearchRepository(repoDB)
+	apiKeyRepo := repository.NewAPIKeyRepository(repoDB)
+	emissionFactorRepo := repository.NewEmissionFactorRepository(repoDB)
+	reportRepo := repository.NewReportRepository(repoDB)
+	slaRepo := repository.NewSLARepository(repoDB)
+	issueReportRepo := repository.NewIssueReportRepository(repoDB)
+	uploadRepo := repository.NewUploadRepository(repoDB)
+	settingsRepo := repository.NewSettingsRepository(repoDB)
+	firmwareRepo := repository.NewFirmwareRepository(repoDB)
+	deviceRepo := repository.NewDeviceRepository(repoDB)
+	maintenanceRepo := repository.NewMaintenanceRepository(repoDB)
+	zoneRepo := repository.NewZoneRepository(repoDB)
+	routeRepo := repository.NewRouteRepository(repoDB)
+	pickupRepo := repository.NewPickupRequestRepository(repoDB)
+	earningRepo := repository.NewEarningRepository(repoDB)
+	paymentRepo := repository.NewPaymentRepository(repoDB)
+	taskOfferRepo := repository.NewTaskOfferRepository(repoDB)
+	opsRepo := repository.NewOpsRepository(repoDB)
+	statsRepo := repository.NewDailyStatsRepository(repoDB)
 
 	// Initialize services
-	notificationSvc := services.NewNotificationService(driverRepo)
-	valuationSvc := services.NewValuationService(pricingRepo)
-	routeSvc := services.NewRouteService(binRepo, &cfg.Google)
-	analyticsSvc := services.NewAnalyticsService(binRepo, collectionRepo, driverRepo)
+	settingsSvc := services.NewSettingsService(settingsRepo, &cfg.Devices, &cfg.Dispatch)
+	if err := settingsSvc.Reload(context.Background()); err != nil {
+		log.Printf("Warning: using the environment configuration: %v", err)
+	}
+	notificationChannels, err := services.NewNotificationChannels(&cfg.Channels)
+	if err != nil {
+		log.Fatalf("Invalid notification channel configuration: %v", err)
+	}
+	notificationSvc := services.NewNotificationService(driverRepo, userRepo, binRepo, notificationRepo, taskOfferRepo, settingsSvc, notificationChannels, &cfg.Channels, &cfg.Alerts)
+	exchangeRateProvider, err := services.NewExchangeRateProvider(&cfg.Currency)
+	if err != nil {
+		log.Fatalf("Invalid exchange rate configuration: %v", err)
+	}
+	if exchangeRateProvider != nil {
+		log.Printf("Using %s exchange rates", exchangeRateProvider.Name())
+	}
+	exchangeRateSvc := services.NewExchangeRateService(exchangeRateProvider, cfg.Currency.TTL)
+	valuationSvc := services.NewValuationService(pricingRepo, contractRepo, exchangeRateSvc)
+	routingProvider, err := services.NewRoutingProvider(&cfg.Routing, &cfg.Google)
+	if err != nil {
+		log.Fatalf("Invalid routing configuration: %v", err)
+	}
+	log.Printf("Using %s routing provider", routingProvider.Name())
+	routeSvc := services.NewRouteService(binRepo, routeRepo, driverRepo, collectionRepo, notificationSvc, routingProvider, &cfg.Routing)
+	analyticsSvc := services.NewAnalyticsService(binRepo, statsRepo, collectionRepo, driverRepo, pricingRepo, readCache, &cfg.Drivers)
+	predictionSvc := services.NewPredictionService(binRepo, readingRepo, &cfg.Prediction)
+	dispatchSvc := services.NewDispatchService(binRepo, collectionRepo, driverRepo, contractRepo, zoneRepo, pickupRepo, notificationSvc, settingsSvc)
+	rewardSvc := services.NewRewardService(rewardRepo)
+	earningSvc := services.NewEarningService(earningRepo, driverRepo, &cfg.Earnings)
+	paymentProvider, err := payments.NewProvider(&cfg.Payments)
+	if err != nil {
+		log.Fatalf("Invalid payments configuration: %v", err)
+	}
+	log.Printf("Using %s payment provider", paymentProvider.Name())
+	taskSvc := services.NewTaskService(taskOfferRepo, binRepo, notificationRepo, notificationSvc)
+	paymentSvc := services.NewPaymentService(paymentRepo, companyRepo, driverRepo, userRepo, paymentProvider, &cfg.Payments)
+	tokenManager := auth.NewTokenManager(&cfg.Security)
+	var tracker *services.ShipmentTrackerClient
+	var sagaSvc *services.CollectionSagaService
+	if cfg.Sagas.TrackerURL != "" {
+		tracker = services.NewShipmentTrackerClient(cfg.Sagas.TrackerURL, tokenManager)
+		sagaSvc = services.NewCollectionSagaService(&cfg.Sagas, collectionSagaRepo, collectionRepo, binRepo, valuationSvc, rewardSvc, tracker)
+		log.Printf("Shipping collected waste to the shipment tracker at %s", cfg.Sagas.TrackerURL)
+	} else {
+		log.Println("No shipment tracker configured - collections and pickups are not shipped")
+	}
+	apiKeySvc := services.NewAPIKeyService(apiKeyRepo)
+	impactSvc := services.NewImpactService(collectionRepo, emissionFactorRepo)
+	reportSvc := services.NewReportService(reportRepo, &cfg.Export)
+	objectStore, err := services.NewObjectStore(&cfg.Storage)
+	if err != nil {
+		log.Fatalf("Invalid storage configuration: %v", err)
+	}
+	if objectStore != nil {
+		log.Printf("Using %s object storage", objectStore.Name())
+	} else {
+		log.Println("No storage provider configured - file uploads are disabled")
+	}
+	uploadSvc := services.NewUploadService(uploadRepo, objectStore, &cfg.Storage)
+	collectionSvc := services.NewCollectionService(collectionRepo, binRepo, driverRepo, rewardSvc, earningSvc, sagaSvc, uploadSvc, &cfg.Collections)
+	provisioningSvc, err := services.NewProvisioningService(binRepo, &cfg.Provisioning, cfg.MQTT.CACertFile)
+	if err != nil {
+		log.Fatalf("Invalid provisioning configuration: %v", err)
+	}
+	if !provisioningSvc.Enabled() {
+		log.Println("No device CA configured - device provisioning is disabled")
+	}
+	deviceSvc := services.NewDeviceService(deviceRepo, binRepo)
+	maintenanceSvc := services.NewMaintenanceService(maintenanceRepo, binRepo)
+	zoneSvc := services.NewZoneService(zoneRepo, binRepo, driverRepo)
+	pickupSvc := services.NewPickupRequestService(pickupRepo, valuationSvc, uploadSvc, notificationSvc, earningSvc, tracker, &cfg.Sagas)
+	issueReportSvc := services.NewIssueReportService(issueReportRepo, binRepo, notificationSvc, uploadSvc, &cfg.Issues)
+
+	// Initialize realtime hub for live dashboard updates
+	hubCtx, stopHub := context.WithCancel(context.Background())
+	defer stopHub()
+	hub := realtime.NewHub()
+	go hub.Run(hubCtx)
+	metrics.RegisterWebSocketConnections(hub.ClientCount)
+	locationBroker := realtime.NewLocationBroker()
 
 	// Initialize MQTT client
-	mqttClient := mqtt.NewClient(&cfg.MQTT, binRepo, notificationSvc)
+	mqttClient, err := mqtt.NewClient(&cfg.MQTT, binRepo, deadLetterRepo, firmwareRepo, notificationSvc, predictionSvc, hub, settingsSvc)
+	if err != nil {
+		log.Fatalf("Invalid MQTT configuration: %v", err)
+	}
 	if err := mqttClient.Connect(); err != nil {
 		log.Printf("Warning: Failed to connect to MQTT broker: %v", err)
 		log.Println("Continuing without MQTT - IoT data ingestion will be unavailable")
 	} else {
-		defer mqttClient.Disconnect()
 		if err := mqttClient.Subscribe(); err != nil {
 			log.Printf("Warning: Failed to subscribe to MQTT topics: %v", err)
 		}
 	}
+	firmwareSvc := services.NewFirmwareService(firmwareRepo, mqttClient)
+
+	// Start background jobs
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	scheduler := jobs.NewScheduler()
+	if err := scheduler.Register(jobs.Job{
+		Name:     "settings-reload",
+		Interval: cfg.Settings.ReloadInterval,
+		Run:      settingsSvc.Reload,
+	}); err != nil {
+		log.Fatalf("Invalid job configuration: %v", err)
+	}
+	offlineDetector := jobs.NewOfflineBinDetector(binRepo, notificationSvc, settingsSvc)
+	if err := scheduler.Register(jobs.Job{
+		Name:     "offline-bins",
+		Interval: cfg.Devices.OfflineCheckInterval,
+		Run:      offlineDetector.Run,
+	}); err != nil {
+		log.Fatalf("Invalid job configuration: %v", err)
+	}
+	autoDispatcher := jobs.NewAutoDispatcher(dispatchSvc)
+	if err := scheduler.Register(jobs.Job{
+		Name:         "auto-dispatch",
+		ScheduleFunc: settingsSvc.DispatchSchedule,
+		Run:          autoDispatcher.Run,
+	}); err != nil {
+		log.Fatalf("Invalid job configuration: %v", err)
+	}
+	maintenanceStarter := jobs.NewMaintenanceStarter(maintenanceSvc)
+	if err := scheduler.Register(jobs.Job{
+		Name:     "maintenance-start",
+		Interval: time.Minute,
+		Run:      maintenanceStarter.Run,
+	}); err != nil {
+		log.Fatalf("Invalid job configuration: %v", err)
+	}
+	routeReoptimizer := jobs.NewRouteReoptimizer(routeSvc)
+	if err := scheduler.Register(jobs.Job{
+		Name:     "route-reoptimize",
+		Interval: cfg.Routing.ReoptimizeInterval,
+		Run:      routeReoptimizer.Run,
+	}); err != nil {
+		log.Fatalf("Invalid job configuration: %v", err)
+	}
+	slaMonitor := jobs.NewSLAMonitor(slaRepo, notificationSvc)
+	if err := scheduler.Register(jobs.Job{
+		Name:     "sla-monitor",
+		Interval: cfg.SLA.CheckInterval,
+		Run:      slaMonitor.Run,
+	}); err != nil {
+		log.Fatalf("Invalid job configuration: %v", err)
+	}
+	alertEscalator := jobs.NewAlertEscalator(notificationSvc)
+	if err := scheduler.Register(jobs.Job{
+		Name:     "alert-escalation",
+		Interval: cfg.Alerts.CheckInterval,
+		Run:      alertEscalator.Run,
+	}); err != nil {
+		log.Fatalf("Invalid job configuration: %v", err)
+	}
+	dailyStatsMaterializer := jobs.NewDailyStatsMaterializer(statsRepo, cfg.Stats.RefreshDays)
+	if err := scheduler.Register(jobs.Job{
+		Name:     "daily-stats",
+		Schedule: cfg.Stats.Schedule,
+		Run:      dailyStatsMaterializer.Run,
+	}); err != nil {
+		log.Fatalf("Invalid job configuration: %v", err)
+	}
+	exportCleaner := jobs.NewExportCleaner(reportSvc)
+	if err := scheduler.Register(jobs.Job{
+		Name:     "export-cleanup",
+		Interval: time.Hour,
+		Run:      exportCleaner.Run,
+	}); err != nil {
+		log.Fatalf("Invalid job configuration: %v", err)
+	}
+	processedEventCleaner := jobs.NewProcessedEventCleaner(processedEventRepo, cfg.NATS.DedupRetention)
+	if err := scheduler.Register(jobs.Job{
+		Name:     "processed-events-cleanup",
+		Interval: time.Hour,
+		Run:      processedEventCleaner.Run,
+	}); err != nil {
+		log.Fatalf("Invalid job configuration: %v", err)
+	}
+	if uploadSvc.Enabled() {
+		uploadCleaner := jobs.NewUploadCleaner(uploadSvc)
+		if err := scheduler.Register(jobs.Job{
+			Name:     "upload-cleanup",
+			Interval: time.Hour,
+			Run:      uploadCleaner.Run,
+		}); err != nil {
+			log.Fatalf("Invalid job configuration: %v", err)
+		}
+	}
+	if sagaSvc != nil {
+		sagaRunner := jobs.NewCollectionSagaRunner(sagaSvc)
+		if err := scheduler.Register(jobs.Job{
+			Name:     "collection-sagas",
+			Interval: cfg.Sagas.RetryInterval,
+			Run:      sagaRunner.Run,
+		}); err != nil {
+			log.Fatalf("Invalid job configuration: %v", err)
+		}
+	}
+	if tracker != nil {
+		pickupShipper := jobs.NewPickupShipper(pickupSvc)
+		if err := scheduler.Register(jobs.Job{
+			Name:     "pickup-shipments",
+			Interval: cfg.Sagas.RetryInterval,
+			Run:      pickupShipper.Run,
+		}); err != nil {
+			log.Fatalf("Invalid job configuration: %v", err)
+		}
+	}
+	scheduler.Start(jobsCtx)
+	if err := reportSvc.Start(jobsCtx); err != nil {
+		log.Fatalf("Failed to start report exports: %v", err)
+	}
 
 	// Initialize NATS client
-	natsClient := nats.NewClient(cfg)
+	natsClient := nats.NewClient(&cfg.NATS)
 	if err := natsClient.Connect(); err != nil {
 		log.Printf("Warning: Failed to connect to NATS: %v", err)
 	} else {
 		defer natsClient.Close()
 
-		// Initialize NATS event handler
-		natsHandler := nats.NewEventHandler(notificationSvc)
-
-		// Subscribe to topics
-		natsClient.Subscribe("shipment.created", natsHandler.HandleShipmentCreated)
-		natsClient.Subscribe("shipment.price.confirmed", natsHandler.HandlePriceConfirmed)
-		natsClient.Subscribe("shipment.pickup.started", natsHandler.HandlePickupStarted)
-		natsClient.Subscribe("shipment.completed", natsHandler.HandleDeliveryCompleted)
+		// Consume the shipment events through the durable consumer
+		natsHandler := nats.NewEventHandler(userRepo, processedEventRepo, notificationSvc, rewardSvc, earningSvc, sagaSvc, cfg.NATS.AckWait)
+		if _, err := natsClient.Consume(natsHandler.Handlers()); err != nil {
+			log.Printf("Warning: Failed to consume NATS shipment events: %v", err)
+		} else {
+			log.Printf("Consuming NATS shipment events as %s", cfg.NATS.Consumer)
+		}
+	}
 
-		log.Println("Subscribed to NATS shipment topics")
+	// Initialize the liveness and readiness probes; the API keeps serving
+	// without the brokers
+	migrations, err := database.Migrations()
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
 	}
+	probes := health.NewChecker(healthCheckTimeout)
+	probes.Require("postgres", health.Database(db))
+	probes.Require("migrations", health.Migrations(db, migrations))
+	probes.Observe("mqtt", health.Connection(mqttClient.IsConnected))
+	probes.Observe("nats", health.Connection(natsClient.IsConnected))
+
+	// Initialize password hasher
+	passwordHasher := security.NewBcryptHasher(cfg.Security.BcryptCost)
+	memberSvc := services.NewCompanyMemberService(memberRepo, userRepo, passwordHasher, cfg.Security.InviteTTL)
 
 	// Initialize handlers
-	userHandler := handlers.NewUserHandler(userRepo)
-	driverHandler := handlers.NewDriverHandler(driverRepo, binRepo, collectionRepo, routeSvc)
-	binHandler := handlers.NewBinHandler(binRepo)
-	companyHandler := handlers.NewCompanyHandler(companyRepo, pricingRepo, valuationSvc)
-	analyticsHandler := handlers.NewAnalyticsHandler(analyticsSvc)
+	authHandler := handlers.NewAuthHandler(userRepo, driverRepo, memberRepo, organizationRepo, passwordHasher, tokenManager)
+	userHandler := handlers.NewUserHandler(userRepo, organizationRepo, passwordHasher)
+	organizationHandler := handlers.NewOrganizationHandler(organizationRepo, userRepo, passwordHasher)
+	driverHandler := handlers.NewDriverHandler(driverRepo, binRepo, collectionRepo, vehicleRepo, collectionSvc, routeSvc, passwordHasher, locationBroker)
+	binHandler := handlers.NewBinHandler(binRepo, predictionSvc, settingsSvc)
+	collectionHandler := handlers.NewCollectionHandler(collectionRepo, binRepo, driverRepo, collectionSvc)
+	notificationHandler := handlers.NewNotificationHandler(notificationRepo, notificationSvc)
+	shiftHandler := handlers.NewShiftHandler(shiftRepo, driverRepo)
+	vehicleHandler := handlers.NewVehicleHandler(vehicleRepo, driverRepo)
+	companyHandler := handlers.NewCompanyHandler(companyRepo, pricingRepo, binRepo, valuationSvc)
+	memberHandler := handlers.NewCompanyMemberHandler(memberSvc, memberRepo, companyRepo, tokenManager)
+	rewardHandler := handlers.NewRewardHandler(rewardRepo)
+	analyticsHandler := handlers.NewAnalyticsHandler(analyticsSvc, companyRepo)
+	impactHandler := handlers.NewImpactHandler(impactSvc, emissionFactorRepo, userRepo, companyRepo)
+	exportHandler := handlers.NewExportHandler(reportSvc)
+	pricingHandler := handlers.NewPricingHandler(pricingRepo, companyRepo, exchangeRateSvc)
+	slaHandler := handlers.NewSLAHandler(slaRepo, companyRepo)
+	contractHandler := handlers.NewContractHandler(contractRepo, companyRepo)
+	issueReportHandler := handlers.NewIssueReportHandler(issueReportSvc, issueReportRepo, binRepo)
+	pickupHandler := handlers.NewPickupRequestHandler(pickupSvc, dispatchSvc, pickupRepo)
+	earningHandler := handlers.NewEarningHandler(earningSvc, earningRepo)
+	paymentHandler := handlers.NewPaymentHandler(paymentSvc, paymentRepo)
+	taskHandler := handlers.NewTaskHandler(taskSvc, taskOfferRepo)
+	opsHandler := handlers.NewOpsHandler(opsRepo)
+	uploadHandler := handlers.NewUploadHandler(uploadSvc)
+	searchHandler := handlers.NewSearchHandler(searchRepo)
+	deadLetterHandler := handlers.NewDeadLetterHandler(deadLetterRepo, mqttClient)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyRepo, apiKeySvc)
+	settingsHandler := handlers.NewSettingsHandler(settingsSvc)
+	deviceCommandHandler := handlers.NewDeviceCommandHandler(binRepo, mqttClient)
+	firmwareHandler := handlers.NewFirmwareHandler(firmwareSvc)
+	provisioningHandler := handlers.NewProvisioningHandler(provisioningSvc, binRepo)
+	deviceHandler := handlers.NewDeviceHandler(deviceSvc)
+	maintenanceHandler := handlers.NewMaintenanceHandler(maintenanceSvc, binRepo)
+	zoneHandler := handlers.NewZoneHandler(zoneSvc)
+	routeHandler := handlers.NewRouteHandler(routeSvc)
+	ingestHandler := handlers.NewIngestHandler(mqttClient)
+	realtimeHandler := handlers.NewRealtimeHandler(hub, locationBroker, driverRepo, &cfg.CORS)
+	graphqlHandler := graphql.NewHandler(binRepo, driverRepo, collectionRepo, companyRepo, analyticsSvc)
+
+	// Load the OpenAPI spec that /api/v1 requests are validated against
+	var apiSpec routers.Router
+	if cfg.Server.ValidateRequests {
+		apiSpec, err = handlers.NewOpenAPIRouter(docs.Spec, "/api/v1")
+		if err != nil {
+			log.Fatalf("Failed to load OpenAPI spec: %v", err)
+		}
+	}
 
 	// Setup router
-	router := setupRouter(userHandler, driverHandler, binHandler, companyHandler, analyticsHandler, mqttClient)
+	router := setupRouter(tokenManager, apiKeySvc, authHandler, userHandler, organizationHandler, driverHandler, notificationHandler, taskHandler, shiftHandler, vehicleHandler, binHandler, deviceCommandHandler, firmwareHandler, provisioningHandler, deviceHandler, maintenanceHandler, zoneHandler, routeHandler, ingestHandler, collectionHandler, companyHandler, memberHandler, rewardHandler, analyticsHandler, opsHandler, impactHandler, exportHandler, pricingHandler, slaHandler, contractHandler, issueReportHandler, pickupHandler, earningHandler, paymentHandler, uploadHandler, searchHandler, deadLetterHandler, apiKeyHandler, settingsHandler, realtimeHandler, graphqlHandler, apiSpec, &cfg.Server, &cfg.CORS, probes)
 
 	// Create server
 	srv := &http.Server{
@@ -105,6 +421,22 @@ func main() {
 		}
 	}()
 
+	// Serve the gRPC API alongside the REST API
+	var grpcServer *grpc.Server
+	if cfg.Server.GRPCPort != "" {
+		lis, err := net.Listen("tcp", ":"+cfg.Server.GRPCPort)
+		if err != nil {
+			log.Fatalf("Failed to listen on gRPC port: %v", err)
+		}
+		grpcServer = grpcapi.NewServer(tokenManager, apiKeySvc, binRepo, driverRepo, collectionRepo, collectionSvc, hub, locationBroker)
+		go func() {
+			log.Printf("gRPC server starting on port %s", cfg.Server.GRPCPort)
+			if err := grpcServer.Serve(lis); err != nil {
+				log.Fatalf("Failed to start gRPC server: %v", err)
+			}
+		}()
+	}
+
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -117,110 +449,519 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
+	if grpcServer != nil {
+		grpcServer.GracefulStop()
+	}
+	mqttClient.Close()
+	stopJobs()
+	scheduler.Wait()
+	reportSvc.Wait()
 
 	log.Println("Server exited gracefully")
 }
 
 func setupRouter(
+	tokenManager *auth.TokenManager,
+	apiKeySvc *services.APIKeyService,
+	authHandler *handlers.AuthHandler,
 	userHandler *handlers.UserHandler,
+	organizationHandler *handlers.OrganizationHandler,
 	driverHandler *handlers.DriverHandler,
+	notificationHandler *handlers.NotificationHandler,
+	taskHandler *handlers.TaskHandler,
+	shiftHandler *handlers.ShiftHandler,
+	vehicleHandler *handlers.VehicleHandler,
 	binHandler *handlers.BinHandler,
+	deviceCommandHandler *handlers.DeviceCommandHandler,
+	firmwareHandler *handlers.FirmwareHandler,
+	provisioningHandler *handlers.ProvisioningHandler,
+	deviceHandler *handlers.DeviceHandler,
+	maintenanceHandler *handlers.MaintenanceHandler,
+	zoneHandler *handlers.ZoneHandler,
+	routeHandler *handlers.RouteHandler,
+	ingestHandler *handlers.IngestHandler,
+	collectionHandler *handlers.CollectionHandler,
 	companyHandler *handlers.CompanyHandler,
+	memberHandler *handlers.CompanyMemberHandler,
+	rewardHandler *handlers.RewardHandler,
 	analyticsHandler *handlers.AnalyticsHandler,
-	mqttClient *mqtt.Client,
+	opsHandler *handlers.OpsHandler,
+	impactHandler *handlers.ImpactHandler,
+	exportHandler *handlers.ExportHandler,
+	pricingHandler *handlers.PricingHandler,
+	slaHandler *handlers.SLAHandler,
+	contractHandler *handlers.ContractHandler,
+	issueReportHandler *handlers.IssueReportHandler,
+	pickupHandler *handlers.PickupRequestHandler,
+	earningHandler *handlers.EarningHandler,
+	paymentHandler *handlers.PaymentHandler,
+	uploadHandler *handlers.UploadHandler,
+	searchHandler *handlers.SearchHandler,
+	deadLetterHandler *handlers.DeadLetterHandler,
+	apiKeyHandler *handlers.APIKeyHandler,
+	settingsHandler *handlers.SettingsHandler,
+	realtimeHandler *handlers.RealtimeHandler,
+	graphqlHandler *graphql.Handler,
+	apiSpec routers.Router,
+	serverCfg *config.ServerConfig,
+	corsCfg *config.CORSConfig,
+	probes *health.Checker,
 ) *gin.Engine {
 	router := gin.New()
+	if err := router.SetTrustedProxies(serverCfg.TrustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 
 	// Middleware
 	router.Use(handlers.RecoveryMiddleware())
 	router.Use(handlers.LoggerMiddleware())
-	router.Use(handlers.CORSMiddleware())
+	router.Use(handlers.SecurityHeadersMiddleware(serverCfg.HSTSMaxAge))
+	router.Use(handlers.CORSMiddleware(corsCfg))
 	router.Use(handlers.RequestIDMiddleware())
+	router.Use(handlers.MetricsMiddleware())
+	// Innermost, so the other middleware see the responses it writes
+	router.Use(handlers.ErrorMiddleware())
 
-	// Health check
-	router.GET("/health", func(c *gin.Context) {
-		status := "healthy"
-		mqttStatus := "connected"
-		if mqttClient == nil || !mqttClient.IsConnected() {
-			mqttStatus = "disconnected"
-		}
-		c.JSON(http.StatusOK, gin.H{
-			"status":      status,
-			"mqtt_status": mqttStatus,
-			"timestamp":   time.Now().UTC(),
-		})
+	// Liveness and readiness probes
+	router.GET("/healthz", func(c *gin.Context) {
+		c.JSON(http.StatusOK, probes.Live())
 	})
+	router.GET("/readyz", func(c *gin.Context) {
+		report := probes.Ready(c.Request.Context())
+		c.JSON(report.HTTPStatus(), report)
+	})
+
+	// Prometheus metrics
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	// OpenAPI spec and Swagger UI
+	if serverCfg.Swagger {
+		router.GET("/swagger/*any", handlers.SwaggerHandler(docs.Spec))
+	}
+
+	// Role shorthands for route authorization
+	admin := models.RoleAdmin
+	dispatcher := models.RoleDispatcher
+	company := models.RoleCompany
+	driver := models.RoleDriver
+	citizen := models.RoleCitizen
+	device := models.RoleDevice
+	owner := models.CompanyOwner
+	manager := models.CompanyManager
+
+	// Companies and the other reference data are shared by every
+	// organization, so only the default organization's admins change them
+	platform := handlers.RequireOrganization(models.DefaultOrganizationID)
+
+	// Live update streams
+	ws := router.Group("/ws")
+	ws.Use(handlers.StreamAuthMiddleware(tokenManager), handlers.RequireRoles(admin, dispatcher, company))
+	{
+		ws.GET("/bins", realtimeHandler.StreamBins)
+	}
+
+	// GraphQL gateway for the admin dashboard
+	gql := router.Group("/graphql")
+	gql.Use(handlers.AuthMiddleware(tokenManager, apiKeySvc), handlers.RequireRoles(admin, dispatcher))
+	{
+		gql.GET("", graphqlHandler.Query)
+		gql.POST("", graphqlHandler.Query)
+	}
 
 	// API v1 routes
 	v1 := router.Group("/api/v1")
+	if apiSpec != nil {
+		v1.Use(handlers.OpenAPIValidationMiddleware(apiSpec))
+	}
 	{
+		// Public routes
+		v1.POST("/auth/login", authHandler.Login)
+		v1.POST("/auth/invites/accept", memberHandler.AcceptInvite)
+		v1.POST("/users", userHandler.CreateUser)
+		v1.GET("/bins/nearby", binHandler.FindNearbyBins)
+
+		// Payment providers authenticate webhooks by their signature
+		v1.POST("/payments/webhooks/:provider", paymentHandler.HandleWebhook)
+
+		// Event streams authenticate via header or access_token query parameter
+		v1.GET("/drivers/:id/location/stream",
+			handlers.StreamAuthMiddleware(tokenManager),
+			handlers.RequireSelfOrRoles("id", admin, dispatcher),
+			realtimeHandler.StreamDriverLocation)
+	}
+
+	// Authenticated routes
+	api := v1.Group("")
+	api.Use(handlers.AuthMiddleware(tokenManager, apiKeySvc))
+	{
+		api.GET("/auth/me", authHandler.Me)
+		api.GET("/organizations/current", organizationHandler.GetCurrentOrganization)
+
 		// User routes
-		users := v1.Group("/users")
+		users := api.Group("/users")
 		{
-			users.GET("", userHandler.ListUsers)
-			users.POST("", userHandler.CreateUser)
-			users.GET("/:id", userHandler.GetUser)
-			users.PUT("/:id", userHandler.UpdateUser)
-			users.DELETE("/:id", userHandler.DeleteUser)
-			users.GET("/:id/rewards", userHandler.GetRewardPoints)
-			users.POST("/:id/rewards", userHandler.AddRewardPoints)
+			users.GET("", handlers.RequireRoles(admin), userHandler.ListUsers)
+			users.GET("/:id", handlers.RequireSelfOrRoles("id", admin), userHandler.GetUser)
+			users.PUT("/:id", handlers.RequireSelfOrRoles("id", admin), userHandler.UpdateUser)
+			users.DELETE("/:id", handlers.RequireRoles(admin), userHandler.DeleteUser)
+			users.PUT("/:id/role", handlers.RequireRoles(admin), userHandler.UpdateUserRole)
+			users.GET("/:id/rewards", handlers.RequireSelfOrRoles("id", admin), userHandler.GetRewardPoints)
+			users.POST("/:id/rewards", handlers.RequireRoles(admin), userHandler.AddRewardPoints)
+			users.GET("/:id/rewards/history", handlers.RequireSelfOrRoles("id", admin), rewardHandler.ListRewardHistory)
+			users.GET("/:id/impact", handlers.RequireSelfOrRoles("id", admin), impactHandler.GetUserImpact)
+			users.GET("/:id/notifications", handlers.RequireSelfOrRoles("id", admin), notificationHandler.ListUserNotifications)
+			users.POST("/:id/notifications/:notificationId/acknowledge", handlers.RequireSelfOrRoles("id"), notificationHandler.AcknowledgeUserNotification)
+			users.GET("/:id/notification-channels", handlers.RequireSelfOrRoles("id", admin), notificationHandler.GetUserChannels)
+			users.PUT("/:id/notification-channels", handlers.RequireSelfOrRoles("id", admin), notificationHandler.SetUserChannels)
+			users.PUT("/:id/payment-account", handlers.RequireSelfOrRoles("id", admin), paymentHandler.SetUserPaymentAccount)
 		}
 
 		// Driver routes
-		drivers := v1.Group("/drivers")
+		drivers := api.Group("/drivers")
+		{
+			drivers.GET("", handlers.RequireRoles(admin, dispatcher), driverHandler.ListDrivers)
+			drivers.POST("", handlers.RequireRoles(admin, dispatcher), driverHandler.CreateDriver)
+			drivers.GET("/:id", handlers.RequireSelfOrRoles("id", admin, dispatcher), driverHandler.GetDriver)
+			drivers.PUT("/:id", handlers.RequireSelfOrRoles("id", admin, dispatcher), driverHandler.UpdateDriver)
+			drivers.PUT("/:id/location", handlers.RequireSelfOrRoles("id"), driverHandler.UpdateLocation)
+			drivers.GET("/:id/routes", handlers.RequireSelfOrRoles("id", admin, dispatcher), driverHandler.GetRoutes)
+			drivers.POST("/:id/verify", handlers.RequireSelfOrRoles("id"), driverHandler.VerifyTask)
+			drivers.GET("/:id/stats", handlers.RequireSelfOrRoles("id", admin, dispatcher), driverHandler.GetDriverStats)
+
+			// Shift schedule
+			drivers.GET("/:id/shifts", handlers.RequireSelfOrRoles("id", admin, dispatcher), shiftHandler.ListShifts)
+			drivers.POST("/:id/shifts", handlers.RequireRoles(admin, dispatcher), shiftHandler.CreateShift)
+			drivers.DELETE("/:id/shifts/:shiftId", handlers.RequireRoles(admin, dispatcher), shiftHandler.DeleteShift)
+
+			// Vehicle assignment
+			drivers.GET("/:id/vehicle", handlers.RequireSelfOrRoles("id", admin, dispatcher), vehicleHandler.GetDriverVehicle)
+			drivers.PUT("/:id/vehicle", handlers.RequireRoles(admin, dispatcher), vehicleHandler.AssignVehicle)
+			drivers.DELETE("/:id/vehicle", handlers.RequireRoles(admin, dispatcher), vehicleHandler.UnassignVehicle)
+
+			// Zones the driver is dispatched to
+			drivers.GET("/:id/zones", handlers.RequireSelfOrRoles("id", admin, dispatcher), zoneHandler.GetDriverZones)
+			drivers.PUT("/:id/zones", handlers.RequireRoles(admin, dispatcher), zoneHandler.SetDriverZones)
+
+			// Notification inbox
+			drivers.GET("/:id/notifications", handlers.RequireSelfOrRoles("id", admin, dispatcher), notificationHandler.ListNotifications)
+			drivers.GET("/:id/notifications/unread-count", handlers.RequireSelfOrRoles("id", admin, dispatcher), notificationHandler.GetUnreadCount)
+			drivers.PUT("/:id/notifications/read-all", handlers.RequireSelfOrRoles("id"), notificationHandler.MarkAllAsRead)
+			drivers.PUT("/:id/notifications/:notificationId/read", handlers.RequireSelfOrRoles("id"), notificationHandler.MarkAsRead)
+			drivers.POST("/:id/notifications/:notificationId/acknowledge", handlers.RequireSelfOrRoles("id"), notificationHandler.AcknowledgeNotification)
+			drivers.DELETE("/:id/notifications/:notificationId", handlers.RequireSelfOrRoles("id", admin), notificationHandler.DeleteNotification)
+			drivers.GET("/:id/notification-channels", handlers.RequireSelfOrRoles("id", admin, dispatcher), notificationHandler.GetDriverChannels)
+			drivers.PUT("/:id/notification-channels", handlers.RequireSelfOrRoles("id", admin), notificationHandler.SetDriverChannels)
+			drivers.GET("/:id/tasks", handlers.RequireSelfOrRoles("id", admin, dispatcher), taskHandler.ListTasks)
+			drivers.POST("/:id/tasks/:taskId/accept", handlers.RequireSelfOrRoles("id"), taskHandler.AcceptTask)
+			drivers.POST("/:id/tasks/:taskId/decline", handlers.RequireSelfOrRoles("id"), taskHandler.DeclineTask)
+			drivers.GET("/:id/notification-settings", handlers.RequireSelfOrRoles("id", admin, dispatcher), notificationHandler.GetDriverSettings)
+			drivers.PUT("/:id/notification-settings", handlers.RequireSelfOrRoles("id", admin), notificationHandler.UpdateDriverSettings)
+
+			// Earnings statement and bonuses
+			drivers.GET("/:id/earnings", handlers.RequireSelfOrRoles("id", admin, dispatcher), earningHandler.GetDriverEarnings)
+			drivers.POST("/:id/earnings/bonuses", handlers.RequireRoles(admin), earningHandler.GrantBonus)
+			drivers.PUT("/:id/payment-account", handlers.RequireSelfOrRoles("id", admin), paymentHandler.SetDriverPaymentAccount)
+		}
+
+		// Planned collection routes; drivers only see their own
+		routes := api.Group("/routes")
+		routes.Use(handlers.RequireRoles(admin, dispatcher, driver))
 		{
-			drivers.GET("", driverHandler.ListDrivers)
-			drivers.POST("", driverHandler.CreateDriver)
-			drivers.GET("/:id", driverHandler.GetDriver)
-			drivers.PUT("/:id", driverHandler.UpdateDriver)
-			drivers.PUT("/:id/location", driverHandler.UpdateLocation)
-			drivers.GET("/:id/routes", driverHandler.GetRoutes)
-			drivers.POST("/:id/verify", driverHandler.VerifyTask)
-			drivers.GET("/:id/stats", driverHandler.GetDriverStats)
+			routes.GET("/:id", routeHandler.GetRoute)
+			routes.GET("/:id/export", routeHandler.ExportRoute)
+			routes.POST("/:id/reoptimize", routeHandler.ReoptimizeRoute)
+		}
+
+		// Vehicle routes
+		vehicles := api.Group("/vehicles")
+		vehicles.Use(handlers.RequireRoles(admin, dispatcher))
+		{
+			vehicles.GET("", vehicleHandler.ListVehicles)
+			vehicles.POST("", vehicleHandler.CreateVehicle)
+			vehicles.GET("/:id", vehicleHandler.GetVehicle)
+			vehicles.PUT("/:id", vehicleHandler.UpdateVehicle)
+			vehicles.DELETE("/:id", handlers.RequireRoles(admin), vehicleHandler.DeleteVehicle)
 		}
 
 		// Bin routes
-		bins := v1.Group("/bins")
+		bins := api.Group("/bins")
 		{
 			bins.GET("", binHandler.ListBins)
-			bins.POST("", binHandler.CreateBin)
+			bins.POST("", handlers.RequireRoles(admin, dispatcher), binHandler.CreateBin)
 			bins.GET("/needs-collection", binHandler.GetBinsNeedingCollection)
+			bins.GET("/low-battery", binHandler.GetLowBatteryBins)
+			bins.GET("/offline", binHandler.GetOfflineBins)
 			bins.GET("/statistics", binHandler.GetBinStatistics)
+			bins.POST("/import", handlers.RequireRoles(admin, dispatcher), binHandler.ImportBins)
+			bins.GET("/export", handlers.RequireRoles(admin, dispatcher), binHandler.ExportBins)
 			bins.GET("/:id", binHandler.GetBin)
-			bins.PUT("/:id", binHandler.UpdateBin)
-			bins.DELETE("/:id", binHandler.DeleteBin)
+			bins.GET("/:id/prediction", binHandler.GetPrediction)
+			bins.POST("/:id/commands", handlers.RequireRoles(admin, dispatcher), deviceCommandHandler.SendCommand)
+			bins.PUT("/:id/config", handlers.RequireRoles(admin), deviceCommandHandler.PushConfig)
+			bins.POST("/:id/provisioning/reset", handlers.RequireRoles(admin), provisioningHandler.ResetProvisioning)
+			bins.GET("/:id/devices", handlers.RequireRoles(admin, dispatcher), deviceHandler.ListBinDevices)
+			bins.GET("/:id/maintenance", handlers.RequireRoles(admin, dispatcher), maintenanceHandler.ListBinMaintenance)
+			bins.POST("/:id/maintenance", handlers.RequireRoles(admin, dispatcher), maintenanceHandler.ScheduleMaintenance)
+			bins.PUT("/:id", handlers.RequireRoles(admin, dispatcher), binHandler.UpdateBin)
+			bins.PUT("/:id/thresholds", handlers.RequireRoles(admin), binHandler.UpdateBinThresholds)
+			bins.DELETE("/:id", handlers.RequireRoles(admin), binHandler.DeleteBin)
+			bins.GET("/:id/reports", handlers.RequireRoles(admin, dispatcher), issueReportHandler.ListBinIssueReports)
+			bins.POST("/:id/reports", handlers.RequireRoles(citizen, driver, admin, dispatcher), issueReportHandler.CreateIssueReport)
+		}
+
+		// Device registry routes
+		devices := api.Group("/devices")
+		devices.Use(handlers.RequireRoles(admin, dispatcher))
+		{
+			devices.GET("", deviceHandler.ListDevices)
+			devices.POST("", deviceHandler.RegisterDevice)
+			devices.GET("/:id", deviceHandler.GetDevice)
+			devices.PUT("/:id", deviceHandler.UpdateDevice)
+			devices.POST("/:id/assign", deviceHandler.AssignDevice)
+			devices.POST("/:id/decommission", handlers.RequireRoles(admin), deviceHandler.DecommissionDevice)
+			devices.GET("/:id/assignments", deviceHandler.ListDeviceAssignments)
+		}
+
+		// Bin maintenance routes
+		maintenance := api.Group("/maintenance")
+		maintenance.Use(handlers.RequireRoles(admin, dispatcher))
+		{
+			maintenance.GET("", maintenanceHandler.ListMaintenance)
+			maintenance.GET("/:id", maintenanceHandler.GetMaintenance)
+			maintenance.POST("/:id/start", maintenanceHandler.StartMaintenance)
+			maintenance.POST("/:id/complete", maintenanceHandler.CompleteMaintenance)
+			maintenance.POST("/:id/cancel", maintenanceHandler.CancelMaintenance)
+		}
+
+		// Zone routes; dispatchers plan by zone, admins draw them
+		zones := api.Group("/zones")
+		zones.Use(handlers.RequireRoles(admin, dispatcher))
+		{
+			zones.GET("", zoneHandler.ListZones)
+			zones.POST("", handlers.RequireRoles(admin), zoneHandler.CreateZone)
+			zones.GET("/:id", zoneHandler.GetZone)
+			zones.PUT("/:id", handlers.RequireRoles(admin), zoneHandler.UpdateZone)
+			zones.DELETE("/:id", handlers.RequireRoles(admin), zoneHandler.DeleteZone)
+			zones.GET("/:id/bins", zoneHandler.ListZoneBins)
+			zones.GET("/:id/analytics", zoneHandler.GetZoneAnalytics)
+		}
+
+		// Firmware rollout routes
+		firmwareRollouts := api.Group("/firmware-rollouts")
+		firmwareRollouts.Use(handlers.RequireRoles(admin))
+		{
+			firmwareRollouts.GET("", firmwareHandler.ListRollouts)
+			firmwareRollouts.POST("", firmwareHandler.CreateRollout)
+			firmwareRollouts.GET("/:id", firmwareHandler.GetRollout)
+			firmwareRollouts.GET("/:id/updates", firmwareHandler.ListUpdates)
+			firmwareRollouts.POST("/:id/cancel", firmwareHandler.CancelRollout)
+		}
+
+		// Sensor ingestion for bins that cannot reach the MQTT broker
+		api.POST("/ingest/bin-status", handlers.RequireRoles(device), ingestHandler.IngestBinStatus)
+
+		// First-boot provisioning of sensors, with the fleet's device API key
+		api.POST("/provisioning/register", handlers.RequireRoles(device), provisioningHandler.Provision)
+
+		// Collection routes
+		collections := api.Group("/collections")
+		collections.Use(handlers.RequireRoles(admin, dispatcher, driver))
+		{
+			collections.GET("", collectionHandler.ListCollections)
+			collections.POST("", handlers.RequireRoles(admin, dispatcher), collectionHandler.CreateCollection)
+			collections.GET("/:id", collectionHandler.GetCollection)
+			collections.POST("/:id/complete", collectionHandler.CompleteCollection)
+			collections.POST("/:id/cancel", handlers.RequireRoles(admin, dispatcher), collectionHandler.CancelCollection)
+		}
+
+		// Issue reports filed on bins; reporters other than staff see their own
+		reports := api.Group("/reports")
+		reports.Use(handlers.RequireRoles(citizen, driver, admin, dispatcher))
+		{
+			reports.GET("", issueReportHandler.ListIssueReports)
+			reports.GET("/:id", issueReportHandler.GetIssueReport)
+			reports.PUT("/:id/status", handlers.RequireRoles(admin, dispatcher), issueReportHandler.UpdateIssueReportStatus)
+		}
+
+		// On-demand pickups requested by citizens; citizens see their own and
+		// drivers those assigned to them
+		pickups := api.Group("/pickup-requests")
+		pickups.Use(handlers.RequireRoles(citizen, driver, admin, dispatcher))
+		{
+			pickups.POST("", handlers.RequireRoles(citizen), pickupHandler.CreatePickupRequest)
+			pickups.GET("", pickupHandler.ListPickupRequests)
+			pickups.GET("/:id", pickupHandler.GetPickupRequest)
+			pickups.POST("/:id/assign", handlers.RequireRoles(admin, dispatcher), pickupHandler.AssignPickupRequest)
+			pickups.POST("/:id/complete", handlers.RequireRoles(driver, admin, dispatcher), pickupHandler.CompletePickupRequest)
+			pickups.POST("/:id/cancel", handlers.RequireRoles(citizen, admin, dispatcher), pickupHandler.CancelPickupRequest)
+		}
+
+		// Payout batches settling the driver earnings, for finance
+		payouts := api.Group("/payout-batches")
+		payouts.Use(handlers.RequireRoles(admin))
+		{
+			payouts.GET("", earningHandler.ListPayoutBatches)
+			payouts.POST("", earningHandler.CreatePayoutBatch)
+			payouts.GET("/:id", earningHandler.GetPayoutBatch)
+			payouts.POST("/:id/mark-paid", earningHandler.MarkPayoutBatchPaid)
 		}
 
+		// Company charges and driver and user payouts through the payment provider
+		paymentRoutes := api.Group("/payments")
+		paymentRoutes.Use(handlers.RequireRoles(admin))
+		{
+			paymentRoutes.GET("", paymentHandler.ListPayments)
+			paymentRoutes.POST("/charges", paymentHandler.CreateCharge)
+			paymentRoutes.POST("/payouts", paymentHandler.CreatePayout)
+			paymentRoutes.GET("/:id", paymentHandler.GetPayment)
+		}
+
+		// Pre-signed file uploads for photos and evidence
+		api.POST("/uploads", handlers.RequireRoles(admin, dispatcher, company, driver, citizen), uploadHandler.CreateUpload)
+
 		// Company routes
-		companies := v1.Group("/companies")
+		companies := api.Group("/companies")
 		{
 			companies.GET("", companyHandler.ListCompanies)
-			companies.POST("", companyHandler.CreateCompany)
+			companies.POST("", handlers.RequireRoles(admin), platform, companyHandler.CreateCompany)
 			companies.GET("/:id", companyHandler.GetCompany)
-			companies.PUT("/:id", companyHandler.UpdateCompany)
-			companies.DELETE("/:id", companyHandler.DeleteCompany)
+			companies.PUT("/:id", handlers.RequireRoles(admin), platform, companyHandler.UpdateCompany)
+			companies.DELETE("/:id", handlers.RequireRoles(admin), platform, companyHandler.DeleteCompany)
+			companies.PUT("/:id/bin-thresholds", handlers.RequireRoles(admin), platform, companyHandler.UpdateBinThresholds)
+			companies.PUT("/:id/payment-account", handlers.RequireRoles(admin), platform, paymentHandler.SetCompanyPaymentAccount)
+			companies.GET("/:id/analytics", handlers.RequireCompanyOrRoles("id", admin, dispatcher), analyticsHandler.GetCompanyAnalytics)
+			companies.GET("/:id/impact", handlers.RequireCompanyOrRoles("id", admin, dispatcher), impactHandler.GetCompanyImpact)
+			companies.POST("/:id/valuations", companyHandler.CalculateCompanyValuation)
+			companies.PUT("/:id/pricing-rules/bulk", handlers.RequireCompanyOrRoles("id", admin), handlers.RequireCompanyMember(owner, manager), companyHandler.BulkUpsertPricingRules)
+
+			// Company portal accounts; owners manage the members
+			companies.GET("/:id/members", handlers.RequireCompanyOrRoles("id", admin), memberHandler.ListMembers)
+			companies.PUT("/:id/members/:userId", handlers.RequireCompanyOrRoles("id", admin), handlers.RequireCompanyMember(owner), memberHandler.UpdateMember)
+			companies.DELETE("/:id/members/:userId", handlers.RequireCompanyOrRoles("id", admin), handlers.RequireCompanyMember(owner), memberHandler.RemoveMember)
+			companies.GET("/:id/invites", handlers.RequireCompanyOrRoles("id", admin), handlers.RequireCompanyMember(owner), memberHandler.ListInvites)
+			companies.POST("/:id/invites", handlers.RequireCompanyOrRoles("id", admin), handlers.RequireCompanyMember(owner), memberHandler.CreateInvite)
+			companies.DELETE("/:id/invites/:inviteId", handlers.RequireCompanyOrRoles("id", admin), handlers.RequireCompanyMember(owner), memberHandler.RevokeInvite)
 		}
 
 		// Pricing rules routes
-		pricingRules := v1.Group("/pricing-rules")
+		pricingRules := api.Group("/pricing-rules")
 		{
 			pricingRules.GET("", companyHandler.ListPricingRules)
-			pricingRules.POST("", companyHandler.CreatePricingRule)
+			pricingRules.POST("", handlers.RequireRoles(admin, company), handlers.RequireCompanyMember(owner, manager), companyHandler.CreatePricingRule)
 			pricingRules.GET("/:id", companyHandler.GetPricingRule)
-			pricingRules.PUT("/:id", companyHandler.UpdatePricingRule)
-			pricingRules.DELETE("/:id", companyHandler.DeletePricingRule)
+			pricingRules.GET("/:id/versions", companyHandler.ListPricingRuleVersions)
+			pricingRules.PUT("/:id", handlers.RequireRoles(admin, company), handlers.RequireCompanyMember(owner, manager), companyHandler.UpdatePricingRule)
+			pricingRules.DELETE("/:id", handlers.RequireRoles(admin, company), handlers.RequireCompanyMember(owner, manager), companyHandler.DeletePricingRule)
 		}
 
+		// Time-limited promotional rates
+		promotions := api.Group("/pricing-promotions")
+		{
+			promotions.GET("", pricingHandler.ListPromotions)
+			promotions.POST("", handlers.RequireRoles(admin, company), handlers.RequireCompanyMember(owner, manager), pricingHandler.CreatePromotion)
+			promotions.PUT("/:id", handlers.RequireRoles(admin, company), handlers.RequireCompanyMember(owner, manager), pricingHandler.UpdatePromotion)
+			promotions.DELETE("/:id", handlers.RequireRoles(admin, company), handlers.RequireCompanyMember(owner, manager), pricingHandler.DeletePromotion)
+		}
+
+		// Market prices of recovered materials
+		api.GET("/market-prices", pricingHandler.ListMarketPrices)
+		api.POST("/market-prices", handlers.RequireRoles(admin), platform, pricingHandler.CreateMarketPrice)
+		api.GET("/exchange-rates", pricingHandler.GetExchangeRates)
+
 		// Valuations
-		v1.POST("/valuations", companyHandler.CalculateValuation)
+		api.POST("/valuations", companyHandler.CalculateValuation)
+		api.POST("/valuations/compare", companyHandler.CompareValuations)
+
+		// Reward earning rules
+		rewardRules := api.Group("/reward-rules")
+		{
+			rewardRules.GET("", rewardHandler.ListRewardRules)
+			rewardRules.POST("", handlers.RequireRoles(admin), platform, rewardHandler.CreateRewardRule)
+			rewardRules.PUT("/:id", handlers.RequireRoles(admin), platform, rewardHandler.UpdateRewardRule)
+			rewardRules.DELETE("/:id", handlers.RequireRoles(admin), platform, rewardHandler.DeleteRewardRule)
+		}
+
+		// Emission factors of the CO2 impact reports
+		emissionFactors := api.Group("/emission-factors")
+		{
+			emissionFactors.GET("", impactHandler.ListEmissionFactors)
+			emissionFactors.POST("", handlers.RequireRoles(admin), platform, impactHandler.CreateEmissionFactor)
+			emissionFactors.PUT("/:id", handlers.RequireRoles(admin), platform, impactHandler.UpdateEmissionFactor)
+			emissionFactors.DELETE("/:id", handlers.RequireRoles(admin), platform, impactHandler.DeleteEmissionFactor)
+		}
+
+		// Collection SLA rules
+		slaRules := api.Group("/sla-rules")
+		slaRules.Use(handlers.RequireRoles(admin, dispatcher))
+		{
+			slaRules.GET("", slaHandler.ListSLARules)
+			slaRules.POST("", handlers.RequireRoles(admin), platform, slaHandler.CreateSLARule)
+			slaRules.PUT("/:id", handlers.RequireRoles(admin), platform, slaHandler.UpdateSLARule)
+			slaRules.DELETE("/:id", handlers.RequireRoles(admin), platform, slaHandler.DeleteSLARule)
+		}
+
+		// Contracts between companies and municipalities
+		contracts := api.Group("/contracts")
+		contracts.Use(handlers.RequireRoles(admin, dispatcher, company))
+		{
+			contracts.GET("", contractHandler.ListContracts)
+			contracts.POST("", handlers.RequireRoles(admin), platform, contractHandler.CreateContract)
+			contracts.GET("/:id", contractHandler.GetContract)
+			contracts.PUT("/:id", handlers.RequireRoles(admin), platform, contractHandler.UpdateContract)
+			contracts.DELETE("/:id", handlers.RequireRoles(admin), platform, contractHandler.DeleteContract)
+		}
+
+		// Real-time operations screen
+		api.GET("/ops/live", handlers.RequireRoles(admin, dispatcher), opsHandler.GetLive)
 
-		// Analytics routes
-		analytics := v1.Group("/analytics")
+		// Analytics routes; drivers see the leaderboard in their app
+		api.GET("/analytics/drivers/leaderboard", handlers.RequireRoles(admin, dispatcher, driver), analyticsHandler.GetDriverLeaderboard)
+		analytics := api.Group("/analytics")
+		analytics.Use(handlers.RequireRoles(admin, dispatcher))
 		{
 			analytics.GET("/dashboard", analyticsHandler.GetDashboardStats)
 			analytics.GET("/bins", analyticsHandler.GetBinAnalytics)
 			analytics.GET("/drivers", analyticsHandler.GetDriverAnalytics)
 			analytics.GET("/collections", analyticsHandler.GetCollectionAnalytics)
+			analytics.GET("/heatmap", analyticsHandler.GetHeatmap)
+			analytics.GET("/zones", zoneHandler.GetZonesAnalytics)
+			analytics.GET("/impact", impactHandler.GetCityImpact)
+			analytics.GET("/sla", slaHandler.GetSLAReport)
+			analytics.GET("/export", exportHandler.ExportReport)
+			analytics.GET("/exports/:id", exportHandler.GetExport)
+			analytics.GET("/exports/:id/download", exportHandler.DownloadExport)
+		}
+
+		// Global search
+		api.GET("/search", handlers.RequireRoles(admin, dispatcher), searchHandler.Search)
+
+		// Admin routes
+		adminRoutes := api.Group("/admin")
+		adminRoutes.Use(handlers.RequireRoles(admin))
+		{
+			adminRoutes.GET("/api-keys", apiKeyHandler.ListAPIKeys)
+			adminRoutes.POST("/api-keys", apiKeyHandler.CreateAPIKey)
+			adminRoutes.GET("/api-keys/:id", apiKeyHandler.GetAPIKey)
+			adminRoutes.DELETE("/api-keys/:id", apiKeyHandler.RevokeAPIKey)
+		}
+
+		// Platform routes, for the admins of the default organization; dead
+		// letters hold device messages of every organization and the settings
+		// apply to the whole deployment
+		platformRoutes := api.Group("/admin")
+		platformRoutes.Use(handlers.RequireRoles(admin), platform)
+		{
+			platformRoutes.GET("/organizations", organizationHandler.ListOrganizations)
+			platformRoutes.POST("/organizations", organizationHandler.CreateOrganization)
+			platformRoutes.PUT("/organizations/:id", organizationHandler.UpdateOrganization)
+
+			platformRoutes.GET("/dead-letters", deadLetterHandler.ListDeadLetters)
+			platformRoutes.GET("/dead-letters/:id", deadLetterHandler.GetDeadLetter)
+			platformRoutes.POST("/dead-letters/:id/replay", deadLetterHandler.ReplayDeadLetter)
+			platformRoutes.DELETE("/dead-letters/:id", deadLetterHandler.DeleteDeadLetter)
+
+			platformRoutes.GET("/settings", settingsHandler.GetSettings)
+			platformRoutes.PUT("/settings", settingsHandler.UpdateSettings)
 		}
 	}
 
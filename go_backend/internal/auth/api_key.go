@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// APIKeyPrefix starts every issued API key so leaked keys are easy to recognize
+const APIKeyPrefix = "swk_"
+
+// apiKeyDisplayLength is how much of a key is kept readable for listings
+const apiKeyDisplayLength = len(APIKeyPrefix) + 8
+
+// GenerateAPIKey creates a random API key and returns it with its display
+// prefix and hash; only the prefix and hash are stored
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key = APIKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return key, key[:apiKeyDisplayLength], HashAPIKey(key), nil
+}
+
+// HashAPIKey returns the stored form of an API key. Keys carry 256 bits of
+// randomness, so an unsalted SHA-256 is sufficient and allows lookup by hash.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// ClaimsForAPIKey returns the principal an API key authenticates as
+func ClaimsForAPIKey(key *models.APIKey) *Claims {
+	return &Claims{
+		SubjectID:      key.ID,
+		OrganizationID: key.OrganizationID,
+		Role:           key.Role,
+		APIKeyID:       &key.ID,
+		CompanyID:      key.CompanyID,
+	}
+}
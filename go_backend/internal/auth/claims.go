@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// Claims represents the authenticated principal carried in an access token
+type Claims struct {
+	SubjectID uuid.UUID   `json:"sid"`
+	Email     string      `json:"email"`
+	Role      models.Role `json:"role"`
+	// OrganizationID is the tenant the principal belongs to
+	OrganizationID uuid.UUID `json:"oid"`
+	// APIKeyID is set when the principal authenticated with an API key
+	APIKeyID *uuid.UUID `json:"-"`
+	// CompanyID is set for company members and API keys issued for a company
+	CompanyID *uuid.UUID `json:"cid,omitempty"`
+	// CompanyRole is the member's role within CompanyID
+	CompanyRole models.CompanyMemberRole `json:"crole,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// MemberRole returns the role the principal acts with within its company;
+// API keys issued for a company act as managers
+func (c *Claims) MemberRole() models.CompanyMemberRole {
+	if c.CompanyRole == "" && c.APIKeyID != nil && c.CompanyID != nil {
+		return models.CompanyManager
+	}
+	return c.CompanyRole
+}
+
+// HasRole returns true if the principal holds any of the given roles
+func (c *Claims) HasRole(roles ...models.Role) bool {
+	for _, r := range roles {
+		if c.Role == r {
+			return true
+		}
+	}
+	return false
+}
+
+type contextKey struct{}
+
+type organizationKey struct{}
+
+// WithClaims returns a copy of ctx carrying the given claims
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, contextKey{}, claims)
+}
+
+// ClaimsFromContext returns the claims stored in ctx, if any
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(contextKey{}).(*Claims)
+	return claims, ok && claims != nil
+}
+
+// WithOrganization returns a copy of ctx confined to the given organization.
+// Repositories only read and write that organization's rows; background work
+// on behalf of a tenant's record uses it to stay within that tenant.
+func WithOrganization(ctx context.Context, organizationID uuid.UUID) context.Context {
+	return context.WithValue(ctx, organizationKey{}, organizationID)
+}
+
+// WithoutOrganization returns a copy of ctx spanning every organization, for
+// platform work on records that belong to no tenant
+func WithoutOrganization(ctx context.Context) context.Context {
+	return context.WithValue(ctx, organizationKey{}, uuid.Nil)
+}
+
+// OrganizationFromContext returns the organization ctx is confined to, if any
+func OrganizationFromContext(ctx context.Context) (uuid.UUID, bool) {
+	id, ok := ctx.Value(organizationKey{}).(uuid.UUID)
+	return id, ok && id != uuid.Nil
+}
@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+)
+
+// InviteTokenPrefix starts every company invite token
+const InviteTokenPrefix = "swi_"
+
+// GenerateInviteToken creates a random company invite token and returns it
+// with its hash; only the hash is stored
+func GenerateInviteToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate invite token: %w", err)
+	}
+	token = InviteTokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return token, HashInviteToken(token), nil
+}
+
+// HashInviteToken returns the stored form of an invite token; like API keys,
+// tokens carry 256 bits of randomness and are looked up by hash
+func HashInviteToken(token string) string {
+	return HashAPIKey(token)
+}
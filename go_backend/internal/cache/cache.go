@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/smartwaste/backend/internal/config"
+)
+
+// Keys of the cached read paths
+const (
+	KeyDashboardStats        = "analytics:dashboard"
+	KeyBinStatistics         = "bins:statistics"
+	KeyBinsNeedingCollection = "bins:needs-collection"
+)
+
+// BinKeys are the entries derived from bin fill levels, invalidated whenever a bin changes
+var BinKeys = []string{KeyDashboardStats, KeyBinStatistics, KeyBinsNeedingCollection}
+
+// OrganizationKey returns the key of an entry cached for one organization
+func OrganizationKey(organizationID uuid.UUID, key string) string {
+	return "org:" + organizationID.String() + ":" + key
+}
+
+// OrganizationKeys returns the keys of the given entries cached for one organization
+func OrganizationKeys(organizationID uuid.UUID, keys ...string) []string {
+	scoped := make([]string, len(keys))
+	for i, key := range keys {
+		scoped[i] = OrganizationKey(organizationID, key)
+	}
+	return scoped
+}
+
+// Cache stores JSON-encoded values by key
+type Cache interface {
+	// Get decodes the value stored at key into dest and reports whether it was found
+	Get(ctx context.Context, key string, dest interface{}) (bool, error)
+	Set(ctx context.Context, key string, value interface{}) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// New creates the cache selected by the configuration; a disabled cache never stores anything
+func New(cfg *config.CacheConfig) (Cache, error) {
+	if !cfg.Enabled {
+		return Noop{}, nil
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.RedisAddr,
+		Password: cfg.RedisPassword,
+		DB:       cfg.RedisDB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to redis at %s: %w", cfg.RedisAddr, err)
+	}
+
+	return &RedisCache{client: client, ttl: cfg.TTL}, nil
+}
+
+// RedisCache stores values in Redis with a fixed TTL
+type RedisCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// Get decodes the value stored at key into dest
+func (c *RedisCache) Get(ctx context.Context, key string, dest interface{}) (bool, error) {
+	data, err := c.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, json.Unmarshal(data, dest)
+}
+
+// Set stores value at key until the TTL expires
+func (c *RedisCache) Set(ctx context.Context, key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return c.client.Set(ctx, key, data, c.ttl).Err()
+}
+
+// Delete removes the given keys
+func (c *RedisCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return c.client.Del(ctx, keys...).Err()
+}
+
+// Noop is a cache that never stores anything
+type Noop struct{}
+
+// Get always reports a miss
+func (Noop) Get(ctx context.Context, key string, dest interface{}) (bool, error) { return false, nil }
+
+// Set discards the value
+func (Noop) Set(ctx context.Context, key string, value interface{}) error { return nil }
+
+// Delete does nothing
+func (Noop) Delete(ctx context.Context, keys ...string) error { return nil }
+
+// Fetch returns the cached value at key, or loads and caches it on a miss.
+// Cache failures are logged and fall through to load.
+func Fetch[T any](ctx context.Context, c Cache, key string, load func() (T, error)) (T, error) {
+	var value T
+	found, err := c.Get(ctx, key, &value)
+	if err != nil {
+		log.Printf("Cache read of %s failed: %v", key, err)
+	}
+	if found && err == nil {
+		return value, nil
+	}
+
+	value, err = load()
+	if err != nil {
+		return value, err
+	}
+	if err := c.Set(ctx, key, value); err != nil {
+		log.Printf("Cache write of %s failed: %v", key, err)
+	}
+	return value, nil
+}
@@ -2,35 +2,94 @@ package config
 
 import (
 	"log"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/smartwaste/pkg/postgres"
 	"github.com/spf13/viper"
 )
 
 // Config holds all configuration for the application
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	MQTT     MQTTConfig
-	Google   GoogleConfig
+	Server       ServerConfig
+	CORS         CORSConfig
+	Database     DatabaseConfig
+	MQTT         MQTTConfig
+	NATS         NATSConfig
+	Google       GoogleConfig
+	Security     SecurityConfig
+	Prediction   PredictionConfig
+	Devices      DeviceHealthConfig
+	Routing      RoutingConfig
+	Dispatch     DispatchConfig
+	Cache        CacheConfig
+	Export       ExportConfig
+	Drivers      DriverScoringConfig
+	Collections  CollectionConfig
+	SLA          SLAConfig
+	Stats        StatsConfig
+	Issues       IssueReportConfig
+	Storage      StorageConfig
+	Currency     ExchangeRateConfig
+	Sagas        SagaConfig
+	Earnings     EarningsConfig
+	Payments     PaymentsConfig
+	Channels     NotificationChannelConfig
+	Alerts       AlertConfig
+	Settings     SettingsConfig
+	Provisioning ProvisioningConfig
 }
 
 // ServerConfig holds server-related configuration
 type ServerConfig struct {
-	Port string
-	Mode string // debug, release, test
+	Port             string
+	GRPCPort         string // Empty disables the gRPC server
+	Mode             string // debug, release, test
+	Swagger          bool   // Serve the OpenAPI spec and Swagger UI under /swagger
+	ValidateRequests bool   // Reject /api/v1 requests that do not match the OpenAPI spec
+
+	// TrustedProxies are the proxies, as IPs or CIDRs, whose X-Forwarded-For
+	// is taken as the client IP; it is ignored from anyone else
+	TrustedProxies []string
+	// HSTSMaxAge is how long browsers only use HTTPS with the API, 0 not
+	// sending Strict-Transport-Security
+	HSTSMaxAge time.Duration
 }
 
-// DatabaseConfig holds database-related configuration
-type DatabaseConfig struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	DBName   string
-	SSLMode  string
+// CORSConfig holds the cross-origin policy of browsers calling the API
+type CORSConfig struct {
+	AllowedOrigins   []string      // Origins such as https://dashboard.example.com; "*" allows any
+	AllowedMethods   []string      // Methods a cross-origin request may use
+	AllowedHeaders   []string      // Request headers a cross-origin request may send
+	AllowCredentials bool          // Let browsers send cookies; ignored when any origin is allowed
+	MaxAge           time.Duration // How long browsers cache a preflight
+}
+
+// AllowsAnyOrigin returns true if the policy allows every origin
+func (c *CORSConfig) AllowsAnyOrigin() bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsOrigin returns true if browsers may call the API from origin
+func (c *CORSConfig) AllowsOrigin(origin string) bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
 }
 
+// DatabaseConfig holds database-related configuration, shared with the
+// other services
+type DatabaseConfig = postgres.Config
+
 // MQTTConfig holds MQTT broker configuration
 type MQTTConfig struct {
 	Broker   string
@@ -38,13 +97,168 @@ type MQTTConfig struct {
 	ClientID string
 	Username string
 	Password string
+
+	// TLS (mqtts) settings
+	TLSEnabled         bool
+	CACertFile         string // PEM bundle used to verify the broker; system roots if empty
+	ClientCertFile     string // PEM client certificate for mutual TLS
+	ClientKeyFile      string // PEM private key for ClientCertFile
+	TLSServerName      string // Overrides the name checked against the broker certificate
+	InsecureSkipVerify bool   // Disables broker certificate verification (development only)
+
+	// DedupWindow is how long message IDs are remembered to drop retransmissions
+	DedupWindow time.Duration
+
+	// Fill levels are buffered and written in batches of up to FillBatchSize
+	// bins every FillFlushInterval; a zero interval writes each update directly
+	FillBatchSize     int
+	FillFlushInterval time.Duration
+}
+
+// NATSConfig holds the NATS server and the JetStream consumer of shipment
+// events
+type NATSConfig struct {
+	URL        string
+	Stream     string        // JetStream stream of the shipment tracker's events
+	Consumer   string        // Durable consumer name, shared by every backend instance
+	AckWait    time.Duration // How long an event may be handled before it is redelivered
+	MaxDeliver int           // Deliveries of an event before it is given up on
+
+	// DedupRetention is how long handled event IDs are remembered to skip
+	// redeliveries
+	DedupRetention time.Duration
 }
 
 // GoogleConfig holds Google API configuration
 type GoogleConfig struct {
 	MapsAPIKey string
+
+	// DirectionsCacheTTL is how long Directions results are reused for the
+	// same points, 0 disabling the cache
+	DirectionsCacheTTL  time.Duration
+	DirectionsCacheSize int // Routes kept in the cache
+	// DirectionsDailyQuota caps the Directions requests per UTC day, after
+	// which routes are estimated from straight-line distances; 0 is unlimited
+	DirectionsDailyQuota int
+}
+
+// SecurityConfig holds credential-related configuration
+type SecurityConfig struct {
+	BcryptCost int
+	JWTSecret  string
+	JWTIssuer  string
+	TokenTTL   time.Duration
+	InviteTTL  time.Duration
+}
+
+// PredictionConfig holds fill-rate prediction configuration
+type PredictionConfig struct {
+	HistoryWindow time.Duration // How far back readings are used for the fit
+	MinReadings   int           // Readings required before predicting
+}
+
+// DeviceHealthConfig holds sensor health monitoring configuration
+type DeviceHealthConfig struct {
+	LowBatteryThreshold      int           // Battery level (%) below which a bin is flagged
+	FireTemperatureThreshold float64       // Temperature (°C) at or above which a bin raises a fire hazard
+	OfflineAfter             time.Duration // Silence after which a bin is flagged offline
+	OfflineCheckInterval     time.Duration // How often the offline detection job runs
+}
+
+// RoutingConfig holds route optimization configuration
+type RoutingConfig struct {
+	Provider               string        // auto, google, osrm or haversine
+	OSRMURL                string        // Base URL of the OSRM server
+	TruckCapacityLiters    int           // Default truck capacity for capacity-aware routes
+	ReoptimizeInterval     time.Duration // How often active routes are re-sequenced on live changes
+	ReoptimizeRadiusMeters int           // Distance from a remaining stop within which full bins are added
+}
+
+// SettingsConfig holds the runtime settings configuration
+type SettingsConfig struct {
+	ReloadInterval time.Duration // How often settings changed by another instance are picked up
+}
+
+// ProvisioningConfig holds the certificate authority issuing the client
+// certificates of bin sensors at their first boot
+type ProvisioningConfig struct {
+	CACertFile   string        // PEM certificate of the device CA; empty disables provisioning
+	CAKeyFile    string        // PEM private key of the device CA
+	CertValidity time.Duration // How long issued certificates are valid
+	BrokerURL    string        // mqtts:// URL of the mutual TLS listener handed to devices
+}
+
+// DispatchConfig holds automatic dispatch configuration
+type DispatchConfig struct {
+	Enabled      bool
+	Schedule     string // Cron expression for dispatch runs
+	MaxPerDriver int    // Collections assigned to one driver per run; 0 is unlimited
+}
+
+// CacheConfig holds the Redis read cache configuration
+type CacheConfig struct {
+	Enabled       bool
+	RedisAddr     string // host:port of the Redis server
+	RedisPassword string
+	RedisDB       int
+	TTL           time.Duration // How long cached statistics are served before reloading
+}
+
+// ExportConfig holds background report export configuration
+type ExportConfig struct {
+	Dir       string        // Directory the generated files are written to
+	Retention time.Duration // How long finished exports can be downloaded
+	Workers   int           // Exports generated concurrently
+	QueueSize int           // Exports waiting for a worker before new ones are refused
+}
+
+// DriverScoringConfig holds driver leaderboard configuration
+type DriverScoringConfig struct {
+	OnTimeWithin time.Duration // Time from assignment within which a completed collection is on time
+}
+
+// CollectionConfig holds collection verification configuration
+type CollectionConfig struct {
+	VerifyRadiusMeters int // Distance from its bin within which a collection can be verified, 0 not checking
+}
+
+// SLAConfig holds collection SLA monitoring configuration
+type SLAConfig struct {
+	CheckInterval time.Duration // How often SLA breaches are recorded and resolved
+}
+
+// StatsConfig holds the materialization of the daily stats trend analytics
+// read from
+type StatsConfig struct {
+	Schedule    string // Cron schedule of the nightly materialization
+	RefreshDays int    // Trailing days recomputed each run, for collections completed late
 }
 
+// IssueReportConfig holds citizen issue report configuration
+type IssueReportConfig struct {
+	FlagReporters int           // Distinct people with open reports on a bin before it is flagged
+	FlagWindow    time.Duration // How recent those reports must be
+}
+
+// StorageConfig holds the object storage configuration of file uploads
+type StorageConfig struct {
+	Provider     string // s3, minio or gcs; empty disables uploads
+	Endpoint     string // host[:port]; defaults to the provider's public endpoint
+	Region       string
+	Bucket       string
+	AccessKey    string // For gcs, the HMAC key of a service account
+	SecretKey    string
+	UseSSL       bool
+	PublicURL    string        // Base URL objects are served from; defaults to the bucket URL
+	MaxSize      int64         // Largest accepted upload in bytes
+	ContentTypes []string      // Accepted MIME types
+	URLExpiry    time.Duration // How long an upload URL stays valid
+	OrphanAfter  time.Duration // Age after which uploads nothing refers to are deleted
+}
+
+// insecureJWTSecret is the JWT_SECRET default, only accepted in debug mode
+const insecureJWTSecret = "change-me-in-production"
+
 var (
 	cfg  *Config
 	once sync.Once
@@ -60,25 +274,132 @@ func LoadConfig() *Config {
 
 		// Set defaults
 		viper.SetDefault("SERVER_PORT", "8080")
+		viper.SetDefault("GRPC_PORT", "9090")
 		viper.SetDefault("SERVER_MODE", "debug")
+		viper.SetDefault("SWAGGER_ENABLED", true)
+		viper.SetDefault("OPENAPI_VALIDATION_ENABLED", true)
+		viper.SetDefault("TRUSTED_PROXIES", "")
+		viper.SetDefault("HSTS_MAX_AGE", "8760h")
+		viper.SetDefault("CORS_ALLOWED_ORIGINS", "*")
+		viper.SetDefault("CORS_ALLOWED_METHODS", "GET,POST,PUT,PATCH,DELETE,OPTIONS")
+		viper.SetDefault("CORS_ALLOWED_HEADERS", "Origin,Content-Type,Accept,Authorization,X-API-Key,X-Request-ID")
+		viper.SetDefault("CORS_ALLOW_CREDENTIALS", false)
+		viper.SetDefault("CORS_MAX_AGE", "24h")
 		viper.SetDefault("DB_HOST", "postgres")
 		viper.SetDefault("DB_PORT", "5432")
 		viper.SetDefault("DB_USER", "postgres")
 		viper.SetDefault("DB_PASSWORD", "postgres")
 		viper.SetDefault("DB_NAME", "smartwaste")
 		viper.SetDefault("DB_SSLMODE", "disable")
+		viper.SetDefault("DB_MAX_OPEN_CONNS", postgres.DefaultMaxOpenConns)
+		viper.SetDefault("DB_MAX_IDLE_CONNS", postgres.DefaultMaxIdleConns)
+		viper.SetDefault("DB_CONN_MAX_LIFETIME", postgres.DefaultConnMaxLifetime)
+		viper.SetDefault("DB_CONN_MAX_IDLE_TIME", postgres.DefaultConnMaxIdleTime)
+		viper.SetDefault("DB_QUERY_TIMEOUT", postgres.DefaultQueryTimeout)
 		viper.SetDefault("MQTT_BROKER", "mosquitto")
 		viper.SetDefault("MQTT_PORT", "1883")
 		viper.SetDefault("MQTT_CLIENT_ID", "smartwaste-backend")
+		viper.SetDefault("MQTT_TLS_ENABLED", false)
+		viper.SetDefault("MQTT_TLS_INSECURE_SKIP_VERIFY", false)
+		viper.SetDefault("MQTT_DEDUP_WINDOW", "10m")
+		viper.SetDefault("MQTT_FILL_BATCH_SIZE", 500)
+		viper.SetDefault("MQTT_FILL_FLUSH_INTERVAL", "500ms")
+		viper.SetDefault("NATS_URL", "nats://nats:4222")
+		viper.SetDefault("NATS_STREAM", "SHIPMENTS")
+		viper.SetDefault("NATS_CONSUMER", "smartwaste-backend")
+		viper.SetDefault("NATS_ACK_WAIT", "30s")
+		viper.SetDefault("NATS_MAX_DELIVER", 5)
+		viper.SetDefault("NATS_DEDUP_RETENTION", "168h")
 		viper.SetDefault("GOOGLE_MAPS_API_KEY", "")
+		viper.SetDefault("GOOGLE_DIRECTIONS_CACHE_TTL", "24h")
+		viper.SetDefault("GOOGLE_DIRECTIONS_CACHE_SIZE", 10000)
+		viper.SetDefault("GOOGLE_DIRECTIONS_DAILY_QUOTA", 0)
+		viper.SetDefault("BCRYPT_COST", 12)
+		viper.SetDefault("JWT_SECRET", insecureJWTSecret)
+		viper.SetDefault("JWT_ISSUER", "smartwaste")
+		viper.SetDefault("JWT_TTL", "24h")
+		viper.SetDefault("COMPANY_INVITE_TTL", "168h")
+		viper.SetDefault("PREDICTION_HISTORY_WINDOW", "168h")
+		viper.SetDefault("PREDICTION_MIN_READINGS", 3)
+		viper.SetDefault("LOW_BATTERY_THRESHOLD", 20)
+		viper.SetDefault("FIRE_TEMPERATURE_THRESHOLD", 60.0)
+		viper.SetDefault("BIN_OFFLINE_AFTER", "2h")
+		viper.SetDefault("BIN_OFFLINE_CHECK_INTERVAL", "5m")
+		viper.SetDefault("ROUTING_PROVIDER", "auto")
+		viper.SetDefault("OSRM_URL", "https://router.project-osrm.org")
+		viper.SetDefault("ROUTE_TRUCK_CAPACITY_LITERS", 10000)
+		viper.SetDefault("ROUTE_REOPTIMIZE_INTERVAL", "1m")
+		viper.SetDefault("ROUTE_REOPTIMIZE_RADIUS_M", 800)
+		viper.SetDefault("AUTO_DISPATCH_ENABLED", false)
+		viper.SetDefault("AUTO_DISPATCH_SCHEDULE", "*/15 * * * *")
+		viper.SetDefault("AUTO_DISPATCH_MAX_PER_DRIVER", 10)
+		viper.SetDefault("SETTINGS_RELOAD_INTERVAL", "30s")
+		viper.SetDefault("PROVISIONING_CERT_VALIDITY", "8760h")
+		viper.SetDefault("CACHE_ENABLED", false)
+		viper.SetDefault("REDIS_ADDR", "redis:6379")
+		viper.SetDefault("REDIS_DB", 0)
+		viper.SetDefault("CACHE_TTL", "30s")
+		viper.SetDefault("EXPORT_DIR", "/tmp/smartwaste-exports")
+		viper.SetDefault("EXPORT_RETENTION", "168h")
+		viper.SetDefault("EXPORT_WORKERS", 2)
+		viper.SetDefault("EXPORT_QUEUE_SIZE", 100)
+		viper.SetDefault("LEADERBOARD_ON_TIME_WITHIN", "2h")
+		viper.SetDefault("COLLECTION_VERIFY_RADIUS_M", 100)
+		viper.SetDefault("SLA_CHECK_INTERVAL", "15m")
+		viper.SetDefault("STATS_SCHEDULE", "30 0 * * *")
+		viper.SetDefault("STATS_REFRESH_DAYS", 7)
+		viper.SetDefault("ISSUE_FLAG_REPORTERS", 3)
+		viper.SetDefault("ISSUE_FLAG_WINDOW", "24h")
+		viper.SetDefault("STORAGE_PROVIDER", "")
+		viper.SetDefault("STORAGE_REGION", "us-east-1")
+		viper.SetDefault("STORAGE_USE_SSL", true)
+		viper.SetDefault("UPLOAD_MAX_SIZE", 10<<20)
+		viper.SetDefault("UPLOAD_CONTENT_TYPES", "image/jpeg,image/png,image/webp")
+		viper.SetDefault("UPLOAD_URL_EXPIRY", "15m")
+		viper.SetDefault("UPLOAD_ORPHAN_AFTER", "24h")
+		viper.SetDefault("EXCHANGE_RATE_PROVIDER", "ecb")
+		viper.SetDefault("EXCHANGE_RATE_URL", "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml")
+		viper.SetDefault("EXCHANGE_RATE_BASE", "EUR")
+		viper.SetDefault("EXCHANGE_RATES", "")
+		viper.SetDefault("EXCHANGE_RATE_TTL", "24h")
+		viper.SetDefault("SHIPMENT_TRACKER_URL", "")
+		viper.SetDefault("SAGA_RETRY_INTERVAL", "30s")
+		viper.SetDefault("SAGA_MAX_BACKOFF", "30m")
+		viper.SetDefault("SAGA_MAX_ATTEMPTS", 8)
+		viper.SetDefault("EARNINGS_COLLECTION_RATE", 2.5)
+		viper.SetDefault("EARNINGS_SHIPMENT_RATE", 5)
+		viper.SetDefault("EARNINGS_PER_KG_RATE", 0.1)
+		viper.SetDefault("EARNINGS_CURRENCY", "USD")
+		viper.SetDefault("PAYMENTS_PROVIDER", "stub")
+		viper.SetDefault("PAYMENTS_CURRENCY", "USD")
+		viper.SetDefault("STRIPE_URL", "https://api.stripe.com")
+		viper.SetDefault("NOTIFICATION_CHANNELS", "push,sms")
+		viper.SetDefault("FCM_URL", "https://fcm.googleapis.com/fcm/send")
+		viper.SetDefault("TWILIO_URL", "https://api.twilio.com")
+		viper.SetDefault("SMTP_PORT", 587)
+		viper.SetDefault("ALERT_DEDUP_WINDOW", "1h")
+		viper.SetDefault("ALERT_ESCALATE_AFTER", "30m")
+		viper.SetDefault("ALERT_ESCALATION_CHECK_INTERVAL", "1m")
 
 		// Read from environment variables
 		viper.AutomaticEnv()
 
 		cfg = &Config{
 			Server: ServerConfig{
-				Port: viper.GetString("SERVER_PORT"),
-				Mode: viper.GetString("SERVER_MODE"),
+				Port:             viper.GetString("SERVER_PORT"),
+				GRPCPort:         viper.GetString("GRPC_PORT"),
+				Mode:             viper.GetString("SERVER_MODE"),
+				Swagger:          viper.GetBool("SWAGGER_ENABLED"),
+				ValidateRequests: viper.GetBool("OPENAPI_VALIDATION_ENABLED"),
+				TrustedProxies:   splitList(viper.GetString("TRUSTED_PROXIES")),
+				HSTSMaxAge:       viper.GetDuration("HSTS_MAX_AGE"),
+			},
+			CORS: CORSConfig{
+				AllowedOrigins:   splitList(viper.GetString("CORS_ALLOWED_ORIGINS")),
+				AllowedMethods:   splitList(viper.GetString("CORS_ALLOWED_METHODS")),
+				AllowedHeaders:   splitList(viper.GetString("CORS_ALLOWED_HEADERS")),
+				AllowCredentials: viper.GetBool("CORS_ALLOW_CREDENTIALS"),
+				MaxAge:           viper.GetDuration("CORS_MAX_AGE"),
 			},
 			Database: DatabaseConfig{
 				Host:     viper.GetString("DB_HOST"),
@@ -87,6 +408,12 @@ func LoadConfig() *Config {
 				Password: viper.GetString("DB_PASSWORD"),
 				DBName:   viper.GetString("DB_NAME"),
 				SSLMode:  viper.GetString("DB_SSLMODE"),
+
+				MaxOpenConns:    viper.GetInt("DB_MAX_OPEN_CONNS"),
+				MaxIdleConns:    viper.GetInt("DB_MAX_IDLE_CONNS"),
+				ConnMaxLifetime: viper.GetDuration("DB_CONN_MAX_LIFETIME"),
+				ConnMaxIdleTime: viper.GetDuration("DB_CONN_MAX_IDLE_TIME"),
+				QueryTimeout:    viper.GetDuration("DB_QUERY_TIMEOUT"),
 			},
 			MQTT: MQTTConfig{
 				Broker:   viper.GetString("MQTT_BROKER"),
@@ -94,12 +421,173 @@ func LoadConfig() *Config {
 				ClientID: viper.GetString("MQTT_CLIENT_ID"),
 				Username: viper.GetString("MQTT_USERNAME"),
 				Password: viper.GetString("MQTT_PASSWORD"),
+
+				TLSEnabled:         viper.GetBool("MQTT_TLS_ENABLED"),
+				CACertFile:         viper.GetString("MQTT_CA_CERT"),
+				ClientCertFile:     viper.GetString("MQTT_CLIENT_CERT"),
+				ClientKeyFile:      viper.GetString("MQTT_CLIENT_KEY"),
+				TLSServerName:      viper.GetString("MQTT_TLS_SERVER_NAME"),
+				InsecureSkipVerify: viper.GetBool("MQTT_TLS_INSECURE_SKIP_VERIFY"),
+
+				DedupWindow: viper.GetDuration("MQTT_DEDUP_WINDOW"),
+
+				FillBatchSize:     viper.GetInt("MQTT_FILL_BATCH_SIZE"),
+				FillFlushInterval: viper.GetDuration("MQTT_FILL_FLUSH_INTERVAL"),
+			},
+			NATS: NATSConfig{
+				URL:        viper.GetString("NATS_URL"),
+				Stream:     viper.GetString("NATS_STREAM"),
+				Consumer:   viper.GetString("NATS_CONSUMER"),
+				AckWait:    viper.GetDuration("NATS_ACK_WAIT"),
+				MaxDeliver: viper.GetInt("NATS_MAX_DELIVER"),
+
+				DedupRetention: viper.GetDuration("NATS_DEDUP_RETENTION"),
 			},
 			Google: GoogleConfig{
-				MapsAPIKey: viper.GetString("GOOGLE_MAPS_API_KEY"),
+				MapsAPIKey:           viper.GetString("GOOGLE_MAPS_API_KEY"),
+				DirectionsCacheTTL:   viper.GetDuration("GOOGLE_DIRECTIONS_CACHE_TTL"),
+				DirectionsCacheSize:  viper.GetInt("GOOGLE_DIRECTIONS_CACHE_SIZE"),
+				DirectionsDailyQuota: viper.GetInt("GOOGLE_DIRECTIONS_DAILY_QUOTA"),
+			},
+			Security: SecurityConfig{
+				BcryptCost: viper.GetInt("BCRYPT_COST"),
+				JWTSecret:  viper.GetString("JWT_SECRET"),
+				JWTIssuer:  viper.GetString("JWT_ISSUER"),
+				TokenTTL:   viper.GetDuration("JWT_TTL"),
+				InviteTTL:  viper.GetDuration("COMPANY_INVITE_TTL"),
+			},
+			Prediction: PredictionConfig{
+				HistoryWindow: viper.GetDuration("PREDICTION_HISTORY_WINDOW"),
+				MinReadings:   viper.GetInt("PREDICTION_MIN_READINGS"),
+			},
+			Devices: DeviceHealthConfig{
+				LowBatteryThreshold:      viper.GetInt("LOW_BATTERY_THRESHOLD"),
+				FireTemperatureThreshold: viper.GetFloat64("FIRE_TEMPERATURE_THRESHOLD"),
+				OfflineAfter:             viper.GetDuration("BIN_OFFLINE_AFTER"),
+				OfflineCheckInterval:     viper.GetDuration("BIN_OFFLINE_CHECK_INTERVAL"),
+			},
+			Routing: RoutingConfig{
+				Provider:               viper.GetString("ROUTING_PROVIDER"),
+				OSRMURL:                viper.GetString("OSRM_URL"),
+				TruckCapacityLiters:    viper.GetInt("ROUTE_TRUCK_CAPACITY_LITERS"),
+				ReoptimizeInterval:     viper.GetDuration("ROUTE_REOPTIMIZE_INTERVAL"),
+				ReoptimizeRadiusMeters: viper.GetInt("ROUTE_REOPTIMIZE_RADIUS_M"),
+			},
+			Dispatch: DispatchConfig{
+				Enabled:      viper.GetBool("AUTO_DISPATCH_ENABLED"),
+				Schedule:     viper.GetString("AUTO_DISPATCH_SCHEDULE"),
+				MaxPerDriver: viper.GetInt("AUTO_DISPATCH_MAX_PER_DRIVER"),
+			},
+			Cache: CacheConfig{
+				Enabled:       viper.GetBool("CACHE_ENABLED"),
+				RedisAddr:     viper.GetString("REDIS_ADDR"),
+				RedisPassword: viper.GetString("REDIS_PASSWORD"),
+				RedisDB:       viper.GetInt("REDIS_DB"),
+				TTL:           viper.GetDuration("CACHE_TTL"),
+			},
+			Export: ExportConfig{
+				Dir:       viper.GetString("EXPORT_DIR"),
+				Retention: viper.GetDuration("EXPORT_RETENTION"),
+				Workers:   viper.GetInt("EXPORT_WORKERS"),
+				QueueSize: viper.GetInt("EXPORT_QUEUE_SIZE"),
+			},
+			Drivers: DriverScoringConfig{
+				OnTimeWithin: viper.GetDuration("LEADERBOARD_ON_TIME_WITHIN"),
+			},
+			Collections: CollectionConfig{
+				VerifyRadiusMeters: viper.GetInt("COLLECTION_VERIFY_RADIUS_M"),
+			},
+			SLA: SLAConfig{
+				CheckInterval: viper.GetDuration("SLA_CHECK_INTERVAL"),
+			},
+			Stats: StatsConfig{
+				Schedule:    viper.GetString("STATS_SCHEDULE"),
+				RefreshDays: viper.GetInt("STATS_REFRESH_DAYS"),
+			},
+			Issues: IssueReportConfig{
+				FlagReporters: viper.GetInt("ISSUE_FLAG_REPORTERS"),
+				FlagWindow:    viper.GetDuration("ISSUE_FLAG_WINDOW"),
+			},
+			Storage: StorageConfig{
+				Provider:     viper.GetString("STORAGE_PROVIDER"),
+				Endpoint:     viper.GetString("STORAGE_ENDPOINT"),
+				Region:       viper.GetString("STORAGE_REGION"),
+				Bucket:       viper.GetString("STORAGE_BUCKET"),
+				AccessKey:    viper.GetString("STORAGE_ACCESS_KEY"),
+				SecretKey:    viper.GetString("STORAGE_SECRET_KEY"),
+				UseSSL:       viper.GetBool("STORAGE_USE_SSL"),
+				PublicURL:    viper.GetString("STORAGE_PUBLIC_URL"),
+				MaxSize:      viper.GetInt64("UPLOAD_MAX_SIZE"),
+				ContentTypes: splitList(viper.GetString("UPLOAD_CONTENT_TYPES")),
+				URLExpiry:    viper.GetDuration("UPLOAD_URL_EXPIRY"),
+				OrphanAfter:  viper.GetDuration("UPLOAD_ORPHAN_AFTER"),
+			},
+			Currency: ExchangeRateConfig{
+				Provider: viper.GetString("EXCHANGE_RATE_PROVIDER"),
+				URL:      viper.GetString("EXCHANGE_RATE_URL"),
+				Base:     viper.GetString("EXCHANGE_RATE_BASE"),
+				Rates:    viper.GetString("EXCHANGE_RATES"),
+				TTL:      viper.GetDuration("EXCHANGE_RATE_TTL"),
+			},
+			Sagas: SagaConfig{
+				TrackerURL:    viper.GetString("SHIPMENT_TRACKER_URL"),
+				RetryInterval: viper.GetDuration("SAGA_RETRY_INTERVAL"),
+				MaxBackoff:    viper.GetDuration("SAGA_MAX_BACKOFF"),
+				MaxAttempts:   viper.GetInt("SAGA_MAX_ATTEMPTS"),
+			},
+			Earnings: EarningsConfig{
+				CollectionRate: viper.GetFloat64("EARNINGS_COLLECTION_RATE"),
+				ShipmentRate:   viper.GetFloat64("EARNINGS_SHIPMENT_RATE"),
+				PerKgRate:      viper.GetFloat64("EARNINGS_PER_KG_RATE"),
+				Currency:       strings.ToUpper(viper.GetString("EARNINGS_CURRENCY")),
+			},
+			Payments: PaymentsConfig{
+				Provider:            viper.GetString("PAYMENTS_PROVIDER"),
+				Currency:            strings.ToUpper(viper.GetString("PAYMENTS_CURRENCY")),
+				StripeURL:           viper.GetString("STRIPE_URL"),
+				StripeSecretKey:     viper.GetString("STRIPE_SECRET_KEY"),
+				StripeWebhookSecret: viper.GetString("STRIPE_WEBHOOK_SECRET"),
+			},
+			Channels: NotificationChannelConfig{
+				Default:          splitList(viper.GetString("NOTIFICATION_CHANNELS")),
+				FCMURL:           viper.GetString("FCM_URL"),
+				FCMServerKey:     viper.GetString("FCM_SERVER_KEY"),
+				TwilioURL:        viper.GetString("TWILIO_URL"),
+				TwilioAccountSID: viper.GetString("TWILIO_ACCOUNT_SID"),
+				TwilioAuthToken:  viper.GetString("TWILIO_AUTH_TOKEN"),
+				TwilioFrom:       viper.GetString("TWILIO_FROM_NUMBER"),
+				SMTPHost:         viper.GetString("SMTP_HOST"),
+				SMTPPort:         viper.GetInt("SMTP_PORT"),
+				SMTPUsername:     viper.GetString("SMTP_USERNAME"),
+				SMTPPassword:     viper.GetString("SMTP_PASSWORD"),
+				SMTPFrom:         viper.GetString("SMTP_FROM"),
+			},
+			Alerts: AlertConfig{
+				DedupWindow:   viper.GetDuration("ALERT_DEDUP_WINDOW"),
+				EscalateAfter: viper.GetDuration("ALERT_ESCALATE_AFTER"),
+				CheckInterval: viper.GetDuration("ALERT_ESCALATION_CHECK_INTERVAL"),
+			},
+			Settings: SettingsConfig{
+				ReloadInterval: viper.GetDuration("SETTINGS_RELOAD_INTERVAL"),
+			},
+			Provisioning: ProvisioningConfig{
+				CACertFile:   viper.GetString("PROVISIONING_CA_CERT"),
+				CAKeyFile:    viper.GetString("PROVISIONING_CA_KEY"),
+				CertValidity: viper.GetDuration("PROVISIONING_CERT_VALIDITY"),
+				BrokerURL:    viper.GetString("PROVISIONING_BROKER_URL"),
 			},
 		}
 
+		if cfg.Security.JWTSecret == insecureJWTSecret {
+			if cfg.Server.Mode != "debug" {
+				log.Fatalf("JWT_SECRET must be set in %s mode", cfg.Server.Mode)
+			}
+			log.Println("Warning: JWT_SECRET is using the insecure default value")
+		}
+		if cfg.Server.Mode == "release" && cfg.CORS.AllowsAnyOrigin() {
+			log.Println("Warning: CORS_ALLOWED_ORIGINS allows any origin")
+		}
+
 		log.Printf("Configuration loaded: Server Port=%s, DB Host=%s, MQTT Broker=%s",
 			cfg.Server.Port, cfg.Database.Host, cfg.MQTT.Broker)
 	})
@@ -115,12 +603,75 @@ func GetConfig() *Config {
 	return cfg
 }
 
-// GetDSN returns the PostgreSQL connection string
-func (c *DatabaseConfig) GetDSN() string {
-	return "host=" + c.Host +
-		" port=" + c.Port +
-		" user=" + c.User +
-		" password=" + c.Password +
-		" dbname=" + c.DBName +
-		" sslmode=" + c.SSLMode
+// ExchangeRateConfig holds the exchange rate source of currency conversions
+type ExchangeRateConfig struct {
+	Provider string        // ecb, static or none
+	URL      string        // Daily reference rates fetched by the ecb provider
+	Base     string        // Currency the static rates are quoted against
+	Rates    string        // Static rates, e.g. "USD=1.08,GBP=0.86"
+	TTL      time.Duration // How long fetched rates are reused
+}
+
+// SagaConfig holds the shipment tracker that completed collections are handed
+// over to and the retries of the sagas doing it
+type SagaConfig struct {
+	TrackerURL    string        // REST API of the shipment tracker; empty disables the sagas
+	RetryInterval time.Duration // Delay before a failed step is retried, doubling with each failure
+	MaxBackoff    time.Duration // Longest delay between retries
+	MaxAttempts   int           // Attempts to create a shipment before the collection is reopened
+}
+
+// EarningsConfig holds the rates drivers are paid at
+type EarningsConfig struct {
+	CollectionRate float64 // Paid per completed collection or pickup
+	ShipmentRate   float64 // Paid per delivered shipment
+	PerKgRate      float64 // Paid per kg collected or delivered, on top of the rate
+	Currency       string  // Currency of the rates and payouts
+}
+
+// PaymentsConfig holds the provider companies are charged and drivers and
+// users paid out with
+type PaymentsConfig struct {
+	Provider            string // stub or stripe
+	Currency            string // Currency of payments that do not name one
+	StripeURL           string
+	StripeSecretKey     string
+	StripeWebhookSecret string // Signing secret of the webhook endpoint
+}
+
+// NotificationChannelConfig holds the channels notifications are delivered
+// over. A channel is enabled once configured, except push which logs the
+// messages it would send until an FCM server key is set.
+type NotificationChannelConfig struct {
+	Default          []string // Channels tried in order for recipients without preferences
+	FCMURL           string
+	FCMServerKey     string
+	TwilioURL        string
+	TwilioAccountSID string
+	TwilioAuthToken  string
+	TwilioFrom       string // Number SMS are sent from
+	SMTPHost         string
+	SMTPPort         int
+	SMTPUsername     string
+	SMTPPassword     string
+	SMTPFrom         string // Address emails are sent from
+}
+
+// AlertConfig holds the deduplication and escalation of the bin alerts sent
+// to the nearest driver
+type AlertConfig struct {
+	DedupWindow   time.Duration // A bin does not raise the same alert again within it
+	EscalateAfter time.Duration // An unacknowledged alert escalates after it
+	CheckInterval time.Duration // How often alerts due for escalation are looked for
+}
+
+// splitList parses a comma-separated setting, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
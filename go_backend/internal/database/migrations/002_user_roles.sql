@@ -0,0 +1,9 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 002_user_roles.sql
+
+-- Access role for user accounts: admin, dispatcher, company or citizen.
+-- Drivers authenticate from the drivers table and always carry the driver role.
+ALTER TABLE users ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'citizen'
+    CHECK (role IN ('admin', 'dispatcher', 'company', 'citizen'));
+
+CREATE INDEX idx_users_role ON users(role);
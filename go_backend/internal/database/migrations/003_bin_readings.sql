@@ -0,0 +1,15 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 003_bin_readings.sql
+
+-- Bin readings table: fill-level history reported by the sensors
+CREATE TABLE bin_readings (
+    id BIGSERIAL PRIMARY KEY,
+    bin_id UUID NOT NULL REFERENCES bins(id) ON DELETE CASCADE,
+    fill_level INTEGER NOT NULL CHECK (fill_level >= 0 AND fill_level <= 100),
+    recorded_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_bin_readings_bin_time ON bin_readings(bin_id, recorded_at DESC);
+
+-- Estimated time at which the bin reaches the collection threshold
+ALTER TABLE bins ADD COLUMN predicted_full_at TIMESTAMP WITH TIME ZONE;
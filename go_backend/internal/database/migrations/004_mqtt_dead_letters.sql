@@ -0,0 +1,15 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 004_mqtt_dead_letters.sql
+
+-- Dead letters table: MQTT messages that could not be processed
+CREATE TABLE mqtt_dead_letters (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    topic VARCHAR(255) NOT NULL,
+    payload TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    replay_count INTEGER DEFAULT 0,
+    received_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    replayed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_mqtt_dead_letters_pending ON mqtt_dead_letters(received_at DESC) WHERE replayed_at IS NULL;
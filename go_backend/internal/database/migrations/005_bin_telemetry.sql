@@ -0,0 +1,10 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 005_bin_telemetry.sql
+
+-- Sensor health telemetry reported alongside the fill level
+ALTER TABLE bins ADD COLUMN battery_level INTEGER CHECK (battery_level >= 0 AND battery_level <= 100);
+ALTER TABLE bins ADD COLUMN rssi INTEGER;
+ALTER TABLE bins ADD COLUMN temperature_c DECIMAL(5, 2);
+ALTER TABLE bins ADD COLUMN firmware_version VARCHAR(50);
+
+CREATE INDEX idx_bins_battery_level ON bins(battery_level) WHERE is_active = true;
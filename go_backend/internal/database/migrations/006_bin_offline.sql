@@ -0,0 +1,9 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 006_bin_offline.sql
+
+-- Bins whose sensors stopped reporting are flagged by the offline detection job
+ALTER TABLE bins ADD COLUMN is_offline BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE bins ADD COLUMN offline_since TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_bins_last_updated_at ON bins(last_updated_at) WHERE is_active = true AND is_offline = false;
+CREATE INDEX idx_bins_offline ON bins(offline_since) WHERE is_offline = true;
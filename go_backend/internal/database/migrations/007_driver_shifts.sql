@@ -0,0 +1,45 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 007_driver_shifts.sql
+
+-- Driver shifts table: weekly recurring and one-off working hours.
+-- Recurring shifts set day_of_week/start_time/end_time (in the shift's time zone;
+-- end_time <= start_time spans midnight). One-off shifts set starts_at/ends_at.
+CREATE TABLE driver_shifts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    driver_id UUID NOT NULL REFERENCES drivers(id) ON DELETE CASCADE,
+    day_of_week SMALLINT CHECK (day_of_week >= 0 AND day_of_week <= 6),
+    start_time TIME,
+    end_time TIME,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    starts_at TIMESTAMP WITH TIME ZONE,
+    ends_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (
+        (day_of_week IS NOT NULL AND start_time IS NOT NULL AND end_time IS NOT NULL AND starts_at IS NULL AND ends_at IS NULL)
+        OR (day_of_week IS NULL AND start_time IS NULL AND end_time IS NULL AND starts_at IS NOT NULL AND ends_at > starts_at)
+    )
+);
+
+CREATE INDEX idx_driver_shifts_driver ON driver_shifts(driver_id);
+
+-- driver_on_shift reports whether a driver is working at the given time.
+-- Drivers without any shift are unrestricted.
+CREATE OR REPLACE FUNCTION driver_on_shift(p_driver_id UUID, p_at TIMESTAMP WITH TIME ZONE)
+RETURNS BOOLEAN AS $$
+    SELECT NOT EXISTS (SELECT 1 FROM driver_shifts WHERE driver_id = p_driver_id)
+        OR EXISTS (
+            SELECT 1
+            FROM driver_shifts s,
+                LATERAL (SELECT p_at AT TIME ZONE s.timezone AS local_at) l
+            WHERE s.driver_id = p_driver_id
+                AND (
+                    (s.day_of_week IS NULL AND p_at >= s.starts_at AND p_at < s.ends_at)
+                    OR (s.start_time < s.end_time
+                        AND EXTRACT(DOW FROM l.local_at) = s.day_of_week
+                        AND l.local_at::time >= s.start_time AND l.local_at::time < s.end_time)
+                    OR (s.start_time >= s.end_time
+                        AND ((EXTRACT(DOW FROM l.local_at) = s.day_of_week AND l.local_at::time >= s.start_time)
+                            OR (EXTRACT(DOW FROM l.local_at) = (s.day_of_week + 1) % 7 AND l.local_at::time < s.end_time)))
+                )
+        );
+$$ LANGUAGE SQL STABLE;
@@ -0,0 +1,28 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 008_vehicles.sql
+
+-- Vehicles table: collection trucks and their capacity
+CREATE TABLE vehicles (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    plate_number VARCHAR(50) UNIQUE NOT NULL,
+    make VARCHAR(100),
+    model VARCHAR(100),
+    capacity_liters INTEGER NOT NULL CHECK (capacity_liters > 0),
+    capacity_kg DECIMAL(10, 2) CHECK (capacity_kg > 0),
+    fuel_type VARCHAR(20) NOT NULL DEFAULT 'diesel'
+        CHECK (fuel_type IN ('diesel', 'petrol', 'electric', 'hybrid', 'cng')),
+    maintenance_status VARCHAR(20) NOT NULL DEFAULT 'operational'
+        CHECK (maintenance_status IN ('operational', 'maintenance_due', 'in_maintenance', 'out_of_service')),
+    last_maintenance_at TIMESTAMP WITH TIME ZONE,
+    next_maintenance_at TIMESTAMP WITH TIME ZONE,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_vehicles_updated_at BEFORE UPDATE ON vehicles
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- A vehicle is assigned to at most one driver
+ALTER TABLE drivers ADD COLUMN vehicle_id UUID REFERENCES vehicles(id) ON DELETE SET NULL;
+CREATE UNIQUE INDEX idx_drivers_vehicle_id ON drivers(vehicle_id) WHERE vehicle_id IS NOT NULL;
@@ -0,0 +1,42 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 009_rewards.sql
+
+-- The citizen credited with reward points for a collection
+ALTER TABLE collections ADD COLUMN user_id UUID REFERENCES users(id) ON DELETE SET NULL;
+CREATE INDEX idx_collections_user_id ON collections(user_id);
+
+-- Reward earning rules: points = base_points + points_per_kg * weight.
+-- waste_type '*' applies to waste types without a rule of their own.
+CREATE TABLE reward_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    waste_type VARCHAR(50) NOT NULL,
+    base_points INTEGER NOT NULL DEFAULT 0 CHECK (base_points >= 0),
+    points_per_kg DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (points_per_kg >= 0),
+    min_weight_kg DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    max_points INTEGER CHECK (max_points > 0),
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_reward_rules_waste_type ON reward_rules(waste_type) WHERE is_active = true;
+
+CREATE TRIGGER update_reward_rules_updated_at BEFORE UPDATE ON reward_rules
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Reward ledger: one row per automatic credit. The unique source makes
+-- crediting idempotent when a completion or NATS event is processed twice.
+CREATE TABLE reward_transactions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    points INTEGER NOT NULL,
+    source VARCHAR(20) NOT NULL CHECK (source IN ('collection', 'shipment')),
+    source_id UUID NOT NULL,
+    reward_rule_id UUID REFERENCES reward_rules(id) ON DELETE SET NULL,
+    waste_type VARCHAR(50) NOT NULL,
+    weight_kg DECIMAL(10, 2),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (source, source_id)
+);
+
+CREATE INDEX idx_reward_transactions_user ON reward_transactions(user_id, created_at DESC);
@@ -0,0 +1,10 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 010_bin_thresholds.sql
+
+-- Per-bin fill levels (%): collection_threshold puts a bin on routes and
+-- automatic dispatch, alert_threshold notifies the nearest driver
+ALTER TABLE bins
+    ADD COLUMN collection_threshold INTEGER NOT NULL DEFAULT 80
+        CHECK (collection_threshold BETWEEN 1 AND 100),
+    ADD COLUMN alert_threshold INTEGER NOT NULL DEFAULT 90
+        CHECK (alert_threshold BETWEEN 1 AND 100);
@@ -0,0 +1,11 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 011_list_pagination.sql
+
+-- Keyset pagination: list endpoints order by a sort column then id, and a
+-- cursor resumes with a row comparison on the same pair
+CREATE INDEX idx_bins_created_at_id ON bins(created_at DESC, id DESC) WHERE is_active = true;
+CREATE INDEX idx_collections_started_at_id ON collections(started_at DESC, id DESC);
+CREATE INDEX idx_users_created_at_id ON users(created_at DESC, id DESC);
+CREATE INDEX idx_drivers_created_at_id ON drivers(created_at DESC, id DESC);
+CREATE INDEX idx_notifications_driver_sent_at_id ON notifications(driver_id, sent_at DESC, id DESC);
+CREATE INDEX idx_mqtt_dead_letters_received_at_id ON mqtt_dead_letters(received_at DESC, id DESC);
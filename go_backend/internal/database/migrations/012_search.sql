@@ -0,0 +1,16 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 012_search.sql
+
+-- Global search: trigram indexes serve both the similarity (%) operator and
+-- substring ILIKE matches on the searched columns
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_bins_device_id_trgm ON bins USING GIN (device_id gin_trgm_ops);
+CREATE INDEX idx_bins_location_name_trgm ON bins USING GIN (location_name gin_trgm_ops);
+CREATE INDEX idx_drivers_full_name_trgm ON drivers USING GIN (full_name gin_trgm_ops);
+CREATE INDEX idx_drivers_email_trgm ON drivers USING GIN (email gin_trgm_ops);
+CREATE INDEX idx_drivers_phone_trgm ON drivers USING GIN (phone gin_trgm_ops);
+CREATE INDEX idx_users_full_name_trgm ON users USING GIN (full_name gin_trgm_ops);
+CREATE INDEX idx_users_email_trgm ON users USING GIN (email gin_trgm_ops);
+CREATE INDEX idx_companies_name_trgm ON companies USING GIN (name gin_trgm_ops);
+CREATE INDEX idx_companies_email_trgm ON companies USING GIN (email gin_trgm_ops);
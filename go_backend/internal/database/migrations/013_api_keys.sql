@@ -0,0 +1,23 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 013_api_keys.sql
+
+-- API keys let services and company integrations call the API without a
+-- user login. Only the SHA-256 hash of a key is stored; key_prefix is the
+-- readable start of the key shown in listings. company_id optionally ties
+-- an integration key to the company it was issued for.
+CREATE TABLE api_keys (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    key_prefix VARCHAR(16) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('dispatcher', 'company', 'citizen')),
+    company_id UUID REFERENCES companies(id) ON DELETE CASCADE,
+    rate_limit_per_minute INTEGER NOT NULL DEFAULT 60 CHECK (rate_limit_per_minute > 0),
+    created_by UUID,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_api_keys_created_at ON api_keys(created_at DESC, id DESC);
@@ -0,0 +1,7 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 014_device_api_keys.sql
+
+-- Device keys authenticate bins that post their readings over HTTP instead of MQTT
+ALTER TABLE api_keys DROP CONSTRAINT api_keys_role_check;
+ALTER TABLE api_keys ADD CONSTRAINT api_keys_role_check
+    CHECK (role IN ('dispatcher', 'company', 'citizen', 'device'));
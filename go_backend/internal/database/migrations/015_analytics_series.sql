@@ -0,0 +1,5 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 015_analytics_series.sql
+
+-- Analytics series aggregate the readings of all bins over a time range
+CREATE INDEX idx_bin_readings_recorded_at ON bin_readings(recorded_at);
@@ -0,0 +1,28 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 016_emission_factors.sql
+
+-- CO2-equivalent emissions avoided per kg of collected waste, compared with
+-- sending it to landfill. waste_type '*' applies to waste types without a
+-- factor of their own.
+CREATE TABLE emission_factors (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    waste_type VARCHAR(50) NOT NULL,
+    kg_co2e_per_kg DECIMAL(10, 4) NOT NULL CHECK (kg_co2e_per_kg >= 0),
+    source VARCHAR(255),
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_emission_factors_waste_type ON emission_factors(waste_type) WHERE is_active = true;
+
+CREATE TRIGGER update_emission_factors_updated_at BEFORE UPDATE ON emission_factors
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Indicative defaults for the waste types the classifier detects; replace
+-- them with the factors of the reporting methodology in use
+INSERT INTO emission_factors (waste_type, kg_co2e_per_kg, source) VALUES
+    ('plastic', 1.5000, 'Indicative default'),
+    ('metal', 4.0000, 'Indicative default'),
+    ('glass', 0.3000, 'Indicative default'),
+    ('organic', 0.5000, 'Indicative default');
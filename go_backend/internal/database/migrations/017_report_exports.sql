@@ -0,0 +1,24 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 017_report_exports.sql
+
+-- Reports generated in the background. The file lives on the disk of the
+-- instance that generated it until expires_at.
+CREATE TABLE report_exports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    report VARCHAR(20) NOT NULL CHECK (report IN ('collections', 'bins', 'drivers')),
+    format VARCHAR(10) NOT NULL CHECK (format IN ('csv', 'pdf')),
+    period_from TIMESTAMP WITH TIME ZONE NOT NULL,
+    period_to TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+    row_count INTEGER,
+    size_bytes BIGINT,
+    error TEXT,
+    file_path TEXT,
+    requested_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_report_exports_expires_at ON report_exports(expires_at) WHERE expires_at IS NOT NULL;
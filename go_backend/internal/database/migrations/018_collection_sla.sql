@@ -0,0 +1,67 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 018_collection_sla.sql
+
+-- When a bin last reached its collection threshold; maintained by trigger and
+-- cleared once the bin drops back below it
+ALTER TABLE bins ADD COLUMN threshold_crossed_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE bins SET threshold_crossed_at = last_updated_at WHERE fill_level >= collection_threshold;
+
+CREATE OR REPLACE FUNCTION track_bin_threshold_crossing()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.fill_level >= NEW.collection_threshold THEN
+        NEW.threshold_crossed_at = COALESCE(NEW.threshold_crossed_at, CURRENT_TIMESTAMP);
+    ELSE
+        NEW.threshold_crossed_at = NULL;
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER track_bins_threshold_crossing BEFORE INSERT OR UPDATE OF fill_level, collection_threshold ON bins
+    FOR EACH ROW EXECUTE FUNCTION track_bin_threshold_crossing();
+
+CREATE INDEX idx_bins_threshold_crossed_at ON bins(threshold_crossed_at) WHERE is_active = true AND threshold_crossed_at IS NOT NULL;
+
+-- Hours a bin may stay at or above its collection threshold before it must be
+-- emptied. A NULL company or waste type matches every bin; the most specific
+-- active rule applies, company before waste type.
+CREATE TABLE sla_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    company_id UUID REFERENCES companies(id) ON DELETE CASCADE,
+    waste_type VARCHAR(50),
+    max_hours INTEGER NOT NULL CHECK (max_hours > 0),
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_sla_rules_scope ON sla_rules(
+    COALESCE(company_id, '00000000-0000-0000-0000-000000000000'::uuid),
+    COALESCE(waste_type, '')
+) WHERE is_active = true;
+
+CREATE TRIGGER update_sla_rules_updated_at BEFORE UPDATE ON sla_rules
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO sla_rules (max_hours) VALUES (24);
+
+-- Bins not emptied within their SLA, one per threshold crossing. The rule's
+-- limit is copied so later rule changes do not rewrite past breaches.
+CREATE TABLE sla_breaches (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    bin_id UUID NOT NULL REFERENCES bins(id) ON DELETE CASCADE,
+    rule_id UUID REFERENCES sla_rules(id) ON DELETE SET NULL,
+    company_id UUID REFERENCES companies(id) ON DELETE SET NULL,
+    max_hours INTEGER NOT NULL,
+    threshold_crossed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    due_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    detected_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE (bin_id, threshold_crossed_at)
+);
+
+CREATE INDEX idx_sla_breaches_due_at ON sla_breaches(due_at);
+CREATE INDEX idx_sla_breaches_company_id ON sla_breaches(company_id, due_at);
+CREATE INDEX idx_sla_breaches_open ON sla_breaches(bin_id) WHERE resolved_at IS NULL;
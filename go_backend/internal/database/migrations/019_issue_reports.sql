@@ -0,0 +1,36 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 019_issue_reports.sql
+
+-- Problems with a bin reported by citizens and staff, triaged by dispatchers
+CREATE TABLE issue_reports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    bin_id UUID NOT NULL REFERENCES bins(id) ON DELETE CASCADE,
+    reported_by UUID NOT NULL,
+    reporter_role VARCHAR(20) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('overflowing', 'damaged', 'missing', 'other')),
+    description TEXT,
+    photo_url TEXT,
+    latitude DECIMAL(10, 8),
+    longitude DECIMAL(11, 8),
+    status VARCHAR(20) NOT NULL DEFAULT 'open'
+        CHECK (status IN ('open', 'acknowledged', 'in_progress', 'resolved', 'rejected')),
+    triage_note TEXT,
+    triaged_by UUID,
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_issue_reports_bin_id ON issue_reports(bin_id, created_at DESC);
+CREATE INDEX idx_issue_reports_status ON issue_reports(status, created_at DESC);
+CREATE INDEX idx_issue_reports_reported_by ON issue_reports(reported_by, created_at DESC);
+
+CREATE TRIGGER update_issue_reports_updated_at BEFORE UPDATE ON issue_reports
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Bins flagged for inspection after several people reported them; cleared
+-- once their last open report is closed
+ALTER TABLE bins ADD COLUMN is_flagged BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE bins ADD COLUMN flagged_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_bins_flagged ON bins(flagged_at) WHERE is_flagged = true;
@@ -0,0 +1,20 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 020_uploads.sql
+
+-- Files uploaded to object storage through pre-signed URLs. An upload is
+-- attached once a record refers to its URL; uploads left unattached are
+-- deleted, object included, by the upload cleanup job.
+CREATE TABLE uploads (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    object_key VARCHAR(255) NOT NULL UNIQUE,
+    url TEXT NOT NULL UNIQUE,
+    purpose VARCHAR(30) NOT NULL CHECK (purpose IN ('issue_report', 'waste_image', 'dispute_evidence')),
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL CHECK (size_bytes > 0),
+    uploaded_by UUID NOT NULL,
+    uploader_role VARCHAR(20) NOT NULL,
+    attached_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_uploads_unattached ON uploads(created_at) WHERE attached_at IS NULL;
@@ -0,0 +1,50 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 021_pricing_strategies.sql
+
+-- Pricing model of each rule. Tiered rules price weight brackets in turn and
+-- the weight above the last bracket at price_per_kg; market rules pay
+-- market_factor times the latest market price, falling back to price_per_kg.
+-- Condition multipliers scale the price of the matching conditions, which
+-- lets one rule with condition 'any' cover every condition of a waste type.
+ALTER TABLE pricing_rules
+    ADD COLUMN pricing_model VARCHAR(20) NOT NULL DEFAULT 'flat'
+        CHECK (pricing_model IN ('flat', 'tiered', 'market')),
+    ADD COLUMN tiers JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN condition_multipliers JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN market_factor DECIMAL(6, 3) NOT NULL DEFAULT 1 CHECK (market_factor > 0);
+
+CREATE INDEX idx_pricing_rules_lookup ON pricing_rules(waste_type, condition) WHERE is_active = true;
+
+-- Time-limited rates multiplying valuations of a company's rules, a waste
+-- type, or both; the highest running promotion applies
+CREATE TABLE pricing_promotions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    company_id UUID REFERENCES companies(id) ON DELETE CASCADE,
+    waste_type VARCHAR(50),
+    multiplier DECIMAL(6, 3) NOT NULL CHECK (multiplier > 0),
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX idx_pricing_promotions_window ON pricing_promotions(starts_at, ends_at) WHERE is_active = true;
+
+CREATE TRIGGER update_pricing_promotions_updated_at BEFORE UPDATE ON pricing_promotions
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Market prices of recovered materials; the latest price in effect applies
+CREATE TABLE market_prices (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    waste_type VARCHAR(50) NOT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+    price_per_kg DECIMAL(10, 4) NOT NULL CHECK (price_per_kg >= 0),
+    source VARCHAR(100),
+    effective_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_market_prices_latest ON market_prices(waste_type, currency, effective_at DESC);
@@ -0,0 +1,22 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 022_pricing_rule_versions.sql
+
+-- Pricing rules are versioned: changing a rule closes the current version at
+-- the new version's effective_from instead of updating it, so valuations can
+-- be reproduced with the rule in effect at any time. All versions of a rule
+-- share the lineage_id of the first one.
+ALTER TABLE pricing_rules
+    ADD COLUMN lineage_id UUID,
+    ADD COLUMN version INTEGER NOT NULL DEFAULT 1 CHECK (version > 0),
+    ADD COLUMN effective_from TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN effective_to TIMESTAMP WITH TIME ZONE;
+
+UPDATE pricing_rules SET lineage_id = id, effective_from = created_at;
+
+ALTER TABLE pricing_rules
+    ALTER COLUMN lineage_id SET NOT NULL,
+    ADD CONSTRAINT pricing_rules_lineage_version UNIQUE (lineage_id, version),
+    ADD CONSTRAINT pricing_rules_effective_range CHECK (effective_to IS NULL OR effective_to > effective_from);
+
+DROP INDEX IF EXISTS idx_pricing_rules_lookup;
+CREATE INDEX idx_pricing_rules_lookup ON pricing_rules(waste_type, condition, effective_from) WHERE is_active = true;
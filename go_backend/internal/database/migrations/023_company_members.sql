@@ -0,0 +1,38 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 023_company_members.sql
+
+-- Company members are user accounts with the company role that log in to the
+-- company portal; a user belongs to at most one company. Owners manage the
+-- members, managers also change pricing, viewers only read.
+CREATE TABLE company_members (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'manager', 'viewer')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_company_members_company ON company_members(company_id, role);
+
+CREATE TRIGGER update_company_members_updated_at BEFORE UPDATE ON company_members
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Invites create a member account when accepted. Only the SHA-256 hash of the
+-- invite token is stored; accepted and revoked invites are kept for auditing.
+CREATE TABLE company_invites (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'manager', 'viewer')),
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    invited_by UUID,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    accepted_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One pending invite per company and email
+CREATE UNIQUE INDEX idx_company_invites_pending ON company_invites(company_id, lower(email))
+    WHERE accepted_at IS NULL AND revoked_at IS NULL;
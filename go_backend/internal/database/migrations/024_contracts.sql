@@ -0,0 +1,32 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 024_contracts.sql
+
+-- Agreements between a company and a municipality. A company is only
+-- dispatched to its bins inside the service area of a contract in effect that
+-- covers their waste type, and only values waste its contracts cover. The
+-- rate card fixes the price per kg of some of the covered waste types, taking
+-- precedence over the company's own pricing rules.
+CREATE TABLE contracts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    municipality VARCHAR(100) NOT NULL,
+    waste_types JSONB NOT NULL DEFAULT '[]',
+    min_latitude DECIMAL(10, 8) NOT NULL,
+    min_longitude DECIMAL(11, 8) NOT NULL,
+    max_latitude DECIMAL(10, 8) NOT NULL,
+    max_longitude DECIMAL(11, 8) NOT NULL,
+    rate_card JSONB NOT NULL DEFAULT '[]',
+    valid_from TIMESTAMP WITH TIME ZONE NOT NULL,
+    valid_to TIMESTAMP WITH TIME ZONE,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (min_latitude < max_latitude AND min_longitude < max_longitude),
+    CHECK (valid_to IS NULL OR valid_to > valid_from)
+);
+
+CREATE INDEX idx_contracts_company ON contracts(company_id, valid_from) WHERE is_active = true;
+
+CREATE TRIGGER update_contracts_updated_at BEFORE UPDATE ON contracts
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
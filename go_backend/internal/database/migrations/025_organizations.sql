@@ -0,0 +1,53 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 025_organizations.sql
+
+-- Organizations are the tenants of a deployment, typically one per city. Users,
+-- drivers, bins, collections and API keys belong to exactly one; requests only
+-- see the rows of the organization of their access token. Companies, pricing
+-- and the other reference data stay shared by every organization.
+CREATE TABLE organizations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    slug VARCHAR(50) NOT NULL UNIQUE,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_organizations_updated_at BEFORE UPDATE ON organizations
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Existing data moves to the default organization, whose admins also manage
+-- the other organizations
+INSERT INTO organizations (id, name, slug)
+VALUES ('00000000-0000-0000-0000-000000000001', 'Default', 'default');
+
+-- The defaults only backfill existing rows; new rows must name their organization
+ALTER TABLE users ADD COLUMN organization_id UUID NOT NULL
+    DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations(id);
+ALTER TABLE drivers ADD COLUMN organization_id UUID NOT NULL
+    DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations(id);
+ALTER TABLE bins ADD COLUMN organization_id UUID NOT NULL
+    DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations(id);
+ALTER TABLE collections ADD COLUMN organization_id UUID NOT NULL
+    DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations(id);
+ALTER TABLE api_keys ADD COLUMN organization_id UUID NOT NULL
+    DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations(id);
+ALTER TABLE company_invites ADD COLUMN organization_id UUID NOT NULL
+    DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations(id);
+ALTER TABLE report_exports ADD COLUMN organization_id UUID NOT NULL
+    DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations(id);
+
+ALTER TABLE users ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE drivers ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE bins ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE collections ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE api_keys ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE company_invites ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE report_exports ALTER COLUMN organization_id DROP DEFAULT;
+
+CREATE INDEX idx_users_organization ON users(organization_id, created_at);
+CREATE INDEX idx_drivers_organization ON drivers(organization_id, created_at);
+CREATE INDEX idx_bins_organization ON bins(organization_id, created_at) WHERE is_active = true;
+CREATE INDEX idx_collections_organization ON collections(organization_id, started_at);
+CREATE INDEX idx_api_keys_organization ON api_keys(organization_id, created_at);
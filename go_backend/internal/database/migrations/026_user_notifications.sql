@@ -0,0 +1,10 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 026_user_notifications.sql
+
+-- Notifications were only sent to drivers; users are now notified too, e.g.
+-- of the points a completed shipment earned them. A notification has either a
+-- driver or a user.
+ALTER TABLE notifications ADD COLUMN user_id UUID REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_notifications_user_sent_at_id ON notifications(user_id, sent_at DESC, id DESC)
+    WHERE user_id IS NOT NULL;
@@ -0,0 +1,8 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 027_reward_penalties.sql
+
+-- Late shipment cancellations take points back from the user, recorded in
+-- the reward ledger with negative points. A cancellation is only debited once.
+ALTER TABLE reward_transactions DROP CONSTRAINT reward_transactions_source_check;
+ALTER TABLE reward_transactions ADD CONSTRAINT reward_transactions_source_check
+    CHECK (source IN ('collection', 'shipment', 'cancellation'));
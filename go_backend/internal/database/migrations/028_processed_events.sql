@@ -0,0 +1,16 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 028_processed_events.sql
+
+-- Shipment events the backend handled, by the event_id the shipment tracker
+-- gives them, so an event JetStream redelivers is not handled twice. An event
+-- is claimed while it is handled and processed once it was; a claim older
+-- than the ack wait, of an instance that stopped, may be taken over.
+CREATE TABLE processed_events (
+    event_id UUID PRIMARY KEY,
+    subject VARCHAR(255) NOT NULL,
+    claimed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    processed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_processed_events_processed_at ON processed_events(processed_at)
+    WHERE processed_at IS NOT NULL;
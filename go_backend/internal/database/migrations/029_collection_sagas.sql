@@ -0,0 +1,28 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 029_collection_sagas.sql
+
+-- A completed collection of a user hands its waste over as a shipment in the
+-- shipment tracker. The saga is recorded with the completion and driven to
+-- its end by the backend: the shipment is created, retrying while the tracker
+-- fails, and the collection is reopened if it cannot be or the shipment is
+-- cancelled.
+CREATE TABLE collection_sagas (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    collection_id UUID NOT NULL UNIQUE REFERENCES collections(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    waste_type VARCHAR(50) NOT NULL,
+    weight_kg DECIMAL(10, 2) NOT NULL CHECK (weight_kg > 0),
+    price_offered DECIMAL(10, 2) NOT NULL CHECK (price_offered > 0),
+    shipment_id UUID UNIQUE,
+    state VARCHAR(20) NOT NULL DEFAULT 'creating_shipment'
+        CHECK (state IN ('creating_shipment', 'shipment_created', 'compensating', 'compensated')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_collection_sagas_due ON collection_sagas(next_attempt_at)
+    WHERE state IN ('creating_shipment', 'compensating');
@@ -0,0 +1,12 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 030_settings.sql
+
+-- Runtime settings of the deployment that admins change without a redeploy,
+-- one row per section (thresholds, dispatch, notifications). A section
+-- without a row keeps the value of the environment configuration.
+CREATE TABLE settings (
+    key VARCHAR(50) PRIMARY KEY,
+    value JSONB NOT NULL,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
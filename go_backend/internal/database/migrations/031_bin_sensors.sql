@@ -0,0 +1,9 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 031_bin_sensors.sql
+
+-- Readings of the optional load cell and lid switch of a bin. With a
+-- max_weight_kg, the weight also estimates the fill level of bins whose
+-- waste is heavy before it is tall.
+ALTER TABLE bins ADD COLUMN weight_kg DECIMAL(7, 2) CHECK (weight_kg >= 0);
+ALTER TABLE bins ADD COLUMN max_weight_kg DECIMAL(7, 2) CHECK (max_weight_kg > 0);
+ALTER TABLE bins ADD COLUMN lid_open BOOLEAN;
@@ -0,0 +1,9 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 032_device_config.sql
+
+-- Last configuration a bin sensor acknowledged after it was pushed on
+-- bins/{device_id}/config: its version, whether the sensor applied or
+-- rejected it, and when
+ALTER TABLE bins ADD COLUMN config_version BIGINT;
+ALTER TABLE bins ADD COLUMN config_status VARCHAR(20) CHECK (config_status IN ('applied', 'rejected'));
+ALTER TABLE bins ADD COLUMN config_acked_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,40 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 033_firmware_rollouts.sql
+
+-- Bins are grouped into device groups, such as a hardware revision or a
+-- pilot district, to roll out firmware to part of the fleet at a time
+ALTER TABLE bins ADD COLUMN device_group VARCHAR(100);
+
+CREATE INDEX idx_bins_device_group ON bins(organization_id, device_group) WHERE is_active = true;
+
+-- Firmware versions offered to the sensors of a device group, or of the whole
+-- organization without one. The sensors download the binary from url and
+-- check it against sha256 and signature before installing it.
+CREATE TABLE firmware_rollouts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    device_group VARCHAR(100),
+    version VARCHAR(50) NOT NULL,
+    url TEXT NOT NULL,
+    sha256 CHAR(64) NOT NULL,
+    signature TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'cancelled')),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    cancelled_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_firmware_rollouts_org ON firmware_rollouts(organization_id, created_at DESC);
+
+-- Progress of a rollout on each bin it targets, as reported by its sensor
+CREATE TABLE firmware_updates (
+    rollout_id UUID NOT NULL REFERENCES firmware_rollouts(id) ON DELETE CASCADE,
+    bin_id UUID NOT NULL REFERENCES bins(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'downloading', 'installed', 'updated', 'failed', 'cancelled')),
+    error TEXT,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (rollout_id, bin_id)
+);
+
+CREATE INDEX idx_firmware_updates_bin ON firmware_updates(bin_id);
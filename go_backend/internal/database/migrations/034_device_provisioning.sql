@@ -0,0 +1,12 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 034_device_provisioning.sql
+
+-- Sensors provision themselves at their first boot with the hardware serial
+-- registered on their bin, and are issued a client certificate for the
+-- broker's mutual TLS listener
+ALTER TABLE bins ADD COLUMN hardware_serial VARCHAR(100);
+ALTER TABLE bins ADD COLUMN provisioned_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE bins ADD COLUMN certificate_serial VARCHAR(40);
+ALTER TABLE bins ADD COLUMN certificate_expires_at TIMESTAMP WITH TIME ZONE;
+
+CREATE UNIQUE INDEX idx_bins_hardware_serial ON bins(organization_id, hardware_serial) WHERE hardware_serial IS NOT NULL;
@@ -0,0 +1,68 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 035_devices.sql
+
+-- Sensor units tracked apart from the bins they are installed on, so a unit
+-- keeps its history when it is swapped, repaired or retired
+CREATE TABLE devices (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    hardware_serial VARCHAR(100) NOT NULL,
+    model VARCHAR(100),
+    -- Last firmware reported while the device was installed on a bin
+    firmware_version VARCHAR(50),
+    connectivity VARCHAR(20) NOT NULL DEFAULT 'wifi'
+        CHECK (connectivity IN ('wifi', 'cellular', 'lorawan', 'ethernet')),
+    sim_iccid VARCHAR(22),
+    carrier VARCHAR(100),
+    apn VARCHAR(100),
+    status VARCHAR(20) NOT NULL DEFAULT 'in_stock'
+        CHECK (status IN ('in_stock', 'active', 'decommissioned')),
+    bin_id UUID REFERENCES bins(id) ON DELETE SET NULL,
+    notes TEXT,
+    decommissioned_at TIMESTAMP WITH TIME ZONE,
+    decommission_reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (organization_id, hardware_serial)
+);
+
+CREATE TRIGGER update_devices_updated_at BEFORE UPDATE ON devices
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- A bin carries at most one device
+CREATE UNIQUE INDEX idx_devices_bin_id ON devices(bin_id) WHERE bin_id IS NOT NULL;
+CREATE INDEX idx_devices_org_status ON devices(organization_id, status);
+
+-- Which device served which bin, and when; open while unassigned_at is NULL
+CREATE TABLE device_assignments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    device_id UUID NOT NULL REFERENCES devices(id) ON DELETE CASCADE,
+    bin_id UUID NOT NULL REFERENCES bins(id) ON DELETE CASCADE,
+    assigned_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    unassigned_at TIMESTAMP WITH TIME ZONE,
+    assigned_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reason TEXT
+);
+
+CREATE INDEX idx_device_assignments_device ON device_assignments(device_id, assigned_at DESC);
+CREATE INDEX idx_device_assignments_bin ON device_assignments(bin_id, assigned_at DESC);
+
+-- Devices with the state their bin reports: the firmware and signal of an
+-- installed device come from its bin's telemetry, and it is provisioned once
+-- it was issued a certificate with its own serial
+CREATE VIEW device_details AS
+SELECT
+    d.id, d.organization_id, d.hardware_serial, d.model,
+    COALESCE(b.firmware_version, d.firmware_version) AS firmware_version,
+    d.connectivity, d.sim_iccid, d.carrier, d.apn, d.status, d.bin_id, d.notes,
+    d.decommissioned_at, d.decommission_reason, d.created_at, d.updated_at,
+    b.device_id AS bin_device_id,
+    CASE
+        WHEN b.id IS NULL THEN 'unassigned'
+        WHEN b.provisioned_at IS NOT NULL AND b.hardware_serial = d.hardware_serial THEN 'provisioned'
+        ELSE 'pending'
+    END AS provisioning_status,
+    b.provisioned_at, b.certificate_expires_at, b.rssi,
+    b.last_updated_at AS last_reading_at
+FROM devices d
+LEFT JOIN bins b ON b.id = d.bin_id;
@@ -0,0 +1,53 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 036_bin_maintenance.sql
+
+-- Lifecycle of a bin beyond is_active: a bin under maintenance stays active
+-- but is left out of dispatch and collection planning. is_active follows the
+-- status, and setting is_active directly retires or reactivates the bin.
+ALTER TABLE bins ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'active'
+    CHECK (status IN ('active', 'maintenance', 'retired'));
+
+UPDATE bins SET status = 'retired' WHERE is_active = false;
+
+CREATE OR REPLACE FUNCTION sync_bin_status()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.status IS DISTINCT FROM OLD.status THEN
+        NEW.is_active := NEW.status <> 'retired';
+    ELSIF NEW.is_active IS DISTINCT FROM OLD.is_active THEN
+        NEW.status := CASE WHEN NEW.is_active THEN 'active' ELSE 'retired' END;
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER sync_bins_status BEFORE UPDATE OF status, is_active ON bins
+    FOR EACH ROW EXECUTE FUNCTION sync_bin_status();
+
+CREATE INDEX idx_bins_org_status ON bins(organization_id, status);
+
+-- Maintenance of a bin, scheduled ahead or started at once. The bin is under
+-- maintenance while one of its records is in progress.
+CREATE TABLE bin_maintenance (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    bin_id UUID NOT NULL REFERENCES bins(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('damaged', 'cleaning', 'sensor_fault')),
+    status VARCHAR(20) NOT NULL DEFAULT 'scheduled'
+        CHECK (status IN ('scheduled', 'in_progress', 'completed', 'cancelled')),
+    scheduled_for TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    description TEXT,
+    resolution TEXT,
+    scheduled_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    completed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_bin_maintenance_bin_id ON bin_maintenance(bin_id, scheduled_for DESC);
+CREATE INDEX idx_bin_maintenance_open ON bin_maintenance(scheduled_for)
+    WHERE status IN ('scheduled', 'in_progress');
+
+CREATE TRIGGER update_bin_maintenance_updated_at BEFORE UPDATE ON bin_maintenance
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
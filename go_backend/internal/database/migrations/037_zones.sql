@@ -0,0 +1,69 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 037_zones.sql
+
+-- Neighborhoods and districts of an organization. boundary holds the ring of
+-- [longitude, latitude] points as sent by clients; area is the same ring as a
+-- native polygon, for containment tests.
+CREATE TABLE zones (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(20) NOT NULL DEFAULT 'neighborhood' CHECK (kind IN ('neighborhood', 'district')),
+    description TEXT,
+    boundary JSONB NOT NULL,
+    area POLYGON NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (organization_id, name)
+);
+
+CREATE TRIGGER update_zones_updated_at BEFORE UPDATE ON zones
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- bin_zone returns the zone of the organization containing the location.
+-- Where zones overlap, the oldest one wins.
+CREATE OR REPLACE FUNCTION bin_zone(p_organization_id UUID, p_latitude DECIMAL, p_longitude DECIMAL)
+RETURNS UUID AS $$
+    SELECT id FROM zones
+    WHERE organization_id = p_organization_id AND area @> point(p_longitude, p_latitude)
+    ORDER BY created_at, id
+    LIMIT 1;
+$$ LANGUAGE SQL STABLE;
+
+-- Bins are assigned to the zone containing them; zone changes reassign the
+-- bins of their organization
+ALTER TABLE bins ADD COLUMN zone_id UUID REFERENCES zones(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_bins_zone_id ON bins(zone_id) WHERE zone_id IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION assign_bin_zone()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.zone_id := bin_zone(NEW.organization_id, NEW.latitude, NEW.longitude);
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER assign_bins_zone BEFORE INSERT OR UPDATE OF latitude, longitude, organization_id ON bins
+    FOR EACH ROW EXECUTE FUNCTION assign_bin_zone();
+
+-- Zones a driver covers; drivers without any are dispatched anywhere. A zone
+-- still covered by drivers cannot be deleted, so they are not left
+-- unrestricted by accident.
+CREATE TABLE driver_zones (
+    driver_id UUID NOT NULL REFERENCES drivers(id) ON DELETE CASCADE,
+    zone_id UUID NOT NULL REFERENCES zones(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (driver_id, zone_id)
+);
+
+CREATE INDEX idx_driver_zones_zone ON driver_zones(zone_id);
+
+-- Zones with the number of active bins they contain, without the native
+-- polygon
+CREATE VIEW zone_details AS
+SELECT
+    z.id, z.organization_id, z.name, z.kind, z.description, z.boundary,
+    z.created_at, z.updated_at,
+    (SELECT COUNT(*) FROM bins b WHERE b.zone_id = z.id AND b.is_active = true) AS bin_count
+FROM zones z;
@@ -0,0 +1,6 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 038_driver_routes.sql
+
+-- Planned routes are kept so drivers can export them to their navigation
+-- app; planning again replaces the pending route of the driver
+CREATE INDEX idx_driver_routes_driver ON driver_routes(driver_id, status);
@@ -0,0 +1,12 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 039_route_reoptimization.sql
+
+-- Routes remember how they were optimized, so the stops left can be
+-- re-sequenced the same way when bins are emptied by someone else or fill up
+-- nearby
+ALTER TABLE driver_routes ADD COLUMN optimize_by VARCHAR(20) NOT NULL DEFAULT 'distance'
+    CHECK (optimize_by IN ('distance', 'fill_level', 'two_opt', 'capacity'));
+ALTER TABLE driver_routes ADD COLUMN truck_capacity_liters INTEGER CHECK (truck_capacity_liters > 0);
+ALTER TABLE driver_routes ADD COLUMN reoptimized_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_driver_routes_active ON driver_routes(status) WHERE status IN ('pending', 'in_progress');
@@ -0,0 +1,11 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 040_collection_photos.sql
+
+-- Proof-of-collection photos of the bin before and after it was emptied,
+-- uploaded by the driver through the upload service
+ALTER TABLE collections ADD COLUMN before_photo_url TEXT;
+ALTER TABLE collections ADD COLUMN after_photo_url TEXT;
+
+ALTER TABLE uploads DROP CONSTRAINT uploads_purpose_check;
+ALTER TABLE uploads ADD CONSTRAINT uploads_purpose_check
+    CHECK (purpose IN ('issue_report', 'waste_image', 'dispute_evidence', 'collection_photo'));
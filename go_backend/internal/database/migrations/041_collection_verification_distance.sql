@@ -0,0 +1,6 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 041_collection_verification_distance.sql
+
+-- Distance in meters between the driver and the bin when they scanned its QR
+-- code, recorded when the location of the driver was known
+ALTER TABLE collections ADD COLUMN verification_distance_m DOUBLE PRECISION CHECK (verification_distance_m >= 0);
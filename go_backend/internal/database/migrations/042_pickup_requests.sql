@@ -0,0 +1,48 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 042_pickup_requests.sql
+
+-- On-demand pickups of bulky or recyclable waste requested by citizens. A
+-- request is valued when filed, dispatched to a driver like a full bin and,
+-- once collected, handed over to the shipment tracker as a shipment of its
+-- user, retried until the tracker takes it or gives up.
+CREATE TABLE pickup_requests (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    waste_type VARCHAR(50) NOT NULL,
+    estimated_weight_kg DECIMAL(10, 2) NOT NULL CHECK (estimated_weight_kg > 0),
+    weight_kg DECIMAL(10, 2) CHECK (weight_kg > 0),
+    photo_urls JSONB NOT NULL DEFAULT '[]',
+    latitude DECIMAL(10, 8) NOT NULL,
+    longitude DECIMAL(11, 8) NOT NULL,
+    address TEXT,
+    notes TEXT,
+    zone_id UUID REFERENCES zones(id) ON DELETE SET NULL,
+    estimated_value DECIMAL(10, 2),
+    price_offered DECIMAL(10, 2),
+    currency VARCHAR(3),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'assigned', 'collected', 'cancelled')),
+    driver_id UUID REFERENCES drivers(id) ON DELETE SET NULL,
+    shipment_id UUID UNIQUE,
+    shipment_attempts INTEGER NOT NULL DEFAULT 0,
+    shipment_error TEXT,
+    assigned_at TIMESTAMP WITH TIME ZONE,
+    collected_at TIMESTAMP WITH TIME ZONE,
+    cancelled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_pickup_requests_org_status ON pickup_requests(organization_id, status, created_at);
+CREATE INDEX idx_pickup_requests_user ON pickup_requests(user_id, created_at DESC);
+CREATE INDEX idx_pickup_requests_driver ON pickup_requests(driver_id, status);
+CREATE INDEX idx_pickup_requests_unshipped ON pickup_requests(collected_at)
+    WHERE status = 'collected' AND shipment_id IS NULL;
+
+CREATE TRIGGER update_pickup_requests_updated_at BEFORE UPDATE ON pickup_requests
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Pickups are dispatched within zones like the bins there
+CREATE TRIGGER assign_pickup_requests_zone BEFORE INSERT OR UPDATE OF latitude, longitude, organization_id ON pickup_requests
+    FOR EACH ROW EXECUTE FUNCTION assign_bin_zone();
@@ -0,0 +1,56 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 043_driver_earnings.sql
+
+-- Payout batches settle the unpaid earnings of an organization's drivers up
+-- to the end of a period, one line per driver
+CREATE TABLE payout_batches (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL CHECK (period_end > period_start),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'paid')),
+    total_amount DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    currency VARCHAR(3) NOT NULL,
+    driver_count INTEGER NOT NULL DEFAULT 0,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    paid_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_payout_batches_org ON payout_batches(organization_id, created_at DESC);
+
+CREATE TRIGGER update_payout_batches_updated_at BEFORE UPDATE ON payout_batches
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE payout_lines (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    batch_id UUID NOT NULL REFERENCES payout_batches(id) ON DELETE CASCADE,
+    driver_id UUID NOT NULL REFERENCES drivers(id) ON DELETE CASCADE,
+    amount DECIMAL(12, 2) NOT NULL,
+    earnings_count INTEGER NOT NULL,
+    UNIQUE (batch_id, driver_id)
+);
+
+-- Ledger of what drivers earned: their completed collections and pickups,
+-- the shipments they delivered and the bonuses granted to them. Each source
+-- is only credited once; an entry is paid once its batch is.
+CREATE TABLE driver_earnings (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    driver_id UUID NOT NULL REFERENCES drivers(id) ON DELETE CASCADE,
+    source VARCHAR(20) NOT NULL CHECK (source IN ('collection', 'pickup', 'shipment', 'bonus')),
+    source_id UUID NOT NULL,
+    amount DECIMAL(12, 2) NOT NULL CHECK (amount > 0),
+    currency VARCHAR(3) NOT NULL,
+    weight_kg DECIMAL(10, 2),
+    description TEXT,
+    payout_batch_id UUID REFERENCES payout_batches(id) ON DELETE SET NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    earned_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (source, source_id)
+);
+
+CREATE INDEX idx_driver_earnings_driver ON driver_earnings(driver_id, earned_at);
+CREATE INDEX idx_driver_earnings_unpaid ON driver_earnings(organization_id, earned_at)
+    WHERE payout_batch_id IS NULL;
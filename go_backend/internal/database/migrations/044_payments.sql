@@ -0,0 +1,52 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 044_payments.sql
+
+-- Accounts companies are charged from and drivers and users paid out to
+-- with the payment provider: a customer and its saved payment method, or a
+-- connected account receiving transfers
+CREATE TABLE payment_accounts (
+    owner_type VARCHAR(20) NOT NULL CHECK (owner_type IN ('company', 'driver', 'user')),
+    owner_id UUID NOT NULL,
+    provider VARCHAR(20) NOT NULL,
+    account_id VARCHAR(255) NOT NULL,
+    payment_method VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (owner_type, owner_id)
+);
+
+CREATE TRIGGER update_payment_accounts_updated_at BEFORE UPDATE ON payment_accounts
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Charges of companies for collection services and payouts to drivers and
+-- users. A payment is recorded before the provider is called, so its ID keys
+-- the provider request, and settled by the provider's webhooks when the
+-- outcome is not known right away.
+CREATE TABLE payments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('charge', 'payout')),
+    company_id UUID REFERENCES companies(id) ON DELETE SET NULL,
+    driver_id UUID REFERENCES drivers(id) ON DELETE SET NULL,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    amount DECIMAL(12, 2) NOT NULL CHECK (amount > 0),
+    currency VARCHAR(3) NOT NULL,
+    description TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'processing', 'succeeded', 'failed')),
+    provider VARCHAR(20) NOT NULL,
+    reference VARCHAR(255),
+    failure_reason TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (provider, reference)
+);
+
+CREATE INDEX idx_payments_org ON payments(organization_id, created_at DESC);
+CREATE INDEX idx_payments_company ON payments(company_id, created_at DESC) WHERE company_id IS NOT NULL;
+CREATE INDEX idx_payments_driver ON payments(driver_id, created_at DESC) WHERE driver_id IS NOT NULL;
+CREATE INDEX idx_payments_user ON payments(user_id, created_at DESC) WHERE user_id IS NOT NULL;
+
+CREATE TRIGGER update_payments_updated_at BEFORE UPDATE ON payments
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,17 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 045_notification_channels.sql
+
+-- Channels drivers and users are notified over besides their inbox, tried in
+-- order until one delivers. Recipients without a row get the configured
+-- NOTIFICATION_CHANNELS order.
+CREATE TABLE notification_channel_preferences (
+    recipient_type VARCHAR(20) NOT NULL CHECK (recipient_type IN ('driver', 'user')),
+    recipient_id UUID NOT NULL,
+    channels TEXT[] NOT NULL CHECK (cardinality(channels) > 0 AND channels <@ ARRAY['push', 'sms', 'email']),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (recipient_type, recipient_id)
+);
+
+CREATE TRIGGER update_notification_channel_preferences_updated_at BEFORE UPDATE ON notification_channel_preferences
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,29 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 046_driver_notification_settings.sql
+
+-- What drivers want delivered to them besides their inbox: the notification
+-- types (NULL for all), quiet hours in their time zone (an end before the
+-- start spans midnight) and how many notifications an hour at most (NULL for
+-- no limit). Drivers without a row get everything.
+CREATE TABLE driver_notification_settings (
+    driver_id UUID PRIMARY KEY REFERENCES drivers(id) ON DELETE CASCADE,
+    types TEXT[],
+    quiet_hours_start TIME,
+    quiet_hours_end TIME,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    max_per_hour INTEGER CHECK (max_per_hour > 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((quiet_hours_start IS NULL) = (quiet_hours_end IS NULL)),
+    CHECK (quiet_hours_start IS NULL OR quiet_hours_start <> quiet_hours_end)
+);
+
+CREATE TRIGGER update_driver_notification_settings_updated_at BEFORE UPDATE ON driver_notification_settings
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- When a notification was delivered over a channel, which the hourly limit
+-- counts; NULL while it is only in the inbox
+ALTER TABLE notifications ADD COLUMN delivered_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_notifications_driver_delivered ON notifications(driver_id, delivered_at)
+    WHERE delivered_at IS NOT NULL;
@@ -0,0 +1,20 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 047_notification_escalation.sql
+
+-- Bin alerts sent to the nearest driver escalate until acknowledged: to the
+-- next-nearest driver, then to the dispatchers. Each escalation is a new
+-- notification sharing the alert_id of the first one, with its level; the
+-- notification escalated is stamped so it escalates once. An alert
+-- acknowledged by any of its recipients stops escalating.
+ALTER TABLE notifications
+    ADD COLUMN alert_id UUID,
+    ADD COLUMN escalation_level SMALLINT NOT NULL DEFAULT 0,
+    ADD COLUMN escalated_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN acknowledged_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_notifications_alert ON notifications(alert_id) WHERE alert_id IS NOT NULL;
+CREATE INDEX idx_notifications_escalation_due ON notifications(sent_at)
+    WHERE alert_id IS NOT NULL AND acknowledged_at IS NULL AND escalated_at IS NULL;
+
+-- Recent alerts of a bin, so repeated sensor reports do not alert again
+CREATE INDEX idx_notifications_bin_type ON notifications(bin_id, type, sent_at DESC) WHERE bin_id IS NOT NULL;
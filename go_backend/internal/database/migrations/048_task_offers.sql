@@ -0,0 +1,22 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 048_task_offers.sql
+
+-- Full bins offered to the drivers their alert notifies. The driver accepts,
+-- which creates the collection and withdraws the other offers of the bin, or
+-- declines, which offers the bin to the next-nearest driver.
+CREATE TABLE task_offers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    bin_id UUID NOT NULL REFERENCES bins(id) ON DELETE CASCADE,
+    driver_id UUID NOT NULL REFERENCES drivers(id) ON DELETE CASCADE,
+    notification_id UUID REFERENCES notifications(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'offered'
+        CHECK (status IN ('offered', 'accepted', 'declined', 'withdrawn')),
+    collection_id UUID REFERENCES collections(id) ON DELETE SET NULL,
+    decline_reason TEXT,
+    offered_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    responded_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_task_offers_driver ON task_offers(driver_id, offered_at DESC, id DESC);
+CREATE INDEX idx_task_offers_open_bin ON task_offers(bin_id) WHERE status = 'offered';
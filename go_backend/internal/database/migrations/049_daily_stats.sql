@@ -0,0 +1,25 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 049_daily_stats.sql
+
+-- Daily snapshot of each organization's readings and collections, per UTC
+-- day, materialized nightly so trend analytics over long ranges do not scan
+-- the raw tables. The sum of the fill levels is kept alongside the average
+-- so days average correctly into weeks and months.
+CREATE TABLE daily_stats (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    readings INTEGER NOT NULL DEFAULT 0,
+    bins_reporting INTEGER NOT NULL DEFAULT 0,
+    fill_level_sum BIGINT NOT NULL DEFAULT 0,
+    average_fill_level DOUBLE PRECISION,
+    max_fill_level INTEGER,
+    collections INTEGER NOT NULL DEFAULT 0,
+    completed INTEGER NOT NULL DEFAULT 0,
+    cancelled INTEGER NOT NULL DEFAULT 0,
+    weight_kg DECIMAL(14, 2) NOT NULL DEFAULT 0,
+    active_drivers INTEGER NOT NULL DEFAULT 0,
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (organization_id, day)
+);
+
+CREATE INDEX idx_daily_stats_day ON daily_stats(day);
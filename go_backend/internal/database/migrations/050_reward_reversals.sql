@@ -0,0 +1,9 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 050_reward_reversals.sql
+
+-- A collection reopened by its compensated saga takes back the points it
+-- credited, recorded in the reward ledger with negative points. A collection
+-- is only reversed once.
+ALTER TABLE reward_transactions DROP CONSTRAINT reward_transactions_source_check;
+ALTER TABLE reward_transactions ADD CONSTRAINT reward_transactions_source_check
+    CHECK (source IN ('collection', 'shipment', 'cancellation', 'collection_reversal'));
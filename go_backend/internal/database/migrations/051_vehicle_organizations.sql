@@ -0,0 +1,17 @@
+-- Smart Waste Management Database Schema
+-- PostgreSQL Migration: 051_vehicle_organizations.sql
+
+-- Vehicles belong to one organization like the drivers they are assigned to;
+-- requests only see the vehicles of the organization of their access token.
+-- Assigned vehicles move to the organization of their driver, the others to
+-- the default organization.
+ALTER TABLE vehicles ADD COLUMN organization_id UUID NOT NULL
+    DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations(id);
+
+UPDATE vehicles v SET organization_id = d.organization_id
+FROM drivers d
+WHERE d.vehicle_id = v.id;
+
+ALTER TABLE vehicles ALTER COLUMN organization_id DROP DEFAULT;
+
+CREATE INDEX idx_vehicles_organization ON vehicles(organization_id, plate_number) WHERE is_active = true;
@@ -1,35 +1,21 @@
 package database
 
 import (
-	"fmt"
 	"log"
-	"time"
 
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
 	"github.com/smartwaste/backend/internal/config"
+	"github.com/smartwaste/pkg/postgres"
 )
 
 var db *sqlx.DB
 
 // InitDB initializes the database connection
 func InitDB(cfg *config.DatabaseConfig) (*sqlx.DB, error) {
-	dsn := cfg.GetDSN()
-
 	var err error
-	db, err = sqlx.Connect("postgres", dsn)
+	db, err = postgres.Open(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
-	}
-
-	// Configure connection pool
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxLifetime(5 * time.Minute)
-
-	// Test connection
-	if err := db.Ping(); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
+		return nil, err
 	}
 
 	log.Println("Database connection established successfully")
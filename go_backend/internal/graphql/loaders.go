@@ -0,0 +1,153 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/vikstrous/dataloadgen"
+)
+
+// loaderWait is how long a loader collects keys before running its batch
+const loaderWait = 2 * time.Millisecond
+
+// recentKey selects the newest collections of one bin or driver
+type recentKey struct {
+	ID    uuid.UUID
+	Limit int
+}
+
+// Loaders batch the per-object relation lookups of one request into a single
+// query per relation
+type Loaders struct {
+	bins              *dataloadgen.Loader[uuid.UUID, *models.Bin]
+	drivers           *dataloadgen.Loader[uuid.UUID, *models.Driver]
+	companies         *dataloadgen.Loader[uuid.UUID, *models.Company]
+	companyBins       *dataloadgen.Loader[uuid.UUID, []models.Bin]
+	binCollections    *dataloadgen.Loader[recentKey, []models.Collection]
+	driverCollections *dataloadgen.Loader[recentKey, []models.Collection]
+}
+
+// newLoaders creates the loaders for one request
+func (r *Resolver) newLoaders() *Loaders {
+	return &Loaders{
+		bins: dataloadgen.NewLoader(func(ctx context.Context, ids []uuid.UUID) ([]*models.Bin, []error) {
+			bins, err := r.binRepo.GetByIDs(ctx, ids)
+			if err != nil {
+				return nil, loadError("bins", err)
+			}
+			return byID(ids, bins, func(b *models.Bin) uuid.UUID { return b.ID }), nil
+		}, dataloadgen.WithWait(loaderWait)),
+
+		drivers: dataloadgen.NewLoader(func(ctx context.Context, ids []uuid.UUID) ([]*models.Driver, []error) {
+			drivers, err := r.driverRepo.GetByIDs(ctx, ids)
+			if err != nil {
+				return nil, loadError("drivers", err)
+			}
+			return byID(ids, drivers, func(d *models.Driver) uuid.UUID { return d.ID }), nil
+		}, dataloadgen.WithWait(loaderWait)),
+
+		companies: dataloadgen.NewLoader(func(ctx context.Context, ids []uuid.UUID) ([]*models.Company, []error) {
+			companies, err := r.companyRepo.GetByIDs(ctx, ids)
+			if err != nil {
+				return nil, loadError("companies", err)
+			}
+			return byID(ids, companies, func(c *models.Company) uuid.UUID { return c.ID }), nil
+		}, dataloadgen.WithWait(loaderWait)),
+
+		companyBins: dataloadgen.NewLoader(func(ctx context.Context, ids []uuid.UUID) ([][]models.Bin, []error) {
+			bins, err := r.binRepo.ListByCompanies(ctx, ids)
+			if err != nil {
+				return nil, loadError("company bins", err)
+			}
+			return groupBy(ids, bins, func(b *models.Bin) uuid.UUID { return *b.CompanyID }), nil
+		}, dataloadgen.WithWait(loaderWait)),
+
+		binCollections: dataloadgen.NewLoader(func(ctx context.Context, keys []recentKey) ([][]models.Collection, []error) {
+			return loadRecent(ctx, keys, r.collectionRepo.ListRecentByBins, func(c *models.Collection) uuid.UUID { return c.BinID })
+		}, dataloadgen.WithWait(loaderWait)),
+
+		driverCollections: dataloadgen.NewLoader(func(ctx context.Context, keys []recentKey) ([][]models.Collection, []error) {
+			return loadRecent(ctx, keys, r.collectionRepo.ListRecentByDrivers, func(c *models.Collection) uuid.UUID { return c.DriverID })
+		}, dataloadgen.WithWait(loaderWait)),
+	}
+}
+
+type loadersKey struct{}
+
+// withLoaders returns a copy of ctx carrying the loaders
+func withLoaders(ctx context.Context, loaders *Loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, loaders)
+}
+
+// loadersFor returns the loaders of the request ctx belongs to
+func loadersFor(ctx context.Context) *Loaders {
+	return ctx.Value(loadersKey{}).(*Loaders)
+}
+
+// loadError logs a failed batch and fails every key of it with a generic error
+func loadError(what string, err error) []error {
+	log.Printf("GraphQL: failed to load %s: %v", what, err)
+	return []error{fmt.Errorf("failed to load %s", what)}
+}
+
+// byID orders rows to match ids, leaving nil for IDs without a row
+func byID[T any](ids []uuid.UUID, rows []T, id func(*T) uuid.UUID) []*T {
+	index := make(map[uuid.UUID]*T, len(rows))
+	for i := range rows {
+		index[id(&rows[i])] = &rows[i]
+	}
+
+	out := make([]*T, len(ids))
+	for i, key := range ids {
+		out[i] = index[key]
+	}
+	return out
+}
+
+// groupBy splits rows by parent ID in the order of ids, keeping row order
+func groupBy[T any](ids []uuid.UUID, rows []T, parent func(*T) uuid.UUID) [][]T {
+	groups := make(map[uuid.UUID][]T, len(ids))
+	for _, row := range rows {
+		groups[parent(&row)] = append(groups[parent(&row)], row)
+	}
+
+	out := make([][]T, len(ids))
+	for i, key := range ids {
+		out[i] = groups[key]
+	}
+	return out
+}
+
+// loadRecent runs one query per distinct limit among keys
+func loadRecent(
+	ctx context.Context,
+	keys []recentKey,
+	list func(ctx context.Context, ids []uuid.UUID, limit int) ([]models.Collection, error),
+	parent func(*models.Collection) uuid.UUID,
+) ([][]models.Collection, []error) {
+	idsByLimit := make(map[int][]uuid.UUID)
+	for _, key := range keys {
+		idsByLimit[key.Limit] = append(idsByLimit[key.Limit], key.ID)
+	}
+
+	results := make(map[recentKey][]models.Collection, len(keys))
+	for limit, ids := range idsByLimit {
+		collections, err := list(ctx, ids, limit)
+		if err != nil {
+			return nil, loadError("collections", err)
+		}
+		for i, group := range groupBy(ids, collections, parent) {
+			results[recentKey{ID: ids[i], Limit: limit}] = group
+		}
+	}
+
+	out := make([][]models.Collection, len(keys))
+	for i, key := range keys {
+		out[i] = results[key]
+	}
+	return out, nil
+}
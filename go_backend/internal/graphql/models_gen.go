@@ -0,0 +1,35 @@
+// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package graphql
+
+import (
+	"github.com/smartwaste/backend/internal/models"
+)
+
+type BinConnection struct {
+	Nodes      []models.Bin `json:"nodes"`
+	TotalCount int          `json:"totalCount"`
+	// Pass as after to fetch the next page; null on the last page.
+	NextCursor *string `json:"nextCursor,omitempty"`
+}
+
+type CollectionConnection struct {
+	Nodes      []models.Collection `json:"nodes"`
+	TotalCount int                 `json:"totalCount"`
+	NextCursor *string             `json:"nextCursor,omitempty"`
+}
+
+type CompanyConnection struct {
+	Nodes      []models.Company `json:"nodes"`
+	TotalCount int              `json:"totalCount"`
+	NextCursor *string          `json:"nextCursor,omitempty"`
+}
+
+type DriverConnection struct {
+	Nodes      []models.Driver `json:"nodes"`
+	TotalCount int             `json:"totalCount"`
+	NextCursor *string         `json:"nextCursor,omitempty"`
+}
+
+type Query struct {
+}
@@ -0,0 +1,66 @@
+package graphql
+
+//go:generate go run github.com/99designs/gqlgen generate
+
+import (
+	"errors"
+
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/internal/services"
+)
+
+// maxPageSize caps the first argument of list fields, matching the REST per_page limit
+const maxPageSize = 100
+
+// errInvalidCursor is reported for cursors that do not belong to the list
+var errInvalidCursor = errors.New("invalid cursor")
+
+// Resolver resolves the dashboard schema against the REST API's repositories
+type Resolver struct {
+	binRepo        *repository.BinRepository
+	driverRepo     *repository.DriverRepository
+	collectionRepo *repository.CollectionRepository
+	companyRepo    *repository.CompanyRepository
+	analyticsSvc   *services.AnalyticsService
+}
+
+// page converts the first and after arguments of a list field into a repository page
+func page(first *int, after *string) (repository.Page, error) {
+	page := repository.Page{Limit: pageSize(first, 20)}
+	if after != nil && *after != "" {
+		cursor, err := repository.DecodeCursor(*after)
+		if err != nil {
+			return page, errInvalidCursor
+		}
+		page.After = cursor
+	}
+	return page, nil
+}
+
+// pageSize returns the requested number of items, capped at maxPageSize
+func pageSize(first *int, fallback int) int {
+	if first == nil || *first < 1 {
+		return fallback
+	}
+	if *first > maxPageSize {
+		return maxPageSize
+	}
+	return *first
+}
+
+// listError reports cursors that do not belong to the list as such and hides
+// other database errors behind message
+func listError(err error, message string) error {
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return errInvalidCursor
+	}
+	return errors.New(message)
+}
+
+// nextCursor returns the cursor of the following page, or nil on the last page
+func nextCursor(result repository.PageResult) *string {
+	if result.NextCursor == "" {
+		return nil
+	}
+	return &result.NextCursor
+}
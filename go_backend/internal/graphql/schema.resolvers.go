@@ -0,0 +1,183 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.60
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/internal/services"
+)
+
+// Company is the resolver for the company field.
+func (r *binResolver) Company(ctx context.Context, obj *models.Bin) (*models.Company, error) {
+	if obj.CompanyID == nil {
+		return nil, nil
+	}
+	return loadersFor(ctx).companies.Load(ctx, *obj.CompanyID)
+}
+
+// Collections is the resolver for the collections field.
+func (r *binResolver) Collections(ctx context.Context, obj *models.Bin, first *int) ([]models.Collection, error) {
+	return loadersFor(ctx).binCollections.Load(ctx, recentKey{ID: obj.ID, Limit: pageSize(first, 10)})
+}
+
+// Bin is the resolver for the bin field.
+func (r *collectionResolver) Bin(ctx context.Context, obj *models.Collection) (*models.Bin, error) {
+	return loadersFor(ctx).bins.Load(ctx, obj.BinID)
+}
+
+// Driver is the resolver for the driver field.
+func (r *collectionResolver) Driver(ctx context.Context, obj *models.Collection) (*models.Driver, error) {
+	return loadersFor(ctx).drivers.Load(ctx, obj.DriverID)
+}
+
+// Bins is the resolver for the bins field.
+func (r *companyResolver) Bins(ctx context.Context, obj *models.Company) ([]models.Bin, error) {
+	return loadersFor(ctx).companyBins.Load(ctx, obj.ID)
+}
+
+// Collections is the resolver for the collections field.
+func (r *driverResolver) Collections(ctx context.Context, obj *models.Driver, first *int) ([]models.Collection, error) {
+	return loadersFor(ctx).driverCollections.Load(ctx, recentKey{ID: obj.ID, Limit: pageSize(first, 10)})
+}
+
+// Bin is the resolver for the bin field.
+func (r *queryResolver) Bin(ctx context.Context, id uuid.UUID) (*models.Bin, error) {
+	return loadersFor(ctx).bins.Load(ctx, id)
+}
+
+// Bins is the resolver for the bins field.
+func (r *queryResolver) Bins(ctx context.Context, first *int, after *string) (*BinConnection, error) {
+	p, err := page(first, after)
+	if err != nil {
+		return nil, err
+	}
+
+	bins, result, err := r.binRepo.List(ctx, p)
+	if err != nil {
+		return nil, listError(err, "failed to retrieve bins")
+	}
+	return &BinConnection{Nodes: bins, TotalCount: result.Total, NextCursor: nextCursor(result)}, nil
+}
+
+// BinsNeedingCollection is the resolver for the binsNeedingCollection field.
+func (r *queryResolver) BinsNeedingCollection(ctx context.Context, threshold *int) ([]models.Bin, error) {
+	if threshold != nil && (*threshold < 1 || *threshold > 100) {
+		return nil, errors.New("threshold must be between 1 and 100")
+	}
+
+	bins, err := r.binRepo.GetBinsNeedingCollection(ctx, threshold)
+	if err != nil {
+		return nil, errors.New("failed to retrieve bins")
+	}
+	return bins, nil
+}
+
+// BinStatistics is the resolver for the binStatistics field.
+func (r *queryResolver) BinStatistics(ctx context.Context) (*repository.BinStatistics, error) {
+	stats, err := r.binRepo.Statistics(ctx)
+	if err != nil {
+		return nil, errors.New("failed to retrieve statistics")
+	}
+	return &stats, nil
+}
+
+// Driver is the resolver for the driver field.
+func (r *queryResolver) Driver(ctx context.Context, id uuid.UUID) (*models.Driver, error) {
+	return loadersFor(ctx).drivers.Load(ctx, id)
+}
+
+// Drivers is the resolver for the drivers field.
+func (r *queryResolver) Drivers(ctx context.Context, first *int, after *string) (*DriverConnection, error) {
+	p, err := page(first, after)
+	if err != nil {
+		return nil, err
+	}
+
+	drivers, result, err := r.driverRepo.List(ctx, p)
+	if err != nil {
+		return nil, listError(err, "failed to retrieve drivers")
+	}
+	return &DriverConnection{Nodes: drivers, TotalCount: result.Total, NextCursor: nextCursor(result)}, nil
+}
+
+// Collection is the resolver for the collection field.
+func (r *queryResolver) Collection(ctx context.Context, id uuid.UUID) (*models.Collection, error) {
+	collection, err := r.collectionRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("failed to retrieve collection")
+	}
+	return collection, nil
+}
+
+// Collections is the resolver for the collections field.
+func (r *queryResolver) Collections(ctx context.Context, filter *models.CollectionFilter, first *int, after *string) (*CollectionConnection, error) {
+	p, err := page(first, after)
+	if err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		filter = &models.CollectionFilter{}
+	}
+
+	collections, result, err := r.collectionRepo.ListFiltered(ctx, *filter, p)
+	if err != nil {
+		return nil, listError(err, "failed to retrieve collections")
+	}
+	return &CollectionConnection{Nodes: collections, TotalCount: result.Total, NextCursor: nextCursor(result)}, nil
+}
+
+// Company is the resolver for the company field.
+func (r *queryResolver) Company(ctx context.Context, id uuid.UUID) (*models.Company, error) {
+	return loadersFor(ctx).companies.Load(ctx, id)
+}
+
+// Companies is the resolver for the companies field.
+func (r *queryResolver) Companies(ctx context.Context, first *int, after *string) (*CompanyConnection, error) {
+	p, err := page(first, after)
+	if err != nil {
+		return nil, err
+	}
+
+	companies, result, err := r.companyRepo.List(ctx, p)
+	if err != nil {
+		return nil, listError(err, "failed to retrieve companies")
+	}
+	return &CompanyConnection{Nodes: companies, TotalCount: result.Total, NextCursor: nextCursor(result)}, nil
+}
+
+// DashboardStats is the resolver for the dashboardStats field.
+func (r *queryResolver) DashboardStats(ctx context.Context) (*services.DashboardStats, error) {
+	stats, err := r.analyticsSvc.GetDashboardStats(ctx)
+	if err != nil {
+		return nil, errors.New("failed to retrieve dashboard statistics")
+	}
+	return stats, nil
+}
+
+// Bin returns BinResolver implementation.
+func (r *Resolver) Bin() BinResolver { return &binResolver{r} }
+
+// Collection returns CollectionResolver implementation.
+func (r *Resolver) Collection() CollectionResolver { return &collectionResolver{r} }
+
+// Company returns CompanyResolver implementation.
+func (r *Resolver) Company() CompanyResolver { return &companyResolver{r} }
+
+// Driver returns DriverResolver implementation.
+func (r *Resolver) Driver() DriverResolver { return &driverResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+type binResolver struct{ *Resolver }
+type collectionResolver struct{ *Resolver }
+type companyResolver struct{ *Resolver }
+type driverResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
@@ -0,0 +1,50 @@
+package graphql
+
+import (
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/gin-gonic/gin"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/internal/services"
+)
+
+// maxQueryComplexity bounds how much a single query may fan out
+const maxQueryComplexity = 1000
+
+// Handler serves the dashboard GraphQL endpoint
+type Handler struct {
+	resolver *Resolver
+	server   *handler.Server
+}
+
+// NewHandler creates a new Handler
+func NewHandler(
+	binRepo *repository.BinRepository,
+	driverRepo *repository.DriverRepository,
+	collectionRepo *repository.CollectionRepository,
+	companyRepo *repository.CompanyRepository,
+	analyticsSvc *services.AnalyticsService,
+) *Handler {
+	resolver := &Resolver{
+		binRepo:        binRepo,
+		driverRepo:     driverRepo,
+		collectionRepo: collectionRepo,
+		companyRepo:    companyRepo,
+		analyticsSvc:   analyticsSvc,
+	}
+
+	server := handler.New(NewExecutableSchema(Config{Resolvers: resolver}))
+	server.AddTransport(transport.GET{})
+	server.AddTransport(transport.POST{})
+	server.Use(extension.Introspection{})
+	server.Use(extension.FixedComplexityLimit(maxQueryComplexity))
+
+	return &Handler{resolver: resolver, server: server}
+}
+
+// Query executes a GraphQL request with fresh dataloaders
+func (h *Handler) Query(c *gin.Context) {
+	ctx := withLoaders(c.Request.Context(), h.resolver.newLoaders())
+	h.server.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
+}
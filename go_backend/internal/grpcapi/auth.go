@@ -0,0 +1,114 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/smartwaste/backend/internal/auth"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/services"
+	pb "github.com/smartwaste/backend/pkg/pb/smartwaste/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// methodRoles lists the roles allowed to call each method, mirroring the REST
+// route guards. Methods absent from the map are open to any authenticated
+// principal; per-resource checks (a driver's own data) happen in the methods.
+var methodRoles = map[string][]models.Role{
+	pb.BinService_WatchBinUpdates_FullMethodName: {models.RoleAdmin, models.RoleDispatcher, models.RoleCompany},
+
+	pb.DriverService_ListDrivers_FullMethodName: {models.RoleAdmin, models.RoleDispatcher},
+
+	pb.CollectionService_GetCollection_FullMethodName:      {models.RoleAdmin, models.RoleDispatcher, models.RoleDriver},
+	pb.CollectionService_ListCollections_FullMethodName:    {models.RoleAdmin, models.RoleDispatcher, models.RoleDriver},
+	pb.CollectionService_CompleteCollection_FullMethodName: {models.RoleAdmin, models.RoleDispatcher, models.RoleDriver},
+}
+
+// authenticator resolves the principal of a call from its metadata, accepting
+// the same bearer tokens and API keys as the REST API
+type authenticator struct {
+	tokens  *auth.TokenManager
+	apiKeys *services.APIKeyService
+}
+
+// authenticate returns ctx carrying the claims of the caller authorized for method
+func (a *authenticator) authenticate(ctx context.Context, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var claims *auth.Claims
+	if keys := md.Get("x-api-key"); len(keys) > 0 && keys[0] != "" {
+		key, err := a.apiKeys.Authenticate(ctx, keys[0])
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidAPIKey) {
+				return nil, status.Error(codes.Unauthenticated, "invalid, revoked or expired API key")
+			}
+			return nil, status.Error(codes.Internal, "failed to verify API key")
+		}
+		if _, _, ok := a.apiKeys.Allow(key); !ok {
+			return nil, status.Error(codes.ResourceExhausted, "API key rate limit exceeded")
+		}
+		claims = auth.ClaimsForAPIKey(key)
+	} else {
+		var tokenString string
+		if values := md.Get("authorization"); len(values) > 0 {
+			tokenString, _ = strings.CutPrefix(values[0], "Bearer ")
+		}
+		if tokenString == "" {
+			return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+		}
+		parsed, err := a.tokens.Parse(tokenString)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+		}
+		claims = parsed
+	}
+
+	if roles, ok := methodRoles[method]; ok && !claims.HasRole(roles...) {
+		return nil, status.Error(codes.PermissionDenied, "insufficient permissions")
+	}
+	return auth.WithOrganization(auth.WithClaims(ctx, claims), claims.OrganizationID), nil
+}
+
+// unary authenticates unary calls
+func (a *authenticator) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := a.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// stream authenticates streaming calls
+func (a *authenticator) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+}
+
+// authenticatedStream is a server stream whose context carries the caller's claims
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// requireSelfOrRoles fails unless the caller is the given subject or holds one of the roles
+func requireSelfOrRoles(ctx context.Context, subjectID string, roles ...models.Role) error {
+	claims, ok := auth.ClaimsFromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "authentication required")
+	}
+	if claims.SubjectID.String() != subjectID && !claims.HasRole(roles...) {
+		return status.Error(codes.PermissionDenied, "insufficient permissions")
+	}
+	return nil
+}
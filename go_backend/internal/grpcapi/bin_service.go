@@ -0,0 +1,132 @@
+package grpcapi
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/auth"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/realtime"
+	"github.com/smartwaste/backend/internal/repository"
+	pb "github.com/smartwaste/backend/pkg/pb/smartwaste/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// binServer implements pb.BinServiceServer
+type binServer struct {
+	pb.UnimplementedBinServiceServer
+	binRepo *repository.BinRepository
+	hub     *realtime.Hub
+}
+
+// GetBin returns a bin by ID
+func (s *binServer) GetBin(ctx context.Context, req *pb.GetBinRequest) (*pb.GetBinResponse, error) {
+	id, err := parseID(req.GetId(), "bin ID")
+	if err != nil {
+		return nil, err
+	}
+
+	bin, err := s.binRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to retrieve bin")
+	}
+	if bin == nil {
+		return nil, status.Error(codes.NotFound, "bin not found")
+	}
+
+	return &pb.GetBinResponse{Bin: binToProto(bin)}, nil
+}
+
+// ListBins returns one page of active bins
+func (s *binServer) ListBins(ctx context.Context, req *pb.ListBinsRequest) (*pb.ListBinsResponse, error) {
+	page, err := parsePage(req.GetPageSize(), req.GetPageToken())
+	if err != nil {
+		return nil, err
+	}
+
+	bins, result, err := s.binRepo.List(ctx, page)
+	if err != nil {
+		return nil, listError(err, "failed to retrieve bins")
+	}
+
+	return &pb.ListBinsResponse{
+		Bins:          binsToProto(bins),
+		NextPageToken: result.NextCursor,
+		Total:         int32(result.Total),
+	}, nil
+}
+
+// ListBinsNeedingCollection returns bins at or above their collection threshold
+func (s *binServer) ListBinsNeedingCollection(ctx context.Context, req *pb.ListBinsNeedingCollectionRequest) (*pb.ListBinsNeedingCollectionResponse, error) {
+	var threshold *int
+	if req.Threshold != nil {
+		value := int(req.GetThreshold())
+		if value < 1 || value > 100 {
+			return nil, status.Error(codes.InvalidArgument, "threshold must be between 1 and 100")
+		}
+		threshold = &value
+	}
+
+	bins, err := s.binRepo.GetBinsNeedingCollection(ctx, threshold)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to retrieve bins")
+	}
+
+	return &pb.ListBinsNeedingCollectionResponse{Bins: binsToProto(bins)}, nil
+}
+
+// GetBinStatistics returns fleet-wide fill level statistics
+func (s *binServer) GetBinStatistics(ctx context.Context, _ *pb.GetBinStatisticsRequest) (*pb.GetBinStatisticsResponse, error) {
+	stats, err := s.binRepo.Statistics(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to retrieve statistics")
+	}
+
+	return &pb.GetBinStatisticsResponse{
+		TotalBins:        int32(stats.TotalBins),
+		NeedsCollection:  int32(stats.NeedsCollection),
+		NeedsAlert:       int32(stats.NeedsAlert),
+		AverageFillLevel: stats.AverageFillLevel,
+		Fill_0_25:        int32(stats.Fill0To25),
+		Fill_26_50:       int32(stats.Fill26To50),
+		Fill_51_75:       int32(stats.Fill51To75),
+		Fill_76_100:      int32(stats.Fill76To100),
+	}, nil
+}
+
+// WatchBinUpdates streams fill level changes until the client goes away
+func (s *binServer) WatchBinUpdates(req *pb.WatchBinUpdatesRequest, stream grpc.ServerStreamingServer[pb.WatchBinUpdatesResponse]) error {
+	companyID, err := parseOptionalID(req.CompanyId, "company ID")
+	if err != nil {
+		return err
+	}
+
+	// Company principals only receive their own company's bins
+	if claims, ok := auth.ClaimsFromContext(stream.Context()); ok && claims.Role == models.RoleCompany {
+		own := uuid.Nil
+		if claims.CompanyID != nil {
+			own = *claims.CompanyID
+		}
+		if companyID != nil && *companyID != own {
+			return status.Error(codes.PermissionDenied, "you can only watch your own company's bins")
+		}
+		companyID = &own
+	}
+
+	organizationID, _ := auth.OrganizationFromContext(stream.Context())
+	events, unsubscribe := s.hub.Subscribe(organizationID, companyID)
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event := <-events:
+			if err := stream.Send(binUpdateToProto(&event)); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package grpcapi
+
+import (
+	"context"
+
+	"github.com/smartwaste/backend/internal/auth"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/internal/services"
+	pb "github.com/smartwaste/backend/pkg/pb/smartwaste/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// collectionServer implements pb.CollectionServiceServer
+type collectionServer struct {
+	pb.UnimplementedCollectionServiceServer
+	collectionRepo *repository.CollectionRepository
+	collectionSvc  *services.CollectionService
+}
+
+// GetCollection returns a collection by ID
+func (s *collectionServer) GetCollection(ctx context.Context, req *pb.GetCollectionRequest) (*pb.GetCollectionResponse, error) {
+	collection, err := s.loadCollection(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetCollectionResponse{Collection: collectionToProto(collection)}, nil
+}
+
+// ListCollections returns one page of collections; drivers only see their own
+func (s *collectionServer) ListCollections(ctx context.Context, req *pb.ListCollectionsRequest) (*pb.ListCollectionsResponse, error) {
+	var filter models.CollectionFilter
+	var err error
+
+	if filter.DriverID, err = parseOptionalID(req.DriverId, "driver ID"); err != nil {
+		return nil, err
+	}
+	if filter.BinID, err = parseOptionalID(req.BinId, "bin ID"); err != nil {
+		return nil, err
+	}
+	if req.GetStatus() != pb.CollectionStatus_COLLECTION_STATUS_UNSPECIFIED {
+		collectionStatus, ok := collectionStatusFromProto(req.GetStatus())
+		if !ok {
+			return nil, status.Error(codes.InvalidArgument, "invalid collection status")
+		}
+		filter.Status = &collectionStatus
+	}
+	if req.From != nil {
+		from := req.GetFrom().AsTime()
+		filter.From = &from
+	}
+	if req.To != nil {
+		to := req.GetTo().AsTime()
+		filter.To = &to
+	}
+
+	if claims, ok := auth.ClaimsFromContext(ctx); ok && claims.Role == models.RoleDriver {
+		filter.DriverID = &claims.SubjectID
+	}
+
+	page, err := parsePage(req.GetPageSize(), req.GetPageToken())
+	if err != nil {
+		return nil, err
+	}
+
+	collections, result, err := s.collectionRepo.ListFiltered(ctx, filter, page)
+	if err != nil {
+		return nil, listError(err, "failed to retrieve collections")
+	}
+
+	out := make([]*pb.Collection, len(collections))
+	for i := range collections {
+		out[i] = collectionToProto(&collections[i])
+	}
+	return &pb.ListCollectionsResponse{
+		Collections:   out,
+		NextPageToken: result.NextCursor,
+		Total:         int32(result.Total),
+	}, nil
+}
+
+// CompleteCollection completes an open collection and empties its bin
+func (s *collectionServer) CompleteCollection(ctx context.Context, req *pb.CompleteCollectionRequest) (*pb.CompleteCollectionResponse, error) {
+	if req.GetFillLevelAfter() < 0 || req.GetFillLevelAfter() > 100 {
+		return nil, status.Error(codes.InvalidArgument, "fill_level_after must be between 0 and 100")
+	}
+
+	collection, err := s.loadCollection(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if !collection.Status.IsOpen() {
+		return nil, status.Error(codes.FailedPrecondition, "collection is already "+string(collection.Status))
+	}
+
+	updated, err := s.collectionSvc.Complete(ctx, collection, &models.CompleteCollectionRequest{
+		FillLevelAfter: int(req.GetFillLevelAfter()),
+		WeightKg:       req.WeightKg,
+		Notes:          req.Notes,
+	})
+	if err != nil || updated == nil {
+		return nil, status.Error(codes.Internal, "failed to complete collection")
+	}
+
+	return &pb.CompleteCollectionResponse{Collection: collectionToProto(updated)}, nil
+}
+
+// loadCollection resolves a collection ID, refusing drivers access to
+// collections they are not assigned to
+func (s *collectionServer) loadCollection(ctx context.Context, value string) (*models.Collection, error) {
+	id, err := parseID(value, "collection ID")
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := s.collectionRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to retrieve collection")
+	}
+	if collection == nil {
+		return nil, status.Error(codes.NotFound, "collection not found")
+	}
+
+	if claims, ok := auth.ClaimsFromContext(ctx); ok && claims.Role == models.RoleDriver && claims.SubjectID != collection.DriverID {
+		return nil, status.Error(codes.PermissionDenied, "you are not assigned to this collection")
+	}
+
+	return collection, nil
+}
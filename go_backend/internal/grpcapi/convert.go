@@ -0,0 +1,138 @@
+package grpcapi
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/realtime"
+	pb "github.com/smartwaste/backend/pkg/pb/smartwaste/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// collectionStatuses maps collection statuses to their protobuf enum values
+var collectionStatuses = map[models.CollectionStatus]pb.CollectionStatus{
+	models.CollectionStatusPending:    pb.CollectionStatus_COLLECTION_STATUS_PENDING,
+	models.CollectionStatusInProgress: pb.CollectionStatus_COLLECTION_STATUS_IN_PROGRESS,
+	models.CollectionStatusCompleted:  pb.CollectionStatus_COLLECTION_STATUS_COMPLETED,
+	models.CollectionStatusCancelled:  pb.CollectionStatus_COLLECTION_STATUS_CANCELLED,
+}
+
+func binToProto(b *models.Bin) *pb.Bin {
+	return &pb.Bin{
+		Id:                  b.ID.String(),
+		DeviceId:            b.DeviceID,
+		LocationName:        b.LocationName,
+		Latitude:            b.Latitude,
+		Longitude:           b.Longitude,
+		FillLevel:           int32(b.FillLevel),
+		WasteType:           b.WasteType,
+		CapacityLiters:      int32(b.CapacityLiters),
+		CompanyId:           optionalID(b.CompanyID),
+		IsActive:            b.IsActive,
+		IsOffline:           b.IsOffline,
+		CollectionThreshold: int32(b.CollectionThreshold),
+		AlertThreshold:      int32(b.AlertThreshold),
+		BatteryLevel:        optionalInt(b.BatteryLevel),
+		Rssi:                optionalInt(b.RSSI),
+		TemperatureC:        b.TemperatureC,
+		FirmwareVersion:     b.FirmwareVersion,
+		LastUpdatedAt:       timestamppb.New(b.LastUpdatedAt),
+		LastCollectionAt:    optionalTime(b.LastCollectionAt),
+		PredictedFullAt:     optionalTime(b.PredictedFullAt),
+		OfflineSince:        optionalTime(b.OfflineSince),
+		CreatedAt:           timestamppb.New(b.CreatedAt),
+	}
+}
+
+func binsToProto(bins []models.Bin) []*pb.Bin {
+	out := make([]*pb.Bin, len(bins))
+	for i := range bins {
+		out[i] = binToProto(&bins[i])
+	}
+	return out
+}
+
+func binUpdateToProto(e *realtime.BinUpdateEvent) *pb.WatchBinUpdatesResponse {
+	return &pb.WatchBinUpdatesResponse{
+		BinId:           e.BinID.String(),
+		DeviceId:        e.DeviceID,
+		CompanyId:       optionalID(e.CompanyID),
+		FillLevel:       int32(e.FillLevel),
+		Latitude:        e.Latitude,
+		Longitude:       e.Longitude,
+		PredictedFullAt: optionalTime(e.PredictedFullAt),
+		Timestamp:       timestamppb.New(e.Timestamp),
+	}
+}
+
+func driverToProto(d *models.Driver) *pb.Driver {
+	return &pb.Driver{
+		Id:               d.ID.String(),
+		Email:            d.Email,
+		FullName:         d.FullName,
+		Phone:            d.Phone,
+		LicenseNumber:    d.LicenseNumber,
+		VehicleType:      d.VehicleType,
+		VehiclePlate:     d.VehiclePlate,
+		VehicleId:        optionalID(d.VehicleID),
+		Latitude:         d.Latitude,
+		Longitude:        d.Longitude,
+		IsAvailable:      d.IsAvailable,
+		TotalCollections: int32(d.TotalCollections),
+		AverageRating:    d.AverageRating,
+		CreatedAt:        timestamppb.New(d.CreatedAt),
+		UpdatedAt:        timestamppb.New(d.UpdatedAt),
+	}
+}
+
+func collectionToProto(c *models.Collection) *pb.Collection {
+	return &pb.Collection{
+		Id:              c.ID.String(),
+		BinId:           c.BinID.String(),
+		DriverId:        c.DriverID.String(),
+		UserId:          optionalID(c.UserID),
+		FillLevelBefore: int32(c.FillLevelBefore),
+		FillLevelAfter:  int32(c.FillLevelAfter),
+		WeightKg:        c.WeightKg,
+		QrCodeVerified:  c.QRCodeVerified,
+		Notes:           c.Notes,
+		Status:          collectionStatuses[c.Status],
+		StartedAt:       timestamppb.New(c.StartedAt),
+		CompletedAt:     optionalTime(c.CompletedAt),
+	}
+}
+
+// collectionStatusFromProto returns the collection status of an enum value;
+// false means the value is unspecified or unknown
+func collectionStatusFromProto(s pb.CollectionStatus) (models.CollectionStatus, bool) {
+	for collectionStatus, value := range collectionStatuses {
+		if value == s {
+			return collectionStatus, true
+		}
+	}
+	return "", false
+}
+
+func optionalID(id *uuid.UUID) *string {
+	if id == nil {
+		return nil
+	}
+	s := id.String()
+	return &s
+}
+
+func optionalInt(v *int) *int32 {
+	if v == nil {
+		return nil
+	}
+	i := int32(*v)
+	return &i
+}
+
+func optionalTime(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
@@ -0,0 +1,137 @@
+package grpcapi
+
+import (
+	"context"
+
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/realtime"
+	"github.com/smartwaste/backend/internal/repository"
+	pb "github.com/smartwaste/backend/pkg/pb/smartwaste/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// driverServer implements pb.DriverServiceServer
+type driverServer struct {
+	pb.UnimplementedDriverServiceServer
+	driverRepo *repository.DriverRepository
+	locations  *realtime.LocationBroker
+}
+
+// GetDriver returns a driver by ID; drivers may only read themselves
+func (s *driverServer) GetDriver(ctx context.Context, req *pb.GetDriverRequest) (*pb.GetDriverResponse, error) {
+	if err := requireSelfOrRoles(ctx, req.GetId(), models.RoleAdmin, models.RoleDispatcher); err != nil {
+		return nil, err
+	}
+	id, err := parseID(req.GetId(), "driver ID")
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := s.driverRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to retrieve driver")
+	}
+	if driver == nil {
+		return nil, status.Error(codes.NotFound, "driver not found")
+	}
+
+	return &pb.GetDriverResponse{Driver: driverToProto(driver)}, nil
+}
+
+// ListDrivers returns one page of drivers
+func (s *driverServer) ListDrivers(ctx context.Context, req *pb.ListDriversRequest) (*pb.ListDriversResponse, error) {
+	page, err := parsePage(req.GetPageSize(), req.GetPageToken())
+	if err != nil {
+		return nil, err
+	}
+
+	drivers, result, err := s.driverRepo.List(ctx, page)
+	if err != nil {
+		return nil, listError(err, "failed to retrieve drivers")
+	}
+
+	out := make([]*pb.Driver, len(drivers))
+	for i := range drivers {
+		out[i] = driverToProto(&drivers[i])
+	}
+	return &pb.ListDriversResponse{
+		Drivers:       out,
+		NextPageToken: result.NextCursor,
+		Total:         int32(result.Total),
+	}, nil
+}
+
+// UpdateDriverLocation records the calling driver's position
+func (s *driverServer) UpdateDriverLocation(ctx context.Context, req *pb.UpdateDriverLocationRequest) (*pb.UpdateDriverLocationResponse, error) {
+	if err := requireSelfOrRoles(ctx, req.GetDriverId()); err != nil {
+		return nil, err
+	}
+	id, err := parseID(req.GetDriverId(), "driver ID")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.driverRepo.UpdateLocation(ctx, id, req.GetLatitude(), req.GetLongitude()); err != nil {
+		return nil, status.Error(codes.Internal, "failed to update location")
+	}
+
+	// Fan the new position out to live tracking subscribers
+	s.locations.Publish(id, req.GetLatitude(), req.GetLongitude())
+
+	return &pb.UpdateDriverLocationResponse{}, nil
+}
+
+// WatchDriverLocation streams a driver's positions, starting with the last known one
+func (s *driverServer) WatchDriverLocation(req *pb.WatchDriverLocationRequest, stream grpc.ServerStreamingServer[pb.WatchDriverLocationResponse]) error {
+	ctx := stream.Context()
+	if err := requireSelfOrRoles(ctx, req.GetDriverId(), models.RoleAdmin, models.RoleDispatcher); err != nil {
+		return err
+	}
+	id, err := parseID(req.GetDriverId(), "driver ID")
+	if err != nil {
+		return err
+	}
+
+	driver, err := s.driverRepo.GetByID(ctx, id)
+	if err != nil {
+		return status.Error(codes.Internal, "failed to retrieve driver")
+	}
+	if driver == nil {
+		return status.Error(codes.NotFound, "driver not found")
+	}
+
+	events, unsubscribe := s.locations.Subscribe(id)
+	defer unsubscribe()
+
+	if driver.Latitude != nil && driver.Longitude != nil {
+		err := stream.Send(&pb.WatchDriverLocationResponse{
+			DriverId:  driver.ID.String(),
+			Latitude:  *driver.Latitude,
+			Longitude: *driver.Longitude,
+			Timestamp: timestamppb.New(driver.UpdatedAt),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event := <-events:
+			err := stream.Send(&pb.WatchDriverLocationResponse{
+				DriverId:  event.DriverID.String(),
+				Latitude:  event.Latitude,
+				Longitude: event.Longitude,
+				Timestamp: timestamppb.New(event.Timestamp),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
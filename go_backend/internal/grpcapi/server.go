@@ -0,0 +1,104 @@
+// Package grpcapi serves the gRPC mirror of the REST API for internal
+// services and the mobile backend-for-frontend.
+package grpcapi
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/auth"
+	"github.com/smartwaste/backend/internal/realtime"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/internal/services"
+	pb "github.com/smartwaste/backend/pkg/pb/smartwaste/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// defaultPageSize and maxPageSize match the REST list endpoints
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// NewServer creates a gRPC server with the bin, driver and collection services
+// registered behind the same authentication as the REST API
+func NewServer(
+	tokens *auth.TokenManager,
+	apiKeys *services.APIKeyService,
+	binRepo *repository.BinRepository,
+	driverRepo *repository.DriverRepository,
+	collectionRepo *repository.CollectionRepository,
+	collectionSvc *services.CollectionService,
+	hub *realtime.Hub,
+	locations *realtime.LocationBroker,
+) *grpc.Server {
+	authn := &authenticator{tokens: tokens, apiKeys: apiKeys}
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(authn.unary),
+		grpc.ChainStreamInterceptor(authn.stream),
+	)
+
+	pb.RegisterBinServiceServer(server, &binServer{binRepo: binRepo, hub: hub})
+	pb.RegisterDriverServiceServer(server, &driverServer{driverRepo: driverRepo, locations: locations})
+	pb.RegisterCollectionServiceServer(server, &collectionServer{
+		collectionRepo: collectionRepo,
+		collectionSvc:  collectionSvc,
+	})
+
+	// Lets grpcurl and similar tools discover the services
+	reflection.Register(server)
+
+	return server
+}
+
+// parsePage converts a page size and token into a repository page. The page
+// token is the keyset cursor the REST API returns as next_cursor.
+func parsePage(size int32, token string) (repository.Page, error) {
+	page := repository.Page{Limit: int(size)}
+	if page.Limit < 1 {
+		page.Limit = defaultPageSize
+	} else if page.Limit > maxPageSize {
+		page.Limit = maxPageSize
+	}
+
+	if token != "" {
+		cursor, err := repository.DecodeCursor(token)
+		if err != nil {
+			return page, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+		page.After = cursor
+	}
+	return page, nil
+}
+
+// listError converts a failed list query into a status error
+func listError(err error, message string) error {
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return status.Error(codes.InvalidArgument, "invalid page token")
+	}
+	return status.Error(codes.Internal, message)
+}
+
+// parseID parses a UUID request field
+func parseID(value, field string) (uuid.UUID, error) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, status.Errorf(codes.InvalidArgument, "invalid %s", field)
+	}
+	return id, nil
+}
+
+// parseOptionalID parses an optional UUID request field
+func parseOptionalID(value *string, field string) (*uuid.UUID, error) {
+	if value == nil {
+		return nil, nil
+	}
+	id, err := parseID(*value, field)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
@@ -1,22 +1,43 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
 	"github.com/smartwaste/backend/internal/services"
 	"github.com/smartwaste/backend/pkg/utils"
 )
 
+// Default period and maximum length of analytics series
+const (
+	defaultAnalyticsDays = 30
+	maxAnalyticsBuckets  = 366
+)
+
+// Default and bounds of the heatmap grid size
+const (
+	defaultHeatmapGrid = 32
+	minHeatmapGrid     = 4
+	maxHeatmapGrid     = 128
+)
+
 // AnalyticsHandler handles analytics-related HTTP requests
 type AnalyticsHandler struct {
 	analyticsSvc *services.AnalyticsService
+	companyRepo  *repository.CompanyRepository
 }
 
 // NewAnalyticsHandler creates a new AnalyticsHandler
-func NewAnalyticsHandler(analyticsSvc *services.AnalyticsService) *AnalyticsHandler {
-	return &AnalyticsHandler{analyticsSvc: analyticsSvc}
+func NewAnalyticsHandler(analyticsSvc *services.AnalyticsService, companyRepo *repository.CompanyRepository) *AnalyticsHandler {
+	return &AnalyticsHandler{analyticsSvc: analyticsSvc, companyRepo: companyRepo}
 }
 
 // GetDashboardStats retrieves overall dashboard statistics
@@ -28,7 +49,7 @@ func NewAnalyticsHandler(analyticsSvc *services.AnalyticsService) *AnalyticsHand
 func (h *AnalyticsHandler) GetDashboardStats(c *gin.Context) {
 	stats, err := h.analyticsSvc.GetDashboardStats(c.Request.Context())
 	if err != nil {
-		utils.InternalError(c, "Failed to retrieve dashboard statistics")
+		abortWithError(c, err, "Failed to retrieve dashboard statistics")
 		return
 	}
 
@@ -37,14 +58,24 @@ func (h *AnalyticsHandler) GetDashboardStats(c *gin.Context) {
 
 // GetBinAnalytics retrieves bin-specific analytics
 // @Summary Get bin analytics
+// @Description Current fill-level snapshot plus the average and peak fill level reported per time bucket
 // @Tags Analytics
 // @Produce json
+// @Param from query string false "Series start (YYYY-MM-DD or RFC3339); defaults to 30 days before to"
+// @Param to query string false "Series end (YYYY-MM-DD inclusive, or RFC3339); defaults to now"
+// @Param group_by query string false "Bucket width: day, week or month" default(day)
 // @Success 200 {object} services.BinAnalytics
+// @Failure 400 {object} utils.APIError
 // @Router /api/v1/analytics/bins [get]
 func (h *AnalyticsHandler) GetBinAnalytics(c *gin.Context) {
-	analytics, err := h.analyticsSvc.GetBinAnalytics(c.Request.Context())
+	period, ok := parseAnalyticsRange(c)
+	if !ok {
+		return
+	}
+
+	analytics, err := h.analyticsSvc.GetBinAnalytics(c.Request.Context(), period)
 	if err != nil {
-		utils.InternalError(c, "Failed to retrieve bin analytics")
+		abortWithError(c, err, "Failed to retrieve bin analytics")
 		return
 	}
 
@@ -60,29 +91,263 @@ func (h *AnalyticsHandler) GetBinAnalytics(c *gin.Context) {
 func (h *AnalyticsHandler) GetDriverAnalytics(c *gin.Context) {
 	analytics, err := h.analyticsSvc.GetDriverAnalytics(c.Request.Context())
 	if err != nil {
-		utils.InternalError(c, "Failed to retrieve driver analytics")
+		abortWithError(c, err, "Failed to retrieve driver analytics")
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, analytics)
 }
 
+// GetDriverLeaderboard ranks the drivers by their collections of the current week or month
+// @Summary Get driver leaderboard
+// @Description Drivers ranked by completed collections, weight, on-time rate or rating, with the badges they earned. Drivers also get their own standing as current_driver.
+// @Tags Analytics
+// @Produce json
+// @Param period query string false "week or month" default(week)
+// @Param rank_by query string false "collections, weight, on_time_rate or rating" default(collections)
+// @Param limit query int false "Number of drivers (1-100)" default(20)
+// @Success 200 {object} models.DriverLeaderboard
+// @Failure 400 {object} utils.APIError
+// @Router /api/v1/analytics/drivers/leaderboard [get]
+func (h *AnalyticsHandler) GetDriverLeaderboard(c *gin.Context) {
+	query := services.LeaderboardQuery{
+		Period: models.LeaderboardPeriod(c.DefaultQuery("period", string(models.LeaderboardWeek))),
+		RankBy: models.LeaderboardRankBy(c.DefaultQuery("rank_by", string(models.RankByCollections))),
+		Limit:  getQueryInt(c, "limit", 20),
+	}
+	if !query.Period.IsValid() {
+		utils.BadRequest(c, "period must be week or month")
+		return
+	}
+	if !query.RankBy.IsValid() {
+		utils.BadRequest(c, "rank_by must be collections, weight, on_time_rate or rating")
+		return
+	}
+	if query.Limit < 1 || query.Limit > 100 {
+		query.Limit = 20
+	}
+	if claims, ok := currentClaims(c); ok && claims.Role == models.RoleDriver {
+		query.DriverID = &claims.SubjectID
+	}
+
+	leaderboard, err := h.analyticsSvc.GetDriverLeaderboard(c.Request.Context(), query)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve driver leaderboard")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, leaderboard)
+}
+
+// GetHeatmap retrieves bins aggregated into a grid for a map heat layer
+// @Summary Get a bin heatmap
+// @Description Average fill level, or completed collections over the period, per grid cell of the bounding box
+// @Tags Analytics
+// @Produce json
+// @Param metric query string false "fill_level or collections" default(fill_level)
+// @Param bbox query string true "Bounding box: min_lng,min_lat,max_lng,max_lat"
+// @Param grid query int false "Cells along each side of the box (4-128)" default(32)
+// @Param from query string false "Collections since (YYYY-MM-DD or RFC3339); defaults to 30 days before to"
+// @Param to query string false "Collections until (YYYY-MM-DD inclusive, or RFC3339); defaults to now"
+// @Success 200 {object} models.Heatmap
+// @Failure 400 {object} utils.APIError
+// @Router /api/v1/analytics/heatmap [get]
+func (h *AnalyticsHandler) GetHeatmap(c *gin.Context) {
+	query := models.HeatmapQuery{
+		Metric: models.HeatmapMetric(c.DefaultQuery("metric", string(models.HeatmapFillLevel))),
+		Grid:   getQueryInt(c, "grid", defaultHeatmapGrid),
+	}
+	if !query.Metric.IsValid() {
+		utils.BadRequest(c, "metric must be fill_level or collections")
+		return
+	}
+	if query.Grid < minHeatmapGrid || query.Grid > maxHeatmapGrid {
+		query.Grid = defaultHeatmapGrid
+	}
+
+	bbox, err := parseBoundingBox(c.Query("bbox"))
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+	query.BBox = bbox
+
+	if query.Metric == models.HeatmapCollections {
+		period, ok := parsePeriod(c)
+		if !ok {
+			return
+		}
+		query.Period = period
+	}
+
+	heatmap, err := h.analyticsSvc.GetHeatmap(c.Request.Context(), query)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve heatmap")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, heatmap)
+}
+
 // GetCollectionAnalytics retrieves collection analytics
 // @Summary Get collection analytics
+// @Description Today and month totals plus the collections started and weight collected per time bucket
 // @Tags Analytics
 // @Produce json
+// @Param from query string false "Series start (YYYY-MM-DD or RFC3339); defaults to 30 days before to"
+// @Param to query string false "Series end (YYYY-MM-DD inclusive, or RFC3339); defaults to now"
+// @Param group_by query string false "Bucket width: day, week or month" default(day)
 // @Success 200 {object} services.CollectionAnalytics
+// @Failure 400 {object} utils.APIError
 // @Router /api/v1/analytics/collections [get]
 func (h *AnalyticsHandler) GetCollectionAnalytics(c *gin.Context) {
-	analytics, err := h.analyticsSvc.GetCollectionAnalytics(c.Request.Context())
+	period, ok := parseAnalyticsRange(c)
+	if !ok {
+		return
+	}
+
+	analytics, err := h.analyticsSvc.GetCollectionAnalytics(c.Request.Context(), period)
 	if err != nil {
-		utils.InternalError(c, "Failed to retrieve collection analytics")
+		abortWithError(c, err, "Failed to retrieve collection analytics")
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, analytics)
 }
 
+// GetCompanyAnalytics retrieves the analytics of one company's bins
+// @Summary Get company analytics
+// @Description Fill levels of the company's bins, its collections over the period, the weight recovered per waste type and the valuation totals. Admins, dispatchers and the company's own API keys.
+// @Tags Analytics
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param from query string false "Period start (YYYY-MM-DD or RFC3339); defaults to 30 days before to"
+// @Param to query string false "Period end (YYYY-MM-DD inclusive, or RFC3339); defaults to now"
+// @Param group_by query string false "Bucket width of the collection series: day, week or month" default(day)
+// @Success 200 {object} services.CompanyAnalytics
+// @Failure 400 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/companies/{id}/analytics [get]
+func (h *AnalyticsHandler) GetCompanyAnalytics(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid company ID format")
+		return
+	}
+
+	period, ok := parseAnalyticsRange(c)
+	if !ok {
+		return
+	}
+
+	company, err := h.companyRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve company")
+		return
+	}
+	if company == nil {
+		utils.NotFound(c, "Company not found")
+		return
+	}
+
+	analytics, err := h.analyticsSvc.GetCompanyAnalytics(c.Request.Context(), id, period)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve company analytics")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, analytics)
+}
+
+// parseBoundingBox parses a min_lng,min_lat,max_lng,max_lat bounding box.
+// Boxes crossing the antimeridian are not supported.
+func parseBoundingBox(value string) (models.BoundingBox, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return models.BoundingBox{}, errors.New("bbox must be min_lng,min_lat,max_lng,max_lat")
+	}
+	coords := make([]float64, 4)
+	for i, part := range parts {
+		coord, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return models.BoundingBox{}, errors.New("bbox must be min_lng,min_lat,max_lng,max_lat")
+		}
+		coords[i] = coord
+	}
+
+	bbox := models.BoundingBox{MinLongitude: coords[0], MinLatitude: coords[1], MaxLongitude: coords[2], MaxLatitude: coords[3]}
+	if bbox.MinLatitude < -90 || bbox.MaxLatitude > 90 {
+		return bbox, errors.New("bbox latitudes must be between -90 and 90")
+	}
+	if bbox.MinLongitude < -180 || bbox.MaxLongitude > 180 {
+		return bbox, errors.New("bbox longitudes must be between -180 and 180")
+	}
+	if bbox.MinLatitude >= bbox.MaxLatitude || bbox.MinLongitude >= bbox.MaxLongitude {
+		return bbox, errors.New("bbox minimums must be below its maximums")
+	}
+	return bbox, nil
+}
+
+// parseAnalyticsRange reads the from, to and group_by parameters of an
+// analytics series, defaulting to daily buckets over the last 30 days. It
+// writes the error response and returns false when they are invalid.
+func parseAnalyticsRange(c *gin.Context) (models.AnalyticsRange, bool) {
+	period, ok := parsePeriod(c)
+	if !ok {
+		return period, false
+	}
+
+	period.GroupBy = models.GroupByDay
+	if value := c.Query("group_by"); value != "" {
+		period.GroupBy = models.AnalyticsGroupBy(value)
+		if !period.GroupBy.IsValid() {
+			utils.BadRequest(c, "group_by must be day, week or month")
+			return period, false
+		}
+	}
+
+	if period.To.Sub(period.From) > maxAnalyticsBuckets*period.GroupBy.MinWidth() {
+		utils.BadRequest(c, fmt.Sprintf("The range spans more than %d buckets; use a wider group_by", maxAnalyticsBuckets))
+		return period, false
+	}
+	return period, true
+}
+
+// parsePeriod reads the from and to parameters of a report, defaulting to the
+// last 30 days, and writes the error response when they are invalid
+func parsePeriod(c *gin.Context) (models.AnalyticsRange, bool) {
+	period := models.AnalyticsRange{To: time.Now().UTC()}
+
+	if value := c.Query("to"); value != "" {
+		to, dateOnly, err := parseDateParam(value)
+		if err != nil {
+			utils.BadRequest(c, "Invalid to date")
+			return period, false
+		}
+		// A bare date includes the whole day
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+		period.To = to
+	}
+
+	period.From = period.To.AddDate(0, 0, -defaultAnalyticsDays).Truncate(24 * time.Hour)
+	if value := c.Query("from"); value != "" {
+		from, _, err := parseDateParam(value)
+		if err != nil {
+			utils.BadRequest(c, "Invalid from date")
+			return period, false
+		}
+		period.From = from
+	}
+
+	if !period.From.Before(period.To) {
+		utils.BadRequest(c, "from must be before to")
+		return period, false
+	}
+	return period, true
+}
+
 // Helper function to get query parameter as int
 func getQueryInt(c *gin.Context, key string, defaultValue int) int {
 	valueStr := c.Query(key)
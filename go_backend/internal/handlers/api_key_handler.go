@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/internal/services"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// APIKeyHandler lets administrators issue and revoke API keys
+type APIKeyHandler struct {
+	repo *repository.APIKeyRepository
+	svc  *services.APIKeyService
+}
+
+// NewAPIKeyHandler creates a new APIKeyHandler
+func NewAPIKeyHandler(repo *repository.APIKeyRepository, svc *services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		repo: repo,
+		svc:  svc,
+	}
+}
+
+// ListAPIKeys retrieves issued API keys
+// @Summary List API keys
+// @Tags Admin
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Param cursor query string false "Continue after the next_cursor of a previous page"
+// @Success 200 {array} models.APIKey
+// @Router /api/v1/admin/api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	pagination, ok := parsePage(c, 20)
+	if !ok {
+		return
+	}
+
+	keys, result, err := h.repo.List(c.Request.Context(), pagination.page)
+	if err != nil {
+		listError(c, err, "Failed to retrieve API keys")
+		return
+	}
+
+	utils.SuccessResponseWithPagination(c, keys, pagination.meta(result))
+}
+
+// CreateAPIKey issues a new API key
+// @Summary Issue an API key
+// @Description The plaintext key is only returned in this response; store it securely.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param key body models.CreateAPIKeyRequest true "API key data"
+// @Success 201 {object} models.CreateAPIKeyResponse
+// @Failure 400 {object} utils.APIError
+// @Router /api/v1/admin/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var req models.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+	if !models.IsValidAPIKeyRole(req.Role) {
+		utils.ValidationError(c, "Role must be one of: dispatcher, company, citizen, device")
+		return
+	}
+
+	var createdBy *uuid.UUID
+	if claims, ok := currentClaims(c); ok && claims.APIKeyID == nil {
+		createdBy = &claims.SubjectID
+	}
+
+	key, err := h.svc.Issue(c.Request.Context(), &req, createdBy)
+	if err != nil {
+		if repository.IsForeignKeyViolation(err) {
+			utils.ValidationError(c, "Company not found")
+			return
+		}
+		abortWithError(c, err, "Failed to issue API key")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, key)
+}
+
+// GetAPIKey retrieves an API key by ID
+// @Summary Get API key
+// @Tags Admin
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} models.APIKey
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/admin/api-keys/{id} [get]
+func (h *APIKeyHandler) GetAPIKey(c *gin.Context) {
+	key, ok := h.loadAPIKey(c)
+	if !ok {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, key)
+}
+
+// RevokeAPIKey revokes an API key; requests made with it are rejected from then on
+// @Summary Revoke API key
+// @Tags Admin
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} models.APIKey
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/admin/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	key, ok := h.loadAPIKey(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Revoke(c.Request.Context(), key.ID); err != nil {
+		abortWithError(c, err, "Failed to revoke API key")
+		return
+	}
+
+	revoked, err := h.repo.GetByID(c.Request.Context(), key.ID)
+	if err == nil && revoked == nil {
+		err = repository.ErrNotFound
+	}
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve API key")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, revoked)
+}
+
+// loadAPIKey resolves the :id API key, writing the error response itself
+func (h *APIKeyHandler) loadAPIKey(c *gin.Context) (*models.APIKey, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid API key ID format")
+		return nil, false
+	}
+
+	key, err := h.repo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve API key")
+		return nil, false
+	}
+	if key == nil {
+		utils.NotFound(c, "API key not found")
+		return nil, false
+	}
+
+	return key, true
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/auth"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/pkg/security"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// AuthHandler handles authentication HTTP requests
+type AuthHandler struct {
+	userRepo   *repository.UserRepository
+	driverRepo *repository.DriverRepository
+	memberRepo *repository.CompanyMemberRepository
+	orgRepo    *repository.OrganizationRepository
+	hasher     security.PasswordHasher
+	tokens     *auth.TokenManager
+}
+
+// NewAuthHandler creates a new AuthHandler
+func NewAuthHandler(
+	userRepo *repository.UserRepository,
+	driverRepo *repository.DriverRepository,
+	memberRepo *repository.CompanyMemberRepository,
+	orgRepo *repository.OrganizationRepository,
+	hasher security.PasswordHasher,
+	tokens *auth.TokenManager,
+) *AuthHandler {
+	return &AuthHandler{
+		userRepo:   userRepo,
+		driverRepo: driverRepo,
+		memberRepo: memberRepo,
+		orgRepo:    orgRepo,
+		hasher:     hasher,
+		tokens:     tokens,
+	}
+}
+
+// Login authenticates a user or driver and issues an access token scoped to
+// their organization; company members' tokens carry their company and role
+// within it. Members of a deactivated organization cannot log in.
+// @Summary Log in with email and password
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param credentials body models.LoginRequest true "Credentials"
+// @Success 200 {object} models.LoginResponse
+// @Failure 401 {object} utils.APIError
+// @Router /api/v1/auth/login [post]
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req models.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// Users take precedence; fall back to the drivers table
+	var (
+		subjectID      uuid.UUID
+		organizationID uuid.UUID
+		email          string
+		passwordHash   string
+		role           models.Role
+	)
+
+	user, err := h.userRepo.GetByEmail(ctx, req.Email)
+	if err != nil {
+		abortWithError(c, err, "Failed to authenticate")
+		return
+	}
+	if user != nil {
+		subjectID, organizationID, email, passwordHash, role = user.ID, user.OrganizationID, user.Email, user.PasswordHash, user.Role
+	} else {
+		driver, err := h.driverRepo.GetByEmail(ctx, req.Email)
+		if err != nil {
+			abortWithError(c, err, "Failed to authenticate")
+			return
+		}
+		if driver == nil {
+			utils.Unauthorized(c, "Invalid email or password")
+			return
+		}
+		subjectID, organizationID, email, passwordHash, role = driver.ID, driver.OrganizationID, driver.Email, driver.PasswordHash, models.RoleDriver
+	}
+
+	if err := h.hasher.VerifyPassword(passwordHash, req.Password); err != nil {
+		if errors.Is(err, security.ErrPasswordMismatch) {
+			utils.Unauthorized(c, "Invalid email or password")
+			return
+		}
+		abortWithError(c, err, "Failed to authenticate")
+		return
+	}
+
+	organization, err := h.orgRepo.GetByID(ctx, organizationID)
+	if err != nil {
+		abortWithError(c, err, "Failed to authenticate")
+		return
+	}
+	if organization == nil || !organization.IsActive {
+		utils.Unauthorized(c, "Organization is deactivated")
+		return
+	}
+
+	var member *models.CompanyMember
+	if role == models.RoleCompany {
+		if member, err = h.memberRepo.GetByUser(ctx, subjectID); err != nil {
+			abortWithError(c, err, "Failed to authenticate")
+			return
+		}
+	}
+
+	var (
+		token     string
+		expiresAt time.Time
+	)
+	if member != nil {
+		token, expiresAt, err = h.tokens.IssueMember(organizationID, email, member)
+	} else {
+		token, expiresAt, err = h.tokens.Issue(subjectID, organizationID, email, role)
+	}
+	if err != nil {
+		abortWithError(c, err, "Failed to issue access token")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, loginResponse(token, expiresAt, role, subjectID, organizationID, member))
+}
+
+// Me returns the authenticated principal
+// @Summary Get the authenticated principal
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} utils.APIError
+// @Router /api/v1/auth/me [get]
+func (h *AuthHandler) Me(c *gin.Context) {
+	claims, ok := currentClaims(c)
+	if !ok {
+		utils.Unauthorized(c, "Authentication required")
+		return
+	}
+
+	me := gin.H{
+		"subject_id":      claims.SubjectID,
+		"organization_id": claims.OrganizationID,
+		"email":           claims.Email,
+		"role":            claims.Role,
+	}
+	if claims.CompanyID != nil {
+		me["company_id"] = claims.CompanyID
+		me["company_role"] = claims.MemberRole()
+	}
+	utils.SuccessResponse(c, http.StatusOK, me)
+}
+
+// loginResponse describes an issued access token; member is nil unless the
+// principal is a company member
+func loginResponse(token string, expiresAt time.Time, role models.Role, subjectID, organizationID uuid.UUID, member *models.CompanyMember) *models.LoginResponse {
+	response := &models.LoginResponse{
+		AccessToken:    token,
+		TokenType:      "Bearer",
+		ExpiresAt:      expiresAt.Unix(),
+		Role:           role,
+		SubjectID:      subjectID.String(),
+		OrganizationID: organizationID.String(),
+	}
+	if member != nil {
+		response.CompanyID = member.CompanyID.String()
+		response.CompanyRole = member.Role
+	}
+	return response
+}
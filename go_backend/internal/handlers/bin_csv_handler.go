@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/pkg/utils"
+	"github.com/smartwaste/pkg/validation"
+)
+
+const (
+	// maxBinImportSize caps the size of an uploaded CSV file
+	maxBinImportSize = 5 << 20
+	// maxBinImportRows caps the number of data rows in a single import
+	maxBinImportRows = 5000
+)
+
+// binExportColumns is the column order of exported CSV files. Imports accept the
+// same header so an export can be edited and re-imported.
+var binExportColumns = []string{
+	"id", "device_id", "location_name", "latitude", "longitude", "waste_type",
+	"capacity_liters", "company_id", "fill_level", "is_active", "last_updated_at", "created_at",
+	"collection_threshold", "alert_threshold",
+}
+
+// binImportRequiredColumns must be present in an import header
+var binImportRequiredColumns = []string{"device_id", "latitude", "longitude", "waste_type", "capacity_liters"}
+
+// ImportBins registers bins in bulk from a CSV upload
+// @Summary Import bins from CSV
+// @Tags Bins
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV file with a header row"
+// @Param dry_run query bool false "Validate without creating bins"
+// @Success 200 {object} models.BinImportResult
+// @Failure 400 {object} utils.APIError
+// @Router /api/v1/bins/import [post]
+func (h *BinHandler) ImportBins(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBinImportSize)
+	file, _, err := c.Request.FormFile("file")
+	if err != nil {
+		utils.BadRequest(c, "A CSV file of at most 5 MB is required in the 'file' field")
+		return
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		utils.BadRequest(c, "CSV file is empty or unreadable")
+		return
+	}
+	columns, err := parseBinCSVHeader(header)
+	if err != nil {
+		validationError(c, err)
+		return
+	}
+
+	// Read the whole file first so an oversized import is rejected before any bin is created
+	type csvRow struct {
+		line   int
+		record []string
+		err    error
+	}
+	var rows []csvRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if len(rows) == maxBinImportRows {
+			utils.ValidationError(c, fmt.Sprintf("CSV file exceeds %d rows", maxBinImportRows))
+			return
+		}
+		rows = append(rows, csvRow{line: line, record: record, err: err})
+	}
+
+	result := &models.BinImportResult{
+		DryRun: c.Query("dry_run") == "true",
+		Total:  len(rows),
+		Errors: []models.BinImportRowError{},
+	}
+	seen := make(map[string]int)
+	ctx := c.Request.Context()
+
+	for _, row := range rows {
+		if row.err != nil {
+			addImportError(result, row.line, "", "Malformed CSV row: "+row.err.Error())
+			continue
+		}
+
+		bin, err := parseBinCSVRow(columns, row.record)
+		if err != nil {
+			addImportError(result, row.line, columns.value(row.record, "device_id"), err.Error())
+			continue
+		}
+
+		if firstLine, ok := seen[bin.DeviceID]; ok {
+			addImportError(result, row.line, bin.DeviceID, fmt.Sprintf("Duplicate device_id, first seen on row %d", firstLine))
+			continue
+		}
+		seen[bin.DeviceID] = row.line
+
+		existing, err := h.repo.GetByDeviceID(ctx, bin.DeviceID)
+		if err != nil {
+			abortWithError(c, err, "Failed to check existing bins")
+			return
+		}
+		if existing != nil {
+			addImportError(result, row.line, bin.DeviceID, "Device ID already registered")
+			continue
+		}
+
+		if result.DryRun {
+			continue
+		}
+
+		if err := h.repo.Create(ctx, bin); err != nil {
+			switch {
+			case repository.IsUniqueViolation(err):
+				addImportError(result, row.line, bin.DeviceID, "Device ID already registered")
+			case repository.IsForeignKeyViolation(err):
+				addImportError(result, row.line, bin.DeviceID, "Company not found")
+			default:
+				abortWithError(c, err, "Failed to create bins")
+				return
+			}
+			continue
+		}
+		result.Imported++
+	}
+
+	result.Failed = len(result.Errors)
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// ExportBins streams every bin as CSV
+// @Summary Export bins as CSV
+// @Tags Bins
+// @Produce text/csv
+// @Success 200 {file} file
+// @Router /api/v1/bins/export [get]
+func (h *BinHandler) ExportBins(c *gin.Context) {
+	filename := fmt.Sprintf("bins-%s.csv", time.Now().UTC().Format("20060102"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	if err := writer.Write(binExportColumns); err != nil {
+		return
+	}
+
+	err := h.repo.Each(c.Request.Context(), func(bin *models.Bin) error {
+		return writer.Write(binCSVRecord(bin))
+	})
+	writer.Flush()
+
+	if err == nil {
+		err = writer.Error()
+	}
+	if err != nil {
+		// Headers are already sent; the truncated file is the only signal left
+		log.Printf("Bin export aborted: %v", err)
+	}
+}
+
+// addImportError records a failed row
+func addImportError(result *models.BinImportResult, row int, deviceID, message string) {
+	result.Errors = append(result.Errors, models.BinImportRowError{Row: row, DeviceID: deviceID, Message: message})
+}
+
+// binCSVColumns maps header names to their index in a record
+type binCSVColumns map[string]int
+
+// value returns the trimmed value of the named column, or "" if absent
+func (cols binCSVColumns) value(record []string, name string) string {
+	i, ok := cols[name]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// parseBinCSVHeader indexes the header row and checks required columns are present
+func parseBinCSVHeader(header []string) (binCSVColumns, error) {
+	columns := make(binCSVColumns, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+
+	var missing []string
+	for _, name := range binImportRequiredColumns {
+		if _, ok := columns[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("CSV header is missing required columns: %s", strings.Join(missing, ", "))
+	}
+
+	return columns, nil
+}
+
+// parseBinCSVRow validates an import row and converts it to a bin
+func parseBinCSVRow(columns binCSVColumns, record []string) (*models.Bin, error) {
+	bin := &models.Bin{
+		DeviceID:  columns.value(record, "device_id"),
+		WasteType: columns.value(record, "waste_type"),
+		IsActive:  true,
+
+		CollectionThreshold: models.DefaultCollectionThreshold,
+		AlertThreshold:      models.DefaultAlertThreshold,
+	}
+	if bin.DeviceID == "" {
+		return nil, errors.New("device_id is required")
+	}
+	if bin.WasteType == "" {
+		return nil, errors.New("waste_type is required")
+	}
+	if !validation.IsWasteType(bin.WasteType) {
+		return nil, errors.New("waste_type must be one of: " + strings.Join(validation.WasteTypes, ", "))
+	}
+
+	var err error
+	bin.Latitude, err = strconv.ParseFloat(columns.value(record, "latitude"), 64)
+	if err != nil || !validation.IsLatitude(bin.Latitude) {
+		return nil, errors.New("latitude must be a number between -90 and 90")
+	}
+	bin.Longitude, err = strconv.ParseFloat(columns.value(record, "longitude"), 64)
+	if err != nil || !validation.IsLongitude(bin.Longitude) {
+		return nil, errors.New("longitude must be a number between -180 and 180")
+	}
+	bin.CapacityLiters, err = strconv.Atoi(columns.value(record, "capacity_liters"))
+	if err != nil || bin.CapacityLiters <= 0 {
+		return nil, errors.New("capacity_liters must be a positive integer")
+	}
+
+	if name := columns.value(record, "location_name"); name != "" {
+		bin.LocationName = &name
+	}
+	if value := columns.value(record, "company_id"); value != "" {
+		companyID, err := uuid.Parse(value)
+		if err != nil {
+			return nil, errors.New("company_id must be a UUID")
+		}
+		bin.CompanyID = &companyID
+	}
+	if value := columns.value(record, "collection_threshold"); value != "" {
+		bin.CollectionThreshold, err = strconv.Atoi(value)
+		if err != nil || bin.CollectionThreshold < 1 || bin.CollectionThreshold > 100 {
+			return nil, errors.New("collection_threshold must be an integer between 1 and 100")
+		}
+	}
+	if value := columns.value(record, "alert_threshold"); value != "" {
+		bin.AlertThreshold, err = strconv.Atoi(value)
+		if err != nil || bin.AlertThreshold < 1 || bin.AlertThreshold > 100 {
+			return nil, errors.New("alert_threshold must be an integer between 1 and 100")
+		}
+	}
+
+	return bin, nil
+}
+
+// binCSVRecord formats a bin in binExportColumns order
+func binCSVRecord(bin *models.Bin) []string {
+	var locationName, companyID string
+	if bin.LocationName != nil {
+		locationName = *bin.LocationName
+	}
+	if bin.CompanyID != nil {
+		companyID = bin.CompanyID.String()
+	}
+
+	return []string{
+		bin.ID.String(),
+		bin.DeviceID,
+		locationName,
+		strconv.FormatFloat(bin.Latitude, 'f', -1, 64),
+		strconv.FormatFloat(bin.Longitude, 'f', -1, 64),
+		bin.WasteType,
+		strconv.Itoa(bin.CapacityLiters),
+		companyID,
+		strconv.Itoa(bin.FillLevel),
+		strconv.FormatBool(bin.IsActive),
+		bin.LastUpdatedAt.UTC().Format(time.RFC3339),
+		bin.CreatedAt.UTC().Format(time.RFC3339),
+		strconv.Itoa(bin.CollectionThreshold),
+		strconv.Itoa(bin.AlertThreshold),
+	}
+}
@@ -2,22 +2,33 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/smartwaste/backend/internal/models"
 	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/internal/services"
 	"github.com/smartwaste/backend/pkg/utils"
 )
 
+// maxNearbyRadiusM caps the radius of nearby bin searches
+const maxNearbyRadiusM = 50000
+
 // BinHandler handles bin-related HTTP requests
 type BinHandler struct {
-	repo *repository.BinRepository
+	repo              *repository.BinRepository
+	predictionService *services.PredictionService
+	settings          *services.SettingsService
 }
 
 // NewBinHandler creates a new BinHandler
-func NewBinHandler(repo *repository.BinRepository) *BinHandler {
-	return &BinHandler{repo: repo}
+func NewBinHandler(repo *repository.BinRepository, predictionService *services.PredictionService, settings *services.SettingsService) *BinHandler {
+	return &BinHandler{
+		repo:              repo,
+		predictionService: predictionService,
+		settings:          settings,
+	}
 }
 
 // GetBin retrieves a bin by ID
@@ -38,7 +49,7 @@ func (h *BinHandler) GetBin(c *gin.Context) {
 
 	bin, err := h.repo.GetByID(c.Request.Context(), id)
 	if err != nil {
-		utils.InternalError(c, "Failed to retrieve bin")
+		abortWithError(c, err, "Failed to retrieve bin")
 		return
 	}
 
@@ -50,6 +61,41 @@ func (h *BinHandler) GetBin(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, bin.ToResponse())
 }
 
+// GetPrediction estimates when a bin will reach the collection threshold
+// @Summary Get bin fill prediction
+// @Tags Bins
+// @Produce json
+// @Param id path string true "Bin ID"
+// @Success 200 {object} models.BinPrediction
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/bins/{id}/prediction [get]
+func (h *BinHandler) GetPrediction(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		utils.BadRequest(c, "Invalid bin ID format")
+		return
+	}
+
+	bin, err := h.repo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve bin")
+		return
+	}
+	if bin == nil {
+		utils.NotFound(c, "Bin not found")
+		return
+	}
+
+	prediction, err := h.predictionService.Predict(c.Request.Context(), bin)
+	if err != nil {
+		abortWithError(c, err, "Failed to compute prediction")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, prediction)
+}
+
 // CreateBin creates a new bin
 // @Summary Register a new bin
 // @Tags Bins
@@ -62,14 +108,14 @@ func (h *BinHandler) GetBin(c *gin.Context) {
 func (h *BinHandler) CreateBin(c *gin.Context) {
 	var req models.CreateBinRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationError(c, err.Error())
+		validationError(c, err)
 		return
 	}
 
 	// Check if device ID already exists
 	existing, err := h.repo.GetByDeviceID(c.Request.Context(), req.DeviceID)
 	if err != nil {
-		utils.InternalError(c, "Failed to check existing bin")
+		abortWithError(c, err, "Failed to check existing bin")
 		return
 	}
 	if existing != nil {
@@ -85,11 +131,23 @@ func (h *BinHandler) CreateBin(c *gin.Context) {
 		WasteType:      req.WasteType,
 		CapacityLiters: req.CapacityLiters,
 		CompanyID:      req.CompanyID,
+		MaxWeightKg:    req.MaxWeightKg,
+		DeviceGroup:    req.DeviceGroup,
+		HardwareSerial: req.HardwareSerial,
 		IsActive:       true,
+
+		CollectionThreshold: models.DefaultCollectionThreshold,
+		AlertThreshold:      models.DefaultAlertThreshold,
+	}
+	if req.CollectionThreshold != nil {
+		bin.CollectionThreshold = *req.CollectionThreshold
+	}
+	if req.AlertThreshold != nil {
+		bin.AlertThreshold = *req.AlertThreshold
 	}
 
 	if err := h.repo.Create(c.Request.Context(), bin); err != nil {
-		utils.InternalError(c, "Failed to create bin")
+		abortWithError(c, err, "Failed to create bin")
 		return
 	}
 
@@ -115,13 +173,13 @@ func (h *BinHandler) UpdateBin(c *gin.Context) {
 
 	var req models.UpdateBinRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationError(c, err.Error())
+		validationError(c, err)
 		return
 	}
 
 	bin, err := h.repo.GetByID(c.Request.Context(), id)
 	if err != nil {
-		utils.InternalError(c, "Failed to retrieve bin")
+		abortWithError(c, err, "Failed to retrieve bin")
 		return
 	}
 	if bin == nil {
@@ -151,9 +209,76 @@ func (h *BinHandler) UpdateBin(c *gin.Context) {
 	if req.CompanyID != nil {
 		bin.CompanyID = req.CompanyID
 	}
+	if req.MaxWeightKg != nil {
+		bin.MaxWeightKg = req.MaxWeightKg
+	}
+	if req.DeviceGroup != nil {
+		bin.DeviceGroup = req.DeviceGroup
+	}
+	if req.HardwareSerial != nil {
+		bin.HardwareSerial = req.HardwareSerial
+	}
+	if req.CollectionThreshold != nil {
+		bin.CollectionThreshold = *req.CollectionThreshold
+	}
+	if req.AlertThreshold != nil {
+		bin.AlertThreshold = *req.AlertThreshold
+	}
+
+	if err := h.repo.Update(c.Request.Context(), bin); err != nil {
+		abortWithError(c, err, "Failed to update bin")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, bin.ToResponse())
+}
+
+// UpdateBinThresholds sets the collection and alert thresholds of a bin
+// @Summary Update bin thresholds
+// @Tags Bins
+// @Accept json
+// @Produce json
+// @Param id path string true "Bin ID"
+// @Param thresholds body models.UpdateBinThresholdsRequest true "Thresholds"
+// @Success 200 {object} models.BinResponse
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/bins/{id}/thresholds [put]
+func (h *BinHandler) UpdateBinThresholds(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid bin ID format")
+		return
+	}
+
+	var req models.UpdateBinThresholdsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+	if req.CollectionThreshold == nil && req.AlertThreshold == nil {
+		utils.ValidationError(c, "Provide collection_threshold, alert_threshold or both")
+		return
+	}
+
+	bin, err := h.repo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve bin")
+		return
+	}
+	if bin == nil {
+		utils.NotFound(c, "Bin not found")
+		return
+	}
+
+	if req.CollectionThreshold != nil {
+		bin.CollectionThreshold = *req.CollectionThreshold
+	}
+	if req.AlertThreshold != nil {
+		bin.AlertThreshold = *req.AlertThreshold
+	}
 
 	if err := h.repo.Update(c.Request.Context(), bin); err != nil {
-		utils.InternalError(c, "Failed to update bin")
+		abortWithError(c, err, "Failed to update bin thresholds")
 		return
 	}
 
@@ -164,18 +289,35 @@ func (h *BinHandler) UpdateBin(c *gin.Context) {
 // @Summary List bins
 // @Tags Bins
 // @Produce json
+// @Param zone_id query string false "Only the bins of this zone"
 // @Param page query int false "Page number" default(1)
 // @Param per_page query int false "Items per page" default(20)
+// @Param cursor query string false "Continue after the next_cursor of a previous page"
 // @Success 200 {array} models.BinResponse
 // @Router /api/v1/bins [get]
 func (h *BinHandler) ListBins(c *gin.Context) {
-	page := getQueryInt(c, "page", 1)
-	perPage := getQueryInt(c, "per_page", 20)
-	offset := (page - 1) * perPage
+	pagination, ok := parsePage(c, 20)
+	if !ok {
+		return
+	}
 
-	bins, err := h.repo.List(c.Request.Context(), perPage, offset)
+	var (
+		bins   []models.Bin
+		result repository.PageResult
+		err    error
+	)
+	if value := c.Query("zone_id"); value != "" {
+		zoneID, parseErr := uuid.Parse(value)
+		if parseErr != nil {
+			utils.BadRequest(c, "Invalid zone ID format")
+			return
+		}
+		bins, result, err = h.repo.ListByZone(c.Request.Context(), zoneID, pagination.page)
+	} else {
+		bins, result, err = h.repo.List(c.Request.Context(), pagination.page)
+	}
 	if err != nil {
-		utils.InternalError(c, "Failed to retrieve bins")
+		listError(c, err, "Failed to retrieve bins")
 		return
 	}
 
@@ -184,25 +326,30 @@ func (h *BinHandler) ListBins(c *gin.Context) {
 		responses[i] = *b.ToResponse()
 	}
 
-	utils.SuccessResponseWithPagination(c, responses, &utils.Pagination{
-		Page:    page,
-		PerPage: perPage,
-	})
+	utils.SuccessResponseWithPagination(c, responses, pagination.meta(result))
 }
 
-// GetBinsNeedingCollection retrieves bins with fill level above threshold
+// GetBinsNeedingCollection retrieves bins at or above their collection threshold
 // @Summary Get bins needing collection
 // @Tags Bins
 // @Produce json
-// @Param threshold query int false "Fill level threshold" default(80)
+// @Param threshold query int false "Fill level threshold overriding each bin's collection_threshold"
 // @Success 200 {array} models.BinResponse
 // @Router /api/v1/bins/needs-collection [get]
 func (h *BinHandler) GetBinsNeedingCollection(c *gin.Context) {
-	threshold := getQueryInt(c, "threshold", 80)
+	var threshold *int
+	if value := c.Query("threshold"); value != "" {
+		t, err := strconv.Atoi(value)
+		if err != nil || t < 1 || t > 100 {
+			utils.BadRequest(c, "threshold must be an integer between 1 and 100")
+			return
+		}
+		threshold = &t
+	}
 
 	bins, err := h.repo.GetBinsNeedingCollection(c.Request.Context(), threshold)
 	if err != nil {
-		utils.InternalError(c, "Failed to retrieve bins")
+		abortWithError(c, err, "Failed to retrieve bins")
 		return
 	}
 
@@ -218,6 +365,120 @@ func (h *BinHandler) GetBinsNeedingCollection(c *gin.Context) {
 	})
 }
 
+// GetLowBatteryBins retrieves bins whose sensor battery is below threshold
+// @Summary Get bins with low sensor battery
+// @Tags Bins
+// @Produce json
+// @Param threshold query int false "Battery level threshold (defaults to the low_battery setting)"
+// @Success 200 {array} models.BinResponse
+// @Router /api/v1/bins/low-battery [get]
+func (h *BinHandler) GetLowBatteryBins(c *gin.Context) {
+	threshold := getQueryInt(c, "threshold", h.settings.Current().Thresholds.LowBattery)
+
+	bins, err := h.repo.GetLowBatteryBins(c.Request.Context(), threshold)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve bins")
+		return
+	}
+
+	responses := make([]models.BinResponse, len(bins))
+	for i, b := range bins {
+		responses[i] = *b.ToResponse()
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"threshold": threshold,
+		"count":     len(bins),
+		"bins":      responses,
+	})
+}
+
+// GetOfflineBins retrieves bins whose sensors have stopped reporting
+// @Summary Get offline bins
+// @Tags Bins
+// @Produce json
+// @Success 200 {array} models.BinResponse
+// @Router /api/v1/bins/offline [get]
+func (h *BinHandler) GetOfflineBins(c *gin.Context) {
+	bins, err := h.repo.GetOfflineBins(c.Request.Context())
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve bins")
+		return
+	}
+
+	responses := make([]models.BinResponse, len(bins))
+	for i, b := range bins {
+		responses[i] = *b.ToResponse()
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"count": len(bins),
+		"bins":  responses,
+	})
+}
+
+// FindNearbyBins finds available bins close to a location
+// @Summary Find nearby bins
+// @Tags Bins
+// @Produce json
+// @Param lat query number true "Latitude"
+// @Param lng query number true "Longitude"
+// @Param radius_m query number false "Search radius in meters" default(1000)
+// @Param waste_type query string false "Only bins accepting this waste type"
+// @Param limit query int false "Maximum number of bins" default(20)
+// @Success 200 {array} models.NearbyBinResponse
+// @Failure 400 {object} utils.APIError
+// @Router /api/v1/bins/nearby [get]
+func (h *BinHandler) FindNearbyBins(c *gin.Context) {
+	lat, err := strconv.ParseFloat(c.Query("lat"), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		utils.BadRequest(c, "lat must be a latitude between -90 and 90")
+		return
+	}
+	lng, err := strconv.ParseFloat(c.Query("lng"), 64)
+	if err != nil || lng < -180 || lng > 180 {
+		utils.BadRequest(c, "lng must be a longitude between -180 and 180")
+		return
+	}
+
+	radius := 1000.0
+	if value := c.Query("radius_m"); value != "" {
+		radius, err = strconv.ParseFloat(value, 64)
+		if err != nil || radius <= 0 || radius > maxNearbyRadiusM {
+			utils.BadRequest(c, "radius_m must be between 0 and 50000")
+			return
+		}
+	}
+
+	limit := getQueryInt(c, "limit", 20)
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	bins, err := h.repo.FindNearby(c.Request.Context(), models.NearbyBinQuery{
+		Latitude:  lat,
+		Longitude: lng,
+		RadiusM:   radius,
+		WasteType: c.Query("waste_type"),
+		Limit:     limit,
+	})
+	if err != nil {
+		abortWithError(c, err, "Failed to search bins")
+		return
+	}
+
+	responses := make([]models.NearbyBinResponse, len(bins))
+	for i, b := range bins {
+		responses[i] = *b.ToResponse()
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"radius_m": radius,
+		"count":    len(bins),
+		"bins":     responses,
+	})
+}
+
 // GetBinStatistics retrieves bin statistics
 // @Summary Get bin statistics
 // @Tags Bins
@@ -227,7 +488,7 @@ func (h *BinHandler) GetBinsNeedingCollection(c *gin.Context) {
 func (h *BinHandler) GetBinStatistics(c *gin.Context) {
 	stats, err := h.repo.GetStatistics(c.Request.Context())
 	if err != nil {
-		utils.InternalError(c, "Failed to retrieve bin statistics")
+		abortWithError(c, err, "Failed to retrieve bin statistics")
 		return
 	}
 
@@ -250,7 +511,7 @@ func (h *BinHandler) DeleteBin(c *gin.Context) {
 	}
 
 	if err := h.repo.Delete(c.Request.Context(), id); err != nil {
-		utils.InternalError(c, "Failed to delete bin")
+		abortWithError(c, err, "Failed to delete bin")
 		return
 	}
 
@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/internal/services"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// CollectionHandler handles collection-related HTTP requests
+type CollectionHandler struct {
+	collectionRepo *repository.CollectionRepository
+	binRepo        *repository.BinRepository
+	driverRepo     *repository.DriverRepository
+	collectionSvc  *services.CollectionService
+}
+
+// NewCollectionHandler creates a new CollectionHandler
+func NewCollectionHandler(
+	collectionRepo *repository.CollectionRepository,
+	binRepo *repository.BinRepository,
+	driverRepo *repository.DriverRepository,
+	collectionSvc *services.CollectionService,
+) *CollectionHandler {
+	return &CollectionHandler{
+		collectionRepo: collectionRepo,
+		binRepo:        binRepo,
+		driverRepo:     driverRepo,
+		collectionSvc:  collectionSvc,
+	}
+}
+
+// CreateCollection schedules a new collection for a bin
+// @Summary Create collection
+// @Tags Collections
+// @Accept json
+// @Produce json
+// @Param collection body models.CreateCollectionRequest true "Collection data"
+// @Success 201 {object} models.CollectionResponse
+// @Failure 400 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/collections [post]
+func (h *CollectionHandler) CreateCollection(c *gin.Context) {
+	var req models.CreateCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	bin, err := h.binRepo.GetByID(c.Request.Context(), req.BinID)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve bin")
+		return
+	}
+	if bin == nil {
+		utils.NotFound(c, "Bin not found")
+		return
+	}
+
+	driver, err := h.driverRepo.GetByID(c.Request.Context(), req.DriverID)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve driver")
+		return
+	}
+	if driver == nil {
+		utils.NotFound(c, "Driver not found")
+		return
+	}
+
+	collection := &models.Collection{
+		BinID:           req.BinID,
+		DriverID:        req.DriverID,
+		UserID:          req.UserID,
+		FillLevelBefore: bin.FillLevel,
+		Status:          models.CollectionStatusPending,
+	}
+
+	if err := h.collectionRepo.Create(c.Request.Context(), collection); err != nil {
+		if repository.IsForeignKeyViolation(err) {
+			utils.NotFound(c, "User not found")
+			return
+		}
+		abortWithError(c, err, "Failed to create collection")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, collection.ToResponse())
+}
+
+// ListCollections retrieves collections with optional filters
+// @Summary List collections
+// @Tags Collections
+// @Produce json
+// @Param driver_id query string false "Filter by driver ID"
+// @Param bin_id query string false "Filter by bin ID"
+// @Param status query string false "Filter by status"
+// @Param from query string false "Started on or after (YYYY-MM-DD or RFC3339)"
+// @Param to query string false "Started before (YYYY-MM-DD inclusive, or RFC3339)"
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Param cursor query string false "Continue after the next_cursor of a previous page"
+// @Success 200 {array} models.CollectionResponse
+// @Failure 400 {object} utils.APIError
+// @Router /api/v1/collections [get]
+func (h *CollectionHandler) ListCollections(c *gin.Context) {
+	var filter models.CollectionFilter
+
+	if value := c.Query("driver_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(c, "Invalid driver ID format")
+			return
+		}
+		filter.DriverID = &id
+	}
+	if value := c.Query("bin_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(c, "Invalid bin ID format")
+			return
+		}
+		filter.BinID = &id
+	}
+	if value := c.Query("status"); value != "" {
+		status := models.CollectionStatus(value)
+		if !status.IsValid() {
+			utils.BadRequest(c, "Invalid collection status")
+			return
+		}
+		filter.Status = &status
+	}
+	if value := c.Query("from"); value != "" {
+		from, _, err := parseDateParam(value)
+		if err != nil {
+			utils.BadRequest(c, "Invalid from date")
+			return
+		}
+		filter.From = &from
+	}
+	if value := c.Query("to"); value != "" {
+		to, dateOnly, err := parseDateParam(value)
+		if err != nil {
+			utils.BadRequest(c, "Invalid to date")
+			return
+		}
+		// A bare date includes the whole day
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+		filter.To = &to
+	}
+
+	// Drivers only see their own collections
+	if claims, ok := currentClaims(c); ok && claims.Role == models.RoleDriver {
+		filter.DriverID = &claims.SubjectID
+	}
+
+	pagination, ok := parsePage(c, 20)
+	if !ok {
+		return
+	}
+
+	collections, result, err := h.collectionRepo.ListFiltered(c.Request.Context(), filter, pagination.page)
+	if err != nil {
+		listError(c, err, "Failed to retrieve collections")
+		return
+	}
+
+	responses := make([]models.CollectionResponse, len(collections))
+	for i, col := range collections {
+		responses[i] = *col.ToResponse()
+	}
+
+	utils.SuccessResponseWithPagination(c, responses, pagination.meta(result))
+}
+
+// GetCollection retrieves a collection by ID
+// @Summary Get collection by ID
+// @Tags Collections
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Success 200 {object} models.CollectionResponse
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/collections/{id} [get]
+func (h *CollectionHandler) GetCollection(c *gin.Context) {
+	collection, ok := h.loadCollection(c)
+	if !ok {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, collection.ToResponse())
+}
+
+// CompleteCollection completes a collection and marks the bin as emptied
+// @Summary Complete collection
+// @Tags Collections
+// @Accept json
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Param collection body models.CompleteCollectionRequest true "Completion data"
+// @Success 200 {object} models.CollectionResponse
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/collections/{id}/complete [post]
+func (h *CollectionHandler) CompleteCollection(c *gin.Context) {
+	var req models.CompleteCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	collection, ok := h.loadCollection(c)
+	if !ok {
+		return
+	}
+	if !collection.Status.IsOpen() {
+		utils.Conflict(c, "Collection is already "+string(collection.Status))
+		return
+	}
+
+	updated, err := h.collectionSvc.Complete(c.Request.Context(), collection, &req)
+	if err != nil && writeUploadError(c, err, "") {
+		return
+	}
+	if err == nil && updated == nil {
+		err = repository.ErrNotFound
+	}
+	if err != nil {
+		abortWithError(c, err, "Failed to complete collection")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, updated.ToResponse())
+}
+
+// CancelCollection cancels an open collection
+// @Summary Cancel collection
+// @Tags Collections
+// @Accept json
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Param collection body models.CancelCollectionRequest false "Cancellation reason"
+// @Success 200 {object} models.CollectionResponse
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/collections/{id}/cancel [post]
+func (h *CollectionHandler) CancelCollection(c *gin.Context) {
+	var req models.CancelCollectionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			validationError(c, err)
+			return
+		}
+	}
+
+	collection, ok := h.loadCollection(c)
+	if !ok {
+		return
+	}
+	if !collection.Status.IsOpen() {
+		utils.Conflict(c, "Collection is already "+string(collection.Status))
+		return
+	}
+
+	if err := h.collectionRepo.Cancel(c.Request.Context(), collection.ID, req.Reason); err != nil {
+		abortWithError(c, err, "Failed to cancel collection")
+		return
+	}
+
+	collection.Status = models.CollectionStatusCancelled
+	if req.Reason != nil {
+		collection.Notes = req.Reason
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, collection.ToResponse())
+}
+
+// loadCollection resolves the :id collection and checks that drivers only
+// access collections assigned to them. It writes the error response itself.
+func (h *CollectionHandler) loadCollection(c *gin.Context) (*models.Collection, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid collection ID format")
+		return nil, false
+	}
+
+	collection, err := h.collectionRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve collection")
+		return nil, false
+	}
+	if collection == nil {
+		utils.NotFound(c, "Collection not found")
+		return nil, false
+	}
+
+	if claims, ok := currentClaims(c); ok && claims.Role == models.RoleDriver && claims.SubjectID != collection.DriverID {
+		utils.Forbidden(c, "You are not assigned to this collection")
+		return nil, false
+	}
+
+	return collection, true
+}
+
+// parseDateParam parses a YYYY-MM-DD or RFC3339 query value and reports
+// whether it was a bare date
+func parseDateParam(value string) (time.Time, bool, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	return t, false, err
+}
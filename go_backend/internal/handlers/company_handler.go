@@ -1,20 +1,27 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/google/uuid"
 	"github.com/smartwaste/backend/internal/models"
 	"github.com/smartwaste/backend/internal/repository"
 	"github.com/smartwaste/backend/internal/services"
 	"github.com/smartwaste/backend/pkg/utils"
+	"github.com/smartwaste/pkg/validation"
 )
 
 // CompanyHandler handles company-related HTTP requests
 type CompanyHandler struct {
 	companyRepo    *repository.CompanyRepository
 	pricingRepo    *repository.PricingRepository
+	binRepo        *repository.BinRepository
 	valuationSvc   *services.ValuationService
 }
 
@@ -22,11 +29,13 @@ type CompanyHandler struct {
 func NewCompanyHandler(
 	companyRepo *repository.CompanyRepository,
 	pricingRepo *repository.PricingRepository,
+	binRepo *repository.BinRepository,
 	valuationSvc *services.ValuationService,
 ) *CompanyHandler {
 	return &CompanyHandler{
 		companyRepo:  companyRepo,
 		pricingRepo:  pricingRepo,
+		binRepo:      binRepo,
 		valuationSvc: valuationSvc,
 	}
 }
@@ -49,7 +58,7 @@ func (h *CompanyHandler) GetCompany(c *gin.Context) {
 
 	company, err := h.companyRepo.GetByID(c.Request.Context(), id)
 	if err != nil {
-		utils.InternalError(c, "Failed to retrieve company")
+		abortWithError(c, err, "Failed to retrieve company")
 		return
 	}
 
@@ -73,14 +82,14 @@ func (h *CompanyHandler) GetCompany(c *gin.Context) {
 func (h *CompanyHandler) CreateCompany(c *gin.Context) {
 	var req models.CreateCompanyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationError(c, err.Error())
+		validationError(c, err)
 		return
 	}
 
 	// Check if email already exists
 	existing, err := h.companyRepo.GetByEmail(c.Request.Context(), req.Email)
 	if err != nil {
-		utils.InternalError(c, "Failed to check existing company")
+		abortWithError(c, err, "Failed to check existing company")
 		return
 	}
 	if existing != nil {
@@ -100,7 +109,7 @@ func (h *CompanyHandler) CreateCompany(c *gin.Context) {
 	}
 
 	if err := h.companyRepo.Create(c.Request.Context(), company); err != nil {
-		utils.InternalError(c, "Failed to create company")
+		abortWithError(c, err, "Failed to create company")
 		return
 	}
 
@@ -126,13 +135,13 @@ func (h *CompanyHandler) UpdateCompany(c *gin.Context) {
 
 	var req models.UpdateCompanyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationError(c, err.Error())
+		validationError(c, err)
 		return
 	}
 
 	company, err := h.companyRepo.GetByID(c.Request.Context(), id)
 	if err != nil {
-		utils.InternalError(c, "Failed to retrieve company")
+		abortWithError(c, err, "Failed to retrieve company")
 		return
 	}
 	if company == nil {
@@ -167,7 +176,7 @@ func (h *CompanyHandler) UpdateCompany(c *gin.Context) {
 	}
 
 	if err := h.companyRepo.Update(c.Request.Context(), company); err != nil {
-		utils.InternalError(c, "Failed to update company")
+		abortWithError(c, err, "Failed to update company")
 		return
 	}
 
@@ -180,16 +189,18 @@ func (h *CompanyHandler) UpdateCompany(c *gin.Context) {
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param per_page query int false "Items per page" default(20)
+// @Param cursor query string false "Continue after the next_cursor of a previous page"
 // @Success 200 {array} models.CompanyResponse
 // @Router /api/v1/companies [get]
 func (h *CompanyHandler) ListCompanies(c *gin.Context) {
-	page := getQueryInt(c, "page", 1)
-	perPage := getQueryInt(c, "per_page", 20)
-	offset := (page - 1) * perPage
+	pagination, ok := parsePage(c, 20)
+	if !ok {
+		return
+	}
 
-	companies, err := h.companyRepo.List(c.Request.Context(), perPage, offset)
+	companies, result, err := h.companyRepo.List(c.Request.Context(), pagination.page)
 	if err != nil {
-		utils.InternalError(c, "Failed to retrieve companies")
+		listError(c, err, "Failed to retrieve companies")
 		return
 	}
 
@@ -198,10 +209,7 @@ func (h *CompanyHandler) ListCompanies(c *gin.Context) {
 		responses[i] = *comp.ToResponse()
 	}
 
-	utils.SuccessResponseWithPagination(c, responses, &utils.Pagination{
-		Page:    page,
-		PerPage: perPage,
-	})
+	utils.SuccessResponseWithPagination(c, responses, pagination.meta(result))
 }
 
 // DeleteCompany deletes a company
@@ -219,13 +227,62 @@ func (h *CompanyHandler) DeleteCompany(c *gin.Context) {
 	}
 
 	if err := h.companyRepo.Delete(c.Request.Context(), id); err != nil {
-		utils.InternalError(c, "Failed to delete company")
+		abortWithError(c, err, "Failed to delete company")
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
 
+// UpdateBinThresholds sets the collection and alert thresholds of every bin of a company
+// @Summary Update company bin thresholds
+// @Tags Companies
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param thresholds body models.UpdateBinThresholdsRequest true "Thresholds"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/companies/{id}/bin-thresholds [put]
+func (h *CompanyHandler) UpdateBinThresholds(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid company ID format")
+		return
+	}
+
+	var req models.UpdateBinThresholdsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+	if req.CollectionThreshold == nil && req.AlertThreshold == nil {
+		utils.ValidationError(c, "Provide collection_threshold, alert_threshold or both")
+		return
+	}
+
+	company, err := h.companyRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve company")
+		return
+	}
+	if company == nil {
+		utils.NotFound(c, "Company not found")
+		return
+	}
+
+	updated, err := h.binRepo.UpdateThresholdsByCompany(c.Request.Context(), id, req.CollectionThreshold, req.AlertThreshold)
+	if err != nil {
+		abortWithError(c, err, "Failed to update bin thresholds")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"company_id":   id,
+		"bins_updated": updated,
+	})
+}
+
 // --- Pricing Rules ---
 
 // GetPricingRule retrieves a pricing rule by ID
@@ -245,7 +302,7 @@ func (h *CompanyHandler) GetPricingRule(c *gin.Context) {
 
 	rule, err := h.pricingRepo.GetByID(c.Request.Context(), id)
 	if err != nil {
-		utils.InternalError(c, "Failed to retrieve pricing rule")
+		abortWithError(c, err, "Failed to retrieve pricing rule")
 		return
 	}
 
@@ -268,30 +325,34 @@ func (h *CompanyHandler) GetPricingRule(c *gin.Context) {
 func (h *CompanyHandler) CreatePricingRule(c *gin.Context) {
 	var req models.CreatePricingRuleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationError(c, err.Error())
+		validationError(c, err)
 		return
 	}
 
-	rule := &models.PricingRule{
-		WasteType:   req.WasteType,
-		Condition:   req.Condition,
-		PricePerKg:  req.PricePerKg,
-		Currency:    req.Currency,
-		MinWeightKg: req.MinWeightKg,
-		MaxWeightKg: req.MaxWeightKg,
-		CompanyID:   req.CompanyID,
-		IsActive:    true,
+	if req.CompanyID == nil {
+		req.CompanyID = principalCompany(c)
+	}
+	if !requireOwnCompany(c, req.CompanyID) {
+		return
+	}
+
+	rule, err := newPricingRule(&req, time.Now())
+	if err != nil {
+		validationError(c, err)
+		return
 	}
 
 	if err := h.pricingRepo.Create(c.Request.Context(), rule); err != nil {
-		utils.InternalError(c, "Failed to create pricing rule")
+		abortWithError(c, err, "Failed to create pricing rule")
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusCreated, rule.ToResponse())
 }
 
-// UpdatePricingRule updates a pricing rule
+// UpdatePricingRule changes a pricing rule. A version already in effect is
+// kept for past valuations and superseded by a new version from effective_from;
+// a version that has not taken effect yet is changed in place.
 // @Summary Update pricing rule
 // @Tags Pricing Rules
 // @Accept json
@@ -299,6 +360,7 @@ func (h *CompanyHandler) CreatePricingRule(c *gin.Context) {
 // @Param id path string true "Pricing Rule ID"
 // @Param rule body models.UpdatePricingRuleRequest true "Pricing rule data"
 // @Success 200 {object} models.PricingRuleResponse
+// @Failure 409 {object} utils.APIError
 // @Router /api/v1/pricing-rules/{id} [put]
 func (h *CompanyHandler) UpdatePricingRule(c *gin.Context) {
 	idParam := c.Param("id")
@@ -310,49 +372,368 @@ func (h *CompanyHandler) UpdatePricingRule(c *gin.Context) {
 
 	var req models.UpdatePricingRuleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationError(c, err.Error())
+		validationError(c, err)
 		return
 	}
 
 	rule, err := h.pricingRepo.GetByID(c.Request.Context(), id)
 	if err != nil {
-		utils.InternalError(c, "Failed to retrieve pricing rule")
+		abortWithError(c, err, "Failed to retrieve pricing rule")
 		return
 	}
 	if rule == nil {
 		utils.NotFound(c, "Pricing rule not found")
 		return
 	}
+	if !requireOwnCompany(c, rule.CompanyID) {
+		return
+	}
+
+	latest, err := h.pricingRepo.LatestVersion(c.Request.Context(), rule.LineageID)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve pricing rule")
+		return
+	}
+	if latest.ID != rule.ID {
+		utils.Conflict(c, fmt.Sprintf("Only the latest version of a pricing rule can be changed; it is version %d (%s)", latest.Version, latest.ID))
+		return
+	}
+
+	if req.IsActive != nil && !*req.IsActive {
+		if err := h.pricingRepo.Delete(c.Request.Context(), rule.ID); err != nil {
+			abortWithError(c, err, "Failed to update pricing rule")
+			return
+		}
+		if rule, err = h.pricingRepo.GetByID(c.Request.Context(), rule.ID); err != nil {
+			abortWithError(c, err, "Failed to retrieve pricing rule")
+			return
+		}
+		utils.SuccessResponse(c, http.StatusOK, rule.ToResponse())
+		return
+	}
 
 	// Update fields
+	next := *rule
 	if req.WasteType != nil {
-		rule.WasteType = *req.WasteType
+		next.WasteType = *req.WasteType
 	}
 	if req.Condition != nil {
-		rule.Condition = *req.Condition
+		next.Condition = *req.Condition
 	}
 	if req.PricePerKg != nil {
-		rule.PricePerKg = *req.PricePerKg
+		next.PricePerKg = *req.PricePerKg
 	}
 	if req.Currency != nil {
-		rule.Currency = *req.Currency
+		next.Currency = *req.Currency
 	}
 	if req.MinWeightKg != nil {
-		rule.MinWeightKg = *req.MinWeightKg
+		next.MinWeightKg = *req.MinWeightKg
 	}
 	if req.MaxWeightKg != nil {
-		rule.MaxWeightKg = req.MaxWeightKg
+		next.MaxWeightKg = req.MaxWeightKg
 	}
-	if req.IsActive != nil {
-		rule.IsActive = *req.IsActive
+	if req.PricingModel != nil {
+		next.PricingModel = *req.PricingModel
+	}
+	if req.Tiers != nil {
+		next.Tiers = req.Tiers
+	}
+	if req.ConditionMultipliers != nil {
+		next.ConditionMultipliers = req.ConditionMultipliers
+	}
+	if req.MarketFactor != nil {
+		next.MarketFactor = *req.MarketFactor
+	}
+	now := time.Now()
+	if req.EffectiveTo != nil {
+		next.EffectiveTo = req.EffectiveTo
+	} else if next.EffectiveTo != nil && !next.EffectiveTo.After(now) {
+		// Changing a rule that has ended starts it again
+		next.EffectiveTo = nil
+	}
+	inPlace, err := revisePricingRule(rule, &next, req.EffectiveFrom, now)
+	if err != nil {
+		validationError(c, err)
+		return
 	}
 
-	if err := h.pricingRepo.Update(c.Request.Context(), rule); err != nil {
-		utils.InternalError(c, "Failed to update pricing rule")
+	if inPlace {
+		err = h.pricingRepo.Update(c.Request.Context(), &next)
+	} else {
+		err = h.pricingRepo.CreateVersion(c.Request.Context(), rule, &next)
+	}
+	if err != nil {
+		if repository.IsUniqueViolation(err) {
+			utils.Conflict(c, "The pricing rule was changed concurrently; retry with its latest version")
+			return
+		}
+		abortWithError(c, err, "Failed to update pricing rule")
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, rule.ToResponse())
+	utils.SuccessResponse(c, http.StatusOK, next.ToResponse())
+}
+
+// ListPricingRuleVersions retrieves every version of a pricing rule
+// @Summary List pricing rule versions
+// @Description The versions of the rule the given version belongs to, the first first
+// @Tags Pricing Rules
+// @Produce json
+// @Param id path string true "ID of any version of the pricing rule"
+// @Success 200 {array} models.PricingRuleResponse
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/pricing-rules/{id}/versions [get]
+func (h *CompanyHandler) ListPricingRuleVersions(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid pricing rule ID format")
+		return
+	}
+
+	rule, err := h.pricingRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve pricing rule")
+		return
+	}
+	if rule == nil {
+		utils.NotFound(c, "Pricing rule not found")
+		return
+	}
+
+	versions, err := h.pricingRepo.ListVersions(c.Request.Context(), rule.LineageID)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve pricing rule versions")
+		return
+	}
+
+	responses := make([]models.PricingRuleResponse, len(versions))
+	for i, v := range versions {
+		responses[i] = *v.ToResponse()
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, responses)
+}
+
+// BulkUpsertPricingRules creates or changes a company's pricing rules in one go
+// @Summary Bulk upsert company pricing rules
+// @Description Each row fully defines the company's rule of its waste type and condition and is matched to the existing one, which is versioned like a single update. Nothing is written unless every row is valid.
+// @Tags Pricing Rules
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param dry_run query bool false "Validate without writing rules"
+// @Param rules body models.BulkPricingRulesRequest true "Pricing rules"
+// @Success 200 {object} models.BulkPricingRulesResult
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/companies/{id}/pricing-rules/bulk [put]
+func (h *CompanyHandler) BulkUpsertPricingRules(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid company ID format")
+		return
+	}
+
+	var req models.BulkPricingRulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	company, err := h.companyRepo.GetByID(ctx, id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve company")
+		return
+	}
+	if company == nil {
+		utils.NotFound(c, "Company not found")
+		return
+	}
+
+	existing, err := h.pricingRepo.ListByCompany(ctx, id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve pricing rules")
+		return
+	}
+	// The latest version of each waste type and condition is the one revised
+	latest := make(map[string]*models.PricingRule, len(existing))
+	for i := range existing {
+		rule := &existing[i]
+		key := pricingRuleKey(rule.WasteType, rule.Condition)
+		if current, ok := latest[key]; !ok || rule.EffectiveFrom.After(current.EffectiveFrom) {
+			latest[key] = rule
+		}
+	}
+
+	result := &models.BulkPricingRulesResult{
+		DryRun:  c.Query("dry_run") == "true",
+		Total:   len(req.Rules),
+		Results: make([]models.BulkPricingRuleResult, len(req.Rules)),
+	}
+	changes := make([]models.PricingRuleChange, 0, len(req.Rules))
+	changeRows := make([]int, 0, len(req.Rules))
+	seen := make(map[string]int)
+	now := time.Now()
+
+	for i := range req.Rules {
+		row := &req.Rules[i]
+		row.WasteType = strings.TrimSpace(row.WasteType)
+		row.Condition = strings.TrimSpace(row.Condition)
+		row.Currency = strings.ToUpper(row.Currency)
+		res := &result.Results[i]
+		*res = models.BulkPricingRuleResult{Index: i, WasteType: row.WasteType, Condition: row.Condition}
+
+		fail := func(message string) {
+			res.Status = models.BulkRuleFailed
+			res.Error = message
+			result.Failed++
+		}
+		if err := binding.Validator.ValidateStruct(row); err != nil {
+			if res.Fields = validation.Translate(err); res.Fields != nil {
+				fail(validation.Summary(res.Fields))
+			} else {
+				fail(err.Error())
+			}
+			continue
+		}
+		if row.CompanyID != nil && *row.CompanyID != id {
+			fail("company_id must be left out or match the company")
+			continue
+		}
+		key := pricingRuleKey(row.WasteType, row.Condition)
+		if first, ok := seen[key]; ok {
+			fail(fmt.Sprintf("Duplicate waste_type and condition, first seen at index %d", first))
+			continue
+		}
+		seen[key] = i
+
+		row.CompanyID = &id
+		next, err := newPricingRule(row, now)
+		if err != nil {
+			fail(err.Error())
+			continue
+		}
+
+		current, ok := latest[key]
+		if !ok {
+			changes = append(changes, models.PricingRuleChange{Next: next})
+			changeRows = append(changeRows, i)
+			res.Status = models.BulkRuleCreated
+			res.Rule = next.ToResponse()
+			result.Created++
+			continue
+		}
+
+		if row.EffectiveFrom == nil && current.SamePricing(next) {
+			res.Status = models.BulkRuleUnchanged
+			res.Rule = current.ToResponse()
+			result.Unchanged++
+			continue
+		}
+		inPlace, err := revisePricingRule(current, next, row.EffectiveFrom, now)
+		if err != nil {
+			fail(err.Error())
+			continue
+		}
+		if inPlace {
+			next.ID = current.ID
+			next.LineageID = current.LineageID
+			next.Version = current.Version
+			next.CreatedAt = current.CreatedAt
+		}
+		changes = append(changes, models.PricingRuleChange{Current: current, Next: next, InPlace: inPlace})
+		changeRows = append(changeRows, i)
+		res.Status = models.BulkRuleUpdated
+		res.Rule = next.ToResponse()
+		result.Updated++
+	}
+
+	if result.Failed == 0 && !result.DryRun && len(changes) > 0 {
+		if err := h.pricingRepo.ApplyChanges(ctx, changes); err != nil {
+			if repository.IsUniqueViolation(err) {
+				utils.Conflict(c, "Pricing rules were changed concurrently; retry the upsert")
+				return
+			}
+			abortWithError(c, err, "Failed to save pricing rules")
+			return
+		}
+		// Report the rows with their saved ids, versions and timestamps
+		for i, change := range changes {
+			result.Results[changeRows[i]].Rule = change.Next.ToResponse()
+		}
+		result.Applied = true
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// newPricingRule builds and validates a pricing rule from a create request;
+// it takes effect now unless the request schedules it
+func newPricingRule(req *models.CreatePricingRuleRequest, now time.Time) (*models.PricingRule, error) {
+	rule := &models.PricingRule{
+		WasteType:            req.WasteType,
+		Condition:            req.Condition,
+		PricePerKg:           req.PricePerKg,
+		Currency:             req.Currency,
+		MinWeightKg:          req.MinWeightKg,
+		MaxWeightKg:          req.MaxWeightKg,
+		CompanyID:            req.CompanyID,
+		PricingModel:         req.PricingModel,
+		Tiers:                req.Tiers,
+		ConditionMultipliers: req.ConditionMultipliers,
+		MarketFactor:         1,
+		EffectiveFrom:        now,
+		EffectiveTo:          req.EffectiveTo,
+		IsActive:             true,
+	}
+	if rule.PricingModel == "" {
+		rule.PricingModel = models.PricingModelFlat
+	}
+	if req.MarketFactor != nil {
+		rule.MarketFactor = *req.MarketFactor
+	}
+	if req.EffectiveFrom != nil {
+		rule.EffectiveFrom = *req.EffectiveFrom
+	}
+	if err := rule.ValidateStrategy(); err != nil {
+		return nil, err
+	}
+	if err := rule.ValidatePeriod(); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// revisePricingRule schedules next as the revision of current, the latest
+// version of its rule, and validates it. A version that has not taken effect
+// yet has never priced anything and is changed in place; otherwise next is a
+// new version from effectiveFrom, now by default. It reports whether the
+// change is in place.
+func revisePricingRule(current, next *models.PricingRule, effectiveFrom *time.Time, now time.Time) (bool, error) {
+	if effectiveFrom != nil && effectiveFrom.Before(now) {
+		return false, errors.New("effective_from cannot be in the past; omit it to apply the change now")
+	}
+
+	next.IsActive = true
+	inPlace := current.EffectiveFrom.After(now)
+	switch {
+	case effectiveFrom != nil:
+		next.EffectiveFrom = *effectiveFrom
+	case inPlace:
+		next.EffectiveFrom = current.EffectiveFrom
+	default:
+		next.EffectiveFrom = now
+	}
+	if err := next.ValidateStrategy(); err != nil {
+		return false, err
+	}
+	return inPlace, next.ValidatePeriod()
+}
+
+// pricingRuleKey identifies the rule of a waste type and condition
+func pricingRuleKey(wasteType, condition string) string {
+	return wasteType + "\x00" + condition
 }
 
 // ListPricingRules retrieves all pricing rules
@@ -361,16 +742,18 @@ func (h *CompanyHandler) UpdatePricingRule(c *gin.Context) {
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param per_page query int false "Items per page" default(50)
+// @Param cursor query string false "Continue after the next_cursor of a previous page"
 // @Success 200 {array} models.PricingRuleResponse
 // @Router /api/v1/pricing-rules [get]
 func (h *CompanyHandler) ListPricingRules(c *gin.Context) {
-	page := getQueryInt(c, "page", 1)
-	perPage := getQueryInt(c, "per_page", 50)
-	offset := (page - 1) * perPage
+	pagination, ok := parsePage(c, 50)
+	if !ok {
+		return
+	}
 
-	rules, err := h.pricingRepo.List(c.Request.Context(), perPage, offset)
+	rules, result, err := h.pricingRepo.List(c.Request.Context(), pagination.page)
 	if err != nil {
-		utils.InternalError(c, "Failed to retrieve pricing rules")
+		listError(c, err, "Failed to retrieve pricing rules")
 		return
 	}
 
@@ -379,13 +762,10 @@ func (h *CompanyHandler) ListPricingRules(c *gin.Context) {
 		responses[i] = *r.ToResponse()
 	}
 
-	utils.SuccessResponseWithPagination(c, responses, &utils.Pagination{
-		Page:    page,
-		PerPage: perPage,
-	})
+	utils.SuccessResponseWithPagination(c, responses, pagination.meta(result))
 }
 
-// DeletePricingRule deletes a pricing rule
+// DeletePricingRule ends a pricing rule now; past versions are kept
 // @Summary Delete pricing rule
 // @Tags Pricing Rules
 // @Param id path string true "Pricing Rule ID"
@@ -400,7 +780,7 @@ func (h *CompanyHandler) DeletePricingRule(c *gin.Context) {
 	}
 
 	if err := h.pricingRepo.Delete(c.Request.Context(), id); err != nil {
-		utils.InternalError(c, "Failed to delete pricing rule")
+		abortWithError(c, err, "Failed to delete pricing rule")
 		return
 	}
 
@@ -414,19 +794,126 @@ func (h *CompanyHandler) DeletePricingRule(c *gin.Context) {
 // @Produce json
 // @Param request body models.ValuationRequest true "Valuation request"
 // @Success 200 {object} models.ValuationResponse
+// @Failure 400 {object} utils.APIError
+// @Failure 503 {object} utils.APIError
 // @Router /api/v1/valuations [post]
 func (h *CompanyHandler) CalculateValuation(c *gin.Context) {
-	var req models.ValuationRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationError(c, err.Error())
+	req, ok := bindValuationRequest(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.valuationSvc.CalculateValue(c.Request.Context(), req)
+	if err != nil {
+		valuationError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// CalculateCompanyValuation values waste with one company's pricing rules only
+// @Summary Calculate company valuation
+// @Tags Pricing Rules
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param request body models.ValuationRequest true "Valuation request"
+// @Success 200 {object} models.ValuationResponse
+// @Failure 400 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Failure 503 {object} utils.APIError
+// @Router /api/v1/companies/{id}/valuations [post]
+func (h *CompanyHandler) CalculateCompanyValuation(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid company ID format")
+		return
+	}
+
+	req, ok := bindValuationRequest(c)
+	if !ok {
+		return
+	}
+
+	company, err := h.companyRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve company")
+		return
+	}
+	if company == nil {
+		utils.NotFound(c, "Company not found")
 		return
 	}
 
-	result, err := h.valuationSvc.CalculateValue(c.Request.Context(), &req)
+	result, err := h.valuationSvc.CalculateCompanyValue(c.Request.Context(), company.ID, req)
 	if err != nil {
-		utils.InternalError(c, "Failed to calculate valuation")
+		valuationError(c, err)
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, result)
 }
+
+// CompareValuations ranks the offers of every active company for a waste lot
+// @Summary Compare company valuations
+// @Description Offers are compared in target_currency, which is required when companies price in several currencies
+// @Tags Pricing Rules
+// @Accept json
+// @Produce json
+// @Param request body models.ValuationRequest true "Valuation request"
+// @Param limit query int false "Maximum number of offers (1-100)" default(20)
+// @Success 200 {object} models.ValuationComparison
+// @Failure 400 {object} utils.APIError
+// @Failure 503 {object} utils.APIError
+// @Router /api/v1/valuations/compare [post]
+func (h *CompanyHandler) CompareValuations(c *gin.Context) {
+	req, ok := bindValuationRequest(c)
+	if !ok {
+		return
+	}
+
+	limit := getQueryInt(c, "limit", 20)
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	comparison, err := h.valuationSvc.Compare(c.Request.Context(), req, limit)
+	if errors.Is(err, services.ErrMixedCurrencies) {
+		utils.ValidationError(c, "Companies price this waste in several currencies; set target_currency to compare them")
+		return
+	}
+	if err != nil {
+		valuationError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, comparison)
+}
+
+// bindValuationRequest reads a valuation request, writing the error response itself
+func bindValuationRequest(c *gin.Context) (*models.ValuationRequest, bool) {
+	var req models.ValuationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return nil, false
+	}
+
+	if req.TargetCurrency != nil {
+		currency := strings.ToUpper(*req.TargetCurrency)
+		req.TargetCurrency = &currency
+	}
+	return &req, true
+}
+
+// valuationError writes the response of a failed valuation
+func valuationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrUnknownCurrency):
+		validationError(c, err)
+	case errors.Is(err, services.ErrRatesUnavailable):
+		utils.ErrorResponse(c, http.StatusServiceUnavailable, "EXCHANGE_RATES_UNAVAILABLE", "Exchange rates are unavailable")
+	default:
+		abortWithError(c, err, "Failed to calculate valuation")
+	}
+}
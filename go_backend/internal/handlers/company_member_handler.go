@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/auth"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/internal/services"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// CompanyMemberHandler handles company portal accounts and their invites
+type CompanyMemberHandler struct {
+	svc         *services.CompanyMemberService
+	repo        *repository.CompanyMemberRepository
+	companyRepo *repository.CompanyRepository
+	tokens      *auth.TokenManager
+}
+
+// NewCompanyMemberHandler creates a new CompanyMemberHandler
+func NewCompanyMemberHandler(svc *services.CompanyMemberService, repo *repository.CompanyMemberRepository, companyRepo *repository.CompanyRepository, tokens *auth.TokenManager) *CompanyMemberHandler {
+	return &CompanyMemberHandler{svc: svc, repo: repo, companyRepo: companyRepo, tokens: tokens}
+}
+
+// ListMembers retrieves the members of a company
+// @Summary List company members
+// @Tags Companies
+// @Produce json
+// @Param id path string true "Company ID"
+// @Success 200 {array} models.CompanyMember
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/companies/{id}/members [get]
+func (h *CompanyMemberHandler) ListMembers(c *gin.Context) {
+	company, ok := h.loadCompany(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.repo.List(c.Request.Context(), company.ID)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve members")
+		return
+	}
+	if members == nil {
+		members = []models.CompanyMember{}
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, members)
+}
+
+// UpdateMember changes a member's role within the company
+// @Summary Update company member role
+// @Tags Companies
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param userId path string true "User ID"
+// @Param member body models.UpdateMemberRequest true "Member role"
+// @Success 200 {object} models.CompanyMember
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/companies/{id}/members/{userId} [put]
+func (h *CompanyMemberHandler) UpdateMember(c *gin.Context) {
+	var req models.UpdateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+	if !req.Role.IsValid() {
+		utils.ValidationError(c, "Role must be one of: owner, manager, viewer")
+		return
+	}
+
+	member, ok := h.loadMember(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.ChangeRole(c.Request.Context(), member, req.Role); err != nil {
+		if errors.Is(err, services.ErrLastOwner) {
+			utils.Conflict(c, "The company's last owner cannot be demoted")
+			return
+		}
+		abortWithError(c, err, "Failed to update member")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, member)
+}
+
+// RemoveMember removes a member from the company; the account falls back to
+// the citizen role
+// @Summary Remove company member
+// @Tags Companies
+// @Param id path string true "Company ID"
+// @Param userId path string true "User ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/companies/{id}/members/{userId} [delete]
+func (h *CompanyMemberHandler) RemoveMember(c *gin.Context) {
+	member, ok := h.loadMember(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Remove(c.Request.Context(), member); err != nil {
+		if errors.Is(err, services.ErrLastOwner) {
+			utils.Conflict(c, "The company's last owner cannot be removed")
+			return
+		}
+		abortWithError(c, err, "Failed to remove member")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListInvites retrieves the pending invites of a company
+// @Summary List pending company invites
+// @Tags Companies
+// @Produce json
+// @Param id path string true "Company ID"
+// @Success 200 {array} models.CompanyInvite
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/companies/{id}/invites [get]
+func (h *CompanyMemberHandler) ListInvites(c *gin.Context) {
+	company, ok := h.loadCompany(c)
+	if !ok {
+		return
+	}
+
+	invites, err := h.repo.ListPendingInvites(c.Request.Context(), company.ID, time.Now())
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve invites")
+		return
+	}
+	if invites == nil {
+		invites = []models.CompanyInvite{}
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, invites)
+}
+
+// CreateInvite invites an email address to join the company
+// @Summary Invite a company member
+// @Description The invite token is only returned in this response; hand it to the invitee.
+// @Tags Companies
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param invite body models.CreateInviteRequest true "Invite data"
+// @Success 201 {object} models.CreateInviteResponse
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/companies/{id}/invites [post]
+func (h *CompanyMemberHandler) CreateInvite(c *gin.Context) {
+	var req models.CreateInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+	if !req.Role.IsValid() {
+		utils.ValidationError(c, "Role must be one of: owner, manager, viewer")
+		return
+	}
+
+	company, ok := h.loadCompany(c)
+	if !ok {
+		return
+	}
+
+	var invitedBy *uuid.UUID
+	if claims, ok := currentClaims(c); ok && claims.APIKeyID == nil {
+		invitedBy = &claims.SubjectID
+	}
+
+	invite, err := h.svc.Invite(c.Request.Context(), company.ID, &req, invitedBy)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrEmailRegistered):
+			utils.Conflict(c, "Email already registered")
+		case errors.Is(err, services.ErrInvitePending):
+			utils.Conflict(c, "An invite is already pending for this email")
+		default:
+			abortWithError(c, err, "Failed to create invite")
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, invite)
+}
+
+// RevokeInvite revokes a pending invite
+// @Summary Revoke company invite
+// @Tags Companies
+// @Param id path string true "Company ID"
+// @Param inviteId path string true "Invite ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/companies/{id}/invites/{inviteId} [delete]
+func (h *CompanyMemberHandler) RevokeInvite(c *gin.Context) {
+	company, ok := h.loadCompany(c)
+	if !ok {
+		return
+	}
+
+	inviteID, err := uuid.Parse(c.Param("inviteId"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid invite ID format")
+		return
+	}
+
+	invite, err := h.repo.GetInvite(c.Request.Context(), inviteID)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve invite")
+		return
+	}
+	if invite == nil || invite.CompanyID != company.ID {
+		utils.NotFound(c, "Invite not found")
+		return
+	}
+
+	if err := h.repo.RevokeInvite(c.Request.Context(), invite.ID); err != nil {
+		abortWithError(c, err, "Failed to revoke invite")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AcceptInvite accepts an invite, creating the member's account, and logs
+// the new member in
+// @Summary Accept a company invite
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param invite body models.AcceptInviteRequest true "Invite token and account data"
+// @Success 201 {object} models.LoginResponse
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/auth/invites/accept [post]
+func (h *CompanyMemberHandler) AcceptInvite(c *gin.Context) {
+	var req models.AcceptInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	member, err := h.svc.Accept(c.Request.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidInvite):
+			utils.NotFound(c, "Invite not found or no longer valid")
+		case errors.Is(err, services.ErrEmailRegistered):
+			utils.Conflict(c, "Email already registered")
+		default:
+			abortWithError(c, err, "Failed to accept invite")
+		}
+		return
+	}
+
+	token, expiresAt, err := h.tokens.IssueMember(member.OrganizationID, member.Email, member)
+	if err != nil {
+		abortWithError(c, err, "Failed to issue access token")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, loginResponse(token, expiresAt, models.RoleCompany, member.UserID, member.OrganizationID, member))
+}
+
+// loadCompany resolves the :id company, writing the error response itself
+func (h *CompanyMemberHandler) loadCompany(c *gin.Context) (*models.Company, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid company ID format")
+		return nil, false
+	}
+
+	company, err := h.companyRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve company")
+		return nil, false
+	}
+	if company == nil {
+		utils.NotFound(c, "Company not found")
+		return nil, false
+	}
+	return company, true
+}
+
+// loadMember resolves the :userId member of the :id company, writing the
+// error response itself
+func (h *CompanyMemberHandler) loadMember(c *gin.Context) (*models.CompanyMember, bool) {
+	company, ok := h.loadCompany(c)
+	if !ok {
+		return nil, false
+	}
+
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid user ID format")
+		return nil, false
+	}
+
+	member, err := h.repo.GetMember(c.Request.Context(), company.ID, userID)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve member")
+		return nil, false
+	}
+	if member == nil {
+		utils.NotFound(c, "Member not found")
+		return nil, false
+	}
+	return member, true
+}
@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// ContractHandler handles the contracts between companies and municipalities
+type ContractHandler struct {
+	contractRepo *repository.ContractRepository
+	companyRepo  *repository.CompanyRepository
+}
+
+// NewContractHandler creates a new ContractHandler
+func NewContractHandler(contractRepo *repository.ContractRepository, companyRepo *repository.CompanyRepository) *ContractHandler {
+	return &ContractHandler{contractRepo: contractRepo, companyRepo: companyRepo}
+}
+
+// ListContracts retrieves contracts; company principals only see their own
+// @Summary List contracts
+// @Tags Contracts
+// @Produce json
+// @Param company_id query string false "Only contracts of this company"
+// @Param in_effect query bool false "Only contracts in effect now"
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Param cursor query string false "Continue after the next_cursor of a previous page"
+// @Success 200 {array} models.Contract
+// @Failure 400 {object} utils.APIError
+// @Router /api/v1/contracts [get]
+func (h *ContractHandler) ListContracts(c *gin.Context) {
+	var filter models.ContractFilter
+	if value := c.Query("company_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(c, "Invalid company ID format")
+			return
+		}
+		filter.CompanyID = &id
+	}
+	if c.Query("in_effect") == "true" {
+		now := time.Now()
+		filter.ActiveAt = &now
+	}
+
+	pagination, ok := parsePage(c, 20)
+	if !ok {
+		return
+	}
+
+	contracts, result, err := h.contractRepo.List(c.Request.Context(), filter, pagination.page)
+	if err != nil {
+		listError(c, err, "Failed to retrieve contracts")
+		return
+	}
+	if contracts == nil {
+		contracts = []models.Contract{}
+	}
+
+	utils.SuccessResponseWithPagination(c, contracts, pagination.meta(result))
+}
+
+// GetContract retrieves a contract by ID
+// @Summary Get contract by ID
+// @Tags Contracts
+// @Produce json
+// @Param id path string true "Contract ID"
+// @Success 200 {object} models.Contract
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/contracts/{id} [get]
+func (h *ContractHandler) GetContract(c *gin.Context) {
+	contract, ok := h.loadContract(c)
+	if !ok {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, contract)
+}
+
+// CreateContract creates a contract between a company and a municipality
+// @Summary Create contract
+// @Tags Contracts
+// @Accept json
+// @Produce json
+// @Param contract body models.CreateContractRequest true "Contract data"
+// @Success 201 {object} models.Contract
+// @Failure 400 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/contracts [post]
+func (h *ContractHandler) CreateContract(c *gin.Context) {
+	var req models.CreateContractRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	contract := &models.Contract{
+		CompanyID:    req.CompanyID,
+		Name:         strings.TrimSpace(req.Name),
+		Municipality: strings.TrimSpace(req.Municipality),
+		WasteTypes:   trimWasteTypes(req.WasteTypes),
+		BoundingBox: models.BoundingBox{
+			MinLatitude:  req.MinLatitude,
+			MinLongitude: req.MinLongitude,
+			MaxLatitude:  req.MaxLatitude,
+			MaxLongitude: req.MaxLongitude,
+		},
+		RateCard:  req.RateCard,
+		ValidFrom: req.ValidFrom,
+		ValidTo:   req.ValidTo,
+	}
+	if err := contract.Validate(); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	company, err := h.companyRepo.GetByID(c.Request.Context(), contract.CompanyID)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve company")
+		return
+	}
+	if company == nil {
+		utils.NotFound(c, "Company not found")
+		return
+	}
+
+	if err := h.contractRepo.Create(c.Request.Context(), contract); err != nil {
+		abortWithError(c, err, "Failed to create contract")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, contract)
+}
+
+// UpdateContract updates a contract; the change applies to dispatch and
+// valuations from then on
+// @Summary Update contract
+// @Tags Contracts
+// @Accept json
+// @Produce json
+// @Param id path string true "Contract ID"
+// @Param contract body models.UpdateContractRequest true "Contract data"
+// @Success 200 {object} models.Contract
+// @Failure 400 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/contracts/{id} [put]
+func (h *ContractHandler) UpdateContract(c *gin.Context) {
+	var req models.UpdateContractRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	contract, ok := h.loadContract(c)
+	if !ok {
+		return
+	}
+
+	if req.Name != nil {
+		contract.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Municipality != nil {
+		contract.Municipality = strings.TrimSpace(*req.Municipality)
+	}
+	if req.WasteTypes != nil {
+		contract.WasteTypes = trimWasteTypes(req.WasteTypes)
+	}
+	if req.MinLatitude != nil {
+		contract.MinLatitude = *req.MinLatitude
+	}
+	if req.MinLongitude != nil {
+		contract.MinLongitude = *req.MinLongitude
+	}
+	if req.MaxLatitude != nil {
+		contract.MaxLatitude = *req.MaxLatitude
+	}
+	if req.MaxLongitude != nil {
+		contract.MaxLongitude = *req.MaxLongitude
+	}
+	if req.RateCard != nil {
+		contract.RateCard = req.RateCard
+	}
+	if req.ValidFrom != nil {
+		contract.ValidFrom = *req.ValidFrom
+	}
+	if req.ValidTo != nil {
+		contract.ValidTo = req.ValidTo
+	}
+	if req.IsActive != nil {
+		contract.IsActive = *req.IsActive
+	}
+	if err := contract.Validate(); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	if err := h.contractRepo.Update(c.Request.Context(), contract); err != nil {
+		abortWithError(c, err, "Failed to update contract")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, contract)
+}
+
+// DeleteContract ends a contract
+// @Summary Delete contract
+// @Tags Contracts
+// @Param id path string true "Contract ID"
+// @Success 204 "No Content"
+// @Router /api/v1/contracts/{id} [delete]
+func (h *ContractHandler) DeleteContract(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid contract ID format")
+		return
+	}
+
+	if err := h.contractRepo.Delete(c.Request.Context(), id); err != nil {
+		abortWithError(c, err, "Failed to delete contract")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// loadContract resolves the :id contract, writing the error response itself
+func (h *ContractHandler) loadContract(c *gin.Context) (*models.Contract, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid contract ID format")
+		return nil, false
+	}
+
+	contract, err := h.contractRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve contract")
+		return nil, false
+	}
+	if contract == nil {
+		utils.NotFound(c, "Contract not found")
+		return nil, false
+	}
+	return contract, true
+}
+
+// trimWasteTypes trims the waste types of a contract, dropping duplicates
+func trimWasteTypes(wasteTypes []string) models.WasteTypes {
+	trimmed := make(models.WasteTypes, 0, len(wasteTypes))
+	for _, wasteType := range wasteTypes {
+		wasteType = strings.TrimSpace(wasteType)
+		if !trimmed.Contains(wasteType) {
+			trimmed = append(trimmed, wasteType)
+		}
+	}
+	return trimmed
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/auth"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/mqtt"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// DeadLetterHandler exposes dead-lettered MQTT messages to administrators
+type DeadLetterHandler struct {
+	repo       *repository.DeadLetterRepository
+	mqttClient *mqtt.Client
+}
+
+// NewDeadLetterHandler creates a new DeadLetterHandler
+func NewDeadLetterHandler(repo *repository.DeadLetterRepository, mqttClient *mqtt.Client) *DeadLetterHandler {
+	return &DeadLetterHandler{
+		repo:       repo,
+		mqttClient: mqttClient,
+	}
+}
+
+// ListDeadLetters retrieves dead-lettered messages
+// @Summary List dead-lettered MQTT messages
+// @Tags Admin
+// @Produce json
+// @Param pending query bool false "Only messages not yet replayed"
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Param cursor query string false "Continue after the next_cursor of a previous page"
+// @Success 200 {array} models.DeadLetter
+// @Router /api/v1/admin/dead-letters [get]
+func (h *DeadLetterHandler) ListDeadLetters(c *gin.Context) {
+	pagination, ok := parsePage(c, 20)
+	if !ok {
+		return
+	}
+	pendingOnly := c.Query("pending") == "true"
+
+	deadLetters, result, err := h.repo.List(c.Request.Context(), pendingOnly, pagination.page)
+	if err != nil {
+		listError(c, err, "Failed to retrieve dead letters")
+		return
+	}
+
+	utils.SuccessResponseWithPagination(c, deadLetters, pagination.meta(result))
+}
+
+// GetDeadLetter retrieves a dead-lettered message by ID
+// @Summary Get dead-lettered MQTT message
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Dead letter ID"
+// @Success 200 {object} models.DeadLetter
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/admin/dead-letters/{id} [get]
+func (h *DeadLetterHandler) GetDeadLetter(c *gin.Context) {
+	deadLetter, ok := h.loadDeadLetter(c)
+	if !ok {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, deadLetter)
+}
+
+// ReplayDeadLetter reprocesses a dead-lettered message, optionally with a corrected payload
+// @Summary Replay dead-lettered MQTT message
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Dead letter ID"
+// @Param replay body models.ReplayDeadLetterRequest false "Corrected payload"
+// @Success 200 {object} models.DeadLetter
+// @Failure 404 {object} utils.APIError
+// @Failure 422 {object} utils.APIError
+// @Router /api/v1/admin/dead-letters/{id}/replay [post]
+func (h *DeadLetterHandler) ReplayDeadLetter(c *gin.Context) {
+	var req models.ReplayDeadLetterRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			validationError(c, err)
+			return
+		}
+	}
+
+	deadLetter, ok := h.loadDeadLetter(c)
+	if !ok {
+		return
+	}
+	if deadLetter.ReplayedAt != nil {
+		utils.Conflict(c, "Dead letter has already been replayed")
+		return
+	}
+
+	payload := deadLetter.Payload
+	if req.Payload != nil {
+		payload = *req.Payload
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	// Dead letters belong to no organization, so the message is processed as
+	// one from the broker, for the bin of its device in any organization
+	if err := h.mqttClient.ProcessBinStatus(auth.WithoutOrganization(ctx), []byte(payload)); err != nil {
+		if recordErr := h.repo.RecordFailedReplay(ctx, deadLetter.ID, payload, err.Error()); recordErr != nil {
+			abortWithError(c, recordErr, "Failed to update dead letter")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusUnprocessableEntity, "REPLAY_FAILED", err.Error())
+		return
+	}
+
+	if err := h.repo.MarkReplayed(ctx, deadLetter.ID, payload); err != nil {
+		abortWithError(c, err, "Failed to update dead letter")
+		return
+	}
+
+	updated, err := h.repo.GetByID(ctx, deadLetter.ID)
+	if err == nil && updated == nil {
+		err = repository.ErrNotFound
+	}
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve dead letter")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, updated)
+}
+
+// DeleteDeadLetter discards a dead-lettered message
+// @Summary Delete dead-lettered MQTT message
+// @Tags Admin
+// @Param id path string true "Dead letter ID"
+// @Success 204 "No Content"
+// @Router /api/v1/admin/dead-letters/{id} [delete]
+func (h *DeadLetterHandler) DeleteDeadLetter(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid dead letter ID format")
+		return
+	}
+
+	if err := h.repo.Delete(c.Request.Context(), id); err != nil {
+		abortWithError(c, err, "Failed to delete dead letter")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// loadDeadLetter resolves the :id dead letter, writing the error response itself
+func (h *DeadLetterHandler) loadDeadLetter(c *gin.Context) (*models.DeadLetter, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid dead letter ID format")
+		return nil, false
+	}
+
+	deadLetter, err := h.repo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve dead letter")
+		return nil, false
+	}
+	if deadLetter == nil {
+		utils.NotFound(c, "Dead letter not found")
+		return nil, false
+	}
+
+	return deadLetter, true
+}
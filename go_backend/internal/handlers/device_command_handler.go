@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/mqtt"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// DeviceCommandHandler sends commands and configurations to bin sensors over MQTT
+type DeviceCommandHandler struct {
+	binRepo    *repository.BinRepository
+	mqttClient *mqtt.Client
+}
+
+// NewDeviceCommandHandler creates a new DeviceCommandHandler
+func NewDeviceCommandHandler(binRepo *repository.BinRepository, mqttClient *mqtt.Client) *DeviceCommandHandler {
+	return &DeviceCommandHandler{
+		binRepo:    binRepo,
+		mqttClient: mqttClient,
+	}
+}
+
+// SendCommand publishes a command to the bin's sensor
+// @Summary Send command to bin sensor
+// @Tags Bins
+// @Accept json
+// @Produce json
+// @Param id path string true "Bin ID"
+// @Param command body models.CreateDeviceCommandRequest true "Command"
+// @Success 202 {object} models.DeviceCommand
+// @Failure 400 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Failure 503 {object} utils.APIError
+// @Router /api/v1/bins/{id}/commands [post]
+func (h *DeviceCommandHandler) SendCommand(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		utils.BadRequest(c, "Invalid bin ID format")
+		return
+	}
+
+	var req models.CreateDeviceCommandRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+	if !req.Type.IsValid() {
+		utils.BadRequest(c, "Unsupported command type")
+		return
+	}
+	if req.Type == models.DeviceCommandSetInterval && req.IntervalSeconds == nil {
+		utils.BadRequest(c, "interval_seconds is required for set_interval")
+		return
+	}
+
+	bin, err := h.binRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve bin")
+		return
+	}
+	if bin == nil {
+		utils.NotFound(c, "Bin not found")
+		return
+	}
+
+	if !h.mqttClient.IsConnected() {
+		utils.ErrorResponse(c, http.StatusServiceUnavailable, "MQTT_UNAVAILABLE", "MQTT broker is not connected")
+		return
+	}
+
+	command := &models.DeviceCommand{
+		ID:       uuid.New(),
+		Type:     req.Type,
+		IssuedAt: time.Now().UTC(),
+	}
+	if req.Type == models.DeviceCommandSetInterval {
+		command.IntervalSeconds = req.IntervalSeconds
+	}
+
+	if err := h.mqttClient.SendCommand(bin.DeviceID, command); err != nil {
+		abortWithError(c, err, "Failed to publish command")
+		return
+	}
+
+	// Accepted: delivery to the device is asynchronous
+	utils.SuccessResponse(c, http.StatusAccepted, command)
+}
+
+// PushConfig publishes a configuration to the bin's sensor
+// @Summary Push configuration to bin sensor
+// @Tags Bins
+// @Accept json
+// @Produce json
+// @Param id path string true "Bin ID"
+// @Param config body models.UpdateDeviceConfigRequest true "Configuration"
+// @Success 202 {object} models.DeviceConfig
+// @Failure 400 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Failure 503 {object} utils.APIError
+// @Router /api/v1/bins/{id}/config [put]
+func (h *DeviceCommandHandler) PushConfig(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		utils.BadRequest(c, "Invalid bin ID format")
+		return
+	}
+
+	var req models.UpdateDeviceConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+	if req.LowFillThreshold != nil && req.HighFillThreshold != nil && *req.LowFillThreshold > *req.HighFillThreshold {
+		utils.BadRequest(c, "low_fill_threshold must not be above high_fill_threshold")
+		return
+	}
+
+	bin, err := h.binRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve bin")
+		return
+	}
+	if bin == nil {
+		utils.NotFound(c, "Bin not found")
+		return
+	}
+
+	if !h.mqttClient.IsConnected() {
+		utils.ErrorResponse(c, http.StatusServiceUnavailable, "MQTT_UNAVAILABLE", "MQTT broker is not connected")
+		return
+	}
+
+	// Milliseconds since the epoch keep later pushes newer for the sensor
+	config := req.DeviceConfig(time.Now().UnixMilli())
+	if err := h.mqttClient.PublishConfig(bin.DeviceID, config); err != nil {
+		abortWithError(c, err, "Failed to publish configuration")
+		return
+	}
+
+	// Accepted: the sensor acknowledges it asynchronously, recorded on the bin
+	config.MQTTPassword = nil
+	utils.SuccessResponse(c, http.StatusAccepted, config)
+}
@@ -0,0 +1,331 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/services"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// DeviceHandler handles the registry of sensor units and their installation on bins
+type DeviceHandler struct {
+	svc *services.DeviceService
+}
+
+// NewDeviceHandler creates a new DeviceHandler
+func NewDeviceHandler(svc *services.DeviceService) *DeviceHandler {
+	return &DeviceHandler{svc: svc}
+}
+
+// ListDevices retrieves the devices of the fleet
+// @Summary List devices
+// @Tags Devices
+// @Produce json
+// @Param status query string false "in_stock, active or decommissioned"
+// @Param model query string false "Hardware model"
+// @Param connectivity query string false "wifi, cellular, lorawan or ethernet"
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Param cursor query string false "Continue after the next_cursor of a previous page"
+// @Success 200 {array} models.Device
+// @Router /api/v1/devices [get]
+func (h *DeviceHandler) ListDevices(c *gin.Context) {
+	pagination, ok := parsePage(c, 20)
+	if !ok {
+		return
+	}
+
+	var filter models.DeviceFilter
+	if status := c.Query("status"); status != "" {
+		s := models.DeviceStatus(status)
+		if !s.IsValid() {
+			utils.BadRequest(c, "status must be in_stock, active or decommissioned")
+			return
+		}
+		filter.Status = &s
+	}
+	if model := c.Query("model"); model != "" {
+		filter.Model = &model
+	}
+	if connectivity := c.Query("connectivity"); connectivity != "" {
+		conn := models.Connectivity(connectivity)
+		if !conn.IsValid() {
+			utils.BadRequest(c, "connectivity must be wifi, cellular, lorawan or ethernet")
+			return
+		}
+		filter.Connectivity = &conn
+	}
+
+	devices, result, err := h.svc.List(c.Request.Context(), filter, pagination.page)
+	if err != nil {
+		listError(c, err, "Failed to retrieve devices")
+		return
+	}
+
+	utils.SuccessResponseWithPagination(c, devices, pagination.meta(result))
+}
+
+// RegisterDevice registers a sensor unit, optionally installed on a bin at once
+// @Summary Register device
+// @Tags Devices
+// @Accept json
+// @Produce json
+// @Param device body models.CreateDeviceRequest true "Device"
+// @Success 201 {object} models.Device
+// @Failure 400 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/devices [post]
+func (h *DeviceHandler) RegisterDevice(c *gin.Context) {
+	var req models.CreateDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+	if req.Connectivity != "" && !req.Connectivity.IsValid() {
+		utils.ValidationError(c, "Invalid connectivity")
+		return
+	}
+
+	device, err := h.svc.Register(c.Request.Context(), &req, assignedBy(c))
+	if err != nil {
+		if errors.Is(err, services.ErrBinNotFound) {
+			utils.NotFound(c, "Bin not found")
+			return
+		}
+		abortWithError(c, err, "Failed to register device")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, device)
+}
+
+// GetDevice retrieves a device with the state reported by its bin
+// @Summary Get device
+// @Tags Devices
+// @Produce json
+// @Param id path string true "Device ID"
+// @Success 200 {object} models.Device
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/devices/{id} [get]
+func (h *DeviceHandler) GetDevice(c *gin.Context) {
+	device, ok := h.loadDevice(c)
+	if !ok {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, device)
+}
+
+// UpdateDevice updates the hardware and connectivity details of a device
+// @Summary Update device
+// @Tags Devices
+// @Accept json
+// @Produce json
+// @Param id path string true "Device ID"
+// @Param device body models.UpdateDeviceRequest true "Device details"
+// @Success 200 {object} models.Device
+// @Failure 400 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/devices/{id} [put]
+func (h *DeviceHandler) UpdateDevice(c *gin.Context) {
+	device, ok := h.loadDevice(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	if req.Model != nil {
+		device.Model = req.Model
+	}
+	if req.Connectivity != nil {
+		if !req.Connectivity.IsValid() {
+			utils.ValidationError(c, "Invalid connectivity")
+			return
+		}
+		device.Connectivity = *req.Connectivity
+	}
+	if req.SimICCID != nil {
+		device.SimICCID = req.SimICCID
+	}
+	if req.Carrier != nil {
+		device.Carrier = req.Carrier
+	}
+	if req.APN != nil {
+		device.APN = req.APN
+	}
+	if req.Notes != nil {
+		device.Notes = req.Notes
+	}
+
+	if err := h.svc.Update(c.Request.Context(), device); err != nil {
+		h.writeError(c, err, "Failed to update device")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, device)
+}
+
+// AssignDevice installs a device on a bin, replacing the device the bin had
+// @Summary Assign device to a bin
+// @Tags Devices
+// @Accept json
+// @Produce json
+// @Param id path string true "Device ID"
+// @Param assignment body models.AssignDeviceRequest true "Bin"
+// @Success 200 {object} models.Device
+// @Failure 400 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/devices/{id}/assign [post]
+func (h *DeviceHandler) AssignDevice(c *gin.Context) {
+	device, ok := h.loadDevice(c)
+	if !ok {
+		return
+	}
+
+	var req models.AssignDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	if err := h.svc.Assign(c.Request.Context(), device, &req, assignedBy(c)); err != nil {
+		h.writeError(c, err, "Failed to assign device")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, device)
+}
+
+// DecommissionDevice retires a device, removing it from the bin it serves
+// @Summary Decommission device
+// @Tags Devices
+// @Accept json
+// @Produce json
+// @Param id path string true "Device ID"
+// @Param decommission body models.DecommissionDeviceRequest false "Reason"
+// @Success 200 {object} models.Device
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/devices/{id}/decommission [post]
+func (h *DeviceHandler) DecommissionDevice(c *gin.Context) {
+	device, ok := h.loadDevice(c)
+	if !ok {
+		return
+	}
+
+	var req models.DecommissionDeviceRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			validationError(c, err)
+			return
+		}
+	}
+
+	if err := h.svc.Decommission(c.Request.Context(), device, req.Reason); err != nil {
+		h.writeError(c, err, "Failed to decommission device")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, device)
+}
+
+// ListDeviceAssignments retrieves the bins a device served
+// @Summary List device assignments
+// @Tags Devices
+// @Produce json
+// @Param id path string true "Device ID"
+// @Success 200 {array} models.DeviceAssignment
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/devices/{id}/assignments [get]
+func (h *DeviceHandler) ListDeviceAssignments(c *gin.Context) {
+	device, ok := h.loadDevice(c)
+	if !ok {
+		return
+	}
+
+	assignments, err := h.svc.Assignments(c.Request.Context(), device.ID)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve device assignments")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, assignments)
+}
+
+// ListBinDevices retrieves the devices that served a bin
+// @Summary List the devices of a bin
+// @Tags Devices
+// @Produce json
+// @Param id path string true "Bin ID"
+// @Success 200 {array} models.DeviceAssignment
+// @Router /api/v1/bins/{id}/devices [get]
+func (h *DeviceHandler) ListBinDevices(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid bin ID format")
+		return
+	}
+
+	assignments, err := h.svc.BinAssignments(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve bin devices")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, assignments)
+}
+
+// assignedBy returns the user making the change, or nil for an API key
+func assignedBy(c *gin.Context) *uuid.UUID {
+	if claims, ok := currentClaims(c); ok && claims.APIKeyID == nil {
+		return &claims.SubjectID
+	}
+	return nil
+}
+
+// writeError maps the errors of device changes to responses
+func (h *DeviceHandler) writeError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrDeviceDecommissioned):
+		utils.Conflict(c, "Device is decommissioned")
+	case errors.Is(err, services.ErrDeviceAlreadyAssigned):
+		utils.Conflict(c, "Device is already installed on this bin")
+	case errors.Is(err, services.ErrBinNotFound):
+		utils.NotFound(c, "Bin not found")
+	default:
+		abortWithError(c, err, message)
+	}
+}
+
+// loadDevice resolves the :id device, writing the error response itself
+func (h *DeviceHandler) loadDevice(c *gin.Context) (*models.Device, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid device ID format")
+		return nil, false
+	}
+
+	device, err := h.svc.Get(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve device")
+		return nil, false
+	}
+	if device == nil {
+		utils.NotFound(c, "Device not found")
+		return nil, false
+	}
+
+	return device, true
+}
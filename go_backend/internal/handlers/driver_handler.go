@@ -1,13 +1,16 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/realtime"
 	"github.com/smartwaste/backend/internal/repository"
 	"github.com/smartwaste/backend/internal/services"
+	"github.com/smartwaste/backend/pkg/security"
 	"github.com/smartwaste/backend/pkg/utils"
 )
 
@@ -16,7 +19,11 @@ type DriverHandler struct {
 	driverRepo     *repository.DriverRepository
 	binRepo        *repository.BinRepository
 	collectionRepo *repository.CollectionRepository
+	vehicleRepo    *repository.VehicleRepository
+	collectionSvc  *services.CollectionService
 	routeService   *services.RouteService
+	hasher         security.PasswordHasher
+	locations      *realtime.LocationBroker
 }
 
 // NewDriverHandler creates a new DriverHandler
@@ -24,13 +31,21 @@ func NewDriverHandler(
 	driverRepo *repository.DriverRepository,
 	binRepo *repository.BinRepository,
 	collectionRepo *repository.CollectionRepository,
+	vehicleRepo *repository.VehicleRepository,
+	collectionSvc *services.CollectionService,
 	routeService *services.RouteService,
+	hasher security.PasswordHasher,
+	locations *realtime.LocationBroker,
 ) *DriverHandler {
 	return &DriverHandler{
 		driverRepo:     driverRepo,
 		binRepo:        binRepo,
 		collectionRepo: collectionRepo,
+		vehicleRepo:    vehicleRepo,
+		collectionSvc:  collectionSvc,
 		routeService:   routeService,
+		hasher:         hasher,
+		locations:      locations,
 	}
 }
 
@@ -52,7 +67,7 @@ func (h *DriverHandler) GetDriver(c *gin.Context) {
 
 	driver, err := h.driverRepo.GetByID(c.Request.Context(), id)
 	if err != nil {
-		utils.InternalError(c, "Failed to retrieve driver")
+		abortWithError(c, err, "Failed to retrieve driver")
 		return
 	}
 
@@ -76,14 +91,14 @@ func (h *DriverHandler) GetDriver(c *gin.Context) {
 func (h *DriverHandler) CreateDriver(c *gin.Context) {
 	var req models.CreateDriverRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationError(c, err.Error())
+		validationError(c, err)
 		return
 	}
 
 	// Check if email already exists
 	existing, err := h.driverRepo.GetByEmail(c.Request.Context(), req.Email)
 	if err != nil {
-		utils.InternalError(c, "Failed to check existing driver")
+		abortWithError(c, err, "Failed to check existing driver")
 		return
 	}
 	if existing != nil {
@@ -91,9 +106,15 @@ func (h *DriverHandler) CreateDriver(c *gin.Context) {
 		return
 	}
 
+	passwordHash, err := h.hasher.HashPassword(req.Password)
+	if err != nil {
+		abortWithError(c, err, "Failed to secure password")
+		return
+	}
+
 	driver := &models.Driver{
 		Email:         req.Email,
-		PasswordHash:  req.Password, // In production, hash this!
+		PasswordHash:  passwordHash,
 		FullName:      req.FullName,
 		Phone:         req.Phone,
 		LicenseNumber: req.LicenseNumber,
@@ -103,7 +124,7 @@ func (h *DriverHandler) CreateDriver(c *gin.Context) {
 	}
 
 	if err := h.driverRepo.Create(c.Request.Context(), driver); err != nil {
-		utils.InternalError(c, "Failed to create driver")
+		abortWithError(c, err, "Failed to create driver")
 		return
 	}
 
@@ -129,13 +150,13 @@ func (h *DriverHandler) UpdateDriver(c *gin.Context) {
 
 	var req models.UpdateDriverRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationError(c, err.Error())
+		validationError(c, err)
 		return
 	}
 
 	driver, err := h.driverRepo.GetByID(c.Request.Context(), id)
 	if err != nil {
-		utils.InternalError(c, "Failed to retrieve driver")
+		abortWithError(c, err, "Failed to retrieve driver")
 		return
 	}
 	if driver == nil {
@@ -161,7 +182,7 @@ func (h *DriverHandler) UpdateDriver(c *gin.Context) {
 	}
 
 	if err := h.driverRepo.Update(c.Request.Context(), driver); err != nil {
-		utils.InternalError(c, "Failed to update driver")
+		abortWithError(c, err, "Failed to update driver")
 		return
 	}
 
@@ -187,15 +208,18 @@ func (h *DriverHandler) UpdateLocation(c *gin.Context) {
 
 	var req models.UpdateDriverLocationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationError(c, err.Error())
+		validationError(c, err)
 		return
 	}
 
 	if err := h.driverRepo.UpdateLocation(c.Request.Context(), id, req.Latitude, req.Longitude); err != nil {
-		utils.InternalError(c, "Failed to update location")
+		abortWithError(c, err, "Failed to update location")
 		return
 	}
 
+	// Fan the new position out to live tracking subscribers
+	h.locations.Publish(id, req.Latitude, req.Longitude)
+
 	utils.SuccessResponse(c, http.StatusOK, gin.H{
 		"driver_id": id,
 		"latitude":  req.Latitude,
@@ -204,12 +228,14 @@ func (h *DriverHandler) UpdateLocation(c *gin.Context) {
 	})
 }
 
-// GetRoutes retrieves optimized routes for a driver
+// GetRoutes plans an optimized route for a driver, kept as their pending
+// route so it can be exported to a navigation app
 // @Summary Get optimized routes
 // @Tags Drivers
 // @Produce json
 // @Param id path string true "Driver ID"
-// @Param optimize_by query string false "Optimization criteria: distance or fill_level" default(distance)
+// @Param optimize_by query string false "Optimization criteria: distance, fill_level, two_opt or capacity" default(distance)
+// @Param truck_capacity_liters query int false "Truck capacity for the capacity mode (defaults to the assigned vehicle, then ROUTE_TRUCK_CAPACITY_LITERS)"
 // @Success 200 {object} models.RouteResponse
 // @Router /api/v1/drivers/{id}/routes [get]
 func (h *DriverHandler) GetRoutes(c *gin.Context) {
@@ -226,10 +252,10 @@ func (h *DriverHandler) GetRoutes(c *gin.Context) {
 		return
 	}
 
-	// Get bins needing collection (>80% full)
-	bins, err := h.routeService.GetBinsForRoute(c.Request.Context(), 80)
+	// Get bins at or above their collection threshold
+	bins, err := h.routeService.GetBinsForRoute(c.Request.Context())
 	if err != nil {
-		utils.InternalError(c, "Failed to get bins for route")
+		abortWithError(c, err, "Failed to get bins for route")
 		return
 	}
 
@@ -255,18 +281,36 @@ func (h *DriverHandler) GetRoutes(c *gin.Context) {
 		driverLng = *driver.Longitude
 	}
 
-	optimizeBy := c.DefaultQuery("optimize_by", "distance")
-	route, err := h.routeService.OptimizeRoute(c.Request.Context(), driverLat, driverLng, binIDs, optimizeBy)
+	opts := models.RouteOptions{
+		OptimizeBy:          c.DefaultQuery("optimize_by", models.RouteOptimizeDistance),
+		TruckCapacityLiters: getQueryInt(c, "truck_capacity_liters", 0),
+	}
+	// Plan against the driver's assigned truck unless the caller overrides it
+	if opts.TruckCapacityLiters <= 0 && driver.VehicleID != nil {
+		vehicle, err := h.vehicleRepo.GetByID(c.Request.Context(), *driver.VehicleID)
+		if err != nil {
+			abortWithError(c, err, "Failed to retrieve driver vehicle")
+			return
+		}
+		if vehicle != nil {
+			opts.TruckCapacityLiters = vehicle.CapacityLiters
+		}
+	}
+	route, err := h.routeService.OptimizeRoute(c.Request.Context(), driverLat, driverLng, binIDs, opts)
 	if err != nil {
-		utils.InternalError(c, "Failed to calculate route")
+		abortWithError(c, err, "Failed to calculate route")
 		return
 	}
 
 	route.DriverID = id
+	if err := h.routeService.Save(c.Request.Context(), route); err != nil {
+		abortWithError(c, err, "Failed to save route")
+		return
+	}
 	utils.SuccessResponse(c, http.StatusOK, route.ToResponse())
 }
 
-// VerifyTask verifies a collection task via QR code
+// VerifyTask verifies a collection task via QR code, scanned near its bin
 // @Summary Verify task via QR code
 // @Tags Drivers
 // @Accept json
@@ -274,6 +318,10 @@ func (h *DriverHandler) GetRoutes(c *gin.Context) {
 // @Param id path string true "Driver ID"
 // @Param request body models.VerifyTaskRequest true "QR code data"
 // @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} utils.APIError
+// @Failure 403 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Failure 422 {object} utils.APIError
 // @Router /api/v1/drivers/{id}/verify [post]
 func (h *DriverHandler) VerifyTask(c *gin.Context) {
 	idParam := c.Param("id")
@@ -285,7 +333,7 @@ func (h *DriverHandler) VerifyTask(c *gin.Context) {
 
 	var req models.VerifyTaskRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationError(c, err.Error())
+		validationError(c, err)
 		return
 	}
 
@@ -298,7 +346,7 @@ func (h *DriverHandler) VerifyTask(c *gin.Context) {
 	// Get collection
 	collection, err := h.collectionRepo.GetByID(c.Request.Context(), collectionID)
 	if err != nil {
-		utils.InternalError(c, "Failed to retrieve collection")
+		abortWithError(c, err, "Failed to retrieve collection")
 		return
 	}
 	if collection == nil {
@@ -324,16 +372,25 @@ func (h *DriverHandler) VerifyTask(c *gin.Context) {
 		return
 	}
 
-	// Mark as verified
-	if err := h.collectionRepo.VerifyQRCode(c.Request.Context(), collectionID); err != nil {
-		utils.InternalError(c, "Failed to verify collection")
+	// Check the driver is at the bin and mark as verified
+	distance, err := h.collectionSvc.VerifyLocation(c.Request.Context(), collection, req.Latitude, req.Longitude)
+	switch {
+	case errors.Is(err, services.ErrDriverLocationUnknown):
+		utils.BadRequest(c, "Location is required to verify the collection")
+		return
+	case errors.Is(err, services.ErrTooFarFromBin):
+		utils.ErrorResponse(c, http.StatusUnprocessableEntity, "TOO_FAR_FROM_BIN", err.Error())
+		return
+	case err != nil:
+		abortWithError(c, err, "Failed to verify collection")
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, gin.H{
-		"collection_id": collectionID,
-		"verified":      true,
-		"message":       "Task verified successfully",
+		"collection_id":           collectionID,
+		"verified":                true,
+		"verification_distance_m": distance,
+		"message":                 "Task verified successfully",
 	})
 }
 
@@ -354,7 +411,7 @@ func (h *DriverHandler) GetDriverStats(c *gin.Context) {
 
 	stats, err := h.collectionRepo.GetDriverStats(c.Request.Context(), id)
 	if err != nil {
-		utils.InternalError(c, "Failed to retrieve driver statistics")
+		abortWithError(c, err, "Failed to retrieve driver statistics")
 		return
 	}
 
@@ -368,16 +425,18 @@ func (h *DriverHandler) GetDriverStats(c *gin.Context) {
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param per_page query int false "Items per page" default(20)
+// @Param cursor query string false "Continue after the next_cursor of a previous page"
 // @Success 200 {array} models.DriverResponse
 // @Router /api/v1/drivers [get]
 func (h *DriverHandler) ListDrivers(c *gin.Context) {
-	page := getQueryInt(c, "page", 1)
-	perPage := getQueryInt(c, "per_page", 20)
-	offset := (page - 1) * perPage
+	pagination, ok := parsePage(c, 20)
+	if !ok {
+		return
+	}
 
-	drivers, err := h.driverRepo.List(c.Request.Context(), perPage, offset)
+	drivers, result, err := h.driverRepo.List(c.Request.Context(), pagination.page)
 	if err != nil {
-		utils.InternalError(c, "Failed to retrieve drivers")
+		listError(c, err, "Failed to retrieve drivers")
 		return
 	}
 
@@ -386,8 +445,5 @@ func (h *DriverHandler) ListDrivers(c *gin.Context) {
 		responses[i] = *d.ToResponse()
 	}
 
-	utils.SuccessResponseWithPagination(c, responses, &utils.Pagination{
-		Page:    page,
-		PerPage: perPage,
-	})
+	utils.SuccessResponseWithPagination(c, responses, pagination.meta(result))
 }
@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/internal/services"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// EarningHandler handles driver earnings statements and the payout batches
+// finance settles them with
+type EarningHandler struct {
+	earningSvc  *services.EarningService
+	earningRepo *repository.EarningRepository
+}
+
+// NewEarningHandler creates a new EarningHandler
+func NewEarningHandler(earningSvc *services.EarningService, earningRepo *repository.EarningRepository) *EarningHandler {
+	return &EarningHandler{earningSvc: earningSvc, earningRepo: earningRepo}
+}
+
+// GetDriverEarnings retrieves the earnings statement of a driver for a month
+// @Summary Get driver earnings statement
+// @Description Totals what the driver earned over the month by source, and how much of it was paid out
+// @Tags Earnings
+// @Produce json
+// @Param id path string true "Driver ID"
+// @Param period query string false "Month formatted YYYY-MM, defaults to the current month"
+// @Success 200 {object} models.DriverEarningsStatement
+// @Failure 400 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/drivers/{id}/earnings [get]
+func (h *EarningHandler) GetDriverEarnings(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid driver ID format")
+		return
+	}
+	period, err := models.EarningsPeriod(c.Query("period"), time.Now())
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	statement, err := h.earningSvc.Statement(c.Request.Context(), id, period)
+	if err != nil {
+		h.writeEarningError(c, err, "Failed to retrieve driver earnings")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, statement)
+}
+
+// GrantBonus grants a driver a bonus on top of their earnings
+// @Summary Grant a driver bonus
+// @Tags Earnings
+// @Accept json
+// @Produce json
+// @Param id path string true "Driver ID"
+// @Param bonus body models.GrantBonusRequest true "Bonus amount and reason"
+// @Success 201 {object} models.DriverEarning
+// @Failure 400 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/drivers/{id}/earnings/bonuses [post]
+func (h *EarningHandler) GrantBonus(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid driver ID format")
+		return
+	}
+
+	var req models.GrantBonusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	claims, ok := currentClaims(c)
+	if !ok {
+		utils.Unauthorized(c, "Authentication required")
+		return
+	}
+
+	earning, err := h.earningSvc.GrantBonus(c.Request.Context(), id, claims.SubjectID, &req)
+	if err != nil {
+		h.writeEarningError(c, err, "Failed to grant bonus")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, earning)
+}
+
+// CreatePayoutBatch pays out the unpaid earnings up to the end of a month
+// @Summary Create a payout batch
+// @Description Settles the unpaid earnings of every driver earned before the end of the month, with one line per driver
+// @Tags Earnings
+// @Accept json
+// @Produce json
+// @Param batch body models.CreatePayoutBatchRequest true "Month to pay out"
+// @Success 201 {object} models.PayoutBatch
+// @Failure 400 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/payout-batches [post]
+func (h *EarningHandler) CreatePayoutBatch(c *gin.Context) {
+	var req models.CreatePayoutBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+	period, err := models.EarningsPeriod(req.Period, time.Now())
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	claims, ok := currentClaims(c)
+	if !ok {
+		utils.Unauthorized(c, "Authentication required")
+		return
+	}
+
+	batch, err := h.earningSvc.CreateBatch(c.Request.Context(), period, claims.SubjectID)
+	if err != nil {
+		h.writeEarningError(c, err, "Failed to create payout batch")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, batch)
+}
+
+// ListPayoutBatches retrieves the payout batches
+// @Summary List payout batches
+// @Tags Earnings
+// @Produce json
+// @Param status query string false "Filter by status (pending, paid)"
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Param cursor query string false "Continue after the next_cursor of a previous page"
+// @Success 200 {array} models.PayoutBatch
+// @Failure 400 {object} utils.APIError
+// @Router /api/v1/payout-batches [get]
+func (h *EarningHandler) ListPayoutBatches(c *gin.Context) {
+	var status *models.PayoutBatchStatus
+	if value := c.Query("status"); value != "" {
+		s := models.PayoutBatchStatus(value)
+		if s != models.PayoutBatchPending && s != models.PayoutBatchPaid {
+			utils.BadRequest(c, "Invalid payout batch status")
+			return
+		}
+		status = &s
+	}
+
+	pagination, ok := parsePage(c, 20)
+	if !ok {
+		return
+	}
+
+	batches, result, err := h.earningRepo.ListBatches(c.Request.Context(), status, pagination.page)
+	if err != nil {
+		listError(c, err, "Failed to retrieve payout batches")
+		return
+	}
+	if batches == nil {
+		batches = []models.PayoutBatch{}
+	}
+
+	utils.SuccessResponseWithPagination(c, batches, pagination.meta(result))
+}
+
+// GetPayoutBatch retrieves a payout batch with its lines
+// @Summary Get payout batch by ID
+// @Tags Earnings
+// @Produce json
+// @Param id path string true "Payout Batch ID"
+// @Success 200 {object} models.PayoutBatch
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/payout-batches/{id} [get]
+func (h *EarningHandler) GetPayoutBatch(c *gin.Context) {
+	batch, ok := h.loadBatch(c)
+	if !ok {
+		return
+	}
+
+	lines, err := h.earningRepo.ListLines(c.Request.Context(), batch.ID)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve payout lines")
+		return
+	}
+	batch.Lines = lines
+
+	utils.SuccessResponse(c, http.StatusOK, batch)
+}
+
+// MarkPayoutBatchPaid records that finance paid out a payout batch
+// @Summary Mark a payout batch paid
+// @Tags Earnings
+// @Produce json
+// @Param id path string true "Payout Batch ID"
+// @Success 200 {object} models.PayoutBatch
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/payout-batches/{id}/mark-paid [post]
+func (h *EarningHandler) MarkPayoutBatchPaid(c *gin.Context) {
+	batch, ok := h.loadBatch(c)
+	if !ok {
+		return
+	}
+
+	if err := h.earningSvc.MarkBatchPaid(c.Request.Context(), batch); err != nil {
+		h.writeEarningError(c, err, "Failed to mark payout batch paid")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, batch)
+}
+
+// loadBatch resolves the :id payout batch, writing the error response itself
+func (h *EarningHandler) loadBatch(c *gin.Context) (*models.PayoutBatch, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid payout batch ID format")
+		return nil, false
+	}
+
+	batch, err := h.earningRepo.GetBatch(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve payout batch")
+		return nil, false
+	}
+	if batch == nil {
+		utils.NotFound(c, "Payout batch not found")
+		return nil, false
+	}
+	return batch, true
+}
+
+// writeEarningError writes the response for an error with driver earnings
+func (h *EarningHandler) writeEarningError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrDriverNotFound):
+		utils.NotFound(c, "Driver not found")
+	case errors.Is(err, services.ErrNothingToPay):
+		utils.Conflict(c, "No unpaid earnings to pay out for this period")
+	case errors.Is(err, services.ErrBatchPaid):
+		utils.Conflict(c, "Payout batch was already paid")
+	default:
+		abortWithError(c, err, message)
+	}
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/services"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// ExportHandler handles report exports
+type ExportHandler struct {
+	reportSvc *services.ReportService
+}
+
+// NewExportHandler creates a new ExportHandler
+func NewExportHandler(reportSvc *services.ReportService) *ExportHandler {
+	return &ExportHandler{reportSvc: reportSvc}
+}
+
+// ExportReport streams a collection, bin or driver report, or queues it for
+// download when async is set
+// @Summary Export a report
+// @Tags Analytics
+// @Produce text/csv,application/pdf,json
+// @Param report query string true "collections, bins or drivers"
+// @Param format query string false "csv or pdf" default(csv)
+// @Param from query string false "Period start (YYYY-MM-DD or RFC3339); defaults to 30 days before to"
+// @Param to query string false "Period end (YYYY-MM-DD inclusive, or RFC3339); defaults to now"
+// @Param async query bool false "Generate in the background and return the export to poll"
+// @Success 200 {file} file
+// @Success 202 {object} models.ReportExport
+// @Failure 400 {object} utils.APIError
+// @Failure 429 {object} utils.APIError
+// @Router /api/v1/analytics/export [get]
+func (h *ExportHandler) ExportReport(c *gin.Context) {
+	req := models.ReportRequest{
+		Report: models.ReportKind(c.Query("report")),
+		Format: models.ReportFormat(c.DefaultQuery("format", string(models.ReportFormatCSV))),
+	}
+	if !req.Report.IsValid() {
+		utils.BadRequest(c, "report must be collections, bins or drivers")
+		return
+	}
+	if !req.Format.IsValid() {
+		utils.BadRequest(c, "format must be csv or pdf")
+		return
+	}
+
+	period, ok := parsePeriod(c)
+	if !ok {
+		return
+	}
+	req.Period = period
+
+	if c.Query("async") == "true" {
+		h.enqueue(c, req)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", req.Filename()))
+	if req.Format == models.ReportFormatPDF {
+		// Render first so a failure can still be reported as JSON
+		var buf bytes.Buffer
+		if _, err := h.reportSvc.Write(c.Request.Context(), &buf, req); err != nil {
+			c.Writer.Header().Del("Content-Disposition")
+			abortWithError(c, err, "Failed to generate report")
+			return
+		}
+		c.Data(http.StatusOK, req.Format.ContentType(), buf.Bytes())
+		return
+	}
+
+	c.Header("Content-Type", req.Format.ContentType())
+	c.Status(http.StatusOK)
+	if _, err := h.reportSvc.Write(c.Request.Context(), c.Writer, req); err != nil {
+		// Headers are already sent; the truncated file is the only signal left
+		log.Printf("Report export aborted: %v", err)
+	}
+}
+
+func (h *ExportHandler) enqueue(c *gin.Context, req models.ReportRequest) {
+	var requestedBy *uuid.UUID
+	if claims, ok := currentClaims(c); ok {
+		requestedBy = &claims.SubjectID
+	}
+
+	export, err := h.reportSvc.Enqueue(c.Request.Context(), req, requestedBy)
+	if errors.Is(err, services.ErrExportQueueFull) {
+		utils.TooManyRequests(c, "Too many exports in progress, try again later")
+		return
+	}
+	if err != nil {
+		abortWithError(c, err, "Failed to queue export")
+		return
+	}
+
+	c.Header("Location", exportURL(export.ID))
+	utils.SuccessResponse(c, http.StatusAccepted, export)
+}
+
+// GetExport retrieves the status of a background export
+// @Summary Get report export
+// @Tags Analytics
+// @Produce json
+// @Param id path string true "Export ID"
+// @Success 200 {object} models.ReportExport
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/analytics/exports/{id} [get]
+func (h *ExportHandler) GetExport(c *gin.Context) {
+	export, ok := h.loadExport(c)
+	if !ok {
+		return
+	}
+
+	if export.Status == models.ExportStatusCompleted {
+		export.DownloadURL = exportURL(export.ID) + "/download"
+	}
+	utils.SuccessResponse(c, http.StatusOK, export)
+}
+
+// DownloadExport serves the file of a completed background export
+// @Summary Download report export
+// @Tags Analytics
+// @Produce text/csv,application/pdf
+// @Param id path string true "Export ID"
+// @Success 200 {file} file
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/analytics/exports/{id}/download [get]
+func (h *ExportHandler) DownloadExport(c *gin.Context) {
+	export, ok := h.loadExport(c)
+	if !ok {
+		return
+	}
+
+	if export.Status != models.ExportStatusCompleted || export.FilePath == nil {
+		utils.Conflict(c, fmt.Sprintf("Export is %s", export.Status))
+		return
+	}
+	if export.ExpiresAt != nil && export.ExpiresAt.Before(time.Now()) {
+		utils.NotFound(c, "Export has expired")
+		return
+	}
+	if _, err := os.Stat(*export.FilePath); err != nil {
+		utils.NotFound(c, "Export file is not available on this server")
+		return
+	}
+
+	c.Header("Content-Type", export.Format.ContentType())
+	c.FileAttachment(*export.FilePath, export.Request().Filename())
+}
+
+// loadExport parses the export ID and fetches the export, writing the error
+// response when it cannot
+func (h *ExportHandler) loadExport(c *gin.Context) (*models.ReportExport, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid export ID format")
+		return nil, false
+	}
+
+	export, err := h.reportSvc.GetExport(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve export")
+		return nil, false
+	}
+	if export == nil {
+		utils.NotFound(c, "Export not found")
+		return nil, false
+	}
+	return export, true
+}
+
+func exportURL(id uuid.UUID) string {
+	return "/api/v1/analytics/exports/" + id.String()
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/internal/services"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// FirmwareHandler handles firmware rollouts to the bin sensors
+type FirmwareHandler struct {
+	svc *services.FirmwareService
+}
+
+// NewFirmwareHandler creates a new FirmwareHandler
+func NewFirmwareHandler(svc *services.FirmwareService) *FirmwareHandler {
+	return &FirmwareHandler{svc: svc}
+}
+
+// ListRollouts retrieves firmware rollouts with their progress
+// @Summary List firmware rollouts
+// @Tags Firmware
+// @Produce json
+// @Param status query string false "active or cancelled"
+// @Param device_group query string false "Device group"
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Param cursor query string false "Continue after the next_cursor of a previous page"
+// @Success 200 {array} models.FirmwareRollout
+// @Router /api/v1/firmware-rollouts [get]
+func (h *FirmwareHandler) ListRollouts(c *gin.Context) {
+	pagination, ok := parsePage(c, 20)
+	if !ok {
+		return
+	}
+
+	var filter models.FirmwareRolloutFilter
+	if status := c.Query("status"); status != "" {
+		s := models.RolloutStatus(status)
+		if s != models.RolloutActive && s != models.RolloutCancelled {
+			utils.BadRequest(c, "status must be active or cancelled")
+			return
+		}
+		filter.Status = &s
+	}
+	if group := c.Query("device_group"); group != "" {
+		filter.DeviceGroup = &group
+	}
+
+	rollouts, result, err := h.svc.List(c.Request.Context(), filter, pagination.page)
+	if err != nil {
+		listError(c, err, "Failed to retrieve firmware rollouts")
+		return
+	}
+
+	utils.SuccessResponseWithPagination(c, rollouts, pagination.meta(result))
+}
+
+// CreateRollout rolls a firmware version out to the sensors of a device group
+// @Summary Create firmware rollout
+// @Tags Firmware
+// @Accept json
+// @Produce json
+// @Param rollout body models.CreateFirmwareRolloutRequest true "Rollout"
+// @Success 201 {object} models.FirmwareRollout
+// @Failure 400 {object} utils.APIError
+// @Failure 503 {object} utils.APIError
+// @Router /api/v1/firmware-rollouts [post]
+func (h *FirmwareHandler) CreateRollout(c *gin.Context) {
+	var req models.CreateFirmwareRolloutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	var createdBy *uuid.UUID
+	if claims, ok := currentClaims(c); ok && claims.APIKeyID == nil {
+		createdBy = &claims.SubjectID
+	}
+
+	rollout, err := h.svc.Create(c.Request.Context(), &req, createdBy)
+	if err != nil {
+		if errors.Is(err, services.ErrOTAUnavailable) {
+			utils.ErrorResponse(c, http.StatusServiceUnavailable, "MQTT_UNAVAILABLE", "MQTT broker is not connected")
+			return
+		}
+		abortWithError(c, err, "Failed to create firmware rollout")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, rollout)
+}
+
+// GetRollout retrieves a firmware rollout with its progress
+// @Summary Get firmware rollout
+// @Tags Firmware
+// @Produce json
+// @Param id path string true "Rollout ID"
+// @Success 200 {object} models.FirmwareRollout
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/firmware-rollouts/{id} [get]
+func (h *FirmwareHandler) GetRollout(c *gin.Context) {
+	rollout, ok := h.loadRollout(c)
+	if !ok {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, rollout)
+}
+
+// ListUpdates retrieves the progress of a firmware rollout on each of its bins
+// @Summary List firmware rollout updates
+// @Tags Firmware
+// @Produce json
+// @Param id path string true "Rollout ID"
+// @Success 200 {array} models.FirmwareUpdate
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/firmware-rollouts/{id}/updates [get]
+func (h *FirmwareHandler) ListUpdates(c *gin.Context) {
+	rollout, ok := h.loadRollout(c)
+	if !ok {
+		return
+	}
+
+	updates, err := h.svc.Updates(c.Request.Context(), rollout.ID)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve firmware updates")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, updates)
+}
+
+// CancelRollout cancels a firmware rollout on the sensors that did not start installing it
+// @Summary Cancel firmware rollout
+// @Tags Firmware
+// @Produce json
+// @Param id path string true "Rollout ID"
+// @Success 200 {object} models.FirmwareRollout
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Failure 503 {object} utils.APIError
+// @Router /api/v1/firmware-rollouts/{id}/cancel [post]
+func (h *FirmwareHandler) CancelRollout(c *gin.Context) {
+	rollout, ok := h.loadRollout(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Cancel(c.Request.Context(), rollout); err != nil {
+		switch {
+		case errors.Is(err, services.ErrRolloutNotActive):
+			utils.Conflict(c, "Firmware rollout is already cancelled")
+		case errors.Is(err, services.ErrOTAUnavailable):
+			utils.ErrorResponse(c, http.StatusServiceUnavailable, "MQTT_UNAVAILABLE", "MQTT broker is not connected")
+		default:
+			abortWithError(c, err, "Failed to cancel firmware rollout")
+		}
+		return
+	}
+
+	updated, err := h.svc.Get(c.Request.Context(), rollout.ID)
+	if err == nil && updated == nil {
+		err = repository.ErrNotFound
+	}
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve firmware rollout")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, updated)
+}
+
+// loadRollout resolves the :id rollout, writing the error response itself
+func (h *FirmwareHandler) loadRollout(c *gin.Context) (*models.FirmwareRollout, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid rollout ID format")
+		return nil, false
+	}
+
+	rollout, err := h.svc.Get(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve firmware rollout")
+		return nil, false
+	}
+	if rollout == nil {
+		utils.NotFound(c, "Firmware rollout not found")
+		return nil, false
+	}
+
+	return rollout, true
+}
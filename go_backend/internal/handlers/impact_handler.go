@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/internal/services"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// ImpactHandler handles emission factors and CO2 impact reports
+type ImpactHandler struct {
+	impactSvc   *services.ImpactService
+	factorRepo  *repository.EmissionFactorRepository
+	userRepo    *repository.UserRepository
+	companyRepo *repository.CompanyRepository
+}
+
+// NewImpactHandler creates a new ImpactHandler
+func NewImpactHandler(
+	impactSvc *services.ImpactService,
+	factorRepo *repository.EmissionFactorRepository,
+	userRepo *repository.UserRepository,
+	companyRepo *repository.CompanyRepository,
+) *ImpactHandler {
+	return &ImpactHandler{
+		impactSvc:   impactSvc,
+		factorRepo:  factorRepo,
+		userRepo:    userRepo,
+		companyRepo: companyRepo,
+	}
+}
+
+// GetUserImpact estimates the CO2e saved by the collections credited to a user
+// @Summary Get user impact
+// @Tags Impact
+// @Produce json
+// @Param id path string true "User ID"
+// @Param from query string false "Period start (YYYY-MM-DD or RFC3339); defaults to 30 days before to"
+// @Param to query string false "Period end (YYYY-MM-DD inclusive, or RFC3339); defaults to now"
+// @Success 200 {object} models.ImpactReport
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/users/{id}/impact [get]
+func (h *ImpactHandler) GetUserImpact(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid user ID format")
+		return
+	}
+
+	period, ok := parsePeriod(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve user")
+		return
+	}
+	if user == nil {
+		utils.NotFound(c, "User not found")
+		return
+	}
+
+	report, err := h.impactSvc.GetUserImpact(c.Request.Context(), id, period)
+	if err != nil {
+		abortWithError(c, err, "Failed to calculate impact")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, report)
+}
+
+// GetCompanyImpact estimates the CO2e saved by the collections of a company's bins
+// @Summary Get company impact
+// @Tags Impact
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param from query string false "Period start (YYYY-MM-DD or RFC3339); defaults to 30 days before to"
+// @Param to query string false "Period end (YYYY-MM-DD inclusive, or RFC3339); defaults to now"
+// @Success 200 {object} models.ImpactReport
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/companies/{id}/impact [get]
+func (h *ImpactHandler) GetCompanyImpact(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid company ID format")
+		return
+	}
+
+	period, ok := parsePeriod(c)
+	if !ok {
+		return
+	}
+
+	company, err := h.companyRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve company")
+		return
+	}
+	if company == nil {
+		utils.NotFound(c, "Company not found")
+		return
+	}
+
+	report, err := h.impactSvc.GetCompanyImpact(c.Request.Context(), id, period)
+	if err != nil {
+		abortWithError(c, err, "Failed to calculate impact")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, report)
+}
+
+// GetCityImpact estimates the CO2e saved by all collections
+// @Summary Get city-wide impact
+// @Tags Impact
+// @Produce json
+// @Param from query string false "Period start (YYYY-MM-DD or RFC3339); defaults to 30 days before to"
+// @Param to query string false "Period end (YYYY-MM-DD inclusive, or RFC3339); defaults to now"
+// @Success 200 {object} models.ImpactReport
+// @Router /api/v1/analytics/impact [get]
+func (h *ImpactHandler) GetCityImpact(c *gin.Context) {
+	period, ok := parsePeriod(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.impactSvc.GetCityImpact(c.Request.Context(), period)
+	if err != nil {
+		abortWithError(c, err, "Failed to calculate impact")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, report)
+}
+
+// ListEmissionFactors retrieves the active emission factors
+// @Summary List emission factors
+// @Tags Impact
+// @Produce json
+// @Success 200 {array} models.EmissionFactor
+// @Router /api/v1/emission-factors [get]
+func (h *ImpactHandler) ListEmissionFactors(c *gin.Context) {
+	factors, err := h.factorRepo.List(c.Request.Context())
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve emission factors")
+		return
+	}
+	if factors == nil {
+		factors = []models.EmissionFactor{}
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, factors)
+}
+
+// CreateEmissionFactor creates the emission factor of a waste type ('*' for all others)
+// @Summary Create emission factor
+// @Tags Impact
+// @Accept json
+// @Produce json
+// @Param factor body models.CreateEmissionFactorRequest true "Emission factor data"
+// @Success 201 {object} models.EmissionFactor
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/emission-factors [post]
+func (h *ImpactHandler) CreateEmissionFactor(c *gin.Context) {
+	var req models.CreateEmissionFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	factor := &models.EmissionFactor{
+		WasteType:   strings.TrimSpace(req.WasteType),
+		KgCO2ePerKg: req.KgCO2ePerKg,
+		Source:      req.Source,
+	}
+
+	if err := h.factorRepo.Create(c.Request.Context(), factor); err != nil {
+		if repository.IsUniqueViolation(err) {
+			utils.Conflict(c, "An active emission factor already exists for this waste type")
+			return
+		}
+		abortWithError(c, err, "Failed to create emission factor")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, factor)
+}
+
+// UpdateEmissionFactor updates an emission factor
+// @Summary Update emission factor
+// @Tags Impact
+// @Accept json
+// @Produce json
+// @Param id path string true "Emission Factor ID"
+// @Param factor body models.UpdateEmissionFactorRequest true "Emission factor data"
+// @Success 200 {object} models.EmissionFactor
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/emission-factors/{id} [put]
+func (h *ImpactHandler) UpdateEmissionFactor(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid emission factor ID format")
+		return
+	}
+
+	var req models.UpdateEmissionFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	factor, err := h.factorRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve emission factor")
+		return
+	}
+	if factor == nil {
+		utils.NotFound(c, "Emission factor not found")
+		return
+	}
+
+	if req.KgCO2ePerKg != nil {
+		factor.KgCO2ePerKg = *req.KgCO2ePerKg
+	}
+	if req.Source != nil {
+		factor.Source = req.Source
+	}
+	if req.IsActive != nil {
+		factor.IsActive = *req.IsActive
+	}
+
+	if err := h.factorRepo.Update(c.Request.Context(), factor); err != nil {
+		if repository.IsUniqueViolation(err) {
+			utils.Conflict(c, "An active emission factor already exists for this waste type")
+			return
+		}
+		abortWithError(c, err, "Failed to update emission factor")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, factor)
+}
+
+// DeleteEmissionFactor deactivates an emission factor
+// @Summary Delete emission factor
+// @Tags Impact
+// @Param id path string true "Emission Factor ID"
+// @Success 204 "No Content"
+// @Router /api/v1/emission-factors/{id} [delete]
+func (h *ImpactHandler) DeleteEmissionFactor(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid emission factor ID format")
+		return
+	}
+
+	if err := h.factorRepo.Delete(c.Request.Context(), id); err != nil {
+		abortWithError(c, err, "Failed to delete emission factor")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/mqtt"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// ingestSource is recorded as the topic of dead-lettered HTTP updates
+const ingestSource = "http/ingest/bin-status"
+
+// Limits on a posted batch of updates
+const (
+	maxIngestBatch     = 500
+	maxIngestBodyBytes = 1 << 20
+)
+
+// IngestHandler accepts bin status updates over HTTP for deployments that cannot reach the MQTT broker
+type IngestHandler struct {
+	mqttClient *mqtt.Client
+}
+
+// NewIngestHandler creates a new IngestHandler
+func NewIngestHandler(mqttClient *mqtt.Client) *IngestHandler {
+	return &IngestHandler{mqttClient: mqttClient}
+}
+
+// IngestBinStatus processes one bin status update or an array of them
+// @Summary Post bin status updates
+// @Description Accepts the MQTT bin status payload, or an array of up to 500 of them, and processes each like a message on bins/{id}/status. Invalid updates are dead-lettered.
+// @Tags Ingestion
+// @Accept json
+// @Produce json
+// @Param updates body []models.BinStatusUpdate true "Bin status update of schema version 1 or 2, or array of updates"
+// @Success 200 {object} models.BinStatusIngestResponse
+// @Failure 400 {object} utils.APIError
+// @Router /api/v1/ingest/bin-status [post]
+func (h *IngestHandler) IngestBinStatus(c *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIngestBodyBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			utils.BadRequest(c, fmt.Sprintf("Request body exceeds %d bytes", maxIngestBodyBytes))
+			return
+		}
+		utils.BadRequest(c, "Failed to read request body")
+		return
+	}
+
+	payloads, err := splitIngestBatch(body)
+	if err != nil {
+		validationError(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	response := models.BinStatusIngestResponse{Results: make([]models.BinStatusIngestResult, 0, len(payloads))}
+	for i, payload := range payloads {
+		result := models.BinStatusIngestResult{Index: i, Status: models.IngestStatusAccepted}
+		var header struct {
+			BinID string `json:"bin_id"`
+		}
+		if json.Unmarshal(payload, &header) == nil {
+			result.BinID = header.BinID
+		}
+
+		if err := h.mqttClient.IngestBinStatus(ctx, ingestSource, payload); err != nil {
+			result.Status = models.IngestStatusFailed
+			if errors.Is(err, mqtt.ErrInvalidPayload) {
+				result.Status = models.IngestStatusInvalid
+			}
+			result.Error = err.Error()
+			response.Rejected++
+		} else {
+			response.Accepted++
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, response)
+}
+
+// splitIngestBatch returns the individual updates of a body holding either
+// a single JSON object or an array of them
+func splitIngestBatch(body []byte) ([]json.RawMessage, error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return nil, errors.New("request body is empty")
+	}
+
+	if trimmed[0] != '[' {
+		return []json.RawMessage{trimmed}, nil
+	}
+
+	var payloads []json.RawMessage
+	if err := json.Unmarshal(trimmed, &payloads); err != nil {
+		return nil, fmt.Errorf("malformed JSON array: %v", err)
+	}
+	if len(payloads) == 0 {
+		return nil, errors.New("batch is empty")
+	}
+	if len(payloads) > maxIngestBatch {
+		return nil, fmt.Errorf("batch holds %d updates, at most %d are accepted", len(payloads), maxIngestBatch)
+	}
+	return payloads, nil
+}
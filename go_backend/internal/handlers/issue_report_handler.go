@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/internal/services"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// IssueReportHandler handles citizen issue reports on bins
+type IssueReportHandler struct {
+	issueSvc   *services.IssueReportService
+	reportRepo *repository.IssueReportRepository
+	binRepo    *repository.BinRepository
+}
+
+// NewIssueReportHandler creates a new IssueReportHandler
+func NewIssueReportHandler(issueSvc *services.IssueReportService, reportRepo *repository.IssueReportRepository, binRepo *repository.BinRepository) *IssueReportHandler {
+	return &IssueReportHandler{issueSvc: issueSvc, reportRepo: reportRepo, binRepo: binRepo}
+}
+
+// CreateIssueReport reports an overflowing, damaged or missing bin
+// @Summary Report a bin issue
+// @Tags Issue Reports
+// @Accept json
+// @Produce json
+// @Param id path string true "Bin ID"
+// @Param report body models.CreateIssueReportRequest true "Issue report data"
+// @Success 201 {object} models.IssueReport
+// @Failure 400 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/bins/{id}/reports [post]
+func (h *IssueReportHandler) CreateIssueReport(c *gin.Context) {
+	bin, ok := h.loadBin(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateIssueReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+	if !req.Type.IsValid() {
+		utils.ValidationError(c, "type must be overflowing, damaged, missing or other")
+		return
+	}
+
+	claims, ok := currentClaims(c)
+	if !ok {
+		utils.Unauthorized(c, "Authentication required")
+		return
+	}
+
+	report := &models.IssueReport{
+		ReportedBy:   claims.SubjectID,
+		ReporterRole: claims.Role,
+		Type:         req.Type,
+		Description:  req.Description,
+		PhotoURL:     req.PhotoURL,
+		Latitude:     req.Latitude,
+		Longitude:    req.Longitude,
+	}
+	if report.Description != nil {
+		if description := strings.TrimSpace(*report.Description); description != "" {
+			report.Description = &description
+		} else {
+			report.Description = nil
+		}
+	}
+
+	if err := h.issueSvc.Create(c.Request.Context(), bin, report); err != nil {
+		if writeUploadError(c, err, "photo_url") {
+			return
+		}
+		abortWithError(c, err, "Failed to create issue report")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, report)
+}
+
+// ListBinIssueReports retrieves the issue reports of a bin
+// @Summary List bin issue reports
+// @Tags Issue Reports
+// @Produce json
+// @Param id path string true "Bin ID"
+// @Param status query string false "Filter by status"
+// @Param type query string false "Filter by issue type"
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Param cursor query string false "Continue after the next_cursor of a previous page"
+// @Success 200 {array} models.IssueReport
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/bins/{id}/reports [get]
+func (h *IssueReportHandler) ListBinIssueReports(c *gin.Context) {
+	bin, ok := h.loadBin(c)
+	if !ok {
+		return
+	}
+
+	filter, ok := parseIssueReportFilter(c)
+	if !ok {
+		return
+	}
+	filter.BinID = &bin.ID
+
+	h.listIssueReports(c, filter)
+}
+
+// ListIssueReports retrieves issue reports; citizens and drivers only see their own
+// @Summary List issue reports
+// @Tags Issue Reports
+// @Produce json
+// @Param bin_id query string false "Filter by bin ID"
+// @Param status query string false "Filter by status"
+// @Param type query string false "Filter by issue type"
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Param cursor query string false "Continue after the next_cursor of a previous page"
+// @Success 200 {array} models.IssueReport
+// @Failure 400 {object} utils.APIError
+// @Router /api/v1/reports [get]
+func (h *IssueReportHandler) ListIssueReports(c *gin.Context) {
+	filter, ok := parseIssueReportFilter(c)
+	if !ok {
+		return
+	}
+	if value := c.Query("bin_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(c, "Invalid bin ID format")
+			return
+		}
+		filter.BinID = &id
+	}
+
+	if claims, ok := currentClaims(c); ok && !isIssueTriager(claims.Role) {
+		filter.ReportedBy = &claims.SubjectID
+	}
+
+	h.listIssueReports(c, filter)
+}
+
+// GetIssueReport retrieves an issue report by ID
+// @Summary Get issue report by ID
+// @Tags Issue Reports
+// @Produce json
+// @Param id path string true "Issue Report ID"
+// @Success 200 {object} models.IssueReport
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/reports/{id} [get]
+func (h *IssueReportHandler) GetIssueReport(c *gin.Context) {
+	report, ok := h.loadIssueReport(c)
+	if !ok {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, report)
+}
+
+// UpdateIssueReportStatus triages an issue report
+// @Summary Update issue report status
+// @Description open → acknowledged → in_progress → resolved; open and acknowledged reports can also be resolved or rejected directly
+// @Tags Issue Reports
+// @Accept json
+// @Produce json
+// @Param id path string true "Issue Report ID"
+// @Param status body models.UpdateIssueStatusRequest true "New status"
+// @Success 200 {object} models.IssueReport
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/reports/{id}/status [put]
+func (h *IssueReportHandler) UpdateIssueReportStatus(c *gin.Context) {
+	var req models.UpdateIssueStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+	if !req.Status.IsValid() {
+		utils.ValidationError(c, "Invalid issue status")
+		return
+	}
+
+	report, ok := h.loadIssueReport(c)
+	if !ok {
+		return
+	}
+
+	claims, _ := currentClaims(c)
+	previous := report.Status
+	err := h.issueSvc.UpdateStatus(c.Request.Context(), report, req.Status, req.Note, claims.SubjectID)
+	if errors.Is(err, services.ErrInvalidIssueTransition) {
+		utils.Conflict(c, fmt.Sprintf("Cannot move a report from %s to %s", previous, req.Status))
+		return
+	}
+	if err != nil {
+		abortWithError(c, err, "Failed to update issue report")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, report)
+}
+
+// listIssueReports writes one page of the issue reports matching the filter
+func (h *IssueReportHandler) listIssueReports(c *gin.Context, filter models.IssueReportFilter) {
+	pagination, ok := parsePage(c, 20)
+	if !ok {
+		return
+	}
+
+	reports, result, err := h.reportRepo.ListFiltered(c.Request.Context(), filter, pagination.page)
+	if err != nil {
+		listError(c, err, "Failed to retrieve issue reports")
+		return
+	}
+	if reports == nil {
+		reports = []models.IssueReport{}
+	}
+
+	utils.SuccessResponseWithPagination(c, reports, pagination.meta(result))
+}
+
+// loadBin resolves the :id bin, writing the error response itself
+func (h *IssueReportHandler) loadBin(c *gin.Context) (*models.Bin, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid bin ID format")
+		return nil, false
+	}
+
+	bin, err := h.binRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve bin")
+		return nil, false
+	}
+	if bin == nil {
+		utils.NotFound(c, "Bin not found")
+		return nil, false
+	}
+	return bin, true
+}
+
+// loadIssueReport resolves the :id report and checks that reporters other
+// than staff only access their own reports. It writes the error response itself.
+func (h *IssueReportHandler) loadIssueReport(c *gin.Context) (*models.IssueReport, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid issue report ID format")
+		return nil, false
+	}
+
+	report, err := h.reportRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve issue report")
+		return nil, false
+	}
+	if report == nil {
+		utils.NotFound(c, "Issue report not found")
+		return nil, false
+	}
+
+	if claims, ok := currentClaims(c); ok && !isIssueTriager(claims.Role) && claims.SubjectID != report.ReportedBy {
+		utils.Forbidden(c, "You can only view your own reports")
+		return nil, false
+	}
+
+	return report, true
+}
+
+// parseIssueReportFilter reads the status and type filters of a report listing
+func parseIssueReportFilter(c *gin.Context) (models.IssueReportFilter, bool) {
+	var filter models.IssueReportFilter
+	if value := c.Query("status"); value != "" {
+		status := models.IssueStatus(value)
+		if !status.IsValid() {
+			utils.BadRequest(c, "Invalid issue status")
+			return filter, false
+		}
+		filter.Status = &status
+	}
+	if value := c.Query("type"); value != "" {
+		issueType := models.IssueType(value)
+		if !issueType.IsValid() {
+			utils.BadRequest(c, "Invalid issue type")
+			return filter, false
+		}
+		filter.Type = &issueType
+	}
+	return filter, true
+}
+
+// isIssueTriager reports whether the role triages every issue report
+func isIssueTriager(role models.Role) bool {
+	return role == models.RoleAdmin || role == models.RoleDispatcher
+}
@@ -0,0 +1,308 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/internal/services"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// MaintenanceHandler handles the maintenance of bins
+type MaintenanceHandler struct {
+	svc     *services.MaintenanceService
+	binRepo *repository.BinRepository
+}
+
+// NewMaintenanceHandler creates a new MaintenanceHandler
+func NewMaintenanceHandler(svc *services.MaintenanceService, binRepo *repository.BinRepository) *MaintenanceHandler {
+	return &MaintenanceHandler{svc: svc, binRepo: binRepo}
+}
+
+// ScheduleMaintenance schedules maintenance of a bin
+// @Summary Schedule bin maintenance
+// @Description Maintenance without a scheduled_for in the future starts at once. The bin is left out of dispatch and collection planning while it is in progress.
+// @Tags Maintenance
+// @Accept json
+// @Produce json
+// @Param id path string true "Bin ID"
+// @Param maintenance body models.ScheduleMaintenanceRequest true "Maintenance"
+// @Success 201 {object} models.BinMaintenance
+// @Failure 400 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/bins/{id}/maintenance [post]
+func (h *MaintenanceHandler) ScheduleMaintenance(c *gin.Context) {
+	bin, ok := h.loadBin(c)
+	if !ok {
+		return
+	}
+
+	var req models.ScheduleMaintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+	if !req.Type.IsValid() {
+		utils.ValidationError(c, "type must be damaged, cleaning or sensor_fault")
+		return
+	}
+
+	maintenance, err := h.svc.Schedule(c.Request.Context(), bin, &req, assignedBy(c))
+	if errors.Is(err, services.ErrBinRetired) {
+		utils.Conflict(c, "Bin is retired")
+		return
+	}
+	if err != nil {
+		abortWithError(c, err, "Failed to schedule maintenance")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, maintenance)
+}
+
+// ListBinMaintenance retrieves the maintenance of a bin
+// @Summary List bin maintenance
+// @Tags Maintenance
+// @Produce json
+// @Param id path string true "Bin ID"
+// @Param status query string false "scheduled, in_progress, completed or cancelled"
+// @Param type query string false "damaged, cleaning or sensor_fault"
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Param cursor query string false "Continue after the next_cursor of a previous page"
+// @Success 200 {array} models.BinMaintenance
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/bins/{id}/maintenance [get]
+func (h *MaintenanceHandler) ListBinMaintenance(c *gin.Context) {
+	bin, ok := h.loadBin(c)
+	if !ok {
+		return
+	}
+
+	filter, ok := parseMaintenanceFilter(c)
+	if !ok {
+		return
+	}
+	filter.BinID = &bin.ID
+
+	h.listMaintenance(c, filter)
+}
+
+// ListMaintenance retrieves the maintenance of all bins
+// @Summary List maintenance
+// @Tags Maintenance
+// @Produce json
+// @Param bin_id query string false "Filter by bin ID"
+// @Param status query string false "scheduled, in_progress, completed or cancelled"
+// @Param type query string false "damaged, cleaning or sensor_fault"
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Param cursor query string false "Continue after the next_cursor of a previous page"
+// @Success 200 {array} models.BinMaintenance
+// @Failure 400 {object} utils.APIError
+// @Router /api/v1/maintenance [get]
+func (h *MaintenanceHandler) ListMaintenance(c *gin.Context) {
+	filter, ok := parseMaintenanceFilter(c)
+	if !ok {
+		return
+	}
+	if value := c.Query("bin_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(c, "Invalid bin ID format")
+			return
+		}
+		filter.BinID = &id
+	}
+
+	h.listMaintenance(c, filter)
+}
+
+// GetMaintenance retrieves a maintenance by ID
+// @Summary Get maintenance by ID
+// @Tags Maintenance
+// @Produce json
+// @Param id path string true "Maintenance ID"
+// @Success 200 {object} models.BinMaintenance
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/maintenance/{id} [get]
+func (h *MaintenanceHandler) GetMaintenance(c *gin.Context) {
+	maintenance, ok := h.loadMaintenance(c)
+	if !ok {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, maintenance)
+}
+
+// StartMaintenance starts a scheduled maintenance ahead of time
+// @Summary Start maintenance
+// @Tags Maintenance
+// @Produce json
+// @Param id path string true "Maintenance ID"
+// @Success 200 {object} models.BinMaintenance
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/maintenance/{id}/start [post]
+func (h *MaintenanceHandler) StartMaintenance(c *gin.Context) {
+	maintenance, ok := h.loadMaintenance(c)
+	if !ok {
+		return
+	}
+
+	previous := maintenance.Status
+	err := h.svc.Start(c.Request.Context(), maintenance)
+	h.writeTransition(c, maintenance, previous, models.BinMaintenanceInProgress, err)
+}
+
+// CompleteMaintenance completes a maintenance, putting its bin back in service
+// @Summary Complete maintenance
+// @Tags Maintenance
+// @Accept json
+// @Produce json
+// @Param id path string true "Maintenance ID"
+// @Param completion body models.CompleteMaintenanceRequest false "Resolution"
+// @Success 200 {object} models.BinMaintenance
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/maintenance/{id}/complete [post]
+func (h *MaintenanceHandler) CompleteMaintenance(c *gin.Context) {
+	h.close(c, models.BinMaintenanceCompleted, h.svc.Complete)
+}
+
+// CancelMaintenance cancels a maintenance, putting its bin back in service
+// @Summary Cancel maintenance
+// @Tags Maintenance
+// @Accept json
+// @Produce json
+// @Param id path string true "Maintenance ID"
+// @Param cancellation body models.CompleteMaintenanceRequest false "Reason"
+// @Success 200 {object} models.BinMaintenance
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/maintenance/{id}/cancel [post]
+func (h *MaintenanceHandler) CancelMaintenance(c *gin.Context) {
+	h.close(c, models.BinMaintenanceCancelled, h.svc.Cancel)
+}
+
+// close completes or cancels the :id maintenance with the optional resolution of the body
+func (h *MaintenanceHandler) close(c *gin.Context, status models.BinMaintenanceStatus, closeFn func(ctx context.Context, maintenance *models.BinMaintenance, resolution *string, completedBy *uuid.UUID) error) {
+	var req models.CompleteMaintenanceRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			validationError(c, err)
+			return
+		}
+	}
+
+	maintenance, ok := h.loadMaintenance(c)
+	if !ok {
+		return
+	}
+
+	previous := maintenance.Status
+	err := closeFn(c.Request.Context(), maintenance, req.Resolution, assignedBy(c))
+	h.writeTransition(c, maintenance, previous, status, err)
+}
+
+// writeTransition writes the response to a maintenance status change
+func (h *MaintenanceHandler) writeTransition(c *gin.Context, maintenance *models.BinMaintenance, previous, status models.BinMaintenanceStatus, err error) {
+	if errors.Is(err, services.ErrInvalidMaintenanceTransition) {
+		utils.Conflict(c, fmt.Sprintf("Cannot move a maintenance from %s to %s", previous, status))
+		return
+	}
+	if err != nil {
+		abortWithError(c, err, "Failed to update maintenance")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, maintenance)
+}
+
+// listMaintenance writes one page of the maintenance matching the filter
+func (h *MaintenanceHandler) listMaintenance(c *gin.Context, filter models.MaintenanceFilter) {
+	pagination, ok := parsePage(c, 20)
+	if !ok {
+		return
+	}
+
+	records, result, err := h.svc.List(c.Request.Context(), filter, pagination.page)
+	if err != nil {
+		listError(c, err, "Failed to retrieve maintenance")
+		return
+	}
+	if records == nil {
+		records = []models.BinMaintenance{}
+	}
+
+	utils.SuccessResponseWithPagination(c, records, pagination.meta(result))
+}
+
+// loadBin resolves the :id bin, writing the error response itself
+func (h *MaintenanceHandler) loadBin(c *gin.Context) (*models.Bin, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid bin ID format")
+		return nil, false
+	}
+
+	bin, err := h.binRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve bin")
+		return nil, false
+	}
+	if bin == nil {
+		utils.NotFound(c, "Bin not found")
+		return nil, false
+	}
+	return bin, true
+}
+
+// loadMaintenance resolves the :id maintenance, writing the error response itself
+func (h *MaintenanceHandler) loadMaintenance(c *gin.Context) (*models.BinMaintenance, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid maintenance ID format")
+		return nil, false
+	}
+
+	maintenance, err := h.svc.Get(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve maintenance")
+		return nil, false
+	}
+	if maintenance == nil {
+		utils.NotFound(c, "Maintenance not found")
+		return nil, false
+	}
+	return maintenance, true
+}
+
+// parseMaintenanceFilter reads the status and type filters of a maintenance listing
+func parseMaintenanceFilter(c *gin.Context) (models.MaintenanceFilter, bool) {
+	var filter models.MaintenanceFilter
+	if value := c.Query("status"); value != "" {
+		status := models.BinMaintenanceStatus(value)
+		if !status.IsValid() {
+			utils.BadRequest(c, "status must be scheduled, in_progress, completed or cancelled")
+			return filter, false
+		}
+		filter.Status = &status
+	}
+	if value := c.Query("type"); value != "" {
+		maintenanceType := models.MaintenanceType(value)
+		if !maintenanceType.IsValid() {
+			utils.BadRequest(c, "type must be damaged, cleaning or sensor_fault")
+			return filter, false
+		}
+		filter.Type = &maintenanceType
+	}
+	return filter, true
+}
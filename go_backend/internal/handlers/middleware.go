@@ -1,23 +1,68 @@
 package handlers
 
 import (
+	"errors"
 	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/auth"
+	"github.com/smartwaste/backend/internal/config"
+	"github.com/smartwaste/backend/internal/metrics"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/internal/services"
+	"github.com/smartwaste/backend/pkg/utils"
 )
 
-// CORSMiddleware handles Cross-Origin Resource Sharing
-func CORSMiddleware() gin.HandlerFunc {
+// CORSMiddleware answers the cross-origin requests of the allowed origins,
+// preflights included. Requests from other origins get no CORS headers, so
+// browsers do not let their callers read the response.
+func CORSMiddleware(cfg *config.CORSConfig) gin.HandlerFunc {
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	anyOrigin := cfg.AllowsAnyOrigin()
+
 	return func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, X-Request-ID")
-		c.Header("Access-Control-Expose-Headers", "Content-Length")
-		c.Header("Access-Control-Max-Age", "86400")
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		c.Writer.Header().Add("Vary", "Origin")
+		if !cfg.AllowsOrigin(origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if anyOrigin {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+			if cfg.AllowCredentials {
+				c.Header("Access-Control-Allow-Credentials", "true")
+			}
+		}
+		c.Header("Access-Control-Expose-Headers", "Content-Length, Retry-After, X-RateLimit-Limit, X-RateLimit-Remaining, X-Request-ID")
+
+		if preflight {
+			c.Header("Access-Control-Allow-Methods", methods)
+			c.Header("Access-Control-Allow-Headers", headers)
+			c.Header("Access-Control-Max-Age", maxAge)
+			c.AbortWithStatus(http.StatusNoContent)
 			return
 		}
 
@@ -25,6 +70,27 @@ func CORSMiddleware() gin.HandlerFunc {
 	}
 }
 
+// SecurityHeadersMiddleware sets the headers keeping browsers from sniffing
+// content types, framing the API or leaking its URLs, and from calling it
+// over plain HTTP for hstsMaxAge, unless 0
+func SecurityHeadersMiddleware(hstsMaxAge time.Duration) gin.HandlerFunc {
+	hsts := ""
+	if hstsMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(int(hstsMaxAge.Seconds())) + "; includeSubDomains"
+	}
+
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		header.Set("X-Content-Type-Options", "nosniff")
+		header.Set("X-Frame-Options", "DENY")
+		header.Set("Referrer-Policy", "no-referrer")
+		if hsts != "" {
+			header.Set("Strict-Transport-Security", hsts)
+		}
+		c.Next()
+	}
+}
+
 // RequestIDMiddleware adds a unique request ID to each request
 func RequestIDMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -55,7 +121,7 @@ func LoggerMiddleware() gin.HandlerFunc {
 		statusCode := c.Writer.Status()
 
 		if raw != "" {
-			path = path + "?" + raw
+			path = path + "?" + redactQuery(raw)
 		}
 
 		log.Printf("[%d] %s %s | %v | %s",
@@ -63,6 +129,34 @@ func LoggerMiddleware() gin.HandlerFunc {
 	}
 }
 
+// MetricsMiddleware records request counts and latency per route template
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		startTime := time.Now()
+
+		c.Next()
+
+		// Label by template (/bins/:id) rather than path to keep cardinality bounded
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := c.Request.Method
+		metrics.HTTPRequests.WithLabelValues(method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(method, route).Observe(time.Since(startTime).Seconds())
+	}
+}
+
+// redactQuery hides access tokens passed in the query string from the logs
+func redactQuery(raw string) string {
+	values, err := url.ParseQuery(raw)
+	if err != nil || !values.Has("access_token") {
+		return raw
+	}
+	values.Set("access_token", "REDACTED")
+	return values.Encode()
+}
+
 // RecoveryMiddleware recovers from panics
 func RecoveryMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -82,6 +176,273 @@ func RecoveryMiddleware() gin.HandlerFunc {
 	}
 }
 
+// ErrorMiddleware writes the response of a request a handler aborted with
+// abortWithError: repository errors a client can act on get 404, 409 or 422
+// with their message, any other error a 500 with the handler's message
+func ErrorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		last := c.Errors.Last()
+		err := repository.Translate(last.Err)
+
+		var constraintErr *repository.ConstraintError
+		switch {
+		case errors.As(err, &constraintErr) && errors.Is(err, repository.ErrConflict):
+			utils.Conflict(c, constraintErr.Message)
+		case errors.As(err, &constraintErr):
+			utils.UnprocessableEntity(c, constraintErr.Message)
+		case errors.Is(err, repository.ErrNotFound):
+			utils.NotFound(c, "Not found")
+		default:
+			message, _ := last.Meta.(string)
+			if message == "" {
+				message = "An unexpected error occurred"
+			}
+			log.Printf("%s %s: %s: %v", c.Request.Method, c.FullPath(), message, last.Err)
+			utils.InternalError(c, message)
+		}
+	}
+}
+
+// abortWithError aborts the request with err, for ErrorMiddleware to write
+// the response; message describes the failure when err is unexpected
+func abortWithError(c *gin.Context, err error, message string) {
+	_ = c.Error(err).SetMeta(message)
+	c.Abort()
+}
+
+// AuthMiddleware validates the bearer access token, or the API key in the
+// X-API-Key header, and stores the principal's claims
+func AuthMiddleware(tokens *auth.TokenManager, apiKeys *services.APIKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key := c.GetHeader("X-API-Key"); key != "" {
+			authenticateAPIKey(c, apiKeys, key)
+			return
+		}
+
+		header := c.GetHeader("Authorization")
+		tokenString, found := strings.CutPrefix(header, "Bearer ")
+		if !found || tokenString == "" {
+			utils.Unauthorized(c, "Missing bearer token")
+			c.Abort()
+			return
+		}
+
+		authenticate(c, tokens, tokenString)
+	}
+}
+
+// StreamAuthMiddleware is AuthMiddleware for streaming endpoints (WebSocket,
+// Server-Sent Events) whose browser clients cannot set headers: it also
+// accepts the token in the access_token query parameter
+func StreamAuthMiddleware(tokens *auth.TokenManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !found || tokenString == "" {
+			tokenString = c.Query("access_token")
+		}
+		if tokenString == "" {
+			utils.Unauthorized(c, "Missing access token")
+			c.Abort()
+			return
+		}
+
+		authenticate(c, tokens, tokenString)
+	}
+}
+
+// authenticate parses the token and stores its claims on the request
+func authenticate(c *gin.Context, tokens *auth.TokenManager, tokenString string) {
+	claims, err := tokens.Parse(tokenString)
+	if err != nil {
+		utils.Unauthorized(c, "Invalid or expired token")
+		c.Abort()
+		return
+	}
+
+	setPrincipal(c, claims)
+	c.Next()
+}
+
+// setPrincipal stores the claims on the request and confines it to the
+// organization of the principal
+func setPrincipal(c *gin.Context, claims *auth.Claims) {
+	c.Set("claims", claims)
+	ctx := auth.WithClaims(c.Request.Context(), claims)
+	c.Request = c.Request.WithContext(auth.WithOrganization(ctx, claims.OrganizationID))
+}
+
+// authenticateAPIKey validates the key, enforces its rate limit and stores
+// the claims of the role it was issued for
+func authenticateAPIKey(c *gin.Context, apiKeys *services.APIKeyService, plaintext string) {
+	key, err := apiKeys.Authenticate(c.Request.Context(), plaintext)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidAPIKey) {
+			utils.Unauthorized(c, "Invalid, revoked or expired API key")
+		} else {
+			utils.InternalError(c, "Failed to verify API key")
+		}
+		c.Abort()
+		return
+	}
+
+	remaining, retryAfter, ok := apiKeys.Allow(key)
+	c.Header("X-RateLimit-Limit", strconv.Itoa(key.RateLimitPerMinute))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	if !ok {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		utils.TooManyRequests(c, "API key rate limit exceeded")
+		c.Abort()
+		return
+	}
+
+	setPrincipal(c, auth.ClaimsForAPIKey(key))
+	c.Next()
+}
+
+// RequireRoles allows the request only if the principal holds one of the roles
+func RequireRoles(roles ...models.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := currentClaims(c)
+		if !ok {
+			utils.Unauthorized(c, "Authentication required")
+			c.Abort()
+			return
+		}
+		if !claims.HasRole(roles...) {
+			utils.Forbidden(c, "Insufficient permissions")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireSelfOrRoles allows the request if the principal is the resource
+// identified by the given path parameter, or holds one of the roles
+func RequireSelfOrRoles(param string, roles ...models.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := currentClaims(c)
+		if !ok {
+			utils.Unauthorized(c, "Authentication required")
+			c.Abort()
+			return
+		}
+		if claims.SubjectID.String() != c.Param(param) && !claims.HasRole(roles...) {
+			utils.Forbidden(c, "Insufficient permissions")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireCompanyOrRoles allows the request if the principal holds the company
+// role for the company identified by the given route parameter, or holds one
+// of the roles
+func RequireCompanyOrRoles(param string, roles ...models.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := currentClaims(c)
+		if !ok {
+			utils.Unauthorized(c, "Authentication required")
+			c.Abort()
+			return
+		}
+		ownCompany := claims.Role == models.RoleCompany &&
+			claims.CompanyID != nil && claims.CompanyID.String() == c.Param(param)
+		if !ownCompany && !claims.HasRole(roles...) {
+			utils.Forbidden(c, "Insufficient permissions")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireCompanyMember allows company principals acting with one of the
+// member roles within their company; other principals pass through, their
+// access being decided by the other route checks
+func RequireCompanyMember(roles ...models.CompanyMemberRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := currentClaims(c)
+		if !ok {
+			utils.Unauthorized(c, "Authentication required")
+			c.Abort()
+			return
+		}
+		if claims.Role == models.RoleCompany {
+			memberRole := claims.MemberRole()
+			allowed := false
+			for _, role := range roles {
+				allowed = allowed || memberRole == role
+			}
+			if !allowed {
+				utils.Forbidden(c, "Insufficient company permissions")
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// RequireOrganization allows the request only if the principal belongs to the
+// given organization
+func RequireOrganization(organizationID uuid.UUID) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := currentClaims(c)
+		if !ok {
+			utils.Unauthorized(c, "Authentication required")
+			c.Abort()
+			return
+		}
+		if claims.OrganizationID != organizationID {
+			utils.Forbidden(c, "Insufficient permissions")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// principalCompany returns the company a company principal acts for, or nil
+// for every other principal
+func principalCompany(c *gin.Context) *uuid.UUID {
+	if claims, ok := currentClaims(c); ok && claims.Role == models.RoleCompany {
+		if claims.CompanyID == nil {
+			return &uuid.Nil
+		}
+		return claims.CompanyID
+	}
+	return nil
+}
+
+// requireOwnCompany checks that a company principal only changes records of
+// its own company, not global ones or another company's. It writes the error
+// response itself.
+func requireOwnCompany(c *gin.Context, companyID *uuid.UUID) bool {
+	own := principalCompany(c)
+	if own != nil && (companyID == nil || *companyID != *own) {
+		utils.Forbidden(c, "You can only manage your own company's pricing")
+		return false
+	}
+	return true
+}
+
+// currentClaims returns the claims of the authenticated principal
+func currentClaims(c *gin.Context) (*auth.Claims, bool) {
+	value, exists := c.Get("claims")
+	if !exists {
+		return nil, false
+	}
+	claims, ok := value.(*auth.Claims)
+	return claims, ok
+}
+
 // generateRequestID generates a unique request ID
 func generateRequestID() string {
 	return time.Now().Format("20060102150405") + "-" + randomString(8)
@@ -0,0 +1,492 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/internal/services"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// NotificationHandler handles the driver and user notification inboxes and
+// the channels notifications are delivered over
+type NotificationHandler struct {
+	repo            *repository.NotificationRepository
+	notificationSvc *services.NotificationService
+}
+
+// NewNotificationHandler creates a new NotificationHandler
+func NewNotificationHandler(repo *repository.NotificationRepository, notificationSvc *services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{repo: repo, notificationSvc: notificationSvc}
+}
+
+// ListNotifications retrieves a driver's notifications
+// @Summary List driver notifications
+// @Tags Drivers
+// @Produce json
+// @Param id path string true "Driver ID"
+// @Param unread query bool false "Only unread notifications"
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Param cursor query string false "Continue after the next_cursor of a previous page"
+// @Success 200 {array} models.NotificationResponse
+// @Router /api/v1/drivers/{id}/notifications [get]
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	driverID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid driver ID format")
+		return
+	}
+
+	pagination, ok := parsePage(c, 20)
+	if !ok {
+		return
+	}
+	unreadOnly := c.Query("unread") == "true"
+
+	notifications, result, err := h.repo.ListByDriver(c.Request.Context(), driverID, unreadOnly, pagination.page)
+	if err != nil {
+		listError(c, err, "Failed to retrieve notifications")
+		return
+	}
+
+	responses := make([]models.NotificationResponse, len(notifications))
+	for i, n := range notifications {
+		responses[i] = *n.ToResponse()
+	}
+
+	utils.SuccessResponseWithPagination(c, responses, pagination.meta(result))
+}
+
+// ListUserNotifications retrieves a user's notifications
+// @Summary List user notifications
+// @Tags Users
+// @Produce json
+// @Param id path string true "User ID"
+// @Param unread query bool false "Only unread notifications"
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Param cursor query string false "Continue after the next_cursor of a previous page"
+// @Success 200 {array} models.NotificationResponse
+// @Router /api/v1/users/{id}/notifications [get]
+func (h *NotificationHandler) ListUserNotifications(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid user ID format")
+		return
+	}
+
+	pagination, ok := parsePage(c, 20)
+	if !ok {
+		return
+	}
+	unreadOnly := c.Query("unread") == "true"
+
+	notifications, result, err := h.repo.ListByUser(c.Request.Context(), userID, unreadOnly, pagination.page)
+	if err != nil {
+		listError(c, err, "Failed to retrieve notifications")
+		return
+	}
+
+	responses := make([]models.NotificationResponse, len(notifications))
+	for i, n := range notifications {
+		responses[i] = *n.ToResponse()
+	}
+
+	utils.SuccessResponseWithPagination(c, responses, pagination.meta(result))
+}
+
+// GetUnreadCount retrieves the number of unread notifications
+// @Summary Get unread notification count
+// @Tags Drivers
+// @Produce json
+// @Param id path string true "Driver ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/drivers/{id}/notifications/unread-count [get]
+func (h *NotificationHandler) GetUnreadCount(c *gin.Context) {
+	driverID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid driver ID format")
+		return
+	}
+
+	count, err := h.repo.CountUnread(c.Request.Context(), driverID)
+	if err != nil {
+		abortWithError(c, err, "Failed to count notifications")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"driver_id":    driverID,
+		"unread_count": count,
+	})
+}
+
+// MarkAsRead marks a single notification as read
+// @Summary Mark notification as read
+// @Tags Drivers
+// @Produce json
+// @Param id path string true "Driver ID"
+// @Param notificationId path string true "Notification ID"
+// @Success 200 {object} models.NotificationResponse
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/drivers/{id}/notifications/{notificationId}/read [put]
+func (h *NotificationHandler) MarkAsRead(c *gin.Context) {
+	notification, ok := h.loadNotification(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.MarkRead(c.Request.Context(), notification.ID); err != nil {
+		abortWithError(c, err, "Failed to update notification")
+		return
+	}
+
+	updated, err := h.repo.GetByID(c.Request.Context(), notification.ID)
+	if err == nil && updated == nil {
+		err = repository.ErrNotFound
+	}
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve notification")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, updated.ToResponse())
+}
+
+// AcknowledgeNotification acknowledges an alert sent to a driver, which stops
+// it from escalating to other drivers and the dispatchers
+// @Summary Acknowledge driver alert
+// @Tags Drivers
+// @Produce json
+// @Param id path string true "Driver ID"
+// @Param notificationId path string true "Notification ID"
+// @Success 200 {object} models.NotificationResponse
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/drivers/{id}/notifications/{notificationId}/acknowledge [post]
+func (h *NotificationHandler) AcknowledgeNotification(c *gin.Context) {
+	notification, ok := h.loadNotification(c)
+	if !ok {
+		return
+	}
+	h.acknowledge(c, notification)
+}
+
+// AcknowledgeUserNotification acknowledges an alert escalated to a user
+// @Summary Acknowledge user alert
+// @Tags Users
+// @Produce json
+// @Param id path string true "User ID"
+// @Param notificationId path string true "Notification ID"
+// @Success 200 {object} models.NotificationResponse
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/users/{id}/notifications/{notificationId}/acknowledge [post]
+func (h *NotificationHandler) AcknowledgeUserNotification(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid user ID format")
+		return
+	}
+	notificationID, err := uuid.Parse(c.Param("notificationId"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid notification ID format")
+		return
+	}
+
+	notification, err := h.repo.GetUserNotification(c.Request.Context(), notificationID, userID)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve notification")
+		return
+	}
+	if notification == nil {
+		utils.NotFound(c, "Notification not found")
+		return
+	}
+	h.acknowledge(c, notification)
+}
+
+// MarkAllAsRead marks all of a driver's notifications as read
+// @Summary Mark all notifications as read
+// @Tags Drivers
+// @Produce json
+// @Param id path string true "Driver ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/drivers/{id}/notifications/read-all [put]
+func (h *NotificationHandler) MarkAllAsRead(c *gin.Context) {
+	driverID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid driver ID format")
+		return
+	}
+
+	updated, err := h.repo.MarkAllRead(c.Request.Context(), driverID)
+	if err != nil {
+		abortWithError(c, err, "Failed to update notifications")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"driver_id": driverID,
+		"updated":   updated,
+	})
+}
+
+// DeleteNotification deletes a notification
+// @Summary Delete notification
+// @Tags Drivers
+// @Param id path string true "Driver ID"
+// @Param notificationId path string true "Notification ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/drivers/{id}/notifications/{notificationId} [delete]
+func (h *NotificationHandler) DeleteNotification(c *gin.Context) {
+	notification, ok := h.loadNotification(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Delete(c.Request.Context(), notification.ID); err != nil {
+		abortWithError(c, err, "Failed to delete notification")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetDriverChannels retrieves the channels a driver is notified over
+// @Summary Get driver notification channels
+// @Description Channels tried in order until one delivers; the configured defaults when the driver chose none
+// @Tags Drivers
+// @Produce json
+// @Param id path string true "Driver ID"
+// @Success 200 {object} models.NotificationChannelPreferences
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/drivers/{id}/notification-channels [get]
+func (h *NotificationHandler) GetDriverChannels(c *gin.Context) {
+	h.getChannels(c, models.NotificationRecipientDriver, "Invalid driver ID format")
+}
+
+// SetDriverChannels sets the channels a driver is notified over
+// @Summary Set driver notification channels
+// @Tags Drivers
+// @Accept json
+// @Produce json
+// @Param id path string true "Driver ID"
+// @Param channels body models.SetNotificationChannelsRequest true "Channels in the order they are tried"
+// @Success 200 {object} models.NotificationChannelPreferences
+// @Failure 400 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/drivers/{id}/notification-channels [put]
+func (h *NotificationHandler) SetDriverChannels(c *gin.Context) {
+	h.setChannels(c, models.NotificationRecipientDriver, "Invalid driver ID format")
+}
+
+// GetUserChannels retrieves the channels a user is notified over
+// @Summary Get user notification channels
+// @Description Channels tried in order until one delivers; the configured defaults when the user chose none
+// @Tags Users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} models.NotificationChannelPreferences
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/users/{id}/notification-channels [get]
+func (h *NotificationHandler) GetUserChannels(c *gin.Context) {
+	h.getChannels(c, models.NotificationRecipientUser, "Invalid user ID format")
+}
+
+// SetUserChannels sets the channels a user is notified over
+// @Summary Set user notification channels
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param channels body models.SetNotificationChannelsRequest true "Channels in the order they are tried"
+// @Success 200 {object} models.NotificationChannelPreferences
+// @Failure 400 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/users/{id}/notification-channels [put]
+func (h *NotificationHandler) SetUserChannels(c *gin.Context) {
+	h.setChannels(c, models.NotificationRecipientUser, "Invalid user ID format")
+}
+
+// GetDriverSettings retrieves what a driver wants delivered to them
+// @Summary Get driver notification settings
+// @Description Notification types, quiet hours and hourly limit of the deliveries over the driver's channels; everything is delivered when the driver saved none
+// @Tags Drivers
+// @Produce json
+// @Param id path string true "Driver ID"
+// @Success 200 {object} models.DriverNotificationSettings
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/drivers/{id}/notification-settings [get]
+func (h *NotificationHandler) GetDriverSettings(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid driver ID format")
+		return
+	}
+
+	settings, err := h.notificationSvc.DriverSettings(c.Request.Context(), id)
+	if err != nil {
+		writeRecipientError(c, err, "Failed to retrieve notification settings")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, settings)
+}
+
+// UpdateDriverSettings replaces what a driver wants delivered to them
+// @Summary Update driver notification settings
+// @Description Notifications withheld by the settings are still saved to the driver's inbox
+// @Tags Drivers
+// @Accept json
+// @Produce json
+// @Param id path string true "Driver ID"
+// @Param settings body models.UpdateDriverNotificationSettingsRequest true "Notification settings"
+// @Success 200 {object} models.DriverNotificationSettings
+// @Failure 400 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/drivers/{id}/notification-settings [put]
+func (h *NotificationHandler) UpdateDriverSettings(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid driver ID format")
+		return
+	}
+
+	var req models.UpdateDriverNotificationSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+	for _, notificationType := range req.Types {
+		if !notificationType.IsValid() {
+			utils.ValidationError(c, "Unknown notification type: "+string(notificationType))
+			return
+		}
+	}
+	if req.QuietHoursStart != nil {
+		if !isShiftTime(*req.QuietHoursStart) || !isShiftTime(*req.QuietHoursEnd) {
+			utils.ValidationError(c, "quiet_hours_start and quiet_hours_end must be HH:MM")
+			return
+		}
+		if *req.QuietHoursStart == *req.QuietHoursEnd {
+			utils.ValidationError(c, "quiet_hours_start and quiet_hours_end must differ")
+			return
+		}
+	}
+	if req.Timezone != "" {
+		if _, err := time.LoadLocation(req.Timezone); err != nil {
+			utils.ValidationError(c, "Unknown time zone: "+req.Timezone)
+			return
+		}
+	}
+
+	settings, err := h.notificationSvc.UpdateDriverSettings(c.Request.Context(), id, &req)
+	if err != nil {
+		writeRecipientError(c, err, "Failed to save notification settings")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, settings)
+}
+
+// getChannels writes the channel preferences of the :id recipient
+func (h *NotificationHandler) getChannels(c *gin.Context, recipientType models.NotificationRecipientType, invalidID string) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, invalidID)
+		return
+	}
+
+	preferences, err := h.notificationSvc.ChannelPreferences(c.Request.Context(), recipientType, id)
+	if err != nil {
+		writeRecipientError(c, err, "Failed to retrieve notification channels")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, preferences)
+}
+
+// setChannels saves the channel preferences of the :id recipient
+func (h *NotificationHandler) setChannels(c *gin.Context, recipientType models.NotificationRecipientType, invalidID string) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, invalidID)
+		return
+	}
+
+	var req models.SetNotificationChannelsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	preferences, err := h.notificationSvc.SetChannelPreferences(c.Request.Context(), recipientType, id, req.Channels)
+	if err != nil {
+		writeRecipientError(c, err, "Failed to save notification channels")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, preferences)
+}
+
+// writeRecipientError writes the response for an error with a notification
+// recipient
+func writeRecipientError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrDriverNotFound):
+		utils.NotFound(c, "Driver not found")
+	case errors.Is(err, services.ErrUserNotFound):
+		utils.NotFound(c, "User not found")
+	default:
+		abortWithError(c, err, message)
+	}
+}
+
+// acknowledge acknowledges an alert notification and writes it
+func (h *NotificationHandler) acknowledge(c *gin.Context, notification *models.Notification) {
+	if notification.AlertID == nil {
+		utils.Conflict(c, "Notification is not an alert")
+		return
+	}
+	if err := h.repo.Acknowledge(c.Request.Context(), notification); err != nil {
+		abortWithError(c, err, "Failed to acknowledge notification")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, notification.ToResponse())
+}
+
+// loadNotification resolves the notification from the path and checks that it
+// belongs to the driver in the path. It writes the error response itself.
+func (h *NotificationHandler) loadNotification(c *gin.Context) (*models.Notification, bool) {
+	driverID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid driver ID format")
+		return nil, false
+	}
+	notificationID, err := uuid.Parse(c.Param("notificationId"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid notification ID format")
+		return nil, false
+	}
+
+	notification, err := h.repo.GetByID(c.Request.Context(), notificationID)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve notification")
+		return nil, false
+	}
+	if notification == nil || notification.DriverID == nil || *notification.DriverID != driverID {
+		utils.NotFound(c, "Notification not found")
+		return nil, false
+	}
+
+	return notification, true
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+	"github.com/smartwaste/backend/pkg/utils"
+	"github.com/smartwaste/pkg/validation"
+	swaggerFiles "github.com/swaggo/files"
+)
+
+// skipBodyValidation marks operations whose handlers validate the body
+// themselves, e.g. to dead-letter invalid items instead of rejecting the request
+const skipBodyValidation = "x-skip-body-validation"
+
+// swaggerIndex loads Swagger UI from the bundled assets and points it at the spec
+var swaggerIndex = []byte(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <title>Smart Waste Management API</title>
+  <link rel="stylesheet" href="swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "doc.yaml", dom_id: "#swagger-ui", deepLinking: true });
+  </script>
+</body>
+</html>
+`)
+
+// NewOpenAPIRouter parses and validates an OpenAPI document and returns a
+// router matching its operations against request paths under basePath
+func NewOpenAPIRouter(spec []byte, basePath string) (routers.Router, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI spec: %w", err)
+	}
+	if err := doc.Validate(loader.Context); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI spec: %w", err)
+	}
+
+	// Match on the path alone so requests validate whichever host they arrive on
+	doc.Servers = openapi3.Servers{{URL: basePath}}
+	for _, item := range doc.Paths.Map() {
+		item.Servers = nil
+	}
+	return gorillamux.NewRouter(doc)
+}
+
+// OpenAPIValidationMiddleware rejects requests whose parameters or body do not
+// match the operation documented in the spec. Routes the spec does not
+// describe pass through unchecked.
+func OpenAPIValidationMiddleware(router routers.Router) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route, pathParams, err := router.FindRoute(c.Request)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		skipBody, _ := route.Operation.Extensions[skipBodyValidation].(bool)
+		input := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				ExcludeRequestBody: skipBody,
+				// Credentials are checked by AuthMiddleware
+				AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+			},
+		}
+		if err := openapi3filter.ValidateRequest(c.Request.Context(), input); err != nil {
+			var requestErr *openapi3filter.RequestError
+			if errors.As(err, &requestErr) && requestErr.Parameter != nil {
+				utils.BadRequest(c, parameterErrorMessage(requestErr))
+			} else if fields := bodyFieldErrors(err); fields != nil {
+				utils.ValidationErrors(c, bodyErrorMessage(err), fields)
+			} else {
+				utils.ValidationError(c, bodyErrorMessage(err))
+			}
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// parameterErrorMessage describes an invalid parameter without the schema dump
+// kin-openapi appends to its errors
+func parameterErrorMessage(err *openapi3filter.RequestError) string {
+	message := fmt.Sprintf("Invalid %s parameter %q", err.Parameter.In, err.Parameter.Name)
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		return message + ": " + schemaErr.Reason
+	}
+	if err.Err != nil {
+		return message + ": " + err.Err.Error()
+	}
+	return message
+}
+
+// bodyErrorMessage names the offending field of an invalid request body
+func bodyErrorMessage(err error) string {
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		// Missing properties already name themselves in the reason
+		if pointer := schemaErr.JSONPointer(); len(pointer) > 0 && schemaErr.SchemaField != "required" {
+			return fmt.Sprintf("Invalid request body: %s: %s", strings.Join(pointer, "."), schemaErr.Reason)
+		}
+		return "Invalid request body: " + schemaErr.Reason
+	}
+	var requestErr *openapi3filter.RequestError
+	if errors.As(err, &requestErr) && requestErr.Err != nil {
+		return "Invalid request body: " + requestErr.Err.Error()
+	}
+	return err.Error()
+}
+
+// bodyFieldErrors describes the offending field of an invalid request body in
+// the shape of the handlers' validation errors, or returns nil when the error
+// does not concern a field
+func bodyFieldErrors(err error) []validation.FieldError {
+	var schemaErr *openapi3.SchemaError
+	if !errors.As(err, &schemaErr) {
+		return nil
+	}
+	field := strings.Join(schemaErr.JSONPointer(), ".")
+	if field == "" {
+		return nil
+	}
+	return []validation.FieldError{{
+		Field:   field,
+		Rule:    schemaErr.SchemaField,
+		Message: schemaErr.Reason,
+	}}
+}
+
+// SwaggerHandler serves the OpenAPI spec at doc.yaml and Swagger UI for it
+// under a /*any route
+func SwaggerHandler(spec []byte) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch name := c.Param("any"); name {
+		case "", "/", "/index.html":
+			c.Data(http.StatusOK, "text/html; charset=utf-8", swaggerIndex)
+		case "/doc.yaml":
+			c.Data(http.StatusOK, "application/yaml", spec)
+		default:
+			c.FileFromFS(name, swaggerFiles.HTTP)
+		}
+	}
+}
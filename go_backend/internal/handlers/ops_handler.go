@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// liveAlertWindow is how far back the operations screen shows unacknowledged
+// bin alerts
+const liveAlertWindow = 24 * time.Hour
+
+// OpsHandler handles the real-time operations screen of dispatchers
+type OpsHandler struct {
+	opsRepo *repository.OpsRepository
+}
+
+// NewOpsHandler creates a new OpsHandler
+func NewOpsHandler(opsRepo *repository.OpsRepository) *OpsHandler {
+	return &OpsHandler{opsRepo: opsRepo}
+}
+
+// GetLive retrieves the live state of operations
+// @Summary Get live operations
+// @Description Drivers available on shift or on a route with their positions, the routes in progress, the bins at or above their collection threshold and the bin alerts of the last 24 hours nobody acknowledged, in one payload
+// @Tags Operations
+// @Produce json
+// @Success 200 {object} models.OpsLive
+// @Router /api/v1/ops/live [get]
+func (h *OpsHandler) GetLive(c *gin.Context) {
+	live, err := h.opsRepo.Live(c.Request.Context(), time.Now().Add(-liveAlertWindow))
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve live operations")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, live)
+}
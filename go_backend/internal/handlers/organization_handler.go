@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/pkg/security"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// OrganizationHandler handles the tenants of the deployment
+type OrganizationHandler struct {
+	repo     *repository.OrganizationRepository
+	userRepo *repository.UserRepository
+	hasher   security.PasswordHasher
+}
+
+// NewOrganizationHandler creates a new OrganizationHandler
+func NewOrganizationHandler(repo *repository.OrganizationRepository, userRepo *repository.UserRepository, hasher security.PasswordHasher) *OrganizationHandler {
+	return &OrganizationHandler{repo: repo, userRepo: userRepo, hasher: hasher}
+}
+
+// GetCurrentOrganization retrieves the organization of the principal
+// @Summary Get the organization of the authenticated principal
+// @Tags Organizations
+// @Produce json
+// @Success 200 {object} models.Organization
+// @Failure 401 {object} utils.APIError
+// @Router /api/v1/organizations/current [get]
+func (h *OrganizationHandler) GetCurrentOrganization(c *gin.Context) {
+	claims, ok := currentClaims(c)
+	if !ok {
+		utils.Unauthorized(c, "Authentication required")
+		return
+	}
+
+	organization, err := h.repo.GetByID(c.Request.Context(), claims.OrganizationID)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve organization")
+		return
+	}
+	if organization == nil {
+		utils.NotFound(c, "Organization not found")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, organization)
+}
+
+// ListOrganizations retrieves every organization of the deployment
+// @Summary List organizations
+// @Tags Organizations
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Param cursor query string false "Continue after the next_cursor of a previous page"
+// @Success 200 {array} models.Organization
+// @Failure 400 {object} utils.APIError
+// @Router /api/v1/admin/organizations [get]
+func (h *OrganizationHandler) ListOrganizations(c *gin.Context) {
+	pagination, ok := parsePage(c, 20)
+	if !ok {
+		return
+	}
+
+	organizations, result, err := h.repo.List(c.Request.Context(), pagination.page)
+	if err != nil {
+		listError(c, err, "Failed to retrieve organizations")
+		return
+	}
+	if organizations == nil {
+		organizations = []models.Organization{}
+	}
+
+	utils.SuccessResponseWithPagination(c, organizations, pagination.meta(result))
+}
+
+// CreateOrganization creates an organization together with its first admin
+// @Summary Create organization
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Param organization body models.CreateOrganizationRequest true "Organization and admin data"
+// @Success 201 {object} models.CreateOrganizationResponse
+// @Failure 400 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/admin/organizations [post]
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	var req models.CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	existing, err := h.userRepo.GetByEmail(c.Request.Context(), req.AdminEmail)
+	if err != nil {
+		abortWithError(c, err, "Failed to check existing user")
+		return
+	}
+	if existing != nil {
+		utils.Conflict(c, "Email already registered")
+		return
+	}
+
+	passwordHash, err := h.hasher.HashPassword(req.AdminPassword)
+	if err != nil {
+		abortWithError(c, err, "Failed to secure password")
+		return
+	}
+
+	organization := &models.Organization{
+		Name: strings.TrimSpace(req.Name),
+		Slug: req.Slug,
+	}
+	admin := &models.User{
+		Email:        req.AdminEmail,
+		PasswordHash: passwordHash,
+		FullName:     req.AdminFullName,
+		Role:         models.RoleAdmin,
+	}
+	if err := h.repo.CreateWithAdmin(c.Request.Context(), organization, admin); err != nil {
+		if repository.IsUniqueViolation(err) {
+			utils.Conflict(c, "Organization slug or admin email already taken")
+			return
+		}
+		abortWithError(c, err, "Failed to create organization")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, models.CreateOrganizationResponse{
+		Organization: *organization,
+		Admin:        admin.ToResponse(),
+	})
+}
+
+// UpdateOrganization renames, deactivates or reactivates an organization
+// @Summary Update organization
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param organization body models.UpdateOrganizationRequest true "Organization data"
+// @Success 200 {object} models.Organization
+// @Failure 400 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/admin/organizations/{id} [put]
+func (h *OrganizationHandler) UpdateOrganization(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid organization ID format")
+		return
+	}
+
+	var req models.UpdateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	organization, err := h.repo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve organization")
+		return
+	}
+	if organization == nil {
+		utils.NotFound(c, "Organization not found")
+		return
+	}
+
+	if req.Name != nil {
+		organization.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.IsActive != nil {
+		// Its admins manage the other organizations
+		if !*req.IsActive && organization.ID == models.DefaultOrganizationID {
+			utils.BadRequest(c, "The default organization cannot be deactivated")
+			return
+		}
+		organization.IsActive = *req.IsActive
+	}
+
+	if err := h.repo.Update(c.Request.Context(), organization); err != nil {
+		abortWithError(c, err, "Failed to update organization")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, organization)
+}
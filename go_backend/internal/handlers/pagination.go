@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// maxPerPage caps the page size a client can request
+const maxPerPage = 100
+
+// pageQuery holds the pagination parameters of a list request
+type pageQuery struct {
+	number int
+	page   repository.Page
+}
+
+// parsePage reads page, per_page and cursor from the query string. A cursor
+// takes precedence over page. It writes a 400 response and returns false when
+// the cursor is malformed.
+func parsePage(c *gin.Context, defaultPerPage int) (pageQuery, bool) {
+	perPage := getQueryInt(c, "per_page", defaultPerPage)
+	if perPage < 1 {
+		perPage = defaultPerPage
+	} else if perPage > maxPerPage {
+		perPage = maxPerPage
+	}
+
+	if value := c.Query("cursor"); value != "" {
+		cursor, err := repository.DecodeCursor(value)
+		if err != nil {
+			utils.BadRequest(c, "Invalid cursor")
+			return pageQuery{}, false
+		}
+		return pageQuery{page: repository.Page{Limit: perPage, After: cursor}}, true
+	}
+
+	number := getQueryInt(c, "page", 1)
+	if number < 1 {
+		number = 1
+	}
+	return pageQuery{
+		number: number,
+		page:   repository.Page{Limit: perPage, Offset: (number - 1) * perPage},
+	}, true
+}
+
+// meta builds the response pagination metadata from a list result
+func (p pageQuery) meta(result repository.PageResult) *utils.Pagination {
+	pagination := utils.NewPagination(p.number, p.page.Limit, result.Total)
+	pagination.NextCursor = result.NextCursor
+	return pagination
+}
+
+// listError responds to a failed list query, reporting cursors that do not
+// belong to the endpoint as bad requests
+func listError(c *gin.Context, err error, message string) {
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		utils.BadRequest(c, "Invalid cursor")
+		return
+	}
+	abortWithError(c, err, message)
+}
@@ -0,0 +1,324 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/payments"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/internal/services"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// maxWebhookBodyBytes limits the payload of a payment provider webhook
+const maxWebhookBodyBytes = 1 << 20
+
+// PaymentHandler handles company charges, driver and user payouts, the
+// payment accounts they use and the webhooks of the payment provider
+type PaymentHandler struct {
+	paymentSvc  *services.PaymentService
+	paymentRepo *repository.PaymentRepository
+}
+
+// NewPaymentHandler creates a new PaymentHandler
+func NewPaymentHandler(paymentSvc *services.PaymentService, paymentRepo *repository.PaymentRepository) *PaymentHandler {
+	return &PaymentHandler{paymentSvc: paymentSvc, paymentRepo: paymentRepo}
+}
+
+// SetCompanyPaymentAccount sets the customer and payment method a company is
+// charged with
+// @Summary Set company payment account
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param account body models.SetPaymentAccountRequest true "Provider customer and saved payment method"
+// @Success 200 {object} models.PaymentAccount
+// @Failure 400 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/companies/{id}/payment-account [put]
+func (h *PaymentHandler) SetCompanyPaymentAccount(c *gin.Context) {
+	h.setAccount(c, models.PaymentOwnerCompany, "Invalid company ID format")
+}
+
+// SetDriverPaymentAccount sets the connected account a driver is paid out to
+// @Summary Set driver payment account
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Driver ID"
+// @Param account body models.SetPaymentAccountRequest true "Provider connected account"
+// @Success 200 {object} models.PaymentAccount
+// @Failure 400 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/drivers/{id}/payment-account [put]
+func (h *PaymentHandler) SetDriverPaymentAccount(c *gin.Context) {
+	h.setAccount(c, models.PaymentOwnerDriver, "Invalid driver ID format")
+}
+
+// SetUserPaymentAccount sets the connected account a user is paid out to
+// @Summary Set user payment account
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param account body models.SetPaymentAccountRequest true "Provider connected account"
+// @Success 200 {object} models.PaymentAccount
+// @Failure 400 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/users/{id}/payment-account [put]
+func (h *PaymentHandler) SetUserPaymentAccount(c *gin.Context) {
+	h.setAccount(c, models.PaymentOwnerUser, "Invalid user ID format")
+}
+
+// CreateCharge charges a company for collection services
+// @Summary Charge a company
+// @Description Charges the saved payment method of the company. A charge the provider settles later stays processing until its webhook arrives.
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Param charge body models.CreateChargeRequest true "Company, amount and description"
+// @Success 201 {object} models.Payment
+// @Failure 400 {object} utils.APIError
+// @Failure 402 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Failure 502 {object} utils.APIError
+// @Router /api/v1/payments/charges [post]
+func (h *PaymentHandler) CreateCharge(c *gin.Context) {
+	var req models.CreateChargeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	claims, ok := currentClaims(c)
+	if !ok {
+		utils.Unauthorized(c, "Authentication required")
+		return
+	}
+
+	payment, err := h.paymentSvc.Charge(c.Request.Context(), &req, claims.SubjectID)
+	if err != nil {
+		h.writePaymentError(c, err, "Failed to charge company")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, payment)
+}
+
+// CreatePayout pays out a driver or a user
+// @Summary Pay out a driver or user
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Param payout body models.CreatePayoutRequest true "Driver or user, amount and description"
+// @Success 201 {object} models.Payment
+// @Failure 400 {object} utils.APIError
+// @Failure 402 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Failure 502 {object} utils.APIError
+// @Router /api/v1/payments/payouts [post]
+func (h *PaymentHandler) CreatePayout(c *gin.Context) {
+	var req models.CreatePayoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	claims, ok := currentClaims(c)
+	if !ok {
+		utils.Unauthorized(c, "Authentication required")
+		return
+	}
+
+	payment, err := h.paymentSvc.Payout(c.Request.Context(), &req, claims.SubjectID)
+	if err != nil {
+		h.writePaymentError(c, err, "Failed to pay out")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, payment)
+}
+
+// ListPayments retrieves the charges and payouts
+// @Summary List payments
+// @Tags Payments
+// @Produce json
+// @Param kind query string false "Filter by kind (charge, payout)"
+// @Param status query string false "Filter by status (pending, processing, succeeded, failed)"
+// @Param company_id query string false "Filter by company"
+// @Param driver_id query string false "Filter by driver"
+// @Param user_id query string false "Filter by user"
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Param cursor query string false "Continue after the next_cursor of a previous page"
+// @Success 200 {array} models.Payment
+// @Failure 400 {object} utils.APIError
+// @Router /api/v1/payments [get]
+func (h *PaymentHandler) ListPayments(c *gin.Context) {
+	var filter models.PaymentFilter
+	if value := c.Query("kind"); value != "" {
+		kind := models.PaymentKind(value)
+		if kind != models.PaymentCharge && kind != models.PaymentPayout {
+			utils.BadRequest(c, "Invalid payment kind")
+			return
+		}
+		filter.Kind = &kind
+	}
+	if value := c.Query("status"); value != "" {
+		status := models.PaymentStatus(value)
+		if !status.IsValid() {
+			utils.BadRequest(c, "Invalid payment status")
+			return
+		}
+		filter.Status = &status
+	}
+	if value := c.Query("company_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(c, "Invalid company ID format")
+			return
+		}
+		filter.CompanyID = &id
+	}
+	if value := c.Query("driver_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(c, "Invalid driver ID format")
+			return
+		}
+		filter.DriverID = &id
+	}
+	if value := c.Query("user_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(c, "Invalid user ID format")
+			return
+		}
+		filter.UserID = &id
+	}
+
+	pagination, ok := parsePage(c, 20)
+	if !ok {
+		return
+	}
+
+	list, result, err := h.paymentRepo.ListFiltered(c.Request.Context(), filter, pagination.page)
+	if err != nil {
+		listError(c, err, "Failed to retrieve payments")
+		return
+	}
+	if list == nil {
+		list = []models.Payment{}
+	}
+
+	utils.SuccessResponseWithPagination(c, list, pagination.meta(result))
+}
+
+// GetPayment retrieves a payment
+// @Summary Get payment by ID
+// @Tags Payments
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Success 200 {object} models.Payment
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/payments/{id} [get]
+func (h *PaymentHandler) GetPayment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid payment ID format")
+		return
+	}
+
+	payment, err := h.paymentRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve payment")
+		return
+	}
+	if payment == nil {
+		utils.NotFound(c, "Payment not found")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, payment)
+}
+
+// HandleWebhook records the payment status updates the provider posts
+// @Summary Receive payment provider webhook
+// @Description Verifies the signature of the webhook with the provider's webhook secret and settles the payment it reports on
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Param provider path string true "Payment provider (stripe)"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/payments/webhooks/{provider} [post]
+func (h *PaymentHandler) HandleWebhook(c *gin.Context) {
+	payload, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookBodyBytes))
+	if err != nil {
+		utils.BadRequest(c, "Failed to read request body")
+		return
+	}
+
+	err = h.paymentSvc.HandleWebhook(c.Request.Context(), c.Param("provider"), payload, c.Request.Header)
+	switch {
+	case errors.Is(err, payments.ErrWebhooksUnsupported):
+		utils.NotFound(c, "Payment provider not configured")
+	case errors.Is(err, payments.ErrInvalidSignature):
+		utils.BadRequest(c, "Invalid webhook signature")
+	case err != nil:
+		abortWithError(c, err, "Failed to process webhook")
+	default:
+		utils.SuccessResponse(c, http.StatusOK, gin.H{"status": "received"})
+	}
+}
+
+// setAccount sets the payment account of the :id owner
+func (h *PaymentHandler) setAccount(c *gin.Context, ownerType models.PaymentOwnerType, invalidID string) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, invalidID)
+		return
+	}
+
+	var req models.SetPaymentAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	account, err := h.paymentSvc.SetAccount(c.Request.Context(), ownerType, id, &req)
+	if err != nil {
+		h.writePaymentError(c, err, "Failed to set payment account")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, account)
+}
+
+// writePaymentError writes the response for an error with a payment
+func (h *PaymentHandler) writePaymentError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrCompanyNotFound):
+		utils.NotFound(c, "Company not found")
+	case errors.Is(err, services.ErrDriverNotFound):
+		utils.NotFound(c, "Driver not found")
+	case errors.Is(err, services.ErrUserNotFound):
+		utils.NotFound(c, "User not found")
+	case errors.Is(err, services.ErrPaymentAccountMissing):
+		utils.Conflict(c, "No payment account is set up with the payment provider")
+	case errors.Is(err, services.ErrPaymentDeclined):
+		utils.ErrorResponse(c, http.StatusPaymentRequired, "PAYMENT_DECLINED", err.Error())
+	case errors.Is(err, services.ErrPaymentProvider):
+		utils.ErrorResponse(c, http.StatusBadGateway, "PAYMENT_PROVIDER_ERROR", "Payment provider unavailable, the payment is pending")
+	default:
+		abortWithError(c, err, message)
+	}
+}
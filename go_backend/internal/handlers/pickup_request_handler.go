@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/internal/services"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// PickupRequestHandler handles the on-demand pickups citizens request
+type PickupRequestHandler struct {
+	pickupSvc   *services.PickupRequestService
+	dispatchSvc *services.DispatchService
+	pickupRepo  *repository.PickupRequestRepository
+}
+
+// NewPickupRequestHandler creates a new PickupRequestHandler
+func NewPickupRequestHandler(pickupSvc *services.PickupRequestService, dispatchSvc *services.DispatchService, pickupRepo *repository.PickupRequestRepository) *PickupRequestHandler {
+	return &PickupRequestHandler{pickupSvc: pickupSvc, dispatchSvc: dispatchSvc, pickupRepo: pickupRepo}
+}
+
+// CreatePickupRequest requests an on-demand pickup of bulky or recyclable waste
+// @Summary Request a pickup
+// @Description The request is valued at its estimated weight and dispatched by the next auto-dispatch run
+// @Tags Pickup Requests
+// @Accept json
+// @Produce json
+// @Param pickup body models.CreatePickupRequestRequest true "Pickup request data"
+// @Success 201 {object} models.PickupRequest
+// @Failure 400 {object} utils.APIError
+// @Router /api/v1/pickup-requests [post]
+func (h *PickupRequestHandler) CreatePickupRequest(c *gin.Context) {
+	var req models.CreatePickupRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	claims, ok := currentClaims(c)
+	if !ok {
+		utils.Unauthorized(c, "Authentication required")
+		return
+	}
+
+	pickup, err := h.pickupSvc.Create(c.Request.Context(), claims.SubjectID, &req)
+	if err != nil {
+		if writeUploadError(c, err, "photo_urls") {
+			return
+		}
+		abortWithError(c, err, "Failed to create pickup request")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, pickup)
+}
+
+// ListPickupRequests retrieves pickup requests; citizens see their own and
+// drivers those assigned to them
+// @Summary List pickup requests
+// @Tags Pickup Requests
+// @Produce json
+// @Param status query string false "Filter by status"
+// @Param user_id query string false "Filter by requesting user ID"
+// @Param driver_id query string false "Filter by assigned driver ID"
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Param cursor query string false "Continue after the next_cursor of a previous page"
+// @Success 200 {array} models.PickupRequest
+// @Failure 400 {object} utils.APIError
+// @Router /api/v1/pickup-requests [get]
+func (h *PickupRequestHandler) ListPickupRequests(c *gin.Context) {
+	var filter models.PickupRequestFilter
+	if value := c.Query("status"); value != "" {
+		status := models.PickupStatus(value)
+		if !status.IsValid() {
+			utils.BadRequest(c, "Invalid pickup status")
+			return
+		}
+		filter.Status = &status
+	}
+	if value := c.Query("user_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(c, "Invalid user ID format")
+			return
+		}
+		filter.UserID = &id
+	}
+	if value := c.Query("driver_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(c, "Invalid driver ID format")
+			return
+		}
+		filter.DriverID = &id
+	}
+
+	if claims, ok := currentClaims(c); ok {
+		switch claims.Role {
+		case models.RoleCitizen:
+			filter.UserID = &claims.SubjectID
+		case models.RoleDriver:
+			filter.DriverID = &claims.SubjectID
+		}
+	}
+
+	pagination, ok := parsePage(c, 20)
+	if !ok {
+		return
+	}
+
+	pickups, result, err := h.pickupRepo.ListFiltered(c.Request.Context(), filter, pagination.page)
+	if err != nil {
+		listError(c, err, "Failed to retrieve pickup requests")
+		return
+	}
+	if pickups == nil {
+		pickups = []models.PickupRequest{}
+	}
+
+	utils.SuccessResponseWithPagination(c, pickups, pagination.meta(result))
+}
+
+// GetPickupRequest retrieves a pickup request by ID
+// @Summary Get pickup request by ID
+// @Tags Pickup Requests
+// @Produce json
+// @Param id path string true "Pickup Request ID"
+// @Success 200 {object} models.PickupRequest
+// @Failure 403 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/pickup-requests/{id} [get]
+func (h *PickupRequestHandler) GetPickupRequest(c *gin.Context) {
+	pickup, ok := h.loadPickup(c)
+	if !ok {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, pickup)
+}
+
+// AssignPickupRequest dispatches a pickup request to a driver
+// @Summary Assign a pickup request
+// @Description Assigns the given driver, or the nearest available driver covering the pickup location
+// @Tags Pickup Requests
+// @Accept json
+// @Produce json
+// @Param id path string true "Pickup Request ID"
+// @Param assignment body models.AssignPickupRequest false "Driver to assign"
+// @Success 200 {object} models.PickupRequest
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/pickup-requests/{id}/assign [post]
+func (h *PickupRequestHandler) AssignPickupRequest(c *gin.Context) {
+	var req models.AssignPickupRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			validationError(c, err)
+			return
+		}
+	}
+
+	pickup, ok := h.loadPickup(c)
+	if !ok {
+		return
+	}
+
+	if err := h.dispatchSvc.DispatchPickup(c.Request.Context(), pickup, req.DriverID); err != nil {
+		h.writePickupError(c, err, "Failed to assign pickup request")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, pickup)
+}
+
+// CompletePickupRequest records a collected pickup request
+// @Summary Complete a pickup request
+// @Description Prices the pickup at the collected weight and ships its waste to the shipment tracker
+// @Tags Pickup Requests
+// @Accept json
+// @Produce json
+// @Param id path string true "Pickup Request ID"
+// @Param completion body models.CompletePickupRequest true "Collected weight"
+// @Success 200 {object} models.PickupRequest
+// @Failure 403 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/pickup-requests/{id}/complete [post]
+func (h *PickupRequestHandler) CompletePickupRequest(c *gin.Context) {
+	var req models.CompletePickupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	pickup, ok := h.loadPickup(c)
+	if !ok {
+		return
+	}
+
+	if err := h.pickupSvc.Complete(c.Request.Context(), pickup, &req); err != nil {
+		h.writePickupError(c, err, "Failed to complete pickup request")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, pickup)
+}
+
+// CancelPickupRequest cancels a pickup request not collected yet
+// @Summary Cancel a pickup request
+// @Tags Pickup Requests
+// @Produce json
+// @Param id path string true "Pickup Request ID"
+// @Success 200 {object} models.PickupRequest
+// @Failure 403 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/pickup-requests/{id}/cancel [post]
+func (h *PickupRequestHandler) CancelPickupRequest(c *gin.Context) {
+	pickup, ok := h.loadPickup(c)
+	if !ok {
+		return
+	}
+
+	if err := h.pickupSvc.Cancel(c.Request.Context(), pickup); err != nil {
+		h.writePickupError(c, err, "Failed to cancel pickup request")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, pickup)
+}
+
+// loadPickup resolves the :id pickup request and checks that citizens only
+// access their own requests and drivers those assigned to them. It writes the
+// error response itself.
+func (h *PickupRequestHandler) loadPickup(c *gin.Context) (*models.PickupRequest, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid pickup request ID format")
+		return nil, false
+	}
+
+	pickup, err := h.pickupRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve pickup request")
+		return nil, false
+	}
+	if pickup == nil {
+		utils.NotFound(c, "Pickup request not found")
+		return nil, false
+	}
+
+	if claims, ok := currentClaims(c); ok {
+		switch {
+		case claims.Role == models.RoleCitizen && claims.SubjectID != pickup.UserID:
+			utils.Forbidden(c, "You can only access your own pickup requests")
+			return nil, false
+		case claims.Role == models.RoleDriver && (pickup.DriverID == nil || claims.SubjectID != *pickup.DriverID):
+			utils.Forbidden(c, "You can only access the pickups assigned to you")
+			return nil, false
+		}
+	}
+
+	return pickup, true
+}
+
+// writePickupError writes the response for an error changing a pickup request
+func (h *PickupRequestHandler) writePickupError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrPickupClosed):
+		utils.Conflict(c, "Pickup request was already collected or cancelled")
+	case errors.Is(err, services.ErrPickupNotAssigned):
+		utils.Conflict(c, "Pickup request is not assigned to a driver yet")
+	case errors.Is(err, services.ErrNoDriverAvailable):
+		utils.Conflict(c, "No available driver covers this pickup")
+	case errors.Is(err, services.ErrDriverNotFound):
+		utils.NotFound(c, "Driver not found")
+	default:
+		abortWithError(c, err, message)
+	}
+}
@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/internal/services"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// PricingHandler handles pricing promotions, market prices and exchange rates
+type PricingHandler struct {
+	pricingRepo   *repository.PricingRepository
+	companyRepo   *repository.CompanyRepository
+	exchangeRates *services.ExchangeRateService
+}
+
+// NewPricingHandler creates a new PricingHandler
+func NewPricingHandler(pricingRepo *repository.PricingRepository, companyRepo *repository.CompanyRepository, exchangeRates *services.ExchangeRateService) *PricingHandler {
+	return &PricingHandler{pricingRepo: pricingRepo, companyRepo: companyRepo, exchangeRates: exchangeRates}
+}
+
+// ListPromotions retrieves the running and upcoming pricing promotions
+// @Summary List pricing promotions
+// @Tags Pricing Rules
+// @Produce json
+// @Param company_id query string false "Only promotions of this company"
+// @Success 200 {array} models.PricingPromotion
+// @Failure 400 {object} utils.APIError
+// @Router /api/v1/pricing-promotions [get]
+func (h *PricingHandler) ListPromotions(c *gin.Context) {
+	var companyID *uuid.UUID
+	if value := c.Query("company_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(c, "Invalid company ID format")
+			return
+		}
+		companyID = &id
+	}
+
+	promotions, err := h.pricingRepo.ListPromotions(c.Request.Context(), time.Now(), companyID)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve promotions")
+		return
+	}
+	if promotions == nil {
+		promotions = []models.PricingPromotion{}
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, promotions)
+}
+
+// CreatePromotion creates a time-limited promotional rate
+// @Summary Create pricing promotion
+// @Tags Pricing Rules
+// @Accept json
+// @Produce json
+// @Param promotion body models.CreatePromotionRequest true "Promotion data"
+// @Success 201 {object} models.PricingPromotion
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/pricing-promotions [post]
+func (h *PricingHandler) CreatePromotion(c *gin.Context) {
+	var req models.CreatePromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	if req.CompanyID == nil {
+		req.CompanyID = principalCompany(c)
+	}
+	if !requireOwnCompany(c, req.CompanyID) {
+		return
+	}
+
+	promotion := &models.PricingPromotion{
+		Name:       strings.TrimSpace(req.Name),
+		CompanyID:  req.CompanyID,
+		Multiplier: req.Multiplier,
+		StartsAt:   req.StartsAt,
+		EndsAt:     req.EndsAt,
+	}
+	if req.WasteType != nil {
+		if wasteType := strings.TrimSpace(*req.WasteType); wasteType != "" {
+			promotion.WasteType = &wasteType
+		}
+	}
+
+	if promotion.CompanyID != nil {
+		company, err := h.companyRepo.GetByID(c.Request.Context(), *promotion.CompanyID)
+		if err != nil {
+			abortWithError(c, err, "Failed to retrieve company")
+			return
+		}
+		if company == nil {
+			utils.NotFound(c, "Company not found")
+			return
+		}
+	}
+
+	if err := h.pricingRepo.CreatePromotion(c.Request.Context(), promotion); err != nil {
+		abortWithError(c, err, "Failed to create promotion")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, promotion)
+}
+
+// UpdatePromotion updates a pricing promotion
+// @Summary Update pricing promotion
+// @Tags Pricing Rules
+// @Accept json
+// @Produce json
+// @Param id path string true "Promotion ID"
+// @Param promotion body models.UpdatePromotionRequest true "Promotion data"
+// @Success 200 {object} models.PricingPromotion
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/pricing-promotions/{id} [put]
+func (h *PricingHandler) UpdatePromotion(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid promotion ID format")
+		return
+	}
+
+	var req models.UpdatePromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	promotion, err := h.pricingRepo.GetPromotion(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve promotion")
+		return
+	}
+	if promotion == nil {
+		utils.NotFound(c, "Promotion not found")
+		return
+	}
+	if !requireOwnCompany(c, promotion.CompanyID) {
+		return
+	}
+
+	if req.Name != nil {
+		promotion.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Multiplier != nil {
+		promotion.Multiplier = *req.Multiplier
+	}
+	if req.StartsAt != nil {
+		promotion.StartsAt = *req.StartsAt
+	}
+	if req.EndsAt != nil {
+		promotion.EndsAt = *req.EndsAt
+	}
+	if req.IsActive != nil {
+		promotion.IsActive = *req.IsActive
+	}
+	if !promotion.EndsAt.After(promotion.StartsAt) {
+		utils.ValidationError(c, "ends_at must be after starts_at")
+		return
+	}
+
+	if err := h.pricingRepo.UpdatePromotion(c.Request.Context(), promotion); err != nil {
+		abortWithError(c, err, "Failed to update promotion")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, promotion)
+}
+
+// DeletePromotion ends a pricing promotion
+// @Summary Delete pricing promotion
+// @Tags Pricing Rules
+// @Param id path string true "Promotion ID"
+// @Success 204 "No Content"
+// @Router /api/v1/pricing-promotions/{id} [delete]
+func (h *PricingHandler) DeletePromotion(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid promotion ID format")
+		return
+	}
+
+	if err := h.pricingRepo.DeletePromotion(c.Request.Context(), id); err != nil {
+		abortWithError(c, err, "Failed to delete promotion")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListMarketPrices retrieves the market price in effect of every waste type
+// @Summary List market prices
+// @Tags Pricing Rules
+// @Produce json
+// @Success 200 {array} models.MarketPrice
+// @Router /api/v1/market-prices [get]
+func (h *PricingHandler) ListMarketPrices(c *gin.Context) {
+	prices, err := h.pricingRepo.ListMarketPrices(c.Request.Context(), time.Now())
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve market prices")
+		return
+	}
+	if prices == nil {
+		prices = []models.MarketPrice{}
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, prices)
+}
+
+// CreateMarketPrice records the market price of a material
+// @Summary Record market price
+// @Description Market rules value waste at a share of the latest price in effect
+// @Tags Pricing Rules
+// @Accept json
+// @Produce json
+// @Param price body models.CreateMarketPriceRequest true "Market price"
+// @Success 201 {object} models.MarketPrice
+// @Router /api/v1/market-prices [post]
+func (h *PricingHandler) CreateMarketPrice(c *gin.Context) {
+	var req models.CreateMarketPriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	price := &models.MarketPrice{
+		WasteType:   strings.TrimSpace(req.WasteType),
+		Currency:    strings.ToUpper(req.Currency),
+		PricePerKg:  req.PricePerKg,
+		Source:      req.Source,
+		EffectiveAt: time.Now(),
+	}
+	if req.EffectiveAt != nil {
+		price.EffectiveAt = *req.EffectiveAt
+	}
+
+	if err := h.pricingRepo.CreateMarketPrice(c.Request.Context(), price); err != nil {
+		abortWithError(c, err, "Failed to record market price")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, price)
+}
+
+// GetExchangeRates retrieves the cached exchange rates valuations are converted with
+// @Summary Get exchange rates
+// @Tags Pricing Rules
+// @Produce json
+// @Success 200 {object} models.ExchangeRates
+// @Failure 503 {object} utils.APIError
+// @Router /api/v1/exchange-rates [get]
+func (h *PricingHandler) GetExchangeRates(c *gin.Context) {
+	rates, err := h.exchangeRates.Rates(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, services.ErrRatesUnavailable) {
+			utils.ErrorResponse(c, http.StatusServiceUnavailable, "EXCHANGE_RATES_UNAVAILABLE", "Exchange rates are unavailable")
+			return
+		}
+		abortWithError(c, err, "Failed to retrieve exchange rates")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, rates)
+}
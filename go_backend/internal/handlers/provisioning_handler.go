@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/internal/services"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// ProvisioningHandler issues broker credentials to bin sensors at their first boot
+type ProvisioningHandler struct {
+	svc     *services.ProvisioningService
+	binRepo *repository.BinRepository
+}
+
+// NewProvisioningHandler creates a new ProvisioningHandler
+func NewProvisioningHandler(svc *services.ProvisioningService, binRepo *repository.BinRepository) *ProvisioningHandler {
+	return &ProvisioningHandler{
+		svc:     svc,
+		binRepo: binRepo,
+	}
+}
+
+// Provision signs the certificate signing request of a sensor registered on a bin
+// @Summary Provision a bin sensor
+// @Tags Provisioning
+// @Accept json
+// @Produce json
+// @Param request body models.ProvisionDeviceRequest true "Hardware serial and CSR"
+// @Success 201 {object} models.ProvisionDeviceResponse
+// @Failure 400 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Failure 503 {object} utils.APIError
+// @Router /api/v1/provisioning/register [post]
+func (h *ProvisioningHandler) Provision(c *gin.Context) {
+	var req models.ProvisionDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	resp, err := h.svc.Provision(c.Request.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrProvisioningDisabled):
+			utils.ErrorResponse(c, http.StatusServiceUnavailable, "PROVISIONING_UNAVAILABLE", "Device provisioning is not configured")
+		case errors.Is(err, services.ErrDeviceNotRegistered):
+			utils.NotFound(c, "No bin is registered with this hardware serial")
+		case errors.Is(err, services.ErrAlreadyProvisioned):
+			utils.Conflict(c, "Bin is already provisioned")
+		case errors.Is(err, services.ErrInvalidCSR):
+			utils.BadRequest(c, err.Error())
+		default:
+			abortWithError(c, err, "Failed to provision device")
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, resp)
+}
+
+// ResetProvisioning lets the sensor of a bin provision itself again
+// @Summary Allow a bin sensor to provision again
+// @Tags Provisioning
+// @Produce json
+// @Param id path string true "Bin ID"
+// @Success 200 {object} models.BinResponse
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/bins/{id}/provisioning/reset [post]
+func (h *ProvisioningHandler) ResetProvisioning(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid bin ID format")
+		return
+	}
+
+	bin, err := h.binRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve bin")
+		return
+	}
+	if bin == nil {
+		utils.NotFound(c, "Bin not found")
+		return
+	}
+
+	if err := h.svc.AllowReprovisioning(c.Request.Context(), bin); err != nil {
+		abortWithError(c, err, "Failed to reset provisioning")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, bin.ToResponse())
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/smartwaste/backend/internal/auth"
+	"github.com/smartwaste/backend/internal/config"
+	"github.com/smartwaste/backend/internal/realtime"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// sseHeartbeatInterval keeps idle event streams alive through proxies
+const sseHeartbeatInterval = 25 * time.Second
+
+// RealtimeHandler handles live update streams for dashboards
+type RealtimeHandler struct {
+	hub        *realtime.Hub
+	locations  *realtime.LocationBroker
+	driverRepo *repository.DriverRepository
+	upgrader   websocket.Upgrader
+}
+
+// NewRealtimeHandler creates a new RealtimeHandler. Browsers may only open
+// streams from the origins the CORS policy allows.
+func NewRealtimeHandler(hub *realtime.Hub, locations *realtime.LocationBroker, driverRepo *repository.DriverRepository, cors *config.CORSConfig) *RealtimeHandler {
+	return &RealtimeHandler{
+		hub:        hub,
+		locations:  locations,
+		driverRepo: driverRepo,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			// Clients other than browsers send no origin
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				return origin == "" || cors.AllowsOrigin(origin)
+			},
+		},
+	}
+}
+
+// StreamBins upgrades the connection to a WebSocket and pushes bin fill-level updates
+// @Summary Live bin fill-level updates (WebSocket)
+// @Tags Bins
+// @Param company_id query string false "Only receive bins of this company"
+// @Param access_token query string false "Access token for clients that cannot set headers"
+// @Success 101 "Switching Protocols"
+// @Failure 400 {object} utils.APIError
+// @Failure 403 {object} utils.APIError
+// @Router /ws/bins [get]
+func (h *RealtimeHandler) StreamBins(c *gin.Context) {
+	var companyID *uuid.UUID
+	if value := c.Query("company_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(c, "Invalid company ID format")
+			return
+		}
+		companyID = &id
+	}
+
+	// Company principals only receive their own company's bins
+	if own := principalCompany(c); own != nil {
+		if companyID != nil && *companyID != *own {
+			utils.Forbidden(c, "You can only stream your own company's bins")
+			return
+		}
+		companyID = own
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already written an HTTP error response
+		log.Printf("WebSocket upgrade failed: %v", err)
+		return
+	}
+
+	// Authentication confined the request to the principal's organization
+	organizationID, _ := auth.OrganizationFromContext(c.Request.Context())
+	h.hub.Serve(conn, organizationID, companyID)
+}
+
+// StreamDriverLocation streams a driver's location updates as Server-Sent Events
+// @Summary Stream driver location (SSE)
+// @Tags Drivers
+// @Produce text/event-stream
+// @Param id path string true "Driver ID"
+// @Param access_token query string false "Access token for clients that cannot set headers"
+// @Success 200 {object} realtime.DriverLocationEvent
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/drivers/{id}/location/stream [get]
+func (h *RealtimeHandler) StreamDriverLocation(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid driver ID format")
+		return
+	}
+
+	driver, err := h.driverRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve driver")
+		return
+	}
+	if driver == nil {
+		utils.NotFound(c, "Driver not found")
+		return
+	}
+
+	// The stream outlives the server's write timeout
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("Failed to clear write deadline for location stream: %v", err)
+	}
+
+	events, unsubscribe := h.locations.Subscribe(id)
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// Send the last known position so the map can render immediately
+	if driver.Latitude != nil && driver.Longitude != nil {
+		c.SSEvent("location", realtime.DriverLocationEvent{
+			DriverID:  driver.ID,
+			Latitude:  *driver.Latitude,
+			Longitude: *driver.Longitude,
+			Timestamp: driver.UpdatedAt,
+		})
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event := <-events:
+			c.SSEvent("location", event)
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			if _, err := c.Writer.WriteString(": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// RewardHandler handles reward earning rules and the reward ledger
+type RewardHandler struct {
+	rewardRepo *repository.RewardRepository
+}
+
+// NewRewardHandler creates a new RewardHandler
+func NewRewardHandler(rewardRepo *repository.RewardRepository) *RewardHandler {
+	return &RewardHandler{
+		rewardRepo: rewardRepo,
+	}
+}
+
+// ListRewardRules retrieves the active earning rules
+// @Summary List reward rules
+// @Tags Rewards
+// @Produce json
+// @Success 200 {array} models.RewardRule
+// @Router /api/v1/reward-rules [get]
+func (h *RewardHandler) ListRewardRules(c *gin.Context) {
+	rules, err := h.rewardRepo.ListRules(c.Request.Context())
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve reward rules")
+		return
+	}
+	if rules == nil {
+		rules = []models.RewardRule{}
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, rules)
+}
+
+// CreateRewardRule creates an earning rule for a waste type ('*' for all others)
+// @Summary Create reward rule
+// @Tags Rewards
+// @Accept json
+// @Produce json
+// @Param rule body models.CreateRewardRuleRequest true "Reward rule data"
+// @Success 201 {object} models.RewardRule
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/reward-rules [post]
+func (h *RewardHandler) CreateRewardRule(c *gin.Context) {
+	var req models.CreateRewardRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	rule := &models.RewardRule{
+		WasteType:   strings.TrimSpace(req.WasteType),
+		BasePoints:  req.BasePoints,
+		PointsPerKg: req.PointsPerKg,
+		MinWeightKg: req.MinWeightKg,
+		MaxPoints:   req.MaxPoints,
+	}
+
+	if err := h.rewardRepo.CreateRule(c.Request.Context(), rule); err != nil {
+		if repository.IsUniqueViolation(err) {
+			utils.Conflict(c, "An active reward rule already exists for this waste type")
+			return
+		}
+		abortWithError(c, err, "Failed to create reward rule")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, rule)
+}
+
+// UpdateRewardRule updates an earning rule
+// @Summary Update reward rule
+// @Tags Rewards
+// @Accept json
+// @Produce json
+// @Param id path string true "Reward Rule ID"
+// @Param rule body models.UpdateRewardRuleRequest true "Reward rule data"
+// @Success 200 {object} models.RewardRule
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/reward-rules/{id} [put]
+func (h *RewardHandler) UpdateRewardRule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid reward rule ID format")
+		return
+	}
+
+	var req models.UpdateRewardRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	rule, err := h.rewardRepo.GetRuleByID(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve reward rule")
+		return
+	}
+	if rule == nil {
+		utils.NotFound(c, "Reward rule not found")
+		return
+	}
+
+	if req.BasePoints != nil {
+		rule.BasePoints = *req.BasePoints
+	}
+	if req.PointsPerKg != nil {
+		rule.PointsPerKg = *req.PointsPerKg
+	}
+	if req.MinWeightKg != nil {
+		rule.MinWeightKg = *req.MinWeightKg
+	}
+	if req.MaxPoints != nil {
+		rule.MaxPoints = req.MaxPoints
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if err := h.rewardRepo.UpdateRule(c.Request.Context(), rule); err != nil {
+		if repository.IsUniqueViolation(err) {
+			utils.Conflict(c, "An active reward rule already exists for this waste type")
+			return
+		}
+		abortWithError(c, err, "Failed to update reward rule")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, rule)
+}
+
+// DeleteRewardRule deactivates an earning rule
+// @Summary Delete reward rule
+// @Tags Rewards
+// @Param id path string true "Reward Rule ID"
+// @Success 204 "No Content"
+// @Router /api/v1/reward-rules/{id} [delete]
+func (h *RewardHandler) DeleteRewardRule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid reward rule ID format")
+		return
+	}
+
+	if err := h.rewardRepo.DeleteRule(c.Request.Context(), id); err != nil {
+		abortWithError(c, err, "Failed to delete reward rule")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListRewardHistory retrieves the points a user earned automatically
+// @Summary List user reward history
+// @Tags Users
+// @Produce json
+// @Param id path string true "User ID"
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Param cursor query string false "Continue after the next_cursor of a previous page"
+// @Success 200 {array} models.RewardTransaction
+// @Router /api/v1/users/{id}/rewards/history [get]
+func (h *RewardHandler) ListRewardHistory(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid user ID format")
+		return
+	}
+
+	pagination, ok := parsePage(c, 20)
+	if !ok {
+		return
+	}
+
+	txns, result, err := h.rewardRepo.ListTransactions(c.Request.Context(), id, pagination.page)
+	if err != nil {
+		listError(c, err, "Failed to retrieve reward history")
+		return
+	}
+	if txns == nil {
+		txns = []models.RewardTransaction{}
+	}
+
+	utils.SuccessResponseWithPagination(c, txns, pagination.meta(result))
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/services"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// RouteHandler handles the routes planned for drivers
+type RouteHandler struct {
+	routeService *services.RouteService
+}
+
+// NewRouteHandler creates a new RouteHandler
+func NewRouteHandler(routeService *services.RouteService) *RouteHandler {
+	return &RouteHandler{routeService: routeService}
+}
+
+// GetRoute retrieves a planned route
+// @Summary Get route
+// @Tags Routes
+// @Produce json
+// @Param id path string true "Route ID"
+// @Success 200 {object} models.RouteResponse
+// @Failure 403 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/routes/{id} [get]
+func (h *RouteHandler) GetRoute(c *gin.Context) {
+	route, ok := h.loadRoute(c)
+	if !ok {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, route.ToResponse())
+}
+
+// ExportRoute exports the stops of a route for a navigation app, as a GPX file
+// or Google Maps directions links
+// @Summary Export route
+// @Tags Routes
+// @Produce application/gpx+xml
+// @Produce json
+// @Param id path string true "Route ID"
+// @Param format query string true "gpx or gmaps"
+// @Success 200 {object} models.RouteNavigation
+// @Failure 400 {object} utils.APIError
+// @Failure 403 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/routes/{id}/export [get]
+func (h *RouteHandler) ExportRoute(c *gin.Context) {
+	format := models.RouteExportFormat(c.Query("format"))
+	if !format.IsValid() {
+		utils.BadRequest(c, "format must be gpx or gmaps")
+		return
+	}
+
+	route, ok := h.loadRoute(c)
+	if !ok {
+		return
+	}
+
+	if format == models.RouteExportGoogleMaps {
+		utils.SuccessResponse(c, http.StatusOK, services.RouteGoogleMapsLinks(route))
+		return
+	}
+
+	body, err := services.RouteGPX(route)
+	if err != nil {
+		abortWithError(c, err, "Failed to export route")
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "route-"+route.ID.String()+".gpx"))
+	c.Data(http.StatusOK, "application/gpx+xml", body)
+}
+
+// ReoptimizeRoute re-sequences the stops left on an active route from where
+// the driver is, dropping the bins emptied by someone else and adding full
+// bins nearby
+// @Summary Re-optimize route
+// @Tags Routes
+// @Produce json
+// @Param id path string true "Route ID"
+// @Success 200 {object} models.RouteReoptimization
+// @Failure 403 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/routes/{id}/reoptimize [post]
+func (h *RouteHandler) ReoptimizeRoute(c *gin.Context) {
+	route, ok := h.loadRoute(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.routeService.Reoptimize(c.Request.Context(), route, true)
+	if errors.Is(err, services.ErrRouteClosed) {
+		utils.Conflict(c, "Route is no longer active")
+		return
+	}
+	if err != nil {
+		abortWithError(c, err, "Failed to re-optimize route")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// loadRoute resolves the :id route, writing the error response itself.
+// Drivers only see their own routes.
+func (h *RouteHandler) loadRoute(c *gin.Context) (*models.DriverRoute, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid route ID format")
+		return nil, false
+	}
+
+	route, err := h.routeService.Get(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve route")
+		return nil, false
+	}
+	if route == nil {
+		utils.NotFound(c, "Route not found")
+		return nil, false
+	}
+
+	if claims, ok := currentClaims(c); ok && claims.Role == models.RoleDriver && claims.SubjectID != route.DriverID {
+		utils.Forbidden(c, "This route is not yours")
+		return nil, false
+	}
+
+	return route, true
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// Bounds of the search term and result count
+const (
+	minSearchTermLength = 2
+	maxSearchTermLength = 100
+	maxSearchResults    = 50
+)
+
+// SearchHandler handles the global search across entities
+type SearchHandler struct {
+	repo *repository.SearchRepository
+}
+
+// NewSearchHandler creates a new SearchHandler
+func NewSearchHandler(repo *repository.SearchRepository) *SearchHandler {
+	return &SearchHandler{repo: repo}
+}
+
+// Search finds bins, drivers, users and companies matching a term
+// @Summary Search across entities
+// @Tags Search
+// @Produce json
+// @Param q query string true "Search term (at least 2 characters)"
+// @Param types query string false "Comma-separated entity types: bin, driver, user, company"
+// @Param limit query int false "Maximum number of results" default(20)
+// @Success 200 {array} models.SearchResult
+// @Failure 400 {object} utils.APIError
+// @Router /api/v1/search [get]
+func (h *SearchHandler) Search(c *gin.Context) {
+	term := strings.TrimSpace(c.Query("q"))
+	if n := utf8.RuneCountInString(term); n < minSearchTermLength || n > maxSearchTermLength {
+		utils.BadRequest(c, "q must be between 2 and 100 characters")
+		return
+	}
+
+	types := models.SearchResultTypes
+	if value := c.Query("types"); value != "" {
+		types = nil
+		for _, name := range strings.Split(value, ",") {
+			t := models.SearchResultType(strings.TrimSpace(name))
+			if !t.IsValid() {
+				utils.BadRequest(c, "Invalid search type: "+string(t))
+				return
+			}
+			types = append(types, t)
+		}
+	}
+
+	limit := getQueryInt(c, "limit", 20)
+	if limit < 1 {
+		limit = 20
+	} else if limit > maxSearchResults {
+		limit = maxSearchResults
+	}
+
+	results, err := h.repo.Search(c.Request.Context(), term, types, limit)
+	if err != nil {
+		abortWithError(c, err, "Failed to search")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, results)
+}
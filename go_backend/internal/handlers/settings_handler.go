@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/services"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// SettingsHandler handles the runtime settings of the deployment
+type SettingsHandler struct {
+	service *services.SettingsService
+}
+
+// NewSettingsHandler creates a new SettingsHandler
+func NewSettingsHandler(service *services.SettingsService) *SettingsHandler {
+	return &SettingsHandler{service: service}
+}
+
+// GetSettings retrieves the runtime settings in effect
+// @Summary Get runtime settings
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} models.Settings
+// @Router /api/v1/admin/settings [get]
+func (h *SettingsHandler) GetSettings(c *gin.Context) {
+	utils.SuccessResponse(c, http.StatusOK, h.service.Current())
+}
+
+// UpdateSettings changes runtime settings; every instance applies them
+// within the reload interval
+// @Summary Update runtime settings
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param settings body models.UpdateSettingsRequest true "Settings to change"
+// @Success 200 {object} models.Settings
+// @Failure 400 {object} utils.APIError
+// @Router /api/v1/admin/settings [put]
+func (h *SettingsHandler) UpdateSettings(c *gin.Context) {
+	var req models.UpdateSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	var updatedBy *uuid.UUID
+	if claims, ok := currentClaims(c); ok && claims.APIKeyID == nil {
+		updatedBy = &claims.SubjectID
+	}
+
+	settings, err := h.service.Update(c.Request.Context(), &req, updatedBy)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSettings) {
+			utils.BadRequest(c, err.Error())
+			return
+		}
+		abortWithError(c, err, "Failed to update settings")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, settings)
+}
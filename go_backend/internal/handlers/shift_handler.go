@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// ShiftHandler handles driver shift schedules
+type ShiftHandler struct {
+	shiftRepo  *repository.DriverShiftRepository
+	driverRepo *repository.DriverRepository
+}
+
+// NewShiftHandler creates a new ShiftHandler
+func NewShiftHandler(shiftRepo *repository.DriverShiftRepository, driverRepo *repository.DriverRepository) *ShiftHandler {
+	return &ShiftHandler{
+		shiftRepo:  shiftRepo,
+		driverRepo: driverRepo,
+	}
+}
+
+// CreateShift adds a recurring weekly or one-off shift to a driver's schedule
+// @Summary Create driver shift
+// @Tags Drivers
+// @Accept json
+// @Produce json
+// @Param id path string true "Driver ID"
+// @Param shift body models.CreateDriverShiftRequest true "Shift data"
+// @Success 201 {object} models.DriverShift
+// @Failure 400 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/drivers/{id}/shifts [post]
+func (h *ShiftHandler) CreateShift(c *gin.Context) {
+	driverID, ok := h.loadDriverID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateDriverShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	shift := &models.DriverShift{
+		DriverID: driverID,
+		Timezone: req.Timezone,
+	}
+	if shift.Timezone == "" {
+		shift.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(shift.Timezone); err != nil {
+		utils.ValidationError(c, "Unknown time zone: "+shift.Timezone)
+		return
+	}
+
+	recurring := req.DayOfWeek != nil || req.StartTime != nil || req.EndTime != nil
+	oneOff := req.StartsAt != nil || req.EndsAt != nil
+	switch {
+	case recurring && oneOff:
+		utils.ValidationError(c, "A shift is either recurring (day_of_week, start_time, end_time) or one-off (starts_at, ends_at)")
+		return
+	case recurring:
+		if req.DayOfWeek == nil || req.StartTime == nil || req.EndTime == nil {
+			utils.ValidationError(c, "Recurring shifts require day_of_week, start_time and end_time")
+			return
+		}
+		if !isShiftTime(*req.StartTime) || !isShiftTime(*req.EndTime) {
+			utils.ValidationError(c, "start_time and end_time must be HH:MM")
+			return
+		}
+		if *req.StartTime == *req.EndTime {
+			utils.ValidationError(c, "start_time and end_time must differ")
+			return
+		}
+		shift.DayOfWeek = req.DayOfWeek
+		shift.StartTime = req.StartTime
+		shift.EndTime = req.EndTime
+	case oneOff:
+		if req.StartsAt == nil || req.EndsAt == nil {
+			utils.ValidationError(c, "One-off shifts require starts_at and ends_at")
+			return
+		}
+		if !req.EndsAt.After(*req.StartsAt) {
+			utils.ValidationError(c, "ends_at must be after starts_at")
+			return
+		}
+		shift.StartsAt = req.StartsAt
+		shift.EndsAt = req.EndsAt
+	default:
+		utils.ValidationError(c, "Provide day_of_week, start_time and end_time, or starts_at and ends_at")
+		return
+	}
+
+	if err := h.shiftRepo.Create(c.Request.Context(), shift); err != nil {
+		abortWithError(c, err, "Failed to create shift")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, shift)
+}
+
+// ListShifts retrieves a driver's schedule
+// @Summary List driver shifts
+// @Tags Drivers
+// @Produce json
+// @Param id path string true "Driver ID"
+// @Success 200 {array} models.DriverShift
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/drivers/{id}/shifts [get]
+func (h *ShiftHandler) ListShifts(c *gin.Context) {
+	driverID, ok := h.loadDriverID(c)
+	if !ok {
+		return
+	}
+
+	shifts, err := h.shiftRepo.ListByDriver(c.Request.Context(), driverID)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve shifts")
+		return
+	}
+
+	onShift, err := h.shiftRepo.IsOnShift(c.Request.Context(), driverID)
+	if err != nil {
+		abortWithError(c, err, "Failed to check shift status")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"on_shift": onShift,
+		"shifts":   shifts,
+	})
+}
+
+// DeleteShift removes a shift from a driver's schedule
+// @Summary Delete driver shift
+// @Tags Drivers
+// @Param id path string true "Driver ID"
+// @Param shiftId path string true "Shift ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/drivers/{id}/shifts/{shiftId} [delete]
+func (h *ShiftHandler) DeleteShift(c *gin.Context) {
+	driverID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid driver ID format")
+		return
+	}
+	shiftID, err := uuid.Parse(c.Param("shiftId"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid shift ID format")
+		return
+	}
+
+	shift, err := h.shiftRepo.GetByID(c.Request.Context(), shiftID)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve shift")
+		return
+	}
+	if shift == nil || shift.DriverID != driverID {
+		utils.NotFound(c, "Shift not found")
+		return
+	}
+
+	if err := h.shiftRepo.Delete(c.Request.Context(), shiftID); err != nil {
+		abortWithError(c, err, "Failed to delete shift")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// loadDriverID resolves the :id driver, writing the error response itself
+func (h *ShiftHandler) loadDriverID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid driver ID format")
+		return uuid.Nil, false
+	}
+
+	driver, err := h.driverRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve driver")
+		return uuid.Nil, false
+	}
+	if driver == nil {
+		utils.NotFound(c, "Driver not found")
+		return uuid.Nil, false
+	}
+
+	return id, true
+}
+
+// isShiftTime returns true if value is a valid HH:MM clock time
+func isShiftTime(value string) bool {
+	_, err := time.Parse(models.ShiftTimeLayout, value)
+	return err == nil
+}
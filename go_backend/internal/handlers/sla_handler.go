@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// SLAHandler handles collection SLA rules and breach reports
+type SLAHandler struct {
+	slaRepo     *repository.SLARepository
+	companyRepo *repository.CompanyRepository
+}
+
+// NewSLAHandler creates a new SLAHandler
+func NewSLAHandler(slaRepo *repository.SLARepository, companyRepo *repository.CompanyRepository) *SLAHandler {
+	return &SLAHandler{slaRepo: slaRepo, companyRepo: companyRepo}
+}
+
+// GetSLAReport summarizes the SLA breaches that fell due in a period
+// @Summary Get SLA breaches
+// @Description Breaches that fell due in the period, per company, with the breaches still open now
+// @Tags Analytics
+// @Produce json
+// @Param from query string false "Period start (YYYY-MM-DD or RFC3339); defaults to 30 days before to"
+// @Param to query string false "Period end (YYYY-MM-DD inclusive, or RFC3339); defaults to now"
+// @Param company_id query string false "Only breaches of this company's bins"
+// @Param limit query int false "Maximum number of open breaches (1-100)" default(20)
+// @Success 200 {object} models.SLAReport
+// @Failure 400 {object} utils.APIError
+// @Router /api/v1/analytics/sla [get]
+func (h *SLAHandler) GetSLAReport(c *gin.Context) {
+	period, ok := parsePeriod(c)
+	if !ok {
+		return
+	}
+
+	var companyID *uuid.UUID
+	if value := c.Query("company_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(c, "Invalid company ID format")
+			return
+		}
+		companyID = &id
+	}
+
+	limit := getQueryInt(c, "limit", 20)
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	report, err := h.slaRepo.Report(c.Request.Context(), period, companyID, limit)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve SLA report")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, report)
+}
+
+// ListSLARules retrieves the active SLA rules
+// @Summary List SLA rules
+// @Tags SLA
+// @Produce json
+// @Success 200 {array} models.SLARule
+// @Router /api/v1/sla-rules [get]
+func (h *SLAHandler) ListSLARules(c *gin.Context) {
+	rules, err := h.slaRepo.List(c.Request.Context())
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve SLA rules")
+		return
+	}
+	if rules == nil {
+		rules = []models.SLARule{}
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, rules)
+}
+
+// CreateSLARule creates an SLA rule for a company, a waste type, both or all bins
+// @Summary Create SLA rule
+// @Tags SLA
+// @Accept json
+// @Produce json
+// @Param rule body models.CreateSLARuleRequest true "SLA rule data"
+// @Success 201 {object} models.SLARule
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/sla-rules [post]
+func (h *SLAHandler) CreateSLARule(c *gin.Context) {
+	var req models.CreateSLARuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	rule := &models.SLARule{
+		CompanyID: req.CompanyID,
+		MaxHours:  req.MaxHours,
+	}
+	if req.WasteType != nil {
+		if wasteType := strings.TrimSpace(*req.WasteType); wasteType != "" {
+			rule.WasteType = &wasteType
+		}
+	}
+
+	if rule.CompanyID != nil {
+		company, err := h.companyRepo.GetByID(c.Request.Context(), *rule.CompanyID)
+		if err != nil {
+			abortWithError(c, err, "Failed to retrieve company")
+			return
+		}
+		if company == nil {
+			utils.NotFound(c, "Company not found")
+			return
+		}
+	}
+
+	if err := h.slaRepo.Create(c.Request.Context(), rule); err != nil {
+		if repository.IsUniqueViolation(err) {
+			utils.Conflict(c, "An active SLA rule already exists for this company and waste type")
+			return
+		}
+		abortWithError(c, err, "Failed to create SLA rule")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, rule)
+}
+
+// UpdateSLARule updates an SLA rule; breaches already recorded keep their limit
+// @Summary Update SLA rule
+// @Tags SLA
+// @Accept json
+// @Produce json
+// @Param id path string true "SLA Rule ID"
+// @Param rule body models.UpdateSLARuleRequest true "SLA rule data"
+// @Success 200 {object} models.SLARule
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/sla-rules/{id} [put]
+func (h *SLAHandler) UpdateSLARule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid SLA rule ID format")
+		return
+	}
+
+	var req models.UpdateSLARuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	rule, err := h.slaRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve SLA rule")
+		return
+	}
+	if rule == nil {
+		utils.NotFound(c, "SLA rule not found")
+		return
+	}
+
+	if req.MaxHours != nil {
+		rule.MaxHours = *req.MaxHours
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if err := h.slaRepo.Update(c.Request.Context(), rule); err != nil {
+		if repository.IsUniqueViolation(err) {
+			utils.Conflict(c, "An active SLA rule already exists for this company and waste type")
+			return
+		}
+		abortWithError(c, err, "Failed to update SLA rule")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, rule)
+}
+
+// DeleteSLARule deactivates an SLA rule
+// @Summary Delete SLA rule
+// @Tags SLA
+// @Param id path string true "SLA Rule ID"
+// @Success 204 "No Content"
+// @Router /api/v1/sla-rules/{id} [delete]
+func (h *SLAHandler) DeleteSLARule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid SLA rule ID format")
+		return
+	}
+
+	if err := h.slaRepo.Delete(c.Request.Context(), id); err != nil {
+		abortWithError(c, err, "Failed to delete SLA rule")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
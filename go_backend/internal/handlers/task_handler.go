@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/internal/services"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// TaskHandler handles the full bins offered to drivers by their alerts
+type TaskHandler struct {
+	taskSvc  *services.TaskService
+	taskRepo *repository.TaskOfferRepository
+}
+
+// NewTaskHandler creates a new TaskHandler
+func NewTaskHandler(taskSvc *services.TaskService, taskRepo *repository.TaskOfferRepository) *TaskHandler {
+	return &TaskHandler{taskSvc: taskSvc, taskRepo: taskRepo}
+}
+
+// ListTasks retrieves the tasks offered to a driver
+// @Summary List driver task offers
+// @Tags Drivers
+// @Produce json
+// @Param id path string true "Driver ID"
+// @Param status query string false "Filter by status (offered, accepted, declined, withdrawn)"
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Param cursor query string false "Continue after the next_cursor of a previous page"
+// @Success 200 {array} models.TaskOffer
+// @Failure 400 {object} utils.APIError
+// @Router /api/v1/drivers/{id}/tasks [get]
+func (h *TaskHandler) ListTasks(c *gin.Context) {
+	driverID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid driver ID format")
+		return
+	}
+
+	var status *models.TaskOfferStatus
+	if value := c.Query("status"); value != "" {
+		s := models.TaskOfferStatus(value)
+		if !s.IsValid() {
+			utils.BadRequest(c, "Invalid task status")
+			return
+		}
+		status = &s
+	}
+
+	pagination, ok := parsePage(c, 20)
+	if !ok {
+		return
+	}
+
+	tasks, result, err := h.taskRepo.ListByDriver(c.Request.Context(), driverID, status, pagination.page)
+	if err != nil {
+		listError(c, err, "Failed to retrieve tasks")
+		return
+	}
+	if tasks == nil {
+		tasks = []models.TaskOffer{}
+	}
+
+	utils.SuccessResponseWithPagination(c, tasks, pagination.meta(result))
+}
+
+// AcceptTask accepts a task offered to a driver
+// @Summary Accept task offer
+// @Description Creates a pending collection of the bin assigned to the driver and acknowledges its alert. A bin that already has an open collection withdraws the offer.
+// @Tags Drivers
+// @Produce json
+// @Param id path string true "Driver ID"
+// @Param taskId path string true "Task offer ID"
+// @Success 201 {object} models.AcceptTaskResponse
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/drivers/{id}/tasks/{taskId}/accept [post]
+func (h *TaskHandler) AcceptTask(c *gin.Context) {
+	driverID, taskID, ok := parseTaskIDs(c)
+	if !ok {
+		return
+	}
+
+	accepted, err := h.taskSvc.Accept(c.Request.Context(), driverID, taskID)
+	if err != nil {
+		writeTaskError(c, err, "Failed to accept task")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, accepted)
+}
+
+// DeclineTask declines a task offered to a driver
+// @Summary Decline task offer
+// @Description Offers the bin to the next-nearest driver, or alerts the dispatchers when no driver is left
+// @Tags Drivers
+// @Accept json
+// @Produce json
+// @Param id path string true "Driver ID"
+// @Param taskId path string true "Task offer ID"
+// @Param decline body models.DeclineTaskRequest false "Decline reason"
+// @Success 200 {object} models.TaskOffer
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/drivers/{id}/tasks/{taskId}/decline [post]
+func (h *TaskHandler) DeclineTask(c *gin.Context) {
+	var req models.DeclineTaskRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			validationError(c, err)
+			return
+		}
+	}
+
+	driverID, taskID, ok := parseTaskIDs(c)
+	if !ok {
+		return
+	}
+
+	offer, err := h.taskSvc.Decline(c.Request.Context(), driverID, taskID, req.Reason)
+	if err != nil {
+		writeTaskError(c, err, "Failed to decline task")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, offer)
+}
+
+// parseTaskIDs parses the driver and task offer IDs of the path
+func parseTaskIDs(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	driverID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid driver ID format")
+		return uuid.Nil, uuid.Nil, false
+	}
+	taskID, err := uuid.Parse(c.Param("taskId"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid task ID format")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return driverID, taskID, true
+}
+
+// writeTaskError writes the response for an error answering a task offer
+func writeTaskError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrTaskNotFound):
+		utils.NotFound(c, "Task not found")
+	case errors.Is(err, services.ErrTaskClosed):
+		utils.Conflict(c, "Task offer is no longer open")
+	case errors.Is(err, services.ErrBinCollectionOpen):
+		utils.Conflict(c, "Bin already has an open collection, the offer was withdrawn")
+	default:
+		abortWithError(c, err, message)
+	}
+}
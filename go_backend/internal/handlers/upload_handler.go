@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/services"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// UploadHandler handles file uploads to object storage
+type UploadHandler struct {
+	uploadSvc *services.UploadService
+}
+
+// NewUploadHandler creates a new UploadHandler
+func NewUploadHandler(uploadSvc *services.UploadService) *UploadHandler {
+	return &UploadHandler{uploadSvc: uploadSvc}
+}
+
+// CreateUpload issues a pre-signed URL to upload a file to
+// @Summary Create upload URL
+// @Description PUT the file to upload_url with exactly the returned headers before it expires, then refer to url from e.g. an issue report. Uploads nothing refers to are deleted after a day.
+// @Tags Uploads
+// @Accept json
+// @Produce json
+// @Param upload body models.CreateUploadRequest true "File to upload"
+// @Success 201 {object} models.UploadTicket
+// @Failure 400 {object} utils.APIError
+// @Failure 503 {object} utils.APIError
+// @Router /api/v1/uploads [post]
+func (h *UploadHandler) CreateUpload(c *gin.Context) {
+	var req models.CreateUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+	if !req.Purpose.IsValid() {
+		utils.ValidationError(c, "purpose must be issue_report, waste_image or dispute_evidence")
+		return
+	}
+	req.ContentType = strings.ToLower(strings.TrimSpace(req.ContentType))
+
+	claims, ok := currentClaims(c)
+	if !ok {
+		utils.Unauthorized(c, "Authentication required")
+		return
+	}
+
+	ticket, err := h.uploadSvc.CreateTicket(c.Request.Context(), &req, claims.SubjectID, claims.Role)
+	if err != nil {
+		if writeUploadError(c, err, "") {
+			return
+		}
+		abortWithError(c, err, "Failed to create upload")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, ticket)
+}
+
+// writeUploadError writes the response of an upload error, naming the request
+// field that refers to the upload if any. It returns false for other errors.
+func writeUploadError(c *gin.Context, err error, field string) bool {
+	switch {
+	case errors.Is(err, services.ErrUploadsDisabled):
+		utils.ErrorResponse(c, http.StatusServiceUnavailable, "STORAGE_UNAVAILABLE", "File uploads are not configured")
+	case errors.Is(err, services.ErrUploadType),
+		errors.Is(err, services.ErrUploadTooLarge),
+		errors.Is(err, services.ErrUploadNotFound),
+		errors.Is(err, services.ErrUploadNotReceived),
+		errors.Is(err, services.ErrUploadWrongUploader):
+		if field != "" {
+			utils.ValidationError(c, field+": "+err.Error())
+		} else {
+			validationError(c, err)
+		}
+	default:
+		return false
+	}
+	return true
+}
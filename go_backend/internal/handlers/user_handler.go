@@ -7,17 +7,20 @@ import (
 	"github.com/google/uuid"
 	"github.com/smartwaste/backend/internal/models"
 	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/pkg/security"
 	"github.com/smartwaste/backend/pkg/utils"
 )
 
 // UserHandler handles user-related HTTP requests
 type UserHandler struct {
-	repo *repository.UserRepository
+	repo    *repository.UserRepository
+	orgRepo *repository.OrganizationRepository
+	hasher  security.PasswordHasher
 }
 
 // NewUserHandler creates a new UserHandler
-func NewUserHandler(repo *repository.UserRepository) *UserHandler {
-	return &UserHandler{repo: repo}
+func NewUserHandler(repo *repository.UserRepository, orgRepo *repository.OrganizationRepository, hasher security.PasswordHasher) *UserHandler {
+	return &UserHandler{repo: repo, orgRepo: orgRepo, hasher: hasher}
 }
 
 // GetUser retrieves a user by ID
@@ -38,7 +41,7 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 
 	user, err := h.repo.GetByID(c.Request.Context(), id)
 	if err != nil {
-		utils.InternalError(c, "Failed to retrieve user")
+		abortWithError(c, err, "Failed to retrieve user")
 		return
 	}
 
@@ -50,7 +53,8 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, user.ToResponse())
 }
 
-// CreateUser creates a new user
+// CreateUser registers a citizen with the organization named by its slug, or
+// the default organization
 // @Summary Create a new user
 // @Tags Users
 // @Accept json
@@ -58,18 +62,33 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 // @Param user body models.CreateUserRequest true "User data"
 // @Success 201 {object} models.UserResponse
 // @Failure 400 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
 // @Router /api/v1/users [post]
 func (h *UserHandler) CreateUser(c *gin.Context) {
 	var req models.CreateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationError(c, err.Error())
+		validationError(c, err)
+		return
+	}
+
+	slug := req.Organization
+	if slug == "" {
+		slug = models.DefaultOrganizationSlug
+	}
+	organization, err := h.orgRepo.GetBySlug(c.Request.Context(), slug)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve organization")
+		return
+	}
+	if organization == nil || !organization.IsActive {
+		utils.NotFound(c, "Organization not found")
 		return
 	}
 
 	// Check if email already exists
 	existing, err := h.repo.GetByEmail(c.Request.Context(), req.Email)
 	if err != nil {
-		utils.InternalError(c, "Failed to check existing user")
+		abortWithError(c, err, "Failed to check existing user")
 		return
 	}
 	if existing != nil {
@@ -77,17 +96,25 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		return
 	}
 
+	passwordHash, err := h.hasher.HashPassword(req.Password)
+	if err != nil {
+		abortWithError(c, err, "Failed to secure password")
+		return
+	}
+
 	user := &models.User{
-		Email:        req.Email,
-		PasswordHash: req.Password, // In production, hash this!
-		FullName:     req.FullName,
-		Phone:        req.Phone,
-		Address:      req.Address,
-		RewardPoints: 0,
+		OrganizationID: organization.ID,
+		Email:          req.Email,
+		PasswordHash:   passwordHash,
+		FullName:       req.FullName,
+		Phone:          req.Phone,
+		Address:        req.Address,
+		RewardPoints:   0,
+		Role:           models.RoleCitizen,
 	}
 
 	if err := h.repo.Create(c.Request.Context(), user); err != nil {
-		utils.InternalError(c, "Failed to create user")
+		abortWithError(c, err, "Failed to create user")
 		return
 	}
 
@@ -115,13 +142,13 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 
 	var req models.UpdateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationError(c, err.Error())
+		validationError(c, err)
 		return
 	}
 
 	user, err := h.repo.GetByID(c.Request.Context(), id)
 	if err != nil {
-		utils.InternalError(c, "Failed to retrieve user")
+		abortWithError(c, err, "Failed to retrieve user")
 		return
 	}
 	if user == nil {
@@ -141,13 +168,63 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 	}
 
 	if err := h.repo.Update(c.Request.Context(), user); err != nil {
-		utils.InternalError(c, "Failed to update user")
+		abortWithError(c, err, "Failed to update user")
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, user.ToResponse())
 }
 
+// UpdateUserRole changes the access role of a user
+// @Summary Update user role
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body models.UpdateRoleRequest true "New role"
+// @Success 200 {object} models.UserResponse
+// @Failure 400 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/users/{id}/role [put]
+func (h *UserHandler) UpdateUserRole(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		utils.BadRequest(c, "Invalid user ID format")
+		return
+	}
+
+	var req models.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	// Drivers authenticate through the drivers table
+	if !req.Role.IsValid() || req.Role == models.RoleDriver {
+		utils.ValidationError(c, "Invalid role")
+		return
+	}
+
+	user, err := h.repo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve user")
+		return
+	}
+	if user == nil {
+		utils.NotFound(c, "User not found")
+		return
+	}
+
+	if err := h.repo.UpdateRole(c.Request.Context(), id, req.Role); err != nil {
+		abortWithError(c, err, "Failed to update user role")
+		return
+	}
+	user.Role = req.Role
+
+	utils.SuccessResponse(c, http.StatusOK, user.ToResponse())
+}
+
 // GetRewardPoints retrieves a user's reward points
 // @Summary Get user reward points
 // @Tags Users
@@ -166,7 +243,7 @@ func (h *UserHandler) GetRewardPoints(c *gin.Context) {
 
 	points, err := h.repo.GetRewardPoints(c.Request.Context(), id)
 	if err != nil {
-		utils.InternalError(c, "Failed to retrieve reward points")
+		abortWithError(c, err, "Failed to retrieve reward points")
 		return
 	}
 
@@ -196,12 +273,12 @@ func (h *UserHandler) AddRewardPoints(c *gin.Context) {
 
 	var req models.AddRewardPointsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationError(c, err.Error())
+		validationError(c, err)
 		return
 	}
 
 	if err := h.repo.UpdateRewardPoints(c.Request.Context(), id, req.Points); err != nil {
-		utils.InternalError(c, "Failed to update reward points")
+		abortWithError(c, err, "Failed to update reward points")
 		return
 	}
 
@@ -222,16 +299,18 @@ func (h *UserHandler) AddRewardPoints(c *gin.Context) {
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param per_page query int false "Items per page" default(20)
+// @Param cursor query string false "Continue after the next_cursor of a previous page"
 // @Success 200 {array} models.UserResponse
 // @Router /api/v1/users [get]
 func (h *UserHandler) ListUsers(c *gin.Context) {
-	page := getQueryInt(c, "page", 1)
-	perPage := getQueryInt(c, "per_page", 20)
-	offset := (page - 1) * perPage
+	pagination, ok := parsePage(c, 20)
+	if !ok {
+		return
+	}
 
-	users, err := h.repo.List(c.Request.Context(), perPage, offset)
+	users, result, err := h.repo.List(c.Request.Context(), pagination.page)
 	if err != nil {
-		utils.InternalError(c, "Failed to retrieve users")
+		listError(c, err, "Failed to retrieve users")
 		return
 	}
 
@@ -240,10 +319,7 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 		responses[i] = *u.ToResponse()
 	}
 
-	utils.SuccessResponseWithPagination(c, responses, &utils.Pagination{
-		Page:    page,
-		PerPage: perPage,
-	})
+	utils.SuccessResponseWithPagination(c, responses, pagination.meta(result))
 }
 
 // DeleteUser deletes a user
@@ -262,7 +338,7 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 	}
 
 	if err := h.repo.Delete(c.Request.Context(), id); err != nil {
-		utils.InternalError(c, "Failed to delete user")
+		abortWithError(c, err, "Failed to delete user")
 		return
 	}
 
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/smartwaste/backend/pkg/utils"
+	"github.com/smartwaste/pkg/validation"
+)
+
+// RegisterValidators registers the custom validators of the request DTOs on
+// the validator Gin binds requests with
+func RegisterValidators() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return nil
+	}
+	return validation.Register(v)
+}
+
+// validationError writes a 400 response for a request that failed binding or
+// validation, detailing the failed fields when the error concerns them
+func validationError(c *gin.Context, err error) {
+	fields := validation.Translate(err)
+	if fields == nil {
+		validationError(c, err)
+		return
+	}
+	utils.ValidationErrors(c, validation.Summary(fields), fields)
+}
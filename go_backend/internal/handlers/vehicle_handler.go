@@ -0,0 +1,373 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// VehicleHandler handles fleet vehicles and their assignment to drivers
+type VehicleHandler struct {
+	vehicleRepo *repository.VehicleRepository
+	driverRepo  *repository.DriverRepository
+}
+
+// NewVehicleHandler creates a new VehicleHandler
+func NewVehicleHandler(vehicleRepo *repository.VehicleRepository, driverRepo *repository.DriverRepository) *VehicleHandler {
+	return &VehicleHandler{
+		vehicleRepo: vehicleRepo,
+		driverRepo:  driverRepo,
+	}
+}
+
+// GetVehicle retrieves a vehicle by ID
+// @Summary Get vehicle by ID
+// @Tags Vehicles
+// @Produce json
+// @Param id path string true "Vehicle ID"
+// @Success 200 {object} models.Vehicle
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/vehicles/{id} [get]
+func (h *VehicleHandler) GetVehicle(c *gin.Context) {
+	vehicle, ok := h.loadVehicle(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, vehicle)
+}
+
+// CreateVehicle registers a new vehicle
+// @Summary Create a new vehicle
+// @Tags Vehicles
+// @Accept json
+// @Produce json
+// @Param vehicle body models.CreateVehicleRequest true "Vehicle data"
+// @Success 201 {object} models.Vehicle
+// @Failure 400 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/vehicles [post]
+func (h *VehicleHandler) CreateVehicle(c *gin.Context) {
+	var req models.CreateVehicleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	vehicle := &models.Vehicle{
+		PlateNumber:       req.PlateNumber,
+		Make:              req.Make,
+		Model:             req.Model,
+		CapacityLiters:    req.CapacityLiters,
+		CapacityKg:        req.CapacityKg,
+		FuelType:          req.FuelType,
+		MaintenanceStatus: models.MaintenanceStatusOperational,
+		NextMaintenanceAt: req.NextMaintenanceAt,
+	}
+	if vehicle.FuelType == "" {
+		vehicle.FuelType = models.FuelTypeDiesel
+	}
+	if req.MaintenanceStatus != nil {
+		vehicle.MaintenanceStatus = *req.MaintenanceStatus
+	}
+	if !vehicle.FuelType.IsValid() {
+		utils.ValidationError(c, "Invalid fuel type")
+		return
+	}
+	if !vehicle.MaintenanceStatus.IsValid() {
+		utils.ValidationError(c, "Invalid maintenance status")
+		return
+	}
+
+	if err := h.vehicleRepo.Create(c.Request.Context(), vehicle); err != nil {
+		if repository.IsUniqueViolation(err) {
+			utils.Conflict(c, "Plate number already registered")
+			return
+		}
+		abortWithError(c, err, "Failed to create vehicle")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, vehicle)
+}
+
+// UpdateVehicle updates a vehicle
+// @Summary Update vehicle
+// @Tags Vehicles
+// @Accept json
+// @Produce json
+// @Param id path string true "Vehicle ID"
+// @Param vehicle body models.UpdateVehicleRequest true "Vehicle data"
+// @Success 200 {object} models.Vehicle
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/vehicles/{id} [put]
+func (h *VehicleHandler) UpdateVehicle(c *gin.Context) {
+	vehicle, ok := h.loadVehicle(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var req models.UpdateVehicleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	if req.PlateNumber != nil {
+		vehicle.PlateNumber = *req.PlateNumber
+	}
+	if req.Make != nil {
+		vehicle.Make = req.Make
+	}
+	if req.Model != nil {
+		vehicle.Model = req.Model
+	}
+	if req.CapacityLiters != nil {
+		vehicle.CapacityLiters = *req.CapacityLiters
+	}
+	if req.CapacityKg != nil {
+		vehicle.CapacityKg = req.CapacityKg
+	}
+	if req.FuelType != nil {
+		if !req.FuelType.IsValid() {
+			utils.ValidationError(c, "Invalid fuel type")
+			return
+		}
+		vehicle.FuelType = *req.FuelType
+	}
+	if req.MaintenanceStatus != nil {
+		if !req.MaintenanceStatus.IsValid() {
+			utils.ValidationError(c, "Invalid maintenance status")
+			return
+		}
+		vehicle.MaintenanceStatus = *req.MaintenanceStatus
+	}
+	if req.LastMaintenanceAt != nil {
+		vehicle.LastMaintenanceAt = req.LastMaintenanceAt
+	}
+	if req.NextMaintenanceAt != nil {
+		vehicle.NextMaintenanceAt = req.NextMaintenanceAt
+	}
+	if req.IsActive != nil {
+		vehicle.IsActive = *req.IsActive
+	}
+
+	if err := h.vehicleRepo.Update(c.Request.Context(), vehicle); err != nil {
+		if repository.IsUniqueViolation(err) {
+			utils.Conflict(c, "Plate number already registered")
+			return
+		}
+		abortWithError(c, err, "Failed to update vehicle")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, vehicle)
+}
+
+// ListVehicles lists active vehicles
+// @Summary List vehicles
+// @Tags Vehicles
+// @Produce json
+// @Param status query string false "Filter by maintenance status"
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Param cursor query string false "Continue after the next_cursor of a previous page"
+// @Success 200 {array} models.Vehicle
+// @Router /api/v1/vehicles [get]
+func (h *VehicleHandler) ListVehicles(c *gin.Context) {
+	pagination, ok := parsePage(c, 20)
+	if !ok {
+		return
+	}
+
+	var status *models.MaintenanceStatus
+	if value := c.Query("status"); value != "" {
+		s := models.MaintenanceStatus(value)
+		if !s.IsValid() {
+			utils.ValidationError(c, "Invalid maintenance status")
+			return
+		}
+		status = &s
+	}
+
+	vehicles, result, err := h.vehicleRepo.List(c.Request.Context(), status, pagination.page)
+	if err != nil {
+		listError(c, err, "Failed to retrieve vehicles")
+		return
+	}
+	if vehicles == nil {
+		vehicles = []models.Vehicle{}
+	}
+
+	utils.SuccessResponseWithPagination(c, vehicles, pagination.meta(result))
+}
+
+// DeleteVehicle retires a vehicle and releases it from its driver
+// @Summary Delete vehicle
+// @Tags Vehicles
+// @Param id path string true "Vehicle ID"
+// @Success 204 "No Content"
+// @Router /api/v1/vehicles/{id} [delete]
+func (h *VehicleHandler) DeleteVehicle(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid vehicle ID format")
+		return
+	}
+
+	if err := h.vehicleRepo.Delete(c.Request.Context(), id); err != nil {
+		abortWithError(c, err, "Failed to delete vehicle")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetDriverVehicle retrieves the vehicle assigned to a driver
+// @Summary Get driver's vehicle
+// @Tags Drivers
+// @Produce json
+// @Param id path string true "Driver ID"
+// @Success 200 {object} models.Vehicle
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/drivers/{id}/vehicle [get]
+func (h *VehicleHandler) GetDriverVehicle(c *gin.Context) {
+	driver, ok := h.loadDriver(c)
+	if !ok {
+		return
+	}
+	if driver.VehicleID == nil {
+		utils.NotFound(c, "Driver has no vehicle assigned")
+		return
+	}
+
+	vehicle, ok := h.loadVehicle(c, driver.VehicleID.String())
+	if !ok {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, vehicle)
+}
+
+// AssignVehicle assigns a vehicle to a driver
+// @Summary Assign vehicle to driver
+// @Tags Drivers
+// @Accept json
+// @Produce json
+// @Param id path string true "Driver ID"
+// @Param request body models.AssignVehicleRequest true "Vehicle to assign"
+// @Success 200 {object} models.DriverResponse
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/drivers/{id}/vehicle [put]
+func (h *VehicleHandler) AssignVehicle(c *gin.Context) {
+	driver, ok := h.loadDriver(c)
+	if !ok {
+		return
+	}
+
+	var req models.AssignVehicleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	vehicle, ok := h.loadVehicle(c, req.VehicleID.String())
+	if !ok {
+		return
+	}
+	if !vehicle.IsServiceable() {
+		utils.Conflict(c, "Vehicle is not available for service")
+		return
+	}
+
+	holder, err := h.driverRepo.GetByVehicleID(c.Request.Context(), vehicle.ID)
+	if err != nil {
+		abortWithError(c, err, "Failed to check vehicle assignment")
+		return
+	}
+	if holder != nil && holder.ID != driver.ID {
+		utils.Conflict(c, "Vehicle is already assigned to another driver")
+		return
+	}
+
+	if err := h.driverRepo.AssignVehicle(c.Request.Context(), driver.ID, &vehicle.ID); err != nil {
+		if repository.IsUniqueViolation(err) {
+			utils.Conflict(c, "Vehicle is already assigned to another driver")
+			return
+		}
+		abortWithError(c, err, "Failed to assign vehicle")
+		return
+	}
+
+	driver.VehicleID = &vehicle.ID
+	utils.SuccessResponse(c, http.StatusOK, driver.ToResponse())
+}
+
+// UnassignVehicle removes a driver's vehicle assignment
+// @Summary Unassign driver's vehicle
+// @Tags Drivers
+// @Param id path string true "Driver ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/drivers/{id}/vehicle [delete]
+func (h *VehicleHandler) UnassignVehicle(c *gin.Context) {
+	driver, ok := h.loadDriver(c)
+	if !ok {
+		return
+	}
+
+	if err := h.driverRepo.AssignVehicle(c.Request.Context(), driver.ID, nil); err != nil {
+		abortWithError(c, err, "Failed to unassign vehicle")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// loadVehicle resolves a vehicle ID, writing the error response itself
+func (h *VehicleHandler) loadVehicle(c *gin.Context, idParam string) (*models.Vehicle, bool) {
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		utils.BadRequest(c, "Invalid vehicle ID format")
+		return nil, false
+	}
+
+	vehicle, err := h.vehicleRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve vehicle")
+		return nil, false
+	}
+	if vehicle == nil {
+		utils.NotFound(c, "Vehicle not found")
+		return nil, false
+	}
+
+	return vehicle, true
+}
+
+// loadDriver resolves the :id driver, writing the error response itself
+func (h *VehicleHandler) loadDriver(c *gin.Context) (*models.Driver, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid driver ID format")
+		return nil, false
+	}
+
+	driver, err := h.driverRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve driver")
+		return nil, false
+	}
+	if driver == nil {
+		utils.NotFound(c, "Driver not found")
+		return nil, false
+	}
+
+	return driver, true
+}
@@ -0,0 +1,359 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/internal/services"
+	"github.com/smartwaste/backend/pkg/utils"
+)
+
+// ZoneHandler handles the zones bins are grouped into and the zones drivers cover
+type ZoneHandler struct {
+	svc *services.ZoneService
+}
+
+// NewZoneHandler creates a new ZoneHandler
+func NewZoneHandler(svc *services.ZoneService) *ZoneHandler {
+	return &ZoneHandler{svc: svc}
+}
+
+// ListZones retrieves the zones of the organization
+// @Summary List zones
+// @Tags Zones
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Param cursor query string false "Continue after the next_cursor of a previous page"
+// @Success 200 {array} models.Zone
+// @Router /api/v1/zones [get]
+func (h *ZoneHandler) ListZones(c *gin.Context) {
+	pagination, ok := parsePage(c, 20)
+	if !ok {
+		return
+	}
+
+	zones, result, err := h.svc.List(c.Request.Context(), pagination.page)
+	if err != nil {
+		listError(c, err, "Failed to retrieve zones")
+		return
+	}
+
+	utils.SuccessResponseWithPagination(c, zones, pagination.meta(result))
+}
+
+// CreateZone creates a zone, moving the bins within its boundary into it
+// @Summary Create zone
+// @Tags Zones
+// @Accept json
+// @Produce json
+// @Param zone body models.CreateZoneRequest true "Zone"
+// @Success 201 {object} models.Zone
+// @Failure 400 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/zones [post]
+func (h *ZoneHandler) CreateZone(c *gin.Context) {
+	var req models.CreateZoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+	if req.Kind != "" && !req.Kind.IsValid() {
+		utils.ValidationError(c, "kind must be neighborhood or district")
+		return
+	}
+	if err := req.Boundary.Validate(); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	zone, err := h.svc.Create(c.Request.Context(), &req)
+	if err != nil {
+		if repository.IsUniqueViolation(err) {
+			utils.Conflict(c, "A zone with this name already exists")
+			return
+		}
+		abortWithError(c, err, "Failed to create zone")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, zone)
+}
+
+// GetZone retrieves a zone
+// @Summary Get zone
+// @Tags Zones
+// @Produce json
+// @Param id path string true "Zone ID"
+// @Success 200 {object} models.Zone
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/zones/{id} [get]
+func (h *ZoneHandler) GetZone(c *gin.Context) {
+	zone, ok := h.loadZone(c)
+	if !ok {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, zone)
+}
+
+// UpdateZone updates a zone; a new boundary reassigns the bins of the organization
+// @Summary Update zone
+// @Tags Zones
+// @Accept json
+// @Produce json
+// @Param id path string true "Zone ID"
+// @Param zone body models.UpdateZoneRequest true "Zone details"
+// @Success 200 {object} models.Zone
+// @Failure 400 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/zones/{id} [put]
+func (h *ZoneHandler) UpdateZone(c *gin.Context) {
+	zone, ok := h.loadZone(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateZoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	if req.Name != nil {
+		zone.Name = *req.Name
+	}
+	if req.Kind != nil {
+		if !req.Kind.IsValid() {
+			utils.ValidationError(c, "kind must be neighborhood or district")
+			return
+		}
+		zone.Kind = *req.Kind
+	}
+	if req.Description != nil {
+		zone.Description = req.Description
+	}
+	if req.Boundary != nil {
+		if err := req.Boundary.Validate(); err != nil {
+			utils.ValidationError(c, err.Error())
+			return
+		}
+		zone.Boundary = req.Boundary
+	}
+
+	if err := h.svc.Update(c.Request.Context(), zone); err != nil {
+		if repository.IsUniqueViolation(err) {
+			utils.Conflict(c, "A zone with this name already exists")
+			return
+		}
+		abortWithError(c, err, "Failed to update zone")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, zone)
+}
+
+// DeleteZone deletes a zone, moving its bins to the zone containing them next
+// @Summary Delete zone
+// @Tags Zones
+// @Param id path string true "Zone ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} utils.APIError
+// @Failure 409 {object} utils.APIError
+// @Router /api/v1/zones/{id} [delete]
+func (h *ZoneHandler) DeleteZone(c *gin.Context) {
+	zone, ok := h.loadZone(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Delete(c.Request.Context(), zone); err != nil {
+		if repository.IsForeignKeyViolation(err) {
+			utils.Conflict(c, "Zone is still covered by drivers")
+			return
+		}
+		abortWithError(c, err, "Failed to delete zone")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListZoneBins retrieves the active bins of a zone
+// @Summary List the bins of a zone
+// @Tags Zones
+// @Produce json
+// @Param id path string true "Zone ID"
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Param cursor query string false "Continue after the next_cursor of a previous page"
+// @Success 200 {array} models.BinResponse
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/zones/{id}/bins [get]
+func (h *ZoneHandler) ListZoneBins(c *gin.Context) {
+	zone, ok := h.loadZone(c)
+	if !ok {
+		return
+	}
+
+	pagination, ok := parsePage(c, 20)
+	if !ok {
+		return
+	}
+
+	bins, result, err := h.svc.Bins(c.Request.Context(), zone.ID, pagination.page)
+	if err != nil {
+		listError(c, err, "Failed to retrieve bins")
+		return
+	}
+
+	responses := make([]models.BinResponse, len(bins))
+	for i, b := range bins {
+		responses[i] = *b.ToResponse()
+	}
+
+	utils.SuccessResponseWithPagination(c, responses, pagination.meta(result))
+}
+
+// GetZoneAnalytics summarizes the bins of a zone and its collections over a period
+// @Summary Get zone analytics
+// @Tags Zones
+// @Produce json
+// @Param id path string true "Zone ID"
+// @Param from query string false "Period start (YYYY-MM-DD or RFC3339); defaults to 30 days before to"
+// @Param to query string false "Period end (YYYY-MM-DD inclusive, or RFC3339); defaults to now"
+// @Success 200 {object} models.ZoneAnalyticsReport
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/zones/{id}/analytics [get]
+func (h *ZoneHandler) GetZoneAnalytics(c *gin.Context) {
+	zone, ok := h.loadZone(c)
+	if !ok {
+		return
+	}
+
+	h.writeAnalytics(c, &zone.ID)
+}
+
+// GetZonesAnalytics summarizes every zone of the organization over a period
+// @Summary Get analytics per zone
+// @Tags Analytics
+// @Produce json
+// @Param from query string false "Period start (YYYY-MM-DD or RFC3339); defaults to 30 days before to"
+// @Param to query string false "Period end (YYYY-MM-DD inclusive, or RFC3339); defaults to now"
+// @Success 200 {object} models.ZoneAnalyticsReport
+// @Router /api/v1/analytics/zones [get]
+func (h *ZoneHandler) GetZonesAnalytics(c *gin.Context) {
+	h.writeAnalytics(c, nil)
+}
+
+func (h *ZoneHandler) writeAnalytics(c *gin.Context, zoneID *uuid.UUID) {
+	period, ok := parsePeriod(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.svc.Analytics(c.Request.Context(), period, zoneID)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve zone analytics")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, report)
+}
+
+// GetDriverZones retrieves the zones a driver covers; drivers without any are
+// dispatched anywhere
+// @Summary Get driver zones
+// @Tags Zones
+// @Produce json
+// @Param id path string true "Driver ID"
+// @Success 200 {array} models.Zone
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/drivers/{id}/zones [get]
+func (h *ZoneHandler) GetDriverZones(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid driver ID format")
+		return
+	}
+
+	zones, err := h.svc.DriverZones(c.Request.Context(), id)
+	if err != nil {
+		h.writeDriverError(c, err, "Failed to retrieve driver zones")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, zones)
+}
+
+// SetDriverZones restricts dispatch of a driver to the zones, or lifts the
+// restriction with an empty list
+// @Summary Set driver zones
+// @Tags Zones
+// @Accept json
+// @Produce json
+// @Param id path string true "Driver ID"
+// @Param zones body models.SetDriverZonesRequest true "Zones"
+// @Success 200 {array} models.Zone
+// @Failure 400 {object} utils.APIError
+// @Failure 404 {object} utils.APIError
+// @Router /api/v1/drivers/{id}/zones [put]
+func (h *ZoneHandler) SetDriverZones(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid driver ID format")
+		return
+	}
+
+	var req models.SetDriverZonesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	zones, err := h.svc.SetDriverZones(c.Request.Context(), id, req.ZoneIDs)
+	if err != nil {
+		h.writeDriverError(c, err, "Failed to set driver zones")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, zones)
+}
+
+// writeDriverError maps the errors of driver zone changes to responses
+func (h *ZoneHandler) writeDriverError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrDriverNotFound):
+		utils.NotFound(c, "Driver not found")
+	case errors.Is(err, services.ErrZoneNotFound):
+		utils.NotFound(c, "Zone not found")
+	default:
+		abortWithError(c, err, message)
+	}
+}
+
+// loadZone resolves the :id zone, writing the error response itself
+func (h *ZoneHandler) loadZone(c *gin.Context) (*models.Zone, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid zone ID format")
+		return nil, false
+	}
+
+	zone, err := h.svc.Get(c.Request.Context(), id)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve zone")
+		return nil, false
+	}
+	if zone == nil {
+		utils.NotFound(c, "Zone not found")
+		return nil, false
+	}
+
+	return zone, true
+}
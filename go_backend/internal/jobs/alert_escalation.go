@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/smartwaste/backend/internal/services"
+)
+
+// AlertEscalator escalates the bin alerts drivers did not acknowledge in time
+type AlertEscalator struct {
+	notificationService *services.NotificationService
+}
+
+// NewAlertEscalator creates a new AlertEscalator
+func NewAlertEscalator(notificationService *services.NotificationService) *AlertEscalator {
+	return &AlertEscalator{notificationService: notificationService}
+}
+
+// Run escalates the alerts due for escalation
+func (e *AlertEscalator) Run(ctx context.Context) error {
+	escalated, err := e.notificationService.EscalateAlerts(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to escalate alerts: %w", err)
+	}
+	if escalated > 0 {
+		log.Printf("Escalated %d unacknowledged alerts", escalated)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package jobs
+
+import (
+	"context"
+	"log"
+
+	"github.com/smartwaste/backend/internal/services"
+)
+
+// AutoDispatcher periodically dispatches full bins and pickup requests to drivers
+type AutoDispatcher struct {
+	dispatchService *services.DispatchService
+}
+
+// NewAutoDispatcher creates a new AutoDispatcher
+func NewAutoDispatcher(dispatchService *services.DispatchService) *AutoDispatcher {
+	return &AutoDispatcher{dispatchService: dispatchService}
+}
+
+// Run dispatches every bin awaiting collection and every pending pickup request
+func (d *AutoDispatcher) Run(ctx context.Context) error {
+	result, err := d.dispatchService.DispatchPending(ctx)
+	if err != nil {
+		return err
+	}
+
+	if result.Dispatched > 0 || result.Pickups > 0 || result.Unassigned > 0 || result.OutOfContract > 0 {
+		log.Printf("Auto-dispatch: %d collections and %d pickups dispatched, %d left without a driver, %d company bins outside any contract",
+			result.Dispatched, result.Pickups, result.Unassigned, result.OutOfContract)
+	}
+	return nil
+}
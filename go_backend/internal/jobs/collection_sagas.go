@@ -0,0 +1,24 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/smartwaste/backend/internal/services"
+)
+
+// CollectionSagaRunner retries the failed steps of collection sagas once
+// they are due
+type CollectionSagaRunner struct {
+	sagaSvc *services.CollectionSagaService
+}
+
+// NewCollectionSagaRunner creates a new CollectionSagaRunner
+func NewCollectionSagaRunner(sagaSvc *services.CollectionSagaService) *CollectionSagaRunner {
+	return &CollectionSagaRunner{sagaSvc: sagaSvc}
+}
+
+// Run resumes the sagas with a step due
+func (r *CollectionSagaRunner) Run(ctx context.Context) error {
+	_, err := r.sagaSvc.ResumeDue(ctx)
+	return err
+}
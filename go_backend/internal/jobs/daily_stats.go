@@ -0,0 +1,69 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/smartwaste/backend/internal/repository"
+)
+
+// dailyStatsChunk bounds the days materialized in one transaction, so a
+// backfill of a long history does not hold one for the whole of it
+const dailyStatsChunk = 31 * 24 * time.Hour
+
+// DailyStatsMaterializer snapshots the readings and collections of every
+// organization per UTC day for the trend analytics
+type DailyStatsMaterializer struct {
+	statsRepo   *repository.DailyStatsRepository
+	refreshDays int
+}
+
+// NewDailyStatsMaterializer creates a new DailyStatsMaterializer that
+// recomputes the last refreshDays days on every run, picking up late readings
+// and collections completed after the day they started
+func NewDailyStatsMaterializer(statsRepo *repository.DailyStatsRepository, refreshDays int) *DailyStatsMaterializer {
+	return &DailyStatsMaterializer{statsRepo: statsRepo, refreshDays: refreshDays}
+}
+
+// Run materializes the days up to yesterday. The first run backfills them
+// from the earliest reading or collection.
+func (m *DailyStatsMaterializer) Run(ctx context.Context) error {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	from := today.AddDate(0, 0, -m.refreshDays)
+
+	horizon, err := m.statsRepo.Horizon(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the daily stats horizon: %w", err)
+	}
+	if horizon == nil {
+		earliest, err := m.statsRepo.EarliestActivity(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get the earliest activity: %w", err)
+		}
+		if earliest == nil {
+			return nil
+		}
+		from = earliest.UTC().Truncate(24 * time.Hour)
+	} else if horizon.Before(from) {
+		from = *horizon
+	}
+
+	var days int64
+	for start := from; start.Before(today); start = start.Add(dailyStatsChunk) {
+		end := start.Add(dailyStatsChunk)
+		if end.After(today) {
+			end = today
+		}
+		rows, err := m.statsRepo.Materialize(ctx, start, end)
+		if err != nil {
+			return fmt.Errorf("failed to materialize the daily stats from %s: %w", start.Format("2006-01-02"), err)
+		}
+		days += rows
+	}
+	if days > 0 {
+		log.Printf("Materialized %d daily stats since %s", days, from.Format("2006-01-02"))
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/smartwaste/backend/internal/services"
+)
+
+// ExportCleaner deletes background report exports past their retention
+type ExportCleaner struct {
+	reportService *services.ReportService
+}
+
+// NewExportCleaner creates a new ExportCleaner
+func NewExportCleaner(reportService *services.ReportService) *ExportCleaner {
+	return &ExportCleaner{reportService: reportService}
+}
+
+// Run deletes the expired exports and their files
+func (e *ExportCleaner) Run(ctx context.Context) error {
+	return e.reportService.PurgeExpired(ctx)
+}
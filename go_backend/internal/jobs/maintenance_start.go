@@ -0,0 +1,32 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/smartwaste/backend/internal/services"
+)
+
+// MaintenanceStarter takes bins out of service when their scheduled
+// maintenance becomes due
+type MaintenanceStarter struct {
+	maintenanceService *services.MaintenanceService
+}
+
+// NewMaintenanceStarter creates a new MaintenanceStarter
+func NewMaintenanceStarter(maintenanceService *services.MaintenanceService) *MaintenanceStarter {
+	return &MaintenanceStarter{maintenanceService: maintenanceService}
+}
+
+// Run starts the maintenance scheduled for now or earlier
+func (m *MaintenanceStarter) Run(ctx context.Context) error {
+	started, err := m.maintenanceService.StartDue(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start scheduled maintenance: %w", err)
+	}
+	if started > 0 {
+		log.Printf("Started %d scheduled bin maintenance", started)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/internal/services"
+)
+
+// OfflineBinDetector flags bins whose sensors have stopped reporting
+type OfflineBinDetector struct {
+	binRepo             *repository.BinRepository
+	notificationService *services.NotificationService
+	settings            *services.SettingsService
+}
+
+// NewOfflineBinDetector creates a new OfflineBinDetector; the offline window
+// comes from the runtime settings
+func NewOfflineBinDetector(binRepo *repository.BinRepository, notificationService *services.NotificationService, settings *services.SettingsService) *OfflineBinDetector {
+	return &OfflineBinDetector{
+		binRepo:             binRepo,
+		notificationService: notificationService,
+		settings:            settings,
+	}
+}
+
+// Run marks bins without a reading inside the offline window as offline and
+// raises one alert per newly offline bin
+func (d *OfflineBinDetector) Run(ctx context.Context) error {
+	bins, err := d.binRepo.MarkOffline(ctx, time.Now().Add(-d.settings.OfflineAfter()))
+	if err != nil {
+		return fmt.Errorf("failed to mark bins offline: %w", err)
+	}
+
+	for i := range bins {
+		log.Printf("Bin %s has not reported since %s, marked offline",
+			bins[i].DeviceID, bins[i].LastUpdatedAt.Format(time.RFC3339))
+		if err := d.notificationService.NotifyBinOffline(ctx, &bins[i]); err != nil {
+			log.Printf("Failed to send offline alert for bin %s: %v", bins[i].DeviceID, err)
+		}
+	}
+
+	return nil
+}
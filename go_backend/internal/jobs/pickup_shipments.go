@@ -0,0 +1,24 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/smartwaste/backend/internal/services"
+)
+
+// PickupShipper retries shipping the collected pickup requests whose
+// shipment failed
+type PickupShipper struct {
+	pickupSvc *services.PickupRequestService
+}
+
+// NewPickupShipper creates a new PickupShipper
+func NewPickupShipper(pickupSvc *services.PickupRequestService) *PickupShipper {
+	return &PickupShipper{pickupSvc: pickupSvc}
+}
+
+// Run ships the collected pickups left without a shipment
+func (s *PickupShipper) Run(ctx context.Context) error {
+	_, err := s.pickupSvc.ShipPending(ctx)
+	return err
+}
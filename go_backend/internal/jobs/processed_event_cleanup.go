@@ -0,0 +1,34 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/smartwaste/backend/internal/repository"
+)
+
+// ProcessedEventCleaner forgets the shipment events handled longer ago than
+// JetStream could redeliver them
+type ProcessedEventCleaner struct {
+	processedRepo *repository.ProcessedEventRepository
+	retention     time.Duration
+}
+
+// NewProcessedEventCleaner creates a new ProcessedEventCleaner
+func NewProcessedEventCleaner(processedRepo *repository.ProcessedEventRepository, retention time.Duration) *ProcessedEventCleaner {
+	return &ProcessedEventCleaner{processedRepo: processedRepo, retention: retention}
+}
+
+// Run deletes the events processed before the retention
+func (c *ProcessedEventCleaner) Run(ctx context.Context) error {
+	deleted, err := c.processedRepo.DeleteProcessedBefore(ctx, time.Now().Add(-c.retention))
+	if err != nil {
+		return fmt.Errorf("failed to delete processed events: %w", err)
+	}
+	if deleted > 0 {
+		log.Printf("Forgot %d processed shipment events", deleted)
+	}
+	return nil
+}
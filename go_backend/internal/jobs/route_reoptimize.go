@@ -0,0 +1,32 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/smartwaste/backend/internal/services"
+)
+
+// RouteReoptimizer re-sequences the active routes whose bins were emptied by
+// someone else or that have full bins appearing nearby
+type RouteReoptimizer struct {
+	routeService *services.RouteService
+}
+
+// NewRouteReoptimizer creates a new RouteReoptimizer
+func NewRouteReoptimizer(routeService *services.RouteService) *RouteReoptimizer {
+	return &RouteReoptimizer{routeService: routeService}
+}
+
+// Run re-optimizes the active routes whose stops changed
+func (r *RouteReoptimizer) Run(ctx context.Context) error {
+	updated, err := r.routeService.ReoptimizeActive(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to re-optimize routes: %w", err)
+	}
+	if updated > 0 {
+		log.Printf("Re-optimized %d active routes", updated)
+	}
+	return nil
+}
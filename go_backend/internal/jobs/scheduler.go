@@ -0,0 +1,157 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Job is a unit of background work run on a fixed interval or cron schedule
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Schedule string // Standard 5-field cron expression; takes precedence over Interval
+	// ScheduleFunc returns the cron expression of a job whose schedule changes
+	// at runtime, or "" while it is paused; it takes precedence over Schedule
+	ScheduleFunc func() string
+	Run          func(ctx context.Context) error
+}
+
+// scheduleRecheck is how often a job with a ScheduleFunc looks for a new schedule
+const scheduleRecheck = time.Minute
+
+// scheduledJob is a registered job with its resolved schedule
+type scheduledJob struct {
+	Job
+	schedule cron.Schedule
+}
+
+// Scheduler runs registered jobs in the background until its context is cancelled
+type Scheduler struct {
+	jobs []scheduledJob
+	wg   sync.WaitGroup
+}
+
+// NewScheduler creates a new Scheduler
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// Register adds a job to the scheduler. Jobs must be registered before Start.
+func (s *Scheduler) Register(job Job) error {
+	sj := scheduledJob{Job: job}
+	switch {
+	case job.ScheduleFunc != nil:
+		if spec := job.ScheduleFunc(); spec != "" {
+			if _, err := cron.ParseStandard(spec); err != nil {
+				return fmt.Errorf("invalid schedule %q for job %s: %w", spec, job.Name, err)
+			}
+		}
+	case job.Schedule != "":
+		schedule, err := cron.ParseStandard(job.Schedule)
+		if err != nil {
+			return fmt.Errorf("invalid schedule %q for job %s: %w", job.Schedule, job.Name, err)
+		}
+		sj.schedule = schedule
+	case job.Interval > 0:
+		sj.schedule = cron.Every(job.Interval)
+	default:
+		return fmt.Errorf("job %s has neither a schedule nor an interval", job.Name)
+	}
+
+	s.jobs = append(s.jobs, sj)
+	return nil
+}
+
+// Start launches every registered job. Interval jobs run once immediately and
+// then on their interval, cron jobs at their scheduled times; a run never
+// overlaps the previous run of the same job.
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go func(job scheduledJob) {
+			defer s.wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+	log.Printf("Scheduler started with %d jobs", len(s.jobs))
+}
+
+// Wait blocks until every job has stopped after the context was cancelled
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, job scheduledJob) {
+	if job.ScheduleFunc != nil {
+		s.dynamicLoop(ctx, job)
+		return
+	}
+	if job.Schedule == "" {
+		s.run(ctx, job)
+	}
+
+	for {
+		timer := time.NewTimer(time.Until(job.schedule.Next(time.Now())))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.run(ctx, job)
+		}
+	}
+}
+
+// dynamicLoop runs a job at the times of its current schedule, looking for a
+// new one every scheduleRecheck
+func (s *Scheduler) dynamicLoop(ctx context.Context, job scheduledJob) {
+	var (
+		spec     string
+		schedule cron.Schedule
+		next     time.Time
+	)
+	for {
+		if current := job.ScheduleFunc(); current != spec {
+			spec, schedule, next = current, nil, time.Time{}
+			if spec != "" {
+				parsed, err := cron.ParseStandard(spec)
+				if err != nil {
+					log.Printf("Job %s paused, invalid schedule %q: %v", job.Name, spec, err)
+				} else {
+					schedule, next = parsed, parsed.Next(time.Now())
+					log.Printf("Job %s scheduled with %q", job.Name, spec)
+				}
+			} else {
+				log.Printf("Job %s paused", job.Name)
+			}
+		}
+
+		wait := scheduleRecheck
+		if schedule != nil && time.Until(next) < wait {
+			wait = time.Until(next)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if schedule != nil && !time.Now().Before(next) {
+			s.run(ctx, job)
+			next = schedule.Next(time.Now())
+		}
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, job scheduledJob) {
+	if err := job.Run(ctx); err != nil && ctx.Err() == nil {
+		log.Printf("Job %s failed: %v", job.Name, err)
+	}
+}
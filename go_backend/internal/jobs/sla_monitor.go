@@ -0,0 +1,53 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/internal/services"
+)
+
+// SLAMonitor records bins that were not emptied within their collection SLA
+type SLAMonitor struct {
+	slaRepo             *repository.SLARepository
+	notificationService *services.NotificationService
+}
+
+// NewSLAMonitor creates a new SLAMonitor
+func NewSLAMonitor(slaRepo *repository.SLARepository, notificationService *services.NotificationService) *SLAMonitor {
+	return &SLAMonitor{
+		slaRepo:             slaRepo,
+		notificationService: notificationService,
+	}
+}
+
+// Run resolves the breaches of bins that have since been emptied, then records
+// the new breaches and raises one alert per breach
+func (m *SLAMonitor) Run(ctx context.Context) error {
+	now := time.Now()
+	resolved, err := m.slaRepo.ResolveBreaches(ctx, now)
+	if err != nil {
+		return fmt.Errorf("failed to resolve SLA breaches: %w", err)
+	}
+	if resolved > 0 {
+		log.Printf("Resolved %d SLA breaches", resolved)
+	}
+
+	breaches, err := m.slaRepo.RecordBreaches(ctx, now)
+	if err != nil {
+		return fmt.Errorf("failed to record SLA breaches: %w", err)
+	}
+
+	for i := range breaches {
+		log.Printf("Bin %s breached its %dh collection SLA, due %s",
+			breaches[i].DeviceID, breaches[i].MaxHours, breaches[i].DueAt.Format(time.RFC3339))
+		if err := m.notificationService.NotifySLABreach(ctx, &breaches[i]); err != nil {
+			log.Printf("Failed to send SLA breach alert for bin %s: %v", breaches[i].DeviceID, err)
+		}
+	}
+
+	return nil
+}
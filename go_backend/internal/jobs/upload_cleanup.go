@@ -0,0 +1,22 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/smartwaste/backend/internal/services"
+)
+
+// UploadCleaner deletes uploads that nothing referred to in time
+type UploadCleaner struct {
+	uploadService *services.UploadService
+}
+
+// NewUploadCleaner creates a new UploadCleaner
+func NewUploadCleaner(uploadService *services.UploadService) *UploadCleaner {
+	return &UploadCleaner{uploadService: uploadService}
+}
+
+// Run deletes the orphaned uploads and their objects
+func (u *UploadCleaner) Run(ctx context.Context) error {
+	return u.uploadService.PurgeOrphaned(ctx)
+}
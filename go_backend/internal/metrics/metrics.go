@@ -0,0 +1,124 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// namespace prefixes every metric exported by the backend
+const namespace = "smartwaste"
+
+// Results of processing an MQTT bin status message
+const (
+	MQTTResultProcessed = "processed"
+	MQTTResultDuplicate = "duplicate"
+	MQTTResultInvalid   = "invalid"
+	MQTTResultFailed    = "failed"
+)
+
+var (
+	// HTTPRequests counts handled requests by route template and status code
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests handled, by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPRequestDuration observes request latency by route template
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency, by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// MQTTMessages counts bin status messages by outcome
+	MQTTMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "mqtt",
+		Name:      "messages_processed_total",
+		Help:      "MQTT bin status messages, by result (processed, duplicate, invalid, failed).",
+	}, []string{"result"})
+
+	// SensorPayloads counts bin status payloads by schema version and outcome
+	SensorPayloads = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "mqtt",
+		Name:      "payloads_total",
+		Help:      "Bin status payloads, by schema_version (1, 2, unsupported) and result (processed, duplicate, invalid, failed).",
+	}, []string{"schema_version", "result"})
+
+	// MQTTIngestionLag observes the delay between a sensor reading and its processing
+	MQTTIngestionLag = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "mqtt",
+		Name:      "ingestion_lag_seconds",
+		Help:      "Time from the sensor timestamp of a reading until it was processed.",
+		Buckets:   []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 300, 900},
+	})
+
+	// MQTTLastMessage records when a bin status message was last processed
+	MQTTLastMessage = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "mqtt",
+		Name:      "last_message_timestamp_seconds",
+		Help:      "Unix time the last bin status message was processed.",
+	})
+
+	// MQTTFillLevelFlushes observes the bins written by each flush of the
+	// buffered fill levels
+	MQTTFillLevelFlushes = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "mqtt",
+		Name:      "fill_level_flush_size",
+		Help:      "Bins whose fill level was written by a flush of the buffered updates.",
+		Buckets:   []float64{1, 5, 10, 50, 100, 250, 500, 1000, 2500},
+	})
+
+	// MQTTFillLevelFlushErrors counts flushes of buffered fill levels that failed
+	MQTTFillLevelFlushErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "mqtt",
+		Name:      "fill_level_flush_errors_total",
+		Help:      "Flushes of buffered fill levels that failed and were retried.",
+	})
+
+	// GoogleDirections counts the Directions lookups by result
+	GoogleDirections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "google",
+		Name:      "directions_total",
+		Help:      "Google Maps Directions lookups, by result (cached, requested, quota_exceeded).",
+	}, []string{"result"})
+
+	// NATSMessages counts shipment events received by subject
+	NATSMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "nats",
+		Name:      "messages_received_total",
+		Help:      "NATS messages received, by subject.",
+	}, []string{"subject"})
+)
+
+// RegisterDBStats exports the connection pool statistics of db
+func RegisterDBStats(db *sql.DB) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, namespace))
+}
+
+// RegisterWebSocketConnections exports the number of live dashboard WebSocket
+// connections as reported by count
+func RegisterWebSocketConnections(count func() int) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "connections",
+		Help:      "Open dashboard WebSocket connections.",
+	}, func() float64 {
+		return float64(count())
+	})
+}
@@ -0,0 +1,142 @@
+package models
+
+import "time"
+
+// AnalyticsGroupBy is the width of the time buckets of an analytics series
+type AnalyticsGroupBy string
+
+const (
+	GroupByDay   AnalyticsGroupBy = "day"
+	GroupByWeek  AnalyticsGroupBy = "week"
+	GroupByMonth AnalyticsGroupBy = "month"
+)
+
+// IsValid checks if the grouping is supported
+func (g AnalyticsGroupBy) IsValid() bool {
+	switch g {
+	case GroupByDay, GroupByWeek, GroupByMonth:
+		return true
+	}
+	return false
+}
+
+// MinWidth returns the shortest duration a bucket of this grouping can span
+func (g AnalyticsGroupBy) MinWidth() time.Duration {
+	switch g {
+	case GroupByWeek:
+		return 7 * 24 * time.Hour
+	case GroupByMonth:
+		return 28 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// AnalyticsRange selects the period [From, To) of an analytics series and its
+// bucket width; buckets start at UTC day, ISO week or month boundaries
+type AnalyticsRange struct {
+	From    time.Time        `json:"from"`
+	To      time.Time        `json:"to"`
+	GroupBy AnalyticsGroupBy `json:"group_by,omitempty"`
+}
+
+// CollectionSeriesPoint aggregates the collections started in one bucket
+type CollectionSeriesPoint struct {
+	Bucket        time.Time `db:"bucket" json:"bucket"`
+	Collections   int       `db:"collections" json:"collections"`
+	Completed     int       `db:"completed" json:"completed"`
+	Cancelled     int       `db:"cancelled" json:"cancelled"`
+	WeightKg      float64   `db:"weight_kg" json:"weight_kg"`           // Of completed collections
+	ActiveDrivers int       `db:"active_drivers" json:"active_drivers"` // Most drivers collecting on one day of the bucket
+}
+
+// BinSeriesPoint aggregates the fill-level readings recorded in one bucket;
+// the levels are null for buckets without readings
+type BinSeriesPoint struct {
+	Bucket           time.Time `db:"bucket" json:"bucket"`
+	Readings         int       `db:"readings" json:"readings"`
+	BinsReporting    int       `db:"bins_reporting" json:"bins_reporting"` // Most bins reporting on one day of the bucket
+	AverageFillLevel *float64  `db:"average_fill_level" json:"average_fill_level"`
+	MaxFillLevel     *int      `db:"max_fill_level" json:"max_fill_level"`
+}
+
+// WasteTypeWeight is the weight recovered from bins of one waste type
+type WasteTypeWeight struct {
+	WasteType   string  `db:"waste_type" json:"waste_type"`
+	Collections int     `db:"collections" json:"collections"`
+	WeightKg    float64 `db:"weight_kg" json:"weight_kg"`
+}
+
+// ValuationTotal is the value of the detected waste of one type in one currency
+type ValuationTotal struct {
+	WasteType  string  `db:"waste_type" json:"waste_type"`
+	Currency   string  `db:"currency" json:"currency"`
+	Valuations int     `db:"valuations" json:"valuations"`
+	TotalValue float64 `db:"total_value" json:"total_value"`
+}
+
+// HeatmapMetric is the value aggregated per heatmap cell
+type HeatmapMetric string
+
+const (
+	HeatmapFillLevel   HeatmapMetric = "fill_level"
+	HeatmapCollections HeatmapMetric = "collections"
+)
+
+// IsValid checks if the heatmap metric is supported
+func (m HeatmapMetric) IsValid() bool {
+	return m == HeatmapFillLevel || m == HeatmapCollections
+}
+
+// BoundingBox is a map area in degrees, in GeoJSON order
+type BoundingBox struct {
+	MinLongitude float64 `db:"min_longitude" json:"min_longitude"`
+	MinLatitude  float64 `db:"min_latitude" json:"min_latitude"`
+	MaxLongitude float64 `db:"max_longitude" json:"max_longitude"`
+	MaxLatitude  float64 `db:"max_latitude" json:"max_latitude"`
+}
+
+// Contains returns true if the point lies inside the box, edges included
+func (b BoundingBox) Contains(latitude, longitude float64) bool {
+	return latitude >= b.MinLatitude && latitude <= b.MaxLatitude &&
+		longitude >= b.MinLongitude && longitude <= b.MaxLongitude
+}
+
+// HeatmapQuery selects the area, grid and metric of a heatmap; Period only
+// applies to the collections metric
+type HeatmapQuery struct {
+	Metric HeatmapMetric
+	BBox   BoundingBox
+	Grid   int // Cells along each side of the bounding box
+	Period AnalyticsRange
+}
+
+// CellSize returns the height and width of a grid cell in degrees
+func (q HeatmapQuery) CellSize() (lat, lng float64) {
+	return (q.BBox.MaxLatitude - q.BBox.MinLatitude) / float64(q.Grid),
+		(q.BBox.MaxLongitude - q.BBox.MinLongitude) / float64(q.Grid)
+}
+
+// HeatmapCell aggregates the bins of one grid cell, positioned at their mean
+// location. Value is the average fill level or the number of completed
+// collections; Intensity scales it to 0-1 for a heat layer.
+type HeatmapCell struct {
+	Row       int     `db:"cell_row" json:"row"`
+	Column    int     `db:"cell_column" json:"column"`
+	Latitude  float64 `db:"latitude" json:"latitude"`
+	Longitude float64 `db:"longitude" json:"longitude"`
+	Bins      int     `db:"bins" json:"bins"`
+	Value     float64 `db:"value" json:"value"`
+	Intensity float64 `db:"-" json:"intensity"`
+}
+
+// Heatmap is the grid of a heatmap metric over a bounding box
+type Heatmap struct {
+	Metric      HeatmapMetric   `json:"metric"`
+	BBox        BoundingBox     `json:"bbox"`
+	Grid        int             `json:"grid"`
+	CellSizeLat float64         `json:"cell_size_lat"`
+	CellSizeLng float64         `json:"cell_size_lng"`
+	Period      *AnalyticsRange `json:"period,omitempty"`
+	MaxValue    float64         `json:"max_value"`
+	Cells       []HeatmapCell   `json:"cells"`
+}
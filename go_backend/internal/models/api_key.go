@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultAPIKeyRateLimit is the requests per minute allowed to a key issued without a limit
+const DefaultAPIKeyRateLimit = 60
+
+// APIKey is a credential for services and integrations calling the API
+// without a user login. The key acts with the permissions of its role.
+type APIKey struct {
+	ID                 uuid.UUID  `db:"id" json:"id"`
+	OrganizationID     uuid.UUID  `db:"organization_id" json:"organization_id"`
+	Name               string     `db:"name" json:"name"`
+	KeyPrefix          string     `db:"key_prefix" json:"key_prefix"`
+	KeyHash            string     `db:"key_hash" json:"-"`
+	Role               Role       `db:"role" json:"role"`
+	CompanyID          *uuid.UUID `db:"company_id" json:"company_id,omitempty"`
+	RateLimitPerMinute int        `db:"rate_limit_per_minute" json:"rate_limit_per_minute"`
+	CreatedBy          *uuid.UUID `db:"created_by" json:"created_by,omitempty"`
+	ExpiresAt          *time.Time `db:"expires_at" json:"expires_at,omitempty"`
+	LastUsedAt         *time.Time `db:"last_used_at" json:"last_used_at,omitempty"`
+	RevokedAt          *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt          time.Time  `db:"created_at" json:"created_at"`
+}
+
+// IsUsable returns true if the key is neither revoked nor expired at now
+func (k *APIKey) IsUsable(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// IsValidAPIKeyRole returns true if keys may be issued with the role. Admin
+// and driver access always requires a user login.
+func IsValidAPIKeyRole(r Role) bool {
+	switch r {
+	case RoleDispatcher, RoleCompany, RoleCitizen, RoleDevice:
+		return true
+	}
+	return false
+}
+
+// CreateAPIKeyRequest represents the request to issue an API key
+type CreateAPIKeyRequest struct {
+	Name               string     `json:"name" binding:"required,max=100"`
+	Role               Role       `json:"role" binding:"required"`
+	CompanyID          *uuid.UUID `json:"company_id"`
+	RateLimitPerMinute *int       `json:"rate_limit_per_minute" binding:"omitempty,gt=0"`
+	ExpiresAt          *time.Time `json:"expires_at"`
+}
+
+// CreateAPIKeyResponse carries a newly issued key; the plaintext key is only returned once
+type CreateAPIKeyResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
@@ -1,93 +1,392 @@
 package models
 
 import (
+	"math"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const (
+	// DefaultCollectionThreshold is the fill level (%) at which new bins are routed and dispatched
+	DefaultCollectionThreshold = 80
+	// DefaultAlertThreshold is the fill level (%) at which new bins notify the nearest driver
+	DefaultAlertThreshold = 90
+)
+
+// BinStatus is where a bin is in its lifecycle
+type BinStatus string
+
+const (
+	BinStatusActive BinStatus = "active"
+	// BinStatusMaintenance is active but left out of dispatch and collection planning
+	BinStatusMaintenance BinStatus = "maintenance"
+	// BinStatusRetired is deactivated
+	BinStatusRetired BinStatus = "retired"
+)
+
 // Bin represents a smart waste bin with IoT sensors
 type Bin struct {
-	ID               uuid.UUID  `db:"id" json:"id"`
-	DeviceID         string     `db:"device_id" json:"device_id"`
-	LocationName     *string    `db:"location_name" json:"location_name,omitempty"`
-	Latitude         float64    `db:"latitude" json:"latitude"`
-	Longitude        float64    `db:"longitude" json:"longitude"`
-	FillLevel        int        `db:"fill_level" json:"fill_level"`
-	WasteType        string     `db:"waste_type" json:"waste_type"`
-	CapacityLiters   int        `db:"capacity_liters" json:"capacity_liters"`
-	LastCollectionAt *time.Time `db:"last_collection_at" json:"last_collection_at,omitempty"`
-	LastUpdatedAt    time.Time  `db:"last_updated_at" json:"last_updated_at"`
-	IsActive         bool       `db:"is_active" json:"is_active"`
-	CompanyID        *uuid.UUID `db:"company_id" json:"company_id,omitempty"`
-	CreatedAt        time.Time  `db:"created_at" json:"created_at"`
+	ID                  uuid.UUID  `db:"id" json:"id"`
+	OrganizationID      uuid.UUID  `db:"organization_id" json:"organization_id"`
+	DeviceID            string     `db:"device_id" json:"device_id"`
+	LocationName        *string    `db:"location_name" json:"location_name,omitempty"`
+	Latitude            float64    `db:"latitude" json:"latitude"`
+	Longitude           float64    `db:"longitude" json:"longitude"`
+	FillLevel           int        `db:"fill_level" json:"fill_level"`
+	WasteType           string     `db:"waste_type" json:"waste_type"`
+	CapacityLiters      int        `db:"capacity_liters" json:"capacity_liters"`
+	LastCollectionAt    *time.Time `db:"last_collection_at" json:"last_collection_at,omitempty"`
+	LastUpdatedAt       time.Time  `db:"last_updated_at" json:"last_updated_at"`
+	IsActive            bool       `db:"is_active" json:"is_active"`
+	Status              BinStatus  `db:"status" json:"status"`
+	CompanyID           *uuid.UUID `db:"company_id" json:"company_id,omitempty"`
+	ZoneID              *uuid.UUID `db:"zone_id" json:"zone_id,omitempty"`
+	CreatedAt           time.Time  `db:"created_at" json:"created_at"`
+	PredictedFullAt     *time.Time `db:"predicted_full_at" json:"predicted_full_at,omitempty"`
+	BatteryLevel        *int       `db:"battery_level" json:"battery_level,omitempty"`
+	RSSI                *int       `db:"rssi" json:"rssi,omitempty"`
+	TemperatureC        *float64   `db:"temperature_c" json:"temperature_c,omitempty"`
+	FirmwareVersion     *string    `db:"firmware_version" json:"firmware_version,omitempty"`
+	DeviceGroup         *string    `db:"device_group" json:"device_group,omitempty"`
+	HardwareSerial      *string    `db:"hardware_serial" json:"hardware_serial,omitempty"`
+	ProvisionedAt       *time.Time `db:"provisioned_at" json:"provisioned_at,omitempty"`
+	CertificateSerial   *string    `db:"certificate_serial" json:"certificate_serial,omitempty"`
+	CertExpiresAt       *time.Time `db:"certificate_expires_at" json:"certificate_expires_at,omitempty"`
+	WeightKg            *float64   `db:"weight_kg" json:"weight_kg,omitempty"`
+	MaxWeightKg         *float64   `db:"max_weight_kg" json:"max_weight_kg,omitempty"`
+	LidOpen             *bool      `db:"lid_open" json:"lid_open,omitempty"`
+	ConfigVersion       *int64     `db:"config_version" json:"config_version,omitempty"`
+	ConfigStatus        *string    `db:"config_status" json:"config_status,omitempty"`
+	ConfigAckedAt       *time.Time `db:"config_acked_at" json:"config_acked_at,omitempty"`
+	IsOffline           bool       `db:"is_offline" json:"is_offline"`
+	OfflineSince        *time.Time `db:"offline_since" json:"offline_since,omitempty"`
+	CollectionThreshold int        `db:"collection_threshold" json:"collection_threshold"`
+	AlertThreshold      int        `db:"alert_threshold" json:"alert_threshold"`
+	ThresholdCrossedAt  *time.Time `db:"threshold_crossed_at" json:"threshold_crossed_at,omitempty"`
+	IsFlagged           bool       `db:"is_flagged" json:"is_flagged"`
+	FlaggedAt           *time.Time `db:"flagged_at" json:"flagged_at,omitempty"`
 }
 
 // CreateBinRequest represents the request to register a new bin
 type CreateBinRequest struct {
 	DeviceID       string     `json:"device_id" binding:"required"`
 	LocationName   *string    `json:"location_name"`
-	Latitude       float64    `json:"latitude" binding:"required"`
-	Longitude      float64    `json:"longitude" binding:"required"`
-	WasteType      string     `json:"waste_type" binding:"required"`
+	Latitude       float64    `json:"latitude" binding:"required,latitude"`
+	Longitude      float64    `json:"longitude" binding:"required,longitude"`
+	WasteType      string     `json:"waste_type" binding:"required,waste_type"`
 	CapacityLiters int        `json:"capacity_liters" binding:"required,gt=0"`
 	CompanyID      *uuid.UUID `json:"company_id"`
+	// MaxWeightKg is the weight of a full bin, for bins with a load cell
+	MaxWeightKg *float64 `json:"max_weight_kg" binding:"omitempty,gt=0"`
+	// DeviceGroup groups sensors for firmware rollouts
+	DeviceGroup *string `json:"device_group" binding:"omitempty,max=100"`
+	// HardwareSerial is the serial the sensor provisions itself with
+	HardwareSerial *string `json:"hardware_serial" binding:"omitempty,max=100"`
+
+	CollectionThreshold *int `json:"collection_threshold" binding:"omitempty,min=1,max=100"`
+	AlertThreshold      *int `json:"alert_threshold" binding:"omitempty,min=1,max=100"`
+}
+
+// FillLevelUpdate is a fill level reported by the sensor of a bin, written in
+// a batch with those of other bins
+type FillLevelUpdate struct {
+	DeviceID   string
+	FillLevel  int
+	ReceivedAt time.Time
 }
 
 // UpdateBinRequest represents the request to update a bin
 type UpdateBinRequest struct {
 	LocationName   *string    `json:"location_name"`
-	Latitude       *float64   `json:"latitude"`
-	Longitude      *float64   `json:"longitude"`
-	WasteType      *string    `json:"waste_type"`
+	Latitude       *float64   `json:"latitude" binding:"omitempty,latitude"`
+	Longitude      *float64   `json:"longitude" binding:"omitempty,longitude"`
+	WasteType      *string    `json:"waste_type" binding:"omitempty,waste_type"`
 	CapacityLiters *int       `json:"capacity_liters"`
 	IsActive       *bool      `json:"is_active"`
 	CompanyID      *uuid.UUID `json:"company_id"`
+	MaxWeightKg    *float64   `json:"max_weight_kg" binding:"omitempty,gt=0"`
+	DeviceGroup    *string    `json:"device_group" binding:"omitempty,max=100"`
+	HardwareSerial *string    `json:"hardware_serial" binding:"omitempty,max=100"`
+
+	CollectionThreshold *int `json:"collection_threshold" binding:"omitempty,min=1,max=100"`
+	AlertThreshold      *int `json:"alert_threshold" binding:"omitempty,min=1,max=100"`
+}
+
+// UpdateBinThresholdsRequest sets the fill-level thresholds of a bin or of all
+// bins of a company; omitted thresholds are left unchanged
+type UpdateBinThresholdsRequest struct {
+	CollectionThreshold *int `json:"collection_threshold" binding:"omitempty,min=1,max=100"`
+	AlertThreshold      *int `json:"alert_threshold" binding:"omitempty,min=1,max=100"`
+}
+
+// NearbyBinQuery holds the parameters of a nearby bin search
+type NearbyBinQuery struct {
+	Latitude  float64
+	Longitude float64
+	RadiusM   float64
+	WasteType string
+	Limit     int
+}
+
+// NearbyBin is a bin found by a geospatial search with its distance from the query point
+type NearbyBin struct {
+	Bin
+	DistanceM float64 `db:"distance_m"`
+}
+
+// NearbyBinResponse is the public view of a nearby bin for citizen apps
+type NearbyBinResponse struct {
+	ID           uuid.UUID `json:"id"`
+	LocationName *string   `json:"location_name,omitempty"`
+	Latitude     float64   `json:"latitude"`
+	Longitude    float64   `json:"longitude"`
+	WasteType    string    `json:"waste_type"`
+	FillLevel    int       `json:"fill_level"`
+	DistanceM    float64   `json:"distance_m"`
+}
+
+// ToResponse converts NearbyBin to NearbyBinResponse
+func (b *NearbyBin) ToResponse() *NearbyBinResponse {
+	return &NearbyBinResponse{
+		ID:           b.ID,
+		LocationName: b.LocationName,
+		Latitude:     b.Latitude,
+		Longitude:    b.Longitude,
+		WasteType:    b.WasteType,
+		FillLevel:    b.FillLevel,
+		DistanceM:    b.DistanceM,
+	}
 }
 
 // BinStatusUpdate represents IoT payload from ESP32
 type BinStatusUpdate struct {
-	BinID     string `json:"bin_id"`
-	FillLevel int    `json:"fill_level"`
+	// SchemaVersion is the version of the payload the update was decoded
+	// from; payloads without one are version 1
+	SchemaVersion int    `json:"schema_version,omitempty"`
+	BinID         string `json:"bin_id"`
+	FillLevel     int    `json:"fill_level"`
+
+	// MessageID identifies a publish so retransmissions can be dropped;
+	// sensors send "<boot id>-<sequence number>"
+	MessageID string `json:"message_id,omitempty"`
+
+	// Timestamp is the Unix time the sensor took the reading
+	Timestamp int64 `json:"timestamp,omitempty"`
+
+	// Optional sensor health telemetry
+	BatteryLevel    *int     `json:"battery_level,omitempty"`
+	RSSI            *int     `json:"rssi,omitempty"`
+	Temperature     *float64 `json:"temperature,omitempty"`
+	FirmwareVersion *string  `json:"firmware_version,omitempty"`
+
+	// Optional load cell and lid switch readings
+	WeightKg *float64 `json:"weight_kg,omitempty"`
+	LidOpen  *bool    `json:"lid_open,omitempty"`
+}
+
+// BinStatusUpdateV2 is the schema version 2 bin status payload. It groups
+// the readings of the optional sensors apart from the health of the device,
+// and requires the fill level, message ID and timestamp.
+type BinStatusUpdateV2 struct {
+	SchemaVersion int              `json:"schema_version"`
+	BinID         string           `json:"bin_id"`
+	MessageID     string           `json:"message_id"`
+	Timestamp     int64            `json:"timestamp"`
+	FillLevel     *int             `json:"fill_level"`
+	Sensors       BinSensorReading `json:"sensors"`
+	Health        DeviceHealth     `json:"health"`
+}
+
+// BinSensorReading holds the readings of the optional sensors of a bin
+type BinSensorReading struct {
+	WeightKg    *float64 `json:"weight_kg,omitempty"`
+	LidOpen     *bool    `json:"lid_open,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+}
+
+// DeviceHealth holds the health telemetry of a bin sensor
+type DeviceHealth struct {
+	BatteryLevel    *int    `json:"battery_level,omitempty"`
+	RSSI            *int    `json:"rssi,omitempty"`
+	FirmwareVersion *string `json:"firmware_version,omitempty"`
+}
+
+// Update returns the payload as the update it describes
+func (p *BinStatusUpdateV2) Update() BinStatusUpdate {
+	update := BinStatusUpdate{
+		SchemaVersion:   p.SchemaVersion,
+		BinID:           p.BinID,
+		MessageID:       p.MessageID,
+		Timestamp:       p.Timestamp,
+		BatteryLevel:    p.Health.BatteryLevel,
+		RSSI:            p.Health.RSSI,
+		Temperature:     p.Sensors.Temperature,
+		FirmwareVersion: p.Health.FirmwareVersion,
+		WeightKg:        p.Sensors.WeightKg,
+		LidOpen:         p.Sensors.LidOpen,
+	}
+	if p.FillLevel != nil {
+		update.FillLevel = *p.FillLevel
+	}
+	return update
+}
+
+// HasTelemetry returns true if the update carries any health telemetry
+func (u *BinStatusUpdate) HasTelemetry() bool {
+	return u.BatteryLevel != nil || u.RSSI != nil || u.Temperature != nil || u.FirmwareVersion != nil ||
+		u.WeightKg != nil || u.LidOpen != nil
+}
+
+// BinReading is a single fill-level sample reported by a bin
+type BinReading struct {
+	ID         int64     `db:"id" json:"id"`
+	BinID      uuid.UUID `db:"bin_id" json:"bin_id"`
+	FillLevel  int       `db:"fill_level" json:"fill_level"`
+	RecordedAt time.Time `db:"recorded_at" json:"recorded_at"`
+}
+
+// BinPrediction is the estimated fill trajectory of a bin
+type BinPrediction struct {
+	BinID            uuid.UUID  `json:"bin_id"`
+	CurrentFillLevel int        `json:"current_fill_level"`
+	Threshold        int        `json:"threshold"`
+	FillRatePerHour  *float64   `json:"fill_rate_per_hour,omitempty"`
+	PredictedFullAt  *time.Time `json:"predicted_full_at,omitempty"`
+	HoursRemaining   *float64   `json:"hours_remaining,omitempty"`
+	SampleSize       int        `json:"sample_size"`
+	Confidence       *float64   `json:"confidence,omitempty"` // R² of the fit
 }
 
 // BinResponse represents the API response for a bin
 type BinResponse struct {
-	ID               uuid.UUID  `json:"id"`
-	DeviceID         string     `json:"device_id"`
-	LocationName     *string    `json:"location_name,omitempty"`
-	Latitude         float64    `json:"latitude"`
-	Longitude        float64    `json:"longitude"`
-	FillLevel        int        `json:"fill_level"`
-	WasteType        string     `json:"waste_type"`
-	CapacityLiters   int        `json:"capacity_liters"`
-	LastCollectionAt *time.Time `json:"last_collection_at,omitempty"`
-	LastUpdatedAt    time.Time  `json:"last_updated_at"`
-	IsActive         bool       `json:"is_active"`
-	CompanyID        *uuid.UUID `json:"company_id,omitempty"`
-	CreatedAt        time.Time  `json:"created_at"`
+	ID                  uuid.UUID  `json:"id"`
+	DeviceID            string     `json:"device_id"`
+	LocationName        *string    `json:"location_name,omitempty"`
+	Latitude            float64    `json:"latitude"`
+	Longitude           float64    `json:"longitude"`
+	FillLevel           int        `json:"fill_level"`
+	WasteType           string     `json:"waste_type"`
+	CapacityLiters      int        `json:"capacity_liters"`
+	LastCollectionAt    *time.Time `json:"last_collection_at,omitempty"`
+	LastUpdatedAt       time.Time  `json:"last_updated_at"`
+	IsActive            bool       `json:"is_active"`
+	Status              BinStatus  `json:"status"`
+	CompanyID           *uuid.UUID `json:"company_id,omitempty"`
+	ZoneID              *uuid.UUID `json:"zone_id,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	PredictedFullAt     *time.Time `json:"predicted_full_at,omitempty"`
+	BatteryLevel        *int       `json:"battery_level,omitempty"`
+	RSSI                *int       `json:"rssi,omitempty"`
+	TemperatureC        *float64   `json:"temperature_c,omitempty"`
+	FirmwareVersion     *string    `json:"firmware_version,omitempty"`
+	DeviceGroup         *string    `json:"device_group,omitempty"`
+	HardwareSerial      *string    `json:"hardware_serial,omitempty"`
+	ProvisionedAt       *time.Time `json:"provisioned_at,omitempty"`
+	CertificateSerial   *string    `json:"certificate_serial,omitempty"`
+	CertExpiresAt       *time.Time `json:"certificate_expires_at,omitempty"`
+	WeightKg            *float64   `json:"weight_kg,omitempty"`
+	MaxWeightKg         *float64   `json:"max_weight_kg,omitempty"`
+	LidOpen             *bool      `json:"lid_open,omitempty"`
+	ConfigVersion       *int64     `json:"config_version,omitempty"`
+	ConfigStatus        *string    `json:"config_status,omitempty"`
+	ConfigAckedAt       *time.Time `json:"config_acked_at,omitempty"`
+	IsOffline           bool       `json:"is_offline"`
+	OfflineSince        *time.Time `json:"offline_since,omitempty"`
+	CollectionThreshold int        `json:"collection_threshold"`
+	AlertThreshold      int        `json:"alert_threshold"`
+	ThresholdCrossedAt  *time.Time `json:"threshold_crossed_at,omitempty"`
+	IsFlagged           bool       `json:"is_flagged"`
+	FlaggedAt           *time.Time `json:"flagged_at,omitempty"`
 }
 
 // ToResponse converts Bin to BinResponse
 func (b *Bin) ToResponse() *BinResponse {
 	return &BinResponse{
-		ID:               b.ID,
-		DeviceID:         b.DeviceID,
-		LocationName:     b.LocationName,
-		Latitude:         b.Latitude,
-		Longitude:        b.Longitude,
-		FillLevel:        b.FillLevel,
-		WasteType:        b.WasteType,
-		CapacityLiters:   b.CapacityLiters,
-		LastCollectionAt: b.LastCollectionAt,
-		LastUpdatedAt:    b.LastUpdatedAt,
-		IsActive:         b.IsActive,
-		CompanyID:        b.CompanyID,
-		CreatedAt:        b.CreatedAt,
+		ID:                  b.ID,
+		DeviceID:            b.DeviceID,
+		LocationName:        b.LocationName,
+		Latitude:            b.Latitude,
+		Longitude:           b.Longitude,
+		FillLevel:           b.FillLevel,
+		WasteType:           b.WasteType,
+		CapacityLiters:      b.CapacityLiters,
+		LastCollectionAt:    b.LastCollectionAt,
+		LastUpdatedAt:       b.LastUpdatedAt,
+		IsActive:            b.IsActive,
+		Status:              b.Status,
+		CompanyID:           b.CompanyID,
+		ZoneID:              b.ZoneID,
+		CreatedAt:           b.CreatedAt,
+		PredictedFullAt:     b.PredictedFullAt,
+		BatteryLevel:        b.BatteryLevel,
+		RSSI:                b.RSSI,
+		TemperatureC:        b.TemperatureC,
+		FirmwareVersion:     b.FirmwareVersion,
+		DeviceGroup:         b.DeviceGroup,
+		HardwareSerial:      b.HardwareSerial,
+		ProvisionedAt:       b.ProvisionedAt,
+		CertificateSerial:   b.CertificateSerial,
+		CertExpiresAt:       b.CertExpiresAt,
+		WeightKg:            b.WeightKg,
+		MaxWeightKg:         b.MaxWeightKg,
+		LidOpen:             b.LidOpen,
+		ConfigVersion:       b.ConfigVersion,
+		ConfigStatus:        b.ConfigStatus,
+		ConfigAckedAt:       b.ConfigAckedAt,
+		IsOffline:           b.IsOffline,
+		OfflineSince:        b.OfflineSince,
+		CollectionThreshold: b.CollectionThreshold,
+		AlertThreshold:      b.AlertThreshold,
+		ThresholdCrossedAt:  b.ThresholdCrossedAt,
+		IsFlagged:           b.IsFlagged,
+		FlaggedAt:           b.FlaggedAt,
+	}
+}
+
+// NeedsCollection returns true if the bin fill level reached its collection threshold
+func (b *Bin) NeedsCollection() bool {
+	return b.FillLevel >= b.CollectionThreshold
+}
+
+// WeightFillLevel estimates the fill level (%) from weightKg against the
+// weight of the full bin. ok is false when the bin has no MaxWeightKg.
+func (b *Bin) WeightFillLevel(weightKg float64) (level int, ok bool) {
+	if b.MaxWeightKg == nil || *b.MaxWeightKg <= 0 {
+		return 0, false
+	}
+	level = int(math.Round(weightKg / *b.MaxWeightKg * 100))
+	if level > 100 {
+		level = 100
+	} else if level < 0 {
+		level = 0
 	}
+	return level, true
+}
+
+// NeedsAlert returns true if the bin fill level reached its alert threshold
+func (b *Bin) NeedsAlert() bool {
+	return b.FillLevel >= b.AlertThreshold
+}
+
+// Bin status ingestion outcomes reported per update
+const (
+	IngestStatusAccepted = "accepted"
+	IngestStatusInvalid  = "invalid"
+	IngestStatusFailed   = "failed"
+)
+
+// BinStatusIngestResult is the outcome of one update posted to the HTTP ingestion endpoint
+type BinStatusIngestResult struct {
+	Index  int    `json:"index"`
+	BinID  string `json:"bin_id,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
 }
 
-// NeedsCollection returns true if the bin fill level exceeds the threshold
-func (b *Bin) NeedsCollection(threshold int) bool {
-	return b.FillLevel >= threshold
+// BinStatusIngestResponse summarizes a posted batch of bin status updates
+type BinStatusIngestResponse struct {
+	Accepted int                     `json:"accepted"`
+	Rejected int                     `json:"rejected"`
+	Results  []BinStatusIngestResult `json:"results"`
 }
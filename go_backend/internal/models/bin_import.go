@@ -0,0 +1,17 @@
+package models
+
+// BinImportRowError describes why a CSV row could not be imported
+type BinImportRowError struct {
+	Row      int    `json:"row"` // 1-based line number in the file, header included
+	DeviceID string `json:"device_id,omitempty"`
+	Message  string `json:"message"`
+}
+
+// BinImportResult reports the outcome of a bulk bin import
+type BinImportResult struct {
+	DryRun   bool                `json:"dry_run"`
+	Total    int                 `json:"total"`
+	Imported int                 `json:"imported"`
+	Failed   int                 `json:"failed"`
+	Errors   []BinImportRowError `json:"errors"`
+}
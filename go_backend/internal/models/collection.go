@@ -19,13 +19,18 @@ const (
 // Collection represents a waste collection event
 type Collection struct {
 	ID              uuid.UUID        `db:"id" json:"id"`
+	OrganizationID  uuid.UUID        `db:"organization_id" json:"organization_id"`
 	BinID           uuid.UUID        `db:"bin_id" json:"bin_id"`
 	DriverID        uuid.UUID        `db:"driver_id" json:"driver_id"`
+	UserID          *uuid.UUID       `db:"user_id" json:"user_id,omitempty"`
 	FillLevelBefore int              `db:"fill_level_before" json:"fill_level_before"`
 	FillLevelAfter  int              `db:"fill_level_after" json:"fill_level_after"`
 	WeightKg        *float64         `db:"weight_kg" json:"weight_kg,omitempty"`
 	QRCodeVerified  bool             `db:"qr_code_verified" json:"qr_code_verified"`
+	VerifyDistanceM *float64         `db:"verification_distance_m" json:"verification_distance_m,omitempty"` // How far from the bin the QR code was scanned
 	Notes           *string          `db:"notes" json:"notes,omitempty"`
+	BeforePhotoURL  *string          `db:"before_photo_url" json:"before_photo_url,omitempty"`
+	AfterPhotoURL   *string          `db:"after_photo_url" json:"after_photo_url,omitempty"`
 	StartedAt       time.Time        `db:"started_at" json:"started_at"`
 	CompletedAt     *time.Time       `db:"completed_at" json:"completed_at,omitempty"`
 	Status          CollectionStatus `db:"status" json:"status"`
@@ -33,8 +38,9 @@ type Collection struct {
 
 // CreateCollectionRequest represents the request to create a new collection
 type CreateCollectionRequest struct {
-	BinID    uuid.UUID `json:"bin_id" binding:"required"`
-	DriverID uuid.UUID `json:"driver_id" binding:"required"`
+	BinID    uuid.UUID  `json:"bin_id" binding:"required"`
+	DriverID uuid.UUID  `json:"driver_id" binding:"required"`
+	UserID   *uuid.UUID `json:"user_id"` // Citizen credited with reward points on verified completion
 }
 
 // UpdateCollectionRequest represents the request to update a collection
@@ -45,11 +51,50 @@ type UpdateCollectionRequest struct {
 	Status         *string  `json:"status"`
 }
 
-// CompleteCollectionRequest represents the request to complete a collection
+// CollectionFilter narrows collection listings; nil fields are ignored
+type CollectionFilter struct {
+	DriverID *uuid.UUID
+	BinID    *uuid.UUID
+	Status   *CollectionStatus
+	From     *time.Time
+	To       *time.Time
+}
+
+// CompleteCollectionRequest represents the request to complete a collection.
+// The proof-of-collection photos are uploads of the driver of the collection.
 type CompleteCollectionRequest struct {
 	FillLevelAfter int      `json:"fill_level_after" binding:"required,gte=0,lte=100"`
 	WeightKg       *float64 `json:"weight_kg"`
 	Notes          *string  `json:"notes"`
+	BeforePhotoURL *string  `json:"before_photo_url" binding:"omitempty,url"`
+	AfterPhotoURL  *string  `json:"after_photo_url" binding:"omitempty,url"`
+}
+
+// CancelCollectionRequest represents the request to cancel a collection
+type CancelCollectionRequest struct {
+	Reason *string `json:"reason"`
+}
+
+// DispatchResult summarizes an automatic dispatch run
+type DispatchResult struct {
+	Dispatched    int `json:"dispatched"`
+	Unassigned    int `json:"unassigned"`      // Bins and pickups left for the next run because no driver was free
+	OutOfContract int `json:"out_of_contract"` // Company bins no contract in effect covers
+	Pickups       int `json:"pickups"`         // Pickup requests assigned to a driver
+}
+
+// IsValid returns true if the status is a known collection status
+func (s CollectionStatus) IsValid() bool {
+	switch s {
+	case CollectionStatusPending, CollectionStatusInProgress, CollectionStatusCompleted, CollectionStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// IsOpen returns true if the collection can still be completed or cancelled
+func (s CollectionStatus) IsOpen() bool {
+	return s == CollectionStatusPending || s == CollectionStatusInProgress
 }
 
 // CollectionResponse represents the API response for a collection
@@ -57,11 +102,15 @@ type CollectionResponse struct {
 	ID              uuid.UUID        `json:"id"`
 	BinID           uuid.UUID        `json:"bin_id"`
 	DriverID        uuid.UUID        `json:"driver_id"`
+	UserID          *uuid.UUID       `json:"user_id,omitempty"`
 	FillLevelBefore int              `json:"fill_level_before"`
 	FillLevelAfter  int              `json:"fill_level_after"`
 	WeightKg        *float64         `json:"weight_kg,omitempty"`
 	QRCodeVerified  bool             `json:"qr_code_verified"`
+	VerifyDistanceM *float64         `json:"verification_distance_m,omitempty"`
 	Notes           *string          `json:"notes,omitempty"`
+	BeforePhotoURL  *string          `json:"before_photo_url,omitempty"`
+	AfterPhotoURL   *string          `json:"after_photo_url,omitempty"`
 	StartedAt       time.Time        `json:"started_at"`
 	CompletedAt     *time.Time       `json:"completed_at,omitempty"`
 	Status          CollectionStatus `json:"status"`
@@ -73,11 +122,15 @@ func (c *Collection) ToResponse() *CollectionResponse {
 		ID:              c.ID,
 		BinID:           c.BinID,
 		DriverID:        c.DriverID,
+		UserID:          c.UserID,
 		FillLevelBefore: c.FillLevelBefore,
 		FillLevelAfter:  c.FillLevelAfter,
 		WeightKg:        c.WeightKg,
 		QRCodeVerified:  c.QRCodeVerified,
+		VerifyDistanceM: c.VerifyDistanceM,
 		Notes:           c.Notes,
+		BeforePhotoURL:  c.BeforePhotoURL,
+		AfterPhotoURL:   c.AfterPhotoURL,
 		StartedAt:       c.StartedAt,
 		CompletedAt:     c.CompletedAt,
 		Status:          c.Status,
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SagaState represents the step a collection saga is at
+type SagaState string
+
+const (
+	// SagaCreatingShipment is retried until the shipment is created
+	SagaCreatingShipment SagaState = "creating_shipment"
+	// SagaShipmentCreated ends the saga unless the shipment is cancelled
+	SagaShipmentCreated SagaState = "shipment_created"
+	// SagaCompensating is retried until the collection is reopened
+	SagaCompensating SagaState = "compensating"
+	// SagaCompensated ends the saga with the collection reopened
+	SagaCompensated SagaState = "compensated"
+)
+
+// CollectionSaga keeps a completed collection and the shipment handing its
+// waste over to the shipment tracker consistent
+type CollectionSaga struct {
+	ID             uuid.UUID  `db:"id" json:"id"`
+	OrganizationID uuid.UUID  `db:"organization_id" json:"organization_id"`
+	CollectionID   uuid.UUID  `db:"collection_id" json:"collection_id"`
+	UserID         uuid.UUID  `db:"user_id" json:"user_id"`
+	WasteType      string     `db:"waste_type" json:"waste_type"`
+	WeightKg       float64    `db:"weight_kg" json:"weight_kg"`
+	PriceOffered   float64    `db:"price_offered" json:"price_offered"`
+	ShipmentID     *uuid.UUID `db:"shipment_id" json:"shipment_id,omitempty"`
+	State          SagaState  `db:"state" json:"state"`
+	Attempts       int        `db:"attempts" json:"attempts"`
+	NextAttemptAt  time.Time  `db:"next_attempt_at" json:"next_attempt_at"`
+	LastError      *string    `db:"last_error" json:"last_error,omitempty"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time  `db:"updated_at" json:"updated_at"`
+}
@@ -25,7 +25,7 @@ type Company struct {
 type CreateCompanyRequest struct {
 	Name               string  `json:"name" binding:"required"`
 	Email              string  `json:"email" binding:"required,email"`
-	Phone              *string `json:"phone"`
+	Phone              *string `json:"phone" binding:"omitempty,phone"`
 	Address            *string `json:"address"`
 	City               *string `json:"city"`
 	Country            *string `json:"country"`
@@ -36,7 +36,7 @@ type CreateCompanyRequest struct {
 type UpdateCompanyRequest struct {
 	Name               *string `json:"name"`
 	Email              *string `json:"email"`
-	Phone              *string `json:"phone"`
+	Phone              *string `json:"phone" binding:"omitempty,phone"`
 	Address            *string `json:"address"`
 	City               *string `json:"city"`
 	Country            *string `json:"country"`
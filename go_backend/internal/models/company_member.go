@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CompanyMemberRole is the role of a member within their company
+type CompanyMemberRole string
+
+const (
+	CompanyOwner   CompanyMemberRole = "owner"
+	CompanyManager CompanyMemberRole = "manager"
+	CompanyViewer  CompanyMemberRole = "viewer"
+)
+
+// IsValid returns true if the role is a known company member role
+func (r CompanyMemberRole) IsValid() bool {
+	switch r {
+	case CompanyOwner, CompanyManager, CompanyViewer:
+		return true
+	}
+	return false
+}
+
+// CompanyMember is a user account of a company portal
+type CompanyMember struct {
+	ID             uuid.UUID         `db:"id" json:"id"`
+	OrganizationID uuid.UUID         `db:"organization_id" json:"organization_id"`
+	CompanyID      uuid.UUID         `db:"company_id" json:"company_id"`
+	UserID         uuid.UUID         `db:"user_id" json:"user_id"`
+	Role           CompanyMemberRole `db:"role" json:"role"`
+	Email          string            `db:"email" json:"email"`
+	FullName       string            `db:"full_name" json:"full_name"`
+	CreatedAt      time.Time         `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time         `db:"updated_at" json:"updated_at"`
+}
+
+// CompanyInvite invites an email address to join a company with a role
+type CompanyInvite struct {
+	ID             uuid.UUID         `db:"id" json:"id"`
+	OrganizationID uuid.UUID         `db:"organization_id" json:"organization_id"`
+	CompanyID      uuid.UUID         `db:"company_id" json:"company_id"`
+	Email          string            `db:"email" json:"email"`
+	Role           CompanyMemberRole `db:"role" json:"role"`
+	TokenHash      string            `db:"token_hash" json:"-"`
+	InvitedBy      *uuid.UUID        `db:"invited_by" json:"invited_by,omitempty"`
+	ExpiresAt      time.Time         `db:"expires_at" json:"expires_at"`
+	AcceptedAt     *time.Time        `db:"accepted_at" json:"accepted_at,omitempty"`
+	RevokedAt      *time.Time        `db:"revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt      time.Time         `db:"created_at" json:"created_at"`
+}
+
+// IsPending returns true if the invite can still be accepted at now
+func (i *CompanyInvite) IsPending(now time.Time) bool {
+	return i.AcceptedAt == nil && i.RevokedAt == nil && now.Before(i.ExpiresAt)
+}
+
+// CreateInviteRequest represents the request to invite a company member
+type CreateInviteRequest struct {
+	Email string            `json:"email" binding:"required,email"`
+	Role  CompanyMemberRole `json:"role" binding:"required"`
+}
+
+// CreateInviteResponse carries a new invite; the token is only returned once
+// and is handed to the invitee to accept it
+type CreateInviteResponse struct {
+	CompanyInvite
+	Token string `json:"token"`
+}
+
+// AcceptInviteRequest represents the request to accept an invite and create
+// the member's account
+type AcceptInviteRequest struct {
+	Token    string  `json:"token" binding:"required"`
+	FullName string  `json:"full_name" binding:"required"`
+	Password string  `json:"password" binding:"required,min=8"`
+	Phone    *string `json:"phone" binding:"omitempty,phone"`
+}
+
+// UpdateMemberRequest represents the request to change a member's role
+type UpdateMemberRequest struct {
+	Role CompanyMemberRole `json:"role" binding:"required"`
+}
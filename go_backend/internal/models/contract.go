@@ -0,0 +1,182 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ContractRate is the price per kg a contract fixes for one waste type
+type ContractRate struct {
+	WasteType  string  `json:"waste_type" binding:"required,waste_type"`
+	PricePerKg float64 `json:"price_per_kg" binding:"gte=0"`
+	Currency   string  `json:"currency" binding:"required,len=3"`
+}
+
+// RateCard is the list of rates agreed in a contract, one per waste type
+type RateCard []ContractRate
+
+// Scan reads the rate card from a JSONB column
+func (r *RateCard) Scan(src interface{}) error {
+	return scanJSON(src, r)
+}
+
+// Value writes the rate card to a JSONB column
+func (r RateCard) Value() (driver.Value, error) {
+	if r == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(r)
+}
+
+// WasteTypes is a list of waste types stored in a JSONB column
+type WasteTypes []string
+
+// Scan reads the waste types from a JSONB column
+func (w *WasteTypes) Scan(src interface{}) error {
+	return scanJSON(src, w)
+}
+
+// Value writes the waste types to a JSONB column
+func (w WasteTypes) Value() (driver.Value, error) {
+	if w == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(w)
+}
+
+// Contains returns true if the waste type is in the list
+func (w WasteTypes) Contains(wasteType string) bool {
+	for _, t := range w {
+		if t == wasteType {
+			return true
+		}
+	}
+	return false
+}
+
+// Contract is an agreement between a company and a municipality: the company
+// collects and buys the covered waste types inside the service area from
+// ValidFrom until ValidTo, at the rates of its rate card where it has one
+type Contract struct {
+	ID           uuid.UUID  `db:"id" json:"id"`
+	CompanyID    uuid.UUID  `db:"company_id" json:"company_id"`
+	Name         string     `db:"name" json:"name"`
+	Municipality string     `db:"municipality" json:"municipality"`
+	WasteTypes   WasteTypes `db:"waste_types" json:"waste_types"`
+	BoundingBox             // Service area
+	RateCard     RateCard   `db:"rate_card" json:"rate_card"`
+	ValidFrom    time.Time  `db:"valid_from" json:"valid_from"`
+	ValidTo      *time.Time `db:"valid_to" json:"valid_to,omitempty"`
+	IsActive     bool       `db:"is_active" json:"is_active"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// InEffect returns true if the contract is active and valid at the given time
+func (c *Contract) InEffect(at time.Time) bool {
+	return c.IsActive && !at.Before(c.ValidFrom) && (c.ValidTo == nil || at.Before(*c.ValidTo))
+}
+
+// CoversBin returns true if the bin's waste type is covered and the bin lies
+// inside the service area
+func (c *Contract) CoversBin(bin *Bin) bool {
+	return c.WasteTypes.Contains(bin.WasteType) && c.Contains(bin.Latitude, bin.Longitude)
+}
+
+// Rate returns the rate the contract fixes for the waste type, if any
+func (c *Contract) Rate(wasteType string) *ContractRate {
+	for i := range c.RateCard {
+		if c.RateCard[i].WasteType == wasteType {
+			return &c.RateCard[i]
+		}
+	}
+	return nil
+}
+
+// PricingRule returns the flat rule valuing waste of the given type and
+// condition at the contract's rate, or nil if the rate card has no rate for it
+func (c *Contract) PricingRule(wasteType, condition string) *PricingRule {
+	rate := c.Rate(wasteType)
+	if rate == nil {
+		return nil
+	}
+	return &PricingRule{
+		CompanyID:    &c.CompanyID,
+		WasteType:    wasteType,
+		Condition:    condition,
+		PricingModel: PricingModelFlat,
+		PricePerKg:   rate.PricePerKg,
+		Currency:     rate.Currency,
+		IsActive:     true,
+	}
+}
+
+// Validate checks the service area, the validity period and that the rate
+// card only prices covered waste types, once each
+func (c *Contract) Validate() error {
+	if c.MinLatitude >= c.MaxLatitude || c.MinLongitude >= c.MaxLongitude {
+		return errors.New("service area minimums must be below its maximums")
+	}
+	if c.ValidTo != nil && !c.ValidTo.After(c.ValidFrom) {
+		return errors.New("valid_to must be after valid_from")
+	}
+	priced := make(map[string]bool, len(c.RateCard))
+	for i, rate := range c.RateCard {
+		if !c.WasteTypes.Contains(rate.WasteType) {
+			return fmt.Errorf("rate_card[%d].waste_type %s is not covered by the contract", i, rate.WasteType)
+		}
+		if priced[rate.WasteType] {
+			return fmt.Errorf("rate_card[%d].waste_type %s is priced more than once", i, rate.WasteType)
+		}
+		priced[rate.WasteType] = true
+	}
+	return nil
+}
+
+// CompanyContract is a contract with the name of its company
+type CompanyContract struct {
+	Contract
+	CompanyName string `db:"company_name"`
+}
+
+// CreateContractRequest represents the request to create a contract; the
+// service area is a bounding box in degrees
+type CreateContractRequest struct {
+	CompanyID    uuid.UUID  `json:"company_id" binding:"required"`
+	Name         string     `json:"name" binding:"required,max=100"`
+	Municipality string     `json:"municipality" binding:"required,max=100"`
+	WasteTypes   []string   `json:"waste_types" binding:"required,min=1,dive,required,waste_type"`
+	MinLatitude  float64    `json:"min_latitude" binding:"latitude"`
+	MinLongitude float64    `json:"min_longitude" binding:"longitude"`
+	MaxLatitude  float64    `json:"max_latitude" binding:"latitude"`
+	MaxLongitude float64    `json:"max_longitude" binding:"longitude"`
+	RateCard     RateCard   `json:"rate_card" binding:"dive"`
+	ValidFrom    time.Time  `json:"valid_from" binding:"required"`
+	ValidTo      *time.Time `json:"valid_to"`
+}
+
+// UpdateContractRequest represents the request to update a contract
+type UpdateContractRequest struct {
+	Name         *string    `json:"name" binding:"omitempty,max=100"`
+	Municipality *string    `json:"municipality" binding:"omitempty,max=100"`
+	WasteTypes   []string   `json:"waste_types" binding:"omitempty,min=1,dive,required,waste_type"`
+	MinLatitude  *float64   `json:"min_latitude" binding:"omitempty,latitude"`
+	MinLongitude *float64   `json:"min_longitude" binding:"omitempty,longitude"`
+	MaxLatitude  *float64   `json:"max_latitude" binding:"omitempty,latitude"`
+	MaxLongitude *float64   `json:"max_longitude" binding:"omitempty,longitude"`
+	RateCard     RateCard   `json:"rate_card" binding:"omitempty,dive"`
+	ValidFrom    *time.Time `json:"valid_from"`
+	ValidTo      *time.Time `json:"valid_to"`
+	IsActive     *bool      `json:"is_active"`
+}
+
+// ContractFilter narrows contract listings; nil fields are ignored
+type ContractFilter struct {
+	CompanyID *uuid.UUID
+	ActiveAt  *time.Time // Only contracts in effect at this time
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeadLetter is an MQTT message that failed processing and was set aside
+type DeadLetter struct {
+	ID          uuid.UUID  `db:"id" json:"id"`
+	Topic       string     `db:"topic" json:"topic"`
+	Payload     string     `db:"payload" json:"payload"`
+	Reason      string     `db:"reason" json:"reason"`
+	ReplayCount int        `db:"replay_count" json:"replay_count"`
+	ReceivedAt  time.Time  `db:"received_at" json:"received_at"`
+	ReplayedAt  *time.Time `db:"replayed_at" json:"replayed_at,omitempty"`
+}
+
+// ReplayDeadLetterRequest represents the request to replay a dead letter,
+// optionally with a corrected payload
+type ReplayDeadLetterRequest struct {
+	Payload *string `json:"payload"`
+}
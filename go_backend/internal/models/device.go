@@ -0,0 +1,145 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeviceStatus represents where a sensor unit is in its life
+type DeviceStatus string
+
+const (
+	// DeviceInStock is registered but not installed on a bin
+	DeviceInStock DeviceStatus = "in_stock"
+	// DeviceActive is installed on a bin
+	DeviceActive DeviceStatus = "active"
+	// DeviceDecommissioned is retired and cannot be installed again
+	DeviceDecommissioned DeviceStatus = "decommissioned"
+)
+
+// IsValid returns true if the device status is known
+func (s DeviceStatus) IsValid() bool {
+	switch s {
+	case DeviceInStock, DeviceActive, DeviceDecommissioned:
+		return true
+	}
+	return false
+}
+
+// Connectivity is how a device reaches the broker
+type Connectivity string
+
+const (
+	ConnectivityWiFi     Connectivity = "wifi"
+	ConnectivityCellular Connectivity = "cellular"
+	ConnectivityLoRaWAN  Connectivity = "lorawan"
+	ConnectivityEthernet Connectivity = "ethernet"
+)
+
+// IsValid returns true if the connectivity is known
+func (c Connectivity) IsValid() bool {
+	switch c {
+	case ConnectivityWiFi, ConnectivityCellular, ConnectivityLoRaWAN, ConnectivityEthernet:
+		return true
+	}
+	return false
+}
+
+// ProvisioningStatus is whether the device was issued its broker certificate
+type ProvisioningStatus string
+
+const (
+	// ProvisioningUnassigned is not installed on a bin to provision for
+	ProvisioningUnassigned ProvisioningStatus = "unassigned"
+	// ProvisioningPending is installed and waiting for its first boot
+	ProvisioningPending     ProvisioningStatus = "pending"
+	ProvisioningProvisioned ProvisioningStatus = "provisioned"
+)
+
+// Device is a sensor unit, tracked apart from the bin it is installed on
+type Device struct {
+	ID             uuid.UUID `db:"id" json:"id"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	HardwareSerial string    `db:"hardware_serial" json:"hardware_serial"`
+	Model          *string   `db:"model" json:"model,omitempty"`
+	// FirmwareVersion is reported by the bin the device is installed on, or
+	// the last one reported before it was removed
+	FirmwareVersion    *string      `db:"firmware_version" json:"firmware_version,omitempty"`
+	Connectivity       Connectivity `db:"connectivity" json:"connectivity"`
+	SimICCID           *string      `db:"sim_iccid" json:"sim_iccid,omitempty"`
+	Carrier            *string      `db:"carrier" json:"carrier,omitempty"`
+	APN                *string      `db:"apn" json:"apn,omitempty"`
+	Status             DeviceStatus `db:"status" json:"status"`
+	BinID              *uuid.UUID   `db:"bin_id" json:"bin_id,omitempty"`
+	Notes              *string      `db:"notes" json:"notes,omitempty"`
+	DecommissionedAt   *time.Time   `db:"decommissioned_at" json:"decommissioned_at,omitempty"`
+	DecommissionReason *string      `db:"decommission_reason" json:"decommission_reason,omitempty"`
+	CreatedAt          time.Time    `db:"created_at" json:"created_at"`
+	UpdatedAt          time.Time    `db:"updated_at" json:"updated_at"`
+
+	// From the bin the device is installed on
+	BinDeviceID          *string            `db:"bin_device_id" json:"bin_device_id,omitempty"`
+	ProvisioningStatus   ProvisioningStatus `db:"provisioning_status" json:"provisioning_status"`
+	ProvisionedAt        *time.Time         `db:"provisioned_at" json:"provisioned_at,omitempty"`
+	CertificateExpiresAt *time.Time         `db:"certificate_expires_at" json:"certificate_expires_at,omitempty"`
+	RSSI                 *int               `db:"rssi" json:"rssi,omitempty"`
+	LastReadingAt        *time.Time         `db:"last_reading_at" json:"last_reading_at,omitempty"`
+}
+
+// DeviceAssignment is a period a device served a bin
+type DeviceAssignment struct {
+	ID         uuid.UUID `db:"id" json:"id"`
+	DeviceID   uuid.UUID `db:"device_id" json:"device_id"`
+	BinID      uuid.UUID `db:"bin_id" json:"bin_id"`
+	AssignedAt time.Time `db:"assigned_at" json:"assigned_at"`
+	// UnassignedAt is empty while the device still serves the bin
+	UnassignedAt   *time.Time `db:"unassigned_at" json:"unassigned_at,omitempty"`
+	AssignedBy     *uuid.UUID `db:"assigned_by" json:"assigned_by,omitempty"`
+	Reason         *string    `db:"reason" json:"reason,omitempty"`
+	HardwareSerial string     `db:"hardware_serial" json:"hardware_serial"`
+	BinDeviceID    string     `db:"bin_device_id" json:"bin_device_id"`
+}
+
+// CreateDeviceRequest represents the request to register a sensor unit,
+// optionally installed on a bin at once
+type CreateDeviceRequest struct {
+	HardwareSerial  string       `json:"hardware_serial" binding:"required,max=100"`
+	Model           *string      `json:"model" binding:"omitempty,max=100"`
+	FirmwareVersion *string      `json:"firmware_version" binding:"omitempty,max=50"`
+	Connectivity    Connectivity `json:"connectivity"`
+	SimICCID        *string      `json:"sim_iccid" binding:"omitempty,min=18,max=22,numeric"`
+	Carrier         *string      `json:"carrier" binding:"omitempty,max=100"`
+	APN             *string      `json:"apn" binding:"omitempty,max=100"`
+	Notes           *string      `json:"notes" binding:"omitempty,max=2000"`
+	BinID           *uuid.UUID   `json:"bin_id"`
+}
+
+// UpdateDeviceRequest represents the request to update the details of a device
+type UpdateDeviceRequest struct {
+	Model        *string       `json:"model" binding:"omitempty,max=100"`
+	Connectivity *Connectivity `json:"connectivity"`
+	SimICCID     *string       `json:"sim_iccid" binding:"omitempty,min=18,max=22,numeric"`
+	Carrier      *string       `json:"carrier" binding:"omitempty,max=100"`
+	APN          *string       `json:"apn" binding:"omitempty,max=100"`
+	Notes        *string       `json:"notes" binding:"omitempty,max=2000"`
+}
+
+// AssignDeviceRequest represents the request to install a device on a bin,
+// moving it from the bin it served and replacing the device of the new one
+type AssignDeviceRequest struct {
+	BinID  uuid.UUID `json:"bin_id" binding:"required"`
+	Reason *string   `json:"reason" binding:"omitempty,max=500"`
+}
+
+// DecommissionDeviceRequest represents the request to retire a device
+type DecommissionDeviceRequest struct {
+	Reason *string `json:"reason" binding:"omitempty,max=500"`
+}
+
+// DeviceFilter narrows device listings; nil fields are ignored
+type DeviceFilter struct {
+	Status       *DeviceStatus
+	Model        *string
+	Connectivity *Connectivity
+}
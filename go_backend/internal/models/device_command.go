@@ -0,0 +1,110 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeviceCommandType represents an instruction the backend can send to a bin sensor
+type DeviceCommandType string
+
+const (
+	DeviceCommandSetInterval DeviceCommandType = "set_interval"
+	DeviceCommandReadNow     DeviceCommandType = "read_now"
+	DeviceCommandReboot      DeviceCommandType = "reboot"
+)
+
+// IsValid returns true if the command type is supported by the sensors
+func (t DeviceCommandType) IsValid() bool {
+	switch t {
+	case DeviceCommandSetInterval, DeviceCommandReadNow, DeviceCommandReboot:
+		return true
+	}
+	return false
+}
+
+// DeviceCommand is the payload published to bins/{device_id}/cmd
+type DeviceCommand struct {
+	ID              uuid.UUID         `json:"id"`
+	Type            DeviceCommandType `json:"type"`
+	IntervalSeconds *int              `json:"interval_seconds,omitempty"`
+	IssuedAt        time.Time         `json:"issued_at"`
+}
+
+// CreateDeviceCommandRequest represents the request to send a command to a bin
+type CreateDeviceCommandRequest struct {
+	Type            DeviceCommandType `json:"type" binding:"required"`
+	IntervalSeconds *int              `json:"interval_seconds" binding:"omitempty,gte=1,lte=86400"`
+}
+
+// Outcomes of a pushed configuration reported by the sensors
+const (
+	DeviceConfigApplied  = "applied"
+	DeviceConfigRejected = "rejected"
+)
+
+// DeviceConfig is the payload published, retained, to bins/{device_id}/config.
+// Sensors apply the fields set over their current configuration and keep it
+// across restarts.
+type DeviceConfig struct {
+	// Version orders the pushes; sensors ignore one not newer than the last applied
+	Version int64 `json:"version"`
+
+	ReadIntervalSeconds      *int     `json:"read_interval_seconds,omitempty"`
+	AdaptiveReporting        *bool    `json:"adaptive_reporting,omitempty"`
+	HighFillThreshold        *int     `json:"high_fill_threshold,omitempty"`
+	HighFillIntervalSeconds  *int     `json:"high_fill_interval_seconds,omitempty"`
+	LowFillThreshold         *int     `json:"low_fill_threshold,omitempty"`
+	LowFillIntervalSeconds   *int     `json:"low_fill_interval_seconds,omitempty"`
+	ReportMinDelta           *int     `json:"report_min_delta,omitempty"`
+	HeartbeatIntervalSeconds *int     `json:"heartbeat_interval_seconds,omitempty"`
+	HazardTemperatureC       *float64 `json:"hazard_temperature_c,omitempty"`
+
+	// Broker credentials, used by the sensor from its next connection on
+	MQTTUsername *string `json:"mqtt_username,omitempty"`
+	MQTTPassword *string `json:"mqtt_password,omitempty"`
+}
+
+// UpdateDeviceConfigRequest represents the request to push a configuration
+// to a bin sensor; omitted fields keep their value on the sensor
+type UpdateDeviceConfigRequest struct {
+	ReadIntervalSeconds      *int     `json:"read_interval_seconds" binding:"omitempty,gte=1,lte=86400"`
+	AdaptiveReporting        *bool    `json:"adaptive_reporting"`
+	HighFillThreshold        *int     `json:"high_fill_threshold" binding:"omitempty,min=0,max=100"`
+	HighFillIntervalSeconds  *int     `json:"high_fill_interval_seconds" binding:"omitempty,gte=1,lte=86400"`
+	LowFillThreshold         *int     `json:"low_fill_threshold" binding:"omitempty,min=0,max=100"`
+	LowFillIntervalSeconds   *int     `json:"low_fill_interval_seconds" binding:"omitempty,gte=1,lte=86400"`
+	ReportMinDelta           *int     `json:"report_min_delta" binding:"omitempty,min=0,max=100"`
+	HeartbeatIntervalSeconds *int     `json:"heartbeat_interval_seconds" binding:"omitempty,min=0,max=86400"`
+	HazardTemperatureC       *float64 `json:"hazard_temperature_c" binding:"omitempty,gt=0"`
+	MQTTUsername             *string  `json:"mqtt_username" binding:"omitempty,max=255"`
+	MQTTPassword             *string  `json:"mqtt_password" binding:"omitempty,max=255"`
+}
+
+// DeviceConfig returns the configuration to publish with the given version
+func (r *UpdateDeviceConfigRequest) DeviceConfig(version int64) *DeviceConfig {
+	return &DeviceConfig{
+		Version:                  version,
+		ReadIntervalSeconds:      r.ReadIntervalSeconds,
+		AdaptiveReporting:        r.AdaptiveReporting,
+		HighFillThreshold:        r.HighFillThreshold,
+		HighFillIntervalSeconds:  r.HighFillIntervalSeconds,
+		LowFillThreshold:         r.LowFillThreshold,
+		LowFillIntervalSeconds:   r.LowFillIntervalSeconds,
+		ReportMinDelta:           r.ReportMinDelta,
+		HeartbeatIntervalSeconds: r.HeartbeatIntervalSeconds,
+		HazardTemperatureC:       r.HazardTemperatureC,
+		MQTTUsername:             r.MQTTUsername,
+		MQTTPassword:             r.MQTTPassword,
+	}
+}
+
+// DeviceConfigAck is the outcome of a pushed configuration, published by the
+// sensor to bins/{device_id}/config/ack
+type DeviceConfigAck struct {
+	Version   int64  `json:"version"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	Timestamp int64  `json:"timestamp"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ShiftTimeLayout is the clock format of recurring shift times
+const ShiftTimeLayout = "15:04"
+
+// DriverShift is a period a driver is working. Recurring shifts repeat weekly on
+// DayOfWeek between StartTime and EndTime; one-off shifts run from StartsAt to EndsAt.
+type DriverShift struct {
+	ID        uuid.UUID  `db:"id" json:"id"`
+	DriverID  uuid.UUID  `db:"driver_id" json:"driver_id"`
+	DayOfWeek *int       `db:"day_of_week" json:"day_of_week,omitempty"` // 0 = Sunday
+	StartTime *string    `db:"start_time" json:"start_time,omitempty"`
+	EndTime   *string    `db:"end_time" json:"end_time,omitempty"`
+	Timezone  string     `db:"timezone" json:"timezone"`
+	StartsAt  *time.Time `db:"starts_at" json:"starts_at,omitempty"`
+	EndsAt    *time.Time `db:"ends_at" json:"ends_at,omitempty"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+}
+
+// CreateDriverShiftRequest creates either a weekly recurring shift (day_of_week,
+// start_time, end_time) or a one-off shift (starts_at, ends_at)
+type CreateDriverShiftRequest struct {
+	DayOfWeek *int       `json:"day_of_week" binding:"omitempty,min=0,max=6"`
+	StartTime *string    `json:"start_time"` // HH:MM; an end before the start spans midnight
+	EndTime   *string    `json:"end_time"`
+	Timezone  string     `json:"timezone"` // IANA name, defaults to UTC
+	StartsAt  *time.Time `json:"starts_at"`
+	EndsAt    *time.Time `json:"ends_at"`
+}
+
+// IsRecurring returns true if the shift repeats weekly
+func (s *DriverShift) IsRecurring() bool {
+	return s.DayOfWeek != nil
+}
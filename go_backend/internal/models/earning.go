@@ -0,0 +1,122 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EarningsPeriodLayout is the format of an earnings period, a calendar month
+const EarningsPeriodLayout = "2006-01"
+
+// EarningSource identifies what a driver was paid for
+type EarningSource string
+
+const (
+	EarningSourceCollection EarningSource = "collection"
+	EarningSourcePickup     EarningSource = "pickup"
+	EarningSourceShipment   EarningSource = "shipment" // a shipment the driver delivered
+	EarningSourceBonus      EarningSource = "bonus"
+)
+
+// PayoutBatchStatus is the status of a payout batch
+type PayoutBatchStatus string
+
+const (
+	PayoutBatchPending PayoutBatchStatus = "pending"
+	PayoutBatchPaid    PayoutBatchStatus = "paid"
+)
+
+// EarningsPeriod returns the calendar month (UTC) of an earnings period, or
+// the month containing now when value is empty
+func EarningsPeriod(value string, now time.Time) (AnalyticsRange, error) {
+	if value == "" {
+		return LeaderboardMonth.Range(now), nil
+	}
+	from, err := time.Parse(EarningsPeriodLayout, value)
+	if err != nil {
+		return AnalyticsRange{}, errors.New("period must be a month formatted YYYY-MM")
+	}
+	return AnalyticsRange{From: from, To: from.AddDate(0, 1, 0)}, nil
+}
+
+// DriverEarning is a ledger entry of what a driver earned; it is paid once
+// its payout batch is
+type DriverEarning struct {
+	ID             uuid.UUID     `db:"id" json:"id"`
+	OrganizationID uuid.UUID     `db:"organization_id" json:"organization_id"`
+	DriverID       uuid.UUID     `db:"driver_id" json:"driver_id"`
+	Source         EarningSource `db:"source" json:"source"`
+	SourceID       uuid.UUID     `db:"source_id" json:"source_id"`
+	Amount         float64       `db:"amount" json:"amount"`
+	Currency       string        `db:"currency" json:"currency"`
+	WeightKg       *float64      `db:"weight_kg" json:"weight_kg,omitempty"`
+	Description    *string       `db:"description" json:"description,omitempty"`
+	PayoutBatchID  *uuid.UUID    `db:"payout_batch_id" json:"payout_batch_id,omitempty"`
+	Paid           bool          `db:"paid" json:"paid"` // Whether its payout batch was paid
+	CreatedBy      *uuid.UUID    `db:"created_by" json:"created_by,omitempty"`
+	EarnedAt       time.Time     `db:"earned_at" json:"earned_at"`
+}
+
+// GrantBonusRequest represents the request to grant a driver a bonus
+type GrantBonusRequest struct {
+	Amount      float64 `json:"amount" binding:"required,gt=0"`
+	Description string  `json:"description" binding:"required,max=500"`
+}
+
+// EarningsBySource totals the earnings of one source in a statement
+type EarningsBySource struct {
+	Source EarningSource `json:"source"`
+	Count  int           `json:"count"`
+	Amount float64       `json:"amount"`
+}
+
+// DriverEarningsStatement is what a driver earned over a month, split by
+// source and by whether it was paid out yet
+type DriverEarningsStatement struct {
+	DriverID uuid.UUID          `json:"driver_id"`
+	Period   string             `json:"period"`
+	From     time.Time          `json:"from"`
+	To       time.Time          `json:"to"`
+	Currency string             `json:"currency"`
+	Total    float64            `json:"total"`
+	Paid     float64            `json:"paid"`
+	Unpaid   float64            `json:"unpaid"`
+	Sources  []EarningsBySource `json:"sources"`
+	Earnings []DriverEarning    `json:"earnings"`
+}
+
+// PayoutBatch settles the unpaid earnings of an organization's drivers up to
+// the end of its period
+type PayoutBatch struct {
+	ID             uuid.UUID         `db:"id" json:"id"`
+	OrganizationID uuid.UUID         `db:"organization_id" json:"organization_id"`
+	PeriodStart    time.Time         `db:"period_start" json:"period_start"`
+	PeriodEnd      time.Time         `db:"period_end" json:"period_end"`
+	Status         PayoutBatchStatus `db:"status" json:"status"`
+	TotalAmount    float64           `db:"total_amount" json:"total_amount"`
+	Currency       string            `db:"currency" json:"currency"`
+	DriverCount    int               `db:"driver_count" json:"driver_count"`
+	CreatedBy      *uuid.UUID        `db:"created_by" json:"created_by,omitempty"`
+	PaidAt         *time.Time        `db:"paid_at" json:"paid_at,omitempty"`
+	CreatedAt      time.Time         `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time         `db:"updated_at" json:"updated_at"`
+	Lines          []PayoutLine      `db:"-" json:"lines,omitempty"`
+}
+
+// PayoutLine is what a payout batch pays one driver
+type PayoutLine struct {
+	ID            uuid.UUID `db:"id" json:"id"`
+	BatchID       uuid.UUID `db:"batch_id" json:"batch_id"`
+	DriverID      uuid.UUID `db:"driver_id" json:"driver_id"`
+	FullName      string    `db:"full_name" json:"full_name"`
+	Amount        float64   `db:"amount" json:"amount"`
+	EarningsCount int       `db:"earnings_count" json:"earnings_count"`
+}
+
+// CreatePayoutBatchRequest represents the request to pay out the unpaid
+// earnings up to the end of a month
+type CreatePayoutBatchRequest struct {
+	Period string `json:"period" binding:"required"`
+}
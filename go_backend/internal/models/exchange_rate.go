@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// ExchangeRates are the rates of currencies against a base currency on a day
+type ExchangeRates struct {
+	Base   string             `json:"base"`
+	Date   time.Time          `json:"date"`
+	Source string             `json:"source"`
+	Rates  map[string]float64 `json:"rates"` // Units of each currency per unit of Base
+}
+
+// Rate returns the units of to per unit of from, crossing through the base
+// currency, and whether both currencies are known
+func (r *ExchangeRates) Rate(from, to string) (float64, bool) {
+	if from == to {
+		return 1, true
+	}
+	fromRate, ok := r.rate(from)
+	if !ok {
+		return 0, false
+	}
+	toRate, ok := r.rate(to)
+	if !ok {
+		return 0, false
+	}
+	return toRate / fromRate, true
+}
+
+// rate returns the units of a currency per unit of the base currency
+func (r *ExchangeRates) rate(currency string) (float64, bool) {
+	if currency == r.Base {
+		return 1, true
+	}
+	rate, ok := r.Rates[currency]
+	return rate, ok && rate > 0
+}
+
+// CurrencyConversion is a valuation converted to another currency
+type CurrencyConversion struct {
+	Currency   string    `json:"currency"`
+	Rate       float64   `json:"rate"` // Units of Currency per unit of the native currency
+	PricePerKg float64   `json:"price_per_kg"`
+	TotalPrice float64   `json:"total_price"`
+	RatesDate  time.Time `json:"rates_date"`
+	Source     string    `json:"source"`
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RolloutStatus represents the state of a firmware rollout
+type RolloutStatus string
+
+const (
+	RolloutActive    RolloutStatus = "active"
+	RolloutCancelled RolloutStatus = "cancelled"
+)
+
+// FirmwareUpdateStatus represents the progress of a rollout on one bin sensor
+type FirmwareUpdateStatus string
+
+const (
+	// FirmwareUpdatePending is offered to the sensor, which did not answer yet
+	FirmwareUpdatePending     FirmwareUpdateStatus = "pending"
+	FirmwareUpdateDownloading FirmwareUpdateStatus = "downloading"
+	// FirmwareUpdateInstalled is verified and swapped in; the sensor restarts into it
+	FirmwareUpdateInstalled FirmwareUpdateStatus = "installed"
+	// FirmwareUpdateUpdated is running on the sensor after its restart
+	FirmwareUpdateUpdated FirmwareUpdateStatus = "updated"
+	FirmwareUpdateFailed  FirmwareUpdateStatus = "failed"
+	// FirmwareUpdateCancelled was withdrawn with its rollout before it finished
+	FirmwareUpdateCancelled FirmwareUpdateStatus = "cancelled"
+)
+
+// IsValid returns true if the status can be reported by a sensor
+func (s FirmwareUpdateStatus) IsValid() bool {
+	switch s {
+	case FirmwareUpdateDownloading, FirmwareUpdateInstalled, FirmwareUpdateUpdated, FirmwareUpdateFailed:
+		return true
+	}
+	return false
+}
+
+// IsFinal returns true if the sensor is done with the update, successfully or not
+func (s FirmwareUpdateStatus) IsFinal() bool {
+	return s == FirmwareUpdateUpdated || s == FirmwareUpdateFailed || s == FirmwareUpdateCancelled
+}
+
+// FirmwareRollout is a firmware version offered to the bin sensors of a
+// device group, or of the whole organization without one
+type FirmwareRollout struct {
+	ID             uuid.UUID     `db:"id" json:"id"`
+	OrganizationID uuid.UUID     `db:"organization_id" json:"organization_id"`
+	DeviceGroup    *string       `db:"device_group" json:"device_group,omitempty"`
+	Version        string        `db:"version" json:"version"`
+	URL            string        `db:"url" json:"url"`
+	SHA256         string        `db:"sha256" json:"sha256"`
+	Signature      string        `db:"signature" json:"signature"`
+	Status         RolloutStatus `db:"status" json:"status"`
+	CreatedBy      *uuid.UUID    `db:"created_by" json:"created_by,omitempty"`
+	CreatedAt      time.Time     `db:"created_at" json:"created_at"`
+	CancelledAt    *time.Time    `db:"cancelled_at" json:"cancelled_at,omitempty"`
+
+	// Progress counts the bins of the rollout by update status
+	Progress map[FirmwareUpdateStatus]int `db:"-" json:"progress,omitempty"`
+}
+
+// FirmwareUpdate is the progress of a rollout on one bin sensor
+type FirmwareUpdate struct {
+	RolloutID uuid.UUID            `db:"rollout_id" json:"rollout_id"`
+	BinID     uuid.UUID            `db:"bin_id" json:"bin_id"`
+	DeviceID  string               `db:"device_id" json:"device_id"`
+	Status    FirmwareUpdateStatus `db:"status" json:"status"`
+	Error     *string              `db:"error" json:"error,omitempty"`
+	UpdatedAt time.Time            `db:"updated_at" json:"updated_at"`
+}
+
+// CreateFirmwareRolloutRequest represents the request to roll out a firmware
+// version. The signature is the base64 Ed25519 signature of the SHA-256
+// digest of the binary, checked by the sensors against their public key.
+type CreateFirmwareRolloutRequest struct {
+	Version     string  `json:"version" binding:"required,max=50"`
+	URL         string  `json:"url" binding:"required,url,max=2000"`
+	SHA256      string  `json:"sha256" binding:"required,len=64,hexadecimal"`
+	Signature   string  `json:"signature" binding:"required,base64,max=200"`
+	DeviceGroup *string `json:"device_group" binding:"omitempty,max=100"`
+}
+
+// FirmwareRolloutFilter narrows a firmware rollout listing
+type FirmwareRolloutFilter struct {
+	Status      *RolloutStatus
+	DeviceGroup *string
+}
+
+// OTAUpdate is the payload published, retained, to bins/{device_id}/ota
+type OTAUpdate struct {
+	RolloutID uuid.UUID `json:"rollout_id"`
+	Version   string    `json:"version"`
+	URL       string    `json:"url"`
+	SHA256    string    `json:"sha256"`
+	Signature string    `json:"signature"`
+}
+
+// OTAStatus is the progress of an update published by the sensor to
+// bins/{device_id}/ota/status
+type OTAStatus struct {
+	RolloutID uuid.UUID            `json:"rollout_id"`
+	Version   string               `json:"version"`
+	Status    FirmwareUpdateStatus `json:"status"`
+	Error     string               `json:"error,omitempty"`
+	Timestamp int64                `json:"timestamp"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmissionFactorAnyWasteType is the waste type of the fallback emission factor
+const EmissionFactorAnyWasteType = "*"
+
+// ImpactScope identifies whose collections an impact report covers
+type ImpactScope string
+
+const (
+	ImpactScopeUser    ImpactScope = "user"
+	ImpactScopeCompany ImpactScope = "company"
+	ImpactScopeCity    ImpactScope = "city"
+)
+
+// EmissionFactor is the CO2-equivalent avoided per kg of a waste type
+type EmissionFactor struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	WasteType   string    `db:"waste_type" json:"waste_type"`
+	KgCO2ePerKg float64   `db:"kg_co2e_per_kg" json:"kg_co2e_per_kg"`
+	Source      *string   `db:"source" json:"source,omitempty"`
+	IsActive    bool      `db:"is_active" json:"is_active"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// CreateEmissionFactorRequest represents the request to create an emission factor
+type CreateEmissionFactorRequest struct {
+	WasteType   string  `json:"waste_type" binding:"required,waste_type_or_any"`
+	KgCO2ePerKg float64 `json:"kg_co2e_per_kg" binding:"gte=0"`
+	Source      *string `json:"source"`
+}
+
+// UpdateEmissionFactorRequest represents the request to update an emission factor
+type UpdateEmissionFactorRequest struct {
+	KgCO2ePerKg *float64 `json:"kg_co2e_per_kg" binding:"omitempty,gte=0"`
+	Source      *string  `json:"source"`
+	IsActive    *bool    `json:"is_active"`
+}
+
+// ImpactByWasteType is the estimated CO2e saved by the collected weight of one
+// waste type; the factor is null when no emission factor applies
+type ImpactByWasteType struct {
+	WasteType   string   `json:"waste_type"`
+	Collections int      `json:"collections"`
+	WeightKg    float64  `json:"weight_kg"`
+	KgCO2ePerKg *float64 `json:"kg_co2e_per_kg"`
+	CO2eKg      float64  `json:"co2e_kg"`
+}
+
+// ImpactReport estimates the CO2e savings of the collections completed in a period
+type ImpactReport struct {
+	Scope         ImpactScope         `json:"scope"`
+	ScopeID       *uuid.UUID          `json:"scope_id,omitempty"`
+	Period        AnalyticsRange      `json:"period"`
+	TotalWeightKg float64             `json:"total_weight_kg"`
+	TotalCO2eKg   float64             `json:"total_co2e_kg"`
+	ByWasteType   []ImpactByWasteType `json:"by_waste_type"`
+}
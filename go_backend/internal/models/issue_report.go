@@ -0,0 +1,111 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IssueType is the kind of problem reported with a bin
+type IssueType string
+
+const (
+	IssueTypeOverflowing IssueType = "overflowing"
+	IssueTypeDamaged     IssueType = "damaged"
+	IssueTypeMissing     IssueType = "missing"
+	IssueTypeOther       IssueType = "other"
+)
+
+// IsValid returns true if the type is a known issue type
+func (t IssueType) IsValid() bool {
+	switch t {
+	case IssueTypeOverflowing, IssueTypeDamaged, IssueTypeMissing, IssueTypeOther:
+		return true
+	}
+	return false
+}
+
+// IssueStatus represents the triage status of an issue report
+type IssueStatus string
+
+const (
+	IssueStatusOpen         IssueStatus = "open"
+	IssueStatusAcknowledged IssueStatus = "acknowledged"
+	IssueStatusInProgress   IssueStatus = "in_progress"
+	IssueStatusResolved     IssueStatus = "resolved"
+	IssueStatusRejected     IssueStatus = "rejected"
+)
+
+// ValidIssueTransitions defines valid triage status transitions
+var ValidIssueTransitions = map[IssueStatus][]IssueStatus{
+	IssueStatusOpen:         {IssueStatusAcknowledged, IssueStatusInProgress, IssueStatusResolved, IssueStatusRejected},
+	IssueStatusAcknowledged: {IssueStatusInProgress, IssueStatusResolved, IssueStatusRejected},
+	IssueStatusInProgress:   {IssueStatusResolved},
+}
+
+// IsValid returns true if the status is a known issue status
+func (s IssueStatus) IsValid() bool {
+	switch s {
+	case IssueStatusOpen, IssueStatusAcknowledged, IssueStatusInProgress, IssueStatusResolved, IssueStatusRejected:
+		return true
+	}
+	return false
+}
+
+// IsClosed returns true if the report needs no further triage
+func (s IssueStatus) IsClosed() bool {
+	return s == IssueStatusResolved || s == IssueStatusRejected
+}
+
+// IssueReport is a problem with a bin reported by a citizen or staff member
+type IssueReport struct {
+	ID           uuid.UUID   `db:"id" json:"id"`
+	BinID        uuid.UUID   `db:"bin_id" json:"bin_id"`
+	ReportedBy   uuid.UUID   `db:"reported_by" json:"reported_by"`
+	ReporterRole Role        `db:"reporter_role" json:"reporter_role"`
+	Type         IssueType   `db:"type" json:"type"`
+	Description  *string     `db:"description" json:"description,omitempty"`
+	PhotoURL     *string     `db:"photo_url" json:"photo_url,omitempty"`
+	Latitude     *float64    `db:"latitude" json:"latitude,omitempty"`
+	Longitude    *float64    `db:"longitude" json:"longitude,omitempty"`
+	Status       IssueStatus `db:"status" json:"status"`
+	TriageNote   *string     `db:"triage_note" json:"triage_note,omitempty"`
+	TriagedBy    *uuid.UUID  `db:"triaged_by" json:"triaged_by,omitempty"`
+	ResolvedAt   *time.Time  `db:"resolved_at" json:"resolved_at,omitempty"`
+	CreatedAt    time.Time   `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time   `db:"updated_at" json:"updated_at"`
+}
+
+// CanTransitionTo checks if the report can move to the given triage status
+func (r *IssueReport) CanTransitionTo(newStatus IssueStatus) bool {
+	for _, validStatus := range ValidIssueTransitions[r.Status] {
+		if validStatus == newStatus {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateIssueReportRequest represents the request to report an issue with a bin.
+// The location is where the reporter saw the problem, e.g. a missing bin.
+type CreateIssueReportRequest struct {
+	Type        IssueType `json:"type" binding:"required"`
+	Description *string   `json:"description" binding:"omitempty,max=2000"`
+	PhotoURL    *string   `json:"photo_url" binding:"omitempty,url"`
+	Latitude    *float64  `json:"latitude" binding:"required_with=Longitude,omitempty,latitude"`
+	Longitude   *float64  `json:"longitude" binding:"required_with=Latitude,omitempty,longitude"`
+}
+
+// UpdateIssueStatusRequest represents the request to triage an issue report
+type UpdateIssueStatusRequest struct {
+	Status IssueStatus `json:"status" binding:"required"`
+	Note   *string     `json:"note"`
+}
+
+// IssueReportFilter narrows issue report listings; nil fields are ignored
+type IssueReportFilter struct {
+	BinID      *uuid.UUID
+	ReportedBy *uuid.UUID
+	Status     *IssueStatus
+	Type       *IssueType
+}
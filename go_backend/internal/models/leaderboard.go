@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LeaderboardPeriod is the calendar period a driver leaderboard covers
+type LeaderboardPeriod string
+
+const (
+	LeaderboardWeek  LeaderboardPeriod = "week"
+	LeaderboardMonth LeaderboardPeriod = "month"
+)
+
+// IsValid checks if the leaderboard period is supported
+func (p LeaderboardPeriod) IsValid() bool {
+	return p == LeaderboardWeek || p == LeaderboardMonth
+}
+
+// Range returns the current ISO week or calendar month (UTC) containing now
+func (p LeaderboardPeriod) Range(now time.Time) AnalyticsRange {
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if p == LeaderboardMonth {
+		from := today.AddDate(0, 0, 1-today.Day())
+		return AnalyticsRange{From: from, To: from.AddDate(0, 1, 0)}
+	}
+	// Weeks start on Monday
+	from := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	return AnalyticsRange{From: from, To: from.AddDate(0, 0, 7)}
+}
+
+// LeaderboardRankBy is the metric drivers are ranked by
+type LeaderboardRankBy string
+
+const (
+	RankByCollections LeaderboardRankBy = "collections"
+	RankByWeight      LeaderboardRankBy = "weight"
+	RankByOnTimeRate  LeaderboardRankBy = "on_time_rate"
+	RankByRating      LeaderboardRankBy = "rating"
+)
+
+// IsValid checks if the ranking metric is supported
+func (r LeaderboardRankBy) IsValid() bool {
+	switch r {
+	case RankByCollections, RankByWeight, RankByOnTimeRate, RankByRating:
+		return true
+	}
+	return false
+}
+
+// DriverBadge is an achievement shown on the driver app leaderboard
+type DriverBadge string
+
+const (
+	BadgeTopCollector DriverBadge = "top_collector" // Most completed collections of the period
+	BadgeHeaviestLoad DriverBadge = "heaviest_load" // Most weight collected in the period
+	BadgePunctual     DriverBadge = "punctual"      // At least 95% on time over 10 or more collections
+	BadgeHighlyRated  DriverBadge = "highly_rated"  // Average rating of 4.5 or more
+	BadgeCentury      DriverBadge = "century"       // 100 or more completed collections in the period
+)
+
+// DriverLeaderboardEntry is the standing of one driver over the period; the
+// on-time rate is null for drivers without completed collections
+type DriverLeaderboardEntry struct {
+	Rank          int           `db:"-" json:"rank"`
+	DriverID      uuid.UUID     `db:"driver_id" json:"driver_id"`
+	FullName      string        `db:"full_name" json:"full_name"`
+	Completed     int           `db:"completed" json:"completed_collections"`
+	WeightKg      float64       `db:"weight_kg" json:"weight_kg"`
+	OnTime        int           `db:"on_time" json:"on_time_collections"`
+	OnTimeRate    *float64      `db:"-" json:"on_time_rate"`
+	AverageRating float64       `db:"average_rating" json:"average_rating"`
+	Badges        []DriverBadge `db:"-" json:"badges"`
+}
+
+// DriverLeaderboard ranks the drivers by their collections of the period
+type DriverLeaderboard struct {
+	Period        LeaderboardPeriod        `json:"period"`
+	From          time.Time                `json:"from"`
+	To            time.Time                `json:"to"`
+	RankBy        LeaderboardRankBy        `json:"rank_by"`
+	OnTimeMinutes int                      `json:"on_time_minutes"` // Completion window of an on-time collection
+	Drivers       []DriverLeaderboardEntry `json:"drivers"`
+	CurrentDriver *DriverLeaderboardEntry  `json:"current_driver,omitempty"` // The requesting driver, even outside the limit
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaintenanceType is the kind of work done on a bin
+type MaintenanceType string
+
+const (
+	MaintenanceTypeDamaged     MaintenanceType = "damaged"
+	MaintenanceTypeCleaning    MaintenanceType = "cleaning"
+	MaintenanceTypeSensorFault MaintenanceType = "sensor_fault"
+)
+
+// IsValid returns true if the type is a known maintenance type
+func (t MaintenanceType) IsValid() bool {
+	switch t {
+	case MaintenanceTypeDamaged, MaintenanceTypeCleaning, MaintenanceTypeSensorFault:
+		return true
+	}
+	return false
+}
+
+// BinMaintenanceStatus represents the progress of a bin maintenance
+type BinMaintenanceStatus string
+
+const (
+	BinMaintenanceScheduled  BinMaintenanceStatus = "scheduled"
+	BinMaintenanceInProgress BinMaintenanceStatus = "in_progress"
+	BinMaintenanceCompleted  BinMaintenanceStatus = "completed"
+	BinMaintenanceCancelled  BinMaintenanceStatus = "cancelled"
+)
+
+// ValidBinMaintenanceTransitions defines valid maintenance status transitions
+var ValidBinMaintenanceTransitions = map[BinMaintenanceStatus][]BinMaintenanceStatus{
+	BinMaintenanceScheduled:  {BinMaintenanceInProgress, BinMaintenanceCompleted, BinMaintenanceCancelled},
+	BinMaintenanceInProgress: {BinMaintenanceCompleted, BinMaintenanceCancelled},
+}
+
+// IsValid returns true if the status is a known maintenance status
+func (s BinMaintenanceStatus) IsValid() bool {
+	switch s {
+	case BinMaintenanceScheduled, BinMaintenanceInProgress, BinMaintenanceCompleted, BinMaintenanceCancelled:
+		return true
+	}
+	return false
+}
+
+// BinMaintenance is work on a bin that takes it out of service while in progress
+type BinMaintenance struct {
+	ID           uuid.UUID            `db:"id" json:"id"`
+	BinID        uuid.UUID            `db:"bin_id" json:"bin_id"`
+	Type         MaintenanceType      `db:"type" json:"type"`
+	Status       BinMaintenanceStatus `db:"status" json:"status"`
+	ScheduledFor time.Time            `db:"scheduled_for" json:"scheduled_for"`
+	StartedAt    *time.Time           `db:"started_at" json:"started_at,omitempty"`
+	CompletedAt  *time.Time           `db:"completed_at" json:"completed_at,omitempty"`
+	Description  *string              `db:"description" json:"description,omitempty"`
+	Resolution   *string              `db:"resolution" json:"resolution,omitempty"`
+	ScheduledBy  *uuid.UUID           `db:"scheduled_by" json:"scheduled_by,omitempty"`
+	CompletedBy  *uuid.UUID           `db:"completed_by" json:"completed_by,omitempty"`
+	CreatedAt    time.Time            `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time            `db:"updated_at" json:"updated_at"`
+}
+
+// CanTransitionTo checks if the maintenance can move to the given status
+func (m *BinMaintenance) CanTransitionTo(newStatus BinMaintenanceStatus) bool {
+	for _, validStatus := range ValidBinMaintenanceTransitions[m.Status] {
+		if validStatus == newStatus {
+			return true
+		}
+	}
+	return false
+}
+
+// ScheduleMaintenanceRequest represents the request to schedule maintenance
+// of a bin; maintenance scheduled for now or earlier starts at once
+type ScheduleMaintenanceRequest struct {
+	Type         MaintenanceType `json:"type" binding:"required"`
+	ScheduledFor *time.Time      `json:"scheduled_for"`
+	Description  *string         `json:"description" binding:"omitempty,max=2000"`
+}
+
+// CompleteMaintenanceRequest represents the request to complete or cancel maintenance
+type CompleteMaintenanceRequest struct {
+	Resolution *string `json:"resolution" binding:"omitempty,max=2000"`
+}
+
+// MaintenanceFilter narrows maintenance listings; nil fields are ignored
+type MaintenanceFilter struct {
+	BinID  *uuid.UUID
+	Status *BinMaintenanceStatus
+	Type   *MaintenanceType
+}
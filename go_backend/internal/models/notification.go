@@ -12,14 +12,22 @@ type NotificationType string
 const (
 	NotificationTypeBinFull        NotificationType = "bin_full"
 	NotificationTypeRouteAssigned  NotificationType = "route_assigned"
+	NotificationTypeRouteUpdated   NotificationType = "route_updated"
 	NotificationTypeTaskCompleted  NotificationType = "task_completed"
 	NotificationTypeSystemAlert    NotificationType = "system_alert"
+	NotificationTypeLowBattery     NotificationType = "low_battery"
+	NotificationTypeShipmentAvailable NotificationType = "shipment_available"
+	NotificationTypeRewardCredited NotificationType = "reward_credited"
+	NotificationTypeFireHazard     NotificationType = "fire_hazard"
+	NotificationTypePickupAssigned NotificationType = "pickup_assigned"
+	NotificationTypePickupCollected NotificationType = "pickup_collected"
 )
 
-// Notification represents a notification sent to a driver
+// Notification represents a notification sent to a driver or a user
 type Notification struct {
 	ID       uuid.UUID         `db:"id" json:"id"`
 	DriverID *uuid.UUID        `db:"driver_id" json:"driver_id,omitempty"`
+	UserID   *uuid.UUID        `db:"user_id" json:"user_id,omitempty"`
 	BinID    *uuid.UUID        `db:"bin_id" json:"bin_id,omitempty"`
 	Type     NotificationType  `db:"type" json:"type"`
 	Title    string            `db:"title" json:"title"`
@@ -27,8 +35,20 @@ type Notification struct {
 	IsRead   bool              `db:"is_read" json:"is_read"`
 	SentAt   time.Time         `db:"sent_at" json:"sent_at"`
 	ReadAt   *time.Time        `db:"read_at" json:"read_at,omitempty"`
+	DeliveredAt *time.Time     `db:"delivered_at" json:"delivered_at,omitempty"` // Over a channel; nil while only in the inbox
+	AlertID         *uuid.UUID `db:"alert_id" json:"alert_id,omitempty"` // First notification of an alert that escalates until acknowledged
+	EscalationLevel int        `db:"escalation_level" json:"escalation_level"`
+	EscalatedAt     *time.Time `db:"escalated_at" json:"escalated_at,omitempty"`
+	AcknowledgedAt  *time.Time `db:"acknowledged_at" json:"acknowledged_at,omitempty"`
 }
 
+// Escalation levels of a bin alert
+const (
+	EscalationNearestDriver     = 0
+	EscalationNextNearestDriver = 1
+	EscalationDispatchers       = 2
+)
+
 // CreateNotificationRequest represents the request to create a notification
 type CreateNotificationRequest struct {
 	DriverID *uuid.UUID       `json:"driver_id"`
@@ -42,6 +62,7 @@ type CreateNotificationRequest struct {
 type NotificationResponse struct {
 	ID       uuid.UUID        `json:"id"`
 	DriverID *uuid.UUID       `json:"driver_id,omitempty"`
+	UserID   *uuid.UUID       `json:"user_id,omitempty"`
 	BinID    *uuid.UUID       `json:"bin_id,omitempty"`
 	Type     NotificationType `json:"type"`
 	Title    string           `json:"title"`
@@ -49,6 +70,10 @@ type NotificationResponse struct {
 	IsRead   bool             `json:"is_read"`
 	SentAt   time.Time        `json:"sent_at"`
 	ReadAt   *time.Time       `json:"read_at,omitempty"`
+	DeliveredAt *time.Time    `json:"delivered_at,omitempty"`
+	AlertID         *uuid.UUID `json:"alert_id,omitempty"`
+	EscalationLevel int        `json:"escalation_level"`
+	AcknowledgedAt  *time.Time `json:"acknowledged_at,omitempty"`
 }
 
 // ToResponse converts Notification to NotificationResponse
@@ -56,6 +81,7 @@ func (n *Notification) ToResponse() *NotificationResponse {
 	return &NotificationResponse{
 		ID:       n.ID,
 		DriverID: n.DriverID,
+		UserID:   n.UserID,
 		BinID:    n.BinID,
 		Type:     n.Type,
 		Title:    n.Title,
@@ -63,5 +89,9 @@ func (n *Notification) ToResponse() *NotificationResponse {
 		IsRead:   n.IsRead,
 		SentAt:   n.SentAt,
 		ReadAt:   n.ReadAt,
+		DeliveredAt: n.DeliveredAt,
+		AlertID:         n.AlertID,
+		EscalationLevel: n.EscalationLevel,
+		AcknowledgedAt:  n.AcknowledgedAt,
 	}
 }
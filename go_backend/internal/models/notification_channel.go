@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationChannel is a way notifications reach their recipient besides
+// their inbox
+type NotificationChannel string
+
+const (
+	NotificationChannelPush  NotificationChannel = "push"  // Firebase Cloud Messaging to the driver app
+	NotificationChannelSMS   NotificationChannel = "sms"   // Text message to the phone number
+	NotificationChannelEmail NotificationChannel = "email" // Email to the account address
+)
+
+// IsValid checks if the notification channel is supported
+func (c NotificationChannel) IsValid() bool {
+	switch c {
+	case NotificationChannelPush, NotificationChannelSMS, NotificationChannelEmail:
+		return true
+	}
+	return false
+}
+
+// NotificationRecipientType is the kind of account notifications are sent to
+type NotificationRecipientType string
+
+const (
+	NotificationRecipientDriver NotificationRecipientType = "driver"
+	NotificationRecipientUser   NotificationRecipientType = "user"
+)
+
+// NotificationRecipient is where a driver or user can be reached
+type NotificationRecipient struct {
+	Type     NotificationRecipientType
+	ID       uuid.UUID
+	FullName string
+	Email    string
+	Phone    *string
+	FCMToken *string
+}
+
+// Recipient returns where the driver can be reached
+func (d *Driver) Recipient() *NotificationRecipient {
+	recipient := &NotificationRecipient{
+		Type:     NotificationRecipientDriver,
+		ID:       d.ID,
+		FullName: d.FullName,
+		Email:    d.Email,
+		FCMToken: d.FCMToken,
+	}
+	if d.Phone != "" {
+		recipient.Phone = &d.Phone
+	}
+	return recipient
+}
+
+// Recipient returns where the user can be reached
+func (u *User) Recipient() *NotificationRecipient {
+	return &NotificationRecipient{
+		Type:     NotificationRecipientUser,
+		ID:       u.ID,
+		FullName: u.FullName,
+		Email:    u.Email,
+		Phone:    u.Phone,
+	}
+}
+
+// NotificationChannelPreferences are the channels a driver or user is
+// notified over, tried in order until one delivers
+type NotificationChannelPreferences struct {
+	RecipientType NotificationRecipientType `json:"recipient_type"`
+	RecipientID   uuid.UUID                 `json:"recipient_id"`
+	Channels      []NotificationChannel     `json:"channels"`
+	IsDefault     bool                      `json:"is_default"` // No preferences saved; the configured order applies
+	UpdatedAt     *time.Time                `json:"updated_at,omitempty"`
+}
+
+// SetNotificationChannelsRequest represents the request to set the channels
+// a driver or user is notified over
+type SetNotificationChannelsRequest struct {
+	Channels []NotificationChannel `json:"channels" binding:"required,min=1,max=3,unique,dive,oneof=push sms email"`
+}
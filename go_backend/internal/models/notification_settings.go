@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationTypes lists the notification types drivers can choose from
+var NotificationTypes = []NotificationType{
+	NotificationTypeBinFull,
+	NotificationTypeRouteAssigned,
+	NotificationTypeRouteUpdated,
+	NotificationTypeTaskCompleted,
+	NotificationTypeSystemAlert,
+	NotificationTypeLowBattery,
+	NotificationTypeShipmentAvailable,
+	NotificationTypeRewardCredited,
+	NotificationTypeFireHazard,
+	NotificationTypePickupAssigned,
+	NotificationTypePickupCollected,
+}
+
+// IsValid checks if the notification type is supported
+func (t NotificationType) IsValid() bool {
+	for _, known := range NotificationTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// DriverNotificationSettings is what a driver wants delivered to them over
+// their channels. Notifications they do not want, arriving in their quiet
+// hours or past their hourly limit are only saved to their inbox.
+type DriverNotificationSettings struct {
+	DriverID        uuid.UUID          `json:"driver_id"`
+	Types           []NotificationType `json:"types,omitempty"`             // Delivered types; all when empty
+	QuietHoursStart *string            `json:"quiet_hours_start,omitempty"` // HH:MM; an end before the start spans midnight
+	QuietHoursEnd   *string            `json:"quiet_hours_end,omitempty"`
+	Timezone        string             `json:"timezone"`               // IANA name of the quiet hours
+	MaxPerHour      *int               `json:"max_per_hour,omitempty"` // No limit when nil
+	IsDefault       bool               `json:"is_default"`             // No settings saved; everything is delivered
+	UpdatedAt       *time.Time         `json:"updated_at,omitempty"`
+}
+
+// UpdateDriverNotificationSettingsRequest replaces the notification settings
+// of a driver; omitted fields lift the restriction
+type UpdateDriverNotificationSettingsRequest struct {
+	Types           []NotificationType `json:"types" binding:"omitempty,unique"`
+	QuietHoursStart *string            `json:"quiet_hours_start" binding:"required_with=QuietHoursEnd"`
+	QuietHoursEnd   *string            `json:"quiet_hours_end" binding:"required_with=QuietHoursStart"`
+	Timezone        string             `json:"timezone"` // IANA name, defaults to UTC
+	MaxPerHour      *int               `json:"max_per_hour" binding:"omitempty,min=1,max=1000"`
+}
+
+// Wants returns true if the driver wants notifications of the type delivered
+func (s *DriverNotificationSettings) Wants(notificationType NotificationType) bool {
+	if len(s.Types) == 0 {
+		return true
+	}
+	for _, wanted := range s.Types {
+		if wanted == notificationType {
+			return true
+		}
+	}
+	return false
+}
+
+// InQuietHours returns true if at falls in the driver's quiet hours
+func (s *DriverNotificationSettings) InQuietHours(at time.Time) bool {
+	if s.QuietHoursStart == nil || s.QuietHoursEnd == nil {
+		return false
+	}
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	// HH:MM strings compare in clock order
+	clock := at.In(location).Format(ShiftTimeLayout)
+	start, end := *s.QuietHoursStart, *s.QuietHoursEnd
+	if start < end {
+		return clock >= start && clock < end
+	}
+	return clock >= start || clock < end
+}
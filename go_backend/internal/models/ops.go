@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OpsLive is the state of operations the real-time operations screen shows:
+// the drivers at work, their routes underway, the bins that need collecting
+// and the bin alerts nobody acknowledged
+type OpsLive struct {
+	GeneratedAt time.Time    `json:"generated_at"`
+	Drivers     []LiveDriver `json:"drivers"`
+	Routes      []LiveRoute  `json:"routes"`
+	Bins        []LiveBin    `json:"bins"`
+	Alerts      []LiveAlert  `json:"alerts"`
+}
+
+// LiveDriver is a driver available on shift or on a route, with their last
+// reported position
+type LiveDriver struct {
+	ID          uuid.UUID  `db:"id" json:"id"`
+	FullName    string     `db:"full_name" json:"full_name"`
+	Latitude    *float64   `db:"latitude" json:"latitude,omitempty"`
+	Longitude   *float64   `db:"longitude" json:"longitude,omitempty"`
+	IsAvailable bool       `db:"is_available" json:"is_available"`
+	OnShift     bool       `db:"on_shift" json:"on_shift"`
+	VehicleID   *uuid.UUID `db:"vehicle_id" json:"vehicle_id,omitempty"`
+	RouteID     *uuid.UUID `db:"route_id" json:"route_id,omitempty"` // Route in progress
+}
+
+// LiveRoute is a route in progress and how far along it the driver is
+type LiveRoute struct {
+	ID              uuid.UUID  `json:"id"`
+	DriverID        uuid.UUID  `json:"driver_id"`
+	DriverName      string     `json:"driver_name"`
+	StartedAt       *time.Time `json:"started_at,omitempty"`
+	Stops           int        `json:"stops"`
+	CompletedStops  int        `json:"completed_stops"`
+	TotalDistanceKm *float64   `json:"total_distance_km,omitempty"`
+}
+
+// LiveBin is a bin in service at or above its collection threshold, with the
+// open collection of it if one was assigned
+type LiveBin struct {
+	ID                  uuid.UUID         `db:"id" json:"id"`
+	DeviceID            string            `db:"device_id" json:"device_id"`
+	LocationName        *string           `db:"location_name" json:"location_name,omitempty"`
+	Latitude            float64           `db:"latitude" json:"latitude"`
+	Longitude           float64           `db:"longitude" json:"longitude"`
+	FillLevel           int               `db:"fill_level" json:"fill_level"`
+	CollectionThreshold int               `db:"collection_threshold" json:"collection_threshold"`
+	AlertThreshold      int               `db:"alert_threshold" json:"alert_threshold"`
+	CollectionID        *uuid.UUID        `db:"collection_id" json:"collection_id,omitempty"`
+	CollectionDriverID  *uuid.UUID        `db:"collection_driver_id" json:"collection_driver_id,omitempty"`
+	CollectionStatus    *CollectionStatus `db:"collection_status" json:"collection_status,omitempty"`
+}
+
+// LiveAlert is a bin alert raised recently that none of its recipients
+// acknowledged
+type LiveAlert struct {
+	AlertID         uuid.UUID        `db:"alert_id" json:"alert_id"`
+	BinID           uuid.UUID        `db:"bin_id" json:"bin_id"`
+	DeviceID        string           `db:"device_id" json:"device_id"`
+	Type            NotificationType `db:"type" json:"type"`
+	EscalationLevel int              `db:"escalation_level" json:"escalation_level"` // Highest level reached
+	Recipients      int              `db:"recipients" json:"recipients"`
+	RaisedAt        time.Time        `db:"raised_at" json:"raised_at"`
+	LastSentAt      time.Time        `db:"last_sent_at" json:"last_sent_at"`
+}
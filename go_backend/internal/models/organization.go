@@ -0,0 +1,62 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultOrganizationID identifies the organization existing data was moved to
+// when organizations were introduced. Its admins manage the other organizations.
+var DefaultOrganizationID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+// DefaultOrganizationSlug is the slug of the default organization
+const DefaultOrganizationSlug = "default"
+
+// Organization is a tenant of the deployment, typically a city. Users,
+// drivers, bins, collections and API keys belong to one organization and are
+// only visible within it.
+type Organization struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	Name      string    `db:"name" json:"name"`
+	Slug      string    `db:"slug" json:"slug"`
+	IsActive  bool      `db:"is_active" json:"is_active"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// CreateOrganizationRequest represents the request to create an organization
+// together with its first admin account
+type CreateOrganizationRequest struct {
+	Name          string `json:"name" binding:"required,max=100"`
+	Slug          string `json:"slug" binding:"required,max=50"`
+	AdminEmail    string `json:"admin_email" binding:"required,email"`
+	AdminPassword string `json:"admin_password" binding:"required,min=8"`
+	AdminFullName string `json:"admin_full_name" binding:"required"`
+}
+
+// organizationSlug matches lowercase words of letters and digits joined by dashes
+var organizationSlug = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Validate checks the slug, which names the organization at registration
+func (r *CreateOrganizationRequest) Validate() error {
+	if !organizationSlug.MatchString(r.Slug) {
+		return errors.New("slug must be lowercase letters and digits separated by dashes")
+	}
+	return nil
+}
+
+// UpdateOrganizationRequest represents the request to update an organization;
+// members of a deactivated organization can no longer log in
+type UpdateOrganizationRequest struct {
+	Name     *string `json:"name" binding:"omitempty,max=100"`
+	IsActive *bool   `json:"is_active"`
+}
+
+// CreateOrganizationResponse carries a new organization and its first admin
+type CreateOrganizationResponse struct {
+	Organization Organization  `json:"organization"`
+	Admin        *UserResponse `json:"admin"`
+}
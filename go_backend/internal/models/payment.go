@@ -0,0 +1,108 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PaymentKind is what a payment moves money for
+type PaymentKind string
+
+const (
+	PaymentCharge PaymentKind = "charge" // Taken from a company for collection services
+	PaymentPayout PaymentKind = "payout" // Sent to a driver or a user
+)
+
+// PaymentStatus is the status of a payment
+type PaymentStatus string
+
+const (
+	PaymentPending    PaymentStatus = "pending"    // Recorded, the provider did not answer yet
+	PaymentProcessing PaymentStatus = "processing" // Accepted, the provider settles it later
+	PaymentSucceeded  PaymentStatus = "succeeded"
+	PaymentFailed     PaymentStatus = "failed"
+)
+
+// IsValid checks if the payment status is supported
+func (s PaymentStatus) IsValid() bool {
+	switch s {
+	case PaymentPending, PaymentProcessing, PaymentSucceeded, PaymentFailed:
+		return true
+	}
+	return false
+}
+
+// PaymentOwnerType is the kind of record a payment account belongs to
+type PaymentOwnerType string
+
+const (
+	PaymentOwnerCompany PaymentOwnerType = "company"
+	PaymentOwnerDriver  PaymentOwnerType = "driver"
+	PaymentOwnerUser    PaymentOwnerType = "user"
+)
+
+// PaymentAccount is the provider account a company is charged from, or a
+// driver or user paid out to
+type PaymentAccount struct {
+	OwnerType     PaymentOwnerType `db:"owner_type" json:"owner_type"`
+	OwnerID       uuid.UUID        `db:"owner_id" json:"owner_id"`
+	Provider      string           `db:"provider" json:"provider"`
+	AccountID     string           `db:"account_id" json:"account_id"`                   // Customer of a company, connected account of a payee
+	PaymentMethod *string          `db:"payment_method" json:"payment_method,omitempty"` // Saved payment method companies are charged with
+	CreatedAt     time.Time        `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time        `db:"updated_at" json:"updated_at"`
+}
+
+// SetPaymentAccountRequest represents the request to set a payment account
+type SetPaymentAccountRequest struct {
+	AccountID     string  `json:"account_id" binding:"required,max=255"`
+	PaymentMethod *string `json:"payment_method" binding:"omitempty,max=255"`
+}
+
+// Payment is a charge of a company or a payout to a driver or user
+type Payment struct {
+	ID             uuid.UUID     `db:"id" json:"id"`
+	OrganizationID uuid.UUID     `db:"organization_id" json:"organization_id"`
+	Kind           PaymentKind   `db:"kind" json:"kind"`
+	CompanyID      *uuid.UUID    `db:"company_id" json:"company_id,omitempty"`
+	DriverID       *uuid.UUID    `db:"driver_id" json:"driver_id,omitempty"`
+	UserID         *uuid.UUID    `db:"user_id" json:"user_id,omitempty"`
+	Amount         float64       `db:"amount" json:"amount"`
+	Currency       string        `db:"currency" json:"currency"`
+	Description    *string       `db:"description" json:"description,omitempty"`
+	Status         PaymentStatus `db:"status" json:"status"`
+	Provider       string        `db:"provider" json:"provider"`
+	Reference      *string       `db:"reference" json:"reference,omitempty"`
+	FailureReason  *string       `db:"failure_reason" json:"failure_reason,omitempty"`
+	CreatedBy      *uuid.UUID    `db:"created_by" json:"created_by,omitempty"`
+	CreatedAt      time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time     `db:"updated_at" json:"updated_at"`
+}
+
+// CreateChargeRequest represents the request to charge a company
+type CreateChargeRequest struct {
+	CompanyID   uuid.UUID `json:"company_id" binding:"required"`
+	Amount      float64   `json:"amount" binding:"required,gt=0"`
+	Currency    *string   `json:"currency" binding:"omitempty,len=3"`
+	Description *string   `json:"description" binding:"omitempty,max=500"`
+}
+
+// CreatePayoutRequest represents the request to pay out a driver or a user;
+// exactly one of them is set
+type CreatePayoutRequest struct {
+	DriverID    *uuid.UUID `json:"driver_id" binding:"required_without=UserID,excluded_with=UserID"`
+	UserID      *uuid.UUID `json:"user_id" binding:"required_without=DriverID"`
+	Amount      float64    `json:"amount" binding:"required,gt=0"`
+	Currency    *string    `json:"currency" binding:"omitempty,len=3"`
+	Description *string    `json:"description" binding:"omitempty,max=500"`
+}
+
+// PaymentFilter holds the filters of a payment listing
+type PaymentFilter struct {
+	Kind      *PaymentKind
+	Status    *PaymentStatus
+	CompanyID *uuid.UUID
+	DriverID  *uuid.UUID
+	UserID    *uuid.UUID
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PickupStatus is where an on-demand pickup request is in its lifecycle
+type PickupStatus string
+
+const (
+	PickupStatusPending   PickupStatus = "pending"
+	PickupStatusAssigned  PickupStatus = "assigned"
+	PickupStatusCollected PickupStatus = "collected"
+	PickupStatusCancelled PickupStatus = "cancelled"
+)
+
+// IsValid returns true if the status is a known pickup status
+func (s PickupStatus) IsValid() bool {
+	switch s {
+	case PickupStatusPending, PickupStatusAssigned, PickupStatusCollected, PickupStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// IsOpen returns true while the pickup is still to be collected
+func (s PickupStatus) IsOpen() bool {
+	return s == PickupStatusPending || s == PickupStatusAssigned
+}
+
+// PhotoURLs is a list of photo URLs stored in a JSONB column
+type PhotoURLs []string
+
+// Scan reads the photo URLs from a JSONB column
+func (p *PhotoURLs) Scan(src interface{}) error {
+	return scanJSON(src, p)
+}
+
+// Value writes the photo URLs to a JSONB column
+func (p PhotoURLs) Value() (driver.Value, error) {
+	if p == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(p)
+}
+
+// PickupRequest is an on-demand pickup of bulky or recyclable waste requested
+// by a citizen. It is valued at the estimated weight when filed and priced at
+// the weight the driver collected, which the shipment of the waste is offered at.
+type PickupRequest struct {
+	ID                uuid.UUID    `db:"id" json:"id"`
+	OrganizationID    uuid.UUID    `db:"organization_id" json:"organization_id"`
+	UserID            uuid.UUID    `db:"user_id" json:"user_id"`
+	WasteType         string       `db:"waste_type" json:"waste_type"`
+	EstimatedWeightKg float64      `db:"estimated_weight_kg" json:"estimated_weight_kg"`
+	WeightKg          *float64     `db:"weight_kg" json:"weight_kg,omitempty"` // Weight the driver collected
+	PhotoURLs         PhotoURLs    `db:"photo_urls" json:"photo_urls"`
+	Latitude          float64      `db:"latitude" json:"latitude"`
+	Longitude         float64      `db:"longitude" json:"longitude"`
+	Address           *string      `db:"address" json:"address,omitempty"`
+	Notes             *string      `db:"notes" json:"notes,omitempty"`
+	ZoneID            *uuid.UUID   `db:"zone_id" json:"zone_id,omitempty"`
+	EstimatedValue    *float64     `db:"estimated_value" json:"estimated_value,omitempty"`
+	PriceOffered      *float64     `db:"price_offered" json:"price_offered,omitempty"`
+	Currency          *string      `db:"currency" json:"currency,omitempty"`
+	Status            PickupStatus `db:"status" json:"status"`
+	DriverID          *uuid.UUID   `db:"driver_id" json:"driver_id,omitempty"`
+	ShipmentID        *uuid.UUID   `db:"shipment_id" json:"shipment_id,omitempty"`
+	ShipmentAttempts  int          `db:"shipment_attempts" json:"-"`
+	ShipmentError     *string      `db:"shipment_error" json:"shipment_error,omitempty"`
+	AssignedAt        *time.Time   `db:"assigned_at" json:"assigned_at,omitempty"`
+	CollectedAt       *time.Time   `db:"collected_at" json:"collected_at,omitempty"`
+	CancelledAt       *time.Time   `db:"cancelled_at" json:"cancelled_at,omitempty"`
+	CreatedAt         time.Time    `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time    `db:"updated_at" json:"updated_at"`
+}
+
+// CreatePickupRequestRequest represents the request to ask for a pickup. The
+// photos are uploads of the requester.
+type CreatePickupRequestRequest struct {
+	WasteType         string   `json:"waste_type" binding:"required,waste_type"`
+	EstimatedWeightKg float64  `json:"estimated_weight_kg" binding:"required,gt=0"`
+	PhotoURLs         []string `json:"photo_urls" binding:"max=5,dive,url"`
+	Latitude          float64  `json:"latitude" binding:"required,latitude"`
+	Longitude         float64  `json:"longitude" binding:"required,longitude"`
+	Address           *string  `json:"address" binding:"omitempty,max=500"`
+	Notes             *string  `json:"notes" binding:"omitempty,max=2000"`
+}
+
+// AssignPickupRequest represents the request to dispatch a pickup; without a
+// driver the nearest available one covering its location is assigned
+type AssignPickupRequest struct {
+	DriverID *uuid.UUID `json:"driver_id"`
+}
+
+// CompletePickupRequest represents the request to record a collected pickup
+type CompletePickupRequest struct {
+	WeightKg float64 `json:"weight_kg" binding:"required,gt=0"`
+	Notes    *string `json:"notes" binding:"omitempty,max=2000"`
+}
+
+// PickupRequestFilter narrows pickup request listings; nil fields are ignored
+type PickupRequestFilter struct {
+	UserID   *uuid.UUID
+	DriverID *uuid.UUID
+	Status   *PickupStatus
+}
@@ -1,76 +1,397 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/smartwaste/pkg/validation"
 )
 
-// PricingRule represents a pricing rule for waste valuation
+// ConditionAny is the condition of rules matching every condition without a rule of its own
+const ConditionAny = "any"
+
+// PricingModel selects the strategy a pricing rule values waste with
+type PricingModel string
+
+const (
+	PricingModelFlat   PricingModel = "flat"   // price_per_kg for every kg
+	PricingModelTiered PricingModel = "tiered" // Weight brackets priced in turn
+	PricingModelMarket PricingModel = "market" // A share of the latest market price
+)
+
+// IsValid returns true if the model is a known pricing model
+func (m PricingModel) IsValid() bool {
+	switch m {
+	case PricingModelFlat, PricingModelTiered, PricingModelMarket:
+		return true
+	}
+	return false
+}
+
+// PriceTier prices the weight of a bracket ending at UpToKg
+type PriceTier struct {
+	UpToKg     float64 `json:"up_to_kg"`
+	PricePerKg float64 `json:"price_per_kg"`
+}
+
+// PriceTiers are the weight brackets of a tiered rule, in ascending order
+type PriceTiers []PriceTier
+
+// Validate checks that the brackets ascend and their prices are not negative
+func (t PriceTiers) Validate() error {
+	previous := 0.0
+	for i, tier := range t {
+		if tier.UpToKg <= previous {
+			return fmt.Errorf("tiers[%d].up_to_kg must be above %.2f", i, previous)
+		}
+		if tier.PricePerKg < 0 {
+			return fmt.Errorf("tiers[%d].price_per_kg cannot be negative", i)
+		}
+		previous = tier.UpToKg
+	}
+	return nil
+}
+
+// Scan reads the tiers from a JSONB column
+func (t *PriceTiers) Scan(src interface{}) error {
+	return scanJSON(src, t)
+}
+
+// Value writes the tiers to a JSONB column
+func (t PriceTiers) Value() (driver.Value, error) {
+	if t == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(t)
+}
+
+// ConditionMultipliers scale the price of a rule per waste condition
+type ConditionMultipliers map[string]float64
+
+// Validate checks that every multiplier is positive
+func (m ConditionMultipliers) Validate() error {
+	for condition, multiplier := range m {
+		if multiplier <= 0 {
+			return fmt.Errorf("condition_multipliers.%s must be positive", condition)
+		}
+	}
+	return nil
+}
+
+// Scan reads the multipliers from a JSONB column
+func (m *ConditionMultipliers) Scan(src interface{}) error {
+	return scanJSON(src, m)
+}
+
+// Value writes the multipliers to a JSONB column
+func (m ConditionMultipliers) Value() (driver.Value, error) {
+	if m == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(m)
+}
+
+// scanJSON decodes a JSONB column into dest
+func scanJSON(src interface{}, dest interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, dest)
+	case string:
+		return json.Unmarshal([]byte(v), dest)
+	}
+	return errors.New("unsupported JSON column type")
+}
+
+// PricingRule represents a version of a pricing rule for waste valuation,
+// in effect from EffectiveFrom until EffectiveTo. PricePerKg is the flat
+// price, the price above the last tier of tiered rules and the fallback of
+// market rules without a market price.
 type PricingRule struct {
-	ID          uuid.UUID  `db:"id" json:"id"`
-	WasteType   string     `db:"waste_type" json:"waste_type"`
-	Condition   string     `db:"condition" json:"condition"`
-	PricePerKg  float64    `db:"price_per_kg" json:"price_per_kg"`
-	Currency    string     `db:"currency" json:"currency"`
-	MinWeightKg float64    `db:"min_weight_kg" json:"min_weight_kg"`
-	MaxWeightKg *float64   `db:"max_weight_kg" json:"max_weight_kg,omitempty"`
-	CompanyID   *uuid.UUID `db:"company_id" json:"company_id,omitempty"`
-	IsActive    bool       `db:"is_active" json:"is_active"`
-	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
-	UpdatedAt   time.Time  `db:"updated_at" json:"updated_at"`
+	ID                   uuid.UUID            `db:"id" json:"id"`
+	LineageID            uuid.UUID            `db:"lineage_id" json:"lineage_id"`
+	Version              int                  `db:"version" json:"version"`
+	WasteType            string               `db:"waste_type" json:"waste_type"`
+	Condition            string               `db:"condition" json:"condition"`
+	PricePerKg           float64              `db:"price_per_kg" json:"price_per_kg"`
+	Currency             string               `db:"currency" json:"currency"`
+	MinWeightKg          float64              `db:"min_weight_kg" json:"min_weight_kg"`
+	MaxWeightKg          *float64             `db:"max_weight_kg" json:"max_weight_kg,omitempty"`
+	CompanyID            *uuid.UUID           `db:"company_id" json:"company_id,omitempty"`
+	PricingModel         PricingModel         `db:"pricing_model" json:"pricing_model"`
+	Tiers                PriceTiers           `db:"tiers" json:"tiers"`
+	ConditionMultipliers ConditionMultipliers `db:"condition_multipliers" json:"condition_multipliers"`
+	MarketFactor         float64              `db:"market_factor" json:"market_factor"`
+	EffectiveFrom        time.Time            `db:"effective_from" json:"effective_from"`
+	EffectiveTo          *time.Time           `db:"effective_to" json:"effective_to,omitempty"`
+	IsActive             bool                 `db:"is_active" json:"is_active"`
+	CreatedAt            time.Time            `db:"created_at" json:"created_at"`
+	UpdatedAt            time.Time            `db:"updated_at" json:"updated_at"`
+}
+
+// InEffect reports whether the version applies at the given time
+func (p *PricingRule) InEffect(at time.Time) bool {
+	return !p.EffectiveFrom.After(at) && (p.EffectiveTo == nil || p.EffectiveTo.After(at))
+}
+
+// ValidatePeriod checks that the version ends after it starts
+func (p *PricingRule) ValidatePeriod() error {
+	if p.EffectiveTo != nil && !p.EffectiveTo.After(p.EffectiveFrom) {
+		return errors.New("effective_to must be after effective_from")
+	}
+	return nil
+}
+
+// Accepts reports whether the rule values the weight
+func (p *PricingRule) Accepts(weightKg float64) bool {
+	return weightKg >= p.MinWeightKg && (p.MaxWeightKg == nil || weightKg <= *p.MaxWeightKg)
+}
+
+// SamePricing reports whether two versions price waste identically over the
+// same period
+func (p *PricingRule) SamePricing(o *PricingRule) bool {
+	sameMax := (p.MaxWeightKg == nil && o.MaxWeightKg == nil) ||
+		(p.MaxWeightKg != nil && o.MaxWeightKg != nil && *p.MaxWeightKg == *o.MaxWeightKg)
+	sameTo := (p.EffectiveTo == nil && o.EffectiveTo == nil) ||
+		(p.EffectiveTo != nil && o.EffectiveTo != nil && p.EffectiveTo.Equal(*o.EffectiveTo))
+	return p.PricePerKg == o.PricePerKg && p.Currency == o.Currency && p.MinWeightKg == o.MinWeightKg &&
+		sameMax && sameTo && p.PricingModel == o.PricingModel && p.MarketFactor == o.MarketFactor &&
+		(len(p.Tiers) == 0 && len(o.Tiers) == 0 || reflect.DeepEqual(p.Tiers, o.Tiers)) &&
+		(len(p.ConditionMultipliers) == 0 && len(o.ConditionMultipliers) == 0 ||
+			reflect.DeepEqual(p.ConditionMultipliers, o.ConditionMultipliers))
+}
+
+// CompanyPricingRule is a company's pricing rule with the company's name
+type CompanyPricingRule struct {
+	PricingRule
+	CompanyName string `db:"company_name"`
+}
+
+// ValidateStrategy checks that the rule has what its pricing model needs
+func (p *PricingRule) ValidateStrategy() error {
+	if !p.PricingModel.IsValid() {
+		return errors.New("pricing_model must be flat, tiered or market")
+	}
+	if err := p.Tiers.Validate(); err != nil {
+		return err
+	}
+	if p.PricingModel == PricingModelTiered && len(p.Tiers) == 0 {
+		return errors.New("tiered rules need at least one tier")
+	}
+	if p.MarketFactor <= 0 {
+		return errors.New("market_factor must be positive")
+	}
+	return p.ConditionMultipliers.Validate()
 }
 
 // CreatePricingRuleRequest represents the request to create a pricing rule
 type CreatePricingRuleRequest struct {
-	WasteType   string     `json:"waste_type" binding:"required"`
-	Condition   string     `json:"condition" binding:"required"`
-	PricePerKg  float64    `json:"price_per_kg" binding:"required,gt=0"`
-	Currency    string     `json:"currency" binding:"required,len=3"`
-	MinWeightKg float64    `json:"min_weight_kg"`
-	MaxWeightKg *float64   `json:"max_weight_kg"`
-	CompanyID   *uuid.UUID `json:"company_id"`
+	WasteType            string               `json:"waste_type" binding:"required,waste_type"`
+	Condition            string               `json:"condition" binding:"required"`
+	PricePerKg           float64              `json:"price_per_kg" binding:"required,gt=0"`
+	Currency             string               `json:"currency" binding:"required,len=3"`
+	MinWeightKg          float64              `json:"min_weight_kg"`
+	MaxWeightKg          *float64             `json:"max_weight_kg"`
+	CompanyID            *uuid.UUID           `json:"company_id"`
+	PricingModel         PricingModel         `json:"pricing_model"`
+	Tiers                PriceTiers           `json:"tiers"`
+	ConditionMultipliers ConditionMultipliers `json:"condition_multipliers"`
+	MarketFactor         *float64             `json:"market_factor"`
+	EffectiveFrom        *time.Time           `json:"effective_from"`
+	EffectiveTo          *time.Time           `json:"effective_to"`
 }
 
 // UpdatePricingRuleRequest represents the request to update a pricing rule
 type UpdatePricingRuleRequest struct {
-	WasteType   *string  `json:"waste_type"`
-	Condition   *string  `json:"condition"`
-	PricePerKg  *float64 `json:"price_per_kg"`
-	Currency    *string  `json:"currency"`
-	MinWeightKg *float64 `json:"min_weight_kg"`
-	MaxWeightKg *float64 `json:"max_weight_kg"`
-	IsActive    *bool    `json:"is_active"`
+	WasteType            *string              `json:"waste_type" binding:"omitempty,waste_type"`
+	Condition            *string              `json:"condition"`
+	PricePerKg           *float64             `json:"price_per_kg"`
+	Currency             *string              `json:"currency"`
+	MinWeightKg          *float64             `json:"min_weight_kg"`
+	MaxWeightKg          *float64             `json:"max_weight_kg"`
+	PricingModel         *PricingModel        `json:"pricing_model"`
+	Tiers                PriceTiers           `json:"tiers"`
+	ConditionMultipliers ConditionMultipliers `json:"condition_multipliers"`
+	MarketFactor         *float64             `json:"market_factor"`
+	EffectiveFrom        *time.Time           `json:"effective_from"`
+	EffectiveTo          *time.Time           `json:"effective_to"`
+	IsActive             *bool                `json:"is_active"`
+}
+
+// BulkPricingRulesRequest represents the request to upsert a company's
+// pricing rules. Each row fully defines the rule of its waste type and
+// condition; fields left out take their defaults.
+type BulkPricingRulesRequest struct {
+	Rules []CreatePricingRuleRequest `json:"rules" binding:"required,min=1,max=500"`
+}
+
+// BulkRuleStatus is the outcome of one row of a bulk pricing rule upsert
+type BulkRuleStatus string
+
+const (
+	BulkRuleCreated   BulkRuleStatus = "created"
+	BulkRuleUpdated   BulkRuleStatus = "updated"
+	BulkRuleUnchanged BulkRuleStatus = "unchanged"
+	BulkRuleFailed    BulkRuleStatus = "failed"
+)
+
+// BulkPricingRuleResult reports the outcome of one row of a bulk upsert
+type BulkPricingRuleResult struct {
+	Index     int                  `json:"index"` // 0-based position in the request's rules
+	WasteType string               `json:"waste_type"`
+	Condition string               `json:"condition"`
+	Status    BulkRuleStatus       `json:"status"`
+	Rule      *PricingRuleResponse `json:"rule,omitempty"`
+	Error     string               `json:"error,omitempty"`
+	// Fields details the failed fields of a row that failed validation
+	Fields []validation.FieldError `json:"fields,omitempty"`
+}
+
+// BulkPricingRulesResult reports the outcome of a bulk upsert; nothing is
+// applied unless every row is valid
+type BulkPricingRulesResult struct {
+	DryRun    bool                    `json:"dry_run"`
+	Applied   bool                    `json:"applied"`
+	Total     int                     `json:"total"`
+	Created   int                     `json:"created"`
+	Updated   int                     `json:"updated"`
+	Unchanged int                     `json:"unchanged"`
+	Failed    int                     `json:"failed"`
+	Results   []BulkPricingRuleResult `json:"results"`
+}
+
+// PricingRuleChange is one write of a bulk upsert: Next is a new rule when
+// Current is nil, and otherwise revises Current, in place when InPlace
+type PricingRuleChange struct {
+	Current *PricingRule
+	Next    *PricingRule
+	InPlace bool
 }
 
 // PricingRuleResponse represents the API response for a pricing rule
 type PricingRuleResponse struct {
-	ID          uuid.UUID  `json:"id"`
-	WasteType   string     `json:"waste_type"`
-	Condition   string     `json:"condition"`
-	PricePerKg  float64    `json:"price_per_kg"`
-	Currency    string     `json:"currency"`
-	MinWeightKg float64    `json:"min_weight_kg"`
-	MaxWeightKg *float64   `json:"max_weight_kg,omitempty"`
-	CompanyID   *uuid.UUID `json:"company_id,omitempty"`
-	IsActive    bool       `json:"is_active"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID                   uuid.UUID            `json:"id"`
+	LineageID            uuid.UUID            `json:"lineage_id"`
+	Version              int                  `json:"version"`
+	WasteType            string               `json:"waste_type"`
+	Condition            string               `json:"condition"`
+	PricePerKg           float64              `json:"price_per_kg"`
+	Currency             string               `json:"currency"`
+	MinWeightKg          float64              `json:"min_weight_kg"`
+	MaxWeightKg          *float64             `json:"max_weight_kg,omitempty"`
+	CompanyID            *uuid.UUID           `json:"company_id,omitempty"`
+	PricingModel         PricingModel         `json:"pricing_model"`
+	Tiers                PriceTiers           `json:"tiers,omitempty"`
+	ConditionMultipliers ConditionMultipliers `json:"condition_multipliers,omitempty"`
+	MarketFactor         float64              `json:"market_factor"`
+	EffectiveFrom        time.Time            `json:"effective_from"`
+	EffectiveTo          *time.Time           `json:"effective_to,omitempty"`
+	IsActive             bool                 `json:"is_active"`
+	CreatedAt            time.Time            `json:"created_at"`
+	UpdatedAt            time.Time            `json:"updated_at"`
 }
 
 // ToResponse converts PricingRule to PricingRuleResponse
 func (p *PricingRule) ToResponse() *PricingRuleResponse {
 	return &PricingRuleResponse{
-		ID:          p.ID,
-		WasteType:   p.WasteType,
-		Condition:   p.Condition,
-		PricePerKg:  p.PricePerKg,
-		Currency:    p.Currency,
-		MinWeightKg: p.MinWeightKg,
-		MaxWeightKg: p.MaxWeightKg,
-		CompanyID:   p.CompanyID,
-		IsActive:    p.IsActive,
-		CreatedAt:   p.CreatedAt,
-		UpdatedAt:   p.UpdatedAt,
+		ID:                   p.ID,
+		LineageID:            p.LineageID,
+		Version:              p.Version,
+		WasteType:            p.WasteType,
+		Condition:            p.Condition,
+		PricePerKg:           p.PricePerKg,
+		Currency:             p.Currency,
+		MinWeightKg:          p.MinWeightKg,
+		MaxWeightKg:          p.MaxWeightKg,
+		CompanyID:            p.CompanyID,
+		PricingModel:         p.PricingModel,
+		Tiers:                p.Tiers,
+		ConditionMultipliers: p.ConditionMultipliers,
+		MarketFactor:         p.MarketFactor,
+		EffectiveFrom:        p.EffectiveFrom,
+		EffectiveTo:          p.EffectiveTo,
+		IsActive:             p.IsActive,
+		CreatedAt:            p.CreatedAt,
+		UpdatedAt:            p.UpdatedAt,
 	}
 }
+
+// PricingPromotion is a time-limited rate multiplying the valuations of a
+// company's rules, a waste type, or both; nil scopes match everything
+type PricingPromotion struct {
+	ID         uuid.UUID  `db:"id" json:"id"`
+	Name       string     `db:"name" json:"name"`
+	CompanyID  *uuid.UUID `db:"company_id" json:"company_id,omitempty"`
+	WasteType  *string    `db:"waste_type" json:"waste_type,omitempty"`
+	Multiplier float64    `db:"multiplier" json:"multiplier"`
+	StartsAt   time.Time  `db:"starts_at" json:"starts_at"`
+	EndsAt     time.Time  `db:"ends_at" json:"ends_at"`
+	IsActive   bool       `db:"is_active" json:"is_active"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// CreatePromotionRequest represents the request to create a pricing promotion
+type CreatePromotionRequest struct {
+	Name       string     `json:"name" binding:"required,max=100"`
+	CompanyID  *uuid.UUID `json:"company_id"`
+	WasteType  *string    `json:"waste_type" binding:"omitempty,waste_type"`
+	Multiplier float64    `json:"multiplier" binding:"required,gt=0"`
+	StartsAt   time.Time  `json:"starts_at" binding:"required"`
+	EndsAt     time.Time  `json:"ends_at" binding:"required,gtfield=StartsAt"`
+}
+
+// UpdatePromotionRequest represents the request to update a pricing promotion
+type UpdatePromotionRequest struct {
+	Name       *string    `json:"name" binding:"omitempty,max=100"`
+	Multiplier *float64   `json:"multiplier" binding:"omitempty,gt=0"`
+	StartsAt   *time.Time `json:"starts_at"`
+	EndsAt     *time.Time `json:"ends_at"`
+	IsActive   *bool      `json:"is_active"`
+}
+
+// MarketPrice is the market price of a recovered material from EffectiveAt on
+type MarketPrice struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	WasteType   string    `db:"waste_type" json:"waste_type"`
+	Currency    string    `db:"currency" json:"currency"`
+	PricePerKg  float64   `db:"price_per_kg" json:"price_per_kg"`
+	Source      *string   `db:"source" json:"source,omitempty"`
+	EffectiveAt time.Time `db:"effective_at" json:"effective_at"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+}
+
+// CreateMarketPriceRequest represents the request to record a market price
+type CreateMarketPriceRequest struct {
+	WasteType   string     `json:"waste_type" binding:"required,waste_type"`
+	Currency    string     `json:"currency" binding:"required,len=3"`
+	PricePerKg  float64    `json:"price_per_kg" binding:"min=0"`
+	Source      *string    `json:"source" binding:"omitempty,max=100"`
+	EffectiveAt *time.Time `json:"effective_at"`
+}
+
+// QuoteLineKind is the step of a valuation a quote line comes from
+type QuoteLineKind string
+
+const (
+	QuoteLineBase      QuoteLineKind = "base"      // Weight priced by the rule's model
+	QuoteLineTier      QuoteLineKind = "tier"      // Weight of one bracket of a tiered rule
+	QuoteLineCondition QuoteLineKind = "condition" // Condition multiplier adjustment
+	QuoteLinePromotion QuoteLineKind = "promotion" // Promotional rate adjustment
+)
+
+// QuoteLine is one item of a valuation; the amounts add up to its total
+type QuoteLine struct {
+	Kind        QuoteLineKind `json:"kind"`
+	Description string        `json:"description"`
+	WeightKg    *float64      `json:"weight_kg,omitempty"`
+	PricePerKg  *float64      `json:"price_per_kg,omitempty"`
+	Multiplier  *float64      `json:"multiplier,omitempty"`
+	Amount      float64       `json:"amount"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// ProvisionDeviceRequest is sent by a sensor at its first boot, with the fleet
+// provisioning API key, to be issued a client certificate for the broker
+type ProvisionDeviceRequest struct {
+	// HardwareSerial is the serial registered on the sensor's bin
+	HardwareSerial string `json:"hardware_serial" binding:"required,max=100"`
+	// CSR is the PEM certificate signing request of the key the sensor
+	// generated; the private key never leaves the device
+	CSR string `json:"csr" binding:"required,max=8192"`
+}
+
+// ProvisionDeviceResponse carries the identity and credentials of a
+// provisioned sensor
+type ProvisionDeviceResponse struct {
+	// BinID is the device ID of the bin, the common name of the certificate
+	// and so the broker username of the sensor
+	BinID       string `json:"bin_id"`
+	Certificate string `json:"certificate"`
+	// CACertificate verifies the broker; empty when it has a publicly
+	// trusted certificate
+	CACertificate string    `json:"ca_certificate,omitempty"`
+	BrokerURL     string    `json:"broker_url,omitempty"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
@@ -0,0 +1,142 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReportKind selects the rows of an exported report
+type ReportKind string
+
+const (
+	ReportCollections ReportKind = "collections"
+	ReportBins        ReportKind = "bins"
+	ReportDrivers     ReportKind = "drivers"
+)
+
+// IsValid checks if the report kind is supported
+func (k ReportKind) IsValid() bool {
+	switch k {
+	case ReportCollections, ReportBins, ReportDrivers:
+		return true
+	}
+	return false
+}
+
+// ReportFormat is the file format of an exported report
+type ReportFormat string
+
+const (
+	ReportFormatCSV ReportFormat = "csv"
+	ReportFormatPDF ReportFormat = "pdf"
+)
+
+// IsValid checks if the format is supported
+func (f ReportFormat) IsValid() bool {
+	return f == ReportFormatCSV || f == ReportFormatPDF
+}
+
+// ContentType returns the MIME type of files in this format
+func (f ReportFormat) ContentType() string {
+	if f == ReportFormatPDF {
+		return "application/pdf"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// ReportRequest selects a report, its format and the period it covers
+type ReportRequest struct {
+	Report ReportKind
+	Format ReportFormat
+	Period AnalyticsRange
+}
+
+// Filename names the file of the report, e.g. collections-20250101-20250131.csv
+func (r ReportRequest) Filename() string {
+	// The period ends before To, so the last day covered is the day before a midnight To
+	last := r.Period.To.Add(-time.Nanosecond)
+	return string(r.Report) + "-" + r.Period.From.Format("20060102") + "-" + last.Format("20060102") + "." + string(r.Format)
+}
+
+// ExportStatus represents the progress of a background export
+type ExportStatus string
+
+const (
+	ExportStatusPending   ExportStatus = "pending"
+	ExportStatusRunning   ExportStatus = "running"
+	ExportStatusCompleted ExportStatus = "completed"
+	ExportStatusFailed    ExportStatus = "failed"
+)
+
+// ReportExport is a report generated in the background for later download
+type ReportExport struct {
+	ID             uuid.UUID    `db:"id" json:"id"`
+	OrganizationID uuid.UUID    `db:"organization_id" json:"-"`
+	Report         ReportKind   `db:"report" json:"report"`
+	Format         ReportFormat `db:"format" json:"format"`
+	PeriodFrom     time.Time    `db:"period_from" json:"from"`
+	PeriodTo       time.Time    `db:"period_to" json:"to"`
+	Status         ExportStatus `db:"status" json:"status"`
+	Rows           *int         `db:"row_count" json:"rows,omitempty"`
+	SizeBytes      *int64       `db:"size_bytes" json:"size_bytes,omitempty"`
+	Error          *string      `db:"error" json:"error,omitempty"`
+	FilePath       *string      `db:"file_path" json:"-"`
+	RequestedBy    *uuid.UUID   `db:"requested_by" json:"requested_by,omitempty"`
+	CreatedAt      time.Time    `db:"created_at" json:"created_at"`
+	CompletedAt    *time.Time   `db:"completed_at" json:"completed_at,omitempty"`
+	ExpiresAt      *time.Time   `db:"expires_at" json:"expires_at,omitempty"`
+	DownloadURL    string       `db:"-" json:"download_url,omitempty"`
+}
+
+// Request returns the report the export generates
+func (e *ReportExport) Request() ReportRequest {
+	return ReportRequest{
+		Report: e.Report,
+		Format: e.Format,
+		Period: AnalyticsRange{From: e.PeriodFrom, To: e.PeriodTo},
+	}
+}
+
+// CollectionReportRow is one collection started in the report period
+type CollectionReportRow struct {
+	ID           uuid.UUID  `db:"id"`
+	StartedAt    time.Time  `db:"started_at"`
+	CompletedAt  *time.Time `db:"completed_at"`
+	Status       string     `db:"status"`
+	BinDeviceID  string     `db:"device_id"`
+	LocationName *string    `db:"location_name"`
+	WasteType    string     `db:"waste_type"`
+	CompanyName  *string    `db:"company_name"`
+	DriverName   string     `db:"driver_name"`
+	FillBefore   int        `db:"fill_level_before"`
+	WeightKg     *float64   `db:"weight_kg"`
+}
+
+// BinReportRow summarizes one active bin over the report period
+type BinReportRow struct {
+	ID                uuid.UUID `db:"id"`
+	DeviceID          string    `db:"device_id"`
+	LocationName      *string   `db:"location_name"`
+	WasteType         string    `db:"waste_type"`
+	CompanyName       *string   `db:"company_name"`
+	FillLevel         int       `db:"fill_level"`
+	Readings          int       `db:"readings"`
+	AverageFillLevel  *float64  `db:"average_fill_level"`
+	MaxFillLevel      *int      `db:"max_fill_level"`
+	Collections       int       `db:"collections"`
+	CollectedWeightKg float64   `db:"collected_weight_kg"`
+}
+
+// DriverReportRow summarizes the collections of one driver over the report period
+type DriverReportRow struct {
+	ID                 uuid.UUID `db:"id"`
+	FullName           string    `db:"full_name"`
+	Email              string    `db:"email"`
+	VehiclePlate       *string   `db:"vehicle_plate"`
+	Collections        int       `db:"collections"`
+	Completed          int       `db:"completed"`
+	Cancelled          int       `db:"cancelled"`
+	WeightKg           float64   `db:"weight_kg"`
+	AverageDurationMin *float64  `db:"average_duration_min"`
+}
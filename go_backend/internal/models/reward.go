@@ -0,0 +1,85 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RewardRuleAnyWasteType is the waste type of the fallback earning rule
+const RewardRuleAnyWasteType = "*"
+
+// RewardSource identifies what earned a reward, or lost points
+type RewardSource string
+
+const (
+	RewardSourceCollection   RewardSource = "collection"
+	RewardSourceShipment     RewardSource = "shipment"
+	RewardSourceCancellation RewardSource = "cancellation" // penalty of a late shipment cancellation
+
+	RewardSourceCollectionReversal RewardSource = "collection_reversal" // points of a collection reopened by its saga
+)
+
+// RewardRule defines how many points a waste type earns
+type RewardRule struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	WasteType   string    `db:"waste_type" json:"waste_type"`
+	BasePoints  int       `db:"base_points" json:"base_points"`
+	PointsPerKg float64   `db:"points_per_kg" json:"points_per_kg"`
+	MinWeightKg float64   `db:"min_weight_kg" json:"min_weight_kg"`
+	MaxPoints   *int      `db:"max_points" json:"max_points,omitempty"`
+	IsActive    bool      `db:"is_active" json:"is_active"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// CreateRewardRuleRequest represents the request to create a reward rule
+type CreateRewardRuleRequest struct {
+	WasteType   string  `json:"waste_type" binding:"required,waste_type_or_any"`
+	BasePoints  int     `json:"base_points" binding:"gte=0"`
+	PointsPerKg float64 `json:"points_per_kg" binding:"gte=0"`
+	MinWeightKg float64 `json:"min_weight_kg" binding:"gte=0"`
+	MaxPoints   *int    `json:"max_points" binding:"omitempty,gt=0"`
+}
+
+// UpdateRewardRuleRequest represents the request to update a reward rule
+type UpdateRewardRuleRequest struct {
+	BasePoints  *int     `json:"base_points" binding:"omitempty,gte=0"`
+	PointsPerKg *float64 `json:"points_per_kg" binding:"omitempty,gte=0"`
+	MinWeightKg *float64 `json:"min_weight_kg" binding:"omitempty,gte=0"`
+	MaxPoints   *int     `json:"max_points" binding:"omitempty,gt=0"`
+	IsActive    *bool    `json:"is_active"`
+}
+
+// RewardTransaction is a ledger entry for automatically credited points
+type RewardTransaction struct {
+	ID           uuid.UUID    `db:"id" json:"id"`
+	UserID       uuid.UUID    `db:"user_id" json:"user_id"`
+	Points       int          `db:"points" json:"points"`
+	Source       RewardSource `db:"source" json:"source"`
+	SourceID     uuid.UUID    `db:"source_id" json:"source_id"`
+	RewardRuleID *uuid.UUID   `db:"reward_rule_id" json:"reward_rule_id,omitempty"`
+	WasteType    string       `db:"waste_type" json:"waste_type"`
+	WeightKg     *float64     `db:"weight_kg" json:"weight_kg,omitempty"`
+	CreatedAt    time.Time    `db:"created_at" json:"created_at"`
+}
+
+// PointsFor returns the points earned for weightKg of waste.
+// Unknown weights earn only the base points.
+func (r *RewardRule) PointsFor(weightKg *float64) int {
+	points := r.BasePoints
+	if weightKg != nil {
+		if *weightKg < r.MinWeightKg {
+			return 0
+		}
+		points += int(math.Floor(r.PointsPerKg * *weightKg))
+	} else if r.MinWeightKg > 0 {
+		return 0
+	}
+
+	if r.MaxPoints != nil && points > *r.MaxPoints {
+		points = *r.MaxPoints
+	}
+	return points
+}
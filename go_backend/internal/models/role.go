@@ -0,0 +1,48 @@
+package models
+
+// Role represents an access role granted to an authenticated principal
+type Role string
+
+const (
+	RoleAdmin      Role = "admin"
+	RoleDispatcher Role = "dispatcher"
+	RoleDriver     Role = "driver"
+	RoleCompany    Role = "company"
+	RoleCitizen    Role = "citizen"
+
+	// RoleDevice is only held by API keys issued to bins reporting over HTTP
+	RoleDevice Role = "device"
+)
+
+// IsValid returns true if the role is one of the known roles
+func (r Role) IsValid() bool {
+	switch r {
+	case RoleAdmin, RoleDispatcher, RoleDriver, RoleCompany, RoleCitizen:
+		return true
+	}
+	return false
+}
+
+// LoginRequest represents the request to authenticate with email and password
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+// LoginResponse represents the response for a successful login
+type LoginResponse struct {
+	AccessToken    string `json:"access_token"`
+	TokenType      string `json:"token_type"`
+	ExpiresAt      int64  `json:"expires_at"`
+	Role           Role   `json:"role"`
+	SubjectID      string `json:"subject_id"`
+	OrganizationID string `json:"organization_id"`
+	// CompanyID and CompanyRole are set for company members
+	CompanyID   string            `json:"company_id,omitempty"`
+	CompanyRole CompanyMemberRole `json:"company_role,omitempty"`
+}
+
+// UpdateRoleRequest represents the request to change a user's role
+type UpdateRoleRequest struct {
+	Role Role `json:"role" binding:"required"`
+}
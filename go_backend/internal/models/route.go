@@ -17,15 +17,53 @@ const (
 	RouteStatusCancelled  RouteStatus = "cancelled"
 )
 
+// Route optimization modes
+const (
+	RouteOptimizeDistance  = "distance"   // Nearest-neighbour order
+	RouteOptimizeFillLevel = "fill_level" // Fullest bins first
+	RouteOptimizeTwoOpt    = "two_opt"    // Nearest-neighbour improved with 2-opt
+	RouteOptimizeCapacity  = "capacity"   // 2-opt trips that fit the truck capacity
+)
+
+// RouteExportFormat is the format a route is exported in for navigation apps
+type RouteExportFormat string
+
+const (
+	RouteExportGPX        RouteExportFormat = "gpx"
+	RouteExportGoogleMaps RouteExportFormat = "gmaps"
+)
+
+// IsValid returns true if the export format is supported
+func (f RouteExportFormat) IsValid() bool {
+	return f == RouteExportGPX || f == RouteExportGoogleMaps
+}
+
+// RouteNavigation holds the Google Maps directions links of a route. Google
+// Maps takes a limited number of stops per link, so longer routes are split
+// into legs, each starting where the previous one ended.
+type RouteNavigation struct {
+	RouteID uuid.UUID `json:"route_id"`
+	Stops   int       `json:"stops"`
+	URLs    []string  `json:"urls"`
+}
+
+// RouteOptions controls how a route is optimized
+type RouteOptions struct {
+	OptimizeBy          string
+	TruckCapacityLiters int // Used by the capacity mode; the configured default if zero
+}
+
 // Waypoint represents a single point in a route
 type Waypoint struct {
-	BinID       uuid.UUID `json:"bin_id"`
-	DeviceID    string    `json:"device_id"`
-	Latitude    float64   `json:"latitude"`
-	Longitude   float64   `json:"longitude"`
-	FillLevel   int       `json:"fill_level"`
-	Order       int       `json:"order"`
-	IsCompleted bool      `json:"is_completed"`
+	BinID                 uuid.UUID `json:"bin_id"`
+	DeviceID              string    `json:"device_id"`
+	Latitude              float64   `json:"latitude"`
+	Longitude             float64   `json:"longitude"`
+	FillLevel             int       `json:"fill_level"`
+	EstimatedVolumeLiters float64   `json:"estimated_volume_liters,omitempty"`
+	Order                 int       `json:"order"`
+	Trip                  int       `json:"trip,omitempty"` // Set in capacity mode; the truck unloads between trips
+	IsCompleted           bool      `json:"is_completed"`
 }
 
 // DriverRoute represents an optimized route for a driver
@@ -35,18 +73,37 @@ type DriverRoute struct {
 	Waypoints                json.RawMessage `db:"waypoints" json:"-"`
 	WaypointsList            []Waypoint      `db:"-" json:"waypoints"`
 	TotalDistanceKm          *float64        `db:"total_distance_km" json:"total_distance_km,omitempty"`
+	Trips                    int             `db:"-" json:"trips,omitempty"`
 	EstimatedDurationMinutes *int            `db:"estimated_duration_minutes" json:"estimated_duration_minutes,omitempty"`
 	Status                   RouteStatus     `db:"status" json:"status"`
+	OptimizeBy               string          `db:"optimize_by" json:"optimize_by"`
+	TruckCapacityLiters      *int            `db:"truck_capacity_liters" json:"truck_capacity_liters,omitempty"`
 	CreatedAt                time.Time       `db:"created_at" json:"created_at"`
 	StartedAt                *time.Time      `db:"started_at" json:"started_at,omitempty"`
 	CompletedAt              *time.Time      `db:"completed_at" json:"completed_at,omitempty"`
+	// ReoptimizedAt is the last time the stops left were re-sequenced
+	ReoptimizedAt *time.Time `db:"reoptimized_at" json:"reoptimized_at,omitempty"`
+}
+
+// IsActive returns true while the route is still driven
+func (r *DriverRoute) IsActive() bool {
+	return r.Status == RouteStatusPending || r.Status == RouteStatusInProgress
+}
+
+// Options returns the options the route was optimized with
+func (r *DriverRoute) Options() RouteOptions {
+	opts := RouteOptions{OptimizeBy: r.OptimizeBy}
+	if r.TruckCapacityLiters != nil {
+		opts.TruckCapacityLiters = *r.TruckCapacityLiters
+	}
+	return opts
 }
 
 // CreateRouteRequest represents the request to create a route
 type CreateRouteRequest struct {
 	DriverID   uuid.UUID   `json:"driver_id" binding:"required"`
 	BinIDs     []uuid.UUID `json:"bin_ids" binding:"required,min=1"`
-	OptimizeBy string      `json:"optimize_by"` // "distance" or "fill_level"
+	OptimizeBy string      `json:"optimize_by"` // distance, fill_level, two_opt or capacity
 }
 
 // RouteResponse represents the API response for a route
@@ -55,11 +112,25 @@ type RouteResponse struct {
 	DriverID                 uuid.UUID   `json:"driver_id"`
 	Waypoints                []Waypoint  `json:"waypoints"`
 	TotalDistanceKm          *float64    `json:"total_distance_km,omitempty"`
+	Trips                    int         `json:"trips,omitempty"`
 	EstimatedDurationMinutes *int        `json:"estimated_duration_minutes,omitempty"`
 	Status                   RouteStatus `json:"status"`
+	OptimizeBy               string      `json:"optimize_by"`
 	CreatedAt                time.Time   `json:"created_at"`
 	StartedAt                *time.Time  `json:"started_at,omitempty"`
 	CompletedAt              *time.Time  `json:"completed_at,omitempty"`
+	ReoptimizedAt            *time.Time  `json:"reoptimized_at,omitempty"`
+}
+
+// RouteReoptimization is the outcome of re-sequencing the stops left on a
+// route: the bins emptied by someone else are dropped, those the driver
+// collected are completed and full bins nearby are added
+type RouteReoptimization struct {
+	Route     *RouteResponse `json:"route"`
+	Changed   bool           `json:"changed"`
+	Added     []uuid.UUID    `json:"added"`
+	Removed   []uuid.UUID    `json:"removed"`
+	Completed []uuid.UUID    `json:"completed"`
 }
 
 // ParseWaypoints parses the JSON waypoints into the WaypointsList
@@ -78,10 +149,13 @@ func (r *DriverRoute) ToResponse() *RouteResponse {
 		DriverID:                 r.DriverID,
 		Waypoints:                r.WaypointsList,
 		TotalDistanceKm:          r.TotalDistanceKm,
+		Trips:                    r.Trips,
 		EstimatedDurationMinutes: r.EstimatedDurationMinutes,
 		Status:                   r.Status,
+		OptimizeBy:               r.OptimizeBy,
 		CreatedAt:                r.CreatedAt,
 		StartedAt:                r.StartedAt,
 		CompletedAt:              r.CompletedAt,
+		ReoptimizedAt:            r.ReoptimizedAt,
 	}
 }
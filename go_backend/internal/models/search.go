@@ -0,0 +1,39 @@
+package models
+
+import "github.com/google/uuid"
+
+// SearchResultType identifies the entity a search result points to
+type SearchResultType string
+
+const (
+	SearchResultBin     SearchResultType = "bin"
+	SearchResultDriver  SearchResultType = "driver"
+	SearchResultUser    SearchResultType = "user"
+	SearchResultCompany SearchResultType = "company"
+)
+
+// SearchResultTypes lists every searchable entity type
+var SearchResultTypes = []SearchResultType{
+	SearchResultBin,
+	SearchResultDriver,
+	SearchResultUser,
+	SearchResultCompany,
+}
+
+// IsValid checks if the search result type is supported
+func (t SearchResultType) IsValid() bool {
+	switch t {
+	case SearchResultBin, SearchResultDriver, SearchResultUser, SearchResultCompany:
+		return true
+	}
+	return false
+}
+
+// SearchResult is a single match of the global search
+type SearchResult struct {
+	Type     SearchResultType `db:"type" json:"type"`
+	ID       uuid.UUID        `db:"id" json:"id"`
+	Title    string           `db:"title" json:"title"`
+	Subtitle string           `db:"subtitle" json:"subtitle,omitempty"`
+	Score    float64          `db:"score" json:"score"`
+}
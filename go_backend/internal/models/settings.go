@@ -0,0 +1,132 @@
+package models
+
+import "strings"
+
+// Sections of the runtime settings, each stored as one row
+const (
+	SettingsThresholds    = "thresholds"
+	SettingsDispatch      = "dispatch"
+	SettingsNotifications = "notifications"
+)
+
+// Settings are the runtime settings of the deployment. Admins change them
+// through the API and the services pick them up without a redeploy; until
+// then they hold the environment configuration.
+type Settings struct {
+	Thresholds    ThresholdSettings    `json:"thresholds"`
+	Dispatch      DispatchSettings     `json:"dispatch"`
+	Notifications NotificationSettings `json:"notifications"`
+}
+
+// ThresholdSettings are the limits at which bin sensors raise alerts
+type ThresholdSettings struct {
+	// LowBattery is the battery level (%) below which a bin is flagged
+	LowBattery int `json:"low_battery"`
+	// OfflineAfterMinutes is the silence after which a bin is flagged offline
+	OfflineAfterMinutes int `json:"offline_after_minutes"`
+	// FireTemperatureC is the temperature at or above which a bin raises a
+	// fire hazard alert
+	FireTemperatureC float64 `json:"fire_temperature_c"`
+}
+
+// DispatchSettings control the automatic dispatch of full bins
+type DispatchSettings struct {
+	Enabled  bool   `json:"enabled"`
+	Schedule string `json:"schedule"` // Standard 5-field cron expression
+	// MaxPerDriver caps the collections assigned to one driver per run; 0 is unlimited
+	MaxPerDriver int `json:"max_per_driver"`
+}
+
+// NotificationSettings turn the automatic notifications on or off by kind
+type NotificationSettings struct {
+	BinFull    bool `json:"bin_full"`
+	LowBattery bool `json:"low_battery"`
+	BinOffline bool `json:"bin_offline"`
+	BinFlagged bool `json:"bin_flagged"`
+	SLABreach  bool `json:"sla_breach"`
+	FireHazard bool `json:"fire_hazard"`
+}
+
+// UpdateSettingsRequest represents the request to change runtime settings;
+// omitted fields keep their value
+type UpdateSettingsRequest struct {
+	Thresholds    *UpdateThresholdSettingsRequest    `json:"thresholds"`
+	Dispatch      *UpdateDispatchSettingsRequest     `json:"dispatch"`
+	Notifications *UpdateNotificationSettingsRequest `json:"notifications"`
+}
+
+// UpdateThresholdSettingsRequest changes the alert thresholds
+type UpdateThresholdSettingsRequest struct {
+	LowBattery          *int     `json:"low_battery" binding:"omitempty,min=0,max=100"`
+	OfflineAfterMinutes *int     `json:"offline_after_minutes" binding:"omitempty,min=1"`
+	FireTemperatureC    *float64 `json:"fire_temperature_c" binding:"omitempty,gt=0"`
+}
+
+// UpdateDispatchSettingsRequest changes the automatic dispatch
+type UpdateDispatchSettingsRequest struct {
+	Enabled      *bool   `json:"enabled"`
+	Schedule     *string `json:"schedule" binding:"omitempty,max=100"`
+	MaxPerDriver *int    `json:"max_per_driver" binding:"omitempty,min=0"`
+}
+
+// UpdateNotificationSettingsRequest turns notifications on or off
+type UpdateNotificationSettingsRequest struct {
+	BinFull    *bool `json:"bin_full"`
+	LowBattery *bool `json:"low_battery"`
+	BinOffline *bool `json:"bin_offline"`
+	BinFlagged *bool `json:"bin_flagged"`
+	SLABreach  *bool `json:"sla_breach"`
+	FireHazard *bool `json:"fire_hazard"`
+}
+
+// Apply copies the fields set in the request onto settings and returns the
+// sections it changed
+func (r *UpdateSettingsRequest) Apply(settings *Settings) []string {
+	var changed []string
+	if t := r.Thresholds; t != nil {
+		if t.LowBattery != nil {
+			settings.Thresholds.LowBattery = *t.LowBattery
+		}
+		if t.OfflineAfterMinutes != nil {
+			settings.Thresholds.OfflineAfterMinutes = *t.OfflineAfterMinutes
+		}
+		if t.FireTemperatureC != nil {
+			settings.Thresholds.FireTemperatureC = *t.FireTemperatureC
+		}
+		changed = append(changed, SettingsThresholds)
+	}
+	if d := r.Dispatch; d != nil {
+		if d.Enabled != nil {
+			settings.Dispatch.Enabled = *d.Enabled
+		}
+		if d.Schedule != nil {
+			settings.Dispatch.Schedule = strings.TrimSpace(*d.Schedule)
+		}
+		if d.MaxPerDriver != nil {
+			settings.Dispatch.MaxPerDriver = *d.MaxPerDriver
+		}
+		changed = append(changed, SettingsDispatch)
+	}
+	if n := r.Notifications; n != nil {
+		if n.BinFull != nil {
+			settings.Notifications.BinFull = *n.BinFull
+		}
+		if n.LowBattery != nil {
+			settings.Notifications.LowBattery = *n.LowBattery
+		}
+		if n.BinOffline != nil {
+			settings.Notifications.BinOffline = *n.BinOffline
+		}
+		if n.BinFlagged != nil {
+			settings.Notifications.BinFlagged = *n.BinFlagged
+		}
+		if n.SLABreach != nil {
+			settings.Notifications.SLABreach = *n.SLABreach
+		}
+		if n.FireHazard != nil {
+			settings.Notifications.FireHazard = *n.FireHazard
+		}
+		changed = append(changed, SettingsNotifications)
+	}
+	return changed
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SLARule limits how long a bin may stay at or above its collection threshold.
+// A nil company or waste type matches every bin; the most specific active rule
+// applies, company before waste type.
+type SLARule struct {
+	ID        uuid.UUID  `db:"id" json:"id"`
+	CompanyID *uuid.UUID `db:"company_id" json:"company_id,omitempty"`
+	WasteType *string    `db:"waste_type" json:"waste_type,omitempty"`
+	MaxHours  int        `db:"max_hours" json:"max_hours"`
+	IsActive  bool       `db:"is_active" json:"is_active"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// CreateSLARuleRequest represents the request to create an SLA rule
+type CreateSLARuleRequest struct {
+	CompanyID *uuid.UUID `json:"company_id"`
+	WasteType *string    `json:"waste_type" binding:"omitempty,waste_type"`
+	MaxHours  int        `json:"max_hours" binding:"required,gt=0"`
+}
+
+// UpdateSLARuleRequest represents the request to update an SLA rule
+type UpdateSLARuleRequest struct {
+	MaxHours *int  `json:"max_hours" binding:"omitempty,gt=0"`
+	IsActive *bool `json:"is_active"`
+}
+
+// SLABreach records a bin that was not emptied within its SLA after crossing
+// its collection threshold. It is resolved once the bin drops back below the
+// threshold.
+type SLABreach struct {
+	ID                 uuid.UUID  `db:"id" json:"id"`
+	BinID              uuid.UUID  `db:"bin_id" json:"bin_id"`
+	DeviceID           string     `db:"device_id" json:"device_id"`
+	RuleID             *uuid.UUID `db:"rule_id" json:"rule_id,omitempty"`
+	CompanyID          *uuid.UUID `db:"company_id" json:"company_id,omitempty"`
+	MaxHours           int        `db:"max_hours" json:"max_hours"`
+	ThresholdCrossedAt time.Time  `db:"threshold_crossed_at" json:"threshold_crossed_at"`
+	DueAt              time.Time  `db:"due_at" json:"due_at"`
+	DetectedAt         time.Time  `db:"detected_at" json:"detected_at"`
+	ResolvedAt         *time.Time `db:"resolved_at" json:"resolved_at,omitempty"`
+}
+
+// SLACompanyBreaches totals the breaches of one company's bins; CompanyID is
+// nil for bins without a company
+type SLACompanyBreaches struct {
+	CompanyID *uuid.UUID `db:"company_id" json:"company_id"`
+	Breaches  int        `db:"breaches" json:"breaches"`
+	Open      int        `db:"open" json:"open"`
+}
+
+// SLAReport summarizes the breaches that fell due in a period, with the
+// breaches still open now
+type SLAReport struct {
+	Period              AnalyticsRange       `json:"period"`
+	CompanyID           *uuid.UUID           `json:"company_id,omitempty"`
+	Breaches            int                  `json:"breaches"`
+	Resolved            int                  `json:"resolved"`
+	Open                int                  `json:"open"`
+	AverageOverdueHours *float64             `json:"average_overdue_hours"` // Of resolved breaches
+	ByCompany           []SLACompanyBreaches `json:"by_company"`
+	OpenBreaches        []SLABreach          `json:"open_breaches"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskOfferStatus is where a task offered to a driver is in its lifecycle
+type TaskOfferStatus string
+
+const (
+	TaskOfferOffered   TaskOfferStatus = "offered"
+	TaskOfferAccepted  TaskOfferStatus = "accepted"
+	TaskOfferDeclined  TaskOfferStatus = "declined"
+	TaskOfferWithdrawn TaskOfferStatus = "withdrawn" // Another driver accepted the bin, or it was collected
+)
+
+// IsValid returns true if the status is a known task offer status
+func (s TaskOfferStatus) IsValid() bool {
+	switch s {
+	case TaskOfferOffered, TaskOfferAccepted, TaskOfferDeclined, TaskOfferWithdrawn:
+		return true
+	}
+	return false
+}
+
+// TaskOffer is a full bin offered to the driver its alert notified, who
+// accepts or declines collecting it
+type TaskOffer struct {
+	ID             uuid.UUID       `db:"id" json:"id"`
+	OrganizationID uuid.UUID       `db:"organization_id" json:"organization_id"`
+	BinID          uuid.UUID       `db:"bin_id" json:"bin_id"`
+	DriverID       uuid.UUID       `db:"driver_id" json:"driver_id"`
+	NotificationID *uuid.UUID      `db:"notification_id" json:"notification_id,omitempty"`
+	Status         TaskOfferStatus `db:"status" json:"status"`
+	CollectionID   *uuid.UUID      `db:"collection_id" json:"collection_id,omitempty"` // Created on acceptance
+	DeclineReason  *string         `db:"decline_reason" json:"decline_reason,omitempty"`
+	OfferedAt      time.Time       `db:"offered_at" json:"offered_at"`
+	RespondedAt    *time.Time      `db:"responded_at" json:"responded_at,omitempty"`
+}
+
+// DeclineTaskRequest represents the request to decline a task offer
+type DeclineTaskRequest struct {
+	Reason *string `json:"reason" binding:"omitempty,max=500"`
+}
+
+// AcceptTaskResponse is the accepted task offer with the collection created
+// for it
+type AcceptTaskResponse struct {
+	Task       *TaskOffer          `json:"task"`
+	Collection *CollectionResponse `json:"collection"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UploadPurpose is what an uploaded file is for
+type UploadPurpose string
+
+const (
+	UploadPurposeIssueReport     UploadPurpose = "issue_report"
+	UploadPurposeWasteImage      UploadPurpose = "waste_image"
+	UploadPurposeDisputeEvidence UploadPurpose = "dispute_evidence"
+	UploadPurposeCollectionPhoto UploadPurpose = "collection_photo"
+)
+
+// IsValid returns true if the purpose is a known upload purpose
+func (p UploadPurpose) IsValid() bool {
+	switch p {
+	case UploadPurposeIssueReport, UploadPurposeWasteImage, UploadPurposeDisputeEvidence, UploadPurposeCollectionPhoto:
+		return true
+	}
+	return false
+}
+
+// Upload is a file uploaded to object storage, served from URL
+type Upload struct {
+	ID           uuid.UUID     `db:"id" json:"id"`
+	ObjectKey    string        `db:"object_key" json:"object_key"`
+	URL          string        `db:"url" json:"url"`
+	Purpose      UploadPurpose `db:"purpose" json:"purpose"`
+	ContentType  string        `db:"content_type" json:"content_type"`
+	SizeBytes    int64         `db:"size_bytes" json:"size_bytes"`
+	UploadedBy   uuid.UUID     `db:"uploaded_by" json:"uploaded_by"`
+	UploaderRole Role          `db:"uploader_role" json:"uploader_role"`
+	AttachedAt   *time.Time    `db:"attached_at" json:"attached_at,omitempty"`
+	CreatedAt    time.Time     `db:"created_at" json:"created_at"`
+}
+
+// CreateUploadRequest represents the request for a pre-signed upload URL
+type CreateUploadRequest struct {
+	Purpose     UploadPurpose `json:"purpose" binding:"required"`
+	ContentType string        `json:"content_type" binding:"required"`
+	SizeBytes   int64         `json:"size_bytes" binding:"required,min=1"`
+}
+
+// UploadTicket is a pre-signed URL to PUT a file to. The request must send
+// exactly the listed headers; once it succeeds the file is served from URL.
+type UploadTicket struct {
+	Upload
+	UploadURL    string            `json:"upload_url"`
+	UploadMethod string            `json:"upload_method"`
+	Headers      map[string]string `json:"headers"`
+	ExpiresAt    time.Time         `json:"expires_at"`
+}
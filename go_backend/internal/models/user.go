@@ -8,15 +8,17 @@ import (
 
 // User represents a user in the system
 type User struct {
-	ID           uuid.UUID `db:"id" json:"id"`
-	Email        string    `db:"email" json:"email"`
-	PasswordHash string    `db:"password_hash" json:"-"`
-	FullName     string    `db:"full_name" json:"full_name"`
-	Phone        *string   `db:"phone" json:"phone,omitempty"`
-	Address      *string   `db:"address" json:"address,omitempty"`
-	RewardPoints int       `db:"reward_points" json:"reward_points"`
-	CreatedAt    time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+	ID             uuid.UUID `db:"id" json:"id"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	Email          string    `db:"email" json:"email"`
+	PasswordHash   string    `db:"password_hash" json:"-"`
+	FullName       string    `db:"full_name" json:"full_name"`
+	Phone          *string   `db:"phone" json:"phone,omitempty"`
+	Address        *string   `db:"address" json:"address,omitempty"`
+	RewardPoints   int       `db:"reward_points" json:"reward_points"`
+	Role           Role      `db:"role" json:"role"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
 }
 
 // CreateUserRequest represents the request to create a new user
@@ -24,27 +26,32 @@ type CreateUserRequest struct {
 	Email    string  `json:"email" binding:"required,email"`
 	Password string  `json:"password" binding:"required,min=8"`
 	FullName string  `json:"full_name" binding:"required"`
-	Phone    *string `json:"phone"`
+	Phone    *string `json:"phone" binding:"omitempty,phone"`
 	Address  *string `json:"address"`
+	// Organization is the slug of the organization to register with; the
+	// default organization when empty
+	Organization string `json:"organization"`
 }
 
 // UpdateUserRequest represents the request to update a user
 type UpdateUserRequest struct {
 	FullName *string `json:"full_name"`
-	Phone    *string `json:"phone"`
+	Phone    *string `json:"phone" binding:"omitempty,phone"`
 	Address  *string `json:"address"`
 }
 
 // UserResponse represents the API response for a user
 type UserResponse struct {
-	ID           uuid.UUID `json:"id"`
-	Email        string    `json:"email"`
-	FullName     string    `json:"full_name"`
-	Phone        *string   `json:"phone,omitempty"`
-	Address      *string   `json:"address,omitempty"`
-	RewardPoints int       `json:"reward_points"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID             uuid.UUID `json:"id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+	Email          string    `json:"email"`
+	FullName       string    `json:"full_name"`
+	Phone          *string   `json:"phone,omitempty"`
+	Address        *string   `json:"address,omitempty"`
+	RewardPoints   int       `json:"reward_points"`
+	Role           Role      `json:"role"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 }
 
 // AddRewardPointsRequest represents the request to add reward points
@@ -56,13 +63,15 @@ type AddRewardPointsRequest struct {
 // ToResponse converts User to UserResponse
 func (u *User) ToResponse() *UserResponse {
 	return &UserResponse{
-		ID:           u.ID,
-		Email:        u.Email,
-		FullName:     u.FullName,
-		Phone:        u.Phone,
-		Address:      u.Address,
-		RewardPoints: u.RewardPoints,
-		CreatedAt:    u.CreatedAt,
-		UpdatedAt:    u.UpdatedAt,
+		ID:             u.ID,
+		OrganizationID: u.OrganizationID,
+		Email:          u.Email,
+		FullName:       u.FullName,
+		Phone:          u.Phone,
+		Address:        u.Address,
+		RewardPoints:   u.RewardPoints,
+		Role:           u.Role,
+		CreatedAt:      u.CreatedAt,
+		UpdatedAt:      u.UpdatedAt,
 	}
 }
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FuelType represents a vehicle's fuel or power source
+type FuelType string
+
+const (
+	FuelTypeDiesel   FuelType = "diesel"
+	FuelTypePetrol   FuelType = "petrol"
+	FuelTypeElectric FuelType = "electric"
+	FuelTypeHybrid   FuelType = "hybrid"
+	FuelTypeCNG      FuelType = "cng"
+)
+
+// MaintenanceStatus represents whether a vehicle can be used for collections
+type MaintenanceStatus string
+
+const (
+	MaintenanceStatusOperational    MaintenanceStatus = "operational"
+	MaintenanceStatusMaintenanceDue MaintenanceStatus = "maintenance_due"
+	MaintenanceStatusInMaintenance  MaintenanceStatus = "in_maintenance"
+	MaintenanceStatusOutOfService   MaintenanceStatus = "out_of_service"
+)
+
+// Vehicle represents a collection truck
+type Vehicle struct {
+	ID                uuid.UUID         `db:"id" json:"id"`
+	OrganizationID    uuid.UUID         `db:"organization_id" json:"organization_id"`
+	PlateNumber       string            `db:"plate_number" json:"plate_number"`
+	Make              *string           `db:"make" json:"make,omitempty"`
+	Model             *string           `db:"model" json:"model,omitempty"`
+	CapacityLiters    int               `db:"capacity_liters" json:"capacity_liters"`
+	CapacityKg        *float64          `db:"capacity_kg" json:"capacity_kg,omitempty"`
+	FuelType          FuelType          `db:"fuel_type" json:"fuel_type"`
+	MaintenanceStatus MaintenanceStatus `db:"maintenance_status" json:"maintenance_status"`
+	LastMaintenanceAt *time.Time        `db:"last_maintenance_at" json:"last_maintenance_at,omitempty"`
+	NextMaintenanceAt *time.Time        `db:"next_maintenance_at" json:"next_maintenance_at,omitempty"`
+	IsActive          bool              `db:"is_active" json:"is_active"`
+	CreatedAt         time.Time         `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time         `db:"updated_at" json:"updated_at"`
+}
+
+// CreateVehicleRequest represents the request to register a new vehicle
+type CreateVehicleRequest struct {
+	PlateNumber       string             `json:"plate_number" binding:"required"`
+	Make              *string            `json:"make"`
+	Model             *string            `json:"model"`
+	CapacityLiters    int                `json:"capacity_liters" binding:"required,gt=0"`
+	CapacityKg        *float64           `json:"capacity_kg" binding:"omitempty,gt=0"`
+	FuelType          FuelType           `json:"fuel_type"`
+	MaintenanceStatus *MaintenanceStatus `json:"maintenance_status"`
+	NextMaintenanceAt *time.Time         `json:"next_maintenance_at"`
+}
+
+// UpdateVehicleRequest represents the request to update a vehicle
+type UpdateVehicleRequest struct {
+	PlateNumber       *string            `json:"plate_number"`
+	Make              *string            `json:"make"`
+	Model             *string            `json:"model"`
+	CapacityLiters    *int               `json:"capacity_liters" binding:"omitempty,gt=0"`
+	CapacityKg        *float64           `json:"capacity_kg" binding:"omitempty,gt=0"`
+	FuelType          *FuelType          `json:"fuel_type"`
+	MaintenanceStatus *MaintenanceStatus `json:"maintenance_status"`
+	LastMaintenanceAt *time.Time         `json:"last_maintenance_at"`
+	NextMaintenanceAt *time.Time         `json:"next_maintenance_at"`
+	IsActive          *bool              `json:"is_active"`
+}
+
+// AssignVehicleRequest represents the request to assign a vehicle to a driver
+type AssignVehicleRequest struct {
+	VehicleID uuid.UUID `json:"vehicle_id" binding:"required"`
+}
+
+// IsValid returns true if the fuel type is known
+func (f FuelType) IsValid() bool {
+	switch f {
+	case FuelTypeDiesel, FuelTypePetrol, FuelTypeElectric, FuelTypeHybrid, FuelTypeCNG:
+		return true
+	}
+	return false
+}
+
+// IsValid returns true if the maintenance status is known
+func (s MaintenanceStatus) IsValid() bool {
+	switch s {
+	case MaintenanceStatusOperational, MaintenanceStatusMaintenanceDue, MaintenanceStatusInMaintenance, MaintenanceStatusOutOfService:
+		return true
+	}
+	return false
+}
+
+// IsServiceable returns true if the vehicle can be sent on collections
+func (v *Vehicle) IsServiceable() bool {
+	return v.IsActive &&
+		(v.MaintenanceStatus == MaintenanceStatusOperational || v.MaintenanceStatus == MaintenanceStatusMaintenanceDue)
+}
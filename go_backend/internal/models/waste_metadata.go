@@ -22,7 +22,7 @@ type WasteMetadata struct {
 // CreateWasteMetadataRequest represents the request to create waste metadata
 type CreateWasteMetadataRequest struct {
 	CollectionID    *uuid.UUID `json:"collection_id"`
-	WasteType       string     `json:"waste_type" binding:"required"`
+	WasteType       string     `json:"waste_type" binding:"required,waste_type"`
 	Condition       string     `json:"condition" binding:"required"`
 	ConfidenceScore *float64   `json:"confidence_score"`
 	ImageURL        *string    `json:"image_url"`
@@ -41,23 +41,37 @@ type WasteMetadataResponse struct {
 	PricingRuleID   *uuid.UUID `json:"pricing_rule_id,omitempty"`
 }
 
-// ValuationRequest represents the request to valuate waste
+// ValuationRequest represents the request to valuate waste; rules of the
+// company are preferred over the global ones, and the total is also
+// converted to TargetCurrency when set. ValuatedAt values the waste with the
+// rules, market prices and promotions in effect at that time, now by default.
 type ValuationRequest struct {
-	WasteType string  `json:"waste_type" binding:"required"`
-	Condition string  `json:"condition" binding:"required"`
-	WeightKg  float64 `json:"weight_kg" binding:"required,gt=0"`
+	WasteType      string     `json:"waste_type" binding:"required,waste_type"`
+	Condition      string     `json:"condition" binding:"required"`
+	WeightKg       float64    `json:"weight_kg" binding:"required,gt=0"`
+	CompanyID      *uuid.UUID `json:"company_id"`
+	TargetCurrency *string    `json:"target_currency" binding:"omitempty,len=3"`
+	ValuatedAt     *time.Time `json:"valuated_at"`
 }
 
-// ValuationResponse represents the response for waste valuation
+// ValuationResponse represents the response for waste valuation. PricePerKg
+// is the effective price of the whole weight and Breakdown itemizes the total.
 type ValuationResponse struct {
-	WasteType     string   `json:"waste_type"`
-	Condition     string   `json:"condition"`
-	WeightKg      float64  `json:"weight_kg"`
-	PricePerKg    float64  `json:"price_per_kg"`
-	TotalPrice    float64  `json:"total_price"`
-	Currency      string   `json:"currency"`
-	PricingRuleID *string  `json:"pricing_rule_id,omitempty"`
-	Message       string   `json:"message,omitempty"`
+	WasteType     string              `json:"waste_type"`
+	Condition     string              `json:"condition"`
+	WeightKg      float64             `json:"weight_kg"`
+	PricePerKg    float64             `json:"price_per_kg"`
+	TotalPrice    float64             `json:"total_price"`
+	Currency      string              `json:"currency"`
+	PricingRuleID *string             `json:"pricing_rule_id,omitempty"`
+	RuleVersion   int                 `json:"rule_version,omitempty"`
+	PricingModel  PricingModel        `json:"pricing_model,omitempty"`
+	PromotionID   *string             `json:"promotion_id,omitempty"`
+	ContractID    *string             `json:"contract_id,omitempty"` // Contract the company values the waste under
+	Breakdown     []QuoteLine         `json:"breakdown,omitempty"`
+	Conversion    *CurrencyConversion `json:"conversion,omitempty"`
+	ValuatedAt    time.Time           `json:"valuated_at"`
+	Message       string              `json:"message,omitempty"`
 }
 
 // ToResponse converts WasteMetadata to WasteMetadataResponse
@@ -74,3 +88,23 @@ func (w *WasteMetadata) ToResponse() *WasteMetadataResponse {
 		PricingRuleID:   w.PricingRuleID,
 	}
 }
+
+// ValuationOffer is one company's valuation of a waste lot; Total is in the
+// currency of the comparison
+type ValuationOffer struct {
+	Rank        int                `json:"rank"`
+	CompanyID   uuid.UUID          `json:"company_id"`
+	CompanyName string             `json:"company_name"`
+	Total       float64            `json:"total"`
+	Valuation   *ValuationResponse `json:"valuation"`
+}
+
+// ValuationComparison ranks the offers of the companies valuing a waste lot,
+// the best paying first
+type ValuationComparison struct {
+	WasteType string           `json:"waste_type"`
+	Condition string           `json:"condition"`
+	WeightKg  float64          `json:"weight_kg"`
+	Currency  string           `json:"currency,omitempty"`
+	Offers    []ValuationOffer `json:"offers"`
+}
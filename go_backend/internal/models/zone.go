@@ -0,0 +1,157 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ZoneKind is the kind of area a zone covers
+type ZoneKind string
+
+const (
+	ZoneKindNeighborhood ZoneKind = "neighborhood"
+	ZoneKindDistrict     ZoneKind = "district"
+)
+
+// IsValid returns true if the zone kind is known
+func (k ZoneKind) IsValid() bool {
+	switch k {
+	case ZoneKindNeighborhood, ZoneKindDistrict:
+		return true
+	}
+	return false
+}
+
+// Polygon is a ring of [longitude, latitude] points, stored in a JSONB
+// column. The ring may or may not repeat its first point at the end.
+type Polygon [][2]float64
+
+// Scan reads the polygon from a JSONB column
+func (p *Polygon) Scan(src interface{}) error {
+	return scanJSON(src, p)
+}
+
+// Value writes the polygon to a JSONB column
+func (p Polygon) Value() (driver.Value, error) {
+	if p == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(p)
+}
+
+// Validate checks the points are coordinates and that the ring has at least
+// three distinct points
+func (p Polygon) Validate() error {
+	distinct := make(map[[2]float64]bool, len(p))
+	for i, point := range p {
+		if point[0] < -180 || point[0] > 180 {
+			return fmt.Errorf("boundary[%d] longitude must be between -180 and 180", i)
+		}
+		if point[1] < -90 || point[1] > 90 {
+			return fmt.Errorf("boundary[%d] latitude must be between -90 and 90", i)
+		}
+		distinct[point] = true
+	}
+	if len(distinct) < 3 {
+		return errors.New("boundary needs at least three distinct points")
+	}
+	return nil
+}
+
+// Path returns the ring in the syntax of a PostgreSQL polygon, with
+// longitudes as x and latitudes as y
+func (p Polygon) Path() string {
+	points := make([]string, len(p))
+	for i, point := range p {
+		points[i] = "(" + strconv.FormatFloat(point[0], 'f', -1, 64) + "," +
+			strconv.FormatFloat(point[1], 'f', -1, 64) + ")"
+	}
+	return "(" + strings.Join(points, ",") + ")"
+}
+
+// Zone is a neighborhood or district of an organization. Bins belong to the
+// zone containing them, the oldest one where zones overlap.
+type Zone struct {
+	ID             uuid.UUID `db:"id" json:"id"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	Name           string    `db:"name" json:"name"`
+	Kind           ZoneKind  `db:"kind" json:"kind"`
+	Description    *string   `db:"description" json:"description,omitempty"`
+	Boundary       Polygon   `db:"boundary" json:"boundary"`
+	// BinCount is the number of active bins in the zone
+	BinCount  int       `db:"bin_count" json:"bin_count"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// CreateZoneRequest represents the request to create a zone
+type CreateZoneRequest struct {
+	Name        string   `json:"name" binding:"required,max=100"`
+	Kind        ZoneKind `json:"kind"`
+	Description *string  `json:"description" binding:"omitempty,max=2000"`
+	Boundary    Polygon  `json:"boundary" binding:"required"`
+}
+
+// UpdateZoneRequest represents the request to update a zone; a new boundary
+// reassigns the bins of the organization
+type UpdateZoneRequest struct {
+	Name        *string   `json:"name" binding:"omitempty,max=100"`
+	Kind        *ZoneKind `json:"kind"`
+	Description *string   `json:"description" binding:"omitempty,max=2000"`
+	Boundary    Polygon   `json:"boundary"`
+}
+
+// SetDriverZonesRequest replaces the zones a driver covers; an empty list
+// lets the driver be dispatched anywhere
+type SetDriverZonesRequest struct {
+	ZoneIDs []uuid.UUID `json:"zone_ids" binding:"required"`
+}
+
+// ZoneCoverage maps the drivers restricted to some zones to those zones
+type ZoneCoverage map[uuid.UUID][]uuid.UUID
+
+// Covers returns true if the driver may be dispatched to the bin: drivers
+// without zones cover every bin, the others only the bins of their zones
+func (c ZoneCoverage) Covers(driverID uuid.UUID, bin *Bin) bool {
+	zones, restricted := c[driverID]
+	if !restricted {
+		return true
+	}
+	if bin.ZoneID == nil {
+		return false
+	}
+	for _, id := range zones {
+		if id == *bin.ZoneID {
+			return true
+		}
+	}
+	return false
+}
+
+// ZoneAnalytics summarizes the bins of a zone and the collections of the
+// period. Collections count toward the zone their bin is in now.
+type ZoneAnalytics struct {
+	ZoneID           uuid.UUID `db:"zone_id" json:"zone_id"`
+	Name             string    `db:"name" json:"name"`
+	Kind             ZoneKind  `db:"kind" json:"kind"`
+	Bins             int       `db:"bins" json:"bins"`
+	AverageFillLevel float64   `db:"average_fill_level" json:"average_fill_level"`
+	NeedsCollection  int       `db:"needs_collection" json:"needs_collection"`
+	UnderMaintenance int       `db:"under_maintenance" json:"under_maintenance"`
+	Offline          int       `db:"offline" json:"offline"`
+	Collections      int       `db:"collections" json:"collections"`
+	CollectedKg      float64   `db:"collected_kg" json:"collected_kg"`
+}
+
+// ZoneAnalyticsReport holds the analytics of zones over a period
+type ZoneAnalyticsReport struct {
+	Period AnalyticsRange  `json:"period"`
+	Zones  []ZoneAnalytics `json:"zones"`
+}
@@ -0,0 +1,55 @@
+package mqtt
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	pahomqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// Devices publish "online" on bins/{device_id}/availability when they connect
+// and leave "offline" as their last will, which the broker publishes when the
+// connection drops without a clean disconnect
+const (
+	availabilityTopic   = "bins/+/availability"
+	availabilityOffline = "offline"
+)
+
+// availabilityHandler processes device availability messages
+func (c *Client) availabilityHandler(client pahomqtt.Client, msg pahomqtt.Message) {
+	go c.handleAvailability(msg.Topic(), msg.Payload())
+}
+
+// handleAvailability flags the bin of a device that went offline at once,
+// rather than after the offline window without readings. Coming back online
+// is recorded by its next reading.
+func (c *Client) handleAvailability(topic string, payload []byte) {
+	if strings.TrimSpace(string(payload)) != availabilityOffline {
+		return
+	}
+	parts := strings.Split(topic, "/")
+	if len(parts) != 3 || parts[1] == "" {
+		log.Printf("Ignoring availability on unexpected topic %s", topic)
+		return
+	}
+	deviceID := parts[1]
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	bin, err := c.binRepo.MarkDeviceOffline(ctx, deviceID)
+	if err != nil {
+		log.Printf("Failed to mark bin %s offline: %v", deviceID, err)
+		return
+	}
+	if bin == nil {
+		return
+	}
+
+	log.Printf("Bin %s dropped its connection, marked offline", deviceID)
+	if err := c.notificationService.NotifyBinOffline(ctx, bin); err != nil {
+		log.Printf("Failed to send offline alert for bin %s: %v", deviceID, err)
+	}
+}
@@ -3,30 +3,41 @@ package mqtt
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"time"
 
 	pahomqtt "github.com/eclipse/paho.mqtt.golang"
 	"github.com/smartwaste/backend/internal/config"
+	"github.com/smartwaste/backend/internal/metrics"
 	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/realtime"
 	"github.com/smartwaste/backend/internal/repository"
 	"github.com/smartwaste/backend/internal/services"
 )
 
+// ErrInvalidPayload indicates a bin status message that cannot be processed
+var ErrInvalidPayload = errors.New("invalid bin status payload")
+
 // Client wraps the MQTT client
 type Client struct {
 	client              pahomqtt.Client
 	binRepo             *repository.BinRepository
+	deadLetterRepo      *repository.DeadLetterRepository
+	firmwareRepo        *repository.FirmwareRepository
 	notificationService *services.NotificationService
-	fillLevelThreshold  int
+	predictionService   *services.PredictionService
+	hub                 *realtime.Hub
+	settings            *services.SettingsService
+	dedup               *messageDeduper
+	fillLevels          *fillLevelBuffer
 }
 
 // NewClient creates a new MQTT client
-func NewClient(cfg *config.MQTTConfig, binRepo *repository.BinRepository, notificationService *services.NotificationService) *Client {
+func NewClient(cfg *config.MQTTConfig, binRepo *repository.BinRepository, deadLetterRepo *repository.DeadLetterRepository, firmwareRepo *repository.FirmwareRepository, notificationService *services.NotificationService, predictionService *services.PredictionService, hub *realtime.Hub, settings *services.SettingsService) (*Client, error) {
 	opts := pahomqtt.NewClientOptions()
-	broker := fmt.Sprintf("tcp://%s:%s", cfg.Broker, cfg.Port)
-	opts.AddBroker(broker)
+	opts.AddBroker(brokerURL(cfg))
 	opts.SetClientID(cfg.ClientID)
 
 	if cfg.Username != "" {
@@ -34,6 +45,16 @@ func NewClient(cfg *config.MQTTConfig, binRepo *repository.BinRepository, notifi
 		opts.SetPassword(cfg.Password)
 	}
 
+	if cfg.TLSEnabled {
+		tlsConfig, err := newTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		opts.SetTLSConfig(tlsConfig)
+	} else if cfg.Username != "" {
+		log.Println("Warning: MQTT credentials are sent in plaintext; enable MQTT_TLS_ENABLED in production")
+	}
+
 	// Set connection options
 	opts.SetAutoReconnect(true)
 	opts.SetConnectRetry(true)
@@ -43,8 +64,14 @@ func NewClient(cfg *config.MQTTConfig, binRepo *repository.BinRepository, notifi
 
 	mqttClient := &Client{
 		binRepo:             binRepo,
+		deadLetterRepo:      deadLetterRepo,
+		firmwareRepo:        firmwareRepo,
 		notificationService: notificationService,
-		fillLevelThreshold:  90, // Trigger notification when fill level exceeds 90%
+		predictionService:   predictionService,
+		hub:                 hub,
+		settings:            settings,
+		dedup:               newMessageDeduper(cfg.DedupWindow),
+		fillLevels:          newFillLevelBuffer(binRepo, cfg.FillFlushInterval, cfg.FillBatchSize),
 	}
 
 	// Set callbacks
@@ -54,7 +81,7 @@ func NewClient(cfg *config.MQTTConfig, binRepo *repository.BinRepository, notifi
 
 	mqttClient.client = pahomqtt.NewClient(opts)
 
-	return mqttClient
+	return mqttClient, nil
 }
 
 // Connect establishes connection to the MQTT broker
@@ -73,16 +100,33 @@ func (c *Client) Disconnect() {
 	log.Println("Disconnected from MQTT broker")
 }
 
-// Subscribe subscribes to the bin status topic
+// Close disconnects from the broker, if connected, and writes the buffered
+// fill levels
+func (c *Client) Close() {
+	if c.client.IsConnected() {
+		c.Disconnect()
+	}
+	c.fillLevels.close()
+}
+
+// Subscribe subscribes to the bin status, availability, configuration
+// acknowledgement and firmware update status topics
 func (c *Client) Subscribe() error {
 	// Subscribe to bin status updates from all bins
 	// Topic pattern: bins/+/status where + is a wildcard for bin_id
-	topic := "bins/+/status"
-	token := c.client.Subscribe(topic, 1, c.binStatusHandler)
-	if token.Wait() && token.Error() != nil {
-		return fmt.Errorf("failed to subscribe to topic %s: %w", topic, token.Error())
+	topics := map[string]pahomqtt.MessageHandler{
+		"bins/+/status":   c.binStatusHandler,
+		availabilityTopic: c.availabilityHandler,
+		configAckTopic:    c.configAckHandler,
+		otaStatusTopic:    c.otaStatusHandler,
+	}
+	for topic, handler := range topics {
+		token := c.client.Subscribe(topic, 1, handler)
+		if token.Wait() && token.Error() != nil {
+			return fmt.Errorf("failed to subscribe to topic %s: %w", topic, token.Error())
+		}
+		log.Printf("Subscribed to topic: %s", topic)
 	}
-	log.Printf("Subscribed to topic: %s", topic)
 	return nil
 }
 
@@ -107,53 +151,225 @@ func (c *Client) messageHandler(client pahomqtt.Client, msg pahomqtt.Message) {
 
 // binStatusHandler processes bin status updates
 func (c *Client) binStatusHandler(client pahomqtt.Client, msg pahomqtt.Message) {
+	// A retained status is the last reading of the device, already processed
+	// when it was published; it may long be out of date
+	if msg.Retained() {
+		return
+	}
 	// Process message in a goroutine for concurrent handling
-	go c.processBinStatus(msg.Payload())
+	go c.handleBinStatus(msg.Topic(), msg.Payload())
 }
 
-// processBinStatus handles the bin status update logic
-func (c *Client) processBinStatus(payload []byte) {
+// handleBinStatus processes a bin status message received from the broker
+func (c *Client) handleBinStatus(topic string, payload []byte) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Parse JSON payload
-	var status models.BinStatusUpdate
-	if err := json.Unmarshal(payload, &status); err != nil {
-		log.Printf("Failed to parse bin status payload: %v", err)
-		return
+	c.IngestBinStatus(ctx, topic, payload)
+}
+
+// IngestBinStatus processes a bin status message and dead-letters it if it is
+// invalid. source names where the message came from: the MQTT topic, or the
+// HTTP endpoint for devices that cannot reach the broker.
+func (c *Client) IngestBinStatus(ctx context.Context, source string, payload []byte) error {
+	err := c.ProcessBinStatus(ctx, payload)
+	if err == nil {
+		return nil
 	}
 
-	log.Printf("Processing bin status update: BinID=%s, FillLevel=%d%%", status.BinID, status.FillLevel)
+	log.Printf("Failed to process bin status on %s: %v", source, err)
+	version, _ := payloadVersion(payload)
+	if !errors.Is(err, ErrInvalidPayload) {
+		recordResult(schemaLabel(version), metrics.MQTTResultFailed)
+		return err
+	}
+	recordResult(schemaLabel(version), metrics.MQTTResultInvalid)
 
-	// Validate fill level
-	if status.FillLevel < 0 || status.FillLevel > 100 {
-		log.Printf("Invalid fill level %d for bin %s", status.FillLevel, status.BinID)
-		return
+	deadLetter := &models.DeadLetter{
+		Topic:   source,
+		Payload: string(payload),
+		Reason:  err.Error(),
+	}
+	if err := c.deadLetterRepo.Create(ctx, deadLetter); err != nil {
+		log.Printf("Failed to store dead letter for %s: %v", source, err)
 	}
+	return err
+}
 
-	// Update bin fill level in database
-	if err := c.binRepo.UpdateFillLevel(ctx, status.BinID, status.FillLevel); err != nil {
-		log.Printf("Failed to update bin fill level: %v", err)
-		return
+// ProcessBinStatus handles the bin status update logic. Errors wrapping
+// ErrInvalidPayload mean the message can never succeed as-is.
+func (c *Client) ProcessBinStatus(ctx context.Context, payload []byte) error {
+	status, err := decodeBinStatus(payload)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Processing bin status update: BinID=%s, FillLevel=%d%%, SchemaVersion=%d", status.BinID, status.FillLevel, status.SchemaVersion)
+
+	// Drop retransmissions; a failed message is released so it can be retried
+	if status.MessageID != "" {
+		key := status.BinID + "/" + status.MessageID
+		if !c.dedup.claim(key, time.Now()) {
+			log.Printf("Dropping duplicate message %s from bin %s", status.MessageID, status.BinID)
+			recordResult(schemaLabel(status.SchemaVersion), metrics.MQTTResultDuplicate)
+			return nil
+		}
+		err := c.applyBinStatus(ctx, status)
+		if err != nil {
+			c.dedup.release(key)
+		}
+		return err
+	}
+
+	return c.applyBinStatus(ctx, status)
+}
+
+// applyBinStatus stores a validated status update and fires the resulting alerts
+func (c *Client) applyBinStatus(ctx context.Context, status *models.BinStatusUpdate) error {
+
+	// Get bin details
+	bin, err := c.binRepo.GetByDeviceID(ctx, status.BinID)
+	if err != nil {
+		return fmt.Errorf("failed to get bin %s: %w", status.BinID, err)
+	}
+	if bin == nil {
+		return fmt.Errorf("%w: unknown device %s", ErrInvalidPayload, status.BinID)
+	}
+
+	// Heavy waste such as glass or rubble fills a bin by weight before the
+	// ultrasonic sensor sees it full, so the higher estimate wins
+	fillLevel := status.FillLevel
+	if status.WeightKg != nil {
+		if byWeight, ok := bin.WeightFillLevel(*status.WeightKg); ok && byWeight > fillLevel {
+			log.Printf("Bin %s is %d%% full by weight (%.1fkg), above the %d%% measured",
+				status.BinID, byWeight, *status.WeightKg, fillLevel)
+			fillLevel = byWeight
+		}
 	}
 
-	// Check if bin needs collection (threshold exceeded)
-	if status.FillLevel >= c.fillLevelThreshold {
+	// Update bin fill level in database, batched with the other bins
+	if err := c.fillLevels.add(ctx, status.BinID, fillLevel, time.Now()); err != nil {
+		return fmt.Errorf("failed to update bin fill level: %w", err)
+	}
+	bin.FillLevel = fillLevel
+	if bin.IsOffline {
+		log.Printf("Bin %s is reporting again, clearing offline flag", status.BinID)
+		bin.IsOffline = false
+		bin.OfflineSince = nil
+	}
+
+	// Store sensor health telemetry
+	if status.HasTelemetry() {
+		previousBattery := bin.BatteryLevel
+		previousTemperature := bin.TemperatureC
+		if err := c.binRepo.UpdateTelemetry(ctx, status.BinID, status); err != nil {
+			return fmt.Errorf("failed to update bin telemetry: %w", err)
+		}
+		applyTelemetry(bin, status)
+
+		// Alert once when the battery drops below the threshold, not on every reading
+		threshold := c.settings.Current().Thresholds.LowBattery
+		if batteryCrossedThreshold(previousBattery, bin.BatteryLevel, threshold) {
+			log.Printf("Bin %s battery level (%d%%) below threshold (%d%%), triggering notification",
+				status.BinID, *bin.BatteryLevel, threshold)
+			go c.notificationService.NotifyLowBattery(context.WithoutCancel(ctx), bin)
+		}
+
+		// Alert once when the bin gets hot enough to be on fire
+		fireThreshold := c.settings.Current().Thresholds.FireTemperatureC
+		if temperatureCrossedThreshold(previousTemperature, bin.TemperatureC, fireThreshold) {
+			log.Printf("Bin %s temperature (%.1f°C) at or above fire threshold (%.1f°C), triggering notification",
+				status.BinID, *bin.TemperatureC, fireThreshold)
+			go c.notificationService.NotifyFireHazard(context.WithoutCancel(ctx), bin, fireThreshold)
+		}
+	}
+
+	// Record the reading and refresh the fill prediction
+	if err := c.predictionService.RecordReading(ctx, bin); err != nil {
+		log.Printf("Failed to update prediction for bin %s: %v", status.BinID, err)
+	}
+
+	// Push the new fill level to live dashboards
+	c.hub.PublishBinUpdate(bin)
+
+	// Check if the bin reached its alert threshold
+	if bin.NeedsAlert() {
 		log.Printf("Bin %s fill level (%d%%) exceeds threshold (%d%%), triggering notification",
-			status.BinID, status.FillLevel, c.fillLevelThreshold)
+			status.BinID, bin.FillLevel, bin.AlertThreshold)
+
+		// Trigger notification to nearest driver; it outlives this message's context
+		go c.notificationService.NotifyNearestDriver(context.WithoutCancel(ctx), bin)
+	}
+
+	recordProcessed(status, time.Now())
+	return nil
+}
 
-		// Get bin details
-		bin, err := c.binRepo.GetByDeviceID(ctx, status.BinID)
-		if err != nil || bin == nil {
-			log.Printf("Failed to get bin details for notification: %v", err)
-			return
+// recordResult counts the outcome of a message, overall and for its schema version
+func recordResult(version, result string) {
+	metrics.MQTTMessages.WithLabelValues(result).Inc()
+	metrics.SensorPayloads.WithLabelValues(version, result).Inc()
+}
+
+// recordProcessed updates the ingestion metrics for a stored status update
+func recordProcessed(status *models.BinStatusUpdate, now time.Time) {
+	recordResult(schemaLabel(status.SchemaVersion), metrics.MQTTResultProcessed)
+	metrics.MQTTLastMessage.Set(float64(now.Unix()))
+	if status.Timestamp > 0 {
+		lag := now.Sub(time.Unix(status.Timestamp, 0)).Seconds()
+		if lag < 0 {
+			// Sensor clock is ahead of ours
+			lag = 0
 		}
+		metrics.MQTTIngestionLag.Observe(lag)
+	}
+}
 
-		// Trigger notification to nearest driver
-		go c.notificationService.NotifyNearestDriver(ctx, bin)
+// applyTelemetry copies the telemetry carried by the update onto the bin
+func applyTelemetry(bin *models.Bin, status *models.BinStatusUpdate) {
+	if status.BatteryLevel != nil {
+		bin.BatteryLevel = status.BatteryLevel
+	}
+	if status.RSSI != nil {
+		bin.RSSI = status.RSSI
+	}
+	if status.Temperature != nil {
+		bin.TemperatureC = status.Temperature
+	}
+	if status.FirmwareVersion != nil {
+		bin.FirmwareVersion = status.FirmwareVersion
+	}
+	if status.WeightKg != nil {
+		bin.WeightKg = status.WeightKg
+	}
+	if status.LidOpen != nil {
+		bin.LidOpen = status.LidOpen
 	}
 }
 
+// batteryCrossedThreshold reports whether the battery level has just fallen
+// below the low-battery threshold
+func batteryCrossedThreshold(previous, current *int, threshold int) bool {
+	if current == nil || *current >= threshold {
+		return false
+	}
+	return previous == nil || *previous >= threshold
+}
+
+// temperatureCrossedThreshold reports whether the temperature has just
+// reached the fire hazard threshold
+func temperatureCrossedThreshold(previous, current *float64, threshold float64) bool {
+	if current == nil || *current < threshold {
+		return false
+	}
+	return previous == nil || *previous < threshold
+}
+
+// SendCommand publishes a command to a device's command topic
+func (c *Client) SendCommand(deviceID string, command *models.DeviceCommand) error {
+	return c.Publish(fmt.Sprintf("bins/%s/cmd", deviceID), command)
+}
+
 // Publish publishes a message to a topic
 func (c *Client) Publish(topic string, payload interface{}) error {
 	data, err := json.Marshal(payload)
@@ -0,0 +1,69 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	pahomqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// Sensors acknowledge each configuration pushed on bins/{device_id}/config
+// on bins/{device_id}/config/ack
+const configAckTopic = "bins/+/config/ack"
+
+// PublishConfig pushes a configuration to a device. It is retained so a
+// device offline at the time receives it when it connects.
+func (c *Client) PublishConfig(deviceID string, cfg *models.DeviceConfig) error {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	token := c.client.Publish(fmt.Sprintf("bins/%s/config", deviceID), 1, true, data)
+	if token.Wait() && token.Error() != nil {
+		return fmt.Errorf("failed to publish message: %w", token.Error())
+	}
+	return nil
+}
+
+// configAckHandler processes configuration acknowledgements
+func (c *Client) configAckHandler(client pahomqtt.Client, msg pahomqtt.Message) {
+	go c.handleConfigAck(msg.Topic(), msg.Payload())
+}
+
+// handleConfigAck records the configuration a device applied or rejected
+func (c *Client) handleConfigAck(topic string, payload []byte) {
+	parts := strings.Split(topic, "/")
+	if len(parts) != 4 || parts[1] == "" {
+		log.Printf("Ignoring configuration acknowledgement on unexpected topic %s", topic)
+		return
+	}
+	deviceID := parts[1]
+
+	var ack models.DeviceConfigAck
+	if err := json.Unmarshal(payload, &ack); err != nil {
+		log.Printf("Ignoring malformed configuration acknowledgement from %s: %v", deviceID, err)
+		return
+	}
+	if ack.Status != models.DeviceConfigApplied && ack.Status != models.DeviceConfigRejected {
+		log.Printf("Ignoring configuration acknowledgement from %s with status %q", deviceID, ack.Status)
+		return
+	}
+
+	if ack.Status == models.DeviceConfigRejected {
+		log.Printf("Bin %s rejected configuration version %d: %s", deviceID, ack.Version, ack.Error)
+	} else {
+		log.Printf("Bin %s applied configuration version %d", deviceID, ack.Version)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.binRepo.RecordConfigAck(ctx, deviceID, &ack); err != nil {
+		log.Printf("Failed to record configuration acknowledgement of bin %s: %v", deviceID, err)
+	}
+}
@@ -0,0 +1,61 @@
+package mqtt
+
+import (
+	"sync"
+	"time"
+)
+
+// messageDeduper remembers recently processed message IDs so retransmitted
+// sensor messages are only processed once
+type messageDeduper struct {
+	mu        sync.Mutex
+	window    time.Duration
+	seen      map[string]time.Time
+	lastPrune time.Time
+}
+
+// newMessageDeduper creates a deduper that remembers IDs for window.
+// A zero window disables deduplication.
+func newMessageDeduper(window time.Duration) *messageDeduper {
+	return &messageDeduper{
+		window: window,
+		seen:   make(map[string]time.Time),
+	}
+}
+
+// claim marks key as being processed. It returns false if key was claimed
+// within the window, meaning the message is a duplicate.
+func (d *messageDeduper) claim(key string, now time.Time) bool {
+	if d.window <= 0 {
+		return true
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if now.Sub(d.lastPrune) >= d.window {
+		for k, at := range d.seen {
+			if now.Sub(at) >= d.window {
+				delete(d.seen, k)
+			}
+		}
+		d.lastPrune = now
+	}
+
+	if at, ok := d.seen[key]; ok && now.Sub(at) < d.window {
+		return false
+	}
+	d.seen[key] = now
+	return true
+}
+
+// release forgets key so a message that failed processing can be retried
+func (d *messageDeduper) release(key string) {
+	if d.window <= 0 {
+		return
+	}
+
+	d.mu.Lock()
+	delete(d.seen, key)
+	d.mu.Unlock()
+}
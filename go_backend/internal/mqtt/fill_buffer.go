@@ -0,0 +1,162 @@
+package mqtt
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/smartwaste/backend/internal/metrics"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// fillFlushTimeout bounds a flush of the buffered fill levels
+const fillFlushTimeout = 10 * time.Second
+
+// fillLevelStore writes fill levels, such as *repository.BinRepository
+type fillLevelStore interface {
+	UpdateFillLevel(ctx context.Context, deviceID string, fillLevel int) error
+	UpdateFillLevels(ctx context.Context, updates []models.FillLevelUpdate) error
+}
+
+// fillLevelBuffer batches the fill level writes of the status messages: the
+// latest level of each bin is kept and written with those of the other bins
+// every interval, or as soon as batchSize bins are pending. A zero interval
+// disables buffering.
+type fillLevelBuffer struct {
+	store     fillLevelStore
+	interval  time.Duration
+	batchSize int
+
+	mu      sync.Mutex
+	pending map[string]models.FillLevelUpdate
+	closed  bool
+
+	full chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+// newFillLevelBuffer creates the buffer and starts flushing it
+func newFillLevelBuffer(store fillLevelStore, interval time.Duration, batchSize int) *fillLevelBuffer {
+	b := &fillLevelBuffer{
+		store:     store,
+		interval:  interval,
+		batchSize: batchSize,
+		pending:   make(map[string]models.FillLevelUpdate),
+		full:      make(chan struct{}, 1),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	if interval > 0 {
+		go b.run()
+	} else {
+		close(b.done)
+	}
+	return b
+}
+
+// add records the fill level of a bin, writing it directly when buffering is
+// disabled or the buffer was closed
+func (b *fillLevelBuffer) add(ctx context.Context, deviceID string, fillLevel int, receivedAt time.Time) error {
+	b.mu.Lock()
+	if b.interval <= 0 || b.closed {
+		b.mu.Unlock()
+		return b.store.UpdateFillLevel(ctx, deviceID, fillLevel)
+	}
+	b.pending[deviceID] = models.FillLevelUpdate{DeviceID: deviceID, FillLevel: fillLevel, ReceivedAt: receivedAt}
+	full := b.batchSize > 0 && len(b.pending) >= b.batchSize
+	b.mu.Unlock()
+
+	if full {
+		select {
+		case b.full <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// run flushes the buffer every interval and whenever it fills up, until close
+func (b *fillLevelBuffer) run() {
+	defer close(b.done)
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-b.full:
+		case <-b.stop:
+			b.flush()
+			return
+		}
+		b.flush()
+	}
+}
+
+// flush writes the pending fill levels in batches of batchSize. A failed
+// batch is put back to be retried, unless newer levels arrived meanwhile.
+func (b *fillLevelBuffer) flush() {
+	b.mu.Lock()
+	if len(b.pending) == 0 {
+		b.mu.Unlock()
+		return
+	}
+	updates := make([]models.FillLevelUpdate, 0, len(b.pending))
+	for _, update := range b.pending {
+		updates = append(updates, update)
+	}
+	b.pending = make(map[string]models.FillLevelUpdate)
+	b.mu.Unlock()
+
+	batchSize := b.batchSize
+	if batchSize <= 0 {
+		batchSize = len(updates)
+	}
+	for start := 0; start < len(updates); start += batchSize {
+		batch := updates[start:min(start+batchSize, len(updates))]
+
+		ctx, cancel := context.WithTimeout(context.Background(), fillFlushTimeout)
+		err := b.store.UpdateFillLevels(ctx, batch)
+		cancel()
+		if err != nil {
+			log.Printf("Failed to write %d buffered fill levels, retrying: %v", len(batch), err)
+			metrics.MQTTFillLevelFlushErrors.Inc()
+			b.requeue(batch)
+			continue
+		}
+		metrics.MQTTFillLevelFlushes.Observe(float64(len(batch)))
+	}
+}
+
+// requeue puts back the updates of a failed batch that are still the latest
+func (b *fillLevelBuffer) requeue(batch []models.FillLevelUpdate) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, update := range batch {
+		if _, newer := b.pending[update.DeviceID]; !newer {
+			b.pending[update.DeviceID] = update
+		}
+	}
+}
+
+// close stops buffering and flushes the pending fill levels; later updates
+// are written directly
+func (b *fillLevelBuffer) close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	b.mu.Unlock()
+
+	if b.interval > 0 {
+		close(b.stop)
+	}
+	<-b.done
+
+	// A last attempt at what the final flush could not write
+	b.flush()
+}
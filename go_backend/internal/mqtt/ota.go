@@ -0,0 +1,89 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	pahomqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// Sensors report the progress of the firmware update offered on
+// bins/{device_id}/ota on bins/{device_id}/ota/status
+const otaStatusTopic = "bins/+/ota/status"
+
+// PublishOTA offers a firmware update to a device. It is retained so a device
+// offline at the time receives it when it connects.
+func (c *Client) PublishOTA(deviceID string, update *models.OTAUpdate) error {
+	data, err := json.Marshal(update)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return c.publishRetained(fmt.Sprintf("bins/%s/ota", deviceID), data)
+}
+
+// ClearOTA withdraws the firmware update offered to a device by replacing
+// the retained message with an empty one, which the broker discards
+func (c *Client) ClearOTA(deviceID string) error {
+	return c.publishRetained(fmt.Sprintf("bins/%s/ota", deviceID), nil)
+}
+
+func (c *Client) publishRetained(topic string, data []byte) error {
+	token := c.client.Publish(topic, 1, true, data)
+	if token.Wait() && token.Error() != nil {
+		return fmt.Errorf("failed to publish message: %w", token.Error())
+	}
+	return nil
+}
+
+// otaStatusHandler processes firmware update progress reports
+func (c *Client) otaStatusHandler(client pahomqtt.Client, msg pahomqtt.Message) {
+	go c.handleOTAStatus(msg.Topic(), msg.Payload())
+}
+
+// handleOTAStatus records the progress of a firmware update and withdraws the
+// update once the device is done with it, so it is not offered again
+func (c *Client) handleOTAStatus(topic string, payload []byte) {
+	parts := strings.Split(topic, "/")
+	if len(parts) != 4 || parts[1] == "" {
+		log.Printf("Ignoring firmware update status on unexpected topic %s", topic)
+		return
+	}
+	deviceID := parts[1]
+
+	var status models.OTAStatus
+	if err := json.Unmarshal(payload, &status); err != nil {
+		log.Printf("Ignoring malformed firmware update status from %s: %v", deviceID, err)
+		return
+	}
+	if !status.Status.IsValid() {
+		log.Printf("Ignoring firmware update status from %s with status %q", deviceID, status.Status)
+		return
+	}
+
+	var message *string
+	if status.Status == models.FirmwareUpdateFailed {
+		log.Printf("Bin %s failed to update to firmware %s: %s", deviceID, status.Version, status.Error)
+		if status.Error != "" {
+			message = &status.Error
+		}
+	} else {
+		log.Printf("Bin %s firmware %s: %s", deviceID, status.Version, status.Status)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.firmwareRepo.UpdateStatus(ctx, deviceID, status.RolloutID, status.Status, message); err != nil {
+		log.Printf("Failed to record firmware update status of bin %s: %v", deviceID, err)
+		return
+	}
+	if status.Status.IsFinal() {
+		if err := c.ClearOTA(deviceID); err != nil {
+			log.Printf("Failed to withdraw firmware %s from bin %s: %v", status.Version, deviceID, err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// Schema versions of the bin status payload. Payloads without a
+// schema_version come from sensors that predate it and are version 1.
+const (
+	schemaV1 = 1
+	schemaV2 = 2
+)
+
+// payloadVersion returns the schema version a payload declares
+func payloadVersion(payload []byte) (int, error) {
+	var header struct {
+		SchemaVersion *int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(payload, &header); err != nil {
+		return 0, err
+	}
+	if header.SchemaVersion == nil {
+		return schemaV1, nil
+	}
+	return *header.SchemaVersion, nil
+}
+
+// schemaLabel returns the metric label of a schema version, folding the
+// versions the backend does not accept, and unreadable payloads, into one
+func schemaLabel(version int) string {
+	switch version {
+	case schemaV1, schemaV2:
+		return strconv.Itoa(version)
+	}
+	return "unsupported"
+}
+
+// decodeBinStatus parses and validates a bin status payload of any
+// supported schema version into the update it describes
+func decodeBinStatus(payload []byte) (*models.BinStatusUpdate, error) {
+	version, err := payloadVersion(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed JSON: %v", ErrInvalidPayload, err)
+	}
+
+	var status models.BinStatusUpdate
+	switch version {
+	case schemaV1:
+		if err := json.Unmarshal(payload, &status); err != nil {
+			return nil, fmt.Errorf("%w: malformed JSON: %v", ErrInvalidPayload, err)
+		}
+	case schemaV2:
+		var v2 models.BinStatusUpdateV2
+		if err := json.Unmarshal(payload, &v2); err != nil {
+			return nil, fmt.Errorf("%w: malformed JSON: %v", ErrInvalidPayload, err)
+		}
+		switch {
+		case v2.FillLevel == nil:
+			return nil, fmt.Errorf("%w: missing fill_level", ErrInvalidPayload)
+		case v2.MessageID == "":
+			return nil, fmt.Errorf("%w: missing message_id", ErrInvalidPayload)
+		case v2.Timestamp <= 0:
+			return nil, fmt.Errorf("%w: missing timestamp", ErrInvalidPayload)
+		}
+		status = v2.Update()
+	default:
+		return nil, fmt.Errorf("%w: unsupported schema_version %d", ErrInvalidPayload, version)
+	}
+	status.SchemaVersion = version
+
+	if status.BinID == "" {
+		return nil, fmt.Errorf("%w: missing bin_id", ErrInvalidPayload)
+	}
+	if status.FillLevel < 0 || status.FillLevel > 100 {
+		return nil, fmt.Errorf("%w: fill level %d out of range", ErrInvalidPayload, status.FillLevel)
+	}
+	if status.BatteryLevel != nil && (*status.BatteryLevel < 0 || *status.BatteryLevel > 100) {
+		return nil, fmt.Errorf("%w: battery level %d out of range", ErrInvalidPayload, *status.BatteryLevel)
+	}
+	if status.WeightKg != nil && *status.WeightKg < 0 {
+		return nil, fmt.Errorf("%w: weight %g out of range", ErrInvalidPayload, *status.WeightKg)
+	}
+	return &status, nil
+}
@@ -0,0 +1,55 @@
+package mqtt
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/smartwaste/backend/internal/config"
+)
+
+// brokerURL returns the broker address with the scheme matching the transport
+func brokerURL(cfg *config.MQTTConfig) string {
+	scheme := "tcp"
+	if cfg.TLSEnabled {
+		scheme = "ssl"
+	}
+	return fmt.Sprintf("%s://%s:%s", scheme, cfg.Broker, cfg.Port)
+}
+
+// newTLSConfig builds the TLS configuration for the broker connection from the
+// CA bundle and optional client certificate in the MQTT config
+func newTLSConfig(cfg *config.MQTTConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.TLSServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if cfg.CACertFile != "" {
+		pem, err := os.ReadFile(cfg.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read MQTT CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in MQTT CA bundle %s", cfg.CACertFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.ClientCertFile != "" || cfg.ClientKeyFile != "" {
+		if cfg.ClientCertFile == "" || cfg.ClientKeyFile == "" {
+			return nil, errors.New("MQTT client certificate and key must be configured together")
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCertFile, cfg.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load MQTT client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
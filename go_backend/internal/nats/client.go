@@ -1,41 +1,39 @@
 package nats
 
 import (
+	"errors"
 	"log"
 	"time"
 
 	"github.com/nats-io/nats.go"
 	"github.com/smartwaste/backend/internal/config"
+	"github.com/smartwaste/backend/internal/metrics"
+	"github.com/smartwaste/pkg/events"
 )
 
+// retryDelay is how long a failed event waits before it is redelivered
+const retryDelay = 5 * time.Second
+
 // Client represents a NATS client
 type Client struct {
 	conn *nats.Conn
 	js   nats.JetStreamContext
-	url  string
+	cfg  *config.NATSConfig
 }
 
 // NewClient creates a new NATS client
-func NewClient(cfg *config.Config) *Client {
-	// Check if NATS URL is configured, otherwise default
-	url := "nats://localhost:4222"
-	if cfg.MQTT.Broker != "" {
-		// This is a bit of a hack since we're reusing the config struct which might not have NATS specific fields yet
-		// ideally we'd add NATS config to the main config struct
-		// reusing a hardcoded default or env var would be better if config isn't updated
-	}
-
+func NewClient(cfg *config.NATSConfig) *Client {
 	return &Client{
-		url: url,
+		cfg: cfg,
 	}
 }
 
-// Connect connects to the NATS server
+// Connect connects to the NATS server and initializes JetStream
 func (c *Client) Connect() error {
 	opts := []nats.Option{
 		nats.Name("Go Backend Service"),
 		nats.ReconnectWait(2 * time.Second),
-		nats.MaxReconnects(10),
+		nats.MaxReconnects(-1),
 		nats.DisconnectErrHandler(func(nc *nats.Conn, err error) {
 			log.Printf("Disconnected from NATS: %v", err)
 		}),
@@ -44,7 +42,7 @@ func (c *Client) Connect() error {
 		}),
 	}
 
-	nc, err := nats.Connect(c.url, opts...)
+	nc, err := nats.Connect(c.cfg.URL, opts...)
 	if err != nil {
 		return err
 	}
@@ -52,20 +50,80 @@ func (c *Client) Connect() error {
 
 	js, err := nc.JetStream()
 	if err != nil {
-		log.Printf("Warning: Failed to init JetStream: %v", err)
-		// We might still be able to use basic NATS
+		nc.Close()
+		return err
 	}
 	c.js = js
 
-	log.Println("Connected to NATS")
+	log.Println("Connected to NATS and JetStream initialized")
 	return nil
 }
 
-// Subscribe subscribes to a subject
-func (c *Client) Subscribe(subject string, handler func([]byte)) (*nats.Subscription, error) {
-	return c.conn.Subscribe(subject, func(msg *nats.Msg) {
-		handler(msg.Data)
-	})
+// Handler processes the data of an event. Returning an error redelivers the
+// event later, so handlers must be safe to run more than once.
+type Handler func(data []byte) error
+
+// Consume delivers the shipment events to the handlers registered for their
+// subject through a durable JetStream consumer. Events published while the
+// backend was down are delivered once it is back, and backend instances
+// share the consumer so each event is handled once. Events of other subjects
+// are acknowledged without handling.
+func (c *Client) Consume(handlers map[string]Handler) (*nats.Subscription, error) {
+	if err := c.ensureStream(); err != nil {
+		return nil, err
+	}
+
+	return c.js.QueueSubscribe(events.SubjectShipments, c.cfg.Consumer, func(msg *nats.Msg) {
+		metrics.NATSMessages.WithLabelValues(msg.Subject).Inc()
+
+		handler, ok := handlers[msg.Subject]
+		if !ok {
+			msg.Ack()
+			return
+		}
+
+		if err := handler(msg.Data); err != nil {
+			attempt := uint64(1)
+			if meta, metaErr := msg.Metadata(); metaErr == nil {
+				attempt = meta.NumDelivered
+			}
+			if c.cfg.MaxDeliver > 0 && attempt >= uint64(c.cfg.MaxDeliver) {
+				log.Printf("Giving up on %s event after %d attempts: %v", msg.Subject, attempt, err)
+				msg.Term()
+				return
+			}
+			log.Printf("Failed to handle %s event (attempt %d), retrying: %v", msg.Subject, attempt, err)
+			msg.NakWithDelay(retryDelay)
+			return
+		}
+		msg.Ack()
+	},
+		nats.Durable(c.cfg.Consumer),
+		nats.ManualAck(),
+		nats.AckExplicit(),
+		nats.AckWait(c.cfg.AckWait),
+		nats.MaxDeliver(c.cfg.MaxDeliver),
+		nats.DeliverNew(),
+	)
+}
+
+// ensureStream creates the shipment stream if the shipment tracker has not
+// yet, with the same configuration
+func (c *Client) ensureStream() error {
+	_, err := c.js.StreamInfo(c.cfg.Stream)
+	if errors.Is(err, nats.ErrStreamNotFound) {
+		_, err = c.js.AddStream(&nats.StreamConfig{
+			Name:     c.cfg.Stream,
+			Subjects: []string{events.SubjectShipments},
+			Storage:  nats.FileStorage,
+		})
+	}
+	return err
+}
+
+// IsConnected returns true while the connection to NATS is up
+func (c *Client) IsConnected() bool {
+	return c.conn != nil && c.conn.IsConnected()
 }
 
 // Close closes the connection
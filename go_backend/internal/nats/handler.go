@@ -1,72 +1,240 @@
 package nats
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/auth"
+	"github.com/smartwaste/backend/internal/repository"
 	"github.com/smartwaste/backend/internal/services"
+	"github.com/smartwaste/pkg/events"
 )
 
-// EventPayload matches the payload structure from shipment_tracker
-type EventPayload struct {
-	EventID   string      `json:"event_id"`
-	EventType string      `json:"event_type"`
-	Timestamp string      `json:"timestamp"`
-	Data      interface{} `json:"data"`
-}
+// handleTimeout bounds the handling of one event
+const handleTimeout = 10 * time.Second
 
 // EventHandler handles incoming NATS events
 type EventHandler struct {
+	userRepo        *repository.UserRepository
+	processedRepo   *repository.ProcessedEventRepository
 	notificationSvc *services.NotificationService
+	rewardSvc       *services.RewardService
+	earningSvc      *services.EarningService
+	sagaSvc         *services.CollectionSagaService
+	claimLease      time.Duration
 }
 
-// NewEventHandler creates a new event handler
-func NewEventHandler(notificationSvc *services.NotificationService) *EventHandler {
+// NewEventHandler creates a new event handler. An event claimed for handling
+// by an instance may be taken over by another after claimLease. sagaSvc is
+// nil when collections are not shipped.
+func NewEventHandler(
+	userRepo *repository.UserRepository,
+	processedRepo *repository.ProcessedEventRepository,
+	notificationSvc *services.NotificationService,
+	rewardSvc *services.RewardService,
+	earningSvc *services.EarningService,
+	sagaSvc *services.CollectionSagaService,
+	claimLease time.Duration,
+) *EventHandler {
 	return &EventHandler{
+		userRepo:        userRepo,
+		processedRepo:   processedRepo,
 		notificationSvc: notificationSvc,
+		rewardSvc:       rewardSvc,
+		earningSvc:      earningSvc,
+		sagaSvc:         sagaSvc,
+		claimLease:      claimLease,
+	}
+}
+
+// Handlers returns the handlers of the shipment events the backend consumes,
+// by subject. Each event is handled once, however often it is delivered.
+func (h *EventHandler) Handlers() map[string]Handler {
+	return map[string]Handler{
+		events.SubjectShipmentCreated: h.once(h.HandleShipmentCreated),
+		events.SubjectPriceConfirmed:  h.once(h.HandlePriceConfirmed),
+		events.SubjectPickupStarted:   h.once(h.HandlePickupStarted),
+		events.SubjectCompleted:       h.once(h.HandleDeliveryCompleted),
+		events.SubjectCancelled:       h.once(h.HandleShipmentCancelled),
+	}
+}
+
+// once skips the events already handled, by their event_id. The event is
+// claimed while handler runs, so concurrent deliveries to other instances
+// skip it too, and released if handler fails for the redelivery to retry.
+func (h *EventHandler) once(handler Handler) Handler {
+	return func(data []byte) error {
+		payload, err := events.Parse(data)
+		if err != nil || payload.EventID == uuid.Nil {
+			// Left for the handler to drop
+			return handler(data)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), handleTimeout)
+		claimed, err := h.processedRepo.Claim(ctx, payload.EventID, payload.EventType, h.claimLease)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to claim event %v: %w", payload.EventID, err)
+		}
+		if !claimed {
+			log.Printf("Skipping %s event %v, already handled", payload.EventType, payload.EventID)
+			return nil
+		}
+
+		handleErr := handler(data)
+
+		ctx, cancel = context.WithTimeout(context.Background(), handleTimeout)
+		defer cancel()
+		if handleErr != nil {
+			if err := h.processedRepo.Release(ctx, payload.EventID); err != nil {
+				log.Printf("Failed to release event %v: %v", payload.EventID, err)
+			}
+			return handleErr
+		}
+		// The claim keeps redeliveries away until the lease ends
+		if err := h.processedRepo.MarkProcessed(ctx, payload.EventID); err != nil {
+			log.Printf("Failed to mark event %v processed: %v", payload.EventID, err)
+		}
+		return nil
 	}
 }
 
 // HandleShipmentCreated handles shipment creation events
-func (h *EventHandler) HandleShipmentCreated(data []byte) {
-	var payload EventPayload
-	if err := json.Unmarshal(data, &payload); err != nil {
+func (h *EventHandler) HandleShipmentCreated(data []byte) error {
+	payload, err := events.Parse(data)
+	if err != nil {
 		log.Printf("Error unmarshalling shipment created event: %v", err)
-		return
+		return nil
 	}
 	log.Printf("Received Shipment Created Event: %v", payload.EventID)
+
+	var shipment events.ShipmentCreated
+	if ok, err := decodeData(payload, &shipment); !ok {
+		return err
+	}
 	// TODO: Notify admin or update local state
+	return nil
 }
 
-// HandlePriceConfirmed handles price confirmation events
-func (h *EventHandler) HandlePriceConfirmed(data []byte) {
-	var payload EventPayload
-	if err := json.Unmarshal(data, &payload); err != nil {
+// HandlePriceConfirmed handles price confirmation events by telling the
+// available drivers of the user's organization that the shipment can be
+// picked up
+func (h *EventHandler) HandlePriceConfirmed(data []byte) error {
+	payload, err := events.Parse(data)
+	if err != nil {
 		log.Printf("Error unmarshalling price confirmed event: %v", err)
-		return
+		return nil
 	}
 	log.Printf("Received Price Confirmed Event: %v", payload.EventID)
-	// Example: Notify driver that price is confirmed and they can proceed
+
+	var shipment events.StatusChanged
+	if ok, err := decodeData(payload, &shipment); !ok {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), handleTimeout)
+	defer cancel()
+	user, err := h.userRepo.GetByID(ctx, shipment.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		log.Printf("Shipment %s of event %v belongs to unknown user %s", shipment.ShipmentID, payload.EventID, shipment.UserID)
+		return nil
+	}
+
+	return h.notificationSvc.NotifyShipmentAvailable(auth.WithOrganization(ctx, user.OrganizationID), &shipment)
 }
 
 // HandlePickupStarted handles pickup started events
-func (h *EventHandler) HandlePickupStarted(data []byte) {
-	var payload EventPayload
-	if err := json.Unmarshal(data, &payload); err != nil {
+func (h *EventHandler) HandlePickupStarted(data []byte) error {
+	payload, err := events.Parse(data)
+	if err != nil {
 		log.Printf("Error unmarshalling pickup started event: %v", err)
-		return
+		return nil
 	}
 	log.Printf("Received Pickup Started Event: %v", payload.EventID)
 	// Notify user that driver has started pickup
+	return nil
 }
 
-// HandleDeliveryCompleted handles delivery completion events
-func (h *EventHandler) HandleDeliveryCompleted(data []byte) {
-	var payload EventPayload
-	if err := json.Unmarshal(data, &payload); err != nil {
+// HandleDeliveryCompleted handles delivery completion events by crediting
+// the driver's earnings and the user's reward points and telling the user. A
+// redelivered event credits nothing, as each shipment is only credited once.
+func (h *EventHandler) HandleDeliveryCompleted(data []byte) error {
+	payload, err := events.Parse(data)
+	if err != nil {
 		log.Printf("Error unmarshalling delivery completed event: %v", err)
-		return
+		return nil
 	}
 	log.Printf("Received Delivery Completed Event: %v", payload.EventID)
-	// Process payment, update user stats, etc.
+
+	var shipment events.StatusChanged
+	if ok, err := decodeData(payload, &shipment); !ok {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), handleTimeout)
+	defer cancel()
+	if _, err := h.earningSvc.CreditShipment(ctx, &shipment); err != nil {
+		return err
+	}
+	txn, err := h.rewardSvc.CreditShipment(ctx, &shipment)
+	if err != nil {
+		return err
+	}
+	if txn == nil {
+		return nil
+	}
+	if err := h.notificationSvc.NotifyRewardCredited(ctx, txn); err != nil {
+		log.Printf("Failed to notify user %s of reward for event %v: %v", txn.UserID, payload.EventID, err)
+	}
+	return nil
+}
+
+// HandleShipmentCancelled handles cancellation events by taking the penalty
+// points of a late cancellation from the user and reopening the collection
+// the shipment was created for. A redelivered event takes nothing, as each
+// cancellation is only debited once.
+func (h *EventHandler) HandleShipmentCancelled(data []byte) error {
+	payload, err := events.Parse(data)
+	if err != nil {
+		log.Printf("Error unmarshalling shipment cancelled event: %v", err)
+		return nil
+	}
+	log.Printf("Received Shipment Cancelled Event: %v", payload.EventID)
+
+	var shipment events.StatusChanged
+	if ok, err := decodeData(payload, &shipment); !ok {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), handleTimeout)
+	defer cancel()
+	if _, err := h.rewardSvc.DebitCancellation(ctx, &shipment); err != nil {
+		return err
+	}
+	if h.sagaSvc == nil {
+		return nil
+	}
+	return h.sagaSvc.OnShipmentCancelled(ctx, shipment.ShipmentID)
+}
+
+// decodeData decodes the data of an event into v, returning false if it
+// cannot be. Invalid events are dropped, but events of a schema version the
+// backend does not support are retried, for an upgraded instance to handle.
+func decodeData(payload *events.Event, v events.Payload) (bool, error) {
+	err := payload.Decode(v)
+	if errors.Is(err, events.ErrUnsupportedVersion) {
+		return false, err
+	}
+	if err != nil {
+		log.Printf("Dropping %s event %v: %v", payload.EventType, payload.EventID, err)
+		return false, nil
+	}
+	return true, nil
 }
@@ -0,0 +1,105 @@
+// Package payments moves money with a payment provider: companies are
+// charged for collection services and drivers and users paid out. Outcomes
+// the provider does not know right away arrive later through its webhooks.
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/config"
+)
+
+// Provider names accepted by PAYMENTS_PROVIDER
+const (
+	ProviderStub   = "stub"   // Accepts every payment without moving money
+	ProviderStripe = "stripe" // Stripe PaymentIntents and Connect transfers
+)
+
+// httpTimeout bounds calls to payment providers
+const httpTimeout = 15 * time.Second
+
+var (
+	// ErrDeclined is returned when the provider refuses a payment
+	ErrDeclined = errors.New("payment declined")
+	// ErrInvalidSignature is returned for webhooks the provider did not sign
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrWebhooksUnsupported is returned by providers without webhooks
+	ErrWebhooksUnsupported = errors.New("provider has no webhooks")
+)
+
+// Status is the outcome of a payment as far as the provider knows
+type Status string
+
+const (
+	StatusProcessing Status = "processing" // Settled later through a webhook
+	StatusSucceeded  Status = "succeeded"
+	StatusFailed     Status = "failed"
+)
+
+// Charge describes an amount to take from a customer's saved payment method
+type Charge struct {
+	PaymentID     uuid.UUID // Keys the provider request, so retries charge once
+	Amount        float64
+	Currency      string
+	Customer      string // Provider customer of the company
+	PaymentMethod string // Saved payment method of the customer
+	Description   string
+}
+
+// Payout describes an amount to send to a connected account
+type Payout struct {
+	PaymentID   uuid.UUID // Keys the provider request, so retries pay once
+	Amount      float64
+	Currency    string
+	Account     string // Provider account of the driver or user
+	Description string
+}
+
+// Result is what the provider answered to a charge or payout
+type Result struct {
+	Reference     string
+	Status        Status
+	FailureReason string
+}
+
+// WebhookEvent is a change of a payment reported by the provider
+type WebhookEvent struct {
+	ID            string
+	PaymentID     uuid.UUID // From the metadata the payment was created with
+	Reference     string
+	Status        Status
+	FailureReason string
+}
+
+// Provider moves the money of charges and payouts
+type Provider interface {
+	Name() string
+	// Charge takes the amount from the customer's payment method
+	Charge(ctx context.Context, charge *Charge) (*Result, error)
+	// Payout sends the amount to the connected account
+	Payout(ctx context.Context, payout *Payout) (*Result, error)
+	// ParseWebhook verifies and decodes a webhook request. It returns nil for
+	// events that do not concern payments.
+	ParseWebhook(payload []byte, header http.Header) (*WebhookEvent, error)
+}
+
+// NewProvider creates the payment provider selected by the configuration
+func NewProvider(cfg *config.PaymentsConfig) (Provider, error) {
+	switch cfg.Provider {
+	case ProviderStub, "":
+		return NewStubProvider(), nil
+	case ProviderStripe:
+		if cfg.StripeSecretKey == "" || cfg.StripeWebhookSecret == "" {
+			return nil, fmt.Errorf("payment provider %q requires STRIPE_SECRET_KEY and STRIPE_WEBHOOK_SECRET", cfg.Provider)
+		}
+		client := &http.Client{Timeout: httpTimeout}
+		return NewStripeProvider(client, cfg.StripeURL, cfg.StripeSecretKey, cfg.StripeWebhookSecret), nil
+	default:
+		return nil, fmt.Errorf("unknown payment provider %q", cfg.Provider)
+	}
+}
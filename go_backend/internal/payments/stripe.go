@@ -0,0 +1,255 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// stripeSignatureTolerance is how old a signed webhook may be, against replays
+const stripeSignatureTolerance = 5 * time.Minute
+
+// StripeProvider charges companies with off-session PaymentIntents on their
+// saved payment method and pays drivers and users with transfers to their
+// Connect accounts. Charges Stripe cannot settle right away are reported by
+// its payment_intent webhooks, and reversed transfers by transfer.reversed.
+type StripeProvider struct {
+	client        *http.Client
+	baseURL       string
+	secretKey     string
+	webhookSecret string
+}
+
+// NewStripeProvider creates a new StripeProvider
+func NewStripeProvider(client *http.Client, baseURL, secretKey, webhookSecret string) *StripeProvider {
+	return &StripeProvider{
+		client:        client,
+		baseURL:       strings.TrimSuffix(baseURL, "/"),
+		secretKey:     secretKey,
+		webhookSecret: webhookSecret,
+	}
+}
+
+// Name returns the provider name
+func (p *StripeProvider) Name() string {
+	return ProviderStripe
+}
+
+// stripeObject is the part of the Stripe PaymentIntents, transfers and errors
+// the provider reads
+type stripeObject struct {
+	ID               string            `json:"id"`
+	Status           string            `json:"status"`
+	Metadata         map[string]string `json:"metadata"`
+	LastPaymentError *struct {
+		Message string `json:"message"`
+	} `json:"last_payment_error"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// stripeEvent is a Stripe webhook event
+type stripeEvent struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Object stripeObject `json:"object"`
+	} `json:"data"`
+}
+
+// Charge confirms a PaymentIntent on the customer's payment method
+func (p *StripeProvider) Charge(ctx context.Context, charge *Charge) (*Result, error) {
+	if charge.Customer == "" || charge.PaymentMethod == "" {
+		return nil, fmt.Errorf("%w: a Stripe customer and payment method are required", ErrDeclined)
+	}
+	form := url.Values{
+		"amount":               {strconv.FormatInt(minorUnits(charge.Amount), 10)},
+		"currency":             {strings.ToLower(charge.Currency)},
+		"customer":             {charge.Customer},
+		"payment_method":       {charge.PaymentMethod},
+		"confirm":              {"true"},
+		"off_session":          {"true"},
+		"description":          {charge.Description},
+		"metadata[payment_id]": {charge.PaymentID.String()},
+	}
+	intent, err := p.post(ctx, "/v1/payment_intents", form, "charge-"+charge.PaymentID.String())
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Reference: intent.ID, Status: intentStatus(intent.Status), FailureReason: failureReason(intent)}, nil
+}
+
+// Payout transfers the amount to the Connect account
+func (p *StripeProvider) Payout(ctx context.Context, payout *Payout) (*Result, error) {
+	if payout.Account == "" {
+		return nil, fmt.Errorf("%w: a Stripe connected account is required", ErrDeclined)
+	}
+	form := url.Values{
+		"amount":               {strconv.FormatInt(minorUnits(payout.Amount), 10)},
+		"currency":             {strings.ToLower(payout.Currency)},
+		"destination":          {payout.Account},
+		"description":          {payout.Description},
+		"metadata[payment_id]": {payout.PaymentID.String()},
+	}
+	transfer, err := p.post(ctx, "/v1/transfers", form, "payout-"+payout.PaymentID.String())
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Reference: transfer.ID, Status: StatusSucceeded}, nil
+}
+
+// ParseWebhook verifies the Stripe-Signature of a webhook and decodes the
+// payment it reports on
+func (p *StripeProvider) ParseWebhook(payload []byte, header http.Header) (*WebhookEvent, error) {
+	if err := p.verifySignature(payload, header.Get("Stripe-Signature"), time.Now()); err != nil {
+		return nil, err
+	}
+
+	var event stripeEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("invalid stripe event: %w", err)
+	}
+
+	object := &event.Data.Object
+	webhook := &WebhookEvent{ID: event.ID, Reference: object.ID}
+	switch event.Type {
+	case "payment_intent.succeeded":
+		webhook.Status = StatusSucceeded
+	case "payment_intent.processing":
+		webhook.Status = StatusProcessing
+	case "payment_intent.payment_failed", "payment_intent.canceled":
+		webhook.Status = StatusFailed
+		webhook.FailureReason = failureReason(object)
+		if webhook.FailureReason == "" {
+			webhook.FailureReason = "payment intent " + object.Status
+		}
+	case "transfer.reversed":
+		webhook.Status = StatusFailed
+		webhook.FailureReason = "transfer reversed"
+	default:
+		return nil, nil
+	}
+
+	// Payments of other integrations on the same account are not ours
+	id, err := uuid.Parse(object.Metadata["payment_id"])
+	if err != nil {
+		return nil, nil
+	}
+	webhook.PaymentID = id
+	return webhook, nil
+}
+
+// verifySignature checks the header holds a recent v1 signature of the
+// payload: an HMAC-SHA256 of "timestamp.payload" with the webhook secret
+func (p *StripeProvider) verifySignature(payload []byte, header string, now time.Time) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > stripeSignatureTolerance || age < -stripeSignatureTolerance {
+		return fmt.Errorf("%w: timestamp outside the tolerance", ErrInvalidSignature)
+	}
+
+	mac := hmac.New(sha256.New, []byte(p.webhookSecret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	expected := mac.Sum(nil)
+	for _, signature := range signatures {
+		if actual, err := hex.DecodeString(signature); err == nil && hmac.Equal(actual, expected) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// post sends a form request to the Stripe API
+func (p *StripeProvider) post(ctx context.Context, path string, form url.Values, idempotencyKey string) (*stripeObject, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.secretKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Idempotency-Key", idempotencyKey)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("stripe request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var object stripeObject
+	if err := json.NewDecoder(resp.Body).Decode(&object); err != nil {
+		return nil, fmt.Errorf("invalid stripe response: %w", err)
+	}
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+		return nil, fmt.Errorf("%w: %s", ErrDeclined, stripeMessage(&object, resp.Status))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("stripe returned %s: %s", resp.Status, stripeMessage(&object, resp.Status))
+	}
+	return &object, nil
+}
+
+// intentStatus maps the status of a confirmed PaymentIntent. Intents needing
+// the customer, such as for 3-D Secure, count as processing until a webhook
+// reports how they ended.
+func intentStatus(status string) Status {
+	switch status {
+	case "succeeded":
+		return StatusSucceeded
+	case "requires_payment_method", "canceled":
+		return StatusFailed
+	default:
+		return StatusProcessing
+	}
+}
+
+// failureReason returns why Stripe failed a PaymentIntent, if it said
+func failureReason(object *stripeObject) string {
+	if object.LastPaymentError != nil {
+		return object.LastPaymentError.Message
+	}
+	return ""
+}
+
+// stripeMessage returns the error message of a Stripe response
+func stripeMessage(object *stripeObject, status string) string {
+	if object.Error != nil && object.Error.Message != "" {
+		return object.Error.Message
+	}
+	return status
+}
+
+// minorUnits converts an amount to the currency's minor units, assuming two
+// decimals
+func minorUnits(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
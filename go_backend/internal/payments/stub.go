@@ -0,0 +1,37 @@
+package payments
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// StubProvider accepts every payment without moving money, for development
+// and deployments that settle offline
+type StubProvider struct{}
+
+// NewStubProvider creates a new StubProvider
+func NewStubProvider() *StubProvider {
+	return &StubProvider{}
+}
+
+// Name returns the provider name
+func (p *StubProvider) Name() string {
+	return ProviderStub
+}
+
+// Charge succeeds with a generated reference
+func (p *StubProvider) Charge(ctx context.Context, charge *Charge) (*Result, error) {
+	return &Result{Reference: "stub_" + uuid.NewString(), Status: StatusSucceeded}, nil
+}
+
+// Payout succeeds with a generated reference
+func (p *StubProvider) Payout(ctx context.Context, payout *Payout) (*Result, error) {
+	return &Result{Reference: "stub_" + uuid.NewString(), Status: StatusSucceeded}, nil
+}
+
+// ParseWebhook rejects every webhook, as stub payments settle right away
+func (p *StubProvider) ParseWebhook(payload []byte, header http.Header) (*WebhookEvent, error) {
+	return nil, ErrWebhooksUnsupported
+}
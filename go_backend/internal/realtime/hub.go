@@ -0,0 +1,299 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+const (
+	// writeWait is the time allowed to write a message to the peer
+	writeWait = 10 * time.Second
+	// pongWait is the time allowed to read the next pong message from the peer
+	pongWait = 60 * time.Second
+	// pingPeriod sends pings to the peer with this period (must be less than pongWait)
+	pingPeriod = (pongWait * 9) / 10
+	// sendBufferSize is the number of messages queued per client before it is dropped
+	sendBufferSize = 64
+)
+
+// BinUpdateEvent is the message pushed to dashboard clients when a bin changes
+type BinUpdateEvent struct {
+	Type      string     `json:"type"`
+	BinID     uuid.UUID  `json:"bin_id"`
+	DeviceID  string     `json:"device_id"`
+	CompanyID *uuid.UUID `json:"company_id,omitempty"`
+	FillLevel int        `json:"fill_level"`
+	Latitude  float64    `json:"latitude"`
+	Longitude float64    `json:"longitude"`
+	// PredictedFullAt is the estimated time the bin reaches the collection threshold
+	PredictedFullAt *time.Time `json:"predicted_full_at,omitempty"`
+	Timestamp       time.Time  `json:"timestamp"`
+}
+
+// message is a broadcast payload along with the organization and company it
+// belongs to
+type message struct {
+	organizationID uuid.UUID
+	companyID      *uuid.UUID
+	event          BinUpdateEvent
+	data           []byte
+}
+
+// subscription is an in-process consumer of bin updates, such as a gRPC stream
+type subscription struct {
+	organizationID uuid.UUID
+	companyID      *uuid.UUID
+	events         chan BinUpdateEvent
+}
+
+// Client is a single WebSocket connection subscribed to the hub
+type Client struct {
+	hub            *Hub
+	conn           *websocket.Conn
+	send           chan []byte
+	organizationID uuid.UUID
+	companyID      *uuid.UUID
+}
+
+// Hub maintains the set of connected clients and broadcasts bin updates to them
+type Hub struct {
+	clients    map[*Client]bool
+	register   chan *Client
+	unregister chan *Client
+	broadcast  chan message
+	done       chan struct{}
+	mu         sync.RWMutex
+
+	subsMu        sync.RWMutex
+	subscriptions map[*subscription]struct{}
+}
+
+// NewHub creates a new Hub
+func NewHub() *Hub {
+	return &Hub{
+		clients:    make(map[*Client]bool),
+		register:   make(chan *Client),
+		unregister: make(chan *Client),
+		broadcast:  make(chan message, 256),
+		done:       make(chan struct{}),
+
+		subscriptions: make(map[*subscription]struct{}),
+	}
+}
+
+// Run processes client registrations and broadcasts until the context is done
+func (h *Hub) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			close(h.done)
+			h.mu.Lock()
+			for client := range h.clients {
+				close(client.send)
+				delete(h.clients, client)
+			}
+			h.mu.Unlock()
+			return
+
+		case client := <-h.register:
+			h.mu.Lock()
+			h.clients[client] = true
+			h.mu.Unlock()
+
+		case client := <-h.unregister:
+			h.mu.Lock()
+			if _, ok := h.clients[client]; ok {
+				delete(h.clients, client)
+				close(client.send)
+			}
+			h.mu.Unlock()
+
+		case msg := <-h.broadcast:
+			h.mu.Lock()
+			for client := range h.clients {
+				if !client.accepts(msg) {
+					continue
+				}
+				select {
+				case client.send <- msg.data:
+				default:
+					// Client is too slow to keep up, drop it
+					delete(h.clients, client)
+					close(client.send)
+				}
+			}
+			h.mu.Unlock()
+			h.notifySubscriptions(msg)
+		}
+	}
+}
+
+// ClientCount returns the number of connected clients
+func (h *Hub) ClientCount() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
+
+// PublishBinUpdate broadcasts the current state of a bin to subscribed clients
+func (h *Hub) PublishBinUpdate(bin *models.Bin) {
+	event := BinUpdateEvent{
+		Type:            "bin.fill_level",
+		BinID:           bin.ID,
+		DeviceID:        bin.DeviceID,
+		CompanyID:       bin.CompanyID,
+		FillLevel:       bin.FillLevel,
+		Latitude:        bin.Latitude,
+		Longitude:       bin.Longitude,
+		PredictedFullAt: bin.PredictedFullAt,
+		Timestamp:       time.Now().UTC(),
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Failed to marshal bin update: %v", err)
+		return
+	}
+
+	select {
+	case h.broadcast <- message{organizationID: bin.OrganizationID, companyID: bin.CompanyID, event: event, data: data}:
+	default:
+		log.Printf("Realtime hub backlog full, dropping update for bin %s", bin.DeviceID)
+	}
+}
+
+// Subscribe registers an in-process consumer of the bin updates of an
+// organization. A nil companyID receives bins of every company. Updates are
+// dropped while the consumer lags behind; the returned function must be called
+// to release the subscription.
+func (h *Hub) Subscribe(organizationID uuid.UUID, companyID *uuid.UUID) (<-chan BinUpdateEvent, func()) {
+	sub := &subscription{
+		organizationID: organizationID,
+		companyID:      companyID,
+		events:         make(chan BinUpdateEvent, sendBufferSize),
+	}
+
+	h.subsMu.Lock()
+	h.subscriptions[sub] = struct{}{}
+	h.subsMu.Unlock()
+
+	unsubscribe := func() {
+		h.subsMu.Lock()
+		defer h.subsMu.Unlock()
+		delete(h.subscriptions, sub)
+	}
+	return sub.events, unsubscribe
+}
+
+// notifySubscriptions hands a broadcast to the in-process subscribers
+func (h *Hub) notifySubscriptions(msg message) {
+	h.subsMu.RLock()
+	defer h.subsMu.RUnlock()
+	for sub := range h.subscriptions {
+		if sub.organizationID != msg.organizationID || !acceptsCompany(sub.companyID, msg.companyID) {
+			continue
+		}
+		select {
+		case sub.events <- msg.event:
+		default:
+			log.Printf("Realtime subscriber lagging, dropping update for bin %s", msg.event.DeviceID)
+		}
+	}
+}
+
+// Serve registers a new connection with the hub and starts its pumps. The
+// client only receives the bins of its organization; a nil companyID
+// subscribes it to bins of every company.
+func (h *Hub) Serve(conn *websocket.Conn, organizationID uuid.UUID, companyID *uuid.UUID) {
+	client := &Client{
+		hub:            h,
+		conn:           conn,
+		send:           make(chan []byte, sendBufferSize),
+		organizationID: organizationID,
+		companyID:      companyID,
+	}
+	select {
+	case h.register <- client:
+	case <-h.done:
+		conn.Close()
+		return
+	}
+
+	go client.writePump()
+	go client.readPump()
+}
+
+// accepts returns true if the client is subscribed to the organization and
+// company of a broadcast
+func (c *Client) accepts(msg message) bool {
+	return c.organizationID == msg.organizationID && acceptsCompany(c.companyID, msg.companyID)
+}
+
+// acceptsCompany returns true if a consumer filtering on filter receives
+// updates of bins belonging to companyID; a nil filter receives everything
+func acceptsCompany(filter, companyID *uuid.UUID) bool {
+	if filter == nil {
+		return true
+	}
+	return companyID != nil && *companyID == *filter
+}
+
+// readPump drains incoming messages so control frames are processed and
+// unregisters the client when the connection closes
+func (c *Client) readPump() {
+	defer func() {
+		select {
+		case c.hub.unregister <- c:
+		case <-c.hub.done:
+		}
+		c.conn.Close()
+	}()
+
+	c.conn.SetReadLimit(512)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		if _, _, err := c.conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// writePump pushes queued messages and periodic pings to the connection
+func (c *Client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case data, ok := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
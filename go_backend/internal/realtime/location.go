@@ -0,0 +1,87 @@
+package realtime
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// locationBufferSize is the number of location events queued per subscriber;
+// older positions are dropped in favour of newer ones when it is full
+const locationBufferSize = 16
+
+// DriverLocationEvent is pushed to subscribers when a driver reports a new position
+type DriverLocationEvent struct {
+	DriverID  uuid.UUID `json:"driver_id"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// LocationBroker fans out driver location updates to per-driver subscribers
+type LocationBroker struct {
+	mu          sync.RWMutex
+	subscribers map[uuid.UUID]map[chan DriverLocationEvent]struct{}
+}
+
+// NewLocationBroker creates a new LocationBroker
+func NewLocationBroker() *LocationBroker {
+	return &LocationBroker{
+		subscribers: make(map[uuid.UUID]map[chan DriverLocationEvent]struct{}),
+	}
+}
+
+// Subscribe registers interest in a driver's location. The returned function
+// must be called to release the subscription.
+func (b *LocationBroker) Subscribe(driverID uuid.UUID) (<-chan DriverLocationEvent, func()) {
+	ch := make(chan DriverLocationEvent, locationBufferSize)
+
+	b.mu.Lock()
+	if b.subscribers[driverID] == nil {
+		b.subscribers[driverID] = make(map[chan DriverLocationEvent]struct{})
+	}
+	b.subscribers[driverID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if subs, ok := b.subscribers[driverID]; ok {
+			delete(subs, ch)
+			if len(subs) == 0 {
+				delete(b.subscribers, driverID)
+			}
+		}
+	}
+
+	return ch, unsubscribe
+}
+
+// Publish sends a location update to every subscriber of the driver
+func (b *LocationBroker) Publish(driverID uuid.UUID, latitude, longitude float64) {
+	event := DriverLocationEvent{
+		DriverID:  driverID,
+		Latitude:  latitude,
+		Longitude: longitude,
+		Timestamp: time.Now().UTC(),
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for ch := range b.subscribers[driverID] {
+		select {
+		case ch <- event:
+		default:
+			// Subscriber is lagging; drop its oldest position and retry once
+			select {
+			case <-ch:
+			default:
+			}
+			select {
+			case ch <- event:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// APIKeyRepository handles API key data operations
+type APIKeyRepository struct {
+	db *DB
+}
+
+// NewAPIKeyRepository creates a new APIKeyRepository instance
+func NewAPIKeyRepository(db *DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// Create creates a new API key in the organization of ctx
+func (r *APIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	assignOrganization(ctx, &key.OrganizationID)
+	query := `
+		INSERT INTO api_keys (organization_id, name, key_prefix, key_hash, role, company_id, rate_limit_per_minute, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at`
+
+	return r.db.QueryRowxContext(ctx, query,
+		key.OrganizationID,
+		key.Name,
+		key.KeyPrefix,
+		key.KeyHash,
+		key.Role,
+		key.CompanyID,
+		key.RateLimitPerMinute,
+		key.CreatedBy,
+		key.ExpiresAt,
+	).Scan(&key.ID, &key.CreatedAt)
+}
+
+// GetByID retrieves an API key by ID within the organization of ctx
+func (r *APIKeyRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.APIKey, error) {
+	var key models.APIKey
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `SELECT * FROM api_keys WHERE id = $1` + tenant
+
+	err := r.db.GetContext(ctx, &key, query, append([]interface{}{id}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &key, err
+}
+
+// GetByHash retrieves an API key of any organization by the hash of its
+// plaintext value
+func (r *APIKeyRepository) GetByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	var key models.APIKey
+	query := `SELECT * FROM api_keys WHERE key_hash = $1`
+
+	err := r.db.GetContext(ctx, &key, query, hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &key, err
+}
+
+// List retrieves the API keys of the organization of ctx with pagination,
+// newest first
+func (r *APIKeyRepository) List(ctx context.Context, page Page) ([]models.APIKey, PageResult, error) {
+	q := &listQuery{from: "api_keys"}
+	q.tenant(ctx, "organization_id")
+	return listPage(ctx, r.db, q, page, func(k models.APIKey) Cursor {
+		return Cursor{Keys: []string{timeKey(k.CreatedAt)}, ID: k.ID}
+	}, true, "created_at")
+}
+
+// Revoke revokes an API key within the organization of ctx; revoking an
+// already revoked key keeps the original time
+func (r *APIKeyRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, CURRENT_TIMESTAMP) WHERE id = $1` + tenant
+	_, err := r.db.ExecContext(ctx, query, append([]interface{}{id}, args...)...)
+	return err
+}
+
+// TouchLastUsed records that a key was used, at most once a minute
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE api_keys SET last_used_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < CURRENT_TIMESTAMP - INTERVAL '1 minute')`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// BinReadingRepository handles bin fill-level history
+type BinReadingRepository struct {
+	db *DB
+}
+
+// NewBinReadingRepository creates a new BinReadingRepository instance
+func NewBinReadingRepository(db *DB) *BinReadingRepository {
+	return &BinReadingRepository{db: db}
+}
+
+// Create records a fill-level reading for a bin
+func (r *BinReadingRepository) Create(ctx context.Context, reading *models.BinReading) error {
+	query := `
+		INSERT INTO bin_readings (bin_id, fill_level)
+		VALUES ($1, $2)
+		RETURNING id, recorded_at`
+
+	return r.db.QueryRowxContext(ctx, query, reading.BinID, reading.FillLevel).
+		Scan(&reading.ID, &reading.RecordedAt)
+}
+
+// ListSince retrieves the readings of a bin of the organization of ctx
+// recorded after the given time, oldest first
+func (r *BinReadingRepository) ListSince(ctx context.Context, binID uuid.UUID, since time.Time) ([]models.BinReading, error) {
+	var readings []models.BinReading
+	owner, args := ownerCondition(ctx, "bin_id", "bins", 3)
+	query := `SELECT * FROM bin_readings WHERE bin_id = $1 AND recorded_at > $2` + owner + ` ORDER BY recorded_at ASC`
+	err := r.db.SelectContext(ctx, &readings, query, append([]interface{}{binID, since}, args...)...)
+	return readings, err
+}
@@ -4,31 +4,70 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"log"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/smartwaste/backend/internal/cache"
 	"github.com/smartwaste/backend/internal/models"
 )
 
 // BinRepository handles bin data operations
 type BinRepository struct {
-	db *sqlx.DB
+	db    dbtx
+	cache cache.Cache
 }
 
-// NewBinRepository creates a new BinRepository instance
-func NewBinRepository(db *sqlx.DB) *BinRepository {
-	return &BinRepository{db: db}
+// NewBinRepository creates a new BinRepository instance; statistics and the
+// bins needing collection are read through c
+func NewBinRepository(db *DB, c cache.Cache) *BinRepository {
+	return &BinRepository{db: db, cache: c}
 }
 
-// Create creates a new bin
+// WithTx runs fn in a database transaction; use Tx to bind repositories to it
+func (r *BinRepository) WithTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	return withTx(ctx, r.db, fn)
+}
+
+// Tx returns a copy of the repository that runs its queries in tx
+func (r *BinRepository) Tx(tx *sqlx.Tx) *BinRepository {
+	return &BinRepository{db: tx, cache: r.cache}
+}
+
+// InvalidateCache drops the cached entries derived from bin fill levels,
+// deployment-wide and of the organizations whose bins changed. Writes made
+// through a transaction-bound copy do not invalidate on their own; call this
+// once the transaction has committed.
+func (r *BinRepository) InvalidateCache(ctx context.Context, organizationIDs ...uuid.UUID) {
+	keys := append([]string{}, cache.BinKeys...)
+	for _, id := range organizationIDs {
+		keys = append(keys, cache.OrganizationKeys(id, cache.BinKeys...)...)
+	}
+	if err := r.cache.Delete(ctx, keys...); err != nil {
+		log.Printf("Failed to invalidate bin cache: %v", err)
+	}
+}
+
+// invalidate drops the bin cache after a write outside a transaction
+func (r *BinRepository) invalidate(ctx context.Context, organizationIDs ...uuid.UUID) {
+	if _, inTx := r.db.(*sqlx.Tx); !inTx {
+		r.InvalidateCache(ctx, organizationIDs...)
+	}
+}
+
+// Create creates a new bin, in the organization of ctx when it is confined to one
 func (r *BinRepository) Create(ctx context.Context, bin *models.Bin) error {
+	assignOrganization(ctx, &bin.OrganizationID)
 	query := `
-		INSERT INTO bins (device_id, location_name, latitude, longitude, waste_type, capacity_liters, company_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, fill_level, last_updated_at, is_active, created_at`
+		INSERT INTO bins (organization_id, device_id, location_name, latitude, longitude, waste_type, capacity_liters, company_id, collection_threshold, alert_threshold, max_weight_kg, device_group, hardware_serial)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id, fill_level, last_updated_at, is_active, status, zone_id, created_at`
 
-	return r.db.QueryRowxContext(ctx, query,
+	err := r.db.QueryRowxContext(ctx, query,
+		bin.OrganizationID,
 		bin.DeviceID,
 		bin.LocationName,
 		bin.Latitude,
@@ -36,41 +75,118 @@ func (r *BinRepository) Create(ctx context.Context, bin *models.Bin) error {
 		bin.WasteType,
 		bin.CapacityLiters,
 		bin.CompanyID,
-	).Scan(&bin.ID, &bin.FillLevel, &bin.LastUpdatedAt, &bin.IsActive, &bin.CreatedAt)
+		bin.CollectionThreshold,
+		bin.AlertThreshold,
+		bin.MaxWeightKg,
+		bin.DeviceGroup,
+		bin.HardwareSerial,
+	).Scan(&bin.ID, &bin.FillLevel, &bin.LastUpdatedAt, &bin.IsActive, &bin.Status, &bin.ZoneID, &bin.CreatedAt)
+	if err == nil {
+		r.invalidate(ctx, bin.OrganizationID)
+	}
+	return err
 }
 
-// GetByID retrieves a bin by ID
+// GetByID retrieves a bin by ID, within the organization and company scope of ctx
 func (r *BinRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Bin, error) {
 	var bin models.Bin
-	query := `SELECT * FROM bins WHERE id = $1`
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	scope, scopeArgs := scopeCondition(ctx, "company_id", 2+len(args), false)
+	query := `SELECT * FROM bins WHERE id = $1` + tenant + scope
 
-	err := r.db.GetContext(ctx, &bin, query, id)
+	err := r.db.GetContext(ctx, &bin, query, append(append([]interface{}{id}, args...), scopeArgs...)...)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
 	}
 	return &bin, err
 }
 
-// GetByDeviceID retrieves a bin by device ID
+// GetByIDs retrieves the bins with the given IDs in one query, within the
+// organization and company scope of ctx, in no particular order. IDs without
+// a bin are left out.
+func (r *BinRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]models.Bin, error) {
+	var bins []models.Bin
+	if len(ids) == 0 {
+		return bins, nil
+	}
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	scope, scopeArgs := scopeCondition(ctx, "company_id", 2+len(args), false)
+	query := `SELECT * FROM bins WHERE id = ANY($1)` + tenant + scope
+	err := r.db.SelectContext(ctx, &bins, query, append(append([]interface{}{pq.Array(ids)}, args...), scopeArgs...)...)
+	return bins, err
+}
+
+// GetByDeviceID retrieves a bin by device ID within the organization of ctx
 func (r *BinRepository) GetByDeviceID(ctx context.Context, deviceID string) (*models.Bin, error) {
 	var bin models.Bin
-	query := `SELECT * FROM bins WHERE device_id = $1`
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `SELECT * FROM bins WHERE device_id = $1` + tenant
 
-	err := r.db.GetContext(ctx, &bin, query, deviceID)
+	err := r.db.GetContext(ctx, &bin, query, append([]interface{}{deviceID}, args...)...)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
 	}
 	return &bin, err
 }
 
-// Update updates a bin
+// GetByHardwareSerial retrieves the bin a sensor serial is registered on,
+// within the organization of ctx
+func (r *BinRepository) GetByHardwareSerial(ctx context.Context, serial string) (*models.Bin, error) {
+	var bin models.Bin
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `SELECT * FROM bins WHERE hardware_serial = $1` + tenant
+
+	err := r.db.GetContext(ctx, &bin, query, append([]interface{}{serial}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &bin, err
+}
+
+// RecordProvisioning records the client certificate issued to a bin's sensor.
+// It returns ErrNotFound when the bin was provisioned in the meantime.
+func (r *BinRepository) RecordProvisioning(ctx context.Context, bin *models.Bin, certificateSerial string, expiresAt time.Time) error {
+	query := `
+		UPDATE bins
+		SET provisioned_at = CURRENT_TIMESTAMP, certificate_serial = $1, certificate_expires_at = $2
+		WHERE id = $3 AND provisioned_at IS NULL
+		RETURNING provisioned_at`
+	err := r.db.QueryRowxContext(ctx, query, certificateSerial, expiresAt, bin.ID).Scan(&bin.ProvisionedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	bin.CertificateSerial, bin.CertExpiresAt = &certificateSerial, &expiresAt
+	r.invalidate(ctx, bin.OrganizationID)
+	return nil
+}
+
+// ResetProvisioning lets the sensor of a bin provision itself again
+func (r *BinRepository) ResetProvisioning(ctx context.Context, bin *models.Bin) error {
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `UPDATE bins SET provisioned_at = NULL WHERE id = $1` + tenant
+	if err := affected(r.db.ExecContext(ctx, query, append([]interface{}{bin.ID}, args...)...)); err != nil {
+		return err
+	}
+	bin.ProvisionedAt = nil
+	r.invalidate(ctx, bin.OrganizationID)
+	return nil
+}
+
+// Update updates a bin within the organization of ctx, refreshing the
+// status that follows is_active and the zone that follows the location
 func (r *BinRepository) Update(ctx context.Context, bin *models.Bin) error {
+	tenant, args := tenantCondition(ctx, "organization_id", 14)
 	query := `
 		UPDATE bins
-		SET location_name = $1, latitude = $2, longitude = $3, waste_type = $4, capacity_liters = $5, is_active = $6, company_id = $7
-		WHERE id = $8`
+		SET location_name = $1, latitude = $2, longitude = $3, waste_type = $4, capacity_liters = $5, is_active = $6, company_id = $7,
+			collection_threshold = $8, alert_threshold = $9, max_weight_kg = $10, device_group = $11, hardware_serial = $12
+		WHERE id = $13` + tenant + `
+		RETURNING status, zone_id`
 
-	_, err := r.db.ExecContext(ctx, query,
+	err := r.db.QueryRowxContext(ctx, query, append([]interface{}{
 		bin.LocationName,
 		bin.Latitude,
 		bin.Longitude,
@@ -78,83 +194,562 @@ func (r *BinRepository) Update(ctx context.Context, bin *models.Bin) error {
 		bin.CapacityLiters,
 		bin.IsActive,
 		bin.CompanyID,
+		bin.CollectionThreshold,
+		bin.AlertThreshold,
+		bin.MaxWeightKg,
+		bin.DeviceGroup,
+		bin.HardwareSerial,
 		bin.ID,
-	)
+	}, args...)...).Scan(&bin.Status, &bin.ZoneID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err == nil {
+		r.invalidate(ctx, bin.OrganizationID)
+	}
 	return err
 }
 
-// UpdateFillLevel updates a bin's fill level
+// UpdateThresholdsByCompany sets the thresholds of every bin of a company
+// within the organization of ctx; nil thresholds are left unchanged. It
+// returns the number of bins updated.
+func (r *BinRepository) UpdateThresholdsByCompany(ctx context.Context, companyID uuid.UUID, collectionThreshold, alertThreshold *int) (int64, error) {
+	tenant, args := tenantCondition(ctx, "organization_id", 4)
+	query := `
+		UPDATE bins
+		SET collection_threshold = COALESCE($1, collection_threshold), alert_threshold = COALESCE($2, alert_threshold)
+		WHERE company_id = $3` + tenant + `
+		RETURNING organization_id`
+
+	var organizationIDs []uuid.UUID
+	err := r.db.SelectContext(ctx, &organizationIDs, query, append([]interface{}{collectionThreshold, alertThreshold, companyID}, args...)...)
+	if err != nil {
+		return 0, err
+	}
+	r.invalidate(ctx, organizationIDs...)
+	return int64(len(organizationIDs)), nil
+}
+
+// UpdateFillLevel updates a bin's fill level within the organization of ctx
 func (r *BinRepository) UpdateFillLevel(ctx context.Context, deviceID string, fillLevel int) error {
-	query := `UPDATE bins SET fill_level = $1, last_updated_at = CURRENT_TIMESTAMP WHERE device_id = $2`
-	_, err := r.db.ExecContext(ctx, query, fillLevel, deviceID)
+	tenant, args := tenantCondition(ctx, "organization_id", 3)
+	query := `
+		UPDATE bins
+		SET fill_level = $1, last_updated_at = CURRENT_TIMESTAMP, is_offline = false, offline_since = NULL
+		WHERE device_id = $2` + tenant + `
+		RETURNING organization_id`
+	var organizationID uuid.UUID
+	err := r.db.QueryRowxContext(ctx, query, append([]interface{}{fillLevel, deviceID}, args...)...).Scan(&organizationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err == nil {
+		r.invalidate(ctx, organizationID)
+	}
 	return err
 }
 
-// MarkCollected marks a bin as collected
-func (r *BinRepository) MarkCollected(ctx context.Context, id uuid.UUID) error {
-	query := `UPDATE bins SET fill_level = 0, last_collection_at = $1, last_updated_at = CURRENT_TIMESTAMP WHERE id = $2`
-	_, err := r.db.ExecContext(ctx, query, time.Now(), id)
+// UpdateFillLevels updates the fill levels of several bins in one statement,
+// as UpdateFillLevel does for one. Devices without a bin are skipped.
+func (r *BinRepository) UpdateFillLevels(ctx context.Context, updates []models.FillLevelUpdate) error {
+	if len(updates) == 0 {
+		return nil
+	}
+	deviceIDs := make([]string, len(updates))
+	fillLevels := make([]int64, len(updates))
+	receivedAt := make([]string, len(updates))
+	for i, update := range updates {
+		deviceIDs[i] = update.DeviceID
+		fillLevels[i] = int64(update.FillLevel)
+		receivedAt[i] = update.ReceivedAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	tenant, args := tenantCondition(ctx, "b.organization_id", 4)
+	query := `
+		WITH updated AS (
+			UPDATE bins b
+			SET fill_level = u.fill_level, last_updated_at = u.received_at, is_offline = false, offline_since = NULL
+			FROM unnest($1::text[], $2::int[], $3::timestamptz[]) AS u(device_id, fill_level, received_at)
+			WHERE b.device_id = u.device_id` + tenant + `
+			RETURNING b.organization_id
+		)
+		SELECT DISTINCT organization_id FROM updated`
+	var organizationIDs []uuid.UUID
+	err := r.db.SelectContext(ctx, &organizationIDs, query, append([]interface{}{pq.Array(deviceIDs), pq.Array(fillLevels), pq.Array(receivedAt)}, args...)...)
+	if err != nil {
+		return err
+	}
+	r.invalidate(ctx, organizationIDs...)
+	return nil
+}
+
+// UpdateTelemetry stores sensor health telemetry, keeping previous values for
+// fields the update does not carry
+func (r *BinRepository) UpdateTelemetry(ctx context.Context, deviceID string, update *models.BinStatusUpdate) error {
+	tenant, args := tenantCondition(ctx, "organization_id", 8)
+	query := `
+		UPDATE bins
+		SET battery_level = COALESCE($1, battery_level),
+			rssi = COALESCE($2, rssi),
+			temperature_c = COALESCE($3, temperature_c),
+			firmware_version = COALESCE($4, firmware_version),
+			weight_kg = COALESCE($5, weight_kg),
+			lid_open = COALESCE($6, lid_open)
+		WHERE device_id = $7` + tenant
+
+	_, err := r.db.ExecContext(ctx, query, append([]interface{}{
+		update.BatteryLevel,
+		update.RSSI,
+		update.Temperature,
+		update.FirmwareVersion,
+		update.WeightKg,
+		update.LidOpen,
+		deviceID,
+	}, args...)...)
 	return err
 }
 
-// GetBinsNeedingCollection retrieves bins with fill level above threshold
-func (r *BinRepository) GetBinsNeedingCollection(ctx context.Context, threshold int) ([]models.Bin, error) {
+// RecordConfigAck stores the configuration the sensor of a device last
+// acknowledged. An acknowledgement older than the one stored is ignored.
+func (r *BinRepository) RecordConfigAck(ctx context.Context, deviceID string, ack *models.DeviceConfigAck) error {
+	query := `
+		UPDATE bins
+		SET config_version = $1, config_status = $2, config_acked_at = CURRENT_TIMESTAMP
+		WHERE device_id = $3 AND (config_version IS NULL OR config_version <= $1)
+		RETURNING organization_id`
+	var organizationID uuid.UUID
+	err := r.db.QueryRowxContext(ctx, query, ack.Version, ack.Status, deviceID).Scan(&organizationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err == nil {
+		r.invalidate(ctx, organizationID)
+	}
+	return err
+}
+
+// GetBinsAwaitingDispatch retrieves bins in service at or above their
+// collection threshold that have no pending or in-progress collection,
+// fullest first, within the organization of ctx. Bins under maintenance are
+// left out.
+func (r *BinRepository) GetBinsAwaitingDispatch(ctx context.Context) ([]models.Bin, error) {
+	var bins []models.Bin
+	tenant, args := tenantCondition(ctx, "b.organization_id", 1)
+	query := `
+		SELECT * FROM bins b
+		WHERE b.status = 'active' AND b.fill_level >= b.collection_threshold` + tenant + `
+			AND NOT EXISTS (
+				SELECT 1 FROM collections c
+				WHERE c.bin_id = b.id AND c.status IN ('pending', 'in_progress')
+			)
+		ORDER BY b.fill_level DESC`
+	err := r.db.SelectContext(ctx, &bins, query, args...)
+	return bins, err
+}
+
+// GetLowBatteryBins retrieves active bins whose battery level is below the
+// threshold, within the organization and company scope of ctx
+func (r *BinRepository) GetLowBatteryBins(ctx context.Context, threshold int) ([]models.Bin, error) {
 	var bins []models.Bin
-	query := `SELECT * FROM bins WHERE is_active = true AND fill_level >= $1 ORDER BY fill_level DESC`
-	err := r.db.SelectContext(ctx, &bins, query, threshold)
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	scope, scopeArgs := scopeCondition(ctx, "company_id", 2+len(args), false)
+	query := `SELECT * FROM bins WHERE is_active = true AND battery_level < $1` + tenant + scope + ` ORDER BY battery_level ASC`
+	err := r.db.SelectContext(ctx, &bins, query, append(append([]interface{}{threshold}, args...), scopeArgs...)...)
 	return bins, err
 }
 
-// List retrieves all bins with pagination
-func (r *BinRepository) List(ctx context.Context, limit, offset int) ([]models.Bin, error) {
+// MarkOffline flags active bins that have not reported since the cutoff as
+// offline and returns the bins that were newly flagged
+func (r *BinRepository) MarkOffline(ctx context.Context, cutoff time.Time) ([]models.Bin, error) {
+	var bins []models.Bin
+	query := `
+		UPDATE bins
+		SET is_offline = true, offline_since = CURRENT_TIMESTAMP
+		WHERE is_active = true AND is_offline = false AND last_updated_at < $1
+		RETURNING *`
+	err := r.db.SelectContext(ctx, &bins, query, cutoff)
+	return bins, err
+}
+
+// MarkDeviceOffline flags the active bin of a device that dropped its
+// connection as offline. It returns nil when the device is unknown or its
+// bin was already offline.
+func (r *BinRepository) MarkDeviceOffline(ctx context.Context, deviceID string) (*models.Bin, error) {
+	var bin models.Bin
+	query := `
+		UPDATE bins
+		SET is_offline = true, offline_since = CURRENT_TIMESTAMP
+		WHERE device_id = $1 AND is_active = true AND is_offline = false
+		RETURNING *`
+	err := r.db.GetContext(ctx, &bin, query, deviceID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.invalidate(ctx, bin.OrganizationID)
+	return &bin, nil
+}
+
+// GetOfflineBins retrieves active bins currently flagged as offline, within
+// the organization and company scope of ctx
+func (r *BinRepository) GetOfflineBins(ctx context.Context) ([]models.Bin, error) {
 	var bins []models.Bin
-	query := `SELECT * FROM bins WHERE is_active = true ORDER BY created_at DESC LIMIT $1 OFFSET $2`
-	err := r.db.SelectContext(ctx, &bins, query, limit, offset)
+	tenant, args := tenantCondition(ctx, "organization_id", 1)
+	scope, scopeArgs := scopeCondition(ctx, "company_id", 1+len(args), false)
+	query := `SELECT * FROM bins WHERE is_active = true AND is_offline = true` + tenant + scope + ` ORDER BY last_updated_at ASC`
+	err := r.db.SelectContext(ctx, &bins, query, append(args, scopeArgs...)...)
+	return bins, err
+}
+
+// FindNearby retrieves available bins within the query radius, nearest first,
+// within the organization of ctx if any. Available bins are active, online
+// and not full.
+func (r *BinRepository) FindNearby(ctx context.Context, q models.NearbyBinQuery) ([]models.NearbyBin, error) {
+	var bins []models.NearbyBin
+	tenant, args := tenantCondition(ctx, "organization_id", 6)
+	// Haversine distance in meters; LEAST guards acos against rounding above 1
+	query := `
+		SELECT * FROM (
+			SELECT *,
+				(6371000 * acos(LEAST(1, cos(radians($1)) * cos(radians(latitude)) * cos(radians(longitude) - radians($2)) + sin(radians($1)) * sin(radians(latitude))))) AS distance_m
+			FROM bins
+			WHERE is_active = true AND is_offline = false AND fill_level < 100
+				AND ($4 = '' OR waste_type = $4)` + tenant + `
+		) nearby
+		WHERE distance_m <= $3
+		ORDER BY distance_m ASC
+		LIMIT $5`
+
+	err := r.db.SelectContext(ctx, &bins, query, append([]interface{}{q.Latitude, q.Longitude, q.RadiusM, q.WasteType, q.Limit}, args...)...)
 	return bins, err
 }
 
-// ListByCompany retrieves bins for a specific company
+// Heatmap aggregates the active bins of the organization of ctx inside the
+// query bounding box into a grid of q.Grid x q.Grid cells, returning only the
+// cells that contain bins. Bins on the upper edges of the box fall into the
+// last row or column.
+func (r *BinRepository) Heatmap(ctx context.Context, q models.HeatmapQuery) ([]models.HeatmapCell, error) {
+	cellLat, cellLng := q.CellSize()
+	cells := `
+		LEAST(FLOOR((b.latitude - $2) / $5)::int, $7 - 1) AS cell_row,
+		LEAST(FLOOR((b.longitude - $1) / $6)::int, $7 - 1) AS cell_column,
+		AVG(b.latitude)::float8 AS latitude,
+		AVG(b.longitude)::float8 AS longitude`
+	inBox := `b.is_active = true
+		AND b.latitude BETWEEN $2 AND $4
+		AND b.longitude BETWEEN $1 AND $3`
+	args := []interface{}{q.BBox.MinLongitude, q.BBox.MinLatitude, q.BBox.MaxLongitude, q.BBox.MaxLatitude, cellLat, cellLng, q.Grid}
+	if scope := tenantScope(ctx); scope != nil {
+		args = append(args, *scope)
+		inBox += fmt.Sprintf(" AND b.organization_id = $%d", len(args))
+	}
+
+	var query string
+	switch q.Metric {
+	case models.HeatmapFillLevel:
+		query = `SELECT` + cells + `,
+				COUNT(*) AS bins,
+				AVG(b.fill_level)::float8 AS value
+			FROM bins b
+			WHERE ` + inBox + `
+			GROUP BY 1, 2
+			ORDER BY 1, 2`
+	case models.HeatmapCollections:
+		// Aggregated per bin first so a cell is positioned by its bins, not
+		// weighted towards the ones collected most often
+		query = `SELECT` + cells + `,
+				COUNT(*) AS bins,
+				SUM(b.collections)::float8 AS value
+			FROM (
+				SELECT b.id, b.organization_id, b.latitude, b.longitude, b.is_active, COUNT(*) AS collections
+				FROM collections c
+				JOIN bins b ON b.id = c.bin_id
+				WHERE c.status = 'completed' AND c.completed_at >= $%[1]d AND c.completed_at < $%[2]d
+				GROUP BY b.id
+			) b
+			WHERE ` + inBox + `
+			GROUP BY 1, 2
+			ORDER BY 1, 2`
+		args = append(args, q.Period.From, q.Period.To)
+		query = fmt.Sprintf(query, len(args)-1, len(args))
+	default:
+		return nil, fmt.Errorf("unsupported heatmap metric %q", q.Metric)
+	}
+
+	var result []models.HeatmapCell
+	err := r.db.SelectContext(ctx, &result, query, args...)
+	return result, err
+}
+
+// MarkCollected marks a bin as collected
+func (r *BinRepository) MarkCollected(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE bins SET fill_level = 0, last_collection_at = $1, predicted_full_at = NULL, last_updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+		RETURNING organization_id`
+	return r.updateReturningOrganization(ctx, query, time.Now(), id)
+}
+
+// Flag flags a bin for inspection, returning false if it was already flagged
+func (r *BinRepository) Flag(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `
+		UPDATE bins SET is_flagged = true, flagged_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND is_flagged = false
+		RETURNING organization_id`
+	var organizationID uuid.UUID
+	err := r.db.QueryRowxContext(ctx, query, id).Scan(&organizationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	r.invalidate(ctx, organizationID)
+	return true, nil
+}
+
+// Unflag clears the inspection flag of a bin
+func (r *BinRepository) Unflag(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE bins SET is_flagged = false, flagged_at = NULL
+		WHERE id = $1 AND is_flagged = true
+		RETURNING organization_id`
+	return r.updateReturningOrganization(ctx, query, id)
+}
+
+// updateReturningOrganization runs an update of at most one bin returning its
+// organization, then drops that organization's cached entries. Updating no
+// bin is not an error.
+func (r *BinRepository) updateReturningOrganization(ctx context.Context, query string, args ...interface{}) error {
+	var organizationID uuid.UUID
+	err := r.db.QueryRowxContext(ctx, query, args...).Scan(&organizationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err == nil {
+		r.invalidate(ctx, organizationID)
+	}
+	return err
+}
+
+// UpdatePrediction stores the predicted time at which a bin becomes full
+func (r *BinRepository) UpdatePrediction(ctx context.Context, id uuid.UUID, predictedFullAt *time.Time) error {
+	query := `UPDATE bins SET predicted_full_at = $1 WHERE id = $2`
+	_, err := r.db.ExecContext(ctx, query, predictedFullAt, id)
+	return err
+}
+
+// GetBinsNeedingCollection retrieves bins with fill level at or above threshold,
+// or each bin's own collection threshold if threshold is nil, within the
+// organization and company scope of ctx. Bins under maintenance are left out. Only the per-bin threshold listing
+// outside a company scope is cached, per organization.
+func (r *BinRepository) GetBinsNeedingCollection(ctx context.Context, threshold *int) ([]models.Bin, error) {
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	scope, scopeArgs := scopeCondition(ctx, "company_id", 2+len(args), false)
+	load := func() ([]models.Bin, error) {
+		var bins []models.Bin
+		query := `SELECT * FROM bins WHERE status = 'active' AND fill_level >= COALESCE($1, collection_threshold)` + tenant + scope + ` ORDER BY fill_level DESC`
+		err := r.db.SelectContext(ctx, &bins, query, append(append([]interface{}{threshold}, args...), scopeArgs...)...)
+		return bins, err
+	}
+	if threshold != nil || scope != "" {
+		return load()
+	}
+	return cache.Fetch(ctx, r.cache, r.cacheKey(ctx, cache.KeyBinsNeedingCollection), load)
+}
+
+// cacheKey returns the key an entry is cached at for the organization of ctx
+func (r *BinRepository) cacheKey(ctx context.Context, key string) string {
+	if scope := tenantScope(ctx); scope != nil {
+		return cache.OrganizationKey(*scope, key)
+	}
+	return key
+}
+
+// List retrieves active bins with pagination, newest first, within the
+// organization and company scope of ctx
+func (r *BinRepository) List(ctx context.Context, page Page) ([]models.Bin, PageResult, error) {
+	return r.list(ctx, &listQuery{from: "bins", conditions: []string{"is_active = true"}}, page)
+}
+
+// ListByZone retrieves the active bins of a zone like List
+func (r *BinRepository) ListByZone(ctx context.Context, zoneID uuid.UUID, page Page) ([]models.Bin, PageResult, error) {
+	q := &listQuery{from: "bins", conditions: []string{"is_active = true"}}
+	q.where("zone_id = $%d", zoneID)
+	return r.list(ctx, q, page)
+}
+
+func (r *BinRepository) list(ctx context.Context, q *listQuery, page Page) ([]models.Bin, PageResult, error) {
+	q.tenant(ctx, "organization_id")
+	q.scope(ctx, "company_id", false)
+	return listPage(ctx, r.db, q, page, func(b models.Bin) Cursor {
+		return Cursor{Keys: []string{timeKey(b.CreatedAt)}, ID: b.ID}
+	}, true, "created_at")
+}
+
+// ListByCompany retrieves bins for a specific company within the organization of ctx
 func (r *BinRepository) ListByCompany(ctx context.Context, companyID uuid.UUID) ([]models.Bin, error) {
 	var bins []models.Bin
-	query := `SELECT * FROM bins WHERE company_id = $1 AND is_active = true ORDER BY fill_level DESC`
-	err := r.db.SelectContext(ctx, &bins, query, companyID)
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `SELECT * FROM bins WHERE company_id = $1 AND is_active = true` + tenant + ` ORDER BY fill_level DESC`
+	err := r.db.SelectContext(ctx, &bins, query, append([]interface{}{companyID}, args...)...)
 	return bins, err
 }
 
-// Delete deletes a bin (soft delete by setting is_active = false)
+// ListByCompanies retrieves the active bins of several companies within the
+// organization of ctx, fullest first
+func (r *BinRepository) ListByCompanies(ctx context.Context, companyIDs []uuid.UUID) ([]models.Bin, error) {
+	var bins []models.Bin
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `SELECT * FROM bins WHERE company_id = ANY($1) AND is_active = true` + tenant + ` ORDER BY fill_level DESC`
+	err := r.db.SelectContext(ctx, &bins, query, append([]interface{}{pq.Array(companyIDs)}, args...)...)
+	return bins, err
+}
+
+// Each streams every bin of the organization of ctx, ordered by creation
+// time, to fn. Iteration stops at the first error returned by fn.
+func (r *BinRepository) Each(ctx context.Context, fn func(bin *models.Bin) error) error {
+	tenant, args := tenantCondition(ctx, "organization_id", 1)
+	rows, err := r.db.QueryxContext(ctx, `SELECT * FROM bins WHERE true`+tenant+` ORDER BY created_at ASC`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bin models.Bin
+		if err := rows.StructScan(&bin); err != nil {
+			return err
+		}
+		if err := fn(&bin); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Delete deletes a bin within the organization of ctx (soft delete by
+// setting is_active = false)
 func (r *BinRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `UPDATE bins SET is_active = false WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `UPDATE bins SET is_active = false WHERE id = $1` + tenant + ` RETURNING organization_id`
+	return r.updateReturningOrganization(ctx, query, append([]interface{}{id}, args...)...)
+}
+
+// BinStatistics summarizes the fill levels of active bins
+type BinStatistics struct {
+	TotalBins        int     `db:"total_bins" json:"total_bins"`
+	NeedsCollection  int     `db:"needs_collection" json:"needs_collection"`
+	NeedsAlert       int     `db:"needs_alert" json:"needs_alert"`
+	Fill0To25        int     `db:"fill_0_25" json:"fill_0_25"`
+	Fill26To50       int     `db:"fill_26_50" json:"fill_26_50"`
+	Fill51To75       int     `db:"fill_51_75" json:"fill_51_75"`
+	Fill76To100      int     `db:"fill_76_100" json:"fill_76_100"`
+	AverageFillLevel float64 `db:"average_fill_level" json:"average_fill_level"`
+}
+
+// Statistics retrieves the bin statistics of the organization of ctx, served
+// from the cache when enabled; company principals get the statistics of their
+// own bins
+func (r *BinRepository) Statistics(ctx context.Context) (BinStatistics, error) {
+	if scope := companyScope(ctx); scope != nil {
+		return r.CompanyStatistics(ctx, *scope)
+	}
+	return cache.Fetch(ctx, r.cache, r.cacheKey(ctx, cache.KeyBinStatistics), func() (BinStatistics, error) {
+		return r.loadStatistics(ctx)
+	})
 }
 
 // GetStatistics retrieves bin statistics
 func (r *BinRepository) GetStatistics(ctx context.Context) (map[string]interface{}, error) {
-	stats := make(map[string]interface{})
+	s, err := r.Statistics(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"total_bins":         s.TotalBins,
+		"needs_collection":   s.NeedsCollection,
+		"needs_alert":        s.NeedsAlert,
+		"fill_0_25":          s.Fill0To25,
+		"fill_26_50":         s.Fill26To50,
+		"fill_51_75":         s.Fill51To75,
+		"fill_76_100":        s.Fill76To100,
+		"average_fill_level": s.AverageFillLevel,
+	}, nil
+}
+
+// CompanyStatistics retrieves the statistics of a company's active bins
+// within the organization of ctx
+func (r *BinRepository) CompanyStatistics(ctx context.Context, companyID uuid.UUID) (BinStatistics, error) {
+	var stats BinStatistics
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `
+		SELECT
+			COUNT(*) AS total_bins,
+			COUNT(*) FILTER (WHERE status = 'active' AND fill_level >= collection_threshold) AS needs_collection,
+			COUNT(*) FILTER (WHERE fill_level >= alert_threshold) AS needs_alert,
+			COUNT(*) FILTER (WHERE fill_level <= 25) AS fill_0_25,
+			COUNT(*) FILTER (WHERE fill_level BETWEEN 26 AND 50) AS fill_26_50,
+			COUNT(*) FILTER (WHERE fill_level BETWEEN 51 AND 75) AS fill_51_75,
+			COUNT(*) FILTER (WHERE fill_level >= 76) AS fill_76_100,
+			COALESCE(AVG(fill_level), 0)::float8 AS average_fill_level
+		FROM bins WHERE is_active = true AND company_id = $1` + tenant
+	err := r.db.GetContext(ctx, &stats, query, append([]interface{}{companyID}, args...)...)
+	return stats, err
+}
+
+func (r *BinRepository) loadStatistics(ctx context.Context) (BinStatistics, error) {
+	var stats BinStatistics
+	tenant, args := tenantCondition(ctx, "organization_id", 1)
 
 	// Total bins
-	var totalBins int
-	err := r.db.GetContext(ctx, &totalBins, `SELECT COUNT(*) FROM bins WHERE is_active = true`)
+	err := r.db.GetContext(ctx, &stats.TotalBins, `SELECT COUNT(*) FROM bins WHERE is_active = true`+tenant, args...)
 	if err != nil {
-		return nil, err
+		return stats, err
 	}
-	stats["total_bins"] = totalBins
 
-	// Bins needing collection (>80%)
-	var needsCollection int
-	err = r.db.GetContext(ctx, &needsCollection, `SELECT COUNT(*) FROM bins WHERE is_active = true AND fill_level >= 80`)
+	// Bins in service at or above their collection threshold
+	err = r.db.GetContext(ctx, &stats.NeedsCollection, `SELECT COUNT(*) FROM bins WHERE status = 'active' AND fill_level >= collection_threshold`+tenant, args...)
 	if err != nil {
-		return nil, err
+		return stats, err
+	}
+
+	// Bins at or above their alert threshold
+	err = r.db.GetContext(ctx, &stats.NeedsAlert, `SELECT COUNT(*) FROM bins WHERE is_active = true AND fill_level >= alert_threshold`+tenant, args...)
+	if err != nil {
+		return stats, err
 	}
-	stats["needs_collection"] = needsCollection
+
+	// Bins per fill level quartile
+	var fillRanges struct {
+		Low      int `db:"low"`
+		Medium   int `db:"medium"`
+		High     int `db:"high"`
+		Critical int `db:"critical"`
+	}
+	err = r.db.GetContext(ctx, &fillRanges, `
+		SELECT
+			COUNT(*) FILTER (WHERE fill_level <= 25) AS low,
+			COUNT(*) FILTER (WHERE fill_level BETWEEN 26 AND 50) AS medium,
+			COUNT(*) FILTER (WHERE fill_level BETWEEN 51 AND 75) AS high,
+			COUNT(*) FILTER (WHERE fill_level >= 76) AS critical
+		FROM bins WHERE is_active = true`+tenant, args...)
+	if err != nil {
+		return stats, err
+	}
+	stats.Fill0To25 = fillRanges.Low
+	stats.Fill26To50 = fillRanges.Medium
+	stats.Fill51To75 = fillRanges.High
+	stats.Fill76To100 = fillRanges.Critical
 
 	// Average fill level
-	var avgFillLevel float64
-	err = r.db.GetContext(ctx, &avgFillLevel, `SELECT COALESCE(AVG(fill_level), 0) FROM bins WHERE is_active = true`)
+	err = r.db.GetContext(ctx, &stats.AverageFillLevel, `SELECT COALESCE(AVG(fill_level), 0) FROM bins WHERE is_active = true`+tenant, args...)
 	if err != nil {
-		return nil, err
+		return stats, err
 	}
-	stats["average_fill_level"] = avgFillLevel
 
 	return stats, nil
 }
@@ -4,58 +4,73 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/smartwaste/backend/internal/models"
 )
 
 // CollectionRepository handles collection data operations
 type CollectionRepository struct {
-	db *sqlx.DB
+	db dbtx
 }
 
 // NewCollectionRepository creates a new CollectionRepository instance
-func NewCollectionRepository(db *sqlx.DB) *CollectionRepository {
+func NewCollectionRepository(db *DB) *CollectionRepository {
 	return &CollectionRepository{db: db}
 }
 
-// Create creates a new collection
+// WithTx runs fn in a database transaction; use Tx to bind repositories to it
+func (r *CollectionRepository) WithTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	return withTx(ctx, r.db, fn)
+}
+
+// Tx returns a copy of the repository that runs its queries in tx
+func (r *CollectionRepository) Tx(tx *sqlx.Tx) *CollectionRepository {
+	return &CollectionRepository{db: tx}
+}
+
+// Create creates a new collection in the organization of its bin
 func (r *CollectionRepository) Create(ctx context.Context, collection *models.Collection) error {
 	query := `
-		INSERT INTO collections (bin_id, driver_id, fill_level_before, status)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, started_at`
+		INSERT INTO collections (organization_id, bin_id, driver_id, user_id, fill_level_before, status)
+		VALUES ((SELECT organization_id FROM bins WHERE id = $1), $1, $2, $3, $4, $5)
+		RETURNING id, organization_id, started_at`
 
 	return r.db.QueryRowxContext(ctx, query,
 		collection.BinID,
 		collection.DriverID,
+		collection.UserID,
 		collection.FillLevelBefore,
 		models.CollectionStatusPending,
-	).Scan(&collection.ID, &collection.StartedAt)
+	).Scan(&collection.ID, &collection.OrganizationID, &collection.StartedAt)
 }
 
-// GetByID retrieves a collection by ID
+// GetByID retrieves a collection by ID within the organization of ctx
 func (r *CollectionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Collection, error) {
 	var collection models.Collection
-	query := `SELECT * FROM collections WHERE id = $1`
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `SELECT * FROM collections WHERE id = $1` + tenant
 
-	err := r.db.GetContext(ctx, &collection, query, id)
+	err := r.db.GetContext(ctx, &collection, query, append([]interface{}{id}, args...)...)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
 	}
 	return &collection, err
 }
 
-// Update updates a collection
+// Update updates a collection within the organization of ctx
 func (r *CollectionRepository) Update(ctx context.Context, collection *models.Collection) error {
+	tenant, args := tenantCondition(ctx, "organization_id", 8)
 	query := `
 		UPDATE collections
 		SET fill_level_after = $1, weight_kg = $2, qr_code_verified = $3, notes = $4, status = $5, completed_at = $6
-		WHERE id = $7`
+		WHERE id = $7` + tenant
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := r.db.ExecContext(ctx, query, append([]interface{}{
 		collection.FillLevelAfter,
 		collection.WeightKg,
 		collection.QRCodeVerified,
@@ -63,60 +78,154 @@ func (r *CollectionRepository) Update(ctx context.Context, collection *models.Co
 		collection.Status,
 		collection.CompletedAt,
 		collection.ID,
-	)
+	}, args...)...)
 	return err
 }
 
-// Complete marks a collection as completed
-func (r *CollectionRepository) Complete(ctx context.Context, id uuid.UUID, fillLevelAfter int, weightKg *float64, notes *string) error {
+// Complete marks a collection within the organization of ctx as completed
+func (r *CollectionRepository) Complete(ctx context.Context, id uuid.UUID, req *models.CompleteCollectionRequest) error {
 	now := time.Now()
+	tenant, args := tenantCondition(ctx, "organization_id", 9)
 	query := `
 		UPDATE collections
-		SET fill_level_after = $1, weight_kg = $2, notes = $3, status = $4, completed_at = $5
-		WHERE id = $6`
+		SET fill_level_after = $1, weight_kg = $2, notes = $3, before_photo_url = $4, after_photo_url = $5,
+			status = $6, completed_at = $7
+		WHERE id = $8` + tenant
 
-	_, err := r.db.ExecContext(ctx, query, fillLevelAfter, weightKg, notes, models.CollectionStatusCompleted, now, id)
+	_, err := r.db.ExecContext(ctx, query, append([]interface{}{
+		req.FillLevelAfter,
+		req.WeightKg,
+		req.Notes,
+		req.BeforePhotoURL,
+		req.AfterPhotoURL,
+		models.CollectionStatusCompleted,
+		now,
+		id,
+	}, args...)...)
 	return err
 }
 
-// VerifyQRCode verifies QR code for a collection
-func (r *CollectionRepository) VerifyQRCode(ctx context.Context, id uuid.UUID) error {
-	query := `UPDATE collections SET qr_code_verified = true WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+// CompletedBinsSince returns which of the bins a driver completed a
+// collection of since the given time
+func (r *CollectionRepository) CompletedBinsSince(ctx context.Context, driverID uuid.UUID, binIDs []uuid.UUID, since time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if len(binIDs) == 0 {
+		return ids, nil
+	}
+	query := `
+		SELECT DISTINCT bin_id FROM collections
+		WHERE driver_id = $1 AND bin_id = ANY($2) AND status = $3 AND completed_at >= $4`
+
+	err := r.db.SelectContext(ctx, &ids, query, driverID, pq.Array(binIDs), models.CollectionStatusCompleted, since)
+	return ids, err
+}
+
+// Cancel marks a collection within the organization of ctx as cancelled
+func (r *CollectionRepository) Cancel(ctx context.Context, id uuid.UUID, notes *string) error {
+	tenant, args := tenantCondition(ctx, "organization_id", 4)
+	query := `UPDATE collections SET status = $1, notes = COALESCE($2, notes) WHERE id = $3` + tenant
+	_, err := r.db.ExecContext(ctx, query, append([]interface{}{models.CollectionStatusCancelled, notes, id}, args...)...)
+	return err
+}
+
+// Reopen puts a completed collection within the organization of ctx back in
+// progress, returning false if it was not completed
+func (r *CollectionRepository) Reopen(ctx context.Context, id uuid.UUID) (bool, error) {
+	tenant, args := tenantCondition(ctx, "organization_id", 4)
+	query := `UPDATE collections SET status = $1, completed_at = NULL WHERE id = $2 AND status = $3` + tenant
+	result, err := r.db.ExecContext(ctx, query, append([]interface{}{models.CollectionStatusInProgress, id, models.CollectionStatusCompleted}, args...)...)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+// VerifyQRCode verifies QR code for a collection within the organization of
+// ctx, recording how far from the bin it was scanned if known
+func (r *CollectionRepository) VerifyQRCode(ctx context.Context, id uuid.UUID, distanceMeters *float64) error {
+	tenant, args := tenantCondition(ctx, "organization_id", 3)
+	query := `UPDATE collections SET qr_code_verified = true, verification_distance_m = $1 WHERE id = $2` + tenant
+	_, err := r.db.ExecContext(ctx, query, append([]interface{}{distanceMeters, id}, args...)...)
 	return err
 }
 
 // List retrieves all collections with pagination
-func (r *CollectionRepository) List(ctx context.Context, limit, offset int) ([]models.Collection, error) {
-	var collections []models.Collection
-	query := `SELECT * FROM collections ORDER BY started_at DESC LIMIT $1 OFFSET $2`
-	err := r.db.SelectContext(ctx, &collections, query, limit, offset)
-	return collections, err
+func (r *CollectionRepository) List(ctx context.Context, page Page) ([]models.Collection, PageResult, error) {
+	return r.ListFiltered(ctx, models.CollectionFilter{}, page)
+}
+
+// ListFiltered retrieves collections matching the filter with pagination,
+// newest first, within the organization of ctx
+func (r *CollectionRepository) ListFiltered(ctx context.Context, filter models.CollectionFilter, page Page) ([]models.Collection, PageResult, error) {
+	q := &listQuery{from: "collections"}
+	q.tenant(ctx, "organization_id")
+	if filter.DriverID != nil {
+		q.where("driver_id = $%d", *filter.DriverID)
+	}
+	if filter.BinID != nil {
+		q.where("bin_id = $%d", *filter.BinID)
+	}
+	if filter.Status != nil {
+		q.where("status = $%d", *filter.Status)
+	}
+	if filter.From != nil {
+		q.where("started_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		q.where("started_at < $%d", *filter.To)
+	}
+
+	return listPage(ctx, r.db, q, page, func(c models.Collection) Cursor {
+		return Cursor{Keys: []string{timeKey(c.StartedAt)}, ID: c.ID}
+	}, true, "started_at")
 }
 
 // ListByDriver retrieves collections for a specific driver
-func (r *CollectionRepository) ListByDriver(ctx context.Context, driverID uuid.UUID, limit, offset int) ([]models.Collection, error) {
-	var collections []models.Collection
-	query := `SELECT * FROM collections WHERE driver_id = $1 ORDER BY started_at DESC LIMIT $2 OFFSET $3`
-	err := r.db.SelectContext(ctx, &collections, query, driverID, limit, offset)
-	return collections, err
+func (r *CollectionRepository) ListByDriver(ctx context.Context, driverID uuid.UUID, page Page) ([]models.Collection, PageResult, error) {
+	return r.ListFiltered(ctx, models.CollectionFilter{DriverID: &driverID}, page)
 }
 
 // ListByBin retrieves collections for a specific bin
-func (r *CollectionRepository) ListByBin(ctx context.Context, binID uuid.UUID, limit, offset int) ([]models.Collection, error) {
+func (r *CollectionRepository) ListByBin(ctx context.Context, binID uuid.UUID, page Page) ([]models.Collection, PageResult, error) {
+	return r.ListFiltered(ctx, models.CollectionFilter{BinID: &binID}, page)
+}
+
+// ListRecentByBins retrieves up to limit of the most recent collections of each bin
+func (r *CollectionRepository) ListRecentByBins(ctx context.Context, binIDs []uuid.UUID, limit int) ([]models.Collection, error) {
+	return r.listRecent(ctx, "bin_id", binIDs, limit)
+}
+
+// ListRecentByDrivers retrieves up to limit of the most recent collections of each driver
+func (r *CollectionRepository) ListRecentByDrivers(ctx context.Context, driverIDs []uuid.UUID, limit int) ([]models.Collection, error) {
+	return r.listRecent(ctx, "driver_id", driverIDs, limit)
+}
+
+// listRecent takes the newest collections per parent within the organization
+// of ctx in one query; column is the parent's foreign key column
+func (r *CollectionRepository) listRecent(ctx context.Context, column string, ids []uuid.UUID, limit int) ([]models.Collection, error) {
 	var collections []models.Collection
-	query := `SELECT * FROM collections WHERE bin_id = $1 ORDER BY started_at DESC LIMIT $2 OFFSET $3`
-	err := r.db.SelectContext(ctx, &collections, query, binID, limit, offset)
+	tenant, args := tenantCondition(ctx, "organization_id", 3)
+	query := `
+		SELECT c.* FROM unnest($1::uuid[]) AS p(id)
+		CROSS JOIN LATERAL (
+			SELECT * FROM collections WHERE ` + column + ` = p.id` + tenant + `
+			ORDER BY started_at DESC, id DESC LIMIT $2
+		) c`
+	err := r.db.SelectContext(ctx, &collections, query, append([]interface{}{pq.Array(ids), limit}, args...)...)
 	return collections, err
 }
 
-// GetDriverStats retrieves driver performance statistics
+// GetDriverStats retrieves driver performance statistics within the
+// organization of ctx
 func (r *CollectionRepository) GetDriverStats(ctx context.Context, driverID uuid.UUID) (map[string]interface{}, error) {
 	stats := make(map[string]interface{})
+	tenant, tenantArgs := tenantCondition(ctx, "organization_id", 2)
+	args := append([]interface{}{driverID}, tenantArgs...)
 
 	// Total collections
 	var total int
-	err := r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM collections WHERE driver_id = $1`, driverID)
+	err := r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM collections WHERE driver_id = $1`+tenant, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -124,7 +233,7 @@ func (r *CollectionRepository) GetDriverStats(ctx context.Context, driverID uuid
 
 	// Completed collections
 	var completed int
-	err = r.db.GetContext(ctx, &completed, `SELECT COUNT(*) FROM collections WHERE driver_id = $1 AND status = 'completed'`, driverID)
+	err = r.db.GetContext(ctx, &completed, `SELECT COUNT(*) FROM collections WHERE driver_id = $1 AND status = 'completed'`+tenant, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -132,7 +241,7 @@ func (r *CollectionRepository) GetDriverStats(ctx context.Context, driverID uuid
 
 	// Total weight collected
 	var totalWeight sql.NullFloat64
-	err = r.db.GetContext(ctx, &totalWeight, `SELECT COALESCE(SUM(weight_kg), 0) FROM collections WHERE driver_id = $1 AND status = 'completed'`, driverID)
+	err = r.db.GetContext(ctx, &totalWeight, `SELECT COALESCE(SUM(weight_kg), 0) FROM collections WHERE driver_id = $1 AND status = 'completed'`+tenant, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -141,14 +250,15 @@ func (r *CollectionRepository) GetDriverStats(ctx context.Context, driverID uuid
 	return stats, nil
 }
 
-// GetCollectionStats retrieves overall collection statistics
+// GetCollectionStats retrieves the collection statistics of the organization of ctx
 func (r *CollectionRepository) GetCollectionStats(ctx context.Context) (map[string]interface{}, error) {
 	stats := make(map[string]interface{})
+	tenant, args := tenantCondition(ctx, "organization_id", 1)
 
 	// Total collections today
 	var todayCollections int
 	err := r.db.GetContext(ctx, &todayCollections,
-		`SELECT COUNT(*) FROM collections WHERE DATE(started_at) = CURRENT_DATE`)
+		`SELECT COUNT(*) FROM collections WHERE DATE(started_at) = CURRENT_DATE`+tenant, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -157,7 +267,7 @@ func (r *CollectionRepository) GetCollectionStats(ctx context.Context) (map[stri
 	// Total weight today
 	var todayWeight sql.NullFloat64
 	err = r.db.GetContext(ctx, &todayWeight,
-		`SELECT COALESCE(SUM(weight_kg), 0) FROM collections WHERE DATE(started_at) = CURRENT_DATE AND status = 'completed'`)
+		`SELECT COALESCE(SUM(weight_kg), 0) FROM collections WHERE DATE(started_at) = CURRENT_DATE AND status = 'completed'`+tenant, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -166,7 +276,7 @@ func (r *CollectionRepository) GetCollectionStats(ctx context.Context) (map[stri
 	// Total collections this month
 	var monthCollections int
 	err = r.db.GetContext(ctx, &monthCollections,
-		`SELECT COUNT(*) FROM collections WHERE DATE_TRUNC('month', started_at) = DATE_TRUNC('month', CURRENT_DATE)`)
+		`SELECT COUNT(*) FROM collections WHERE DATE_TRUNC('month', started_at) = DATE_TRUNC('month', CURRENT_DATE)`+tenant, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -174,3 +284,96 @@ func (r *CollectionRepository) GetCollectionStats(ctx context.Context) (map[stri
 
 	return stats, nil
 }
+
+// Series aggregates the collections of the organization of ctx started in
+// the range per time bucket, optionally only those of one company's bins
+func (r *CollectionRepository) Series(ctx context.Context, period models.AnalyticsRange, companyID *uuid.UUID) ([]models.CollectionSeriesPoint, error) {
+	args := seriesArgs(period)
+	companyFilter := ""
+	if companyID != nil {
+		args = append(args, *companyID)
+		companyFilter = fmt.Sprintf(" AND bin_id IN (SELECT id FROM bins WHERE company_id = $%d)", len(args))
+	}
+	if scope := tenantScope(ctx); scope != nil {
+		args = append(args, *scope)
+		companyFilter += fmt.Sprintf(" AND organization_id = $%d", len(args))
+	}
+
+	query := `
+	WITH ` + seriesBuckets + `,
+	days AS (
+		SELECT date_trunc('day', started_at AT TIME ZONE 'UTC') AS day,
+			COUNT(*) AS collections,
+			COUNT(*) FILTER (WHERE status = 'completed') AS completed,
+			COUNT(*) FILTER (WHERE status = 'cancelled') AS cancelled,
+			COALESCE(SUM(weight_kg) FILTER (WHERE status = 'completed'), 0) AS weight_kg,
+			COUNT(DISTINCT driver_id) AS active_drivers
+		FROM collections
+		WHERE started_at >= $2 AND started_at < $3` + companyFilter + `
+		GROUP BY 1
+	),
+	totals AS (
+		SELECT date_trunc($1, day) AS bucket,
+			SUM(collections) AS collections,
+			SUM(completed) AS completed,
+			SUM(cancelled) AS cancelled,
+			SUM(weight_kg) AS weight_kg,
+			MAX(active_drivers) AS active_drivers
+		FROM days
+		GROUP BY 1
+	)
+	SELECT b.bucket AT TIME ZONE 'UTC' AS bucket,
+		COALESCE(t.collections, 0) AS collections,
+		COALESCE(t.completed, 0) AS completed,
+		COALESCE(t.cancelled, 0) AS cancelled,
+		COALESCE(t.weight_kg, 0) AS weight_kg,
+		COALESCE(t.active_drivers, 0) AS active_drivers
+	FROM buckets b
+	LEFT JOIN totals t ON t.bucket = b.bucket
+	ORDER BY b.bucket`
+
+	var points []models.CollectionSeriesPoint
+	err := r.db.SelectContext(ctx, &points, query, args...)
+	return points, err
+}
+
+// WeightScope restricts WeightByWasteType to the collections of one company's
+// bins or of one citizen; the zero value covers all collections
+type WeightScope struct {
+	CompanyID *uuid.UUID
+	UserID    *uuid.UUID
+}
+
+// WeightByWasteType totals the weight of the completed collections of the
+// organization of ctx started in the range per waste type of the bin,
+// heaviest first
+func (r *CollectionRepository) WeightByWasteType(ctx context.Context, scope WeightScope, period models.AnalyticsRange) ([]models.WasteTypeWeight, error) {
+	args := []interface{}{period.From, period.To}
+	filter := ""
+	if tenant := tenantScope(ctx); tenant != nil {
+		args = append(args, *tenant)
+		filter += fmt.Sprintf(" AND c.organization_id = $%d", len(args))
+	}
+	if scope.CompanyID != nil {
+		args = append(args, *scope.CompanyID)
+		filter += fmt.Sprintf(" AND b.company_id = $%d", len(args))
+	}
+	if scope.UserID != nil {
+		args = append(args, *scope.UserID)
+		filter += fmt.Sprintf(" AND c.user_id = $%d", len(args))
+	}
+
+	query := `
+		SELECT COALESCE(b.waste_type, 'general') AS waste_type,
+			COUNT(*) AS collections, COALESCE(SUM(c.weight_kg), 0) AS weight_kg
+		FROM collections c
+		JOIN bins b ON b.id = c.bin_id
+		WHERE c.status = 'completed'
+			AND c.started_at >= $1 AND c.started_at < $2` + filter + `
+		GROUP BY 1
+		ORDER BY 3 DESC, 1`
+
+	var weights []models.WasteTypeWeight
+	err := r.db.SelectContext(ctx, &weights, query, args...)
+	return weights, err
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// CollectionSagaRepository handles the sagas of completed collections
+type CollectionSagaRepository struct {
+	db dbtx
+}
+
+// NewCollectionSagaRepository creates a new CollectionSagaRepository instance
+func NewCollectionSagaRepository(db *DB) *CollectionSagaRepository {
+	return &CollectionSagaRepository{db: db}
+}
+
+// Tx returns a copy of the repository that runs its queries in tx
+func (r *CollectionSagaRepository) Tx(tx *sqlx.Tx) *CollectionSagaRepository {
+	return &CollectionSagaRepository{db: tx}
+}
+
+// Create starts the saga of a collection, due at its NextAttemptAt. A
+// collection only has one saga; Create leaves an existing one untouched and
+// returns false.
+func (r *CollectionSagaRepository) Create(ctx context.Context, saga *models.CollectionSaga) (bool, error) {
+	query := `
+		INSERT INTO collection_sagas (organization_id, collection_id, user_id, waste_type, weight_kg, price_offered, state, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (collection_id) DO NOTHING
+		RETURNING id, attempts, created_at, updated_at`
+
+	saga.State = models.SagaCreatingShipment
+	err := r.db.QueryRowxContext(ctx, query,
+		saga.OrganizationID,
+		saga.CollectionID,
+		saga.UserID,
+		saga.WasteType,
+		saga.WeightKg,
+		saga.PriceOffered,
+		saga.State,
+		saga.NextAttemptAt,
+	).Scan(&saga.ID, &saga.Attempts, &saga.CreatedAt, &saga.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// GetByCollection retrieves the saga of a collection
+func (r *CollectionSagaRepository) GetByCollection(ctx context.Context, collectionID uuid.UUID) (*models.CollectionSaga, error) {
+	return r.get(ctx, `SELECT * FROM collection_sagas WHERE collection_id = $1`, collectionID)
+}
+
+// GetByShipment retrieves the saga that created a shipment
+func (r *CollectionSagaRepository) GetByShipment(ctx context.Context, shipmentID uuid.UUID) (*models.CollectionSaga, error) {
+	return r.get(ctx, `SELECT * FROM collection_sagas WHERE shipment_id = $1`, shipmentID)
+}
+
+func (r *CollectionSagaRepository) get(ctx context.Context, query string, args ...interface{}) (*models.CollectionSaga, error) {
+	var saga models.CollectionSaga
+	err := r.db.GetContext(ctx, &saga, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &saga, err
+}
+
+// ClaimDue returns up to limit sagas with a step due, oldest first, and
+// postpones them by lease so other instances leave them alone while the
+// step runs
+func (r *CollectionSagaRepository) ClaimDue(ctx context.Context, lease time.Duration, limit int) ([]models.CollectionSaga, error) {
+	now := time.Now()
+	query := `
+		UPDATE collection_sagas
+		SET next_attempt_at = $1
+		WHERE id IN (
+			SELECT id FROM collection_sagas
+			WHERE state IN ($2, $3) AND next_attempt_at <= $4
+			ORDER BY next_attempt_at
+			LIMIT $5
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`
+
+	var sagas []models.CollectionSaga
+	err := r.db.SelectContext(ctx, &sagas, query,
+		now.Add(lease), models.SagaCreatingShipment, models.SagaCompensating, now, limit)
+	return sagas, err
+}
+
+// Save records the step a saga reached
+func (r *CollectionSagaRepository) Save(ctx context.Context, saga *models.CollectionSaga) error {
+	saga.UpdatedAt = time.Now()
+	query := `
+		UPDATE collection_sagas
+		SET shipment_id = $1, state = $2, attempts = $3, next_attempt_at = $4, last_error = $5, updated_at = $6
+		WHERE id = $7`
+
+	_, err := r.db.ExecContext(ctx, query,
+		saga.ShipmentID,
+		saga.State,
+		saga.Attempts,
+		saga.NextAttemptAt,
+		saga.LastError,
+		saga.UpdatedAt,
+		saga.ID,
+	)
+	return err
+}
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// CompanyMemberRepository handles company member accounts and their invites
+type CompanyMemberRepository struct {
+	db *DB
+}
+
+// NewCompanyMemberRepository creates a new CompanyMemberRepository instance
+func NewCompanyMemberRepository(db *DB) *CompanyMemberRepository {
+	return &CompanyMemberRepository{db: db}
+}
+
+// memberColumns selects a member joined to its user as company_members m, users u
+const memberColumns = `m.id, u.organization_id, m.company_id, m.user_id, m.role, u.email, u.full_name, m.created_at, m.updated_at`
+
+// GetByUser retrieves the membership of a user
+func (r *CompanyMemberRepository) GetByUser(ctx context.Context, userID uuid.UUID) (*models.CompanyMember, error) {
+	var member models.CompanyMember
+	query := `
+		SELECT ` + memberColumns + `
+		FROM company_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.user_id = $1`
+
+	err := r.db.GetContext(ctx, &member, query, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &member, err
+}
+
+// GetMember retrieves a user's membership of a company
+func (r *CompanyMemberRepository) GetMember(ctx context.Context, companyID, userID uuid.UUID) (*models.CompanyMember, error) {
+	member, err := r.GetByUser(ctx, userID)
+	if member != nil && member.CompanyID != companyID {
+		return nil, nil
+	}
+	return member, err
+}
+
+// List retrieves the members of a company within the organization of ctx,
+// owners first
+func (r *CompanyMemberRepository) List(ctx context.Context, companyID uuid.UUID) ([]models.CompanyMember, error) {
+	var members []models.CompanyMember
+	tenant, args := tenantCondition(ctx, "u.organization_id", 2)
+	query := `
+		SELECT ` + memberColumns + `
+		FROM company_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.company_id = $1` + tenant + `
+		ORDER BY CASE m.role WHEN 'owner' THEN 0 WHEN 'manager' THEN 1 ELSE 2 END, u.full_name`
+	err := r.db.SelectContext(ctx, &members, query, append([]interface{}{companyID}, args...)...)
+	return members, err
+}
+
+// CountOwners counts the owners of a company within the organization of ctx
+func (r *CompanyMemberRepository) CountOwners(ctx context.Context, companyID uuid.UUID) (int, error) {
+	var owners int
+	tenant, args := tenantCondition(ctx, "u.organization_id", 2)
+	query := `
+		SELECT COUNT(*) FROM company_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.company_id = $1 AND m.role = 'owner'` + tenant
+	err := r.db.GetContext(ctx, &owners, query, append([]interface{}{companyID}, args...)...)
+	return owners, err
+}
+
+// UpdateRole changes a member's role within their company
+func (r *CompanyMemberRepository) UpdateRole(ctx context.Context, member *models.CompanyMember) error {
+	query := `UPDATE company_members SET role = $1 WHERE id = $2 RETURNING updated_at`
+	return r.db.QueryRowxContext(ctx, query, member.Role, member.ID).Scan(&member.UpdatedAt)
+}
+
+// Remove removes a member from their company; the account stays and falls
+// back to the citizen role
+func (r *CompanyMemberRepository) Remove(ctx context.Context, member *models.CompanyMember) error {
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM company_members WHERE id = $1`, member.ID); err != nil {
+			return err
+		}
+		query := `UPDATE users SET role = 'citizen', updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND role = 'company'`
+		_, err := tx.ExecContext(ctx, query, member.UserID)
+		return err
+	})
+}
+
+// CreateInvite creates a new company invite into the organization of ctx. An
+// expired invite still pending for the same email is revoked first, so the
+// email can be invited again.
+func (r *CompanyMemberRepository) CreateInvite(ctx context.Context, invite *models.CompanyInvite) error {
+	assignOrganization(ctx, &invite.OrganizationID)
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		query := `
+			UPDATE company_invites SET revoked_at = CURRENT_TIMESTAMP
+			WHERE company_id = $1 AND lower(email) = lower($2)
+				AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at <= CURRENT_TIMESTAMP`
+		if _, err := tx.ExecContext(ctx, query, invite.CompanyID, invite.Email); err != nil {
+			return err
+		}
+
+		query = `
+			INSERT INTO company_invites (organization_id, company_id, email, role, token_hash, invited_by, expires_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id, created_at`
+		return tx.QueryRowxContext(ctx, query,
+			invite.OrganizationID,
+			invite.CompanyID,
+			invite.Email,
+			invite.Role,
+			invite.TokenHash,
+			invite.InvitedBy,
+			invite.ExpiresAt,
+		).Scan(&invite.ID, &invite.CreatedAt)
+	})
+}
+
+// GetInvite retrieves a company invite by ID within the organization of ctx
+func (r *CompanyMemberRepository) GetInvite(ctx context.Context, id uuid.UUID) (*models.CompanyInvite, error) {
+	var invite models.CompanyInvite
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `SELECT * FROM company_invites WHERE id = $1` + tenant
+
+	err := r.db.GetContext(ctx, &invite, query, append([]interface{}{id}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &invite, err
+}
+
+// GetInviteByHash retrieves a company invite by the hash of its token
+func (r *CompanyMemberRepository) GetInviteByHash(ctx context.Context, hash string) (*models.CompanyInvite, error) {
+	var invite models.CompanyInvite
+	query := `SELECT * FROM company_invites WHERE token_hash = $1`
+
+	err := r.db.GetContext(ctx, &invite, query, hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &invite, err
+}
+
+// ListPendingInvites retrieves the invites of a company within the
+// organization of ctx that can still be accepted at now, newest first
+func (r *CompanyMemberRepository) ListPendingInvites(ctx context.Context, companyID uuid.UUID, now time.Time) ([]models.CompanyInvite, error) {
+	var invites []models.CompanyInvite
+	tenant, args := tenantCondition(ctx, "organization_id", 3)
+	query := `
+		SELECT * FROM company_invites
+		WHERE company_id = $1 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > $2` + tenant + `
+		ORDER BY created_at DESC`
+	err := r.db.SelectContext(ctx, &invites, query, append([]interface{}{companyID, now}, args...)...)
+	return invites, err
+}
+
+// RevokeInvite revokes a pending invite
+func (r *CompanyMemberRepository) RevokeInvite(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE company_invites SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND accepted_at IS NULL AND revoked_at IS NULL`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// Accept creates the invitee's user account with the company role in the
+// organization of the invite and its membership, and marks the invite accepted, in one transaction. It returns
+// false if the invite was accepted or revoked concurrently.
+func (r *CompanyMemberRepository) Accept(ctx context.Context, invite *models.CompanyInvite, user *models.User, member *models.CompanyMember) (bool, error) {
+	accepted := false
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE company_invites SET accepted_at = CURRENT_TIMESTAMP
+			WHERE id = $1 AND accepted_at IS NULL AND revoked_at IS NULL`, invite.ID)
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err != nil || n == 0 {
+			return err
+		}
+
+		user.OrganizationID = invite.OrganizationID
+		query := `
+			INSERT INTO users (organization_id, email, password_hash, full_name, phone, role)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id, reward_points, created_at, updated_at`
+		if err := tx.QueryRowxContext(ctx, query,
+			user.OrganizationID,
+			user.Email,
+			user.PasswordHash,
+			user.FullName,
+			user.Phone,
+			user.Role,
+		).Scan(&user.ID, &user.RewardPoints, &user.CreatedAt, &user.UpdatedAt); err != nil {
+			return err
+		}
+
+		member.UserID = user.ID
+		member.OrganizationID = user.OrganizationID
+		query = `
+			INSERT INTO company_members (company_id, user_id, role)
+			VALUES ($1, $2, $3)
+			RETURNING id, created_at, updated_at`
+		if err := tx.QueryRowxContext(ctx, query, member.CompanyID, member.UserID, member.Role).
+			Scan(&member.ID, &member.CreatedAt, &member.UpdatedAt); err != nil {
+			return err
+		}
+		accepted = true
+		return nil
+	})
+	return accepted, err
+}
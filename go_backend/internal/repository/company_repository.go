@@ -6,17 +6,17 @@ import (
 	"errors"
 
 	"github.com/google/uuid"
-	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/smartwaste/backend/internal/models"
 )
 
 // CompanyRepository handles company data operations
 type CompanyRepository struct {
-	db *sqlx.DB
+	db *DB
 }
 
 // NewCompanyRepository creates a new CompanyRepository instance
-func NewCompanyRepository(db *sqlx.DB) *CompanyRepository {
+func NewCompanyRepository(db *DB) *CompanyRepository {
 	return &CompanyRepository{db: db}
 }
 
@@ -38,18 +38,27 @@ func (r *CompanyRepository) Create(ctx context.Context, company *models.Company)
 	).Scan(&company.ID, &company.IsActive, &company.CreatedAt, &company.UpdatedAt)
 }
 
-// GetByID retrieves a company by ID
+// GetByID retrieves a company by ID, within the company scope of ctx
 func (r *CompanyRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Company, error) {
 	var company models.Company
-	query := `SELECT * FROM companies WHERE id = $1`
+	scope, args := scopeCondition(ctx, "id", 2, false)
+	query := `SELECT * FROM companies WHERE id = $1` + scope
 
-	err := r.db.GetContext(ctx, &company, query, id)
+	err := r.db.GetContext(ctx, &company, query, append([]interface{}{id}, args...)...)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
 	}
 	return &company, err
 }
 
+// GetByIDs retrieves the companies with the given IDs, in no particular order
+func (r *CompanyRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]models.Company, error) {
+	var companies []models.Company
+	query := `SELECT * FROM companies WHERE id = ANY($1)`
+	err := r.db.SelectContext(ctx, &companies, query, pq.Array(ids))
+	return companies, err
+}
+
 // GetByEmail retrieves a company by email
 func (r *CompanyRepository) GetByEmail(ctx context.Context, email string) (*models.Company, error) {
 	var company models.Company
@@ -83,17 +92,18 @@ func (r *CompanyRepository) Update(ctx context.Context, company *models.Company)
 	).Scan(&company.UpdatedAt)
 }
 
-// List retrieves all companies with pagination
-func (r *CompanyRepository) List(ctx context.Context, limit, offset int) ([]models.Company, error) {
-	var companies []models.Company
-	query := `SELECT * FROM companies WHERE is_active = true ORDER BY name ASC LIMIT $1 OFFSET $2`
-	err := r.db.SelectContext(ctx, &companies, query, limit, offset)
-	return companies, err
+// List retrieves active companies with pagination, by name; company
+// principals only list their own
+func (r *CompanyRepository) List(ctx context.Context, page Page) ([]models.Company, PageResult, error) {
+	q := &listQuery{from: "companies", conditions: []string{"is_active = true"}}
+	q.scope(ctx, "id", false)
+	return listPage(ctx, r.db, q, page, func(c models.Company) Cursor {
+		return Cursor{Keys: []string{c.Name}, ID: c.ID}
+	}, false, "name")
 }
 
 // Delete deletes a company (soft delete)
 func (r *CompanyRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `UPDATE companies SET is_active = false WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+	return affected(r.db.ExecContext(ctx, query, id))
 }
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// ContractRepository handles company contracts with municipalities
+type ContractRepository struct {
+	db *DB
+}
+
+// NewContractRepository creates a new ContractRepository instance
+func NewContractRepository(db *DB) *ContractRepository {
+	return &ContractRepository{db: db}
+}
+
+// contractInEffect filters contracts ct to those in effect at the time bound
+// to the given placeholder
+func contractInEffect(placeholder string) string {
+	return "ct.is_active = true AND ct.valid_from <= " + placeholder +
+		" AND (ct.valid_to IS NULL OR ct.valid_to > " + placeholder + ")"
+}
+
+// Create creates a new contract
+func (r *ContractRepository) Create(ctx context.Context, contract *models.Contract) error {
+	query := `
+		INSERT INTO contracts (company_id, name, municipality, waste_types,
+			min_latitude, min_longitude, max_latitude, max_longitude, rate_card, valid_from, valid_to)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, is_active, created_at, updated_at`
+
+	return r.db.QueryRowxContext(ctx, query,
+		contract.CompanyID,
+		contract.Name,
+		contract.Municipality,
+		contract.WasteTypes,
+		contract.MinLatitude,
+		contract.MinLongitude,
+		contract.MaxLatitude,
+		contract.MaxLongitude,
+		contract.RateCard,
+		contract.ValidFrom,
+		contract.ValidTo,
+	).Scan(&contract.ID, &contract.IsActive, &contract.CreatedAt, &contract.UpdatedAt)
+}
+
+// GetByID retrieves a contract by ID, within the company scope of ctx
+func (r *ContractRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Contract, error) {
+	var contract models.Contract
+	scope, args := scopeCondition(ctx, "company_id", 2, false)
+	query := `SELECT * FROM contracts WHERE id = $1` + scope
+
+	err := r.db.GetContext(ctx, &contract, query, append([]interface{}{id}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &contract, err
+}
+
+// Update updates a contract
+func (r *ContractRepository) Update(ctx context.Context, contract *models.Contract) error {
+	query := `
+		UPDATE contracts
+		SET name = $1, municipality = $2, waste_types = $3, min_latitude = $4, min_longitude = $5,
+			max_latitude = $6, max_longitude = $7, rate_card = $8, valid_from = $9, valid_to = $10, is_active = $11
+		WHERE id = $12
+		RETURNING updated_at`
+
+	return r.db.QueryRowxContext(ctx, query,
+		contract.Name,
+		contract.Municipality,
+		contract.WasteTypes,
+		contract.MinLatitude,
+		contract.MinLongitude,
+		contract.MaxLatitude,
+		contract.MaxLongitude,
+		contract.RateCard,
+		contract.ValidFrom,
+		contract.ValidTo,
+		contract.IsActive,
+		contract.ID,
+	).Scan(&contract.UpdatedAt)
+}
+
+// List retrieves active contracts matching the filter with pagination, the
+// most recently started first, within the company scope of ctx
+func (r *ContractRepository) List(ctx context.Context, filter models.ContractFilter, page Page) ([]models.Contract, PageResult, error) {
+	q := &listQuery{from: "contracts", conditions: []string{"is_active = true"}}
+	q.scope(ctx, "company_id", false)
+	if filter.CompanyID != nil {
+		q.where("company_id = $%d", *filter.CompanyID)
+	}
+	if filter.ActiveAt != nil {
+		q.where("valid_from <= $%[1]d AND (valid_to IS NULL OR valid_to > $%[1]d)", *filter.ActiveAt)
+	}
+
+	return listPage(ctx, r.db, q, page, func(contract models.Contract) Cursor {
+		return Cursor{Keys: []string{timeKey(contract.ValidFrom)}, ID: contract.ID}
+	}, true, "valid_from")
+}
+
+// Delete ends a contract (soft delete)
+func (r *ContractRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE contracts SET is_active = false WHERE id = $1`
+	return affected(r.db.ExecContext(ctx, query, id))
+}
+
+// ListInEffect retrieves the contracts of active companies in effect at the
+// given time
+func (r *ContractRepository) ListInEffect(ctx context.Context, at time.Time) ([]models.Contract, error) {
+	var contracts []models.Contract
+	query := `
+		SELECT ct.* FROM contracts ct
+		JOIN companies c ON c.id = ct.company_id
+		WHERE ` + contractInEffect("$1") + ` AND c.is_active = true
+		ORDER BY ct.company_id, ct.valid_from DESC`
+	err := r.db.SelectContext(ctx, &contracts, query, at)
+	return contracts, err
+}
+
+// CompanyContract retrieves the company's contract in effect at the given
+// time that covers the waste type, preferring contracts with a rate for it
+// and then the most recently started
+func (r *ContractRepository) CompanyContract(ctx context.Context, companyID uuid.UUID, wasteType string, at time.Time) (*models.Contract, error) {
+	var contract models.Contract
+	query := `
+		SELECT ct.* FROM contracts ct
+		WHERE ct.company_id = $1 AND ct.waste_types @> jsonb_build_array($2::text) AND ` + contractInEffect("$3") + `
+		ORDER BY ct.rate_card @> jsonb_build_array(jsonb_build_object('waste_type', $2::text)) DESC, ct.valid_from DESC
+		LIMIT 1`
+
+	err := r.db.GetContext(ctx, &contract, query, companyID, wasteType, at)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &contract, err
+}
+
+// ListCovering retrieves, for every active company with one, its contract in
+// effect at the given time that covers the waste type, chosen like
+// CompanyContract
+func (r *ContractRepository) ListCovering(ctx context.Context, wasteType string, at time.Time) ([]models.CompanyContract, error) {
+	var contracts []models.CompanyContract
+	query := `
+		SELECT DISTINCT ON (ct.company_id) ct.*, c.name AS company_name
+		FROM contracts ct
+		JOIN companies c ON c.id = ct.company_id
+		WHERE ct.waste_types @> jsonb_build_array($1::text) AND ` + contractInEffect("$2") + `
+			AND c.is_active = true
+		ORDER BY ct.company_id,
+			ct.rate_card @> jsonb_build_array(jsonb_build_object('waste_type', $1::text)) DESC,
+			ct.valid_from DESC`
+	err := r.db.SelectContext(ctx, &contracts, query, wasteType, at)
+	return contracts, err
+}
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// DailyStatsRepository handles the daily snapshots of readings and
+// collections trend analytics read from. Series combine the materialized days
+// with the raw rows of the days the snapshots do not cover: the partial days
+// at the edges of the range and the days since the last materialization.
+type DailyStatsRepository struct {
+	db *DB
+}
+
+// NewDailyStatsRepository creates a new DailyStatsRepository instance
+func NewDailyStatsRepository(db *DB) *DailyStatsRepository {
+	return &DailyStatsRepository{db: db}
+}
+
+// Materialize recomputes the snapshots of every organization for the UTC
+// days from..to, replacing those already there. An organization without
+// readings or collections on a day has no snapshot of it.
+func (r *DailyStatsRepository) Materialize(ctx context.Context, from, to time.Time) (int64, error) {
+	var rows int64
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		query := `
+			DELETE FROM daily_stats
+			WHERE day >= ($1::timestamptz AT TIME ZONE 'UTC')::date AND day < ($2::timestamptz AT TIME ZONE 'UTC')::date`
+		if _, err := tx.ExecContext(ctx, query, from, to); err != nil {
+			return err
+		}
+
+		query = `
+			INSERT INTO daily_stats (organization_id, day, readings, bins_reporting, fill_level_sum, average_fill_level,
+				max_fill_level, collections, completed, cancelled, weight_kg, active_drivers)
+			SELECT organization_id, day, SUM(readings), SUM(bins_reporting), SUM(fill_level_sum),
+				SUM(fill_level_sum)::float8 / NULLIF(SUM(readings), 0), MAX(max_fill_level),
+				SUM(collections), SUM(completed), SUM(cancelled), SUM(weight_kg), SUM(active_drivers)
+			FROM (
+				SELECT b.organization_id, (r.recorded_at AT TIME ZONE 'UTC')::date AS day,
+					COUNT(*) AS readings, COUNT(DISTINCT r.bin_id) AS bins_reporting,
+					SUM(r.fill_level) AS fill_level_sum, MAX(r.fill_level) AS max_fill_level,
+					0 AS collections, 0 AS completed, 0 AS cancelled, 0 AS weight_kg, 0 AS active_drivers
+				FROM bin_readings r
+				JOIN bins b ON b.id = r.bin_id
+				WHERE r.recorded_at >= $1 AND r.recorded_at < $2
+				GROUP BY 1, 2
+				UNION ALL
+				SELECT organization_id, (started_at AT TIME ZONE 'UTC')::date, 0, 0, 0, NULL,
+					COUNT(*), COUNT(*) FILTER (WHERE status = 'completed'), COUNT(*) FILTER (WHERE status = 'cancelled'),
+					COALESCE(SUM(weight_kg) FILTER (WHERE status = 'completed'), 0), COUNT(DISTINCT driver_id)
+				FROM collections
+				WHERE started_at >= $1 AND started_at < $2
+				GROUP BY 1, 2
+			) t
+			GROUP BY organization_id, day`
+		result, err := tx.ExecContext(ctx, query, from, to)
+		if err != nil {
+			return err
+		}
+		rows, err = result.RowsAffected()
+		return err
+	})
+	return rows, err
+}
+
+// Horizon returns the UTC midnight ending the last materialized day, or nil
+// if nothing was materialized yet
+func (r *DailyStatsRepository) Horizon(ctx context.Context) (*time.Time, error) {
+	var horizon *time.Time
+	query := `SELECT (MAX(day) + 1)::timestamp AT TIME ZONE 'UTC' FROM daily_stats`
+	err := r.db.GetContext(ctx, &horizon, query)
+	return horizon, err
+}
+
+// EarliestActivity returns when the first reading was recorded or the first
+// collection started, or nil if there are none
+func (r *DailyStatsRepository) EarliestActivity(ctx context.Context) (*time.Time, error) {
+	var earliest *time.Time
+	query := `SELECT LEAST((SELECT MIN(recorded_at) FROM bin_readings), (SELECT MIN(started_at) FROM collections))`
+	err := r.db.GetContext(ctx, &earliest, query)
+	return earliest, err
+}
+
+// BinSeries aggregates the fill-level readings of the organization of ctx
+// recorded in the range per time bucket
+func (r *DailyStatsRepository) BinSeries(ctx context.Context, period models.AnalyticsRange) ([]models.BinSeriesPoint, error) {
+	args, err := r.seriesArgs(ctx, period)
+	if err != nil {
+		return nil, err
+	}
+	tenant, tenantArgs := tenantCondition(ctx, "organization_id", 6)
+	owner, ownerArgs := ownerCondition(ctx, "bin_id", "bins", 6+len(tenantArgs))
+
+	query := `
+	WITH ` + seriesBuckets + `,
+	days AS (
+		SELECT day::timestamp AS day, readings, bins_reporting, fill_level_sum, max_fill_level
+		FROM daily_stats
+		WHERE day >= ($4::timestamptz AT TIME ZONE 'UTC')::date AND day < ($5::timestamptz AT TIME ZONE 'UTC')::date` + tenant + `
+		UNION ALL
+		SELECT date_trunc('day', recorded_at AT TIME ZONE 'UTC'), COUNT(*), COUNT(DISTINCT bin_id),
+			SUM(fill_level), MAX(fill_level)
+		FROM bin_readings
+		WHERE ((recorded_at >= $2 AND recorded_at < $4) OR (recorded_at >= $5 AND recorded_at < $3))` + owner + `
+		GROUP BY 1
+	),
+	totals AS (
+		SELECT date_trunc($1, day) AS bucket,
+			SUM(readings) AS readings,
+			MAX(bins_reporting) AS bins_reporting,
+			SUM(fill_level_sum)::float8 / NULLIF(SUM(readings), 0) AS average_fill_level,
+			MAX(max_fill_level) AS max_fill_level
+		FROM days
+		GROUP BY 1
+	)
+	SELECT b.bucket AT TIME ZONE 'UTC' AS bucket,
+		COALESCE(t.readings, 0) AS readings,
+		COALESCE(t.bins_reporting, 0) AS bins_reporting,
+		t.average_fill_level,
+		t.max_fill_level
+	FROM buckets b
+	LEFT JOIN totals t ON t.bucket = b.bucket
+	ORDER BY b.bucket`
+
+	var points []models.BinSeriesPoint
+	err = r.db.SelectContext(ctx, &points, query, append(append(args, tenantArgs...), ownerArgs...)...)
+	return points, err
+}
+
+// CollectionSeries aggregates the collections of the organization of ctx
+// started in the range per time bucket
+func (r *DailyStatsRepository) CollectionSeries(ctx context.Context, period models.AnalyticsRange) ([]models.CollectionSeriesPoint, error) {
+	args, err := r.seriesArgs(ctx, period)
+	if err != nil {
+		return nil, err
+	}
+	tenant, scope := tenantCondition(ctx, "organization_id", 6)
+
+	query := `
+	WITH ` + seriesBuckets + `,
+	days AS (
+		SELECT day::timestamp AS day, collections, completed, cancelled, weight_kg, active_drivers
+		FROM daily_stats
+		WHERE day >= ($4::timestamptz AT TIME ZONE 'UTC')::date AND day < ($5::timestamptz AT TIME ZONE 'UTC')::date` + tenant + `
+		UNION ALL
+		SELECT date_trunc('day', started_at AT TIME ZONE 'UTC'), COUNT(*),
+			COUNT(*) FILTER (WHERE status = 'completed'),
+			COUNT(*) FILTER (WHERE status = 'cancelled'),
+			COALESCE(SUM(weight_kg) FILTER (WHERE status = 'completed'), 0),
+			COUNT(DISTINCT driver_id)
+		FROM collections
+		WHERE ((started_at >= $2 AND started_at < $4) OR (started_at >= $5 AND started_at < $3))` + tenant + `
+		GROUP BY 1
+	),
+	totals AS (
+		SELECT date_trunc($1, day) AS bucket,
+			SUM(collections) AS collections,
+			SUM(completed) AS completed,
+			SUM(cancelled) AS cancelled,
+			SUM(weight_kg) AS weight_kg,
+			MAX(active_drivers) AS active_drivers
+		FROM days
+		GROUP BY 1
+	)
+	SELECT b.bucket AT TIME ZONE 'UTC' AS bucket,
+		COALESCE(t.collections, 0) AS collections,
+		COALESCE(t.completed, 0) AS completed,
+		COALESCE(t.cancelled, 0) AS cancelled,
+		COALESCE(t.weight_kg, 0) AS weight_kg,
+		COALESCE(t.active_drivers, 0) AS active_drivers
+	FROM buckets b
+	LEFT JOIN totals t ON t.bucket = b.bucket
+	ORDER BY b.bucket`
+
+	var points []models.CollectionSeriesPoint
+	err = r.db.SelectContext(ctx, &points, query, append(args, scope...)...)
+	return points, err
+}
+
+// seriesArgs returns the $1..$5 arguments of a series: those of seriesBuckets
+// followed by the start and end of the whole UTC days of the range that are
+// materialized. The raw rows cover the range outside them.
+func (r *DailyStatsRepository) seriesArgs(ctx context.Context, period models.AnalyticsRange) ([]interface{}, error) {
+	horizon, err := r.Horizon(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	from := period.From.UTC().Truncate(24 * time.Hour)
+	if from.Before(period.From) {
+		from = from.Add(24 * time.Hour)
+	}
+	to := period.To.UTC().Truncate(24 * time.Hour)
+	if horizon == nil {
+		to = from
+	} else if horizon.Before(to) {
+		to = *horizon
+	}
+	if !from.Before(to) {
+		from, to = period.To, period.To
+	}
+
+	return append(seriesArgs(period), from, to), nil
+}
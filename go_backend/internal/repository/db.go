@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DB is the connection pool of the repositories. It bounds each statement by
+// a context deadline, so a caller gives up on a slow query (and the pool gets
+// its connection back) rather than waiting on it indefinitely.
+type DB struct {
+	*sqlx.DB
+	queryTimeout time.Duration
+}
+
+// NewDB wraps db for the repositories; a queryTimeout of 0 leaves the
+// statements bounded by their callers' contexts only
+func NewDB(db *sqlx.DB, queryTimeout time.Duration) *DB {
+	return &DB{DB: db, queryTimeout: queryTimeout}
+}
+
+// withDeadline bounds ctx by the query timeout, keeping an earlier deadline
+func (db *DB) withDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if db.queryTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, db.queryTimeout)
+}
+
+// ExecContext executes a statement within the query timeout
+func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, cancel := db.withDeadline(ctx)
+	defer cancel()
+	return db.DB.ExecContext(ctx, query, args...)
+}
+
+// GetContext scans a row within the query timeout
+func (db *DB) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	ctx, cancel := db.withDeadline(ctx)
+	defer cancel()
+	return db.DB.GetContext(ctx, dest, query, args...)
+}
+
+// SelectContext scans rows within the query timeout
+func (db *DB) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	ctx, cancel := db.withDeadline(ctx)
+	defer cancel()
+	return db.DB.SelectContext(ctx, dest, query, args...)
+}
+
+// QueryRowxContext and QueryxContext are left to the embedded *sqlx.DB: their
+// rows outlive the call, so the deadline could not be released with them.
+// The statement_timeout of the connections (DB_QUERY_TIMEOUT) bounds them.
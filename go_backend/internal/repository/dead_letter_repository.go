@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// DeadLetterRepository handles dead-lettered MQTT messages
+type DeadLetterRepository struct {
+	db *DB
+}
+
+// NewDeadLetterRepository creates a new DeadLetterRepository instance
+func NewDeadLetterRepository(db *DB) *DeadLetterRepository {
+	return &DeadLetterRepository{db: db}
+}
+
+// Create stores a dead-lettered message
+func (r *DeadLetterRepository) Create(ctx context.Context, deadLetter *models.DeadLetter) error {
+	query := `
+		INSERT INTO mqtt_dead_letters (topic, payload, reason)
+		VALUES ($1, $2, $3)
+		RETURNING id, replay_count, received_at`
+
+	return r.db.QueryRowxContext(ctx, query,
+		deadLetter.Topic,
+		deadLetter.Payload,
+		deadLetter.Reason,
+	).Scan(&deadLetter.ID, &deadLetter.ReplayCount, &deadLetter.ReceivedAt)
+}
+
+// GetByID retrieves a dead letter by ID
+func (r *DeadLetterRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DeadLetter, error) {
+	var deadLetter models.DeadLetter
+	query := `SELECT * FROM mqtt_dead_letters WHERE id = $1`
+
+	err := r.db.GetContext(ctx, &deadLetter, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &deadLetter, err
+}
+
+// List retrieves dead letters with pagination, newest first
+func (r *DeadLetterRepository) List(ctx context.Context, pendingOnly bool, page Page) ([]models.DeadLetter, PageResult, error) {
+	q := &listQuery{from: "mqtt_dead_letters"}
+	if pendingOnly {
+		q.conditions = append(q.conditions, "replayed_at IS NULL")
+	}
+	return listPage(ctx, r.db, q, page, func(d models.DeadLetter) Cursor {
+		return Cursor{Keys: []string{timeKey(d.ReceivedAt)}, ID: d.ID}
+	}, true, "received_at")
+}
+
+// MarkReplayed records a successful replay
+func (r *DeadLetterRepository) MarkReplayed(ctx context.Context, id uuid.UUID, payload string) error {
+	query := `
+		UPDATE mqtt_dead_letters
+		SET payload = $1, replay_count = replay_count + 1, replayed_at = $2
+		WHERE id = $3`
+	_, err := r.db.ExecContext(ctx, query, payload, time.Now(), id)
+	return err
+}
+
+// RecordFailedReplay records a replay attempt that failed again
+func (r *DeadLetterRepository) RecordFailedReplay(ctx context.Context, id uuid.UUID, payload, reason string) error {
+	query := `
+		UPDATE mqtt_dead_letters
+		SET payload = $1, reason = $2, replay_count = replay_count + 1
+		WHERE id = $3`
+	_, err := r.db.ExecContext(ctx, query, payload, reason, id)
+	return err
+}
+
+// Delete deletes a dead letter
+func (r *DeadLetterRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM mqtt_dead_letters WHERE id = $1`
+	return affected(r.db.ExecContext(ctx, query, id))
+}
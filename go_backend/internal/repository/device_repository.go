@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// DeviceRepository handles the sensor units of the fleet and the bins they
+// served. The hardware serial of a device is copied to the bin it is
+// installed on, which is what the sensor provisions itself with.
+type DeviceRepository struct {
+	db *DB
+}
+
+// NewDeviceRepository creates a new DeviceRepository instance
+func NewDeviceRepository(db *DB) *DeviceRepository {
+	return &DeviceRepository{db: db}
+}
+
+// Create registers a device, installed on binID at once when set
+func (r *DeviceRepository) Create(ctx context.Context, device *models.Device, binID *uuid.UUID, assignedBy *uuid.UUID) error {
+	assignOrganization(ctx, &device.OrganizationID)
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		query := `
+			INSERT INTO devices (organization_id, hardware_serial, model, firmware_version, connectivity, sim_iccid, carrier, apn, notes)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			RETURNING id`
+		if err := tx.QueryRowxContext(ctx, query,
+			device.OrganizationID,
+			device.HardwareSerial,
+			device.Model,
+			device.FirmwareVersion,
+			device.Connectivity,
+			device.SimICCID,
+			device.Carrier,
+			device.APN,
+			device.Notes,
+		).Scan(&device.ID); err != nil {
+			return err
+		}
+
+		if binID != nil {
+			if err := attachDevice(ctx, tx, device.ID, *binID, assignedBy, nil); err != nil {
+				return err
+			}
+		}
+		return reloadDevice(ctx, tx, device)
+	})
+}
+
+// GetByID retrieves a device by ID within the organization of ctx
+func (r *DeviceRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Device, error) {
+	var device models.Device
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `SELECT * FROM device_details WHERE id = $1` + tenant
+
+	err := r.db.GetContext(ctx, &device, query, append([]interface{}{id}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &device, err
+}
+
+// GetByBin retrieves the device installed on a bin
+func (r *DeviceRepository) GetByBin(ctx context.Context, binID uuid.UUID) (*models.Device, error) {
+	var device models.Device
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `SELECT * FROM device_details WHERE bin_id = $1` + tenant
+
+	err := r.db.GetContext(ctx, &device, query, append([]interface{}{binID}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &device, err
+}
+
+// ListFiltered retrieves devices ordered by hardware serial
+func (r *DeviceRepository) ListFiltered(ctx context.Context, filter models.DeviceFilter, page Page) ([]models.Device, PageResult, error) {
+	q := &listQuery{from: "device_details"}
+	q.tenant(ctx, "organization_id")
+	if filter.Status != nil {
+		q.where("status = $%d", *filter.Status)
+	}
+	if filter.Model != nil {
+		q.where("model = $%d", *filter.Model)
+	}
+	if filter.Connectivity != nil {
+		q.where("connectivity = $%d", *filter.Connectivity)
+	}
+	return listPage(ctx, r.db, q, page, func(d models.Device) Cursor {
+		return Cursor{Keys: []string{d.HardwareSerial}, ID: d.ID}
+	}, false, "hardware_serial")
+}
+
+// Update updates the details of a device
+func (r *DeviceRepository) Update(ctx context.Context, device *models.Device) error {
+	query := `
+		UPDATE devices
+		SET model = $1, connectivity = $2, sim_iccid = $3, carrier = $4, apn = $5, notes = $6
+		WHERE id = $7
+		RETURNING updated_at`
+
+	return r.db.QueryRowxContext(ctx, query,
+		device.Model,
+		device.Connectivity,
+		device.SimICCID,
+		device.Carrier,
+		device.APN,
+		device.Notes,
+		device.ID,
+	).Scan(&device.UpdatedAt)
+}
+
+// Assign installs a device on a bin, moving it from the bin it served and
+// sending the device the bin had back to stock. Both bins have to provision
+// their new sensor. It returns ErrNotFound when the device is decommissioned.
+func (r *DeviceRepository) Assign(ctx context.Context, device *models.Device, binID uuid.UUID, assignedBy *uuid.UUID, reason *string) error {
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		var status models.DeviceStatus
+		query := `SELECT status FROM devices WHERE id = $1 FOR UPDATE`
+		if err := tx.GetContext(ctx, &status, query, device.ID); err != nil {
+			return err
+		}
+		if status == models.DeviceDecommissioned {
+			return ErrNotFound
+		}
+
+		if err := attachDevice(ctx, tx, device.ID, binID, assignedBy, reason); err != nil {
+			return err
+		}
+		return reloadDevice(ctx, tx, device)
+	})
+}
+
+// Decommission retires a device, removing it from the bin it served. It
+// returns ErrNotFound when the device is already decommissioned.
+func (r *DeviceRepository) Decommission(ctx context.Context, device *models.Device, reason *string) error {
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if err := detachDevice(ctx, tx, device.ID); err != nil {
+			return err
+		}
+		query := `
+			UPDATE devices
+			SET status = 'decommissioned', decommissioned_at = CURRENT_TIMESTAMP, decommission_reason = $1
+			WHERE id = $2 AND status <> 'decommissioned'`
+		if err := affected(tx.ExecContext(ctx, query, reason, device.ID)); err != nil {
+			return err
+		}
+		return reloadDevice(ctx, tx, device)
+	})
+}
+
+// Assignments retrieves the bins a device served, most recent first
+func (r *DeviceRepository) Assignments(ctx context.Context, deviceID uuid.UUID) ([]models.DeviceAssignment, error) {
+	return r.assignments(ctx, "a.device_id = $1", deviceID)
+}
+
+// BinAssignments retrieves the devices of the organization of ctx that
+// served a bin, most recent first
+func (r *DeviceRepository) BinAssignments(ctx context.Context, binID uuid.UUID) ([]models.DeviceAssignment, error) {
+	return r.assignments(ctx, "a.bin_id = $1", binID)
+}
+
+func (r *DeviceRepository) assignments(ctx context.Context, condition string, id uuid.UUID) ([]models.DeviceAssignment, error) {
+	assignments := []models.DeviceAssignment{}
+	tenant, args := tenantCondition(ctx, "d.organization_id", 2)
+	query := `
+		SELECT a.*, d.hardware_serial, b.device_id AS bin_device_id FROM device_assignments a
+		JOIN devices d ON d.id = a.device_id
+		JOIN bins b ON b.id = a.bin_id
+		WHERE ` + condition + tenant + `
+		ORDER BY a.assigned_at DESC, a.id`
+	err := r.db.SelectContext(ctx, &assignments, query, append([]interface{}{id}, args...)...)
+	return assignments, err
+}
+
+// attachDevice installs a device on a bin within tx, detaching it and the
+// device the bin had first, and opens the assignment
+func attachDevice(ctx context.Context, tx *sqlx.Tx, deviceID, binID uuid.UUID, assignedBy *uuid.UUID, reason *string) error {
+	var displaced []uuid.UUID
+	query := `SELECT id FROM devices WHERE bin_id = $1 AND id <> $2 FOR UPDATE`
+	if err := tx.SelectContext(ctx, &displaced, query, binID, deviceID); err != nil {
+		return err
+	}
+	for _, id := range append(displaced, deviceID) {
+		if err := detachDevice(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+
+	query = `UPDATE devices SET bin_id = $1, status = 'active' WHERE id = $2`
+	if _, err := tx.ExecContext(ctx, query, binID, deviceID); err != nil {
+		return err
+	}
+
+	// The serial moves to the new bin, which provisions the device afresh
+	query = `
+		UPDATE bins SET hardware_serial = NULL
+		WHERE id <> $1 AND (organization_id, hardware_serial) = (SELECT organization_id, hardware_serial FROM devices WHERE id = $2)`
+	if _, err := tx.ExecContext(ctx, query, binID, deviceID); err != nil {
+		return err
+	}
+	query = `
+		UPDATE bins b
+		SET hardware_serial = d.hardware_serial, firmware_version = d.firmware_version,
+			provisioned_at = NULL, certificate_serial = NULL, certificate_expires_at = NULL
+		FROM devices d
+		WHERE b.id = $1 AND d.id = $2`
+	if err := affected(tx.ExecContext(ctx, query, binID, deviceID)); err != nil {
+		return err
+	}
+
+	query = `INSERT INTO device_assignments (device_id, bin_id, assigned_by, reason) VALUES ($1, $2, $3, $4)`
+	_, err := tx.ExecContext(ctx, query, deviceID, binID, assignedBy, reason)
+	return err
+}
+
+// detachDevice removes a device from the bin it serves within tx, if any:
+// the device keeps the firmware the bin last reported, the bin loses the serial
+// and certificate of the device, and the assignment is closed
+func detachDevice(ctx context.Context, tx *sqlx.Tx, deviceID uuid.UUID) error {
+	var previous struct {
+		BinID  uuid.UUID `db:"bin_id"`
+		Serial string    `db:"hardware_serial"`
+	}
+	query := `
+		UPDATE devices d
+		SET firmware_version = COALESCE(b.firmware_version, d.firmware_version), bin_id = NULL, status = 'in_stock'
+		FROM bins b
+		WHERE d.id = $1 AND b.id = d.bin_id
+		RETURNING b.id AS bin_id, d.hardware_serial`
+	err := tx.GetContext(ctx, &previous, query, deviceID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Unless an admin gave the bin another serial since
+	query = `
+		UPDATE bins
+		SET hardware_serial = NULL, firmware_version = NULL,
+			provisioned_at = NULL, certificate_serial = NULL, certificate_expires_at = NULL
+		WHERE id = $1 AND hardware_serial = $2`
+	if _, err := tx.ExecContext(ctx, query, previous.BinID, previous.Serial); err != nil {
+		return err
+	}
+
+	query = `UPDATE device_assignments SET unassigned_at = CURRENT_TIMESTAMP WHERE device_id = $1 AND unassigned_at IS NULL`
+	_, err = tx.ExecContext(ctx, query, deviceID)
+	return err
+}
+
+// reloadDevice refreshes device from the database within tx
+func reloadDevice(ctx context.Context, tx *sqlx.Tx, device *models.Device) error {
+	return tx.GetContext(ctx, device, `SELECT * FROM device_details WHERE id = $1`, device.ID)
+}
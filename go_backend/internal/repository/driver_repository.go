@@ -4,30 +4,44 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/smartwaste/backend/internal/models"
 )
 
 // DriverRepository handles driver data operations
 type DriverRepository struct {
-	db *sqlx.DB
+	db dbtx
 }
 
 // NewDriverRepository creates a new DriverRepository instance
-func NewDriverRepository(db *sqlx.DB) *DriverRepository {
+func NewDriverRepository(db *DB) *DriverRepository {
 	return &DriverRepository{db: db}
 }
 
-// Create creates a new driver
+// WithTx runs fn in a database transaction; use Tx to bind repositories to it
+func (r *DriverRepository) WithTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	return withTx(ctx, r.db, fn)
+}
+
+// Tx returns a copy of the repository that runs its queries in tx
+func (r *DriverRepository) Tx(tx *sqlx.Tx) *DriverRepository {
+	return &DriverRepository{db: tx}
+}
+
+// Create creates a new driver, in the organization of ctx when it is confined to one
 func (r *DriverRepository) Create(ctx context.Context, driver *models.Driver) error {
+	assignOrganization(ctx, &driver.OrganizationID)
 	query := `
-		INSERT INTO drivers (email, password_hash, full_name, phone, license_number, vehicle_type, vehicle_plate)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO drivers (organization_id, email, password_hash, full_name, phone, license_number, vehicle_type, vehicle_plate)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id, created_at, updated_at`
 
 	return r.db.QueryRowxContext(ctx, query,
+		driver.OrganizationID,
 		driver.Email,
 		driver.PasswordHash,
 		driver.FullName,
@@ -38,19 +52,30 @@ func (r *DriverRepository) Create(ctx context.Context, driver *models.Driver) er
 	).Scan(&driver.ID, &driver.CreatedAt, &driver.UpdatedAt)
 }
 
-// GetByID retrieves a driver by ID
+// GetByID retrieves a driver by ID, within the organization of ctx
 func (r *DriverRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Driver, error) {
 	var driver models.Driver
-	query := `SELECT * FROM drivers WHERE id = $1`
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `SELECT * FROM drivers WHERE id = $1` + tenant
 
-	err := r.db.GetContext(ctx, &driver, query, id)
+	err := r.db.GetContext(ctx, &driver, query, append([]interface{}{id}, args...)...)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
 	}
 	return &driver, err
 }
 
-// GetByEmail retrieves a driver by email
+// GetByIDs retrieves the drivers with the given IDs within the organization
+// of ctx, in no particular order
+func (r *DriverRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]models.Driver, error) {
+	var drivers []models.Driver
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `SELECT * FROM drivers WHERE id = ANY($1)` + tenant
+	err := r.db.SelectContext(ctx, &drivers, query, append([]interface{}{pq.Array(ids)}, args...)...)
+	return drivers, err
+}
+
+// GetByEmail retrieves a driver by email in any organization, for logins
 func (r *DriverRepository) GetByEmail(ctx context.Context, email string) (*models.Driver, error) {
 	var driver models.Driver
 	query := `SELECT * FROM drivers WHERE email = $1`
@@ -62,35 +87,65 @@ func (r *DriverRepository) GetByEmail(ctx context.Context, email string) (*model
 	return &driver, err
 }
 
-// Update updates a driver
+// Update updates a driver within the organization of ctx
 func (r *DriverRepository) Update(ctx context.Context, driver *models.Driver) error {
+	tenant, args := tenantCondition(ctx, "organization_id", 7)
 	query := `
 		UPDATE drivers
 		SET full_name = $1, phone = $2, vehicle_type = $3, vehicle_plate = $4, is_available = $5, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $6
+		WHERE id = $6` + tenant + `
 		RETURNING updated_at`
 
-	return r.db.QueryRowxContext(ctx, query,
+	return r.db.QueryRowxContext(ctx, query, append([]interface{}{
 		driver.FullName,
 		driver.Phone,
 		driver.VehicleType,
 		driver.VehiclePlate,
 		driver.IsAvailable,
 		driver.ID,
-	).Scan(&driver.UpdatedAt)
+	}, args...)...).Scan(&driver.UpdatedAt)
+}
+
+// AssignVehicle sets or clears (nil) the driver's vehicle, within the
+// organization of ctx
+func (r *DriverRepository) AssignVehicle(ctx context.Context, id uuid.UUID, vehicleID *uuid.UUID) error {
+	// The vehicle must belong to the organization of the driver
+	tenant, args := tenantCondition(ctx, "organization_id", 3)
+	query := `
+		UPDATE drivers SET vehicle_id = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+			AND ($1::uuid IS NULL OR $1 IN (SELECT id FROM vehicles WHERE organization_id = drivers.organization_id))` + tenant
+	_, err := r.db.ExecContext(ctx, query, append([]interface{}{vehicleID, id}, args...)...)
+	return err
+}
+
+// GetByVehicleID retrieves the driver a vehicle is assigned to, within the
+// organization of ctx
+func (r *DriverRepository) GetByVehicleID(ctx context.Context, vehicleID uuid.UUID) (*models.Driver, error) {
+	var driver models.Driver
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `SELECT * FROM drivers WHERE vehicle_id = $1` + tenant
+
+	err := r.db.GetContext(ctx, &driver, query, append([]interface{}{vehicleID}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &driver, err
 }
 
-// UpdateLocation updates a driver's location
+// UpdateLocation updates a driver's location within the organization of ctx
 func (r *DriverRepository) UpdateLocation(ctx context.Context, id uuid.UUID, lat, lng float64) error {
-	query := `UPDATE drivers SET latitude = $1, longitude = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $3`
-	_, err := r.db.ExecContext(ctx, query, lat, lng, id)
+	tenant, args := tenantCondition(ctx, "organization_id", 4)
+	query := `UPDATE drivers SET latitude = $1, longitude = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $3` + tenant
+	_, err := r.db.ExecContext(ctx, query, append([]interface{}{lat, lng, id}, args...)...)
 	return err
 }
 
-// UpdateFCMToken updates a driver's FCM token
+// UpdateFCMToken updates a driver's FCM token within the organization of ctx
 func (r *DriverRepository) UpdateFCMToken(ctx context.Context, id uuid.UUID, token string) error {
-	query := `UPDATE drivers SET fcm_token = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
-	_, err := r.db.ExecContext(ctx, query, token, id)
+	tenant, args := tenantCondition(ctx, "organization_id", 3)
+	query := `UPDATE drivers SET fcm_token = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2` + tenant
+	_, err := r.db.ExecContext(ctx, query, append([]interface{}{token, id}, args...)...)
 	return err
 }
 
@@ -101,44 +156,83 @@ func (r *DriverRepository) IncrementCollections(ctx context.Context, id uuid.UUI
 	return err
 }
 
-// GetAvailableDrivers retrieves all available drivers
+// GetAvailableDrivers retrieves all available drivers who are on shift,
+// within the organization of ctx
 func (r *DriverRepository) GetAvailableDrivers(ctx context.Context) ([]models.Driver, error) {
 	var drivers []models.Driver
-	query := `SELECT * FROM drivers WHERE is_available = true ORDER BY average_rating DESC`
-	err := r.db.SelectContext(ctx, &drivers, query)
+	tenant, args := tenantCondition(ctx, "organization_id", 1)
+	query := `
+		SELECT * FROM drivers
+		WHERE is_available = true AND driver_on_shift(id, CURRENT_TIMESTAMP)` + tenant + `
+		ORDER BY average_rating DESC`
+	err := r.db.SelectContext(ctx, &drivers, query, args...)
 	return drivers, err
 }
 
-// GetNearestDriver finds the nearest available driver to a given location
+// LeaderboardStats totals the collections each driver of the organization of
+// ctx completed in the range. A collection is on time when it was completed
+// within onTimeWithin of being assigned.
+func (r *DriverRepository) LeaderboardStats(ctx context.Context, period models.AnalyticsRange, onTimeWithin time.Duration) ([]models.DriverLeaderboardEntry, error) {
+	tenant, args := tenantCondition(ctx, "d.organization_id", 4)
+	query := `
+		SELECT d.id AS driver_id, d.full_name,
+			COALESCE(d.average_rating, 0)::float8 AS average_rating,
+			COUNT(c.id) AS completed,
+			COALESCE(SUM(c.weight_kg), 0) AS weight_kg,
+			COUNT(c.id) FILTER (WHERE c.completed_at <= c.started_at + make_interval(secs => $3::float8)) AS on_time
+		FROM drivers d
+		LEFT JOIN collections c ON c.driver_id = d.id AND c.status = 'completed'
+			AND c.completed_at >= $1 AND c.completed_at < $2
+		WHERE true` + tenant + `
+		GROUP BY d.id`
+
+	var entries []models.DriverLeaderboardEntry
+	err := r.db.SelectContext(ctx, &entries, query, append([]interface{}{period.From, period.To, onTimeWithin.Seconds()}, args...)...)
+	return entries, err
+}
+
+// GetNearestDriver finds the nearest available on-shift driver to a given
+// location, within the organization of ctx
 func (r *DriverRepository) GetNearestDriver(ctx context.Context, lat, lng float64) (*models.Driver, error) {
+	return r.GetNearestDriverExcluding(ctx, lat, lng, nil)
+}
+
+// GetNearestDriverExcluding finds the nearest available on-shift driver to a
+// given location other than the excluded ones, within the organization of ctx
+func (r *DriverRepository) GetNearestDriverExcluding(ctx context.Context, lat, lng float64, exclude []uuid.UUID) (*models.Driver, error) {
 	var driver models.Driver
+	tenant, args := tenantCondition(ctx, "organization_id", 4)
+	excluded := make([]string, len(exclude))
+	for i, id := range exclude {
+		excluded[i] = id.String()
+	}
 	// Using Haversine formula approximation for distance calculation
 	query := `
-		SELECT *,
-			(6371 * acos(cos(radians($1)) * cos(radians(latitude)) * cos(radians(longitude) - radians($2)) + sin(radians($1)) * sin(radians(latitude)))) AS distance
-		FROM drivers
+		SELECT * FROM drivers
 		WHERE is_available = true AND latitude IS NOT NULL AND longitude IS NOT NULL
-		ORDER BY distance ASC
+			AND driver_on_shift(id, CURRENT_TIMESTAMP) AND NOT (id = ANY($3::uuid[]))` + tenant + `
+		ORDER BY (6371 * acos(LEAST(1, cos(radians($1)) * cos(radians(latitude)) * cos(radians(longitude) - radians($2)) + sin(radians($1)) * sin(radians(latitude))))) ASC
 		LIMIT 1`
 
-	err := r.db.GetContext(ctx, &driver, query, lat, lng)
+	err := r.db.GetContext(ctx, &driver, query, append([]interface{}{lat, lng, pq.StringArray(excluded)}, args...)...)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
 	}
 	return &driver, err
 }
 
-// List retrieves all drivers with pagination
-func (r *DriverRepository) List(ctx context.Context, limit, offset int) ([]models.Driver, error) {
-	var drivers []models.Driver
-	query := `SELECT * FROM drivers ORDER BY created_at DESC LIMIT $1 OFFSET $2`
-	err := r.db.SelectContext(ctx, &drivers, query, limit, offset)
-	return drivers, err
+// List retrieves the drivers of the organization of ctx with pagination, newest first
+func (r *DriverRepository) List(ctx context.Context, page Page) ([]models.Driver, PageResult, error) {
+	q := &listQuery{from: "drivers"}
+	q.tenant(ctx, "organization_id")
+	return listPage(ctx, r.db, q, page, func(d models.Driver) Cursor {
+		return Cursor{Keys: []string{timeKey(d.CreatedAt)}, ID: d.ID}
+	}, true, "created_at")
 }
 
-// Delete deletes a driver
+// Delete deletes a driver within the organization of ctx
 func (r *DriverRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM drivers WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `DELETE FROM drivers WHERE id = $1` + tenant
+	return affected(r.db.ExecContext(ctx, query, append([]interface{}{id}, args...)...))
 }
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// DriverShiftRepository handles driver shift data operations
+type DriverShiftRepository struct {
+	db *DB
+}
+
+// NewDriverShiftRepository creates a new DriverShiftRepository instance
+func NewDriverShiftRepository(db *DB) *DriverShiftRepository {
+	return &DriverShiftRepository{db: db}
+}
+
+// Create creates a new shift for a driver of the organization of ctx. It
+// returns ErrNotFound when the driver is not in it.
+func (r *DriverShiftRepository) Create(ctx context.Context, shift *models.DriverShift) error {
+	tenant, args := tenantCondition(ctx, "organization_id", 8)
+	query := `
+		INSERT INTO driver_shifts (driver_id, day_of_week, start_time, end_time, timezone, starts_at, ends_at)
+		SELECT id, $2, $3, $4, $5, $6, $7 FROM drivers WHERE id = $1` + tenant + `
+		RETURNING id, created_at`
+
+	err := r.db.QueryRowxContext(ctx, query, append([]interface{}{
+		shift.DriverID,
+		shift.DayOfWeek,
+		shift.StartTime,
+		shift.EndTime,
+		shift.Timezone,
+		shift.StartsAt,
+		shift.EndsAt,
+	}, args...)...).Scan(&shift.ID, &shift.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// GetByID retrieves a shift of a driver of the organization of ctx by ID
+func (r *DriverShiftRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DriverShift, error) {
+	var shift models.DriverShift
+	owner, args := ownerCondition(ctx, "driver_id", "drivers", 2)
+	query := `SELECT * FROM driver_shifts WHERE id = $1` + owner
+
+	err := r.db.GetContext(ctx, &shift, query, append([]interface{}{id}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &shift, err
+}
+
+// ListByDriver retrieves the shifts of a driver of the organization of ctx,
+// recurring shifts first in weekly order
+func (r *DriverShiftRepository) ListByDriver(ctx context.Context, driverID uuid.UUID) ([]models.DriverShift, error) {
+	var shifts []models.DriverShift
+	owner, args := ownerCondition(ctx, "driver_id", "drivers", 2)
+	query := `
+		SELECT * FROM driver_shifts
+		WHERE driver_id = $1` + owner + `
+		ORDER BY day_of_week ASC NULLS LAST, start_time ASC, starts_at ASC`
+	err := r.db.SelectContext(ctx, &shifts, query, append([]interface{}{driverID}, args...)...)
+	return shifts, err
+}
+
+// IsOnShift returns true if the driver, of the organization of ctx, is
+// working now
+func (r *DriverShiftRepository) IsOnShift(ctx context.Context, driverID uuid.UUID) (bool, error) {
+	var onShift bool
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM drivers WHERE id = $1` + tenant + ` AND driver_on_shift(id, CURRENT_TIMESTAMP)
+		)`
+	err := r.db.GetContext(ctx, &onShift, query, append([]interface{}{driverID}, args...)...)
+	return onShift, err
+}
+
+// Delete deletes a shift of a driver of the organization of ctx
+func (r *DriverShiftRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	owner, args := ownerCondition(ctx, "driver_id", "drivers", 2)
+	query := `DELETE FROM driver_shifts WHERE id = $1` + owner
+	return affected(r.db.ExecContext(ctx, query, append([]interface{}{id}, args...)...))
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// EarningRepository handles the driver earnings ledger and the payout batches
+// settling it
+type EarningRepository struct {
+	db dbtx
+}
+
+// NewEarningRepository creates a new EarningRepository instance
+func NewEarningRepository(db *DB) *EarningRepository {
+	return &EarningRepository{db: db}
+}
+
+// Record records a ledger entry in the organization of its driver. It
+// returns false if the source was already credited or the driver is unknown.
+func (r *EarningRepository) Record(ctx context.Context, earning *models.DriverEarning) (bool, error) {
+	query := `
+		INSERT INTO driver_earnings (organization_id, driver_id, source, source_id, amount, currency, weight_kg, description, created_by)
+		SELECT organization_id, id, $2, $3, $4, $5, $6, $7, $8 FROM drivers WHERE id = $1
+		ON CONFLICT (source, source_id) DO NOTHING
+		RETURNING id, organization_id, earned_at`
+
+	err := r.db.QueryRowxContext(ctx, query,
+		earning.DriverID,
+		earning.Source,
+		earning.SourceID,
+		earning.Amount,
+		earning.Currency,
+		earning.WeightKg,
+		earning.Description,
+		earning.CreatedBy,
+	).Scan(&earning.ID, &earning.OrganizationID, &earning.EarnedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// ListByDriver retrieves the earnings of a driver within the organization of
+// ctx over [from, to), oldest first, with whether their batch was paid
+func (r *EarningRepository) ListByDriver(ctx context.Context, driverID uuid.UUID, from, to time.Time) ([]models.DriverEarning, error) {
+	var earnings []models.DriverEarning
+	tenant, args := tenantCondition(ctx, "e.organization_id", 4)
+	query := `
+		SELECT e.*, COALESCE(b.status = 'paid', false) AS paid
+		FROM driver_earnings e
+		LEFT JOIN payout_batches b ON b.id = e.payout_batch_id
+		WHERE e.driver_id = $1 AND e.earned_at >= $2 AND e.earned_at < $3` + tenant + `
+		ORDER BY e.earned_at, e.id`
+
+	err := r.db.SelectContext(ctx, &earnings, query, append([]interface{}{driverID, from, to}, args...)...)
+	return earnings, err
+}
+
+// CreateBatch creates a payout batch of the organization of ctx settling the
+// unpaid earnings in its currency earned before the end of its period, with
+// one line per driver. It returns ErrNotFound, creating nothing, when there
+// is nothing to pay.
+func (r *EarningRepository) CreateBatch(ctx context.Context, batch *models.PayoutBatch) error {
+	assignOrganization(ctx, &batch.OrganizationID)
+
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		query := `
+			INSERT INTO payout_batches (organization_id, period_start, period_end, currency, created_by)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id, status, created_at`
+		err := tx.QueryRowxContext(ctx, query,
+			batch.OrganizationID,
+			batch.PeriodStart,
+			batch.PeriodEnd,
+			batch.Currency,
+			batch.CreatedBy,
+		).Scan(&batch.ID, &batch.Status, &batch.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		// Earnings claimed by a concurrent batch are skipped once it commits
+		query = `
+			UPDATE driver_earnings SET payout_batch_id = $1
+			WHERE organization_id = $2 AND currency = $3 AND earned_at < $4 AND payout_batch_id IS NULL`
+		if _, err := tx.ExecContext(ctx, query, batch.ID, batch.OrganizationID, batch.Currency, batch.PeriodEnd); err != nil {
+			return err
+		}
+
+		query = `
+			INSERT INTO payout_lines (batch_id, driver_id, amount, earnings_count)
+			SELECT payout_batch_id, driver_id, SUM(amount), COUNT(*)
+			FROM driver_earnings WHERE payout_batch_id = $1
+			GROUP BY payout_batch_id, driver_id`
+		if _, err := tx.ExecContext(ctx, query, batch.ID); err != nil {
+			return err
+		}
+
+		query = `
+			UPDATE payout_batches SET
+				total_amount = (SELECT COALESCE(SUM(amount), 0) FROM payout_lines WHERE batch_id = $1),
+				driver_count = (SELECT COUNT(*) FROM payout_lines WHERE batch_id = $1)
+			WHERE id = $1
+			RETURNING total_amount, driver_count, updated_at`
+		if err := tx.QueryRowxContext(ctx, query, batch.ID).Scan(&batch.TotalAmount, &batch.DriverCount, &batch.UpdatedAt); err != nil {
+			return err
+		}
+		if batch.DriverCount == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+// GetBatch retrieves a payout batch by ID within the organization of ctx
+func (r *EarningRepository) GetBatch(ctx context.Context, id uuid.UUID) (*models.PayoutBatch, error) {
+	var batch models.PayoutBatch
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `SELECT * FROM payout_batches WHERE id = $1` + tenant
+
+	err := r.db.GetContext(ctx, &batch, query, append([]interface{}{id}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &batch, err
+}
+
+// ListBatches retrieves the payout batches of the organization of ctx with
+// pagination, newest first
+func (r *EarningRepository) ListBatches(ctx context.Context, status *models.PayoutBatchStatus, page Page) ([]models.PayoutBatch, PageResult, error) {
+	q := &listQuery{from: "payout_batches"}
+	q.tenant(ctx, "organization_id")
+	if status != nil {
+		q.where("status = $%d", *status)
+	}
+
+	return listPage(ctx, r.db, q, page, func(batch models.PayoutBatch) Cursor {
+		return Cursor{Keys: []string{timeKey(batch.CreatedAt)}, ID: batch.ID}
+	}, true, "created_at")
+}
+
+// ListLines retrieves the lines of a payout batch, largest first
+func (r *EarningRepository) ListLines(ctx context.Context, batchID uuid.UUID) ([]models.PayoutLine, error) {
+	var lines []models.PayoutLine
+	query := `
+		SELECT l.*, d.full_name
+		FROM payout_lines l
+		JOIN drivers d ON d.id = l.driver_id
+		WHERE l.batch_id = $1
+		ORDER BY l.amount DESC, d.full_name`
+
+	err := r.db.SelectContext(ctx, &lines, query, batchID)
+	return lines, err
+}
+
+// MarkBatchPaid marks a pending payout batch paid. It returns ErrNotFound
+// when the batch is not pending anymore.
+func (r *EarningRepository) MarkBatchPaid(ctx context.Context, batch *models.PayoutBatch) error {
+	query := `
+		UPDATE payout_batches SET status = 'paid', paid_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'pending'
+		RETURNING status, paid_at, updated_at`
+
+	err := r.db.QueryRowxContext(ctx, query, batch.ID).Scan(&batch.Status, &batch.PaidAt, &batch.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// EmissionFactorRepository handles the emission factors of waste types
+type EmissionFactorRepository struct {
+	db *DB
+}
+
+// NewEmissionFactorRepository creates a new EmissionFactorRepository instance
+func NewEmissionFactorRepository(db *DB) *EmissionFactorRepository {
+	return &EmissionFactorRepository{db: db}
+}
+
+// Create creates a new emission factor
+func (r *EmissionFactorRepository) Create(ctx context.Context, factor *models.EmissionFactor) error {
+	query := `
+		INSERT INTO emission_factors (waste_type, kg_co2e_per_kg, source)
+		VALUES ($1, $2, $3)
+		RETURNING id, is_active, created_at, updated_at`
+
+	return r.db.QueryRowxContext(ctx, query,
+		factor.WasteType,
+		factor.KgCO2ePerKg,
+		factor.Source,
+	).Scan(&factor.ID, &factor.IsActive, &factor.CreatedAt, &factor.UpdatedAt)
+}
+
+// GetByID retrieves an emission factor by ID
+func (r *EmissionFactorRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.EmissionFactor, error) {
+	var factor models.EmissionFactor
+	query := `SELECT * FROM emission_factors WHERE id = $1`
+
+	err := r.db.GetContext(ctx, &factor, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &factor, err
+}
+
+// Update updates an emission factor
+func (r *EmissionFactorRepository) Update(ctx context.Context, factor *models.EmissionFactor) error {
+	query := `
+		UPDATE emission_factors
+		SET kg_co2e_per_kg = $1, source = $2, is_active = $3
+		WHERE id = $4
+		RETURNING updated_at`
+
+	return r.db.QueryRowxContext(ctx, query,
+		factor.KgCO2ePerKg,
+		factor.Source,
+		factor.IsActive,
+		factor.ID,
+	).Scan(&factor.UpdatedAt)
+}
+
+// List retrieves all active emission factors
+func (r *EmissionFactorRepository) List(ctx context.Context) ([]models.EmissionFactor, error) {
+	var factors []models.EmissionFactor
+	query := `SELECT * FROM emission_factors WHERE is_active = true ORDER BY waste_type`
+	err := r.db.SelectContext(ctx, &factors, query)
+	return factors, err
+}
+
+// Delete deletes an emission factor (soft delete)
+func (r *EmissionFactorRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE emission_factors SET is_active = false WHERE id = $1`
+	return affected(r.db.ExecContext(ctx, query, id))
+}
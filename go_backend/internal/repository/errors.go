@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// PostgreSQL error codes surfaced to callers
+const (
+	pgUniqueViolation       = "23505"
+	pgForeignKeyViolation   = "23503"
+	pgNotNullViolation      = "23502"
+	pgCheckViolation        = "23514"
+	pgExclusionViolation    = "23P01"
+	pgInvalidTextValue      = "22P02"
+	pgStringTooLong         = "22001"
+	pgNumericOutOfRange     = "22003"
+	pgInvalidDatetimeFormat = "22007"
+)
+
+// Errors of the repositories, wrapped in a *ConstraintError when they come
+// from a constraint of the database
+var (
+	// ErrNotFound is returned when the row to change does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned when a write conflicts with existing rows, such
+	// as a duplicate key or deleting a row other rows still reference
+	ErrConflict = errors.New("conflict")
+	// ErrForeignKey is returned when a write references a row that does not exist
+	ErrForeignKey = errors.New("foreign key violation")
+	// ErrInvalid is returned when the database rejects a value, such as a
+	// missing column or a failed check constraint
+	ErrInvalid = errors.New("invalid value")
+)
+
+// keyDetail matches the "Key (column)=(value)" prefix of the detail of
+// PostgreSQL constraint errors
+var keyDetail = regexp.MustCompile(`^Key \((.+?)\)=\((.*?)\)`)
+
+// ConstraintError is a write the database rejected. It matches its Kind, one
+// of the errors above, and the underlying *pq.Error with errors.Is and
+// errors.As.
+type ConstraintError struct {
+	Kind       error
+	Table      string
+	Column     string
+	Constraint string
+	// Message explains the error to the client
+	Message string
+	Err     *pq.Error
+}
+
+func (e *ConstraintError) Error() string {
+	return fmt.Sprintf("%v: %s", e.Kind, e.Message)
+}
+
+// Unwrap returns the kind and the underlying PostgreSQL error
+func (e *ConstraintError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// Translate turns the PostgreSQL errors of err that clients can act on into
+// a *ConstraintError, and sql.ErrNoRows into ErrNotFound. Other errors are
+// returned unchanged.
+func Translate(err error) error {
+	if err == nil {
+		return nil
+	}
+	var constraintErr *ConstraintError
+	if errors.As(err, &constraintErr) {
+		return err
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+	e := &ConstraintError{
+		Table:      pqErr.Table,
+		Column:     pqErr.Column,
+		Constraint: pqErr.Constraint,
+		Err:        pqErr,
+	}
+	columns, value := "", ""
+	if match := keyDetail.FindStringSubmatch(pqErr.Detail); match != nil {
+		columns, value = match[1], match[2]
+	}
+
+	switch pqErr.Code {
+	case pgUniqueViolation:
+		e.Kind = ErrConflict
+		e.Message = "A record with this value already exists"
+		if columns != "" {
+			e.Message = fmt.Sprintf("A record with %s %q already exists", columns, value)
+		}
+	case pgExclusionViolation:
+		e.Kind = ErrConflict
+		e.Message = "Overlaps an existing record"
+	case pgForeignKeyViolation:
+		if strings.Contains(pqErr.Detail, "is still referenced") {
+			e.Kind = ErrConflict
+			e.Message = "The record is still referenced by other records"
+			if referencing := referencingTable(pqErr.Detail); referencing != "" {
+				e.Message = "The record is still referenced by " + referencing
+			}
+		} else {
+			e.Kind = ErrForeignKey
+			e.Message = "References a record that does not exist"
+			if columns != "" {
+				e.Message = fmt.Sprintf("%s %q does not exist", columns, value)
+			}
+		}
+	case pgNotNullViolation:
+		e.Kind = ErrInvalid
+		e.Message = "A required value is missing"
+		if pqErr.Column != "" {
+			e.Message = pqErr.Column + " is required"
+		}
+	case pgCheckViolation:
+		e.Kind = ErrInvalid
+		e.Message = "A value is out of the allowed range"
+		if pqErr.Constraint != "" {
+			e.Message = "Violates the " + pqErr.Constraint + " constraint"
+		}
+	case pgInvalidTextValue, pgStringTooLong, pgNumericOutOfRange, pgInvalidDatetimeFormat:
+		e.Kind = ErrInvalid
+		e.Message = pqErr.Message
+	default:
+		return err
+	}
+	return e
+}
+
+// referencingTable is the table named by the detail of a foreign key
+// violation on delete, such as `... is still referenced from table "collections".`
+func referencingTable(detail string) string {
+	_, table, ok := strings.Cut(detail, `from table "`)
+	if !ok {
+		return ""
+	}
+	table, _, _ = strings.Cut(table, `"`)
+	return table
+}
+
+// affected returns ErrNotFound when the statement with the result changed no
+// rows
+func affected(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// IsUniqueViolation returns true if err is a unique constraint violation
+func IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == pgUniqueViolation
+}
+
+// IsForeignKeyViolation returns true if err is a foreign key constraint violation
+func IsForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == pgForeignKeyViolation
+}
@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// FirmwareRepository handles firmware rollouts and their progress per bin
+type FirmwareRepository struct {
+	db *DB
+}
+
+// NewFirmwareRepository creates a new FirmwareRepository instance
+func NewFirmwareRepository(db *DB) *FirmwareRepository {
+	return &FirmwareRepository{db: db}
+}
+
+// Create creates a rollout with a pending update for every active bin of its
+// device group not already running its version, and returns the device IDs
+// of those bins. Pending updates of earlier rollouts on the same bins are
+// cancelled: a sensor is only offered the latest one.
+func (r *FirmwareRepository) Create(ctx context.Context, rollout *models.FirmwareRollout) ([]string, error) {
+	assignOrganization(ctx, &rollout.OrganizationID)
+	var deviceIDs []string
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		query := `
+			INSERT INTO firmware_rollouts (organization_id, device_group, version, url, sha256, signature, created_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id, status, created_at`
+		if err := tx.QueryRowxContext(ctx, query,
+			rollout.OrganizationID,
+			rollout.DeviceGroup,
+			rollout.Version,
+			rollout.URL,
+			rollout.SHA256,
+			rollout.Signature,
+			rollout.CreatedBy,
+		).Scan(&rollout.ID, &rollout.Status, &rollout.CreatedAt); err != nil {
+			return err
+		}
+
+		query = `
+			INSERT INTO firmware_updates (rollout_id, bin_id)
+			SELECT $1, id FROM bins
+			WHERE organization_id = $2 AND is_active = true
+				AND ($3::VARCHAR IS NULL OR device_group = $3)
+				AND firmware_version IS DISTINCT FROM $4`
+		if _, err := tx.ExecContext(ctx, query, rollout.ID, rollout.OrganizationID, rollout.DeviceGroup, rollout.Version); err != nil {
+			return err
+		}
+
+		query = `
+			UPDATE firmware_updates SET status = 'cancelled', updated_at = NOW()
+			WHERE status = 'pending' AND rollout_id <> $1
+				AND bin_id IN (SELECT bin_id FROM firmware_updates WHERE rollout_id = $1)`
+		if _, err := tx.ExecContext(ctx, query, rollout.ID); err != nil {
+			return err
+		}
+
+		query = `
+			SELECT b.device_id FROM firmware_updates u
+			JOIN bins b ON b.id = u.bin_id
+			WHERE u.rollout_id = $1`
+		return tx.SelectContext(ctx, &deviceIDs, query, rollout.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rollout.Progress = map[models.FirmwareUpdateStatus]int{}
+	if len(deviceIDs) > 0 {
+		rollout.Progress[models.FirmwareUpdatePending] = len(deviceIDs)
+	}
+	return deviceIDs, nil
+}
+
+// GetByID retrieves a rollout by ID with its progress
+func (r *FirmwareRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.FirmwareRollout, error) {
+	var rollout models.FirmwareRollout
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `SELECT * FROM firmware_rollouts WHERE id = $1` + tenant
+
+	err := r.db.GetContext(ctx, &rollout, query, append([]interface{}{id}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	rollouts := []models.FirmwareRollout{rollout}
+	if err := r.fillProgress(ctx, rollouts); err != nil {
+		return nil, err
+	}
+	return &rollouts[0], nil
+}
+
+// ListFiltered retrieves rollouts with their progress, newest first
+func (r *FirmwareRepository) ListFiltered(ctx context.Context, filter models.FirmwareRolloutFilter, page Page) ([]models.FirmwareRollout, PageResult, error) {
+	q := &listQuery{from: "firmware_rollouts"}
+	q.tenant(ctx, "organization_id")
+	if filter.Status != nil {
+		q.where("status = $%d", *filter.Status)
+	}
+	if filter.DeviceGroup != nil {
+		q.where("device_group = $%d", *filter.DeviceGroup)
+	}
+
+	rollouts, result, err := listPage(ctx, r.db, q, page, func(f models.FirmwareRollout) Cursor {
+		return Cursor{Keys: []string{timeKey(f.CreatedAt)}, ID: f.ID}
+	}, true, "created_at")
+	if err != nil {
+		return nil, result, err
+	}
+	return rollouts, result, r.fillProgress(ctx, rollouts)
+}
+
+// fillProgress counts the updates of each rollout by status in one query
+func (r *FirmwareRepository) fillProgress(ctx context.Context, rollouts []models.FirmwareRollout) error {
+	if len(rollouts) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(rollouts))
+	index := make(map[uuid.UUID]int, len(rollouts))
+	for i := range rollouts {
+		ids[i] = rollouts[i].ID
+		index[rollouts[i].ID] = i
+		rollouts[i].Progress = map[models.FirmwareUpdateStatus]int{}
+	}
+
+	var counts []struct {
+		RolloutID uuid.UUID                   `db:"rollout_id"`
+		Status    models.FirmwareUpdateStatus `db:"status"`
+		Count     int                         `db:"count"`
+	}
+	query := `
+		SELECT rollout_id, status, COUNT(*) AS count FROM firmware_updates
+		WHERE rollout_id = ANY($1)
+		GROUP BY rollout_id, status`
+	if err := r.db.SelectContext(ctx, &counts, query, pq.Array(ids)); err != nil {
+		return err
+	}
+	for _, c := range counts {
+		rollouts[index[c.RolloutID]].Progress[c.Status] = c.Count
+	}
+	return nil
+}
+
+// Updates retrieves the progress of a rollout on each of its bins, most
+// recently changed first
+func (r *FirmwareRepository) Updates(ctx context.Context, rolloutID uuid.UUID) ([]models.FirmwareUpdate, error) {
+	var updates []models.FirmwareUpdate
+	query := `
+		SELECT u.*, b.device_id FROM firmware_updates u
+		JOIN bins b ON b.id = u.bin_id
+		WHERE u.rollout_id = $1
+		ORDER BY u.updated_at DESC, b.device_id`
+	err := r.db.SelectContext(ctx, &updates, query, rolloutID)
+	return updates, err
+}
+
+// Cancel cancels an active rollout and its pending updates, and returns the
+// device IDs of the bins those were offered to. Updates already under way are
+// left to finish. It returns ErrNotFound when no active rollout has the ID.
+func (r *FirmwareRepository) Cancel(ctx context.Context, id uuid.UUID) ([]string, error) {
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	var deviceIDs []string
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		query := `
+			UPDATE firmware_rollouts SET status = 'cancelled', cancelled_at = NOW()
+			WHERE id = $1 AND status = 'active'` + tenant
+		if err := affected(tx.ExecContext(ctx, query, append([]interface{}{id}, args...)...)); err != nil {
+			return err
+		}
+
+		query = `
+			UPDATE firmware_updates u SET status = 'cancelled', updated_at = NOW()
+			FROM bins b
+			WHERE b.id = u.bin_id AND u.rollout_id = $1 AND u.status = 'pending'
+			RETURNING b.device_id`
+		return tx.SelectContext(ctx, &deviceIDs, query, id)
+	})
+	return deviceIDs, err
+}
+
+// UpdateStatus records the progress reported by the sensor of a device on a
+// rollout. An update the sensor already finished is left unchanged, and
+// ErrNotFound is returned when the rollout was not offered to the device.
+func (r *FirmwareRepository) UpdateStatus(ctx context.Context, deviceID string, rolloutID uuid.UUID, status models.FirmwareUpdateStatus, message *string) error {
+	query := `
+		UPDATE firmware_updates u SET status = $1, error = $2, updated_at = NOW()
+		FROM bins b
+		WHERE b.id = u.bin_id AND b.device_id = $3 AND u.rollout_id = $4
+			AND u.status NOT IN ('updated', 'failed')`
+	return affected(r.db.ExecContext(ctx, query, status, message, deviceID, rolloutID))
+}
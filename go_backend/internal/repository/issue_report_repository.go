@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// IssueReportRepository handles the issue reports of bins
+type IssueReportRepository struct {
+	db *DB
+}
+
+// NewIssueReportRepository creates a new IssueReportRepository instance
+func NewIssueReportRepository(db *DB) *IssueReportRepository {
+	return &IssueReportRepository{db: db}
+}
+
+// Create creates a new issue report
+func (r *IssueReportRepository) Create(ctx context.Context, report *models.IssueReport) error {
+	query := `
+		INSERT INTO issue_reports (bin_id, reported_by, reporter_role, type, description, photo_url, latitude, longitude)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, status, created_at, updated_at`
+
+	return r.db.QueryRowxContext(ctx, query,
+		report.BinID,
+		report.ReportedBy,
+		report.ReporterRole,
+		report.Type,
+		report.Description,
+		report.PhotoURL,
+		report.Latitude,
+		report.Longitude,
+	).Scan(&report.ID, &report.Status, &report.CreatedAt, &report.UpdatedAt)
+}
+
+// GetByID retrieves an issue report by ID, if its bin belongs to the
+// organization of ctx
+func (r *IssueReportRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.IssueReport, error) {
+	var report models.IssueReport
+	owner, args := ownerCondition(ctx, "bin_id", "bins", 2)
+	query := `SELECT * FROM issue_reports WHERE id = $1` + owner
+
+	err := r.db.GetContext(ctx, &report, query, append([]interface{}{id}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &report, err
+}
+
+// ListFiltered retrieves the issue reports on bins of the organization of ctx
+// matching the filter with pagination, newest first
+func (r *IssueReportRepository) ListFiltered(ctx context.Context, filter models.IssueReportFilter, page Page) ([]models.IssueReport, PageResult, error) {
+	q := &listQuery{from: "issue_reports"}
+	q.owner(ctx, "bin_id", "bins")
+	if filter.BinID != nil {
+		q.where("bin_id = $%d", *filter.BinID)
+	}
+	if filter.ReportedBy != nil {
+		q.where("reported_by = $%d", *filter.ReportedBy)
+	}
+	if filter.Status != nil {
+		q.where("status = $%d", *filter.Status)
+	}
+	if filter.Type != nil {
+		q.where("type = $%d", *filter.Type)
+	}
+
+	return listPage(ctx, r.db, q, page, func(report models.IssueReport) Cursor {
+		return Cursor{Keys: []string{timeKey(report.CreatedAt)}, ID: report.ID}
+	}, true, "created_at")
+}
+
+// UpdateStatus stores the triage status and note of an issue report, stamping
+// resolved_at when it is resolved
+func (r *IssueReportRepository) UpdateStatus(ctx context.Context, report *models.IssueReport) error {
+	query := `
+		UPDATE issue_reports
+		SET status = $1, triage_note = $2, triaged_by = $3,
+			resolved_at = CASE WHEN $1 = 'resolved' THEN CURRENT_TIMESTAMP ELSE resolved_at END
+		WHERE id = $4
+		RETURNING resolved_at, updated_at`
+
+	return r.db.QueryRowxContext(ctx, query,
+		report.Status,
+		report.TriageNote,
+		report.TriagedBy,
+		report.ID,
+	).Scan(&report.ResolvedAt, &report.UpdatedAt)
+}
+
+// CountOpenReporters counts the distinct people with a report on the bin that
+// was filed since the given time and is not closed yet
+func (r *IssueReportRepository) CountOpenReporters(ctx context.Context, binID uuid.UUID, since time.Time) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(DISTINCT reported_by) FROM issue_reports
+		WHERE bin_id = $1 AND created_at >= $2 AND status NOT IN ('resolved', 'rejected')`
+	err := r.db.GetContext(ctx, &count, query, binID, since)
+	return count, err
+}
+
+// CountOpen counts the reports on the bin that are not closed yet
+func (r *IssueReportRepository) CountOpen(ctx context.Context, binID uuid.UUID) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM issue_reports WHERE bin_id = $1 AND status NOT IN ('resolved', 'rejected')`
+	err := r.db.GetContext(ctx, &count, query, binID)
+	return count, err
+}
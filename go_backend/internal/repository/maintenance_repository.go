@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// MaintenanceRepository handles the maintenance of bins. Every change keeps
+// the status of the bin in step: it is under maintenance while one of its
+// records is in progress.
+type MaintenanceRepository struct {
+	db *DB
+}
+
+// NewMaintenanceRepository creates a new MaintenanceRepository instance
+func NewMaintenanceRepository(db *DB) *MaintenanceRepository {
+	return &MaintenanceRepository{db: db}
+}
+
+// Create schedules a maintenance with the status set by the caller, putting
+// its bin under maintenance when it starts at once
+func (r *MaintenanceRepository) Create(ctx context.Context, maintenance *models.BinMaintenance) error {
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		query := `
+			INSERT INTO bin_maintenance (bin_id, type, status, scheduled_for, started_at, description, scheduled_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id, created_at, updated_at`
+		if err := tx.QueryRowxContext(ctx, query,
+			maintenance.BinID,
+			maintenance.Type,
+			maintenance.Status,
+			maintenance.ScheduledFor,
+			maintenance.StartedAt,
+			maintenance.Description,
+			maintenance.ScheduledBy,
+		).Scan(&maintenance.ID, &maintenance.CreatedAt, &maintenance.UpdatedAt); err != nil {
+			return err
+		}
+		return syncBinStatus(ctx, tx, maintenance.BinID)
+	})
+}
+
+// GetByID retrieves a maintenance by ID, if its bin belongs to the
+// organization of ctx
+func (r *MaintenanceRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.BinMaintenance, error) {
+	var maintenance models.BinMaintenance
+	owner, args := ownerCondition(ctx, "bin_id", "bins", 2)
+	query := `SELECT * FROM bin_maintenance WHERE id = $1` + owner
+
+	err := r.db.GetContext(ctx, &maintenance, query, append([]interface{}{id}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &maintenance, err
+}
+
+// ListFiltered retrieves the maintenance of bins of the organization of ctx
+// matching the filter with pagination, latest scheduled first
+func (r *MaintenanceRepository) ListFiltered(ctx context.Context, filter models.MaintenanceFilter, page Page) ([]models.BinMaintenance, PageResult, error) {
+	q := &listQuery{from: "bin_maintenance"}
+	q.owner(ctx, "bin_id", "bins")
+	if filter.BinID != nil {
+		q.where("bin_id = $%d", *filter.BinID)
+	}
+	if filter.Status != nil {
+		q.where("status = $%d", *filter.Status)
+	}
+	if filter.Type != nil {
+		q.where("type = $%d", *filter.Type)
+	}
+
+	return listPage(ctx, r.db, q, page, func(m models.BinMaintenance) Cursor {
+		return Cursor{Keys: []string{timeKey(m.ScheduledFor)}, ID: m.ID}
+	}, true, "scheduled_for")
+}
+
+// UpdateStatus moves a maintenance from the status it was loaded with to
+// maintenance.Status, stamping when it started and ended, and updates the
+// status of its bin. It returns ErrNotFound when the maintenance changed since.
+func (r *MaintenanceRepository) UpdateStatus(ctx context.Context, maintenance *models.BinMaintenance, from models.BinMaintenanceStatus) error {
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		query := `
+			UPDATE bin_maintenance
+			SET status = $1, resolution = $2, completed_by = $3,
+				started_at = CASE WHEN $1 IN ('in_progress', 'completed') THEN COALESCE(started_at, CURRENT_TIMESTAMP) ELSE started_at END,
+				completed_at = CASE WHEN $1 IN ('completed', 'cancelled') THEN CURRENT_TIMESTAMP ELSE completed_at END
+			WHERE id = $4 AND status = $5
+			RETURNING started_at, completed_at, updated_at`
+		err := tx.QueryRowxContext(ctx, query,
+			maintenance.Status,
+			maintenance.Resolution,
+			maintenance.CompletedBy,
+			maintenance.ID,
+			from,
+		).Scan(&maintenance.StartedAt, &maintenance.CompletedAt, &maintenance.UpdatedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return syncBinStatus(ctx, tx, maintenance.BinID)
+	})
+}
+
+// StartDue starts the maintenance scheduled for now or earlier, putting their
+// bins under maintenance. It returns how many were started and the
+// organizations of their bins.
+func (r *MaintenanceRepository) StartDue(ctx context.Context, now time.Time) (int, []uuid.UUID, error) {
+	var binIDs []uuid.UUID
+	var organizationIDs []uuid.UUID
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		query := `
+			UPDATE bin_maintenance SET status = 'in_progress', started_at = CURRENT_TIMESTAMP
+			WHERE status = 'scheduled' AND scheduled_for <= $1
+			RETURNING bin_id`
+		if err := tx.SelectContext(ctx, &binIDs, query, now); err != nil {
+			return err
+		}
+		if len(binIDs) == 0 {
+			return nil
+		}
+
+		query = `
+			WITH changed AS (
+				UPDATE bins SET status = 'maintenance'
+				WHERE id = ANY($1) AND status = 'active'
+				RETURNING organization_id
+			)
+			SELECT DISTINCT organization_id FROM changed`
+		return tx.SelectContext(ctx, &organizationIDs, query, pq.Array(binIDs))
+	})
+	return len(binIDs), organizationIDs, err
+}
+
+// syncBinStatus puts a bin under maintenance within tx while one of its
+// maintenance is in progress, and back in service otherwise. Retired bins
+// are left retired.
+func syncBinStatus(ctx context.Context, tx *sqlx.Tx, binID uuid.UUID) error {
+	query := `
+		UPDATE bins
+		SET status = CASE
+			WHEN EXISTS (SELECT 1 FROM bin_maintenance WHERE bin_id = $1 AND status = 'in_progress') THEN 'maintenance'
+			ELSE 'active'
+		END
+		WHERE id = $1 AND status <> 'retired'`
+	_, err := tx.ExecContext(ctx, query, binID)
+	return err
+}
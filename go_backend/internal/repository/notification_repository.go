@@ -0,0 +1,336 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// NotificationRepository handles notification data operations
+type NotificationRepository struct {
+	db *DB
+}
+
+// NewNotificationRepository creates a new NotificationRepository instance
+func NewNotificationRepository(db *DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// Create stores a new notification
+func (r *NotificationRepository) Create(ctx context.Context, notification *models.Notification) error {
+	if notification.ID == uuid.Nil {
+		notification.ID = uuid.New()
+	}
+
+	query := `
+		INSERT INTO notifications (id, driver_id, user_id, bin_id, type, title, message, alert_id, escalation_level)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING is_read, sent_at`
+
+	return r.db.QueryRowxContext(ctx, query,
+		notification.ID,
+		notification.DriverID,
+		notification.UserID,
+		notification.BinID,
+		notification.Type,
+		notification.Title,
+		notification.Message,
+		notification.AlertID,
+		notification.EscalationLevel,
+	).Scan(&notification.IsRead, &notification.SentAt)
+}
+
+// GetByID retrieves a notification by ID, if its driver belongs to the
+// organization of ctx
+func (r *NotificationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Notification, error) {
+	var notification models.Notification
+	owner, args := ownerCondition(ctx, "driver_id", "drivers", 2)
+	query := `SELECT * FROM notifications WHERE id = $1` + owner
+
+	err := r.db.GetContext(ctx, &notification, query, append([]interface{}{id}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &notification, err
+}
+
+// GetUserNotification retrieves a notification of a user of the organization
+// of ctx
+func (r *NotificationRepository) GetUserNotification(ctx context.Context, id, userID uuid.UUID) (*models.Notification, error) {
+	var notification models.Notification
+	owner, args := ownerCondition(ctx, "user_id", "users", 3)
+	query := `SELECT * FROM notifications WHERE id = $1 AND user_id = $2` + owner
+
+	err := r.db.GetContext(ctx, &notification, query, append([]interface{}{id, userID}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &notification, err
+}
+
+// ListByDriver retrieves notifications for a driver of the organization of
+// ctx, newest first
+func (r *NotificationRepository) ListByDriver(ctx context.Context, driverID uuid.UUID, unreadOnly bool, page Page) ([]models.Notification, PageResult, error) {
+	q := &listQuery{from: "notifications"}
+	q.where("driver_id = $%d", driverID)
+	q.owner(ctx, "driver_id", "drivers")
+	if unreadOnly {
+		q.conditions = append(q.conditions, "is_read = false")
+	}
+	return listPage(ctx, r.db, q, page, func(n models.Notification) Cursor {
+		return Cursor{Keys: []string{timeKey(n.SentAt)}, ID: n.ID}
+	}, true, "sent_at")
+}
+
+// ListByUser retrieves notifications for a user of the organization of ctx,
+// newest first
+func (r *NotificationRepository) ListByUser(ctx context.Context, userID uuid.UUID, unreadOnly bool, page Page) ([]models.Notification, PageResult, error) {
+	q := &listQuery{from: "notifications"}
+	q.where("user_id = $%d", userID)
+	q.owner(ctx, "user_id", "users")
+	if unreadOnly {
+		q.conditions = append(q.conditions, "is_read = false")
+	}
+	return listPage(ctx, r.db, q, page, func(n models.Notification) Cursor {
+		return Cursor{Keys: []string{timeKey(n.SentAt)}, ID: n.ID}
+	}, true, "sent_at")
+}
+
+// CountUnread returns the number of unread notifications for a driver of the
+// organization of ctx
+func (r *NotificationRepository) CountUnread(ctx context.Context, driverID uuid.UUID) (int, error) {
+	var count int
+	owner, args := ownerCondition(ctx, "driver_id", "drivers", 2)
+	query := `SELECT COUNT(*) FROM notifications WHERE driver_id = $1 AND is_read = false` + owner
+	err := r.db.GetContext(ctx, &count, query, append([]interface{}{driverID}, args...)...)
+	return count, err
+}
+
+// MarkRead marks a notification as read
+func (r *NotificationRepository) MarkRead(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE notifications SET is_read = true, read_at = $1 WHERE id = $2 AND is_read = false`
+	_, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	return err
+}
+
+// MarkAllRead marks every unread notification of a driver of the
+// organization of ctx as read
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, driverID uuid.UUID) (int64, error) {
+	owner, args := ownerCondition(ctx, "driver_id", "drivers", 3)
+	query := `UPDATE notifications SET is_read = true, read_at = $1 WHERE driver_id = $2 AND is_read = false` + owner
+	result, err := r.db.ExecContext(ctx, query, append([]interface{}{time.Now(), driverID}, args...)...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// Delete deletes a notification
+func (r *NotificationRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM notifications WHERE id = $1`
+	return affected(r.db.ExecContext(ctx, query, id))
+}
+
+// GetChannels retrieves the channels a driver or user chose to be notified
+// over, in order, with when they were saved. It returns nil if they chose none.
+func (r *NotificationRepository) GetChannels(ctx context.Context, recipientType models.NotificationRecipientType, recipientID uuid.UUID) ([]models.NotificationChannel, *time.Time, error) {
+	var row struct {
+		Channels  pq.StringArray `db:"channels"`
+		UpdatedAt time.Time      `db:"updated_at"`
+	}
+	query := `
+		SELECT channels, updated_at FROM notification_channel_preferences
+		WHERE recipient_type = $1 AND recipient_id = $2`
+
+	err := r.db.GetContext(ctx, &row, query, recipientType, recipientID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	channels := make([]models.NotificationChannel, len(row.Channels))
+	for i, channel := range row.Channels {
+		channels[i] = models.NotificationChannel(channel)
+	}
+	return channels, &row.UpdatedAt, nil
+}
+
+// SetChannels saves the channels a driver or user is notified over, in order
+func (r *NotificationRepository) SetChannels(ctx context.Context, recipientType models.NotificationRecipientType, recipientID uuid.UUID, channels []models.NotificationChannel) (time.Time, error) {
+	values := make([]string, len(channels))
+	for i, channel := range channels {
+		values[i] = string(channel)
+	}
+	query := `
+		INSERT INTO notification_channel_preferences (recipient_type, recipient_id, channels)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (recipient_type, recipient_id) DO UPDATE SET channels = EXCLUDED.channels
+		RETURNING updated_at`
+
+	var updatedAt time.Time
+	err := r.db.QueryRowxContext(ctx, query, recipientType, recipientID, pq.Array(values)).Scan(&updatedAt)
+	return updatedAt, err
+}
+
+// driverSettingsRow is a row of driver_notification_settings
+type driverSettingsRow struct {
+	DriverID        uuid.UUID      `db:"driver_id"`
+	Types           pq.StringArray `db:"types"`
+	QuietHoursStart *string        `db:"quiet_hours_start"`
+	QuietHoursEnd   *string        `db:"quiet_hours_end"`
+	Timezone        string         `db:"timezone"`
+	MaxPerHour      *int           `db:"max_per_hour"`
+	UpdatedAt       time.Time      `db:"updated_at"`
+}
+
+// GetDriverSettings retrieves the notification settings of a driver. It
+// returns nil if the driver saved none.
+func (r *NotificationRepository) GetDriverSettings(ctx context.Context, driverID uuid.UUID) (*models.DriverNotificationSettings, error) {
+	var row driverSettingsRow
+	query := `
+		SELECT driver_id, types,
+			to_char(quiet_hours_start, 'HH24:MI') AS quiet_hours_start,
+			to_char(quiet_hours_end, 'HH24:MI') AS quiet_hours_end,
+			timezone, max_per_hour, updated_at
+		FROM driver_notification_settings
+		WHERE driver_id = $1`
+
+	err := r.db.GetContext(ctx, &row, query, driverID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	settings := &models.DriverNotificationSettings{
+		DriverID:        row.DriverID,
+		QuietHoursStart: row.QuietHoursStart,
+		QuietHoursEnd:   row.QuietHoursEnd,
+		Timezone:        row.Timezone,
+		MaxPerHour:      row.MaxPerHour,
+		UpdatedAt:       &row.UpdatedAt,
+	}
+	for _, notificationType := range row.Types {
+		settings.Types = append(settings.Types, models.NotificationType(notificationType))
+	}
+	return settings, nil
+}
+
+// SetDriverSettings creates or replaces the notification settings of a driver
+func (r *NotificationRepository) SetDriverSettings(ctx context.Context, settings *models.DriverNotificationSettings) error {
+	var types interface{}
+	if len(settings.Types) > 0 {
+		values := make([]string, len(settings.Types))
+		for i, notificationType := range settings.Types {
+			values[i] = string(notificationType)
+		}
+		types = pq.Array(values)
+	}
+
+	query := `
+		INSERT INTO driver_notification_settings (driver_id, types, quiet_hours_start, quiet_hours_end, timezone, max_per_hour)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (driver_id) DO UPDATE
+		SET types = EXCLUDED.types, quiet_hours_start = EXCLUDED.quiet_hours_start,
+			quiet_hours_end = EXCLUDED.quiet_hours_end, timezone = EXCLUDED.timezone,
+			max_per_hour = EXCLUDED.max_per_hour
+		RETURNING updated_at`
+
+	settings.UpdatedAt = new(time.Time)
+	return r.db.QueryRowxContext(ctx, query,
+		settings.DriverID,
+		types,
+		settings.QuietHoursStart,
+		settings.QuietHoursEnd,
+		settings.Timezone,
+		settings.MaxPerHour,
+	).Scan(settings.UpdatedAt)
+}
+
+// MarkDelivered records that a notification was delivered over a channel
+func (r *NotificationRepository) MarkDelivered(ctx context.Context, notification *models.Notification) error {
+	query := `UPDATE notifications SET delivered_at = CURRENT_TIMESTAMP WHERE id = $1 RETURNING delivered_at`
+	return r.db.QueryRowxContext(ctx, query, notification.ID).Scan(&notification.DeliveredAt)
+}
+
+// CountDelivered returns the number of notifications delivered to a driver
+// over a channel since a time
+func (r *NotificationRepository) CountDelivered(ctx context.Context, driverID uuid.UUID, since time.Time) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM notifications WHERE driver_id = $1 AND delivered_at >= $2`
+	err := r.db.GetContext(ctx, &count, query, driverID, since)
+	return count, err
+}
+
+// HasRecentAlert returns true if a bin raised an alert of the type since a
+// time, so repeated sensor reports do not alert again
+func (r *NotificationRepository) HasRecentAlert(ctx context.Context, binID uuid.UUID, notificationType models.NotificationType, since time.Time) (bool, error) {
+	var exists bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM notifications
+			WHERE bin_id = $1 AND type = $2 AND alert_id IS NOT NULL AND sent_at >= $3
+		)`
+	err := r.db.GetContext(ctx, &exists, query, binID, notificationType, since)
+	return exists, err
+}
+
+// Acknowledge marks an alert notification acknowledged and read, which stops
+// its alert from escalating further
+func (r *NotificationRepository) Acknowledge(ctx context.Context, notification *models.Notification) error {
+	query := `
+		UPDATE notifications
+		SET acknowledged_at = COALESCE(acknowledged_at, CURRENT_TIMESTAMP),
+			is_read = true, read_at = COALESCE(read_at, CURRENT_TIMESTAMP)
+		WHERE id = $1
+		RETURNING acknowledged_at, is_read, read_at`
+	return r.db.QueryRowxContext(ctx, query, notification.ID).
+		Scan(&notification.AcknowledgedAt, &notification.IsRead, &notification.ReadAt)
+}
+
+// ClaimEscalations stamps and returns the unacknowledged alert notifications
+// sent before a time that have not escalated yet, below the last level. An
+// alert another recipient acknowledged is skipped. Claiming in one statement
+// keeps concurrent instances from escalating the same notification.
+func (r *NotificationRepository) ClaimEscalations(ctx context.Context, before time.Time) ([]models.Notification, error) {
+	var notifications []models.Notification
+	query := `
+		UPDATE notifications n SET escalated_at = CURRENT_TIMESTAMP
+		WHERE n.alert_id IS NOT NULL AND n.acknowledged_at IS NULL AND n.escalated_at IS NULL
+			AND n.sent_at < $1 AND n.escalation_level < $2
+			AND NOT EXISTS (
+				SELECT 1 FROM notifications a
+				WHERE a.alert_id = n.alert_id AND a.acknowledged_at IS NOT NULL
+			)
+		RETURNING n.*`
+	err := r.db.SelectContext(ctx, &notifications, query, before, models.EscalationDispatchers)
+	return notifications, err
+}
+
+// MarkEscalated stamps an alert notification as passed on, returning false
+// if it already was
+func (r *NotificationRepository) MarkEscalated(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `UPDATE notifications SET escalated_at = CURRENT_TIMESTAMP WHERE id = $1 AND escalated_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+// AlertDrivers returns the drivers an alert was sent to so far
+func (r *NotificationRepository) AlertDrivers(ctx context.Context, alertID uuid.UUID) ([]uuid.UUID, error) {
+	var drivers []uuid.UUID
+	query := `SELECT DISTINCT driver_id FROM notifications WHERE alert_id = $1 AND driver_id IS NOT NULL`
+	err := r.db.SelectContext(ctx, &drivers, query, alertID)
+	return drivers, err
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// OpsRepository reads the live state of operations in one aggregate query
+// per section, for the real-time operations screen
+type OpsRepository struct {
+	db *DB
+}
+
+// NewOpsRepository creates a new OpsRepository instance
+func NewOpsRepository(db *DB) *OpsRepository {
+	return &OpsRepository{db: db}
+}
+
+// liveDriverRow is a driver with the summary of their route in progress
+type liveDriverRow struct {
+	models.LiveDriver
+	RouteStartedAt  *time.Time `db:"route_started_at"`
+	RouteDistanceKm *float64   `db:"route_distance_km"`
+	RouteStops      int        `db:"route_stops"`
+	RouteCompleted  int        `db:"route_completed_stops"`
+}
+
+// Live retrieves the drivers available on shift or on a route, the routes in
+// progress, the bins at or above their collection threshold and the bin
+// alerts raised since alertsSince that nobody acknowledged, within the
+// organization of ctx
+func (r *OpsRepository) Live(ctx context.Context, alertsSince time.Time) (*models.OpsLive, error) {
+	live := &models.OpsLive{
+		GeneratedAt: time.Now(),
+		Drivers:     []models.LiveDriver{},
+		Routes:      []models.LiveRoute{},
+		Bins:        []models.LiveBin{},
+		Alerts:      []models.LiveAlert{},
+	}
+
+	var drivers []liveDriverRow
+	tenant, args := tenantCondition(ctx, "d.organization_id", 1)
+	query := `
+		SELECT d.id, d.full_name, d.latitude, d.longitude, d.is_available, d.vehicle_id,
+			driver_on_shift(d.id, CURRENT_TIMESTAMP) AS on_shift,
+			r.id AS route_id, r.started_at AS route_started_at, r.total_distance_km AS route_distance_km,
+			COALESCE(jsonb_array_length(r.waypoints), 0) AS route_stops,
+			COALESCE((
+				SELECT COUNT(*) FROM jsonb_array_elements(r.waypoints) w
+				WHERE (w->>'is_completed')::boolean
+			), 0) AS route_completed_stops
+		FROM drivers d
+		LEFT JOIN LATERAL (
+			SELECT id, started_at, total_distance_km, waypoints FROM driver_routes
+			WHERE driver_id = d.id AND status = 'in_progress'
+			ORDER BY started_at DESC NULLS LAST
+			LIMIT 1
+		) r ON true
+		WHERE ((d.is_available = true AND driver_on_shift(d.id, CURRENT_TIMESTAMP)) OR r.id IS NOT NULL)` + tenant + `
+		ORDER BY d.full_name, d.id`
+	if err := r.db.SelectContext(ctx, &drivers, query, args...); err != nil {
+		return nil, err
+	}
+	for _, driver := range drivers {
+		live.Drivers = append(live.Drivers, driver.LiveDriver)
+		if driver.RouteID != nil {
+			live.Routes = append(live.Routes, models.LiveRoute{
+				ID:              *driver.RouteID,
+				DriverID:        driver.ID,
+				DriverName:      driver.FullName,
+				StartedAt:       driver.RouteStartedAt,
+				Stops:           driver.RouteStops,
+				CompletedStops:  driver.RouteCompleted,
+				TotalDistanceKm: driver.RouteDistanceKm,
+			})
+		}
+	}
+
+	tenant, args = tenantCondition(ctx, "b.organization_id", 1)
+	query = `
+		SELECT b.id, b.device_id, b.location_name, b.latitude, b.longitude, b.fill_level,
+			b.collection_threshold, b.alert_threshold,
+			c.id AS collection_id, c.driver_id AS collection_driver_id, c.status AS collection_status
+		FROM bins b
+		LEFT JOIN LATERAL (
+			SELECT id, driver_id, status FROM collections
+			WHERE bin_id = b.id AND status IN ('pending', 'in_progress')
+			ORDER BY started_at DESC
+			LIMIT 1
+		) c ON true
+		WHERE b.status = 'active' AND b.fill_level >= b.collection_threshold` + tenant + `
+		ORDER BY b.fill_level DESC, b.id`
+	if err := r.db.SelectContext(ctx, &live.Bins, query, args...); err != nil {
+		return nil, err
+	}
+
+	tenant, args = tenantCondition(ctx, "b.organization_id", 2)
+	query = `
+		SELECT n.alert_id, n.bin_id, b.device_id, n.type,
+			MAX(n.escalation_level) AS escalation_level, COUNT(*) AS recipients,
+			MIN(n.sent_at) AS raised_at, MAX(n.sent_at) AS last_sent_at
+		FROM notifications n
+		JOIN bins b ON b.id = n.bin_id
+		WHERE n.alert_id IS NOT NULL AND n.sent_at >= $1` + tenant + `
+		GROUP BY n.alert_id, n.bin_id, b.device_id, n.type
+		HAVING COUNT(n.acknowledged_at) = 0
+		ORDER BY MAX(n.escalation_level) DESC, MIN(n.sent_at)`
+	if err := r.db.SelectContext(ctx, &live.Alerts, query, append([]interface{}{alertsSince}, args...)...); err != nil {
+		return nil, err
+	}
+
+	return live, nil
+}
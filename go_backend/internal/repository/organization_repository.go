@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// OrganizationRepository handles the tenants of the deployment
+type OrganizationRepository struct {
+	db *DB
+}
+
+// NewOrganizationRepository creates a new OrganizationRepository instance
+func NewOrganizationRepository(db *DB) *OrganizationRepository {
+	return &OrganizationRepository{db: db}
+}
+
+// CreateWithAdmin creates an organization and its first admin account in one
+// transaction
+func (r *OrganizationRepository) CreateWithAdmin(ctx context.Context, organization *models.Organization, admin *models.User) error {
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		query := `
+			INSERT INTO organizations (name, slug)
+			VALUES ($1, $2)
+			RETURNING id, is_active, created_at, updated_at`
+		if err := tx.QueryRowxContext(ctx, query, organization.Name, organization.Slug).
+			Scan(&organization.ID, &organization.IsActive, &organization.CreatedAt, &organization.UpdatedAt); err != nil {
+			return err
+		}
+
+		admin.OrganizationID = organization.ID
+		query = `
+			INSERT INTO users (organization_id, email, password_hash, full_name, role)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id, reward_points, created_at, updated_at`
+		return tx.QueryRowxContext(ctx, query,
+			admin.OrganizationID,
+			admin.Email,
+			admin.PasswordHash,
+			admin.FullName,
+			admin.Role,
+		).Scan(&admin.ID, &admin.RewardPoints, &admin.CreatedAt, &admin.UpdatedAt)
+	})
+}
+
+// GetByID retrieves an organization by ID
+func (r *OrganizationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Organization, error) {
+	var organization models.Organization
+	query := `SELECT * FROM organizations WHERE id = $1`
+
+	err := r.db.GetContext(ctx, &organization, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &organization, err
+}
+
+// GetBySlug retrieves an organization by slug
+func (r *OrganizationRepository) GetBySlug(ctx context.Context, slug string) (*models.Organization, error) {
+	var organization models.Organization
+	query := `SELECT * FROM organizations WHERE slug = $1`
+
+	err := r.db.GetContext(ctx, &organization, query, slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &organization, err
+}
+
+// Update updates an organization
+func (r *OrganizationRepository) Update(ctx context.Context, organization *models.Organization) error {
+	query := `
+		UPDATE organizations SET name = $1, is_active = $2
+		WHERE id = $3
+		RETURNING updated_at`
+	return r.db.QueryRowxContext(ctx, query, organization.Name, organization.IsActive, organization.ID).
+		Scan(&organization.UpdatedAt)
+}
+
+// List retrieves every organization with pagination, by name
+func (r *OrganizationRepository) List(ctx context.Context, page Page) ([]models.Organization, PageResult, error) {
+	q := &listQuery{from: "organizations"}
+	return listPage(ctx, r.db, q, page, func(o models.Organization) Cursor {
+		return Cursor{Keys: []string{o.Name}, ID: o.ID}
+	}, false, "name")
+}
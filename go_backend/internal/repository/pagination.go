@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// Page selects one page of a list, either by offset or after a cursor
+type Page struct {
+	Limit  int
+	Offset int
+	// After continues the listing from the row a previous page ended on; Offset is ignored when set
+	After *Cursor
+}
+
+// PageResult describes the full list a page was taken from
+type PageResult struct {
+	Total      int
+	NextCursor string
+}
+
+// Cursor identifies a row by its sort keys and ID for keyset pagination
+type Cursor struct {
+	Keys []string  `json:"k"`
+	ID   uuid.UUID `json:"id"`
+}
+
+// Encode returns the opaque form of the cursor handed to API clients
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor produced by Cursor.Encode
+func DecodeCursor(value string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || len(cursor.Keys) == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// timeKey formats a timestamp sort key without losing precision
+func timeKey(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// listQuery builds the filtered COUNT and page queries of a list endpoint
+type listQuery struct {
+	from       string
+	conditions []string
+	args       []interface{}
+}
+
+// where adds a condition; clause holds a single %d for the placeholder number
+func (q *listQuery) where(clause string, value interface{}) {
+	q.args = append(q.args, value)
+	q.conditions = append(q.conditions, fmt.Sprintf(clause, len(q.args)))
+}
+
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+// count returns the number of rows matching the conditions
+func (q *listQuery) count(ctx context.Context, db dbtx) (int, error) {
+	var total int
+	query := "SELECT COUNT(*) FROM " + q.from + whereClause(q.conditions)
+	err := db.GetContext(ctx, &total, query, q.args...)
+	return total, err
+}
+
+// page selects one page into dest, ordered by columns and then id. A cursor
+// page must carry one key per column.
+func (q *listQuery) page(ctx context.Context, db dbtx, dest interface{}, page Page, desc bool, columns ...string) error {
+	conditions := append([]string(nil), q.conditions...)
+	args := append([]interface{}(nil), q.args...)
+
+	direction, comparison := "ASC", ">"
+	if desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	if page.After != nil {
+		if len(page.After.Keys) != len(columns) {
+			return ErrInvalidCursor
+		}
+		placeholders := make([]string, 0, len(columns)+1)
+		for _, key := range page.After.Keys {
+			args = append(args, key)
+			placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+		}
+		args = append(args, page.After.ID)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s (%s)",
+			strings.Join(columns, ", "), comparison, strings.Join(placeholders, ", ")))
+	}
+
+	order := make([]string, 0, len(columns)+1)
+	for _, column := range columns {
+		order = append(order, column+" "+direction)
+	}
+	order = append(order, "id "+direction)
+
+	args = append(args, page.Limit)
+	query := "SELECT * FROM " + q.from + whereClause(conditions) +
+		fmt.Sprintf(" ORDER BY %s LIMIT $%d", strings.Join(order, ", "), len(args))
+	if page.After == nil {
+		args = append(args, page.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+
+	return db.SelectContext(ctx, dest, query, args...)
+}
+
+// listPage runs the count and page queries of q, filling in the next cursor
+// when the page came back full
+func listPage[T any](ctx context.Context, db dbtx, q *listQuery, page Page, cursor func(T) Cursor, desc bool, columns ...string) ([]T, PageResult, error) {
+	var result PageResult
+	total, err := q.count(ctx, db)
+	if err != nil {
+		return nil, result, err
+	}
+	result.Total = total
+
+	var rows []T
+	if err := q.page(ctx, db, &rows, page, desc, columns...); err != nil {
+		return nil, result, err
+	}
+	if len(rows) > 0 && len(rows) == page.Limit {
+		result.NextCursor = cursor(rows[len(rows)-1]).Encode()
+	}
+	return rows, result, nil
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// PaymentRepository handles payments and the provider accounts they move
+// money from and to
+type PaymentRepository struct {
+	db *DB
+}
+
+// NewPaymentRepository creates a new PaymentRepository instance
+func NewPaymentRepository(db *DB) *PaymentRepository {
+	return &PaymentRepository{db: db}
+}
+
+// SetAccount creates or replaces the payment account of an owner
+func (r *PaymentRepository) SetAccount(ctx context.Context, account *models.PaymentAccount) error {
+	query := `
+		INSERT INTO payment_accounts (owner_type, owner_id, provider, account_id, payment_method)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (owner_type, owner_id) DO UPDATE
+		SET provider = EXCLUDED.provider, account_id = EXCLUDED.account_id, payment_method = EXCLUDED.payment_method
+		RETURNING created_at, updated_at`
+
+	return r.db.QueryRowxContext(ctx, query,
+		account.OwnerType,
+		account.OwnerID,
+		account.Provider,
+		account.AccountID,
+		account.PaymentMethod,
+	).Scan(&account.CreatedAt, &account.UpdatedAt)
+}
+
+// GetAccount retrieves the payment account of an owner
+func (r *PaymentRepository) GetAccount(ctx context.Context, ownerType models.PaymentOwnerType, ownerID uuid.UUID) (*models.PaymentAccount, error) {
+	var account models.PaymentAccount
+	query := `SELECT * FROM payment_accounts WHERE owner_type = $1 AND owner_id = $2`
+
+	err := r.db.GetContext(ctx, &account, query, ownerType, ownerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &account, err
+}
+
+// Create records a new pending payment in the organization of ctx
+func (r *PaymentRepository) Create(ctx context.Context, payment *models.Payment) error {
+	assignOrganization(ctx, &payment.OrganizationID)
+	query := `
+		INSERT INTO payments (organization_id, kind, company_id, driver_id, user_id, amount, currency, description, provider, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, status, created_at, updated_at`
+
+	return r.db.QueryRowxContext(ctx, query,
+		payment.OrganizationID,
+		payment.Kind,
+		payment.CompanyID,
+		payment.DriverID,
+		payment.UserID,
+		payment.Amount,
+		payment.Currency,
+		payment.Description,
+		payment.Provider,
+		payment.CreatedBy,
+	).Scan(&payment.ID, &payment.Status, &payment.CreatedAt, &payment.UpdatedAt)
+}
+
+// GetByID retrieves a payment by ID within the organization of ctx
+func (r *PaymentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Payment, error) {
+	var payment models.Payment
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `SELECT * FROM payments WHERE id = $1` + tenant
+
+	err := r.db.GetContext(ctx, &payment, query, append([]interface{}{id}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &payment, err
+}
+
+// ListFiltered retrieves the payments of the organization of ctx matching the
+// filter with pagination, newest first
+func (r *PaymentRepository) ListFiltered(ctx context.Context, filter models.PaymentFilter, page Page) ([]models.Payment, PageResult, error) {
+	q := &listQuery{from: "payments"}
+	q.tenant(ctx, "organization_id")
+	if filter.Kind != nil {
+		q.where("kind = $%d", *filter.Kind)
+	}
+	if filter.Status != nil {
+		q.where("status = $%d", *filter.Status)
+	}
+	if filter.CompanyID != nil {
+		q.where("company_id = $%d", *filter.CompanyID)
+	}
+	if filter.DriverID != nil {
+		q.where("driver_id = $%d", *filter.DriverID)
+	}
+	if filter.UserID != nil {
+		q.where("user_id = $%d", *filter.UserID)
+	}
+
+	return listPage(ctx, r.db, q, page, func(payment models.Payment) Cursor {
+		return Cursor{Keys: []string{timeKey(payment.CreatedAt)}, ID: payment.ID}
+	}, true, "created_at")
+}
+
+// SaveResult records what the provider answered to a pending payment
+func (r *PaymentRepository) SaveResult(ctx context.Context, payment *models.Payment) error {
+	query := `
+		UPDATE payments SET status = $1, reference = $2, failure_reason = $3
+		WHERE id = $4
+		RETURNING updated_at`
+
+	return r.db.QueryRowxContext(ctx, query,
+		payment.Status,
+		payment.Reference,
+		payment.FailureReason,
+		payment.ID,
+	).Scan(&payment.UpdatedAt)
+}
+
+// ApplyWebhook records the status a provider webhook reports for a payment of
+// any organization, keeping the reference it already has. A late processing
+// event does not reopen a settled payment. It returns nil if the payment is
+// unknown or was not changed.
+func (r *PaymentRepository) ApplyWebhook(ctx context.Context, id uuid.UUID, provider string, status models.PaymentStatus, reference, failureReason *string) (*models.Payment, error) {
+	var payment models.Payment
+	query := `
+		UPDATE payments
+		SET status = $1, reference = COALESCE(reference, $2), failure_reason = $3
+		WHERE id = $4 AND provider = $5 AND status <> $1
+			AND NOT ($1 = 'processing' AND status IN ('succeeded', 'failed'))
+		RETURNING *`
+
+	err := r.db.GetContext(ctx, &payment, query, status, reference, failureReason, id, provider)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &payment, err
+}
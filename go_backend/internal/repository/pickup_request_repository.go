@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// PickupRequestRepository handles the on-demand pickups requested by citizens
+type PickupRequestRepository struct {
+	db *DB
+}
+
+// NewPickupRequestRepository creates a new PickupRequestRepository instance
+func NewPickupRequestRepository(db *DB) *PickupRequestRepository {
+	return &PickupRequestRepository{db: db}
+}
+
+// Create creates a new pickup request in the organization of its user; its
+// zone is set from its location
+func (r *PickupRequestRepository) Create(ctx context.Context, pickup *models.PickupRequest) error {
+	query := `
+		INSERT INTO pickup_requests (organization_id, user_id, waste_type, estimated_weight_kg, photo_urls,
+			latitude, longitude, address, notes, estimated_value, currency)
+		VALUES ((SELECT organization_id FROM users WHERE id = $1), $1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, organization_id, zone_id, status, created_at, updated_at`
+
+	return r.db.QueryRowxContext(ctx, query,
+		pickup.UserID,
+		pickup.WasteType,
+		pickup.EstimatedWeightKg,
+		pickup.PhotoURLs,
+		pickup.Latitude,
+		pickup.Longitude,
+		pickup.Address,
+		pickup.Notes,
+		pickup.EstimatedValue,
+		pickup.Currency,
+	).Scan(&pickup.ID, &pickup.OrganizationID, &pickup.ZoneID, &pickup.Status, &pickup.CreatedAt, &pickup.UpdatedAt)
+}
+
+// GetByID retrieves a pickup request by ID within the organization of ctx
+func (r *PickupRequestRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.PickupRequest, error) {
+	var pickup models.PickupRequest
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `SELECT * FROM pickup_requests WHERE id = $1` + tenant
+
+	err := r.db.GetContext(ctx, &pickup, query, append([]interface{}{id}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &pickup, err
+}
+
+// ListFiltered retrieves the pickup requests of the organization of ctx
+// matching the filter with pagination, newest first
+func (r *PickupRequestRepository) ListFiltered(ctx context.Context, filter models.PickupRequestFilter, page Page) ([]models.PickupRequest, PageResult, error) {
+	q := &listQuery{from: "pickup_requests"}
+	q.tenant(ctx, "organization_id")
+	if filter.UserID != nil {
+		q.where("user_id = $%d", *filter.UserID)
+	}
+	if filter.DriverID != nil {
+		q.where("driver_id = $%d", *filter.DriverID)
+	}
+	if filter.Status != nil {
+		q.where("status = $%d", *filter.Status)
+	}
+
+	return listPage(ctx, r.db, q, page, func(pickup models.PickupRequest) Cursor {
+		return Cursor{Keys: []string{timeKey(pickup.CreatedAt)}, ID: pickup.ID}
+	}, true, "created_at")
+}
+
+// ListPending retrieves the pickup requests of the organization of ctx still
+// waiting for a driver, oldest first
+func (r *PickupRequestRepository) ListPending(ctx context.Context) ([]models.PickupRequest, error) {
+	var pickups []models.PickupRequest
+	tenant, args := tenantCondition(ctx, "organization_id", 1)
+	query := `SELECT * FROM pickup_requests WHERE status = 'pending'` + tenant + ` ORDER BY created_at`
+
+	err := r.db.SelectContext(ctx, &pickups, query, args...)
+	return pickups, err
+}
+
+// UpdateStatus moves a pickup request from the status it was loaded with to
+// pickup.Status, saving its driver, weight and price and stamping the change.
+// It returns ErrNotFound when the request changed since.
+func (r *PickupRequestRepository) UpdateStatus(ctx context.Context, pickup *models.PickupRequest, from models.PickupStatus) error {
+	query := `
+		UPDATE pickup_requests
+		SET status = $1, driver_id = $2, weight_kg = $3, price_offered = $4, currency = COALESCE($5, currency),
+			notes = $6,
+			assigned_at = CASE WHEN $1 = 'assigned' THEN CURRENT_TIMESTAMP ELSE assigned_at END,
+			collected_at = CASE WHEN $1 = 'collected' THEN CURRENT_TIMESTAMP ELSE collected_at END,
+			cancelled_at = CASE WHEN $1 = 'cancelled' THEN CURRENT_TIMESTAMP ELSE cancelled_at END
+		WHERE id = $7 AND status = $8
+		RETURNING assigned_at, collected_at, cancelled_at, updated_at`
+
+	err := r.db.QueryRowxContext(ctx, query,
+		pickup.Status,
+		pickup.DriverID,
+		pickup.WeightKg,
+		pickup.PriceOffered,
+		pickup.Currency,
+		pickup.Notes,
+		pickup.ID,
+		from,
+	).Scan(&pickup.AssignedAt, &pickup.CollectedAt, &pickup.CancelledAt, &pickup.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// SaveShipment records the outcome of shipping a collected pickup request
+func (r *PickupRequestRepository) SaveShipment(ctx context.Context, pickup *models.PickupRequest) error {
+	query := `
+		UPDATE pickup_requests SET shipment_id = $1, shipment_attempts = $2, shipment_error = $3
+		WHERE id = $4
+		RETURNING updated_at`
+
+	return r.db.QueryRowxContext(ctx, query,
+		pickup.ShipmentID,
+		pickup.ShipmentAttempts,
+		pickup.ShipmentError,
+		pickup.ID,
+	).Scan(&pickup.UpdatedAt)
+}
+
+// ListUnshipped retrieves up to limit collected pickup requests of every
+// organization worth shipping that have no shipment yet and fewer than
+// maxAttempts failed attempts, oldest collected first
+func (r *PickupRequestRepository) ListUnshipped(ctx context.Context, maxAttempts, limit int) ([]models.PickupRequest, error) {
+	var pickups []models.PickupRequest
+	query := `
+		SELECT * FROM pickup_requests
+		WHERE status = 'collected' AND shipment_id IS NULL AND price_offered > 0 AND shipment_attempts < $1
+		ORDER BY collected_at
+		LIMIT $2`
+
+	err := r.db.SelectContext(ctx, &pickups, query, maxAttempts, limit)
+	return pickups, err
+}
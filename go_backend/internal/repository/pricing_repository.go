@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -12,22 +13,49 @@ import (
 
 // PricingRepository handles pricing rule data operations
 type PricingRepository struct {
-	db *sqlx.DB
+	db *DB
 }
 
 // NewPricingRepository creates a new PricingRepository instance
-func NewPricingRepository(db *sqlx.DB) *PricingRepository {
+func NewPricingRepository(db *DB) *PricingRepository {
 	return &PricingRepository{db: db}
 }
 
-// Create creates a new pricing rule
+// ruleInEffect filters pricing_rules pr to the versions in effect at the
+// time bound to the given placeholder
+func ruleInEffect(placeholder string) string {
+	return "pr.is_active = true AND pr.effective_from <= " + placeholder +
+		" AND (pr.effective_to IS NULL OR pr.effective_to > " + placeholder + ")"
+}
+
+// Create creates the first version of a new pricing rule
 func (r *PricingRepository) Create(ctx context.Context, rule *models.PricingRule) error {
+	return r.insert(ctx, r.db, rule)
+}
+
+// insert inserts a pricing rule version; the first version of a rule starts
+// its own lineage
+func (r *PricingRepository) insert(ctx context.Context, db dbtx, rule *models.PricingRule) error {
+	if rule.ID == uuid.Nil {
+		rule.ID = uuid.New()
+	}
+	if rule.LineageID == uuid.Nil {
+		rule.LineageID = rule.ID
+	}
+	if rule.Version == 0 {
+		rule.Version = 1
+	}
+
 	query := `
-		INSERT INTO pricing_rules (waste_type, condition, price_per_kg, currency, min_weight_kg, max_weight_kg, company_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, is_active, created_at, updated_at`
+		INSERT INTO pricing_rules (id, lineage_id, version, waste_type, condition, price_per_kg, currency, min_weight_kg,
+			max_weight_kg, company_id, pricing_model, tiers, condition_multipliers, market_factor, effective_from, effective_to)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		RETURNING is_active, created_at, updated_at`
 
-	return r.db.QueryRowxContext(ctx, query,
+	return db.QueryRowxContext(ctx, query,
+		rule.ID,
+		rule.LineageID,
+		rule.Version,
 		rule.WasteType,
 		rule.Condition,
 		rule.PricePerKg,
@@ -35,72 +63,388 @@ func (r *PricingRepository) Create(ctx context.Context, rule *models.PricingRule
 		rule.MinWeightKg,
 		rule.MaxWeightKg,
 		rule.CompanyID,
-	).Scan(&rule.ID, &rule.IsActive, &rule.CreatedAt, &rule.UpdatedAt)
+		rule.PricingModel,
+		rule.Tiers,
+		rule.ConditionMultipliers,
+		rule.MarketFactor,
+		rule.EffectiveFrom,
+		rule.EffectiveTo,
+	).Scan(&rule.IsActive, &rule.CreatedAt, &rule.UpdatedAt)
+}
+
+// CreateVersion supersedes the current version of a rule with next, which
+// takes effect at next.EffectiveFrom. The current version is closed at that
+// time unless it already ends earlier.
+func (r *PricingRepository) CreateVersion(ctx context.Context, current, next *models.PricingRule) error {
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		return r.createVersion(ctx, tx, current, next)
+	})
 }
 
-// GetByID retrieves a pricing rule by ID
+// createVersion closes current and inserts next as its successor
+func (r *PricingRepository) createVersion(ctx context.Context, db dbtx, current, next *models.PricingRule) error {
+	query := `
+		UPDATE pricing_rules
+		SET effective_to = LEAST(COALESCE(effective_to, $1), $1)
+		WHERE id = $2
+		RETURNING effective_to, updated_at`
+	if err := db.QueryRowxContext(ctx, query, next.EffectiveFrom, current.ID).
+		Scan(&current.EffectiveTo, &current.UpdatedAt); err != nil {
+		return err
+	}
+
+	next.ID = uuid.Nil
+	next.LineageID = current.LineageID
+	next.Version = current.Version + 1
+	return r.insert(ctx, db, next)
+}
+
+// ApplyChanges creates and revises pricing rules in one transaction, so
+// either every change is applied or none is
+func (r *PricingRepository) ApplyChanges(ctx context.Context, changes []models.PricingRuleChange) error {
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		for _, change := range changes {
+			var err error
+			switch {
+			case change.Current == nil:
+				err = r.insert(ctx, tx, change.Next)
+			case change.InPlace:
+				err = r.update(ctx, tx, change.Next)
+			default:
+				err = r.createVersion(ctx, tx, change.Current, change.Next)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetByID retrieves a pricing rule version by ID; company principals only
+// see their own and global rules
 func (r *PricingRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.PricingRule, error) {
 	var rule models.PricingRule
-	query := `SELECT * FROM pricing_rules WHERE id = $1`
+	scope, args := scopeCondition(ctx, "company_id", 2, true)
+	query := `SELECT * FROM pricing_rules WHERE id = $1` + scope
+
+	err := r.db.GetContext(ctx, &rule, query, append([]interface{}{id}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &rule, err
+}
+
+// LatestVersion retrieves the most recent version of a rule
+func (r *PricingRepository) LatestVersion(ctx context.Context, lineageID uuid.UUID) (*models.PricingRule, error) {
+	var rule models.PricingRule
+	scope, args := scopeCondition(ctx, "company_id", 2, true)
+	query := `SELECT * FROM pricing_rules WHERE lineage_id = $1` + scope + ` ORDER BY version DESC LIMIT 1`
+
+	err := r.db.GetContext(ctx, &rule, query, append([]interface{}{lineageID}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &rule, err
+}
+
+// ListVersions retrieves every version of a rule, the first first
+func (r *PricingRepository) ListVersions(ctx context.Context, lineageID uuid.UUID) ([]models.PricingRule, error) {
+	var rules []models.PricingRule
+	scope, args := scopeCondition(ctx, "company_id", 2, true)
+	query := `SELECT * FROM pricing_rules WHERE lineage_id = $1` + scope + ` ORDER BY version`
+	err := r.db.SelectContext(ctx, &rules, query, append([]interface{}{lineageID}, args...)...)
+	return rules, err
+}
+
+// GetByTypeAndCondition retrieves the pricing rule of a waste type and
+// condition in effect at the given time, preferring the company's rules over
+// global ones and rules of the condition over rules matching any condition
+func (r *PricingRepository) GetByTypeAndCondition(ctx context.Context, wasteType, condition string, companyID *uuid.UUID, at time.Time) (*models.PricingRule, error) {
+	var rule models.PricingRule
+	query := `
+		SELECT * FROM pricing_rules pr
+		WHERE pr.waste_type = $1 AND pr.condition IN ($2, $3) AND ` + ruleInEffect("$5") + `
+			AND (pr.company_id IS NULL OR pr.company_id = $4)
+		ORDER BY pr.company_id IS NULL, pr.condition = $3, pr.created_at DESC
+		LIMIT 1`
 
-	err := r.db.GetContext(ctx, &rule, query, id)
+	err := r.db.GetContext(ctx, &rule, query, wasteType, condition, models.ConditionAny, companyID, at)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
 	}
 	return &rule, err
 }
 
-// GetByTypeAndCondition retrieves pricing rules by waste type and condition
-func (r *PricingRepository) GetByTypeAndCondition(ctx context.Context, wasteType, condition string) (*models.PricingRule, error) {
+// GetCompanyRule retrieves the pricing rule of a waste type and condition in
+// effect at the given time among the rules of one company only
+func (r *PricingRepository) GetCompanyRule(ctx context.Context, companyID uuid.UUID, wasteType, condition string, at time.Time) (*models.PricingRule, error) {
 	var rule models.PricingRule
-	query := `SELECT * FROM pricing_rules WHERE waste_type = $1 AND condition = $2 AND is_active = true ORDER BY created_at DESC LIMIT 1`
+	query := `
+		SELECT * FROM pricing_rules pr
+		WHERE pr.waste_type = $1 AND pr.condition IN ($2, $3) AND ` + ruleInEffect("$5") + ` AND pr.company_id = $4
+		ORDER BY pr.condition = $3, pr.created_at DESC
+		LIMIT 1`
 
-	err := r.db.GetContext(ctx, &rule, query, wasteType, condition)
+	err := r.db.GetContext(ctx, &rule, query, wasteType, condition, models.ConditionAny, companyID, at)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
 	}
 	return &rule, err
 }
 
-// Update updates a pricing rule
+// ListCompanyRules retrieves the pricing rule of a waste type and condition
+// in effect at the given time of every active company that has one
+func (r *PricingRepository) ListCompanyRules(ctx context.Context, wasteType, condition string, at time.Time) ([]models.CompanyPricingRule, error) {
+	var rules []models.CompanyPricingRule
+	query := `
+		SELECT DISTINCT ON (pr.company_id) pr.*, c.name AS company_name
+		FROM pricing_rules pr
+		JOIN companies c ON c.id = pr.company_id
+		WHERE pr.waste_type = $1 AND pr.condition IN ($2, $3) AND ` + ruleInEffect("$4") + `
+			AND c.is_active = true
+		ORDER BY pr.company_id, pr.condition = $3, pr.created_at DESC`
+	err := r.db.SelectContext(ctx, &rules, query, wasteType, condition, models.ConditionAny, at)
+	return rules, err
+}
+
+// Update updates a pricing rule version in place; only versions that have
+// not taken effect yet are changed this way
 func (r *PricingRepository) Update(ctx context.Context, rule *models.PricingRule) error {
+	return r.update(ctx, r.db, rule)
+}
+
+// update writes a pricing rule version
+func (r *PricingRepository) update(ctx context.Context, db dbtx, rule *models.PricingRule) error {
 	query := `
 		UPDATE pricing_rules
-		SET waste_type = $1, condition = $2, price_per_kg = $3, currency = $4, min_weight_kg = $5, max_weight_kg = $6, is_active = $7
-		WHERE id = $8
+		SET waste_type = $1, condition = $2, price_per_kg = $3, currency = $4, min_weight_kg = $5, max_weight_kg = $6,
+			pricing_model = $7, tiers = $8, condition_multipliers = $9, market_factor = $10, is_active = $11,
+			effective_from = $12, effective_to = $13
+		WHERE id = $14
 		RETURNING updated_at`
 
-	return r.db.QueryRowxContext(ctx, query,
+	return db.QueryRowxContext(ctx, query,
 		rule.WasteType,
 		rule.Condition,
 		rule.PricePerKg,
 		rule.Currency,
 		rule.MinWeightKg,
 		rule.MaxWeightKg,
+		rule.PricingModel,
+		rule.Tiers,
+		rule.ConditionMultipliers,
+		rule.MarketFactor,
 		rule.IsActive,
+		rule.EffectiveFrom,
+		rule.EffectiveTo,
 		rule.ID,
 	).Scan(&rule.UpdatedAt)
 }
 
-// List retrieves all pricing rules with pagination
-func (r *PricingRepository) List(ctx context.Context, limit, offset int) ([]models.PricingRule, error) {
-	var rules []models.PricingRule
-	query := `SELECT * FROM pricing_rules WHERE is_active = true ORDER BY waste_type, condition LIMIT $1 OFFSET $2`
-	err := r.db.SelectContext(ctx, &rules, query, limit, offset)
-	return rules, err
+// List retrieves the active pricing rule versions that are in effect or
+// scheduled with pagination, by waste type and condition; company principals
+// only list their own and global rules
+func (r *PricingRepository) List(ctx context.Context, page Page) ([]models.PricingRule, PageResult, error) {
+	q := &listQuery{from: "pricing_rules", conditions: []string{
+		"is_active = true", "(effective_to IS NULL OR effective_to > NOW())",
+	}}
+	q.scope(ctx, "company_id", true)
+	return listPage(ctx, r.db, q, page, func(p models.PricingRule) Cursor {
+		return Cursor{Keys: []string{p.WasteType, p.Condition}, ID: p.ID}
+	}, false, "waste_type", "condition")
 }
 
-// ListByCompany retrieves pricing rules for a specific company
+// ListByCompany retrieves the pricing rule versions of a specific company
+// that are in effect or scheduled
 func (r *PricingRepository) ListByCompany(ctx context.Context, companyID uuid.UUID) ([]models.PricingRule, error) {
 	var rules []models.PricingRule
-	query := `SELECT * FROM pricing_rules WHERE company_id = $1 AND is_active = true ORDER BY waste_type, condition`
+	query := `
+		SELECT * FROM pricing_rules
+		WHERE company_id = $1 AND is_active = true AND (effective_to IS NULL OR effective_to > NOW())
+		ORDER BY waste_type, condition, effective_from`
 	err := r.db.SelectContext(ctx, &rules, query, companyID)
 	return rules, err
 }
 
-// Delete deletes a pricing rule (soft delete)
+// Delete ends a pricing rule now: the version in effect is closed, so past
+// valuations can still be reproduced, and scheduled versions are withdrawn.
+// Company principals only end their own rules.
 func (r *PricingRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `UPDATE pricing_rules SET is_active = false WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+	scope, scopeArgs := scopeCondition(ctx, "company_id", 2, false)
+	args := append([]interface{}{id}, scopeArgs...)
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		lineage := `(SELECT lineage_id FROM pricing_rules WHERE id = $1` + scope + `)`
+		query := `
+			UPDATE pricing_rules SET effective_to = NOW()
+			WHERE lineage_id = ` + lineage + ` AND is_active = true
+				AND effective_from < NOW() AND (effective_to IS NULL OR effective_to > NOW())`
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+
+		query = `
+			UPDATE pricing_rules SET is_active = false
+			WHERE lineage_id = ` + lineage + ` AND effective_from >= NOW()`
+		_, err := tx.ExecContext(ctx, query, args...)
+		return err
+	})
+}
+
+// ValuationTotals sums the valuations of waste detected in a company's
+// collections within the organization of ctx during the range per waste type
+// and currency, highest first
+func (r *PricingRepository) ValuationTotals(ctx context.Context, companyID uuid.UUID, period models.AnalyticsRange) ([]models.ValuationTotal, error) {
+	tenant, args := tenantCondition(ctx, "c.organization_id", 4)
+	query := `
+		SELECT wm.waste_type, COALESCE(pr.currency, 'USD') AS currency,
+			COUNT(*) AS valuations, SUM(wm.valuated_price) AS total_value
+		FROM waste_metadata wm
+		JOIN collections c ON c.id = wm.collection_id
+		JOIN bins b ON b.id = c.bin_id
+		LEFT JOIN pricing_rules pr ON pr.id = wm.pricing_rule_id
+		WHERE b.company_id = $1 AND wm.valuated_price IS NOT NULL
+			AND wm.detected_at >= $2 AND wm.detected_at < $3` + tenant + `
+		GROUP BY 1, 2
+		ORDER BY 4 DESC, 1, 2`
+
+	var totals []models.ValuationTotal
+	err := r.db.SelectContext(ctx, &totals, query, append([]interface{}{companyID, period.From, period.To}, args...)...)
+	return totals, err
+}
+
+// CreatePromotion creates a new pricing promotion
+func (r *PricingRepository) CreatePromotion(ctx context.Context, promotion *models.PricingPromotion) error {
+	query := `
+		INSERT INTO pricing_promotions (name, company_id, waste_type, multiplier, starts_at, ends_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, is_active, created_at, updated_at`
+
+	return r.db.QueryRowxContext(ctx, query,
+		promotion.Name,
+		promotion.CompanyID,
+		promotion.WasteType,
+		promotion.Multiplier,
+		promotion.StartsAt,
+		promotion.EndsAt,
+	).Scan(&promotion.ID, &promotion.IsActive, &promotion.CreatedAt, &promotion.UpdatedAt)
+}
+
+// GetPromotion retrieves a pricing promotion by ID; company principals only
+// see their own and global promotions
+func (r *PricingRepository) GetPromotion(ctx context.Context, id uuid.UUID) (*models.PricingPromotion, error) {
+	var promotion models.PricingPromotion
+	scope, args := scopeCondition(ctx, "company_id", 2, true)
+	query := `SELECT * FROM pricing_promotions WHERE id = $1` + scope
+
+	err := r.db.GetContext(ctx, &promotion, query, append([]interface{}{id}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &promotion, err
+}
+
+// UpdatePromotion updates a pricing promotion
+func (r *PricingRepository) UpdatePromotion(ctx context.Context, promotion *models.PricingPromotion) error {
+	query := `
+		UPDATE pricing_promotions
+		SET name = $1, multiplier = $2, starts_at = $3, ends_at = $4, is_active = $5
+		WHERE id = $6
+		RETURNING updated_at`
+
+	return r.db.QueryRowxContext(ctx, query,
+		promotion.Name,
+		promotion.Multiplier,
+		promotion.StartsAt,
+		promotion.EndsAt,
+		promotion.IsActive,
+		promotion.ID,
+	).Scan(&promotion.UpdatedAt)
+}
+
+// ListPromotions retrieves the active promotions that have not ended by now,
+// optionally of one company only, the soonest first; company principals only
+// list their own and global promotions
+func (r *PricingRepository) ListPromotions(ctx context.Context, now time.Time, companyID *uuid.UUID) ([]models.PricingPromotion, error) {
+	var promotions []models.PricingPromotion
+	scope, args := scopeCondition(ctx, "company_id", 3, true)
+	query := `
+		SELECT * FROM pricing_promotions
+		WHERE is_active = true AND ends_at > $1
+			AND ($2::uuid IS NULL OR company_id = $2)` + scope + `
+		ORDER BY starts_at, name`
+	err := r.db.SelectContext(ctx, &promotions, query, append([]interface{}{now, companyID}, args...)...)
+	return promotions, err
+}
+
+// DeletePromotion ends a pricing promotion (soft delete); company principals
+// only end their own promotions
+func (r *PricingRepository) DeletePromotion(ctx context.Context, id uuid.UUID) error {
+	scope, args := scopeCondition(ctx, "company_id", 2, false)
+	query := `UPDATE pricing_promotions SET is_active = false WHERE id = $1` + scope
+	return affected(r.db.ExecContext(ctx, query, append([]interface{}{id}, args...)...))
+}
+
+// ActivePromotion retrieves the highest promotion running at the given time
+// that applies to the waste type and company
+func (r *PricingRepository) ActivePromotion(ctx context.Context, wasteType string, companyID *uuid.UUID, at time.Time) (*models.PricingPromotion, error) {
+	var promotion models.PricingPromotion
+	query := `
+		SELECT * FROM pricing_promotions
+		WHERE is_active = true AND starts_at <= $1 AND ends_at > $1
+			AND (waste_type IS NULL OR waste_type = $2)
+			AND (company_id IS NULL OR company_id = $3)
+		ORDER BY multiplier DESC, starts_at DESC
+		LIMIT 1`
+
+	err := r.db.GetContext(ctx, &promotion, query, at, wasteType, companyID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &promotion, err
+}
+
+// CreateMarketPrice records a market price
+func (r *PricingRepository) CreateMarketPrice(ctx context.Context, price *models.MarketPrice) error {
+	query := `
+		INSERT INTO market_prices (waste_type, currency, price_per_kg, source, effective_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+
+	return r.db.QueryRowxContext(ctx, query,
+		price.WasteType,
+		price.Currency,
+		price.PricePerKg,
+		price.Source,
+		price.EffectiveAt,
+	).Scan(&price.ID, &price.CreatedAt)
+}
+
+// LatestMarketPrice retrieves the market price of a waste type in effect at the given time
+func (r *PricingRepository) LatestMarketPrice(ctx context.Context, wasteType, currency string, at time.Time) (*models.MarketPrice, error) {
+	var price models.MarketPrice
+	query := `
+		SELECT * FROM market_prices
+		WHERE waste_type = $1 AND currency = $2 AND effective_at <= $3
+		ORDER BY effective_at DESC
+		LIMIT 1`
+
+	err := r.db.GetContext(ctx, &price, query, wasteType, currency, at)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &price, err
+}
+
+// ListMarketPrices retrieves the market price in effect at the given time of
+// every waste type and currency
+func (r *PricingRepository) ListMarketPrices(ctx context.Context, at time.Time) ([]models.MarketPrice, error) {
+	var prices []models.MarketPrice
+	query := `
+		SELECT DISTINCT ON (waste_type, currency) *
+		FROM market_prices
+		WHERE effective_at <= $1
+		ORDER BY waste_type, currency, effective_at DESC`
+	err := r.db.SelectContext(ctx, &prices, query, at)
+	return prices, err
 }
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProcessedEventRepository records the shipment events the backend handled
+type ProcessedEventRepository struct {
+	db *DB
+}
+
+// NewProcessedEventRepository creates a new ProcessedEventRepository instance
+func NewProcessedEventRepository(db *DB) *ProcessedEventRepository {
+	return &ProcessedEventRepository{db: db}
+}
+
+// Claim claims an event for handling. It returns false if the event was
+// already processed, or is being handled under a claim younger than lease.
+func (r *ProcessedEventRepository) Claim(ctx context.Context, eventID uuid.UUID, subject string, lease time.Duration) (bool, error) {
+	now := time.Now()
+	query := `
+		INSERT INTO processed_events (event_id, subject, claimed_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (event_id) DO UPDATE SET claimed_at = EXCLUDED.claimed_at
+		WHERE processed_events.processed_at IS NULL AND processed_events.claimed_at < $4
+		RETURNING event_id`
+
+	var claimed uuid.UUID
+	err := r.db.QueryRowxContext(ctx, query, eventID, subject, now, now.Add(-lease)).Scan(&claimed)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// MarkProcessed records that a claimed event was handled
+func (r *ProcessedEventRepository) MarkProcessed(ctx context.Context, eventID uuid.UUID) error {
+	query := `UPDATE processed_events SET processed_at = $1 WHERE event_id = $2`
+	_, err := r.db.ExecContext(ctx, query, time.Now(), eventID)
+	return err
+}
+
+// Release drops the claim of an event whose handling failed, so it is handled
+// again when redelivered
+func (r *ProcessedEventRepository) Release(ctx context.Context, eventID uuid.UUID) error {
+	query := `DELETE FROM processed_events WHERE event_id = $1 AND processed_at IS NULL`
+	_, err := r.db.ExecContext(ctx, query, eventID)
+	return err
+}
+
+// DeleteProcessedBefore forgets the events processed before a time, returning
+// how many were deleted
+func (r *ProcessedEventRepository) DeleteProcessedBefore(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM processed_events WHERE processed_at < $1`
+	result, err := r.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
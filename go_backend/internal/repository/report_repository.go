@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// ReportRepository reads the rows of exported reports and tracks background exports
+type ReportRepository struct {
+	db *DB
+}
+
+// NewReportRepository creates a new ReportRepository instance
+func NewReportRepository(db *DB) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+// EachCollection streams the collections of the organization of ctx started
+// in the range, oldest first
+func (r *ReportRepository) EachCollection(ctx context.Context, period models.AnalyticsRange, fn func(row *models.CollectionReportRow) error) error {
+	tenant, args := tenantCondition(ctx, "c.organization_id", 3)
+	query := `
+		SELECT c.id, c.started_at, c.completed_at, c.status, b.device_id, b.location_name,
+			COALESCE(b.waste_type, 'general') AS waste_type, co.name AS company_name,
+			d.full_name AS driver_name, c.fill_level_before, c.weight_kg
+		FROM collections c
+		JOIN bins b ON b.id = c.bin_id
+		JOIN drivers d ON d.id = c.driver_id
+		LEFT JOIN companies co ON co.id = b.company_id
+		WHERE c.started_at >= $1 AND c.started_at < $2` + tenant + `
+		ORDER BY c.started_at, c.id`
+
+	return eachRow(ctx, r.db, fn, query, append([]interface{}{period.From, period.To}, args...)...)
+}
+
+// EachBin streams every active bin of the organization of ctx with its
+// readings and completed collections in the range, ordered by device ID
+func (r *ReportRepository) EachBin(ctx context.Context, period models.AnalyticsRange, fn func(row *models.BinReportRow) error) error {
+	tenant, args := tenantCondition(ctx, "b.organization_id", 3)
+	query := `
+		WITH readings AS (
+			SELECT bin_id, COUNT(*) AS readings,
+				AVG(fill_level)::float8 AS average_fill_level, MAX(fill_level) AS max_fill_level
+			FROM bin_readings
+			WHERE recorded_at >= $1 AND recorded_at < $2
+			GROUP BY bin_id
+		),
+		collected AS (
+			SELECT bin_id, COUNT(*) AS collections, COALESCE(SUM(weight_kg), 0) AS weight_kg
+			FROM collections
+			WHERE status = 'completed' AND started_at >= $1 AND started_at < $2
+			GROUP BY bin_id
+		)
+		SELECT b.id, b.device_id, b.location_name, COALESCE(b.waste_type, 'general') AS waste_type,
+			co.name AS company_name, COALESCE(b.fill_level, 0) AS fill_level,
+			COALESCE(rd.readings, 0) AS readings, rd.average_fill_level, rd.max_fill_level,
+			COALESCE(cl.collections, 0) AS collections, COALESCE(cl.weight_kg, 0) AS collected_weight_kg
+		FROM bins b
+		LEFT JOIN companies co ON co.id = b.company_id
+		LEFT JOIN readings rd ON rd.bin_id = b.id
+		LEFT JOIN collected cl ON cl.bin_id = b.id
+		WHERE b.is_active = true` + tenant + `
+		ORDER BY b.device_id`
+
+	return eachRow(ctx, r.db, fn, query, append([]interface{}{period.From, period.To}, args...)...)
+}
+
+// EachDriver streams every driver of the organization of ctx with the
+// collections they started in the range, ordered by name
+func (r *ReportRepository) EachDriver(ctx context.Context, period models.AnalyticsRange, fn func(row *models.DriverReportRow) error) error {
+	tenant, args := tenantCondition(ctx, "d.organization_id", 3)
+	query := `
+		SELECT d.id, d.full_name, d.email, COALESCE(v.plate_number, d.vehicle_plate) AS vehicle_plate,
+			COUNT(c.id) AS collections,
+			COUNT(c.id) FILTER (WHERE c.status = 'completed') AS completed,
+			COUNT(c.id) FILTER (WHERE c.status = 'cancelled') AS cancelled,
+			COALESCE(SUM(c.weight_kg) FILTER (WHERE c.status = 'completed'), 0) AS weight_kg,
+			(AVG(EXTRACT(EPOCH FROM c.completed_at - c.started_at))
+				FILTER (WHERE c.status = 'completed' AND c.completed_at IS NOT NULL) / 60)::float8 AS average_duration_min
+		FROM drivers d
+		LEFT JOIN vehicles v ON v.id = d.vehicle_id
+		LEFT JOIN collections c ON c.driver_id = d.id AND c.started_at >= $1 AND c.started_at < $2
+		WHERE true` + tenant + `
+		GROUP BY d.id, v.plate_number
+		ORDER BY d.full_name, d.id`
+
+	return eachRow(ctx, r.db, fn, query, append([]interface{}{period.From, period.To}, args...)...)
+}
+
+// CreateExport records a pending background export of the organization of ctx
+func (r *ReportRepository) CreateExport(ctx context.Context, export *models.ReportExport) error {
+	assignOrganization(ctx, &export.OrganizationID)
+	query := `
+		INSERT INTO report_exports (organization_id, report, format, period_from, period_to, requested_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, status, created_at`
+
+	return r.db.QueryRowxContext(ctx, query,
+		export.OrganizationID,
+		export.Report,
+		export.Format,
+		export.PeriodFrom,
+		export.PeriodTo,
+		export.RequestedBy,
+	).Scan(&export.ID, &export.Status, &export.CreatedAt)
+}
+
+// GetExport retrieves a background export by ID within the organization of ctx
+func (r *ReportRepository) GetExport(ctx context.Context, id uuid.UUID) (*models.ReportExport, error) {
+	var export models.ReportExport
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `SELECT * FROM report_exports WHERE id = $1` + tenant
+
+	err := r.db.GetContext(ctx, &export, query, append([]interface{}{id}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &export, err
+}
+
+// StartExport moves a pending export to running. It returns false if the
+// export is no longer pending.
+func (r *ReportRepository) StartExport(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `UPDATE report_exports SET status = 'running' WHERE id = $1 AND status = 'pending'`
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// CompleteExport records the file of a finished export
+func (r *ReportRepository) CompleteExport(ctx context.Context, export *models.ReportExport) error {
+	query := `
+		UPDATE report_exports
+		SET status = 'completed', row_count = $1, size_bytes = $2, file_path = $3,
+			completed_at = CURRENT_TIMESTAMP, expires_at = $4
+		WHERE id = $5
+		RETURNING status, completed_at`
+
+	return r.db.QueryRowxContext(ctx, query,
+		export.Rows,
+		export.SizeBytes,
+		export.FilePath,
+		export.ExpiresAt,
+		export.ID,
+	).Scan(&export.Status, &export.CompletedAt)
+}
+
+// FailExport records why an export failed
+func (r *ReportRepository) FailExport(ctx context.Context, id uuid.UUID, reason string, expiresAt time.Time) error {
+	query := `
+		UPDATE report_exports
+		SET status = 'failed', error = $1, completed_at = CURRENT_TIMESTAMP, expires_at = $2
+		WHERE id = $3`
+	_, err := r.db.ExecContext(ctx, query, reason, expiresAt, id)
+	return err
+}
+
+// FailUnfinishedExports fails the exports that were pending or running when
+// the server stopped
+func (r *ReportRepository) FailUnfinishedExports(ctx context.Context, reason string, expiresAt time.Time) (int64, error) {
+	query := `
+		UPDATE report_exports
+		SET status = 'failed', error = $1, completed_at = CURRENT_TIMESTAMP, expires_at = $2
+		WHERE status IN ('pending', 'running')`
+	result, err := r.db.ExecContext(ctx, query, reason, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// DeleteExpiredExports deletes the exports that expired before the cutoff and
+// returns the paths of their files
+func (r *ReportRepository) DeleteExpiredExports(ctx context.Context, cutoff time.Time) ([]string, error) {
+	var paths []sql.NullString
+	query := `DELETE FROM report_exports WHERE expires_at < $1 RETURNING file_path`
+	if err := r.db.SelectContext(ctx, &paths, query, cutoff); err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if path.Valid {
+			files = append(files, path.String)
+		}
+	}
+	return files, nil
+}
+
+// eachRow streams the rows of a query to fn, stopping at the first error fn returns
+func eachRow[T any](ctx context.Context, db dbtx, fn func(row *T) error, query string, args ...interface{}) error {
+	rows, err := db.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row T
+		if err := rows.StructScan(&row); err != nil {
+			return err
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
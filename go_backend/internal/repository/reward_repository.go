@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// RewardRepository handles reward rules and the reward ledger
+type RewardRepository struct {
+	db *DB
+}
+
+// NewRewardRepository creates a new RewardRepository instance
+func NewRewardRepository(db *DB) *RewardRepository {
+	return &RewardRepository{db: db}
+}
+
+// CreateRule creates a new reward rule
+func (r *RewardRepository) CreateRule(ctx context.Context, rule *models.RewardRule) error {
+	query := `
+		INSERT INTO reward_rules (waste_type, base_points, points_per_kg, min_weight_kg, max_points)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, is_active, created_at, updated_at`
+
+	return r.db.QueryRowxContext(ctx, query,
+		rule.WasteType,
+		rule.BasePoints,
+		rule.PointsPerKg,
+		rule.MinWeightKg,
+		rule.MaxPoints,
+	).Scan(&rule.ID, &rule.IsActive, &rule.CreatedAt, &rule.UpdatedAt)
+}
+
+// GetRuleByID retrieves a reward rule by ID
+func (r *RewardRepository) GetRuleByID(ctx context.Context, id uuid.UUID) (*models.RewardRule, error) {
+	var rule models.RewardRule
+	query := `SELECT * FROM reward_rules WHERE id = $1`
+
+	err := r.db.GetContext(ctx, &rule, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &rule, err
+}
+
+// GetRuleForWasteType retrieves the active rule for a waste type, falling back
+// to the '*' rule
+func (r *RewardRepository) GetRuleForWasteType(ctx context.Context, wasteType string) (*models.RewardRule, error) {
+	var rule models.RewardRule
+	query := `
+		SELECT * FROM reward_rules
+		WHERE is_active = true AND waste_type IN ($1, $2)
+		ORDER BY waste_type = $2
+		LIMIT 1`
+
+	err := r.db.GetContext(ctx, &rule, query, wasteType, models.RewardRuleAnyWasteType)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &rule, err
+}
+
+// UpdateRule updates a reward rule
+func (r *RewardRepository) UpdateRule(ctx context.Context, rule *models.RewardRule) error {
+	query := `
+		UPDATE reward_rules
+		SET base_points = $1, points_per_kg = $2, min_weight_kg = $3, max_points = $4, is_active = $5
+		WHERE id = $6
+		RETURNING updated_at`
+
+	return r.db.QueryRowxContext(ctx, query,
+		rule.BasePoints,
+		rule.PointsPerKg,
+		rule.MinWeightKg,
+		rule.MaxPoints,
+		rule.IsActive,
+		rule.ID,
+	).Scan(&rule.UpdatedAt)
+}
+
+// ListRules retrieves all active reward rules
+func (r *RewardRepository) ListRules(ctx context.Context) ([]models.RewardRule, error) {
+	var rules []models.RewardRule
+	query := `SELECT * FROM reward_rules WHERE is_active = true ORDER BY waste_type`
+	err := r.db.SelectContext(ctx, &rules, query)
+	return rules, err
+}
+
+// DeleteRule deletes a reward rule (soft delete)
+func (r *RewardRepository) DeleteRule(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE reward_rules SET is_active = false WHERE id = $1`
+	return affected(r.db.ExecContext(ctx, query, id))
+}
+
+// Credit records a ledger entry and adds its points to the user, of the
+// organization of ctx, in one statement. It returns false if the source was
+// already credited or the user is not in the organization.
+func (r *RewardRepository) Credit(ctx context.Context, txn *models.RewardTransaction) (bool, error) {
+	tenant, args := tenantCondition(ctx, "organization_id", 8)
+	query := `
+		WITH inserted AS (
+			INSERT INTO reward_transactions (user_id, points, source, source_id, reward_rule_id, waste_type, weight_kg)
+			SELECT id, $2, $3, $4, $5, $6, $7 FROM users WHERE id = $1` + tenant + `
+			ON CONFLICT (source, source_id) DO NOTHING
+			RETURNING id, user_id, points, created_at
+		), credited AS (
+			UPDATE users SET reward_points = reward_points + inserted.points, updated_at = CURRENT_TIMESTAMP
+			FROM inserted WHERE users.id = inserted.user_id
+		)
+		SELECT id, created_at FROM inserted`
+
+	err := r.db.QueryRowxContext(ctx, query, append([]interface{}{
+		txn.UserID,
+		txn.Points,
+		txn.Source,
+		txn.SourceID,
+		txn.RewardRuleID,
+		txn.WasteType,
+		txn.WeightKg,
+	}, args...)...).Scan(&txn.ID, &txn.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Debit takes up to -txn.Points points from the user, of the organization of
+// ctx, never below zero, and records the ledger entry with the points
+// actually taken in one statement. It returns false if the source was already
+// debited or the user had no points.
+func (r *RewardRepository) Debit(ctx context.Context, txn *models.RewardTransaction) (bool, error) {
+	tenant, args := tenantCondition(ctx, "organization_id", 6)
+	query := `
+		WITH balance AS (
+			SELECT id, LEAST($2, COALESCE(reward_points, 0)) AS points
+			FROM users WHERE id = $1` + tenant + `
+			FOR UPDATE
+		), inserted AS (
+			INSERT INTO reward_transactions (user_id, points, source, source_id, waste_type)
+			SELECT balance.id, -balance.points, $3, $4, $5 FROM balance WHERE balance.points > 0
+			ON CONFLICT (source, source_id) DO NOTHING
+			RETURNING id, user_id, points, created_at
+		), debited AS (
+			UPDATE users SET reward_points = reward_points + inserted.points, updated_at = CURRENT_TIMESTAMP
+			FROM inserted WHERE users.id = inserted.user_id
+		)
+		SELECT id, points, created_at FROM inserted`
+
+	err := r.db.QueryRowxContext(ctx, query, append([]interface{}{
+		txn.UserID,
+		-txn.Points,
+		txn.Source,
+		txn.SourceID,
+		txn.WasteType,
+	}, args...)...).Scan(&txn.ID, &txn.Points, &txn.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// GetTransaction retrieves the ledger entry of a source, of a user of the
+// organization of ctx
+func (r *RewardRepository) GetTransaction(ctx context.Context, source models.RewardSource, sourceID uuid.UUID) (*models.RewardTransaction, error) {
+	var txn models.RewardTransaction
+	owner, args := ownerCondition(ctx, "user_id", "users", 3)
+	query := `SELECT * FROM reward_transactions WHERE source = $1 AND source_id = $2` + owner
+
+	err := r.db.GetContext(ctx, &txn, query, append([]interface{}{source, sourceID}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &txn, err
+}
+
+// ListTransactions retrieves the reward ledger of a user of the organization
+// of ctx, newest first
+func (r *RewardRepository) ListTransactions(ctx context.Context, userID uuid.UUID, page Page) ([]models.RewardTransaction, PageResult, error) {
+	q := &listQuery{from: "reward_transactions"}
+	q.where("user_id = $%d", userID)
+	q.owner(ctx, "user_id", "users")
+	return listPage(ctx, r.db, q, page, func(t models.RewardTransaction) Cursor {
+		return Cursor{Keys: []string{timeKey(t.CreatedAt)}, ID: t.ID}
+	}, true, "created_at")
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// RouteRepository handles the routes planned for drivers
+type RouteRepository struct {
+	db *DB
+}
+
+// NewRouteRepository creates a new RouteRepository instance
+func NewRouteRepository(db *DB) *RouteRepository {
+	return &RouteRepository{db: db}
+}
+
+// ReplacePending saves a route planned for a driver in place of the route
+// still pending for them, if any
+func (r *RouteRepository) ReplacePending(ctx context.Context, route *models.DriverRoute) error {
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		query := `DELETE FROM driver_routes WHERE driver_id = $1 AND status = 'pending'`
+		if _, err := tx.ExecContext(ctx, query, route.DriverID); err != nil {
+			return err
+		}
+
+		query = `
+			INSERT INTO driver_routes (id, driver_id, waypoints, total_distance_km, estimated_duration_minutes, status, optimize_by, truck_capacity_liters)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING created_at`
+		return tx.QueryRowxContext(ctx, query,
+			route.ID,
+			route.DriverID,
+			route.Waypoints,
+			route.TotalDistanceKm,
+			route.EstimatedDurationMinutes,
+			route.Status,
+			route.OptimizeBy,
+			route.TruckCapacityLiters,
+		).Scan(&route.CreatedAt)
+	})
+}
+
+// GetByID retrieves a route by ID within the organization of ctx, with its
+// waypoints parsed
+func (r *RouteRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DriverRoute, error) {
+	var route models.DriverRoute
+	owner, args := ownerCondition(ctx, "driver_id", "drivers", 2)
+	query := `SELECT ` + routeColumns + ` FROM driver_routes WHERE id = $1` + owner
+
+	err := r.db.GetContext(ctx, &route, query, append([]interface{}{id}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := parseRoute(&route); err != nil {
+		return nil, err
+	}
+	return &route, nil
+}
+
+// ListActive retrieves the pending and in-progress routes of every
+// organization, with their waypoints parsed
+func (r *RouteRepository) ListActive(ctx context.Context) ([]models.DriverRoute, error) {
+	var routes []models.DriverRoute
+	query := `SELECT ` + routeColumns + ` FROM driver_routes WHERE status IN ('pending', 'in_progress') ORDER BY created_at`
+
+	if err := r.db.SelectContext(ctx, &routes, query); err != nil {
+		return nil, err
+	}
+	for i := range routes {
+		if err := parseRoute(&routes[i]); err != nil {
+			return nil, err
+		}
+	}
+	return routes, nil
+}
+
+// UpdatePlan saves the re-sequenced waypoints of a route, its metrics and
+// status. It returns ErrNotFound when the route was closed meanwhile.
+func (r *RouteRepository) UpdatePlan(ctx context.Context, route *models.DriverRoute) error {
+	query := `
+		UPDATE driver_routes
+		SET waypoints = $1, total_distance_km = $2, estimated_duration_minutes = $3, status = $4,
+			completed_at = $5, reoptimized_at = NOW()
+		WHERE id = $6 AND status IN ('pending', 'in_progress')
+		RETURNING reoptimized_at`
+
+	err := r.db.QueryRowxContext(ctx, query,
+		route.Waypoints,
+		route.TotalDistanceKm,
+		route.EstimatedDurationMinutes,
+		route.Status,
+		route.CompletedAt,
+		route.ID,
+	).Scan(&route.ReoptimizedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// UnroutedCriticalBins retrieves the in-service bins of the organization of a
+// driver at or above their alert threshold that are on no active route and
+// have no open collection, restricted to the zones the driver covers if any
+func (r *RouteRepository) UnroutedCriticalBins(ctx context.Context, driverID uuid.UUID) ([]models.Bin, error) {
+	var bins []models.Bin
+	query := `
+		SELECT b.* FROM bins b
+		JOIN drivers d ON d.id = $1 AND d.organization_id = b.organization_id
+		WHERE b.status = 'active' AND b.fill_level >= b.alert_threshold
+		AND NOT EXISTS (
+			SELECT 1 FROM collections c
+			WHERE c.bin_id = b.id AND c.status IN ('pending', 'in_progress')
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM driver_routes dr, jsonb_array_elements(dr.waypoints) wp
+			WHERE dr.status IN ('pending', 'in_progress') AND wp->>'bin_id' = b.id::text
+		)
+		AND (
+			NOT EXISTS (SELECT 1 FROM driver_zones dz WHERE dz.driver_id = $1)
+			OR b.zone_id IN (SELECT dz.zone_id FROM driver_zones dz WHERE dz.driver_id = $1)
+		)`
+
+	err := r.db.SelectContext(ctx, &bins, query, driverID)
+	return bins, err
+}
+
+// routeColumns lists the driver_routes columns DriverRoute scans
+const routeColumns = `id, driver_id, waypoints, total_distance_km, estimated_duration_minutes, status,
+	optimize_by, truck_capacity_liters, created_at, started_at, completed_at, reoptimized_at`
+
+// parseRoute parses the waypoints of a route read from the database
+func parseRoute(route *models.DriverRoute) error {
+	if err := route.ParseWaypoints(); err != nil {
+		return err
+	}
+	if n := len(route.WaypointsList); n > 0 {
+		route.Trips = route.WaypointsList[n-1].Trip
+	}
+	return nil
+}
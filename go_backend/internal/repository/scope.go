@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/auth"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// companyScope returns the company the principal of ctx is confined to.
+// Company members and company API keys only see their own company's data;
+// company principals without a company see nothing. It returns nil for
+// every other principal and for background work without one.
+func companyScope(ctx context.Context) *uuid.UUID {
+	claims, ok := auth.ClaimsFromContext(ctx)
+	if !ok || claims.Role != models.RoleCompany {
+		return nil
+	}
+	if claims.CompanyID == nil {
+		return &uuid.Nil
+	}
+	return claims.CompanyID
+}
+
+// scopeCondition returns " AND column = $n" confining the rows to the
+// company scope of ctx, with the argument to append, or "" when unscoped.
+// shared also lets the rows without a company through, for records such as
+// global pricing rules that every company reads.
+func scopeCondition(ctx context.Context, column string, n int, shared bool) (string, []interface{}) {
+	scope := companyScope(ctx)
+	if scope == nil {
+		return "", nil
+	}
+	if shared {
+		return fmt.Sprintf(" AND (%s = $%d OR %s IS NULL)", column, n, column), []interface{}{*scope}
+	}
+	return fmt.Sprintf(" AND %s = $%d", column, n), []interface{}{*scope}
+}
+
+// scope adds the company scope of ctx to a list query
+func (q *listQuery) scope(ctx context.Context, column string, shared bool) {
+	if scope := companyScope(ctx); scope != nil {
+		if shared {
+			q.where("("+column+" = $%d OR "+column+" IS NULL)", *scope)
+		} else {
+			q.where(column+" = $%d", *scope)
+		}
+	}
+}
+
+// tenantScope returns the organization ctx is confined to: the tenant of the
+// request's principal, or the organization background work was started for.
+// It returns nil for background work spanning every organization.
+func tenantScope(ctx context.Context) *uuid.UUID {
+	if id, ok := auth.OrganizationFromContext(ctx); ok {
+		return &id
+	}
+	return nil
+}
+
+// tenantCondition returns " AND column = $n" confining the rows to the
+// organization of ctx, with the argument to append, or "" when unscoped
+func tenantCondition(ctx context.Context, column string, n int) (string, []interface{}) {
+	scope := tenantScope(ctx)
+	if scope == nil {
+		return "", nil
+	}
+	return fmt.Sprintf(" AND %s = $%d", column, n), []interface{}{*scope}
+}
+
+// tenant adds the organization of ctx to a list query
+func (q *listQuery) tenant(ctx context.Context, column string) {
+	if scope := tenantScope(ctx); scope != nil {
+		q.where(column+" = $%d", *scope)
+	}
+}
+
+// assignOrganization sets a new row's organization to the one ctx is confined
+// to, so rows are never created in another tenant; unscoped work keeps the
+// organization it set itself
+func assignOrganization(ctx context.Context, organizationID *uuid.UUID) {
+	if scope := tenantScope(ctx); scope != nil {
+		*organizationID = *scope
+	}
+}
+
+// ownerCondition returns " AND column IN (...)" confining rows to those whose
+// owner, the row of the owner table the column references, belongs to the
+// organization of ctx, with the argument to append, or "" when unscoped
+func ownerCondition(ctx context.Context, column, owner string, n int) (string, []interface{}) {
+	scope := tenantScope(ctx)
+	if scope == nil {
+		return "", nil
+	}
+	return fmt.Sprintf(" AND %s IN (SELECT id FROM %s WHERE organization_id = $%d)", column, owner, n), []interface{}{*scope}
+}
+
+// owner adds the organization of ctx to a list query over rows owned by a
+// row of the owner table
+func (q *listQuery) owner(ctx context.Context, column, owner string) {
+	if scope := tenantScope(ctx); scope != nil {
+		q.where(column+" IN (SELECT id FROM "+owner+" WHERE organization_id = $%d)", *scope)
+	}
+}
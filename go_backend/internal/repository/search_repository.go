@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// searchQueries selects the matches of each entity type. $1 is the raw search
+// term, scored with pg_trgm similarity; $2 is the escaped ILIKE pattern, so
+// substring matches are found even when they score below the trigram cut-off.
+// The queries of organization-owned entities end in a WHERE clause that
+// Search extends with the organization of ctx.
+var searchQueries = map[models.SearchResultType]string{
+	models.SearchResultBin: `
+		SELECT 'bin' AS type, id, device_id AS title, COALESCE(location_name, '') AS subtitle,
+			GREATEST(similarity(device_id, $1), similarity(COALESCE(location_name, ''), $1)) AS score
+		FROM bins
+		WHERE is_active = true
+			AND (device_id ILIKE $2 OR location_name ILIKE $2 OR device_id % $1 OR location_name % $1)`,
+	models.SearchResultDriver: `
+		SELECT 'driver' AS type, id, full_name AS title, email AS subtitle,
+			GREATEST(similarity(full_name, $1), similarity(email, $1), similarity(phone, $1)) AS score
+		FROM drivers
+		WHERE (full_name ILIKE $2 OR email ILIKE $2 OR phone ILIKE $2 OR full_name % $1 OR email % $1)`,
+	models.SearchResultUser: `
+		SELECT 'user' AS type, id, full_name AS title, email AS subtitle,
+			GREATEST(similarity(full_name, $1), similarity(email, $1)) AS score
+		FROM users
+		WHERE (full_name ILIKE $2 OR email ILIKE $2 OR full_name % $1 OR email % $1)`,
+	models.SearchResultCompany: `
+		SELECT 'company' AS type, id, name AS title, email AS subtitle,
+			GREATEST(similarity(name, $1), similarity(email, $1)) AS score
+		FROM companies
+		WHERE is_active = true
+			AND (name ILIKE $2 OR email ILIKE $2 OR name % $1 OR email % $1)`,
+}
+
+// organizationSearched are the entity types owned by an organization; companies
+// are shared by every organization
+var organizationSearched = map[models.SearchResultType]bool{
+	models.SearchResultBin:    true,
+	models.SearchResultDriver: true,
+	models.SearchResultUser:   true,
+}
+
+// SearchRepository handles the global search across entities
+type SearchRepository struct {
+	db *DB
+}
+
+// NewSearchRepository creates a new SearchRepository instance
+func NewSearchRepository(db *DB) *SearchRepository {
+	return &SearchRepository{db: db}
+}
+
+// Search retrieves the best matches for term among the given entity types,
+// highest score first
+func (r *SearchRepository) Search(ctx context.Context, term string, types []models.SearchResultType, limit int) ([]models.SearchResult, error) {
+	args := []interface{}{term, "%" + escapeLike(term) + "%", limit}
+	tenant, tenantArgs := tenantCondition(ctx, "organization_id", 4)
+	args = append(args, tenantArgs...)
+
+	parts := make([]string, 0, len(types))
+	for _, t := range types {
+		if query, ok := searchQueries[t]; ok {
+			if organizationSearched[t] {
+				query += tenant
+			}
+			parts = append(parts, query)
+		}
+	}
+	if len(parts) == 0 {
+		return []models.SearchResult{}, nil
+	}
+
+	query := `SELECT * FROM (` + strings.Join(parts, "\n\t\tUNION ALL") + `
+		) results
+		ORDER BY score DESC, title ASC
+		LIMIT $3`
+
+	results := []models.SearchResult{}
+	err := r.db.SelectContext(ctx, &results, query, args...)
+	return results, err
+}
+
+// escapeLike escapes the LIKE wildcards in s so it matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
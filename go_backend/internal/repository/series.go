@@ -0,0 +1,17 @@
+package repository
+
+import "github.com/smartwaste/backend/internal/models"
+
+// seriesBuckets is a CTE listing the UTC start of every bucket of the range
+// $2..$3 truncated to the unit $1, so a series has a row even for empty buckets
+const seriesBuckets = `buckets AS (
+		SELECT generate_series(
+			date_trunc($1, $2::timestamptz AT TIME ZONE 'UTC'),
+			$3::timestamptz AT TIME ZONE 'UTC' - interval '1 microsecond',
+			('1 ' || $1)::interval) AS bucket
+	)`
+
+// seriesArgs returns the $1..$3 arguments of a query built on seriesBuckets
+func seriesArgs(period models.AnalyticsRange) []interface{} {
+	return []interface{}{string(period.GroupBy), period.From, period.To}
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// SettingsRepository stores the runtime settings, one row per section
+type SettingsRepository struct {
+	db *DB
+}
+
+// NewSettingsRepository creates a new SettingsRepository instance
+func NewSettingsRepository(db *DB) *SettingsRepository {
+	return &SettingsRepository{db: db}
+}
+
+// Load decodes the stored sections onto settings. Sections without a row,
+// and fields a stored section does not have, keep their value.
+func (r *SettingsRepository) Load(ctx context.Context, settings *models.Settings) error {
+	var rows []struct {
+		Key   string          `db:"key"`
+		Value json.RawMessage `db:"value"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, `SELECT key, value FROM settings`); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		var section interface{}
+		switch row.Key {
+		case models.SettingsThresholds:
+			section = &settings.Thresholds
+		case models.SettingsDispatch:
+			section = &settings.Dispatch
+		case models.SettingsNotifications:
+			section = &settings.Notifications
+		default:
+			continue
+		}
+		if err := json.Unmarshal(row.Value, section); err != nil {
+			return fmt.Errorf("invalid %s settings: %w", row.Key, err)
+		}
+	}
+	return nil
+}
+
+// Save stores the given sections of settings in one transaction. updatedBy is
+// the user who changed them, nil for an API key.
+func (r *SettingsRepository) Save(ctx context.Context, settings *models.Settings, sections []string, updatedBy *uuid.UUID) error {
+	values := map[string]interface{}{
+		models.SettingsThresholds:    settings.Thresholds,
+		models.SettingsDispatch:      settings.Dispatch,
+		models.SettingsNotifications: settings.Notifications,
+	}
+
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		query := `
+			INSERT INTO settings (key, value, updated_by, updated_at)
+			VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+			ON CONFLICT (key) DO UPDATE
+			SET value = EXCLUDED.value, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at`
+		for _, key := range sections {
+			value, ok := values[key]
+			if !ok {
+				return fmt.Errorf("unknown settings section %q", key)
+			}
+			data, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, query, key, data, updatedBy); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// SLARepository handles collection SLA rules and their breaches
+type SLARepository struct {
+	db *DB
+}
+
+// NewSLARepository creates a new SLARepository instance
+func NewSLARepository(db *DB) *SLARepository {
+	return &SLARepository{db: db}
+}
+
+// breachColumns selects a breach joined to its bin as sla_breaches s, bins b
+const breachColumns = `s.id, s.bin_id, b.device_id, s.rule_id, s.company_id, s.max_hours,
+	s.threshold_crossed_at, s.due_at, s.detected_at, s.resolved_at`
+
+// Create creates a new SLA rule
+func (r *SLARepository) Create(ctx context.Context, rule *models.SLARule) error {
+	query := `
+		INSERT INTO sla_rules (company_id, waste_type, max_hours)
+		VALUES ($1, $2, $3)
+		RETURNING id, is_active, created_at, updated_at`
+
+	return r.db.QueryRowxContext(ctx, query,
+		rule.CompanyID,
+		rule.WasteType,
+		rule.MaxHours,
+	).Scan(&rule.ID, &rule.IsActive, &rule.CreatedAt, &rule.UpdatedAt)
+}
+
+// GetByID retrieves an SLA rule by ID
+func (r *SLARepository) GetByID(ctx context.Context, id uuid.UUID) (*models.SLARule, error) {
+	var rule models.SLARule
+	query := `SELECT * FROM sla_rules WHERE id = $1`
+
+	err := r.db.GetContext(ctx, &rule, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &rule, err
+}
+
+// Update updates an SLA rule
+func (r *SLARepository) Update(ctx context.Context, rule *models.SLARule) error {
+	query := `
+		UPDATE sla_rules
+		SET max_hours = $1, is_active = $2
+		WHERE id = $3
+		RETURNING updated_at`
+
+	return r.db.QueryRowxContext(ctx, query,
+		rule.MaxHours,
+		rule.IsActive,
+		rule.ID,
+	).Scan(&rule.UpdatedAt)
+}
+
+// List retrieves all active SLA rules, the defaults first
+func (r *SLARepository) List(ctx context.Context) ([]models.SLARule, error) {
+	var rules []models.SLARule
+	query := `
+		SELECT * FROM sla_rules
+		WHERE is_active = true
+		ORDER BY company_id NULLS FIRST, waste_type NULLS FIRST`
+	err := r.db.SelectContext(ctx, &rules, query)
+	return rules, err
+}
+
+// Delete deletes an SLA rule (soft delete)
+func (r *SLARepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE sla_rules SET is_active = false WHERE id = $1`
+	return affected(r.db.ExecContext(ctx, query, id))
+}
+
+// RecordBreaches records a breach for every active bin that has been at or
+// above its collection threshold past the limit of its most specific rule, as
+// of now, and returns the breaches that were newly recorded
+func (r *SLARepository) RecordBreaches(ctx context.Context, now time.Time) ([]models.SLABreach, error) {
+	var breaches []models.SLABreach
+	query := `
+		WITH recorded AS (
+			INSERT INTO sla_breaches (bin_id, rule_id, company_id, max_hours, threshold_crossed_at, due_at)
+			SELECT b.id, rule.id, b.company_id, rule.max_hours, b.threshold_crossed_at,
+				b.threshold_crossed_at + rule.max_hours * INTERVAL '1 hour'
+			FROM bins b
+			CROSS JOIN LATERAL (
+				SELECT id, max_hours FROM sla_rules
+				WHERE is_active = true
+					AND (company_id IS NULL OR company_id = b.company_id)
+					AND (waste_type IS NULL OR waste_type = b.waste_type)
+				ORDER BY company_id IS NULL, waste_type IS NULL
+				LIMIT 1
+			) rule
+			WHERE b.is_active = true AND b.threshold_crossed_at IS NOT NULL
+				AND b.threshold_crossed_at + rule.max_hours * INTERVAL '1 hour' <= $1
+			ON CONFLICT (bin_id, threshold_crossed_at) DO NOTHING
+			RETURNING *
+		)
+		SELECT ` + breachColumns + `
+		FROM recorded s
+		JOIN bins b ON b.id = s.bin_id
+		ORDER BY s.due_at`
+	err := r.db.SelectContext(ctx, &breaches, query, now)
+	return breaches, err
+}
+
+// ResolveBreaches resolves the open breaches of bins that dropped back below
+// their collection threshold, or were deactivated, at their last collection
+// when it emptied them and at now otherwise
+func (r *SLARepository) ResolveBreaches(ctx context.Context, now time.Time) (int64, error) {
+	query := `
+		UPDATE sla_breaches s
+		SET resolved_at = CASE
+			WHEN b.last_collection_at >= s.threshold_crossed_at THEN b.last_collection_at
+			ELSE $1
+		END
+		FROM bins b
+		WHERE b.id = s.bin_id AND s.resolved_at IS NULL
+			AND (b.is_active = false OR b.threshold_crossed_at IS DISTINCT FROM s.threshold_crossed_at)`
+	result, err := r.db.ExecContext(ctx, query, now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// slaTotals totals the breaches that fell due in a period
+type slaTotals struct {
+	Breaches            int      `db:"breaches"`
+	Resolved            int      `db:"resolved"`
+	AverageOverdueHours *float64 `db:"average_overdue_hours"`
+}
+
+// Report summarizes the breaches of the bins of the organization of ctx that
+// fell due in the period, optionally of one company's bins only, with up to
+// limit breaches still open now, the most overdue first
+func (r *SLARepository) Report(ctx context.Context, period models.AnalyticsRange, companyID *uuid.UUID, limit int) (*models.SLAReport, error) {
+	const tenantFilter = " AND s.bin_id IN (SELECT id FROM bins WHERE organization_id = $%d)"
+	tenant := tenantScope(ctx)
+
+	args := []interface{}{period.From, period.To}
+	companyFilter := ""
+	if companyID != nil {
+		args = append(args, *companyID)
+		companyFilter = " AND s.company_id = $3"
+	}
+	if tenant != nil {
+		args = append(args, *tenant)
+		companyFilter += fmt.Sprintf(tenantFilter, len(args))
+	}
+
+	var totals slaTotals
+	query := `
+		SELECT COUNT(*) AS breaches,
+			COUNT(resolved_at) AS resolved,
+			AVG(EXTRACT(EPOCH FROM resolved_at - due_at) / 3600)::float8 AS average_overdue_hours
+		FROM sla_breaches s
+		WHERE s.due_at >= $1 AND s.due_at < $2` + companyFilter
+	if err := r.db.GetContext(ctx, &totals, query, args...); err != nil {
+		return nil, err
+	}
+
+	var byCompany []models.SLACompanyBreaches
+	query = `
+		SELECT s.company_id,
+			COUNT(*) AS breaches,
+			COUNT(*) FILTER (WHERE s.resolved_at IS NULL) AS open
+		FROM sla_breaches s
+		WHERE s.due_at >= $1 AND s.due_at < $2` + companyFilter + `
+		GROUP BY s.company_id
+		ORDER BY breaches DESC, s.company_id NULLS LAST`
+	if err := r.db.SelectContext(ctx, &byCompany, query, args...); err != nil {
+		return nil, err
+	}
+
+	openArgs := []interface{}{limit}
+	openFilter := ""
+	if companyID != nil {
+		openArgs = append(openArgs, *companyID)
+		openFilter = " AND s.company_id = $2"
+	}
+	if tenant != nil {
+		openArgs = append(openArgs, *tenant)
+		openFilter += fmt.Sprintf(tenantFilter, len(openArgs))
+	}
+	var open []models.SLABreach
+	query = `
+		SELECT ` + breachColumns + `
+		FROM sla_breaches s
+		JOIN bins b ON b.id = s.bin_id
+		WHERE s.resolved_at IS NULL` + openFilter + `
+		ORDER BY s.due_at
+		LIMIT $1`
+	if err := r.db.SelectContext(ctx, &open, query, openArgs...); err != nil {
+		return nil, err
+	}
+
+	if byCompany == nil {
+		byCompany = []models.SLACompanyBreaches{}
+	}
+	if open == nil {
+		open = []models.SLABreach{}
+	}
+	return &models.SLAReport{
+		Period:              period,
+		CompanyID:           companyID,
+		Breaches:            totals.Breaches,
+		Resolved:            totals.Resolved,
+		Open:                totals.Breaches - totals.Resolved,
+		AverageOverdueHours: totals.AverageOverdueHours,
+		ByCompany:           byCompany,
+		OpenBreaches:        open,
+	}, nil
+}
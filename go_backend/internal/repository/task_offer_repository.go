@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// TaskOfferRepository handles the full bins offered to drivers
+type TaskOfferRepository struct {
+	db dbtx
+}
+
+// NewTaskOfferRepository creates a new TaskOfferRepository instance
+func NewTaskOfferRepository(db *DB) *TaskOfferRepository {
+	return &TaskOfferRepository{db: db}
+}
+
+// Create offers a bin to a driver, in the organization of the bin
+func (r *TaskOfferRepository) Create(ctx context.Context, offer *models.TaskOffer) error {
+	query := `
+		INSERT INTO task_offers (organization_id, bin_id, driver_id, notification_id)
+		VALUES ((SELECT organization_id FROM bins WHERE id = $1), $1, $2, $3)
+		RETURNING id, organization_id, status, offered_at`
+
+	return r.db.QueryRowxContext(ctx, query,
+		offer.BinID,
+		offer.DriverID,
+		offer.NotificationID,
+	).Scan(&offer.ID, &offer.OrganizationID, &offer.Status, &offer.OfferedAt)
+}
+
+// GetByID retrieves a task offer by ID within the organization of ctx
+func (r *TaskOfferRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.TaskOffer, error) {
+	var offer models.TaskOffer
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `SELECT * FROM task_offers WHERE id = $1` + tenant
+
+	err := r.db.GetContext(ctx, &offer, query, append([]interface{}{id}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &offer, err
+}
+
+// ListByDriver retrieves the tasks offered to a driver of the organization
+// of ctx, newest first
+func (r *TaskOfferRepository) ListByDriver(ctx context.Context, driverID uuid.UUID, status *models.TaskOfferStatus, page Page) ([]models.TaskOffer, PageResult, error) {
+	q := &listQuery{from: "task_offers"}
+	q.where("driver_id = $%d", driverID)
+	q.tenant(ctx, "organization_id")
+	if status != nil {
+		q.where("status = $%d", *status)
+	}
+	return listPage(ctx, r.db, q, page, func(offer models.TaskOffer) Cursor {
+		return Cursor{Keys: []string{timeKey(offer.OfferedAt)}, ID: offer.ID}
+	}, true, "offered_at")
+}
+
+// Accept accepts an open task offer, creating the collection of its bin and
+// withdrawing the other offers of the bin. When the bin already has a pending
+// or in-progress collection the offer is withdrawn instead and no collection
+// is created. It returns ErrNotFound when the offer is no longer open.
+func (r *TaskOfferRepository) Accept(ctx context.Context, offer *models.TaskOffer, collection *models.Collection) error {
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		// Serialize the acceptances of the bin's offers
+		query := `SELECT id FROM bins WHERE id = $1 FOR UPDATE`
+		var binID uuid.UUID
+		if err := tx.GetContext(ctx, &binID, query, offer.BinID); err != nil {
+			return err
+		}
+
+		var collected bool
+		query = `
+			SELECT EXISTS (
+				SELECT 1 FROM collections WHERE bin_id = $1 AND status IN ('pending', 'in_progress')
+			)`
+		if err := tx.GetContext(ctx, &collected, query, offer.BinID); err != nil {
+			return err
+		}
+		if collected {
+			return respondOffer(ctx, tx, offer, models.TaskOfferWithdrawn, nil, nil)
+		}
+
+		if err := (&CollectionRepository{db: tx}).Create(ctx, collection); err != nil {
+			return err
+		}
+		if err := respondOffer(ctx, tx, offer, models.TaskOfferAccepted, &collection.ID, nil); err != nil {
+			return err
+		}
+
+		query = `
+			UPDATE task_offers SET status = 'withdrawn', responded_at = CURRENT_TIMESTAMP
+			WHERE bin_id = $1 AND id <> $2 AND status = 'offered'`
+		_, err := tx.ExecContext(ctx, query, offer.BinID, offer.ID)
+		return err
+	})
+}
+
+// Decline declines an open task offer. It returns ErrNotFound when the offer
+// is no longer open.
+func (r *TaskOfferRepository) Decline(ctx context.Context, offer *models.TaskOffer, reason *string) error {
+	return respondOffer(ctx, r.db, offer, models.TaskOfferDeclined, nil, reason)
+}
+
+// respondOffer closes an open task offer with a status
+func respondOffer(ctx context.Context, db dbtx, offer *models.TaskOffer, status models.TaskOfferStatus, collectionID *uuid.UUID, reason *string) error {
+	query := `
+		UPDATE task_offers
+		SET status = $1, collection_id = $2, decline_reason = $3, responded_at = CURRENT_TIMESTAMP
+		WHERE id = $4 AND status = 'offered'
+		RETURNING status, collection_id, decline_reason, responded_at`
+
+	err := db.QueryRowxContext(ctx, query, status, collectionID, reason, offer.ID).
+		Scan(&offer.Status, &offer.CollectionID, &offer.DeclineReason, &offer.RespondedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// UploadRepository handles files uploaded to object storage
+type UploadRepository struct {
+	db *DB
+}
+
+// NewUploadRepository creates a new UploadRepository instance
+func NewUploadRepository(db *DB) *UploadRepository {
+	return &UploadRepository{db: db}
+}
+
+// Create records a new upload
+func (r *UploadRepository) Create(ctx context.Context, upload *models.Upload) error {
+	query := `
+		INSERT INTO uploads (object_key, url, purpose, content_type, size_bytes, uploaded_by, uploader_role)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at`
+
+	return r.db.QueryRowxContext(ctx, query,
+		upload.ObjectKey,
+		upload.URL,
+		upload.Purpose,
+		upload.ContentType,
+		upload.SizeBytes,
+		upload.UploadedBy,
+		upload.UploaderRole,
+	).Scan(&upload.ID, &upload.CreatedAt)
+}
+
+// GetByURL retrieves the upload served from a URL
+func (r *UploadRepository) GetByURL(ctx context.Context, url string) (*models.Upload, error) {
+	var upload models.Upload
+	query := `SELECT * FROM uploads WHERE url = $1`
+
+	err := r.db.GetContext(ctx, &upload, query, url)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &upload, err
+}
+
+// MarkAttached records that a record refers to the upload
+func (r *UploadRepository) MarkAttached(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE uploads SET attached_at = COALESCE(attached_at, NOW()) WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// ListUnattached retrieves up to limit uploads created before the cutoff that
+// nothing refers to, the oldest first
+func (r *UploadRepository) ListUnattached(ctx context.Context, before time.Time, limit int) ([]models.Upload, error) {
+	var uploads []models.Upload
+	query := `
+		SELECT * FROM uploads
+		WHERE attached_at IS NULL AND created_at < $1
+		ORDER BY created_at
+		LIMIT $2`
+	err := r.db.SelectContext(ctx, &uploads, query, before, limit)
+	return uploads, err
+}
+
+// Delete deletes an upload record
+func (r *UploadRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM uploads WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
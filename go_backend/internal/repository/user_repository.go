@@ -6,50 +6,64 @@ import (
 	"errors"
 
 	"github.com/google/uuid"
-	"github.com/jmoiron/sqlx"
 	"github.com/smartwaste/backend/internal/models"
 )
 
 // UserRepository handles user data operations
 type UserRepository struct {
-	db *sqlx.DB
+	db *DB
 }
 
 // NewUserRepository creates a new UserRepository instance
-func NewUserRepository(db *sqlx.DB) *UserRepository {
+func NewUserRepository(db *DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
-// Create creates a new user
+// Create creates a new user, in the organization of ctx when it is confined to one
 func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
+	assignOrganization(ctx, &user.OrganizationID)
 	query := `
-		INSERT INTO users (email, password_hash, full_name, phone, address, reward_points)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO users (organization_id, email, password_hash, full_name, phone, address, reward_points, role)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id, created_at, updated_at`
 
 	return r.db.QueryRowxContext(ctx, query,
+		user.OrganizationID,
 		user.Email,
 		user.PasswordHash,
 		user.FullName,
 		user.Phone,
 		user.Address,
 		user.RewardPoints,
+		user.Role,
 	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
 }
 
-// GetByID retrieves a user by ID
+// GetByID retrieves a user by ID, within the organization of ctx
 func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	var user models.User
-	query := `SELECT * FROM users WHERE id = $1`
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `SELECT * FROM users WHERE id = $1` + tenant
 
-	err := r.db.GetContext(ctx, &user, query, id)
+	err := r.db.GetContext(ctx, &user, query, append([]interface{}{id}, args...)...)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
 	}
 	return &user, err
 }
 
-// GetByEmail retrieves a user by email
+// ListByRole retrieves the users with a role, within the organization of ctx
+func (r *UserRepository) ListByRole(ctx context.Context, role models.Role) ([]models.User, error) {
+	var users []models.User
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `SELECT * FROM users WHERE role = $1` + tenant + ` ORDER BY created_at`
+
+	err := r.db.SelectContext(ctx, &users, query, append([]interface{}{role}, args...)...)
+	return users, err
+}
+
+// GetByEmail retrieves a user by email in any organization; emails are
+// unique across the deployment so logins need no tenant
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
 	query := `SELECT * FROM users WHERE email = $1`
@@ -61,48 +75,60 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.
 	return &user, err
 }
 
-// Update updates a user
+// Update updates a user within the organization of ctx
 func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
+	tenant, args := tenantCondition(ctx, "organization_id", 5)
 	query := `
 		UPDATE users
 		SET full_name = $1, phone = $2, address = $3, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $4
+		WHERE id = $4` + tenant + `
 		RETURNING updated_at`
 
-	return r.db.QueryRowxContext(ctx, query,
+	return r.db.QueryRowxContext(ctx, query, append([]interface{}{
 		user.FullName,
 		user.Phone,
 		user.Address,
 		user.ID,
-	).Scan(&user.UpdatedAt)
+	}, args...)...).Scan(&user.UpdatedAt)
 }
 
-// UpdateRewardPoints updates a user's reward points
+// UpdateRewardPoints updates a user's reward points within the organization of ctx
 func (r *UserRepository) UpdateRewardPoints(ctx context.Context, id uuid.UUID, points int) error {
-	query := `UPDATE users SET reward_points = reward_points + $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
-	_, err := r.db.ExecContext(ctx, query, points, id)
+	tenant, args := tenantCondition(ctx, "organization_id", 3)
+	query := `UPDATE users SET reward_points = reward_points + $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2` + tenant
+	_, err := r.db.ExecContext(ctx, query, append([]interface{}{points, id}, args...)...)
 	return err
 }
 
-// GetRewardPoints retrieves a user's reward points
+// UpdateRole updates a user's access role within the organization of ctx
+func (r *UserRepository) UpdateRole(ctx context.Context, id uuid.UUID, role models.Role) error {
+	tenant, args := tenantCondition(ctx, "organization_id", 3)
+	query := `UPDATE users SET role = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2` + tenant
+	_, err := r.db.ExecContext(ctx, query, append([]interface{}{role, id}, args...)...)
+	return err
+}
+
+// GetRewardPoints retrieves a user's reward points within the organization of ctx
 func (r *UserRepository) GetRewardPoints(ctx context.Context, id uuid.UUID) (int, error) {
 	var points int
-	query := `SELECT reward_points FROM users WHERE id = $1`
-	err := r.db.GetContext(ctx, &points, query, id)
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `SELECT reward_points FROM users WHERE id = $1` + tenant
+	err := r.db.GetContext(ctx, &points, query, append([]interface{}{id}, args...)...)
 	return points, err
 }
 
-// Delete deletes a user
+// Delete deletes a user within the organization of ctx
 func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM users WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `DELETE FROM users WHERE id = $1` + tenant
+	return affected(r.db.ExecContext(ctx, query, append([]interface{}{id}, args...)...))
 }
 
-// List retrieves all users with pagination
-func (r *UserRepository) List(ctx context.Context, limit, offset int) ([]models.User, error) {
-	var users []models.User
-	query := `SELECT * FROM users ORDER BY created_at DESC LIMIT $1 OFFSET $2`
-	err := r.db.SelectContext(ctx, &users, query, limit, offset)
-	return users, err
+// List retrieves the users of the organization of ctx with pagination, newest first
+func (r *UserRepository) List(ctx context.Context, page Page) ([]models.User, PageResult, error) {
+	q := &listQuery{from: "users"}
+	q.tenant(ctx, "organization_id")
+	return listPage(ctx, r.db, q, page, func(u models.User) Cursor {
+		return Cursor{Keys: []string{timeKey(u.CreatedAt)}, ID: u.ID}
+	}, true, "created_at")
 }
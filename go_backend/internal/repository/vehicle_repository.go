@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// VehicleRepository handles vehicle data operations
+type VehicleRepository struct {
+	db *DB
+}
+
+// NewVehicleRepository creates a new VehicleRepository instance
+func NewVehicleRepository(db *DB) *VehicleRepository {
+	return &VehicleRepository{db: db}
+}
+
+// Create creates a new vehicle, in the organization of ctx when it is confined to one
+func (r *VehicleRepository) Create(ctx context.Context, vehicle *models.Vehicle) error {
+	assignOrganization(ctx, &vehicle.OrganizationID)
+	query := `
+		INSERT INTO vehicles (organization_id, plate_number, make, model, capacity_liters, capacity_kg, fuel_type, maintenance_status, next_maintenance_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, is_active, created_at, updated_at`
+
+	return r.db.QueryRowxContext(ctx, query,
+		vehicle.OrganizationID,
+		vehicle.PlateNumber,
+		vehicle.Make,
+		vehicle.Model,
+		vehicle.CapacityLiters,
+		vehicle.CapacityKg,
+		vehicle.FuelType,
+		vehicle.MaintenanceStatus,
+		vehicle.NextMaintenanceAt,
+	).Scan(&vehicle.ID, &vehicle.IsActive, &vehicle.CreatedAt, &vehicle.UpdatedAt)
+}
+
+// GetByID retrieves a vehicle by ID within the organization of ctx
+func (r *VehicleRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Vehicle, error) {
+	var vehicle models.Vehicle
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `SELECT * FROM vehicles WHERE id = $1` + tenant
+
+	err := r.db.GetContext(ctx, &vehicle, query, append([]interface{}{id}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &vehicle, err
+}
+
+// GetByPlate retrieves a vehicle by plate number within the organization of ctx
+func (r *VehicleRepository) GetByPlate(ctx context.Context, plate string) (*models.Vehicle, error) {
+	var vehicle models.Vehicle
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `SELECT * FROM vehicles WHERE plate_number = $1` + tenant
+
+	err := r.db.GetContext(ctx, &vehicle, query, append([]interface{}{plate}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &vehicle, err
+}
+
+// Update updates a vehicle within the organization of ctx
+func (r *VehicleRepository) Update(ctx context.Context, vehicle *models.Vehicle) error {
+	tenant, args := tenantCondition(ctx, "organization_id", 12)
+	query := `
+		UPDATE vehicles
+		SET plate_number = $1, make = $2, model = $3, capacity_liters = $4, capacity_kg = $5, fuel_type = $6,
+			maintenance_status = $7, last_maintenance_at = $8, next_maintenance_at = $9, is_active = $10
+		WHERE id = $11` + tenant + `
+		RETURNING updated_at`
+
+	return r.db.QueryRowxContext(ctx, query, append([]interface{}{
+		vehicle.PlateNumber,
+		vehicle.Make,
+		vehicle.Model,
+		vehicle.CapacityLiters,
+		vehicle.CapacityKg,
+		vehicle.FuelType,
+		vehicle.MaintenanceStatus,
+		vehicle.LastMaintenanceAt,
+		vehicle.NextMaintenanceAt,
+		vehicle.IsActive,
+		vehicle.ID,
+	}, args...)...).Scan(&vehicle.UpdatedAt)
+}
+
+// List retrieves the active vehicles of the organization of ctx with
+// pagination, optionally by maintenance status
+func (r *VehicleRepository) List(ctx context.Context, status *models.MaintenanceStatus, page Page) ([]models.Vehicle, PageResult, error) {
+	q := &listQuery{from: "vehicles", conditions: []string{"is_active = true"}}
+	q.tenant(ctx, "organization_id")
+	if status != nil {
+		q.where("maintenance_status = $%d", *status)
+	}
+	return listPage(ctx, r.db, q, page, func(v models.Vehicle) Cursor {
+		return Cursor{Keys: []string{v.PlateNumber}, ID: v.ID}
+	}, false, "plate_number")
+}
+
+// Delete deletes a vehicle (soft delete) within the organization of ctx and
+// releases it from its driver
+func (r *VehicleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `
+		WITH deleted AS (
+			UPDATE vehicles SET is_active = false WHERE id = $1` + tenant + `
+			RETURNING id
+		), released AS (
+			UPDATE drivers SET vehicle_id = NULL, updated_at = CURRENT_TIMESTAMP
+			WHERE vehicle_id IN (SELECT id FROM deleted)
+		)
+		SELECT id FROM deleted`
+	var deleted uuid.UUID
+	err := r.db.GetContext(ctx, &deleted, query, append([]interface{}{id}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// ZoneRepository handles the zones of organizations and the zones drivers
+// cover. The boundary of a zone is kept as a native polygon next to the
+// JSONB ring, and every change of a boundary reassigns the bins of the
+// organization.
+type ZoneRepository struct {
+	db *DB
+}
+
+// NewZoneRepository creates a new ZoneRepository instance
+func NewZoneRepository(db *DB) *ZoneRepository {
+	return &ZoneRepository{db: db}
+}
+
+// Create creates a zone in the organization of ctx and moves the bins it
+// contains into it
+func (r *ZoneRepository) Create(ctx context.Context, zone *models.Zone) error {
+	assignOrganization(ctx, &zone.OrganizationID)
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		query := `
+			INSERT INTO zones (organization_id, name, kind, description, boundary, area)
+			VALUES ($1, $2, $3, $4, $5, $6::polygon)
+			RETURNING id`
+		if err := tx.QueryRowxContext(ctx, query,
+			zone.OrganizationID,
+			zone.Name,
+			zone.Kind,
+			zone.Description,
+			zone.Boundary,
+			zone.Boundary.Path(),
+		).Scan(&zone.ID); err != nil {
+			return err
+		}
+
+		if err := reassignBins(ctx, tx, zone.OrganizationID); err != nil {
+			return err
+		}
+		return reloadZone(ctx, tx, zone)
+	})
+}
+
+// GetByID retrieves a zone by ID within the organization of ctx
+func (r *ZoneRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Zone, error) {
+	var zone models.Zone
+	tenant, args := tenantCondition(ctx, "organization_id", 2)
+	query := `SELECT * FROM zone_details WHERE id = $1` + tenant
+
+	err := r.db.GetContext(ctx, &zone, query, append([]interface{}{id}, args...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &zone, err
+}
+
+// List retrieves the zones of the organization of ctx ordered by name
+func (r *ZoneRepository) List(ctx context.Context, page Page) ([]models.Zone, PageResult, error) {
+	q := &listQuery{from: "zone_details"}
+	q.tenant(ctx, "organization_id")
+	return listPage(ctx, r.db, q, page, func(z models.Zone) Cursor {
+		return Cursor{Keys: []string{z.Name}, ID: z.ID}
+	}, false, "name")
+}
+
+// Update updates a zone and reassigns the bins of its organization
+func (r *ZoneRepository) Update(ctx context.Context, zone *models.Zone) error {
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		query := `
+			UPDATE zones
+			SET name = $1, kind = $2, description = $3, boundary = $4, area = $5::polygon
+			WHERE id = $6`
+		if err := affected(tx.ExecContext(ctx, query,
+			zone.Name,
+			zone.Kind,
+			zone.Description,
+			zone.Boundary,
+			zone.Boundary.Path(),
+			zone.ID,
+		)); err != nil {
+			return err
+		}
+
+		if err := reassignBins(ctx, tx, zone.OrganizationID); err != nil {
+			return err
+		}
+		return reloadZone(ctx, tx, zone)
+	})
+}
+
+// Delete deletes a zone and moves its bins to the zone containing them
+// next, if any. Zones drivers still cover cannot be deleted.
+func (r *ZoneRepository) Delete(ctx context.Context, zone *models.Zone) error {
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if err := affected(tx.ExecContext(ctx, `DELETE FROM zones WHERE id = $1`, zone.ID)); err != nil {
+			return err
+		}
+		return reassignBins(ctx, tx, zone.OrganizationID)
+	})
+}
+
+// DriverZones retrieves the zones a driver covers, by name
+func (r *ZoneRepository) DriverZones(ctx context.Context, driverID uuid.UUID) ([]models.Zone, error) {
+	zones := []models.Zone{}
+	tenant, args := tenantCondition(ctx, "z.organization_id", 2)
+	query := `
+		SELECT z.* FROM zone_details z
+		JOIN driver_zones dz ON dz.zone_id = z.id
+		WHERE dz.driver_id = $1` + tenant + `
+		ORDER BY z.name, z.id`
+	err := r.db.SelectContext(ctx, &zones, query, append([]interface{}{driverID}, args...)...)
+	return zones, err
+}
+
+// SetDriverZones replaces the zones a driver covers. It returns ErrNotFound
+// when a zone is not one of the organization of the driver.
+func (r *ZoneRepository) SetDriverZones(ctx context.Context, driverID uuid.UUID, zoneIDs []uuid.UUID) error {
+	return withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM driver_zones WHERE driver_id = $1`, driverID); err != nil {
+			return err
+		}
+		if len(zoneIDs) == 0 {
+			return nil
+		}
+
+		query := `
+			INSERT INTO driver_zones (driver_id, zone_id)
+			SELECT d.id, z.id FROM drivers d
+			JOIN zones z ON z.organization_id = d.organization_id
+			WHERE d.id = $1 AND z.id = ANY($2)`
+		result, err := tx.ExecContext(ctx, query, driverID, pq.Array(zoneIDs))
+		if err != nil {
+			return err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n != int64(len(zoneIDs)) {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+// Coverage retrieves the zones of every driver restricted to some
+func (r *ZoneRepository) Coverage(ctx context.Context) (models.ZoneCoverage, error) {
+	var rows []struct {
+		DriverID uuid.UUID `db:"driver_id"`
+		ZoneID   uuid.UUID `db:"zone_id"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, `SELECT driver_id, zone_id FROM driver_zones`); err != nil {
+		return nil, err
+	}
+
+	coverage := make(models.ZoneCoverage)
+	for _, row := range rows {
+		coverage[row.DriverID] = append(coverage[row.DriverID], row.ZoneID)
+	}
+	return coverage, nil
+}
+
+// Analytics summarizes the zones of the organization of ctx, or only zoneID
+// when set, with the collections completed between from and to
+func (r *ZoneRepository) Analytics(ctx context.Context, from, to time.Time, zoneID *uuid.UUID) ([]models.ZoneAnalytics, error) {
+	analytics := []models.ZoneAnalytics{}
+	tenant, args := tenantCondition(ctx, "z.organization_id", 4)
+	query := `
+		SELECT
+			z.id AS zone_id, z.name, z.kind,
+			COUNT(b.id) AS bins,
+			COALESCE(AVG(b.fill_level), 0)::float8 AS average_fill_level,
+			COUNT(b.id) FILTER (WHERE b.status = 'active' AND b.fill_level >= b.collection_threshold) AS needs_collection,
+			COUNT(b.id) FILTER (WHERE b.status = 'maintenance') AS under_maintenance,
+			COUNT(b.id) FILTER (WHERE b.is_offline) AS offline,
+			COALESCE(c.collections, 0) AS collections,
+			COALESCE(c.collected_kg, 0)::float8 AS collected_kg
+		FROM zones z
+		LEFT JOIN bins b ON b.zone_id = z.id AND b.is_active = true
+		LEFT JOIN (
+			SELECT cb.zone_id, COUNT(*) AS collections, SUM(c.weight_kg) AS collected_kg
+			FROM collections c
+			JOIN bins cb ON cb.id = c.bin_id
+			WHERE c.status = 'completed' AND c.completed_at >= $1 AND c.completed_at < $2
+			GROUP BY cb.zone_id
+		) c ON c.zone_id = z.id
+		WHERE ($3::uuid IS NULL OR z.id = $3)` + tenant + `
+		GROUP BY z.id, c.collections, c.collected_kg
+		ORDER BY z.name, z.id`
+	err := r.db.SelectContext(ctx, &analytics, query, append([]interface{}{from, to, zoneID}, args...)...)
+	return analytics, err
+}
+
+// reassignBins moves every bin of an organization within tx to the zone
+// containing it
+func reassignBins(ctx context.Context, tx *sqlx.Tx, organizationID uuid.UUID) error {
+	query := `
+		UPDATE bins b
+		SET zone_id = z.zone_id
+		FROM (
+			SELECT id, bin_zone(organization_id, latitude, longitude) AS zone_id
+			FROM bins WHERE organization_id = $1
+		) z
+		WHERE b.id = z.id AND b.zone_id IS DISTINCT FROM z.zone_id`
+	_, err := tx.ExecContext(ctx, query, organizationID)
+	return err
+}
+
+// reloadZone refreshes zone from the database within tx
+func reloadZone(ctx context.Context, tx *sqlx.Tx, zone *models.Zone) error {
+	return tx.GetContext(ctx, zone, `SELECT * FROM zone_details WHERE id = $1`, zone.ID)
+}
@@ -4,26 +4,43 @@ import (
 	"context"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/auth"
+	"github.com/smartwaste/backend/internal/cache"
+	"github.com/smartwaste/backend/internal/config"
+	"github.com/smartwaste/backend/internal/models"
 	"github.com/smartwaste/backend/internal/repository"
 )
 
 // AnalyticsService handles analytics and reporting
 type AnalyticsService struct {
 	binRepo        *repository.BinRepository
+	statsRepo      *repository.DailyStatsRepository
 	collectionRepo *repository.CollectionRepository
 	driverRepo     *repository.DriverRepository
+	pricingRepo    *repository.PricingRepository
+	cache          cache.Cache
+	scoring        *config.DriverScoringConfig
 }
 
 // NewAnalyticsService creates a new AnalyticsService
 func NewAnalyticsService(
 	binRepo *repository.BinRepository,
+	statsRepo *repository.DailyStatsRepository,
 	collectionRepo *repository.CollectionRepository,
 	driverRepo *repository.DriverRepository,
+	pricingRepo *repository.PricingRepository,
+	c cache.Cache,
+	scoring *config.DriverScoringConfig,
 ) *AnalyticsService {
 	return &AnalyticsService{
 		binRepo:        binRepo,
+		statsRepo:      statsRepo,
 		collectionRepo: collectionRepo,
 		driverRepo:     driverRepo,
+		pricingRepo:    pricingRepo,
+		cache:          c,
+		scoring:        scoring,
 	}
 }
 
@@ -41,8 +58,20 @@ type DashboardStats struct {
 	CollectionStats     map[string]interface{} `json:"collection_stats,omitempty"`
 }
 
-// GetDashboardStats retrieves comprehensive dashboard statistics
+// GetDashboardStats retrieves comprehensive dashboard statistics of the
+// organization of ctx, served from the cache until it expires or a bin or
+// collection changes
 func (s *AnalyticsService) GetDashboardStats(ctx context.Context) (*DashboardStats, error) {
+	key := cache.KeyDashboardStats
+	if organizationID, ok := auth.OrganizationFromContext(ctx); ok {
+		key = cache.OrganizationKey(organizationID, key)
+	}
+	return cache.Fetch(ctx, s.cache, key, func() (*DashboardStats, error) {
+		return s.loadDashboardStats(ctx)
+	})
+}
+
+func (s *AnalyticsService) loadDashboardStats(ctx context.Context) (*DashboardStats, error) {
 	stats := &DashboardStats{
 		Timestamp: time.Now(),
 	}
@@ -79,11 +108,14 @@ func (s *AnalyticsService) GetDashboardStats(ctx context.Context) (*DashboardSta
 
 // BinAnalytics represents bin-specific analytics
 type BinAnalytics struct {
-	TotalBins         int              `json:"total_bins"`
-	ActiveBins        int              `json:"active_bins"`
-	AverageFillLevel  float64          `json:"average_fill_level"`
-	BinsByFillRange   []FillRangeCount `json:"bins_by_fill_range"`
-	BinsNeedingAction int              `json:"bins_needing_action"`
+	TotalBins         int                     `json:"total_bins"`
+	ActiveBins        int                     `json:"active_bins"`
+	AverageFillLevel  float64                 `json:"average_fill_level"`
+	BinsByFillRange   []FillRangeCount        `json:"bins_by_fill_range"`
+	BinsNeedingAction int                     `json:"bins_needing_action"` // At or above their collection threshold
+	BinsOverAlert     int                     `json:"bins_over_alert"`     // At or above their alert threshold
+	Period            models.AnalyticsRange   `json:"period"`
+	Series            []models.BinSeriesPoint `json:"series"`
 }
 
 // FillRangeCount represents count of bins in a fill level range
@@ -92,23 +124,31 @@ type FillRangeCount struct {
 	Count int    `json:"count"`
 }
 
-// GetBinAnalytics retrieves bin-specific analytics
-func (s *AnalyticsService) GetBinAnalytics(ctx context.Context) (*BinAnalytics, error) {
+// GetBinAnalytics retrieves bin-specific analytics with the fill-level series of the period
+func (s *AnalyticsService) GetBinAnalytics(ctx context.Context, period models.AnalyticsRange) (*BinAnalytics, error) {
 	stats, err := s.binRepo.GetStatistics(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	series, err := s.statsRepo.BinSeries(ctx, period)
+	if err != nil {
+		return nil, err
+	}
+
 	return &BinAnalytics{
+		Period:            period,
+		Series:            series,
 		TotalBins:         stats["total_bins"].(int),
 		ActiveBins:        stats["total_bins"].(int), // Same for now
 		AverageFillLevel:  stats["average_fill_level"].(float64),
 		BinsNeedingAction: stats["needs_collection"].(int),
+		BinsOverAlert:     stats["needs_alert"].(int),
 		BinsByFillRange: []FillRangeCount{
-			{Range: "0-25%", Count: 0},   // Would query from DB
-			{Range: "26-50%", Count: 0},  // Would query from DB
-			{Range: "51-75%", Count: 0},  // Would query from DB
-			{Range: "76-100%", Count: stats["needs_collection"].(int)},
+			{Range: "0-25%", Count: stats["fill_0_25"].(int)},
+			{Range: "26-50%", Count: stats["fill_26_50"].(int)},
+			{Range: "51-75%", Count: stats["fill_51_75"].(int)},
+			{Range: "76-100%", Count: stats["fill_76_100"].(int)},
 		},
 	}, nil
 }
@@ -124,7 +164,7 @@ type DriverPerformance struct {
 
 // GetDriverAnalytics retrieves driver-specific analytics
 func (s *AnalyticsService) GetDriverAnalytics(ctx context.Context) (*DriverPerformance, error) {
-	drivers, err := s.driverRepo.List(ctx, 1000, 0)
+	drivers, _, err := s.driverRepo.List(ctx, repository.Page{Limit: 1000})
 	if err != nil {
 		return nil, err
 	}
@@ -159,24 +199,91 @@ func (s *AnalyticsService) GetDriverAnalytics(ctx context.Context) (*DriverPerfo
 
 // CollectionAnalytics represents collection analytics
 type CollectionAnalytics struct {
-	TodayCollections    int     `json:"today_collections"`
-	WeekCollections     int     `json:"week_collections"`
-	MonthCollections    int     `json:"month_collections"`
-	TotalWeightToday    float64 `json:"total_weight_today_kg"`
-	TotalWeightMonth    float64 `json:"total_weight_month_kg"`
-	AverageCollectionTime string `json:"average_collection_time"`
+	TodayCollections      int                            `json:"today_collections"`
+	WeekCollections       int                            `json:"week_collections"`
+	MonthCollections      int                            `json:"month_collections"`
+	TotalWeightToday      float64                        `json:"total_weight_today_kg"`
+	TotalWeightMonth      float64                        `json:"total_weight_month_kg"`
+	AverageCollectionTime string                         `json:"average_collection_time"`
+	Period                models.AnalyticsRange          `json:"period"`
+	Series                []models.CollectionSeriesPoint `json:"series"`
 }
 
-// GetCollectionAnalytics retrieves collection analytics
-func (s *AnalyticsService) GetCollectionAnalytics(ctx context.Context) (*CollectionAnalytics, error) {
+// GetCollectionAnalytics retrieves collection analytics with the collection series of the period
+func (s *AnalyticsService) GetCollectionAnalytics(ctx context.Context, period models.AnalyticsRange) (*CollectionAnalytics, error) {
 	stats, err := s.collectionRepo.GetCollectionStats(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	series, err := s.statsRepo.CollectionSeries(ctx, period)
+	if err != nil {
+		return nil, err
+	}
+
 	return &CollectionAnalytics{
+		Period:           period,
+		Series:           series,
 		TodayCollections: stats["today_collections"].(int),
 		MonthCollections: stats["month_collections"].(int),
 		TotalWeightToday: stats["today_weight_kg"].(float64),
 	}, nil
 }
+
+// CompanyAnalytics summarizes the bins, collections and recovered waste of one company
+type CompanyAnalytics struct {
+	CompanyID         uuid.UUID                      `json:"company_id"`
+	Period            models.AnalyticsRange          `json:"period"`
+	Bins              repository.BinStatistics       `json:"bins"`
+	Collections       CompanyCollectionTotals        `json:"collections"`
+	WeightByWasteType []models.WasteTypeWeight       `json:"weight_by_waste_type"`
+	Valuations        []models.ValuationTotal        `json:"valuations"`
+	Series            []models.CollectionSeriesPoint `json:"series"`
+}
+
+// CompanyCollectionTotals totals a company's collections over the period
+type CompanyCollectionTotals struct {
+	Collections int     `json:"collections"`
+	Completed   int     `json:"completed"`
+	Cancelled   int     `json:"cancelled"`
+	WeightKg    float64 `json:"weight_kg"`
+}
+
+// GetCompanyAnalytics retrieves the analytics of a company's bins over the period
+func (s *AnalyticsService) GetCompanyAnalytics(ctx context.Context, companyID uuid.UUID, period models.AnalyticsRange) (*CompanyAnalytics, error) {
+	bins, err := s.binRepo.CompanyStatistics(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	series, err := s.collectionRepo.Series(ctx, period, &companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	weights, err := s.collectionRepo.WeightByWasteType(ctx, repository.WeightScope{CompanyID: &companyID}, period)
+	if err != nil {
+		return nil, err
+	}
+
+	valuations, err := s.pricingRepo.ValuationTotals(ctx, companyID, period)
+	if err != nil {
+		return nil, err
+	}
+
+	analytics := &CompanyAnalytics{
+		CompanyID:         companyID,
+		Period:            period,
+		Bins:              bins,
+		WeightByWasteType: weights,
+		Valuations:        valuations,
+		Series:            series,
+	}
+	for _, point := range series {
+		analytics.Collections.Collections += point.Collections
+		analytics.Collections.Completed += point.Completed
+		analytics.Collections.Cancelled += point.Cancelled
+		analytics.Collections.WeightKg += point.WeightKg
+	}
+	return analytics, nil
+}
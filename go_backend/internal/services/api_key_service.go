@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/auth"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+)
+
+// ErrInvalidAPIKey is returned when an API key is unknown, revoked or expired
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// rateLimitWindow is the period API key rate limits are counted over
+const rateLimitWindow = time.Minute
+
+// APIKeyService issues API keys and authenticates and rate limits requests made with them
+type APIKeyService struct {
+	repo    *repository.APIKeyRepository
+	limiter *windowLimiter
+}
+
+// NewAPIKeyService creates a new APIKeyService
+func NewAPIKeyService(repo *repository.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{
+		repo:    repo,
+		limiter: newWindowLimiter(rateLimitWindow),
+	}
+}
+
+// Issue creates a new API key. The plaintext key is only available in the
+// returned response.
+func (s *APIKeyService) Issue(ctx context.Context, req *models.CreateAPIKeyRequest, createdBy *uuid.UUID) (*models.CreateAPIKeyResponse, error) {
+	plaintext, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	key := models.APIKey{
+		Name:               req.Name,
+		KeyPrefix:          prefix,
+		KeyHash:            hash,
+		Role:               req.Role,
+		CompanyID:          req.CompanyID,
+		RateLimitPerMinute: models.DefaultAPIKeyRateLimit,
+		CreatedBy:          createdBy,
+		ExpiresAt:          req.ExpiresAt,
+	}
+	if req.RateLimitPerMinute != nil {
+		key.RateLimitPerMinute = *req.RateLimitPerMinute
+	}
+
+	if err := s.repo.Create(ctx, &key); err != nil {
+		return nil, err
+	}
+	return &models.CreateAPIKeyResponse{APIKey: key, Key: plaintext}, nil
+}
+
+// Authenticate returns the usable key matching the plaintext value
+func (s *APIKeyService) Authenticate(ctx context.Context, plaintext string) (*models.APIKey, error) {
+	key, err := s.repo.GetByHash(ctx, auth.HashAPIKey(plaintext))
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || !key.IsUsable(time.Now()) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if err := s.repo.TouchLastUsed(ctx, key.ID); err != nil {
+		log.Printf("Failed to record use of API key %s: %v", key.ID, err)
+	}
+	return key, nil
+}
+
+// Allow counts a request against the key's rate limit. It returns the
+// requests left in the current window, or false and the time until the
+// window resets when the limit is exhausted.
+func (s *APIKeyService) Allow(key *models.APIKey) (remaining int, retryAfter time.Duration, ok bool) {
+	return s.limiter.allow(key.ID, key.RateLimitPerMinute, time.Now())
+}
+
+// windowLimiter counts requests per key in fixed windows. Counts are kept in
+// memory, so each backend instance enforces the limit on its own.
+type windowLimiter struct {
+	window  time.Duration
+	mu      sync.Mutex
+	windows map[uuid.UUID]*limitWindow
+}
+
+type limitWindow struct {
+	start time.Time
+	count int
+}
+
+func newWindowLimiter(window time.Duration) *windowLimiter {
+	return &windowLimiter{
+		window:  window,
+		windows: make(map[uuid.UUID]*limitWindow),
+	}
+}
+
+func (l *windowLimiter) allow(id uuid.UUID, limit int, now time.Time) (int, time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	w, ok := l.windows[id]
+	if !ok || now.Sub(w.start) >= l.window {
+		l.prune(now)
+		w = &limitWindow{start: now.Truncate(l.window)}
+		l.windows[id] = w
+	}
+
+	if w.count >= limit {
+		return 0, w.start.Add(l.window).Sub(now), false
+	}
+	w.count++
+	return limit - w.count, 0, true
+}
+
+// prune drops windows that have ended so revoked keys do not linger
+func (l *windowLimiter) prune(now time.Time) {
+	for id, w := range l.windows {
+		if now.Sub(w.start) >= l.window {
+			delete(l.windows, id)
+		}
+	}
+}
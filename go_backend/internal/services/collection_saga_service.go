@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smartwaste/backend/internal/auth"
+	"github.com/smartwaste/backend/internal/config"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+)
+
+const (
+	// sagaStepLease is how long a claimed saga is left alone by the other
+	// instances, longer than a step takes
+	sagaStepLease = 2 * time.Minute
+	// sagaBatchSize bounds the sagas resumed per run
+	sagaBatchSize = 50
+)
+
+// CollectionSagaService keeps completed collections and their shipments
+// consistent. Completing a collection with a citizen and a weight creates the
+// shipment of its waste in the shipment tracker, retried with backoff; if the
+// tracker refuses it, retries run out or the shipment is later cancelled, the
+// collection is reopened and its reward taken back to compensate.
+type CollectionSagaService struct {
+	cfg            *config.SagaConfig
+	sagaRepo       *repository.CollectionSagaRepository
+	collectionRepo *repository.CollectionRepository
+	binRepo        *repository.BinRepository
+	valuationSvc   *ValuationService
+	rewardSvc      *RewardService
+	tracker        *ShipmentTrackerClient
+}
+
+// NewCollectionSagaService creates a new CollectionSagaService
+func NewCollectionSagaService(
+	cfg *config.SagaConfig,
+	sagaRepo *repository.CollectionSagaRepository,
+	collectionRepo *repository.CollectionRepository,
+	binRepo *repository.BinRepository,
+	valuationSvc *ValuationService,
+	rewardSvc *RewardService,
+	tracker *ShipmentTrackerClient,
+) *CollectionSagaService {
+	return &CollectionSagaService{
+		cfg:            cfg,
+		sagaRepo:       sagaRepo,
+		collectionRepo: collectionRepo,
+		binRepo:        binRepo,
+		valuationSvc:   valuationSvc,
+		rewardSvc:      rewardSvc,
+		tracker:        tracker,
+	}
+}
+
+// Plan prices the shipment of a collection about to be completed with
+// weightKg. It returns nil if the collection is not shipped: it has no
+// citizen, no weight or its waste is worth nothing.
+func (s *CollectionSagaService) Plan(ctx context.Context, collection *models.Collection, bin *models.Bin, weightKg *float64) (*models.CollectionSaga, error) {
+	if collection.UserID == nil || weightKg == nil || *weightKg <= 0 {
+		return nil, nil
+	}
+
+	valuation, err := s.valuationSvc.CalculateValue(ctx, &models.ValuationRequest{
+		WasteType: bin.WasteType,
+		Condition: models.ConditionAny,
+		WeightKg:  *weightKg,
+		CompanyID: bin.CompanyID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to price shipment: %w", err)
+	}
+	if valuation.TotalPrice <= 0 {
+		log.Printf("Not shipping collection %s: its %s waste is worth nothing", collection.ID, bin.WasteType)
+		return nil, nil
+	}
+
+	return &models.CollectionSaga{
+		OrganizationID: collection.OrganizationID,
+		CollectionID:   collection.ID,
+		UserID:         *collection.UserID,
+		WasteType:      bin.WasteType,
+		WeightKg:       *weightKg,
+		PriceOffered:   valuation.TotalPrice,
+	}, nil
+}
+
+// Begin records a planned saga in the transaction completing its collection,
+// so the collection is not completed without it. The first step is left to
+// the caller to Advance once committed; the job only takes it over if that
+// does not finish within the step lease.
+func (s *CollectionSagaService) Begin(ctx context.Context, tx *sqlx.Tx, saga *models.CollectionSaga) error {
+	saga.NextAttemptAt = time.Now().Add(sagaStepLease)
+	if _, err := s.sagaRepo.Tx(tx).Create(ctx, saga); err != nil {
+		return fmt.Errorf("failed to start collection saga: %w", err)
+	}
+	return nil
+}
+
+// Advance runs the due step of a saga, scheduling its retry if it fails
+func (s *CollectionSagaService) Advance(ctx context.Context, saga *models.CollectionSaga) error {
+	switch saga.State {
+	case models.SagaCreatingShipment:
+		if err := s.createShipment(ctx, saga); err != nil {
+			if errors.Is(err, ErrTrackerRejected) || saga.Attempts+1 >= s.cfg.MaxAttempts {
+				log.Printf("Giving up on the shipment of collection %s, reopening it: %v", saga.CollectionID, err)
+				s.compensate(saga, err.Error())
+				return s.Advance(ctx, saga)
+			}
+			return s.retry(ctx, saga, err)
+		}
+	case models.SagaCompensating:
+		// Both are idempotent, so a retry redoes whichever did not finish
+		orgCtx := auth.WithOrganization(ctx, saga.OrganizationID)
+		reopened, err := s.collectionRepo.Reopen(orgCtx, saga.CollectionID)
+		if err != nil {
+			return s.retry(ctx, saga, fmt.Errorf("failed to reopen collection: %w", err))
+		}
+		if reopened {
+			log.Printf("Reopened collection %s", saga.CollectionID)
+		}
+		if _, err := s.rewardSvc.ReverseCollection(orgCtx, saga.CollectionID); err != nil {
+			return s.retry(ctx, saga, err)
+		}
+		saga.State = models.SagaCompensated
+	default:
+		return nil
+	}
+
+	saga.Attempts = 0
+	if err := s.sagaRepo.Save(ctx, saga); err != nil {
+		return fmt.Errorf("failed to save collection saga: %w", err)
+	}
+	return nil
+}
+
+// OnShipmentCancelled reopens the collection a cancelled shipment was created
+// for. Shipments no saga created are ignored.
+func (s *CollectionSagaService) OnShipmentCancelled(ctx context.Context, shipmentID uuid.UUID) error {
+	saga, err := s.sagaRepo.GetByShipment(ctx, shipmentID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch collection saga: %w", err)
+	}
+	if saga == nil || saga.State != models.SagaShipmentCreated {
+		return nil
+	}
+
+	s.compensate(saga, "shipment cancelled")
+	return s.Advance(ctx, saga)
+}
+
+// ResumeDue runs the steps due across organizations, returning how many
+// sagas were resumed
+func (s *CollectionSagaService) ResumeDue(ctx context.Context) (int, error) {
+	sagas, err := s.sagaRepo.ClaimDue(ctx, sagaStepLease, sagaBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim collection sagas: %w", err)
+	}
+	for i := range sagas {
+		if err := s.Advance(ctx, &sagas[i]); err != nil {
+			log.Printf("Collection saga %s failed: %v", sagas[i].ID, err)
+		}
+	}
+	return len(sagas), nil
+}
+
+// createShipment creates the shipment of a saga, unless an earlier attempt
+// that seemed to fail did
+func (s *CollectionSagaService) createShipment(ctx context.Context, saga *models.CollectionSaga) error {
+	if saga.Attempts > 0 {
+		existing, err := s.tracker.FindByCollection(ctx, saga.OrganizationID, saga.CollectionID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			s.created(saga, existing)
+			return nil
+		}
+	}
+
+	req := &CreateTrackerShipment{
+		UserID:            saga.UserID,
+		CollectionID:      saga.CollectionID,
+		WasteType:         saga.WasteType,
+		EstimatedWeightKg: saga.WeightKg,
+		PriceOffered:      saga.PriceOffered,
+	}
+	collection, err := s.collectionRepo.GetByID(auth.WithOrganization(ctx, saga.OrganizationID), saga.CollectionID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch collection: %w", err)
+	}
+	if collection != nil {
+		if bin, err := s.binRepo.GetByID(auth.WithOrganization(ctx, saga.OrganizationID), collection.BinID); err != nil {
+			return fmt.Errorf("failed to fetch bin: %w", err)
+		} else if bin != nil {
+			req.PickupLocation = &TrackerLocation{Latitude: bin.Latitude, Longitude: bin.Longitude}
+			if bin.LocationName != nil {
+				req.PickupLocation.Address = *bin.LocationName
+			}
+		}
+	}
+
+	shipment, err := s.tracker.CreateShipment(ctx, saga.OrganizationID, req)
+	if err != nil {
+		return err
+	}
+	s.created(saga, shipment)
+	return nil
+}
+
+func (s *CollectionSagaService) created(saga *models.CollectionSaga, shipment *TrackerShipment) {
+	saga.ShipmentID = &shipment.ID
+	saga.State = models.SagaShipmentCreated
+	saga.LastError = nil
+	log.Printf("Created shipment %s for collection %s", shipment.ID, saga.CollectionID)
+}
+
+// compensate moves a saga to reopening its collection, due immediately
+func (s *CollectionSagaService) compensate(saga *models.CollectionSaga, reason string) {
+	saga.State = models.SagaCompensating
+	saga.Attempts = 0
+	saga.NextAttemptAt = time.Now()
+	saga.LastError = &reason
+}
+
+// retry records the failure of a step and schedules it again, backing off
+// exponentially from the retry interval up to the max backoff
+func (s *CollectionSagaService) retry(ctx context.Context, saga *models.CollectionSaga, cause error) error {
+	backoff := s.cfg.RetryInterval
+	for i := 0; i < saga.Attempts && backoff < s.cfg.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > s.cfg.MaxBackoff {
+		backoff = s.cfg.MaxBackoff
+	}
+
+	reason := cause.Error()
+	saga.Attempts++
+	saga.NextAttemptAt = time.Now().Add(backoff)
+	saga.LastError = &reason
+	if err := s.sagaRepo.Save(ctx, saga); err != nil {
+		return fmt.Errorf("failed to save collection saga: %w", err)
+	}
+	return cause
+}
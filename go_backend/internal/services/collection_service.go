@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smartwaste/backend/internal/config"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+)
+
+// Collection verification errors; the handlers map each to a response
+var (
+	ErrDriverLocationUnknown = errors.New("the location of the driver is unknown")
+	ErrTooFarFromBin         = errors.New("driver is too far from the bin")
+)
+
+// CollectionService handles the collection lifecycle writes that span several tables
+type CollectionService struct {
+	collectionRepo *repository.CollectionRepository
+	binRepo        *repository.BinRepository
+	driverRepo     *repository.DriverRepository
+	rewardSvc      *RewardService
+	earningSvc     *EarningService
+	sagaSvc        *CollectionSagaService
+	uploadSvc      *UploadService
+	config         *config.CollectionConfig
+}
+
+// NewCollectionService creates a new CollectionService
+func NewCollectionService(
+	collectionRepo *repository.CollectionRepository,
+	binRepo *repository.BinRepository,
+	driverRepo *repository.DriverRepository,
+	rewardSvc *RewardService,
+	earningSvc *EarningService,
+	sagaSvc *CollectionSagaService,
+	uploadSvc *UploadService,
+	cfg *config.CollectionConfig,
+) *CollectionService {
+	return &CollectionService{
+		collectionRepo: collectionRepo,
+		binRepo:        binRepo,
+		driverRepo:     driverRepo,
+		rewardSvc:      rewardSvc,
+		earningSvc:     earningSvc,
+		sagaSvc:        sagaSvc,
+		uploadSvc:      uploadSvc,
+		config:         cfg,
+	}
+}
+
+// VerifyLocation checks that the driver scanning the QR code of a collection
+// is within COLLECTION_VERIFY_RADIUS_M of its bin, at the reported location or
+// else their last known one, then marks the collection verified with the
+// distance. Without a radius the distance is only recorded when known.
+func (s *CollectionService) VerifyLocation(ctx context.Context, collection *models.Collection, latitude, longitude *float64) (*float64, error) {
+	if latitude == nil || longitude == nil {
+		driver, err := s.driverRepo.GetByID(ctx, collection.DriverID)
+		if err != nil {
+			return nil, err
+		}
+		if driver != nil {
+			latitude, longitude = driver.Latitude, driver.Longitude
+		}
+	}
+
+	var distance *float64
+	if latitude != nil && longitude != nil {
+		bin, err := s.binRepo.GetByID(ctx, collection.BinID)
+		if err != nil {
+			return nil, err
+		}
+		if bin == nil {
+			return nil, ErrBinNotFound
+		}
+		meters := math.Round(haversineDistance(*latitude, *longitude, bin.Latitude, bin.Longitude)*10000) / 10
+		distance = &meters
+	}
+
+	if radius := s.config.VerifyRadiusMeters; radius > 0 {
+		if distance == nil {
+			return nil, ErrDriverLocationUnknown
+		}
+		if *distance > float64(radius) {
+			return distance, fmt.Errorf("%w: scanned %.0f m away, the limit is %d m", ErrTooFarFromBin, *distance, radius)
+		}
+	}
+
+	if err := s.collectionRepo.VerifyQRCode(ctx, collection.ID, distance); err != nil {
+		return nil, err
+	}
+	return distance, nil
+}
+
+// Complete completes a collection, empties its bin and counts it for the
+// driver in a single transaction, then credits the attached user. Unless the
+// sagas are disabled, the transaction also starts shipping the waste of a
+// citizen's collection to the shipment tracker. Photos in the upload bucket
+// must have been uploaded by the driver of the collection.
+func (s *CollectionService) Complete(ctx context.Context, collection *models.Collection, req *models.CompleteCollectionRequest) (*models.Collection, error) {
+	var photos []*models.Upload
+	for _, url := range []*string{req.BeforePhotoURL, req.AfterPhotoURL} {
+		if url == nil {
+			continue
+		}
+		photo, err := s.uploadSvc.Resolve(ctx, *url, collection.DriverID)
+		if err != nil {
+			return nil, err
+		}
+		photos = append(photos, photo)
+	}
+
+	var saga *models.CollectionSaga
+	if s.sagaSvc != nil {
+		bin, err := s.binRepo.GetByID(ctx, collection.BinID)
+		if err != nil {
+			return nil, err
+		}
+		if bin != nil {
+			if saga, err = s.sagaSvc.Plan(ctx, collection, bin, req.WeightKg); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	err := s.collectionRepo.WithTx(ctx, func(tx *sqlx.Tx) error {
+		if err := s.collectionRepo.Tx(tx).Complete(ctx, collection.ID, req); err != nil {
+			return err
+		}
+		if err := s.binRepo.Tx(tx).MarkCollected(ctx, collection.BinID); err != nil {
+			return err
+		}
+		if err := s.driverRepo.Tx(tx).IncrementCollections(ctx, collection.DriverID); err != nil {
+			return err
+		}
+		if saga != nil {
+			return s.sagaSvc.Begin(ctx, tx, saga)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.binRepo.InvalidateCache(ctx, collection.OrganizationID)
+	for _, photo := range photos {
+		s.uploadSvc.Attach(ctx, photo)
+	}
+
+	// A failed first attempt is retried by the collection saga job
+	if saga != nil && saga.ID != uuid.Nil {
+		if err := s.sagaSvc.Advance(ctx, saga); err != nil {
+			log.Printf("Failed to ship collection %s, retrying later: %v", collection.ID, err)
+		}
+	}
+
+	updated, err := s.collectionRepo.GetByID(ctx, collection.ID)
+	if err != nil || updated == nil {
+		return updated, err
+	}
+
+	// The collection is already committed, so a crediting failure is only logged
+	if bin, err := s.binRepo.GetByID(ctx, updated.BinID); err != nil || bin == nil {
+		log.Printf("Skipping reward for collection %s: bin lookup failed: %v", updated.ID, err)
+	} else if _, err := s.rewardSvc.CreditCollection(ctx, updated, bin.WasteType); err != nil {
+		log.Printf("Failed to credit reward for collection %s: %v", updated.ID, err)
+	}
+	if _, err := s.earningSvc.CreditCollection(ctx, updated); err != nil {
+		log.Printf("Failed to credit driver earning for collection %s: %v", updated.ID, err)
+	}
+
+	return updated, nil
+}
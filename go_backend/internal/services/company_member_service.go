@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/auth"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/backend/pkg/security"
+)
+
+// Company member errors
+var (
+	ErrInvalidInvite   = errors.New("invite is unknown, revoked, accepted or expired")
+	ErrEmailRegistered = errors.New("email is already registered")
+	ErrInvitePending   = errors.New("an invite is already pending for this email")
+	ErrLastOwner       = errors.New("a company needs at least one owner")
+)
+
+// CompanyMemberService invites company members and manages their roles
+type CompanyMemberService struct {
+	repo     *repository.CompanyMemberRepository
+	userRepo *repository.UserRepository
+	hasher   security.PasswordHasher
+	ttl      time.Duration
+}
+
+// NewCompanyMemberService creates a new CompanyMemberService; invites can be
+// accepted for ttl after they are created
+func NewCompanyMemberService(repo *repository.CompanyMemberRepository, userRepo *repository.UserRepository, hasher security.PasswordHasher, ttl time.Duration) *CompanyMemberService {
+	return &CompanyMemberService{repo: repo, userRepo: userRepo, hasher: hasher, ttl: ttl}
+}
+
+// Invite invites an email address to join the company. The plaintext token
+// is only available in the returned response.
+func (s *CompanyMemberService) Invite(ctx context.Context, companyID uuid.UUID, req *models.CreateInviteRequest, invitedBy *uuid.UUID) (*models.CreateInviteResponse, error) {
+	email := strings.TrimSpace(req.Email)
+	existing, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrEmailRegistered
+	}
+
+	token, hash, err := auth.GenerateInviteToken()
+	if err != nil {
+		return nil, err
+	}
+
+	invite := models.CompanyInvite{
+		CompanyID: companyID,
+		Email:     email,
+		Role:      req.Role,
+		TokenHash: hash,
+		InvitedBy: invitedBy,
+		ExpiresAt: time.Now().Add(s.ttl),
+	}
+	if err := s.repo.CreateInvite(ctx, &invite); err != nil {
+		if repository.IsUniqueViolation(err) {
+			return nil, ErrInvitePending
+		}
+		return nil, err
+	}
+	return &models.CreateInviteResponse{CompanyInvite: invite, Token: token}, nil
+}
+
+// Accept accepts an invite, creating the invitee's account with the company
+// role and its membership
+func (s *CompanyMemberService) Accept(ctx context.Context, req *models.AcceptInviteRequest) (*models.CompanyMember, error) {
+	invite, err := s.repo.GetInviteByHash(ctx, auth.HashInviteToken(strings.TrimSpace(req.Token)))
+	if err != nil {
+		return nil, err
+	}
+	if invite == nil || !invite.IsPending(time.Now()) {
+		return nil, ErrInvalidInvite
+	}
+
+	existing, err := s.userRepo.GetByEmail(ctx, invite.Email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrEmailRegistered
+	}
+
+	passwordHash, err := s.hasher.HashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		Email:        invite.Email,
+		PasswordHash: passwordHash,
+		FullName:     strings.TrimSpace(req.FullName),
+		Phone:        req.Phone,
+		Role:         models.RoleCompany,
+	}
+	member := &models.CompanyMember{
+		CompanyID: invite.CompanyID,
+		Role:      invite.Role,
+		Email:     user.Email,
+		FullName:  user.FullName,
+	}
+
+	accepted, err := s.repo.Accept(ctx, invite, user, member)
+	if err != nil {
+		if repository.IsUniqueViolation(err) {
+			return nil, ErrEmailRegistered
+		}
+		return nil, err
+	}
+	if !accepted {
+		return nil, ErrInvalidInvite
+	}
+	return member, nil
+}
+
+// ChangeRole changes a member's role, keeping at least one owner
+func (s *CompanyMemberService) ChangeRole(ctx context.Context, member *models.CompanyMember, role models.CompanyMemberRole) error {
+	if member.Role == models.CompanyOwner && role != models.CompanyOwner {
+		if err := s.checkNotLastOwner(ctx, member); err != nil {
+			return err
+		}
+	}
+
+	member.Role = role
+	return s.repo.UpdateRole(ctx, member)
+}
+
+// Remove removes a member from their company, keeping at least one owner
+func (s *CompanyMemberService) Remove(ctx context.Context, member *models.CompanyMember) error {
+	if member.Role == models.CompanyOwner {
+		if err := s.checkNotLastOwner(ctx, member); err != nil {
+			return err
+		}
+	}
+	return s.repo.Remove(ctx, member)
+}
+
+// checkNotLastOwner returns ErrLastOwner if the member is their company's only owner
+func (s *CompanyMemberService) checkNotLastOwner(ctx context.Context, member *models.CompanyMember) error {
+	owners, err := s.repo.CountOwners(ctx, member.CompanyID)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+)
+
+var (
+	// ErrDeviceDecommissioned is returned when changing a retired device
+	ErrDeviceDecommissioned = errors.New("device is decommissioned")
+	// ErrDeviceAlreadyAssigned is returned when installing a device on the bin it serves
+	ErrDeviceAlreadyAssigned = errors.New("device is already installed on this bin")
+	// ErrBinNotFound is returned when the bin to install a device on does not exist
+	ErrBinNotFound = errors.New("bin not found")
+)
+
+// DeviceService manages the sensor units of the fleet and the bins they serve
+type DeviceService struct {
+	deviceRepo *repository.DeviceRepository
+	binRepo    *repository.BinRepository
+}
+
+// NewDeviceService creates a new DeviceService
+func NewDeviceService(deviceRepo *repository.DeviceRepository, binRepo *repository.BinRepository) *DeviceService {
+	return &DeviceService{
+		deviceRepo: deviceRepo,
+		binRepo:    binRepo,
+	}
+}
+
+// Register registers a device, installing it on the requested bin at once
+func (s *DeviceService) Register(ctx context.Context, req *models.CreateDeviceRequest, assignedBy *uuid.UUID) (*models.Device, error) {
+	device := &models.Device{
+		HardwareSerial:  req.HardwareSerial,
+		Model:           req.Model,
+		FirmwareVersion: req.FirmwareVersion,
+		Connectivity:    req.Connectivity,
+		SimICCID:        req.SimICCID,
+		Carrier:         req.Carrier,
+		APN:             req.APN,
+		Notes:           req.Notes,
+	}
+	if device.Connectivity == "" {
+		device.Connectivity = models.ConnectivityWiFi
+	}
+
+	var bin *models.Bin
+	if req.BinID != nil {
+		var err error
+		if bin, err = s.loadBin(ctx, *req.BinID); err != nil {
+			return nil, err
+		}
+		device.OrganizationID = bin.OrganizationID
+	}
+
+	if err := s.deviceRepo.Create(ctx, device, req.BinID, assignedBy); err != nil {
+		return nil, err
+	}
+	if bin != nil {
+		s.binRepo.InvalidateCache(ctx, bin.OrganizationID)
+	}
+	return device, nil
+}
+
+// Get retrieves a device
+func (s *DeviceService) Get(ctx context.Context, id uuid.UUID) (*models.Device, error) {
+	return s.deviceRepo.GetByID(ctx, id)
+}
+
+// List retrieves devices ordered by hardware serial
+func (s *DeviceService) List(ctx context.Context, filter models.DeviceFilter, page repository.Page) ([]models.Device, repository.PageResult, error) {
+	return s.deviceRepo.ListFiltered(ctx, filter, page)
+}
+
+// Update saves the details of a device
+func (s *DeviceService) Update(ctx context.Context, device *models.Device) error {
+	if device.Status == models.DeviceDecommissioned {
+		return ErrDeviceDecommissioned
+	}
+	return s.deviceRepo.Update(ctx, device)
+}
+
+// Assign installs a device on a bin of its organization. The device the bin
+// had goes back to stock, and the sensor has to provision itself on its new bin.
+func (s *DeviceService) Assign(ctx context.Context, device *models.Device, req *models.AssignDeviceRequest, assignedBy *uuid.UUID) error {
+	if device.Status == models.DeviceDecommissioned {
+		return ErrDeviceDecommissioned
+	}
+	if device.BinID != nil && *device.BinID == req.BinID {
+		return ErrDeviceAlreadyAssigned
+	}
+	bin, err := s.loadBin(ctx, req.BinID)
+	if err != nil {
+		return err
+	}
+	// Unscoped callers still keep a device within its organization
+	if bin.OrganizationID != device.OrganizationID {
+		return ErrBinNotFound
+	}
+
+	err = s.deviceRepo.Assign(ctx, device, req.BinID, assignedBy, req.Reason)
+	if errors.Is(err, repository.ErrNotFound) {
+		// Decommissioned concurrently
+		return ErrDeviceDecommissioned
+	}
+	if err != nil {
+		return err
+	}
+	s.binRepo.InvalidateCache(ctx, device.OrganizationID)
+	return nil
+}
+
+// Decommission retires a device, removing it from the bin it serves
+func (s *DeviceService) Decommission(ctx context.Context, device *models.Device, reason *string) error {
+	if device.Status == models.DeviceDecommissioned {
+		return ErrDeviceDecommissioned
+	}
+	wasInstalled := device.BinID != nil
+
+	err := s.deviceRepo.Decommission(ctx, device, reason)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrDeviceDecommissioned
+	}
+	if err != nil {
+		return err
+	}
+	if wasInstalled {
+		s.binRepo.InvalidateCache(ctx, device.OrganizationID)
+	}
+	return nil
+}
+
+// Assignments retrieves the bins a device served, most recent first
+func (s *DeviceService) Assignments(ctx context.Context, deviceID uuid.UUID) ([]models.DeviceAssignment, error) {
+	return s.deviceRepo.Assignments(ctx, deviceID)
+}
+
+// BinAssignments retrieves the devices that served a bin, most recent first
+func (s *DeviceService) BinAssignments(ctx context.Context, binID uuid.UUID) ([]models.DeviceAssignment, error) {
+	return s.deviceRepo.BinAssignments(ctx, binID)
+}
+
+// loadBin retrieves an active bin within the organization of ctx
+func (s *DeviceService) loadBin(ctx context.Context, id uuid.UUID) (*models.Bin, error) {
+	bin, err := s.binRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if bin == nil || !bin.IsActive {
+		return nil, ErrBinNotFound
+	}
+	return bin, nil
+}
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+)
+
+// DispatchService assigns collections for full bins and pickup requests to
+// drivers without a dispatcher
+type DispatchService struct {
+	binRepo             *repository.BinRepository
+	collectionRepo      *repository.CollectionRepository
+	driverRepo          *repository.DriverRepository
+	contractRepo        *repository.ContractRepository
+	zoneRepo            *repository.ZoneRepository
+	pickupRepo          *repository.PickupRequestRepository
+	notificationService *NotificationService
+	settings            *SettingsService
+}
+
+// NewDispatchService creates a new DispatchService
+func NewDispatchService(
+	binRepo *repository.BinRepository,
+	collectionRepo *repository.CollectionRepository,
+	driverRepo *repository.DriverRepository,
+	contractRepo *repository.ContractRepository,
+	zoneRepo *repository.ZoneRepository,
+	pickupRepo *repository.PickupRequestRepository,
+	notificationService *NotificationService,
+	settings *SettingsService,
+) *DispatchService {
+	return &DispatchService{
+		binRepo:             binRepo,
+		collectionRepo:      collectionRepo,
+		driverRepo:          driverRepo,
+		contractRepo:        contractRepo,
+		zoneRepo:            zoneRepo,
+		pickupRepo:          pickupRepo,
+		notificationService: notificationService,
+		settings:            settings,
+	}
+}
+
+// DispatchPending creates a collection for every bin over its collection threshold that has
+// no open collection, assigned to the nearest available driver with spare
+// capacity, and notifies that driver. Bins of a company are only dispatched
+// while one of its contracts in effect covers them, and drivers restricted to
+// some zones only get the bins of those zones. Pending pickup requests are
+// then assigned the same way, oldest first.
+func (s *DispatchService) DispatchPending(ctx context.Context) (*models.DispatchResult, error) {
+	bins, err := s.binRepo.GetBinsAwaitingDispatch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bins awaiting dispatch: %w", err)
+	}
+
+	pickups, err := s.pickupRepo.ListPending(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending pickup requests: %w", err)
+	}
+
+	result := &models.DispatchResult{}
+	if len(bins) == 0 && len(pickups) == 0 {
+		return result, nil
+	}
+
+	drivers, err := s.driverRepo.GetAvailableDrivers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get available drivers: %w", err)
+	}
+
+	coverage, err := s.zoneRepo.Coverage(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get driver zones: %w", err)
+	}
+
+	contracts, err := s.contractRepo.ListInEffect(ctx, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contracts in effect: %w", err)
+	}
+	companyContracts := make(map[uuid.UUID][]models.Contract)
+	for _, contract := range contracts {
+		companyContracts[contract.CompanyID] = append(companyContracts[contract.CompanyID], contract)
+	}
+
+	maxPerDriver := s.settings.Current().Dispatch.MaxPerDriver
+	assigned := make(map[uuid.UUID]int)
+	for i := range bins {
+		bin := &bins[i]
+		if bin.CompanyID != nil && !coveredByContract(bin, companyContracts[*bin.CompanyID]) {
+			result.OutOfContract++
+			continue
+		}
+
+		driver := nearestDriverWithCapacity(bin, drivers, coverage, assigned, maxPerDriver)
+		if driver == nil {
+			result.Unassigned++
+			continue
+		}
+
+		collection := &models.Collection{
+			BinID:           bin.ID,
+			DriverID:        driver.ID,
+			FillLevelBefore: bin.FillLevel,
+			Status:          models.CollectionStatusPending,
+		}
+		if err := s.collectionRepo.Create(ctx, collection); err != nil {
+			return result, fmt.Errorf("failed to create collection for bin %s: %w", bin.DeviceID, err)
+		}
+		assigned[driver.ID]++
+		result.Dispatched++
+
+		notification := &models.Notification{
+			ID:    uuid.New(),
+			BinID: &bin.ID,
+			Type:  models.NotificationTypeRouteAssigned,
+			Title: "Collection Assigned",
+			Message: fmt.Sprintf(
+				"You have been assigned bin %s, which is %d%% full.",
+				bin.DeviceID,
+				bin.FillLevel,
+			),
+		}
+		if err := s.notificationService.NotifyDriver(ctx, driver.ID, notification); err != nil {
+			log.Printf("Failed to notify driver %s of collection %s: %v", driver.ID, collection.ID, err)
+		}
+	}
+
+	for i := range pickups {
+		pickup := &pickups[i]
+		driver := nearestDriverWithCapacity(pickupSite(pickup), drivers, coverage, assigned, maxPerDriver)
+		if driver == nil {
+			result.Unassigned++
+			continue
+		}
+
+		if err := s.assignPickup(ctx, pickup, driver); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				continue
+			}
+			return result, err
+		}
+		assigned[driver.ID]++
+		result.Pickups++
+	}
+
+	return result, nil
+}
+
+// DispatchPickup assigns an open pickup request to the driver, or to the
+// nearest available driver covering its location when driverID is nil, and
+// notifies them
+func (s *DispatchService) DispatchPickup(ctx context.Context, pickup *models.PickupRequest, driverID *uuid.UUID) error {
+	if !pickup.Status.IsOpen() {
+		return ErrPickupClosed
+	}
+
+	var driver *models.Driver
+	if driverID != nil {
+		var err error
+		if driver, err = s.driverRepo.GetByID(ctx, *driverID); err != nil {
+			return fmt.Errorf("failed to get driver: %w", err)
+		}
+		if driver == nil {
+			return ErrDriverNotFound
+		}
+	} else {
+		drivers, err := s.driverRepo.GetAvailableDrivers(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get available drivers: %w", err)
+		}
+		coverage, err := s.zoneRepo.Coverage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get driver zones: %w", err)
+		}
+		if driver = nearestDriverWithCapacity(pickupSite(pickup), drivers, coverage, nil, 0); driver == nil {
+			return ErrNoDriverAvailable
+		}
+	}
+
+	if err := s.assignPickup(ctx, pickup, driver); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrPickupClosed
+		}
+		return err
+	}
+	return nil
+}
+
+// assignPickup assigns a pickup request to a driver and notifies them. It
+// returns repository.ErrNotFound when the request changed since it was loaded.
+func (s *DispatchService) assignPickup(ctx context.Context, pickup *models.PickupRequest, driver *models.Driver) error {
+	from := pickup.Status
+	pickup.Status = models.PickupStatusAssigned
+	pickup.DriverID = &driver.ID
+	if err := s.pickupRepo.UpdateStatus(ctx, pickup, from); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to assign pickup request %s: %w", pickup.ID, err)
+	}
+
+	notification := &models.Notification{
+		ID:    uuid.New(),
+		Type:  models.NotificationTypePickupAssigned,
+		Title: "Pickup Assigned",
+		Message: fmt.Sprintf(
+			"You have been assigned a pickup of %.1f kg of %s waste.",
+			pickup.EstimatedWeightKg,
+			pickup.WasteType,
+		),
+	}
+	if pickup.Address != nil {
+		notification.Message += " Address: " + *pickup.Address
+	}
+	if err := s.notificationService.NotifyDriver(ctx, driver.ID, notification); err != nil {
+		log.Printf("Failed to notify driver %s of pickup %s: %v", driver.ID, pickup.ID, err)
+	}
+	return nil
+}
+
+// pickupSite returns the site of a pickup request as drivers are matched to it
+func pickupSite(pickup *models.PickupRequest) *models.Bin {
+	return &models.Bin{
+		OrganizationID: pickup.OrganizationID,
+		Latitude:       pickup.Latitude,
+		Longitude:      pickup.Longitude,
+		ZoneID:         pickup.ZoneID,
+	}
+}
+
+// coveredByContract reports whether any of the contracts covers the bin
+func coveredByContract(bin *models.Bin, contracts []models.Contract) bool {
+	for i := range contracts {
+		if contracts[i].CoversBin(bin) {
+			return true
+		}
+	}
+	return false
+}
+
+// nearestDriverWithCapacity returns the closest located driver of the bin's
+// organization covering its zone who has fewer than maxPerDriver collections
+// assigned in this run
+func nearestDriverWithCapacity(bin *models.Bin, drivers []models.Driver, coverage models.ZoneCoverage, assigned map[uuid.UUID]int, maxPerDriver int) *models.Driver {
+	var nearest *models.Driver
+	minDist := math.MaxFloat64
+
+	for i := range drivers {
+		driver := &drivers[i]
+		if driver.OrganizationID != bin.OrganizationID || driver.Latitude == nil || driver.Longitude == nil {
+			continue
+		}
+		if maxPerDriver > 0 && assigned[driver.ID] >= maxPerDriver {
+			continue
+		}
+		if !coverage.Covers(driver.ID, bin) {
+			continue
+		}
+
+		dist := haversineDistance(bin.Latitude, bin.Longitude, *driver.Latitude, *driver.Longitude)
+		if dist < minDist {
+			minDist = dist
+			nearest = driver
+		}
+	}
+
+	return nearest
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/config"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/pkg/events"
+)
+
+var (
+	// ErrNothingToPay is returned when creating a payout batch without unpaid
+	// earnings to settle
+	ErrNothingToPay = errors.New("no unpaid earnings to pay out")
+	// ErrBatchPaid is returned when marking a payout batch paid twice
+	ErrBatchPaid = errors.New("payout batch was already paid")
+)
+
+// EarningService credits drivers for their work at the configured rates and
+// settles their earnings in payout batches. Each collection, pickup and
+// shipment is only credited once.
+type EarningService struct {
+	cfg         *config.EarningsConfig
+	earningRepo *repository.EarningRepository
+	driverRepo  *repository.DriverRepository
+}
+
+// NewEarningService creates a new EarningService
+func NewEarningService(earningRepo *repository.EarningRepository, driverRepo *repository.DriverRepository, cfg *config.EarningsConfig) *EarningService {
+	return &EarningService{
+		cfg:         cfg,
+		earningRepo: earningRepo,
+		driverRepo:  driverRepo,
+	}
+}
+
+// CreditCollection credits the driver of a completed collection
+func (s *EarningService) CreditCollection(ctx context.Context, collection *models.Collection) (*models.DriverEarning, error) {
+	return s.credit(ctx, &models.DriverEarning{
+		DriverID: collection.DriverID,
+		Source:   models.EarningSourceCollection,
+		SourceID: collection.ID,
+		WeightKg: collection.WeightKg,
+	}, s.cfg.CollectionRate)
+}
+
+// CreditPickup credits the driver of a collected pickup request, at the rate
+// of a collection
+func (s *EarningService) CreditPickup(ctx context.Context, pickup *models.PickupRequest) (*models.DriverEarning, error) {
+	if pickup.DriverID == nil {
+		return nil, nil
+	}
+
+	return s.credit(ctx, &models.DriverEarning{
+		DriverID: *pickup.DriverID,
+		Source:   models.EarningSourcePickup,
+		SourceID: pickup.ID,
+		WeightKg: pickup.WeightKg,
+	}, s.cfg.CollectionRate)
+}
+
+// CreditShipment credits the driver who delivered a completed shipment.
+// Shipments completed without a driver earn nothing.
+func (s *EarningService) CreditShipment(ctx context.Context, shipment *events.StatusChanged) (*models.DriverEarning, error) {
+	if shipment.DriverID == nil {
+		return nil, nil
+	}
+
+	return s.credit(ctx, &models.DriverEarning{
+		DriverID: *shipment.DriverID,
+		Source:   models.EarningSourceShipment,
+		SourceID: shipment.ShipmentID,
+		WeightKg: shipment.WeightKg,
+	}, s.cfg.ShipmentRate)
+}
+
+// GrantBonus grants a driver a bonus on top of their earnings
+func (s *EarningService) GrantBonus(ctx context.Context, driverID, grantedBy uuid.UUID, req *models.GrantBonusRequest) (*models.DriverEarning, error) {
+	if err := s.checkDriver(ctx, driverID); err != nil {
+		return nil, err
+	}
+
+	earning := &models.DriverEarning{
+		DriverID:    driverID,
+		Source:      models.EarningSourceBonus,
+		SourceID:    uuid.New(),
+		Amount:      roundCents(req.Amount),
+		Currency:    s.cfg.Currency,
+		Description: &req.Description,
+		CreatedBy:   &grantedBy,
+	}
+	recorded, err := s.earningRepo.Record(ctx, earning)
+	if err != nil {
+		return nil, fmt.Errorf("failed to grant bonus: %w", err)
+	}
+	if !recorded {
+		return nil, ErrDriverNotFound
+	}
+	return earning, nil
+}
+
+// Statement totals what a driver earned over a month, by source and by
+// whether it was paid out yet
+func (s *EarningService) Statement(ctx context.Context, driverID uuid.UUID, r models.AnalyticsRange) (*models.DriverEarningsStatement, error) {
+	if err := s.checkDriver(ctx, driverID); err != nil {
+		return nil, err
+	}
+
+	earnings, err := s.earningRepo.ListByDriver(ctx, driverID, r.From, r.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch driver earnings: %w", err)
+	}
+	if earnings == nil {
+		earnings = []models.DriverEarning{}
+	}
+
+	statement := &models.DriverEarningsStatement{
+		DriverID: driverID,
+		Period:   r.From.Format(models.EarningsPeriodLayout),
+		From:     r.From,
+		To:       r.To,
+		Currency: s.cfg.Currency,
+		Sources:  []models.EarningsBySource{},
+		Earnings: earnings,
+	}
+	bySource := make(map[models.EarningSource]int)
+	for _, earning := range earnings {
+		i, ok := bySource[earning.Source]
+		if !ok {
+			i = len(statement.Sources)
+			bySource[earning.Source] = i
+			statement.Sources = append(statement.Sources, models.EarningsBySource{Source: earning.Source})
+		}
+		statement.Sources[i].Count++
+		statement.Sources[i].Amount += earning.Amount
+		statement.Total += earning.Amount
+		if earning.Paid {
+			statement.Paid += earning.Amount
+		}
+	}
+	for i := range statement.Sources {
+		statement.Sources[i].Amount = roundCents(statement.Sources[i].Amount)
+	}
+	statement.Total = roundCents(statement.Total)
+	statement.Paid = roundCents(statement.Paid)
+	statement.Unpaid = roundCents(statement.Total - statement.Paid)
+	return statement, nil
+}
+
+// CreateBatch creates a payout batch settling the unpaid earnings of the
+// organization of ctx earned up to the end of a month
+func (s *EarningService) CreateBatch(ctx context.Context, r models.AnalyticsRange, createdBy uuid.UUID) (*models.PayoutBatch, error) {
+	batch := &models.PayoutBatch{
+		PeriodStart: r.From,
+		PeriodEnd:   r.To,
+		Currency:    s.cfg.Currency,
+		CreatedBy:   &createdBy,
+	}
+	if err := s.earningRepo.CreateBatch(ctx, batch); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNothingToPay
+		}
+		return nil, fmt.Errorf("failed to create payout batch: %w", err)
+	}
+	lines, err := s.earningRepo.ListLines(ctx, batch.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch payout lines: %w", err)
+	}
+	batch.Lines = lines
+
+	log.Printf("Created payout batch %s of %.2f %s for %d drivers", batch.ID, batch.TotalAmount, batch.Currency, batch.DriverCount)
+	return batch, nil
+}
+
+// MarkBatchPaid records that finance paid out a pending payout batch
+func (s *EarningService) MarkBatchPaid(ctx context.Context, batch *models.PayoutBatch) error {
+	if batch.Status != models.PayoutBatchPending {
+		return ErrBatchPaid
+	}
+	if err := s.earningRepo.MarkBatchPaid(ctx, batch); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrBatchPaid
+		}
+		return fmt.Errorf("failed to mark payout batch paid: %w", err)
+	}
+	return nil
+}
+
+// credit prices earning at rate plus the per-kg rate and records it. It
+// returns nil if it earns nothing, the source was already credited or the
+// driver is unknown.
+func (s *EarningService) credit(ctx context.Context, earning *models.DriverEarning, rate float64) (*models.DriverEarning, error) {
+	amount := rate
+	if earning.WeightKg != nil {
+		amount += s.cfg.PerKgRate * *earning.WeightKg
+	}
+	earning.Amount = roundCents(amount)
+	if earning.Amount <= 0 {
+		return nil, nil
+	}
+	earning.Currency = s.cfg.Currency
+
+	credited, err := s.earningRepo.Record(ctx, earning)
+	if err != nil {
+		return nil, fmt.Errorf("failed to credit driver earning: %w", err)
+	}
+	if !credited {
+		log.Printf("Earning for %s %s already credited or driver %s unknown", earning.Source, earning.SourceID, earning.DriverID)
+		return nil, nil
+	}
+
+	log.Printf("Credited %.2f %s to driver %s for %s %s", earning.Amount, earning.Currency, earning.DriverID, earning.Source, earning.SourceID)
+	return earning, nil
+}
+
+// checkDriver returns ErrDriverNotFound unless the driver exists in the
+// organization of ctx
+func (s *EarningService) checkDriver(ctx context.Context, id uuid.UUID) error {
+	driver, err := s.driverRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if driver == nil {
+		return ErrDriverNotFound
+	}
+	return nil
+}
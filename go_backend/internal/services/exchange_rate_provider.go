@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/smartwaste/backend/internal/config"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// Exchange rate provider names accepted by EXCHANGE_RATE_PROVIDER
+const (
+	ExchangeRateProviderECB    = "ecb"    // European Central Bank daily reference rates
+	ExchangeRateProviderStatic = "static" // Fixed rates from EXCHANGE_RATES
+	ExchangeRateProviderNone   = "none"
+)
+
+// exchangeRateHTTPTimeout bounds calls to external exchange rate APIs
+const exchangeRateHTTPTimeout = 10 * time.Second
+
+// ExchangeRateProvider fetches the current exchange rates
+type ExchangeRateProvider interface {
+	Name() string
+	Fetch(ctx context.Context) (*models.ExchangeRates, error)
+}
+
+// NewExchangeRateProvider creates the exchange rate provider selected by the
+// configuration; it returns nil when conversions are disabled
+func NewExchangeRateProvider(cfg *config.ExchangeRateConfig) (ExchangeRateProvider, error) {
+	switch cfg.Provider {
+	case ExchangeRateProviderNone, "":
+		return nil, nil
+	case ExchangeRateProviderECB:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("exchange rate provider %q requires EXCHANGE_RATE_URL", cfg.Provider)
+		}
+		return NewECBRateProvider(&http.Client{Timeout: exchangeRateHTTPTimeout}, cfg.URL), nil
+	case ExchangeRateProviderStatic:
+		return NewStaticRateProvider(cfg.Base, cfg.Rates)
+	default:
+		return nil, fmt.Errorf("unknown exchange rate provider %q", cfg.Provider)
+	}
+}
+
+// ECBRateProvider fetches the euro reference rates the European Central Bank
+// publishes every working day
+type ECBRateProvider struct {
+	client *http.Client
+	url    string
+}
+
+// NewECBRateProvider creates a new ECBRateProvider
+func NewECBRateProvider(client *http.Client, url string) *ECBRateProvider {
+	return &ECBRateProvider{client: client, url: url}
+}
+
+// Name returns the provider name
+func (p *ECBRateProvider) Name() string {
+	return ExchangeRateProviderECB
+}
+
+// ecbEnvelope is the eurofxref-daily.xml document
+type ecbEnvelope struct {
+	Cube struct {
+		Day struct {
+			Time  string `xml:"time,attr"`
+			Rates []struct {
+				Currency string  `xml:"currency,attr"`
+				Rate     float64 `xml:"rate,attr"`
+			} `xml:"Cube"`
+		} `xml:"Cube"`
+	} `xml:"Cube"`
+}
+
+// Fetch downloads the latest reference rates
+func (p *ECBRateProvider) Fetch(ctx context.Context) (*models.ExchangeRates, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ECB request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ECB returned status %d", resp.StatusCode)
+	}
+
+	var envelope ecbEnvelope
+	if err := xml.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("failed to decode ECB rates: %w", err)
+	}
+	day := envelope.Cube.Day
+	if len(day.Rates) == 0 {
+		return nil, fmt.Errorf("ECB returned no rates")
+	}
+
+	date, err := time.Parse("2006-01-02", day.Time)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ECB rates date %q", day.Time)
+	}
+	rates := &models.ExchangeRates{
+		Base:   "EUR",
+		Date:   date,
+		Source: ExchangeRateProviderECB,
+		Rates:  make(map[string]float64, len(day.Rates)),
+	}
+	for _, rate := range day.Rates {
+		rates.Rates[rate.Currency] = rate.Rate
+	}
+	return rates, nil
+}
+
+// StaticRateProvider serves fixed rates from the configuration
+type StaticRateProvider struct {
+	rates models.ExchangeRates
+}
+
+// NewStaticRateProvider parses rates given as "USD=1.08,GBP=0.86" against base
+func NewStaticRateProvider(base, rates string) (*StaticRateProvider, error) {
+	if len(base) != 3 {
+		return nil, fmt.Errorf("exchange rate provider %q requires a 3-letter EXCHANGE_RATE_BASE", ExchangeRateProviderStatic)
+	}
+
+	p := &StaticRateProvider{rates: models.ExchangeRates{
+		Base:   strings.ToUpper(base),
+		Source: ExchangeRateProviderStatic,
+		Rates:  make(map[string]float64),
+	}}
+	for _, pair := range strings.Split(rates, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		currency, value, ok := strings.Cut(pair, "=")
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if !ok || err != nil || rate <= 0 || len(strings.TrimSpace(currency)) != 3 {
+			return nil, fmt.Errorf("invalid exchange rate %q in EXCHANGE_RATES", pair)
+		}
+		p.rates.Rates[strings.ToUpper(strings.TrimSpace(currency))] = rate
+	}
+	if len(p.rates.Rates) == 0 {
+		return nil, fmt.Errorf("exchange rate provider %q requires EXCHANGE_RATES", ExchangeRateProviderStatic)
+	}
+	return p, nil
+}
+
+// Name returns the provider name
+func (p *StaticRateProvider) Name() string {
+	return ExchangeRateProviderStatic
+}
+
+// Fetch returns the configured rates, dated today
+func (p *StaticRateProvider) Fetch(ctx context.Context) (*models.ExchangeRates, error) {
+	rates := p.rates
+	rates.Date = time.Now().UTC().Truncate(24 * time.Hour)
+	return &rates, nil
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// exchangeRateRetryAfter spaces out fetches while the provider is failing
+const exchangeRateRetryAfter = 5 * time.Minute
+
+// Exchange rate errors; the handlers map each to a response
+var (
+	ErrRatesUnavailable = errors.New("exchange rates are unavailable")
+	ErrUnknownCurrency  = errors.New("unknown currency")
+)
+
+// ExchangeRateService converts amounts between currencies with rates fetched
+// from a provider and reused until they are older than the TTL
+type ExchangeRateService struct {
+	provider ExchangeRateProvider
+	ttl      time.Duration
+
+	mu          sync.Mutex
+	rates       *models.ExchangeRates
+	fetchedAt   time.Time
+	lastAttempt time.Time
+}
+
+// NewExchangeRateService creates a new ExchangeRateService; provider is nil
+// when conversions are disabled
+func NewExchangeRateService(provider ExchangeRateProvider, ttl time.Duration) *ExchangeRateService {
+	return &ExchangeRateService{provider: provider, ttl: ttl}
+}
+
+// Rates returns the cached rates, fetching them first when they expired.
+// When a refresh fails the expired rates keep being served.
+func (s *ExchangeRateService) Rates(ctx context.Context) (*models.ExchangeRates, error) {
+	if s.provider == nil {
+		return nil, fmt.Errorf("%w: no exchange rate provider configured", ErrRatesUnavailable)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.rates != nil && now.Sub(s.fetchedAt) < s.ttl {
+		return s.rates, nil
+	}
+	if now.Sub(s.lastAttempt) < exchangeRateRetryAfter {
+		if s.rates != nil {
+			return s.rates, nil
+		}
+		return nil, fmt.Errorf("%w: %s provider is failing", ErrRatesUnavailable, s.provider.Name())
+	}
+
+	s.lastAttempt = now
+	rates, err := s.provider.Fetch(ctx)
+	if err != nil {
+		if s.rates != nil {
+			log.Printf("Failed to refresh exchange rates, serving those of %s: %v", s.rates.Date.Format("2006-01-02"), err)
+			return s.rates, nil
+		}
+		return nil, fmt.Errorf("%w: %v", ErrRatesUnavailable, err)
+	}
+
+	s.rates = rates
+	s.fetchedAt = now
+	return rates, nil
+}
+
+// Convert converts a valuation's total and price per kg from its currency to another
+func (s *ExchangeRateService) Convert(ctx context.Context, valuation *models.ValuationResponse, currency string) (*models.CurrencyConversion, error) {
+	if valuation.Currency == currency {
+		return &models.CurrencyConversion{
+			Currency:   currency,
+			Rate:       1,
+			PricePerKg: valuation.PricePerKg,
+			TotalPrice: valuation.TotalPrice,
+			RatesDate:  time.Now().UTC().Truncate(24 * time.Hour),
+			Source:     "identity",
+		}, nil
+	}
+
+	rates, err := s.Rates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rate, ok := rates.Rate(valuation.Currency, currency)
+	if !ok {
+		return nil, fmt.Errorf("%w: no %s rate to convert %s to %s", ErrUnknownCurrency, rates.Source, valuation.Currency, currency)
+	}
+
+	return &models.CurrencyConversion{
+		Currency:   currency,
+		Rate:       rate,
+		PricePerKg: valuation.PricePerKg * rate,
+		TotalPrice: roundCents(valuation.TotalPrice * rate),
+		RatesDate:  rates.Date,
+		Source:     rates.Source,
+	}, nil
+}
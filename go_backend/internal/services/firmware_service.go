@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+)
+
+var (
+	// ErrRolloutNotActive is returned when cancelling a rollout already cancelled
+	ErrRolloutNotActive = errors.New("firmware rollout is not active")
+	// ErrOTAUnavailable is returned when updates cannot be offered to the sensors
+	ErrOTAUnavailable = errors.New("MQTT broker is not connected")
+)
+
+// FirmwarePublisher offers firmware updates to bin sensors, implemented by
+// the MQTT client
+type FirmwarePublisher interface {
+	IsConnected() bool
+	// PublishOTA offers an update to a device until it is cleared
+	PublishOTA(deviceID string, update *models.OTAUpdate) error
+	// ClearOTA withdraws the update offered to a device
+	ClearOTA(deviceID string) error
+}
+
+// FirmwareService rolls firmware versions out to the bin sensors
+type FirmwareService struct {
+	firmwareRepo *repository.FirmwareRepository
+	publisher    FirmwarePublisher
+}
+
+// NewFirmwareService creates a new FirmwareService
+func NewFirmwareService(firmwareRepo *repository.FirmwareRepository, publisher FirmwarePublisher) *FirmwareService {
+	return &FirmwareService{
+		firmwareRepo: firmwareRepo,
+		publisher:    publisher,
+	}
+}
+
+// Create starts a rollout and offers it to the sensors of its bins. The
+// rollout is already saved when offering it to a sensor fails, so that
+// failure is only logged and the bin's update stays pending.
+func (s *FirmwareService) Create(ctx context.Context, req *models.CreateFirmwareRolloutRequest, createdBy *uuid.UUID) (*models.FirmwareRollout, error) {
+	if !s.publisher.IsConnected() {
+		return nil, ErrOTAUnavailable
+	}
+
+	rollout := &models.FirmwareRollout{
+		DeviceGroup: req.DeviceGroup,
+		Version:     req.Version,
+		URL:         req.URL,
+		SHA256:      req.SHA256,
+		Signature:   req.Signature,
+		CreatedBy:   createdBy,
+	}
+	deviceIDs, err := s.firmwareRepo.Create(ctx, rollout)
+	if err != nil {
+		return nil, err
+	}
+
+	update := &models.OTAUpdate{
+		RolloutID: rollout.ID,
+		Version:   rollout.Version,
+		URL:       rollout.URL,
+		SHA256:    rollout.SHA256,
+		Signature: rollout.Signature,
+	}
+	for _, deviceID := range deviceIDs {
+		if err := s.publisher.PublishOTA(deviceID, update); err != nil {
+			log.Printf("Failed to offer firmware %s to bin %s: %v", rollout.Version, deviceID, err)
+		}
+	}
+	log.Printf("Rolling firmware %s out to %d bins", rollout.Version, len(deviceIDs))
+	return rollout, nil
+}
+
+// Get retrieves a rollout with its progress
+func (s *FirmwareService) Get(ctx context.Context, id uuid.UUID) (*models.FirmwareRollout, error) {
+	return s.firmwareRepo.GetByID(ctx, id)
+}
+
+// List retrieves rollouts with their progress, newest first
+func (s *FirmwareService) List(ctx context.Context, filter models.FirmwareRolloutFilter, page repository.Page) ([]models.FirmwareRollout, repository.PageResult, error) {
+	return s.firmwareRepo.ListFiltered(ctx, filter, page)
+}
+
+// Updates retrieves the progress of a rollout on each of its bins
+func (s *FirmwareService) Updates(ctx context.Context, id uuid.UUID) ([]models.FirmwareUpdate, error) {
+	return s.firmwareRepo.Updates(ctx, id)
+}
+
+// Cancel cancels a rollout and withdraws it from the sensors that did not
+// start installing it. Sensors already under way finish their update.
+func (s *FirmwareService) Cancel(ctx context.Context, rollout *models.FirmwareRollout) error {
+	if rollout.Status != models.RolloutActive {
+		return ErrRolloutNotActive
+	}
+	if !s.publisher.IsConnected() {
+		return ErrOTAUnavailable
+	}
+
+	deviceIDs, err := s.firmwareRepo.Cancel(ctx, rollout.ID)
+	if errors.Is(err, repository.ErrNotFound) {
+		// Cancelled concurrently
+		return ErrRolloutNotActive
+	}
+	if err != nil {
+		return err
+	}
+	for _, deviceID := range deviceIDs {
+		if err := s.publisher.ClearOTA(deviceID); err != nil {
+			log.Printf("Failed to withdraw firmware %s from bin %s: %v", rollout.Version, deviceID, err)
+		}
+	}
+	return nil
+}
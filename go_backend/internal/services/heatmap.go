@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// GetHeatmap aggregates bins into a grid over the query bounding box. Fill
+// level intensities are absolute (a full cell is 1); collection intensities
+// are relative to the busiest cell.
+func (s *AnalyticsService) GetHeatmap(ctx context.Context, query models.HeatmapQuery) (*models.Heatmap, error) {
+	cells, err := s.binRepo.Heatmap(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if cells == nil {
+		cells = []models.HeatmapCell{}
+	}
+
+	cellLat, cellLng := query.CellSize()
+	heatmap := &models.Heatmap{
+		Metric:      query.Metric,
+		BBox:        query.BBox,
+		Grid:        query.Grid,
+		CellSizeLat: cellLat,
+		CellSizeLng: cellLng,
+		Cells:       cells,
+	}
+	for _, cell := range cells {
+		if cell.Value > heatmap.MaxValue {
+			heatmap.MaxValue = cell.Value
+		}
+	}
+
+	scale := heatmap.MaxValue
+	if query.Metric == models.HeatmapFillLevel {
+		scale = 100
+	} else {
+		period := query.Period
+		heatmap.Period = &period
+	}
+	if scale > 0 {
+		for i := range cells {
+			cells[i].Intensity = cells[i].Value / scale
+		}
+	}
+	return heatmap, nil
+}
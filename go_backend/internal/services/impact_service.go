@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+)
+
+// ImpactService estimates the CO2-equivalent savings of collected waste from
+// the emission factors of its waste types
+type ImpactService struct {
+	collectionRepo *repository.CollectionRepository
+	factorRepo     *repository.EmissionFactorRepository
+}
+
+// NewImpactService creates a new ImpactService
+func NewImpactService(collectionRepo *repository.CollectionRepository, factorRepo *repository.EmissionFactorRepository) *ImpactService {
+	return &ImpactService{
+		collectionRepo: collectionRepo,
+		factorRepo:     factorRepo,
+	}
+}
+
+// GetUserImpact reports the savings of the collections credited to a citizen
+func (s *ImpactService) GetUserImpact(ctx context.Context, userID uuid.UUID, period models.AnalyticsRange) (*models.ImpactReport, error) {
+	return s.report(ctx, models.ImpactScopeUser, &userID, repository.WeightScope{UserID: &userID}, period)
+}
+
+// GetCompanyImpact reports the savings of the collections of a company's bins
+func (s *ImpactService) GetCompanyImpact(ctx context.Context, companyID uuid.UUID, period models.AnalyticsRange) (*models.ImpactReport, error) {
+	return s.report(ctx, models.ImpactScopeCompany, &companyID, repository.WeightScope{CompanyID: &companyID}, period)
+}
+
+// GetCityImpact reports the savings of all collections
+func (s *ImpactService) GetCityImpact(ctx context.Context, period models.AnalyticsRange) (*models.ImpactReport, error) {
+	return s.report(ctx, models.ImpactScopeCity, nil, repository.WeightScope{}, period)
+}
+
+func (s *ImpactService) report(ctx context.Context, scope models.ImpactScope, scopeID *uuid.UUID, weightScope repository.WeightScope, period models.AnalyticsRange) (*models.ImpactReport, error) {
+	weights, err := s.collectionRepo.WeightByWasteType(ctx, weightScope, period)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total collected weight: %w", err)
+	}
+
+	factors, err := s.factorRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch emission factors: %w", err)
+	}
+	byWasteType := make(map[string]float64, len(factors))
+	for _, factor := range factors {
+		byWasteType[factor.WasteType] = factor.KgCO2ePerKg
+	}
+
+	report := &models.ImpactReport{
+		Scope:       scope,
+		ScopeID:     scopeID,
+		Period:      period,
+		ByWasteType: make([]models.ImpactByWasteType, 0, len(weights)),
+	}
+	for _, weight := range weights {
+		impact := models.ImpactByWasteType{
+			WasteType:   weight.WasteType,
+			Collections: weight.Collections,
+			WeightKg:    weight.WeightKg,
+		}
+		factor, ok := byWasteType[weight.WasteType]
+		if !ok {
+			factor, ok = byWasteType[models.EmissionFactorAnyWasteType]
+		}
+		if ok {
+			impact.KgCO2ePerKg = &factor
+			impact.CO2eKg = weight.WeightKg * factor
+		}
+
+		report.TotalWeightKg += impact.WeightKg
+		report.TotalCO2eKg += impact.CO2eKg
+		report.ByWasteType = append(report.ByWasteType, impact)
+	}
+	return report, nil
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/config"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+)
+
+// ErrInvalidIssueTransition is returned when a report cannot move to the requested status
+var ErrInvalidIssueTransition = errors.New("invalid issue status transition")
+
+// IssueReportService handles issue reports and the flagging of the bins they concern
+type IssueReportService struct {
+	reportRepo          *repository.IssueReportRepository
+	binRepo             *repository.BinRepository
+	notificationService *NotificationService
+	uploadService       *UploadService
+	config              *config.IssueReportConfig
+}
+
+// NewIssueReportService creates a new IssueReportService
+func NewIssueReportService(
+	reportRepo *repository.IssueReportRepository,
+	binRepo *repository.BinRepository,
+	notificationService *NotificationService,
+	uploadService *UploadService,
+	cfg *config.IssueReportConfig,
+) *IssueReportService {
+	return &IssueReportService{
+		reportRepo:          reportRepo,
+		binRepo:             binRepo,
+		notificationService: notificationService,
+		uploadService:       uploadService,
+		config:              cfg,
+	}
+}
+
+// Create files an issue report and flags its bin once enough different people
+// reported it within the flag window. A photo in the upload bucket must have
+// been uploaded by the reporter. The report is already saved when flagging
+// fails, so that failure is only logged.
+func (s *IssueReportService) Create(ctx context.Context, bin *models.Bin, report *models.IssueReport) error {
+	var photo *models.Upload
+	if report.PhotoURL != nil {
+		var err error
+		if photo, err = s.uploadService.Resolve(ctx, *report.PhotoURL, report.ReportedBy); err != nil {
+			return err
+		}
+	}
+
+	report.BinID = bin.ID
+	if err := s.reportRepo.Create(ctx, report); err != nil {
+		return err
+	}
+	s.uploadService.Attach(ctx, photo)
+
+	if bin.IsFlagged {
+		return nil
+	}
+	reporters, err := s.reportRepo.CountOpenReporters(ctx, bin.ID, time.Now().Add(-s.config.FlagWindow))
+	if err != nil {
+		log.Printf("Failed to count issue reports for bin %s: %v", bin.DeviceID, err)
+		return nil
+	}
+	if reporters < s.config.FlagReporters {
+		return nil
+	}
+
+	flagged, err := s.binRepo.Flag(ctx, bin.ID)
+	if err != nil {
+		log.Printf("Failed to flag bin %s: %v", bin.DeviceID, err)
+		return nil
+	}
+	if flagged {
+		log.Printf("Bin %s flagged after issue reports from %d people", bin.DeviceID, reporters)
+		if err := s.notificationService.NotifyBinFlagged(ctx, bin, reporters); err != nil {
+			log.Printf("Failed to send flag alert for bin %s: %v", bin.DeviceID, err)
+		}
+	}
+	return nil
+}
+
+// UpdateStatus moves a report to a new triage status and clears the flag of
+// its bin once the bin has no open reports left
+func (s *IssueReportService) UpdateStatus(ctx context.Context, report *models.IssueReport, status models.IssueStatus, note *string, triagedBy uuid.UUID) error {
+	if !report.CanTransitionTo(status) {
+		return ErrInvalidIssueTransition
+	}
+
+	report.Status = status
+	report.TriagedBy = &triagedBy
+	if note != nil {
+		report.TriageNote = note
+	}
+	if err := s.reportRepo.UpdateStatus(ctx, report); err != nil {
+		return err
+	}
+
+	if !status.IsClosed() {
+		return nil
+	}
+	open, err := s.reportRepo.CountOpen(ctx, report.BinID)
+	if err != nil {
+		log.Printf("Failed to count open issue reports for bin %s: %v", report.BinID, err)
+		return nil
+	}
+	if open == 0 {
+		if err := s.binRepo.Unflag(ctx, report.BinID); err != nil {
+			log.Printf("Failed to clear flag of bin %s: %v", report.BinID, err)
+		}
+	}
+	return nil
+}
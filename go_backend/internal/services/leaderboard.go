@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// Badge thresholds
+const (
+	punctualMinCollections = 10
+	punctualMinRate        = 0.95
+	highlyRatedMinRating   = 4.5
+	centuryCollections     = 100
+)
+
+// LeaderboardQuery selects the period, ranking and size of a driver leaderboard
+type LeaderboardQuery struct {
+	Period   models.LeaderboardPeriod
+	RankBy   models.LeaderboardRankBy
+	Limit    int
+	DriverID *uuid.UUID // Requesting driver, returned as CurrentDriver
+}
+
+// GetDriverLeaderboard ranks every driver by the collections they completed in
+// the current week or month and awards the period's badges
+func (s *AnalyticsService) GetDriverLeaderboard(ctx context.Context, query LeaderboardQuery) (*models.DriverLeaderboard, error) {
+	period := query.Period.Range(time.Now())
+	entries, err := s.driverRepo.LeaderboardStats(ctx, period, s.scoring.OnTimeWithin)
+	if err != nil {
+		return nil, err
+	}
+
+	maxCompleted, maxWeight := 0, 0.0
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Completed > 0 {
+			rate := float64(entry.OnTime) / float64(entry.Completed)
+			entry.OnTimeRate = &rate
+		}
+		maxCompleted = max(maxCompleted, entry.Completed)
+		maxWeight = math.Max(maxWeight, entry.WeightKg)
+	}
+	for i := range entries {
+		entries[i].Badges = driverBadges(&entries[i], maxCompleted, maxWeight)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if c := compareRankMetric(&entries[i], &entries[j], query.RankBy); c != 0 {
+			return c > 0
+		}
+		for _, tieBreak := range []models.LeaderboardRankBy{models.RankByCollections, models.RankByWeight, models.RankByRating} {
+			if c := compareRankMetric(&entries[i], &entries[j], tieBreak); c != 0 {
+				return c > 0
+			}
+		}
+		return strings.ToLower(entries[i].FullName) < strings.ToLower(entries[j].FullName)
+	})
+
+	// Drivers level on the ranked metric share a rank
+	for i := range entries {
+		entries[i].Rank = i + 1
+		if i > 0 && compareRankMetric(&entries[i], &entries[i-1], query.RankBy) == 0 {
+			entries[i].Rank = entries[i-1].Rank
+		}
+	}
+
+	leaderboard := &models.DriverLeaderboard{
+		Period:        query.Period,
+		From:          period.From,
+		To:            period.To,
+		RankBy:        query.RankBy,
+		OnTimeMinutes: int(s.scoring.OnTimeWithin / time.Minute),
+		Drivers:       entries[:min(query.Limit, len(entries))],
+	}
+	if query.DriverID != nil {
+		for i := range entries {
+			if entries[i].DriverID == *query.DriverID {
+				leaderboard.CurrentDriver = &entries[i]
+				break
+			}
+		}
+	}
+	return leaderboard, nil
+}
+
+// compareRankMetric returns 1 if a ranks above b on the metric, -1 if below and
+// 0 if level. Drivers without an on-time rate rank below every driver with one.
+func compareRankMetric(a, b *models.DriverLeaderboardEntry, rankBy models.LeaderboardRankBy) int {
+	var x, y float64
+	switch rankBy {
+	case models.RankByWeight:
+		x, y = a.WeightKg, b.WeightKg
+	case models.RankByOnTimeRate:
+		x, y = -1, -1
+		if a.OnTimeRate != nil {
+			x = *a.OnTimeRate
+		}
+		if b.OnTimeRate != nil {
+			y = *b.OnTimeRate
+		}
+	case models.RankByRating:
+		x, y = a.AverageRating, b.AverageRating
+	default:
+		x, y = float64(a.Completed), float64(b.Completed)
+	}
+
+	switch {
+	case x > y:
+		return 1
+	case x < y:
+		return -1
+	}
+	return 0
+}
+
+// driverBadges awards the badges an entry earned over the period
+func driverBadges(entry *models.DriverLeaderboardEntry, maxCompleted int, maxWeight float64) []models.DriverBadge {
+	badges := []models.DriverBadge{}
+	if entry.Completed > 0 && entry.Completed == maxCompleted {
+		badges = append(badges, models.BadgeTopCollector)
+	}
+	if entry.WeightKg > 0 && entry.WeightKg == maxWeight {
+		badges = append(badges, models.BadgeHeaviestLoad)
+	}
+	if entry.Completed >= punctualMinCollections && entry.OnTimeRate != nil && *entry.OnTimeRate >= punctualMinRate {
+		badges = append(badges, models.BadgePunctual)
+	}
+	if entry.AverageRating >= highlyRatedMinRating {
+		badges = append(badges, models.BadgeHighlyRated)
+	}
+	if entry.Completed >= centuryCollections {
+		badges = append(badges, models.BadgeCentury)
+	}
+	return badges
+}
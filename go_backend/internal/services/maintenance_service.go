@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+)
+
+var (
+	// ErrInvalidMaintenanceTransition is returned when a maintenance cannot move to the requested status
+	ErrInvalidMaintenanceTransition = errors.New("invalid maintenance status transition")
+	// ErrBinRetired is returned when scheduling maintenance of a deactivated bin
+	ErrBinRetired = errors.New("bin is retired")
+)
+
+// MaintenanceService schedules and tracks the maintenance of bins, taking
+// them out of dispatch and collection planning while it is in progress
+type MaintenanceService struct {
+	maintenanceRepo *repository.MaintenanceRepository
+	binRepo         *repository.BinRepository
+}
+
+// NewMaintenanceService creates a new MaintenanceService
+func NewMaintenanceService(maintenanceRepo *repository.MaintenanceRepository, binRepo *repository.BinRepository) *MaintenanceService {
+	return &MaintenanceService{
+		maintenanceRepo: maintenanceRepo,
+		binRepo:         binRepo,
+	}
+}
+
+// Schedule schedules maintenance of a bin, starting it at once unless it is
+// scheduled for later
+func (s *MaintenanceService) Schedule(ctx context.Context, bin *models.Bin, req *models.ScheduleMaintenanceRequest, scheduledBy *uuid.UUID) (*models.BinMaintenance, error) {
+	if !bin.IsActive {
+		return nil, ErrBinRetired
+	}
+
+	now := time.Now()
+	maintenance := &models.BinMaintenance{
+		BinID:        bin.ID,
+		Type:         req.Type,
+		Status:       models.BinMaintenanceScheduled,
+		ScheduledFor: now,
+		Description:  req.Description,
+		ScheduledBy:  scheduledBy,
+	}
+	if req.ScheduledFor != nil && req.ScheduledFor.After(now) {
+		maintenance.ScheduledFor = *req.ScheduledFor
+	} else {
+		maintenance.Status = models.BinMaintenanceInProgress
+		maintenance.StartedAt = &now
+	}
+
+	if err := s.maintenanceRepo.Create(ctx, maintenance); err != nil {
+		return nil, err
+	}
+	if maintenance.Status == models.BinMaintenanceInProgress {
+		s.binRepo.InvalidateCache(ctx, bin.OrganizationID)
+	}
+	return maintenance, nil
+}
+
+// Get retrieves a maintenance
+func (s *MaintenanceService) Get(ctx context.Context, id uuid.UUID) (*models.BinMaintenance, error) {
+	return s.maintenanceRepo.GetByID(ctx, id)
+}
+
+// List retrieves maintenance, latest scheduled first
+func (s *MaintenanceService) List(ctx context.Context, filter models.MaintenanceFilter, page repository.Page) ([]models.BinMaintenance, repository.PageResult, error) {
+	return s.maintenanceRepo.ListFiltered(ctx, filter, page)
+}
+
+// Start starts a scheduled maintenance ahead of time
+func (s *MaintenanceService) Start(ctx context.Context, maintenance *models.BinMaintenance) error {
+	return s.transition(ctx, maintenance, models.BinMaintenanceInProgress, nil, nil)
+}
+
+// Complete completes a maintenance, putting its bin back in service once it
+// has no other maintenance in progress
+func (s *MaintenanceService) Complete(ctx context.Context, maintenance *models.BinMaintenance, resolution *string, completedBy *uuid.UUID) error {
+	return s.transition(ctx, maintenance, models.BinMaintenanceCompleted, resolution, completedBy)
+}
+
+// Cancel cancels a maintenance, putting its bin back in service once it has
+// no other maintenance in progress
+func (s *MaintenanceService) Cancel(ctx context.Context, maintenance *models.BinMaintenance, resolution *string, completedBy *uuid.UUID) error {
+	return s.transition(ctx, maintenance, models.BinMaintenanceCancelled, resolution, completedBy)
+}
+
+// StartDue starts the maintenance that became due, returning how many started
+func (s *MaintenanceService) StartDue(ctx context.Context) (int, error) {
+	started, organizationIDs, err := s.maintenanceRepo.StartDue(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	if len(organizationIDs) > 0 {
+		s.binRepo.InvalidateCache(ctx, organizationIDs...)
+	}
+	return started, nil
+}
+
+// transition moves a maintenance to a new status and invalidates the cached
+// bin listings its bin may have entered or left
+func (s *MaintenanceService) transition(ctx context.Context, maintenance *models.BinMaintenance, status models.BinMaintenanceStatus, resolution *string, completedBy *uuid.UUID) error {
+	if !maintenance.CanTransitionTo(status) {
+		return ErrInvalidMaintenanceTransition
+	}
+
+	from := maintenance.Status
+	maintenance.Status = status
+	if resolution != nil {
+		maintenance.Resolution = resolution
+	}
+	if completedBy != nil {
+		maintenance.CompletedBy = completedBy
+	}
+	err := s.maintenanceRepo.UpdateStatus(ctx, maintenance, from)
+	if errors.Is(err, repository.ErrNotFound) {
+		// Moved concurrently, e.g. started by the scheduler
+		maintenance.Status = from
+		return ErrInvalidMaintenanceTransition
+	}
+	if err != nil {
+		return err
+	}
+
+	if bin, err := s.binRepo.GetByID(ctx, maintenance.BinID); err == nil && bin != nil {
+		s.binRepo.InvalidateCache(ctx, bin.OrganizationID)
+	}
+	return nil
+}
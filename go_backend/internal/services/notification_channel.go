@@ -0,0 +1,253 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/smartwaste/backend/internal/config"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// notificationHTTPTimeout bounds calls to external notification APIs
+const notificationHTTPTimeout = 10 * time.Second
+
+// ErrNoChannelAddress is returned by a channel when the recipient has no
+// address on it, such as a push token or phone number
+var ErrNoChannelAddress = errors.New("recipient has no address on the channel")
+
+// NotificationChannelSender delivers notifications over one channel
+type NotificationChannelSender interface {
+	Channel() models.NotificationChannel
+	Send(ctx context.Context, recipient *models.NotificationRecipient, notification *models.Notification) error
+}
+
+// NewNotificationChannels creates the channels enabled by the configuration.
+// Push is always enabled; it only logs until an FCM server key is set.
+func NewNotificationChannels(cfg *config.NotificationChannelConfig) (map[models.NotificationChannel]NotificationChannelSender, error) {
+	for _, name := range cfg.Default {
+		if !models.NotificationChannel(name).IsValid() {
+			return nil, fmt.Errorf("unknown notification channel %q in NOTIFICATION_CHANNELS", name)
+		}
+	}
+
+	client := &http.Client{Timeout: notificationHTTPTimeout}
+	channels := map[models.NotificationChannel]NotificationChannelSender{
+		models.NotificationChannelPush: NewFCMChannel(client, cfg.FCMURL, cfg.FCMServerKey),
+	}
+	if cfg.TwilioAccountSID != "" {
+		if cfg.TwilioAuthToken == "" || cfg.TwilioFrom == "" {
+			return nil, errors.New("the sms channel requires TWILIO_AUTH_TOKEN and TWILIO_FROM_NUMBER")
+		}
+		channels[models.NotificationChannelSMS] = NewTwilioChannel(client, cfg.TwilioURL, cfg.TwilioAccountSID, cfg.TwilioAuthToken, cfg.TwilioFrom)
+	}
+	if cfg.SMTPHost != "" {
+		if cfg.SMTPFrom == "" {
+			return nil, errors.New("the email channel requires SMTP_FROM")
+		}
+		channels[models.NotificationChannelEmail] = NewSMTPChannel(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom)
+	}
+	return channels, nil
+}
+
+// FCMChannel pushes notifications to the driver app through the Firebase
+// Cloud Messaging HTTP API
+type FCMChannel struct {
+	client    *http.Client
+	url       string
+	serverKey string
+}
+
+// NewFCMChannel creates a new FCMChannel; without a server key it logs the
+// messages instead of sending them
+func NewFCMChannel(client *http.Client, url, serverKey string) *FCMChannel {
+	return &FCMChannel{client: client, url: url, serverKey: serverKey}
+}
+
+// Channel returns the channel name
+func (c *FCMChannel) Channel() models.NotificationChannel {
+	return models.NotificationChannelPush
+}
+
+// Send pushes the notification to the recipient's device token
+func (c *FCMChannel) Send(ctx context.Context, recipient *models.NotificationRecipient, notification *models.Notification) error {
+	if recipient.FCMToken == nil || *recipient.FCMToken == "" {
+		return ErrNoChannelAddress
+	}
+
+	data := map[string]string{
+		"notification_id": notification.ID.String(),
+		"type":            string(notification.Type),
+	}
+	if notification.BinID != nil {
+		data["bin_id"] = notification.BinID.String()
+	}
+
+	if c.serverKey == "" {
+		log.Printf("[FCM] No server key, not pushing to %s %s: %s - %s",
+			recipient.Type, recipient.ID, notification.Title, notification.Message)
+		return nil
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"to": *recipient.FCMToken,
+		"notification": map[string]string{
+			"title": notification.Title,
+			"body":  notification.Message,
+		},
+		"data": data,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "key="+c.serverKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("fcm request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fcm returned %s", resp.Status)
+	}
+
+	// FCM answers 200 for rejected tokens and reports them per message
+	var result struct {
+		Failure int `json:"failure"`
+		Results []struct {
+			Error string `json:"error"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("invalid fcm response: %w", err)
+	}
+	if result.Failure > 0 && len(result.Results) > 0 {
+		return fmt.Errorf("fcm rejected the message: %s", result.Results[0].Error)
+	}
+	return nil
+}
+
+// TwilioChannel texts notifications through the Twilio Messages API
+type TwilioChannel struct {
+	client     *http.Client
+	baseURL    string
+	accountSID string
+	authToken  string
+	from       string
+}
+
+// NewTwilioChannel creates a new TwilioChannel
+func NewTwilioChannel(client *http.Client, baseURL, accountSID, authToken, from string) *TwilioChannel {
+	return &TwilioChannel{
+		client:     client,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		accountSID: accountSID,
+		authToken:  authToken,
+		from:       from,
+	}
+}
+
+// Channel returns the channel name
+func (c *TwilioChannel) Channel() models.NotificationChannel {
+	return models.NotificationChannelSMS
+}
+
+// Send texts the notification to the recipient's phone number
+func (c *TwilioChannel) Send(ctx context.Context, recipient *models.NotificationRecipient, notification *models.Notification) error {
+	if recipient.Phone == nil || *recipient.Phone == "" {
+		return ErrNoChannelAddress
+	}
+
+	form := url.Values{
+		"To":   {*recipient.Phone},
+		"From": {c.from},
+		"Body": {notification.Title + ": " + notification.Message},
+	}
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", c.baseURL, url.PathEscape(c.accountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(c.accountSID, c.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("twilio request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		var twilioErr struct {
+			Message string `json:"message"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&twilioErr) == nil && twilioErr.Message != "" {
+			return fmt.Errorf("twilio returned %s: %s", resp.Status, twilioErr.Message)
+		}
+		return fmt.Errorf("twilio returned %s", resp.Status)
+	}
+	return nil
+}
+
+// SMTPChannel emails notifications through an SMTP relay
+type SMTPChannel struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPChannel creates a new SMTPChannel; it authenticates when a username
+// is given
+func NewSMTPChannel(host string, port int, username, password, from string) *SMTPChannel {
+	channel := &SMTPChannel{addr: host + ":" + strconv.Itoa(port), from: from}
+	if username != "" {
+		channel.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return channel
+}
+
+// Channel returns the channel name
+func (c *SMTPChannel) Channel() models.NotificationChannel {
+	return models.NotificationChannelEmail
+}
+
+// Send emails the notification to the recipient's address. net/smtp does not
+// take a context, so a slow relay is only bounded by its own timeouts.
+func (c *SMTPChannel) Send(ctx context.Context, recipient *models.NotificationRecipient, notification *models.Notification) error {
+	if recipient.Email == "" {
+		return ErrNoChannelAddress
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", c.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", recipient.Email)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", headerValue(notification.Title))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(notification.Message)
+	msg.WriteString("\r\n")
+
+	if err := smtp.SendMail(c.addr, c.auth, c.from, []string{recipient.Email}, []byte(msg.String())); err != nil {
+		return fmt.Errorf("smtp send failed: %w", err)
+	}
+	return nil
+}
+
+// headerValue strips line breaks from a value put in a mail header
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
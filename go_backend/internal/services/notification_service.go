@@ -2,36 +2,80 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/auth"
+	"github.com/smartwaste/backend/internal/config"
 	"github.com/smartwaste/backend/internal/models"
 	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/pkg/events"
 )
 
-// NotificationService handles notifications to drivers
+// NotificationService handles notifications to drivers and users. Each is
+// saved to the recipient's inbox, then delivered over the first of their
+// channels that reaches them. Bin alerts to the nearest driver are not
+// repeated within the dedup window and escalate until acknowledged; full bins
+// are offered to the drivers they alert as tasks.
 type NotificationService struct {
 	driverRepo       *repository.DriverRepository
-	notificationRepo *NotificationRepository
+	userRepo         *repository.UserRepository
+	binRepo          *repository.BinRepository
+	notificationRepo *repository.NotificationRepository
+	taskRepo         *repository.TaskOfferRepository
+	settings         *SettingsService
+	channels         map[models.NotificationChannel]NotificationChannelSender
+	defaultChannels  []models.NotificationChannel
+	alerts           *config.AlertConfig
 }
 
-// NotificationRepository handles notification data operations
-type NotificationRepository struct {
-	// This would be implemented similar to other repositories
-	// For now, we'll log notifications as a placeholder
-}
-
-// NewNotificationService creates a new NotificationService
-func NewNotificationService(driverRepo *repository.DriverRepository) *NotificationService {
+// NewNotificationService creates a new NotificationService delivering over
+// the given channels, in the configured order for recipients without
+// preferences; the automatic notifications turned off in the runtime
+// settings are not sent
+func NewNotificationService(
+	driverRepo *repository.DriverRepository,
+	userRepo *repository.UserRepository,
+	binRepo *repository.BinRepository,
+	notificationRepo *repository.NotificationRepository,
+	taskRepo *repository.TaskOfferRepository,
+	settings *SettingsService,
+	channels map[models.NotificationChannel]NotificationChannelSender,
+	cfg *config.NotificationChannelConfig,
+	alerts *config.AlertConfig,
+) *NotificationService {
+	defaultChannels := make([]models.NotificationChannel, len(cfg.Default))
+	for i, name := range cfg.Default {
+		defaultChannels[i] = models.NotificationChannel(name)
+	}
 	return &NotificationService{
 		driverRepo:       driverRepo,
-		notificationRepo: &NotificationRepository{},
+		userRepo:         userRepo,
+		binRepo:          binRepo,
+		notificationRepo: notificationRepo,
+		taskRepo:         taskRepo,
+		settings:         settings,
+		channels:         channels,
+		defaultChannels:  defaultChannels,
+		alerts:           alerts,
 	}
 }
 
-// NotifyNearestDriver finds the nearest driver and sends them a notification
+// NotifyNearestDriver finds the nearest driver of the bin's organization and
+// sends them a notification, unless the bin already raised one within the
+// dedup window
 func (s *NotificationService) NotifyNearestDriver(ctx context.Context, bin *models.Bin) error {
+	if !s.settings.Current().Notifications.BinFull {
+		return nil
+	}
+	ctx = auth.WithOrganization(ctx, bin.OrganizationID)
+	if alerted, err := s.recentlyAlerted(ctx, bin, models.NotificationTypeBinFull); err != nil || alerted {
+		return err
+	}
 	log.Printf("Finding nearest driver for bin %s at location (%.6f, %.6f)",
 		bin.DeviceID, bin.Latitude, bin.Longitude)
 
@@ -46,69 +90,549 @@ func (s *NotificationService) NotifyNearestDriver(ctx context.Context, bin *mode
 		return nil
 	}
 
-	// Create notification
+	if err := s.alertDriver(ctx, driver, binFullAlert(bin)); err != nil {
+		return err
+	}
+
+	log.Printf("Notification sent to driver %s (%s) for bin %s",
+		driver.ID, driver.FullName, bin.DeviceID)
+
+	return nil
+}
+
+// NotifyLowBattery alerts the nearest driver of the bin's organization that
+// its sensor needs a battery replacement
+func (s *NotificationService) NotifyLowBattery(ctx context.Context, bin *models.Bin) error {
+	if !s.settings.Current().Notifications.LowBattery {
+		return nil
+	}
+	if bin.BatteryLevel == nil {
+		return nil
+	}
+	ctx = auth.WithOrganization(ctx, bin.OrganizationID)
+	if alerted, err := s.recentlyAlerted(ctx, bin, models.NotificationTypeLowBattery); err != nil || alerted {
+		return err
+	}
+
+	driver, err := s.driverRepo.GetNearestDriver(ctx, bin.Latitude, bin.Longitude)
+	if err != nil {
+		return fmt.Errorf("failed to find nearest driver: %w", err)
+	}
+
+	if driver == nil {
+		log.Printf("No available drivers found for low battery on bin %s", bin.DeviceID)
+		return nil
+	}
+
+	if err := s.alertDriver(ctx, driver, lowBatteryAlert(bin)); err != nil {
+		return err
+	}
+
+	log.Printf("Low battery notification sent to driver %s for bin %s", driver.ID, bin.DeviceID)
+	return nil
+}
+
+// alertDriver saves a bin alert to the driver's inbox, offers them the bin as
+// a task when it is full, then delivers the alert. A failed delivery leaves
+// the alert in their inbox.
+func (s *NotificationService) alertDriver(ctx context.Context, driver *models.Driver, notification *models.Notification) error {
+	notification.DriverID = &driver.ID
+	if err := s.notificationRepo.Create(ctx, notification); err != nil {
+		return fmt.Errorf("failed to save notification: %w", err)
+	}
+
+	if notification.Type == models.NotificationTypeBinFull {
+		offer := &models.TaskOffer{BinID: *notification.BinID, DriverID: driver.ID, NotificationID: &notification.ID}
+		if err := s.taskRepo.Create(ctx, offer); err != nil {
+			log.Printf("Failed to offer bin %s to driver %s: %v", *notification.BinID, driver.ID, err)
+		}
+	}
+
+	if err := s.deliver(ctx, driver.Recipient(), notification); err != nil {
+		log.Printf("Failed to deliver notification: %v", err)
+	}
+	return nil
+}
+
+// binFullAlert builds the first notification of a full bin alert
+func binFullAlert(bin *models.Bin) *models.Notification {
+	location := bin.DeviceID
+	if bin.LocationName != nil {
+		location = *bin.LocationName
+	}
+	id := uuid.New()
+	return &models.Notification{
+		ID:      id,
+		BinID:   &bin.ID,
+		Type:    models.NotificationTypeBinFull,
+		Title:   "Bin Collection Required",
+		Message: fmt.Sprintf("Bin %s at %s is %d%% full and requires collection.", bin.DeviceID, location, bin.FillLevel),
+		AlertID: &id,
+	}
+}
+
+// lowBatteryAlert builds the first notification of a low battery alert
+func lowBatteryAlert(bin *models.Bin) *models.Notification {
+	location := bin.DeviceID
+	if bin.LocationName != nil {
+		location = *bin.LocationName
+	}
+	id := uuid.New()
+	return &models.Notification{
+		ID:    id,
+		BinID: &bin.ID,
+		Type:  models.NotificationTypeLowBattery,
+		Title: "Sensor Battery Low",
+		Message: fmt.Sprintf(
+			"The sensor in bin %s at %s is at %d%% battery and needs replacing.",
+			bin.DeviceID,
+			location,
+			*bin.BatteryLevel,
+		),
+		AlertID: &id,
+	}
+}
+
+// recentlyAlerted returns true if the bin raised an alert of the type within
+// the dedup window and since it was last emptied
+func (s *NotificationService) recentlyAlerted(ctx context.Context, bin *models.Bin, notificationType models.NotificationType) (bool, error) {
+	since := time.Now().Add(-s.alerts.DedupWindow)
+	if notificationType == models.NotificationTypeBinFull && bin.LastCollectionAt != nil && bin.LastCollectionAt.After(since) {
+		since = *bin.LastCollectionAt
+	}
+	alerted, err := s.notificationRepo.HasRecentAlert(ctx, bin.ID, notificationType, since)
+	if err != nil {
+		return false, fmt.Errorf("failed to check recent alerts: %w", err)
+	}
+	if alerted {
+		log.Printf("Bin %s already raised a %s alert since %s, not alerting again",
+			bin.DeviceID, notificationType, since.UTC().Format(time.RFC3339))
+	}
+	return alerted, nil
+}
+
+// EscalateAlerts escalates the bin alerts nobody acknowledged within the
+// configured time: a driver's alert goes to the next-nearest driver, then to
+// the dispatchers of the bin's organization. Alerts whose bin no longer needs
+// them stop escalating. It returns the number of alerts escalated.
+func (s *NotificationService) EscalateAlerts(ctx context.Context) (int, error) {
+	due, err := s.notificationRepo.ClaimEscalations(ctx, time.Now().Add(-s.alerts.EscalateAfter))
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim alerts to escalate: %w", err)
+	}
+
+	escalated := 0
+	for i := range due {
+		ok, err := s.escalate(ctx, &due[i], false)
+		if err != nil {
+			log.Printf("Failed to escalate notification %s: %v", due[i].ID, err)
+			continue
+		}
+		if ok {
+			escalated++
+		}
+	}
+	return escalated, nil
+}
+
+// ReassignAlert passes a bin alert a driver declined on to the next-nearest
+// driver who was not sent it yet, or to the dispatchers when none is left.
+// An alert the escalation job already passed on is left to it.
+func (s *NotificationService) ReassignAlert(ctx context.Context, notificationID uuid.UUID) error {
+	notification, err := s.notificationRepo.GetByID(ctx, notificationID)
+	if err != nil {
+		return fmt.Errorf("failed to get notification: %w", err)
+	}
+	if notification == nil || notification.AlertID == nil {
+		return nil
+	}
+	claimed, err := s.notificationRepo.MarkEscalated(ctx, notification.ID)
+	if err != nil {
+		return fmt.Errorf("failed to claim notification: %w", err)
+	}
+	if !claimed {
+		return nil
+	}
+	_, err = s.escalate(ctx, notification, true)
+	return err
+}
+
+// escalate sends an alert notification on to the next level, returning false
+// if its bin no longer needs it or nobody is left to notify. A notification
+// the driver declined goes to another driver whatever its level.
+func (s *NotificationService) escalate(ctx context.Context, previous *models.Notification, declined bool) (bool, error) {
+	if previous.BinID == nil {
+		return false, nil
+	}
+	bin, err := s.binRepo.GetByID(ctx, *previous.BinID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get bin: %w", err)
+	}
+	if bin == nil {
+		return false, nil
+	}
+
+	var alert *models.Notification
+	switch previous.Type {
+	case models.NotificationTypeBinFull:
+		if !bin.NeedsAlert() {
+			return false, nil
+		}
+		alert = binFullAlert(bin)
+	case models.NotificationTypeLowBattery:
+		if bin.BatteryLevel == nil || *bin.BatteryLevel >= s.settings.Current().Thresholds.LowBattery {
+			return false, nil
+		}
+		alert = lowBatteryAlert(bin)
+	default:
+		return false, nil
+	}
+	alert.AlertID = previous.AlertID
+	if !declined {
+		alert.Title += " (Escalated)"
+	}
+	ctx = auth.WithOrganization(ctx, bin.OrganizationID)
+
+	if declined || previous.EscalationLevel == models.EscalationNearestDriver {
+		notified, err := s.notificationRepo.AlertDrivers(ctx, *previous.AlertID)
+		if err != nil {
+			return false, fmt.Errorf("failed to get notified drivers: %w", err)
+		}
+		driver, err := s.driverRepo.GetNearestDriverExcluding(ctx, bin.Latitude, bin.Longitude, notified)
+		if err != nil {
+			return false, fmt.Errorf("failed to find next-nearest driver: %w", err)
+		}
+		if driver != nil {
+			alert.EscalationLevel = models.EscalationNextNearestDriver
+			if declined {
+				alert.Message += " Another driver declined it."
+			} else {
+				alert.Message += " The nearest driver has not acknowledged it."
+			}
+			if err := s.alertDriver(ctx, driver, alert); err != nil {
+				return false, err
+			}
+			log.Printf("Alert %s for bin %s passed on to driver %s", *previous.AlertID, bin.DeviceID, driver.ID)
+			return true, nil
+		}
+	}
+
+	dispatchers, err := s.userRepo.ListByRole(ctx, models.RoleDispatcher)
+	if err != nil {
+		return false, fmt.Errorf("failed to get dispatchers: %w", err)
+	}
+	if len(dispatchers) == 0 {
+		log.Printf("No dispatchers to escalate alert %s for bin %s to", *previous.AlertID, bin.DeviceID)
+		return false, nil
+	}
+	alert.EscalationLevel = models.EscalationDispatchers
+	if declined {
+		alert.Message += " It was declined and no other driver is available."
+	} else {
+		alert.Message += " No driver has acknowledged it."
+	}
+	for i, dispatcher := range dispatchers {
+		notification := *alert
+		if i > 0 {
+			notification.ID = uuid.New()
+		}
+		if err := s.NotifyUser(ctx, dispatcher.ID, &notification); err != nil {
+			log.Printf("Failed to notify dispatcher %s: %v", dispatcher.ID, err)
+		}
+	}
+	log.Printf("Alert %s for bin %s escalated to %d dispatchers", *previous.AlertID, bin.DeviceID, len(dispatchers))
+	return true, nil
+}
+
+// NotifyBinOffline records a system alert for a bin whose sensor stopped reporting.
+// The alert has no recipient driver; it is for operations staff.
+func (s *NotificationService) NotifyBinOffline(ctx context.Context, bin *models.Bin) error {
+	if !s.settings.Current().Notifications.BinOffline {
+		return nil
+	}
 	notification := &models.Notification{
-		ID:       uuid.New(),
-		DriverID: &driver.ID,
-		BinID:    &bin.ID,
-		Type:     models.NotificationTypeBinFull,
-		Title:    "Bin Collection Required",
+		ID:    uuid.New(),
+		BinID: &bin.ID,
+		Type:  models.NotificationTypeSystemAlert,
+		Title: "Bin Sensor Offline",
 		Message: fmt.Sprintf(
-			"Bin %s at %s is %d%% full and requires collection.",
+			"Bin %s has not reported since %s.",
 			bin.DeviceID,
-			*bin.LocationName,
-			bin.FillLevel,
+			bin.LastUpdatedAt.UTC().Format("2006-01-02 15:04 MST"),
 		),
 	}
 
-	// Send FCM notification (placeholder)
-	if err := s.sendFCMNotification(driver, notification); err != nil {
-		log.Printf("Failed to send FCM notification: %v", err)
-		// Continue even if FCM fails - save notification for later retrieval
+	if err := s.notificationRepo.Create(ctx, notification); err != nil {
+		return fmt.Errorf("failed to save notification: %w", err)
 	}
 
-	log.Printf("Notification sent to driver %s (%s) for bin %s",
-		driver.ID, driver.FullName, bin.DeviceID)
+	log.Printf("[SYSTEM ALERT] %s: %s", notification.Title, notification.Message)
+	return nil
+}
+
+// NotifyBinFlagged records a system alert for a bin flagged for inspection
+// after several people reported an issue with it
+func (s *NotificationService) NotifyBinFlagged(ctx context.Context, bin *models.Bin, reporters int) error {
+	if !s.settings.Current().Notifications.BinFlagged {
+		return nil
+	}
+	notification := &models.Notification{
+		ID:      uuid.New(),
+		BinID:   &bin.ID,
+		Type:    models.NotificationTypeSystemAlert,
+		Title:   "Bin Flagged for Inspection",
+		Message: fmt.Sprintf("Bin %s was reported by %d people and needs inspection.", bin.DeviceID, reporters),
+	}
+
+	if err := s.notificationRepo.Create(ctx, notification); err != nil {
+		return fmt.Errorf("failed to save notification: %w", err)
+	}
 
+	log.Printf("[SYSTEM ALERT] %s: %s", notification.Title, notification.Message)
 	return nil
 }
 
-// sendFCMNotification sends a push notification via Firebase Cloud Messaging
-// This is a placeholder implementation - in production, integrate with FCM SDK
-func (s *NotificationService) sendFCMNotification(driver *models.Driver, notification *models.Notification) error {
-	// Placeholder for FCM integration
-	// In production:
-	// 1. Use firebase.google.com/go/messaging
-	// 2. Create message with driver.FCMToken
-	// 3. Send via messaging.Client.Send()
+// NotifySLABreach records a system alert for a bin that was not emptied within
+// its collection SLA. Like offline alerts, it is for operations staff.
+func (s *NotificationService) NotifySLABreach(ctx context.Context, breach *models.SLABreach) error {
+	if !s.settings.Current().Notifications.SLABreach {
+		return nil
+	}
+	notification := &models.Notification{
+		ID:    uuid.New(),
+		BinID: &breach.BinID,
+		Type:  models.NotificationTypeSystemAlert,
+		Title: "Collection SLA Breached",
+		Message: fmt.Sprintf(
+			"Bin %s reached its collection threshold at %s and was not emptied within %d hours.",
+			breach.DeviceID,
+			breach.ThresholdCrossedAt.UTC().Format("2006-01-02 15:04 MST"),
+			breach.MaxHours,
+		),
+	}
+
+	if err := s.notificationRepo.Create(ctx, notification); err != nil {
+		return fmt.Errorf("failed to save notification: %w", err)
+	}
+
+	log.Printf("[SYSTEM ALERT] %s: %s", notification.Title, notification.Message)
+	return nil
+}
 
-	if driver.FCMToken == nil || *driver.FCMToken == "" {
-		log.Printf("Driver %s has no FCM token, skipping push notification", driver.ID)
+// NotifyFireHazard records an alert for a bin whose temperature reached the
+// fire hazard threshold. Like offline alerts, it is for operations staff.
+func (s *NotificationService) NotifyFireHazard(ctx context.Context, bin *models.Bin, threshold float64) error {
+	if !s.settings.Current().Notifications.FireHazard {
+		return nil
+	}
+	if bin.TemperatureC == nil {
 		return nil
 	}
 
-	log.Printf("[FCM PLACEHOLDER] Sending notification to driver %s:", driver.ID)
-	log.Printf("  Token: %s", *driver.FCMToken)
-	log.Printf("  Title: %s", notification.Title)
-	log.Printf("  Message: %s", notification.Message)
-
-	// In production, implement actual FCM sending:
-	/*
-		msg := &messaging.Message{
-			Notification: &messaging.Notification{
-				Title: notification.Title,
-				Body:  notification.Message,
-			},
-			Token: *driver.FCMToken,
-			Data: map[string]string{
-				"bin_id": notification.BinID.String(),
-				"type":   string(notification.Type),
-			},
-		}
-		_, err := fcmClient.Send(ctx, msg)
-		return err
-	*/
+	location := bin.DeviceID
+	if bin.LocationName != nil {
+		location = *bin.LocationName
+	}
+
+	notification := &models.Notification{
+		ID:    uuid.New(),
+		BinID: &bin.ID,
+		Type:  models.NotificationTypeFireHazard,
+		Title: "Possible Fire in Bin",
+		Message: fmt.Sprintf(
+			"Bin %s at %s is at %.1f°C, at or above the fire hazard threshold of %.1f°C.",
+			bin.DeviceID,
+			location,
+			*bin.TemperatureC,
+			threshold,
+		),
+	}
+
+	if err := s.notificationRepo.Create(ctx, notification); err != nil {
+		return fmt.Errorf("failed to save notification: %w", err)
+	}
+
+	log.Printf("[SYSTEM ALERT] %s: %s", notification.Title, notification.Message)
+	return nil
+}
+
+// deliver sends a saved notification over the recipient's channels in order,
+// falling back to the next channel when one fails or cannot reach them. A
+// recipient no channel can reach, or a driver whose settings withhold the
+// notification, only has it in their inbox.
+func (s *NotificationService) deliver(ctx context.Context, recipient *models.NotificationRecipient, notification *models.Notification) error {
+	if recipient.Type == models.NotificationRecipientDriver {
+		reason, err := s.withheld(ctx, recipient.ID, notification)
+		if err != nil {
+			log.Printf("Failed to check the notification settings of driver %s: %v", recipient.ID, err)
+		}
+		if reason != "" {
+			log.Printf("Notification %s to driver %s is only in their inbox: %s", notification.ID, recipient.ID, reason)
+			return nil
+		}
+	}
+
+	channels, _, err := s.notificationRepo.GetChannels(ctx, recipient.Type, recipient.ID)
+	if err != nil {
+		log.Printf("Failed to get notification channels of %s %s, using the defaults: %v", recipient.Type, recipient.ID, err)
+	}
+	if channels == nil {
+		channels = s.defaultChannels
+	}
+
+	var failures []string
+	for _, channel := range channels {
+		sender, ok := s.channels[channel]
+		if !ok {
+			continue
+		}
+		err := sender.Send(ctx, recipient, notification)
+		if err == nil {
+			if err := s.notificationRepo.MarkDelivered(ctx, notification); err != nil {
+				log.Printf("Failed to mark notification %s delivered: %v", notification.ID, err)
+			}
+			log.Printf("Notification %s delivered to %s %s over %s", notification.ID, recipient.Type, recipient.ID, channel)
+			return nil
+		}
+		if !errors.Is(err, ErrNoChannelAddress) {
+			failures = append(failures, fmt.Sprintf("%s: %v", channel, err))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("notification %s not delivered to %s %s (%s)", notification.ID, recipient.Type, recipient.ID, strings.Join(failures, "; "))
+	}
+	log.Printf("No channel reaches %s %s, notification %s is only in their inbox", recipient.Type, recipient.ID, notification.ID)
+	return nil
+}
+
+// withheld returns why the driver's notification settings keep a
+// notification from being delivered over their channels, or "" if they do not
+func (s *NotificationService) withheld(ctx context.Context, driverID uuid.UUID, notification *models.Notification) (string, error) {
+	settings, err := s.notificationRepo.GetDriverSettings(ctx, driverID)
+	if err != nil || settings == nil {
+		return "", err
+	}
+
+	now := time.Now()
+	switch {
+	case !settings.Wants(notification.Type):
+		return fmt.Sprintf("%s notifications are turned off", notification.Type), nil
+	case settings.InQuietHours(now):
+		return "quiet hours", nil
+	case settings.MaxPerHour != nil:
+		delivered, err := s.notificationRepo.CountDelivered(ctx, driverID, now.Add(-time.Hour))
+		if err != nil {
+			return "", err
+		}
+		if delivered >= *settings.MaxPerHour {
+			return fmt.Sprintf("%d notifications were delivered in the last hour", delivered), nil
+		}
+	}
+	return "", nil
+}
+
+// DriverSettings retrieves the notification settings of a driver, which
+// deliver everything if they saved none
+func (s *NotificationService) DriverSettings(ctx context.Context, driverID uuid.UUID) (*models.DriverNotificationSettings, error) {
+	if err := s.checkRecipient(ctx, models.NotificationRecipientDriver, driverID); err != nil {
+		return nil, err
+	}
+	settings, err := s.notificationRepo.GetDriverSettings(ctx, driverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification settings: %w", err)
+	}
+	if settings == nil {
+		settings = &models.DriverNotificationSettings{DriverID: driverID, Timezone: "UTC", IsDefault: true}
+	}
+	return settings, nil
+}
+
+// UpdateDriverSettings replaces the notification settings of a driver
+func (s *NotificationService) UpdateDriverSettings(ctx context.Context, driverID uuid.UUID, req *models.UpdateDriverNotificationSettingsRequest) (*models.DriverNotificationSettings, error) {
+	if err := s.checkRecipient(ctx, models.NotificationRecipientDriver, driverID); err != nil {
+		return nil, err
+	}
 
+	settings := &models.DriverNotificationSettings{
+		DriverID:        driverID,
+		Types:           req.Types,
+		QuietHoursStart: req.QuietHoursStart,
+		QuietHoursEnd:   req.QuietHoursEnd,
+		Timezone:        req.Timezone,
+		MaxPerHour:      req.MaxPerHour,
+	}
+	if settings.Timezone == "" {
+		settings.Timezone = "UTC"
+	}
+	if err := s.notificationRepo.SetDriverSettings(ctx, settings); err != nil {
+		return nil, fmt.Errorf("failed to save notification settings: %w", err)
+	}
+	return settings, nil
+}
+
+// ChannelPreferences retrieves the channels a driver or user is notified
+// over, the configured defaults if they chose none
+func (s *NotificationService) ChannelPreferences(ctx context.Context, recipientType models.NotificationRecipientType, recipientID uuid.UUID) (*models.NotificationChannelPreferences, error) {
+	if err := s.checkRecipient(ctx, recipientType, recipientID); err != nil {
+		return nil, err
+	}
+	channels, updatedAt, err := s.notificationRepo.GetChannels(ctx, recipientType, recipientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification channels: %w", err)
+	}
+
+	preferences := &models.NotificationChannelPreferences{
+		RecipientType: recipientType,
+		RecipientID:   recipientID,
+		Channels:      channels,
+		UpdatedAt:     updatedAt,
+	}
+	if channels == nil {
+		preferences.Channels = s.defaultChannels
+		preferences.IsDefault = true
+	}
+	return preferences, nil
+}
+
+// SetChannelPreferences saves the channels a driver or user is notified over,
+// tried in the given order
+func (s *NotificationService) SetChannelPreferences(ctx context.Context, recipientType models.NotificationRecipientType, recipientID uuid.UUID, channels []models.NotificationChannel) (*models.NotificationChannelPreferences, error) {
+	if err := s.checkRecipient(ctx, recipientType, recipientID); err != nil {
+		return nil, err
+	}
+	updatedAt, err := s.notificationRepo.SetChannels(ctx, recipientType, recipientID, channels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save notification channels: %w", err)
+	}
+	return &models.NotificationChannelPreferences{
+		RecipientType: recipientType,
+		RecipientID:   recipientID,
+		Channels:      channels,
+		UpdatedAt:     &updatedAt,
+	}, nil
+}
+
+// checkRecipient checks the driver or user exists for the principal of ctx
+func (s *NotificationService) checkRecipient(ctx context.Context, recipientType models.NotificationRecipientType, recipientID uuid.UUID) error {
+	if recipientType == models.NotificationRecipientDriver {
+		driver, err := s.driverRepo.GetByID(ctx, recipientID)
+		if err != nil {
+			return fmt.Errorf("failed to get driver: %w", err)
+		}
+		if driver == nil {
+			return ErrDriverNotFound
+		}
+		return nil
+	}
+
+	user, err := s.userRepo.GetByID(ctx, recipientID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
 	return nil
 }
 
@@ -123,7 +647,53 @@ func (s *NotificationService) NotifyDriver(ctx context.Context, driverID uuid.UU
 	}
 
 	notification.DriverID = &driverID
-	return s.sendFCMNotification(driver, notification)
+	if err := s.notificationRepo.Create(ctx, notification); err != nil {
+		return fmt.Errorf("failed to save notification: %w", err)
+	}
+	return s.deliver(ctx, driver.Recipient(), notification)
+}
+
+// NotifyUser sends a notification to a specific user
+func (s *NotificationService) NotifyUser(ctx context.Context, userID uuid.UUID, notification *models.Notification) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return fmt.Errorf("user not found: %s", userID)
+	}
+
+	notification.UserID = &userID
+	if err := s.notificationRepo.Create(ctx, notification); err != nil {
+		return fmt.Errorf("failed to save notification: %w", err)
+	}
+	return s.deliver(ctx, user.Recipient(), notification)
+}
+
+// NotifyShipmentAvailable tells the available drivers of the organization of
+// ctx that a shipment's price was confirmed and it can be picked up
+func (s *NotificationService) NotifyShipmentAvailable(ctx context.Context, shipment *events.StatusChanged) error {
+	message := fmt.Sprintf("A %s shipment is ready for pickup.", shipment.WasteType)
+	if shipment.WeightKg != nil {
+		message = fmt.Sprintf("A %s shipment of %.1f kg is ready for pickup.", shipment.WasteType, *shipment.WeightKg)
+	}
+	return s.NotifyAllAvailableDrivers(ctx, &models.Notification{
+		ID:      uuid.New(),
+		Type:    models.NotificationTypeShipmentAvailable,
+		Title:   "Shipment Available",
+		Message: message + " Shipment: " + shipment.ShipmentID.String(),
+	})
+}
+
+// NotifyRewardCredited tells a user about the points a reward transaction
+// credited them
+func (s *NotificationService) NotifyRewardCredited(ctx context.Context, txn *models.RewardTransaction) error {
+	return s.NotifyUser(ctx, txn.UserID, &models.Notification{
+		ID:      uuid.New(),
+		Type:    models.NotificationTypeRewardCredited,
+		Title:   "Reward Points Earned",
+		Message: fmt.Sprintf("You earned %d points for your %s %s.", txn.Points, txn.WasteType, txn.Source),
+	})
 }
 
 // NotifyAllAvailableDrivers broadcasts a notification to all available drivers
@@ -138,8 +708,13 @@ func (s *NotificationService) NotifyAllAvailableDrivers(ctx context.Context, not
 		notificationCopy.ID = uuid.New()
 		notificationCopy.DriverID = &driver.ID
 
+		if err := s.notificationRepo.Create(ctx, &notificationCopy); err != nil {
+			log.Printf("Failed to save notification for driver %s: %v", driver.ID, err)
+			continue
+		}
+
 		go func(d models.Driver, n *models.Notification) {
-			if err := s.sendFCMNotification(&d, n); err != nil {
+			if err := s.deliver(context.WithoutCancel(ctx), d.Recipient(), n); err != nil {
 				log.Printf("Failed to notify driver %s: %v", d.ID, err)
 			}
 		}(driver, &notificationCopy)
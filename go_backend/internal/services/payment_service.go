@@ -0,0 +1,266 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/config"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/payments"
+	"github.com/smartwaste/backend/internal/repository"
+)
+
+var (
+	// ErrPaymentAccountMissing is returned when charging or paying out an
+	// owner without a payment account of the provider
+	ErrPaymentAccountMissing = errors.New("no payment account with the payment provider")
+	// ErrPaymentDeclined is returned when the provider refuses a payment
+	ErrPaymentDeclined = errors.New("payment declined")
+	// ErrPaymentProvider is returned when the provider could not be reached or
+	// failed; the payment is left pending for its webhooks to settle
+	ErrPaymentProvider = errors.New("payment provider unavailable")
+	// ErrCompanyNotFound is returned when charging a company that does not exist
+	ErrCompanyNotFound = errors.New("company not found")
+	// ErrUserNotFound is returned when paying out a user that does not exist
+	ErrUserNotFound = errors.New("user not found")
+)
+
+// PaymentService charges companies for collection services and pays out
+// drivers and users through the configured payment provider. Each payment is
+// recorded before the provider is called, keying the request by its ID, and
+// settled by the provider's webhooks when the outcome comes later.
+type PaymentService struct {
+	cfg         *config.PaymentsConfig
+	paymentRepo *repository.PaymentRepository
+	companyRepo *repository.CompanyRepository
+	driverRepo  *repository.DriverRepository
+	userRepo    *repository.UserRepository
+	provider    payments.Provider
+}
+
+// NewPaymentService creates a new PaymentService
+func NewPaymentService(
+	paymentRepo *repository.PaymentRepository,
+	companyRepo *repository.CompanyRepository,
+	driverRepo *repository.DriverRepository,
+	userRepo *repository.UserRepository,
+	provider payments.Provider,
+	cfg *config.PaymentsConfig,
+) *PaymentService {
+	return &PaymentService{
+		cfg:         cfg,
+		paymentRepo: paymentRepo,
+		companyRepo: companyRepo,
+		driverRepo:  driverRepo,
+		userRepo:    userRepo,
+		provider:    provider,
+	}
+}
+
+// SetAccount sets the provider account of a company, driver or user, which
+// must exist for the principal of ctx
+func (s *PaymentService) SetAccount(ctx context.Context, ownerType models.PaymentOwnerType, ownerID uuid.UUID, req *models.SetPaymentAccountRequest) (*models.PaymentAccount, error) {
+	if _, err := s.loadOwner(ctx, ownerType, ownerID); err != nil {
+		return nil, err
+	}
+
+	account := &models.PaymentAccount{
+		OwnerType:     ownerType,
+		OwnerID:       ownerID,
+		Provider:      s.provider.Name(),
+		AccountID:     req.AccountID,
+		PaymentMethod: req.PaymentMethod,
+	}
+	if err := s.paymentRepo.SetAccount(ctx, account); err != nil {
+		return nil, fmt.Errorf("failed to set payment account: %w", err)
+	}
+	return account, nil
+}
+
+// Charge takes an amount from the saved payment method of a company
+func (s *PaymentService) Charge(ctx context.Context, req *models.CreateChargeRequest, createdBy uuid.UUID) (*models.Payment, error) {
+	if _, err := s.loadOwner(ctx, models.PaymentOwnerCompany, req.CompanyID); err != nil {
+		return nil, err
+	}
+	account, err := s.account(ctx, models.PaymentOwnerCompany, req.CompanyID)
+	if err != nil {
+		return nil, err
+	}
+	if account.PaymentMethod == nil {
+		return nil, ErrPaymentAccountMissing
+	}
+
+	payment := s.newPayment(models.PaymentCharge, req.Amount, req.Currency, req.Description, createdBy)
+	payment.CompanyID = &req.CompanyID
+	if err := s.paymentRepo.Create(ctx, payment); err != nil {
+		return nil, fmt.Errorf("failed to record charge: %w", err)
+	}
+
+	result, err := s.provider.Charge(ctx, &payments.Charge{
+		PaymentID:     payment.ID,
+		Amount:        payment.Amount,
+		Currency:      payment.Currency,
+		Customer:      account.AccountID,
+		PaymentMethod: *account.PaymentMethod,
+		Description:   optionalString(payment.Description),
+	})
+	return payment, s.saveResult(ctx, payment, result, err)
+}
+
+// Payout sends an amount to the connected account of a driver or a user
+func (s *PaymentService) Payout(ctx context.Context, req *models.CreatePayoutRequest, createdBy uuid.UUID) (*models.Payment, error) {
+	ownerType, ownerID := models.PaymentOwnerUser, uuid.Nil
+	if req.DriverID != nil {
+		ownerType, ownerID = models.PaymentOwnerDriver, *req.DriverID
+	} else if req.UserID != nil {
+		ownerID = *req.UserID
+	}
+	organizationID, err := s.loadOwner(ctx, ownerType, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	account, err := s.account(ctx, ownerType, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	payment := s.newPayment(models.PaymentPayout, req.Amount, req.Currency, req.Description, createdBy)
+	payment.OrganizationID = organizationID
+	payment.DriverID = req.DriverID
+	payment.UserID = req.UserID
+	if err := s.paymentRepo.Create(ctx, payment); err != nil {
+		return nil, fmt.Errorf("failed to record payout: %w", err)
+	}
+
+	result, err := s.provider.Payout(ctx, &payments.Payout{
+		PaymentID:   payment.ID,
+		Amount:      payment.Amount,
+		Currency:    payment.Currency,
+		Account:     account.AccountID,
+		Description: optionalString(payment.Description),
+	})
+	return payment, s.saveResult(ctx, payment, result, err)
+}
+
+// HandleWebhook verifies a webhook of the named provider and records the
+// payment status it reports. Events about other payments are ignored.
+func (s *PaymentService) HandleWebhook(ctx context.Context, provider string, payload []byte, header http.Header) error {
+	if provider != s.provider.Name() {
+		return payments.ErrWebhooksUnsupported
+	}
+	event, err := s.provider.ParseWebhook(payload, header)
+	if err != nil || event == nil {
+		return err
+	}
+
+	var failureReason *string
+	if event.FailureReason != "" {
+		failureReason = &event.FailureReason
+	}
+	payment, err := s.paymentRepo.ApplyWebhook(ctx, event.PaymentID, provider, models.PaymentStatus(event.Status), &event.Reference, failureReason)
+	if err != nil {
+		return fmt.Errorf("failed to apply webhook %s: %w", event.ID, err)
+	}
+	if payment != nil {
+		log.Printf("Payment %s is %s after webhook %s", payment.ID, payment.Status, event.ID)
+	}
+	return nil
+}
+
+// newPayment builds a payment of the configured provider, in the configured
+// currency unless one is given
+func (s *PaymentService) newPayment(kind models.PaymentKind, amount float64, currency, description *string, createdBy uuid.UUID) *models.Payment {
+	payment := &models.Payment{
+		Kind:        kind,
+		Amount:      roundCents(amount),
+		Currency:    s.cfg.Currency,
+		Description: description,
+		Provider:    s.provider.Name(),
+		CreatedBy:   &createdBy,
+	}
+	if currency != nil {
+		payment.Currency = strings.ToUpper(*currency)
+	}
+	return payment
+}
+
+// saveResult records the outcome of calling the provider for a payment. A
+// declined payment fails; one the provider did not answer stays pending, as
+// it may still have gone through.
+func (s *PaymentService) saveResult(ctx context.Context, payment *models.Payment, result *payments.Result, callErr error) error {
+	switch {
+	case errors.Is(callErr, payments.ErrDeclined):
+		reason := callErr.Error()
+		payment.Status = models.PaymentFailed
+		payment.FailureReason = &reason
+	case callErr != nil:
+		log.Printf("Payment %s left pending: %v", payment.ID, callErr)
+		return fmt.Errorf("%w: %v", ErrPaymentProvider, callErr)
+	default:
+		payment.Status = models.PaymentStatus(result.Status)
+		payment.Reference = &result.Reference
+		if result.FailureReason != "" {
+			payment.FailureReason = &result.FailureReason
+		}
+	}
+
+	if err := s.paymentRepo.SaveResult(ctx, payment); err != nil {
+		return fmt.Errorf("failed to save payment: %w", err)
+	}
+	if payment.Status == models.PaymentFailed {
+		return fmt.Errorf("%w: %s", ErrPaymentDeclined, optionalString(payment.FailureReason))
+	}
+	return nil
+}
+
+// account retrieves the payment account of an owner with the configured
+// provider
+func (s *PaymentService) account(ctx context.Context, ownerType models.PaymentOwnerType, ownerID uuid.UUID) (*models.PaymentAccount, error) {
+	account, err := s.paymentRepo.GetAccount(ctx, ownerType, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch payment account: %w", err)
+	}
+	if account == nil || account.Provider != s.provider.Name() {
+		return nil, ErrPaymentAccountMissing
+	}
+	return account, nil
+}
+
+// loadOwner checks the owner of a payment account exists for the principal of
+// ctx, returning the organization of drivers and users
+func (s *PaymentService) loadOwner(ctx context.Context, ownerType models.PaymentOwnerType, ownerID uuid.UUID) (uuid.UUID, error) {
+	switch ownerType {
+	case models.PaymentOwnerCompany:
+		company, err := s.companyRepo.GetByID(ctx, ownerID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		if company == nil {
+			return uuid.Nil, ErrCompanyNotFound
+		}
+		return uuid.Nil, nil
+	case models.PaymentOwnerDriver:
+		driver, err := s.driverRepo.GetByID(ctx, ownerID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		if driver == nil {
+			return uuid.Nil, ErrDriverNotFound
+		}
+		return driver.OrganizationID, nil
+	default:
+		user, err := s.userRepo.GetByID(ctx, ownerID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		if user == nil {
+			return uuid.Nil, ErrUserNotFound
+		}
+		return user.OrganizationID, nil
+	}
+}
@@ -0,0 +1,277 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/config"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+)
+
+// pickupShipmentBatchSize bounds the pickups shipped per run
+const pickupShipmentBatchSize = 50
+
+var (
+	// ErrPickupClosed is returned when changing a pickup request that was
+	// collected or cancelled
+	ErrPickupClosed = errors.New("pickup request is no longer open")
+	// ErrPickupNotAssigned is returned when completing a pickup request no
+	// driver was assigned to
+	ErrPickupNotAssigned = errors.New("pickup request is not assigned to a driver")
+	// ErrNoDriverAvailable is returned when no available driver can take a
+	// pickup request
+	ErrNoDriverAvailable = errors.New("no driver is available for this pickup")
+)
+
+// PickupRequestService handles the on-demand pickups citizens request. A
+// request is valued when filed, dispatched to a driver and, once collected,
+// priced at the collected weight and shipped to the shipment tracker.
+// Shipments that fail are retried by the pickup shipment job.
+type PickupRequestService struct {
+	cfg                 *config.SagaConfig
+	pickupRepo          *repository.PickupRequestRepository
+	valuationSvc        *ValuationService
+	uploadService       *UploadService
+	notificationService *NotificationService
+	earningSvc          *EarningService
+	tracker             *ShipmentTrackerClient
+}
+
+// NewPickupRequestService creates a new PickupRequestService. The tracker is
+// nil when no shipment tracker is configured and collected pickups are not
+// shipped.
+func NewPickupRequestService(
+	pickupRepo *repository.PickupRequestRepository,
+	valuationSvc *ValuationService,
+	uploadService *UploadService,
+	notificationService *NotificationService,
+	earningSvc *EarningService,
+	tracker *ShipmentTrackerClient,
+	cfg *config.SagaConfig,
+) *PickupRequestService {
+	return &PickupRequestService{
+		cfg:                 cfg,
+		pickupRepo:          pickupRepo,
+		valuationSvc:        valuationSvc,
+		uploadService:       uploadService,
+		notificationService: notificationService,
+		earningSvc:          earningSvc,
+		tracker:             tracker,
+	}
+}
+
+// Create files a pickup request for a user, valued at its estimated weight.
+// The photos must be uploads of the user.
+func (s *PickupRequestService) Create(ctx context.Context, userID uuid.UUID, req *models.CreatePickupRequestRequest) (*models.PickupRequest, error) {
+	photos := make([]*models.Upload, 0, len(req.PhotoURLs))
+	for _, url := range req.PhotoURLs {
+		photo, err := s.uploadService.Resolve(ctx, url, userID)
+		if err != nil {
+			return nil, err
+		}
+		photos = append(photos, photo)
+	}
+
+	valuation, err := s.value(ctx, req.WasteType, req.EstimatedWeightKg)
+	if err != nil {
+		return nil, err
+	}
+
+	pickup := &models.PickupRequest{
+		UserID:            userID,
+		WasteType:         req.WasteType,
+		EstimatedWeightKg: req.EstimatedWeightKg,
+		PhotoURLs:         models.PhotoURLs(req.PhotoURLs),
+		Latitude:          req.Latitude,
+		Longitude:         req.Longitude,
+		Address:           req.Address,
+		Notes:             req.Notes,
+		EstimatedValue:    &valuation.TotalPrice,
+		Currency:          &valuation.Currency,
+	}
+	if err := s.pickupRepo.Create(ctx, pickup); err != nil {
+		return nil, fmt.Errorf("failed to create pickup request: %w", err)
+	}
+	for _, photo := range photos {
+		s.uploadService.Attach(ctx, photo)
+	}
+	return pickup, nil
+}
+
+// Complete records the weight the driver collected, prices the pickup at it,
+// credits the driver and notifies its requester. The waste is shipped to the
+// shipment tracker when it is worth something; a shipment that fails is left
+// to the job.
+func (s *PickupRequestService) Complete(ctx context.Context, pickup *models.PickupRequest, req *models.CompletePickupRequest) error {
+	switch {
+	case !pickup.Status.IsOpen():
+		return ErrPickupClosed
+	case pickup.Status != models.PickupStatusAssigned:
+		return ErrPickupNotAssigned
+	}
+
+	valuation, err := s.value(ctx, pickup.WasteType, req.WeightKg)
+	if err != nil {
+		return err
+	}
+
+	pickup.Status = models.PickupStatusCollected
+	pickup.WeightKg = &req.WeightKg
+	pickup.PriceOffered = &valuation.TotalPrice
+	pickup.Currency = &valuation.Currency
+	if req.Notes != nil {
+		pickup.Notes = req.Notes
+	}
+	if err := s.pickupRepo.UpdateStatus(ctx, pickup, models.PickupStatusAssigned); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrPickupClosed
+		}
+		return fmt.Errorf("failed to complete pickup request: %w", err)
+	}
+
+	notification := &models.Notification{
+		ID:    uuid.New(),
+		Type:  models.NotificationTypePickupCollected,
+		Title: "Pickup Collected",
+		Message: fmt.Sprintf(
+			"Your %s pickup was collected: %.1f kg valued at %.2f %s.",
+			pickup.WasteType,
+			req.WeightKg,
+			valuation.TotalPrice,
+			valuation.Currency,
+		),
+	}
+	if err := s.notificationService.NotifyUser(ctx, pickup.UserID, notification); err != nil {
+		log.Printf("Failed to notify user %s of pickup %s: %v", pickup.UserID, pickup.ID, err)
+	}
+	if _, err := s.earningSvc.CreditPickup(ctx, pickup); err != nil {
+		log.Printf("Failed to credit driver earning for pickup %s: %v", pickup.ID, err)
+	}
+
+	if s.tracker != nil && valuation.TotalPrice > 0 {
+		if err := s.ship(ctx, pickup); err != nil {
+			log.Printf("Failed to ship pickup %s, retrying later: %v", pickup.ID, err)
+		}
+	}
+	return nil
+}
+
+// Cancel cancels a pickup request that was not collected yet
+func (s *PickupRequestService) Cancel(ctx context.Context, pickup *models.PickupRequest) error {
+	if !pickup.Status.IsOpen() {
+		return ErrPickupClosed
+	}
+
+	from := pickup.Status
+	pickup.Status = models.PickupStatusCancelled
+	if err := s.pickupRepo.UpdateStatus(ctx, pickup, from); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrPickupClosed
+		}
+		return fmt.Errorf("failed to cancel pickup request: %w", err)
+	}
+	return nil
+}
+
+// ShipPending ships the collected pickups of every organization whose
+// shipment failed before, returning how many were shipped
+func (s *PickupRequestService) ShipPending(ctx context.Context) (int, error) {
+	if s.tracker == nil {
+		return 0, nil
+	}
+
+	pickups, err := s.pickupRepo.ListUnshipped(ctx, s.cfg.MaxAttempts, pickupShipmentBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list unshipped pickups: %w", err)
+	}
+
+	shipped := 0
+	for i := range pickups {
+		if err := s.ship(ctx, &pickups[i]); err != nil {
+			log.Printf("Failed to ship pickup %s: %v", pickups[i].ID, err)
+			continue
+		}
+		shipped++
+	}
+	return shipped, nil
+}
+
+// ship creates the shipment of a collected pickup, unless an earlier attempt
+// that seemed to fail did, and records the outcome. Pickups the tracker
+// refuses are not retried.
+func (s *PickupRequestService) ship(ctx context.Context, pickup *models.PickupRequest) error {
+	shipment, err := s.createShipment(ctx, pickup)
+	if err != nil {
+		reason := err.Error()
+		pickup.ShipmentAttempts++
+		if errors.Is(err, ErrTrackerRejected) {
+			pickup.ShipmentAttempts = s.cfg.MaxAttempts
+		}
+		pickup.ShipmentError = &reason
+		if saveErr := s.pickupRepo.SaveShipment(ctx, pickup); saveErr != nil {
+			return fmt.Errorf("failed to save pickup shipment: %w", saveErr)
+		}
+		return err
+	}
+
+	pickup.ShipmentID = &shipment.ID
+	pickup.ShipmentError = nil
+	if err := s.pickupRepo.SaveShipment(ctx, pickup); err != nil {
+		return fmt.Errorf("failed to save pickup shipment: %w", err)
+	}
+	log.Printf("Created shipment %s for pickup %s", shipment.ID, pickup.ID)
+	return nil
+}
+
+// createShipment creates the shipment of a pickup in the shipment tracker,
+// which keys it by the pickup's ID
+func (s *PickupRequestService) createShipment(ctx context.Context, pickup *models.PickupRequest) (*TrackerShipment, error) {
+	if pickup.ShipmentAttempts > 0 {
+		existing, err := s.tracker.FindByCollection(ctx, pickup.OrganizationID, pickup.ID)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return existing, nil
+		}
+	}
+
+	weight := pickup.EstimatedWeightKg
+	if pickup.WeightKg != nil {
+		weight = *pickup.WeightKg
+	}
+	var price float64
+	if pickup.PriceOffered != nil {
+		price = *pickup.PriceOffered
+	}
+	req := &CreateTrackerShipment{
+		UserID:            pickup.UserID,
+		CollectionID:      pickup.ID,
+		WasteType:         pickup.WasteType,
+		EstimatedWeightKg: weight,
+		PriceOffered:      price,
+		PickupLocation:    &TrackerLocation{Latitude: pickup.Latitude, Longitude: pickup.Longitude},
+		Notes:             pickup.Notes,
+	}
+	if pickup.Address != nil {
+		req.PickupLocation.Address = *pickup.Address
+	}
+	return s.tracker.CreateShipment(ctx, pickup.OrganizationID, req)
+}
+
+// value values waste of a pickup with the global pricing rules
+func (s *PickupRequestService) value(ctx context.Context, wasteType string, weightKg float64) (*models.ValuationResponse, error) {
+	valuation, err := s.valuationSvc.CalculateValue(ctx, &models.ValuationRequest{
+		WasteType: wasteType,
+		Condition: models.ConditionAny,
+		WeightKg:  weightKg,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to value pickup: %w", err)
+	}
+	return valuation, nil
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/smartwaste/backend/internal/config"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+)
+
+// emptiedDropThreshold is the fill-level drop between two readings that is
+// treated as the bin having been emptied
+const emptiedDropThreshold = 20
+
+// PredictionService estimates when bins will reach the collection threshold
+type PredictionService struct {
+	binRepo     *repository.BinRepository
+	readingRepo *repository.BinReadingRepository
+	window      time.Duration
+	minReadings int
+}
+
+// NewPredictionService creates a new PredictionService
+func NewPredictionService(binRepo *repository.BinRepository, readingRepo *repository.BinReadingRepository, cfg *config.PredictionConfig) *PredictionService {
+	minReadings := cfg.MinReadings
+	if minReadings < 2 {
+		minReadings = 2
+	}
+	return &PredictionService{
+		binRepo:     binRepo,
+		readingRepo: readingRepo,
+		window:      cfg.HistoryWindow,
+		minReadings: minReadings,
+	}
+}
+
+// RecordReading stores a new fill-level reading for the bin and refreshes its
+// predicted full time
+func (s *PredictionService) RecordReading(ctx context.Context, bin *models.Bin) error {
+	reading := &models.BinReading{BinID: bin.ID, FillLevel: bin.FillLevel}
+	if err := s.readingRepo.Create(ctx, reading); err != nil {
+		return fmt.Errorf("failed to record bin reading: %w", err)
+	}
+
+	prediction, err := s.Predict(ctx, bin)
+	if err != nil {
+		return err
+	}
+
+	if err := s.binRepo.UpdatePrediction(ctx, bin.ID, prediction.PredictedFullAt); err != nil {
+		return fmt.Errorf("failed to store bin prediction: %w", err)
+	}
+	bin.PredictedFullAt = prediction.PredictedFullAt
+	return nil
+}
+
+// Predict fits a linear fill rate to the bin's readings since it was last
+// emptied and extrapolates when the bin's collection threshold will be reached
+func (s *PredictionService) Predict(ctx context.Context, bin *models.Bin) (*models.BinPrediction, error) {
+	threshold := bin.CollectionThreshold
+	prediction := &models.BinPrediction{
+		BinID:            bin.ID,
+		CurrentFillLevel: bin.FillLevel,
+		Threshold:        threshold,
+	}
+
+	since := time.Now().Add(-s.window)
+	if bin.LastCollectionAt != nil && bin.LastCollectionAt.After(since) {
+		since = *bin.LastCollectionAt
+	}
+
+	readings, err := s.readingRepo.ListSince(ctx, bin.ID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bin readings: %w", err)
+	}
+	readings = readingsSinceEmptied(readings)
+	prediction.SampleSize = len(readings)
+
+	if len(readings) < s.minReadings {
+		return prediction, nil
+	}
+
+	slope, r2, ok := fitFillRate(readings)
+	if !ok {
+		return prediction, nil
+	}
+	prediction.FillRatePerHour = &slope
+	prediction.Confidence = &r2
+
+	last := readings[len(readings)-1]
+	var fullAt time.Time
+	if last.FillLevel >= threshold {
+		fullAt = last.RecordedAt
+	} else if slope > 0 {
+		hours := float64(threshold-last.FillLevel) / slope
+		fullAt = last.RecordedAt.Add(time.Duration(hours * float64(time.Hour)))
+	} else {
+		// Not filling up; no meaningful estimate
+		return prediction, nil
+	}
+
+	remaining := time.Until(fullAt).Hours()
+	if remaining < 0 {
+		remaining = 0
+	}
+	prediction.PredictedFullAt = &fullAt
+	prediction.HoursRemaining = &remaining
+
+	return prediction, nil
+}
+
+// readingsSinceEmptied drops readings from before the most recent emptying,
+// detected as a large fill-level drop between consecutive readings
+func readingsSinceEmptied(readings []models.BinReading) []models.BinReading {
+	start := 0
+	for i := 1; i < len(readings); i++ {
+		if readings[i-1].FillLevel-readings[i].FillLevel >= emptiedDropThreshold {
+			start = i
+		}
+	}
+	return readings[start:]
+}
+
+// fitFillRate computes the least-squares fill rate (percent per hour) and the
+// coefficient of determination. ok is false if the readings span no time.
+func fitFillRate(readings []models.BinReading) (slope, r2 float64, ok bool) {
+	origin := readings[0].RecordedAt
+	n := float64(len(readings))
+
+	var sumX, sumY float64
+	for _, r := range readings {
+		sumX += r.RecordedAt.Sub(origin).Hours()
+		sumY += float64(r.FillLevel)
+	}
+	meanX, meanY := sumX/n, sumY/n
+
+	var sxx, sxy, syy float64
+	for _, r := range readings {
+		dx := r.RecordedAt.Sub(origin).Hours() - meanX
+		dy := float64(r.FillLevel) - meanY
+		sxx += dx * dx
+		sxy += dx * dy
+		syy += dy * dy
+	}
+	if sxx == 0 {
+		return 0, 0, false
+	}
+
+	slope = sxy / sxx
+	if syy == 0 {
+		// Perfectly flat readings are perfectly explained by a zero slope
+		return slope, 1, true
+	}
+	r2 = (sxy * sxy) / (sxx * syy)
+	return slope, r2, true
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// PricingInput is what a pricing strategy values; MarketPrice is only looked
+// up for market rules and is nil when none is known
+type PricingInput struct {
+	Rule        *models.PricingRule
+	WeightKg    float64
+	MarketPrice *models.MarketPrice
+}
+
+// PricingStrategy prices the weight of a valuation by one pricing model,
+// before condition multipliers and promotions
+type PricingStrategy interface {
+	Model() models.PricingModel
+	Quote(input PricingInput) []models.QuoteLine
+}
+
+// FlatPricing prices every kg at the rule's price
+type FlatPricing struct{}
+
+// Model returns the pricing model
+func (FlatPricing) Model() models.PricingModel {
+	return models.PricingModelFlat
+}
+
+// Quote prices the weight in one line
+func (FlatPricing) Quote(input PricingInput) []models.QuoteLine {
+	return []models.QuoteLine{weightLine(models.QuoteLineBase, "Flat rate", input.WeightKg, input.Rule.PricePerKg)}
+}
+
+// TieredPricing prices the weight of each bracket at its own price, like tax
+// brackets, and the weight above the last bracket at the rule's price
+type TieredPricing struct{}
+
+// Model returns the pricing model
+func (TieredPricing) Model() models.PricingModel {
+	return models.PricingModelTiered
+}
+
+// Quote prices the weight in one line per bracket it reaches
+func (TieredPricing) Quote(input PricingInput) []models.QuoteLine {
+	var lines []models.QuoteLine
+	from := 0.0
+	for _, tier := range input.Rule.Tiers {
+		if input.WeightKg <= from {
+			return lines
+		}
+		weight := math.Min(input.WeightKg, tier.UpToKg) - from
+		description := fmt.Sprintf("%.2f-%.2f kg", from, tier.UpToKg)
+		lines = append(lines, weightLine(models.QuoteLineTier, description, weight, tier.PricePerKg))
+		from = tier.UpToKg
+	}
+	if input.WeightKg > from {
+		description := fmt.Sprintf("Above %.2f kg", from)
+		lines = append(lines, weightLine(models.QuoteLineTier, description, input.WeightKg-from, input.Rule.PricePerKg))
+	}
+	return lines
+}
+
+// MarketPricing pays the rule's market factor times the latest market price,
+// or the rule's price when no market price is known
+type MarketPricing struct{}
+
+// Model returns the pricing model
+func (MarketPricing) Model() models.PricingModel {
+	return models.PricingModelMarket
+}
+
+// Quote prices the weight in one line
+func (MarketPricing) Quote(input PricingInput) []models.QuoteLine {
+	if input.MarketPrice == nil {
+		return []models.QuoteLine{weightLine(models.QuoteLineBase, "Rule rate (no market price known)", input.WeightKg, input.Rule.PricePerKg)}
+	}
+	price := input.MarketPrice.PricePerKg * input.Rule.MarketFactor
+	description := fmt.Sprintf("Market rate %.4f/kg x %.3f", input.MarketPrice.PricePerKg, input.Rule.MarketFactor)
+	return []models.QuoteLine{weightLine(models.QuoteLineBase, description, input.WeightKg, price)}
+}
+
+// weightLine prices a weight at a price per kg
+func weightLine(kind models.QuoteLineKind, description string, weightKg, pricePerKg float64) models.QuoteLine {
+	return models.QuoteLine{
+		Kind:        kind,
+		Description: description,
+		WeightKg:    &weightKg,
+		PricePerKg:  &pricePerKg,
+		Amount:      roundCents(weightKg * pricePerKg),
+	}
+}
+
+// multiplierLine adjusts a subtotal by a multiplier
+func multiplierLine(kind models.QuoteLineKind, description string, subtotal, multiplier float64) models.QuoteLine {
+	return models.QuoteLine{
+		Kind:        kind,
+		Description: description,
+		Multiplier:  &multiplier,
+		Amount:      roundCents(subtotal * (multiplier - 1)),
+	}
+}
+
+// roundCents rounds an amount to two decimals
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
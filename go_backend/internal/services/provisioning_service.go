@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/smartwaste/backend/internal/config"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+)
+
+var (
+	// ErrProvisioningDisabled is returned when no device CA is configured
+	ErrProvisioningDisabled = errors.New("device provisioning is not configured")
+	// ErrDeviceNotRegistered is returned when no active bin has the hardware serial
+	ErrDeviceNotRegistered = errors.New("no bin is registered with this hardware serial")
+	// ErrAlreadyProvisioned is returned when the bin's sensor was already
+	// provisioned and an admin did not allow it again
+	ErrAlreadyProvisioned = errors.New("bin is already provisioned")
+	// ErrInvalidCSR is returned for a certificate signing request that cannot be signed
+	ErrInvalidCSR = errors.New("invalid certificate signing request")
+)
+
+// ProvisioningService issues the client certificates bin sensors log in to
+// the broker with. The common name of a certificate is the device ID of its
+// bin, which the broker takes as username for its ACL.
+type ProvisioningService struct {
+	binRepo  *repository.BinRepository
+	config   *config.ProvisioningConfig
+	ca       *x509.Certificate
+	caKey    crypto.Signer
+	brokerCA string
+}
+
+// NewProvisioningService loads the device CA, and the CA bundle devices verify
+// the broker with. Without a device CA, provisioning is disabled.
+func NewProvisioningService(binRepo *repository.BinRepository, cfg *config.ProvisioningConfig, brokerCAFile string) (*ProvisioningService, error) {
+	s := &ProvisioningService{binRepo: binRepo, config: cfg}
+	if cfg.CACertFile == "" {
+		return s, nil
+	}
+	if cfg.CertValidity <= 0 {
+		return nil, errors.New("PROVISIONING_CERT_VALIDITY must be positive")
+	}
+
+	pair, err := tls.LoadX509KeyPair(cfg.CACertFile, cfg.CAKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the device CA: %w", err)
+	}
+	ca, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the device CA: %w", err)
+	}
+	if !ca.IsCA {
+		return nil, fmt.Errorf("%s is not a CA certificate", cfg.CACertFile)
+	}
+	signer, ok := pair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported device CA key")
+	}
+	s.ca, s.caKey = ca, signer
+
+	if brokerCAFile != "" {
+		bundle, err := os.ReadFile(brokerCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read MQTT CA bundle: %w", err)
+		}
+		s.brokerCA = string(bundle)
+	}
+	return s, nil
+}
+
+// Enabled reports whether a device CA is configured
+func (s *ProvisioningService) Enabled() bool {
+	return s.ca != nil
+}
+
+// Provision signs the certificate signing request of the sensor registered on
+// a bin with the hardware serial, within the organization of ctx. A bin is
+// only provisioned once, until an admin allows it again.
+func (s *ProvisioningService) Provision(ctx context.Context, req *models.ProvisionDeviceRequest) (*models.ProvisionDeviceResponse, error) {
+	if !s.Enabled() {
+		return nil, ErrProvisioningDisabled
+	}
+
+	block, _ := pem.Decode([]byte(req.CSR))
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, fmt.Errorf("%w: expected a PEM CERTIFICATE REQUEST", ErrInvalidCSR)
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCSR, err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCSR, err)
+	}
+
+	bin, err := s.binRepo.GetByHardwareSerial(ctx, req.HardwareSerial)
+	if err != nil {
+		return nil, err
+	}
+	if bin == nil || !bin.IsActive {
+		return nil, ErrDeviceNotRegistered
+	}
+	if bin.ProvisionedAt != nil {
+		return nil, ErrAlreadyProvisioned
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate certificate serial: %w", err)
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			CommonName:   bin.DeviceID,
+			Organization: []string{bin.OrganizationID.String()},
+		},
+		// Tolerates devices whose clock is slightly behind
+		NotBefore:             now.Add(-5 * time.Minute),
+		NotAfter:              now.Add(s.config.CertValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, s.ca, csr.PublicKey, s.caKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCSR, err)
+	}
+
+	serialHex := fmt.Sprintf("%x", serial)
+	if err := s.binRepo.RecordProvisioning(ctx, bin, serialHex, template.NotAfter); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			// Provisioned concurrently; the certificate just signed is dropped
+			return nil, ErrAlreadyProvisioned
+		}
+		return nil, err
+	}
+
+	return &models.ProvisionDeviceResponse{
+		BinID:         bin.DeviceID,
+		Certificate:   string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		CACertificate: s.brokerCA,
+		BrokerURL:     s.config.BrokerURL,
+		ExpiresAt:     template.NotAfter,
+	}, nil
+}
+
+// AllowReprovisioning lets the sensor of a bin provision itself again, after
+// it was replaced or reset. Certificates already issued stay valid until
+// they expire.
+func (s *ProvisioningService) AllowReprovisioning(ctx context.Context, bin *models.Bin) error {
+	return s.binRepo.ResetProvisioning(ctx, bin)
+}
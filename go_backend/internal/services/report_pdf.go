@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+const (
+	pdfMargin    = 10.0
+	pdfRowHeight = 5.0
+	pdfFontSize  = 7.0
+)
+
+// writeReportPDF renders a report as an A4 landscape table whose heading is
+// repeated on every page. Unlike CSV the document is built in memory, so very
+// large reports are better exported in the background.
+func writeReportPDF(ctx context.Context, w io.Writer, table reportTable, period models.AnalyticsRange) (int, error) {
+	pdf := fpdf.New("L", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin+2)
+	pdf.AliasNbPages("")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pageWidth, _ := pdf.GetPageSize()
+	widths := columnWidths(table.widths, pageWidth-2*pdfMargin)
+	generatedAt := time.Now().UTC().Format("2006-01-02 15:04 UTC")
+	last := period.To.Add(-time.Nanosecond)
+
+	pdf.SetHeaderFuncMode(func() {
+		pdf.SetFont("Helvetica", "B", 12)
+		pdf.CellFormat(0, 7, tr(table.title), "", 1, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.CellFormat(0, 5, fmt.Sprintf("Period %s to %s, generated %s",
+			period.From.UTC().Format("2006-01-02"), last.UTC().Format("2006-01-02"), generatedAt), "", 1, "L", false, 0, "")
+		pdf.Ln(2)
+
+		pdf.SetFont("Helvetica", "B", pdfFontSize)
+		pdf.SetFillColor(230, 230, 230)
+		for i, column := range table.columns {
+			heading := strings.ReplaceAll(column, "_", " ")
+			pdf.CellFormat(widths[i], pdfRowHeight+1, fitText(pdf, heading, widths[i]), "1", 0, "L", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Helvetica", "", pdfFontSize)
+	}, true)
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-pdfMargin)
+		pdf.SetFont("Helvetica", "I", pdfFontSize)
+		pdf.CellFormat(0, 5, fmt.Sprintf("Page %d/{nb}", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	rows := 0
+	err := table.each(ctx, period, func(record []string) error {
+		rows++
+		for i, value := range record {
+			pdf.CellFormat(widths[i], pdfRowHeight, fitText(pdf, tr(value), widths[i]), "1", 0, "L", false, 0, "")
+		}
+		pdf.Ln(-1)
+		return pdf.Error()
+	})
+	if err != nil {
+		return rows, err
+	}
+	if rows == 0 {
+		pdf.CellFormat(0, pdfRowHeight*2, "No data for this period", "", 1, "C", false, 0, "")
+	}
+
+	return rows, pdf.Output(w)
+}
+
+// columnWidths scales relative widths to fill the printable width
+func columnWidths(relative []float64, total float64) []float64 {
+	sum := 0.0
+	for _, width := range relative {
+		sum += width
+	}
+
+	widths := make([]float64, len(relative))
+	for i, width := range relative {
+		widths[i] = total * width / sum
+	}
+	return widths
+}
+
+// fitText shortens text with an ellipsis so it fits a cell of the given width
+// in the current font
+func fitText(pdf *fpdf.Fpdf, text string, width float64) string {
+	const padding = 2.0
+	if pdf.GetStringWidth(text) <= width-padding {
+		return text
+	}
+	for len(text) > 0 && pdf.GetStringWidth(text+"...") > width-padding {
+		text = text[:len(text)-1]
+	}
+	return text + "..."
+}
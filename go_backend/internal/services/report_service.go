@@ -0,0 +1,347 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/auth"
+	"github.com/smartwaste/backend/internal/config"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+)
+
+// ErrExportQueueFull is returned when every export worker is busy and the
+// queue of waiting exports is full
+var ErrExportQueueFull = errors.New("export queue is full")
+
+// reportTable describes the columns of a report and how to read its rows
+type reportTable struct {
+	title   string
+	columns []string
+	widths  []float64 // Relative column widths of the PDF table
+	each    func(ctx context.Context, period models.AnalyticsRange, fn func(record []string) error) error
+}
+
+// ReportService generates collection, bin and driver reports as CSV or PDF,
+// either streamed to the client or in the background for later download
+type ReportService struct {
+	reportRepo *repository.ReportRepository
+	cfg        *config.ExportConfig
+	queue      chan uuid.UUID
+	wg         sync.WaitGroup
+}
+
+// NewReportService creates a new ReportService
+func NewReportService(reportRepo *repository.ReportRepository, cfg *config.ExportConfig) *ReportService {
+	return &ReportService{
+		reportRepo: reportRepo,
+		cfg:        cfg,
+		queue:      make(chan uuid.UUID, cfg.QueueSize),
+	}
+}
+
+// Write generates a report into w and returns the number of rows written
+func (s *ReportService) Write(ctx context.Context, w io.Writer, req models.ReportRequest) (int, error) {
+	table := s.table(req.Report)
+	if req.Format == models.ReportFormatPDF {
+		return writeReportPDF(ctx, w, table, req.Period)
+	}
+	return writeReportCSV(ctx, w, table, req.Period)
+}
+
+// Enqueue records a background export of the report and queues it for a worker
+func (s *ReportService) Enqueue(ctx context.Context, req models.ReportRequest, requestedBy *uuid.UUID) (*models.ReportExport, error) {
+	export := &models.ReportExport{
+		Report:      req.Report,
+		Format:      req.Format,
+		PeriodFrom:  req.Period.From,
+		PeriodTo:    req.Period.To,
+		RequestedBy: requestedBy,
+	}
+	if err := s.reportRepo.CreateExport(ctx, export); err != nil {
+		return nil, fmt.Errorf("failed to record export: %w", err)
+	}
+
+	select {
+	case s.queue <- export.ID:
+		return export, nil
+	default:
+		if err := s.reportRepo.FailExport(ctx, export.ID, ErrExportQueueFull.Error(), s.expiry()); err != nil {
+			log.Printf("Failed to mark export %s as failed: %v", export.ID, err)
+		}
+		return nil, ErrExportQueueFull
+	}
+}
+
+// GetExport retrieves a background export
+func (s *ReportService) GetExport(ctx context.Context, id uuid.UUID) (*models.ReportExport, error) {
+	return s.reportRepo.GetExport(ctx, id)
+}
+
+// Start fails the exports interrupted by the previous shutdown and launches
+// the export workers, which run until ctx is cancelled
+func (s *ReportService) Start(ctx context.Context) error {
+	if err := os.MkdirAll(s.cfg.Dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	failed, err := s.reportRepo.FailUnfinishedExports(ctx, "Interrupted by a server restart", s.expiry())
+	if err != nil {
+		return fmt.Errorf("failed to clean up unfinished exports: %w", err)
+	}
+	if failed > 0 {
+		log.Printf("Marked %d unfinished exports as failed", failed)
+	}
+
+	for i := 0; i < s.cfg.Workers; i++ {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case id := <-s.queue:
+					s.run(ctx, id)
+				}
+			}
+		}()
+	}
+	return nil
+}
+
+// Wait blocks until every export worker has stopped
+func (s *ReportService) Wait() {
+	s.wg.Wait()
+}
+
+// PurgeExpired deletes the exports past their retention and their files
+func (s *ReportService) PurgeExpired(ctx context.Context) error {
+	files, err := s.reportRepo.DeleteExpiredExports(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to delete expired exports: %w", err)
+	}
+
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Failed to remove export file %s: %v", file, err)
+		}
+	}
+	if len(files) > 0 {
+		log.Printf("Purged %d expired exports", len(files))
+	}
+	return nil
+}
+
+// run generates a queued export into the export directory
+func (s *ReportService) run(ctx context.Context, id uuid.UUID) {
+	started, err := s.reportRepo.StartExport(ctx, id)
+	if err != nil || !started {
+		if err != nil {
+			log.Printf("Failed to start export %s: %v", id, err)
+		}
+		return
+	}
+
+	export, err := s.reportRepo.GetExport(ctx, id)
+	if err != nil || export == nil {
+		log.Printf("Failed to load export %s: %v", id, err)
+		return
+	}
+
+	// The export only covers the organization it was requested in
+	path := filepath.Join(s.cfg.Dir, id.String()+"."+string(export.Format))
+	rows, size, err := s.writeFile(auth.WithOrganization(ctx, export.OrganizationID), path, export.Request())
+	if err != nil {
+		os.Remove(path)
+		if ctx.Err() != nil {
+			// Failed as interrupted on the next start
+			return
+		}
+		log.Printf("Export %s failed: %v", id, err)
+		if err := s.reportRepo.FailExport(ctx, id, err.Error(), s.expiry()); err != nil {
+			log.Printf("Failed to mark export %s as failed: %v", id, err)
+		}
+		return
+	}
+
+	expiresAt := s.expiry()
+	export.Rows = &rows
+	export.SizeBytes = &size
+	export.FilePath = &path
+	export.ExpiresAt = &expiresAt
+	if err := s.reportRepo.CompleteExport(ctx, export); err != nil {
+		log.Printf("Failed to record export %s: %v", id, err)
+		os.Remove(path)
+		return
+	}
+	log.Printf("Export %s completed: %d rows, %d bytes", id, rows, size)
+}
+
+func (s *ReportService) writeFile(ctx context.Context, path string, req models.ReportRequest) (int, int64, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	rows, err := s.Write(ctx, file, req)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	return rows, info.Size(), nil
+}
+
+func (s *ReportService) expiry() time.Time {
+	return time.Now().Add(s.cfg.Retention)
+}
+
+func (s *ReportService) table(kind models.ReportKind) reportTable {
+	switch kind {
+	case models.ReportBins:
+		return reportTable{
+			title: "Bin Report",
+			columns: []string{
+				"bin_id", "device_id", "location_name", "waste_type", "company", "fill_level",
+				"readings", "average_fill_level", "max_fill_level", "collections", "collected_weight_kg",
+			},
+			widths: []float64{3, 2, 3, 1.5, 2.5, 1, 1, 1.5, 1.2, 1.2, 1.8},
+			each: func(ctx context.Context, period models.AnalyticsRange, fn func([]string) error) error {
+				return s.reportRepo.EachBin(ctx, period, func(row *models.BinReportRow) error {
+					return fn([]string{
+						row.ID.String(),
+						row.DeviceID,
+						optionalString(row.LocationName),
+						row.WasteType,
+						optionalString(row.CompanyName),
+						strconv.Itoa(row.FillLevel),
+						strconv.Itoa(row.Readings),
+						optionalFloat(row.AverageFillLevel),
+						optionalInt(row.MaxFillLevel),
+						strconv.Itoa(row.Collections),
+						formatFloat(row.CollectedWeightKg),
+					})
+				})
+			},
+		}
+	case models.ReportDrivers:
+		return reportTable{
+			title: "Driver Report",
+			columns: []string{
+				"driver_id", "full_name", "email", "vehicle_plate", "collections", "completed",
+				"cancelled", "weight_kg", "average_duration_min",
+			},
+			widths: []float64{3, 2.5, 3, 1.5, 1.2, 1.2, 1.2, 1.2, 1.8},
+			each: func(ctx context.Context, period models.AnalyticsRange, fn func([]string) error) error {
+				return s.reportRepo.EachDriver(ctx, period, func(row *models.DriverReportRow) error {
+					return fn([]string{
+						row.ID.String(),
+						row.FullName,
+						row.Email,
+						optionalString(row.VehiclePlate),
+						strconv.Itoa(row.Collections),
+						strconv.Itoa(row.Completed),
+						strconv.Itoa(row.Cancelled),
+						formatFloat(row.WeightKg),
+						optionalFloat(row.AverageDurationMin),
+					})
+				})
+			},
+		}
+	default:
+		return reportTable{
+			title: "Collection Report",
+			columns: []string{
+				"collection_id", "started_at", "completed_at", "status", "device_id", "location_name",
+				"waste_type", "company", "driver", "fill_level_before", "weight_kg",
+			},
+			widths: []float64{3, 2.2, 2.2, 1.3, 2, 2.5, 1.3, 2, 2, 1, 1},
+			each: func(ctx context.Context, period models.AnalyticsRange, fn func([]string) error) error {
+				return s.reportRepo.EachCollection(ctx, period, func(row *models.CollectionReportRow) error {
+					return fn([]string{
+						row.ID.String(),
+						row.StartedAt.UTC().Format(time.RFC3339),
+						optionalTime(row.CompletedAt),
+						row.Status,
+						row.BinDeviceID,
+						optionalString(row.LocationName),
+						row.WasteType,
+						optionalString(row.CompanyName),
+						row.DriverName,
+						strconv.Itoa(row.FillBefore),
+						optionalFloat(row.WeightKg),
+					})
+				})
+			},
+		}
+	}
+}
+
+// writeReportCSV streams a report as CSV with a header row
+func writeReportCSV(ctx context.Context, w io.Writer, table reportTable, period models.AnalyticsRange) (int, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(table.columns); err != nil {
+		return 0, err
+	}
+
+	rows := 0
+	err := table.each(ctx, period, func(record []string) error {
+		rows++
+		return writer.Write(record)
+	})
+	writer.Flush()
+
+	if err == nil {
+		err = writer.Error()
+	}
+	return rows, err
+}
+
+func optionalString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func optionalInt(value *int) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.Itoa(*value)
+}
+
+func optionalFloat(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return formatFloat(*value)
+}
+
+func optionalTime(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+	return value.UTC().Format(time.RFC3339)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+	"github.com/smartwaste/pkg/events"
+)
+
+// RewardService credits users with points according to the reward rules
+type RewardService struct {
+	rewardRepo *repository.RewardRepository
+}
+
+// NewRewardService creates a new RewardService
+func NewRewardService(rewardRepo *repository.RewardRepository) *RewardService {
+	return &RewardService{
+		rewardRepo: rewardRepo,
+	}
+}
+
+// CreditCollection credits the citizen attached to a completed collection.
+// Collections without a user or without a verified QR code earn nothing.
+func (s *RewardService) CreditCollection(ctx context.Context, collection *models.Collection, wasteType string) (*models.RewardTransaction, error) {
+	if collection.UserID == nil || !collection.QRCodeVerified {
+		return nil, nil
+	}
+
+	return s.credit(ctx, &models.RewardTransaction{
+		UserID:    *collection.UserID,
+		Source:    models.RewardSourceCollection,
+		SourceID:  collection.ID,
+		WasteType: wasteType,
+		WeightKg:  collection.WeightKg,
+	})
+}
+
+// CreditShipment credits the user who handed over a completed shipment
+func (s *RewardService) CreditShipment(ctx context.Context, shipment *events.StatusChanged) (*models.RewardTransaction, error) {
+	if shipment.ShipmentID == uuid.Nil || shipment.UserID == uuid.Nil {
+		return nil, fmt.Errorf("shipment event is missing shipment_id or user_id")
+	}
+
+	return s.credit(ctx, &models.RewardTransaction{
+		UserID:    shipment.UserID,
+		Source:    models.RewardSourceShipment,
+		SourceID:  shipment.ShipmentID,
+		WasteType: shipment.WasteType,
+		WeightKg:  shipment.WeightKg,
+	})
+}
+
+// DebitCancellation takes the penalty points of a late cancellation from the
+// user of the shipment, as far as their balance goes. It returns nil if the
+// cancellation carries no penalty or was already debited.
+func (s *RewardService) DebitCancellation(ctx context.Context, shipment *events.StatusChanged) (*models.RewardTransaction, error) {
+	if shipment.ShipmentID == uuid.Nil || shipment.UserID == uuid.Nil {
+		return nil, fmt.Errorf("shipment event is missing shipment_id or user_id")
+	}
+	cancellation, err := shipment.Cancellation()
+	if err != nil {
+		return nil, err
+	}
+	if cancellation.PenaltyPoints <= 0 {
+		return nil, nil
+	}
+
+	txn := &models.RewardTransaction{
+		UserID:    shipment.UserID,
+		Points:    -cancellation.PenaltyPoints,
+		Source:    models.RewardSourceCancellation,
+		SourceID:  shipment.ShipmentID,
+		WasteType: shipment.WasteType,
+	}
+	debited, err := s.rewardRepo.Debit(ctx, txn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to debit penalty: %w", err)
+	}
+	if !debited {
+		log.Printf("Penalty for cancellation of shipment %s already debited or nothing to take", shipment.ShipmentID)
+		return nil, nil
+	}
+
+	log.Printf("Debited %d points from user %s for cancelling shipment %s", -txn.Points, txn.UserID, shipment.ShipmentID)
+	return txn, nil
+}
+
+// ReverseCollection takes back the points credited for a collection that was
+// reopened, as far as the user's balance goes. It returns nil if the
+// collection earned nothing or was already reversed; completing it again does
+// not credit it a second time.
+func (s *RewardService) ReverseCollection(ctx context.Context, collectionID uuid.UUID) (*models.RewardTransaction, error) {
+	credited, err := s.rewardRepo.GetTransaction(ctx, models.RewardSourceCollection, collectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch collection reward: %w", err)
+	}
+	if credited == nil || credited.Points <= 0 {
+		return nil, nil
+	}
+
+	txn := &models.RewardTransaction{
+		UserID:    credited.UserID,
+		Points:    -credited.Points,
+		Source:    models.RewardSourceCollectionReversal,
+		SourceID:  collectionID,
+		WasteType: credited.WasteType,
+	}
+	debited, err := s.rewardRepo.Debit(ctx, txn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reverse collection reward: %w", err)
+	}
+	if !debited {
+		log.Printf("Reward for collection %s already reversed or nothing to take", collectionID)
+		return nil, nil
+	}
+
+	log.Printf("Took back %d points from user %s for reopened collection %s", -txn.Points, txn.UserID, collectionID)
+	return txn, nil
+}
+
+// credit prices txn with the matching rule and records it. It returns nil if
+// no rule applies or the source was already credited.
+func (s *RewardService) credit(ctx context.Context, txn *models.RewardTransaction) (*models.RewardTransaction, error) {
+	rule, err := s.rewardRepo.GetRuleForWasteType(ctx, txn.WasteType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch reward rule: %w", err)
+	}
+	if rule == nil {
+		return nil, nil
+	}
+
+	txn.Points = rule.PointsFor(txn.WeightKg)
+	if txn.Points <= 0 {
+		return nil, nil
+	}
+	txn.RewardRuleID = &rule.ID
+
+	credited, err := s.rewardRepo.Credit(ctx, txn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to credit reward: %w", err)
+	}
+	if !credited {
+		log.Printf("Reward for %s %s already credited", txn.Source, txn.SourceID)
+		return nil, nil
+	}
+
+	log.Printf("Credited %d points to user %s for %s %s", txn.Points, txn.UserID, txn.Source, txn.SourceID)
+	return txn, nil
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// googleMapsMaxStops is the number of stops a Google Maps directions link
+// takes: nine waypoints and the destination
+const googleMapsMaxStops = 10
+
+// gpxFile is a GPX 1.1 document holding the stops of a route both as
+// waypoints, which navigation apps list, and as the route itself
+type gpxFile struct {
+	XMLName   xml.Name    `xml:"gpx"`
+	Version   string      `xml:"version,attr"`
+	Creator   string      `xml:"creator,attr"`
+	Namespace string      `xml:"xmlns,attr"`
+	Metadata  gpxMetadata `xml:"metadata"`
+	Waypoints []gpxPoint  `xml:"wpt"`
+	Route     gpxRoute    `xml:"rte"`
+}
+
+type gpxMetadata struct {
+	Name string    `xml:"name"`
+	Time time.Time `xml:"time"`
+}
+
+type gpxRoute struct {
+	Name   string     `xml:"name"`
+	Points []gpxPoint `xml:"rtept"`
+}
+
+// gpxPoint keeps its coordinates as strings, since GPX does not allow the
+// exponents encoding/xml writes small floats with
+type gpxPoint struct {
+	Latitude    string `xml:"lat,attr"`
+	Longitude   string `xml:"lon,attr"`
+	Name        string `xml:"name"`
+	Description string `xml:"desc"`
+}
+
+// RouteGPX renders the stops of a route as a GPX file
+func RouteGPX(route *models.DriverRoute) ([]byte, error) {
+	name := "Collection route " + route.ID.String()
+	points := make([]gpxPoint, len(route.WaypointsList))
+	for i, wp := range route.WaypointsList {
+		description := fmt.Sprintf("%d%% full", wp.FillLevel)
+		if wp.Trip > 0 {
+			description = fmt.Sprintf("Trip %d, %s", wp.Trip, description)
+		}
+		points[i] = gpxPoint{
+			Latitude:    formatCoordinate(wp.Latitude),
+			Longitude:   formatCoordinate(wp.Longitude),
+			Name:        fmt.Sprintf("%d. %s", wp.Order, wp.DeviceID),
+			Description: description,
+		}
+	}
+
+	file := gpxFile{
+		Version:   "1.1",
+		Creator:   "Smart Waste Management",
+		Namespace: "http://www.topografix.com/GPX/1/1",
+		Metadata:  gpxMetadata{Name: name, Time: route.CreatedAt.UTC()},
+		Waypoints: points,
+		Route:     gpxRoute{Name: name, Points: points},
+	}
+	body, err := xml.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode GPX: %w", err)
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+// RouteGoogleMapsLinks returns the Google Maps driving directions through the
+// stops of a route. The first link starts from the current location of the
+// driver; routes with more stops than a link takes continue in further links.
+func RouteGoogleMapsLinks(route *models.DriverRoute) *models.RouteNavigation {
+	stops := route.WaypointsList
+	navigation := &models.RouteNavigation{RouteID: route.ID, Stops: len(stops), URLs: []string{}}
+
+	origin := ""
+	for start := 0; start < len(stops); start += googleMapsMaxStops {
+		leg := stops[start:min(start+googleMapsMaxStops, len(stops))]
+		last := len(leg) - 1
+
+		query := url.Values{}
+		query.Set("api", "1")
+		query.Set("travelmode", "driving")
+		if origin != "" {
+			query.Set("origin", origin)
+		}
+		query.Set("destination", waypointCoordinates(leg[last]))
+		if last > 0 {
+			waypoints := make([]string, last)
+			for i := range leg[:last] {
+				waypoints[i] = waypointCoordinates(leg[i])
+			}
+			query.Set("waypoints", strings.Join(waypoints, "|"))
+		}
+
+		navigation.URLs = append(navigation.URLs, "https://www.google.com/maps/dir/?"+query.Encode())
+		origin = waypointCoordinates(leg[last])
+	}
+	return navigation
+}
+
+// waypointCoordinates formats a waypoint as "latitude,longitude"
+func waypointCoordinates(wp models.Waypoint) string {
+	return formatCoordinate(wp.Latitude) + "," + formatCoordinate(wp.Longitude)
+}
+
+// formatCoordinate formats a coordinate to six decimals, about 10 cm
+func formatCoordinate(value float64) string {
+	return strconv.FormatFloat(math.Round(value*1e6)/1e6, 'f', -1, 64)
+}
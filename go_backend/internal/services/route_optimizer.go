@@ -0,0 +1,161 @@
+package services
+
+import (
+	"github.com/smartwaste/backend/internal/models"
+)
+
+const (
+	// twoOptMaxPasses bounds the number of improvement sweeps over the tour
+	twoOptMaxPasses = 50
+	// twoOptEpsilon ignores improvements too small to matter (km)
+	twoOptEpsilon = 1e-9
+)
+
+// geoPoint is a latitude/longitude pair
+type geoPoint struct {
+	lat, lng float64
+}
+
+func binPoint(bin *models.Bin) geoPoint {
+	return geoPoint{lat: bin.Latitude, lng: bin.Longitude}
+}
+
+func pointDistance(a, b geoPoint) float64 {
+	return haversineDistance(a.lat, a.lng, b.lat, b.lng)
+}
+
+// nearestNeighborTour orders bins by repeatedly visiting the closest unvisited bin
+func nearestNeighborTour(start geoPoint, bins []*models.Bin) []*models.Bin {
+	remaining := make([]*models.Bin, len(bins))
+	copy(remaining, bins)
+	tour := make([]*models.Bin, 0, len(bins))
+
+	current := start
+	for len(remaining) > 0 {
+		nearest := 0
+		for i := 1; i < len(remaining); i++ {
+			if pointDistance(current, binPoint(remaining[i])) < pointDistance(current, binPoint(remaining[nearest])) {
+				nearest = i
+			}
+		}
+		tour = append(tour, remaining[nearest])
+		current = binPoint(remaining[nearest])
+		remaining = append(remaining[:nearest], remaining[nearest+1:]...)
+	}
+
+	return tour
+}
+
+// improveTwoOpt shortens a tour starting at start by reversing segments while
+// that reduces its length. If closed, the tour returns to start at the end.
+func improveTwoOpt(start geoPoint, tour []*models.Bin, closed bool) []*models.Bin {
+	n := len(tour)
+	if n < 3 {
+		return tour
+	}
+
+	at := func(i int) geoPoint {
+		if i < 0 || i >= n {
+			return start
+		}
+		return binPoint(tour[i])
+	}
+
+	for pass := 0; pass < twoOptMaxPasses; pass++ {
+		improved := false
+		for i := 0; i < n-1; i++ {
+			for j := i + 1; j < n; j++ {
+				// Reversing tour[i..j] replaces edges (i-1, i) and (j, j+1)
+				// with (i-1, j) and (i, j+1)
+				delta := pointDistance(at(i-1), at(j)) - pointDistance(at(i-1), at(i))
+				if j < n-1 || closed {
+					delta += pointDistance(at(i), at(j+1)) - pointDistance(at(j), at(j+1))
+				}
+				if delta < -twoOptEpsilon {
+					reverseBins(tour[i : j+1])
+					improved = true
+				}
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+
+	return tour
+}
+
+func reverseBins(bins []*models.Bin) {
+	for i, j := 0, len(bins)-1; i < j; i, j = i+1, j-1 {
+		bins[i], bins[j] = bins[j], bins[i]
+	}
+}
+
+// binVolumeLiters estimates the waste volume in a bin from its fill level
+func binVolumeLiters(bin *models.Bin) float64 {
+	return float64(bin.CapacityLiters) * float64(bin.FillLevel) / 100
+}
+
+// splitTrips cuts a tour into consecutive trips whose estimated volume fits the
+// truck. A bin larger than the truck gets a trip of its own.
+func splitTrips(tour []*models.Bin, truckCapacityLiters int) [][]*models.Bin {
+	var trips [][]*models.Bin
+	var current []*models.Bin
+	load := 0.0
+
+	for _, bin := range tour {
+		volume := binVolumeLiters(bin)
+		if len(current) > 0 && load+volume > float64(truckCapacityLiters) {
+			trips = append(trips, current)
+			current, load = nil, 0
+		}
+		current = append(current, bin)
+		load += volume
+	}
+	if len(current) > 0 {
+		trips = append(trips, current)
+	}
+
+	return trips
+}
+
+// optimizeTwoOpt builds a nearest-neighbour tour and improves it with 2-opt
+func (s *RouteService) optimizeTwoOpt(bins []*models.Bin, driverLat, driverLng float64) []models.Waypoint {
+	start := geoPoint{lat: driverLat, lng: driverLng}
+	tour := improveTwoOpt(start, nearestNeighborTour(start, bins), false)
+
+	waypoints := make([]models.Waypoint, len(tour))
+	for i, bin := range tour {
+		waypoints[i] = newWaypoint(bin, i+1, 0)
+	}
+	return waypoints
+}
+
+// optimizeByCapacity splits the bins into trips that fit the truck, each
+// starting and ending at the driver's start position where the truck unloads
+func (s *RouteService) optimizeByCapacity(bins []*models.Bin, driverLat, driverLng float64, truckCapacityLiters int) []models.Waypoint {
+	depot := geoPoint{lat: driverLat, lng: driverLng}
+	tour := improveTwoOpt(depot, nearestNeighborTour(depot, bins), true)
+
+	waypoints := make([]models.Waypoint, 0, len(bins))
+	for t, trip := range splitTrips(tour, truckCapacityLiters) {
+		trip = improveTwoOpt(depot, nearestNeighborTour(depot, trip), true)
+		for _, bin := range trip {
+			waypoints = append(waypoints, newWaypoint(bin, len(waypoints)+1, t+1))
+		}
+	}
+	return waypoints
+}
+
+func newWaypoint(bin *models.Bin, order, trip int) models.Waypoint {
+	return models.Waypoint{
+		BinID:                 bin.ID,
+		DeviceID:              bin.DeviceID,
+		Latitude:              bin.Latitude,
+		Longitude:             bin.Longitude,
+		FillLevel:             bin.FillLevel,
+		EstimatedVolumeLiters: binVolumeLiters(bin),
+		Order:                 order,
+		Trip:                  trip,
+	}
+}
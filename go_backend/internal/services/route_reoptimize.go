@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+)
+
+// ErrRouteClosed is returned when re-optimizing a route that was completed
+// or cancelled
+var ErrRouteClosed = errors.New("route is no longer active")
+
+// Reoptimize re-sequences the stops left on an active route. Stops whose bin
+// dropped below its collection threshold are completed when the driver of
+// the route collected them and dropped otherwise; bins at their alert
+// threshold within the re-optimization radius of the stops left are added.
+// The stops are only re-sequenced when they changed, unless force is set;
+// the driver is notified when the order of their stops changed.
+func (s *RouteService) Reoptimize(ctx context.Context, route *models.DriverRoute, force bool) (*models.RouteReoptimization, error) {
+	if !route.IsActive() {
+		return nil, ErrRouteClosed
+	}
+
+	result := &models.RouteReoptimization{Added: []uuid.UUID{}, Removed: []uuid.UUID{}, Completed: []uuid.UUID{}}
+
+	var done, left []models.Waypoint
+	for _, wp := range route.WaypointsList {
+		if wp.IsCompleted {
+			done = append(done, wp)
+		} else {
+			left = append(left, wp)
+		}
+	}
+
+	// Settle the stops emptied since the route was planned
+	leftIDs := make([]uuid.UUID, len(left))
+	for i, wp := range left {
+		leftIDs[i] = wp.BinID
+	}
+	found, err := s.binRepo.GetByIDs(ctx, leftIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bins: %w", err)
+	}
+	byID := make(map[uuid.UUID]*models.Bin, len(found))
+	for i := range found {
+		byID[found[i].ID] = &found[i]
+	}
+
+	var bins []*models.Bin
+	var kept []models.Waypoint
+	var settled []uuid.UUID
+	for _, wp := range left {
+		bin := byID[wp.BinID]
+		if bin == nil || bin.Status != models.BinStatusActive || bin.FillLevel < bin.CollectionThreshold {
+			settled = append(settled, wp.BinID)
+			continue
+		}
+		bins = append(bins, bin)
+		kept = append(kept, wp)
+	}
+
+	collected, err := s.collectionRepo.CompletedBinsSince(ctx, route.DriverID, settled, route.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collected bins: %w", err)
+	}
+	collectedByDriver := make(map[uuid.UUID]bool, len(collected))
+	for _, id := range collected {
+		collectedByDriver[id] = true
+	}
+	for _, wp := range left {
+		if !containsID(settled, wp.BinID) {
+			continue
+		}
+		if collectedByDriver[wp.BinID] {
+			wp.IsCompleted = true
+			done = append(done, wp)
+			result.Completed = append(result.Completed, wp.BinID)
+		} else {
+			result.Removed = append(result.Removed, wp.BinID)
+		}
+	}
+
+	// Add the full bins near the stops left
+	if len(bins) > 0 {
+		candidates, err := s.routeRepo.UnroutedCriticalBins(ctx, route.DriverID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get critical bins: %w", err)
+		}
+		stops := bins
+		for i := range candidates {
+			if s.nearStops(&candidates[i], stops) {
+				bins = append(bins, &candidates[i])
+				result.Added = append(result.Added, candidates[i].ID)
+			}
+		}
+	}
+
+	result.Changed = len(result.Added)+len(result.Removed)+len(result.Completed) > 0
+	if !result.Changed && !force {
+		result.Route = route.ToResponse()
+		return result, nil
+	}
+
+	// Re-plan the stops left from where the driver is
+	startLat, startLng, err := s.routeStart(ctx, route, done, left)
+	if err != nil {
+		return nil, err
+	}
+	var planned []models.Waypoint
+	if len(bins) > 0 {
+		planned = s.planWaypoints(bins, startLat, startLng, route.Options(), route)
+	}
+	distance, duration := s.calculateRouteMetrics(ctx, startLat, startLng, planned)
+
+	lastTrip := 0
+	if n := len(done); n > 0 {
+		lastTrip = done[n-1].Trip
+	}
+	waypoints := make([]models.Waypoint, 0, len(done)+len(planned))
+	waypoints = append(waypoints, done...)
+	for _, wp := range planned {
+		// The truck unloads before the stops re-planned in capacity mode
+		if wp.Trip > 0 {
+			wp.Trip += lastTrip
+		}
+		waypoints = append(waypoints, wp)
+	}
+	for i := range waypoints {
+		waypoints[i].Order = i + 1
+	}
+	resequenced := len(result.Removed) > 0 || !sameStops(kept, planned)
+	result.Changed = result.Changed || resequenced
+
+	route.WaypointsList = waypoints
+	route.TotalDistanceKm = &distance
+	route.EstimatedDurationMinutes = &duration
+	if n := len(waypoints); n > 0 {
+		route.Trips = waypoints[n-1].Trip
+	}
+	if len(planned) == 0 {
+		now := time.Now()
+		route.Status = models.RouteStatusCompleted
+		route.CompletedAt = &now
+	}
+	waypointsJSON, err := json.Marshal(waypoints)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal waypoints: %w", err)
+	}
+	route.Waypoints = waypointsJSON
+
+	if err := s.routeRepo.UpdatePlan(ctx, route); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrRouteClosed
+		}
+		return nil, fmt.Errorf("failed to update route: %w", err)
+	}
+
+	if resequenced && len(planned) > 0 {
+		notification := &models.Notification{
+			ID:    uuid.New(),
+			Type:  models.NotificationTypeRouteUpdated,
+			Title: "Route Updated",
+			Message: fmt.Sprintf(
+				"Your route was re-sequenced: %d stops left, %d added and %d dropped.",
+				len(planned),
+				len(result.Added),
+				len(result.Removed),
+			),
+		}
+		if err := s.notificationService.NotifyDriver(ctx, route.DriverID, notification); err != nil {
+			log.Printf("Failed to notify driver %s of route %s: %v", route.DriverID, route.ID, err)
+		}
+	}
+
+	result.Route = route.ToResponse()
+	return result, nil
+}
+
+// ReoptimizeActive re-sequences the active routes of every organization whose
+// stops changed, returning how many were updated
+func (s *RouteService) ReoptimizeActive(ctx context.Context) (int, error) {
+	routes, err := s.routeRepo.ListActive(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list active routes: %w", err)
+	}
+
+	updated := 0
+	for i := range routes {
+		result, err := s.Reoptimize(ctx, &routes[i], false)
+		if errors.Is(err, ErrRouteClosed) {
+			continue
+		}
+		if err != nil {
+			log.Printf("Failed to re-optimize route %s: %v", routes[i].ID, err)
+			continue
+		}
+		if result.Changed {
+			updated++
+		}
+	}
+	return updated, nil
+}
+
+// routeStart returns where the stops left on a route are re-planned from: the
+// last known location of the driver, else the last stop they completed, else
+// the first stop left
+func (s *RouteService) routeStart(ctx context.Context, route *models.DriverRoute, done, left []models.Waypoint) (float64, float64, error) {
+	driver, err := s.driverRepo.GetByID(ctx, route.DriverID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get driver: %w", err)
+	}
+	switch {
+	case driver != nil && driver.Latitude != nil && driver.Longitude != nil:
+		return *driver.Latitude, *driver.Longitude, nil
+	case len(done) > 0:
+		return done[len(done)-1].Latitude, done[len(done)-1].Longitude, nil
+	case len(left) > 0:
+		return left[0].Latitude, left[0].Longitude, nil
+	}
+	return 0, 0, nil
+}
+
+// nearStops reports whether the bin is within the re-optimization radius of
+// any of the stops
+func (s *RouteService) nearStops(bin *models.Bin, stops []*models.Bin) bool {
+	for _, stop := range stops {
+		if haversineDistance(bin.Latitude, bin.Longitude, stop.Latitude, stop.Longitude) <= s.reoptimizeRadiusKm {
+			return true
+		}
+	}
+	return false
+}
+
+// sameStops reports whether the waypoints visit the same bins in the same order
+func sameStops(a, b []models.Waypoint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].BinID != b[i].BinID {
+			return false
+		}
+	}
+	return true
+}
+
+// containsID reports whether the IDs include id
+func containsID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
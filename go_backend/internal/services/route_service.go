@@ -4,11 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"math"
-	"net/http"
-	"net/url"
 	"sort"
 
 	"github.com/google/uuid"
@@ -19,28 +16,54 @@ import (
 
 // RouteService handles route optimization for drivers
 type RouteService struct {
-	binRepo   *repository.BinRepository
-	googleKey string
+	binRepo             *repository.BinRepository
+	routeRepo           *repository.RouteRepository
+	driverRepo          *repository.DriverRepository
+	collectionRepo      *repository.CollectionRepository
+	notificationService *NotificationService
+	provider            RoutingProvider
+	fallback            RoutingProvider
+	truckCapacityLiters int
+	reoptimizeRadiusKm  float64
 }
 
 // NewRouteService creates a new RouteService
-func NewRouteService(binRepo *repository.BinRepository, cfg *config.GoogleConfig) *RouteService {
+func NewRouteService(
+	binRepo *repository.BinRepository,
+	routeRepo *repository.RouteRepository,
+	driverRepo *repository.DriverRepository,
+	collectionRepo *repository.CollectionRepository,
+	notificationService *NotificationService,
+	provider RoutingProvider,
+	routing *config.RoutingConfig,
+) *RouteService {
 	return &RouteService{
-		binRepo:   binRepo,
-		googleKey: cfg.MapsAPIKey,
+		binRepo:             binRepo,
+		routeRepo:           routeRepo,
+		driverRepo:          driverRepo,
+		collectionRepo:      collectionRepo,
+		notificationService: notificationService,
+		provider:            provider,
+		fallback:            NewHaversineRoutingProvider(),
+		truckCapacityLiters: routing.TruckCapacityLiters,
+		reoptimizeRadiusKm:  float64(routing.ReoptimizeRadiusMeters) / 1000,
 	}
 }
 
 // OptimizeRoute calculates an optimized route for a driver
-func (s *RouteService) OptimizeRoute(ctx context.Context, driverLat, driverLng float64, binIDs []uuid.UUID, optimizeBy string) (*models.DriverRoute, error) {
-	// Get bins
+func (s *RouteService) OptimizeRoute(ctx context.Context, driverLat, driverLng float64, binIDs []uuid.UUID, opts models.RouteOptions) (*models.DriverRoute, error) {
+	// Get bins in one query, keeping the requested order
+	found, err := s.binRepo.GetByIDs(ctx, binIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bins: %w", err)
+	}
+	byID := make(map[uuid.UUID]*models.Bin, len(found))
+	for i := range found {
+		byID[found[i].ID] = &found[i]
+	}
 	bins := make([]*models.Bin, 0, len(binIDs))
 	for _, id := range binIDs {
-		bin, err := s.binRepo.GetByID(ctx, id)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get bin %s: %w", id, err)
-		}
-		if bin != nil {
+		if bin, ok := byID[id]; ok {
 			bins = append(bins, bin)
 		}
 	}
@@ -49,27 +72,21 @@ func (s *RouteService) OptimizeRoute(ctx context.Context, driverLat, driverLng f
 		return nil, fmt.Errorf("no valid bins found")
 	}
 
-	// Sort bins based on optimization criteria
-	var waypoints []models.Waypoint
-	switch optimizeBy {
-	case "fill_level":
-		waypoints = s.optimizeByFillLevel(bins, driverLat, driverLng)
-	case "distance":
-		fallthrough
-	default:
-		waypoints = s.optimizeByDistance(bins, driverLat, driverLng)
+	route := &models.DriverRoute{
+		ID:     uuid.New(),
+		Status: models.RouteStatusPending,
 	}
 
+	// Sort bins based on optimization criteria
+	waypoints := s.planWaypoints(bins, driverLat, driverLng, opts, route)
+
 	// Calculate total distance and duration
-	totalDistance, duration := s.calculateRouteMetrics(driverLat, driverLng, waypoints)
+	totalDistance, duration := s.calculateRouteMetrics(ctx, driverLat, driverLng, waypoints)
 
-	route := &models.DriverRoute{
-		ID:                       uuid.New(),
-		WaypointsList:            waypoints,
-		TotalDistanceKm:          &totalDistance,
-		EstimatedDurationMinutes: &duration,
-		Status:                   models.RouteStatusPending,
-	}
+	route.WaypointsList = waypoints
+	route.TotalDistanceKm = &totalDistance
+	route.Trips = waypoints[len(waypoints)-1].Trip
+	route.EstimatedDurationMinutes = &duration
 
 	// Marshal waypoints to JSON for storage
 	waypointsJSON, err := json.Marshal(waypoints)
@@ -78,18 +95,41 @@ func (s *RouteService) OptimizeRoute(ctx context.Context, driverLat, driverLng f
 	}
 	route.Waypoints = waypointsJSON
 
-	// Try to get optimized route from Google Maps/OSRM
-	if s.googleKey != "" {
-		optimizedRoute, err := s.getGoogleMapsRoute(driverLat, driverLng, waypoints)
-		if err != nil {
-			log.Printf("Failed to get Google Maps route, using calculated distance: %v", err)
-		} else if optimizedRoute != nil {
-			route.TotalDistanceKm = &optimizedRoute.distance
-			route.EstimatedDurationMinutes = &optimizedRoute.duration
+	return route, nil
+}
+
+// planWaypoints orders the bins into waypoints with the optimization the
+// options ask for, recording on the route the one used
+func (s *RouteService) planWaypoints(bins []*models.Bin, driverLat, driverLng float64, opts models.RouteOptions, route *models.DriverRoute) []models.Waypoint {
+	route.OptimizeBy = opts.OptimizeBy
+	route.TruckCapacityLiters = nil
+	switch opts.OptimizeBy {
+	case models.RouteOptimizeFillLevel:
+		return s.optimizeByFillLevel(bins, driverLat, driverLng)
+	case models.RouteOptimizeTwoOpt:
+		return s.optimizeTwoOpt(bins, driverLat, driverLng)
+	case models.RouteOptimizeCapacity:
+		capacity := opts.TruckCapacityLiters
+		if capacity <= 0 {
+			capacity = s.truckCapacityLiters
 		}
+		route.TruckCapacityLiters = &capacity
+		return s.optimizeByCapacity(bins, driverLat, driverLng, capacity)
+	default:
+		route.OptimizeBy = models.RouteOptimizeDistance
+		return s.optimizeByDistance(bins, driverLat, driverLng)
 	}
+}
 
-	return route, nil
+// Save records a route planned for a driver as their pending route, replacing
+// the one planned before
+func (s *RouteService) Save(ctx context.Context, route *models.DriverRoute) error {
+	return s.routeRepo.ReplacePending(ctx, route)
+}
+
+// Get retrieves a planned route
+func (s *RouteService) Get(ctx context.Context, id uuid.UUID) (*models.DriverRoute, error) {
+	return s.routeRepo.GetByID(ctx, id)
 }
 
 // optimizeByDistance sorts bins by distance from driver (nearest first)
@@ -172,24 +212,46 @@ func (s *RouteService) optimizeByFillLevel(bins []*models.Bin, driverLat, driver
 	return waypoints
 }
 
-// calculateRouteMetrics calculates approximate distance and duration
-func (s *RouteService) calculateRouteMetrics(startLat, startLng float64, waypoints []models.Waypoint) (float64, int) {
+// calculateRouteMetrics calculates distance and duration with the routing
+// provider, falling back to straight-line distances if it fails
+func (s *RouteService) calculateRouteMetrics(ctx context.Context, startLat, startLng float64, waypoints []models.Waypoint) (float64, int) {
 	if len(waypoints) == 0 {
 		return 0, 0
 	}
 
-	totalDistance := 0.0
-	currentLat, currentLng := startLat, startLng
+	path := routePath(startLat, startLng, waypoints)
+	metrics, err := s.provider.Route(ctx, path)
+	if err != nil {
+		log.Printf("Routing provider %s failed, using straight-line distance: %v", s.provider.Name(), err)
+		metrics, _ = s.fallback.Route(ctx, path)
+	}
+
+	// Add 2 minutes per stop to the travel time
+	durationMinutes := int(metrics.Duration.Minutes()) + len(waypoints)*2
 
+	return metrics.DistanceKm, durationMinutes
+}
+
+// routePath lists the points driven through, including the returns to the
+// start to unload between and after capacity-split trips
+func routePath(startLat, startLng float64, waypoints []models.Waypoint) []LatLng {
+	start := LatLng{Latitude: startLat, Longitude: startLng}
+	path := make([]LatLng, 0, len(waypoints)+2)
+	path = append(path, start)
+
+	trip := waypoints[0].Trip
 	for _, wp := range waypoints {
-		totalDistance += haversineDistance(currentLat, currentLng, wp.Latitude, wp.Longitude)
-		currentLat, currentLng = wp.Latitude, wp.Longitude
+		if wp.Trip != trip {
+			path = append(path, start)
+			trip = wp.Trip
+		}
+		path = append(path, LatLng{Latitude: wp.Latitude, Longitude: wp.Longitude})
+	}
+	if trip > 0 {
+		path = append(path, start)
 	}
 
-	// Estimate duration: assume average speed of 30 km/h in urban areas + 2 min per stop
-	durationMinutes := int((totalDistance/30)*60) + len(waypoints)*2
-
-	return totalDistance, durationMinutes
+	return path
 }
 
 // haversineDistance calculates distance between two points using Haversine formula
@@ -210,91 +272,7 @@ func haversineDistance(lat1, lng1, lat2, lng2 float64) float64 {
 	return earthRadiusKm * c
 }
 
-// googleMapsRouteResult holds the result from Google Maps API
-type googleMapsRouteResult struct {
-	distance float64 // km
-	duration int     // minutes
-}
-
-// getGoogleMapsRoute fetches optimized route from Google Maps Directions API
-func (s *RouteService) getGoogleMapsRoute(startLat, startLng float64, waypoints []models.Waypoint) (*googleMapsRouteResult, error) {
-	if s.googleKey == "" {
-		return nil, fmt.Errorf("google Maps API key not configured")
-	}
-
-	if len(waypoints) == 0 {
-		return nil, fmt.Errorf("no waypoints provided")
-	}
-
-	// Build waypoints string
-	waypointStrs := make([]string, len(waypoints))
-	for i, wp := range waypoints {
-		waypointStrs[i] = fmt.Sprintf("%f,%f", wp.Latitude, wp.Longitude)
-	}
-
-	// Last waypoint is destination
-	destination := waypointStrs[len(waypointStrs)-1]
-	intermediateWaypoints := ""
-	if len(waypointStrs) > 1 {
-		intermediateWaypoints = "optimize:true|" + url.QueryEscape(waypointStrs[0])
-		for i := 1; i < len(waypointStrs)-1; i++ {
-			intermediateWaypoints += "|" + waypointStrs[i]
-		}
-	}
-
-	apiURL := fmt.Sprintf(
-		"https://maps.googleapis.com/maps/api/directions/json?origin=%f,%f&destination=%s&waypoints=%s&key=%s",
-		startLat, startLng, destination, intermediateWaypoints, s.googleKey,
-	)
-
-	resp, err := http.Get(apiURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to call Google Maps API: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	var result struct {
-		Status string `json:"status"`
-		Routes []struct {
-			Legs []struct {
-				Distance struct {
-					Value int `json:"value"` // meters
-				} `json:"distance"`
-				Duration struct {
-					Value int `json:"value"` // seconds
-				} `json:"duration"`
-			} `json:"legs"`
-		} `json:"routes"`
-	}
-
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	if result.Status != "OK" || len(result.Routes) == 0 {
-		return nil, fmt.Errorf("no routes found: %s", result.Status)
-	}
-
-	// Sum up all legs
-	totalDistance := 0
-	totalDuration := 0
-	for _, leg := range result.Routes[0].Legs {
-		totalDistance += leg.Distance.Value
-		totalDuration += leg.Duration.Value
-	}
-
-	return &googleMapsRouteResult{
-		distance: float64(totalDistance) / 1000, // Convert to km
-		duration: totalDuration / 60,             // Convert to minutes
-	}, nil
-}
-
-// GetBinsForRoute retrieves bins that need collection
-func (s *RouteService) GetBinsForRoute(ctx context.Context, threshold int) ([]models.Bin, error) {
-	return s.binRepo.GetBinsNeedingCollection(ctx, threshold)
+// GetBinsForRoute retrieves bins at or above their collection threshold
+func (s *RouteService) GetBinsForRoute(ctx context.Context) ([]models.Bin, error) {
+	return s.binRepo.GetBinsNeedingCollection(ctx, nil)
 }
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smartwaste/backend/internal/cache"
+	"github.com/smartwaste/backend/internal/config"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+)
+
+// benchRoundTrip is the latency the stub database adds to every query, about
+// that of a database on the same network as the backend
+const benchRoundTrip = 200 * time.Microsecond
+
+// BenchmarkOptimizeRoute compares fetching the bins of a route with one query
+// per waypoint, as OptimizeRoute used to, against the single GetByIDs query.
+// The bins come from a stub driver, so the difference is the round trips.
+func BenchmarkOptimizeRoute(b *testing.B) {
+	for _, n := range []int{100, 250, 500} {
+		bins := benchBins(n)
+		ids := make([]uuid.UUID, len(bins))
+		for i := range bins {
+			ids[i] = bins[i].ID
+		}
+		s := newBenchRouteService(b, bins)
+		ctx := context.Background()
+		opts := models.RouteOptions{OptimizeBy: models.RouteOptimizeDistance}
+
+		batched, err := s.OptimizeRoute(ctx, 36.75, 3.05, ids, opts)
+		if err != nil {
+			b.Fatalf("OptimizeRoute: %v", err)
+		}
+		perWaypoint, err := optimizeRoutePerWaypoint(ctx, s, 36.75, 3.05, ids, opts)
+		if err != nil {
+			b.Fatalf("per-waypoint OptimizeRoute: %v", err)
+		}
+		if len(batched.WaypointsList) != n || len(perWaypoint.WaypointsList) != n {
+			b.Fatalf("planned %d and %d waypoints, want %d", len(perWaypoint.WaypointsList), len(batched.WaypointsList), n)
+		}
+
+		b.Run(fmt.Sprintf("waypoints=%d/per-waypoint", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := optimizeRoutePerWaypoint(ctx, s, 36.75, 3.05, ids, opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("waypoints=%d/batched", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := s.OptimizeRoute(ctx, 36.75, 3.05, ids, opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// optimizeRoutePerWaypoint is OptimizeRoute as it was before GetByIDs,
+// fetching each bin with its own query
+func optimizeRoutePerWaypoint(ctx context.Context, s *RouteService, driverLat, driverLng float64, binIDs []uuid.UUID, opts models.RouteOptions) (*models.DriverRoute, error) {
+	bins := make([]*models.Bin, 0, len(binIDs))
+	for _, id := range binIDs {
+		bin, err := s.binRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get bin %s: %w", id, err)
+		}
+		if bin != nil {
+			bins = append(bins, bin)
+		}
+	}
+	if len(bins) == 0 {
+		return nil, fmt.Errorf("no valid bins found")
+	}
+
+	route := &models.DriverRoute{ID: uuid.New(), Status: models.RouteStatusPending}
+	waypoints := s.planWaypoints(bins, driverLat, driverLng, opts, route)
+	totalDistance, duration := s.calculateRouteMetrics(ctx, driverLat, driverLng, waypoints)
+	route.WaypointsList = waypoints
+	route.TotalDistanceKm = &totalDistance
+	route.Trips = waypoints[len(waypoints)-1].Trip
+	route.EstimatedDurationMinutes = &duration
+
+	waypointsJSON, err := json.Marshal(waypoints)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal waypoints: %w", err)
+	}
+	route.Waypoints = waypointsJSON
+	return route, nil
+}
+
+// benchBins returns n bins scattered around Algiers
+func benchBins(n int) []models.Bin {
+	rng := rand.New(rand.NewSource(1))
+	organizationID := uuid.New()
+	bins := make([]models.Bin, n)
+	for i := range bins {
+		bins[i] = models.Bin{
+			ID:             uuid.New(),
+			OrganizationID: organizationID,
+			DeviceID:       fmt.Sprintf("BIN-%04d", i),
+			Latitude:       36.70 + rng.Float64()*0.1,
+			Longitude:      3.00 + rng.Float64()*0.1,
+			FillLevel:      rng.Intn(101),
+			WasteType:      "general",
+			CapacityLiters: 240,
+			IsActive:       true,
+			Status:         models.BinStatusActive,
+		}
+	}
+	return bins
+}
+
+// newBenchRouteService returns a RouteService reading bins from a stub
+// database and routing with straight-line distances
+func newBenchRouteService(b *testing.B, bins []models.Bin) *RouteService {
+	b.Helper()
+	name := fmt.Sprintf("benchbins-%s", uuid.NewString())
+	sql.Register(name, &benchDriver{bins: bins})
+	db, err := sqlx.Open(name, "")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { db.Close() })
+
+	binRepo := repository.NewBinRepository(repository.NewDB(db, 0), cache.Noop{})
+	return NewRouteService(binRepo, nil, nil, nil, nil, NewHaversineRoutingProvider(), &config.RoutingConfig{TruckCapacityLiters: 10000})
+}
+
+// benchColumns are the bin columns the stub database returns
+var benchColumns = []string{"id", "organization_id", "device_id", "latitude", "longitude", "fill_level", "waste_type", "capacity_liters", "is_active", "status"}
+
+// benchDriver is a database/sql driver answering the bin lookups of
+// BinRepository.GetByID and GetByIDs from memory, after benchRoundTrip
+type benchDriver struct {
+	once sync.Once
+	bins []models.Bin
+	byID map[string]*models.Bin
+}
+
+func (d *benchDriver) Open(string) (driver.Conn, error) {
+	d.once.Do(func() {
+		d.byID = make(map[string]*models.Bin, len(d.bins))
+		for i := range d.bins {
+			d.byID[d.bins[i].ID.String()] = &d.bins[i]
+		}
+	})
+	return &benchConn{driver: d}, nil
+}
+
+type benchConn struct {
+	driver *benchDriver
+}
+
+func (c *benchConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("benchbins: prepared statements are not supported")
+}
+
+func (c *benchConn) Close() error { return nil }
+
+func (c *benchConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("benchbins: transactions are not supported")
+}
+
+// QueryContext looks up the bins of the ID, or the array of IDs, bound to $1
+func (c *benchConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if !strings.HasPrefix(query, "SELECT * FROM bins WHERE id") || len(args) == 0 {
+		return nil, fmt.Errorf("benchbins: unexpected query %q", query)
+	}
+	select {
+	case <-time.After(benchRoundTrip):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	var value string
+	switch v := args[0].Value.(type) {
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	default:
+		return nil, fmt.Errorf("benchbins: unexpected argument %T", v)
+	}
+
+	rows := &benchRows{}
+	for _, id := range strings.Split(strings.Trim(value, "{}"), ",") {
+		if bin, ok := c.driver.byID[strings.Trim(id, `"`)]; ok {
+			rows.bins = append(rows.bins, bin)
+		}
+	}
+	return rows, nil
+}
+
+type benchRows struct {
+	bins []*models.Bin
+	next int
+}
+
+func (r *benchRows) Columns() []string { return benchColumns }
+
+func (r *benchRows) Close() error { return nil }
+
+func (r *benchRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.bins) {
+		return io.EOF
+	}
+	bin := r.bins[r.next]
+	r.next++
+	values := []driver.Value{
+		bin.ID.String(), bin.OrganizationID.String(), bin.DeviceID, bin.Latitude, bin.Longitude,
+		int64(bin.FillLevel), bin.WasteType, int64(bin.CapacityLiters), bin.IsActive, string(bin.Status),
+	}
+	copy(dest, values)
+	return nil
+}
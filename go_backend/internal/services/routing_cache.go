@@ -0,0 +1,107 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrRoutingQuotaExhausted is returned by a provider that used up its daily
+// request quota
+var ErrRoutingQuotaExhausted = errors.New("routing quota exhausted")
+
+// routeCache keeps route metrics by path for a TTL, holding at most size routes
+type routeCache struct {
+	ttl  time.Duration
+	size int
+
+	mu      sync.Mutex
+	entries map[string]routeCacheEntry
+}
+
+type routeCacheEntry struct {
+	metrics   RouteMetrics
+	expiresAt time.Time
+}
+
+// newRouteCache creates a cache, or returns nil when ttl or size disable it
+func newRouteCache(ttl time.Duration, size int) *routeCache {
+	if ttl <= 0 || size <= 0 {
+		return nil
+	}
+	return &routeCache{ttl: ttl, size: size, entries: make(map[string]routeCacheEntry)}
+}
+
+// get returns the metrics cached for key, if not expired
+func (c *routeCache) get(key string) (*RouteMetrics, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	metrics := entry.metrics
+	return &metrics, true
+}
+
+// set caches the metrics of key. A full cache first drops the expired routes,
+// then arbitrary ones.
+func (c *routeCache) set(key string, metrics *RouteMetrics) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.size {
+		for k, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		for k := range c.entries {
+			if len(c.entries) < c.size {
+				break
+			}
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = routeCacheEntry{metrics: *metrics, expiresAt: now.Add(c.ttl)}
+}
+
+// dailyQuota counts requests per UTC day up to a limit, 0 being unlimited
+type dailyQuota struct {
+	limit int
+
+	mu   sync.Mutex
+	day  time.Time
+	used int
+}
+
+// take reserves one request of today's quota, reporting false when none is left
+func (q *dailyQuota) take() bool {
+	if q.limit <= 0 {
+		return true
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if !today.Equal(q.day) {
+		q.day, q.used = today, 0
+	}
+	if q.used >= q.limit {
+		return false
+	}
+	q.used++
+	return true
+}
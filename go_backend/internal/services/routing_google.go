@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/smartwaste/backend/internal/config"
+	"github.com/smartwaste/backend/internal/metrics"
+)
+
+// googleMaxPoints is the origin, destination and up to 25 waypoints allowed per
+// Directions request
+const googleMaxPoints = 27
+
+// GoogleRoutingProvider uses the Google Maps Directions API. Results are
+// cached by the points requested, and requests stop at the daily quota.
+type GoogleRoutingProvider struct {
+	client *http.Client
+	apiKey string
+	cache  *routeCache
+	quota  *dailyQuota
+}
+
+// NewGoogleRoutingProvider creates a new GoogleRoutingProvider
+func NewGoogleRoutingProvider(client *http.Client, cfg *config.GoogleConfig) *GoogleRoutingProvider {
+	return &GoogleRoutingProvider{
+		client: client,
+		apiKey: cfg.MapsAPIKey,
+		cache:  newRouteCache(cfg.DirectionsCacheTTL, cfg.DirectionsCacheSize),
+		quota:  &dailyQuota{limit: cfg.DirectionsDailyQuota},
+	}
+}
+
+// Name returns the provider name
+func (p *GoogleRoutingProvider) Name() string {
+	return RoutingProviderGoogle
+}
+
+// Route fetches driving distance and time for the path in the given order
+func (p *GoogleRoutingProvider) Route(ctx context.Context, path []LatLng) (*RouteMetrics, error) {
+	return routeInChunks(ctx, path, googleMaxPoints, p.cachedDirections)
+}
+
+// cachedDirections serves a chunk from the cache, or requests it while the
+// daily quota lasts
+func (p *GoogleRoutingProvider) cachedDirections(ctx context.Context, path []LatLng) (*RouteMetrics, error) {
+	points := make([]string, len(path))
+	for i, point := range path {
+		points[i] = formatGoogleLatLng(point)
+	}
+	key := strings.Join(points, "|")
+
+	if cached, ok := p.cache.get(key); ok {
+		metrics.GoogleDirections.WithLabelValues("cached").Inc()
+		return cached, nil
+	}
+	if !p.quota.take() {
+		metrics.GoogleDirections.WithLabelValues("quota_exceeded").Inc()
+		return nil, fmt.Errorf("%w: %d Google Maps Directions requests today", ErrRoutingQuotaExhausted, p.quota.limit)
+	}
+
+	metrics.GoogleDirections.WithLabelValues("requested").Inc()
+	route, err := p.directions(ctx, points)
+	if err != nil {
+		return nil, err
+	}
+	p.cache.set(key, route)
+	return route, nil
+}
+
+// directions requests the route through the formatted points
+func (p *GoogleRoutingProvider) directions(ctx context.Context, points []string) (*RouteMetrics, error) {
+	query := url.Values{}
+	query.Set("origin", points[0])
+	query.Set("destination", points[len(points)-1])
+	if len(points) > 2 {
+		query.Set("waypoints", strings.Join(points[1:len(points)-1], "|"))
+	}
+	query.Set("key", p.apiKey)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		"https://maps.googleapis.com/maps/api/directions/json?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build Google Maps request: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call Google Maps API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Status string `json:"status"`
+		Routes []struct {
+			Legs []struct {
+				Distance struct {
+					Value int `json:"value"` // meters
+				} `json:"distance"`
+				Duration struct {
+					Value int `json:"value"` // seconds
+				} `json:"duration"`
+			} `json:"legs"`
+		} `json:"routes"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse Google Maps response: %w", err)
+	}
+
+	if result.Status != "OK" || len(result.Routes) == 0 {
+		return nil, fmt.Errorf("no routes found: %s", result.Status)
+	}
+
+	// Sum up all legs
+	route := &RouteMetrics{}
+	for _, leg := range result.Routes[0].Legs {
+		route.DistanceKm += float64(leg.Distance.Value) / 1000
+		route.Duration += time.Duration(leg.Duration.Value) * time.Second
+	}
+
+	return route, nil
+}
+
+func formatGoogleLatLng(point LatLng) string {
+	return fmt.Sprintf("%f,%f", point.Latitude, point.Longitude)
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// osrmMaxPoints keeps requests within the default osrm-routed coordinate limit
+const osrmMaxPoints = 100
+
+// OSRMRoutingProvider uses an OSRM server's route service
+type OSRMRoutingProvider struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewOSRMRoutingProvider creates a new OSRMRoutingProvider
+func NewOSRMRoutingProvider(client *http.Client, baseURL string) *OSRMRoutingProvider {
+	return &OSRMRoutingProvider{
+		client:  client,
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// Name returns the provider name
+func (p *OSRMRoutingProvider) Name() string {
+	return RoutingProviderOSRM
+}
+
+// Route fetches driving distance and time for the path in the given order
+func (p *OSRMRoutingProvider) Route(ctx context.Context, path []LatLng) (*RouteMetrics, error) {
+	return routeInChunks(ctx, path, osrmMaxPoints, p.route)
+}
+
+func (p *OSRMRoutingProvider) route(ctx context.Context, path []LatLng) (*RouteMetrics, error) {
+	// OSRM expects longitude,latitude pairs
+	coordinates := make([]string, len(path))
+	for i, point := range path {
+		coordinates[i] = fmt.Sprintf("%f,%f", point.Longitude, point.Latitude)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("%s/route/v1/driving/%s?overview=false", p.baseURL, strings.Join(coordinates, ";")), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OSRM request: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call OSRM: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Routes  []struct {
+			Distance float64 `json:"distance"` // meters
+			Duration float64 `json:"duration"` // seconds
+		} `json:"routes"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse OSRM response: %w", err)
+	}
+
+	if result.Code != "Ok" || len(result.Routes) == 0 {
+		return nil, fmt.Errorf("no routes found: %s %s", result.Code, result.Message)
+	}
+
+	return &RouteMetrics{
+		DistanceKm: result.Routes[0].Distance / 1000,
+		Duration:   time.Duration(result.Routes[0].Duration * float64(time.Second)),
+	}, nil
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/smartwaste/backend/internal/config"
+)
+
+// Routing provider names accepted by ROUTING_PROVIDER
+const (
+	RoutingProviderAuto      = "auto" // Google if a key is configured, otherwise Haversine
+	RoutingProviderGoogle    = "google"
+	RoutingProviderOSRM      = "osrm"
+	RoutingProviderHaversine = "haversine"
+)
+
+// routingHTTPTimeout bounds calls to external routing APIs
+const routingHTTPTimeout = 10 * time.Second
+
+// LatLng is a point on a route
+type LatLng struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// RouteMetrics is the travel distance and time along a route
+type RouteMetrics struct {
+	DistanceKm float64
+	Duration   time.Duration
+}
+
+// RoutingProvider computes the travel distance and time of a path visited in order
+type RoutingProvider interface {
+	Name() string
+	Route(ctx context.Context, path []LatLng) (*RouteMetrics, error)
+}
+
+// NewRoutingProvider creates the routing provider selected by the configuration
+func NewRoutingProvider(cfg *config.RoutingConfig, google *config.GoogleConfig) (RoutingProvider, error) {
+	client := &http.Client{Timeout: routingHTTPTimeout}
+
+	switch cfg.Provider {
+	case RoutingProviderAuto, "":
+		if google.MapsAPIKey != "" {
+			return NewGoogleRoutingProvider(client, google), nil
+		}
+		return NewHaversineRoutingProvider(), nil
+	case RoutingProviderGoogle:
+		if google.MapsAPIKey == "" {
+			return nil, fmt.Errorf("routing provider %q requires GOOGLE_MAPS_API_KEY", cfg.Provider)
+		}
+		return NewGoogleRoutingProvider(client, google), nil
+	case RoutingProviderOSRM:
+		if cfg.OSRMURL == "" {
+			return nil, fmt.Errorf("routing provider %q requires OSRM_URL", cfg.Provider)
+		}
+		return NewOSRMRoutingProvider(client, cfg.OSRMURL), nil
+	case RoutingProviderHaversine:
+		return NewHaversineRoutingProvider(), nil
+	default:
+		return nil, fmt.Errorf("unknown routing provider %q", cfg.Provider)
+	}
+}
+
+// HaversineRoutingProvider estimates routes offline from straight-line distances
+type HaversineRoutingProvider struct {
+	speedKmh float64
+}
+
+// NewHaversineRoutingProvider creates a new HaversineRoutingProvider
+func NewHaversineRoutingProvider() *HaversineRoutingProvider {
+	// Assume an average speed of 30 km/h in urban areas
+	return &HaversineRoutingProvider{speedKmh: 30}
+}
+
+// Name returns the provider name
+func (p *HaversineRoutingProvider) Name() string {
+	return RoutingProviderHaversine
+}
+
+// Route sums the great-circle distance between consecutive points
+func (p *HaversineRoutingProvider) Route(ctx context.Context, path []LatLng) (*RouteMetrics, error) {
+	distance := 0.0
+	for i := 1; i < len(path); i++ {
+		distance += haversineDistance(path[i-1].Latitude, path[i-1].Longitude, path[i].Latitude, path[i].Longitude)
+	}
+
+	return &RouteMetrics{
+		DistanceKm: distance,
+		Duration:   time.Duration(distance / p.speedKmh * float64(time.Hour)),
+	}, nil
+}
+
+// routeInChunks splits a path into overlapping chunks of at most maxPoints for
+// APIs that cap the number of points per request, and sums the results
+func routeInChunks(ctx context.Context, path []LatLng, maxPoints int, route func(ctx context.Context, chunk []LatLng) (*RouteMetrics, error)) (*RouteMetrics, error) {
+	if len(path) < 2 {
+		return &RouteMetrics{}, nil
+	}
+
+	total := &RouteMetrics{}
+	for start := 0; start < len(path)-1; start += maxPoints - 1 {
+		end := start + maxPoints
+		if end > len(path) {
+			end = len(path)
+		}
+
+		metrics, err := route(ctx, path[start:end])
+		if err != nil {
+			return nil, err
+		}
+		total.DistanceKm += metrics.DistanceKm
+		total.Duration += metrics.Duration
+	}
+
+	return total, nil
+}
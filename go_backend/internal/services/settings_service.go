@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	"github.com/smartwaste/backend/internal/config"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+)
+
+// ErrInvalidSettings is returned for settings that cannot be applied
+var ErrInvalidSettings = errors.New("invalid settings")
+
+// SettingsService serves the runtime settings to the other services. The
+// settings stored in the database override the environment configuration
+// and are reloaded periodically, so every instance picks up a change.
+type SettingsService struct {
+	repo     *repository.SettingsRepository
+	defaults models.Settings
+
+	mu      sync.RWMutex
+	current models.Settings
+}
+
+// NewSettingsService creates a new SettingsService serving the environment
+// configuration until Reload reads the stored settings
+func NewSettingsService(repo *repository.SettingsRepository, devices *config.DeviceHealthConfig, dispatch *config.DispatchConfig) *SettingsService {
+	defaults := models.Settings{
+		Thresholds: models.ThresholdSettings{
+			LowBattery:          devices.LowBatteryThreshold,
+			FireTemperatureC:    devices.FireTemperatureThreshold,
+			OfflineAfterMinutes: int(devices.OfflineAfter / time.Minute),
+		},
+		Dispatch: models.DispatchSettings{
+			Enabled:      dispatch.Enabled,
+			Schedule:     dispatch.Schedule,
+			MaxPerDriver: dispatch.MaxPerDriver,
+		},
+		Notifications: models.NotificationSettings{
+			BinFull:    true,
+			LowBattery: true,
+			BinOffline: true,
+			BinFlagged: true,
+			SLABreach:  true,
+			FireHazard: true,
+		},
+	}
+	return &SettingsService{repo: repo, defaults: defaults, current: defaults}
+}
+
+// Current returns the settings in effect
+func (s *SettingsService) Current() models.Settings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.current
+}
+
+// OfflineAfter is the silence after which a bin is flagged offline
+func (s *SettingsService) OfflineAfter() time.Duration {
+	return time.Duration(s.Current().Thresholds.OfflineAfterMinutes) * time.Minute
+}
+
+// DispatchSchedule is the cron schedule of the automatic dispatch, or "" while
+// it is disabled
+func (s *SettingsService) DispatchSchedule() string {
+	dispatch := s.Current().Dispatch
+	if !dispatch.Enabled {
+		return ""
+	}
+	return dispatch.Schedule
+}
+
+// Reload reads the stored settings over the environment configuration. When
+// they are invalid the settings in effect are kept.
+func (s *SettingsService) Reload(ctx context.Context) error {
+	settings, err := s.load(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load settings: %w", err)
+	}
+	if err := validateSettings(&settings); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	changed := settings != s.current
+	s.current = settings
+	s.mu.Unlock()
+
+	if changed {
+		log.Printf("Runtime settings reloaded: %+v", settings)
+	}
+	return nil
+}
+
+// Update applies the changes of req to the stored settings and puts them in
+// effect. updatedBy is the user making the change, nil for an API key.
+func (s *SettingsService) Update(ctx context.Context, req *models.UpdateSettingsRequest, updatedBy *uuid.UUID) (*models.Settings, error) {
+	// Start from the stored settings, which another instance may have changed
+	settings, err := s.load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load settings: %w", err)
+	}
+	sections := req.Apply(&settings)
+	if len(sections) == 0 {
+		return &settings, nil
+	}
+	if err := validateSettings(&settings); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Save(ctx, &settings, sections, updatedBy); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.current = settings
+	s.mu.Unlock()
+
+	log.Printf("Runtime settings %v updated", sections)
+	return &settings, nil
+}
+
+// load returns the environment configuration overridden by the stored settings
+func (s *SettingsService) load(ctx context.Context) (models.Settings, error) {
+	settings := s.defaults
+	err := s.repo.Load(ctx, &settings)
+	return settings, err
+}
+
+// validateSettings checks what the request bindings cannot, such as the
+// dispatch schedule
+func validateSettings(settings *models.Settings) error {
+	if settings.Dispatch.Enabled {
+		if _, err := cron.ParseStandard(settings.Dispatch.Schedule); err != nil {
+			return fmt.Errorf("%w: dispatch schedule %q: %v", ErrInvalidSettings, settings.Dispatch.Schedule, err)
+		}
+	}
+	if settings.Thresholds.OfflineAfterMinutes < 1 {
+		return fmt.Errorf("%w: offline_after_minutes must be at least 1", ErrInvalidSettings)
+	}
+	return nil
+}
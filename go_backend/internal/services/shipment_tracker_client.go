@@ -0,0 +1,145 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/auth"
+	"github.com/smartwaste/backend/internal/models"
+)
+
+// trackerHTTPTimeout bounds a call to the shipment tracker
+const trackerHTTPTimeout = 10 * time.Second
+
+// trackerPrincipal is the email of the tokens the backend calls the shipment
+// tracker with
+const trackerPrincipal = "backend@smartwaste.internal"
+
+// ErrTrackerRejected is returned when the shipment tracker refuses a request,
+// so sending it again would not help
+var ErrTrackerRejected = errors.New("shipment tracker rejected the request")
+
+// TrackerLocation is a location of a shipment
+type TrackerLocation struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Address   string  `json:"address,omitempty"`
+}
+
+// CreateTrackerShipment is the request creating a shipment in the tracker
+type CreateTrackerShipment struct {
+	UserID            uuid.UUID        `json:"user_id"`
+	CollectionID      uuid.UUID        `json:"collection_id"`
+	WasteType         string           `json:"waste_type"`
+	EstimatedWeightKg float64          `json:"estimated_weight_kg"`
+	PriceOffered      float64          `json:"price_offered"`
+	PickupLocation    *TrackerLocation `json:"pickup_location,omitempty"`
+	Notes             *string          `json:"notes,omitempty"`
+}
+
+// TrackerShipment is the part of a tracker shipment the backend reads
+type TrackerShipment struct {
+	ID           uuid.UUID `json:"id"`
+	CollectionID uuid.UUID `json:"collection_id"`
+	Status       string    `json:"status"`
+}
+
+// ShipmentTrackerClient calls the REST API of the shipment tracker, as an
+// admin of the organization the call is made for
+type ShipmentTrackerClient struct {
+	client  *http.Client
+	baseURL string
+	tokens  *auth.TokenManager
+}
+
+// NewShipmentTrackerClient creates a new ShipmentTrackerClient
+func NewShipmentTrackerClient(baseURL string, tokens *auth.TokenManager) *ShipmentTrackerClient {
+	return &ShipmentTrackerClient{
+		client:  &http.Client{Timeout: trackerHTTPTimeout},
+		baseURL: strings.TrimRight(baseURL, "/"),
+		tokens:  tokens,
+	}
+}
+
+// CreateShipment creates a shipment
+func (c *ShipmentTrackerClient) CreateShipment(ctx context.Context, organizationID uuid.UUID, req *CreateTrackerShipment) (*TrackerShipment, error) {
+	var shipment TrackerShipment
+	if err := c.do(ctx, organizationID, http.MethodPost, "/api/v1/shipments", req, &shipment); err != nil {
+		return nil, err
+	}
+	return &shipment, nil
+}
+
+// FindByCollection returns the shipment of a collection that was not
+// cancelled, or nil if it has none
+func (c *ShipmentTrackerClient) FindByCollection(ctx context.Context, organizationID, collectionID uuid.UUID) (*TrackerShipment, error) {
+	var page struct {
+		Data []TrackerShipment `json:"data"`
+	}
+	path := fmt.Sprintf("/api/v1/shipments?collection_id=%s&per_page=100", collectionID)
+	if err := c.do(ctx, organizationID, http.MethodGet, path, nil, &page); err != nil {
+		return nil, err
+	}
+	for i := range page.Data {
+		if page.Data[i].Status != "cancelled" {
+			return &page.Data[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *ShipmentTrackerClient) do(ctx context.Context, organizationID uuid.UUID, method, path string, body, out interface{}) error {
+	token, _, err := c.tokens.Issue(uuid.Nil, organizationID, trackerPrincipal, models.RoleAdmin)
+	if err != nil {
+		return fmt.Errorf("failed to issue tracker token: %w", err)
+	}
+
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode tracker request: %w", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to build tracker request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call shipment tracker: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var failure struct {
+			Error string `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&failure)
+		err := fmt.Errorf("shipment tracker returned %d: %s", resp.StatusCode, failure.Error)
+		// Server errors and throttling pass, the rest would fail again
+		if resp.StatusCode < http.StatusInternalServerError && resp.StatusCode != http.StatusTooManyRequests {
+			err = fmt.Errorf("%w: %v", ErrTrackerRejected, err)
+		}
+		return err
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse tracker response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/smartwaste/backend/internal/config"
+)
+
+// Storage provider names accepted by STORAGE_PROVIDER
+const (
+	StorageProviderS3    = "s3"
+	StorageProviderMinIO = "minio"
+	StorageProviderGCS   = "gcs" // Through the S3-compatible XML API and an HMAC key
+)
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Size        int64
+	ContentType string
+}
+
+// ObjectStore stores uploaded files and issues pre-signed URLs to upload them
+type ObjectStore interface {
+	Name() string
+	// PresignPut returns a URL to PUT an object of exactly the given type and
+	// size to, together with the headers the request must send
+	PresignPut(ctx context.Context, key, contentType string, size int64, expires time.Duration) (string, http.Header, error)
+	// Stat returns nil when the object does not exist
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+	// URL returns the URL the object is served from
+	URL(key string) string
+}
+
+// NewObjectStore creates the object store selected by the configuration; it
+// returns nil when no storage provider is configured
+func NewObjectStore(cfg *config.StorageConfig) (ObjectStore, error) {
+	endpoint := cfg.Endpoint
+	switch cfg.Provider {
+	case "":
+		return nil, nil
+	case StorageProviderS3:
+		if endpoint == "" {
+			endpoint = "s3.amazonaws.com"
+		}
+	case StorageProviderGCS:
+		if endpoint == "" {
+			endpoint = "storage.googleapis.com"
+		}
+	case StorageProviderMinIO:
+		if endpoint == "" {
+			return nil, fmt.Errorf("storage provider %q requires STORAGE_ENDPOINT", cfg.Provider)
+		}
+	default:
+		return nil, fmt.Errorf("unknown storage provider %q", cfg.Provider)
+	}
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("storage provider %q requires STORAGE_BUCKET", cfg.Provider)
+	}
+	if cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, fmt.Errorf("storage provider %q requires STORAGE_ACCESS_KEY and STORAGE_SECRET_KEY", cfg.Provider)
+	}
+
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid storage endpoint %q: %w", endpoint, err)
+	}
+
+	publicURL := cfg.PublicURL
+	if publicURL == "" {
+		scheme := "http"
+		if cfg.UseSSL {
+			scheme = "https"
+		}
+		publicURL = fmt.Sprintf("%s://%s/%s", scheme, endpoint, cfg.Bucket)
+	}
+
+	return &S3ObjectStore{
+		name:      cfg.Provider,
+		client:    client,
+		bucket:    cfg.Bucket,
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+	}, nil
+}
+
+// S3ObjectStore stores objects in a bucket of an S3-compatible service
+type S3ObjectStore struct {
+	name      string
+	client    *minio.Client
+	bucket    string
+	publicURL string
+}
+
+// Name returns the provider name
+func (s *S3ObjectStore) Name() string {
+	return s.name
+}
+
+// PresignPut signs the content type and length into the URL so the upload
+// cannot exceed what was validated
+func (s *S3ObjectStore) PresignPut(ctx context.Context, key, contentType string, size int64, expires time.Duration) (string, http.Header, error) {
+	headers := http.Header{}
+	headers.Set("Content-Type", contentType)
+	headers.Set("Content-Length", strconv.FormatInt(size, 10))
+
+	presigned, err := s.client.PresignHeader(ctx, http.MethodPut, s.bucket, key, expires, url.Values{}, headers)
+	if err != nil {
+		return "", nil, err
+	}
+	return presigned.String(), headers, nil
+}
+
+// Stat returns the size and type of an object
+func (s *S3ObjectStore) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	info, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ObjectInfo{Size: info.Size, ContentType: info.ContentType}, nil
+}
+
+// Delete deletes an object; deleting a missing object succeeds
+func (s *S3ObjectStore) Delete(ctx context.Context, key string) error {
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+// URL returns the URL the object is served from
+func (s *S3ObjectStore) URL(key string) string {
+	return s.publicURL + "/" + key
+}
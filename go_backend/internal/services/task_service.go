@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+)
+
+var (
+	// ErrTaskNotFound is returned when a driver responds to a task that was
+	// not offered to them
+	ErrTaskNotFound = errors.New("task not found")
+	// ErrTaskClosed is returned when responding to a task offer that was
+	// already answered or withdrawn
+	ErrTaskClosed = errors.New("task offer is no longer open")
+	// ErrBinCollectionOpen is returned when accepting a bin that already has a
+	// pending or in-progress collection; the offer is withdrawn
+	ErrBinCollectionOpen = errors.New("bin already has an open collection")
+)
+
+// TaskService handles the drivers' answers to the full bins offered to them.
+// Accepting creates the collection of the bin and acknowledges its alert;
+// declining passes the alert on to the next candidate.
+type TaskService struct {
+	taskRepo            *repository.TaskOfferRepository
+	binRepo             *repository.BinRepository
+	notificationRepo    *repository.NotificationRepository
+	notificationService *NotificationService
+}
+
+// NewTaskService creates a new TaskService
+func NewTaskService(
+	taskRepo *repository.TaskOfferRepository,
+	binRepo *repository.BinRepository,
+	notificationRepo *repository.NotificationRepository,
+	notificationService *NotificationService,
+) *TaskService {
+	return &TaskService{
+		taskRepo:            taskRepo,
+		binRepo:             binRepo,
+		notificationRepo:    notificationRepo,
+		notificationService: notificationService,
+	}
+}
+
+// Accept accepts a task offered to the driver, creating a pending collection
+// of the bin assigned to them
+func (s *TaskService) Accept(ctx context.Context, driverID, taskID uuid.UUID) (*models.AcceptTaskResponse, error) {
+	offer, err := s.openOffer(ctx, driverID, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	bin, err := s.binRepo.GetByID(ctx, offer.BinID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bin: %w", err)
+	}
+	if bin == nil {
+		return nil, ErrTaskNotFound
+	}
+
+	collection := &models.Collection{
+		BinID:           bin.ID,
+		DriverID:        driverID,
+		FillLevelBefore: bin.FillLevel,
+		Status:          models.CollectionStatusPending,
+	}
+	if err := s.taskRepo.Accept(ctx, offer, collection); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrTaskClosed
+		}
+		return nil, fmt.Errorf("failed to accept task: %w", err)
+	}
+
+	// The driver answered the alert either way, so it stops escalating
+	s.acknowledge(ctx, offer)
+	if offer.Status == models.TaskOfferWithdrawn {
+		return nil, ErrBinCollectionOpen
+	}
+
+	log.Printf("Driver %s accepted bin %s, collection %s created", driverID, bin.DeviceID, collection.ID)
+	return &models.AcceptTaskResponse{Task: offer, Collection: collection.ToResponse()}, nil
+}
+
+// Decline declines a task offered to the driver and offers the bin to the
+// next-nearest driver, or alerts the dispatchers when no driver is left
+func (s *TaskService) Decline(ctx context.Context, driverID, taskID uuid.UUID, reason *string) (*models.TaskOffer, error) {
+	offer, err := s.openOffer(ctx, driverID, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.taskRepo.Decline(ctx, offer, reason); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrTaskClosed
+		}
+		return nil, fmt.Errorf("failed to decline task: %w", err)
+	}
+
+	if offer.NotificationID != nil {
+		if err := s.notificationService.ReassignAlert(ctx, *offer.NotificationID); err != nil {
+			log.Printf("Failed to reassign declined task %s: %v", offer.ID, err)
+		}
+	}
+	return offer, nil
+}
+
+// openOffer retrieves a task offered to the driver that is still open
+func (s *TaskService) openOffer(ctx context.Context, driverID, taskID uuid.UUID) (*models.TaskOffer, error) {
+	offer, err := s.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+	if offer == nil || offer.DriverID != driverID {
+		return nil, ErrTaskNotFound
+	}
+	if offer.Status != models.TaskOfferOffered {
+		return nil, ErrTaskClosed
+	}
+	return offer, nil
+}
+
+// acknowledge acknowledges the alert notification that offered the task
+func (s *TaskService) acknowledge(ctx context.Context, offer *models.TaskOffer) {
+	if offer.NotificationID == nil {
+		return
+	}
+	notification, err := s.notificationRepo.GetByID(ctx, *offer.NotificationID)
+	if err == nil && notification != nil {
+		err = s.notificationRepo.Acknowledge(ctx, notification)
+	}
+	if err != nil {
+		log.Printf("Failed to acknowledge the alert of task %s: %v", offer.ID, err)
+	}
+}
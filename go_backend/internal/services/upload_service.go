@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/config"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+)
+
+// uploadCleanupBatch bounds the orphaned uploads deleted per cleanup run
+const uploadCleanupBatch = 500
+
+// Upload errors; the handlers map each to a response
+var (
+	ErrUploadsDisabled     = errors.New("uploads are not configured")
+	ErrUploadType          = errors.New("content type is not accepted")
+	ErrUploadTooLarge      = errors.New("file is too large")
+	ErrUploadNotFound      = errors.New("no upload was issued for this URL")
+	ErrUploadNotReceived   = errors.New("the file has not been uploaded yet")
+	ErrUploadWrongUploader = errors.New("the upload belongs to someone else")
+)
+
+// uploadExtensions names objects after their content type
+var uploadExtensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/webp":      ".webp",
+	"image/gif":       ".gif",
+	"image/heic":      ".heic",
+	"application/pdf": ".pdf",
+}
+
+// UploadService issues pre-signed upload URLs, verifies uploads before records
+// refer to them and deletes the uploads nothing refers to
+type UploadService struct {
+	uploadRepo *repository.UploadRepository
+	store      ObjectStore
+	config     *config.StorageConfig
+}
+
+// NewUploadService creates a new UploadService; store is nil when uploads are disabled
+func NewUploadService(uploadRepo *repository.UploadRepository, store ObjectStore, cfg *config.StorageConfig) *UploadService {
+	return &UploadService{
+		uploadRepo: uploadRepo,
+		store:      store,
+		config:     cfg,
+	}
+}
+
+// Enabled reports whether a storage provider is configured
+func (s *UploadService) Enabled() bool {
+	return s.store != nil
+}
+
+// AcceptsType reports whether files of the content type may be uploaded
+func (s *UploadService) AcceptsType(contentType string) bool {
+	for _, accepted := range s.config.ContentTypes {
+		if strings.EqualFold(accepted, contentType) {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateTicket validates an upload and records it, returning a URL to PUT the file to
+func (s *UploadService) CreateTicket(ctx context.Context, req *models.CreateUploadRequest, uploadedBy uuid.UUID, role models.Role) (*models.UploadTicket, error) {
+	if !s.Enabled() {
+		return nil, ErrUploadsDisabled
+	}
+	if !s.AcceptsType(req.ContentType) {
+		return nil, fmt.Errorf("%w; accepted types are %s", ErrUploadType, strings.Join(s.config.ContentTypes, ", "))
+	}
+	if req.SizeBytes > s.config.MaxSize {
+		return nil, fmt.Errorf("%w; the limit is %d bytes", ErrUploadTooLarge, s.config.MaxSize)
+	}
+
+	id := uuid.New()
+	key := fmt.Sprintf("%s/%s/%s%s", req.Purpose, time.Now().UTC().Format("2006/01"), id, uploadExtensions[req.ContentType])
+	upload := models.Upload{
+		ObjectKey:    key,
+		URL:          s.store.URL(key),
+		Purpose:      req.Purpose,
+		ContentType:  req.ContentType,
+		SizeBytes:    req.SizeBytes,
+		UploadedBy:   uploadedBy,
+		UploaderRole: role,
+	}
+
+	expiresAt := time.Now().Add(s.config.URLExpiry)
+	uploadURL, headers, err := s.store.PresignPut(ctx, key, req.ContentType, req.SizeBytes, s.config.URLExpiry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign upload URL: %w", err)
+	}
+	if err := s.uploadRepo.Create(ctx, &upload); err != nil {
+		return nil, err
+	}
+
+	ticket := &models.UploadTicket{
+		Upload:       upload,
+		UploadURL:    uploadURL,
+		UploadMethod: "PUT",
+		Headers:      make(map[string]string, len(headers)),
+		ExpiresAt:    expiresAt,
+	}
+	for name := range headers {
+		ticket.Headers[name] = headers.Get(name)
+	}
+	return ticket, nil
+}
+
+// Resolve checks that a URL about to be stored on a record is usable. URLs
+// outside the upload bucket are returned as nil; uploads must have been
+// received and belong to the uploader. The caller marks the returned upload
+// attached once the record is saved.
+func (s *UploadService) Resolve(ctx context.Context, url string, uploadedBy uuid.UUID) (*models.Upload, error) {
+	if !s.Enabled() {
+		return nil, nil
+	}
+
+	upload, err := s.uploadRepo.GetByURL(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	if upload == nil {
+		if strings.HasPrefix(url, s.store.URL("")) {
+			return nil, ErrUploadNotFound
+		}
+		return nil, nil
+	}
+	if upload.UploadedBy != uploadedBy {
+		return nil, ErrUploadWrongUploader
+	}
+
+	info, err := s.store.Stat(ctx, upload.ObjectKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check upload %s: %w", upload.ID, err)
+	}
+	if info == nil {
+		return nil, ErrUploadNotReceived
+	}
+	if info.Size > s.config.MaxSize {
+		return nil, fmt.Errorf("%w; the limit is %d bytes", ErrUploadTooLarge, s.config.MaxSize)
+	}
+	return upload, nil
+}
+
+// Attach marks a resolved upload as referred to; nil uploads are ignored.
+// The record is already saved when this fails, so the failure is only logged.
+func (s *UploadService) Attach(ctx context.Context, upload *models.Upload) {
+	if upload == nil {
+		return
+	}
+	if err := s.uploadRepo.MarkAttached(ctx, upload.ID); err != nil {
+		log.Printf("Failed to attach upload %s: %v", upload.ID, err)
+	}
+}
+
+// PurgeOrphaned deletes the uploads nothing referred to within the orphan
+// window, objects first so a failed deletion is retried on the next run
+func (s *UploadService) PurgeOrphaned(ctx context.Context) error {
+	if !s.Enabled() {
+		return nil
+	}
+
+	uploads, err := s.uploadRepo.ListUnattached(ctx, time.Now().Add(-s.config.OrphanAfter), uploadCleanupBatch)
+	if err != nil {
+		return fmt.Errorf("failed to list orphaned uploads: %w", err)
+	}
+
+	purged := 0
+	for _, upload := range uploads {
+		if err := s.store.Delete(ctx, upload.ObjectKey); err != nil {
+			log.Printf("Failed to delete object %s: %v", upload.ObjectKey, err)
+			continue
+		}
+		if err := s.uploadRepo.Delete(ctx, upload.ID); err != nil {
+			log.Printf("Failed to delete upload %s: %v", upload.ID, err)
+			continue
+		}
+		purged++
+	}
+	if purged > 0 {
+		log.Printf("Purged %d orphaned uploads", purged)
+	}
+	return nil
+}
@@ -2,32 +2,251 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/smartwaste/backend/internal/models"
 	"github.com/smartwaste/backend/internal/repository"
 )
 
-// ValuationService handles waste valuation based on pricing rules
+// ErrMixedCurrencies is returned when offers in several currencies are compared without a target currency
+var ErrMixedCurrencies = errors.New("offers are priced in several currencies")
+
+// ValuationService handles waste valuation based on pricing rules. Companies
+// only value the waste types their contracts in effect cover, at the
+// contract's rate when it has one.
 type ValuationService struct {
-	pricingRepo *repository.PricingRepository
+	pricingRepo   *repository.PricingRepository
+	contractRepo  *repository.ContractRepository
+	exchangeRates *ExchangeRateService
+	strategies    map[models.PricingModel]PricingStrategy
 }
 
 // NewValuationService creates a new ValuationService
-func NewValuationService(pricingRepo *repository.PricingRepository) *ValuationService {
-	return &ValuationService{
-		pricingRepo: pricingRepo,
+func NewValuationService(pricingRepo *repository.PricingRepository, contractRepo *repository.ContractRepository, exchangeRates *ExchangeRateService) *ValuationService {
+	s := &ValuationService{
+		pricingRepo:   pricingRepo,
+		contractRepo:  contractRepo,
+		exchangeRates: exchangeRates,
+		strategies:    make(map[models.PricingModel]PricingStrategy),
 	}
+	s.RegisterStrategy(FlatPricing{})
+	s.RegisterStrategy(TieredPricing{})
+	s.RegisterStrategy(MarketPricing{})
+	return s
 }
 
-// CalculateValue calculates the value of waste based on type, condition, and weight
+// RegisterStrategy sets the strategy rules of its pricing model are valued with
+func (s *ValuationService) RegisterStrategy(strategy PricingStrategy) {
+	s.strategies[strategy.Model()] = strategy
+}
+
+// CalculateValue calculates the value of waste based on type, condition, and
+// weight, converted to the target currency if requested. The rules of the
+// request's company only apply under one of its contracts covering the waste
+// type; otherwise the global rules value it.
 func (s *ValuationService) CalculateValue(ctx context.Context, req *models.ValuationRequest) (*models.ValuationResponse, error) {
+	at := valuationTime(req)
+
+	var contract *models.Contract
+	if req.CompanyID != nil {
+		var err error
+		contract, err = s.contractRepo.CompanyContract(ctx, *req.CompanyID, req.WasteType, at)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch contract: %w", err)
+		}
+		if contract == nil {
+			global := *req
+			global.CompanyID = nil
+			req = &global
+		}
+	}
+
 	// Find applicable pricing rule
-	rule, err := s.pricingRepo.GetByTypeAndCondition(ctx, req.WasteType, req.Condition)
+	var rule *models.PricingRule
+	if contract != nil {
+		rule = contract.PricingRule(req.WasteType, req.Condition)
+	}
+	if rule == nil {
+		var err error
+		rule, err = s.pricingRepo.GetByTypeAndCondition(ctx, req.WasteType, req.Condition, req.CompanyID, at)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch pricing rule: %w", err)
+		}
+	}
+	return s.valuateUnder(ctx, contract, rule, req, at)
+}
+
+// CalculateCompanyValue values waste with the rules of one company only,
+// under its contract covering the waste type
+func (s *ValuationService) CalculateCompanyValue(ctx context.Context, companyID uuid.UUID, req *models.ValuationRequest) (*models.ValuationResponse, error) {
+	at := valuationTime(req)
+	req.CompanyID = &companyID
+
+	contract, err := s.contractRepo.CompanyContract(ctx, companyID, req.WasteType, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch contract: %w", err)
+	}
+	if contract == nil {
+		return &models.ValuationResponse{
+			WasteType:  req.WasteType,
+			Condition:  req.Condition,
+			WeightKg:   req.WeightKg,
+			Currency:   "USD",
+			ValuatedAt: at,
+			Message:    "No contract of this company covers this waste type",
+		}, nil
+	}
+
+	rule, err := s.contractRule(ctx, contract, req, at)
+	if err != nil {
+		return nil, err
+	}
+	return s.valuateUnder(ctx, contract, rule, req, at)
+}
+
+// Compare values waste with the rules of every active company with a
+// contract covering the waste type and ranks up to limit offers by total,
+// highest first. Companies without a contract rate or a matching rule, or
+// whose rule does not take the weight, make no offer. Offers are compared in
+// the target currency, or in their own when they all share it.
+func (s *ValuationService) Compare(ctx context.Context, req *models.ValuationRequest, limit int) (*models.ValuationComparison, error) {
+	at := valuationTime(req)
+	contracts, err := s.contractRepo.ListCovering(ctx, req.WasteType, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch contracts: %w", err)
+	}
+	companyRules, err := s.pricingRepo.ListCompanyRules(ctx, req.WasteType, req.Condition, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pricing rules: %w", err)
+	}
+	rules := make(map[uuid.UUID]*models.PricingRule, len(companyRules))
+	for i := range companyRules {
+		rules[*companyRules[i].CompanyID] = &companyRules[i].PricingRule
+	}
+
+	comparison := &models.ValuationComparison{
+		WasteType: req.WasteType,
+		Condition: req.Condition,
+		WeightKg:  req.WeightKg,
+		Offers:    []models.ValuationOffer{},
+	}
+	if req.TargetCurrency != nil {
+		comparison.Currency = *req.TargetCurrency
+	}
+
+	for i := range contracts {
+		contract := &contracts[i]
+		rule := contract.PricingRule(req.WasteType, req.Condition)
+		if rule == nil {
+			rule = rules[contract.CompanyID]
+		}
+		if rule == nil || !rule.Accepts(req.WeightKg) {
+			continue
+		}
+
+		offerReq := *req
+		offerReq.CompanyID = &contract.CompanyID
+		valuation, err := s.valuateUnder(ctx, &contract.Contract, rule, &offerReq, at)
+		if err != nil {
+			return nil, err
+		}
+
+		total := valuation.TotalPrice
+		if valuation.Conversion != nil {
+			total = valuation.Conversion.TotalPrice
+		} else if comparison.Currency == "" {
+			comparison.Currency = valuation.Currency
+		} else if comparison.Currency != valuation.Currency {
+			return nil, ErrMixedCurrencies
+		}
+
+		comparison.Offers = append(comparison.Offers, models.ValuationOffer{
+			CompanyID:   contract.CompanyID,
+			CompanyName: contract.CompanyName,
+			Total:       total,
+			Valuation:   valuation,
+		})
+	}
+
+	sort.SliceStable(comparison.Offers, func(i, j int) bool {
+		a, b := comparison.Offers[i], comparison.Offers[j]
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		return a.CompanyName < b.CompanyName
+	})
+	if len(comparison.Offers) > limit {
+		comparison.Offers = comparison.Offers[:limit]
+	}
+	for i := range comparison.Offers {
+		comparison.Offers[i].Rank = i + 1
+	}
+	return comparison, nil
+}
+
+// valuationTime returns the time waste is valued at, now unless the request sets it
+func valuationTime(req *models.ValuationRequest) time.Time {
+	if req.ValuatedAt != nil {
+		return *req.ValuatedAt
+	}
+	return time.Now()
+}
+
+// contractRule returns the rule waste is valued with under a company's
+// contract: the contract's rate for the waste type if it has one, the
+// company's own rule otherwise
+func (s *ValuationService) contractRule(ctx context.Context, contract *models.Contract, req *models.ValuationRequest, at time.Time) (*models.PricingRule, error) {
+	if rule := contract.PricingRule(req.WasteType, req.Condition); rule != nil {
+		return rule, nil
+	}
+	rule, err := s.pricingRepo.GetCompanyRule(ctx, contract.CompanyID, req.WasteType, req.Condition, at)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch pricing rule: %w", err)
 	}
+	return rule, nil
+}
+
+// valuateUnder values waste like valuate, recording the contract it was
+// valued under if any
+func (s *ValuationService) valuateUnder(ctx context.Context, contract *models.Contract, rule *models.PricingRule, req *models.ValuationRequest, at time.Time) (*models.ValuationResponse, error) {
+	valuation, err := s.valuate(ctx, rule, req, at)
+	if err != nil || contract == nil {
+		return valuation, err
+	}
+	contractID := contract.ID.String()
+	valuation.ContractID = &contractID
+	return valuation, nil
+}
+
+// valuate values waste at the given time with the rule then in effect, nil
+// when none matched, converted to the target currency if requested. The
+// conversion always uses the current exchange rates.
+func (s *ValuationService) valuate(ctx context.Context, rule *models.PricingRule, req *models.ValuationRequest, at time.Time) (*models.ValuationResponse, error) {
+	valuation, err := s.quote(ctx, rule, req, at)
+	if err != nil {
+		return nil, err
+	}
+	valuation.ValuatedAt = at
+	if req.TargetCurrency == nil {
+		return valuation, nil
+	}
+
+	valuation.Conversion, err = s.exchangeRates.Convert(ctx, valuation, *req.TargetCurrency)
+	if err != nil {
+		return nil, err
+	}
+	return valuation, nil
+}
 
+// quote values waste in the currency of its rule: the rule's strategy prices
+// the weight, then the condition multiplier and the highest running promotion
+// adjust the subtotal. Market prices and promotions are those of the given time.
+func (s *ValuationService) quote(ctx context.Context, rule *models.PricingRule, req *models.ValuationRequest, at time.Time) (*models.ValuationResponse, error) {
 	if rule == nil {
 		// No specific rule found, return default pricing
 		return &models.ValuationResponse{
@@ -66,35 +285,91 @@ func (s *ValuationService) CalculateValue(ctx context.Context, req *models.Valua
 		}, nil
 	}
 
-	// Calculate total value
-	totalPrice := req.WeightKg * rule.PricePerKg
-	ruleID := rule.ID.String()
+	strategy, ok := s.strategies[rule.PricingModel]
+	if !ok {
+		return nil, fmt.Errorf("no strategy for pricing model %q of rule %s", rule.PricingModel, rule.ID)
+	}
+
+	input := PricingInput{Rule: rule, WeightKg: req.WeightKg}
+	if rule.PricingModel == models.PricingModelMarket {
+		var err error
+		input.MarketPrice, err = s.pricingRepo.LatestMarketPrice(ctx, rule.WasteType, rule.Currency, at)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch market price: %w", err)
+		}
+	}
+
+	breakdown := strategy.Quote(input)
+	subtotal := sumLines(breakdown)
+
+	if multiplier, ok := rule.ConditionMultipliers[req.Condition]; ok && multiplier != 1 {
+		description := fmt.Sprintf("Condition %s x %.3f", req.Condition, multiplier)
+		breakdown = append(breakdown, multiplierLine(models.QuoteLineCondition, description, subtotal, multiplier))
+		subtotal = sumLines(breakdown)
+	}
+
+	promotion, err := s.pricingRepo.ActivePromotion(ctx, rule.WasteType, req.CompanyID, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch promotions: %w", err)
+	}
+	var promotionID *string
+	if promotion != nil && promotion.Multiplier != 1 {
+		description := fmt.Sprintf("%s x %.3f until %s", promotion.Name, promotion.Multiplier, promotion.EndsAt.Format(time.RFC3339))
+		breakdown = append(breakdown, multiplierLine(models.QuoteLinePromotion, description, subtotal, promotion.Multiplier))
+		id := promotion.ID.String()
+		promotionID = &id
+	}
+
+	// Calculate total value; contract rates have no rule of their own
+	totalPrice := sumLines(breakdown)
+	var ruleID *string
+	if rule.ID != uuid.Nil {
+		id := rule.ID.String()
+		ruleID = &id
+	}
 
 	return &models.ValuationResponse{
 		WasteType:     req.WasteType,
 		Condition:     req.Condition,
 		WeightKg:      req.WeightKg,
-		PricePerKg:    rule.PricePerKg,
+		PricePerKg:    totalPrice / req.WeightKg,
 		TotalPrice:    totalPrice,
 		Currency:      rule.Currency,
-		PricingRuleID: &ruleID,
+		PricingRuleID: ruleID,
+		RuleVersion:   rule.Version,
+		PricingModel:  rule.PricingModel,
+		PromotionID:   promotionID,
+		Breakdown:     breakdown,
 		Message:       "Valuation calculated successfully",
 	}, nil
 }
 
-// ValuateWasteMetadata valuates waste based on AI-detected metadata
+// sumLines adds up the amounts of quote lines
+func sumLines(lines []models.QuoteLine) float64 {
+	total := 0.0
+	for _, line := range lines {
+		total += line.Amount
+	}
+	return roundCents(total)
+}
+
+// ValuateWasteMetadata valuates waste based on AI-detected metadata with the
+// pricing in effect when it was detected
 func (s *ValuationService) ValuateWasteMetadata(ctx context.Context, metadata *models.WasteMetadata, weightKg float64) (*models.ValuationResponse, error) {
 	req := &models.ValuationRequest{
 		WasteType: metadata.WasteType,
 		Condition: metadata.Condition,
 		WeightKg:  weightKg,
 	}
+	if !metadata.DetectedAt.IsZero() {
+		req.ValuatedAt = &metadata.DetectedAt
+	}
 	return s.CalculateValue(ctx, req)
 }
 
 // GetPricingRules returns all active pricing rules
-func (s *ValuationService) GetPricingRules(ctx context.Context, limit, offset int) ([]models.PricingRule, error) {
-	return s.pricingRepo.List(ctx, limit, offset)
+func (s *ValuationService) GetPricingRules(ctx context.Context, page repository.Page) ([]models.PricingRule, repository.PageResult, error) {
+	return s.pricingRepo.List(ctx, page)
 }
 
 // Common waste types for reference
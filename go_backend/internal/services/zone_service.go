@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/backend/internal/models"
+	"github.com/smartwaste/backend/internal/repository"
+)
+
+var (
+	// ErrZoneNotFound is returned when a driver is given a zone that does not exist
+	ErrZoneNotFound = errors.New("zone not found")
+	// ErrDriverNotFound is returned when setting the zones of a driver that does not exist
+	ErrDriverNotFound = errors.New("driver not found")
+)
+
+// ZoneService manages the zones bins are grouped into and the zones drivers cover
+type ZoneService struct {
+	zoneRepo   *repository.ZoneRepository
+	binRepo    *repository.BinRepository
+	driverRepo *repository.DriverRepository
+}
+
+// NewZoneService creates a new ZoneService
+func NewZoneService(zoneRepo *repository.ZoneRepository, binRepo *repository.BinRepository, driverRepo *repository.DriverRepository) *ZoneService {
+	return &ZoneService{
+		zoneRepo:   zoneRepo,
+		binRepo:    binRepo,
+		driverRepo: driverRepo,
+	}
+}
+
+// Create creates a zone, moving the bins it contains into it
+func (s *ZoneService) Create(ctx context.Context, req *models.CreateZoneRequest) (*models.Zone, error) {
+	zone := &models.Zone{
+		Name:        req.Name,
+		Kind:        req.Kind,
+		Description: req.Description,
+		Boundary:    req.Boundary,
+	}
+	if zone.Kind == "" {
+		zone.Kind = models.ZoneKindNeighborhood
+	}
+
+	if err := s.zoneRepo.Create(ctx, zone); err != nil {
+		return nil, err
+	}
+	s.binRepo.InvalidateCache(ctx, zone.OrganizationID)
+	return zone, nil
+}
+
+// Get retrieves a zone
+func (s *ZoneService) Get(ctx context.Context, id uuid.UUID) (*models.Zone, error) {
+	return s.zoneRepo.GetByID(ctx, id)
+}
+
+// List retrieves zones ordered by name
+func (s *ZoneService) List(ctx context.Context, page repository.Page) ([]models.Zone, repository.PageResult, error) {
+	return s.zoneRepo.List(ctx, page)
+}
+
+// Update saves a zone, reassigning the bins of its organization
+func (s *ZoneService) Update(ctx context.Context, zone *models.Zone) error {
+	if err := s.zoneRepo.Update(ctx, zone); err != nil {
+		return err
+	}
+	s.binRepo.InvalidateCache(ctx, zone.OrganizationID)
+	return nil
+}
+
+// Delete deletes a zone, moving its bins to the zone containing them next
+func (s *ZoneService) Delete(ctx context.Context, zone *models.Zone) error {
+	if err := s.zoneRepo.Delete(ctx, zone); err != nil {
+		return err
+	}
+	s.binRepo.InvalidateCache(ctx, zone.OrganizationID)
+	return nil
+}
+
+// Bins retrieves the active bins of a zone
+func (s *ZoneService) Bins(ctx context.Context, zoneID uuid.UUID, page repository.Page) ([]models.Bin, repository.PageResult, error) {
+	return s.binRepo.ListByZone(ctx, zoneID, page)
+}
+
+// Analytics summarizes the zones of the organization, or only zoneID when set
+func (s *ZoneService) Analytics(ctx context.Context, period models.AnalyticsRange, zoneID *uuid.UUID) (*models.ZoneAnalyticsReport, error) {
+	zones, err := s.zoneRepo.Analytics(ctx, period.From, period.To, zoneID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ZoneAnalyticsReport{Period: period, Zones: zones}, nil
+}
+
+// DriverZones retrieves the zones a driver covers
+func (s *ZoneService) DriverZones(ctx context.Context, driverID uuid.UUID) ([]models.Zone, error) {
+	if _, err := s.loadDriver(ctx, driverID); err != nil {
+		return nil, err
+	}
+	return s.zoneRepo.DriverZones(ctx, driverID)
+}
+
+// SetDriverZones restricts a driver to the zones, or lets them be dispatched
+// anywhere when there are none, and returns the zones they now cover
+func (s *ZoneService) SetDriverZones(ctx context.Context, driverID uuid.UUID, zoneIDs []uuid.UUID) ([]models.Zone, error) {
+	if _, err := s.loadDriver(ctx, driverID); err != nil {
+		return nil, err
+	}
+
+	unique := make([]uuid.UUID, 0, len(zoneIDs))
+	seen := make(map[uuid.UUID]bool, len(zoneIDs))
+	for _, id := range zoneIDs {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	if err := s.zoneRepo.SetDriverZones(ctx, driverID, unique); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrZoneNotFound
+		}
+		return nil, err
+	}
+	return s.zoneRepo.DriverZones(ctx, driverID)
+}
+
+// loadDriver retrieves a driver, returning ErrDriverNotFound when missing
+func (s *ZoneService) loadDriver(ctx context.Context, id uuid.UUID) (*models.Driver, error) {
+	driver, err := s.driverRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if driver == nil {
+		return nil, ErrDriverNotFound
+	}
+	return driver, nil
+}
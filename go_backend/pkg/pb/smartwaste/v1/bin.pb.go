@@ -0,0 +1,1089 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2-devel
+// 	protoc        (unknown)
+// source: smartwaste/v1/bin.proto
+
+package smartwastev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Bin is a smart waste bin with IoT sensors.
+type Bin struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id                  string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	DeviceId            string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	LocationName        *string                `protobuf:"bytes,3,opt,name=location_name,json=locationName,proto3,oneof" json:"location_name,omitempty"`
+	Latitude            float64                `protobuf:"fixed64,4,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude           float64                `protobuf:"fixed64,5,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	FillLevel           int32                  `protobuf:"varint,6,opt,name=fill_level,json=fillLevel,proto3" json:"fill_level,omitempty"`
+	WasteType           string                 `protobuf:"bytes,7,opt,name=waste_type,json=wasteType,proto3" json:"waste_type,omitempty"`
+	CapacityLiters      int32                  `protobuf:"varint,8,opt,name=capacity_liters,json=capacityLiters,proto3" json:"capacity_liters,omitempty"`
+	CompanyId           *string                `protobuf:"bytes,9,opt,name=company_id,json=companyId,proto3,oneof" json:"company_id,omitempty"`
+	IsActive            bool                   `protobuf:"varint,10,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	IsOffline           bool                   `protobuf:"varint,11,opt,name=is_offline,json=isOffline,proto3" json:"is_offline,omitempty"`
+	CollectionThreshold int32                  `protobuf:"varint,12,opt,name=collection_threshold,json=collectionThreshold,proto3" json:"collection_threshold,omitempty"`
+	AlertThreshold      int32                  `protobuf:"varint,13,opt,name=alert_threshold,json=alertThreshold,proto3" json:"alert_threshold,omitempty"`
+	BatteryLevel        *int32                 `protobuf:"varint,14,opt,name=battery_level,json=batteryLevel,proto3,oneof" json:"battery_level,omitempty"`
+	Rssi                *int32                 `protobuf:"varint,15,opt,name=rssi,proto3,oneof" json:"rssi,omitempty"`
+	TemperatureC        *float64               `protobuf:"fixed64,16,opt,name=temperature_c,json=temperatureC,proto3,oneof" json:"temperature_c,omitempty"`
+	FirmwareVersion     *string                `protobuf:"bytes,17,opt,name=firmware_version,json=firmwareVersion,proto3,oneof" json:"firmware_version,omitempty"`
+	LastUpdatedAt       *timestamppb.Timestamp `protobuf:"bytes,18,opt,name=last_updated_at,json=lastUpdatedAt,proto3" json:"last_updated_at,omitempty"`
+	LastCollectionAt    *timestamppb.Timestamp `protobuf:"bytes,19,opt,name=last_collection_at,json=lastCollectionAt,proto3" json:"last_collection_at,omitempty"`
+	PredictedFullAt     *timestamppb.Timestamp `protobuf:"bytes,20,opt,name=predicted_full_at,json=predictedFullAt,proto3" json:"predicted_full_at,omitempty"`
+	OfflineSince        *timestamppb.Timestamp `protobuf:"bytes,21,opt,name=offline_since,json=offlineSince,proto3" json:"offline_since,omitempty"`
+	CreatedAt           *timestamppb.Timestamp `protobuf:"bytes,22,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+}
+
+func (x *Bin) Reset() {
+	*x = Bin{}
+	mi := &file_smartwaste_v1_bin_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Bin) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Bin) ProtoMessage() {}
+
+func (x *Bin) ProtoReflect() protoreflect.Message {
+	mi := &file_smartwaste_v1_bin_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Bin.ProtoReflect.Descriptor instead.
+func (*Bin) Descriptor() ([]byte, []int) {
+	return file_smartwaste_v1_bin_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Bin) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Bin) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *Bin) GetLocationName() string {
+	if x != nil && x.LocationName != nil {
+		return *x.LocationName
+	}
+	return ""
+}
+
+func (x *Bin) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *Bin) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *Bin) GetFillLevel() int32 {
+	if x != nil {
+		return x.FillLevel
+	}
+	return 0
+}
+
+func (x *Bin) GetWasteType() string {
+	if x != nil {
+		return x.WasteType
+	}
+	return ""
+}
+
+func (x *Bin) GetCapacityLiters() int32 {
+	if x != nil {
+		return x.CapacityLiters
+	}
+	return 0
+}
+
+func (x *Bin) GetCompanyId() string {
+	if x != nil && x.CompanyId != nil {
+		return *x.CompanyId
+	}
+	return ""
+}
+
+func (x *Bin) GetIsActive() bool {
+	if x != nil {
+		return x.IsActive
+	}
+	return false
+}
+
+func (x *Bin) GetIsOffline() bool {
+	if x != nil {
+		return x.IsOffline
+	}
+	return false
+}
+
+func (x *Bin) GetCollectionThreshold() int32 {
+	if x != nil {
+		return x.CollectionThreshold
+	}
+	return 0
+}
+
+func (x *Bin) GetAlertThreshold() int32 {
+	if x != nil {
+		return x.AlertThreshold
+	}
+	return 0
+}
+
+func (x *Bin) GetBatteryLevel() int32 {
+	if x != nil && x.BatteryLevel != nil {
+		return *x.BatteryLevel
+	}
+	return 0
+}
+
+func (x *Bin) GetRssi() int32 {
+	if x != nil && x.Rssi != nil {
+		return *x.Rssi
+	}
+	return 0
+}
+
+func (x *Bin) GetTemperatureC() float64 {
+	if x != nil && x.TemperatureC != nil {
+		return *x.TemperatureC
+	}
+	return 0
+}
+
+func (x *Bin) GetFirmwareVersion() string {
+	if x != nil && x.FirmwareVersion != nil {
+		return *x.FirmwareVersion
+	}
+	return ""
+}
+
+func (x *Bin) GetLastUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastUpdatedAt
+	}
+	return nil
+}
+
+func (x *Bin) GetLastCollectionAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastCollectionAt
+	}
+	return nil
+}
+
+func (x *Bin) GetPredictedFullAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PredictedFullAt
+	}
+	return nil
+}
+
+func (x *Bin) GetOfflineSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OfflineSince
+	}
+	return nil
+}
+
+func (x *Bin) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type GetBinRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *GetBinRequest) Reset() {
+	*x = GetBinRequest{}
+	mi := &file_smartwaste_v1_bin_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBinRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBinRequest) ProtoMessage() {}
+
+func (x *GetBinRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_smartwaste_v1_bin_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBinRequest.ProtoReflect.Descriptor instead.
+func (*GetBinRequest) Descriptor() ([]byte, []int) {
+	return file_smartwaste_v1_bin_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetBinRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetBinResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Bin *Bin `protobuf:"bytes,1,opt,name=bin,proto3" json:"bin,omitempty"`
+}
+
+func (x *GetBinResponse) Reset() {
+	*x = GetBinResponse{}
+	mi := &file_smartwaste_v1_bin_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBinResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBinResponse) ProtoMessage() {}
+
+func (x *GetBinResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_smartwaste_v1_bin_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBinResponse.ProtoReflect.Descriptor instead.
+func (*GetBinResponse) Descriptor() ([]byte, []int) {
+	return file_smartwaste_v1_bin_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetBinResponse) GetBin() *Bin {
+	if x != nil {
+		return x.Bin
+	}
+	return nil
+}
+
+type ListBinsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// page_size defaults to 20 and is capped at 100.
+	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// page_token is the next_page_token of a previous response.
+	PageToken string `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+}
+
+func (x *ListBinsRequest) Reset() {
+	*x = ListBinsRequest{}
+	mi := &file_smartwaste_v1_bin_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBinsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBinsRequest) ProtoMessage() {}
+
+func (x *ListBinsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_smartwaste_v1_bin_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBinsRequest.ProtoReflect.Descriptor instead.
+func (*ListBinsRequest) Descriptor() ([]byte, []int) {
+	return file_smartwaste_v1_bin_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListBinsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListBinsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListBinsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Bins []*Bin `protobuf:"bytes,1,rep,name=bins,proto3" json:"bins,omitempty"`
+	// next_page_token is empty on the last page.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	Total         int32  `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
+}
+
+func (x *ListBinsResponse) Reset() {
+	*x = ListBinsResponse{}
+	mi := &file_smartwaste_v1_bin_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBinsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBinsResponse) ProtoMessage() {}
+
+func (x *ListBinsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_smartwaste_v1_bin_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBinsResponse.ProtoReflect.Descriptor instead.
+func (*ListBinsResponse) Descriptor() ([]byte, []int) {
+	return file_smartwaste_v1_bin_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListBinsResponse) GetBins() []*Bin {
+	if x != nil {
+		return x.Bins
+	}
+	return nil
+}
+
+func (x *ListBinsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *ListBinsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type ListBinsNeedingCollectionRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// threshold overrides each bin's own collection threshold.
+	Threshold *int32 `protobuf:"varint,1,opt,name=threshold,proto3,oneof" json:"threshold,omitempty"`
+}
+
+func (x *ListBinsNeedingCollectionRequest) Reset() {
+	*x = ListBinsNeedingCollectionRequest{}
+	mi := &file_smartwaste_v1_bin_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBinsNeedingCollectionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBinsNeedingCollectionRequest) ProtoMessage() {}
+
+func (x *ListBinsNeedingCollectionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_smartwaste_v1_bin_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBinsNeedingCollectionRequest.ProtoReflect.Descriptor instead.
+func (*ListBinsNeedingCollectionRequest) Descriptor() ([]byte, []int) {
+	return file_smartwaste_v1_bin_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListBinsNeedingCollectionRequest) GetThreshold() int32 {
+	if x != nil && x.Threshold != nil {
+		return *x.Threshold
+	}
+	return 0
+}
+
+type ListBinsNeedingCollectionResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Bins []*Bin `protobuf:"bytes,1,rep,name=bins,proto3" json:"bins,omitempty"`
+}
+
+func (x *ListBinsNeedingCollectionResponse) Reset() {
+	*x = ListBinsNeedingCollectionResponse{}
+	mi := &file_smartwaste_v1_bin_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBinsNeedingCollectionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBinsNeedingCollectionResponse) ProtoMessage() {}
+
+func (x *ListBinsNeedingCollectionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_smartwaste_v1_bin_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBinsNeedingCollectionResponse.ProtoReflect.Descriptor instead.
+func (*ListBinsNeedingCollectionResponse) Descriptor() ([]byte, []int) {
+	return file_smartwaste_v1_bin_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListBinsNeedingCollectionResponse) GetBins() []*Bin {
+	if x != nil {
+		return x.Bins
+	}
+	return nil
+}
+
+type GetBinStatisticsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetBinStatisticsRequest) Reset() {
+	*x = GetBinStatisticsRequest{}
+	mi := &file_smartwaste_v1_bin_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBinStatisticsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBinStatisticsRequest) ProtoMessage() {}
+
+func (x *GetBinStatisticsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_smartwaste_v1_bin_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBinStatisticsRequest.ProtoReflect.Descriptor instead.
+func (*GetBinStatisticsRequest) Descriptor() ([]byte, []int) {
+	return file_smartwaste_v1_bin_proto_rawDescGZIP(), []int{7}
+}
+
+type GetBinStatisticsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TotalBins        int32   `protobuf:"varint,1,opt,name=total_bins,json=totalBins,proto3" json:"total_bins,omitempty"`
+	NeedsCollection  int32   `protobuf:"varint,2,opt,name=needs_collection,json=needsCollection,proto3" json:"needs_collection,omitempty"`
+	NeedsAlert       int32   `protobuf:"varint,3,opt,name=needs_alert,json=needsAlert,proto3" json:"needs_alert,omitempty"`
+	AverageFillLevel float64 `protobuf:"fixed64,4,opt,name=average_fill_level,json=averageFillLevel,proto3" json:"average_fill_level,omitempty"`
+	Fill_0_25        int32   `protobuf:"varint,5,opt,name=fill_0_25,json=fill025,proto3" json:"fill_0_25,omitempty"`
+	Fill_26_50       int32   `protobuf:"varint,6,opt,name=fill_26_50,json=fill2650,proto3" json:"fill_26_50,omitempty"`
+	Fill_51_75       int32   `protobuf:"varint,7,opt,name=fill_51_75,json=fill5175,proto3" json:"fill_51_75,omitempty"`
+	Fill_76_100      int32   `protobuf:"varint,8,opt,name=fill_76_100,json=fill76100,proto3" json:"fill_76_100,omitempty"`
+}
+
+func (x *GetBinStatisticsResponse) Reset() {
+	*x = GetBinStatisticsResponse{}
+	mi := &file_smartwaste_v1_bin_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBinStatisticsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBinStatisticsResponse) ProtoMessage() {}
+
+func (x *GetBinStatisticsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_smartwaste_v1_bin_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBinStatisticsResponse.ProtoReflect.Descriptor instead.
+func (*GetBinStatisticsResponse) Descriptor() ([]byte, []int) {
+	return file_smartwaste_v1_bin_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetBinStatisticsResponse) GetTotalBins() int32 {
+	if x != nil {
+		return x.TotalBins
+	}
+	return 0
+}
+
+func (x *GetBinStatisticsResponse) GetNeedsCollection() int32 {
+	if x != nil {
+		return x.NeedsCollection
+	}
+	return 0
+}
+
+func (x *GetBinStatisticsResponse) GetNeedsAlert() int32 {
+	if x != nil {
+		return x.NeedsAlert
+	}
+	return 0
+}
+
+func (x *GetBinStatisticsResponse) GetAverageFillLevel() float64 {
+	if x != nil {
+		return x.AverageFillLevel
+	}
+	return 0
+}
+
+func (x *GetBinStatisticsResponse) GetFill_0_25() int32 {
+	if x != nil {
+		return x.Fill_0_25
+	}
+	return 0
+}
+
+func (x *GetBinStatisticsResponse) GetFill_26_50() int32 {
+	if x != nil {
+		return x.Fill_26_50
+	}
+	return 0
+}
+
+func (x *GetBinStatisticsResponse) GetFill_51_75() int32 {
+	if x != nil {
+		return x.Fill_51_75
+	}
+	return 0
+}
+
+func (x *GetBinStatisticsResponse) GetFill_76_100() int32 {
+	if x != nil {
+		return x.Fill_76_100
+	}
+	return 0
+}
+
+type WatchBinUpdatesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// company_id limits the stream to bins of one company.
+	CompanyId *string `protobuf:"bytes,1,opt,name=company_id,json=companyId,proto3,oneof" json:"company_id,omitempty"`
+}
+
+func (x *WatchBinUpdatesRequest) Reset() {
+	*x = WatchBinUpdatesRequest{}
+	mi := &file_smartwaste_v1_bin_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchBinUpdatesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchBinUpdatesRequest) ProtoMessage() {}
+
+func (x *WatchBinUpdatesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_smartwaste_v1_bin_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchBinUpdatesRequest.ProtoReflect.Descriptor instead.
+func (*WatchBinUpdatesRequest) Descriptor() ([]byte, []int) {
+	return file_smartwaste_v1_bin_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *WatchBinUpdatesRequest) GetCompanyId() string {
+	if x != nil && x.CompanyId != nil {
+		return *x.CompanyId
+	}
+	return ""
+}
+
+// WatchBinUpdatesResponse is one fill level change.
+type WatchBinUpdatesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BinId           string                 `protobuf:"bytes,1,opt,name=bin_id,json=binId,proto3" json:"bin_id,omitempty"`
+	DeviceId        string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	CompanyId       *string                `protobuf:"bytes,3,opt,name=company_id,json=companyId,proto3,oneof" json:"company_id,omitempty"`
+	FillLevel       int32                  `protobuf:"varint,4,opt,name=fill_level,json=fillLevel,proto3" json:"fill_level,omitempty"`
+	Latitude        float64                `protobuf:"fixed64,5,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude       float64                `protobuf:"fixed64,6,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	PredictedFullAt *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=predicted_full_at,json=predictedFullAt,proto3" json:"predicted_full_at,omitempty"`
+	Timestamp       *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+}
+
+func (x *WatchBinUpdatesResponse) Reset() {
+	*x = WatchBinUpdatesResponse{}
+	mi := &file_smartwaste_v1_bin_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchBinUpdatesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchBinUpdatesResponse) ProtoMessage() {}
+
+func (x *WatchBinUpdatesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_smartwaste_v1_bin_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchBinUpdatesResponse.ProtoReflect.Descriptor instead.
+func (*WatchBinUpdatesResponse) Descriptor() ([]byte, []int) {
+	return file_smartwaste_v1_bin_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *WatchBinUpdatesResponse) GetBinId() string {
+	if x != nil {
+		return x.BinId
+	}
+	return ""
+}
+
+func (x *WatchBinUpdatesResponse) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *WatchBinUpdatesResponse) GetCompanyId() string {
+	if x != nil && x.CompanyId != nil {
+		return *x.CompanyId
+	}
+	return ""
+}
+
+func (x *WatchBinUpdatesResponse) GetFillLevel() int32 {
+	if x != nil {
+		return x.FillLevel
+	}
+	return 0
+}
+
+func (x *WatchBinUpdatesResponse) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *WatchBinUpdatesResponse) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *WatchBinUpdatesResponse) GetPredictedFullAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PredictedFullAt
+	}
+	return nil
+}
+
+func (x *WatchBinUpdatesResponse) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+var File_smartwaste_v1_bin_proto protoreflect.FileDescriptor
+
+var file_smartwaste_v1_bin_proto_rawDesc = []byte{
+	0x0a, 0x17, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x77, 0x61, 0x73, 0x74, 0x65, 0x2f, 0x76, 0x31, 0x2f,
+	0x62, 0x69, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0d, 0x73, 0x6d, 0x61, 0x72, 0x74,
+	0x77, 0x61, 0x73, 0x74, 0x65, 0x2e, 0x76, 0x31, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x8b, 0x08, 0x0a, 0x03, 0x42, 0x69,
+	0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69,
+	0x64, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x49, 0x64, 0x12, 0x28,
+	0x0a, 0x0d, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x4e, 0x61, 0x6d, 0x65, 0x88, 0x01, 0x01, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x61, 0x74, 0x69,
+	0x74, 0x75, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x6c, 0x61, 0x74, 0x69,
+	0x74, 0x75, 0x64, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x6c, 0x6f, 0x6e, 0x67, 0x69, 0x74, 0x75, 0x64,
+	0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x6c, 0x6f, 0x6e, 0x67, 0x69, 0x74, 0x75,
+	0x64, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x66, 0x69, 0x6c, 0x6c, 0x5f, 0x6c, 0x65, 0x76, 0x65, 0x6c,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x66, 0x69, 0x6c, 0x6c, 0x4c, 0x65, 0x76, 0x65,
+	0x6c, 0x12, 0x1d, 0x0a, 0x0a, 0x77, 0x61, 0x73, 0x74, 0x65, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x77, 0x61, 0x73, 0x74, 0x65, 0x54, 0x79, 0x70, 0x65,
+	0x12, 0x27, 0x0a, 0x0f, 0x63, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79, 0x5f, 0x6c, 0x69, 0x74,
+	0x65, 0x72, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x63, 0x61, 0x70, 0x61, 0x63,
+	0x69, 0x74, 0x79, 0x4c, 0x69, 0x74, 0x65, 0x72, 0x73, 0x12, 0x22, 0x0a, 0x0a, 0x63, 0x6f, 0x6d,
+	0x70, 0x61, 0x6e, 0x79, 0x5f, 0x69, 0x64, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x48, 0x01, 0x52,
+	0x09, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x6e, 0x79, 0x49, 0x64, 0x88, 0x01, 0x01, 0x12, 0x1b, 0x0a,
+	0x09, 0x69, 0x73, 0x5f, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x08, 0x69, 0x73, 0x41, 0x63, 0x74, 0x69, 0x76, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x73,
+	0x5f, 0x6f, 0x66, 0x66, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09,
+	0x69, 0x73, 0x4f, 0x66, 0x66, 0x6c, 0x69, 0x6e, 0x65, 0x12, 0x31, 0x0a, 0x14, 0x63, 0x6f, 0x6c,
+	0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c,
+	0x64, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x05, 0x52, 0x13, 0x63, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x12, 0x27, 0x0a, 0x0f,
+	0x61, 0x6c, 0x65, 0x72, 0x74, 0x5f, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x18,
+	0x0d, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x54, 0x68, 0x72, 0x65,
+	0x73, 0x68, 0x6f, 0x6c, 0x64, 0x12, 0x28, 0x0a, 0x0d, 0x62, 0x61, 0x74, 0x74, 0x65, 0x72, 0x79,
+	0x5f, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x05, 0x48, 0x02, 0x52, 0x0c,
+	0x62, 0x61, 0x74, 0x74, 0x65, 0x72, 0x79, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x88, 0x01, 0x01, 0x12,
+	0x17, 0x0a, 0x04, 0x72, 0x73, 0x73, 0x69, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x05, 0x48, 0x03, 0x52,
+	0x04, 0x72, 0x73, 0x73, 0x69, 0x88, 0x01, 0x01, 0x12, 0x28, 0x0a, 0x0d, 0x74, 0x65, 0x6d, 0x70,
+	0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65, 0x5f, 0x63, 0x18, 0x10, 0x20, 0x01, 0x28, 0x01, 0x48,
+	0x04, 0x52, 0x0c, 0x74, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65, 0x43, 0x88,
+	0x01, 0x01, 0x12, 0x2e, 0x0a, 0x10, 0x66, 0x69, 0x72, 0x6d, 0x77, 0x61, 0x72, 0x65, 0x5f, 0x76,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x11, 0x20, 0x01, 0x28, 0x09, 0x48, 0x05, 0x52, 0x0f,
+	0x66, 0x69, 0x72, 0x6d, 0x77, 0x61, 0x72, 0x65, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x88,
+	0x01, 0x01, 0x12, 0x42, 0x0a, 0x0f, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x75, 0x70, 0x64, 0x61, 0x74,
+	0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x12, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0d, 0x6c, 0x61, 0x73, 0x74, 0x55, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x48, 0x0a, 0x12, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x63,
+	0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x61, 0x74, 0x18, 0x13, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x10,
+	0x6c, 0x61, 0x73, 0x74, 0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x41, 0x74,
+	0x12, 0x46, 0x0a, 0x11, 0x70, 0x72, 0x65, 0x64, 0x69, 0x63, 0x74, 0x65, 0x64, 0x5f, 0x66, 0x75,
+	0x6c, 0x6c, 0x5f, 0x61, 0x74, 0x18, 0x14, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0f, 0x70, 0x72, 0x65, 0x64, 0x69, 0x63, 0x74,
+	0x65, 0x64, 0x46, 0x75, 0x6c, 0x6c, 0x41, 0x74, 0x12, 0x3f, 0x0a, 0x0d, 0x6f, 0x66, 0x66, 0x6c,
+	0x69, 0x6e, 0x65, 0x5f, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x18, 0x15, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0c, 0x6f, 0x66, 0x66,
+	0x6c, 0x69, 0x6e, 0x65, 0x53, 0x69, 0x6e, 0x63, 0x65, 0x12, 0x39, 0x0a, 0x0a, 0x63, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x16, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x64, 0x41, 0x74, 0x42, 0x10, 0x0a, 0x0e, 0x5f, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x63, 0x6f, 0x6d, 0x70, 0x61,
+	0x6e, 0x79, 0x5f, 0x69, 0x64, 0x42, 0x10, 0x0a, 0x0e, 0x5f, 0x62, 0x61, 0x74, 0x74, 0x65, 0x72,
+	0x79, 0x5f, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x72, 0x73, 0x73, 0x69,
+	0x42, 0x10, 0x0a, 0x0e, 0x5f, 0x74, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65,
+	0x5f, 0x63, 0x42, 0x13, 0x0a, 0x11, 0x5f, 0x66, 0x69, 0x72, 0x6d, 0x77, 0x61, 0x72, 0x65, 0x5f,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x1f, 0x0a, 0x0d, 0x47, 0x65, 0x74, 0x42, 0x69,
+	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x36, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x42,
+	0x69, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x24, 0x0a, 0x03, 0x62, 0x69,
+	0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x77,
+	0x61, 0x73, 0x74, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x69, 0x6e, 0x52, 0x03, 0x62, 0x69, 0x6e,
+	0x22, 0x4d, 0x0a, 0x0f, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x69, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65,
+	0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22,
+	0x78, 0x0a, 0x10, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x69, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x26, 0x0a, 0x04, 0x62, 0x69, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x12, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x77, 0x61, 0x73, 0x74, 0x65, 0x2e, 0x76,
+	0x31, 0x2e, 0x42, 0x69, 0x6e, 0x52, 0x04, 0x62, 0x69, 0x6e, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x6e,
+	0x65, 0x78, 0x74, 0x5f, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65, 0x54, 0x6f,
+	0x6b, 0x65, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x22, 0x53, 0x0a, 0x20, 0x4c, 0x69, 0x73,
+	0x74, 0x42, 0x69, 0x6e, 0x73, 0x4e, 0x65, 0x65, 0x64, 0x69, 0x6e, 0x67, 0x43, 0x6f, 0x6c, 0x6c,
+	0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x21, 0x0a,
+	0x09, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05,
+	0x48, 0x00, 0x52, 0x09, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x88, 0x01, 0x01,
+	0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x22, 0x4b,
+	0x0a, 0x21, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x69, 0x6e, 0x73, 0x4e, 0x65, 0x65, 0x64, 0x69, 0x6e,
+	0x67, 0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x26, 0x0a, 0x04, 0x62, 0x69, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x12, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x77, 0x61, 0x73, 0x74, 0x65, 0x2e, 0x76,
+	0x31, 0x2e, 0x42, 0x69, 0x6e, 0x52, 0x04, 0x62, 0x69, 0x6e, 0x73, 0x22, 0x19, 0x0a, 0x17, 0x47,
+	0x65, 0x74, 0x42, 0x69, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x69, 0x73, 0x74, 0x69, 0x63, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0xab, 0x02, 0x0a, 0x18, 0x47, 0x65, 0x74, 0x42, 0x69,
+	0x6e, 0x53, 0x74, 0x61, 0x74, 0x69, 0x73, 0x74, 0x69, 0x63, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x62, 0x69, 0x6e,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x42, 0x69,
+	0x6e, 0x73, 0x12, 0x29, 0x0a, 0x10, 0x6e, 0x65, 0x65, 0x64, 0x73, 0x5f, 0x63, 0x6f, 0x6c, 0x6c,
+	0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0f, 0x6e, 0x65,
+	0x65, 0x64, 0x73, 0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1f, 0x0a,
+	0x0b, 0x6e, 0x65, 0x65, 0x64, 0x73, 0x5f, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x0a, 0x6e, 0x65, 0x65, 0x64, 0x73, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x12, 0x2c,
+	0x0a, 0x12, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x5f, 0x66, 0x69, 0x6c, 0x6c, 0x5f, 0x6c,
+	0x65, 0x76, 0x65, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x10, 0x61, 0x76, 0x65, 0x72,
+	0x61, 0x67, 0x65, 0x46, 0x69, 0x6c, 0x6c, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x1a, 0x0a, 0x09,
+	0x66, 0x69, 0x6c, 0x6c, 0x5f, 0x30, 0x5f, 0x32, 0x35, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x07, 0x66, 0x69, 0x6c, 0x6c, 0x30, 0x32, 0x35, 0x12, 0x1c, 0x0a, 0x0a, 0x66, 0x69, 0x6c, 0x6c,
+	0x5f, 0x32, 0x36, 0x5f, 0x35, 0x30, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x66, 0x69,
+	0x6c, 0x6c, 0x32, 0x36, 0x35, 0x30, 0x12, 0x1c, 0x0a, 0x0a, 0x66, 0x69, 0x6c, 0x6c, 0x5f, 0x35,
+	0x31, 0x5f, 0x37, 0x35, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x6c,
+	0x35, 0x31, 0x37, 0x35, 0x12, 0x1e, 0x0a, 0x0b, 0x66, 0x69, 0x6c, 0x6c, 0x5f, 0x37, 0x36, 0x5f,
+	0x31, 0x30, 0x30, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x66, 0x69, 0x6c, 0x6c, 0x37,
+	0x36, 0x31, 0x30, 0x30, 0x22, 0x4b, 0x0a, 0x16, 0x57, 0x61, 0x74, 0x63, 0x68, 0x42, 0x69, 0x6e,
+	0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x22,
+	0x0a, 0x0a, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x6e, 0x79, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x48, 0x00, 0x52, 0x09, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x6e, 0x79, 0x49, 0x64, 0x88,
+	0x01, 0x01, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x6e, 0x79, 0x5f, 0x69,
+	0x64, 0x22, 0xdb, 0x02, 0x0a, 0x17, 0x57, 0x61, 0x74, 0x63, 0x68, 0x42, 0x69, 0x6e, 0x55, 0x70,
+	0x64, 0x61, 0x74, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x15, 0x0a,
+	0x06, 0x62, 0x69, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x62,
+	0x69, 0x6e, 0x49, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x69,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x49,
+	0x64, 0x12, 0x22, 0x0a, 0x0a, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x6e, 0x79, 0x5f, 0x69, 0x64, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x09, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x6e, 0x79,
+	0x49, 0x64, 0x88, 0x01, 0x01, 0x12, 0x1d, 0x0a, 0x0a, 0x66, 0x69, 0x6c, 0x6c, 0x5f, 0x6c, 0x65,
+	0x76, 0x65, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x66, 0x69, 0x6c, 0x6c, 0x4c,
+	0x65, 0x76, 0x65, 0x6c, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x61, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x6c, 0x61, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65,
+	0x12, 0x1c, 0x0a, 0x09, 0x6c, 0x6f, 0x6e, 0x67, 0x69, 0x74, 0x75, 0x64, 0x65, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x09, 0x6c, 0x6f, 0x6e, 0x67, 0x69, 0x74, 0x75, 0x64, 0x65, 0x12, 0x46,
+	0x0a, 0x11, 0x70, 0x72, 0x65, 0x64, 0x69, 0x63, 0x74, 0x65, 0x64, 0x5f, 0x66, 0x75, 0x6c, 0x6c,
+	0x5f, 0x61, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0f, 0x70, 0x72, 0x65, 0x64, 0x69, 0x63, 0x74, 0x65, 0x64,
+	0x46, 0x75, 0x6c, 0x6c, 0x41, 0x74, 0x12, 0x38, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x6e, 0x79, 0x5f, 0x69, 0x64, 0x32,
+	0xe9, 0x03, 0x0a, 0x0a, 0x42, 0x69, 0x6e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x45,
+	0x0a, 0x06, 0x47, 0x65, 0x74, 0x42, 0x69, 0x6e, 0x12, 0x1c, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74,
+	0x77, 0x61, 0x73, 0x74, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x42, 0x69, 0x6e, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x77, 0x61,
+	0x73, 0x74, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x42, 0x69, 0x6e, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4b, 0x0a, 0x08, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x69, 0x6e,
+	0x73, 0x12, 0x1e, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x77, 0x61, 0x73, 0x74, 0x65, 0x2e, 0x76,
+	0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x69, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1f, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x77, 0x61, 0x73, 0x74, 0x65, 0x2e, 0x76,
+	0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x69, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x7e, 0x0a, 0x19, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x69, 0x6e, 0x73, 0x4e, 0x65,
+	0x65, 0x64, 0x69, 0x6e, 0x67, 0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x2f, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x77, 0x61, 0x73, 0x74, 0x65, 0x2e, 0x76, 0x31, 0x2e,
+	0x4c, 0x69, 0x73, 0x74, 0x42, 0x69, 0x6e, 0x73, 0x4e, 0x65, 0x65, 0x64, 0x69, 0x6e, 0x67, 0x43,
+	0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x30, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x77, 0x61, 0x73, 0x74, 0x65, 0x2e, 0x76, 0x31,
+	0x2e, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x69, 0x6e, 0x73, 0x4e, 0x65, 0x65, 0x64, 0x69, 0x6e, 0x67,
+	0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x63, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x42, 0x69, 0x6e, 0x53, 0x74, 0x61, 0x74,
+	0x69, 0x73, 0x74, 0x69, 0x63, 0x73, 0x12, 0x26, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x77, 0x61,
+	0x73, 0x74, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x42, 0x69, 0x6e, 0x53, 0x74, 0x61,
+	0x74, 0x69, 0x73, 0x74, 0x69, 0x63, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x27,
+	0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x77, 0x61, 0x73, 0x74, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x47,
+	0x65, 0x74, 0x42, 0x69, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x69, 0x73, 0x74, 0x69, 0x63, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x62, 0x0a, 0x0f, 0x57, 0x61, 0x74, 0x63, 0x68,
+	0x42, 0x69, 0x6e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73, 0x12, 0x25, 0x2e, 0x73, 0x6d, 0x61,
+	0x72, 0x74, 0x77, 0x61, 0x73, 0x74, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68,
+	0x42, 0x69, 0x6e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x26, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x77, 0x61, 0x73, 0x74, 0x65, 0x2e, 0x76,
+	0x31, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x42, 0x69, 0x6e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x42, 0x41, 0x5a, 0x3f, 0x67,
+	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x77,
+	0x61, 0x73, 0x74, 0x65, 0x2f, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2f, 0x70, 0x6b, 0x67,
+	0x2f, 0x70, 0x62, 0x2f, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x77, 0x61, 0x73, 0x74, 0x65, 0x2f, 0x76,
+	0x31, 0x3b, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x77, 0x61, 0x73, 0x74, 0x65, 0x76, 0x31, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_smartwaste_v1_bin_proto_rawDescOnce sync.Once
+	file_smartwaste_v1_bin_proto_rawDescData = file_smartwaste_v1_bin_proto_rawDesc
+)
+
+func file_smartwaste_v1_bin_proto_rawDescGZIP() []byte {
+	file_smartwaste_v1_bin_proto_rawDescOnce.Do(func() {
+		file_smartwaste_v1_bin_proto_rawDescData = protoimpl.X.CompressGZIP(file_smartwaste_v1_bin_proto_rawDescData)
+	})
+	return file_smartwaste_v1_bin_proto_rawDescData
+}
+
+var file_smartwaste_v1_bin_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_smartwaste_v1_bin_proto_goTypes = []any{
+	(*Bin)(nil),                               // 0: smartwaste.v1.Bin
+	(*GetBinRequest)(nil),                     // 1: smartwaste.v1.GetBinRequest
+	(*GetBinResponse)(nil),                    // 2: smartwaste.v1.GetBinResponse
+	(*ListBinsRequest)(nil),                   // 3: smartwaste.v1.ListBinsRequest
+	(*ListBinsResponse)(nil),                  // 4: smartwaste.v1.ListBinsResponse
+	(*ListBinsNeedingCollectionRequest)(nil),  // 5: smartwaste.v1.ListBinsNeedingCollectionRequest
+	(*ListBinsNeedingCollectionResponse)(nil), // 6: smartwaste.v1.ListBinsNeedingCollectionResponse
+	(*GetBinStatisticsRequest)(nil),           // 7: smartwaste.v1.GetBinStatisticsRequest
+	(*GetBinStatisticsResponse)(nil),          // 8: smartwaste.v1.GetBinStatisticsResponse
+	(*WatchBinUpdatesRequest)(nil),            // 9: smartwaste.v1.WatchBinUpdatesRequest
+	(*WatchBinUpdatesResponse)(nil),           // 10: smartwaste.v1.WatchBinUpdatesResponse
+	(*timestamppb.Timestamp)(nil),             // 11: google.protobuf.Timestamp
+}
+var file_smartwaste_v1_bin_proto_depIdxs = []int32{
+	11, // 0: smartwaste.v1.Bin.last_updated_at:type_name -> google.protobuf.Timestamp
+	11, // 1: smartwaste.v1.Bin.last_collection_at:type_name -> google.protobuf.Timestamp
+	11, // 2: smartwaste.v1.Bin.predicted_full_at:type_name -> google.protobuf.Timestamp
+	11, // 3: smartwaste.v1.Bin.offline_since:type_name -> google.protobuf.Timestamp
+	11, // 4: smartwaste.v1.Bin.created_at:type_name -> google.protobuf.Timestamp
+	0,  // 5: smartwaste.v1.GetBinResponse.bin:type_name -> smartwaste.v1.Bin
+	0,  // 6: smartwaste.v1.ListBinsResponse.bins:type_name -> smartwaste.v1.Bin
+	0,  // 7: smartwaste.v1.ListBinsNeedingCollectionResponse.bins:type_name -> smartwaste.v1.Bin
+	11, // 8: smartwaste.v1.WatchBinUpdatesResponse.predicted_full_at:type_name -> google.protobuf.Timestamp
+	11, // 9: smartwaste.v1.WatchBinUpdatesResponse.timestamp:type_name -> google.protobuf.Timestamp
+	1,  // 10: smartwaste.v1.BinService.GetBin:input_type -> smartwaste.v1.GetBinRequest
+	3,  // 11: smartwaste.v1.BinService.ListBins:input_type -> smartwaste.v1.ListBinsRequest
+	5,  // 12: smartwaste.v1.BinService.ListBinsNeedingCollection:input_type -> smartwaste.v1.ListBinsNeedingCollectionRequest
+	7,  // 13: smartwaste.v1.BinService.GetBinStatistics:input_type -> smartwaste.v1.GetBinStatisticsRequest
+	9,  // 14: smartwaste.v1.BinService.WatchBinUpdates:input_type -> smartwaste.v1.WatchBinUpdatesRequest
+	2,  // 15: smartwaste.v1.BinService.GetBin:output_type -> smartwaste.v1.GetBinResponse
+	4,  // 16: smartwaste.v1.BinService.ListBins:output_type -> smartwaste.v1.ListBinsResponse
+	6,  // 17: smartwaste.v1.BinService.ListBinsNeedingCollection:output_type -> smartwaste.v1.ListBinsNeedingCollectionResponse
+	8,  // 18: smartwaste.v1.BinService.GetBinStatistics:output_type -> smartwaste.v1.GetBinStatisticsResponse
+	10, // 19: smartwaste.v1.BinService.WatchBinUpdates:output_type -> smartwaste.v1.WatchBinUpdatesResponse
+	15, // [15:20] is the sub-list for method output_type
+	10, // [10:15] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_smartwaste_v1_bin_proto_init() }
+func file_smartwaste_v1_bin_proto_init() {
+	if File_smartwaste_v1_bin_proto != nil {
+		return
+	}
+	file_smartwaste_v1_bin_proto_msgTypes[0].OneofWrappers = []any{}
+	file_smartwaste_v1_bin_proto_msgTypes[5].OneofWrappers = []any{}
+	file_smartwaste_v1_bin_proto_msgTypes[9].OneofWrappers = []any{}
+	file_smartwaste_v1_bin_proto_msgTypes[10].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_smartwaste_v1_bin_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_smartwaste_v1_bin_proto_goTypes,
+		DependencyIndexes: file_smartwaste_v1_bin_proto_depIdxs,
+		MessageInfos:      file_smartwaste_v1_bin_proto_msgTypes,
+	}.Build()
+	File_smartwaste_v1_bin_proto = out.File
+	file_smartwaste_v1_bin_proto_rawDesc = nil
+	file_smartwaste_v1_bin_proto_goTypes = nil
+	file_smartwaste_v1_bin_proto_depIdxs = nil
+}
@@ -0,0 +1,291 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: smartwaste/v1/bin.proto
+
+package smartwastev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	BinService_GetBin_FullMethodName                    = "/smartwaste.v1.BinService/GetBin"
+	BinService_ListBins_FullMethodName                  = "/smartwaste.v1.BinService/ListBins"
+	BinService_ListBinsNeedingCollection_FullMethodName = "/smartwaste.v1.BinService/ListBinsNeedingCollection"
+	BinService_GetBinStatistics_FullMethodName          = "/smartwaste.v1.BinService/GetBinStatistics"
+	BinService_WatchBinUpdates_FullMethodName           = "/smartwaste.v1.BinService/WatchBinUpdates"
+)
+
+// BinServiceClient is the client API for BinService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// BinService mirrors the REST bin endpoints and streams live fill levels.
+type BinServiceClient interface {
+	// GetBin returns a bin by ID.
+	GetBin(ctx context.Context, in *GetBinRequest, opts ...grpc.CallOption) (*GetBinResponse, error)
+	// ListBins returns active bins, newest first.
+	ListBins(ctx context.Context, in *ListBinsRequest, opts ...grpc.CallOption) (*ListBinsResponse, error)
+	// ListBinsNeedingCollection returns bins at or above their collection threshold, fullest first.
+	ListBinsNeedingCollection(ctx context.Context, in *ListBinsNeedingCollectionRequest, opts ...grpc.CallOption) (*ListBinsNeedingCollectionResponse, error)
+	// GetBinStatistics returns fleet-wide fill level statistics.
+	GetBinStatistics(ctx context.Context, in *GetBinStatisticsRequest, opts ...grpc.CallOption) (*GetBinStatisticsResponse, error)
+	// WatchBinUpdates streams fill level changes as sensors report them.
+	WatchBinUpdates(ctx context.Context, in *WatchBinUpdatesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchBinUpdatesResponse], error)
+}
+
+type binServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBinServiceClient(cc grpc.ClientConnInterface) BinServiceClient {
+	return &binServiceClient{cc}
+}
+
+func (c *binServiceClient) GetBin(ctx context.Context, in *GetBinRequest, opts ...grpc.CallOption) (*GetBinResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetBinResponse)
+	err := c.cc.Invoke(ctx, BinService_GetBin_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *binServiceClient) ListBins(ctx context.Context, in *ListBinsRequest, opts ...grpc.CallOption) (*ListBinsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListBinsResponse)
+	err := c.cc.Invoke(ctx, BinService_ListBins_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *binServiceClient) ListBinsNeedingCollection(ctx context.Context, in *ListBinsNeedingCollectionRequest, opts ...grpc.CallOption) (*ListBinsNeedingCollectionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListBinsNeedingCollectionResponse)
+	err := c.cc.Invoke(ctx, BinService_ListBinsNeedingCollection_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *binServiceClient) GetBinStatistics(ctx context.Context, in *GetBinStatisticsRequest, opts ...grpc.CallOption) (*GetBinStatisticsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetBinStatisticsResponse)
+	err := c.cc.Invoke(ctx, BinService_GetBinStatistics_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *binServiceClient) WatchBinUpdates(ctx context.Context, in *WatchBinUpdatesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchBinUpdatesResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &BinService_ServiceDesc.Streams[0], BinService_WatchBinUpdates_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchBinUpdatesRequest, WatchBinUpdatesResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BinService_WatchBinUpdatesClient = grpc.ServerStreamingClient[WatchBinUpdatesResponse]
+
+// BinServiceServer is the server API for BinService service.
+// All implementations must embed UnimplementedBinServiceServer
+// for forward compatibility.
+//
+// BinService mirrors the REST bin endpoints and streams live fill levels.
+type BinServiceServer interface {
+	// GetBin returns a bin by ID.
+	GetBin(context.Context, *GetBinRequest) (*GetBinResponse, error)
+	// ListBins returns active bins, newest first.
+	ListBins(context.Context, *ListBinsRequest) (*ListBinsResponse, error)
+	// ListBinsNeedingCollection returns bins at or above their collection threshold, fullest first.
+	ListBinsNeedingCollection(context.Context, *ListBinsNeedingCollectionRequest) (*ListBinsNeedingCollectionResponse, error)
+	// GetBinStatistics returns fleet-wide fill level statistics.
+	GetBinStatistics(context.Context, *GetBinStatisticsRequest) (*GetBinStatisticsResponse, error)
+	// WatchBinUpdates streams fill level changes as sensors report them.
+	WatchBinUpdates(*WatchBinUpdatesRequest, grpc.ServerStreamingServer[WatchBinUpdatesResponse]) error
+	mustEmbedUnimplementedBinServiceServer()
+}
+
+// UnimplementedBinServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedBinServiceServer struct{}
+
+func (UnimplementedBinServiceServer) GetBin(context.Context, *GetBinRequest) (*GetBinResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBin not implemented")
+}
+func (UnimplementedBinServiceServer) ListBins(context.Context, *ListBinsRequest) (*ListBinsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBins not implemented")
+}
+func (UnimplementedBinServiceServer) ListBinsNeedingCollection(context.Context, *ListBinsNeedingCollectionRequest) (*ListBinsNeedingCollectionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBinsNeedingCollection not implemented")
+}
+func (UnimplementedBinServiceServer) GetBinStatistics(context.Context, *GetBinStatisticsRequest) (*GetBinStatisticsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBinStatistics not implemented")
+}
+func (UnimplementedBinServiceServer) WatchBinUpdates(*WatchBinUpdatesRequest, grpc.ServerStreamingServer[WatchBinUpdatesResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchBinUpdates not implemented")
+}
+func (UnimplementedBinServiceServer) mustEmbedUnimplementedBinServiceServer() {}
+func (UnimplementedBinServiceServer) testEmbeddedByValue()                    {}
+
+// UnsafeBinServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BinServiceServer will
+// result in compilation errors.
+type UnsafeBinServiceServer interface {
+	mustEmbedUnimplementedBinServiceServer()
+}
+
+func RegisterBinServiceServer(s grpc.ServiceRegistrar, srv BinServiceServer) {
+	// If the following call pancis, it indicates UnimplementedBinServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&BinService_ServiceDesc, srv)
+}
+
+func _BinService_GetBin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBinRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BinServiceServer).GetBin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BinService_GetBin_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BinServiceServer).GetBin(ctx, req.(*GetBinRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BinService_ListBins_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBinsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BinServiceServer).ListBins(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BinService_ListBins_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BinServiceServer).ListBins(ctx, req.(*ListBinsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BinService_ListBinsNeedingCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBinsNeedingCollectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BinServiceServer).ListBinsNeedingCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BinService_ListBinsNeedingCollection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BinServiceServer).ListBinsNeedingCollection(ctx, req.(*ListBinsNeedingCollectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BinService_GetBinStatistics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBinStatisticsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BinServiceServer).GetBinStatistics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BinService_GetBinStatistics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BinServiceServer).GetBinStatistics(ctx, req.(*GetBinStatisticsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BinService_WatchBinUpdates_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchBinUpdatesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BinServiceServer).WatchBinUpdates(m, &grpc.GenericServerStream[WatchBinUpdatesRequest, WatchBinUpdatesResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BinService_WatchBinUpdatesServer = grpc.ServerStreamingServer[WatchBinUpdatesResponse]
+
+// BinService_ServiceDesc is the grpc.ServiceDesc for BinService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BinService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "smartwaste.v1.BinService",
+	HandlerType: (*BinServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetBin",
+			Handler:    _BinService_GetBin_Handler,
+		},
+		{
+			MethodName: "ListBins",
+			Handler:    _BinService_ListBins_Handler,
+		},
+		{
+			MethodName: "ListBinsNeedingCollection",
+			Handler:    _BinService_ListBinsNeedingCollection_Handler,
+		},
+		{
+			MethodName: "GetBinStatistics",
+			Handler:    _BinService_GetBinStatistics_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchBinUpdates",
+			Handler:       _BinService_WatchBinUpdates_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "smartwaste/v1/bin.proto",
+}
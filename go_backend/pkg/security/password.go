@@ -0,0 +1,52 @@
+package security
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrPasswordMismatch is returned when a password does not match its hash
+var ErrPasswordMismatch = errors.New("password does not match")
+
+// PasswordHasher hashes and verifies account passwords
+type PasswordHasher interface {
+	HashPassword(password string) (string, error)
+	VerifyPassword(hash, password string) error
+}
+
+// BcryptHasher implements PasswordHasher using bcrypt
+type BcryptHasher struct {
+	cost int
+}
+
+// NewBcryptHasher creates a new BcryptHasher with the given cost
+// A cost outside bcrypt's allowed range falls back to bcrypt.DefaultCost
+func NewBcryptHasher(cost int) *BcryptHasher {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		cost = bcrypt.DefaultCost
+	}
+	return &BcryptHasher{cost: cost}
+}
+
+// HashPassword returns the bcrypt hash of a plaintext password
+func (h *BcryptHasher) HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// VerifyPassword checks a plaintext password against a stored bcrypt hash
+func (h *BcryptHasher) VerifyPassword(hash, password string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrPasswordMismatch
+	}
+	if err != nil {
+		return fmt.Errorf("failed to verify password: %w", err)
+	}
+	return nil
+}
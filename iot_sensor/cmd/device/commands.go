@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// Command types sent by the backend on bins/{bin_id}/cmd
+const (
+	CommandSetInterval = "set_interval"
+	CommandReadNow     = "read_now"
+	CommandReboot      = "reboot"
+)
+
+// Command represents an instruction received from the backend
+type Command struct {
+	ID              string `json:"id"`
+	Type            string `json:"type"`
+	IntervalSeconds *int   `json:"interval_seconds,omitempty"`
+}
+
+// Commands carries decoded commands from the MQTT callback to the main loop
+type Commands struct {
+	Interval chan time.Duration
+	ReadNow  chan struct{}
+	Reboot   chan struct{}
+}
+
+// NewCommands creates the command channels
+func NewCommands() *Commands {
+	return &Commands{
+		Interval: make(chan time.Duration, 1),
+		ReadNow:  make(chan struct{}, 1),
+		Reboot:   make(chan struct{}, 1),
+	}
+}
+
+// Handle decodes a command message and forwards it to the main loop
+func (c *Commands) Handle(_ mqtt.Client, msg mqtt.Message) {
+	var cmd Command
+	if err := json.Unmarshal(msg.Payload(), &cmd); err != nil {
+		log.Printf("Ignoring malformed command on %s: %v", msg.Topic(), err)
+		return
+	}
+	log.Printf("Received command %s (%s)", cmd.Type, cmd.ID)
+
+	switch cmd.Type {
+	case CommandSetInterval:
+		if cmd.IntervalSeconds == nil || *cmd.IntervalSeconds <= 0 {
+			log.Printf("Ignoring set_interval command %s without a positive interval", cmd.ID)
+			return
+		}
+		replace(c.Interval, time.Duration(*cmd.IntervalSeconds)*time.Second)
+	case CommandReadNow:
+		replace(c.ReadNow, struct{}{})
+	case CommandReboot:
+		replace(c.Reboot, struct{}{})
+	default:
+		log.Printf("Ignoring unknown command type %q", cmd.Type)
+	}
+}
+
+// replace puts value on a single-slot channel, discarding any pending value
+// so the latest command wins
+func replace[T any](ch chan T, value T) {
+	select {
+	case <-ch:
+	default:
+	}
+	select {
+	case ch <- value:
+	default:
+	}
+}
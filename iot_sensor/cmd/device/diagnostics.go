@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/smartwaste/iot-sensor/pkg/sensor"
+)
+
+// DiagnosticReading is the last reading taken, whether or not it was published
+type DiagnosticReading struct {
+	FillLevel    int       `json:"fill_level"`
+	DistanceCm   float64   `json:"distance_cm"`
+	WeightKg     *float64  `json:"weight_kg,omitempty"`
+	LidOpen      *bool     `json:"lid_open,omitempty"`
+	TemperatureC *float64  `json:"temperature,omitempty"`
+	At           time.Time `json:"at"`
+}
+
+// DiagnosticReport is the state of the device served on /diagnostics
+type DiagnosticReport struct {
+	BinID           string             `json:"bin_id"`
+	FirmwareVersion string             `json:"firmware_version"`
+	StartedAt       time.Time          `json:"started_at"`
+	UptimeSeconds   int64              `json:"uptime_seconds"`
+	Broker          string             `json:"broker"`
+	BrokerConnected bool               `json:"broker_connected"`
+	ReadInterval    string             `json:"read_interval"`
+	LastReading     *DiagnosticReading `json:"last_reading,omitempty"`
+	LastPublish     *PublishResult     `json:"last_publish,omitempty"`
+	Buffered        int                `json:"buffered"`
+}
+
+// Diagnostics serves the state of the device over HTTP on the local network,
+// so a technician can check a bin from a phone without the backend
+type Diagnostics struct {
+	binID     string
+	broker    string
+	started   time.Time
+	client    mqtt.Client
+	publisher *Publisher
+
+	mu       sync.Mutex
+	reading  *DiagnosticReading
+	interval time.Duration
+}
+
+// NewDiagnostics creates the diagnostics of a device
+func NewDiagnostics(binID, broker string, client mqtt.Client, publisher *Publisher) *Diagnostics {
+	return &Diagnostics{
+		binID:     binID,
+		broker:    broker,
+		started:   time.Now(),
+		client:    client,
+		publisher: publisher,
+	}
+}
+
+// RecordReading keeps the last reading and the interval to the next one
+func (d *Diagnostics) RecordReading(reading sensor.Reading, fillLevel int, at time.Time, interval time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.reading = &DiagnosticReading{
+		FillLevel:    fillLevel,
+		DistanceCm:   reading.DistanceCm,
+		WeightKg:     reading.WeightKg,
+		LidOpen:      reading.LidOpen,
+		TemperatureC: reading.TemperatureC,
+		At:           at,
+	}
+	d.interval = interval
+}
+
+// Report returns the current state of the device
+func (d *Diagnostics) Report() DiagnosticReport {
+	d.mu.Lock()
+	reading, interval := d.reading, d.interval
+	d.mu.Unlock()
+
+	return DiagnosticReport{
+		BinID:           d.binID,
+		FirmwareVersion: version,
+		StartedAt:       d.started,
+		UptimeSeconds:   int64(time.Since(d.started).Seconds()),
+		Broker:          d.broker,
+		BrokerConnected: d.client.IsConnectionOpen(),
+		ReadInterval:    interval.String(),
+		LastReading:     reading,
+		LastPublish:     d.publisher.LastResult(),
+		Buffered:        d.publisher.Pending(),
+	}
+}
+
+// ServeHTTP serves the report as JSON
+func (d *Diagnostics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(d.Report()); err != nil {
+		log.Printf("Failed to write diagnostics: %v", err)
+	}
+}
+
+// Serve listens on addr until ctx is cancelled. A failure to listen is
+// logged without stopping the device.
+func (d *Diagnostics) Serve(ctx context.Context, addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/diagnostics", d)
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("Serving diagnostics on http://%s/diagnostics", addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("Diagnostics server stopped: %v", err)
+	}
+}
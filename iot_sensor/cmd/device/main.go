@@ -1,35 +1,107 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/smartwaste/iot-sensor/pkg/buffer"
 	"github.com/smartwaste/iot-sensor/pkg/config"
 	"github.com/smartwaste/iot-sensor/pkg/sensor"
 )
 
+// version is the firmware version, set at build time with
+// -ldflags "-X main.version=1.4.2"
+var version = "dev"
+
+// schemaVersion is the version of the bin status payload the device sends
+const schemaVersion = 2
+
 // Payload represents the data sent to the backend
 type Payload struct {
-	BinID     string `json:"bin_id"`
-	FillLevel int    `json:"fill_level"`
-	Battery   int    `json:"battery_level,omitempty"`
-	Timestamp int64  `json:"timestamp"`
+	SchemaVersion int    `json:"schema_version"`
+	BinID         string `json:"bin_id"`
+	FillLevel     int    `json:"fill_level"`
+	Timestamp     int64  `json:"timestamp"`
+	MessageID     string `json:"message_id"`
+
+	Sensors PayloadSensors `json:"sensors"`
+	Health  PayloadHealth  `json:"health"`
+}
+
+// PayloadSensors holds the optional sensors, omitted when the bin has none
+type PayloadSensors struct {
+	WeightKg    *float64 `json:"weight_kg,omitempty"`
+	LidOpen     *bool    `json:"lid_open,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+}
+
+// PayloadHealth holds the health of the device
+type PayloadHealth struct {
+	Battery         int    `json:"battery_level,omitempty"`
+	FirmwareVersion string `json:"firmware_version"`
+}
+
+// newBootID returns a random identifier for this run so message IDs stay
+// unique when the sequence counter restarts after a reboot
+func newBootID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%08x", time.Now().UnixNano()&0xffffffff)
+	}
+	return hex.EncodeToString(b)
 }
 
 func main() {
+	// Stop cleanly on SIGINT or SIGTERM, e.g. from the container runtime
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// 1. Load Config
 	cfg := config.LoadConfig()
-	log.Printf("Starting IoT Sensor Service for Bin: %s", cfg.BinID)
+	if cfg.Provisioning.URL != "" {
+		// The bin ID and broker credentials are issued by the backend
+		if err := provision(ctx, &cfg); errors.Is(err, context.Canceled) {
+			log.Println("Stopped before the device was provisioned")
+			return
+		} else if err != nil {
+			log.Fatalf("Failed to provision the device: %v", err)
+		}
+	}
+	log.Printf("Starting IoT Sensor Service %s for Bin: %s", version, cfg.BinID)
+	if cfg.QoS > 2 {
+		log.Printf("Warning: invalid MQTT_QOS %d, using 1", cfg.QoS)
+		cfg.QoS = 1
+	}
+	// The configuration pushed by the backend in a previous run
+	remote := LoadRemoteConfigs(cfg)
+	cfg = remote.Current()
 
-	// 2. Setup Sensor
-	var s sensor.Sensor
+	// 2. Setup Sensors
+	suite := &sensor.Suite{}
 
 	if cfg.Simulation {
 		log.Println("Mode: Simulation")
-		s = sensor.NewSimulator(cfg.BinHeightCm)
+		sim := sensor.NewSimulator(cfg.BinHeightCm)
+		suite.Distance = sim
+		if cfg.Sensors.Weight {
+			suite.Weight = sensor.NewWeightSimulator(sim, cfg.Sensors.MaxWeightKg)
+		}
+		if cfg.Sensors.Lid {
+			suite.Lid = sensor.NewLidSimulator()
+		}
+		if cfg.Sensors.Temperature {
+			suite.Temperature = sensor.NewTemperatureSimulator(20)
+		}
 	} else {
 		// Hardware initialization would go here (requires build tags for TinyGo/Hardware)
 		// For now we default to simulator if not strictly configured for hardware
@@ -39,35 +111,185 @@ func main() {
 		// trig := machine.GPIO23
 		// echo := machine.GPIO24
 		// s = sensor.NewHCSR04(trig, echo)
-		s = sensor.NewSimulator(cfg.BinHeightCm)
+		sim := sensor.NewSimulator(cfg.BinHeightCm)
+
+		// Single ultrasonic pings are noisy; the simulator is not, so it is
+		// left unfiltered
+		filtered, err := sensor.NewFilteredSensor(sim, sensor.FilterConfig{
+			Samples:        cfg.Filter.Samples,
+			SampleDelay:    cfg.Filter.SampleDelay,
+			MaxDeviation:   cfg.Filter.MaxDeviation,
+			Alpha:          cfg.Filter.Alpha,
+			ResetDeviation: cfg.Filter.ResetDeviation,
+		})
+		if err != nil {
+			log.Fatalf("Invalid sensor filter configuration: %v", err)
+		}
+		suite.Distance = filtered
+
+		if cfg.Sensors.Weight {
+			// suite.Weight = sensor.NewHX711(machine.GPIO5, machine.GPIO6, int32(cfg.Sensors.LoadCellOffset), cfg.Sensors.LoadCellScale)
+			suite.Weight = sensor.NewWeightSimulator(sim, cfg.Sensors.MaxWeightKg)
+		}
+		if cfg.Sensors.Lid {
+			// suite.Lid = sensor.NewReedSwitch(machine.GPIO17)
+			suite.Lid = sensor.NewLidSimulator()
+		}
+		if cfg.Sensors.Temperature {
+			if cfg.Sensors.TemperatureProbePath == "" {
+				log.Println("Warning: TEMPERATURE_SENSOR is set without TEMPERATURE_PROBE_PATH, skipping the probe")
+			} else {
+				suite.Temperature = sensor.NewDS18B20(cfg.Sensors.TemperatureProbePath)
+			}
+		}
 	}
-	defer s.Close()
 
-	// 3. Setup MQTT
+	// 3. Setup the buffer of readings waiting for the broker
+	buf, err := buffer.Open(cfg.BufferPath, cfg.BufferSize)
+	if err != nil {
+		log.Printf("Warning: keeping unsent readings in memory only: %v", err)
+		if buf, err = buffer.Open("", cfg.BufferSize); err != nil {
+			log.Fatalf("Invalid buffer configuration: %v", err)
+		}
+	} else if n := buf.Len(); n > 0 {
+		log.Printf("%d readings of a previous run waiting to be sent", n)
+	}
+
+	// 4. Setup MQTT; the device keeps reading while the broker is unreachable
 	opts := mqtt.NewClientOptions()
 	opts.AddBroker(cfg.MQTTBroker)
 	opts.SetClientID("iot-sensor-" + cfg.BinID)
 	opts.SetKeepAlive(60 * time.Second)
 	opts.SetPingTimeout(1 * time.Second)
+	opts.SetAutoReconnect(true)
+	opts.SetConnectRetry(true)
+	opts.SetConnectRetryInterval(5 * time.Second)
+	opts.SetMaxReconnectInterval(cfg.ReconnectMaxInterval)
+	// Used with an ssl://, tls:// or mqtts:// broker URL
+	tlsConfig, err := newTLSConfig(cfg.TLS)
+	if err != nil {
+		log.Fatalf("Invalid MQTT TLS configuration: %v", err)
+	}
+	if tlsConfig != nil {
+		opts.SetTLSConfig(tlsConfig)
+	}
+	// Asked on every connection, so pushed credentials apply from the next one
+	opts.SetCredentialsProvider(remote.Credentials)
+
+	// The broker announces the device offline if the connection drops
+	availabilityTopic := fmt.Sprintf("bins/%s/availability", cfg.BinID)
+	opts.SetWill(availabilityTopic, availabilityOffline, cfg.QoS, true)
+
+	var publisher *Publisher
+
+	// Subscribe to backend commands and replay the buffer on every (re)connect
+	commands := NewCommands()
+	cmdTopic := fmt.Sprintf("bins/%s/cmd", cfg.BinID)
+	configTopic := fmt.Sprintf("bins/%s/config", cfg.BinID)
+	// Firmware updates restart the device like the reboot command
+	updater := NewUpdater(cfg, commands.Reboot)
+	otaTopic := fmt.Sprintf("bins/%s/ota", cfg.BinID)
+	opts.SetOnConnectHandler(func(c mqtt.Client) {
+		log.Printf("Connected to MQTT Broker: %s", cfg.MQTTBroker)
+		publishAvailability(c, availabilityTopic, availabilityOnline, cfg)
+		if token := c.Subscribe(cmdTopic, 1, commands.Handle); token.Wait() && token.Error() != nil {
+			log.Printf("Failed to subscribe to %s: %v", cmdTopic, token.Error())
+		} else {
+			log.Printf("Subscribed to commands on %s", cmdTopic)
+		}
+		if token := c.Subscribe(configTopic, 1, remote.Handle); token.Wait() && token.Error() != nil {
+			log.Printf("Failed to subscribe to %s: %v", configTopic, token.Error())
+		}
+		updater.ReportInstalled(c)
+		if token := c.Subscribe(otaTopic, 1, updater.Handle); token.Wait() && token.Error() != nil {
+			log.Printf("Failed to subscribe to %s: %v", otaTopic, token.Error())
+		}
+		publisher.Flush()
+	})
+	opts.SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+		log.Printf("Connection to MQTT broker lost, buffering readings: %v", err)
+	})
+	opts.SetReconnectingHandler(func(_ mqtt.Client, _ *mqtt.ClientOptions) {
+		log.Println("Reconnecting to MQTT broker")
+	})
 
 	client := mqtt.NewClient(opts)
-	if token := client.Connect(); token.Wait() && token.Error() != nil {
-		log.Fatalf("Failed to connect to MQTT: %v", token.Error())
+	publisher = NewPublisher(client, buf, cfg.QoS, cfg.RetainStatus, cfg.PublishTimeout)
+	// Retried in the background until the broker answers
+	client.Connect()
+	log.Printf("Connecting to MQTT Broker: %s", cfg.MQTTBroker)
+
+	diagnostics := NewDiagnostics(cfg.BinID, cfg.MQTTBroker, client, publisher)
+	if cfg.DiagnosticsAddr != "" {
+		go diagnostics.Serve(ctx, cfg.DiagnosticsAddr)
 	}
-	log.Printf("Connected to MQTT Broker: %s", cfg.MQTTBroker)
 
-	// 4. Main Loop
+	run(ctx, cfg, suite, publisher, commands, remote.Updates, diagnostics)
+
+	// A clean disconnect does not trigger the last will, so announce it.
+	// Unsent readings stay in the buffer for the next run.
+	if client.IsConnectionOpen() {
+		publishAvailability(client, availabilityTopic, availabilityOffline, cfg)
+	}
+	client.Disconnect(250)
+	if err := suite.Close(); err != nil {
+		log.Printf("Failed to release sensors: %v", err)
+	}
+	if n := buf.Len(); n > 0 {
+		log.Printf("Stopped with %d readings waiting to be sent", n)
+	} else {
+		log.Println("Stopped")
+	}
+}
+
+// Availability of the device, retained on bins/{bin_id}/availability
+const (
+	availabilityOnline  = "online"
+	availabilityOffline = "offline"
+)
+
+// publishAvailability announces whether the device is online
+func publishAvailability(client mqtt.Client, topic, state string, cfg config.Config) {
+	token := client.Publish(topic, cfg.QoS, true, state)
+	if !token.WaitTimeout(cfg.PublishTimeout) {
+		log.Printf("Failed to announce %s on %s: no acknowledgement", state, topic)
+	} else if err := token.Error(); err != nil {
+		log.Printf("Failed to announce %s on %s: %v", state, topic, err)
+	}
+}
+
+// run reads the sensor and publishes the fill level until ctx is cancelled
+// or the backend asks for a reboot, switching to the configurations pushed by
+// the backend on configs. Each reading is recorded in diagnostics.
+func run(ctx context.Context, cfg config.Config, sensors *sensor.Suite, publisher *Publisher, commands *Commands, configs <-chan config.Config, diagnostics *Diagnostics) {
 	topic := fmt.Sprintf("bins/%s/status", cfg.BinID)
+	bootID := newBootID()
+	var seq uint64
+	policy := NewReportPolicy(cfg.Reporting, cfg.ReadInterval)
+	var interval time.Duration
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	<-timer.C
+	// force publishes the next reading whatever the policy says
+	force := false
+	// lidOpen is the lid state last published, so a change is published at once
+	var lidOpen *bool
 
 	for {
 		// Read Sensor
-		distanceCm, err := s.ReadDistance()
+		reading, err := sensors.Read()
 		if err != nil {
 			log.Printf("Error reading sensor: %v", err)
-			time.Sleep(1 * time.Second)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(1 * time.Second):
+			}
 			continue
 		}
 
+		distanceCm := reading.DistanceCm
+
 		// Calculate Fill Level
 		// Distance = Gap from top to waste.
 		// Fill Height = BinHeight - Distance
@@ -84,21 +306,74 @@ func main() {
 			fillLevel = 0
 		}
 
-		// Create Payload
-		payload := Payload{
-			BinID:     cfg.BinID,
-			FillLevel: fillLevel,
-			Timestamp: time.Now().Unix(),
+		log.Printf("Read %d%% full (Distance: %.1fcm)", fillLevel, distanceCm)
+		now := time.Now()
+		lidChanged := reading.LidOpen != nil && (lidOpen == nil || *reading.LidOpen != *lidOpen)
+		hazard := reading.TemperatureC != nil && *reading.TemperatureC >= cfg.Sensors.HazardTemperatureC
+		if hazard {
+			log.Printf("Bin at %.1f°C, at or above the hazard temperature of %.1f°C", *reading.TemperatureC, cfg.Sensors.HazardTemperatureC)
 		}
+		if force || lidChanged || hazard || policy.ShouldReport(fillLevel, now) {
+			// Create Payload; retransmissions of the same reading keep its message ID
+			seq++
+			payload := Payload{
+				SchemaVersion: schemaVersion,
+				BinID:         cfg.BinID,
+				FillLevel:     fillLevel,
+				Timestamp:     now.Unix(),
+				MessageID:     fmt.Sprintf("%s-%d", bootID, seq),
 
-		data, _ := json.Marshal(payload)
+				Sensors: PayloadSensors{
+					WeightKg:    reading.WeightKg,
+					LidOpen:     reading.LidOpen,
+					Temperature: reading.TemperatureC,
+				},
+				Health: PayloadHealth{FirmwareVersion: version},
+			}
 
-		// Publish
-		token := client.Publish(topic, 0, false, data)
-		token.Wait()
+			data, _ := json.Marshal(payload)
 
-		log.Printf("Published to %s: %s (Distance: %.1fcm)", topic, string(data), distanceCm)
+			// Publish at least once; the backend drops duplicates by message ID
+			publisher.Publish(topic, data)
+			policy.Reported(fillLevel, now)
+			if reading.LidOpen != nil {
+				lidOpen = reading.LidOpen
+			}
+			force = false
+		}
+
+		if next := policy.Interval(fillLevel); next != interval {
+			interval = next
+			log.Printf("Reading every %s at %d%% full", interval, fillLevel)
+		}
+		diagnostics.RecordReading(reading, fillLevel, now, interval)
+		timer.Reset(interval)
 
-		time.Sleep(cfg.ReadInterval)
+		// Wait for the next reading or a command from the backend
+		select {
+		case <-ctx.Done():
+			log.Println("Shutting down")
+			return
+		case <-timer.C:
+			continue
+		case <-commands.ReadNow:
+			log.Println("Immediate reading requested")
+			force = true
+		case base := <-commands.Interval:
+			log.Printf("Report interval changed to %s", base)
+			policy.SetBase(base)
+		case next := <-configs:
+			log.Println("Switching to the pushed configuration")
+			cfg = next
+			policy.SetConfig(cfg.Reporting, cfg.ReadInterval)
+		case <-commands.Reboot:
+			// The supervisor (container restart policy / systemd) brings the device back up
+			log.Println("Reboot requested, shutting down")
+			return
+		}
+		// Woken up by a command; drain the timer before it is reset
+		if !timer.Stop() {
+			<-timer.C
+		}
 	}
 }
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/smartwaste/iot-sensor/pkg/config"
+)
+
+// Progress of a firmware update, reported on bins/{bin_id}/ota/status
+const (
+	otaDownloading = "downloading"
+	otaInstalled   = "installed" // Swapped in; the device restarts into it
+	otaUpdated     = "updated"   // Running after the restart
+	otaFailed      = "failed"
+)
+
+// OTAUpdate is a firmware update offered by the backend, retained on
+// bins/{bin_id}/ota until the device reports it done
+type OTAUpdate struct {
+	RolloutID string `json:"rollout_id"`
+	Version   string `json:"version"`
+	URL       string `json:"url"`
+	// SHA256 is the hex digest of the binary and Signature the base64
+	// Ed25519 signature of that digest
+	SHA256    string `json:"sha256"`
+	Signature string `json:"signature"`
+}
+
+// OTAStatus reports the progress of an update to the backend
+type OTAStatus struct {
+	RolloutID string `json:"rollout_id"`
+	Version   string `json:"version"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// otaState records an installed update across the restart into it
+type otaState struct {
+	RolloutID string `json:"rollout_id"`
+	Version   string `json:"version"`
+}
+
+// Updater installs the firmware updates offered by the backend: it downloads
+// the binary, checks its digest and signature, swaps it in for the running
+// executable and asks the main loop to restart into it.
+type Updater struct {
+	cfg         config.OTAConfig
+	publicKey   ed25519.PublicKey
+	statusTopic string
+	qos         byte
+	timeout     time.Duration
+	restart     chan struct{}
+
+	mu         sync.Mutex
+	installing bool
+	// installed is the update the previous run installed, reported once
+	// connected
+	installed *otaState
+}
+
+// NewUpdater creates an Updater restarting the device through restart. An
+// invalid OTA_PUBLIC_KEY disables updates rather than stopping the device.
+func NewUpdater(cfg config.Config, restart chan struct{}) *Updater {
+	u := &Updater{
+		cfg:         cfg.OTA,
+		statusTopic: fmt.Sprintf("bins/%s/ota/status", cfg.BinID),
+		qos:         cfg.QoS,
+		timeout:     cfg.PublishTimeout,
+		restart:     restart,
+	}
+	if cfg.OTA.PublicKey != "" {
+		key, err := base64.StdEncoding.DecodeString(cfg.OTA.PublicKey)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			log.Println("Warning: OTA_PUBLIC_KEY is not a base64 Ed25519 public key, firmware updates are refused")
+		} else {
+			u.publicKey = key
+		}
+	}
+
+	if u.cfg.StatePath != "" {
+		data, err := os.ReadFile(u.cfg.StatePath)
+		if err == nil {
+			var state otaState
+			if err := json.Unmarshal(data, &state); err != nil {
+				log.Printf("Warning: ignoring the corrupt firmware update state: %v", err)
+			} else {
+				u.installed = &state
+			}
+		} else if !os.IsNotExist(err) {
+			log.Printf("Warning: ignoring the firmware update state: %v", err)
+		}
+	}
+	return u
+}
+
+// ReportInstalled reports whether the update installed by the previous run is
+// the one running now. It is called on every connection until the report
+// goes through.
+func (u *Updater) ReportInstalled(client mqtt.Client) {
+	u.mu.Lock()
+	state := u.installed
+	u.mu.Unlock()
+	if state == nil {
+		return
+	}
+
+	status := OTAStatus{RolloutID: state.RolloutID, Version: state.Version, Status: otaUpdated}
+	if state.Version != version {
+		status.Status = otaFailed
+		status.Error = fmt.Sprintf("running version %s after installing %s", version, state.Version)
+	}
+	if !u.report(client, status) {
+		return
+	}
+	log.Printf("Firmware %s %s", state.Version, status.Status)
+
+	u.mu.Lock()
+	u.installed = nil
+	u.mu.Unlock()
+	if err := os.Remove(u.cfg.StatePath); err != nil && !os.IsNotExist(err) {
+		log.Printf("Failed to remove the firmware update state: %v", err)
+	}
+}
+
+// Handle starts installing an update offered on bins/{bin_id}/ota. The empty
+// message clearing the retained offer is ignored, as is an offer received
+// while an update is under way.
+func (u *Updater) Handle(client mqtt.Client, msg mqtt.Message) {
+	if len(msg.Payload()) == 0 {
+		return
+	}
+	var update OTAUpdate
+	if err := json.Unmarshal(msg.Payload(), &update); err != nil {
+		log.Printf("Ignoring malformed firmware update on %s: %v", msg.Topic(), err)
+		return
+	}
+
+	// Waiting for the broker within a message handler would block the
+	// delivery of the reports themselves
+	if update.Version == version {
+		// Already running it; the backend missed the report
+		go u.report(client, OTAStatus{RolloutID: update.RolloutID, Version: update.Version, Status: otaUpdated})
+		return
+	}
+	if u.publicKey == nil {
+		log.Printf("Refusing firmware %s: no OTA_PUBLIC_KEY to check it against", update.Version)
+		go u.report(client, OTAStatus{RolloutID: update.RolloutID, Version: update.Version, Status: otaFailed,
+			Error: "firmware updates are disabled on the device"})
+		return
+	}
+
+	u.mu.Lock()
+	if u.installing {
+		u.mu.Unlock()
+		log.Printf("Ignoring firmware %s while another update is under way", update.Version)
+		return
+	}
+	u.installing = true
+	u.mu.Unlock()
+
+	go u.install(client, update)
+}
+
+// install downloads, checks and swaps in an update, then restarts the device
+func (u *Updater) install(client mqtt.Client, update OTAUpdate) {
+	defer func() {
+		u.mu.Lock()
+		u.installing = false
+		u.mu.Unlock()
+	}()
+
+	log.Printf("Installing firmware %s from %s", update.Version, update.URL)
+	status := OTAStatus{RolloutID: update.RolloutID, Version: update.Version, Status: otaDownloading}
+	u.report(client, status)
+
+	if err := u.swapIn(update); err != nil {
+		log.Printf("Failed to install firmware %s: %v", update.Version, err)
+		status.Status, status.Error = otaFailed, err.Error()
+		u.report(client, status)
+		return
+	}
+
+	log.Printf("Installed firmware %s, restarting into it", update.Version)
+	status.Status = otaInstalled
+	u.report(client, status)
+	replace(u.restart, struct{}{})
+}
+
+// swapIn replaces the running executable with the verified update, keeping
+// the previous one next to it with a .old suffix
+func (u *Updater) swapIn(update OTAUpdate) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the executable: %w", err)
+	}
+	if executable, err = filepath.EvalSymlinks(executable); err != nil {
+		return fmt.Errorf("failed to locate the executable: %w", err)
+	}
+
+	// Downloaded next to the executable so the swap is a rename
+	tmp, err := os.CreateTemp(filepath.Dir(executable), ".ota-*")
+	if err != nil {
+		return fmt.Errorf("failed to create the download file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	digest, err := u.download(update.URL, tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := u.verify(update, digest); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o755); err != nil {
+		return fmt.Errorf("failed to make the firmware executable: %w", err)
+	}
+
+	if err := u.saveState(otaState{RolloutID: update.RolloutID, Version: update.Version}); err != nil {
+		return err
+	}
+	previous := executable + ".old"
+	err = os.Rename(executable, previous)
+	if err != nil {
+		err = fmt.Errorf("failed to keep the previous firmware: %w", err)
+	} else if err = os.Rename(tmp.Name(), executable); err != nil {
+		if restoreErr := os.Rename(previous, executable); restoreErr != nil {
+			log.Printf("Failed to restore the previous firmware: %v", restoreErr)
+		}
+		err = fmt.Errorf("failed to swap the firmware in: %w", err)
+	}
+	if err != nil && u.cfg.StatePath != "" {
+		// Still running the previous firmware, nothing to report after a restart
+		_ = os.Remove(u.cfg.StatePath)
+	}
+	return err
+}
+
+// download writes the binary at url to dst and returns its SHA-256 digest
+func (u *Updater) download(url string, dst io.Writer) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), u.cfg.DownloadTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid firmware URL: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download the firmware: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download the firmware: %s", resp.Status)
+	}
+
+	hash := sha256.New()
+	// One byte over the limit tells a binary too large from one just at it
+	n, err := io.Copy(io.MultiWriter(dst, hash), io.LimitReader(resp.Body, u.cfg.MaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download the firmware: %w", err)
+	}
+	if n > u.cfg.MaxSize {
+		return nil, fmt.Errorf("firmware is larger than %d bytes", u.cfg.MaxSize)
+	}
+	return hash.Sum(nil), nil
+}
+
+// verify checks the digest of the downloaded binary against the one offered
+// and the offered signature against the public key
+func (u *Updater) verify(update OTAUpdate, digest []byte) error {
+	expected, err := hex.DecodeString(strings.TrimSpace(update.SHA256))
+	if err != nil || len(expected) != sha256.Size {
+		return errors.New("invalid sha256 digest")
+	}
+	if !bytes.Equal(digest, expected) {
+		return fmt.Errorf("sha256 mismatch: downloaded %x", digest)
+	}
+	signature, err := base64.StdEncoding.DecodeString(update.Signature)
+	if err != nil {
+		return errors.New("invalid signature encoding")
+	}
+	if !ed25519.Verify(u.publicKey, digest, signature) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// saveState records the update being swapped in, replacing the file at once
+func (u *Updater) saveState(state otaState) error {
+	if u.cfg.StatePath == "" {
+		return nil
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(u.cfg.StatePath), 0o755); err != nil {
+		return fmt.Errorf("failed to create the firmware update state directory: %w", err)
+	}
+	tmp := u.cfg.StatePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write the firmware update state: %w", err)
+	}
+	if err := os.Rename(tmp, u.cfg.StatePath); err != nil {
+		return fmt.Errorf("failed to write the firmware update state: %w", err)
+	}
+	return nil
+}
+
+// report publishes the progress of an update, returning whether the broker
+// acknowledged it
+func (u *Updater) report(client mqtt.Client, status OTAStatus) bool {
+	status.Timestamp = time.Now().Unix()
+	data, _ := json.Marshal(status)
+	token := client.Publish(u.statusTopic, u.qos, false, data)
+	if !token.WaitTimeout(u.timeout) {
+		log.Printf("Failed to report firmware %s %s: no acknowledgement", status.Version, status.Status)
+		return false
+	}
+	if err := token.Error(); err != nil {
+		log.Printf("Failed to report firmware %s %s: %v", status.Version, status.Status, err)
+		return false
+	}
+	return true
+}
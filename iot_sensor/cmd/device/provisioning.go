@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/smartwaste/iot-sensor/pkg/config"
+	"github.com/smartwaste/iot-sensor/pkg/provisioning"
+)
+
+// provision applies the identity issued by the backend to cfg, registering
+// the device first if it was never provisioned. Registration is retried with
+// backoff, since the bin may not be registered yet when the device first
+// boots, until it succeeds, is refused for good or ctx is cancelled.
+func provision(ctx context.Context, cfg *config.Config) error {
+	p := cfg.Provisioning
+	identity, err := provisioning.Load(p.Dir)
+	if err != nil {
+		return err
+	}
+
+	if identity == nil {
+		if p.Serial == "" {
+			p.Serial = provisioning.DefaultSerial()
+		}
+		if p.Serial == "" {
+			return errors.New("no DEVICE_SERIAL set and no hardware serial found")
+		}
+		if p.APIKey == "" {
+			return errors.New("PROVISIONING_URL is set without PROVISIONING_API_KEY")
+		}
+
+		log.Printf("Provisioning device %s with %s", p.Serial, p.URL)
+		backoff := 5 * time.Second
+		for {
+			identity, err = provisioning.Register(ctx, p.URL, p.APIKey, p.Serial, p.Dir)
+			if err == nil {
+				break
+			}
+			var refused *provisioning.Error
+			if errors.As(err, &refused) && !refused.Retryable() {
+				return err
+			}
+			log.Printf("Provisioning failed, retrying in %s: %v", backoff, err)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			if backoff *= 2; backoff > 5*time.Minute {
+				backoff = 5 * time.Minute
+			}
+		}
+		log.Printf("Provisioned as bin %s", identity.BinID)
+	}
+
+	if time.Now().After(identity.ExpiresAt) {
+		log.Printf("Warning: the client certificate expired on %s; reset the provisioning of bin %s to issue a new one",
+			identity.ExpiresAt.Format(time.RFC3339), identity.BinID)
+	}
+
+	cfg.BinID = identity.BinID
+	if identity.BrokerURL != "" {
+		cfg.MQTTBroker = identity.BrokerURL
+	}
+	cfg.TLS.ClientCert = identity.CertFile()
+	cfg.TLS.ClientKey = identity.KeyFile()
+	if ca := identity.CAFile(); ca != "" {
+		cfg.TLS.CACert = ca
+	}
+	return nil
+}
+
+// newTLSConfig builds the TLS configuration for the broker connection, or
+// returns nil when none is configured and the system roots apply
+func newTLSConfig(cfg config.TLSConfig) (*tls.Config, error) {
+	if cfg == (config.TLSConfig{}) {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: cfg.ServerName,
+	}
+
+	if cfg.CACert != "" {
+		pem, err := os.ReadFile(cfg.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read MQTT CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in MQTT CA bundle %s", cfg.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.ClientCert != "" || cfg.ClientKey != "" {
+		if cfg.ClientCert == "" || cfg.ClientKey == "" {
+			return nil, errors.New("MQTT client certificate and key must be configured together")
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load MQTT client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
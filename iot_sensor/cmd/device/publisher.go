@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/smartwaste/iot-sensor/pkg/buffer"
+)
+
+// Publisher sends readings to the broker, keeping them in the buffer while
+// the broker is unreachable and replaying them in order once it is back.
+// Payloads carry the time they were read, so replayed readings keep it.
+type Publisher struct {
+	client  mqtt.Client
+	buffer  *buffer.Ring
+	qos     byte
+	retain  bool
+	timeout time.Duration
+
+	// mu serializes publishing so replayed readings stay ahead of new ones
+	mu sync.Mutex
+
+	// resultMu guards last apart from mu, which is held for a whole replay
+	resultMu sync.Mutex
+	last     *PublishResult
+}
+
+// PublishResult is the outcome of the last attempt to publish a reading
+type PublishResult struct {
+	Topic string    `json:"topic"`
+	At    time.Time `json:"at"`
+	Error string    `json:"error,omitempty"`
+}
+
+// NewPublisher creates a new Publisher
+func NewPublisher(client mqtt.Client, buf *buffer.Ring, qos byte, retain bool, timeout time.Duration) *Publisher {
+	return &Publisher{client: client, buffer: buf, qos: qos, retain: retain, timeout: timeout}
+}
+
+// Publish sends a reading, or buffers it when the broker is unreachable or
+// older readings are still waiting
+func (p *Publisher) Publish(topic string, payload []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.buffer.Len() == 0 && p.client.IsConnectionOpen() {
+		err := p.send(topic, payload)
+		if err == nil {
+			log.Printf("Published to %s: %s", topic, payload)
+			return
+		}
+		// The broker may still have it; the backend drops the duplicate by message ID
+		log.Printf("Failed to publish to %s, buffering: %v", topic, err)
+	}
+
+	if err := p.buffer.Push(buffer.Message{Topic: topic, Payload: payload}); err != nil {
+		log.Printf("Failed to buffer reading: %v", err)
+	}
+	log.Printf("Buffered reading for %s (%d waiting)", topic, p.buffer.Len())
+	if p.client.IsConnectionOpen() {
+		p.flush()
+	}
+}
+
+// Flush replays the buffered readings, oldest first, until the buffer is
+// empty or a publish fails
+func (p *Publisher) Flush() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.flush()
+}
+
+func (p *Publisher) flush() {
+	replayed := 0
+	for p.client.IsConnectionOpen() {
+		msg, ok := p.buffer.Peek()
+		if !ok {
+			break
+		}
+		if err := p.send(msg.Topic, msg.Payload); err != nil {
+			log.Printf("Replay stopped with %d readings left: %v", p.buffer.Len(), err)
+			break
+		}
+		if err := p.buffer.Pop(); err != nil {
+			log.Printf("Failed to remove replayed reading from the buffer: %v", err)
+			break
+		}
+		replayed++
+	}
+	if replayed > 0 {
+		log.Printf("Replayed %d buffered readings", replayed)
+	}
+}
+
+// LastResult returns the outcome of the last attempt to publish, nil before
+// the first one
+func (p *Publisher) LastResult() *PublishResult {
+	p.resultMu.Lock()
+	defer p.resultMu.Unlock()
+	return p.last
+}
+
+// Pending returns the number of readings waiting in the buffer
+func (p *Publisher) Pending() int {
+	return p.buffer.Len()
+}
+
+// send publishes one message and waits for the broker to acknowledge it
+func (p *Publisher) send(topic string, payload []byte) error {
+	token := p.client.Publish(topic, p.qos, p.retain, payload)
+	var err error
+	if !token.WaitTimeout(p.timeout) {
+		err = fmt.Errorf("no acknowledgement within %s", p.timeout)
+	} else {
+		err = token.Error()
+	}
+
+	result := &PublishResult{Topic: topic, At: time.Now()}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	p.resultMu.Lock()
+	p.last = result
+	p.resultMu.Unlock()
+	return err
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/smartwaste/iot-sensor/pkg/config"
+)
+
+// Outcomes of a pushed configuration, acknowledged on bins/{bin_id}/config/ack
+const (
+	configApplied  = "applied"
+	configRejected = "rejected"
+)
+
+// RemoteConfig is a configuration pushed by the backend on
+// bins/{bin_id}/config. Omitted fields keep their value.
+type RemoteConfig struct {
+	// Version orders the pushes; one not newer than the one applied is ignored
+	Version int64 `json:"version"`
+
+	ReadIntervalSeconds      *int  `json:"read_interval_seconds,omitempty"`
+	AdaptiveReporting        *bool `json:"adaptive_reporting,omitempty"`
+	HighFillThreshold        *int  `json:"high_fill_threshold,omitempty"`
+	HighFillIntervalSeconds  *int  `json:"high_fill_interval_seconds,omitempty"`
+	LowFillThreshold         *int  `json:"low_fill_threshold,omitempty"`
+	LowFillIntervalSeconds   *int  `json:"low_fill_interval_seconds,omitempty"`
+	ReportMinDelta           *int  `json:"report_min_delta,omitempty"`
+	HeartbeatIntervalSeconds *int  `json:"heartbeat_interval_seconds,omitempty"`
+
+	HazardTemperatureC *float64 `json:"hazard_temperature_c,omitempty"`
+
+	// Broker credentials, used from the next connection on
+	MQTTUsername *string `json:"mqtt_username,omitempty"`
+	MQTTPassword *string `json:"mqtt_password,omitempty"`
+}
+
+// ConfigAck reports the outcome of a pushed configuration to the backend
+type ConfigAck struct {
+	Version   int64  `json:"version"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// merge returns r with the fields set in next replacing its own
+func (r RemoteConfig) merge(next RemoteConfig) RemoteConfig {
+	r.Version = next.Version
+	mergeField(&r.ReadIntervalSeconds, next.ReadIntervalSeconds)
+	mergeField(&r.AdaptiveReporting, next.AdaptiveReporting)
+	mergeField(&r.HighFillThreshold, next.HighFillThreshold)
+	mergeField(&r.HighFillIntervalSeconds, next.HighFillIntervalSeconds)
+	mergeField(&r.LowFillThreshold, next.LowFillThreshold)
+	mergeField(&r.LowFillIntervalSeconds, next.LowFillIntervalSeconds)
+	mergeField(&r.ReportMinDelta, next.ReportMinDelta)
+	mergeField(&r.HeartbeatIntervalSeconds, next.HeartbeatIntervalSeconds)
+	mergeField(&r.HazardTemperatureC, next.HazardTemperatureC)
+	mergeField(&r.MQTTUsername, next.MQTTUsername)
+	mergeField(&r.MQTTPassword, next.MQTTPassword)
+	return r
+}
+
+func mergeField[T any](dst **T, src *T) {
+	if src != nil {
+		*dst = src
+	}
+}
+
+// Apply copies the fields set in r onto cfg, failing without changing it when
+// a value is out of range
+func (r *RemoteConfig) Apply(cfg *config.Config) error {
+	next := *cfg
+	seconds := func(name string, value *int, target *time.Duration) error {
+		if value == nil {
+			return nil
+		}
+		if *value <= 0 {
+			return fmt.Errorf("%s must be positive, got %d", name, *value)
+		}
+		*target = time.Duration(*value) * time.Second
+		return nil
+	}
+	percent := func(name string, value *int, target *int) error {
+		if value == nil {
+			return nil
+		}
+		if *value < 0 || *value > 100 {
+			return fmt.Errorf("%s must be between 0 and 100, got %d", name, *value)
+		}
+		*target = *value
+		return nil
+	}
+
+	errs := []error{
+		seconds("read_interval_seconds", r.ReadIntervalSeconds, &next.ReadInterval),
+		percent("high_fill_threshold", r.HighFillThreshold, &next.Reporting.HighThreshold),
+		seconds("high_fill_interval_seconds", r.HighFillIntervalSeconds, &next.Reporting.HighInterval),
+		percent("low_fill_threshold", r.LowFillThreshold, &next.Reporting.LowThreshold),
+		seconds("low_fill_interval_seconds", r.LowFillIntervalSeconds, &next.Reporting.LowInterval),
+		percent("report_min_delta", r.ReportMinDelta, &next.Reporting.MinDelta),
+	}
+	if r.HeartbeatIntervalSeconds != nil {
+		// 0 turns the heartbeat off, as with HEARTBEAT_INTERVAL_SECONDS
+		if *r.HeartbeatIntervalSeconds < 0 {
+			errs = append(errs, fmt.Errorf("heartbeat_interval_seconds must not be negative, got %d", *r.HeartbeatIntervalSeconds))
+		} else {
+			next.Reporting.Heartbeat = time.Duration(*r.HeartbeatIntervalSeconds) * time.Second
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	if next.Reporting.LowThreshold > next.Reporting.HighThreshold {
+		return fmt.Errorf("low_fill_threshold %d is above high_fill_threshold %d",
+			next.Reporting.LowThreshold, next.Reporting.HighThreshold)
+	}
+
+	if r.AdaptiveReporting != nil {
+		next.Reporting.Adaptive = *r.AdaptiveReporting
+	}
+	if r.HazardTemperatureC != nil {
+		next.Sensors.HazardTemperatureC = *r.HazardTemperatureC
+	}
+	if r.MQTTUsername != nil {
+		next.MQTTUsername = *r.MQTTUsername
+	}
+	if r.MQTTPassword != nil {
+		next.MQTTPassword = *r.MQTTPassword
+	}
+
+	*cfg = next
+	return nil
+}
+
+// RemoteConfigs applies the configurations pushed by the backend over the
+// environment configuration and keeps them in a file so they survive a
+// restart. Validated configurations are handed to the main loop on Updates.
+type RemoteConfigs struct {
+	path     string
+	ackTopic string
+	qos      byte
+	timeout  time.Duration
+	base     config.Config
+
+	// Updates carries the configuration to switch to
+	Updates chan config.Config
+
+	mu      sync.Mutex
+	applied RemoteConfig
+	current config.Config
+}
+
+// LoadRemoteConfigs returns the configuration pushed by a previous run applied
+// over base. A missing or invalid file leaves base unchanged.
+func LoadRemoteConfigs(base config.Config) *RemoteConfigs {
+	r := &RemoteConfigs{
+		path:     base.ConfigPath,
+		ackTopic: fmt.Sprintf("bins/%s/config/ack", base.BinID),
+		qos:      base.QoS,
+		timeout:  base.PublishTimeout,
+		base:     base,
+		Updates:  make(chan config.Config, 1),
+		current:  base,
+	}
+	if r.path == "" {
+		return r
+	}
+
+	data, err := os.ReadFile(r.path)
+	if os.IsNotExist(err) {
+		return r
+	}
+	if err != nil {
+		log.Printf("Warning: ignoring the pushed configuration: %v", err)
+		return r
+	}
+	var applied RemoteConfig
+	if err := json.Unmarshal(data, &applied); err != nil {
+		log.Printf("Warning: ignoring the corrupt pushed configuration: %v", err)
+		return r
+	}
+	current := base
+	if err := applied.Apply(&current); err != nil {
+		log.Printf("Warning: ignoring the invalid pushed configuration: %v", err)
+		return r
+	}
+	r.applied, r.current = applied, current
+	log.Printf("Applied pushed configuration version %d", applied.Version)
+	return r
+}
+
+// Current returns the configuration in effect
+func (r *RemoteConfigs) Current() config.Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// Credentials returns the broker credentials in effect, for every connection
+func (r *RemoteConfigs) Credentials() (string, string) {
+	cfg := r.Current()
+	return cfg.MQTTUsername, cfg.MQTTPassword
+}
+
+// Handle applies a configuration pushed on bins/{bin_id}/config and
+// acknowledges it. The backend retains its last push, so the device receives
+// it again on every connection and ignores it once applied.
+func (r *RemoteConfigs) Handle(client mqtt.Client, msg mqtt.Message) {
+	var pushed RemoteConfig
+	if err := json.Unmarshal(msg.Payload(), &pushed); err != nil {
+		log.Printf("Ignoring malformed configuration on %s: %v", msg.Topic(), err)
+		return
+	}
+
+	r.mu.Lock()
+	if pushed.Version <= r.applied.Version {
+		r.mu.Unlock()
+		return
+	}
+	merged := r.applied.merge(pushed)
+	next := r.base
+	err := merged.Apply(&next)
+	if err == nil {
+		if err = r.save(merged); err == nil {
+			previous := r.current
+			r.applied, r.current = merged, next
+			replace(r.Updates, next)
+			if next.MQTTUsername != previous.MQTTUsername || next.MQTTPassword != previous.MQTTPassword {
+				log.Println("Broker credentials changed, used from the next connection on")
+			}
+		}
+	}
+	r.mu.Unlock()
+
+	ack := ConfigAck{Version: pushed.Version, Status: configApplied, Timestamp: time.Now().Unix()}
+	if err != nil {
+		log.Printf("Rejected configuration version %d: %v", pushed.Version, err)
+		ack.Status, ack.Error = configRejected, err.Error()
+	} else {
+		log.Printf("Applied configuration version %d", pushed.Version)
+	}
+	// Waiting for the broker within a message handler would block the
+	// delivery of the acknowledgement itself
+	go r.acknowledge(client, ack)
+}
+
+// acknowledge publishes the outcome of a pushed configuration
+func (r *RemoteConfigs) acknowledge(client mqtt.Client, ack ConfigAck) {
+	data, _ := json.Marshal(ack)
+	token := client.Publish(r.ackTopic, r.qos, false, data)
+	if !token.WaitTimeout(r.timeout) {
+		log.Printf("Failed to acknowledge configuration version %d: no acknowledgement", ack.Version)
+	} else if err := token.Error(); err != nil {
+		log.Printf("Failed to acknowledge configuration version %d: %v", ack.Version, err)
+	}
+}
+
+// save writes the configuration to the file, replacing it at once. It holds
+// broker credentials, so only the device user can read it.
+func (r *RemoteConfigs) save(applied RemoteConfig) error {
+	if r.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(applied)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create configuration directory: %w", err)
+	}
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write configuration: %w", err)
+	}
+	if err := os.Rename(tmp, r.path); err != nil {
+		return fmt.Errorf("failed to write configuration: %w", err)
+	}
+	return nil
+}
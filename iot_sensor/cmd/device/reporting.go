@@ -0,0 +1,78 @@
+package main
+
+import (
+	"time"
+
+	"github.com/smartwaste/iot-sensor/pkg/config"
+)
+
+// ReportPolicy decides how often the sensor reads and which readings are
+// published: with adaptive reporting a bin close to full is read more often
+// and a nearly empty one rarely, and readings that barely moved are skipped
+// until the heartbeat is due.
+type ReportPolicy struct {
+	cfg  config.ReportingConfig
+	base time.Duration
+
+	reported   bool
+	lastLevel  int
+	lastReport time.Time
+}
+
+// NewReportPolicy creates a policy reading every base interval between the
+// adaptive thresholds
+func NewReportPolicy(cfg config.ReportingConfig, base time.Duration) *ReportPolicy {
+	return &ReportPolicy{cfg: cfg, base: base}
+}
+
+// SetBase changes the interval between the adaptive thresholds, as the
+// set_interval command does
+func (p *ReportPolicy) SetBase(base time.Duration) {
+	p.base = base
+}
+
+// SetConfig switches to a configuration pushed by the backend, keeping what
+// was last reported
+func (p *ReportPolicy) SetConfig(cfg config.ReportingConfig, base time.Duration) {
+	p.cfg = cfg
+	p.base = base
+}
+
+// Interval returns the wait before the next reading at fillLevel
+func (p *ReportPolicy) Interval(fillLevel int) time.Duration {
+	if !p.cfg.Adaptive {
+		return p.base
+	}
+	switch {
+	case fillLevel >= p.cfg.HighThreshold:
+		return p.cfg.HighInterval
+	case fillLevel < p.cfg.LowThreshold:
+		return p.cfg.LowInterval
+	default:
+		return p.base
+	}
+}
+
+// ShouldReport reports whether a reading is worth publishing: the first one,
+// one that moved by MinDelta since the last published, or any once the
+// heartbeat is due
+func (p *ReportPolicy) ShouldReport(fillLevel int, now time.Time) bool {
+	if !p.reported {
+		return true
+	}
+	delta := fillLevel - p.lastLevel
+	if delta < 0 {
+		delta = -delta
+	}
+	if delta >= p.cfg.MinDelta {
+		return true
+	}
+	return p.cfg.Heartbeat > 0 && now.Sub(p.lastReport) >= p.cfg.Heartbeat
+}
+
+// Reported records a published reading
+func (p *ReportPolicy) Reported(fillLevel int, now time.Time) {
+	p.reported = true
+	p.lastLevel = fillLevel
+	p.lastReport = now
+}
@@ -0,0 +1,166 @@
+package buffer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Message is an MQTT message waiting to be published
+type Message struct {
+	Topic   string          `json:"topic"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// Ring keeps up to size unsent messages in order, dropping the oldest when
+// full. With a path the messages are kept in a file, one JSON document per
+// line, so they survive a restart of the device.
+type Ring struct {
+	path string
+	size int
+
+	mu       sync.Mutex
+	messages []Message
+}
+
+// Open loads the messages left in the file at path by a previous run. An
+// empty path keeps the messages in memory only.
+func Open(path string, size int) (*Ring, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("buffer size must be positive, got %d", size)
+	}
+	r := &Ring{path: path, size: size}
+	if path == "" {
+		return r, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create buffer directory: %w", err)
+	}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open buffer: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var msg Message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			// A line cut short by a power loss; the rest is still usable
+			log.Printf("Skipping corrupt buffered message: %v", err)
+			continue
+		}
+		r.messages = append(r.messages, msg)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read buffer: %w", err)
+	}
+	if len(r.messages) > size {
+		r.messages = r.messages[len(r.messages)-size:]
+		if err := r.save(); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Len returns the number of messages waiting
+func (r *Ring) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.messages)
+}
+
+// Push appends a message, dropping the oldest one when the ring is full
+func (r *Ring) Push(msg Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.messages) >= r.size {
+		log.Printf("Buffer full, dropping the oldest of %d messages", len(r.messages))
+		r.messages = append(r.messages[1:], msg)
+		return r.save()
+	}
+	r.messages = append(r.messages, msg)
+	return r.append(msg)
+}
+
+// Peek returns the oldest message, if any
+func (r *Ring) Peek() (Message, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.messages) == 0 {
+		return Message{}, false
+	}
+	return r.messages[0], true
+}
+
+// Pop removes the oldest message once it was published
+func (r *Ring) Pop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.messages) == 0 {
+		return nil
+	}
+	r.messages = r.messages[1:]
+	return r.save()
+}
+
+// append adds one message at the end of the file
+func (r *Ring) append(msg Message) error {
+	if r.path == "" {
+		return nil
+	}
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open buffer: %w", err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write buffer: %w", err)
+	}
+	return file.Close()
+}
+
+// save rewrites the file with the messages in the ring, replacing it at once
+// so a power loss leaves either the old or the new content
+func (r *Ring) save() error {
+	if r.path == "" {
+		return nil
+	}
+	tmp := r.path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to write buffer: %w", err)
+	}
+	writer := bufio.NewWriter(file)
+	for _, msg := range r.messages {
+		line, err := json.Marshal(msg)
+		if err != nil {
+			file.Close()
+			return err
+		}
+		writer.Write(line)
+		writer.WriteByte('\n')
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write buffer: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write buffer: %w", err)
+	}
+	return os.Rename(tmp, r.path)
+}
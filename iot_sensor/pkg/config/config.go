@@ -14,6 +14,123 @@ type Config struct {
 	BinHeightCm  float64
 	ReadInterval time.Duration
 	Simulation   bool
+
+	MQTTUsername string
+	MQTTPassword string
+	TLS          TLSConfig
+
+	// QoS is the MQTT quality of service of readings: 0 at most once, 1 at
+	// least once, 2 exactly once
+	QoS byte
+	// RetainStatus has the broker keep the last reading for new subscribers
+	RetainStatus bool
+	// ReconnectMaxInterval caps the backoff between reconnection attempts
+	ReconnectMaxInterval time.Duration
+	// PublishTimeout is how long the broker has to acknowledge a reading
+	// before it is buffered
+	PublishTimeout time.Duration
+	// BufferPath is the file unsent readings are kept in; empty keeps them in
+	// memory only
+	BufferPath string
+	BufferSize int // Unsent readings kept, the oldest being dropped first
+	// ConfigPath is the file the configuration pushed by the backend is kept
+	// in, applied over the environment at startup; empty keeps it in memory only
+	ConfigPath string
+	// DiagnosticsAddr is the address the local diagnostics server listens
+	// on; empty disables it
+	DiagnosticsAddr string
+
+	Reporting ReportingConfig
+	Filter    FilterConfig
+	Sensors   SensorsConfig
+	OTA       OTAConfig
+
+	Provisioning ProvisioningConfig
+}
+
+// TLSConfig secures the connection to the broker. A client certificate
+// authenticates the device in place of a username and password.
+type TLSConfig struct {
+	CACert     string // PEM bundle verifying the broker; the system roots when empty
+	ClientCert string
+	ClientKey  string
+	ServerName string // Overrides the host name the broker certificate is checked for
+}
+
+// ProvisioningConfig registers the device with the backend at its first boot
+// to be issued its bin ID and client certificate; disabled without a URL
+type ProvisioningConfig struct {
+	// URL is the base of the backend API, e.g. https://api.example.com/api/v1
+	URL string
+	// APIKey is the fleet key of the device role the registration is made with
+	APIKey string
+	// Serial is the hardware serial registered on the bin; the board serial
+	// number or machine ID when empty
+	Serial string
+	// Dir is the directory the issued identity and credentials are kept in
+	Dir string
+}
+
+// OTAConfig controls the firmware updates offered by the backend
+type OTAConfig struct {
+	// PublicKey is the base64 Ed25519 key firmware signatures are checked
+	// against; updates are refused without one
+	PublicKey string
+	// StatePath is the file an installed update is recorded in, to report
+	// its outcome after the restart into it
+	StatePath       string
+	DownloadTimeout time.Duration
+	MaxSize         int64 // Largest firmware binary downloaded, in bytes
+}
+
+// SensorsConfig selects the optional sensors fitted to the bin besides the
+// ultrasonic one
+type SensorsConfig struct {
+	Weight bool
+	// MaxWeightKg is the weight of the contents of a full bin, for the
+	// simulated load cell
+	MaxWeightKg float64
+	// LoadCellOffset is the raw reading of the empty bin and LoadCellScale the
+	// raw units per kilogram, found by calibrating the installed load cell
+	LoadCellOffset int
+	LoadCellScale  float64
+
+	Lid bool
+
+	Temperature bool
+	// TemperatureProbePath is the w1_slave file of the 1-Wire probe
+	TemperatureProbePath string
+	// HazardTemperatureC publishes a reading at once, whatever the reporting
+	// policy, when the bin is at least this hot
+	HazardTemperatureC float64
+}
+
+// FilterConfig controls the sampling of the ultrasonic sensor; see
+// sensor.FilterConfig
+type FilterConfig struct {
+	Samples        int
+	SampleDelay    time.Duration
+	MaxDeviation   float64
+	Alpha          float64
+	ResetDeviation float64
+}
+
+// ReportingConfig controls how often the sensor reads and publishes
+type ReportingConfig struct {
+	// Adaptive reads every HighInterval at or above HighThreshold percent
+	// full, every LowInterval below LowThreshold, and every ReadInterval in
+	// between
+	Adaptive      bool
+	HighThreshold int
+	HighInterval  time.Duration
+	LowThreshold  int
+	LowInterval   time.Duration
+	// MinDelta is the change in fill level, in points, for a reading to be
+	// published; 0 publishes every reading
+	MinDelta int
+	// Heartbeat publishes a reading after this long without one even if the
+	// level did not change, so the backend does not flag the bin offline
+	Heartbeat time.Duration
 }
 
 // LoadConfig loads configuration from environment variables
@@ -24,6 +141,63 @@ func LoadConfig() Config {
 		BinHeightCm:  getEnvFloat("BIN_HEIGHT_CM", 100.0),
 		ReadInterval: time.Duration(getEnvInt("READ_INTERVAL_SECONDS", 10)) * time.Second,
 		Simulation:   getEnvBool("SIMULATION_MODE", true), // Default to simulation if no hardware
+
+		MQTTUsername: getEnv("MQTT_USERNAME", ""),
+		MQTTPassword: getEnv("MQTT_PASSWORD", ""),
+		TLS: TLSConfig{
+			CACert:     getEnv("MQTT_CA_CERT", ""),
+			ClientCert: getEnv("MQTT_CLIENT_CERT", ""),
+			ClientKey:  getEnv("MQTT_CLIENT_KEY", ""),
+			ServerName: getEnv("MQTT_TLS_SERVER_NAME", ""),
+		},
+
+		QoS:                  byte(getEnvInt("MQTT_QOS", 1)),
+		RetainStatus:         getEnvBool("MQTT_RETAIN_STATUS", false),
+		ReconnectMaxInterval: time.Duration(getEnvInt("MQTT_RECONNECT_MAX_INTERVAL_SECONDS", 120)) * time.Second,
+		PublishTimeout:       time.Duration(getEnvInt("PUBLISH_TIMEOUT_SECONDS", 10)) * time.Second,
+		BufferPath:           getEnv("BUFFER_PATH", "/var/lib/iot-sensor/readings.jsonl"),
+		BufferSize:           getEnvInt("BUFFER_SIZE", 1000),
+		ConfigPath:           getEnv("CONFIG_PATH", "/var/lib/iot-sensor/config.json"),
+		DiagnosticsAddr:      getEnv("DIAGNOSTICS_ADDR", ":8081"),
+
+		Reporting: ReportingConfig{
+			Adaptive:      getEnvBool("ADAPTIVE_REPORTING", false),
+			HighThreshold: getEnvInt("HIGH_FILL_THRESHOLD", 70),
+			HighInterval:  time.Duration(getEnvInt("HIGH_FILL_INTERVAL_SECONDS", 300)) * time.Second,
+			LowThreshold:  getEnvInt("LOW_FILL_THRESHOLD", 30),
+			LowInterval:   time.Duration(getEnvInt("LOW_FILL_INTERVAL_SECONDS", 3600)) * time.Second,
+			MinDelta:      getEnvInt("REPORT_MIN_DELTA", 0),
+			Heartbeat:     time.Duration(getEnvInt("HEARTBEAT_INTERVAL_SECONDS", 3600)) * time.Second,
+		},
+		Filter: FilterConfig{
+			Samples:        getEnvInt("SENSOR_SAMPLES", 5),
+			SampleDelay:    time.Duration(getEnvInt("SENSOR_SAMPLE_DELAY_MS", 60)) * time.Millisecond,
+			MaxDeviation:   getEnvFloat("SENSOR_MAX_DEVIATION_CM", 10),
+			Alpha:          getEnvFloat("SENSOR_SMOOTHING_ALPHA", 0.5),
+			ResetDeviation: getEnvFloat("SENSOR_SMOOTHING_RESET_CM", 30),
+		},
+		Sensors: SensorsConfig{
+			Weight:               getEnvBool("WEIGHT_SENSOR", false),
+			MaxWeightKg:          getEnvFloat("BIN_MAX_WEIGHT_KG", 50),
+			LoadCellOffset:       getEnvInt("LOAD_CELL_OFFSET", 0),
+			LoadCellScale:        getEnvFloat("LOAD_CELL_SCALE", 1),
+			Lid:                  getEnvBool("LID_SENSOR", false),
+			Temperature:          getEnvBool("TEMPERATURE_SENSOR", false),
+			TemperatureProbePath: getEnv("TEMPERATURE_PROBE_PATH", ""),
+			HazardTemperatureC:   getEnvFloat("HAZARD_TEMPERATURE_C", 60),
+		},
+		OTA: OTAConfig{
+			PublicKey:       getEnv("OTA_PUBLIC_KEY", ""),
+			StatePath:       getEnv("OTA_STATE_PATH", "/var/lib/iot-sensor/ota.json"),
+			DownloadTimeout: time.Duration(getEnvInt("OTA_DOWNLOAD_TIMEOUT_SECONDS", 300)) * time.Second,
+			MaxSize:         int64(getEnvInt("OTA_MAX_SIZE_MB", 64)) << 20,
+		},
+		Provisioning: ProvisioningConfig{
+			URL:    getEnv("PROVISIONING_URL", ""),
+			APIKey: getEnv("PROVISIONING_API_KEY", ""),
+			Serial: getEnv("DEVICE_SERIAL", ""),
+			Dir:    getEnv("CREDENTIALS_DIR", "/var/lib/iot-sensor/credentials"),
+		},
 	}
 }
 
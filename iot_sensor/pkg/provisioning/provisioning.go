@@ -0,0 +1,228 @@
+// Package provisioning registers the device with the backend at its first
+// boot and keeps the identity and broker credentials it is issued.
+package provisioning
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Files of the provisioning directory
+const (
+	identityFile    = "identity.json"
+	certificateFile = "client.crt"
+	keyFile         = "client.key"
+	caFile          = "ca.crt"
+)
+
+// Identity is what the backend issued to the device
+type Identity struct {
+	// BinID is the device ID of the bin, also the broker username
+	BinID     string    `json:"bin_id"`
+	BrokerURL string    `json:"broker_url,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+
+	dir   string
+	hasCA bool
+}
+
+// CertFile returns the path of the client certificate
+func (i *Identity) CertFile() string { return filepath.Join(i.dir, certificateFile) }
+
+// KeyFile returns the path of the client private key
+func (i *Identity) KeyFile() string { return filepath.Join(i.dir, keyFile) }
+
+// CAFile returns the path of the CA bundle to verify the broker with, or ""
+// when the broker has a publicly trusted certificate
+func (i *Identity) CAFile() string {
+	if !i.hasCA {
+		return ""
+	}
+	return filepath.Join(i.dir, caFile)
+}
+
+// Load returns the identity kept in dir, or nil when the device was not
+// provisioned yet
+func Load(dir string) (*Identity, error) {
+	data, err := os.ReadFile(filepath.Join(dir, identityFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	identity := &Identity{dir: dir}
+	if err := json.Unmarshal(data, identity); err != nil {
+		return nil, fmt.Errorf("corrupt identity: %w", err)
+	}
+	for _, name := range []string{certificateFile, keyFile} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			return nil, fmt.Errorf("incomplete identity: %w", err)
+		}
+	}
+	_, err = os.Stat(filepath.Join(dir, caFile))
+	identity.hasCA = err == nil
+	return identity, nil
+}
+
+// Error is an answer of the backend refusing to provision the device
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("provisioning refused (%d %s): %s", e.StatusCode, e.Code, e.Message)
+}
+
+// Retryable reports whether asking again later may succeed: the bin may not
+// be registered yet, or the backend not configured or reachable
+func (e *Error) Retryable() bool {
+	return e.StatusCode == http.StatusNotFound || e.StatusCode == http.StatusConflict ||
+		e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// Register generates a key pair, has the backend at baseURL (the /api/v1
+// URL) sign its certificate for the bin registered with serial, and keeps the
+// result in dir. The private key never leaves the device.
+func Register(ctx context.Context, baseURL, apiKey, serial, dir string) (*Identity, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{SerialNumber: serial},
+	}, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate signing request: %w", err)
+	}
+
+	body, _ := json.Marshal(map[string]string{
+		"hardware_serial": serial,
+		"csr":             string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr})),
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		strings.TrimSuffix(baseURL, "/")+"/provisioning/register", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", apiKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Data struct {
+			BinID         string    `json:"bin_id"`
+			Certificate   string    `json:"certificate"`
+			CACertificate string    `json:"ca_certificate"`
+			BrokerURL     string    `json:"broker_url"`
+			ExpiresAt     time.Time `json:"expires_at"`
+		} `json:"data"`
+		Error *struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("invalid provisioning response (%s): %w", resp.Status, err)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		refused := &Error{StatusCode: resp.StatusCode, Message: resp.Status}
+		if envelope.Error != nil {
+			refused.Code, refused.Message = envelope.Error.Code, envelope.Error.Message
+		}
+		return nil, refused
+	}
+	issued := envelope.Data
+	if issued.BinID == "" || issued.Certificate == "" {
+		return nil, errors.New("provisioning response without bin_id or certificate")
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode key: %w", err)
+	}
+	identity := &Identity{
+		BinID:     issued.BinID,
+		BrokerURL: issued.BrokerURL,
+		ExpiresAt: issued.ExpiresAt,
+		dir:       dir,
+		hasCA:     issued.CACertificate != "",
+	}
+	if err := identity.save(issued.Certificate, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), issued.CACertificate); err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// credentialFile is a file of the provisioning directory
+type credentialFile struct {
+	name string
+	data []byte
+	mode os.FileMode
+}
+
+// save writes the credentials, then the identity, which marks them complete
+func (i *Identity) save(certificate string, key []byte, ca string) error {
+	if err := os.MkdirAll(i.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create the credentials directory: %w", err)
+	}
+	identity, err := json.Marshal(i)
+	if err != nil {
+		return err
+	}
+	files := []credentialFile{
+		{keyFile, key, 0o600},
+		{certificateFile, []byte(certificate), 0o644},
+	}
+	if ca != "" {
+		files = append(files, credentialFile{caFile, []byte(ca), 0o644})
+	}
+	files = append(files, credentialFile{identityFile, identity, 0o644})
+
+	for _, f := range files {
+		path := filepath.Join(i.dir, f.name)
+		if err := os.WriteFile(path+".tmp", f.data, f.mode); err != nil {
+			return fmt.Errorf("failed to write %s: %w", f.name, err)
+		}
+		if err := os.Rename(path+".tmp", path); err != nil {
+			return fmt.Errorf("failed to write %s: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+// DefaultSerial returns the hardware serial of the board: the serial number
+// of a Raspberry Pi, or the machine ID elsewhere
+func DefaultSerial() string {
+	for _, path := range []string{"/sys/firmware/devicetree/base/serial-number", "/etc/machine-id"} {
+		if data, err := os.ReadFile(path); err == nil {
+			// The device tree value is NUL terminated
+			if serial := strings.Trim(string(data), "\x00 \n"); serial != "" {
+				return serial
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,46 @@
+package sensor
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// DS18B20 implements TemperatureSensor with a DS18B20 probe read through the
+// Linux 1-Wire driver (w1-gpio and w1-therm), which exposes each probe as
+// /sys/bus/w1/devices/28-<serial>/w1_slave
+type DS18B20 struct {
+	path string
+}
+
+// NewDS18B20 creates a new probe reading the w1_slave file at path
+func NewDS18B20(path string) *DS18B20 {
+	return &DS18B20{path: path}
+}
+
+// ReadTemperature parses the two lines of the driver, a CRC check ending in
+// YES and the temperature in thousandths of a degree after t=
+func (s *DS18B20) ReadTemperature() (float64, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read probe: %w", err)
+	}
+	lines := bytes.Split(bytes.TrimSpace(data), []byte("\n"))
+	if len(lines) < 2 || !bytes.HasSuffix(bytes.TrimSpace(lines[0]), []byte("YES")) {
+		return 0, fmt.Errorf("probe reading failed its CRC check")
+	}
+	i := bytes.Index(lines[1], []byte("t="))
+	if i < 0 {
+		return 0, fmt.Errorf("probe reading has no temperature")
+	}
+	milli, err := strconv.Atoi(string(bytes.TrimSpace(lines[1][i+2:])))
+	if err != nil {
+		return 0, fmt.Errorf("invalid probe temperature: %w", err)
+	}
+	return float64(milli) / 1000, nil
+}
+
+func (s *DS18B20) Close() error {
+	return nil
+}
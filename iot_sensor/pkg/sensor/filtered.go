@@ -0,0 +1,127 @@
+package sensor
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// FilterConfig controls how FilteredSensor turns noisy samples into a reading
+type FilterConfig struct {
+	// Samples taken per reading, SampleDelay apart; the HC-SR04 needs about
+	// 60ms for the echo of one ping to fade
+	Samples     int
+	SampleDelay time.Duration
+	// MaxDeviation rejects samples further than this many centimeters from
+	// the median of the reading; 0 keeps every sample
+	MaxDeviation float64
+	// Alpha weights a new reading against the previous ones in the moving
+	// average, from 0 exclusive to 1, which disables smoothing
+	Alpha float64
+	// ResetDeviation takes a reading as is when it is this many centimeters
+	// away from the average, as when the bin was emptied; 0 never resets
+	ResetDeviation float64
+}
+
+// FilteredSensor decorates a Sensor to smooth out the noise of ultrasonic
+// readings: each reading is the mean of the samples close to their median,
+// then smoothed with an exponentially weighted moving average
+type FilteredSensor struct {
+	sensor Sensor
+	cfg    FilterConfig
+
+	average float64
+	primed  bool
+}
+
+// NewFilteredSensor wraps sensor with the given filter
+func NewFilteredSensor(sensor Sensor, cfg FilterConfig) (*FilteredSensor, error) {
+	if cfg.Samples < 1 {
+		return nil, fmt.Errorf("samples must be at least 1, got %d", cfg.Samples)
+	}
+	if cfg.Alpha <= 0 || cfg.Alpha > 1 {
+		return nil, fmt.Errorf("alpha must be in (0, 1], got %g", cfg.Alpha)
+	}
+	return &FilteredSensor{sensor: sensor, cfg: cfg}, nil
+}
+
+// ReadDistance samples the sensor and returns the filtered distance. Failed
+// samples are skipped; it fails only when every sample does.
+func (s *FilteredSensor) ReadDistance() (float64, error) {
+	samples := make([]float64, 0, s.cfg.Samples)
+	var lastErr error
+	for i := 0; i < s.cfg.Samples; i++ {
+		if i > 0 && s.cfg.SampleDelay > 0 {
+			time.Sleep(s.cfg.SampleDelay)
+		}
+		distance, err := s.sensor.ReadDistance()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		samples = append(samples, distance)
+	}
+	if len(samples) == 0 {
+		if lastErr == nil {
+			lastErr = errors.New("no samples")
+		}
+		return 0, fmt.Errorf("all %d samples failed: %w", s.cfg.Samples, lastErr)
+	}
+
+	reading := s.reject(samples)
+	return s.smooth(reading), nil
+}
+
+// Close releases the underlying sensor
+func (s *FilteredSensor) Close() error {
+	return s.sensor.Close()
+}
+
+// reject returns the mean of the samples within MaxDeviation of their median
+func (s *FilteredSensor) reject(samples []float64) float64 {
+	mid := median(samples)
+	if s.cfg.MaxDeviation <= 0 {
+		return mean(samples)
+	}
+
+	kept := samples[:0:0]
+	for _, sample := range samples {
+		if math.Abs(sample-mid) <= s.cfg.MaxDeviation {
+			kept = append(kept, sample)
+		}
+	}
+	// The median itself is always within range, so kept is never empty
+	return mean(kept)
+}
+
+// smooth folds reading into the moving average
+func (s *FilteredSensor) smooth(reading float64) float64 {
+	if !s.primed || (s.cfg.ResetDeviation > 0 && math.Abs(reading-s.average) >= s.cfg.ResetDeviation) {
+		s.average = reading
+		s.primed = true
+		return reading
+	}
+	s.average = s.cfg.Alpha*reading + (1-s.cfg.Alpha)*s.average
+	return s.average
+}
+
+// median returns the median of values, which must not be empty
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+func mean(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
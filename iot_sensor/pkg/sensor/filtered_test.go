@@ -0,0 +1,296 @@
+package sensor
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+// sample is one scripted ReadDistance result of fakeSensor
+type sample struct {
+	distance float64
+	err      error
+}
+
+// fakeSensor returns its scripted samples in order, then fails
+type fakeSensor struct {
+	samples []sample
+	reads   int
+	closed  bool
+}
+
+func (f *fakeSensor) ReadDistance() (float64, error) {
+	if f.reads >= len(f.samples) {
+		return 0, errors.New("fake sensor: no more samples")
+	}
+	s := f.samples[f.reads]
+	f.reads++
+	return s.distance, s.err
+}
+
+func (f *fakeSensor) Close() error {
+	f.closed = true
+	return nil
+}
+
+// distances scripts successful samples
+func distances(values ...float64) []sample {
+	samples := make([]sample, len(values))
+	for i, v := range values {
+		samples[i] = sample{distance: v}
+	}
+	return samples
+}
+
+const tolerance = 1e-9
+
+func TestNewFilteredSensor(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     FilterConfig
+		wantErr bool
+	}{
+		{"valid", FilterConfig{Samples: 5, Alpha: 0.3}, false},
+		{"smoothing disabled", FilterConfig{Samples: 1, Alpha: 1}, false},
+		{"no samples", FilterConfig{Samples: 0, Alpha: 0.3}, true},
+		{"zero alpha", FilterConfig{Samples: 5, Alpha: 0}, true},
+		{"negative alpha", FilterConfig{Samples: 5, Alpha: -0.5}, true},
+		{"alpha above one", FilterConfig{Samples: 5, Alpha: 1.5}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewFilteredSensor(&fakeSensor{}, tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewFilteredSensor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFilteredSensorRejectsOutliers(t *testing.T) {
+	echo := errors.New("no echo")
+
+	tests := []struct {
+		name         string
+		samples      []sample
+		maxDeviation float64
+		want         float64
+	}{
+		{
+			name:         "outlier above the median",
+			samples:      distances(50, 51, 49, 120, 50),
+			maxDeviation: 5,
+			want:         50,
+		},
+		{
+			name:         "outliers on both sides",
+			samples:      distances(3, 80, 82, 81, 250),
+			maxDeviation: 5,
+			want:         81,
+		},
+		{
+			name:         "even samples use the mean of the middle two",
+			samples:      distances(40, 44, 42, 90),
+			maxDeviation: 3,
+			want:         42,
+		},
+		{
+			name:         "every sample kept without a deviation",
+			samples:      distances(50, 51, 49, 120, 50),
+			maxDeviation: 0,
+			want:         64,
+		},
+		{
+			name:         "failed samples are skipped",
+			samples:      []sample{{distance: 60}, {err: echo}, {distance: 62}, {err: echo}, {distance: 400}},
+			maxDeviation: 10,
+			want:         61,
+		},
+		{
+			name:         "single successful sample",
+			samples:      []sample{{err: echo}, {err: echo}, {distance: 75}},
+			maxDeviation: 1,
+			want:         75,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeSensor{samples: tt.samples}
+			filtered, err := NewFilteredSensor(fake, FilterConfig{
+				Samples:      len(tt.samples),
+				MaxDeviation: tt.maxDeviation,
+				Alpha:        1,
+			})
+			if err != nil {
+				t.Fatalf("NewFilteredSensor() error = %v", err)
+			}
+
+			got, err := filtered.ReadDistance()
+			if err != nil {
+				t.Fatalf("ReadDistance() error = %v", err)
+			}
+			if math.Abs(got-tt.want) > tolerance {
+				t.Errorf("ReadDistance() = %v, want %v", got, tt.want)
+			}
+			if fake.reads != len(tt.samples) {
+				t.Errorf("read %d samples, want %d", fake.reads, len(tt.samples))
+			}
+		})
+	}
+}
+
+// readAll takes one single-sample reading per distance
+func readAll(t *testing.T, cfg FilterConfig, values ...float64) []float64 {
+	t.Helper()
+	cfg.Samples = 1
+	filtered, err := NewFilteredSensor(&fakeSensor{samples: distances(values...)}, cfg)
+	if err != nil {
+		t.Fatalf("NewFilteredSensor() error = %v", err)
+	}
+
+	readings := make([]float64, len(values))
+	for i := range values {
+		if readings[i], err = filtered.ReadDistance(); err != nil {
+			t.Fatalf("ReadDistance() #%d error = %v", i, err)
+		}
+	}
+	return readings
+}
+
+func TestFilteredSensorSmoothing(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    FilterConfig
+		inputs []float64
+		want   []float64
+	}{
+		{
+			name:   "first reading primes the average",
+			cfg:    FilterConfig{Alpha: 0.2},
+			inputs: []float64{100},
+			want:   []float64{100},
+		},
+		{
+			name:   "half weight",
+			cfg:    FilterConfig{Alpha: 0.5},
+			inputs: []float64{100, 80, 80, 80},
+			want:   []float64{100, 90, 85, 82.5},
+		},
+		{
+			name:   "low alpha damps a spike",
+			cfg:    FilterConfig{Alpha: 0.1},
+			inputs: []float64{100, 100, 200, 100},
+			want:   []float64{100, 100, 110, 109},
+		},
+		{
+			name:   "alpha of one passes readings through",
+			cfg:    FilterConfig{Alpha: 1},
+			inputs: []float64{100, 30, 70},
+			want:   []float64{100, 30, 70},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := readAll(t, tt.cfg, tt.inputs...)
+			for i := range tt.want {
+				if math.Abs(got[i]-tt.want[i]) > tolerance {
+					t.Errorf("readings = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestFilteredSensorResetDeviation(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    FilterConfig
+		inputs []float64
+		want   []float64
+	}{
+		{
+			name:   "emptied bin resets the average",
+			cfg:    FilterConfig{Alpha: 0.5, ResetDeviation: 30},
+			inputs: []float64{20, 20, 110, 110},
+			want:   []float64{20, 20, 110, 110},
+		},
+		{
+			name:   "a jump of exactly the deviation resets",
+			cfg:    FilterConfig{Alpha: 0.5, ResetDeviation: 30},
+			inputs: []float64{50, 80},
+			want:   []float64{50, 80},
+		},
+		{
+			name:   "smaller changes are smoothed",
+			cfg:    FilterConfig{Alpha: 0.5, ResetDeviation: 30},
+			inputs: []float64{50, 70, 40},
+			want:   []float64{50, 60, 50},
+		},
+		{
+			name:   "no reset without a deviation",
+			cfg:    FilterConfig{Alpha: 0.5},
+			inputs: []float64{20, 110},
+			want:   []float64{20, 65},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := readAll(t, tt.cfg, tt.inputs...)
+			for i := range tt.want {
+				if math.Abs(got[i]-tt.want[i]) > tolerance {
+					t.Errorf("readings = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestFilteredSensorAllSamplesFail(t *testing.T) {
+	timeout := errors.New("echo timeout")
+	fake := &fakeSensor{samples: []sample{
+		{err: errors.New("no echo")}, {err: errors.New("no echo")}, {err: timeout},
+		{distance: 40}, {distance: 40}, {distance: 40},
+	}}
+	filtered, err := NewFilteredSensor(fake, FilterConfig{Samples: 3, Alpha: 0.5})
+	if err != nil {
+		t.Fatalf("NewFilteredSensor() error = %v", err)
+	}
+
+	_, err = filtered.ReadDistance()
+	if !errors.Is(err, timeout) {
+		t.Fatalf("ReadDistance() error = %v, want the last sample error", err)
+	}
+	if !strings.Contains(err.Error(), "all 3 samples failed") {
+		t.Errorf("ReadDistance() error = %q, want the number of failed samples", err)
+	}
+
+	// The failed reading leaves the average unprimed
+	got, err := filtered.ReadDistance()
+	if err != nil {
+		t.Fatalf("ReadDistance() error = %v", err)
+	}
+	if got != 40 {
+		t.Errorf("ReadDistance() after the failure = %v, want 40", got)
+	}
+}
+
+func TestFilteredSensorClose(t *testing.T) {
+	fake := &fakeSensor{}
+	filtered, err := NewFilteredSensor(fake, FilterConfig{Samples: 1, Alpha: 1})
+	if err != nil {
+		t.Fatalf("NewFilteredSensor() error = %v", err)
+	}
+	if err := filtered.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if !fake.closed {
+		t.Error("Close() did not close the underlying sensor")
+	}
+}
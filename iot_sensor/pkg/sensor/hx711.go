@@ -0,0 +1,75 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+// Like hcsr04.go, this file needs TinyGo and is excluded from standard builds.
+
+package sensor
+
+import (
+	"errors"
+	"machine"
+	"time"
+)
+
+// HX711 implements WeightSensor with a load cell behind an HX711 amplifier,
+// read on channel A at a gain of 128
+type HX711 struct {
+	data  machine.Pin
+	clock machine.Pin
+	// offset is the raw value of the empty bin and scale the raw units per
+	// kilogram, both found by calibrating the installed bin
+	offset int32
+	scale  float64
+}
+
+// NewHX711 creates a new HX711 load cell
+func NewHX711(dataPin, clockPin machine.Pin, offset int32, scale float64) *HX711 {
+	dataPin.Configure(machine.PinConfig{Mode: machine.PinInput})
+	clockPin.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	clockPin.Low()
+	return &HX711{data: dataPin, clock: clockPin, offset: offset, scale: scale}
+}
+
+func (s *HX711) ReadWeight() (float64, error) {
+	// The data line goes low when a conversion is ready, 10 times a second
+	deadline := time.Now().Add(500 * time.Millisecond)
+	for s.data.Get() {
+		if time.Now().After(deadline) {
+			return 0, errors.New("hx711 not ready")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// 24 bits, most significant first, then one more pulse to select
+	// channel A at a gain of 128 for the next conversion
+	var raw int32
+	for i := 0; i < 24; i++ {
+		s.clock.High()
+		time.Sleep(time.Microsecond)
+		raw <<= 1
+		if s.data.Get() {
+			raw |= 1
+		}
+		s.clock.Low()
+		time.Sleep(time.Microsecond)
+	}
+	s.clock.High()
+	time.Sleep(time.Microsecond)
+	s.clock.Low()
+
+	// Sign-extend the 24-bit two's complement value
+	if raw&0x800000 != 0 {
+		raw |= ^0xffffff
+	}
+	weight := float64(raw-s.offset) / s.scale
+	if weight < 0 {
+		weight = 0
+	}
+	return weight, nil
+}
+
+func (s *HX711) Close() error {
+	// Holding the clock high for over 60µs powers the HX711 down
+	s.clock.High()
+	return nil
+}
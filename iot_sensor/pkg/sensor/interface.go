@@ -7,3 +7,23 @@ type Sensor interface {
 	// Close releases any resources
 	Close() error
 }
+
+// WeightSensor is a load cell weighing the contents of the bin
+type WeightSensor interface {
+	// ReadWeight returns the weight of the waste in kilograms
+	ReadWeight() (float64, error)
+	Close() error
+}
+
+// LidSensor reports whether the lid of the bin is open
+type LidSensor interface {
+	LidOpen() (bool, error)
+	Close() error
+}
+
+// TemperatureSensor is a probe inside the bin
+type TemperatureSensor interface {
+	// ReadTemperature returns the temperature in degrees Celsius
+	ReadTemperature() (float64, error)
+	Close() error
+}
@@ -0,0 +1,30 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+// Like hcsr04.go, this file needs TinyGo and is excluded from standard builds.
+
+package sensor
+
+import "machine"
+
+// ReedSwitch implements LidSensor with a reed switch closed by a magnet on
+// the lid, wired between the pin and ground
+type ReedSwitch struct {
+	pin machine.Pin
+}
+
+// NewReedSwitch creates a new lid switch
+func NewReedSwitch(pin machine.Pin) *ReedSwitch {
+	pin.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+	return &ReedSwitch{pin: pin}
+}
+
+// LidOpen reports an open lid when the magnet is away and the pull-up holds
+// the pin high
+func (s *ReedSwitch) LidOpen() (bool, error) {
+	return s.pin.Get(), nil
+}
+
+func (s *ReedSwitch) Close() error {
+	return nil
+}
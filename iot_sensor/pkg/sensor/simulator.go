@@ -39,3 +39,67 @@ func (s *Simulator) ReadDistance() (float64, error) {
 func (s *Simulator) Close() error {
 	return nil
 }
+
+// WeightSimulator implements WeightSensor with a weight following the fill
+// level of a Simulator
+type WeightSimulator struct {
+	level     *Simulator
+	maxWeight float64
+}
+
+// NewWeightSimulator creates a simulated load cell weighing maxWeight
+// kilograms when the bin of level is full
+func NewWeightSimulator(level *Simulator, maxWeight float64) *WeightSimulator {
+	return &WeightSimulator{level: level, maxWeight: maxWeight}
+}
+
+func (s *WeightSimulator) ReadWeight() (float64, error) {
+	fill := (s.level.binHeight - s.level.currentLevel) / s.level.binHeight
+	// Waste is not evenly dense
+	weight := fill * s.maxWeight * (0.9 + rand.Float64()*0.2)
+	if weight < 0 {
+		weight = 0
+	}
+	return weight, nil
+}
+
+func (s *WeightSimulator) Close() error {
+	return nil
+}
+
+// LidSimulator implements LidSensor with a lid opened now and then
+type LidSimulator struct{}
+
+// NewLidSimulator creates a simulated lid switch
+func NewLidSimulator() *LidSimulator {
+	return &LidSimulator{}
+}
+
+func (s *LidSimulator) LidOpen() (bool, error) {
+	return rand.Float64() < 0.05, nil
+}
+
+func (s *LidSimulator) Close() error {
+	return nil
+}
+
+// TemperatureSimulator implements TemperatureSensor with a temperature
+// drifting around the ambient one
+type TemperatureSimulator struct {
+	current float64
+}
+
+// NewTemperatureSimulator creates a simulated probe starting at ambient
+// degrees Celsius
+func NewTemperatureSimulator(ambient float64) *TemperatureSimulator {
+	return &TemperatureSimulator{current: ambient}
+}
+
+func (s *TemperatureSimulator) ReadTemperature() (float64, error) {
+	s.current += (rand.Float64() - 0.5) * 0.5
+	return s.current, nil
+}
+
+func (s *TemperatureSimulator) Close() error {
+	return nil
+}
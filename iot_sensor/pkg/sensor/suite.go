@@ -0,0 +1,82 @@
+package sensor
+
+import (
+	"errors"
+	"fmt"
+	"log"
+)
+
+// Suite is the set of sensors fitted to a bin. Distance is required; the
+// others are optional and nil when the bin has none.
+type Suite struct {
+	Distance    Sensor
+	Weight      WeightSensor
+	Lid         LidSensor
+	Temperature TemperatureSensor
+}
+
+// Reading is one reading of every sensor of a Suite. Optional fields are nil
+// when the bin has no such sensor or it failed to read.
+type Reading struct {
+	DistanceCm   float64
+	WeightKg     *float64
+	LidOpen      *bool
+	TemperatureC *float64
+}
+
+// Read reads every sensor. It fails only when the distance cannot be read;
+// failures of the other sensors are logged and leave their field empty.
+func (s *Suite) Read() (Reading, error) {
+	distance, err := s.Distance.ReadDistance()
+	if err != nil {
+		return Reading{}, err
+	}
+	reading := Reading{DistanceCm: distance}
+
+	if s.Weight != nil {
+		if weight, err := s.Weight.ReadWeight(); err != nil {
+			log.Printf("Error reading load cell: %v", err)
+		} else {
+			reading.WeightKg = &weight
+		}
+	}
+	if s.Lid != nil {
+		if open, err := s.Lid.LidOpen(); err != nil {
+			log.Printf("Error reading lid switch: %v", err)
+		} else {
+			reading.LidOpen = &open
+		}
+	}
+	if s.Temperature != nil {
+		if temperature, err := s.Temperature.ReadTemperature(); err != nil {
+			log.Printf("Error reading temperature probe: %v", err)
+		} else {
+			reading.TemperatureC = &temperature
+		}
+	}
+	return reading, nil
+}
+
+// Close releases every sensor of the suite
+func (s *Suite) Close() error {
+	var errs []error
+	if err := s.Distance.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("distance: %w", err))
+	}
+	if s.Weight != nil {
+		if err := s.Weight.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("weight: %w", err))
+		}
+	}
+	if s.Lid != nil {
+		if err := s.Lid.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("lid: %w", err))
+		}
+	}
+	if s.Temperature != nil {
+		if err := s.Temperature.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("temperature: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
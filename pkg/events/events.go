@@ -0,0 +1,80 @@
+// Package events holds the schemas of the events the shipment tracker
+// publishes on NATS, so its producers and the consumers of the other
+// services share them.
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event is the envelope of every published event. Schema names the versioned
+// schema of its data, e.g. shipment.created.v1.
+type Event struct {
+	EventID   uuid.UUID       `json:"event_id"`
+	EventType string          `json:"event_type"`
+	Schema    string          `json:"schema,omitempty"`
+	Timestamp string          `json:"timestamp"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// New creates an event of subject carrying data, stamped now. The data must
+// be the payload registered for the subject and valid.
+func New(subject string, data Payload) (*Event, error) {
+	schema, err := schemaFor(subject, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := data.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidPayload, schema.Name(), err)
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s data: %w", subject, err)
+	}
+	return &Event{
+		EventID:   uuid.New(),
+		EventType: subject,
+		Schema:    schema.Name(),
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Data:      raw,
+	}, nil
+}
+
+// Parse decodes the envelope of an event
+func Parse(payload []byte) (*Event, error) {
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// Decode decodes the data of the event into v, which must be the payload
+// registered for its subject, and validates it. Events of another version of
+// the schema than the one registered are rejected; events published before
+// schemas were named are version 1.
+func (e *Event) Decode(v Payload) error {
+	schema, err := schemaFor(e.EventType, v)
+	if err != nil {
+		return err
+	}
+	if e.Schema != "" && e.Schema != schema.Name() {
+		return fmt.Errorf("%w: got %s, want %s", ErrUnsupportedVersion, e.Schema, schema.Name())
+	}
+	if e.Schema == "" && schema.Version != 1 {
+		return fmt.Errorf("%w: got an unversioned event, want %s", ErrUnsupportedVersion, schema.Name())
+	}
+
+	if err := json.Unmarshal(e.Data, v); err != nil {
+		return fmt.Errorf("failed to decode %s data: %w", schema.Name(), err)
+	}
+	if err := v.Validate(); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidPayload, schema.Name(), err)
+	}
+	return nil
+}
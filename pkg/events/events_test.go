@@ -0,0 +1,416 @@
+package events_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/pkg/events"
+)
+
+var (
+	shipmentID   = uuid.MustParse("9b2f4c1e-7d3a-4e5b-8f6a-1c2d3e4f5a6b")
+	userID       = uuid.MustParse("1f0e2d3c-4b5a-4978-8695-a4b3c2d1e0f9")
+	driverID     = uuid.MustParse("5a6b7c8d-9e0f-4a1b-8c2d-3e4f5a6b7c8d")
+	collectionID = uuid.MustParse("c0ffee00-1234-4abc-9def-0123456789ab")
+	createdAt    = time.Date(2026, 3, 14, 9, 26, 53, 0, time.UTC)
+)
+
+// samples returns a valid payload of every v1 subject, with the optional
+// fields set so the round trips cover them
+func samples() map[string]events.Payload {
+	latitude, longitude, address := 36.7538, 3.0588, "1 Rue Didouche Mourad"
+	weight := 42.5
+	dwell := 90
+	previous := createdAt.Add(-5 * time.Minute)
+
+	statusChanged := func(status string) events.Payload {
+		return &events.StatusChanged{
+			ShipmentID:   shipmentID,
+			Status:       status,
+			UpdatedBy:    driverID,
+			UserID:       userID,
+			CollectionID: collectionID,
+			DriverID:     &driverID,
+			WasteType:    "plastic",
+			WeightKg:     &weight,
+			Metadata:     map[string]interface{}{"actual_weight_kg": weight},
+		}
+	}
+
+	return map[string]events.Payload{
+		events.SubjectShipmentCreated: &events.ShipmentCreated{
+			ShipmentID:        shipmentID,
+			UserID:            userID,
+			CollectionID:      collectionID,
+			WasteType:         "plastic",
+			EstimatedWeightKg: 40,
+			PriceOffered:      12.5,
+			Status:            "created",
+			PickupLatitude:    &latitude,
+			PickupLongitude:   &longitude,
+			PickupAddress:     &address,
+			DropoffLatitude:   &latitude,
+			DropoffLongitude:  &longitude,
+			DropoffAddress:    &address,
+			CreatedAt:         createdAt,
+		},
+		events.SubjectDriverAssigned: &events.DriverAssigned{ShipmentID: shipmentID, DriverID: driverID},
+		events.SubjectPriceConfirmed: statusChanged("price_confirmed"),
+		events.SubjectPickupStarted:  statusChanged("pickup_started"),
+		events.SubjectInTransit:      statusChanged("in_transit"),
+		events.SubjectDelivered:      statusChanged("delivered"),
+		events.SubjectCompleted:      statusChanged("completed"),
+		events.SubjectCancelled:      statusChanged("cancelled"),
+		events.SubjectDisputed:       statusChanged("disputed"),
+		events.SubjectResolved:       statusChanged("resolved"),
+		events.SubjectStatusChanged:  statusChanged("driver_assigned"),
+		events.SubjectTransitionProposed: &events.TransitionProposed{
+			ShipmentID:      shipmentID,
+			UserID:          userID,
+			DriverID:        driverID,
+			FromStatus:      "in_transit",
+			ProposedStatus:  "delivered",
+			Source:          "geofence",
+			GeofenceEventID: uuid.MustParse("0d1e2f3a-4b5c-4d6e-8f70-8192a3b4c5d6"),
+			TrackPointID:    &collectionID,
+			Latitude:        latitude,
+			Longitude:       longitude,
+			DistanceM:       12.3,
+			RadiusM:         50,
+			DwellSeconds:    &dwell,
+		},
+		events.SubjectETAUpdated: &events.ETAUpdated{
+			ShipmentID:        shipmentID,
+			UserID:            userID,
+			DriverID:          &driverID,
+			Status:            "in_transit",
+			Target:            "dropoff",
+			DistanceKm:        3.4,
+			DurationSeconds:   540,
+			ArrivesAt:         createdAt,
+			PreviousArrivesAt: &previous,
+		},
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	samples := samples()
+	for _, schema := range events.Schemas() {
+		schema := schema
+		t.Run(schema.Name(), func(t *testing.T) {
+			if schema.Version != 1 {
+				t.Fatalf("version = %d, the suite covers v1", schema.Version)
+			}
+			data, ok := samples[schema.Subject]
+			if !ok {
+				t.Fatalf("no sample payload for %s", schema.Subject)
+			}
+
+			event, err := events.New(schema.Subject, data)
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			if event.EventType != schema.Subject || event.Schema != schema.Name() {
+				t.Errorf("envelope = %s %s, want %s %s", event.EventType, event.Schema, schema.Subject, schema.Name())
+			}
+			if event.EventID == uuid.Nil {
+				t.Error("missing event_id")
+			}
+			if _, err := time.Parse(time.RFC3339, event.Timestamp); err != nil {
+				t.Errorf("timestamp %q: %v", event.Timestamp, err)
+			}
+
+			encoded, err := json.Marshal(event)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			parsed, err := events.Parse(encoded)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			decoded := schema.New()
+			if err := parsed.Decode(decoded); err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if !reflect.DeepEqual(decoded, data) {
+				t.Errorf("decoded %+v, want %+v", decoded, data)
+			}
+		})
+	}
+}
+
+// TestWireFields pins the keys of the data of every v1 subject. Consumers
+// already deployed read these, so a key may only be removed or renamed
+// together with a version bump; optional keys may be added.
+func TestWireFields(t *testing.T) {
+	statusChanged := []string{"collection_id", "driver_id", "metadata", "shipment_id", "status", "updated_by", "user_id", "waste_type", "weight_kg"}
+	tests := map[string][]string{
+		events.SubjectShipmentCreated: {
+			"collection_id", "created_at", "dropoff_address", "dropoff_latitude", "dropoff_longitude", "estimated_weight_kg",
+			"id", "pickup_address", "pickup_latitude", "pickup_longitude", "price_offered", "status", "user_id", "waste_type",
+		},
+		events.SubjectDriverAssigned: {"driver_id", "shipment_id"},
+		events.SubjectPriceConfirmed: statusChanged,
+		events.SubjectPickupStarted:  statusChanged,
+		events.SubjectInTransit:      statusChanged,
+		events.SubjectDelivered:      statusChanged,
+		events.SubjectCompleted:      statusChanged,
+		events.SubjectCancelled:      statusChanged,
+		events.SubjectDisputed:       statusChanged,
+		events.SubjectResolved:       statusChanged,
+		events.SubjectStatusChanged:  statusChanged,
+		events.SubjectTransitionProposed: {
+			"distance_m", "driver_id", "dwell_seconds", "from_status", "geofence_event_id", "latitude", "longitude",
+			"proposed_status", "radius_m", "shipment_id", "source", "track_point_id", "user_id",
+		},
+		events.SubjectETAUpdated: {
+			"arrives_at", "distance_km", "driver_id", "duration_seconds", "previous_arrives_at", "shipment_id", "status", "target", "user_id",
+		},
+	}
+
+	samples := samples()
+	for _, schema := range events.Schemas() {
+		if _, ok := tests[schema.Subject]; !ok {
+			t.Errorf("no wire fields pinned for %s", schema.Name())
+		}
+	}
+	for subject, want := range tests {
+		subject, want := subject, want
+		t.Run(subject, func(t *testing.T) {
+			event, err := events.New(subject, samples[subject])
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			var data map[string]json.RawMessage
+			if err := json.Unmarshal(event.Data, &data); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			for _, key := range want {
+				if _, ok := data[key]; !ok {
+					t.Errorf("missing key %q", key)
+				}
+			}
+		})
+	}
+}
+
+// TestDecodeLegacy decodes events as producers published them before the
+// schemas were named, and with keys a newer producer added
+func TestDecodeLegacy(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		into    events.Payload
+		want    events.Payload
+	}{
+		{
+			name: "unversioned shipment.created",
+			payload: `{"event_id":"7e57e57e-0000-4000-8000-000000000001","event_type":"shipment.created","timestamp":"2026-03-14T09:26:53Z",
+				"data":{"id":"9b2f4c1e-7d3a-4e5b-8f6a-1c2d3e4f5a6b","user_id":"1f0e2d3c-4b5a-4978-8695-a4b3c2d1e0f9",
+				"collection_id":"c0ffee00-1234-4abc-9def-0123456789ab","waste_type":"plastic","estimated_weight_kg":40,
+				"price_offered":12.5,"status":"created","created_at":"2026-03-14T09:26:53Z"}}`,
+			into: &events.ShipmentCreated{},
+			want: &events.ShipmentCreated{
+				ShipmentID:        shipmentID,
+				UserID:            userID,
+				CollectionID:      collectionID,
+				WasteType:         "plastic",
+				EstimatedWeightKg: 40,
+				PriceOffered:      12.5,
+				Status:            "created",
+				CreatedAt:         createdAt,
+			},
+		},
+		{
+			name: "unversioned shipment.completed without driver",
+			payload: `{"event_id":"7e57e57e-0000-4000-8000-000000000002","event_type":"shipment.completed","timestamp":"2026-03-14T09:26:53Z",
+				"data":{"shipment_id":"9b2f4c1e-7d3a-4e5b-8f6a-1c2d3e4f5a6b","status":"completed",
+				"updated_by":"1f0e2d3c-4b5a-4978-8695-a4b3c2d1e0f9","user_id":"1f0e2d3c-4b5a-4978-8695-a4b3c2d1e0f9",
+				"collection_id":"c0ffee00-1234-4abc-9def-0123456789ab","waste_type":"plastic","weight_kg":null}}`,
+			into: &events.StatusChanged{},
+			want: &events.StatusChanged{
+				ShipmentID:   shipmentID,
+				Status:       "completed",
+				UpdatedBy:    userID,
+				UserID:       userID,
+				CollectionID: collectionID,
+				WasteType:    "plastic",
+			},
+		},
+		{
+			name: "v1 shipment.driver.assigned with an added key",
+			payload: `{"event_id":"7e57e57e-0000-4000-8000-000000000003","event_type":"shipment.driver.assigned",
+				"schema":"shipment.driver.assigned.v1","timestamp":"2026-03-14T09:26:53Z",
+				"data":{"shipment_id":"9b2f4c1e-7d3a-4e5b-8f6a-1c2d3e4f5a6b","driver_id":"5a6b7c8d-9e0f-4a1b-8c2d-3e4f5a6b7c8d","vehicle_id":"v-7"}}`,
+			into: &events.DriverAssigned{},
+			want: &events.DriverAssigned{ShipmentID: shipmentID, DriverID: driverID},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := events.Parse([]byte(tt.payload))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if err := event.Decode(tt.into); err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if !reflect.DeepEqual(tt.into, tt.want) {
+				t.Errorf("decoded %+v, want %+v", tt.into, tt.want)
+			}
+		})
+	}
+}
+
+func TestCancellationMetadata(t *testing.T) {
+	payload := `{"event_type":"shipment.cancelled","schema":"shipment.cancelled.v1","data":{
+		"shipment_id":"9b2f4c1e-7d3a-4e5b-8f6a-1c2d3e4f5a6b","status":"cancelled","user_id":"1f0e2d3c-4b5a-4978-8695-a4b3c2d1e0f9",
+		"metadata":{"reason_code":"user_changed_mind","party":"user","fee":2.5,"fee_percent":20,"refund":10,"penalty_points":15,
+		"free_until":"2026-03-14T09:41:53Z"}}}`
+
+	event, err := events.Parse([]byte(payload))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	var shipment events.StatusChanged
+	if err := event.Decode(&shipment); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	metadata, err := shipment.Cancellation()
+	if err != nil {
+		t.Fatalf("Cancellation: %v", err)
+	}
+
+	freeUntil := "2026-03-14T09:41:53Z"
+	want := &events.CancellationMetadata{
+		ReasonCode:    "user_changed_mind",
+		Party:         "user",
+		Fee:           2.5,
+		FeePercent:    20,
+		Refund:        10,
+		PenaltyPoints: 15,
+		FreeUntil:     &freeUntil,
+	}
+	if !reflect.DeepEqual(metadata, want) {
+		t.Errorf("metadata = %+v, want %+v", metadata, want)
+	}
+}
+
+func TestDecodeRejectsUnsupportedVersions(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{"newer version", "shipment.completed.v2"},
+		{"older version", "shipment.completed.v0"},
+		{"schema of another subject", "shipment.cancelled.v1"},
+		{"unversioned name", "shipment.completed"},
+	}
+
+	data, err := json.Marshal(samples()[events.SubjectCompleted])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			event := &events.Event{EventID: uuid.New(), EventType: events.SubjectCompleted, Schema: tt.schema, Data: data}
+			err := event.Decode(&events.StatusChanged{})
+			if !errors.Is(err, events.ErrUnsupportedVersion) {
+				t.Errorf("Decode = %v, want %v", err, events.ErrUnsupportedVersion)
+			}
+		})
+	}
+}
+
+func TestSchemaLookup(t *testing.T) {
+	tests := []struct {
+		name    string
+		subject string
+		data    events.Payload
+		want    error
+	}{
+		{"unknown subject", "shipment.teleported", &events.StatusChanged{}, events.ErrUnknownSubject},
+		{"unregistered subject", events.SubjectContractDeployed, &events.StatusChanged{}, events.ErrUnknownSubject},
+		{"payload of another subject", events.SubjectCompleted, &events.DriverAssigned{}, events.ErrSchemaMismatch},
+		{"nil payload", events.SubjectDriverAssigned, nil, events.ErrSchemaMismatch},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := events.New(tt.subject, tt.data); !errors.Is(err, tt.want) {
+				t.Errorf("New = %v, want %v", err, tt.want)
+			}
+			event := &events.Event{EventType: tt.subject, Schema: tt.subject + ".v1", Data: json.RawMessage(`{}`)}
+			if err := event.Decode(tt.data); !errors.Is(err, tt.want) {
+				t.Errorf("Decode = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequiredFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		subject string
+		mutate  func(events.Payload)
+	}{
+		{"shipment.created without id", events.SubjectShipmentCreated, func(p events.Payload) { p.(*events.ShipmentCreated).ShipmentID = uuid.Nil }},
+		{"shipment.created without user_id", events.SubjectShipmentCreated, func(p events.Payload) { p.(*events.ShipmentCreated).UserID = uuid.Nil }},
+		{"shipment.created without waste_type", events.SubjectShipmentCreated, func(p events.Payload) { p.(*events.ShipmentCreated).WasteType = "" }},
+		{"shipment.created without status", events.SubjectShipmentCreated, func(p events.Payload) { p.(*events.ShipmentCreated).Status = "" }},
+		{"status change without shipment_id", events.SubjectCompleted, func(p events.Payload) { p.(*events.StatusChanged).ShipmentID = uuid.Nil }},
+		{"status change without user_id", events.SubjectCompleted, func(p events.Payload) { p.(*events.StatusChanged).UserID = uuid.Nil }},
+		{"status change without status", events.SubjectCancelled, func(p events.Payload) { p.(*events.StatusChanged).Status = "" }},
+		{"driver assignment without shipment_id", events.SubjectDriverAssigned, func(p events.Payload) { p.(*events.DriverAssigned).ShipmentID = uuid.Nil }},
+		{"driver assignment without driver_id", events.SubjectDriverAssigned, func(p events.Payload) { p.(*events.DriverAssigned).DriverID = uuid.Nil }},
+		{"proposal without shipment_id", events.SubjectTransitionProposed, func(p events.Payload) { p.(*events.TransitionProposed).ShipmentID = uuid.Nil }},
+		{"proposal without driver_id", events.SubjectTransitionProposed, func(p events.Payload) { p.(*events.TransitionProposed).DriverID = uuid.Nil }},
+		{"proposal without from_status", events.SubjectTransitionProposed, func(p events.Payload) { p.(*events.TransitionProposed).FromStatus = "" }},
+		{"proposal without proposed_status", events.SubjectTransitionProposed, func(p events.Payload) { p.(*events.TransitionProposed).ProposedStatus = "" }},
+		{"eta without shipment_id", events.SubjectETAUpdated, func(p events.Payload) { p.(*events.ETAUpdated).ShipmentID = uuid.Nil }},
+		{"eta without target", events.SubjectETAUpdated, func(p events.Payload) { p.(*events.ETAUpdated).Target = "" }},
+		{"eta without arrives_at", events.SubjectETAUpdated, func(p events.Payload) { p.(*events.ETAUpdated).ArrivesAt = time.Time{} }},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			data := samples()[tt.subject]
+			tt.mutate(data)
+
+			if _, err := events.New(tt.subject, data); !errors.Is(err, events.ErrInvalidPayload) {
+				t.Errorf("New = %v, want %v", err, events.ErrInvalidPayload)
+			}
+
+			raw, err := json.Marshal(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			schema, _ := events.Lookup(tt.subject)
+			event := &events.Event{EventID: uuid.New(), EventType: tt.subject, Schema: schema.Name(), Data: raw}
+			if err := event.Decode(schema.New()); !errors.Is(err, events.ErrInvalidPayload) {
+				t.Errorf("Decode = %v, want %v", err, events.ErrInvalidPayload)
+			}
+		})
+	}
+}
+
+func TestDecodeMalformedData(t *testing.T) {
+	event := &events.Event{EventType: events.SubjectDriverAssigned, Schema: "shipment.driver.assigned.v1", Data: json.RawMessage(`{"shipment_id":42}`)}
+	err := event.Decode(&events.DriverAssigned{})
+	if err == nil || errors.Is(err, events.ErrInvalidPayload) || errors.Is(err, events.ErrUnsupportedVersion) {
+		t.Errorf("Decode = %v, want a decoding error", err)
+	}
+
+	if _, err := events.Parse([]byte(`{"event_type":`)); err == nil {
+		t.Error("Parse accepted a truncated envelope")
+	}
+}
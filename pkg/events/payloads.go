@@ -0,0 +1,173 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// errMissingShipment is returned by payloads without the shipment they are about
+var errMissingShipment = errors.New("missing shipment_id")
+
+// ShipmentCreated is the data of shipment.created events, the created
+// shipment
+type ShipmentCreated struct {
+	ShipmentID        uuid.UUID `json:"id"`
+	UserID            uuid.UUID `json:"user_id"`
+	CollectionID      uuid.UUID `json:"collection_id"`
+	WasteType         string    `json:"waste_type"`
+	EstimatedWeightKg float64   `json:"estimated_weight_kg"`
+	PriceOffered      float64   `json:"price_offered"`
+	Status            string    `json:"status"`
+	PickupLatitude    *float64  `json:"pickup_latitude,omitempty"`
+	PickupLongitude   *float64  `json:"pickup_longitude,omitempty"`
+	PickupAddress     *string   `json:"pickup_address,omitempty"`
+	DropoffLatitude   *float64  `json:"dropoff_latitude,omitempty"`
+	DropoffLongitude  *float64  `json:"dropoff_longitude,omitempty"`
+	DropoffAddress    *string   `json:"dropoff_address,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// Validate implements Payload
+func (e *ShipmentCreated) Validate() error {
+	switch {
+	case e.ShipmentID == uuid.Nil:
+		return errors.New("missing id")
+	case e.UserID == uuid.Nil:
+		return errors.New("missing user_id")
+	case e.WasteType == "":
+		return errors.New("missing waste_type")
+	case e.Status == "":
+		return errors.New("missing status")
+	}
+	return nil
+}
+
+// StatusChanged is the data of the events published when a shipment changes
+// status, on the subject of its new status
+type StatusChanged struct {
+	ShipmentID   uuid.UUID              `json:"shipment_id"`
+	Status       string                 `json:"status"`
+	UpdatedBy    uuid.UUID              `json:"updated_by"`
+	UserID       uuid.UUID              `json:"user_id"`
+	CollectionID uuid.UUID              `json:"collection_id"`
+	DriverID     *uuid.UUID             `json:"driver_id,omitempty"` // Assigned driver, once there is one
+	WasteType    string                 `json:"waste_type"`
+	WeightKg     *float64               `json:"weight_kg"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Validate implements Payload
+func (e *StatusChanged) Validate() error {
+	switch {
+	case e.ShipmentID == uuid.Nil:
+		return errMissingShipment
+	case e.UserID == uuid.Nil:
+		return errors.New("missing user_id")
+	case e.Status == "":
+		return errors.New("missing status")
+	}
+	return nil
+}
+
+// CancellationMetadata is the metadata of shipment.cancelled events
+type CancellationMetadata struct {
+	ReasonCode    string  `json:"reason_code"`
+	Party         string  `json:"party"`
+	Fee           float64 `json:"fee"`
+	FeePercent    float64 `json:"fee_percent"`
+	Refund        float64 `json:"refund"`
+	PenaltyPoints int     `json:"penalty_points"`
+	FreeUntil     *string `json:"free_until,omitempty"`
+	Note          *string `json:"note,omitempty"`
+}
+
+// Cancellation decodes the metadata of a cancellation
+func (e *StatusChanged) Cancellation() (*CancellationMetadata, error) {
+	raw, err := json.Marshal(e.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	var metadata CancellationMetadata
+	if err := json.Unmarshal(raw, &metadata); err != nil {
+		return nil, fmt.Errorf("invalid cancellation metadata: %w", err)
+	}
+	return &metadata, nil
+}
+
+// DriverAssigned is the data of shipment.driver.assigned events
+type DriverAssigned struct {
+	ShipmentID uuid.UUID `json:"shipment_id"`
+	DriverID   uuid.UUID `json:"driver_id"`
+}
+
+// Validate implements Payload
+func (e *DriverAssigned) Validate() error {
+	switch {
+	case e.ShipmentID == uuid.Nil:
+		return errMissingShipment
+	case e.DriverID == uuid.Nil:
+		return errors.New("missing driver_id")
+	}
+	return nil
+}
+
+// TransitionProposed is the data of shipment.transition.proposed events,
+// describing the geofence the driver triggered
+type TransitionProposed struct {
+	ShipmentID      uuid.UUID  `json:"shipment_id"`
+	UserID          uuid.UUID  `json:"user_id"`
+	DriverID        uuid.UUID  `json:"driver_id"`
+	FromStatus      string     `json:"from_status"`
+	ProposedStatus  string     `json:"proposed_status"`
+	Source          string     `json:"source"`
+	GeofenceEventID uuid.UUID  `json:"geofence_event_id"`
+	TrackPointID    *uuid.UUID `json:"track_point_id,omitempty"`
+	Latitude        float64    `json:"latitude"`
+	Longitude       float64    `json:"longitude"`
+	DistanceM       float64    `json:"distance_m"`
+	RadiusM         float64    `json:"radius_m"`
+	DwellSeconds    *int       `json:"dwell_seconds,omitempty"`
+}
+
+// Validate implements Payload
+func (e *TransitionProposed) Validate() error {
+	switch {
+	case e.ShipmentID == uuid.Nil:
+		return errMissingShipment
+	case e.DriverID == uuid.Nil:
+		return errors.New("missing driver_id")
+	case e.FromStatus == "" || e.ProposedStatus == "":
+		return errors.New("missing from_status or proposed_status")
+	}
+	return nil
+}
+
+// ETAUpdated is the data of shipment.eta.updated events
+type ETAUpdated struct {
+	ShipmentID        uuid.UUID  `json:"shipment_id"`
+	UserID            uuid.UUID  `json:"user_id"`
+	DriverID          *uuid.UUID `json:"driver_id"`
+	Status            string     `json:"status"`
+	Target            string     `json:"target"`
+	DistanceKm        float64    `json:"distance_km"`
+	DurationSeconds   int        `json:"duration_seconds"`
+	ArrivesAt         time.Time  `json:"arrives_at"`
+	PreviousArrivesAt *time.Time `json:"previous_arrives_at,omitempty"`
+}
+
+// Validate implements Payload
+func (e *ETAUpdated) Validate() error {
+	switch {
+	case e.ShipmentID == uuid.Nil:
+		return errMissingShipment
+	case e.Target == "":
+		return errors.New("missing target")
+	case e.ArrivesAt.IsZero():
+		return errors.New("missing arrives_at")
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Schema errors
+var (
+	ErrUnknownSubject     = errors.New("no schema registered for subject")
+	ErrSchemaMismatch     = errors.New("payload does not match the schema of the subject")
+	ErrInvalidPayload     = errors.New("invalid event payload")
+	ErrUnsupportedVersion = errors.New("unsupported event schema version")
+)
+
+// Payload is the typed data of an event
+type Payload interface {
+	// Validate returns an error if fields consumers rely on are missing
+	Validate() error
+}
+
+// Schema is the versioned schema of the data of the events of a subject.
+// Adding optional fields keeps the version; removing, renaming or changing
+// the meaning of a field bumps it.
+type Schema struct {
+	Subject string
+	Version int
+	payload reflect.Type
+}
+
+// Name returns the versioned name of the schema, e.g. shipment.created.v1
+func (s Schema) Name() string {
+	return fmt.Sprintf("%s.v%d", s.Subject, s.Version)
+}
+
+// New returns an empty payload of the schema
+func (s Schema) New() Payload {
+	return reflect.New(s.payload).Interface().(Payload)
+}
+
+var registry = map[string]Schema{}
+
+func init() {
+	register(SubjectShipmentCreated, 1, &ShipmentCreated{})
+	register(SubjectDriverAssigned, 1, &DriverAssigned{})
+	for _, subject := range []string{
+		SubjectPriceConfirmed,
+		SubjectPickupStarted,
+		SubjectInTransit,
+		SubjectDelivered,
+		SubjectCompleted,
+		SubjectCancelled,
+		SubjectDisputed,
+		SubjectResolved,
+		SubjectStatusChanged,
+	} {
+		register(subject, 1, &StatusChanged{})
+	}
+	register(SubjectTransitionProposed, 1, &TransitionProposed{})
+	register(SubjectETAUpdated, 1, &ETAUpdated{})
+}
+
+// register records the schema of subject, whose payloads are of the type
+// payload points to
+func register(subject string, version int, payload Payload) {
+	if _, ok := registry[subject]; ok {
+		panic("events: schema of " + subject + " registered twice")
+	}
+	registry[subject] = Schema{
+		Subject: subject,
+		Version: version,
+		payload: reflect.TypeOf(payload).Elem(),
+	}
+}
+
+// Lookup returns the schema registered for subject
+func Lookup(subject string) (Schema, bool) {
+	schema, ok := registry[subject]
+	return schema, ok
+}
+
+// Schemas returns every registered schema, by subject
+func Schemas() []Schema {
+	schemas := make([]Schema, 0, len(registry))
+	for _, schema := range registry {
+		schemas = append(schemas, schema)
+	}
+	sort.Slice(schemas, func(i, j int) bool { return schemas[i].Subject < schemas[j].Subject })
+	return schemas
+}
+
+// schemaFor returns the schema of subject, checking payload is of its type
+func schemaFor(subject string, payload Payload) (Schema, error) {
+	schema, ok := registry[subject]
+	if !ok {
+		return Schema{}, fmt.Errorf("%w: %s", ErrUnknownSubject, subject)
+	}
+	if t := reflect.TypeOf(payload); t == nil || t.Kind() != reflect.Pointer || t.Elem() != schema.payload {
+		return Schema{}, fmt.Errorf("%w: %s takes *%s, got %T", ErrSchemaMismatch, schema.Name(), schema.payload.Name(), payload)
+	}
+	return schema, nil
+}
@@ -0,0 +1,39 @@
+package events
+
+// SubjectShipments matches every event published by the shipment tracker
+const SubjectShipments = "shipment.>"
+
+const (
+	// SubjectShipmentCreated is published when a new shipment is created
+	SubjectShipmentCreated = "shipment.created"
+	// SubjectPriceConfirmed is published when a price is confirmed
+	SubjectPriceConfirmed = "shipment.price.confirmed"
+	// SubjectDriverAssigned is published when a driver is assigned
+	SubjectDriverAssigned = "shipment.driver.assigned"
+	// SubjectPickupStarted is published when pickup starts
+	SubjectPickupStarted = "shipment.pickup.started"
+	// SubjectPickupConfirmed is published when pickup is confirmed
+	SubjectPickupConfirmed = "shipment.pickup.confirmed"
+	// SubjectInTransit is published when shipment is in transit
+	SubjectInTransit = "shipment.in.transit"
+	// SubjectDelivered is published when shipment is delivered
+	SubjectDelivered = "shipment.delivered"
+	// SubjectCompleted is published when shipment is completed
+	SubjectCompleted = "shipment.completed"
+	// SubjectCancelled is published when shipment is cancelled
+	SubjectCancelled = "shipment.cancelled"
+	// SubjectDisputed is published when a dispute is raised
+	SubjectDisputed = "shipment.disputed"
+	// SubjectResolved is published when a dispute is resolved
+	SubjectResolved = "shipment.resolved"
+	// SubjectStatusChanged is published for the other status changes
+	SubjectStatusChanged = "shipment.status.changed"
+	// SubjectTransitionProposed is published when a geofence proposes a
+	// transition for the parties to confirm
+	SubjectTransitionProposed = "shipment.transition.proposed"
+	// SubjectETAUpdated is published when the estimated arrival of the driver
+	// changes noticeably
+	SubjectETAUpdated = "shipment.eta.updated"
+	// SubjectContractDeployed is published when a smart contract is deployed
+	SubjectContractDeployed = "shipment.contract.deployed"
+)
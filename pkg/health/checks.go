@@ -0,0 +1,57 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/smartwaste/pkg/postgres"
+)
+
+// Database checks that PostgreSQL answers
+func Database(db *sqlx.DB) Check {
+	return func(ctx context.Context) (string, error) {
+		if err := db.PingContext(ctx); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%d open connections", db.Stats().OpenConnections), nil
+	}
+}
+
+// Migrations checks that the schema of every migration is in the database.
+// Once it is, the check is not run again.
+func Migrations(db *sqlx.DB, migrations []postgres.Migration) Check {
+	var applied atomic.Bool
+	latest := "no migrations"
+	if len(migrations) > 0 {
+		latest = migrations[len(migrations)-1].Name
+	}
+
+	return func(ctx context.Context) (string, error) {
+		if applied.Load() {
+			return latest, nil
+		}
+		pending, err := postgres.PendingMigrations(ctx, db, migrations)
+		if err != nil {
+			return "", err
+		}
+		if len(pending) > 0 {
+			return "", fmt.Errorf("%d pending: %s", len(pending), strings.Join(pending, ", "))
+		}
+		applied.Store(true)
+		return latest, nil
+	}
+}
+
+// Connection checks the state of a broker connection
+func Connection(connected func() bool) Check {
+	return func(ctx context.Context) (string, error) {
+		if !connected() {
+			return "", errors.New("disconnected")
+		}
+		return "connected", nil
+	}
+}
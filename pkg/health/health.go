@@ -0,0 +1,145 @@
+// Package health reports the liveness and readiness of the services, with the
+// status of each dependency, for the orchestrator probes
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Statuses of a dependency
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Statuses of a service
+const (
+	StatusAlive       = "alive"
+	StatusReady       = "ready"
+	StatusDegraded    = "degraded"    // Ready, with an optional dependency down
+	StatusUnavailable = "unavailable" // A required dependency is down
+)
+
+// Check checks a dependency, returning an error if it is down. The detail is
+// reported along with the status.
+type Check func(ctx context.Context) (detail string, err error)
+
+// Dependency is the status of a dependency
+type Dependency struct {
+	Status    string  `json:"status"`
+	Required  bool    `json:"required"`
+	Detail    string  `json:"detail,omitempty"`
+	Error     string  `json:"error,omitempty"`
+	LatencyMs float64 `json:"latency_ms"`
+}
+
+// Report is the status of a service and its dependencies
+type Report struct {
+	Status        string                `json:"status"`
+	Dependencies  map[string]Dependency `json:"dependencies,omitempty"`
+	UptimeSeconds int64                 `json:"uptime_seconds"`
+	Timestamp     time.Time             `json:"timestamp"`
+}
+
+// HTTPStatus returns the status code of the report: 503 while a required
+// dependency is down, so the instance is taken out of rotation
+func (r *Report) HTTPStatus() int {
+	if r.Status == StatusUnavailable {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
+
+type check struct {
+	name     string
+	required bool
+	run      Check
+}
+
+// Checker runs the checks of the dependencies of a service
+type Checker struct {
+	timeout time.Duration
+	started time.Time
+	checks  []check
+}
+
+// NewChecker creates a Checker whose checks each get timeout to answer
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout, started: time.Now()}
+}
+
+// Require registers a dependency the service cannot serve without
+func (c *Checker) Require(name string, run Check) {
+	c.checks = append(c.checks, check{name: name, required: true, run: run})
+}
+
+// Observe registers a dependency the service keeps serving without, in a
+// degraded mode
+func (c *Checker) Observe(name string, run Check) {
+	c.checks = append(c.checks, check{name: name, run: run})
+}
+
+// Live reports the process alive, without checking its dependencies: a
+// dependency being down is no reason to restart the service
+func (c *Checker) Live() *Report {
+	return c.report(StatusAlive)
+}
+
+// Ready checks every dependency concurrently
+func (c *Checker) Ready(ctx context.Context) *Report {
+	report := c.report(StatusReady)
+	report.Dependencies = make(map[string]Dependency, len(c.checks))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, chk := range c.checks {
+		wg.Add(1)
+		go func(chk check) {
+			defer wg.Done()
+			dependency := c.run(ctx, chk)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Dependencies[chk.name] = dependency
+			if dependency.Status == StatusDown {
+				if chk.required {
+					report.Status = StatusUnavailable
+				} else if report.Status == StatusReady {
+					report.Status = StatusDegraded
+				}
+			}
+		}(chk)
+	}
+	wg.Wait()
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, chk check) Dependency {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	detail, err := chk.run(ctx)
+	dependency := Dependency{
+		Status:    StatusUp,
+		Required:  chk.required,
+		Detail:    detail,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		dependency.Status = StatusDown
+		dependency.Error = err.Error()
+	}
+	return dependency
+}
+
+func (c *Checker) report(status string) *Report {
+	return &Report{
+		Status:        status,
+		UptimeSeconds: int64(time.Since(c.started).Seconds()),
+		Timestamp:     time.Now().UTC(),
+	}
+}
@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	sqlComment  = regexp.MustCompile(`--[^\n]*`)
+	createTable = regexp.MustCompile(`(?is)^CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?([\w.]+)`)
+	createIndex = regexp.MustCompile(`(?is)^CREATE\s+(?:UNIQUE\s+)?INDEX\s+(?:CONCURRENTLY\s+)?(?:IF\s+NOT\s+EXISTS\s+)?([\w.]+)`)
+	alterTable  = regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+(?:IF\s+EXISTS\s+)?(?:ONLY\s+)?([\w.]+)`)
+	addColumn   = regexp.MustCompile(`(?is)\bADD\s+COLUMN\s+(?:IF\s+NOT\s+EXISTS\s+)?(\w+)`)
+	dropTable   = regexp.MustCompile(`(?is)^DROP\s+(?:TABLE|INDEX)\s+(?:CONCURRENTLY\s+)?(?:IF\s+EXISTS\s+)?([\w.]+)`)
+)
+
+// Migration is a schema migration and the tables, indexes and columns it
+// creates, by which it is told applied. The migrations are applied outside
+// the services, when the database is initialized, so the schema is all there
+// is to check.
+type Migration struct {
+	Name      string
+	Relations []string    // Tables and indexes
+	Columns   [][2]string // Table and column added to it
+}
+
+// LoadMigrations parses the .sql migrations of fsys, in the order of their
+// names. A table or index dropped by a migration is no longer expected from
+// the migrations before it.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	migrations := make([]Migration, 0, len(names))
+	for _, name := range names {
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", name, err)
+		}
+		migrations = append(migrations, Migration{Name: name})
+		current := &migrations[len(migrations)-1]
+
+		for _, statement := range strings.Split(sqlComment.ReplaceAllString(string(content), ""), ";") {
+			statement = strings.TrimSpace(statement)
+			if m := createTable.FindStringSubmatch(statement); m != nil {
+				current.Relations = append(current.Relations, strings.ToLower(m[1]))
+			} else if m := createIndex.FindStringSubmatch(statement); m != nil {
+				current.Relations = append(current.Relations, strings.ToLower(m[1]))
+			} else if m := alterTable.FindStringSubmatch(statement); m != nil {
+				for _, column := range addColumn.FindAllStringSubmatch(statement, -1) {
+					current.Columns = append(current.Columns, [2]string{strings.ToLower(m[1]), strings.ToLower(column[1])})
+				}
+			} else if m := dropTable.FindStringSubmatch(statement); m != nil {
+				for i := range migrations {
+					migrations[i].Relations = without(migrations[i].Relations, strings.ToLower(m[1]))
+				}
+			}
+		}
+	}
+	return migrations, nil
+}
+
+func without(names []string, name string) []string {
+	kept := names[:0]
+	for _, n := range names {
+		if n != name {
+			kept = append(kept, n)
+		}
+	}
+	return kept
+}
+
+// PendingMigrations returns the names of the migrations some of whose
+// tables, indexes or columns are missing from the database
+func PendingMigrations(ctx context.Context, db *sqlx.DB, migrations []Migration) ([]string, error) {
+	var relations, tables, columns []string
+	for _, migration := range migrations {
+		relations = append(relations, migration.Relations...)
+		for _, column := range migration.Columns {
+			tables = append(tables, column[0])
+			columns = append(columns, column[1])
+		}
+	}
+
+	missing := make(map[string]bool)
+	var names []string
+	err := db.SelectContext(ctx, &names,
+		`SELECT name FROM unnest($1::text[]) AS name WHERE to_regclass(name) IS NULL`, pq.Array(relations))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check migrated relations: %w", err)
+	}
+	for _, name := range names {
+		missing[name] = true
+	}
+
+	var absent []struct {
+		Table  string `db:"table_name"`
+		Column string `db:"column_name"`
+	}
+	err = db.SelectContext(ctx, &absent, `
+		SELECT c.table_name, c.column_name
+		FROM unnest($1::text[], $2::text[]) AS c(table_name, column_name)
+		WHERE NOT EXISTS (
+			SELECT 1 FROM information_schema.columns i
+			WHERE i.table_schema = current_schema() AND i.table_name = c.table_name AND i.column_name = c.column_name
+		)`, pq.Array(tables), pq.Array(columns))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check migrated columns: %w", err)
+	}
+	for _, column := range absent {
+		missing[column.Table+"."+column.Column] = true
+	}
+
+	var pending []string
+	for _, migration := range migrations {
+		applied := true
+		for _, relation := range migration.Relations {
+			applied = applied && !missing[relation]
+		}
+		for _, column := range migration.Columns {
+			applied = applied && !missing[column[0]+"."+column[1]]
+		}
+		if !applied {
+			pending = append(pending, migration.Name)
+		}
+	}
+	return pending, nil
+}
@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+// Default connection pool settings of the services
+const (
+	DefaultMaxOpenConns    = 25
+	DefaultMaxIdleConns    = 5
+	DefaultConnMaxLifetime = 5 * time.Minute
+	DefaultConnMaxIdleTime = 1 * time.Minute
+	DefaultQueryTimeout    = 30 * time.Second
+)
+
+// Config holds the PostgreSQL connection settings of a service
+type Config struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	DBName   string
+	SSLMode  string
+
+	// Connection pool; zero values fall back to the defaults above
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+
+	// QueryTimeout is the statement_timeout of the connections, so the
+	// server cancels a query running longer and frees its connection; 0
+	// disables it
+	QueryTimeout time.Duration
+}
+
+// GetDSN returns the PostgreSQL connection string
+func (c *Config) GetDSN() string {
+	dsn := "host=" + c.Host +
+		" port=" + c.Port +
+		" user=" + c.User +
+		" password=" + c.Password +
+		" dbname=" + c.DBName +
+		" sslmode=" + c.SSLMode
+	if c.QueryTimeout > 0 {
+		// lib/pq sends unknown parameters to the server as run-time parameters
+		dsn += " statement_timeout=" + strconv.FormatInt(c.QueryTimeout.Milliseconds(), 10)
+	}
+	return dsn
+}
+
+// Open connects to PostgreSQL with the pool settings of cfg, checking the
+// connection
+func Open(cfg *Config) (*sqlx.DB, error) {
+	db, err := sqlx.Connect("postgres", cfg.GetDSN())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	db.SetMaxOpenConns(orDefault(cfg.MaxOpenConns, DefaultMaxOpenConns))
+	db.SetMaxIdleConns(orDefault(cfg.MaxIdleConns, DefaultMaxIdleConns))
+	db.SetConnMaxLifetime(orDefault(cfg.ConnMaxLifetime, DefaultConnMaxLifetime))
+	db.SetConnMaxIdleTime(orDefault(cfg.ConnMaxIdleTime, DefaultConnMaxIdleTime))
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+	return db, nil
+}
+
+func orDefault[T int | time.Duration](value, fallback T) T {
+	if value <= 0 {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,153 @@
+// Package response writes the JSON envelope of the API responses of the
+// services.
+package response
+
+import "net/http"
+
+// Context writes JSON responses, such as a *gin.Context
+type Context interface {
+	JSON(code int, obj interface{})
+}
+
+// APIResponse represents a standard API response
+type APIResponse struct {
+	Success bool        `json:"success"`
+	Data    interface{} `json:"data,omitempty"`
+	Error   *APIError   `json:"error,omitempty"`
+	Meta    *Pagination `json:"meta,omitempty"`
+}
+
+// APIError represents an API error
+type APIError struct {
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"` // A string, or the field errors of a failed validation
+}
+
+// Pagination represents pagination metadata
+type Pagination struct {
+	Page       int    `json:"page,omitempty"`
+	PerPage    int    `json:"per_page"`
+	Total      int    `json:"total"`
+	TotalPages int    `json:"total_pages"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// NewPagination creates pagination metadata, deriving the page count from total
+func NewPagination(page, perPage, total int) *Pagination {
+	totalPages := 0
+	if perPage > 0 {
+		totalPages = (total + perPage - 1) / perPage
+	}
+	return &Pagination{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+}
+
+// SuccessResponse sends a successful response
+func SuccessResponse(c Context, statusCode int, data interface{}) {
+	c.JSON(statusCode, APIResponse{
+		Success: true,
+		Data:    data,
+	})
+}
+
+// SuccessResponseWithPagination sends a successful response with pagination
+func SuccessResponseWithPagination(c Context, data interface{}, pagination *Pagination) {
+	c.JSON(http.StatusOK, APIResponse{
+		Success: true,
+		Data:    data,
+		Meta:    pagination,
+	})
+}
+
+// ErrorResponse sends an error response
+func ErrorResponse(c Context, statusCode int, code, message string) {
+	c.JSON(statusCode, APIResponse{
+		Success: false,
+		Error: &APIError{
+			Code:    code,
+			Message: message,
+		},
+	})
+}
+
+// ErrorResponseWithDetails sends an error response with additional details
+func ErrorResponseWithDetails(c Context, statusCode int, code, message string, details interface{}) {
+	c.JSON(statusCode, APIResponse{
+		Success: false,
+		Error: &APIError{
+			Code:    code,
+			Message: message,
+			Details: details,
+		},
+	})
+}
+
+// Common error codes
+const (
+	ErrCodeBadRequest       = "BAD_REQUEST"
+	ErrCodeUnauthorized     = "UNAUTHORIZED"
+	ErrCodeForbidden        = "FORBIDDEN"
+	ErrCodeNotFound         = "NOT_FOUND"
+	ErrCodeConflict         = "CONFLICT"
+	ErrCodeInternalError    = "INTERNAL_ERROR"
+	ErrCodeValidationFailed = "VALIDATION_FAILED"
+	ErrCodeUnprocessable    = "UNPROCESSABLE_ENTITY"
+	ErrCodeRateLimited      = "RATE_LIMITED"
+)
+
+// BadRequest sends a 400 Bad Request response
+func BadRequest(c Context, message string) {
+	ErrorResponse(c, http.StatusBadRequest, ErrCodeBadRequest, message)
+}
+
+// Unauthorized sends a 401 Unauthorized response
+func Unauthorized(c Context, message string) {
+	ErrorResponse(c, http.StatusUnauthorized, ErrCodeUnauthorized, message)
+}
+
+// Forbidden sends a 403 Forbidden response
+func Forbidden(c Context, message string) {
+	ErrorResponse(c, http.StatusForbidden, ErrCodeForbidden, message)
+}
+
+// NotFound sends a 404 Not Found response
+func NotFound(c Context, message string) {
+	ErrorResponse(c, http.StatusNotFound, ErrCodeNotFound, message)
+}
+
+// InternalError sends a 500 Internal Server Error response
+func InternalError(c Context, message string) {
+	ErrorResponse(c, http.StatusInternalServerError, ErrCodeInternalError, message)
+}
+
+// ValidationError sends a 400 response for validation errors
+func ValidationError(c Context, message string) {
+	ErrorResponse(c, http.StatusBadRequest, ErrCodeValidationFailed, message)
+}
+
+// ValidationErrors sends a 400 response for validation errors, detailing the
+// failed fields
+func ValidationErrors(c Context, message string, fields interface{}) {
+	ErrorResponseWithDetails(c, http.StatusBadRequest, ErrCodeValidationFailed, message, fields)
+}
+
+// Conflict sends a 409 Conflict response
+func Conflict(c Context, message string) {
+	ErrorResponse(c, http.StatusConflict, ErrCodeConflict, message)
+}
+
+// UnprocessableEntity sends a 422 response for well-formed requests the data
+// does not allow, such as references to missing records
+func UnprocessableEntity(c Context, message string) {
+	ErrorResponse(c, http.StatusUnprocessableEntity, ErrCodeUnprocessable, message)
+}
+
+// TooManyRequests sends a 429 Too Many Requests response
+func TooManyRequests(c Context, message string) {
+	ErrorResponse(c, http.StatusTooManyRequests, ErrCodeRateLimited, message)
+}
@@ -0,0 +1,262 @@
+// Package validation registers the custom validators of the request DTOs of
+// the services and translates validation failures into structured field
+// errors
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Custom validation tags
+const (
+	TagLatitude  = "latitude"   // A number between -90 and 90
+	TagLongitude = "longitude"  // A number between -180 and 180
+	TagPhone     = "phone"      // 7 to 15 digits, optionally with a leading + and separators
+	TagWasteType = "waste_type" // One of WasteTypes
+
+	// TagWasteTypeOrAny also accepts "*", for rules matching any waste type
+	TagWasteTypeOrAny = "waste_type_or_any"
+)
+
+// WasteTypes are the waste types bins, collections and shipments may carry
+var WasteTypes = []string{
+	"plastic",
+	"paper",
+	"glass",
+	"metal",
+	"organic",
+	"electronic",
+	"textile",
+	"general",
+	"mixed",
+}
+
+var (
+	phoneDigits     = regexp.MustCompile(`^\+?[0-9]{7,15}$`)
+	phoneSeparators = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "")
+)
+
+// FieldError describes why a field of a request failed validation
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Register registers the custom validators on v and names fields after their
+// JSON keys in validation errors
+func Register(v *validator.Validate) error {
+	v.RegisterTagNameFunc(jsonFieldName)
+
+	validators := map[string]validator.Func{
+		TagLatitude:  isLatitude,
+		TagLongitude: isLongitude,
+		TagPhone:     isPhone,
+		TagWasteType: isWasteType,
+	}
+	for tag, fn := range validators {
+		if err := v.RegisterValidation(tag, fn); err != nil {
+			return fmt.Errorf("registering %s validator: %w", tag, err)
+		}
+	}
+	v.RegisterAlias(TagWasteTypeOrAny, TagWasteType+"|eq=*")
+	return nil
+}
+
+// IsLatitude reports whether lat is a valid latitude
+func IsLatitude(lat float64) bool {
+	return lat >= -90 && lat <= 90
+}
+
+// IsLongitude reports whether lng is a valid longitude
+func IsLongitude(lng float64) bool {
+	return lng >= -180 && lng <= 180
+}
+
+// IsPhone reports whether phone looks like a phone number
+func IsPhone(phone string) bool {
+	return phoneDigits.MatchString(phoneSeparators.Replace(phone))
+}
+
+// IsWasteType reports whether wasteType is one of WasteTypes
+func IsWasteType(wasteType string) bool {
+	for _, known := range WasteTypes {
+		if wasteType == known {
+			return true
+		}
+	}
+	return false
+}
+
+func isLatitude(fl validator.FieldLevel) bool {
+	return fl.Field().CanFloat() && IsLatitude(fl.Field().Float())
+}
+
+func isLongitude(fl validator.FieldLevel) bool {
+	return fl.Field().CanFloat() && IsLongitude(fl.Field().Float())
+}
+
+func isPhone(fl validator.FieldLevel) bool {
+	return fl.Field().Kind() == reflect.String && IsPhone(fl.Field().String())
+}
+
+func isWasteType(fl validator.FieldLevel) bool {
+	return fl.Field().Kind() == reflect.String && IsWasteType(fl.Field().String())
+}
+
+// jsonFieldName names a struct field after its JSON key
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
+
+// Translate turns the error of binding or validating a request into field
+// errors. It returns nil when err does not concern particular fields, such as
+// malformed JSON.
+func Translate(err error) []FieldError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, FieldError{
+				Field:   fieldPath(fe),
+				Rule:    fe.Tag(),
+				Message: message(fe),
+			})
+		}
+		return fields
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: fmt.Sprintf("must be a %s, not a %s", jsonType(typeErr.Type), typeErr.Value),
+		}}
+	}
+	return nil
+}
+
+// Summary is a one-line message for fields, such as "latitude must be
+// between -90 and 90 (and 2 more)"
+func Summary(fields []FieldError) string {
+	if len(fields) == 0 {
+		return "Validation failed"
+	}
+	summary := fields[0].Field + " " + fields[0].Message
+	if len(fields) > 1 {
+		summary += fmt.Sprintf(" (and %d more)", len(fields)-1)
+	}
+	return summary
+}
+
+// fieldPath is the path of the field in the request, without the name of the
+// top-level struct, such as "rules[2].waste_type"
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if _, path, ok := strings.Cut(namespace, "."); ok {
+		return path
+	}
+	return namespace
+}
+
+// message explains a failed rule
+func message(fe validator.FieldError) string {
+	param := fe.Param()
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "required_with":
+		return "is required along with " + param
+	case "required_without":
+		return "is required unless " + param + " is set"
+	case "email":
+		return "must be a valid email address"
+	case "url":
+		return "must be a valid URL"
+	case "uuid", "uuid4":
+		return "must be a UUID"
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(param), ", ")
+	case "len":
+		if isCounted(fe.Kind()) {
+			return "must contain exactly " + param + " items"
+		}
+		return "must be exactly " + param + " characters long"
+	case "min":
+		if fe.Kind() == reflect.String {
+			return "must be at least " + param + " characters long"
+		}
+		if isCounted(fe.Kind()) {
+			return "must contain at least " + param + " items"
+		}
+		return "must be at least " + param
+	case "max":
+		if fe.Kind() == reflect.String {
+			return "must be at most " + param + " characters long"
+		}
+		if isCounted(fe.Kind()) {
+			return "must contain at most " + param + " items"
+		}
+		return "must be at most " + param
+	case "gt":
+		return "must be greater than " + param
+	case "gte":
+		return "must be greater than or equal to " + param
+	case "lt":
+		return "must be less than " + param
+	case "lte":
+		return "must be less than or equal to " + param
+	case "gtfield":
+		return "must be after " + param
+	case "eq":
+		return "must be " + param
+	case TagLatitude:
+		return "must be a latitude between -90 and 90"
+	case TagLongitude:
+		return "must be a longitude between -180 and 180"
+	case TagPhone:
+		return "must be a phone number of 7 to 15 digits"
+	case TagWasteType:
+		return "must be one of: " + strings.Join(WasteTypes, ", ")
+	case TagWasteTypeOrAny:
+		return "must be * or one of: " + strings.Join(WasteTypes, ", ")
+	}
+	return "failed the " + fe.Tag() + " rule"
+}
+
+func isCounted(kind reflect.Kind) bool {
+	return kind == reflect.Slice || kind == reflect.Array || kind == reflect.Map
+}
+
+// jsonType names the JSON type of a Go type
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	}
+	return "object"
+}
@@ -1,17 +1,35 @@
 package main
 
 import (
+	"context"
 	"log"
+	"net"
+	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/smartwaste/pkg/health"
+	"github.com/smartwaste/shipment-tracker/docs"
+	"github.com/smartwaste/shipment-tracker/internal/auth"
 	"github.com/smartwaste/shipment-tracker/internal/config"
 	"github.com/smartwaste/shipment-tracker/internal/database"
+	"github.com/smartwaste/shipment-tracker/internal/grpcapi"
 	"github.com/smartwaste/shipment-tracker/internal/handlers"
+	"github.com/smartwaste/shipment-tracker/internal/metrics"
+	"github.com/smartwaste/shipment-tracker/internal/models"
 	"github.com/smartwaste/shipment-tracker/internal/nats"
+	"github.com/smartwaste/shipment-tracker/internal/outbox"
+	"github.com/smartwaste/shipment-tracker/internal/payments"
+	"github.com/smartwaste/shipment-tracker/internal/realtime"
 	"github.com/smartwaste/shipment-tracker/internal/repository"
+	"github.com/smartwaste/shipment-tracker/internal/routing"
 	"github.com/smartwaste/shipment-tracker/internal/services"
 )
 
+// healthCheckTimeout bounds each dependency check of the readiness probe
+const healthCheckTimeout = 2 * time.Second
+
 func main() {
 	// 1. Load Configuration
 	cfg := config.LoadConfig()
@@ -22,39 +40,175 @@ func main() {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 	defer database.CloseDB()
+	metrics.RegisterDBStats(db.DB)
 
-	// 3. Initialize NATS
+	// 3. Initialize NATS; events wait in the outbox while it is unreachable
 	natsClient := nats.NewClient(&cfg.NATS)
 	if err := natsClient.Connect(); err != nil {
-		log.Printf("Warning: Failed to connect to NATS: %v. Continuing without messaging...", err)
+		log.Printf("Warning: Failed to connect to NATS: %v. Events are kept in the outbox...", err)
 	} else {
 		defer natsClient.Close()
 	}
 
 	// 4. Initialize Repositories
-	shipmentRepo := repository.NewShipmentRepository(db)
-	transitionRepo := repository.NewTransitionRepository(db)
-	// contractRepo := repository.NewContractRepository(db) // For later
+	repoDB := repository.NewDB(db, cfg.Database.QueryTimeout)
+	shipmentRepo := repository.NewShipmentRepository(repoDB)
+	transitionRepo := repository.NewTransitionRepository(repoDB)
+	signingKeyRepo := repository.NewSigningKeyRepository(repoDB)
+	paymentRepo := repository.NewPaymentRepository(repoDB)
+	outboxRepo := repository.NewOutboxRepository(repoDB)
+	trackRepo := repository.NewTrackRepository(repoDB)
+	geofenceRepo := repository.NewGeofenceRepository(repoDB)
+	etaRepo := repository.NewETARepository(repoDB)
+	messageRepo := repository.NewMessageRepository(repoDB)
+	// contractRepo := repository.NewContractRepository(repoDB) // For later
 
 	// 5. Initialize Services
-	shipmentService := services.NewShipmentService(shipmentRepo, transitionRepo, natsClient)
+	signingKeyService := services.NewSigningKeyService(signingKeyRepo)
+	paymentProvider, err := payments.NewProvider(&cfg.Payments, &cfg.Blockchain)
+	if err != nil {
+		log.Fatalf("Failed to configure payments: %v", err)
+	}
+	paymentService := services.NewPaymentService(shipmentRepo, paymentRepo, paymentProvider, cfg.Payments.Currency)
+	cancellationPolicy := services.NewCancellationPolicy(&cfg.Cancel, transitionRepo)
+	shipmentService := services.NewShipmentService(shipmentRepo, transitionRepo, signingKeyService, paymentService, outboxRepo, cancellationPolicy)
+	geofenceService, err := services.NewGeofenceService(&cfg.Geofence, trackRepo, geofenceRepo, shipmentService)
+	if err != nil {
+		log.Fatalf("Failed to configure geofences: %v", err)
+	}
+	routingProvider, err := routing.NewProvider(&cfg.Routing)
+	if err != nil {
+		log.Fatalf("Failed to configure routing: %v", err)
+	}
+	etaService := services.NewETAService(&cfg.ETA, routingProvider, trackRepo, etaRepo, outboxRepo)
+	trackingService := services.NewTrackingService(trackRepo, realtime.NewTrackBroker(), geofenceService, etaService)
+	messageService := services.NewMessageService(messageRepo, realtime.NewMessageBroker())
+
+	// Relay the outbox to NATS in the background
+	relayerCtx, stopRelayer := context.WithCancel(context.Background())
+	defer stopRelayer()
+	go outbox.NewRelayer(outboxRepo, natsClient, &cfg.Outbox).Run(relayerCtx)
 
 	// 6. Initialize Handlers
 	shipmentHandler := handlers.NewShipmentHandler(shipmentService)
+	signingKeyHandler := handlers.NewSigningKeyHandler(signingKeyService)
+	paymentHandler := handlers.NewPaymentHandler(shipmentService, paymentService)
+	trackingHandler := handlers.NewTrackingHandler(shipmentService, trackingService, geofenceService, etaService)
+	messageHandler := handlers.NewMessageHandler(shipmentService, messageService, &cfg.CORS)
 
 	// 7. Setup Router
-	router := gin.Default()
+	verifier := auth.NewVerifier(&cfg.Auth)
+
+	// Probes; events wait in the outbox while NATS is down, so it only
+	// degrades the service
+	migrations, err := database.Migrations()
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	probes := health.NewChecker(healthCheckTimeout)
+	probes.Require("postgres", health.Database(db))
+	probes.Require("migrations", health.Migrations(db, migrations))
+	probes.Observe("nats", health.Connection(natsClient.IsConnected))
+
+	if err := handlers.RegisterValidators(); err != nil {
+		log.Fatalf("Failed to register request validators: %v", err)
+	}
+	router := gin.New()
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+	router.Use(handlers.LoggerMiddleware(), gin.Recovery(), handlers.MetricsMiddleware())
+	router.Use(handlers.SecurityHeadersMiddleware(cfg.Server.HSTSMaxAge), handlers.CORSMiddleware(&cfg.CORS))
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	router.GET("/healthz", func(c *gin.Context) {
+		c.JSON(http.StatusOK, probes.Live())
+	})
+	router.GET("/readyz", func(c *gin.Context) {
+		report := probes.Ready(c.Request.Context())
+		c.JSON(report.HTTPStatus(), report)
+	})
+	if cfg.Server.Swagger {
+		router.GET("/swagger/*any", handlers.SwaggerHandler(docs.Spec))
+	}
+
+	// Requests are validated once authenticated
+	var validation []gin.HandlerFunc
+	if cfg.Server.ValidateRequests {
+		apiSpec, err := handlers.NewOpenAPIRouter(docs.Spec, "/api/v1")
+		if err != nil {
+			log.Fatalf("Failed to load OpenAPI spec: %v", err)
+		}
+		validation = append(validation, handlers.OpenAPIValidationMiddleware(apiSpec))
+	}
+
 	v1 := router.Group("/api/v1")
+
+	// Event streams also accept the access token as a query parameter, since
+	// browsers cannot set headers on an EventSource or a WebSocket
+	streams := v1.Group("")
+	streams.Use(handlers.StreamAuthMiddleware(verifier))
+	streams.Use(validation...)
+	{
+		streams.GET("/shipments/:id/track/stream", trackingHandler.StreamTrack)
+		streams.GET("/shipments/:id/messages/stream", messageHandler.StreamMessages)
+	}
+
+	api := v1.Group("")
+	api.Use(handlers.AuthMiddleware(verifier))
+	api.Use(validation...)
 	{
-		shipments := v1.Group("/shipments")
+		shipments := api.Group("/shipments")
 		{
-			shipments.POST("", shipmentHandler.CreateShipment)
+			shipments.GET("", shipmentHandler.ListShipments)
+			shipments.POST("", handlers.RequireRoles(models.RoleCitizen, models.RoleAdmin), shipmentHandler.CreateShipment)
 			shipments.GET("/:id", shipmentHandler.GetShipment)
-			shipments.POST("/:id/assign-driver", shipmentHandler.AssignDriver)
+			shipments.GET("/:id/transitions", shipmentHandler.GetTransitions)
+			shipments.GET("/:id/verify", shipmentHandler.VerifyShipment)
+			shipments.POST("/:id/assign-driver", handlers.RequireRoles(models.RoleAdmin, models.RoleDispatcher, models.RoleDriver), shipmentHandler.AssignDriver)
+			shipments.POST("/:id/confirm-pickup", shipmentHandler.ConfirmPickup)
+			shipments.POST("/:id/start-transit", shipmentHandler.StartTransit)
+			shipments.POST("/:id/confirm-delivery", shipmentHandler.ConfirmDelivery)
+			shipments.POST("/:id/complete", shipmentHandler.CompleteShipment)
+			shipments.GET("/:id/cancellation-terms", shipmentHandler.GetCancellationTerms)
+			shipments.POST("/:id/cancel", shipmentHandler.CancelShipment)
+			shipments.POST("/:id/fund", paymentHandler.FundShipment)
+			shipments.POST("/:id/settle", handlers.RequireRoles(models.RoleAdmin), paymentHandler.SettleShipment)
+			shipments.GET("/:id/payments", paymentHandler.ListShipmentPayments)
+			shipments.POST("/:id/location", trackingHandler.RecordLocation)
+			shipments.GET("/:id/track", trackingHandler.GetTrack)
+			shipments.GET("/:id/geofence-events", trackingHandler.GetGeofenceEvents)
+			shipments.GET("/:id/eta", trackingHandler.GetETA)
+			shipments.GET("/:id/messages", messageHandler.ListMessages)
+			shipments.POST("/:id/messages", messageHandler.SendMessage)
+			shipments.POST("/:id/messages/read", messageHandler.MarkMessagesRead)
+		}
+
+		api.GET("/payouts", paymentHandler.ListPayouts)
+
+		signingKeys := api.Group("/signing-keys")
+		{
+			signingKeys.GET("", signingKeyHandler.ListSigningKeys)
+			signingKeys.POST("", signingKeyHandler.RegisterSigningKey)
+			signingKeys.DELETE("/:id", signingKeyHandler.RevokeSigningKey)
+		}
+	}
+
+	// 8. Serve the gRPC API alongside the REST API
+	if cfg.Server.GRPCPort != "" {
+		lis, err := net.Listen("tcp", ":"+cfg.Server.GRPCPort)
+		if err != nil {
+			log.Fatalf("Failed to listen on gRPC port: %v", err)
 		}
+		grpcServer := grpcapi.NewServer(verifier, shipmentService)
+		go func() {
+			log.Printf("Starting gRPC server on port %s", cfg.Server.GRPCPort)
+			if err := grpcServer.Serve(lis); err != nil {
+				log.Fatalf("Failed to start gRPC server: %v", err)
+			}
+		}()
 	}
 
-	// 8. Start Server
+	// 9. Start Server
 	log.Printf("Starting server on port %s", cfg.Server.Port)
 	if err := router.Run(":" + cfg.Server.Port); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
@@ -0,0 +1,9 @@
+// Package docs embeds the OpenAPI specification of the shipment tracker REST API
+package docs
+
+import _ "embed"
+
+// Spec is the OpenAPI 3 document served under /swagger and used to validate requests
+//
+//go:embed swagger.yaml
+var Spec []byte
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/smartwaste/shipment-tracker/internal/config"
+	"github.com/smartwaste/shipment-tracker/internal/models"
+)
+
+// ErrInvalidToken is returned when an access token cannot be validated
+var ErrInvalidToken = errors.New("invalid access token")
+
+// Claims represents the authenticated principal carried in an access token.
+// Tokens are issued by the main backend and share its signing secret.
+type Claims struct {
+	SubjectID uuid.UUID   `json:"sid"`
+	Email     string      `json:"email"`
+	Role      models.Role `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// HasRole returns true if the principal holds any of the given roles
+func (c *Claims) HasRole(roles ...models.Role) bool {
+	for _, r := range roles {
+		if c.Role == r {
+			return true
+		}
+	}
+	return false
+}
+
+// Verifier validates access tokens issued by the main backend
+type Verifier struct {
+	secret []byte
+	issuer string
+}
+
+// NewVerifier creates a new Verifier
+func NewVerifier(cfg *config.AuthConfig) *Verifier {
+	return &Verifier{
+		secret: []byte(cfg.JWTSecret),
+		issuer: cfg.JWTIssuer,
+	}
+}
+
+// Parse validates a signed access token and returns its claims
+func (v *Verifier) Parse(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return v.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(v.issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if !claims.Role.IsValid() {
+		return nil, fmt.Errorf("%w: unknown role %q", ErrInvalidToken, claims.Role)
+	}
+	return claims, nil
+}
+
+type contextKey struct{}
+
+// WithClaims returns a copy of ctx carrying the given claims
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, contextKey{}, claims)
+}
+
+// ClaimsFromContext returns the claims stored in ctx, if any
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(contextKey{}).(*Claims)
+	return claims, ok && claims != nil
+}
@@ -3,41 +3,121 @@ package config
 import (
 	"log"
 	"strings"
+	"time"
 
+	"github.com/smartwaste/pkg/postgres"
 	"github.com/spf13/viper"
 )
 
 // Config holds all configuration for the service
 type Config struct {
 	Server     ServerConfig
+	CORS       CORSConfig
 	Database   DatabaseConfig
 	NATS       NATSConfig
+	Outbox     OutboxConfig
+	Geofence   GeofenceConfig
+	Routing    RoutingConfig
+	ETA        ETAConfig
+	Cancel     CancellationConfig
 	Blockchain BlockchainConfig
+	Payments   PaymentsConfig
 	Service    ServiceConfig
+	Auth       AuthConfig
 }
 
 // ServerConfig holds server configuration
 type ServerConfig struct {
-	Port string
-	Mode string
+	Port             string
+	GRPCPort         string // Empty disables the gRPC server
+	Mode             string
+	Swagger          bool // Serve the OpenAPI spec and Swagger UI under /swagger
+	ValidateRequests bool // Reject /api/v1 requests that do not match the OpenAPI spec
+
+	// TrustedProxies are the proxies, as IPs or CIDRs, whose X-Forwarded-For
+	// is taken as the client IP; it is ignored from anyone else
+	TrustedProxies []string
+	// HSTSMaxAge is how long browsers only use HTTPS with the API, 0 not
+	// sending Strict-Transport-Security
+	HSTSMaxAge time.Duration
 }
 
-// DatabaseConfig holds database configuration
-type DatabaseConfig struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	DBName   string
-	SSLMode  string
+// CORSConfig holds the cross-origin policy of browsers calling the API
+type CORSConfig struct {
+	AllowedOrigins   []string      // Origins such as https://dashboard.example.com; "*" allows any
+	AllowedMethods   []string      // Methods a cross-origin request may use
+	AllowedHeaders   []string      // Request headers a cross-origin request may send
+	AllowCredentials bool          // Let browsers send cookies; ignored when any origin is allowed
+	MaxAge           time.Duration // How long browsers cache a preflight
 }
 
+// AllowsAnyOrigin returns true if the policy allows every origin
+func (c *CORSConfig) AllowsAnyOrigin() bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsOrigin returns true if browsers may call the API from origin
+func (c *CORSConfig) AllowsOrigin(origin string) bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// DatabaseConfig holds database-related configuration, shared with the
+// other services
+type DatabaseConfig = postgres.Config
+
 // NATSConfig holds NATS messaging configuration
 type NATSConfig struct {
 	URL       string
 	ClusterID string
 }
 
+// OutboxConfig holds the relaying of outbox events to NATS
+type OutboxConfig struct {
+	PollInterval time.Duration // How often the outbox is checked for due events
+	BatchSize    int           // Events relayed per transaction
+	MaxBackoff   time.Duration // Longest wait before retrying a failed event
+	Retention    time.Duration // How long published events are kept
+}
+
+// GeofenceConfig holds the transitions made from the driver's position
+type GeofenceConfig struct {
+	Mode               string        // off, propose or auto
+	PickupRadiusM      float64       // Distance to the pickup at which the pickup starts
+	DropoffRadiusM     float64       // Distance to the dropoff within which the driver must stay
+	DwellTime          time.Duration // How long the driver must stay at the dropoff
+	StationarySpeedKmh float64       // Fastest reported speed still counted as stationary
+}
+
+// RoutingConfig holds the provider of driving distances and times
+type RoutingConfig struct {
+	Provider         string // auto, google, osrm or haversine
+	OSRMURL          string // Base URL of the OSRM server
+	GoogleMapsAPIKey string
+}
+
+// ETAConfig holds the estimation of driver arrivals
+type ETAConfig struct {
+	RefreshInterval time.Duration // Shortest time between estimates from reported positions
+	ChangeThreshold time.Duration // Change of the arrival time that publishes an event
+}
+
+// CancellationConfig holds the penalty policy of shipment cancellations
+type CancellationConfig struct {
+	FreeWindow    time.Duration // How long after the driver assignment the user may cancel for free
+	FeePercent    float64       // Share of the escrow paid to the driver for a later cancellation
+	PenaltyPoints int           // Reward points the user loses for a later cancellation
+}
+
 // BlockchainConfig holds blockchain configuration
 type BlockchainConfig struct {
 	RPCURL          string
@@ -46,12 +126,32 @@ type BlockchainConfig struct {
 	ContractAddress string
 }
 
+// PaymentsConfig holds the escrow payment provider configuration
+type PaymentsConfig struct {
+	Provider        string // stub, stripe or onchain
+	Currency        string // ISO 4217 currency of shipment prices
+	StripeSecretKey string
+	StripeURL       string
+	EscrowContract  string // Escrow contract address of the onchain provider
+	EscrowAccount   string // Platform account the onchain provider sends from
+	WeiPerUnit      string // Wei per currency unit, converting prices on chain
+}
+
 // ServiceConfig holds service-specific configuration
 type ServiceConfig struct {
 	Name     string
 	LogLevel string
 }
 
+// AuthConfig holds access token validation configuration
+type AuthConfig struct {
+	JWTSecret string
+	JWTIssuer string
+}
+
+// insecureJWTSecret is the JWT_SECRET default, only accepted in debug mode
+const insecureJWTSecret = "change-me-in-production"
+
 // LoadConfig loads configuration from environment variables
 func LoadConfig() *Config {
 	viper.AutomaticEnv()
@@ -59,23 +159,71 @@ func LoadConfig() *Config {
 
 	// Set defaults
 	viper.SetDefault("SERVER_PORT", "8082")
+	viper.SetDefault("GRPC_PORT", "9092")
 	viper.SetDefault("SERVER_MODE", "debug")
+	viper.SetDefault("SWAGGER_ENABLED", true)
+	viper.SetDefault("OPENAPI_VALIDATION_ENABLED", true)
+	viper.SetDefault("TRUSTED_PROXIES", "")
+	viper.SetDefault("HSTS_MAX_AGE", "8760h")
+	viper.SetDefault("CORS_ALLOWED_ORIGINS", "*")
+	viper.SetDefault("CORS_ALLOWED_METHODS", "GET,POST,DELETE,OPTIONS")
+	viper.SetDefault("CORS_ALLOWED_HEADERS", "Origin,Content-Type,Accept,Authorization")
+	viper.SetDefault("CORS_ALLOW_CREDENTIALS", false)
+	viper.SetDefault("CORS_MAX_AGE", "24h")
 	viper.SetDefault("DB_HOST", "localhost")
 	viper.SetDefault("DB_PORT", "5432")
 	viper.SetDefault("DB_USER", "postgres")
 	viper.SetDefault("DB_PASSWORD", "postgres")
 	viper.SetDefault("DB_NAME", "smartwaste_shipments")
 	viper.SetDefault("DB_SSLMODE", "disable")
+	viper.SetDefault("DB_MAX_OPEN_CONNS", postgres.DefaultMaxOpenConns)
+	viper.SetDefault("DB_MAX_IDLE_CONNS", postgres.DefaultMaxIdleConns)
+	viper.SetDefault("DB_CONN_MAX_LIFETIME", postgres.DefaultConnMaxLifetime)
+	viper.SetDefault("DB_CONN_MAX_IDLE_TIME", postgres.DefaultConnMaxIdleTime)
+	viper.SetDefault("DB_QUERY_TIMEOUT", postgres.DefaultQueryTimeout)
 	viper.SetDefault("NATS_URL", "nats://localhost:4222")
 	viper.SetDefault("NATS_CLUSTER_ID", "smartwaste-cluster")
+	viper.SetDefault("OUTBOX_POLL_INTERVAL", "1s")
+	viper.SetDefault("OUTBOX_BATCH_SIZE", 100)
+	viper.SetDefault("OUTBOX_MAX_BACKOFF", "5m")
+	viper.SetDefault("OUTBOX_RETENTION", "168h")
+	viper.SetDefault("GEOFENCE_MODE", "propose")
+	viper.SetDefault("GEOFENCE_PICKUP_RADIUS_M", 100)
+	viper.SetDefault("GEOFENCE_DROPOFF_RADIUS_M", 150)
+	viper.SetDefault("GEOFENCE_DWELL_TIME", "2m")
+	viper.SetDefault("GEOFENCE_STATIONARY_SPEED_KMH", 5)
+	viper.SetDefault("ROUTING_PROVIDER", "auto")
+	viper.SetDefault("OSRM_URL", "https://router.project-osrm.org")
+	viper.SetDefault("ETA_REFRESH_INTERVAL", "30s")
+	viper.SetDefault("ETA_CHANGE_THRESHOLD", "2m")
+	viper.SetDefault("CANCELLATION_FREE_WINDOW", "10m")
+	viper.SetDefault("CANCELLATION_FEE_PERCENT", 10)
+	viper.SetDefault("CANCELLATION_PENALTY_POINTS", 10)
 	viper.SetDefault("BLOCKCHAIN_CHAIN_ID", 80001) // Polygon Mumbai
+	viper.SetDefault("PAYMENTS_PROVIDER", "stub")
+	viper.SetDefault("PAYMENTS_CURRENCY", "DZD")
+	viper.SetDefault("STRIPE_URL", "https://api.stripe.com")
 	viper.SetDefault("SERVICE_NAME", "shipment-tracker")
 	viper.SetDefault("LOG_LEVEL", "debug")
+	viper.SetDefault("JWT_SECRET", insecureJWTSecret)
+	viper.SetDefault("JWT_ISSUER", "smartwaste")
 
 	cfg := &Config{
 		Server: ServerConfig{
-			Port: viper.GetString("SERVER_PORT"),
-			Mode: viper.GetString("SERVER_MODE"),
+			Port:             viper.GetString("SERVER_PORT"),
+			GRPCPort:         viper.GetString("GRPC_PORT"),
+			Mode:             viper.GetString("SERVER_MODE"),
+			Swagger:          viper.GetBool("SWAGGER_ENABLED"),
+			ValidateRequests: viper.GetBool("OPENAPI_VALIDATION_ENABLED"),
+			TrustedProxies:   splitList(viper.GetString("TRUSTED_PROXIES")),
+			HSTSMaxAge:       viper.GetDuration("HSTS_MAX_AGE"),
+		},
+		CORS: CORSConfig{
+			AllowedOrigins:   splitList(viper.GetString("CORS_ALLOWED_ORIGINS")),
+			AllowedMethods:   splitList(viper.GetString("CORS_ALLOWED_METHODS")),
+			AllowedHeaders:   splitList(viper.GetString("CORS_ALLOWED_HEADERS")),
+			AllowCredentials: viper.GetBool("CORS_ALLOW_CREDENTIALS"),
+			MaxAge:           viper.GetDuration("CORS_MAX_AGE"),
 		},
 		Database: DatabaseConfig{
 			Host:     viper.GetString("DB_HOST"),
@@ -84,33 +232,90 @@ func LoadConfig() *Config {
 			Password: viper.GetString("DB_PASSWORD"),
 			DBName:   viper.GetString("DB_NAME"),
 			SSLMode:  viper.GetString("DB_SSLMODE"),
+
+			MaxOpenConns:    viper.GetInt("DB_MAX_OPEN_CONNS"),
+			MaxIdleConns:    viper.GetInt("DB_MAX_IDLE_CONNS"),
+			ConnMaxLifetime: viper.GetDuration("DB_CONN_MAX_LIFETIME"),
+			ConnMaxIdleTime: viper.GetDuration("DB_CONN_MAX_IDLE_TIME"),
+			QueryTimeout:    viper.GetDuration("DB_QUERY_TIMEOUT"),
 		},
 		NATS: NATSConfig{
 			URL:       viper.GetString("NATS_URL"),
 			ClusterID: viper.GetString("NATS_CLUSTER_ID"),
 		},
+		Outbox: OutboxConfig{
+			PollInterval: viper.GetDuration("OUTBOX_POLL_INTERVAL"),
+			BatchSize:    viper.GetInt("OUTBOX_BATCH_SIZE"),
+			MaxBackoff:   viper.GetDuration("OUTBOX_MAX_BACKOFF"),
+			Retention:    viper.GetDuration("OUTBOX_RETENTION"),
+		},
+		Geofence: GeofenceConfig{
+			Mode:               viper.GetString("GEOFENCE_MODE"),
+			PickupRadiusM:      viper.GetFloat64("GEOFENCE_PICKUP_RADIUS_M"),
+			DropoffRadiusM:     viper.GetFloat64("GEOFENCE_DROPOFF_RADIUS_M"),
+			DwellTime:          viper.GetDuration("GEOFENCE_DWELL_TIME"),
+			StationarySpeedKmh: viper.GetFloat64("GEOFENCE_STATIONARY_SPEED_KMH"),
+		},
+		Routing: RoutingConfig{
+			Provider:         viper.GetString("ROUTING_PROVIDER"),
+			OSRMURL:          viper.GetString("OSRM_URL"),
+			GoogleMapsAPIKey: viper.GetString("GOOGLE_MAPS_API_KEY"),
+		},
+		ETA: ETAConfig{
+			RefreshInterval: viper.GetDuration("ETA_REFRESH_INTERVAL"),
+			ChangeThreshold: viper.GetDuration("ETA_CHANGE_THRESHOLD"),
+		},
+		Cancel: CancellationConfig{
+			FreeWindow:    viper.GetDuration("CANCELLATION_FREE_WINDOW"),
+			FeePercent:    viper.GetFloat64("CANCELLATION_FEE_PERCENT"),
+			PenaltyPoints: viper.GetInt("CANCELLATION_PENALTY_POINTS"),
+		},
 		Blockchain: BlockchainConfig{
 			RPCURL:          viper.GetString("BLOCKCHAIN_RPC_URL"),
 			ChainID:         viper.GetInt64("BLOCKCHAIN_CHAIN_ID"),
 			PrivateKey:      viper.GetString("BLOCKCHAIN_PRIVATE_KEY"),
 			ContractAddress: viper.GetString("CONTRACT_ADDRESS"),
 		},
+		Payments: PaymentsConfig{
+			Provider:        viper.GetString("PAYMENTS_PROVIDER"),
+			Currency:        viper.GetString("PAYMENTS_CURRENCY"),
+			StripeSecretKey: viper.GetString("STRIPE_SECRET_KEY"),
+			StripeURL:       viper.GetString("STRIPE_URL"),
+			EscrowContract:  viper.GetString("ESCROW_CONTRACT_ADDRESS"),
+			EscrowAccount:   viper.GetString("ESCROW_ACCOUNT"),
+			WeiPerUnit:      viper.GetString("ESCROW_WEI_PER_UNIT"),
+		},
 		Service: ServiceConfig{
 			Name:     viper.GetString("SERVICE_NAME"),
 			LogLevel: viper.GetString("LOG_LEVEL"),
 		},
+		Auth: AuthConfig{
+			JWTSecret: viper.GetString("JWT_SECRET"),
+			JWTIssuer: viper.GetString("JWT_ISSUER"),
+		},
+	}
+
+	if cfg.Auth.JWTSecret == insecureJWTSecret {
+		if cfg.Server.Mode != "debug" {
+			log.Fatalf("JWT_SECRET must be set in %s mode", cfg.Server.Mode)
+		}
+		log.Println("Warning: JWT_SECRET is using the insecure default value")
+	}
+	if cfg.Server.Mode == "release" && cfg.CORS.AllowsAnyOrigin() {
+		log.Println("Warning: CORS_ALLOWED_ORIGINS allows any origin")
 	}
 
 	log.Printf("Configuration loaded for service: %s", cfg.Service.Name)
 	return cfg
 }
 
-// GetDSN returns the database connection string
-func (c *DatabaseConfig) GetDSN() string {
-	return "host=" + c.Host +
-		" port=" + c.Port +
-		" user=" + c.User +
-		" password=" + c.Password +
-		" dbname=" + c.DBName +
-		" sslmode=" + c.SSLMode
+// splitList parses a comma-separated setting, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
@@ -4,7 +4,7 @@ import (
 	"log"
 
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/smartwaste/pkg/postgres"
 	"github.com/smartwaste/shipment-tracker/internal/config"
 )
 
@@ -13,15 +13,11 @@ var db *sqlx.DB
 // InitDB initializes the database connection
 func InitDB(cfg *config.DatabaseConfig) (*sqlx.DB, error) {
 	var err error
-	db, err = sqlx.Connect("postgres", cfg.GetDSN())
+	db, err = postgres.Open(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	// Configure connection pool
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
-
 	log.Println("Database connection established")
 	return db, nil
 }
@@ -0,0 +1,21 @@
+package database
+
+import (
+	"embed"
+	"io/fs"
+
+	"github.com/smartwaste/pkg/postgres"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migrations returns the schema migrations of the service, in order, to check
+// that the database was migrated
+func Migrations() ([]postgres.Migration, error) {
+	dir, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return postgres.LoadMigrations(dir)
+}
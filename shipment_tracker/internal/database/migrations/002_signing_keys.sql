@@ -0,0 +1,18 @@
+-- Shipment Tracker Database Schema
+-- Migration: 002_signing_keys.sql
+
+-- Ed25519 public keys users and drivers register to sign their pickup and
+-- delivery confirmations
+CREATE TABLE IF NOT EXISTS signing_keys (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    subject_id UUID NOT NULL, -- user or driver ID in the main backend
+    name VARCHAR(100) NOT NULL,
+    public_key VARCHAR(64) NOT NULL UNIQUE, -- base64
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_signing_keys_subject_id ON signing_keys(subject_id) WHERE revoked_at IS NULL;
+
+-- The key that verified the signature of a confirmation
+ALTER TABLE state_transitions ADD COLUMN signing_key_id UUID REFERENCES signing_keys(id);
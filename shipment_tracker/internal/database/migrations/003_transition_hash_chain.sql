@@ -0,0 +1,8 @@
+-- Shipment Tracker Database Schema
+-- Migration: 003_transition_hash_chain.sql
+
+-- Each transition carries the SHA-256 of its own content and of the previous
+-- transition of the shipment, so an edited or removed record breaks the chain.
+-- Transitions recorded before the chain existed have no hash.
+ALTER TABLE state_transitions ADD COLUMN previous_hash VARCHAR(64);
+ALTER TABLE state_transitions ADD COLUMN hash VARCHAR(64);
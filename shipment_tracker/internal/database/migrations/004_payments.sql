@@ -0,0 +1,28 @@
+-- Shipment Tracker Database Schema
+-- Migration: 004_payments.sql
+
+-- The price of a shipment is held in escrow from its confirmation until it is
+-- released to the driver on completion or refunded to the user
+ALTER TABLE shipments ADD COLUMN escrow_status VARCHAR(20) NOT NULL DEFAULT 'none'; -- none, held, released, refunded, split
+ALTER TABLE shipments ADD COLUMN escrow_amount DECIMAL(12, 2);
+ALTER TABLE shipments ADD COLUMN escrow_provider VARCHAR(20);
+ALTER TABLE shipments ADD COLUMN escrow_reference VARCHAR(100);
+
+-- Payments ledger: escrow fundings, releases to drivers and refunds to users
+CREATE TABLE IF NOT EXISTS payments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    shipment_id UUID NOT NULL REFERENCES shipments(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL, -- funding, release, refund
+    status VARCHAR(20) NOT NULL, -- succeeded, failed
+    amount DECIMAL(12, 2) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    payer_id UUID, -- user funding the escrow
+    recipient_id UUID, -- driver paid by a release, user paid by a refund
+    provider VARCHAR(20) NOT NULL,
+    provider_reference VARCHAR(100),
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_payments_shipment_id ON payments(shipment_id, created_at);
+CREATE INDEX idx_payments_recipient_id ON payments(recipient_id, created_at) WHERE recipient_id IS NOT NULL;
@@ -0,0 +1,21 @@
+-- Shipment Tracker Database Schema
+-- Migration: 005_outbox.sql
+
+-- Events are written here in the transaction of the change they describe and
+-- relayed to JetStream afterwards, so no event is lost while NATS is down.
+-- The id is the event ID, which JetStream also deduplicates redeliveries by.
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id UUID PRIMARY KEY,
+    subject VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_pending ON outbox_events(next_attempt_at, created_at)
+    WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_published ON outbox_events(published_at)
+    WHERE published_at IS NOT NULL;
@@ -0,0 +1,20 @@
+-- Shipment Tracker Database Schema
+-- Migration: 006_shipment_tracks.sql
+
+-- Track log of the positions the assigned driver reports while a shipment is
+-- on its way, from the assignment until the delivery
+CREATE TABLE IF NOT EXISTS track_points (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    shipment_id UUID NOT NULL REFERENCES shipments(id) ON DELETE CASCADE,
+    driver_id UUID NOT NULL,
+    status VARCHAR(50) NOT NULL, -- Shipment status when the position was reported
+    latitude DECIMAL(10, 8) NOT NULL,
+    longitude DECIMAL(11, 8) NOT NULL,
+    accuracy_m DECIMAL(8, 2),
+    speed_kmh DECIMAL(6, 2),
+    heading DECIMAL(5, 2),
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL, -- When the device measured the position
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_track_points_shipment ON track_points(shipment_id, recorded_at);
@@ -0,0 +1,22 @@
+-- Shipment Tracker Database Schema
+-- Migration: 007_geofence_events.sql
+
+-- Transitions the geofences proposed or made from the driver's position: the
+-- pickup when the driver enters the pickup radius, the delivery once they
+-- stay at the dropoff. Each transition is triggered at most once per shipment.
+CREATE TABLE IF NOT EXISTS geofence_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    shipment_id UUID NOT NULL REFERENCES shipments(id) ON DELETE CASCADE,
+    track_point_id UUID REFERENCES track_points(id) ON DELETE SET NULL,
+    driver_id UUID NOT NULL,
+    from_status VARCHAR(20) NOT NULL,
+    to_status VARCHAR(20) NOT NULL,
+    action VARCHAR(20) NOT NULL, -- proposed, executed
+    latitude DECIMAL(10, 8) NOT NULL,
+    longitude DECIMAL(11, 8) NOT NULL,
+    distance_m DOUBLE PRECISION NOT NULL,
+    radius_m DOUBLE PRECISION NOT NULL,
+    dwell_seconds INTEGER, -- time the driver stayed at the dropoff
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (shipment_id, to_status)
+);
@@ -0,0 +1,20 @@
+-- Shipment Tracker Database Schema
+-- Migration: 008_shipment_etas.sql
+
+-- Latest estimated arrival of the driver at the pickup or dropoff of each
+-- shipment on its way, and the arrival time last published on NATS
+CREATE TABLE IF NOT EXISTS shipment_etas (
+    shipment_id UUID PRIMARY KEY REFERENCES shipments(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL,
+    target VARCHAR(10) NOT NULL, -- pickup, dropoff
+    track_point_id UUID REFERENCES track_points(id) ON DELETE SET NULL,
+    driver_latitude DECIMAL(10, 8) NOT NULL,
+    driver_longitude DECIMAL(11, 8) NOT NULL,
+    position_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    distance_km DOUBLE PRECISION NOT NULL,
+    duration_seconds INTEGER NOT NULL,
+    arrives_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    provider VARCHAR(20) NOT NULL,
+    published_arrives_at TIMESTAMP WITH TIME ZONE,
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
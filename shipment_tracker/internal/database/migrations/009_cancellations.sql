@@ -0,0 +1,7 @@
+-- Shipment Tracker Database Schema
+-- Migration: 009_cancellations.sql
+
+-- Why a shipment was cancelled and the fee its user pays the driver out of
+-- the escrow for cancelling late
+ALTER TABLE shipments ADD COLUMN cancellation_reason VARCHAR(30);
+ALTER TABLE shipments ADD COLUMN cancellation_fee DECIMAL(12, 2);
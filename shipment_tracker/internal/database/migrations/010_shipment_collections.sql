@@ -0,0 +1,5 @@
+-- Shipment Tracker Database Schema
+-- Migration: 010_shipment_collections.sql
+
+-- The backend looks up the shipment of a collection before creating it again
+CREATE INDEX IF NOT EXISTS idx_shipments_collection_id ON shipments(collection_id);
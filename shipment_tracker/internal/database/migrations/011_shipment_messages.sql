@@ -0,0 +1,18 @@
+-- Shipment Tracker Database Schema
+-- Migration: 011_shipment_messages.sql
+
+-- Messages between the user of a shipment and its assigned driver, so they
+-- can coordinate the pickup without leaving the platform. read_at is set once
+-- the other party read the message.
+CREATE TABLE IF NOT EXISTS shipment_messages (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    shipment_id UUID NOT NULL REFERENCES shipments(id) ON DELETE CASCADE,
+    sender_id UUID NOT NULL,
+    sender_role VARCHAR(10) NOT NULL CHECK (sender_role IN ('user', 'driver')),
+    body TEXT NOT NULL,
+    read_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipment_messages_shipment ON shipment_messages(shipment_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_shipment_messages_unread ON shipment_messages(shipment_id, sender_id) WHERE read_at IS NULL;
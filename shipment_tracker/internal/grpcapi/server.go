@@ -0,0 +1,61 @@
+// Package grpcapi serves the gRPC mirror of the shipment REST API.
+package grpcapi
+
+import (
+	"context"
+	"strings"
+
+	"github.com/smartwaste/shipment-tracker/internal/auth"
+	"github.com/smartwaste/shipment-tracker/internal/models"
+	"github.com/smartwaste/shipment-tracker/internal/services"
+	pb "github.com/smartwaste/shipment-tracker/pkg/pb/shipment/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// methodRoles lists the roles allowed to call each method, mirroring the REST
+// route guards; methods absent from the map are open to any valid token
+var methodRoles = map[string][]models.Role{
+	pb.ShipmentService_CreateShipment_FullMethodName: {models.RoleCitizen, models.RoleAdmin},
+	pb.ShipmentService_AssignDriver_FullMethodName:   {models.RoleAdmin, models.RoleDispatcher, models.RoleDriver},
+}
+
+// NewServer creates a gRPC server with the shipment service registered
+func NewServer(verifier *auth.Verifier, service *services.ShipmentService) *grpc.Server {
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(authInterceptor(verifier)))
+	pb.RegisterShipmentServiceServer(server, &shipmentServer{service: service})
+
+	// Lets grpcurl and similar tools discover the service
+	reflection.Register(server)
+
+	return server
+}
+
+// authInterceptor validates the bearer access token in the call metadata and
+// stores its claims
+func authInterceptor(verifier *auth.Verifier) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		var tokenString string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get("authorization"); len(values) > 0 {
+				tokenString, _ = strings.CutPrefix(values[0], "Bearer ")
+			}
+		}
+		if tokenString == "" {
+			return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+		}
+
+		claims, err := verifier.Parse(tokenString)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+		}
+		if roles, ok := methodRoles[info.FullMethod]; ok && !claims.HasRole(roles...) {
+			return nil, status.Error(codes.PermissionDenied, "insufficient permissions")
+		}
+
+		return handler(auth.WithClaims(ctx, claims), req)
+	}
+}
@@ -0,0 +1,197 @@
+package grpcapi
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/shipment-tracker/internal/auth"
+	"github.com/smartwaste/shipment-tracker/internal/models"
+	"github.com/smartwaste/shipment-tracker/internal/services"
+	pb "github.com/smartwaste/shipment-tracker/pkg/pb/shipment/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// shipmentStatuses maps shipment statuses to their protobuf enum values
+var shipmentStatuses = map[models.ShipmentStatus]pb.ShipmentStatus{
+	models.StatusCreated:        pb.ShipmentStatus_SHIPMENT_STATUS_CREATED,
+	models.StatusPriceConfirmed: pb.ShipmentStatus_SHIPMENT_STATUS_PRICE_CONFIRMED,
+	models.StatusDriverAssigned: pb.ShipmentStatus_SHIPMENT_STATUS_DRIVER_ASSIGNED,
+	models.StatusPickupStarted:  pb.ShipmentStatus_SHIPMENT_STATUS_PICKUP_STARTED,
+	models.StatusInTransit:      pb.ShipmentStatus_SHIPMENT_STATUS_IN_TRANSIT,
+	models.StatusDelivered:      pb.ShipmentStatus_SHIPMENT_STATUS_DELIVERED,
+	models.StatusCompleted:      pb.ShipmentStatus_SHIPMENT_STATUS_COMPLETED,
+	models.StatusCancelled:      pb.ShipmentStatus_SHIPMENT_STATUS_CANCELLED,
+	models.StatusDisputed:       pb.ShipmentStatus_SHIPMENT_STATUS_DISPUTED,
+	models.StatusResolved:       pb.ShipmentStatus_SHIPMENT_STATUS_RESOLVED,
+}
+
+// shipmentServer implements pb.ShipmentServiceServer
+type shipmentServer struct {
+	pb.UnimplementedShipmentServiceServer
+	service *services.ShipmentService
+}
+
+// CreateShipment creates a shipment; citizens may only create their own
+func (s *shipmentServer) CreateShipment(ctx context.Context, req *pb.CreateShipmentRequest) (*pb.CreateShipmentResponse, error) {
+	userID, err := parseID(req.GetUserId(), "user ID")
+	if err != nil {
+		return nil, err
+	}
+	collectionID, err := parseID(req.GetCollectionId(), "collection ID")
+	if err != nil {
+		return nil, err
+	}
+	if req.GetWasteType() == "" {
+		return nil, status.Error(codes.InvalidArgument, "waste_type is required")
+	}
+	if req.GetEstimatedWeightKg() <= 0 || req.GetPriceOffered() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "estimated_weight_kg and price_offered must be positive")
+	}
+
+	if claims, ok := auth.ClaimsFromContext(ctx); ok && claims.Role == models.RoleCitizen && claims.SubjectID != userID {
+		return nil, status.Error(codes.PermissionDenied, "cannot create shipments for another user")
+	}
+
+	shipment, err := s.service.CreateShipment(ctx, &models.CreateShipmentRequest{
+		UserID:            userID,
+		CollectionID:      collectionID,
+		WasteType:         req.GetWasteType(),
+		EstimatedWeightKg: req.GetEstimatedWeightKg(),
+		PriceOffered:      req.GetPriceOffered(),
+		PickupLocation:    locationFromProto(req.GetPickupLocation()),
+		DropoffLocation:   locationFromProto(req.GetDropoffLocation()),
+		Notes:             req.Notes,
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.CreateShipmentResponse{Shipment: shipmentToProto(shipment)}, nil
+}
+
+// GetShipment returns a shipment the caller may view
+func (s *shipmentServer) GetShipment(ctx context.Context, req *pb.GetShipmentRequest) (*pb.GetShipmentResponse, error) {
+	shipment, err := s.loadShipment(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetShipmentResponse{Shipment: shipmentToProto(shipment)}, nil
+}
+
+// AssignDriver assigns a driver to a shipment; drivers may only assign themselves
+func (s *shipmentServer) AssignDriver(ctx context.Context, req *pb.AssignDriverRequest) (*pb.AssignDriverResponse, error) {
+	shipmentID, err := parseID(req.GetShipmentId(), "shipment ID")
+	if err != nil {
+		return nil, err
+	}
+	driverID, err := parseID(req.GetDriverId(), "driver ID")
+	if err != nil {
+		return nil, err
+	}
+
+	if claims, ok := auth.ClaimsFromContext(ctx); ok && claims.Role == models.RoleDriver && claims.SubjectID != driverID {
+		return nil, status.Error(codes.PermissionDenied, "drivers can only assign themselves")
+	}
+
+	shipment, err := s.service.GetShipment(ctx, shipmentID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if shipment == nil {
+		return nil, status.Error(codes.NotFound, "shipment not found")
+	}
+	if !shipment.CanTransitionTo(models.StatusDriverAssigned) {
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot transition from %s to %s", shipment.Status, models.StatusDriverAssigned)
+	}
+
+	if err := s.service.AssignDriver(ctx, shipmentID, driverID); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	shipment, err = s.service.GetShipment(ctx, shipmentID)
+	if err != nil || shipment == nil {
+		return nil, status.Error(codes.Internal, "failed to retrieve shipment")
+	}
+	return &pb.AssignDriverResponse{Shipment: shipmentToProto(shipment)}, nil
+}
+
+// loadShipment resolves a shipment ID, applying the same visibility rules as
+// the REST API: operators see everything, users and drivers only their own
+func (s *shipmentServer) loadShipment(ctx context.Context, value string) (*models.Shipment, error) {
+	id, err := parseID(value, "shipment ID")
+	if err != nil {
+		return nil, err
+	}
+
+	shipment, err := s.service.GetShipment(ctx, id)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if shipment == nil {
+		return nil, status.Error(codes.NotFound, "shipment not found")
+	}
+
+	claims, ok := auth.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+	if !claims.HasRole(models.RoleAdmin, models.RoleDispatcher) &&
+		shipment.UserID != claims.SubjectID &&
+		(shipment.DriverID == nil || *shipment.DriverID != claims.SubjectID) {
+		return nil, status.Error(codes.PermissionDenied, "insufficient permissions")
+	}
+
+	return shipment, nil
+}
+
+// parseID parses a UUID request field
+func parseID(value, field string) (uuid.UUID, error) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, status.Errorf(codes.InvalidArgument, "invalid %s", field)
+	}
+	return id, nil
+}
+
+func shipmentToProto(s *models.Shipment) *pb.Shipment {
+	resp := s.ToResponse()
+	out := &pb.Shipment{
+		Id:                resp.ID.String(),
+		UserId:            resp.UserID.String(),
+		CollectionId:      resp.CollectionID.String(),
+		WasteType:         resp.WasteType,
+		EstimatedWeightKg: resp.EstimatedWeightKg,
+		ActualWeightKg:    resp.ActualWeightKg,
+		PriceOffered:      resp.PriceOffered,
+		PriceConfirmed:    resp.PriceConfirmed,
+		ContractAddress:   resp.ContractAddress,
+		Status:            shipmentStatuses[resp.Status],
+		PickupLocation:    locationToProto(resp.PickupLocation),
+		DropoffLocation:   locationToProto(resp.DropoffLocation),
+		Notes:             resp.Notes,
+		CreatedAt:         timestamppb.New(resp.CreatedAt),
+		UpdatedAt:         timestamppb.New(resp.UpdatedAt),
+	}
+	if resp.DriverID != nil {
+		driverID := resp.DriverID.String()
+		out.DriverId = &driverID
+	}
+	return out
+}
+
+func locationToProto(l *models.Location) *pb.Location {
+	if l == nil {
+		return nil
+	}
+	return &pb.Location{Latitude: l.Latitude, Longitude: l.Longitude, Address: l.Address}
+}
+
+func locationFromProto(l *pb.Location) *models.Location {
+	if l == nil {
+		return nil
+	}
+	return &models.Location{Latitude: l.GetLatitude(), Longitude: l.GetLongitude(), Address: l.GetAddress()}
+}
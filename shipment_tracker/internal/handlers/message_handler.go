@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/smartwaste/shipment-tracker/internal/config"
+	"github.com/smartwaste/shipment-tracker/internal/models"
+	"github.com/smartwaste/shipment-tracker/internal/services"
+)
+
+const (
+	// maxMessages bounds the messages returned by one request
+	maxMessages = 500
+	// wsWriteWait bounds writing one frame to a message stream
+	wsWriteWait = 10 * time.Second
+	// wsPongWait is how long a message stream waits for the client's pong
+	wsPongWait = 60 * time.Second
+	// wsPingPeriod pings clients often enough to get their pong in time
+	wsPingPeriod = wsPongWait * 9 / 10
+)
+
+// MessageHandler handles HTTP requests for the messages between the user and
+// the assigned driver of a shipment
+type MessageHandler struct {
+	shipmentService *services.ShipmentService
+	messageService  *services.MessageService
+	upgrader        websocket.Upgrader
+}
+
+// NewMessageHandler creates a new MessageHandler. Browsers may only open
+// message streams from the origins the CORS policy allows.
+func NewMessageHandler(shipmentService *services.ShipmentService, messageService *services.MessageService, cors *config.CORSConfig) *MessageHandler {
+	return &MessageHandler{
+		shipmentService: shipmentService,
+		messageService:  messageService,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			// Clients other than browsers send no origin
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				return origin == "" || cors.AllowsOrigin(origin)
+			},
+		},
+	}
+}
+
+// SendMessage handles the user or the assigned driver messaging the other
+func (h *MessageHandler) SendMessage(c *gin.Context) {
+	var req models.SendMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	shipment, ok := loadShipment(c, h.shipmentService)
+	if !ok {
+		return
+	}
+
+	claims, _ := currentClaims(c)
+	message, err := h.messageService.Send(c.Request.Context(), shipment, claims.SubjectID, &req)
+	if err != nil {
+		messageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, message)
+}
+
+// ListMessages handles retrieving the messages of a shipment, optionally only
+// those sent after since
+func (h *MessageHandler) ListMessages(c *gin.Context) {
+	var since *time.Time
+	if value := c.Query("since"); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "since must be an RFC 3339 time"})
+			return
+		}
+		since = &t
+	}
+	limit := queryInt(c, "limit", maxMessages)
+	if limit < 1 || limit > maxMessages {
+		limit = maxMessages
+	}
+
+	shipment, ok := loadShipment(c, h.shipmentService)
+	if !ok {
+		return
+	}
+
+	messages, err := h.messageService.List(c.Request.Context(), shipment.ID, since, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if messages == nil {
+		messages = []models.ShipmentMessage{}
+	}
+
+	c.JSON(http.StatusOK, messages)
+}
+
+// MarkMessagesRead handles the user or the assigned driver reading the
+// messages the other sent them
+func (h *MessageHandler) MarkMessagesRead(c *gin.Context) {
+	shipment, ok := loadShipment(c, h.shipmentService)
+	if !ok {
+		return
+	}
+
+	claims, _ := currentClaims(c)
+	receipt, err := h.messageService.MarkRead(c.Request.Context(), shipment, claims.SubjectID)
+	if err != nil {
+		messageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, receipt)
+}
+
+// StreamMessages upgrades the connection to a WebSocket and pushes the new
+// messages and read receipts of a shipment
+func (h *MessageHandler) StreamMessages(c *gin.Context) {
+	shipment, ok := loadShipment(c, h.shipmentService)
+	if !ok {
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already written an HTTP error response
+		log.Printf("WebSocket upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	events, unsubscribe := h.messageService.Subscribe(shipment.ID)
+	defer unsubscribe()
+
+	// Messages are sent over REST; the stream only drains what the client
+	// sends so control frames are processed, and notices it going away
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		conn.SetReadLimit(512)
+		conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(wsPingPeriod)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case event := <-events:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-ping.C:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// messageError writes the response for an error sending or reading messages
+func messageError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrNotParticipant):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrMessagingClosed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrEmptyMessage):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
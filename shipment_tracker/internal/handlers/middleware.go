@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smartwaste/shipment-tracker/internal/auth"
+	"github.com/smartwaste/shipment-tracker/internal/config"
+	"github.com/smartwaste/shipment-tracker/internal/metrics"
+	"github.com/smartwaste/shipment-tracker/internal/models"
+)
+
+// CORSMiddleware answers the cross-origin requests of the allowed origins,
+// preflights included. Requests from other origins get no CORS headers, so
+// browsers do not let their callers read the response.
+func CORSMiddleware(cfg *config.CORSConfig) gin.HandlerFunc {
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	anyOrigin := cfg.AllowsAnyOrigin()
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		c.Writer.Header().Add("Vary", "Origin")
+		if !cfg.AllowsOrigin(origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if anyOrigin {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+			if cfg.AllowCredentials {
+				c.Header("Access-Control-Allow-Credentials", "true")
+			}
+		}
+		c.Header("Access-Control-Expose-Headers", "Content-Length")
+
+		if preflight {
+			c.Header("Access-Control-Allow-Methods", methods)
+			c.Header("Access-Control-Allow-Headers", headers)
+			c.Header("Access-Control-Max-Age", maxAge)
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// SecurityHeadersMiddleware sets the headers keeping browsers from sniffing
+// content types, framing the API or leaking its URLs, and from calling it
+// over plain HTTP for hstsMaxAge, unless 0
+func SecurityHeadersMiddleware(hstsMaxAge time.Duration) gin.HandlerFunc {
+	hsts := ""
+	if hstsMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(int(hstsMaxAge.Seconds())) + "; includeSubDomains"
+	}
+
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		header.Set("X-Content-Type-Options", "nosniff")
+		header.Set("X-Frame-Options", "DENY")
+		header.Set("Referrer-Policy", "no-referrer")
+		if hsts != "" {
+			header.Set("Strict-Transport-Security", hsts)
+		}
+		c.Next()
+	}
+}
+
+// MetricsMiddleware records request counts and latency per route template
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		startTime := time.Now()
+
+		c.Next()
+
+		// Label by template (/shipments/:id) rather than path to keep cardinality bounded
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := c.Request.Method
+		metrics.HTTPRequests.WithLabelValues(method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(method, route).Observe(time.Since(startTime).Seconds())
+	}
+}
+
+// LoggerMiddleware logs requests like gin's default logger, hiding access
+// tokens passed in the query string
+func LoggerMiddleware() gin.HandlerFunc {
+	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
+		path, rawQuery, found := strings.Cut(param.Path, "?")
+		if found {
+			path += "?" + redactQuery(rawQuery)
+		}
+		return fmt.Sprintf("[GIN] %v | %3d | %13v | %15s | %-7s %#v\n%s",
+			param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+			param.StatusCode,
+			param.Latency,
+			param.ClientIP,
+			param.Method,
+			path,
+			param.ErrorMessage,
+		)
+	})
+}
+
+// redactQuery hides access tokens passed in the query string from the logs
+func redactQuery(raw string) string {
+	values, err := url.ParseQuery(raw)
+	if err != nil || !values.Has("access_token") {
+		return raw
+	}
+	values.Set("access_token", "REDACTED")
+	return values.Encode()
+}
+
+// AuthMiddleware validates the bearer access token and stores its claims
+func AuthMiddleware(verifier *auth.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !found || tokenString == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing bearer token"})
+			return
+		}
+
+		authenticate(c, verifier, tokenString)
+	}
+}
+
+// StreamAuthMiddleware is AuthMiddleware for Server-Sent Events endpoints,
+// whose browser clients cannot set headers: it also accepts the token in the
+// access_token query parameter
+func StreamAuthMiddleware(verifier *auth.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !found || tokenString == "" {
+			tokenString = c.Query("access_token")
+		}
+		if tokenString == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing access token"})
+			return
+		}
+
+		authenticate(c, verifier, tokenString)
+	}
+}
+
+// authenticate parses the token and stores its claims on the request
+func authenticate(c *gin.Context, verifier *auth.Verifier, tokenString string) {
+	claims, err := verifier.Parse(tokenString)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		return
+	}
+
+	c.Set("claims", claims)
+	c.Request = c.Request.WithContext(auth.WithClaims(c.Request.Context(), claims))
+	c.Next()
+}
+
+// RequireRoles allows the request only if the principal holds one of the roles
+func RequireRoles(roles ...models.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := currentClaims(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+			return
+		}
+		if !claims.HasRole(roles...) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// currentClaims returns the claims of the authenticated principal
+func currentClaims(c *gin.Context) (*auth.Claims, bool) {
+	value, exists := c.Get("claims")
+	if !exists {
+		return nil, false
+	}
+	claims, ok := value.(*auth.Claims)
+	return claims, ok
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/pkg/response"
+	"github.com/smartwaste/shipment-tracker/internal/models"
+	"github.com/smartwaste/shipment-tracker/internal/services"
+)
+
+// PaymentHandler handles HTTP requests for shipment escrows and payouts
+type PaymentHandler struct {
+	shipmentService *services.ShipmentService
+	paymentService  *services.PaymentService
+}
+
+// NewPaymentHandler creates a new PaymentHandler
+func NewPaymentHandler(shipmentService *services.ShipmentService, paymentService *services.PaymentService) *PaymentHandler {
+	return &PaymentHandler{shipmentService: shipmentService, paymentService: paymentService}
+}
+
+// FundShipment handles the user confirming the price of a shipment by funding
+// its escrow
+func (h *PaymentHandler) FundShipment(c *gin.Context) {
+	var req models.FundShipmentRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			bindError(c, err)
+			return
+		}
+	}
+
+	shipment, ok := loadShipment(c, h.shipmentService)
+	if !ok {
+		return
+	}
+
+	claims, _ := currentClaims(c)
+	if shipment.UserID != claims.SubjectID && !claims.HasRole(models.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the user of the shipment can fund it"})
+		return
+	}
+
+	respondTransition(c, shipment, h.shipmentService.ConfirmPrice(c.Request.Context(), shipment, claims.SubjectID, transitionRole(claims, shipment), &req))
+}
+
+// SettleShipment handles an admin settling the escrow of a disputed shipment,
+// or retrying a failed settlement
+func (h *PaymentHandler) SettleShipment(c *gin.Context) {
+	var req models.SettleShipmentRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			bindError(c, err)
+			return
+		}
+	}
+
+	shipment, ok := loadShipment(c, h.shipmentService)
+	if !ok {
+		return
+	}
+
+	respondTransition(c, shipment, h.shipmentService.SettleEscrow(c.Request.Context(), shipment, req.Outcome))
+}
+
+// ListShipmentPayments handles retrieving the payments ledger of a shipment
+func (h *PaymentHandler) ListShipmentPayments(c *gin.Context) {
+	shipment, ok := loadShipment(c, h.shipmentService)
+	if !ok {
+		return
+	}
+
+	payments, err := h.paymentService.ListByShipment(c.Request.Context(), shipment.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if payments == nil {
+		payments = []models.Payment{}
+	}
+
+	c.JSON(http.StatusOK, payments)
+}
+
+// ListPayouts handles listing the payouts to a driver or user; only admins
+// and dispatchers may list those of someone else
+func (h *PaymentHandler) ListPayouts(c *gin.Context) {
+	claims, _ := currentClaims(c)
+	recipientID := claims.SubjectID
+	if value := c.Query("recipient_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipient_id"})
+			return
+		}
+		if id != claims.SubjectID && !claims.HasRole(models.RoleAdmin, models.RoleDispatcher) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			return
+		}
+		recipientID = id
+	}
+
+	page, perPage := parsePage(c)
+	payouts, total, err := h.paymentService.ListPayouts(c.Request.Context(), recipientID, perPage, (page-1)*perPage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if payouts == nil {
+		payouts = []models.Payment{}
+	}
+
+	c.JSON(http.StatusOK, &models.PaymentListResponse{Data: payouts, Meta: response.NewPagination(page, perPage, total)})
+}
@@ -1,10 +1,15 @@
-package api
+package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/smartwaste/pkg/response"
+	"github.com/smartwaste/shipment-tracker/internal/auth"
 	"github.com/smartwaste/shipment-tracker/internal/models"
 	"github.com/smartwaste/shipment-tracker/internal/services"
 )
@@ -23,11 +28,17 @@ func NewShipmentHandler(service *services.ShipmentService) *ShipmentHandler {
 func (h *ShipmentHandler) CreateShipment(c *gin.Context) {
 	var req models.CreateShipmentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		bindError(c, err)
 		return
 	}
 
-	shipment, err := h.service.CreateShipment(&req)
+	// Citizens may only create shipments on their own behalf
+	if claims, ok := currentClaims(c); ok && claims.Role == models.RoleCitizen && claims.SubjectID != req.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Cannot create shipments for another user"})
+		return
+	}
+
+	shipment, err := h.service.CreateShipment(c.Request.Context(), &req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -36,26 +47,122 @@ func (h *ShipmentHandler) CreateShipment(c *gin.Context) {
 	c.JSON(http.StatusCreated, shipment.ToResponse())
 }
 
+// maxPerPage caps the page size of list endpoints
+const maxPerPage = 100
+
+// ListShipments handles listing shipments; users and drivers only see the
+// shipments they created or drive
+func (h *ShipmentHandler) ListShipments(c *gin.Context) {
+	var filter models.ShipmentFilter
+	for param, target := range map[string]**uuid.UUID{
+		"user_id":       &filter.UserID,
+		"driver_id":     &filter.DriverID,
+		"collection_id": &filter.CollectionID,
+	} {
+		if value := c.Query(param); value != "" {
+			id, err := uuid.Parse(value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param})
+				return
+			}
+			*target = &id
+		}
+	}
+	if value := c.Query("status"); value != "" {
+		status := models.ShipmentStatus(value)
+		if !status.IsValid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+			return
+		}
+		filter.Status = &status
+	}
+	for param, target := range map[string]**time.Time{
+		"from": &filter.From,
+		"to":   &filter.To,
+	} {
+		if value := c.Query(param); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param + ", expected RFC 3339"})
+				return
+			}
+			*target = &t
+		}
+	}
+
+	claims, ok := currentClaims(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+	if !claims.HasRole(models.RoleAdmin, models.RoleDispatcher) {
+		filter.ParticipantID = &claims.SubjectID
+	}
+
+	page, perPage := parsePage(c)
+	shipments, total, err := h.service.ListShipments(c.Request.Context(), filter, perPage, (page-1)*perPage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := &models.ShipmentListResponse{
+		Data: make([]*models.ShipmentResponse, 0, len(shipments)),
+		Meta: response.NewPagination(page, perPage, total),
+	}
+	for i := range shipments {
+		resp.Data = append(resp.Data, shipments[i].ToResponse())
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
 // GetShipment handles retrieving a shipment by ID
 func (h *ShipmentHandler) GetShipment(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID"})
+	shipment, ok := loadShipment(c, h.service)
+	if !ok {
 		return
 	}
 
-	shipment, err := h.service.GetShipment(id)
+	c.JSON(http.StatusOK, shipment.ToResponse())
+}
+
+// GetTransitions handles retrieving the state history of a shipment
+func (h *ShipmentHandler) GetTransitions(c *gin.Context) {
+	shipment, ok := loadShipment(c, h.service)
+	if !ok {
+		return
+	}
+
+	transitions, err := h.service.GetTransitions(c.Request.Context(), shipment.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	if shipment == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Shipment not found"})
+
+	resp := make([]*models.TransitionResponse, 0, len(transitions))
+	for i := range transitions {
+		resp = append(resp, transitions[i].ToResponse())
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// VerifyShipment handles re-validating the hash chain of a shipment's
+// transition history
+func (h *ShipmentHandler) VerifyShipment(c *gin.Context) {
+	shipment, ok := loadShipment(c, h.service)
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, shipment.ToResponse())
+	result, err := h.service.VerifyChain(c.Request.Context(), shipment)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
 }
 
 // AssignDriver handles assigning a driver to a shipment
@@ -69,14 +176,272 @@ func (h *ShipmentHandler) AssignDriver(c *gin.Context) {
 
 	var req models.AssignDriverRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		bindError(c, err)
+		return
+	}
+
+	// Drivers may only assign themselves
+	if claims, ok := currentClaims(c); ok && claims.Role == models.RoleDriver && claims.SubjectID != req.DriverID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Drivers can only assign themselves"})
 		return
 	}
 
-	if err := h.service.AssignDriver(id, req.DriverID); err != nil {
+	if err := h.service.AssignDriver(c.Request.Context(), id, req.DriverID); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Driver assigned successfully"})
 }
+
+// ConfirmPickup handles the user or the assigned driver confirming that the
+// pickup started
+func (h *ShipmentHandler) ConfirmPickup(c *gin.Context) {
+	var req models.ConfirmPickupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	shipment, ok := loadShipment(c, h.service)
+	if !ok || !checkConfirmation(c, shipment, req.ConfirmedBy, req.Role) {
+		return
+	}
+
+	respondTransition(c, shipment, h.service.ConfirmPickup(c.Request.Context(), shipment, &req))
+}
+
+// StartTransit handles the assigned driver leaving for the dropoff
+func (h *ShipmentHandler) StartTransit(c *gin.Context) {
+	shipment, ok := loadShipment(c, h.service)
+	if !ok {
+		return
+	}
+
+	claims, _ := currentClaims(c)
+	if !isAssignedDriver(shipment, claims.SubjectID) && !claims.HasRole(models.RoleAdmin, models.RoleDispatcher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the assigned driver can start the transit"})
+		return
+	}
+
+	respondTransition(c, shipment, h.service.StartTransit(c.Request.Context(), shipment, claims.SubjectID, transitionRole(claims, shipment)))
+}
+
+// ConfirmDelivery handles the user or the assigned driver confirming the
+// delivery
+func (h *ShipmentHandler) ConfirmDelivery(c *gin.Context) {
+	var req models.ConfirmDeliveryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	shipment, ok := loadShipment(c, h.service)
+	if !ok || !checkConfirmation(c, shipment, req.ConfirmedBy, req.Role) {
+		return
+	}
+
+	respondTransition(c, shipment, h.service.ConfirmDelivery(c.Request.Context(), shipment, &req))
+}
+
+// CompleteShipment handles the user accepting a delivered shipment
+func (h *ShipmentHandler) CompleteShipment(c *gin.Context) {
+	shipment, ok := loadShipment(c, h.service)
+	if !ok {
+		return
+	}
+
+	claims, _ := currentClaims(c)
+	if shipment.UserID != claims.SubjectID && !claims.HasRole(models.RoleAdmin, models.RoleDispatcher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the user of the shipment can complete it"})
+		return
+	}
+
+	respondTransition(c, shipment, h.service.CompleteShipment(c.Request.Context(), shipment, claims.SubjectID, transitionRole(claims, shipment)))
+}
+
+// CancelShipment handles the user, the assigned driver or an operator
+// cancelling a shipment with a reason code
+func (h *ShipmentHandler) CancelShipment(c *gin.Context) {
+	var req models.CancelShipmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	shipment, claims, ok := h.loadCancellable(c)
+	if !ok {
+		return
+	}
+
+	_, err := h.service.CancelShipment(c.Request.Context(), shipment, claims.SubjectID, transitionRole(claims, shipment), &req)
+	respondTransition(c, shipment, err)
+}
+
+// GetCancellationTerms handles previewing what cancelling a shipment with a
+// reason code would cost its user now
+func (h *ShipmentHandler) GetCancellationTerms(c *gin.Context) {
+	reason := models.CancellationReason(c.DefaultQuery("reason_code", string(models.CancelOther)))
+
+	shipment, claims, ok := h.loadCancellable(c)
+	if !ok {
+		return
+	}
+
+	terms, err := h.service.CancellationTerms(c.Request.Context(), shipment, transitionRole(claims, shipment), reason)
+	if err != nil {
+		respondTransition(c, shipment, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, terms)
+}
+
+// loadCancellable loads the shipment of the request if the principal may
+// cancel it: its user, its assigned driver or an operator
+func (h *ShipmentHandler) loadCancellable(c *gin.Context) (*models.Shipment, *auth.Claims, bool) {
+	shipment, ok := loadShipment(c, h.service)
+	if !ok {
+		return nil, nil, false
+	}
+
+	claims, _ := currentClaims(c)
+	if shipment.UserID != claims.SubjectID && !isAssignedDriver(shipment, claims.SubjectID) &&
+		!claims.HasRole(models.RoleAdmin, models.RoleDispatcher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the user or the assigned driver can cancel the shipment"})
+		return nil, nil, false
+	}
+	return shipment, claims, true
+}
+
+// loadShipment resolves the :id shipment the principal may access, writing
+// the error response itself
+func loadShipment(c *gin.Context, service *services.ShipmentService) (*models.Shipment, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID"})
+		return nil, false
+	}
+
+	shipment, err := service.GetShipment(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if shipment == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shipment not found"})
+		return nil, false
+	}
+	if !canAccessShipment(c, shipment) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		return nil, false
+	}
+	return shipment, true
+}
+
+// respondTransition responds with the shipment after a status or payment
+// change, or with the error that prevented it
+func respondTransition(c *gin.Context, shipment *models.Shipment, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidTransition), errors.Is(err, services.ErrEscrowNotHeld):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrInvalidSignature), errors.Is(err, services.ErrOutcomeRequired),
+		errors.Is(err, services.ErrReasonNotAllowed):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrPaymentFailed):
+		c.JSON(http.StatusPaymentRequired, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, shipment.ToResponse())
+}
+
+// checkConfirmation checks that a confirmation names the user or the
+// assigned driver of the shipment in the given role, and that users and
+// drivers only confirm on their own behalf
+func checkConfirmation(c *gin.Context, shipment *models.Shipment, confirmedBy uuid.UUID, role string) bool {
+	claims, _ := currentClaims(c)
+	if confirmedBy != claims.SubjectID && !claims.HasRole(models.RoleAdmin, models.RoleDispatcher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Cannot confirm for another user"})
+		return false
+	}
+
+	switch role {
+	case "user":
+		if confirmedBy == shipment.UserID {
+			return true
+		}
+	case "driver":
+		if isAssignedDriver(shipment, confirmedBy) {
+			return true
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role must be user or driver"})
+		return false
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": "Confirmation must come from the " + role + " of the shipment"})
+	return false
+}
+
+// isAssignedDriver returns true if id is the driver assigned to the shipment
+func isAssignedDriver(shipment *models.Shipment, id uuid.UUID) bool {
+	return shipment.DriverID != nil && *shipment.DriverID == id
+}
+
+// transitionRole names the party that triggered a transition: "user" or
+// "driver" for the shipment's own, the principal's role for operators
+func transitionRole(claims *auth.Claims, shipment *models.Shipment) string {
+	switch {
+	case isAssignedDriver(shipment, claims.SubjectID):
+		return "driver"
+	case shipment.UserID == claims.SubjectID:
+		return "user"
+	}
+	return string(claims.Role)
+}
+
+// canAccessShipment returns true if the principal may view the shipment:
+// operators see everything, users and drivers only their own shipments
+func canAccessShipment(c *gin.Context, shipment *models.Shipment) bool {
+	claims, ok := currentClaims(c)
+	if !ok {
+		return false
+	}
+	if claims.HasRole(models.RoleAdmin, models.RoleDispatcher) {
+		return true
+	}
+	if shipment.UserID == claims.SubjectID {
+		return true
+	}
+	return isAssignedDriver(shipment, claims.SubjectID)
+}
+
+// parsePage reads the page and per_page query parameters
+func parsePage(c *gin.Context) (page, perPage int) {
+	page = queryInt(c, "page", 1)
+	if page < 1 {
+		page = 1
+	}
+	perPage = queryInt(c, "per_page", 20)
+	if perPage < 1 {
+		perPage = 20
+	} else if perPage > maxPerPage {
+		perPage = maxPerPage
+	}
+	return page, perPage
+}
+
+// queryInt reads an integer query parameter, falling back to def when it is
+// missing or malformed
+func queryInt(c *gin.Context, name string, def int) int {
+	value, err := strconv.Atoi(c.Query(name))
+	if err != nil {
+		return def
+	}
+	return value
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smartwaste/shipment-tracker/internal/models"
+	"github.com/smartwaste/shipment-tracker/internal/services"
+)
+
+// SigningKeyHandler handles HTTP requests for the principal's signing keys
+type SigningKeyHandler struct {
+	service *services.SigningKeyService
+}
+
+// NewSigningKeyHandler creates a new SigningKeyHandler
+func NewSigningKeyHandler(service *services.SigningKeyService) *SigningKeyHandler {
+	return &SigningKeyHandler{service: service}
+}
+
+// ListSigningKeys handles listing the principal's keys
+func (h *SigningKeyHandler) ListSigningKeys(c *gin.Context) {
+	claims, _ := currentClaims(c)
+	keys, err := h.service.List(c.Request.Context(), claims.SubjectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if keys == nil {
+		keys = []models.SigningKey{}
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// RegisterSigningKey handles registering a public key for the principal
+func (h *SigningKeyHandler) RegisterSigningKey(c *gin.Context) {
+	var req models.RegisterSigningKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	claims, _ := currentClaims(c)
+	key, err := h.service.Register(c.Request.Context(), claims.SubjectID, &req)
+	switch {
+	case errors.Is(err, models.ErrInvalidPublicKey):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrKeyRegistered):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+// RevokeSigningKey handles revoking one of the principal's keys
+func (h *SigningKeyHandler) RevokeSigningKey(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UUID"})
+		return
+	}
+
+	claims, _ := currentClaims(c)
+	revoked, err := h.service.Revoke(c.Request.Context(), id, claims.SubjectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !revoked {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Signing key not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smartwaste/shipment-tracker/internal/models"
+	"github.com/smartwaste/shipment-tracker/internal/services"
+)
+
+const (
+	// sseHeartbeatInterval keeps idle track streams alive through proxies
+	sseHeartbeatInterval = 25 * time.Second
+	// maxTrackPoints bounds the positions returned by one track request
+	maxTrackPoints = 1000
+)
+
+// TrackingHandler handles HTTP requests for the live tracking of shipments
+type TrackingHandler struct {
+	shipmentService *services.ShipmentService
+	trackingService *services.TrackingService
+	geofenceService *services.GeofenceService
+	etaService      *services.ETAService
+}
+
+// NewTrackingHandler creates a new TrackingHandler
+func NewTrackingHandler(
+	shipmentService *services.ShipmentService,
+	trackingService *services.TrackingService,
+	geofenceService *services.GeofenceService,
+	etaService *services.ETAService,
+) *TrackingHandler {
+	return &TrackingHandler{
+		shipmentService: shipmentService,
+		trackingService: trackingService,
+		geofenceService: geofenceService,
+		etaService:      etaService,
+	}
+}
+
+// RecordLocation handles the assigned driver pushing a GPS position
+func (h *TrackingHandler) RecordLocation(c *gin.Context) {
+	var req models.RecordLocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	shipment, ok := loadShipment(c, h.shipmentService)
+	if !ok {
+		return
+	}
+
+	claims, _ := currentClaims(c)
+	if !isAssignedDriver(shipment, claims.SubjectID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the assigned driver can report its position"})
+		return
+	}
+
+	point, err := h.trackingService.RecordLocation(c.Request.Context(), shipment, claims.SubjectID, &req)
+	if errors.Is(err, services.ErrNotTracked) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, services.ErrFutureLocation) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, point)
+}
+
+// GetTrack handles retrieving the track log of a shipment, optionally only
+// the positions recorded after since
+func (h *TrackingHandler) GetTrack(c *gin.Context) {
+	var since *time.Time
+	if value := c.Query("since"); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "since must be an RFC 3339 time"})
+			return
+		}
+		since = &t
+	}
+	limit := queryInt(c, "limit", maxTrackPoints)
+	if limit < 1 || limit > maxTrackPoints {
+		limit = maxTrackPoints
+	}
+
+	shipment, ok := loadShipment(c, h.shipmentService)
+	if !ok {
+		return
+	}
+
+	points, err := h.trackingService.GetTrack(c.Request.Context(), shipment.ID, since, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if points == nil {
+		points = []models.TrackPoint{}
+	}
+
+	c.JSON(http.StatusOK, points)
+}
+
+// GetGeofenceEvents handles retrieving the transitions the geofences of a
+// shipment proposed or made
+func (h *TrackingHandler) GetGeofenceEvents(c *gin.Context) {
+	shipment, ok := loadShipment(c, h.shipmentService)
+	if !ok {
+		return
+	}
+
+	events, err := h.geofenceService.ListEvents(c.Request.Context(), shipment.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if events == nil {
+		events = []models.GeofenceEvent{}
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// GetETA handles retrieving the estimated arrival of the driver at the pickup
+// or dropoff of a shipment
+func (h *TrackingHandler) GetETA(c *gin.Context) {
+	shipment, ok := loadShipment(c, h.shipmentService)
+	if !ok {
+		return
+	}
+
+	eta, err := h.etaService.GetETA(c.Request.Context(), shipment)
+	if errors.Is(err, services.ErrETAUnavailable) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, eta)
+}
+
+// StreamTrack streams the positions of a shipment's driver as Server-Sent
+// Events, starting with the last known one
+func (h *TrackingHandler) StreamTrack(c *gin.Context) {
+	shipment, ok := loadShipment(c, h.shipmentService)
+	if !ok {
+		return
+	}
+
+	// Subscribe first so no position falls between the latest and the stream
+	points, unsubscribe := h.trackingService.Subscribe(shipment.ID)
+	defer unsubscribe()
+
+	latest, err := h.trackingService.GetLatest(c.Request.Context(), shipment.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// Send the last known position so the map can render immediately
+	if latest != nil {
+		c.SSEvent("location", latest)
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case point := <-points:
+			c.SSEvent("location", point)
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			if _, err := c.Writer.WriteString(": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
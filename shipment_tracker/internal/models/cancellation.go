@@ -0,0 +1,93 @@
+package models
+
+import "time"
+
+// CancellationReason is the reason code of a cancellation
+type CancellationReason string
+
+const (
+	// Reasons of the user
+	CancelChangedMind       CancellationReason = "changed_mind"
+	CancelDuplicate         CancellationReason = "duplicate"
+	CancelPriceDisagreement CancellationReason = "price_disagreement"
+	CancelDriverNoShow      CancellationReason = "driver_no_show"
+	// Reasons of the driver
+	CancelDriverUnavailable  CancellationReason = "driver_unavailable"
+	CancelVehicleIssue       CancellationReason = "vehicle_issue"
+	CancelWasteNotReady      CancellationReason = "waste_not_ready"
+	CancelPickupInaccessible CancellationReason = "pickup_inaccessible"
+	// Any party
+	CancelOther CancellationReason = "other"
+)
+
+// IsValid returns true if the reason is one of the known reason codes
+func (r CancellationReason) IsValid() bool {
+	switch r {
+	case CancelChangedMind, CancelDuplicate, CancelPriceDisagreement, CancelDriverNoShow,
+		CancelDriverUnavailable, CancelVehicleIssue, CancelWasteNotReady, CancelPickupInaccessible,
+		CancelOther:
+		return true
+	}
+	return false
+}
+
+// AllowedFor returns true if the party of the shipment, "user" or "driver",
+// may give the reason. Operators may give any.
+func (r CancellationReason) AllowedFor(party string) bool {
+	switch party {
+	case "user":
+		return r == CancelChangedMind || r == CancelDuplicate || r == CancelPriceDisagreement ||
+			r == CancelDriverNoShow || r == CancelOther
+	case "driver":
+		return r == CancelDriverUnavailable || r == CancelVehicleIssue || r == CancelWasteNotReady ||
+			r == CancelPickupInaccessible || r == CancelOther
+	}
+	return r.IsValid()
+}
+
+// UserAtFault returns true if the reason makes the user pay for a late
+// cancellation; the driver's reasons never do
+func (r CancellationReason) UserAtFault() bool {
+	switch r {
+	case CancelDriverNoShow, CancelDriverUnavailable, CancelVehicleIssue:
+		return false
+	}
+	return r.IsValid()
+}
+
+// CancelShipmentRequest represents the request to cancel a shipment
+type CancelShipmentRequest struct {
+	ReasonCode CancellationReason `json:"reason_code" binding:"required"`
+	Note       *string            `json:"note" binding:"omitempty,max=500"`
+}
+
+// CancellationTerms is what cancelling a shipment costs its user: the fee
+// released to the driver out of the escrow and the reward points they lose
+type CancellationTerms struct {
+	Party         string             `json:"party"`
+	ReasonCode    CancellationReason `json:"reason_code"`
+	Fee           float64            `json:"fee"`
+	FeePercent    float64            `json:"fee_percent"`
+	Refund        float64            `json:"refund"`
+	PenaltyPoints int                `json:"penalty_points"`
+	FreeUntil     *time.Time         `json:"free_until,omitempty"` // Cancelling is free until then
+}
+
+// Metadata returns the audit metadata of the cancellation transition
+func (t *CancellationTerms) Metadata(note *string) map[string]interface{} {
+	metadata := map[string]interface{}{
+		"reason_code":    t.ReasonCode,
+		"party":          t.Party,
+		"fee":            t.Fee,
+		"fee_percent":    t.FeePercent,
+		"refund":         t.Refund,
+		"penalty_points": t.PenaltyPoints,
+	}
+	if t.FreeUntil != nil {
+		metadata["free_until"] = t.FreeUntil.UTC().Format(time.RFC3339)
+	}
+	if note != nil && *note != "" {
+		metadata["note"] = *note
+	}
+	return metadata
+}
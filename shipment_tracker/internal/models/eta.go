@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ETATarget is where the driver is heading
+type ETATarget string
+
+const (
+	ETATargetPickup  ETATarget = "pickup"
+	ETATargetDropoff ETATarget = "dropoff"
+)
+
+// ShipmentETA is the estimated arrival of the driver at the pickup of an
+// assigned shipment, or at its dropoff once picked up, from their last
+// reported position
+type ShipmentETA struct {
+	ShipmentID         uuid.UUID      `db:"shipment_id" json:"shipment_id"`
+	Status             ShipmentStatus `db:"status" json:"status"`
+	Target             ETATarget      `db:"target" json:"target"`
+	TrackPointID       *uuid.UUID     `db:"track_point_id" json:"track_point_id,omitempty"`
+	DriverLatitude     float64        `db:"driver_latitude" json:"driver_latitude"`
+	DriverLongitude    float64        `db:"driver_longitude" json:"driver_longitude"`
+	PositionAt         time.Time      `db:"position_at" json:"position_at"`
+	DistanceKm         float64        `db:"distance_km" json:"distance_km"`
+	DurationSeconds    int            `db:"duration_seconds" json:"duration_seconds"`
+	ArrivesAt          time.Time      `db:"arrives_at" json:"arrives_at"`
+	Provider           string         `db:"provider" json:"provider"`
+	PublishedArrivesAt *time.Time     `db:"published_arrives_at" json:"-"`
+	ComputedAt         time.Time      `db:"computed_at" json:"computed_at"`
+}
+
+// ETATargetFor returns where the driver of a shipment in status is heading,
+// or false if their arrival is not estimated in it
+func ETATargetFor(status ShipmentStatus) (ETATarget, bool) {
+	switch status {
+	case StatusDriverAssigned:
+		return ETATargetPickup, true
+	case StatusPickupStarted, StatusInTransit:
+		return ETATargetDropoff, true
+	}
+	return "", false
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GeofenceAction is what a geofence did with the transition it detected
+type GeofenceAction string
+
+const (
+	// GeofenceProposed asks the parties to confirm the transition themselves
+	GeofenceProposed GeofenceAction = "proposed"
+	// GeofenceExecuted made the transition on behalf of the driver
+	GeofenceExecuted GeofenceAction = "executed"
+)
+
+// RoleSystem is the triggered_by_role of transitions the tracker makes on its
+// own, on behalf of the principal in triggered_by
+const RoleSystem = "system"
+
+// GeofenceEvent records a transition detected from the position of the
+// driver, with the position and distance it was detected at
+type GeofenceEvent struct {
+	ID           uuid.UUID      `db:"id" json:"id"`
+	ShipmentID   uuid.UUID      `db:"shipment_id" json:"shipment_id"`
+	TrackPointID *uuid.UUID     `db:"track_point_id" json:"track_point_id,omitempty"`
+	DriverID     uuid.UUID      `db:"driver_id" json:"driver_id"`
+	FromStatus   ShipmentStatus `db:"from_status" json:"from_status"`
+	ToStatus     ShipmentStatus `db:"to_status" json:"to_status"`
+	Action       GeofenceAction `db:"action" json:"action"`
+	Latitude     float64        `db:"latitude" json:"latitude"`
+	Longitude    float64        `db:"longitude" json:"longitude"`
+	DistanceM    float64        `db:"distance_m" json:"distance_m"`
+	RadiusM      float64        `db:"radius_m" json:"radius_m"`
+	DwellSeconds *int           `db:"dwell_seconds" json:"dwell_seconds,omitempty"`
+	CreatedAt    time.Time      `db:"created_at" json:"created_at"`
+}
+
+// Metadata returns the audit metadata of the transition the event made
+func (e *GeofenceEvent) Metadata() map[string]interface{} {
+	metadata := map[string]interface{}{
+		"source":            "geofence",
+		"geofence_event_id": e.ID,
+		"latitude":          e.Latitude,
+		"longitude":         e.Longitude,
+		"distance_m":        e.DistanceM,
+		"radius_m":          e.RadiusM,
+	}
+	if e.TrackPointID != nil {
+		metadata["track_point_id"] = *e.TrackPointID
+	}
+	if e.DwellSeconds != nil {
+		metadata["dwell_seconds"] = *e.DwellSeconds
+	}
+	return metadata
+}
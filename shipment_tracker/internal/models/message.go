@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Sender roles of shipment messages
+const (
+	SenderUser   = "user"
+	SenderDriver = "driver"
+)
+
+// Message event types pushed to the clients watching a shipment's messages
+const (
+	MessageEventMessage = "message"
+	MessageEventRead    = "read"
+)
+
+// MessagingStatuses are the statuses in which the user and the assigned
+// driver of a shipment may message each other: from the assignment until the
+// shipment is closed
+var MessagingStatuses = []ShipmentStatus{StatusDriverAssigned, StatusPickupStarted, StatusInTransit, StatusDelivered, StatusDisputed}
+
+// ShipmentMessage is a message between the user of a shipment and its
+// assigned driver
+type ShipmentMessage struct {
+	ID         uuid.UUID  `db:"id" json:"id"`
+	ShipmentID uuid.UUID  `db:"shipment_id" json:"shipment_id"`
+	SenderID   uuid.UUID  `db:"sender_id" json:"sender_id"`
+	SenderRole string     `db:"sender_role" json:"sender_role"`
+	Body       string     `db:"body" json:"body"`
+	ReadAt     *time.Time `db:"read_at" json:"read_at,omitempty"` // When the other party read it
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+}
+
+// SendMessageRequest represents a message sent on a shipment
+type SendMessageRequest struct {
+	Body string `json:"body" binding:"required,max=2000"`
+}
+
+// ReadReceipt lists the messages a party of a shipment read at once
+type ReadReceipt struct {
+	ShipmentID uuid.UUID   `json:"shipment_id"`
+	ReaderID   uuid.UUID   `json:"reader_id"`
+	MessageIDs []uuid.UUID `json:"message_ids"`
+	ReadAt     time.Time   `json:"read_at"`
+}
+
+// MessageEvent is pushed to the clients watching a shipment's messages: a
+// new message, or the receipt of messages the other party read
+type MessageEvent struct {
+	Type       string           `json:"type"`
+	ShipmentID uuid.UUID        `json:"-"`
+	Message    *ShipmentMessage `json:"message,omitempty"`
+	Receipt    *ReadReceipt     `json:"receipt,omitempty"`
+}
+
+// AcceptsMessages returns true if the user and the assigned driver of the
+// shipment may message each other in its current status
+func (s *Shipment) AcceptsMessages() bool {
+	if s.DriverID == nil {
+		return false
+	}
+	for _, status := range MessagingStatuses {
+		if s.Status == status {
+			return true
+		}
+	}
+	return false
+}
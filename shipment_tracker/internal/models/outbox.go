@@ -0,0 +1,20 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxEvent is an event waiting in the outbox to be published on NATS
+type OutboxEvent struct {
+	ID            uuid.UUID       `db:"id"`
+	Subject       string          `db:"subject"`
+	Payload       json.RawMessage `db:"payload"`
+	Attempts      int             `db:"attempts"`
+	LastError     *string         `db:"last_error"`
+	NextAttemptAt time.Time       `db:"next_attempt_at"`
+	CreatedAt     time.Time       `db:"created_at"`
+	PublishedAt   *time.Time      `db:"published_at"`
+}
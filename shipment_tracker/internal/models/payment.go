@@ -0,0 +1,90 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/pkg/response"
+)
+
+// EscrowStatus represents the state of the funds held for a shipment
+type EscrowStatus string
+
+const (
+	EscrowNone     EscrowStatus = "none"
+	EscrowHeld     EscrowStatus = "held"
+	EscrowReleased EscrowStatus = "released" // paid to the driver
+	EscrowRefunded EscrowStatus = "refunded" // paid back to the user
+	EscrowSplit    EscrowStatus = "split"    // shared between both after a dispute
+)
+
+// PaymentType represents the kind of money movement of a payment
+type PaymentType string
+
+const (
+	PaymentFunding PaymentType = "funding"
+	PaymentRelease PaymentType = "release"
+	PaymentRefund  PaymentType = "refund"
+	// PaymentCancellationFee pays the driver the fee of a late cancellation
+	PaymentCancellationFee PaymentType = "cancellation_fee"
+)
+
+// PaymentStatus represents the outcome of a payment with the provider
+type PaymentStatus string
+
+const (
+	PaymentSucceeded PaymentStatus = "succeeded"
+	PaymentFailed    PaymentStatus = "failed"
+)
+
+// DisputeOutcome decides how the escrow of a disputed shipment is settled
+type DisputeOutcome string
+
+const (
+	OutcomeUserWins   DisputeOutcome = "user_wins"   // refunded to the user
+	OutcomeDriverWins DisputeOutcome = "driver_wins" // released to the driver
+	OutcomeSplit      DisputeOutcome = "split"       // half each
+)
+
+// IsValid returns true if the outcome is one of the known outcomes
+func (o DisputeOutcome) IsValid() bool {
+	switch o {
+	case OutcomeUserWins, OutcomeDriverWins, OutcomeSplit:
+		return true
+	}
+	return false
+}
+
+// Payment is an entry of the payments ledger of a shipment
+type Payment struct {
+	ID                uuid.UUID     `db:"id" json:"id"`
+	ShipmentID        uuid.UUID     `db:"shipment_id" json:"shipment_id"`
+	Type              PaymentType   `db:"type" json:"type"`
+	Status            PaymentStatus `db:"status" json:"status"`
+	Amount            float64       `db:"amount" json:"amount"`
+	Currency          string        `db:"currency" json:"currency"`
+	PayerID           *uuid.UUID    `db:"payer_id" json:"payer_id,omitempty"`
+	RecipientID       *uuid.UUID    `db:"recipient_id" json:"recipient_id,omitempty"`
+	Provider          string        `db:"provider" json:"provider"`
+	ProviderReference *string       `db:"provider_reference" json:"provider_reference,omitempty"`
+	Error             *string       `db:"error" json:"error,omitempty"`
+	CreatedAt         time.Time     `db:"created_at" json:"created_at"`
+}
+
+// FundShipmentRequest represents the request to confirm the price of a
+// shipment by funding its escrow
+type FundShipmentRequest struct {
+	PaymentMethod string `json:"payment_method"` // provider specific, e.g. a Stripe payment method ID
+}
+
+// SettleShipmentRequest represents the request to settle the escrow of a
+// shipment; the outcome is required for disputed and resolved shipments
+type SettleShipmentRequest struct {
+	Outcome DisputeOutcome `json:"outcome"`
+}
+
+// PaymentListResponse represents a page of payments
+type PaymentListResponse struct {
+	Data []Payment            `json:"data"`
+	Meta *response.Pagination `json:"meta"`
+}
@@ -0,0 +1,21 @@
+package models
+
+// Role represents an access role granted to an authenticated principal
+type Role string
+
+const (
+	RoleAdmin      Role = "admin"
+	RoleDispatcher Role = "dispatcher"
+	RoleDriver     Role = "driver"
+	RoleCompany    Role = "company"
+	RoleCitizen    Role = "citizen"
+)
+
+// IsValid returns true if the role is one of the known roles
+func (r Role) IsValid() bool {
+	switch r {
+	case RoleAdmin, RoleDispatcher, RoleDriver, RoleCompany, RoleCitizen:
+		return true
+	}
+	return false
+}
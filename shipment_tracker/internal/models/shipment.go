@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/smartwaste/pkg/events"
+	"github.com/smartwaste/pkg/response"
 )
 
 // ShipmentStatus represents the status of a shipment
@@ -22,6 +24,17 @@ const (
 	StatusResolved       ShipmentStatus = "resolved"
 )
 
+// IsValid returns true if the status is one of the known statuses
+func (s ShipmentStatus) IsValid() bool {
+	switch s {
+	case StatusCreated, StatusPriceConfirmed, StatusDriverAssigned, StatusPickupStarted,
+		StatusInTransit, StatusDelivered, StatusCompleted, StatusCancelled,
+		StatusDisputed, StatusResolved:
+		return true
+	}
+	return false
+}
+
 // ValidTransitions defines valid state transitions
 var ValidTransitions = map[ShipmentStatus][]ShipmentStatus{
 	StatusCreated:        {StatusPriceConfirmed, StatusCancelled},
@@ -43,34 +56,40 @@ type Location struct {
 
 // Shipment represents a waste shipment
 type Shipment struct {
-	ID                uuid.UUID      `db:"id" json:"id"`
-	UserID            uuid.UUID      `db:"user_id" json:"user_id"`
-	DriverID          *uuid.UUID     `db:"driver_id" json:"driver_id,omitempty"`
-	CollectionID      uuid.UUID      `db:"collection_id" json:"collection_id"`
-	WasteType         string         `db:"waste_type" json:"waste_type"`
-	EstimatedWeightKg float64        `db:"estimated_weight_kg" json:"estimated_weight_kg"`
-	ActualWeightKg    *float64       `db:"actual_weight_kg" json:"actual_weight_kg,omitempty"`
-	PriceOffered      float64        `db:"price_offered" json:"price_offered"`
-	PriceConfirmed    bool           `db:"price_confirmed" json:"price_confirmed"`
-	ContractAddress   *string        `db:"contract_address" json:"contract_address,omitempty"`
-	ContractTxHash    *string        `db:"contract_tx_hash" json:"contract_tx_hash,omitempty"`
-	Status            ShipmentStatus `db:"status" json:"status"`
-	PickupLatitude    *float64       `db:"pickup_latitude" json:"pickup_latitude,omitempty"`
-	PickupLongitude   *float64       `db:"pickup_longitude" json:"pickup_longitude,omitempty"`
-	PickupAddress     *string        `db:"pickup_address" json:"pickup_address,omitempty"`
-	DropoffLatitude   *float64       `db:"dropoff_latitude" json:"dropoff_latitude,omitempty"`
-	DropoffLongitude  *float64       `db:"dropoff_longitude" json:"dropoff_longitude,omitempty"`
-	DropoffAddress    *string        `db:"dropoff_address" json:"dropoff_address,omitempty"`
-	Notes             *string        `db:"notes" json:"notes,omitempty"`
-	CreatedAt         time.Time      `db:"created_at" json:"created_at"`
-	UpdatedAt         time.Time      `db:"updated_at" json:"updated_at"`
+	ID                 uuid.UUID           `db:"id" json:"id"`
+	UserID             uuid.UUID           `db:"user_id" json:"user_id"`
+	DriverID           *uuid.UUID          `db:"driver_id" json:"driver_id,omitempty"`
+	CollectionID       uuid.UUID           `db:"collection_id" json:"collection_id"`
+	WasteType          string              `db:"waste_type" json:"waste_type"`
+	EstimatedWeightKg  float64             `db:"estimated_weight_kg" json:"estimated_weight_kg"`
+	ActualWeightKg     *float64            `db:"actual_weight_kg" json:"actual_weight_kg,omitempty"`
+	PriceOffered       float64             `db:"price_offered" json:"price_offered"`
+	PriceConfirmed     bool                `db:"price_confirmed" json:"price_confirmed"`
+	ContractAddress    *string             `db:"contract_address" json:"contract_address,omitempty"`
+	ContractTxHash     *string             `db:"contract_tx_hash" json:"contract_tx_hash,omitempty"`
+	Status             ShipmentStatus      `db:"status" json:"status"`
+	PickupLatitude     *float64            `db:"pickup_latitude" json:"pickup_latitude,omitempty"`
+	PickupLongitude    *float64            `db:"pickup_longitude" json:"pickup_longitude,omitempty"`
+	PickupAddress      *string             `db:"pickup_address" json:"pickup_address,omitempty"`
+	DropoffLatitude    *float64            `db:"dropoff_latitude" json:"dropoff_latitude,omitempty"`
+	DropoffLongitude   *float64            `db:"dropoff_longitude" json:"dropoff_longitude,omitempty"`
+	DropoffAddress     *string             `db:"dropoff_address" json:"dropoff_address,omitempty"`
+	Notes              *string             `db:"notes" json:"notes,omitempty"`
+	EscrowStatus       EscrowStatus        `db:"escrow_status" json:"escrow_status"`
+	EscrowAmount       *float64            `db:"escrow_amount" json:"escrow_amount,omitempty"`
+	EscrowProvider     *string             `db:"escrow_provider" json:"escrow_provider,omitempty"`
+	EscrowReference    *string             `db:"escrow_reference" json:"-"`
+	CancellationReason *CancellationReason `db:"cancellation_reason" json:"cancellation_reason,omitempty"`
+	CancellationFee    *float64            `db:"cancellation_fee" json:"cancellation_fee,omitempty"`
+	CreatedAt          time.Time           `db:"created_at" json:"created_at"`
+	UpdatedAt          time.Time           `db:"updated_at" json:"updated_at"`
 }
 
 // CreateShipmentRequest represents the request to create a new shipment
 type CreateShipmentRequest struct {
 	UserID            uuid.UUID `json:"user_id" binding:"required"`
 	CollectionID      uuid.UUID `json:"collection_id" binding:"required"`
-	WasteType         string    `json:"waste_type" binding:"required"`
+	WasteType         string    `json:"waste_type" binding:"required,waste_type"`
 	EstimatedWeightKg float64   `json:"estimated_weight_kg" binding:"required,gt=0"`
 	PriceOffered      float64   `json:"price_offered" binding:"required,gt=0"`
 	PickupLocation    *Location `json:"pickup_location"`
@@ -88,7 +107,7 @@ type ConfirmPickupRequest struct {
 	ConfirmedBy  uuid.UUID `json:"confirmed_by" binding:"required"`
 	Role         string    `json:"role" binding:"required"` // "user" or "driver"
 	ProofHash    *string   `json:"proof_hash"`
-	ActualWeight *float64  `json:"actual_weight_kg"`
+	ActualWeight *float64  `json:"actual_weight_kg" binding:"omitempty,gt=0"`
 	Signature    string    `json:"signature" binding:"required"`
 }
 
@@ -108,43 +127,69 @@ type RaiseDisputeRequest struct {
 	EvidenceHash *string   `json:"evidence_hash"`
 }
 
+// ShipmentFilter narrows a shipment listing; nil fields do not filter
+type ShipmentFilter struct {
+	UserID       *uuid.UUID
+	DriverID     *uuid.UUID
+	CollectionID *uuid.UUID
+	Status       *ShipmentStatus
+	From         *time.Time // created at or after
+	To           *time.Time // created before
+	// ParticipantID keeps the shipments the principal created or drives
+	ParticipantID *uuid.UUID
+}
+
+// ShipmentListResponse represents a page of shipments
+type ShipmentListResponse struct {
+	Data []*ShipmentResponse  `json:"data"`
+	Meta *response.Pagination `json:"meta"`
+}
+
 // ShipmentResponse represents the API response for a shipment
 type ShipmentResponse struct {
-	ID                uuid.UUID      `json:"id"`
-	UserID            uuid.UUID      `json:"user_id"`
-	DriverID          *uuid.UUID     `json:"driver_id,omitempty"`
-	CollectionID      uuid.UUID      `json:"collection_id"`
-	WasteType         string         `json:"waste_type"`
-	EstimatedWeightKg float64        `json:"estimated_weight_kg"`
-	ActualWeightKg    *float64       `json:"actual_weight_kg,omitempty"`
-	PriceOffered      float64        `json:"price_offered"`
-	PriceConfirmed    bool           `json:"price_confirmed"`
-	ContractAddress   *string        `json:"contract_address,omitempty"`
-	Status            ShipmentStatus `json:"status"`
-	PickupLocation    *Location      `json:"pickup_location,omitempty"`
-	DropoffLocation   *Location      `json:"dropoff_location,omitempty"`
-	Notes             *string        `json:"notes,omitempty"`
-	CreatedAt         time.Time      `json:"created_at"`
-	UpdatedAt         time.Time      `json:"updated_at"`
+	ID                 uuid.UUID           `json:"id"`
+	UserID             uuid.UUID           `json:"user_id"`
+	DriverID           *uuid.UUID          `json:"driver_id,omitempty"`
+	CollectionID       uuid.UUID           `json:"collection_id"`
+	WasteType          string              `json:"waste_type"`
+	EstimatedWeightKg  float64             `json:"estimated_weight_kg"`
+	ActualWeightKg     *float64            `json:"actual_weight_kg,omitempty"`
+	PriceOffered       float64             `json:"price_offered"`
+	PriceConfirmed     bool                `json:"price_confirmed"`
+	ContractAddress    *string             `json:"contract_address,omitempty"`
+	Status             ShipmentStatus      `json:"status"`
+	PickupLocation     *Location           `json:"pickup_location,omitempty"`
+	DropoffLocation    *Location           `json:"dropoff_location,omitempty"`
+	Notes              *string             `json:"notes,omitempty"`
+	EscrowStatus       EscrowStatus        `json:"escrow_status"`
+	EscrowAmount       *float64            `json:"escrow_amount,omitempty"`
+	CancellationReason *CancellationReason `json:"cancellation_reason,omitempty"`
+	CancellationFee    *float64            `json:"cancellation_fee,omitempty"`
+	CreatedAt          time.Time           `json:"created_at"`
+	UpdatedAt          time.Time           `json:"updated_at"`
 }
 
 // ToResponse converts Shipment to ShipmentResponse
 func (s *Shipment) ToResponse() *ShipmentResponse {
 	resp := &ShipmentResponse{
-		ID:                s.ID,
-		UserID:            s.UserID,
-		DriverID:          s.DriverID,
-		CollectionID:      s.CollectionID,
-		WasteType:         s.WasteType,
-		EstimatedWeightKg: s.EstimatedWeightKg,
-		ActualWeightKg:    s.ActualWeightKg,
-		PriceOffered:      s.PriceOffered,
-		PriceConfirmed:    s.PriceConfirmed,
-		ContractAddress:   s.ContractAddress,
-		Status:            s.Status,
-		Notes:             s.Notes,
-		CreatedAt:         s.CreatedAt,
-		UpdatedAt:         s.UpdatedAt,
+		ID:                 s.ID,
+		UserID:             s.UserID,
+		DriverID:           s.DriverID,
+		CollectionID:       s.CollectionID,
+		WasteType:          s.WasteType,
+		EstimatedWeightKg:  s.EstimatedWeightKg,
+		ActualWeightKg:     s.ActualWeightKg,
+		PriceOffered:       s.PriceOffered,
+		PriceConfirmed:     s.PriceConfirmed,
+		ContractAddress:    s.ContractAddress,
+		Status:             s.Status,
+		Notes:              s.Notes,
+		EscrowStatus:       s.EscrowStatus,
+		EscrowAmount:       s.EscrowAmount,
+		CancellationReason: s.CancellationReason,
+		CancellationFee:    s.CancellationFee,
+		CreatedAt:          s.CreatedAt,
+		UpdatedAt:          s.UpdatedAt,
 	}
 
 	if s.PickupLatitude != nil && s.PickupLongitude != nil {
@@ -170,6 +215,26 @@ func (s *Shipment) ToResponse() *ShipmentResponse {
 	return resp
 }
 
+// CreatedEvent returns the data of the shipment.created event of the shipment
+func (s *Shipment) CreatedEvent() *events.ShipmentCreated {
+	return &events.ShipmentCreated{
+		ShipmentID:        s.ID,
+		UserID:            s.UserID,
+		CollectionID:      s.CollectionID,
+		WasteType:         s.WasteType,
+		EstimatedWeightKg: s.EstimatedWeightKg,
+		PriceOffered:      s.PriceOffered,
+		Status:            string(s.Status),
+		PickupLatitude:    s.PickupLatitude,
+		PickupLongitude:   s.PickupLongitude,
+		PickupAddress:     s.PickupAddress,
+		DropoffLatitude:   s.DropoffLatitude,
+		DropoffLongitude:  s.DropoffLongitude,
+		DropoffAddress:    s.DropoffAddress,
+		CreatedAt:         s.CreatedAt,
+	}
+}
+
 // CanTransitionTo checks if the shipment can transition to the given status
 func (s *Shipment) CanTransitionTo(newStatus ShipmentStatus) bool {
 	validNextStates, exists := ValidTransitions[s.Status]
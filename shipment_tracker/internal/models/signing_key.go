@@ -0,0 +1,75 @@
+package models
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SigningKey is an Ed25519 public key a user or driver registered to sign
+// their shipment confirmations
+type SigningKey struct {
+	ID        uuid.UUID  `db:"id" json:"id"`
+	SubjectID uuid.UUID  `db:"subject_id" json:"subject_id"`
+	Name      string     `db:"name" json:"name"`
+	PublicKey string     `db:"public_key" json:"public_key"`
+	RevokedAt *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+}
+
+// RegisterSigningKeyRequest represents the request to register a public key
+type RegisterSigningKeyRequest struct {
+	Name      string `json:"name" binding:"required,max=100"`
+	PublicKey string `json:"public_key" binding:"required"` // base64
+}
+
+// ErrInvalidPublicKey is returned for a public key that is not a base64
+// encoded Ed25519 key
+var ErrInvalidPublicKey = errors.New("public key must be a base64 encoded Ed25519 key")
+
+// ParsePublicKey decodes a base64 encoded Ed25519 public key
+func ParsePublicKey(value string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, ErrInvalidPublicKey
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// Verify returns true if signature is a valid base64 encoded signature of
+// message by the key
+func (k *SigningKey) Verify(message []byte, signature string) bool {
+	key, err := ParsePublicKey(k.PublicKey)
+	if err != nil {
+		return false
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return false
+	}
+	return ed25519.Verify(key, message, sig)
+}
+
+// ConfirmationMessage builds the message a party signs to confirm that a
+// shipment reached status:
+//
+//	smartwaste:shipment:<shipment_id>:<status>:<confirmed_by>:<proof_hash>:<actual_weight_kg>
+//
+// with empty fields for a missing proof hash or weight.
+func ConfirmationMessage(shipmentID uuid.UUID, status ShipmentStatus, confirmedBy uuid.UUID, proofHash *string, actualWeight *float64) []byte {
+	var hash, weight string
+	if proofHash != nil {
+		hash = *proofHash
+	}
+	if actualWeight != nil {
+		weight = strconv.FormatFloat(*actualWeight, 'f', -1, 64)
+	}
+	return []byte(strings.Join([]string{
+		"smartwaste", "shipment", shipmentID.String(), string(status), confirmedBy.String(), hash, weight,
+	}, ":"))
+}
@@ -1,7 +1,10 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,11 +20,113 @@ type StateTransition struct {
 	TriggeredByRole string          `db:"triggered_by_role" json:"triggered_by_role"`
 	ProofHash       *string         `db:"proof_hash" json:"proof_hash,omitempty"`
 	Signature       *string         `db:"signature" json:"signature,omitempty"`
+	SigningKeyID    *uuid.UUID      `db:"signing_key_id" json:"signing_key_id,omitempty"`
 	TxHash          *string         `db:"tx_hash" json:"tx_hash,omitempty"`
 	Metadata        json.RawMessage `db:"metadata" json:"metadata,omitempty"`
+	PreviousHash    *string         `db:"previous_hash" json:"previous_hash,omitempty"`
+	Hash            *string         `db:"hash" json:"hash,omitempty"`
 	CreatedAt       time.Time       `db:"created_at" json:"created_at"`
 }
 
+// ComputeHash returns the hex SHA-256 of the transition's content and its
+// previous hash. Metadata is hashed in its canonical JSON form and the time in
+// microseconds, as the database stores them.
+func (t *StateTransition) ComputeHash() string {
+	fields := []string{
+		stringValue(t.PreviousHash),
+		t.ID.String(),
+		t.ShipmentID.String(),
+		"",
+		string(t.ToStatus),
+		t.TriggeredBy.String(),
+		t.TriggeredByRole,
+		stringValue(t.ProofHash),
+		stringValue(t.Signature),
+		"",
+		canonicalJSON(t.Metadata),
+		t.CreatedAt.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano),
+	}
+	if t.FromStatus != nil {
+		fields[3] = string(*t.FromStatus)
+	}
+	if t.SigningKeyID != nil {
+		fields[9] = t.SigningKeyID.String()
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// ChainVerification reports whether the transition history of a shipment is
+// intact
+type ChainVerification struct {
+	ShipmentID  uuid.UUID `json:"shipment_id"`
+	Valid       bool      `json:"valid"`
+	Transitions int       `json:"transitions"`
+	// Unchained counts the transitions recorded before hash chaining existed
+	Unchained int        `json:"unchained"`
+	BrokenAt  *uuid.UUID `json:"broken_at,omitempty"`
+	Reason    string     `json:"reason,omitempty"`
+}
+
+// VerifyChain checks the hash chain of a shipment's transitions, oldest
+// first. Transitions recorded before chaining existed are counted as
+// unchained; they may only precede the chain.
+func VerifyChain(shipmentID uuid.UUID, transitions []StateTransition) *ChainVerification {
+	result := &ChainVerification{ShipmentID: shipmentID, Valid: true, Transitions: len(transitions)}
+	fail := func(t *StateTransition, reason string) *ChainVerification {
+		result.Valid = false
+		result.BrokenAt = &t.ID
+		result.Reason = reason
+		return result
+	}
+
+	var previous *string
+	for i := range transitions {
+		t := &transitions[i]
+		if t.Hash == nil {
+			if previous != nil {
+				return fail(t, "transition without hash inside the chain")
+			}
+			result.Unchained++
+			continue
+		}
+		if stringValue(t.PreviousHash) != stringValue(previous) {
+			return fail(t, "previous hash does not match the preceding transition")
+		}
+		if t.ComputeHash() != *t.Hash {
+			return fail(t, "hash does not match the transition content")
+		}
+		previous = t.Hash
+	}
+	return result
+}
+
+// stringValue dereferences an optional string, empty when nil
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// canonicalJSON re-encodes a JSON document with sorted keys and no spacing,
+// the empty object when there is none
+func canonicalJSON(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return "{}"
+	}
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return string(raw)
+	}
+	canonical, err := json.Marshal(value)
+	if err != nil {
+		return string(raw)
+	}
+	return string(canonical)
+}
+
 // TransitionMetadata holds additional data for a state transition
 type TransitionMetadata struct {
 	ActualWeight *float64  `json:"actual_weight_kg,omitempty"`
@@ -52,7 +157,11 @@ type TransitionResponse struct {
 	TriggeredBy     uuid.UUID       `json:"triggered_by"`
 	TriggeredByRole string          `json:"triggered_by_role"`
 	ProofHash       *string         `json:"proof_hash,omitempty"`
+	Signature       *string         `json:"signature,omitempty"`
+	SigningKeyID    *uuid.UUID      `json:"signing_key_id,omitempty"`
 	TxHash          *string         `json:"tx_hash,omitempty"`
+	PreviousHash    *string         `json:"previous_hash,omitempty"`
+	Hash            *string         `json:"hash,omitempty"`
 	CreatedAt       time.Time       `json:"created_at"`
 }
 
@@ -66,7 +175,11 @@ func (t *StateTransition) ToResponse() *TransitionResponse {
 		TriggeredBy:     t.TriggeredBy,
 		TriggeredByRole: t.TriggeredByRole,
 		ProofHash:       t.ProofHash,
+		Signature:       t.Signature,
+		SigningKeyID:    t.SigningKeyID,
 		TxHash:          t.TxHash,
+		PreviousHash:    t.PreviousHash,
+		Hash:            t.Hash,
 		CreatedAt:       t.CreatedAt,
 	}
 }
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TrackPoint is a position of the assigned driver reported while a shipment
+// is on its way
+type TrackPoint struct {
+	ID         uuid.UUID      `db:"id" json:"id"`
+	ShipmentID uuid.UUID      `db:"shipment_id" json:"shipment_id"`
+	DriverID   uuid.UUID      `db:"driver_id" json:"driver_id"`
+	Status     ShipmentStatus `db:"status" json:"status"`
+	Latitude   float64        `db:"latitude" json:"latitude"`
+	Longitude  float64        `db:"longitude" json:"longitude"`
+	AccuracyM  *float64       `db:"accuracy_m" json:"accuracy_m,omitempty"`
+	SpeedKmh   *float64       `db:"speed_kmh" json:"speed_kmh,omitempty"`
+	Heading    *float64       `db:"heading" json:"heading,omitempty"`
+	RecordedAt time.Time      `db:"recorded_at" json:"recorded_at"`
+	CreatedAt  time.Time      `db:"created_at" json:"created_at"`
+}
+
+// RecordLocationRequest represents a GPS position pushed by the driver app
+type RecordLocationRequest struct {
+	Latitude   *float64   `json:"latitude" binding:"required,latitude"`
+	Longitude  *float64   `json:"longitude" binding:"required,longitude"`
+	AccuracyM  *float64   `json:"accuracy_m" binding:"omitempty,gte=0"`
+	SpeedKmh   *float64   `json:"speed_kmh" binding:"omitempty,gte=0"`
+	Heading    *float64   `json:"heading" binding:"omitempty,gte=0,lt=360"`
+	RecordedAt *time.Time `json:"recorded_at"` // Defaults to when the position is received
+}
+
+// TrackingStatuses are the statuses in which the driver's position is
+// tracked: on the way to the pickup, during it and in transit
+var TrackingStatuses = []ShipmentStatus{StatusDriverAssigned, StatusPickupStarted, StatusInTransit}
+
+// IsTracked returns true if the driver's position is tracked in the
+// shipment's current status
+func (s *Shipment) IsTracked() bool {
+	for _, status := range TrackingStatuses {
+		if s.Status == status {
+			return true
+		}
+	}
+	return false
+}
@@ -1,12 +1,14 @@
 package nats
 
 import (
-	"encoding/json"
+	"errors"
 	"log"
 	"time"
 
 	"github.com/nats-io/nats.go"
+	"github.com/smartwaste/pkg/events"
 	"github.com/smartwaste/shipment-tracker/internal/config"
+	"github.com/smartwaste/shipment-tracker/internal/metrics"
 )
 
 // Client represents a NATS client
@@ -28,7 +30,10 @@ func (c *Client) Connect() error {
 	opts := []nats.Option{
 		nats.Name("Shipment Tracker Service"),
 		nats.ReconnectWait(2 * time.Second),
-		nats.MaxReconnects(10),
+		nats.MaxReconnects(-1),
+		// Keep trying in the background if NATS is down at startup; the
+		// outbox holds the events until the connection is up
+		nats.RetryOnFailedConnect(true),
 		nats.DisconnectErrHandler(func(nc *nats.Conn, err error) {
 			log.Printf("Disconnected from NATS: %v", err)
 		}),
@@ -64,6 +69,11 @@ func (c *Client) Connect() error {
 	return nil
 }
 
+// IsConnected returns true while the connection to NATS is up
+func (c *Client) IsConnected() bool {
+	return c.conn != nil && c.conn.IsConnected()
+}
+
 // Close closes the NATS connection
 func (c *Client) Close() {
 	if c.conn != nil {
@@ -71,16 +81,28 @@ func (c *Client) Close() {
 	}
 }
 
-// Publish publishes a message to a subject
-func (c *Client) Publish(subject string, data interface{}) error {
-	payload, err := json.Marshal(data)
+// Publish publishes an event to JetStream and waits for the stream to store
+// it. JetStream drops a message whose ID it stored recently, so publishing an
+// event again after a lost acknowledgement does not duplicate it.
+func (c *Client) Publish(subject, msgID string, payload []byte) error {
+	if c.js == nil {
+		metrics.NATSPublishes.WithLabelValues(subject, "error").Inc()
+		return errors.New("not connected to NATS")
+	}
+
+	_, err := c.js.Publish(subject, payload, nats.MsgId(msgID))
+	if errors.Is(err, nats.ErrNoStreamResponse) {
+		// The stream could not be created while NATS was down
+		if streamErr := c.createStreams(); streamErr == nil {
+			_, err = c.js.Publish(subject, payload, nats.MsgId(msgID))
+		}
+	}
 	if err != nil {
+		metrics.NATSPublishes.WithLabelValues(subject, "error").Inc()
 		return err
 	}
-
-	// Use JetStream publish for persistence if needed, or standard publish for fire-and-forget
-	// Here we use standard publish for simplicity, but could switch to js.Publish
-	return c.conn.Publish(subject, payload)
+	metrics.NATSPublishes.WithLabelValues(subject, "ok").Inc()
+	return nil
 }
 
 // Subscribe subscribes to a subject
@@ -95,7 +117,7 @@ func (c *Client) createStreams() error {
 	// Define Shipment Stream
 	_, err := c.js.AddStream(&nats.StreamConfig{
 		Name:     "SHIPMENTS",
-		Subjects: []string{"shipment.>"},
+		Subjects: []string{events.SubjectShipments},
 		Storage:  nats.FileStorage,
 	})
 	if err != nil {
@@ -0,0 +1,115 @@
+package outbox
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/smartwaste/shipment-tracker/internal/config"
+	"github.com/smartwaste/shipment-tracker/internal/metrics"
+	"github.com/smartwaste/shipment-tracker/internal/models"
+	"github.com/smartwaste/shipment-tracker/internal/repository"
+)
+
+// cleanupInterval is how often events past their retention are deleted
+const cleanupInterval = time.Hour
+
+// Publisher publishes an event, deduplicated by its message ID
+type Publisher interface {
+	Publish(subject, msgID string, payload []byte) error
+}
+
+// Relayer drains the outbox to NATS. Several instances of the service may
+// run a relayer; each event is claimed by one of them at a time.
+type Relayer struct {
+	repo      *repository.OutboxRepository
+	publisher Publisher
+	cfg       *config.OutboxConfig
+}
+
+// NewRelayer creates a new Relayer
+func NewRelayer(repo *repository.OutboxRepository, publisher Publisher, cfg *config.OutboxConfig) *Relayer {
+	return &Relayer{repo: repo, publisher: publisher, cfg: cfg}
+}
+
+// Run relays due events every poll interval until ctx is done
+func (r *Relayer) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+	lastCleanup := time.Now()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// Keep relaying while there are full batches
+		for {
+			published, err := r.RelayBatch(ctx)
+			if err != nil {
+				log.Printf("Failed to relay outbox events: %v", err)
+				break
+			}
+			if published < r.cfg.BatchSize {
+				break
+			}
+		}
+
+		if pending, err := r.repo.CountPending(ctx); err == nil {
+			metrics.OutboxPending.Set(float64(pending))
+		}
+
+		if r.cfg.Retention > 0 && time.Since(lastCleanup) >= cleanupInterval {
+			lastCleanup = time.Now()
+			if _, err := r.repo.DeletePublishedBefore(ctx, time.Now().Add(-r.cfg.Retention)); err != nil {
+				log.Printf("Failed to delete published outbox events: %v", err)
+			}
+		}
+	}
+}
+
+// RelayBatch publishes a batch of due events in the order they were written,
+// returning how many it published. An event that fails is retried later with
+// an exponential backoff, and the batch stops there: failures are nearly
+// always NATS being unreachable, which the following events would wait for
+// too.
+func (r *Relayer) RelayBatch(ctx context.Context) (int, error) {
+	var published int
+	err := r.repo.WithTx(ctx, func(tx *sqlx.Tx) error {
+		repo := r.repo.Tx(tx)
+		events, err := repo.ClaimDue(ctx, r.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+
+		for i := range events {
+			event := &events[i]
+			if err := r.publisher.Publish(event.Subject, event.ID.String(), event.Payload); err != nil {
+				log.Printf("Failed to publish outbox event %s on %s (attempt %d): %v", event.ID, event.Subject, event.Attempts+1, err)
+				return repo.MarkFailed(ctx, event.ID, err.Error(), time.Now().Add(r.backoff(event)))
+			}
+			if err := repo.MarkPublished(ctx, event.ID); err != nil {
+				return err
+			}
+			published++
+		}
+		return nil
+	})
+	return published, err
+}
+
+// backoff returns how long to wait before retrying an event again, doubling
+// with each failed attempt up to the maximum backoff
+func (r *Relayer) backoff(event *models.OutboxEvent) time.Duration {
+	delay := r.cfg.PollInterval
+	for i := 0; i < event.Attempts && delay < r.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > r.cfg.MaxBackoff {
+		delay = r.cfg.MaxBackoff
+	}
+	return delay
+}
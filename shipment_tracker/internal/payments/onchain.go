@@ -0,0 +1,121 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+)
+
+// Function selectors of the escrow contract
+const (
+	selectorDeposit = "b214faa5" // deposit(bytes32 shipmentId) payable
+	selectorSettle  = "79f48d4c" // settle(bytes32 shipmentId, uint256 released)
+)
+
+// OnChainProvider holds escrows in an escrow smart contract. The platform
+// account deposits the price for the shipment; settling releases part of it
+// to the contract's beneficiary, which pays the drivers, and returns the rest
+// to the platform account for the refund. Transactions are sent with
+// eth_sendTransaction, so the node or signer behind the RPC URL must manage
+// the platform account.
+type OnChainProvider struct {
+	client     *http.Client
+	rpcURL     string
+	contract   string
+	account    string
+	weiPerUnit *big.Int
+}
+
+// NewOnChainProvider creates a new OnChainProvider
+func NewOnChainProvider(client *http.Client, rpcURL, contract, account string, weiPerUnit *big.Int) *OnChainProvider {
+	return &OnChainProvider{
+		client:     client,
+		rpcURL:     rpcURL,
+		contract:   contract,
+		account:    account,
+		weiPerUnit: weiPerUnit,
+	}
+}
+
+// Name returns the provider name
+func (p *OnChainProvider) Name() string {
+	return ProviderOnChain
+}
+
+// Hold deposits the amount in the escrow contract and returns the
+// transaction hash
+func (p *OnChainProvider) Hold(ctx context.Context, hold *Hold) (string, error) {
+	shipmentID := hold.ShipmentID
+	data := selectorDeposit + hex.EncodeToString(append(shipmentID[:], make([]byte, 16)...))
+	return p.sendTransaction(ctx, data, p.toWei(hold.Amount))
+}
+
+// Settle releases the released amount to the beneficiary, refunding the rest,
+// and returns the transaction hash
+func (p *OnChainProvider) Settle(ctx context.Context, settlement *Settlement) (string, error) {
+	shipmentID := settlement.ShipmentID
+	released := make([]byte, 32)
+	p.toWei(settlement.Release).FillBytes(released)
+	data := selectorSettle + hex.EncodeToString(append(shipmentID[:], make([]byte, 16)...)) + hex.EncodeToString(released)
+	return p.sendTransaction(ctx, data, new(big.Int))
+}
+
+// toWei converts an amount of the currency to wei
+func (p *OnChainProvider) toWei(amount float64) *big.Int {
+	value, _ := new(big.Rat).SetString(strconv.FormatFloat(amount, 'f', -1, 64))
+	value.Mul(value, new(big.Rat).SetInt(p.weiPerUnit))
+	return new(big.Int).Quo(value.Num(), value.Denom())
+}
+
+// rpcResponse is a JSON-RPC response
+type rpcResponse struct {
+	Result string `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// sendTransaction sends a contract call from the platform account
+func (p *OnChainProvider) sendTransaction(ctx context.Context, data string, value *big.Int) (string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "eth_sendTransaction",
+		"params": []map[string]string{{
+			"from":  p.account,
+			"to":    p.contract,
+			"value": "0x" + value.Text(16),
+			"data":  "0x" + data,
+		}},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.rpcURL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("escrow transaction failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("invalid JSON-RPC response: %w", err)
+	}
+	if result.Error != nil {
+		return "", fmt.Errorf("%w: %s", ErrDeclined, result.Error.Message)
+	}
+	return result.Result, nil
+}
@@ -0,0 +1,81 @@
+// Package payments holds the escrow of shipment prices with a payment
+// provider: funds are held when the user confirms the price and settled once
+// the shipment ends, released to the driver, refunded to the user or split.
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/shipment-tracker/internal/config"
+)
+
+// Provider names accepted by PAYMENTS_PROVIDER
+const (
+	ProviderStub    = "stub"    // Accepts every payment without moving money
+	ProviderStripe  = "stripe"  // Stripe PaymentIntents with manual capture
+	ProviderOnChain = "onchain" // Escrow smart contract through a JSON-RPC node
+)
+
+// httpTimeout bounds calls to payment providers
+const httpTimeout = 15 * time.Second
+
+// ErrDeclined is returned when the provider refuses a payment
+var ErrDeclined = errors.New("payment declined")
+
+// Hold describes funds to hold in escrow for a shipment
+type Hold struct {
+	ShipmentID    uuid.UUID
+	Amount        float64
+	Currency      string
+	PaymentMethod string // Provider specific instrument of the payer
+}
+
+// Settlement describes how held funds are paid out: Release goes to the
+// driver and Refund back to the payer, together the held amount
+type Settlement struct {
+	ShipmentID uuid.UUID
+	Reference  string // Reference returned when the funds were held
+	Release    float64
+	Refund     float64
+	Currency   string
+}
+
+// Provider moves the money of shipment escrows
+type Provider interface {
+	Name() string
+	// Hold places the funds in escrow and returns the provider's reference
+	Hold(ctx context.Context, hold *Hold) (string, error)
+	// Settle pays out held funds and returns the provider's reference
+	Settle(ctx context.Context, settlement *Settlement) (string, error)
+}
+
+// NewProvider creates the payment provider selected by the configuration
+func NewProvider(cfg *config.PaymentsConfig, chain *config.BlockchainConfig) (Provider, error) {
+	client := &http.Client{Timeout: httpTimeout}
+	switch cfg.Provider {
+	case ProviderStub, "":
+		return NewStubProvider(), nil
+	case ProviderStripe:
+		if cfg.StripeSecretKey == "" {
+			return nil, fmt.Errorf("payment provider %q requires STRIPE_SECRET_KEY", cfg.Provider)
+		}
+		return NewStripeProvider(client, cfg.StripeURL, cfg.StripeSecretKey), nil
+	case ProviderOnChain:
+		if chain.RPCURL == "" || cfg.EscrowContract == "" || cfg.EscrowAccount == "" {
+			return nil, fmt.Errorf("payment provider %q requires BLOCKCHAIN_RPC_URL, ESCROW_CONTRACT_ADDRESS and ESCROW_ACCOUNT", cfg.Provider)
+		}
+		weiPerUnit, ok := new(big.Int).SetString(cfg.WeiPerUnit, 10)
+		if !ok || weiPerUnit.Sign() <= 0 {
+			return nil, fmt.Errorf("payment provider %q requires a positive ESCROW_WEI_PER_UNIT", cfg.Provider)
+		}
+		return NewOnChainProvider(client, chain.RPCURL, cfg.EscrowContract, cfg.EscrowAccount, weiPerUnit), nil
+	default:
+		return nil, fmt.Errorf("unknown payment provider %q", cfg.Provider)
+	}
+}
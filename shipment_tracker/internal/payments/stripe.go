@@ -0,0 +1,127 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// StripeProvider holds escrows as uncaptured Stripe PaymentIntents: the
+// payment method is authorized at hold time and the released part captured
+// at settlement, Stripe returning the rest of the authorization to the payer
+type StripeProvider struct {
+	client    *http.Client
+	baseURL   string
+	secretKey string
+}
+
+// NewStripeProvider creates a new StripeProvider
+func NewStripeProvider(client *http.Client, baseURL, secretKey string) *StripeProvider {
+	return &StripeProvider{client: client, baseURL: strings.TrimSuffix(baseURL, "/"), secretKey: secretKey}
+}
+
+// Name returns the provider name
+func (p *StripeProvider) Name() string {
+	return ProviderStripe
+}
+
+// stripePaymentIntent is the part of a Stripe PaymentIntent the provider reads
+type stripePaymentIntent struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Error  *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Hold authorizes the amount on the payment method
+func (p *StripeProvider) Hold(ctx context.Context, hold *Hold) (string, error) {
+	if hold.PaymentMethod == "" {
+		return "", fmt.Errorf("%w: a Stripe payment method is required", ErrDeclined)
+	}
+	form := url.Values{
+		"amount":                {strconv.FormatInt(minorUnits(hold.Amount), 10)},
+		"currency":              {strings.ToLower(hold.Currency)},
+		"payment_method":        {hold.PaymentMethod},
+		"capture_method":        {"manual"},
+		"confirm":               {"true"},
+		"metadata[shipment_id]": {hold.ShipmentID.String()},
+	}
+	intent, err := p.post(ctx, "/v1/payment_intents", form, "hold-"+hold.ShipmentID.String())
+	if err != nil {
+		return "", err
+	}
+	if intent.Status != "requires_capture" {
+		return "", fmt.Errorf("%w: payment intent is %s", ErrDeclined, intent.Status)
+	}
+	return intent.ID, nil
+}
+
+// Settle captures the released amount, or cancels the authorization when
+// everything is refunded
+func (p *StripeProvider) Settle(ctx context.Context, settlement *Settlement) (string, error) {
+	idempotencyKey := "settle-" + settlement.ShipmentID.String()
+	path := "/v1/payment_intents/" + url.PathEscape(settlement.Reference)
+	if settlement.Release <= 0 {
+		intent, err := p.post(ctx, path+"/cancel", url.Values{}, idempotencyKey)
+		if err != nil {
+			return "", err
+		}
+		return intent.ID, nil
+	}
+
+	form := url.Values{"amount_to_capture": {strconv.FormatInt(minorUnits(settlement.Release), 10)}}
+	intent, err := p.post(ctx, path+"/capture", form, idempotencyKey)
+	if err != nil {
+		return "", err
+	}
+	return intent.ID, nil
+}
+
+// post sends a form request to the Stripe API
+func (p *StripeProvider) post(ctx context.Context, path string, form url.Values, idempotencyKey string) (*stripePaymentIntent, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.secretKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Idempotency-Key", idempotencyKey)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("stripe request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var intent stripePaymentIntent
+	if err := json.NewDecoder(resp.Body).Decode(&intent); err != nil {
+		return nil, fmt.Errorf("invalid stripe response: %w", err)
+	}
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+		return nil, fmt.Errorf("%w: %s", ErrDeclined, stripeMessage(&intent, resp.Status))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("stripe returned %s: %s", resp.Status, stripeMessage(&intent, resp.Status))
+	}
+	return &intent, nil
+}
+
+// stripeMessage returns the error message of a Stripe response
+func stripeMessage(intent *stripePaymentIntent, status string) string {
+	if intent.Error != nil && intent.Error.Message != "" {
+		return intent.Error.Message
+	}
+	return status
+}
+
+// minorUnits converts an amount to the currency's minor units, assuming two
+// decimals
+func minorUnits(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
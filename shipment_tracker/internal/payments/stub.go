@@ -0,0 +1,31 @@
+package payments
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// StubProvider accepts every payment without moving money, for development
+// and deployments that settle offline
+type StubProvider struct{}
+
+// NewStubProvider creates a new StubProvider
+func NewStubProvider() *StubProvider {
+	return &StubProvider{}
+}
+
+// Name returns the provider name
+func (p *StubProvider) Name() string {
+	return ProviderStub
+}
+
+// Hold returns a generated reference
+func (p *StubProvider) Hold(ctx context.Context, hold *Hold) (string, error) {
+	return "stub_" + uuid.NewString(), nil
+}
+
+// Settle returns a generated reference
+func (p *StubProvider) Settle(ctx context.Context, settlement *Settlement) (string, error) {
+	return "stub_" + uuid.NewString(), nil
+}
@@ -0,0 +1,67 @@
+package realtime
+
+import (
+	"log"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/shipment-tracker/internal/models"
+)
+
+// messageBufferSize is the number of message events queued per subscriber
+const messageBufferSize = 32
+
+// MessageBroker fans out the messages and read receipts of a shipment to the
+// clients watching it
+type MessageBroker struct {
+	mu          sync.RWMutex
+	subscribers map[uuid.UUID]map[chan models.MessageEvent]struct{}
+}
+
+// NewMessageBroker creates a new MessageBroker
+func NewMessageBroker() *MessageBroker {
+	return &MessageBroker{
+		subscribers: make(map[uuid.UUID]map[chan models.MessageEvent]struct{}),
+	}
+}
+
+// Subscribe registers interest in a shipment's message events. The returned
+// function must be called to release the subscription.
+func (b *MessageBroker) Subscribe(shipmentID uuid.UUID) (<-chan models.MessageEvent, func()) {
+	ch := make(chan models.MessageEvent, messageBufferSize)
+
+	b.mu.Lock()
+	if b.subscribers[shipmentID] == nil {
+		b.subscribers[shipmentID] = make(map[chan models.MessageEvent]struct{})
+	}
+	b.subscribers[shipmentID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if subs, ok := b.subscribers[shipmentID]; ok {
+			delete(subs, ch)
+			if len(subs) == 0 {
+				delete(b.subscribers, shipmentID)
+			}
+		}
+	}
+
+	return ch, unsubscribe
+}
+
+// Publish sends an event to every subscriber of its shipment. Events are
+// dropped for subscribers lagging behind, which catch up from the message
+// history.
+func (b *MessageBroker) Publish(event models.MessageEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for ch := range b.subscribers[event.ShipmentID] {
+		select {
+		case ch <- event:
+		default:
+			log.Printf("Message subscriber lagging, dropping %s event of shipment %s", event.Type, event.ShipmentID)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package realtime
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/shipment-tracker/internal/models"
+)
+
+// trackBufferSize is the number of track points queued per subscriber; older
+// positions are dropped in favour of newer ones when it is full
+const trackBufferSize = 16
+
+// TrackBroker fans out the positions reported for a shipment to the clients
+// watching it
+type TrackBroker struct {
+	mu          sync.RWMutex
+	subscribers map[uuid.UUID]map[chan models.TrackPoint]struct{}
+}
+
+// NewTrackBroker creates a new TrackBroker
+func NewTrackBroker() *TrackBroker {
+	return &TrackBroker{
+		subscribers: make(map[uuid.UUID]map[chan models.TrackPoint]struct{}),
+	}
+}
+
+// Subscribe registers interest in a shipment's positions. The returned
+// function must be called to release the subscription.
+func (b *TrackBroker) Subscribe(shipmentID uuid.UUID) (<-chan models.TrackPoint, func()) {
+	ch := make(chan models.TrackPoint, trackBufferSize)
+
+	b.mu.Lock()
+	if b.subscribers[shipmentID] == nil {
+		b.subscribers[shipmentID] = make(map[chan models.TrackPoint]struct{})
+	}
+	b.subscribers[shipmentID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if subs, ok := b.subscribers[shipmentID]; ok {
+			delete(subs, ch)
+			if len(subs) == 0 {
+				delete(b.subscribers, shipmentID)
+			}
+		}
+	}
+
+	return ch, unsubscribe
+}
+
+// Publish sends a track point to every subscriber of its shipment
+func (b *TrackBroker) Publish(point models.TrackPoint) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for ch := range b.subscribers[point.ShipmentID] {
+		select {
+		case ch <- point:
+		default:
+			// Subscriber is lagging; drop its oldest position and retry once
+			select {
+			case <-ch:
+			default:
+			}
+			select {
+			case ch <- point:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DB is the connection pool of the repositories. It bounds each statement by
+// a context deadline, so a caller gives up on a slow query (and the pool gets
+// its connection back) rather than waiting on it indefinitely.
+type DB struct {
+	*sqlx.DB
+	queryTimeout time.Duration
+}
+
+// NewDB wraps db for the repositories; a queryTimeout of 0 leaves the
+// statements bounded by their callers' contexts only
+func NewDB(db *sqlx.DB, queryTimeout time.Duration) *DB {
+	return &DB{DB: db, queryTimeout: queryTimeout}
+}
+
+// withDeadline bounds ctx by the query timeout, keeping an earlier deadline
+func (db *DB) withDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if db.queryTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, db.queryTimeout)
+}
+
+// ExecContext executes a statement within the query timeout
+func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, cancel := db.withDeadline(ctx)
+	defer cancel()
+	return db.DB.ExecContext(ctx, query, args...)
+}
+
+// NamedExecContext executes a statement binding the fields of arg within the
+// query timeout
+func (db *DB) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	ctx, cancel := db.withDeadline(ctx)
+	defer cancel()
+	return db.DB.NamedExecContext(ctx, query, arg)
+}
+
+// GetContext scans a row within the query timeout
+func (db *DB) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	ctx, cancel := db.withDeadline(ctx)
+	defer cancel()
+	return db.DB.GetContext(ctx, dest, query, args...)
+}
+
+// SelectContext scans rows within the query timeout
+func (db *DB) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	ctx, cancel := db.withDeadline(ctx)
+	defer cancel()
+	return db.DB.SelectContext(ctx, dest, query, args...)
+}
+
+// QueryRowxContext queries a row within the query timeout. The row outlives
+// the call, so its deadline is released once it is scanned.
+func (db *DB) QueryRowxContext(ctx context.Context, query string, args ...interface{}) *Row {
+	ctx, cancel := db.withDeadline(ctx)
+	return &Row{Row: db.DB.QueryRowxContext(ctx, query, args...), cancel: cancel}
+}
+
+// Row is a row queried by DB.QueryRowxContext, holding its deadline until it
+// is scanned
+type Row struct {
+	*sqlx.Row
+	cancel context.CancelFunc
+}
+
+// Scan copies the columns of the row into dest and releases its deadline
+func (r *Row) Scan(dest ...interface{}) error {
+	defer r.cancel()
+	return r.Row.Scan(dest...)
+}
+
+// StructScan scans the row into the fields of dest and releases its deadline
+func (r *Row) StructScan(dest interface{}) error {
+	defer r.cancel()
+	return r.Row.StructScan(dest)
+}
+
+// MapScan scans the row into dest by column and releases its deadline
+func (r *Row) MapScan(dest map[string]interface{}) error {
+	defer r.cancel()
+	return r.Row.MapScan(dest)
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smartwaste/shipment-tracker/internal/models"
+)
+
+// ETARepository handles database operations for shipment arrival estimates
+type ETARepository struct {
+	db queryer
+}
+
+// NewETARepository creates a new ETARepository
+func NewETARepository(db *DB) *ETARepository {
+	return &ETARepository{db: db}
+}
+
+// WithTx runs fn in a database transaction; use Tx to bind repositories to it
+func (r *ETARepository) WithTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	return withTx(ctx, r.db, fn)
+}
+
+// Tx returns a copy of the repository that runs its queries in tx
+func (r *ETARepository) Tx(tx *sqlx.Tx) *ETARepository {
+	return &ETARepository{db: tx}
+}
+
+// Get retrieves the latest estimate of a shipment
+func (r *ETARepository) Get(ctx context.Context, shipmentID uuid.UUID) (*models.ShipmentETA, error) {
+	var eta models.ShipmentETA
+	err := r.db.GetContext(ctx, &eta, "SELECT * FROM shipment_etas WHERE shipment_id = $1", shipmentID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return &eta, err
+}
+
+// GetForUpdate retrieves the latest estimate of a shipment and locks it until
+// the transaction ends; the repository must be bound to one with Tx
+func (r *ETARepository) GetForUpdate(ctx context.Context, shipmentID uuid.UUID) (*models.ShipmentETA, error) {
+	var eta models.ShipmentETA
+	err := r.db.GetContext(ctx, &eta, "SELECT * FROM shipment_etas WHERE shipment_id = $1 FOR UPDATE", shipmentID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return &eta, err
+}
+
+// Upsert saves the latest estimate of a shipment
+func (r *ETARepository) Upsert(ctx context.Context, eta *models.ShipmentETA) error {
+	query := `
+		INSERT INTO shipment_etas (
+			shipment_id, status, target, track_point_id, driver_latitude, driver_longitude,
+			position_at, distance_km, duration_seconds, arrives_at, provider,
+			published_arrives_at, computed_at
+		) VALUES (
+			:shipment_id, :status, :target, :track_point_id, :driver_latitude, :driver_longitude,
+			:position_at, :distance_km, :duration_seconds, :arrives_at, :provider,
+			:published_arrives_at, :computed_at
+		)
+		ON CONFLICT (shipment_id) DO UPDATE SET
+			status = EXCLUDED.status,
+			target = EXCLUDED.target,
+			track_point_id = EXCLUDED.track_point_id,
+			driver_latitude = EXCLUDED.driver_latitude,
+			driver_longitude = EXCLUDED.driver_longitude,
+			position_at = EXCLUDED.position_at,
+			distance_km = EXCLUDED.distance_km,
+			duration_seconds = EXCLUDED.duration_seconds,
+			arrives_at = EXCLUDED.arrives_at,
+			provider = EXCLUDED.provider,
+			published_arrives_at = EXCLUDED.published_arrives_at,
+			computed_at = EXCLUDED.computed_at`
+
+	_, err := r.db.NamedExecContext(ctx, query, eta)
+	return err
+}
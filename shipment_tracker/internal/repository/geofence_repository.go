@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smartwaste/shipment-tracker/internal/models"
+)
+
+// GeofenceRepository handles database operations for geofence events
+type GeofenceRepository struct {
+	db queryer
+}
+
+// NewGeofenceRepository creates a new GeofenceRepository
+func NewGeofenceRepository(db *DB) *GeofenceRepository {
+	return &GeofenceRepository{db: db}
+}
+
+// Tx returns a copy of the repository that runs its queries in tx
+func (r *GeofenceRepository) Tx(tx *sqlx.Tx) *GeofenceRepository {
+	return &GeofenceRepository{db: tx}
+}
+
+// Create records a geofence event unless the shipment already has one for
+// the same transition, reporting whether it was recorded
+func (r *GeofenceRepository) Create(ctx context.Context, e *models.GeofenceEvent) (bool, error) {
+	query := `
+		INSERT INTO geofence_events (
+			id, shipment_id, track_point_id, driver_id, from_status, to_status, action,
+			latitude, longitude, distance_m, radius_m, dwell_seconds, created_at
+		) VALUES (
+			:id, :shipment_id, :track_point_id, :driver_id, :from_status, :to_status, :action,
+			:latitude, :longitude, :distance_m, :radius_m, :dwell_seconds, :created_at
+		)
+		ON CONFLICT (shipment_id, to_status) DO NOTHING`
+
+	result, err := r.db.NamedExecContext(ctx, query, e)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+// ListByShipment retrieves the geofence events of a shipment, oldest first
+func (r *GeofenceRepository) ListByShipment(ctx context.Context, shipmentID uuid.UUID) ([]models.GeofenceEvent, error) {
+	var events []models.GeofenceEvent
+	err := r.db.SelectContext(ctx, &events, "SELECT * FROM geofence_events WHERE shipment_id = $1 ORDER BY created_at ASC", shipmentID)
+	return events, err
+}
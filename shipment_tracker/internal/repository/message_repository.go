@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/shipment-tracker/internal/models"
+)
+
+// MessageRepository handles database operations for shipment messages
+type MessageRepository struct {
+	db queryer
+}
+
+// NewMessageRepository creates a new MessageRepository
+func NewMessageRepository(db *DB) *MessageRepository {
+	return &MessageRepository{db: db}
+}
+
+// Create records a message
+func (r *MessageRepository) Create(ctx context.Context, m *models.ShipmentMessage) error {
+	query := `
+		INSERT INTO shipment_messages (id, shipment_id, sender_id, sender_role, body, created_at)
+		VALUES (:id, :shipment_id, :sender_id, :sender_role, :body, :created_at)`
+
+	_, err := r.db.NamedExecContext(ctx, query, m)
+	return err
+}
+
+// ListByShipment retrieves up to limit messages of a shipment sent after
+// since, oldest first
+func (r *MessageRepository) ListByShipment(ctx context.Context, shipmentID uuid.UUID, since *time.Time, limit int) ([]models.ShipmentMessage, error) {
+	var messages []models.ShipmentMessage
+	query := "SELECT * FROM shipment_messages WHERE shipment_id = $1"
+	args := []interface{}{shipmentID}
+	if since != nil {
+		query += " AND created_at > $2"
+		args = append(args, *since)
+	}
+	query += fmt.Sprintf(" ORDER BY created_at ASC, id ASC LIMIT $%d", len(args)+1)
+	args = append(args, limit)
+
+	err := r.db.SelectContext(ctx, &messages, query, args...)
+	return messages, err
+}
+
+// MarkRead marks the unread messages of a shipment sent by anyone but the
+// reader as read at readAt, returning their IDs
+func (r *MessageRepository) MarkRead(ctx context.Context, shipmentID, readerID uuid.UUID, readAt time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	query := `
+		UPDATE shipment_messages SET read_at = $3
+		WHERE shipment_id = $1 AND sender_id <> $2 AND read_at IS NULL
+		RETURNING id`
+
+	err := r.db.SelectContext(ctx, &ids, query, shipmentID, readerID, readAt)
+	return ids, err
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smartwaste/shipment-tracker/internal/models"
+)
+
+// OutboxRepository handles database operations for the event outbox
+type OutboxRepository struct {
+	db queryer
+}
+
+// NewOutboxRepository creates a new OutboxRepository
+func NewOutboxRepository(db *DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// WithTx runs fn in a database transaction; use Tx to bind repositories to it
+func (r *OutboxRepository) WithTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	return withTx(ctx, r.db, fn)
+}
+
+// Tx returns a copy of the repository that runs its queries in tx
+func (r *OutboxRepository) Tx(tx *sqlx.Tx) *OutboxRepository {
+	return &OutboxRepository{db: tx}
+}
+
+// Create adds an event to the outbox
+func (r *OutboxRepository) Create(ctx context.Context, e *models.OutboxEvent) error {
+	query := `
+		INSERT INTO outbox_events (id, subject, payload, next_attempt_at, created_at)
+		VALUES (:id, :subject, :payload, :next_attempt_at, :created_at)`
+
+	_, err := r.db.NamedExecContext(ctx, query, e)
+	return err
+}
+
+// ClaimDue locks up to limit unpublished events whose next attempt is due,
+// oldest first. Events locked by another relayer are skipped, so the
+// repository must run in a transaction, which holds the locks.
+func (r *OutboxRepository) ClaimDue(ctx context.Context, limit int) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	query := `
+		SELECT * FROM outbox_events
+		WHERE published_at IS NULL AND next_attempt_at <= CURRENT_TIMESTAMP
+		ORDER BY created_at ASC
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED`
+	err := r.db.SelectContext(ctx, &events, query, limit)
+	return events, err
+}
+
+// MarkPublished records that an event was published
+func (r *OutboxRepository) MarkPublished(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE outbox_events SET published_at = CURRENT_TIMESTAMP, attempts = attempts + 1 WHERE id = $1", id)
+	return err
+}
+
+// MarkFailed records a failed attempt to publish an event and when to retry
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id uuid.UUID, cause string, nextAttemptAt time.Time) error {
+	query := `
+		UPDATE outbox_events SET attempts = attempts + 1, last_error = $1, next_attempt_at = $2
+		WHERE id = $3`
+	_, err := r.db.ExecContext(ctx, query, cause, nextAttemptAt, id)
+	return err
+}
+
+// CountPending returns the number of events not yet published
+func (r *OutboxRepository) CountPending(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM outbox_events WHERE published_at IS NULL")
+	return count, err
+}
+
+// DeletePublishedBefore deletes the events published before the given time
+func (r *OutboxRepository) DeletePublishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM outbox_events WHERE published_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smartwaste/shipment-tracker/internal/models"
+)
+
+// PaymentRepository handles database operations for the payments ledger
+type PaymentRepository struct {
+	db queryer
+}
+
+// NewPaymentRepository creates a new PaymentRepository
+func NewPaymentRepository(db *DB) *PaymentRepository {
+	return &PaymentRepository{db: db}
+}
+
+// Tx returns a copy of the repository that runs its queries in tx
+func (r *PaymentRepository) Tx(tx *sqlx.Tx) *PaymentRepository {
+	return &PaymentRepository{db: tx}
+}
+
+// Create records a payment
+func (r *PaymentRepository) Create(ctx context.Context, p *models.Payment) error {
+	query := `
+		INSERT INTO payments (
+			id, shipment_id, type, status, amount, currency,
+			payer_id, recipient_id, provider, provider_reference, error, created_at
+		) VALUES (
+			:id, :shipment_id, :type, :status, :amount, :currency,
+			:payer_id, :recipient_id, :provider, :provider_reference, :error, :created_at
+		)`
+
+	_, err := r.db.NamedExecContext(ctx, query, p)
+	return err
+}
+
+// ListByShipment retrieves the payments of a shipment, oldest first
+func (r *PaymentRepository) ListByShipment(ctx context.Context, shipmentID uuid.UUID) ([]models.Payment, error) {
+	var payments []models.Payment
+	err := r.db.SelectContext(ctx, &payments, "SELECT * FROM payments WHERE shipment_id = $1 ORDER BY created_at ASC", shipmentID)
+	return payments, err
+}
+
+// ListPayouts retrieves a page of the successful payouts to a driver or user,
+// newest first, together with their total number
+func (r *PaymentRepository) ListPayouts(ctx context.Context, recipientID uuid.UUID, limit, offset int) ([]models.Payment, int, error) {
+	where := " WHERE recipient_id = $1 AND status = $2"
+	args := []interface{}{recipientID, models.PaymentSucceeded}
+
+	var total int
+	if err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM payments"+where, args...); err != nil {
+		return nil, 0, err
+	}
+
+	var payments []models.Payment
+	err := r.db.SelectContext(ctx, &payments, "SELECT * FROM payments"+where+" ORDER BY created_at DESC, id DESC LIMIT $3 OFFSET $4",
+		append(args, limit, offset)...)
+	return payments, total, err
+}
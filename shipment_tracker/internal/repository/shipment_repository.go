@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
@@ -11,96 +12,176 @@ import (
 
 // ShipmentRepository handles database operations for shipments
 type ShipmentRepository struct {
-	db *sqlx.DB
+	db queryer
 }
 
 // NewShipmentRepository creates a new ShipmentRepository
-func NewShipmentRepository(db *sqlx.DB) *ShipmentRepository {
+func NewShipmentRepository(db *DB) *ShipmentRepository {
 	return &ShipmentRepository{db: db}
 }
 
+// WithTx runs fn in a database transaction; use Tx to bind repositories to it
+func (r *ShipmentRepository) WithTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	return withTx(ctx, r.db, fn)
+}
+
+// Tx returns a copy of the repository that runs its queries in tx
+func (r *ShipmentRepository) Tx(tx *sqlx.Tx) *ShipmentRepository {
+	return &ShipmentRepository{db: tx}
+}
+
 // Create creates a new shipment
-func (r *ShipmentRepository) Create(s *models.Shipment) error {
+func (r *ShipmentRepository) Create(ctx context.Context, s *models.Shipment) error {
 	query := `
 		INSERT INTO shipments (
 			id, user_id, collection_id, waste_type, estimated_weight_kg,
 			price_offered, price_confirmed, status,
 			pickup_latitude, pickup_longitude, pickup_address,
 			dropoff_latitude, dropoff_longitude, dropoff_address,
-			notes, created_at, updated_at
+			notes, escrow_status, created_at, updated_at
 		) VALUES (
 			:id, :user_id, :collection_id, :waste_type, :estimated_weight_kg,
 			:price_offered, :price_confirmed, :status,
 			:pickup_latitude, :pickup_longitude, :pickup_address,
 			:dropoff_latitude, :dropoff_longitude, :dropoff_address,
-			:notes, :created_at, :updated_at
+			:notes, :escrow_status, :created_at, :updated_at
 		)`
 
-	_, err := r.db.NamedExec(query, s)
+	_, err := r.db.NamedExecContext(ctx, query, s)
 	return err
 }
 
 // GetByID retrieves a shipment by ID
-func (r *ShipmentRepository) GetByID(id uuid.UUID) (*models.Shipment, error) {
+func (r *ShipmentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Shipment, error) {
 	var s models.Shipment
-	err := r.db.Get(&s, "SELECT * FROM shipments WHERE id = $1", id)
+	err := r.db.GetContext(ctx, &s, "SELECT * FROM shipments WHERE id = $1", id)
 	if err == sql.ErrNoRows {
 		return nil, nil // Not found
 	}
 	return &s, err
 }
 
+// Lock locks a shipment row until the end of the transaction the repository
+// runs in, serializing the changes to the shipment
+func (r *ShipmentRepository) Lock(ctx context.Context, id uuid.UUID) error {
+	var locked uuid.UUID
+	return r.db.GetContext(ctx, &locked, "SELECT id FROM shipments WHERE id = $1 FOR UPDATE", id)
+}
+
 // UpdateStatus updates the status of a shipment
-func (r *ShipmentRepository) UpdateStatus(id uuid.UUID, status models.ShipmentStatus) error {
-	_, err := r.db.Exec("UPDATE shipments SET status = $1 WHERE id = $2", status, id)
+func (r *ShipmentRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status models.ShipmentStatus) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE shipments SET status = $1 WHERE id = $2", status, id)
 	return err
 }
 
 // UpdateContractDetails updates the smart contract details for a shipment
-func (r *ShipmentRepository) UpdateContractDetails(id uuid.UUID, address, txHash string) error {
-	_, err := r.db.Exec("UPDATE shipments SET contract_address = $1, contract_tx_hash = $2 WHERE id = $3", address, txHash, id)
+func (r *ShipmentRepository) UpdateContractDetails(ctx context.Context, id uuid.UUID, address, txHash string) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE shipments SET contract_address = $1, contract_tx_hash = $2 WHERE id = $3", address, txHash, id)
 	return err
 }
 
 // AssignDriver assigns a driver to a shipment
-func (r *ShipmentRepository) AssignDriver(id uuid.UUID, driverID uuid.UUID) error {
-	_, err := r.db.Exec("UPDATE shipments SET driver_id = $1, status = $2 WHERE id = $3", driverID, models.StatusDriverAssigned, id)
+func (r *ShipmentRepository) AssignDriver(ctx context.Context, id uuid.UUID, driverID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE shipments SET driver_id = $1, status = $2 WHERE id = $3", driverID, models.StatusDriverAssigned, id)
+	return err
+}
+
+// HoldEscrow records the funds held for a shipment and confirms its price
+func (r *ShipmentRepository) HoldEscrow(ctx context.Context, id uuid.UUID, amount float64, provider, reference string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE shipments
+		SET price_confirmed = true, escrow_status = $1, escrow_amount = $2, escrow_provider = $3, escrow_reference = $4
+		WHERE id = $5`,
+		models.EscrowHeld, amount, provider, reference, id,
+	)
+	return err
+}
+
+// SettleEscrow moves a held escrow to its settled status, returning false if
+// the escrow was no longer held
+func (r *ShipmentRepository) SettleEscrow(ctx context.Context, id uuid.UUID, status models.EscrowStatus) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE shipments SET escrow_status = $1 WHERE id = $2 AND escrow_status = $3",
+		status, id, models.EscrowHeld,
+	)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+// RecordCancellation records the reason and fee of a cancellation
+func (r *ShipmentRepository) RecordCancellation(ctx context.Context, id uuid.UUID, reason models.CancellationReason, fee float64) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE shipments SET cancellation_reason = $1, cancellation_fee = $2 WHERE id = $3", reason, fee, id)
 	return err
 }
 
 // UpdateActualWeight updates the actual weight of the shipment
-func (r *ShipmentRepository) UpdateActualWeight(id uuid.UUID, weight float64) error {
-	_, err := r.db.Exec("UPDATE shipments SET actual_weight_kg = $1 WHERE id = $2", weight, id)
+func (r *ShipmentRepository) UpdateActualWeight(ctx context.Context, id uuid.UUID, weight float64) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE shipments SET actual_weight_kg = $1 WHERE id = $2", weight, id)
 	return err
 }
 
-// List retrieves a list of shipments with optional filtering
-func (r *ShipmentRepository) List(userID *uuid.UUID, driverID *uuid.UUID, status *models.ShipmentStatus) ([]models.Shipment, error) {
-	query := "SELECT * FROM shipments WHERE 1=1"
+// List retrieves a page of shipments matching the filter, newest first,
+// together with the total number of matches
+func (r *ShipmentRepository) List(ctx context.Context, filter models.ShipmentFilter, limit, offset int) ([]models.Shipment, int, error) {
+	where := " WHERE 1=1"
 	args := []interface{}{}
 	argID := 1
 
-	if userID != nil {
-		query += fmt.Sprintf(" AND user_id = $%d", argID)
-		args = append(args, *userID)
+	if filter.UserID != nil {
+		where += fmt.Sprintf(" AND user_id = $%d", argID)
+		args = append(args, *filter.UserID)
 		argID++
 	}
 
-	if driverID != nil {
-		query += fmt.Sprintf(" AND driver_id = $%d", argID)
-		args = append(args, *driverID)
+	if filter.DriverID != nil {
+		where += fmt.Sprintf(" AND driver_id = $%d", argID)
+		args = append(args, *filter.DriverID)
 		argID++
 	}
 
-	if status != nil {
-		query += fmt.Sprintf(" AND status = $%d", argID)
-		args = append(args, *status)
+	if filter.CollectionID != nil {
+		where += fmt.Sprintf(" AND collection_id = $%d", argID)
+		args = append(args, *filter.CollectionID)
 		argID++
 	}
 
-	query += " ORDER BY created_at DESC"
+	if filter.Status != nil {
+		where += fmt.Sprintf(" AND status = $%d", argID)
+		args = append(args, *filter.Status)
+		argID++
+	}
+
+	if filter.From != nil {
+		where += fmt.Sprintf(" AND created_at >= $%d", argID)
+		args = append(args, *filter.From)
+		argID++
+	}
+
+	if filter.To != nil {
+		where += fmt.Sprintf(" AND created_at < $%d", argID)
+		args = append(args, *filter.To)
+		argID++
+	}
+
+	if filter.ParticipantID != nil {
+		where += fmt.Sprintf(" AND (user_id = $%[1]d OR driver_id = $%[1]d)", argID)
+		args = append(args, *filter.ParticipantID)
+		argID++
+	}
+
+	var total int
+	if err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM shipments"+where, args...); err != nil {
+		return nil, 0, err
+	}
+
+	query := "SELECT * FROM shipments" + where +
+		fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", argID, argID+1)
+	args = append(args, limit, offset)
 
 	var shipments []models.Shipment
-	err := r.db.Select(&shipments, query, args...)
-	return shipments, err
+	err := r.db.SelectContext(ctx, &shipments, query, args...)
+	return shipments, total, err
 }
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/shipment-tracker/internal/models"
+)
+
+// SigningKeyRepository handles database operations for signing keys
+type SigningKeyRepository struct {
+	db queryer
+}
+
+// NewSigningKeyRepository creates a new SigningKeyRepository
+func NewSigningKeyRepository(db *DB) *SigningKeyRepository {
+	return &SigningKeyRepository{db: db}
+}
+
+// Create stores a new signing key
+func (r *SigningKeyRepository) Create(ctx context.Context, k *models.SigningKey) error {
+	query := `
+		INSERT INTO signing_keys (id, subject_id, name, public_key, created_at)
+		VALUES (:id, :subject_id, :name, :public_key, :created_at)`
+
+	_, err := r.db.NamedExecContext(ctx, query, k)
+	return err
+}
+
+// ListBySubject retrieves the keys of a user or driver, newest first
+func (r *SigningKeyRepository) ListBySubject(ctx context.Context, subjectID uuid.UUID) ([]models.SigningKey, error) {
+	var keys []models.SigningKey
+	err := r.db.SelectContext(ctx, &keys, "SELECT * FROM signing_keys WHERE subject_id = $1 ORDER BY created_at DESC", subjectID)
+	return keys, err
+}
+
+// ListActiveBySubject retrieves the keys of a user or driver that are not
+// revoked
+func (r *SigningKeyRepository) ListActiveBySubject(ctx context.Context, subjectID uuid.UUID) ([]models.SigningKey, error) {
+	var keys []models.SigningKey
+	err := r.db.SelectContext(ctx, &keys, "SELECT * FROM signing_keys WHERE subject_id = $1 AND revoked_at IS NULL", subjectID)
+	return keys, err
+}
+
+// Revoke revokes a key of the subject, returning false if it has no such
+// active key
+func (r *SigningKeyRepository) Revoke(ctx context.Context, id, subjectID uuid.UUID) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE signing_keys SET revoked_at = NOW() WHERE id = $1 AND subject_id = $2 AND revoked_at IS NULL",
+		id, subjectID,
+	)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+// GetByPublicKey retrieves a key by its encoded public key
+func (r *SigningKeyRepository) GetByPublicKey(ctx context.Context, publicKey string) (*models.SigningKey, error) {
+	var k models.SigningKey
+	err := r.db.GetContext(ctx, &k, "SELECT * FROM signing_keys WHERE public_key = $1", publicKey)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return &k, err
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/shipment-tracker/internal/models"
+)
+
+// TrackRepository handles database operations for shipment track logs
+type TrackRepository struct {
+	db queryer
+}
+
+// NewTrackRepository creates a new TrackRepository
+func NewTrackRepository(db *DB) *TrackRepository {
+	return &TrackRepository{db: db}
+}
+
+// Create records a track point
+func (r *TrackRepository) Create(ctx context.Context, p *models.TrackPoint) error {
+	query := `
+		INSERT INTO track_points (
+			id, shipment_id, driver_id, status, latitude, longitude,
+			accuracy_m, speed_kmh, heading, recorded_at, created_at
+		) VALUES (
+			:id, :shipment_id, :driver_id, :status, :latitude, :longitude,
+			:accuracy_m, :speed_kmh, :heading, :recorded_at, :created_at
+		)`
+
+	_, err := r.db.NamedExecContext(ctx, query, p)
+	return err
+}
+
+// ListByShipment retrieves up to limit track points of a shipment recorded
+// after since, oldest first
+func (r *TrackRepository) ListByShipment(ctx context.Context, shipmentID uuid.UUID, since *time.Time, limit int) ([]models.TrackPoint, error) {
+	var points []models.TrackPoint
+	query := "SELECT * FROM track_points WHERE shipment_id = $1"
+	args := []interface{}{shipmentID}
+	if since != nil {
+		query += " AND recorded_at > $2"
+		args = append(args, *since)
+	}
+	query += fmt.Sprintf(" ORDER BY recorded_at ASC, id ASC LIMIT $%d", len(args)+1)
+	args = append(args, limit)
+
+	err := r.db.SelectContext(ctx, &points, query, args...)
+	return points, err
+}
+
+// GetLatest retrieves the most recent track point of a shipment
+func (r *TrackRepository) GetLatest(ctx context.Context, shipmentID uuid.UUID) (*models.TrackPoint, error) {
+	var p models.TrackPoint
+	err := r.db.GetContext(ctx, &p, "SELECT * FROM track_points WHERE shipment_id = $1 ORDER BY recorded_at DESC, id DESC LIMIT 1", shipmentID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return &p, err
+}
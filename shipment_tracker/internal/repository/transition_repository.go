@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
 
 	"github.com/google/uuid"
@@ -10,16 +12,26 @@ import (
 
 // TransitionRepository handles database operations for state transitions
 type TransitionRepository struct {
-	db *sqlx.DB
+	db queryer
 }
 
 // NewTransitionRepository creates a new TransitionRepository
-func NewTransitionRepository(db *sqlx.DB) *TransitionRepository {
+func NewTransitionRepository(db *DB) *TransitionRepository {
 	return &TransitionRepository{db: db}
 }
 
+// WithTx runs fn in a database transaction; use Tx to bind repositories to it
+func (r *TransitionRepository) WithTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	return withTx(ctx, r.db, fn)
+}
+
+// Tx returns a copy of the repository that runs its queries in tx
+func (r *TransitionRepository) Tx(tx *sqlx.Tx) *TransitionRepository {
+	return &TransitionRepository{db: tx}
+}
+
 // Create creates a new state transition record
-func (r *TransitionRepository) Create(t *models.StateTransition) error {
+func (r *TransitionRepository) Create(ctx context.Context, t *models.StateTransition) error {
 	// Ensure Metadata is valid JSON if nil
 	if t.Metadata == nil {
 		t.Metadata = json.RawMessage("{}")
@@ -29,20 +41,32 @@ func (r *TransitionRepository) Create(t *models.StateTransition) error {
 		INSERT INTO state_transitions (
 			id, shipment_id, from_status, to_status,
 			triggered_by, triggered_by_role,
-			proof_hash, signature, tx_hash, metadata, created_at
+			proof_hash, signature, signing_key_id, tx_hash, metadata,
+			previous_hash, hash, created_at
 		) VALUES (
 			:id, :shipment_id, :from_status, :to_status,
 			:triggered_by, :triggered_by_role,
-			:proof_hash, :signature, :tx_hash, :metadata, :created_at
+			:proof_hash, :signature, :signing_key_id, :tx_hash, :metadata,
+			:previous_hash, :hash, :created_at
 		)`
 
-	_, err := r.db.NamedExec(query, t)
+	_, err := r.db.NamedExecContext(ctx, query, t)
 	return err
 }
 
 // GetByShipmentID retrieves all transitions for a shipment
-func (r *TransitionRepository) GetByShipmentID(shipmentID uuid.UUID) ([]models.StateTransition, error) {
+func (r *TransitionRepository) GetByShipmentID(ctx context.Context, shipmentID uuid.UUID) ([]models.StateTransition, error) {
 	var transitions []models.StateTransition
-	err := r.db.Select(&transitions, "SELECT * FROM state_transitions WHERE shipment_id = $1 ORDER BY created_at ASC", shipmentID)
+	err := r.db.SelectContext(ctx, &transitions, "SELECT * FROM state_transitions WHERE shipment_id = $1 ORDER BY created_at ASC", shipmentID)
 	return transitions, err
 }
+
+// GetLatest retrieves the most recent transition of a shipment
+func (r *TransitionRepository) GetLatest(ctx context.Context, shipmentID uuid.UUID) (*models.StateTransition, error) {
+	var t models.StateTransition
+	err := r.db.GetContext(ctx, &t, "SELECT * FROM state_transitions WHERE shipment_id = $1 ORDER BY created_at DESC LIMIT 1", shipmentID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return &t, err
+}
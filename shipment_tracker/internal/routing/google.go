@@ -0,0 +1,92 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// GoogleProvider uses the Google Maps Directions API, with the current traffic
+type GoogleProvider struct {
+	client *http.Client
+	apiKey string
+}
+
+// NewGoogleProvider creates a new GoogleProvider
+func NewGoogleProvider(client *http.Client, apiKey string) *GoogleProvider {
+	return &GoogleProvider{
+		client: client,
+		apiKey: apiKey,
+	}
+}
+
+// Name returns the provider name
+func (p *GoogleProvider) Name() string {
+	return ProviderGoogle
+}
+
+// Route fetches the driving distance and time between the points, leaving
+// now so the time accounts for traffic
+func (p *GoogleProvider) Route(ctx context.Context, from, to LatLng) (*Metrics, error) {
+	query := url.Values{}
+	query.Set("origin", formatGoogleLatLng(from))
+	query.Set("destination", formatGoogleLatLng(to))
+	query.Set("departure_time", "now")
+	query.Set("key", p.apiKey)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		"https://maps.googleapis.com/maps/api/directions/json?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build Google Maps request: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call Google Maps API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Status string `json:"status"`
+		Routes []struct {
+			Legs []struct {
+				Distance struct {
+					Value int `json:"value"` // meters
+				} `json:"distance"`
+				Duration struct {
+					Value int `json:"value"` // seconds
+				} `json:"duration"`
+				DurationInTraffic *struct {
+					Value int `json:"value"` // seconds
+				} `json:"duration_in_traffic"`
+			} `json:"legs"`
+		} `json:"routes"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse Google Maps response: %w", err)
+	}
+
+	if result.Status != "OK" || len(result.Routes) == 0 {
+		return nil, fmt.Errorf("no routes found: %s", result.Status)
+	}
+
+	metrics := &Metrics{}
+	for _, leg := range result.Routes[0].Legs {
+		metrics.DistanceKm += float64(leg.Distance.Value) / 1000
+		seconds := leg.Duration.Value
+		if leg.DurationInTraffic != nil {
+			seconds = leg.DurationInTraffic.Value
+		}
+		metrics.Duration += time.Duration(seconds) * time.Second
+	}
+
+	return metrics, nil
+}
+
+func formatGoogleLatLng(point LatLng) string {
+	return fmt.Sprintf("%f,%f", point.Latitude, point.Longitude)
+}
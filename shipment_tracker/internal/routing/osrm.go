@@ -0,0 +1,68 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OSRMProvider uses an OSRM server's route service
+type OSRMProvider struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewOSRMProvider creates a new OSRMProvider
+func NewOSRMProvider(client *http.Client, baseURL string) *OSRMProvider {
+	return &OSRMProvider{
+		client:  client,
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// Name returns the provider name
+func (p *OSRMProvider) Name() string {
+	return ProviderOSRM
+}
+
+// Route fetches the driving distance and time between the points
+func (p *OSRMProvider) Route(ctx context.Context, from, to LatLng) (*Metrics, error) {
+	// OSRM expects longitude,latitude pairs
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("%s/route/v1/driving/%f,%f;%f,%f?overview=false", p.baseURL,
+			from.Longitude, from.Latitude, to.Longitude, to.Latitude), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OSRM request: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call OSRM: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Routes  []struct {
+			Distance float64 `json:"distance"` // meters
+			Duration float64 `json:"duration"` // seconds
+		} `json:"routes"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse OSRM response: %w", err)
+	}
+
+	if result.Code != "Ok" || len(result.Routes) == 0 {
+		return nil, fmt.Errorf("no routes found: %s %s", result.Code, result.Message)
+	}
+
+	return &Metrics{
+		DistanceKm: result.Routes[0].Distance / 1000,
+		Duration:   time.Duration(result.Routes[0].Duration * float64(time.Second)),
+	}, nil
+}
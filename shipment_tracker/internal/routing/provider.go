@@ -0,0 +1,111 @@
+// Package routing estimates the driving distance and time between points,
+// from which the arrival of drivers at pickups and dropoffs is estimated.
+package routing
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/smartwaste/shipment-tracker/internal/config"
+)
+
+// Provider names accepted by ROUTING_PROVIDER
+const (
+	ProviderAuto      = "auto" // Google if a key is configured, otherwise Haversine
+	ProviderGoogle    = "google"
+	ProviderOSRM      = "osrm"
+	ProviderHaversine = "haversine"
+)
+
+// httpTimeout bounds calls to external routing APIs
+const httpTimeout = 10 * time.Second
+
+// LatLng is a point on a route
+type LatLng struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Metrics is the travel distance and time along a route
+type Metrics struct {
+	DistanceKm float64
+	Duration   time.Duration
+}
+
+// Provider computes the travel distance and time from one point to another
+type Provider interface {
+	Name() string
+	Route(ctx context.Context, from, to LatLng) (*Metrics, error)
+}
+
+// NewProvider creates the routing provider selected by the configuration
+func NewProvider(cfg *config.RoutingConfig) (Provider, error) {
+	client := &http.Client{Timeout: httpTimeout}
+
+	switch cfg.Provider {
+	case ProviderAuto, "":
+		if cfg.GoogleMapsAPIKey != "" {
+			return NewGoogleProvider(client, cfg.GoogleMapsAPIKey), nil
+		}
+		return NewHaversineProvider(), nil
+	case ProviderGoogle:
+		if cfg.GoogleMapsAPIKey == "" {
+			return nil, fmt.Errorf("routing provider %q requires GOOGLE_MAPS_API_KEY", cfg.Provider)
+		}
+		return NewGoogleProvider(client, cfg.GoogleMapsAPIKey), nil
+	case ProviderOSRM:
+		if cfg.OSRMURL == "" {
+			return nil, fmt.Errorf("routing provider %q requires OSRM_URL", cfg.Provider)
+		}
+		return NewOSRMProvider(client, cfg.OSRMURL), nil
+	case ProviderHaversine:
+		return NewHaversineProvider(), nil
+	default:
+		return nil, fmt.Errorf("unknown routing provider %q", cfg.Provider)
+	}
+}
+
+// HaversineProvider estimates routes offline from straight-line distances
+type HaversineProvider struct {
+	speedKmh float64
+}
+
+// NewHaversineProvider creates a new HaversineProvider
+func NewHaversineProvider() *HaversineProvider {
+	// Assume an average speed of 30 km/h in urban areas
+	return &HaversineProvider{speedKmh: 30}
+}
+
+// Name returns the provider name
+func (p *HaversineProvider) Name() string {
+	return ProviderHaversine
+}
+
+// Route returns the great-circle distance between the points
+func (p *HaversineProvider) Route(ctx context.Context, from, to LatLng) (*Metrics, error) {
+	distance := DistanceMeters(from.Latitude, from.Longitude, to.Latitude, to.Longitude) / 1000
+
+	return &Metrics{
+		DistanceKm: distance,
+		Duration:   time.Duration(distance / p.speedKmh * float64(time.Hour)),
+	}, nil
+}
+
+// DistanceMeters calculates the distance between two points using the
+// Haversine formula
+func DistanceMeters(lat1, lng1, lat2, lng2 float64) float64 {
+	const earthRadiusM = 6371000.0
+
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLng := (lng2 - lng1) * math.Pi / 180
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*
+			math.Sin(dLng/2)*math.Sin(dLng/2)
+	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+
+	return earthRadiusM * c
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/smartwaste/shipment-tracker/internal/config"
+	"github.com/smartwaste/shipment-tracker/internal/models"
+	"github.com/smartwaste/shipment-tracker/internal/repository"
+)
+
+// ErrReasonNotAllowed is returned when a party cancels with an unknown reason
+// or one that is not theirs
+var ErrReasonNotAllowed = errors.New("cancellation reason not allowed")
+
+// CancellationPolicy prices cancellations: they are free until a driver is
+// assigned and within the free window after, then the user pays a share of
+// the escrow to the driver and loses reward points, unless the cancellation
+// is the driver's doing
+type CancellationPolicy struct {
+	cfg            *config.CancellationConfig
+	transitionRepo *repository.TransitionRepository
+}
+
+// NewCancellationPolicy creates a new CancellationPolicy
+func NewCancellationPolicy(cfg *config.CancellationConfig, transitionRepo *repository.TransitionRepository) *CancellationPolicy {
+	return &CancellationPolicy{cfg: cfg, transitionRepo: transitionRepo}
+}
+
+// Terms returns what cancelling the shipment at now costs, when party gives
+// reason: "user" or "driver" for the shipment's own, the role of operators
+func (p *CancellationPolicy) Terms(ctx context.Context, shipment *models.Shipment, party string, reason models.CancellationReason, now time.Time) (*models.CancellationTerms, error) {
+	if !reason.AllowedFor(party) {
+		return nil, fmt.Errorf("%w: %q for the %s", ErrReasonNotAllowed, reason, party)
+	}
+
+	terms := &models.CancellationTerms{Party: party, ReasonCode: reason}
+	if shipment.EscrowStatus == models.EscrowHeld && shipment.EscrowAmount != nil {
+		terms.Refund = *shipment.EscrowAmount
+	}
+	if shipment.Status != models.StatusDriverAssigned || party == "driver" || !reason.UserAtFault() {
+		return terms, nil
+	}
+
+	// The assignment is the latest transition of an assigned shipment
+	assignedAt := shipment.UpdatedAt
+	latest, err := p.transitionRepo.GetLatest(ctx, shipment.ID)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil && latest.ToStatus == models.StatusDriverAssigned {
+		assignedAt = latest.CreatedAt
+	}
+	freeUntil := assignedAt.Add(p.cfg.FreeWindow)
+	terms.FreeUntil = &freeUntil
+	if now.Before(freeUntil) {
+		return terms, nil
+	}
+
+	terms.FeePercent = p.cfg.FeePercent
+	terms.PenaltyPoints = p.cfg.PenaltyPoints
+	terms.Fee = math.Min(math.Round(terms.Refund*p.cfg.FeePercent)/100, terms.Refund)
+	terms.Refund = math.Round((terms.Refund-terms.Fee)*100) / 100
+	return terms, nil
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/smartwaste/pkg/events"
+	"github.com/smartwaste/shipment-tracker/internal/config"
+	"github.com/smartwaste/shipment-tracker/internal/models"
+	"github.com/smartwaste/shipment-tracker/internal/repository"
+	"github.com/smartwaste/shipment-tracker/internal/routing"
+)
+
+// ErrETAUnavailable is returned when the arrival of the driver cannot be
+// estimated
+var ErrETAUnavailable = errors.New("eta unavailable")
+
+// ETAService estimates when the driver of a shipment arrives at its pickup,
+// or at its dropoff once picked up, from their reported positions. Estimates
+// that move the arrival by more than the threshold are published on NATS.
+type ETAService struct {
+	cfg        *config.ETAConfig
+	router     routing.Provider
+	trackRepo  *repository.TrackRepository
+	etaRepo    *repository.ETARepository
+	outboxRepo *repository.OutboxRepository
+}
+
+// NewETAService creates a new ETAService
+func NewETAService(
+	cfg *config.ETAConfig,
+	router routing.Provider,
+	trackRepo *repository.TrackRepository,
+	etaRepo *repository.ETARepository,
+	outboxRepo *repository.OutboxRepository,
+) *ETAService {
+	return &ETAService{
+		cfg:        cfg,
+		router:     router,
+		trackRepo:  trackRepo,
+		etaRepo:    etaRepo,
+		outboxRepo: outboxRepo,
+	}
+}
+
+// GetETA returns the estimated arrival of the driver of a shipment from their
+// last reported position, estimating it again if the driver moved since
+func (s *ETAService) GetETA(ctx context.Context, shipment *models.Shipment) (*models.ShipmentETA, error) {
+	if _, ok := models.ETATargetFor(shipment.Status); !ok {
+		return nil, fmt.Errorf("%w: the shipment is %s", ErrETAUnavailable, shipment.Status)
+	}
+
+	latest, err := s.trackRepo.GetLatest(ctx, shipment.ID)
+	if err != nil {
+		return nil, err
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("%w: the driver has not reported a position yet", ErrETAUnavailable)
+	}
+
+	eta, err := s.etaRepo.Get(ctx, shipment.ID)
+	if err != nil {
+		return nil, err
+	}
+	if eta != nil && eta.Status == shipment.Status && eta.TrackPointID != nil && *eta.TrackPointID == latest.ID {
+		return eta, nil
+	}
+	return s.Refresh(ctx, shipment, latest)
+}
+
+// OnLocation estimates the arrival again from a newly reported position,
+// unless the last estimate in the same status is more recent than the
+// refresh interval
+func (s *ETAService) OnLocation(ctx context.Context, shipment *models.Shipment, point *models.TrackPoint) error {
+	if _, ok := models.ETATargetFor(shipment.Status); !ok {
+		return nil
+	}
+
+	eta, err := s.etaRepo.Get(ctx, shipment.ID)
+	if err != nil {
+		return err
+	}
+	if eta != nil && eta.Status == shipment.Status && point.RecordedAt.Sub(eta.PositionAt) < s.cfg.RefreshInterval {
+		return nil
+	}
+
+	_, err = s.Refresh(ctx, shipment, point)
+	return err
+}
+
+// Refresh estimates the arrival of the driver from point and saves it,
+// publishing it if it is the first one towards its target or the arrival
+// moved by at least the change threshold since the last one published
+func (s *ETAService) Refresh(ctx context.Context, shipment *models.Shipment, point *models.TrackPoint) (*models.ShipmentETA, error) {
+	target, ok := models.ETATargetFor(shipment.Status)
+	if !ok {
+		return nil, fmt.Errorf("%w: the shipment is %s", ErrETAUnavailable, shipment.Status)
+	}
+	latitude, longitude := shipment.PickupLatitude, shipment.PickupLongitude
+	if target == models.ETATargetDropoff {
+		latitude, longitude = shipment.DropoffLatitude, shipment.DropoffLongitude
+	}
+	if latitude == nil || longitude == nil {
+		return nil, fmt.Errorf("%w: the shipment has no %s location", ErrETAUnavailable, target)
+	}
+
+	metrics, err := s.router.Route(ctx,
+		routing.LatLng{Latitude: point.Latitude, Longitude: point.Longitude},
+		routing.LatLng{Latitude: *latitude, Longitude: *longitude},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to route to the %s: %w", target, err)
+	}
+
+	now := time.Now().UTC()
+	eta := &models.ShipmentETA{
+		ShipmentID:      shipment.ID,
+		Status:          shipment.Status,
+		Target:          target,
+		TrackPointID:    &point.ID,
+		DriverLatitude:  point.Latitude,
+		DriverLongitude: point.Longitude,
+		PositionAt:      point.RecordedAt,
+		DistanceKm:      metrics.DistanceKm,
+		DurationSeconds: int(metrics.Duration / time.Second),
+		ArrivesAt:       now.Add(metrics.Duration).Truncate(time.Second),
+		Provider:        s.router.Name(),
+		ComputedAt:      now,
+	}
+
+	err = s.etaRepo.WithTx(ctx, func(tx *sqlx.Tx) error {
+		previous, err := s.etaRepo.Tx(tx).GetForUpdate(ctx, shipment.ID)
+		if err != nil {
+			return err
+		}
+		// A later position was estimated concurrently
+		if previous != nil && previous.Status == eta.Status && previous.PositionAt.After(eta.PositionAt) {
+			eta = previous
+			return nil
+		}
+
+		publish := previous == nil || previous.PublishedArrivesAt == nil || previous.Target != eta.Target ||
+			absDuration(eta.ArrivesAt.Sub(*previous.PublishedArrivesAt)) >= s.cfg.ChangeThreshold
+		if publish {
+			eta.PublishedArrivesAt = &eta.ArrivesAt
+		} else {
+			eta.PublishedArrivesAt = previous.PublishedArrivesAt
+		}
+
+		if err := s.etaRepo.Tx(tx).Upsert(ctx, eta); err != nil {
+			return err
+		}
+		if !publish {
+			return nil
+		}
+
+		data := &events.ETAUpdated{
+			ShipmentID:      shipment.ID,
+			UserID:          shipment.UserID,
+			DriverID:        shipment.DriverID,
+			Status:          string(eta.Status),
+			Target:          string(eta.Target),
+			DistanceKm:      eta.DistanceKm,
+			DurationSeconds: eta.DurationSeconds,
+			ArrivesAt:       eta.ArrivesAt,
+		}
+		if previous != nil && previous.Target == eta.Target && previous.PublishedArrivesAt != nil {
+			data.PreviousArrivesAt = previous.PublishedArrivesAt
+		}
+		return enqueueEvent(ctx, s.outboxRepo.Tx(tx), events.SubjectETAUpdated, data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return eta, nil
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
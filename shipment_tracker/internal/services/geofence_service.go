@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smartwaste/pkg/events"
+	"github.com/smartwaste/shipment-tracker/internal/config"
+	"github.com/smartwaste/shipment-tracker/internal/models"
+	"github.com/smartwaste/shipment-tracker/internal/repository"
+	"github.com/smartwaste/shipment-tracker/internal/routing"
+)
+
+// Geofence modes
+const (
+	GeofenceModeOff     = "off"     // Positions never change the shipment
+	GeofenceModePropose = "propose" // Transitions are published for the parties to confirm
+	GeofenceModeAuto    = "auto"    // Transitions are made on behalf of the driver
+)
+
+// maxDwellPoints bounds the positions read to tell how long the driver has
+// stayed at the dropoff
+const maxDwellPoints = 500
+
+// errGeofenceTriggered rolls back a geofence transition the shipment already
+// had triggered, by a concurrent position
+var errGeofenceTriggered = errors.New("geofence already triggered")
+
+// GeofenceService turns the positions of drivers into shipment transitions:
+// the pickup starts when the driver enters the pickup radius, and the
+// shipment is delivered once they stay at the dropoff
+type GeofenceService struct {
+	cfg             *config.GeofenceConfig
+	trackRepo       *repository.TrackRepository
+	geofenceRepo    *repository.GeofenceRepository
+	shipmentService *ShipmentService
+}
+
+// NewGeofenceService creates a new GeofenceService
+func NewGeofenceService(
+	cfg *config.GeofenceConfig,
+	trackRepo *repository.TrackRepository,
+	geofenceRepo *repository.GeofenceRepository,
+	shipmentService *ShipmentService,
+) (*GeofenceService, error) {
+	switch cfg.Mode {
+	case GeofenceModeOff, GeofenceModePropose, GeofenceModeAuto:
+	default:
+		return nil, fmt.Errorf("unknown geofence mode %q", cfg.Mode)
+	}
+	if cfg.PickupRadiusM <= 0 || cfg.DropoffRadiusM <= 0 {
+		return nil, errors.New("geofence radii must be positive")
+	}
+
+	return &GeofenceService{
+		cfg:             cfg,
+		trackRepo:       trackRepo,
+		geofenceRepo:    geofenceRepo,
+		shipmentService: shipmentService,
+	}, nil
+}
+
+// Evaluate checks a recorded position of the driver against the geofences of
+// the shipment, proposing or making the transition it triggers. It returns
+// nil if the position triggers none.
+func (s *GeofenceService) Evaluate(ctx context.Context, shipment *models.Shipment, point *models.TrackPoint) (*models.GeofenceEvent, error) {
+	if s.cfg.Mode == GeofenceModeOff || shipment.Status != point.Status {
+		return nil, nil
+	}
+
+	switch point.Status {
+	case models.StatusDriverAssigned:
+		if shipment.PickupLatitude == nil || shipment.PickupLongitude == nil {
+			return nil, nil
+		}
+		distance := routing.DistanceMeters(point.Latitude, point.Longitude, *shipment.PickupLatitude, *shipment.PickupLongitude)
+		if !s.inside(point, distance, s.cfg.PickupRadiusM) {
+			return nil, nil
+		}
+		return s.trigger(ctx, shipment, point, models.StatusPickupStarted, distance, s.cfg.PickupRadiusM, nil)
+
+	case models.StatusInTransit:
+		if shipment.DropoffLatitude == nil || shipment.DropoffLongitude == nil {
+			return nil, nil
+		}
+		distance := routing.DistanceMeters(point.Latitude, point.Longitude, *shipment.DropoffLatitude, *shipment.DropoffLongitude)
+		if !s.inside(point, distance, s.cfg.DropoffRadiusM) || s.moving(point) {
+			return nil, nil
+		}
+		dwell, err := s.dwellTime(ctx, shipment, point)
+		if err != nil {
+			return nil, err
+		}
+		if dwell < s.cfg.DwellTime {
+			return nil, nil
+		}
+		seconds := int(dwell / time.Second)
+		return s.trigger(ctx, shipment, point, models.StatusDelivered, distance, s.cfg.DropoffRadiusM, &seconds)
+	}
+	return nil, nil
+}
+
+// ListEvents retrieves the geofence events of a shipment, oldest first
+func (s *GeofenceService) ListEvents(ctx context.Context, shipmentID uuid.UUID) ([]models.GeofenceEvent, error) {
+	return s.geofenceRepo.ListByShipment(ctx, shipmentID)
+}
+
+// trigger proposes or makes the transition of the shipment to status,
+// recording the geofence event. It returns nil if the shipment already had
+// it triggered.
+func (s *GeofenceService) trigger(ctx context.Context, shipment *models.Shipment, point *models.TrackPoint, status models.ShipmentStatus, distance, radius float64, dwellSeconds *int) (*models.GeofenceEvent, error) {
+	event := &models.GeofenceEvent{
+		ID:           uuid.New(),
+		ShipmentID:   shipment.ID,
+		TrackPointID: &point.ID,
+		DriverID:     point.DriverID,
+		FromStatus:   shipment.Status,
+		ToStatus:     status,
+		Action:       models.GeofenceProposed,
+		Latitude:     point.Latitude,
+		Longitude:    point.Longitude,
+		DistanceM:    math.Round(distance*10) / 10,
+		RadiusM:      radius,
+		DwellSeconds: dwellSeconds,
+		CreatedAt:    time.Now().UTC(),
+	}
+	if s.cfg.Mode == GeofenceModeAuto {
+		event.Action = models.GeofenceExecuted
+	}
+
+	record := func(tx *sqlx.Tx) error {
+		created, err := s.geofenceRepo.Tx(tx).Create(ctx, event)
+		if err != nil {
+			return err
+		}
+		if !created {
+			return errGeofenceTriggered
+		}
+		return nil
+	}
+
+	var err error
+	if event.Action == models.GeofenceExecuted {
+		err = s.shipmentService.AutoTransition(ctx, shipment, status, point.DriverID, event.Metadata(), record)
+	} else {
+		err = s.shipmentService.ProposeTransition(ctx, shipment, status, &events.TransitionProposed{
+			ShipmentID:      shipment.ID,
+			UserID:          shipment.UserID,
+			DriverID:        point.DriverID,
+			FromStatus:      string(shipment.Status),
+			ProposedStatus:  string(status),
+			Source:          "geofence",
+			GeofenceEventID: event.ID,
+			TrackPointID:    event.TrackPointID,
+			Latitude:        event.Latitude,
+			Longitude:       event.Longitude,
+			DistanceM:       event.DistanceM,
+			RadiusM:         event.RadiusM,
+			DwellSeconds:    event.DwellSeconds,
+		}, record)
+	}
+	if errors.Is(err, errGeofenceTriggered) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// inside returns true if a position is within radius, and precise enough to
+// tell
+func (s *GeofenceService) inside(point *models.TrackPoint, distance, radius float64) bool {
+	if point.AccuracyM != nil && *point.AccuracyM > radius {
+		return false
+	}
+	return distance <= radius
+}
+
+// moving returns true if the driver reported a speed above the stationary one
+func (s *GeofenceService) moving(point *models.TrackPoint) bool {
+	return point.SpeedKmh != nil && *point.SpeedKmh > s.cfg.StationarySpeedKmh
+}
+
+// dwellTime returns how long the driver has stayed at the dropoff without
+// moving until point, going back through their positions until one outside
+// the radius or moving
+func (s *GeofenceService) dwellTime(ctx context.Context, shipment *models.Shipment, point *models.TrackPoint) (time.Duration, error) {
+	since := point.RecordedAt.Add(-2 * s.cfg.DwellTime)
+	points, err := s.trackRepo.ListByShipment(ctx, shipment.ID, &since, maxDwellPoints)
+	if err != nil {
+		return 0, err
+	}
+
+	arrived := point.RecordedAt
+	for i := len(points) - 1; i >= 0; i-- {
+		p := &points[i]
+		if p.RecordedAt.After(point.RecordedAt) {
+			continue
+		}
+		distance := routing.DistanceMeters(p.Latitude, p.Longitude, *shipment.DropoffLatitude, *shipment.DropoffLongitude)
+		if p.Status != models.StatusInTransit || !s.inside(p, distance, s.cfg.DropoffRadiusM) || s.moving(p) {
+			break
+		}
+		arrived = p.RecordedAt
+	}
+	return point.RecordedAt.Sub(arrived), nil
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/shipment-tracker/internal/models"
+	"github.com/smartwaste/shipment-tracker/internal/realtime"
+	"github.com/smartwaste/shipment-tracker/internal/repository"
+)
+
+var (
+	// ErrMessagingClosed is returned when messaging on a shipment with no
+	// driver assigned or that was closed
+	ErrMessagingClosed = errors.New("shipment is not open for messages")
+	// ErrNotParticipant is returned when someone other than the user or the
+	// assigned driver of a shipment sends or reads its messages
+	ErrNotParticipant = errors.New("only the user and the assigned driver of a shipment can message")
+	// ErrEmptyMessage is returned for a message with nothing but whitespace
+	ErrEmptyMessage = errors.New("body must not be blank")
+)
+
+// MessageService handles the messages between the user of a shipment and its
+// assigned driver, and pushes them and their read receipts to the clients
+// watching
+type MessageService struct {
+	messageRepo *repository.MessageRepository
+	broker      *realtime.MessageBroker
+}
+
+// NewMessageService creates a new MessageService
+func NewMessageService(messageRepo *repository.MessageRepository, broker *realtime.MessageBroker) *MessageService {
+	return &MessageService{messageRepo: messageRepo, broker: broker}
+}
+
+// Send records a message of the user or assigned driver of a shipment open
+// for messages and pushes it to the clients watching
+func (s *MessageService) Send(ctx context.Context, shipment *models.Shipment, senderID uuid.UUID, req *models.SendMessageRequest) (*models.ShipmentMessage, error) {
+	role, err := senderRole(shipment, senderID)
+	if err != nil {
+		return nil, err
+	}
+	if !shipment.AcceptsMessages() {
+		return nil, fmt.Errorf("%w in status %s", ErrMessagingClosed, shipment.Status)
+	}
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return nil, ErrEmptyMessage
+	}
+
+	message := &models.ShipmentMessage{
+		ID:         uuid.New(),
+		ShipmentID: shipment.ID,
+		SenderID:   senderID,
+		SenderRole: role,
+		Body:       body,
+		CreatedAt:  time.Now().UTC(),
+	}
+	if err := s.messageRepo.Create(ctx, message); err != nil {
+		return nil, err
+	}
+
+	s.broker.Publish(models.MessageEvent{Type: models.MessageEventMessage, ShipmentID: shipment.ID, Message: message})
+	return message, nil
+}
+
+// List retrieves up to limit messages of a shipment sent after since, oldest
+// first
+func (s *MessageService) List(ctx context.Context, shipmentID uuid.UUID, since *time.Time, limit int) ([]models.ShipmentMessage, error) {
+	return s.messageRepo.ListByShipment(ctx, shipmentID, since, limit)
+}
+
+// MarkRead marks the messages the other party of a shipment sent to the
+// reader as read and pushes the receipt to the clients watching
+func (s *MessageService) MarkRead(ctx context.Context, shipment *models.Shipment, readerID uuid.UUID) (*models.ReadReceipt, error) {
+	if _, err := senderRole(shipment, readerID); err != nil {
+		return nil, err
+	}
+
+	receipt := &models.ReadReceipt{ShipmentID: shipment.ID, ReaderID: readerID, ReadAt: time.Now().UTC()}
+	ids, err := s.messageRepo.MarkRead(ctx, shipment.ID, readerID, receipt.ReadAt)
+	if err != nil {
+		return nil, err
+	}
+	receipt.MessageIDs = ids
+	if receipt.MessageIDs == nil {
+		receipt.MessageIDs = []uuid.UUID{}
+	}
+
+	if len(ids) > 0 {
+		s.broker.Publish(models.MessageEvent{Type: models.MessageEventRead, ShipmentID: shipment.ID, Receipt: receipt})
+	}
+	return receipt, nil
+}
+
+// Subscribe streams the message events of a shipment from now on. The
+// returned function must be called to release the subscription.
+func (s *MessageService) Subscribe(shipmentID uuid.UUID) (<-chan models.MessageEvent, func()) {
+	return s.broker.Subscribe(shipmentID)
+}
+
+// senderRole returns whether the principal messages as the user or as the
+// assigned driver of a shipment
+func senderRole(shipment *models.Shipment, id uuid.UUID) (string, error) {
+	switch {
+	case shipment.DriverID != nil && *shipment.DriverID == id:
+		return models.SenderDriver, nil
+	case shipment.UserID == id:
+		return models.SenderUser, nil
+	}
+	return "", ErrNotParticipant
+}
@@ -0,0 +1,252 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smartwaste/shipment-tracker/internal/models"
+	"github.com/smartwaste/shipment-tracker/internal/payments"
+	"github.com/smartwaste/shipment-tracker/internal/repository"
+)
+
+var (
+	// ErrPaymentFailed is returned when the payment provider fails or declines
+	// a payment; the attempt is kept in the ledger
+	ErrPaymentFailed = errors.New("payment failed")
+	// ErrEscrowNotHeld is returned when settling a shipment without held funds
+	ErrEscrowNotHeld = errors.New("no escrow held for the shipment")
+	// ErrOutcomeRequired is returned when settling a disputed shipment without
+	// a valid outcome
+	ErrOutcomeRequired = errors.New("outcome must be user_wins, driver_wins or split")
+)
+
+// PaymentService holds shipment prices in escrow and pays them out
+type PaymentService struct {
+	shipmentRepo *repository.ShipmentRepository
+	paymentRepo  *repository.PaymentRepository
+	provider     payments.Provider
+	currency     string
+}
+
+// NewPaymentService creates a new PaymentService
+func NewPaymentService(
+	shipmentRepo *repository.ShipmentRepository,
+	paymentRepo *repository.PaymentRepository,
+	provider payments.Provider,
+	currency string,
+) *PaymentService {
+	return &PaymentService{
+		shipmentRepo: shipmentRepo,
+		paymentRepo:  paymentRepo,
+		provider:     provider,
+		currency:     currency,
+	}
+}
+
+// Hold places the price of a shipment in escrow with the provider, charged to
+// the payer's payment method. RecordHold must then store it with the price
+// confirmation, or Void give it back.
+func (s *PaymentService) Hold(ctx context.Context, shipment *models.Shipment, payerID uuid.UUID, paymentMethod string) (string, error) {
+	reference, err := s.provider.Hold(ctx, &payments.Hold{
+		ShipmentID:    shipment.ID,
+		Amount:        shipment.PriceOffered,
+		Currency:      s.currency,
+		PaymentMethod: paymentMethod,
+	})
+	if err != nil {
+		s.recordFailure(ctx, shipment, models.PaymentFunding, shipment.PriceOffered, &payerID, nil, err)
+		return "", fmt.Errorf("%w: %v", ErrPaymentFailed, err)
+	}
+	return reference, nil
+}
+
+// RecordHold stores the held escrow on the shipment and in the ledger in tx
+func (s *PaymentService) RecordHold(ctx context.Context, tx *sqlx.Tx, shipment *models.Shipment, payerID uuid.UUID, reference string) error {
+	provider := s.provider.Name()
+	if err := s.shipmentRepo.Tx(tx).HoldEscrow(ctx, shipment.ID, shipment.PriceOffered, provider, reference); err != nil {
+		return err
+	}
+	err := s.paymentRepo.Tx(tx).Create(ctx, &models.Payment{
+		ID:                uuid.New(),
+		ShipmentID:        shipment.ID,
+		Type:              models.PaymentFunding,
+		Status:            models.PaymentSucceeded,
+		Amount:            shipment.PriceOffered,
+		Currency:          s.currency,
+		PayerID:           &payerID,
+		Provider:          provider,
+		ProviderReference: &reference,
+		CreatedAt:         time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	amount := shipment.PriceOffered
+	shipment.PriceConfirmed = true
+	shipment.EscrowStatus = models.EscrowHeld
+	shipment.EscrowAmount = &amount
+	shipment.EscrowProvider = &provider
+	shipment.EscrowReference = &reference
+	return nil
+}
+
+// Void gives back funds held for a shipment whose price confirmation could
+// not be stored
+func (s *PaymentService) Void(ctx context.Context, shipment *models.Shipment, reference string) {
+	_, err := s.provider.Settle(ctx, &payments.Settlement{
+		ShipmentID: shipment.ID,
+		Reference:  reference,
+		Refund:     shipment.PriceOffered,
+		Currency:   s.currency,
+	})
+	if err != nil {
+		log.Printf("Failed to void escrow %s of shipment %s: %v", reference, shipment.ID, err)
+	}
+}
+
+// OutcomeFor returns how the escrow of a shipment is settled: released to the
+// driver once completed, refunded once cancelled, and as decided for a
+// dispute
+func OutcomeFor(shipment *models.Shipment, dispute models.DisputeOutcome) (models.DisputeOutcome, error) {
+	switch shipment.Status {
+	case models.StatusCompleted:
+		return models.OutcomeDriverWins, nil
+	case models.StatusCancelled:
+		return models.OutcomeUserWins, nil
+	case models.StatusDisputed, models.StatusResolved:
+		if !dispute.IsValid() {
+			return "", ErrOutcomeRequired
+		}
+		return dispute, nil
+	}
+	return "", fmt.Errorf("%w: a %s shipment cannot be settled", ErrInvalidTransition, shipment.Status)
+}
+
+// Settle pays out the escrow of a shipment according to the outcome: released
+// to the assigned driver, refunded to the user, or half each. The driver is
+// paid the fee of a late cancellation out of the refund.
+func (s *PaymentService) Settle(ctx context.Context, shipment *models.Shipment, outcome models.DisputeOutcome) error {
+	if shipment.EscrowStatus != models.EscrowHeld || shipment.EscrowAmount == nil || shipment.EscrowReference == nil {
+		return ErrEscrowNotHeld
+	}
+	if provider := *shipment.EscrowProvider; provider != s.provider.Name() {
+		return fmt.Errorf("escrow was held with provider %q, which is not configured", provider)
+	}
+
+	amount := *shipment.EscrowAmount
+	var release float64
+	status := models.EscrowRefunded
+	switch outcome {
+	case models.OutcomeDriverWins:
+		release, status = amount, models.EscrowReleased
+	case models.OutcomeSplit:
+		release, status = math.Round(amount*50)/100, models.EscrowSplit
+	}
+	releaseType := models.PaymentRelease
+	if shipment.Status == models.StatusCancelled && shipment.CancellationFee != nil && *shipment.CancellationFee > 0 {
+		release, releaseType = math.Min(*shipment.CancellationFee, amount), models.PaymentCancellationFee
+		status = models.EscrowSplit
+		if release == amount {
+			status = models.EscrowReleased
+		}
+	}
+	refund := math.Round((amount-release)*100) / 100
+	if release > 0 && shipment.DriverID == nil {
+		return fmt.Errorf("%w: the shipment has no driver to release the escrow to", ErrPaymentFailed)
+	}
+
+	reference, err := s.provider.Settle(ctx, &payments.Settlement{
+		ShipmentID: shipment.ID,
+		Reference:  *shipment.EscrowReference,
+		Release:    release,
+		Refund:     refund,
+		Currency:   s.currency,
+	})
+	if err != nil {
+		if release > 0 {
+			s.recordFailure(ctx, shipment, releaseType, release, nil, shipment.DriverID, err)
+		} else {
+			s.recordFailure(ctx, shipment, models.PaymentRefund, refund, nil, &shipment.UserID, err)
+		}
+		return fmt.Errorf("%w: %v", ErrPaymentFailed, err)
+	}
+
+	err = s.shipmentRepo.WithTx(ctx, func(tx *sqlx.Tx) error {
+		settled, err := s.shipmentRepo.Tx(tx).SettleEscrow(ctx, shipment.ID, status)
+		if err != nil {
+			return err
+		}
+		if !settled {
+			return ErrEscrowNotHeld
+		}
+		if release > 0 {
+			if err := s.paymentRepo.Tx(tx).Create(ctx, s.payout(shipment, releaseType, release, shipment.DriverID, reference)); err != nil {
+				return err
+			}
+		}
+		if refund > 0 {
+			return s.paymentRepo.Tx(tx).Create(ctx, s.payout(shipment, models.PaymentRefund, refund, &shipment.UserID, reference))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	shipment.EscrowStatus = status
+	return nil
+}
+
+// ListByShipment retrieves the payments ledger of a shipment
+func (s *PaymentService) ListByShipment(ctx context.Context, shipmentID uuid.UUID) ([]models.Payment, error) {
+	return s.paymentRepo.ListByShipment(ctx, shipmentID)
+}
+
+// ListPayouts retrieves a page of the payouts to a driver or user and their
+// total number
+func (s *PaymentService) ListPayouts(ctx context.Context, recipientID uuid.UUID, limit, offset int) ([]models.Payment, int, error) {
+	return s.paymentRepo.ListPayouts(ctx, recipientID, limit, offset)
+}
+
+// payout builds a successful release or refund
+func (s *PaymentService) payout(shipment *models.Shipment, paymentType models.PaymentType, amount float64, recipientID *uuid.UUID, reference string) *models.Payment {
+	return &models.Payment{
+		ID:                uuid.New(),
+		ShipmentID:        shipment.ID,
+		Type:              paymentType,
+		Status:            models.PaymentSucceeded,
+		Amount:            amount,
+		Currency:          s.currency,
+		RecipientID:       recipientID,
+		Provider:          s.provider.Name(),
+		ProviderReference: &reference,
+		CreatedAt:         time.Now(),
+	}
+}
+
+// recordFailure keeps a failed payment attempt in the ledger
+func (s *PaymentService) recordFailure(ctx context.Context, shipment *models.Shipment, paymentType models.PaymentType, amount float64, payerID, recipientID *uuid.UUID, cause error) {
+	message := cause.Error()
+	err := s.paymentRepo.Create(ctx, &models.Payment{
+		ID:          uuid.New(),
+		ShipmentID:  shipment.ID,
+		Type:        paymentType,
+		Status:      models.PaymentFailed,
+		Amount:      amount,
+		Currency:    s.currency,
+		PayerID:     payerID,
+		RecipientID: recipientID,
+		Provider:    s.provider.Name(),
+		Error:       &message,
+		CreatedAt:   time.Now(),
+	})
+	if err != nil {
+		log.Printf("Failed to record failed %s of shipment %s: %v", paymentType, shipment.ID, err)
+	}
+}
@@ -1,38 +1,54 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smartwaste/pkg/events"
 	"github.com/smartwaste/shipment-tracker/internal/models"
-	"github.com/smartwaste/shipment-tracker/internal/nats"
 	"github.com/smartwaste/shipment-tracker/internal/repository"
 )
 
+// ErrInvalidTransition is returned when the shipment cannot move to the
+// requested status from its current one
+var ErrInvalidTransition = errors.New("invalid transition")
+
 // ShipmentService handles shipment business logic
 type ShipmentService struct {
 	shipmentRepo   *repository.ShipmentRepository
 	transitionRepo *repository.TransitionRepository
-	natsClient     *nats.Client
+	keyService     *SigningKeyService
+	paymentService *PaymentService
+	outboxRepo     *repository.OutboxRepository
+	cancellation   *CancellationPolicy
 }
 
 // NewShipmentService creates a new ShipmentService
 func NewShipmentService(
 	shipmentRepo *repository.ShipmentRepository,
 	transitionRepo *repository.TransitionRepository,
-	natsClient *nats.Client,
+	keyService *SigningKeyService,
+	paymentService *PaymentService,
+	outboxRepo *repository.OutboxRepository,
+	cancellation *CancellationPolicy,
 ) *ShipmentService {
 	return &ShipmentService{
 		shipmentRepo:   shipmentRepo,
 		transitionRepo: transitionRepo,
-		natsClient:     natsClient,
+		keyService:     keyService,
+		paymentService: paymentService,
+		outboxRepo:     outboxRepo,
+		cancellation:   cancellation,
 	}
 }
 
 // CreateShipment creates a new shipment and logs the transition
-func (s *ShipmentService) CreateShipment(req *models.CreateShipmentRequest) (*models.Shipment, error) {
+func (s *ShipmentService) CreateShipment(ctx context.Context, req *models.CreateShipmentRequest) (*models.Shipment, error) {
 	id := uuid.New()
 	now := time.Now()
 
@@ -44,6 +60,7 @@ func (s *ShipmentService) CreateShipment(req *models.CreateShipmentRequest) (*mo
 		EstimatedWeightKg: req.EstimatedWeightKg,
 		PriceOffered:      req.PriceOffered,
 		Status:            models.StatusCreated,
+		EscrowStatus:      models.EscrowNone,
 		CreatedAt:         now,
 		UpdatedAt:         now,
 	}
@@ -64,12 +81,7 @@ func (s *ShipmentService) CreateShipment(req *models.CreateShipmentRequest) (*mo
 		shipment.Notes = req.Notes
 	}
 
-	// 1. Save shipment to DB
-	if err := s.shipmentRepo.Create(shipment); err != nil {
-		return nil, err
-	}
-
-	// 2. Create initial state transition
+	// 1. Save the shipment and its initial state transition atomically
 	transition := &models.StateTransition{
 		ID:              uuid.New(),
 		ShipmentID:      id,
@@ -79,25 +91,42 @@ func (s *ShipmentService) CreateShipment(req *models.CreateShipmentRequest) (*mo
 		TriggeredByRole: "user",
 		CreatedAt:       now,
 	}
-	if err := s.transitionRepo.Create(transition); err != nil {
-		// Log error but don't fail, we successfully created the shipment
-		// In production, might want transactional integrity here
+	err := s.shipmentRepo.WithTx(ctx, func(tx *sqlx.Tx) error {
+		if err := s.shipmentRepo.Tx(tx).Create(ctx, shipment); err != nil {
+			return err
+		}
+		if err := s.recordTransition(ctx, tx, transition); err != nil {
+			return err
+		}
+		// 2. Publish event to NATS once the shipment is committed
+		return s.enqueueEvent(ctx, tx, events.SubjectShipmentCreated, shipment.CreatedEvent())
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// 3. Publish event to NATS
-	s.publishEvent(nats.TopicShipmentCreated, shipment)
-
 	return shipment, nil
 }
 
 // GetShipment retrieves a shipment by ID
-func (s *ShipmentService) GetShipment(id uuid.UUID) (*models.Shipment, error) {
-	return s.shipmentRepo.GetByID(id)
+func (s *ShipmentService) GetShipment(ctx context.Context, id uuid.UUID) (*models.Shipment, error) {
+	return s.shipmentRepo.GetByID(ctx, id)
+}
+
+// ListShipments retrieves a page of shipments matching the filter and the
+// total number of matches
+func (s *ShipmentService) ListShipments(ctx context.Context, filter models.ShipmentFilter, limit, offset int) ([]models.Shipment, int, error) {
+	return s.shipmentRepo.List(ctx, filter, limit, offset)
+}
+
+// GetTransitions retrieves the state history of a shipment, oldest first
+func (s *ShipmentService) GetTransitions(ctx context.Context, shipmentID uuid.UUID) ([]models.StateTransition, error) {
+	return s.transitionRepo.GetByShipmentID(ctx, shipmentID)
 }
 
 // AssignDriver assigns a driver to the shipment
-func (s *ShipmentService) AssignDriver(shipmentID uuid.UUID, driverID uuid.UUID) error {
-	shipment, err := s.shipmentRepo.GetByID(shipmentID)
+func (s *ShipmentService) AssignDriver(ctx context.Context, shipmentID uuid.UUID, driverID uuid.UUID) error {
+	shipment, err := s.shipmentRepo.GetByID(ctx, shipmentID)
 	if err != nil {
 		return err
 	}
@@ -110,12 +139,7 @@ func (s *ShipmentService) AssignDriver(shipmentID uuid.UUID, driverID uuid.UUID)
 		return fmt.Errorf("cannot transition from %s to %s", shipment.Status, models.StatusDriverAssigned)
 	}
 
-	// Update DB
-	if err := s.shipmentRepo.AssignDriver(shipmentID, driverID); err != nil {
-		return err
-	}
-
-	// Record transition
+	// Assign the driver and record the transition atomically
 	now := time.Now()
 	fromStatus := shipment.Status
 	transition := &models.StateTransition{
@@ -127,39 +151,214 @@ func (s *ShipmentService) AssignDriver(shipmentID uuid.UUID, driverID uuid.UUID)
 		TriggeredByRole: "driver", // or system
 		CreatedAt:       now,
 	}
-	s.transitionRepo.Create(transition)
+	err = s.shipmentRepo.WithTx(ctx, func(tx *sqlx.Tx) error {
+		if err := s.shipmentRepo.Tx(tx).AssignDriver(ctx, shipmentID, driverID); err != nil {
+			return err
+		}
+		if err := s.recordTransition(ctx, tx, transition); err != nil {
+			return err
+		}
+		return s.enqueueEvent(ctx, tx, events.SubjectDriverAssigned, &events.DriverAssigned{
+			ShipmentID: shipmentID,
+			DriverID:   driverID,
+		})
+	})
+	return err
+}
+
+// ConfirmPickup starts the pickup of a shipment, recording the weight
+// measured by the confirming party if given. The confirmation must be signed
+// by the confirming party.
+func (s *ShipmentService) ConfirmPickup(ctx context.Context, shipment *models.Shipment, req *models.ConfirmPickupRequest) error {
+	message := models.ConfirmationMessage(shipment.ID, models.StatusPickupStarted, req.ConfirmedBy, req.ProofHash, req.ActualWeight)
+	key, err := s.keyService.Verify(ctx, req.ConfirmedBy, message, req.Signature)
+	if err != nil {
+		return err
+	}
+
+	metadata := map[string]interface{}{}
+	var saveWeight func(tx *sqlx.Tx) error
+	if req.ActualWeight != nil {
+		shipment.ActualWeightKg = req.ActualWeight
+		metadata["actual_weight_kg"] = *req.ActualWeight
+		saveWeight = func(tx *sqlx.Tx) error {
+			return s.shipmentRepo.Tx(tx).UpdateActualWeight(ctx, shipment.ID, *req.ActualWeight)
+		}
+	}
+	return s.updateStatusAndRecord(ctx, shipment, models.StatusPickupStarted, req.ConfirmedBy, req.Role, req.ProofHash, &req.Signature, &key.ID, metadata, saveWeight)
+}
+
+// StartTransit marks a picked-up shipment as on its way to the dropoff
+func (s *ShipmentService) StartTransit(ctx context.Context, shipment *models.Shipment, triggeredBy uuid.UUID, role string) error {
+	return s.updateStatusAndRecord(ctx, shipment, models.StatusInTransit, triggeredBy, role, nil, nil, nil, nil, nil)
+}
+
+// ConfirmDelivery marks a shipment in transit as delivered. The confirmation
+// must be signed by the confirming party.
+func (s *ShipmentService) ConfirmDelivery(ctx context.Context, shipment *models.Shipment, req *models.ConfirmDeliveryRequest) error {
+	message := models.ConfirmationMessage(shipment.ID, models.StatusDelivered, req.ConfirmedBy, req.ProofHash, nil)
+	key, err := s.keyService.Verify(ctx, req.ConfirmedBy, message, req.Signature)
+	if err != nil {
+		return err
+	}
+	return s.updateStatusAndRecord(ctx, shipment, models.StatusDelivered, req.ConfirmedBy, req.Role, req.ProofHash, &req.Signature, &key.ID, nil, nil)
+}
+
+// CompleteShipment completes a delivered or resolved shipment, which credits
+// the user's rewards in the backend and releases the escrow to the driver
+func (s *ShipmentService) CompleteShipment(ctx context.Context, shipment *models.Shipment, triggeredBy uuid.UUID, role string) error {
+	return s.updateStatusAndRecord(ctx, shipment, models.StatusCompleted, triggeredBy, role, nil, nil, nil, nil, nil)
+}
+
+// CancellationTerms returns what cancelling the shipment now would cost, when
+// the party in role gives reason
+func (s *ShipmentService) CancellationTerms(ctx context.Context, shipment *models.Shipment, role string, reason models.CancellationReason) (*models.CancellationTerms, error) {
+	if !shipment.CanTransitionTo(models.StatusCancelled) {
+		return nil, fmt.Errorf("%w from %s to %s", ErrInvalidTransition, shipment.Status, models.StatusCancelled)
+	}
+	return s.cancellation.Terms(ctx, shipment, role, reason, time.Now())
+}
+
+// CancelShipment cancels a shipment with the terms of the cancellation
+// policy, recorded in the metadata of the transition. The escrow is then
+// refunded to the user, less the fee of a late cancellation, which is
+// released to the driver.
+func (s *ShipmentService) CancelShipment(ctx context.Context, shipment *models.Shipment, triggeredBy uuid.UUID, role string, req *models.CancelShipmentRequest) (*models.CancellationTerms, error) {
+	terms, err := s.CancellationTerms(ctx, shipment, role, req.ReasonCode)
+	if err != nil {
+		return nil, err
+	}
 
-	// Publish event
-	s.publishEvent(nats.TopicDriverAssigned, map[string]interface{}{
-		"shipment_id": shipmentID,
-		"driver_id":   driverID,
+	err = s.updateStatusAndRecord(ctx, shipment, models.StatusCancelled, triggeredBy, role, nil, nil, nil, terms.Metadata(req.Note), func(tx *sqlx.Tx) error {
+		return s.shipmentRepo.Tx(tx).RecordCancellation(ctx, shipment.ID, req.ReasonCode, terms.Fee)
 	})
+	if err != nil {
+		return nil, err
+	}
+	shipment.CancellationReason = &terms.ReasonCode
+	shipment.CancellationFee = &terms.Fee
+	return terms, nil
+}
+
+// ConfirmPrice confirms the offered price of a new shipment by holding it in
+// escrow, charged to the payment method of the payer
+func (s *ShipmentService) ConfirmPrice(ctx context.Context, shipment *models.Shipment, payerID uuid.UUID, role string, req *models.FundShipmentRequest) error {
+	if !shipment.CanTransitionTo(models.StatusPriceConfirmed) {
+		return fmt.Errorf("%w from %s to %s", ErrInvalidTransition, shipment.Status, models.StatusPriceConfirmed)
+	}
+
+	reference, err := s.paymentService.Hold(ctx, shipment, payerID, req.PaymentMethod)
+	if err != nil {
+		return err
+	}
 
+	metadata := map[string]interface{}{"escrow_amount": shipment.PriceOffered}
+	err = s.updateStatusAndRecord(ctx, shipment, models.StatusPriceConfirmed, payerID, role, nil, nil, nil, metadata, func(tx *sqlx.Tx) error {
+		return s.paymentService.RecordHold(ctx, tx, shipment, payerID, reference)
+	})
+	if err != nil {
+		s.paymentService.Void(ctx, shipment, reference)
+		return err
+	}
 	return nil
 }
 
+// SettleEscrow pays out the escrow of an ended or disputed shipment, retrying
+// a settlement that failed when the shipment ended. Disputed and resolved
+// shipments are settled with the outcome of the dispute.
+func (s *ShipmentService) SettleEscrow(ctx context.Context, shipment *models.Shipment, dispute models.DisputeOutcome) error {
+	outcome, err := OutcomeFor(shipment, dispute)
+	if err != nil {
+		return err
+	}
+	return s.paymentService.Settle(ctx, shipment, outcome)
+}
+
+// ProposeTransition publishes a transition for the parties of the shipment to
+// confirm, committing record with the event
+func (s *ShipmentService) ProposeTransition(ctx context.Context, shipment *models.Shipment, newStatus models.ShipmentStatus, proposal *events.TransitionProposed, record func(tx *sqlx.Tx) error) error {
+	if !shipment.CanTransitionTo(newStatus) {
+		return fmt.Errorf("%w from %s to %s", ErrInvalidTransition, shipment.Status, newStatus)
+	}
+
+	return s.shipmentRepo.WithTx(ctx, func(tx *sqlx.Tx) error {
+		if err := record(tx); err != nil {
+			return err
+		}
+		return s.enqueueEvent(ctx, tx, events.SubjectTransitionProposed, proposal)
+	})
+}
+
+// AutoTransition moves the shipment to a status on behalf of triggeredBy,
+// without a signed confirmation. The transition is recorded with the system
+// role and the metadata explaining it; apply commits with it.
+func (s *ShipmentService) AutoTransition(ctx context.Context, shipment *models.Shipment, newStatus models.ShipmentStatus, triggeredBy uuid.UUID, metadata map[string]interface{}, apply func(tx *sqlx.Tx) error) error {
+	return s.updateStatusAndRecord(ctx, shipment, newStatus, triggeredBy, models.RoleSystem, nil, nil, nil, metadata, apply)
+}
+
+// VerifyChain recomputes the hash chain of a shipment's transitions,
+// reporting the first one that was altered or does not follow its
+// predecessor. The chain must also end in the shipment's current status, so
+// dropping the latest transitions is detected too.
+func (s *ShipmentService) VerifyChain(ctx context.Context, shipment *models.Shipment) (*models.ChainVerification, error) {
+	transitions, err := s.transitionRepo.GetByShipmentID(ctx, shipment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := models.VerifyChain(shipment.ID, transitions)
+	if result.Valid && len(transitions) > 0 && transitions[len(transitions)-1].ToStatus != shipment.Status {
+		last := transitions[len(transitions)-1].ID
+		result.Valid = false
+		result.BrokenAt = &last
+		result.Reason = "latest transition does not lead to the shipment status"
+	}
+	return result, nil
+}
+
+// recordTransition appends a transition to the hash chain of its shipment in
+// tx, locking the shipment so concurrent changes cannot fork the chain
+func (s *ShipmentService) recordTransition(ctx context.Context, tx *sqlx.Tx, transition *models.StateTransition) error {
+	if err := s.shipmentRepo.Tx(tx).Lock(ctx, transition.ShipmentID); err != nil {
+		return err
+	}
+	latest, err := s.transitionRepo.Tx(tx).GetLatest(ctx, transition.ShipmentID)
+	if err != nil {
+		return err
+	}
+	if latest != nil {
+		transition.PreviousHash = latest.Hash
+	}
+
+	transition.CreatedAt = transition.CreatedAt.Truncate(time.Microsecond)
+	hash := transition.ComputeHash()
+	transition.Hash = &hash
+	return s.transitionRepo.Tx(tx).Create(ctx, transition)
+}
+
 // Helper to update shipment status and record transition
 func (s *ShipmentService) updateStatusAndRecord(
+	ctx context.Context,
 	shipment *models.Shipment,
 	newStatus models.ShipmentStatus,
 	triggeredBy uuid.UUID,
 	role string,
 	proofHash *string,
 	signature *string,
+	signingKeyID *uuid.UUID,
 	metadata map[string]interface{},
+	apply func(tx *sqlx.Tx) error, // Further changes committed with the transition
 ) error {
 	// 1. Validate Transition
 	if !shipment.CanTransitionTo(newStatus) {
-		return fmt.Errorf("invalid transition from %s to %s", shipment.Status, newStatus)
+		return fmt.Errorf("%w from %s to %s", ErrInvalidTransition, shipment.Status, newStatus)
 	}
 
-	// 2. Update Shipment Status
-	if err := s.shipmentRepo.UpdateStatus(shipment.ID, newStatus); err != nil {
-		return err
+	// 2. Update the shipment status and record the transition atomically
+	var mdBytes []byte
+	if metadata != nil {
+		mdBytes, _ = json.Marshal(metadata)
 	}
-
-	// 3. Record Transition
-	mdBytes, _ := json.Marshal(metadata)
 	fromStatus := shipment.Status
 	transition := &models.StateTransition{
 		ID:              uuid.New(),
@@ -170,20 +369,56 @@ func (s *ShipmentService) updateStatusAndRecord(
 		TriggeredByRole: role,
 		ProofHash:       proofHash,
 		Signature:       signature,
+		SigningKeyID:    signingKeyID,
 		Metadata:        json.RawMessage(mdBytes),
 		CreatedAt:       time.Now(),
 	}
-	if err := s.transitionRepo.Create(transition); err != nil {
+	err := s.shipmentRepo.WithTx(ctx, func(tx *sqlx.Tx) error {
+		if err := s.shipmentRepo.Tx(tx).UpdateStatus(ctx, shipment.ID, newStatus); err != nil {
+			return err
+		}
+		if apply != nil {
+			if err := apply(tx); err != nil {
+				return err
+			}
+		}
+		if err := s.recordTransition(ctx, tx, transition); err != nil {
+			return err
+		}
+
+		// 3. Publish the event once the change is committed. It includes the
+		// shipment details consumers need and the metadata of the transition;
+		// the backend credits rewards and driver earnings from
+		// shipment.completed and takes the penalty of late cancellations from
+		// shipment.cancelled.
+		weightKg := shipment.EstimatedWeightKg
+		if shipment.ActualWeightKg != nil {
+			weightKg = *shipment.ActualWeightKg
+		}
+		return s.enqueueEvent(ctx, tx, s.getTopicForStatus(newStatus), &events.StatusChanged{
+			ShipmentID:   shipment.ID,
+			Status:       string(newStatus),
+			UpdatedBy:    triggeredBy,
+			UserID:       shipment.UserID,
+			CollectionID: shipment.CollectionID,
+			DriverID:     shipment.DriverID,
+			WasteType:    shipment.WasteType,
+			WeightKg:     &weightKg,
+			Metadata:     metadata,
+		})
+	})
+	if err != nil {
 		return err
 	}
+	shipment.Status = newStatus
 
-	// 4. Publish Event
-	topic := s.getTopicForStatus(newStatus)
-	s.publishEvent(topic, map[string]interface{}{
-		"shipment_id": shipment.ID,
-		"status":      newStatus,
-		"updated_by":  triggeredBy,
-	})
+	// Ended shipments pay out their escrow; a failed settlement stays in the
+	// ledger for an admin to retry
+	if shipment.EscrowStatus == models.EscrowHeld && (newStatus == models.StatusCompleted || newStatus == models.StatusCancelled) {
+		if err := s.SettleEscrow(ctx, shipment, ""); err != nil {
+			fmt.Printf("Failed to settle escrow of shipment %s: %v\n", shipment.ID, err)
+		}
+	}
 
 	return nil
 }
@@ -191,35 +426,49 @@ func (s *ShipmentService) updateStatusAndRecord(
 func (s *ShipmentService) getTopicForStatus(status models.ShipmentStatus) string {
 	switch status {
 	case models.StatusPriceConfirmed:
-		return nats.TopicPriceConfirmed
+		return events.SubjectPriceConfirmed
 	case models.StatusPickupStarted:
-		return nats.TopicPickupStarted
+		return events.SubjectPickupStarted
 	case models.StatusInTransit:
-		return nats.TopicInTransit
+		return events.SubjectInTransit
 	case models.StatusDelivered:
-		return nats.TopicDelivered
+		return events.SubjectDelivered
 	case models.StatusCompleted:
-		return nats.TopicCompleted
+		return events.SubjectCompleted
 	case models.StatusCancelled:
-		return nats.TopicCancelled
+		return events.SubjectCancelled
 	case models.StatusDisputed:
-		return nats.TopicDisputed
+		return events.SubjectDisputed
 	case models.StatusResolved:
-		return nats.TopicResolved
+		return events.SubjectResolved
 	default:
-		return "shipment.status.changed"
+		return events.SubjectStatusChanged
 	}
 }
 
-func (s *ShipmentService) publishEvent(topic string, data interface{}) {
-	payload := nats.EventPayload{
-		EventID:   uuid.New().String(),
-		EventType: topic,
-		Timestamp: time.Now().UTC().Format(time.RFC3339),
-		Data:      data,
+// enqueueEvent writes an event to the outbox in tx, so it is only published,
+// by the outbox relayer, if the change it describes is committed
+func (s *ShipmentService) enqueueEvent(ctx context.Context, tx *sqlx.Tx, topic string, data events.Payload) error {
+	return enqueueEvent(ctx, s.outboxRepo.Tx(tx), topic, data)
+}
+
+// enqueueEvent writes an event to the outbox, due immediately. The data must
+// be the payload of the schema of topic and valid.
+func enqueueEvent(ctx context.Context, outboxRepo *repository.OutboxRepository, topic string, data events.Payload) error {
+	event, err := events.New(topic, data)
+	if err != nil {
+		return err
 	}
-	if err := s.natsClient.Publish(topic, payload); err != nil {
-		// Log error
-		fmt.Printf("Failed to publish event %s: %v\n", topic, err)
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event %s: %w", topic, err)
 	}
+	now := time.Now().UTC()
+	return outboxRepo.Create(ctx, &models.OutboxEvent{
+		ID:            event.EventID,
+		Subject:       topic,
+		Payload:       payload,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	})
 }
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/shipment-tracker/internal/models"
+	"github.com/smartwaste/shipment-tracker/internal/repository"
+)
+
+var (
+	// ErrKeyRegistered is returned when the public key is already registered
+	ErrKeyRegistered = errors.New("public key already registered")
+	// ErrInvalidSignature is returned when a confirmation is not signed by an
+	// active key of the confirming party
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// SigningKeyService manages the keys confirmations are signed with
+type SigningKeyService struct {
+	keyRepo *repository.SigningKeyRepository
+}
+
+// NewSigningKeyService creates a new SigningKeyService
+func NewSigningKeyService(keyRepo *repository.SigningKeyRepository) *SigningKeyService {
+	return &SigningKeyService{keyRepo: keyRepo}
+}
+
+// Register registers a public key for a user or driver
+func (s *SigningKeyService) Register(ctx context.Context, subjectID uuid.UUID, req *models.RegisterSigningKeyRequest) (*models.SigningKey, error) {
+	publicKey := strings.TrimSpace(req.PublicKey)
+	if _, err := models.ParsePublicKey(publicKey); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.keyRepo.GetByPublicKey(ctx, publicKey)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrKeyRegistered
+	}
+
+	key := &models.SigningKey{
+		ID:        uuid.New(),
+		SubjectID: subjectID,
+		Name:      strings.TrimSpace(req.Name),
+		PublicKey: publicKey,
+		CreatedAt: time.Now(),
+	}
+	if err := s.keyRepo.Create(ctx, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// List retrieves the keys of a user or driver, revoked ones included
+func (s *SigningKeyService) List(ctx context.Context, subjectID uuid.UUID) ([]models.SigningKey, error) {
+	return s.keyRepo.ListBySubject(ctx, subjectID)
+}
+
+// Revoke revokes a key of a user or driver; confirmations it verified stay
+// valid. It returns false if the subject has no such active key.
+func (s *SigningKeyService) Revoke(ctx context.Context, id, subjectID uuid.UUID) (bool, error) {
+	return s.keyRepo.Revoke(ctx, id, subjectID)
+}
+
+// Verify checks that signature signs message with one of the active keys of
+// the subject and returns that key
+func (s *SigningKeyService) Verify(ctx context.Context, subjectID uuid.UUID, message []byte, signature string) (*models.SigningKey, error) {
+	keys, err := s.keyRepo.ListActiveBySubject(ctx, subjectID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range keys {
+		if keys[i].Verify(message, signature) {
+			return &keys[i], nil
+		}
+	}
+	return nil, ErrInvalidSignature
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smartwaste/shipment-tracker/internal/models"
+	"github.com/smartwaste/shipment-tracker/internal/realtime"
+	"github.com/smartwaste/shipment-tracker/internal/repository"
+)
+
+var (
+	// ErrNotTracked is returned when a position is reported for a shipment
+	// that is not on its way
+	ErrNotTracked = errors.New("shipment is not being tracked")
+	// ErrFutureLocation is returned for a position dated in the future
+	ErrFutureLocation = errors.New("recorded_at is in the future")
+)
+
+// maxClockSkew is how far in the future a reported position may be dated,
+// allowing for the device clock running ahead
+const maxClockSkew = time.Minute
+
+// TrackingService records the positions of the drivers of shipments on their
+// way and streams them to the clients watching
+type TrackingService struct {
+	trackRepo *repository.TrackRepository
+	broker    *realtime.TrackBroker
+	geofence  *GeofenceService
+	eta       *ETAService
+}
+
+// NewTrackingService creates a new TrackingService
+func NewTrackingService(trackRepo *repository.TrackRepository, broker *realtime.TrackBroker, geofence *GeofenceService, eta *ETAService) *TrackingService {
+	return &TrackingService{trackRepo: trackRepo, broker: broker, geofence: geofence, eta: eta}
+}
+
+// RecordLocation adds a position of the assigned driver to the track log of
+// a shipment and pushes it to the clients watching. The position is then
+// checked against the geofences of the shipment, which may change its status,
+// and the arrival of the driver estimated again in the background.
+func (s *TrackingService) RecordLocation(ctx context.Context, shipment *models.Shipment, driverID uuid.UUID, req *models.RecordLocationRequest) (*models.TrackPoint, error) {
+	if !shipment.IsTracked() {
+		return nil, fmt.Errorf("%w in status %s", ErrNotTracked, shipment.Status)
+	}
+
+	now := time.Now().UTC()
+	recordedAt := now
+	if req.RecordedAt != nil {
+		if req.RecordedAt.After(now.Add(maxClockSkew)) {
+			return nil, ErrFutureLocation
+		}
+		recordedAt = req.RecordedAt.UTC()
+	}
+
+	point := &models.TrackPoint{
+		ID:         uuid.New(),
+		ShipmentID: shipment.ID,
+		DriverID:   driverID,
+		Status:     shipment.Status,
+		Latitude:   *req.Latitude,
+		Longitude:  *req.Longitude,
+		AccuracyM:  req.AccuracyM,
+		SpeedKmh:   req.SpeedKmh,
+		Heading:    req.Heading,
+		RecordedAt: recordedAt,
+		CreatedAt:  now,
+	}
+	if err := s.trackRepo.Create(ctx, point); err != nil {
+		return nil, err
+	}
+
+	s.broker.Publish(*point)
+
+	// The position is kept even if the geofences cannot be checked
+	if _, err := s.geofence.Evaluate(ctx, shipment, point); err != nil {
+		log.Printf("Failed to check geofences of shipment %s: %v", shipment.ID, err)
+	}
+
+	// Routing may be slow, so the driver does not wait for the estimate, which
+	// outlives the request
+	go s.refreshETA(context.WithoutCancel(ctx), *shipment, *point)
+	return point, nil
+}
+
+// refreshETA estimates the arrival of the driver from a reported position
+func (s *TrackingService) refreshETA(ctx context.Context, shipment models.Shipment, point models.TrackPoint) {
+	if err := s.eta.OnLocation(ctx, &shipment, &point); err != nil && !errors.Is(err, ErrETAUnavailable) {
+		log.Printf("Failed to estimate arrival of shipment %s: %v", shipment.ID, err)
+	}
+}
+
+// GetTrack retrieves up to limit positions of a shipment recorded after
+// since, oldest first
+func (s *TrackingService) GetTrack(ctx context.Context, shipmentID uuid.UUID, since *time.Time, limit int) ([]models.TrackPoint, error) {
+	return s.trackRepo.ListByShipment(ctx, shipmentID, since, limit)
+}
+
+// GetLatest retrieves the last known position of a shipment's driver
+func (s *TrackingService) GetLatest(ctx context.Context, shipmentID uuid.UUID) (*models.TrackPoint, error) {
+	return s.trackRepo.GetLatest(ctx, shipmentID)
+}
+
+// Subscribe streams the positions reported for a shipment from now on. The
+// returned function must be called to release the subscription.
+func (s *TrackingService) Subscribe(shipmentID uuid.UUID) (<-chan models.TrackPoint, func()) {
+	return s.broker.Subscribe(shipmentID)
+}